@@ -214,14 +214,10 @@ func runAgentsList(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	registry, err := agents.NewRegistry(agents.RegistryConfig{
-		UseBuiltin:  cfg.Agents.UseBuiltin,
-		SearchPaths: cfg.Agents.SearchPaths,
-	})
+	registry, err := agents.NewRegistryFromConfig(cfg)
 	if err != nil {
 		return fmt.Errorf("create registry: %w", err)
 	}
-	registry.SetPreferences(cfg.Agents.Preferences)
 
 	var agentList []*agents.Agent
 
@@ -271,6 +267,8 @@ func runAgentsList(cmd *cobra.Command, args []string) error {
 			case agents.SourceUser:
 				userDir, _ := agents.GetUserAgentsDir()
 				fmt.Printf("  [user] %s/\n", userDir)
+			case agents.SourceConfig:
+				fmt.Println("  [config] agents.presets in config.yaml")
 			case agents.SourceBuiltin:
 				fmt.Println("  [builtin]")
 			}
@@ -340,14 +338,10 @@ func runAgentsShow(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	registry, err := agents.NewRegistry(agents.RegistryConfig{
-		UseBuiltin:  cfg.Agents.UseBuiltin,
-		SearchPaths: cfg.Agents.SearchPaths,
-	})
+	registry, err := agents.NewRegistryFromConfig(cfg)
 	if err != nil {
 		return fmt.Errorf("create registry: %w", err)
 	}
-	registry.SetPreferences(cfg.Agents.Preferences)
 
 	agent, err := registry.Get(name)
 	if err != nil {
@@ -455,14 +449,10 @@ func runAgentsEdit(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	registry, err := agents.NewRegistry(agents.RegistryConfig{
-		UseBuiltin:  cfg.Agents.UseBuiltin,
-		SearchPaths: cfg.Agents.SearchPaths,
-	})
+	registry, err := agents.NewRegistryFromConfig(cfg)
 	if err != nil {
 		return fmt.Errorf("create registry: %w", err)
 	}
-	registry.SetPreferences(cfg.Agents.Preferences)
 
 	agent, err := registry.Get(name)
 	if err != nil {
@@ -473,6 +463,9 @@ func runAgentsEdit(cmd *cobra.Command, args []string) error {
 	if agent.Source == agents.SourceBuiltin {
 		return fmt.Errorf("cannot edit built-in agent '%s'. Copy it first: term-llm agents copy %s my-%s", name, name, name)
 	}
+	if agent.Source == agents.SourceConfig {
+		return fmt.Errorf("agent '%s' is defined in config.yaml (agents.presets). Edit it with: term-llm config edit", name)
+	}
 
 	// Get editor
 	editor := os.Getenv("EDITOR")
@@ -504,14 +497,10 @@ func runAgentsCopy(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	registry, err := agents.NewRegistry(agents.RegistryConfig{
-		UseBuiltin:  cfg.Agents.UseBuiltin,
-		SearchPaths: cfg.Agents.SearchPaths,
-	})
+	registry, err := agents.NewRegistryFromConfig(cfg)
 	if err != nil {
 		return fmt.Errorf("create registry: %w", err)
 	}
-	registry.SetPreferences(cfg.Agents.Preferences)
 
 	srcAgent, err := registry.Get(srcName)
 	if err != nil {
@@ -586,10 +575,7 @@ func agentNameCompletion(cmd *cobra.Command, args []string, toComplete string) (
 		return nil, cobra.ShellCompDirectiveError
 	}
 
-	registry, err := agents.NewRegistry(agents.RegistryConfig{
-		UseBuiltin:  cfg.Agents.UseBuiltin,
-		SearchPaths: cfg.Agents.SearchPaths,
-	})
+	registry, err := agents.NewRegistryFromConfig(cfg)
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveError
 	}
@@ -637,10 +623,7 @@ func exportableAgentCompletion(cmd *cobra.Command, args []string, toComplete str
 		return nil, cobra.ShellCompDirectiveError
 	}
 
-	registry, err := agents.NewRegistry(agents.RegistryConfig{
-		UseBuiltin:  cfg.Agents.UseBuiltin,
-		SearchPaths: cfg.Agents.SearchPaths,
-	})
+	registry, err := agents.NewRegistryFromConfig(cfg)
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveError
 	}
@@ -653,7 +636,7 @@ func exportableAgentCompletion(cmd *cobra.Command, args []string, toComplete str
 
 	var names []string
 	for _, agent := range agentList {
-		if agent.Source == agents.SourceBuiltin {
+		if agent.Source == agents.SourceBuiltin || agent.Source == agents.SourceConfig {
 			continue
 		}
 		if strings.HasPrefix(agent.Name, toComplete) {
@@ -704,10 +687,7 @@ func runAgentsPrefSet(cmd *cobra.Command, args []string) error {
 	// Validate that the agent exists (warn if not)
 	cfg, err := loadConfigWithSetup()
 	if err == nil {
-		registry, err := agents.NewRegistry(agents.RegistryConfig{
-			UseBuiltin:  cfg.Agents.UseBuiltin,
-			SearchPaths: cfg.Agents.SearchPaths,
-		})
+		registry, err := agents.NewRegistryFromConfig(cfg)
 		if err == nil {
 			if _, err := registry.Get(agentName); err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: agent '%s' does not exist\n", agentName)
@@ -820,23 +800,19 @@ func runAgentsExportGist(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	registry, err := agents.NewRegistry(agents.RegistryConfig{
-		UseBuiltin:  cfg.Agents.UseBuiltin,
-		SearchPaths: cfg.Agents.SearchPaths,
-	})
+	registry, err := agents.NewRegistryFromConfig(cfg)
 	if err != nil {
 		return fmt.Errorf("create registry: %w", err)
 	}
-	registry.SetPreferences(cfg.Agents.Preferences)
 
 	agent, err := registry.Get(name)
 	if err != nil {
 		return err
 	}
 
-	// Built-in agents can't be exported directly
-	if agent.Source == agents.SourceBuiltin {
-		return fmt.Errorf("cannot export built-in agent '%s'. Copy it first: term-llm agents copy %s my-%s", name, name, name)
+	// Built-in and config agents can't be exported directly
+	if agent.Source == agents.SourceBuiltin || agent.Source == agents.SourceConfig {
+		return fmt.Errorf("cannot export %s agent '%s'. Copy it first: term-llm agents copy %s my-%s", agent.Source.SourceName(), name, name, name)
 	}
 
 	// Initialize gist client
@@ -13,6 +13,7 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"charm.land/bubbles/v2/spinner"
 	tea "charm.land/bubbletea/v2"
@@ -41,7 +42,9 @@ var (
 	askNoSearch        bool
 	askText            bool
 	askPorcelain       bool
+	askQuiet           bool
 	askJSON            bool
+	askOutput          string
 	askProgressive     bool
 	askProvider        string
 	askFiles           []string
@@ -72,6 +75,8 @@ var (
 	askSkills string
 	// Session resume flag
 	askResume string
+	// Label for piped stdin
+	askStdinAs string
 
 	askRunnerCleanupTimeout = runpkg.DefaultRunnerCleanupTimeout
 
@@ -102,10 +107,13 @@ Examples:
   term-llm ask "Explain the difference between TCP and UDP" -d
   term-llm ask "List 5 programming languages" --text
   term-llm ask "Explain git rebase" --json | jq -c .
+  term-llm ask "Summarize README.md" -o json | jq -r .response
   term-llm ask -f code.go "Explain this code"
   term-llm ask -f code.go:10-50 "Explain this function"
   term-llm ask -f clipboard "What is this?"
   cat error.log | term-llm ask "What went wrong?"
+  git diff | term-llm ask --stdin-as changes.patch "Review this diff"
+  cat question.txt | term-llm ask -              (stdin is the question)
 
 Agent examples (use @agent shortcut or --agent flag):
   term-llm ask @reviewer "Review this code" -f main.go
@@ -156,21 +164,42 @@ func init() {
 	// Ask-specific flags
 	askCmd.Flags().BoolVarP(&askText, "text", "t", false, "Output plain text instead of rendered markdown")
 	askCmd.Flags().BoolVar(&askPorcelain, "porcelain", false, "Output plain text without tool status lines (implies --text)")
+	askCmd.Flags().BoolVar(&askQuiet, "quiet", false, "Do not print the run summary after a run that used tools")
 	askCmd.Flags().BoolVar(&askJSON, "json", false, "Emit JSONL event stream on stdout (one event per line, implies --text)")
+	askCmd.Flags().StringVarP(&askOutput, "output", "o", "text", "Output format: text, json (one result object at the end) or jsonl (same as --json)")
 	askCmd.Flags().BoolVar(&askProgressive, "progressive", false, "Enable progressive execution with persisted best-so-far progress")
 	askCmd.Flags().DurationVar(&askTimeout, "timeout", 0, "Set a hard deadline for the run (used by progressive execution for finalization budget)")
 	askCmd.Flags().StringVar(&askStopWhen, "stop-when", "", "Progressive stop condition: done or timeout (defaults to done in progressive mode)")
 	askCmd.Flags().StringVar(&askContinueWith, "continue-with", "", "Custom continuation prompt for progressive timeout mode")
 	askCmd.Flags().BoolVar(&askFast, "fast", false, "Use the configured fast provider/model instead of the default")
+	askCmd.Flags().StringVar(&askStdinAs, "stdin-as", "", "Attach piped stdin as a file with this name (e.g. changes.patch)")
 
 	// Session resume flag - NoOptDefVal allows --resume without a value
 	askCmd.Flags().StringVarP(&askResume, "resume", "r", "", "Continue a session (empty for most recent, or session ID)")
 	askCmd.Flags().Lookup("resume").NoOptDefVal = " " // space means "flag was passed without value"
+	_ = askCmd.RegisterFlagCompletionFunc("resume", sessionResumeFlagCompletion)
 
 	rootCmd.AddCommand(askCmd)
 }
 
-func runAsk(cmd *cobra.Command, args []string) error {
+func runAsk(cmd *cobra.Command, args []string) (err error) {
+	var jsonEnvelope *askJSONEnvelope
+	switch askOutput {
+	case "", "text":
+	case "jsonl":
+		askJSON = true
+	case "json":
+		askJSON = true
+		jsonEnvelope = newAskJSONEnvelope(cmd.OutOrStdout())
+		defer func() {
+			if finishErr := jsonEnvelope.finish(err); err == nil {
+				err = finishErr
+			}
+		}()
+	default:
+		return fmt.Errorf("invalid --output %q (want text, json or jsonl)", askOutput)
+	}
+
 	// Extract @agent from args if present
 	atAgent, filteredArgs := ExtractAgentFromArgs(args)
 	if atAgent != "" && askAgent == "" {
@@ -245,6 +274,7 @@ func runAsk(cmd *cobra.Command, args []string) error {
 		return err
 	}
 	engine := newEngine(provider, cfg)
+	quotaNotice := startQuotaWarning(ctx, cfg, provider)
 
 	// Set up debug logger if enabled
 	debugLogger, err := createDebugLogger(cfg)
@@ -358,6 +388,7 @@ func runAsk(cmd *cobra.Command, args []string) error {
 	settings.SessionID = sessionID
 	settings.SystemPrompt = InjectSkillsMetadata(settings.SystemPrompt, skillsSetup)
 	alignSettingsToActiveProvider(&settings, cfg, provider)
+	attachProjectContext(engine, newProjectContext(cfg, settings, settings.BaseDir))
 
 	// Initialize local tools if we have any
 	toolMgr, err := settings.SetupToolManager(cfg, engine)
@@ -395,7 +426,9 @@ func runAsk(cmd *cobra.Command, args []string) error {
 	}
 
 	if agent != nil && agent.OutputTool.IsConfigured() {
-		outputTool = registerAgentOutputTool(agent.OutputTool, toolMgr, engine)
+		if outputTool, err = registerAgentOutputTool(agent.OutputTool, toolMgr, engine); err != nil {
+			return err
+		}
 	}
 
 	RegisterSkillToolWithEngine(engine, toolMgr, skillsSetup)
@@ -419,20 +452,26 @@ func runAsk(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Read files if provided
+	// Read files if provided; "-" names stdin, which is read below.
+	fileArgs, stdinRequested := splitStdinArg(askFiles)
 	var files []input.FileContent
-	if len(askFiles) > 0 {
-		files, err = input.ReadFiles(askFiles)
+	if len(fileArgs) > 0 {
+		files, err = input.ReadFiles(fileArgs)
 		if err != nil {
 			return fmt.Errorf("failed to read files: %w", err)
 		}
 	}
 
 	// Read stdin if available
-	stdinContent, err := input.ReadStdin()
+	stdinContent, err := input.ReadStdinText()
 	if err != nil {
-		return fmt.Errorf("failed to read stdin: %w", err)
+		return err
+	}
+	if stdinContent == "" && (stdinRequested || question == input.StdinArg) {
+		return fmt.Errorf("stdin was requested with \"-\" but nothing was piped in")
 	}
+	question, files, stdinContent = applyAskStdin(question, files, stdinContent, askStdinAs, cmd.ErrOrStderr())
+	files, imageParts := splitAskImageFiles(files, imagePrepOptions(cfg), cmd.ErrOrStderr())
 
 	userPrompt := prompt.AskUserPrompt(question, files, stdinContent)
 
@@ -492,8 +531,9 @@ func runAsk(cmd *cobra.Command, args []string) error {
 	// Add session history (if resuming)
 	messages = append(messages, sessionMessages...)
 
-	// Add new user message
-	messages = append(messages, llm.UserText(userPrompt))
+	// Add new user message, images first as chat sends them
+	userParts := append(append([]llm.Part(nil), imageParts...), llm.Part{Type: llm.PartText, Text: userPrompt})
+	messages = append(messages, llm.Message{Role: llm.RoleUser, Parts: userParts})
 
 	debugMode := askDebug
 	if sess != nil {
@@ -537,6 +577,7 @@ func runAsk(cmd *cobra.Command, args []string) error {
 	var jsonEmit *jsonEmitter
 	if askJSON {
 		jsonEmit = newJSONEmitter(cmd.OutOrStdout())
+		jsonEmit.envelope = jsonEnvelope
 	}
 
 	// Create stream adapter for unified event handling with proper buffering
@@ -630,7 +671,7 @@ func runAsk(cmd *cobra.Command, args []string) error {
 		userMsg := &session.Message{
 			SessionID:   sess.ID,
 			Role:        llm.RoleUser,
-			Parts:       []llm.Part{{Type: llm.PartText, Text: userPrompt}},
+			Parts:       userParts,
 			TextContent: userPrompt,
 			CreatedAt:   time.Now(),
 			Sequence:    -1, // Auto-allocate sequence
@@ -730,6 +771,19 @@ func runAsk(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// The run summary is fed by the engine: turns and tokens as each turn
+	// completes, tools and file changes as each tool call finishes.
+	runSummary := ui.NewRunSummary(activeModel(cfg), settings.MaxTurns)
+	runSummary.SetProvider(cfg.DefaultProvider)
+	summaryTurnCompleted := turnCompletedCallback
+	turnCompletedCallback = func(ctx context.Context, turnIndex int, turnMessages []llm.Message, metrics llm.TurnMetrics) error {
+		runSummary.ObserveTurn(turnIndex, metrics)
+		if summaryTurnCompleted != nil {
+			return summaryTurnCompleted(ctx, turnIndex, turnMessages, metrics)
+		}
+		return nil
+	}
+
 	var compactionUsages compactionUsageCollector
 	compactionCallback := func(cbCtx context.Context, result *llm.CompactionResult) error {
 		compactionUsages.add(result)
@@ -814,37 +868,40 @@ func runAsk(cmd *cobra.Command, args []string) error {
 		contextEstimateCount = sess.LastMessageCount
 	}
 	baseRunReq := runpkg.Request{
-		Platform:                    runpkg.PlatformConsole,
-		AgentName:                   askAgent,
-		Messages:                    messages,
-		Engine:                      engine,
-		ProviderInstance:            provider,
-		SessionID:                   sessionID,
-		DeferSession:                true,
-		DisableRuntimePersistence:   true,
-		Provider:                    askProvider,
-		Model:                       activeModel(cfg),
-		Tools:                       settings.Tools,
-		ReadDirs:                    append([]string(nil), askReadDirs...),
-		WriteDirs:                   append([]string(nil), askWriteDirs...),
-		ShellAllow:                  append([]string(nil), askShellAllow...),
-		MCP:                         settings.MCP,
-		Skills:                      askSkills,
-		SystemMessage:               instructions,
-		MaxTurns:                    settings.MaxTurns,
-		MaxTurnsSet:                 true,
-		MaxOutputTokens:             settings.MaxOutputTokens,
-		Search:                      &searchEnabled,
-		Debug:                       debugMode,
-		DebugRaw:                    debugRaw,
-		ForceExternalSearch:         &forceExternalSearch,
-		DisableExternalWebFetch:     askNoWebFetch,
-		ExtraTools:                  append([]llm.ToolSpec(nil), req.Tools...),
-		IncludeConfiguredTools:      &includeConfiguredTools,
-		OnAssistantSnapshot:         assistantSnapshotCallback,
-		OnResponseCompleted:         responseCompletedCallback,
-		OnTurnCompleted:             turnCompletedCallback,
-		OnCompaction:                compactionCallback,
+		Platform:                  runpkg.PlatformConsole,
+		AgentName:                 askAgent,
+		Messages:                  messages,
+		Engine:                    engine,
+		ProviderInstance:          provider,
+		SessionID:                 sessionID,
+		DeferSession:              true,
+		DisableRuntimePersistence: true,
+		Provider:                  askProvider,
+		Model:                     activeModel(cfg),
+		Tools:                     settings.Tools,
+		ReadDirs:                  append([]string(nil), askReadDirs...),
+		WriteDirs:                 append([]string(nil), askWriteDirs...),
+		ShellAllow:                append([]string(nil), askShellAllow...),
+		MCP:                       settings.MCP,
+		Skills:                    askSkills,
+		SystemMessage:             instructions,
+		MaxTurns:                  settings.MaxTurns,
+		MaxTurnsSet:               true,
+		MaxOutputTokens:           settings.MaxOutputTokens,
+		Search:                    &searchEnabled,
+		Debug:                     debugMode,
+		DebugRaw:                  debugRaw,
+		ForceExternalSearch:       &forceExternalSearch,
+		DisableExternalWebFetch:   askNoWebFetch,
+		ExtraTools:                append([]llm.ToolSpec(nil), req.Tools...),
+		IncludeConfiguredTools:    &includeConfiguredTools,
+		OnAssistantSnapshot:       assistantSnapshotCallback,
+		OnResponseCompleted:       responseCompletedCallback,
+		OnTurnCompleted:           turnCompletedCallback,
+		OnCompaction:              compactionCallback,
+		OnToolExecuted: func(_ context.Context, event llm.Event) {
+			runSummary.ObserveToolExec(event)
+		},
 		OnSyntheticUserMessage:      persistSyntheticUserMessage,
 		ContextEstimateTotalTokens:  contextEstimateTotal,
 		ContextEstimateMessageCount: contextEstimateCount,
@@ -1161,6 +1218,9 @@ func runAsk(cmd *cobra.Command, args []string) error {
 				return finishInterrupted()
 			}
 			err := fmt.Errorf("streaming failed: %w", streamErr)
+			if turnID := llm.TurnIDFromError(streamErr); turnID != "" {
+				err = fmt.Errorf("turn %s: streaming failed: %w", turnID, streamErr)
+			}
 			if askJSON {
 				_ = emitFatalError(jsonEmit, stats, err)
 				jsonFinalPending = false
@@ -1266,10 +1326,15 @@ func runAsk(cmd *cobra.Command, args []string) error {
 		jsonFinalPending = false
 	}
 
+	if warning := quotaNotice.Poll(); warning != "" {
+		fmt.Fprintln(cmd.ErrOrStderr(), warning)
+	}
+	finishRunSummary(cmd.ErrOrStderr(), debugLogger, runSummary, !askQuiet && !askJSON && !askPorcelain && runSummary.HasToolActivity())
+
 	compactionUsages.merge(stats)
 	if showStats && stats != nil && !askJSON {
 		stats.Finalize()
-		setEstimatedStatsCost(stats, activeModel(cfg))
+		setEstimatedStatsCost(stats, cfg.DefaultProvider, activeModel(cfg))
 		fmt.Fprintln(cmd.ErrOrStderr(), stats.Render())
 	}
 
@@ -1628,7 +1693,7 @@ func streamPlainText(ctx context.Context, events <-chan ui.StreamEvent, suppress
 				trailingNewlines = 0
 				continue
 			case ui.StreamEventRetry:
-				fmt.Fprintf(stderr, "\r%s\n", ev.RetryStatus("Rate limited", 0, "..."))
+				fmt.Fprintf(stderr, "\r%s\n", ev.RetryStatus(ui.RetryLabel(ev.RetryReason, "Rate limited"), 0, "..."))
 
 			case ui.StreamEventUsage:
 				// Skip usage events in plain text mode
@@ -1701,15 +1766,17 @@ func streamPlainText(ctx context.Context, events <-chan ui.StreamEvent, suppress
 
 			case ui.StreamEventImage:
 				flushPendingText()
-				// Display image inline in plain text mode
+				// Display image inline in plain text mode, followed by where
+				// it was saved so the path is usable without image support.
 				if ev.ImagePath != "" {
 					if rendered := ui.RenderInlineImage(ev.ImagePath); rendered != "" {
 						fmt.Print(rendered)
 						fmt.Print("\r\n") // CR+LF to reset cursor position after image
-						printedAny = true
-						trailingNewlines = 1
-						lastPrintedType = ui.SegmentImage
 					}
+					fmt.Printf("[image saved to %s]\n", ev.ImagePath)
+					printedAny = true
+					trailingNewlines = 1
+					lastPrintedType = ui.SegmentImage
 				}
 
 			case ui.StreamEventDone:
@@ -1840,6 +1907,7 @@ type askRetryMsg struct {
 	Attempt     int
 	MaxAttempts int
 	WaitSecs    float64
+	Reason      string
 }
 type askReasoningMsg ui.StreamEvent
 type askPhaseMsg string
@@ -2202,7 +2270,7 @@ func (m askStreamModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		ev := msg.event
 		switch ev.Type {
 		case ui.StreamEventRetry:
-			innerMsg = askRetryMsg{Attempt: ev.RetryAttempt, MaxAttempts: ev.RetryMax, WaitSecs: ev.RetryWait}
+			innerMsg = askRetryMsg{Attempt: ev.RetryAttempt, MaxAttempts: ev.RetryMax, WaitSecs: ev.RetryWait, Reason: ev.RetryReason}
 		case ui.StreamEventUsage:
 			innerMsg = askUsageMsg{InputTokens: ev.InputTokens, OutputTokens: ev.OutputTokens}
 		case ui.StreamEventPhase:
@@ -2409,7 +2477,7 @@ func (m askStreamModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, m.tickEvery()
 
 	case askRetryMsg:
-		m.retryStatus = ui.FormatRetryStatus("Rate limited", msg.Attempt, msg.MaxAttempts, msg.WaitSecs, 0, "...")
+		m.retryStatus = ui.FormatRetryStatus(ui.RetryLabel(msg.Reason, "Rate limited"), msg.Attempt, msg.MaxAttempts, msg.WaitSecs, 0, "...")
 		return m, m.tickEvery()
 
 	case askPhaseMsg:
@@ -2918,7 +2986,9 @@ func enableMCPServersWithFeedback(ctx context.Context, mcpFlag string, engine *l
 	}
 
 	// Register MCP tools
-	mcp.RegisterMCPTools(mcpManager, engine.Tools())
+	if err := mcp.RegisterMCPTools(mcpManager, engine.Tools()); err != nil {
+		fmt.Fprintf(errWriter, "\rwarning: skipped MCP tools: %v\n", err)
+	}
 	tools := mcpManager.AllTools()
 
 	// Show result
@@ -3097,3 +3167,42 @@ func (tc *textCollector) Text() string {
 	defer tc.mu.Unlock()
 	return tc.text.String()
 }
+
+// askStdinMaxChars caps how much piped stdin goes into the prompt.
+const askStdinMaxChars = 200_000
+
+// splitStdinArg removes "-" from file arguments and reports whether it was
+// there.
+func splitStdinArg(paths []string) ([]string, bool) {
+	var rest []string
+	requested := false
+	for _, p := range paths {
+		if p == input.StdinArg {
+			requested = true
+			continue
+		}
+		rest = append(rest, p)
+	}
+	return rest, requested
+}
+
+// applyAskStdin places piped stdin in the prompt. Oversized input is cut
+// down the middle with a notice. A question of "-" means stdin is the
+// question itself; with a label, stdin is attached as a file of that name
+// so the model treats it like one.
+func applyAskStdin(question string, files []input.FileContent, stdin, label string, stderr io.Writer) (string, []input.FileContent, string) {
+	if stdin == "" {
+		return question, files, ""
+	}
+	if n := utf8.RuneCountInString(stdin); n > askStdinMaxChars {
+		fmt.Fprintf(stderr, "warning: stdin is %d characters; keeping the first and last %d\n", n, askStdinMaxChars/2)
+		stdin = llm.TruncateToolResult(stdin, askStdinMaxChars)
+	}
+	if question == input.StdinArg {
+		return strings.TrimSpace(stdin), files, ""
+	}
+	if label != "" {
+		return question, append(files, input.FileContent{Path: label, Content: stdin}), ""
+	}
+	return question, files, stdin
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/samsaffron/term-llm/internal/config"
+	"github.com/samsaffron/term-llm/internal/imageprep"
+	"github.com/samsaffron/term-llm/internal/input"
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+// imagePrepOptions returns the attachment settings from image.attachments.
+func imagePrepOptions(cfg *config.Config) imageprep.Options {
+	if cfg == nil {
+		return imageprep.Options{}
+	}
+	return imageprep.Options{
+		MaxDimension: cfg.Image.Attachments.MaxDimension,
+		Quality:      cfg.Image.Attachments.Quality,
+	}
+}
+
+// splitAskImageFiles moves image files out of the -f attachments into image
+// parts, shrinking each for the model and reporting it on w. Images the
+// pipeline cannot decode are sent unchanged with a warning.
+func splitAskImageFiles(files []input.FileContent, opts imageprep.Options, w io.Writer) ([]input.FileContent, []llm.Part) {
+	var text []input.FileContent
+	var parts []llm.Part
+	for _, f := range files {
+		data := []byte(f.Content)
+		mediaType := http.DetectContentType(data)
+		if !strings.HasPrefix(mediaType, "image/") {
+			text = append(text, f)
+			continue
+		}
+		res, err := imageprep.Prepare(data, opts)
+		if err != nil {
+			fmt.Fprintf(w, "Warning: %s: %v; sending unchanged\n", f.Path, err)
+			res = imageprep.Result{Data: data, MediaType: mediaType}
+		} else {
+			fmt.Fprintf(w, "Attached %s (%s)\n", f.Path, res.Summary())
+		}
+		parts = append(parts, llm.Part{
+			Type:      llm.PartImage,
+			ImageData: &llm.ToolImageData{MediaType: res.MediaType, Base64: base64.StdEncoding.EncodeToString(res.Data)},
+		})
+	}
+	return text, parts
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/samsaffron/term-llm/internal/imageprep"
+	"github.com/samsaffron/term-llm/internal/input"
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+func TestSplitAskImageFilesShrinksImagesAndKeepsText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 800, 400))); err != nil {
+		t.Fatal(err)
+	}
+	files := []input.FileContent{
+		{Path: "notes.txt", Content: "hello"},
+		{Path: "shot.png", Content: buf.String()},
+		{Path: "odd.bmp", Content: "BM\x00\x00 not decodable"},
+	}
+	var report strings.Builder
+
+	text, parts := splitAskImageFiles(files, imageprep.Options{MaxDimension: 200}, &report)
+
+	if len(text) != 1 || text[0].Path != "notes.txt" {
+		t.Fatalf("text files = %+v, want only notes.txt", text)
+	}
+	if len(parts) != 2 || parts[0].Type != llm.PartImage || parts[0].ImageData.MediaType != "image/png" {
+		t.Fatalf("parts = %+v, want two images", parts)
+	}
+	data, _ := base64.StdEncoding.DecodeString(parts[0].ImageData.Base64)
+	if cfg, _, err := image.DecodeConfig(bytes.NewReader(data)); err != nil || cfg.Width != 200 || cfg.Height != 100 {
+		t.Fatalf("shot.png = %+v (%v), want 200x100", cfg, err)
+	}
+	if parts[1].ImageData.MediaType != "image/bmp" {
+		t.Fatalf("unsupported image media type = %s, want it sent unchanged", parts[1].ImageData.MediaType)
+	}
+	out := report.String()
+	if !strings.Contains(out, "Attached shot.png (800×400") || !strings.Contains(out, "Warning: odd.bmp") {
+		t.Fatalf("report = %q", out)
+	}
+}
@@ -18,9 +18,10 @@ import (
 // writes happen atomically under a single mutex so output order
 // matches seq order.
 type jsonEmitter struct {
-	w   io.Writer
-	mu  sync.Mutex
-	seq int64
+	w        io.Writer
+	envelope *askJSONEnvelope // When set, events are folded into it instead of written
+	mu       sync.Mutex
+	seq      int64
 }
 
 func newJSONEmitter(w io.Writer) *jsonEmitter {
@@ -50,6 +51,9 @@ func (e *jsonEmitter) emit(eventType string, payload map[string]any) error {
 	if err != nil {
 		return fmt.Errorf("marshal %s event: %w", eventType, err)
 	}
+	if e.envelope != nil {
+		return e.envelope.record(eventType, data)
+	}
 	if _, err := e.w.Write(data); err != nil {
 		return err
 	}
@@ -219,11 +223,15 @@ func emitStreamEvent(e *jsonEmitter, ev ui.StreamEvent) error {
 		return e.emit("phase", map[string]any{"phase": ev.Phase})
 
 	case ui.StreamEventRetry:
-		return e.emit("retry", map[string]any{
+		payload := map[string]any{
 			"attempt":      ev.RetryAttempt,
 			"max":          ev.RetryMax,
 			"wait_seconds": ev.RetryWait,
-		})
+		}
+		if ev.RetryReason != "" {
+			payload["reason"] = ev.RetryReason
+		}
+		return e.emit("retry", payload)
 
 	case ui.StreamEventImage:
 		if ev.ImagePath == "" {
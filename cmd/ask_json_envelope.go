@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// askJSONEnvelope collects the JSONL events of one ask run and writes them
+// as a single JSON object once the run ends (--output json). The run
+// itself streams exactly as with --output jsonl, so tool loops and
+// on_complete hooks behave the same; only the printing is deferred.
+type askJSONEnvelope struct {
+	w io.Writer
+
+	mu       sync.Mutex
+	written  bool
+	response strings.Builder
+	result   askJSONResult
+	toolIdx  map[string]int // call_id → index into result.ToolCalls
+}
+
+// askJSONResult is the object printed by ask --output json.
+type askJSONResult struct {
+	SessionID   string             `json:"session_id,omitempty"`
+	Provider    string             `json:"provider"`
+	Model       string             `json:"model"`
+	Agent       string             `json:"agent,omitempty"`
+	Response    string             `json:"response"`
+	ToolCalls   []askJSONToolCall  `json:"tool_calls"`
+	Usage       askJSONUsage       `json:"usage"`
+	DurationMs  int64              `json:"duration_ms"`
+	LLMCalls    int                `json:"llm_calls"`
+	Progressive json.RawMessage    `json:"progressive,omitempty"`
+	OnComplete  *askJSONOnComplete `json:"on_complete,omitempty"`
+	Error       string             `json:"error,omitempty"`
+}
+
+type askJSONToolCall struct {
+	CallID  string          `json:"call_id,omitempty"`
+	Name    string          `json:"name"`
+	Info    string          `json:"info,omitempty"` // Short summary of the arguments
+	Args    json.RawMessage `json:"args,omitempty"`
+	Success bool            `json:"success"`
+}
+
+type askJSONUsage struct {
+	InputTokens       int `json:"input_tokens"`
+	OutputTokens      int `json:"output_tokens"`
+	CachedInputTokens int `json:"cached_input_tokens"`
+	CacheWriteTokens  int `json:"cache_write_tokens"`
+}
+
+type askJSONOnComplete struct {
+	Stdout string `json:"stdout,omitempty"`
+	Stderr string `json:"stderr,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func newAskJSONEnvelope(w io.Writer) *askJSONEnvelope {
+	return &askJSONEnvelope{
+		w:       w,
+		result:  askJSONResult{ToolCalls: []askJSONToolCall{}},
+		toolIdx: make(map[string]int),
+	}
+}
+
+// askJSONEvent is the union of the JSONL event fields the envelope reads.
+type askJSONEvent struct {
+	SessionID string          `json:"session_id"`
+	Provider  string          `json:"provider"`
+	Model     string          `json:"model"`
+	Agent     string          `json:"agent"`
+	Text      string          `json:"text"`
+	CallID    string          `json:"call_id"`
+	Name      string          `json:"name"`
+	Info      string          `json:"info"`
+	Args      json.RawMessage `json:"args"`
+	Success   bool            `json:"success"`
+	Message   string          `json:"message"`
+	Stderr    string          `json:"stderr"`
+	askJSONUsage
+	DurationMs int64 `json:"duration_ms"`
+	LLMCalls   int   `json:"llm_calls"`
+}
+
+// record folds one marshalled event into the result. The done event, which
+// always ends a run's event stream, writes the result.
+func (a *askJSONEnvelope) record(eventType string, data []byte) error {
+	var ev askJSONEvent
+	if err := json.Unmarshal(data, &ev); err != nil {
+		return fmt.Errorf("decode %s event: %w", eventType, err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	r := &a.result
+	switch eventType {
+	case "session.started":
+		r.SessionID, r.Provider, r.Model, r.Agent = ev.SessionID, ev.Provider, ev.Model, ev.Agent
+	case "text.delta":
+		a.response.WriteString(ev.Text)
+	case "tool.started":
+		if len(ev.Args) > 0 && string(ev.Args) == "null" {
+			ev.Args = nil
+		}
+		a.toolIdx[ev.CallID] = len(r.ToolCalls)
+		r.ToolCalls = append(r.ToolCalls, askJSONToolCall{CallID: ev.CallID, Name: ev.Name, Info: ev.Info, Args: ev.Args})
+	case "tool.completed":
+		if i, ok := a.toolIdx[ev.CallID]; ok {
+			r.ToolCalls[i].Success = ev.Success
+		}
+	case "usage":
+		r.Usage.InputTokens += ev.InputTokens
+		r.Usage.OutputTokens += ev.OutputTokens
+		r.Usage.CachedInputTokens += ev.CachedInputTokens
+		r.Usage.CacheWriteTokens += ev.CacheWriteTokens
+	case "stats":
+		r.DurationMs, r.LLMCalls = ev.DurationMs, ev.LLMCalls
+		if r.Usage == (askJSONUsage{}) {
+			r.Usage = ev.askJSONUsage
+		}
+	case "progressive.result":
+		r.Progressive = trimEventEnvelope(data)
+	case "on_complete.output":
+		a.onComplete().Stdout = ev.Text
+	case "on_complete.completed":
+		a.onComplete().Stderr = ev.Stderr
+	case "on_complete.failed":
+		a.onComplete().Stderr = ev.Stderr
+		a.onComplete().Error = ev.Message
+	case "error":
+		if r.Error == "" {
+			r.Error = ev.Message
+		}
+	case "done":
+		return a.writeLocked()
+	}
+	return nil
+}
+
+func (a *askJSONEnvelope) onComplete() *askJSONOnComplete {
+	if a.result.OnComplete == nil {
+		a.result.OnComplete = &askJSONOnComplete{}
+	}
+	return a.result.OnComplete
+}
+
+// finish writes the result if the run ended without a done event, such as
+// when it failed before streaming started. err becomes the result's error.
+func (a *askJSONEnvelope) finish(err error) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.written {
+		return nil
+	}
+	if err != nil && a.result.Error == "" {
+		a.result.Error = err.Error()
+	}
+	return a.writeLocked()
+}
+
+func (a *askJSONEnvelope) writeLocked() error {
+	if a.written {
+		return nil
+	}
+	a.written = true
+	a.result.Response = a.response.String()
+	enc := json.NewEncoder(a.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(a.result)
+}
+
+// trimEventEnvelope drops the JSONL envelope fields (type, seq, ts) from a
+// marshalled event so it can be embedded in the result.
+func trimEventEnvelope(data []byte) json.RawMessage {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+	delete(fields, "type")
+	delete(fields, "seq")
+	delete(fields, "ts")
+	trimmed, err := json.Marshal(fields)
+	if err != nil {
+		return nil
+	}
+	return trimmed
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/samsaffron/term-llm/internal/config"
+	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/spf13/viper"
+)
+
+// runAskWithMockProvider runs the ask command against provider with the
+// given --output format and returns what it wrote to stdout.
+func runAskWithMockProvider(t *testing.T, provider *llm.MockProvider, output string, setup func()) (string, error) {
+	t.Helper()
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	configDir := filepath.Join(configHome, "term-llm")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatalf("mkdir config: %v", err)
+	}
+	configYAML := "default_provider: mock\nproviders:\n  mock:\n    model: mock-model\nsessions:\n  enabled: false\n"
+	if err := os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(configYAML), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	oldProviderFactory := newAskProvider
+	newAskProvider = func(*config.Config, bool) (llm.Provider, error) { return provider, nil }
+	oldOutput, oldJSON, oldText, oldPorcelain := askOutput, askJSON, askText, askPorcelain
+	oldTools, oldReadDirs, oldProgressive := askTools, askReadDirs, askProgressive
+	askOutput, askJSON, askText, askPorcelain, askProgressive = output, false, false, false, false
+	t.Cleanup(func() {
+		newAskProvider = oldProviderFactory
+		askOutput, askJSON, askText, askPorcelain = oldOutput, oldJSON, oldText, oldPorcelain
+		askTools, askReadDirs, askProgressive = oldTools, oldReadDirs, oldProgressive
+	})
+	if setup != nil {
+		setup()
+	}
+
+	var stdout, stderr bytes.Buffer
+	oldStdout, oldStderr := askCmd.OutOrStdout(), askCmd.ErrOrStderr()
+	askCmd.SetOut(&stdout)
+	askCmd.SetErr(&stderr)
+	t.Cleanup(func() {
+		askCmd.SetOut(oldStdout)
+		askCmd.SetErr(oldStderr)
+	})
+	err := runAsk(askCmd, []string{"what is in notes.txt?"})
+	return stdout.String(), err
+}
+
+func decodeAskJSONResult(t *testing.T, stdout string) askJSONResult {
+	t.Helper()
+	var result askJSONResult
+	dec := json.NewDecoder(strings.NewReader(stdout))
+	if err := dec.Decode(&result); err != nil {
+		t.Fatalf("stdout is not a JSON object: %v\n%s", err, stdout)
+	}
+	if dec.More() {
+		t.Fatalf("stdout has more than one JSON value:\n%s", stdout)
+	}
+	return result
+}
+
+func TestAskOutputJSONPrintsSingleResultWithToolCalls(t *testing.T) {
+	dir := t.TempDir()
+	notes := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(notes, []byte("buy milk\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	provider := llm.NewMockProvider("mock").
+		WithCapabilities(llm.Capabilities{ToolCalls: true}).
+		AddToolCall("call-1", "read_file", map[string]string{"path": notes}).
+		AddTurn(llm.MockTurn{Text: "It says to buy milk.", Usage: llm.Usage{InputTokens: 12, OutputTokens: 5}})
+
+	stdout, err := runAskWithMockProvider(t, provider, "json", func() {
+		askTools, askReadDirs = "read_file", []string{dir}
+	})
+	if err != nil {
+		t.Fatalf("runAsk: %v", err)
+	}
+
+	result := decodeAskJSONResult(t, stdout)
+	if result.Response != "It says to buy milk." {
+		t.Errorf("response = %q", result.Response)
+	}
+	if result.Provider != "mock" || result.Model != "mock-model" {
+		t.Errorf("provider/model = %q/%q", result.Provider, result.Model)
+	}
+	if len(result.ToolCalls) != 1 || result.ToolCalls[0].Name != "read_file" || !result.ToolCalls[0].Success {
+		t.Errorf("tool calls = %+v, want one successful read_file", result.ToolCalls)
+	}
+	if result.Usage.InputTokens != 12 || result.Usage.OutputTokens != 5 {
+		t.Errorf("usage = %+v", result.Usage)
+	}
+	if result.Error != "" {
+		t.Errorf("error = %q, want none", result.Error)
+	}
+}
+
+func TestAskOutputJSONReportsFailure(t *testing.T) {
+	provider := llm.NewMockProvider("mock").AddError(errors.New("upstream exploded"))
+
+	stdout, err := runAskWithMockProvider(t, provider, "json", nil)
+	if err == nil {
+		t.Fatal("runAsk succeeded, want an error so the exit code is non-zero")
+	}
+	result := decodeAskJSONResult(t, stdout)
+	if !strings.Contains(result.Error, "upstream exploded") {
+		t.Errorf("error = %q, want the provider error", result.Error)
+	}
+}
+
+func TestAskOutputJSONLStreamsEvents(t *testing.T) {
+	provider := llm.NewMockProvider("mock").AddTextResponse("hello")
+
+	stdout, err := runAskWithMockProvider(t, provider, "jsonl", nil)
+	if err != nil {
+		t.Fatalf("runAsk: %v", err)
+	}
+	var types []string
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		var ev map[string]any
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			t.Fatalf("line %q is not JSON: %v", line, err)
+		}
+		types = append(types, ev["type"].(string))
+	}
+	if types[0] != "session.started" || types[len(types)-1] != "done" || !strings.Contains(strings.Join(types, ","), "text.delta") {
+		t.Fatalf("event types = %v", types)
+	}
+}
+
+func TestAskOutputRejectsUnknownFormat(t *testing.T) {
+	_, err := runAskWithMockProvider(t, llm.NewMockProvider("mock"), "yaml", nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid --output") {
+		t.Fatalf("err = %v, want an invalid --output error", err)
+	}
+}
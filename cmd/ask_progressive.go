@@ -240,7 +240,9 @@ func (b *askProgressiveBridge) HandleEvent(event llm.Event) error {
 		}
 	case llm.EventRetry:
 		b.stats.ScheduleRetryStart(event.RetryWaitSecs)
-		return b.send(ui.RetryEvent(event.RetryAttempt, event.RetryMaxAttempts, event.RetryWaitSecs))
+		retry := ui.RetryEvent(event.RetryAttempt, event.RetryMaxAttempts, event.RetryWaitSecs)
+		retry.RetryReason = event.RetryReason.Label()
+		return b.send(retry)
 	case llm.EventAttemptDiscard:
 		b.stats.DiscardUsage(b.attemptInput, b.attemptOutput, b.attemptCached, b.attemptCacheWrite, b.attemptUsageCalls)
 		b.resetAttemptUsage()
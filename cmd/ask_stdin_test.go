@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/samsaffron/term-llm/internal/input"
+)
+
+func TestSplitStdinArg(t *testing.T) {
+	rest, requested := splitStdinArg([]string{"main.go", "-", "*.md"})
+	if !requested || strings.Join(rest, ",") != "main.go,*.md" {
+		t.Fatalf("splitStdinArg = %v, %v", rest, requested)
+	}
+	if _, requested := splitStdinArg([]string{"main.go"}); requested {
+		t.Fatal("stdin reported as requested without -")
+	}
+}
+
+func TestApplyAskStdin(t *testing.T) {
+	diff := "--- a/x\n+++ b/x\n"
+	tests := []struct {
+		name         string
+		question     string
+		label        string
+		wantQuestion string
+		wantFile     string
+		wantStdin    string
+	}{
+		{name: "plain pipe", question: "review this", wantQuestion: "review this", wantStdin: diff},
+		{name: "labelled", question: "review this", label: "changes.patch", wantQuestion: "review this", wantFile: "changes.patch"},
+		{name: "stdin is the question", question: "-", wantQuestion: strings.TrimSpace(diff)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			question, files, stdin := applyAskStdin(tt.question, nil, diff, tt.label, &bytes.Buffer{})
+			if question != tt.wantQuestion || stdin != tt.wantStdin {
+				t.Fatalf("question=%q stdin=%q, want %q and %q", question, stdin, tt.wantQuestion, tt.wantStdin)
+			}
+			if tt.wantFile == "" {
+				if len(files) != 0 {
+					t.Fatalf("files = %+v, want none", files)
+				}
+				return
+			}
+			if len(files) != 1 || files[0] != (input.FileContent{Path: tt.wantFile, Content: diff}) {
+				t.Fatalf("files = %+v, want stdin as %s", files, tt.wantFile)
+			}
+		})
+	}
+}
+
+func TestApplyAskStdinTruncatesLargeInput(t *testing.T) {
+	var stderr bytes.Buffer
+	big := strings.Repeat("x", askStdinMaxChars+5000)
+	_, _, stdin := applyAskStdin("summarize", nil, big, "", &stderr)
+	if len(stdin) >= len(big) || !strings.Contains(stdin, "chars truncated") {
+		t.Fatalf("stdin was not truncated (len %d)", len(stdin))
+	}
+	if !strings.Contains(stderr.String(), "warning: stdin is") {
+		t.Fatalf("stderr = %q, want a truncation warning", stderr.String())
+	}
+}
@@ -215,11 +215,17 @@ func chatgptAuthStatus() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	detail := ""
+	var details []string
 	if creds.AccountID != "" {
-		detail = "account " + creds.AccountID
+		details = append(details, "account "+creds.AccountID)
 	}
-	return formatAuthStatusLine(label, formatExpiry(creds.ExpiresAt, creds.IsExpired()), detail), nil
+	if creds.RefreshToken != "" {
+		details = append(details, "refresh token")
+	} else {
+		details = append(details, "no refresh token")
+	}
+	state := formatExpiry("chatgpt", creds.ExpiresAt, creds.IsExpired(), creds.RefreshToken != "")
+	return formatAuthStatusLine(label, state, strings.Join(details, ", ")), nil
 }
 
 func copilotAuthStatus() (string, error) {
@@ -232,18 +238,27 @@ func copilotAuthStatus() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return formatAuthStatusLine(label, "chat "+formatExpiry(creds.ExpiresAt, creds.IsExpired()), billingDetail), nil
+	// Copilot stores a long-lived GitHub OAuth token and never a refresh token.
+	details := "no refresh token"
+	if billingDetail != "" {
+		details += ", " + billingDetail
+	}
+	return formatAuthStatusLine(label, "chat "+formatExpiry("copilot", creds.ExpiresAt, creds.IsExpired(), false), details), nil
 }
 
 // formatExpiry renders a human-friendly state string given a Unix expiry
 // timestamp. A zero expiry means "no expiry set" (Copilot tokens are
-// long-lived). Expired tokens are still considered "signed in" because the
-// next API call will refresh them transparently.
-func formatExpiry(unix int64, expired bool) string {
+// long-lived). Expired tokens with a refresh token are still considered
+// "signed in" because the next API call will refresh them transparently;
+// without one the user has to sign in again.
+func formatExpiry(provider string, unix int64, expired, refreshable bool) string {
 	if unix == 0 {
 		return "signed in (no expiry)"
 	}
 	if expired {
+		if !refreshable {
+			return "expired; run 'term-llm auth login " + provider + "'"
+		}
 		return "expired (will refresh on next use)"
 	}
 	return "signed in; expires " + time.Unix(unix, 0).UTC().Format("2006-01-02 15:04 UTC")
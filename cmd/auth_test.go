@@ -14,18 +14,20 @@ import (
 
 func TestFormatExpiry(t *testing.T) {
 	cases := []struct {
-		name    string
-		unix    int64
-		expired bool
-		want    string
+		name        string
+		unix        int64
+		expired     bool
+		refreshable bool
+		want        string
 	}{
-		{"no expiry", 0, false, "signed in (no expiry)"},
-		{"expired", time.Now().Add(-1 * time.Hour).Unix(), true, "expired (will refresh on next use)"},
-		{"future", time.Date(2030, 1, 2, 15, 4, 0, 0, time.UTC).Unix(), false, "signed in; expires 2030-01-02 15:04 UTC"},
+		{"no expiry", 0, false, false, "signed in (no expiry)"},
+		{"expired", time.Now().Add(-1 * time.Hour).Unix(), true, true, "expired (will refresh on next use)"},
+		{"expired without refresh token", time.Now().Add(-1 * time.Hour).Unix(), true, false, "expired; run 'term-llm auth login chatgpt'"},
+		{"future", time.Date(2030, 1, 2, 15, 4, 0, 0, time.UTC).Unix(), false, true, "signed in; expires 2030-01-02 15:04 UTC"},
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			if got := formatExpiry(c.unix, c.expired); got != c.want {
+			if got := formatExpiry("chatgpt", c.unix, c.expired, c.refreshable); got != c.want {
 				t.Fatalf("formatExpiry = %q, want %q", got, c.want)
 			}
 		})
@@ -163,7 +165,7 @@ func TestRunAuthStatus(t *testing.T) {
 	for _, want := range []string{
 		"ChatGPT (Codex)",
 		"signed in; expires 2030-01-02 15:04 UTC",
-		"(account acct-xyz)",
+		"(account acct-xyz, no refresh token)",
 		"GitHub Copilot",
 		"not signed in",
 	} {
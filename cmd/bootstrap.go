@@ -12,6 +12,7 @@ import (
 	"github.com/samsaffron/term-llm/internal/config"
 	"github.com/samsaffron/term-llm/internal/llm"
 	"github.com/samsaffron/term-llm/internal/ui"
+	"github.com/samsaffron/term-llm/internal/usage"
 )
 
 func loadConfig() (*config.Config, error) {
@@ -20,6 +21,8 @@ func loadConfig() (*config.Config, error) {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 	registerModelLimits(cfg)
+	registerPricing(cfg)
+	registerTokenizers(cfg)
 	return cfg, nil
 }
 
@@ -95,6 +98,38 @@ func registerModelLimits(cfg *config.Config) {
 	llm.RegisterConfigReasoningEfforts(reasoning)
 }
 
+// registerPricing makes configured model prices available to cost estimates.
+func registerPricing(cfg *config.Config) {
+	overrides := make(map[string]usage.PriceOverride, len(cfg.Pricing))
+	for _, p := range cfg.Pricing {
+		model := strings.TrimSpace(p.Model)
+		if model == "" {
+			continue
+		}
+		overrides[model] = usage.PriceOverride{
+			Input:       p.Input,
+			Output:      p.Output,
+			CachedInput: p.CachedInput,
+			CacheWrite:  p.CacheWrite,
+		}
+	}
+	usage.SetPriceOverrides(overrides)
+}
+
+// registerTokenizers applies configured model → token encoding overrides used
+// for context-size estimates.
+func registerTokenizers(cfg *config.Config) {
+	models := make(map[string]string, len(cfg.Tokenizers))
+	for _, t := range cfg.Tokenizers {
+		model := strings.TrimSpace(t.Model)
+		if model == "" {
+			continue
+		}
+		models[model] = t.Encoding
+	}
+	llm.RegisterTokenizerModels(models)
+}
+
 func configuredProviderModels(pc config.ProviderConfig) []string {
 	seen := make(map[string]bool, len(pc.Models)+1)
 	var models []string
@@ -126,6 +161,7 @@ func loadConfigWithSetup() (*config.Config, error) {
 			return nil, fmt.Errorf("setup cancelled: %w", err)
 		}
 		registerModelLimits(cfg)
+		registerPricing(cfg)
 		return cfg, nil
 	}
 
@@ -191,6 +227,7 @@ func initThemeFromConfig(cfg *config.Config) {
 		ReasoningHeader:  cfg.Theme.ReasoningHeader,
 		ReasoningRaw:     cfg.Theme.ReasoningRaw,
 	})
+	ui.SetHyperlinkMode(cfg.Theme.Hyperlinks)
 }
 
 // resolveForceExternalSearch determines whether to force external search based on
@@ -240,7 +277,10 @@ func createDebugLogger(cfg *config.Config) (*llm.DebugLogger, error) {
 	// Generate session ID: timestamp + random suffix for uniqueness
 	sessionID := generateSessionID()
 
-	logger, err := llm.NewDebugLogger(dir, sessionID)
+	logger, err := llm.NewDebugLoggerWithLimits(dir, sessionID, llm.DebugLogLimits{
+		MaxFileBytes:  int64(cfg.DebugLogs.MaxFileMB) << 20,
+		MaxTotalBytes: int64(cfg.DebugLogs.MaxTotalMB) << 20,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create debug logger: %w", err)
 	}
@@ -182,6 +182,7 @@ func init() {
 	// Session resume flag - NoOptDefVal allows --resume without a value
 	chatCmd.Flags().StringVarP(&chatResume, "resume", "r", "", "Resume session (empty for most recent, or session ID)")
 	chatCmd.Flags().Lookup("resume").NoOptDefVal = " " // space means "flag was passed without value"
+	_ = chatCmd.RegisterFlagCompletionFunc("resume", sessionResumeFlagCompletion)
 
 	rootCmd.AddCommand(chatCmd)
 }
@@ -498,6 +499,7 @@ func runChatOnce(ctx context.Context, cmd *cobra.Command, initialText, cliAgent
 	if err != nil {
 		return "", "", err
 	}
+	quotaNotice := startQuotaWarning(ctx, cfg, provider)
 	fastProvider, fastErr := llm.NewFastProvider(cfg, cfg.DefaultProvider)
 	if fastErr != nil {
 		fmt.Fprintf(cmd.ErrOrStderr(), "warning: fast provider setup failed: %v\n", fastErr)
@@ -662,7 +664,9 @@ func runChatOnce(ctx context.Context, cmd *cobra.Command, initialText, cliAgent
 	// Only enable alt-screen when stdout is a terminal (avoid corrupting piped output)
 	// Disable alt-screen in auto-send mode for clean output
 	autoSendMode := len(chatAutoSend) > 0
-	useAltScreen := term.IsTerminal(int(os.Stdout.Fd())) && !autoSendMode
+	interactiveScreen := term.IsTerminal(int(os.Stdout.Fd())) && !autoSendMode
+	screenTerminal := config.ChatTerminalKey(os.Getenv)
+	useAltScreen := interactiveScreen && cfg.Chat.ScreenMode(screenTerminal) != config.ChatScreenModeInline
 
 	// Create chat model
 	chatPlatformMessage := ""
@@ -670,6 +674,10 @@ func runChatOnce(ctx context.Context, cmd *cobra.Command, initialText, cliAgent
 		chatPlatformMessage = agent.PlatformMessages.For("chat")
 	}
 	model := chat.NewWithFastProviderAndApproval(cfg, provider, fastProvider, engine, providerKey, modelName, mcpManager, settings.MaxTurns, forceExternalSearch, chatNoWebFetch, settings.Search, enabledLocalTools, settings.Tools, settings.MCP, false, initialText, store, sess, useAltScreen, chatAutoSend, autoSendMode, chatTextMode, agentName, chatPlatformMessage, resolvedYolo, desiredApprovalMode, toolMgr)
+	if interactiveScreen {
+		model.SetScreenModeTerminal(screenTerminal)
+	}
+	model.SetAutoTitle(cfg.Sessions.AutoTitle)
 	model.ConfigureTerminalTitleEnvironment(chat.TerminalTitleEnvironmentFromEnv())
 	terminalTitleRestored := false
 	restoreTerminalTitle := func() {
@@ -729,10 +737,12 @@ func runChatOnce(ctx context.Context, cmd *cobra.Command, initialText, cliAgent
 	// Wire agent resolver, lister, and current agent for /handover support
 	model.SetAgentResolver(LoadAgent)
 	currentRuntimeContext := chat.RuntimeSystemContext{
-		SystemPrompt: cfg.Chat.Instructions,
-		ApplySkills:  skillContextApplier(skillsSetup),
-		Skills:       skillsSetup,
+		SystemPrompt:   cfg.Chat.Instructions,
+		ApplySkills:    skillContextApplier(skillsSetup),
+		Skills:         skillsSetup,
+		ProjectContext: newProjectContext(cfg, settings, runtimeDir),
 	}
+	attachProjectContext(engine, currentRuntimeContext.ProjectContext)
 	model.SetRuntimeSystemContextResolver(func(targetAgent *agents.Agent, providerKey, modelName, dir string) (chat.RuntimeSystemContext, error) {
 		systemMessage := chatSystemMessage
 		if targetAgent != agent {
@@ -767,11 +777,16 @@ func runChatOnce(ctx context.Context, cmd *cobra.Command, initialText, cliAgent
 	}
 
 	// Run the TUI
-	if useAltScreen {
+	if interactiveScreen {
 		opts = append(opts, tea.WithOutput(newPostFrameWriter(os.Stdout, model.TakePostFrameImageSequence)))
 	}
 	p := tea.NewProgram(model, opts...)
 	model.SetProgram(p)
+	go func() {
+		if warning := quotaNotice.Wait(); warning != "" {
+			p.Send(chat.FooterWarningMsg{Text: warning})
+		}
+	}()
 
 	// Set up spawn_agent event callback for subagent progress visibility
 	if toolMgr != nil {
@@ -791,7 +806,7 @@ func runChatOnce(ctx context.Context, cmd *cobra.Command, initialText, cliAgent
 		}
 		approvalMgr.PromptUIFunc = func(path string, isWrite bool, isShell bool, workDir string) (tools.ApprovalResult, error) {
 			// In alt screen mode, use inline approval UI
-			if useAltScreen {
+			if model.AltScreen() {
 				// Use buffered channel to prevent goroutine leak if TUI exits before responding
 				doneCh := make(chan tools.ApprovalResult, 1)
 				p.Send(chat.ApprovalRequestMsg{
@@ -830,44 +845,43 @@ func runChatOnce(ctx context.Context, cmd *cobra.Command, initialText, cliAgent
 		}
 	}
 
-	// Set up ask_user handling
-	if useAltScreen {
+	// Set up ask_user handling. /screen can switch modes between turns, so the
+	// UI is chosen per prompt.
+	askUserStart, askUserEnd := tools.CreateTUIHooks(p, func() {
+		done := make(chan struct{})
+		p.Send(chat.FlushBeforeAskUserMsg{Done: done})
+		<-done
+	})
+	tools.SetAskUserUIFunc(func(questions []tools.AskUserQuestion) ([]tools.AskUserAnswer, error) {
+		if !model.AltScreen() {
+			// In inline mode, use external UI with terminal release
+			askUserStart()
+			defer func() {
+				askUserEnd()
+				p.Send(chat.ResumeFromExternalUIMsg{})
+			}()
+			return tools.RunAskUser(questions)
+		}
+
 		// In alt screen mode, use inline rendering
-		tools.SetAskUserUIFunc(func(questions []tools.AskUserQuestion) ([]tools.AskUserAnswer, error) {
-			// Use buffered channel to prevent goroutine leak if TUI exits before responding
-			doneCh := make(chan []tools.AskUserAnswer, 1)
-			p.Send(chat.AskUserRequestMsg{
-				Questions: questions,
-				DoneCh:    doneCh,
-			})
-			// Block until user responds or context is cancelled
-			select {
-			case answers := <-doneCh:
-				if answers == nil {
-					return nil, fmt.Errorf("cancelled by user")
-				}
-				return answers, nil
-			case <-ctx.Done():
-				return nil, fmt.Errorf("cancelled: %w", ctx.Err())
-			}
-		})
-		defer tools.ClearAskUserUIFunc()
-	} else {
-		// In inline mode, use external UI with hooks
-		start, end := tools.CreateTUIHooks(p, func() {
-			done := make(chan struct{})
-			p.Send(chat.FlushBeforeAskUserMsg{Done: done})
-			<-done
+		// Use buffered channel to prevent goroutine leak if TUI exits before responding
+		doneCh := make(chan []tools.AskUserAnswer, 1)
+		p.Send(chat.AskUserRequestMsg{
+			Questions: questions,
+			DoneCh:    doneCh,
 		})
-		// Wrap end hook to also send resume message after terminal is restored
-		originalEnd := end
-		end = func() {
-			originalEnd()
-			p.Send(chat.ResumeFromExternalUIMsg{})
+		// Block until user responds or context is cancelled
+		select {
+		case answers := <-doneCh:
+			if answers == nil {
+				return nil, fmt.Errorf("cancelled by user")
+			}
+			return answers, nil
+		case <-ctx.Done():
+			return nil, fmt.Errorf("cancelled: %w", ctx.Err())
 		}
-		tools.SetAskUserHooks(start, end)
-		defer tools.ClearAskUserHooks()
-	}
+	})
+	defer tools.ClearAskUserUIFunc()
 
 	// Set up initiate_handover handling — works in both alt screen and inline modes
 	// because cmdHandover already handles both.
@@ -904,7 +918,11 @@ func runChatOnce(ctx context.Context, cmd *cobra.Command, initialText, cliAgent
 		}
 	}()
 
+	// The TUI owns the terminal: providers report AuthRequiredError instead of
+	// prompting on stdin, and the chat model runs the sign-in itself.
+	llm.SetInteractiveAuth(false)
 	finalModel, err = p.Run()
+	llm.SetInteractiveAuth(true)
 	restoreTerminalTitle()
 
 	// Cleanup MCP servers and Guardian-owned providers before closing logging.
@@ -1020,9 +1038,10 @@ func resolveChatRuntimeSystemContextWithConfig(cmd *cobra.Command, cfg *config.C
 
 	skillsSetup := SetupSkillsInDir(&cfg.Skills, chatSkills, agentSkills, errWriter, runtimeDir)
 	return chat.RuntimeSystemContext{
-		SystemPrompt: InjectSkillsMetadata(settings.SystemPrompt, skillsSetup),
-		ApplySkills:  skillContextApplier(skillsSetup),
-		Skills:       skillsSetup,
+		SystemPrompt:   InjectSkillsMetadata(settings.SystemPrompt, skillsSetup),
+		ApplySkills:    skillContextApplier(skillsSetup),
+		Skills:         skillsSetup,
+		ProjectContext: newProjectContext(cfg, settings, runtimeDir),
 	}, nil
 }
 
@@ -45,7 +45,9 @@ func applyChildSkillRuntime(engine *llm.Engine, toolMgr *tools.ToolManager, skil
 		}
 		for _, definition := range skill.ToolDefs {
 			if tool, ok := toolMgr.Registry.Get(definition.Name); ok {
-				engine.AddDynamicTool(tool)
+				if err := engine.AddDynamicTool(tool); err != nil {
+					return fmt.Errorf("register isolated skill %q tools: %w", skill.Name, err)
+				}
 			}
 		}
 	}
@@ -28,7 +28,9 @@ func TestApplyChildSkillRuntimeRegistersToolsBeforeRestricting(t *testing.T) {
 		t.Fatal(err)
 	}
 	engine := llm.NewEngine(llm.NewMockProvider("mock"), nil)
-	toolMgr.SetupEngine(engine)
+	if err := toolMgr.SetupEngine(engine); err != nil {
+		t.Fatal(err)
+	}
 
 	skill := &runpkg.SkillRunMetadata{
 		Name:                "review",
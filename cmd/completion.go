@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"sort"
 	"strings"
 
@@ -15,7 +16,9 @@ import (
 func ProviderFlagCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	// Try to load config for custom provider completions; nil is OK if it fails
 	cfg, _ := config.Load()
-	completions := llm.GetProviderCompletions(toComplete, false, cfg)
+	ctx, cancel := context.WithTimeout(context.Background(), completionBudget)
+	defer cancel()
+	completions := providerModelCompletions(ctx, cfg, toComplete)
 
 	// If completing provider name (no colon), don't add space so user can type ":"
 	if !strings.Contains(toComplete, ":") {
@@ -220,7 +223,7 @@ func ToolsFlagCompletion(cmd *cobra.Command, args []string, toComplete string) (
 // Returns the specific providers that the usage command supports.
 func UsageProviderFlagCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	// Include aliases (claude, gemini) that are accepted by the usage command
-	providers := []string{"claude-code", "claude", "copilot", "gemini-cli", "gemini", "term-llm", "all"}
+	providers := []string{"claude-code", "claude", "copilot", "chatgpt", "gemini-cli", "gemini", "term-llm", "all"}
 	var completions []string
 	for _, p := range providers {
 		if strings.HasPrefix(p, toComplete) {
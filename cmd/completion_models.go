@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/cache"
+	"github.com/samsaffron/term-llm/internal/config"
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+const (
+	// completionBudget bounds how long a completion that may touch the
+	// network can take before it answers from what it already has.
+	completionBudget = 300 * time.Millisecond
+	// modelCompletionCacheTTL is how long listed models are used without
+	// asking the provider again. Older lists are still offered when the
+	// provider cannot be reached.
+	modelCompletionCacheTTL = time.Hour
+)
+
+func completionModelCacheKey(provider string) string {
+	return "completion-" + strings.ReplaceAll(provider, "/", "_")
+}
+
+// readCompletionModels returns the model IDs last listed for provider and
+// whether the list is younger than modelCompletionCacheTTL.
+func readCompletionModels(provider string) ([]string, bool) {
+	cached, err := cache.ReadModelCache(completionModelCacheKey(provider))
+	if err != nil || cached == nil {
+		return nil, false
+	}
+	return cached.Models, time.Since(cached.FetchedAt) < modelCompletionCacheTTL
+}
+
+// writeCompletionModels caches the models a provider listed, for
+// completion to use later.
+func writeCompletionModels(provider string, models []llm.ModelInfo) {
+	ids := make([]string, 0, len(models))
+	for _, m := range models {
+		if m.ID != "" {
+			ids = append(ids, m.ID)
+		}
+	}
+	if len(ids) > 0 {
+		_ = cache.WriteModelCache(completionModelCacheKey(provider), ids)
+	}
+}
+
+// listModelsForCompletion asks a provider for its models. It is a variable
+// so tests can stand in for the network.
+var listModelsForCompletion = func(ctx context.Context, cfg *config.Config, providerName string) ([]llm.ModelInfo, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("no config")
+	}
+	providerCfg, configured := cfg.Providers[providerName]
+	providerType := config.InferProviderType(providerName, providerCfg.Type)
+	// Copilot may start an interactive sign-in, which completion must never do.
+	if !configured || !modelListSupportedTypes[providerType] || providerType == config.ProviderTypeCopilot {
+		return nil, fmt.Errorf("provider %q cannot list models here", providerName)
+	}
+	if err := cfg.ResolveProviderCredentials(providerName); err != nil {
+		return nil, err
+	}
+	lister, err := newModelLister(providerName, providerType, cfg.Providers[providerName], configured)
+	if err != nil {
+		return nil, err
+	}
+	return lister.ListModels(ctx)
+}
+
+// listedModelCompletions completes provider:model from the models the
+// provider listed. A stale or missing cache is refreshed if the provider
+// answers before ctx is done; otherwise the stale list is used.
+func listedModelCompletions(ctx context.Context, cfg *config.Config, provider, modelPrefix string) []string {
+	models, fresh := readCompletionModels(provider)
+	if !fresh {
+		type result struct {
+			models []llm.ModelInfo
+			err    error
+		}
+		done := make(chan result, 1)
+		go func() {
+			models, err := listModelsForCompletion(ctx, cfg, provider)
+			done <- result{models, err}
+		}()
+		select {
+		case r := <-done:
+			if r.err == nil && len(r.models) > 0 {
+				writeCompletionModels(provider, r.models)
+				models, _ = readCompletionModels(provider)
+			}
+		case <-ctx.Done():
+		}
+	}
+
+	var completions []string
+	for _, m := range models {
+		if strings.HasPrefix(m, modelPrefix) {
+			completions = append(completions, provider+":"+m)
+		}
+	}
+	return completions
+}
+
+// providerModelCompletions merges the built-in and configured completions
+// for a --provider value with the models the provider itself listed. It
+// answers within ctx's deadline, dropping whichever source is too slow.
+func providerModelCompletions(ctx context.Context, cfg *config.Config, toComplete string) []string {
+	static := make(chan []string, 1)
+	go func() { static <- llm.GetProviderCompletions(toComplete, false, cfg) }()
+
+	var listed []string
+	if provider, modelPrefix, ok := strings.Cut(toComplete, ":"); ok {
+		listed = listedModelCompletions(ctx, cfg, provider, modelPrefix)
+	}
+
+	var completions []string
+	select {
+	case completions = <-static:
+	case <-ctx.Done():
+	}
+	seen := make(map[string]bool, len(completions))
+	for _, c := range completions {
+		seen[c] = true
+	}
+	for _, c := range listed {
+		if !seen[c] {
+			seen[c] = true
+			completions = append(completions, c)
+		}
+	}
+	return completions
+}
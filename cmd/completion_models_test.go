@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/cache"
+	"github.com/samsaffron/term-llm/internal/config"
+	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/session"
+)
+
+func writeStaleCompletionCache(t *testing.T, provider string, models []string) {
+	t.Helper()
+	data, err := json.Marshal(cache.ModelCache{Models: models, FetchedAt: time.Now().Add(-2 * modelCompletionCacheTTL)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(os.Getenv("XDG_CACHE_HOME"), "term-llm")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, completionModelCacheKey(provider)+"-models.json"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func stubListModelsForCompletion(t *testing.T, fn func(ctx context.Context) ([]llm.ModelInfo, error)) *int {
+	t.Helper()
+	calls := 0
+	old := listModelsForCompletion
+	listModelsForCompletion = func(ctx context.Context, cfg *config.Config, provider string) ([]llm.ModelInfo, error) {
+		calls++
+		return fn(ctx)
+	}
+	t.Cleanup(func() { listModelsForCompletion = old })
+	return &calls
+}
+
+func TestListedModelCompletionsUsesFreshCacheWithoutListing(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	writeCompletionModels("acme", []llm.ModelInfo{{ID: "big-1"}, {ID: "big-2"}, {ID: "small-1"}})
+	calls := stubListModelsForCompletion(t, func(context.Context) ([]llm.ModelInfo, error) {
+		t.Fatal("a fresh cache should not be refreshed")
+		return nil, nil
+	})
+
+	got := listedModelCompletions(context.Background(), nil, "acme", "big")
+	if strings.Join(got, ",") != "acme:big-1,acme:big-2" || *calls != 0 {
+		t.Fatalf("completions = %v (calls %d)", got, *calls)
+	}
+}
+
+func TestListedModelCompletionsRefreshesStaleCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	writeStaleCompletionCache(t, "acme", []string{"old-1"})
+	stubListModelsForCompletion(t, func(context.Context) ([]llm.ModelInfo, error) {
+		return []llm.ModelInfo{{ID: "new-1"}}, nil
+	})
+
+	got := listedModelCompletions(context.Background(), nil, "acme", "")
+	if strings.Join(got, ",") != "acme:new-1" {
+		t.Fatalf("completions = %v, want the refreshed list", got)
+	}
+	if models, fresh := readCompletionModels("acme"); !fresh || strings.Join(models, ",") != "new-1" {
+		t.Fatalf("cache = %v (fresh %v), want the refreshed list", models, fresh)
+	}
+}
+
+func TestListedModelCompletionsFallsBackWhenProviderIsSlow(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	writeStaleCompletionCache(t, "acme", []string{"old-1"})
+	stubListModelsForCompletion(t, func(ctx context.Context) ([]llm.ModelInfo, error) {
+		<-ctx.Done()
+		time.Sleep(time.Second) // An unreachable provider that ignores cancellation
+		return nil, ctx.Err()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	got := listedModelCompletions(ctx, nil, "acme", "")
+	if elapsed := time.Since(start); elapsed > completionBudget {
+		t.Fatalf("completion took %s, want it to give up at the deadline", elapsed)
+	}
+	if strings.Join(got, ",") != "acme:old-1" {
+		t.Fatalf("completions = %v, want the stale list", got)
+	}
+}
+
+func TestSessionSummaryCompletions(t *testing.T) {
+	summaries := []session.SessionSummary{
+		{ID: "sess-abc", Number: 42, Name: "Auth refactor", Model: "gpt-5"},
+		{ID: "sess-def", Number: 7, Summary: "  fix   the\nflaky test  "},
+		{ID: "sess-ghi", Number: 41},
+	}
+	tests := []struct {
+		toComplete string
+		want       []string
+	}{
+		{"", []string{"42\tAuth refactor · gpt-5", "7\tfix the flaky test", "41\t(untitled)"}},
+		{"4", []string{"42\tAuth refactor · gpt-5", "41\t(untitled)"}},
+		{"#42", []string{"42\tAuth refactor · gpt-5"}},
+		{"sess-d", []string{"sess-def\tfix the flaky test"}},
+	}
+	for _, tt := range tests {
+		got := sessionSummaryCompletions(summaries, tt.toComplete)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("sessionSummaryCompletions(%q) = %q, want %q", tt.toComplete, got, tt.want)
+		}
+	}
+}
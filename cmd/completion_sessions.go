@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/samsaffron/term-llm/internal/session"
+	"github.com/spf13/cobra"
+)
+
+// sessionCompletionLimit caps how many recent sessions completion offers.
+const sessionCompletionLimit = 200
+
+// sessionArgCompletion completes the <number|id> argument of the sessions
+// subcommands from the local session store, most recent first. Numbers
+// are offered by default; IDs once the typed prefix can only be an ID.
+func sessionArgCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return sessionCompletions(toComplete, session.ListOptions{}, false), cobra.ShellCompDirectiveNoFileComp
+}
+
+// archivedSessionArgCompletion completes "sessions restore <number|id>".
+func archivedSessionArgCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return sessionCompletions(toComplete, session.ListOptions{ArchivedOnly: true}, false), cobra.ShellCompDirectiveNoFileComp
+}
+
+// sessionExportArgCompletion completes "sessions export <number|id|current> [path]".
+func sessionExportArgCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveDefault // The output path
+	}
+	return sessionCompletions(toComplete, session.ListOptions{}, true), cobra.ShellCompDirectiveNoFileComp
+}
+
+// sessionResumeFlagCompletion completes --resume on chat and ask.
+func sessionResumeFlagCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return sessionCompletions(toComplete, session.ListOptions{}, false), cobra.ShellCompDirectiveNoFileComp
+}
+
+func sessionCompletions(toComplete string, opts session.ListOptions, withCurrent bool) []string {
+	store, err := getSessionStore()
+	if err != nil {
+		return nil
+	}
+	defer store.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), completionBudget)
+	defer cancel()
+	opts.Limit = sessionCompletionLimit
+	summaries, err := store.List(ctx, opts)
+	if err != nil {
+		return nil
+	}
+	completions := sessionSummaryCompletions(summaries, toComplete)
+	if withCurrent && strings.HasPrefix("current", toComplete) {
+		completions = append([]string{"current\tthe session chat last marked current"}, completions...)
+	}
+	return completions
+}
+
+// sessionSummaryCompletions turns summaries into "value\tdescription"
+// completions matching toComplete, keeping the store's order.
+func sessionSummaryCompletions(summaries []session.SessionSummary, toComplete string) []string {
+	prefix := strings.TrimPrefix(toComplete, "#")
+	var completions []string
+	for _, s := range summaries {
+		desc := sessionCompletionDescription(s)
+		if s.Number > 0 {
+			if number := strconv.FormatInt(s.Number, 10); strings.HasPrefix(number, prefix) {
+				completions = append(completions, number+"\t"+desc)
+				continue
+			}
+		}
+		if prefix != "" && strings.HasPrefix(s.ID, prefix) {
+			completions = append(completions, s.ID+"\t"+desc)
+		}
+	}
+	return completions
+}
+
+func sessionCompletionDescription(s session.SessionSummary) string {
+	title := strings.Join(strings.Fields(s.PreferredShortTitle()), " ")
+	if title == "" {
+		title = "(untitled)"
+	}
+	if len([]rune(title)) > 60 {
+		title = string([]rune(title)[:59]) + "…"
+	}
+	if s.Model != "" {
+		title += " · " + s.Model
+	}
+	return title
+}
@@ -8,6 +8,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/samsaffron/term-llm/internal/config"
@@ -17,6 +18,11 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+var (
+	configSetType string
+	configForce   bool
+)
+
 var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "Manage term-llm configuration",
@@ -79,12 +85,30 @@ Examples:
   term-llm config set default_provider gemini
   term-llm config set providers.anthropic.model claude-opus-4-6
   term-llm config set exec.suggestions 5
-  term-llm config set image.provider flux`,
+  term-llm config set image.provider flux
+  term-llm config set serve.title 2026 --type string
+
+Unknown keys are rejected unless --force is given. Values are written as
+YAML infers them (true is a bool, 5 an int); --type forces a type.`,
 	Args:              cobra.ExactArgs(2),
 	RunE:              configSet,
 	ValidArgsFunction: configSetCompletion,
 }
 
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a configuration value",
+	Long: `Remove a configuration value so its default applies again, preserving
+comments. Mappings left empty by the removal are removed too.
+
+Examples:
+  term-llm config unset providers.anthropic.model
+  term-llm config unset serve.title`,
+	Args:              cobra.ExactArgs(1),
+	RunE:              configUnset,
+	ValidArgsFunction: configGetCompletion,
+}
+
 var configGetCmd = &cobra.Command{
 	Use:   "get <key>",
 	Short: "Get a configuration value",
@@ -107,6 +131,9 @@ func init() {
 	configCmd.AddCommand(configEditMcpCmd)
 	configCmd.AddCommand(configSetCmd)
 	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configSetCmd.Flags().StringVar(&configSetType, "type", "auto", "Value type: auto, string, bool, int or float")
+	configSetCmd.Flags().BoolVar(&configForce, "force", false, "Set the key even if it is not a known config key")
 }
 
 func configShow(cmd *cobra.Command, args []string) error {
@@ -954,136 +981,94 @@ func installShellCompletion(shell string) error {
 func configSet(cmd *cobra.Command, args []string) error {
 	key := args[0]
 	value := args[1]
+	if !configForce && !config.IsKnownKey(key) {
+		return fmt.Errorf("unknown config key %q (use --force to set it anyway)", key)
+	}
 	if isApprovalConfigKey(key) {
 		if _, err := parseConfiguredApprovalMode(key, value); err != nil {
 			return err
 		}
 	}
+	value, tag, err := typedConfigValue(value, configSetType)
+	if err != nil {
+		return err
+	}
 
 	configPath, err := config.GetConfigPath()
 	if err != nil {
 		return fmt.Errorf("failed to get config path: %w", err)
 	}
-
-	// Ensure config directory exists
-	configDir := filepath.Dir(configPath)
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
-	}
-
-	// Read existing file or create empty document
-	var root yaml.Node
-	data, err := os.ReadFile(configPath)
+	root, err := config.ReadConfigDocument(configPath)
 	if err != nil {
-		if os.IsNotExist(err) {
-			// Create new document with empty mapping
-			root = yaml.Node{
-				Kind: yaml.DocumentNode,
-				Content: []*yaml.Node{{
-					Kind: yaml.MappingNode,
-				}},
-			}
-		} else {
-			return fmt.Errorf("failed to read config: %w", err)
-		}
-	} else {
-		if err := yaml.Unmarshal(data, &root); err != nil {
-			return fmt.Errorf("failed to parse config: %w", err)
-		}
+		return err
 	}
 
 	// Navigate/create path and set value
-	keyParts := strings.Split(key, ".")
-	if err := setYAMLValue(&root, keyParts, value); err != nil {
+	if err := config.SetYAMLScalar(root, strings.Split(key, "."), value, tag); err != nil {
 		return fmt.Errorf("failed to set value: %w", err)
 	}
-
-	// Write back
-	var buf bytes.Buffer
-	encoder := yaml.NewEncoder(&buf)
-	encoder.SetIndent(2)
-	if err := encoder.Encode(&root); err != nil {
-		return fmt.Errorf("failed to encode config: %w", err)
-	}
-	encoder.Close()
-
-	if err := config.WriteFileAtomically(configPath, buf.Bytes(), 0644); err != nil {
-		return fmt.Errorf("failed to write config: %w", err)
+	if err := config.WriteConfigDocument(configPath, root); err != nil {
+		return err
 	}
 
 	fmt.Printf("%s = %s\n", key, value)
 	return nil
 }
 
-// setYAMLValue navigates/creates the path in a yaml.Node tree and sets the value
-func setYAMLValue(root *yaml.Node, path []string, value string) error {
-	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
-		return fmt.Errorf("invalid document structure")
+// configUnset removes a configuration value so its default applies again.
+func configUnset(cmd *cobra.Command, args []string) error {
+	key := args[0]
+	configPath, err := config.GetConfigPath()
+	if err != nil {
+		return fmt.Errorf("failed to get config path: %w", err)
 	}
-
-	current := root.Content[0]
-	if current.Kind != yaml.MappingNode {
-		return fmt.Errorf("root is not a mapping")
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return fmt.Errorf("config file does not exist")
 	}
-
-	for i, part := range path {
-		isLast := i == len(path)-1
-
-		// Find or create the key
-		found := false
-		for j := 0; j < len(current.Content); j += 2 {
-			keyNode := current.Content[j]
-			if keyNode.Value == part {
-				if isLast {
-					// Set the value
-					valueNode := current.Content[j+1]
-					valueNode.Value = value
-					valueNode.Tag = ""
-					valueNode.Kind = yaml.ScalarNode
-				} else {
-					// Navigate deeper
-					current = current.Content[j+1]
-					if current.Kind != yaml.MappingNode {
-						// Convert to mapping if needed
-						current.Kind = yaml.MappingNode
-						current.Content = nil
-						current.Value = ""
-						current.Tag = ""
-					}
-				}
-				found = true
-				break
-			}
-		}
-
-		if !found {
-			// Create the key
-			keyNode := &yaml.Node{
-				Kind:  yaml.ScalarNode,
-				Value: part,
-			}
-
-			if isLast {
-				// Create scalar value
-				valueNode := &yaml.Node{
-					Kind:  yaml.ScalarNode,
-					Value: value,
-				}
-				current.Content = append(current.Content, keyNode, valueNode)
-			} else {
-				// Create mapping for intermediate path
-				newMapping := &yaml.Node{
-					Kind: yaml.MappingNode,
-				}
-				current.Content = append(current.Content, keyNode, newMapping)
-				current = newMapping
-			}
-		}
+	root, err := config.ReadConfigDocument(configPath)
+	if err != nil {
+		return err
 	}
-
+	if !config.UnsetYAMLValue(root, strings.Split(key, ".")) {
+		return fmt.Errorf("key not found: %s", key)
+	}
+	if err := config.WriteConfigDocument(configPath, root); err != nil {
+		return err
+	}
+	fmt.Printf("unset %s\n", key)
 	return nil
 }
 
+// typedConfigValue checks value against a --type and returns it normalized
+// with the YAML tag to write. "auto" leaves the tag empty so the value is
+// written plain and YAML infers bool, int or string as usual.
+func typedConfigValue(value, typ string) (string, string, error) {
+	switch typ {
+	case "", "auto":
+		return value, "", nil
+	case "string":
+		return value, "!!str", nil
+	case "bool":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid bool %q", value)
+		}
+		return strconv.FormatBool(b), "!!bool", nil
+	case "int":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid int %q", value)
+		}
+		return strconv.FormatInt(n, 10), "!!int", nil
+	case "float":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "", "", fmt.Errorf("invalid float %q", value)
+		}
+		return value, "!!float", nil
+	}
+	return "", "", fmt.Errorf("unknown --type %q (want auto, string, bool, int or float)", typ)
+}
+
 // configGet gets a configuration value
 func configGet(cmd *cobra.Command, args []string) error {
 	key := args[0]
@@ -1167,7 +1152,12 @@ func getYAMLValue(root *yaml.Node, path []string) (string, error) {
 	if current.Kind == yaml.ScalarNode {
 		return current.Value, nil
 	}
-	return "", fmt.Errorf("value is not a scalar")
+	// Mappings and lists print as YAML.
+	out, err := yaml.Marshal(current)
+	if err != nil {
+		return "", fmt.Errorf("encode value: %w", err)
+	}
+	return strings.TrimRight(string(out), "\n"), nil
 }
 
 // configSetCompletion provides completions for config set
@@ -1301,6 +1291,9 @@ func configValueCompletions(key, toComplete string) []string {
 			}
 		}
 		return completions
+
+	case "edit.fuzzy_match":
+		return filterPrefix([]string{"exact", "whitespace", "similar"}, toComplete)
 	}
 
 	if isBoolConfigKey(key) {
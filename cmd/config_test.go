@@ -71,6 +71,110 @@ func TestConfigSet_AtomicWritePreservesExistingMode(t *testing.T) {
 	}
 }
 
+func TestConfigSetGetUnsetPreservesComments(t *testing.T) {
+	xdgHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdgHome)
+	configPath := filepath.Join(xdgHome, "term-llm", "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	seed := `# my config
+default_provider: anthropic # picked after testing
+
+providers:
+  # work account
+  anthropic:
+    model: claude-sonnet-4-6
+`
+	if err := os.WriteFile(configPath, []byte(seed), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	defer func(typ string, force bool) { configSetType, configForce = typ, force }(configSetType, configForce)
+	set := func(key, value, typ string) error {
+		configSetType = typ
+		return configSet(nil, []string{key, value})
+	}
+
+	if err := set("default_provider", "openai", "auto"); err != nil {
+		t.Fatal(err)
+	}
+	if err := set("serve.telegram.idle_timeout", "45", "auto"); err != nil {
+		t.Fatal(err)
+	}
+	if err := set("serve.title", "2026", "string"); err != nil {
+		t.Fatal(err)
+	}
+	if err := set("serve.disable_metrics", "yes", "bool"); err == nil {
+		t.Fatal("--type bool accepted \"yes\"")
+	}
+	if err := set("no_such_key", "1", "auto"); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("unknown key error = %v, want a hint about --force", err)
+	}
+	configForce = true
+	if err := set("experimental.thing", "on", "auto"); err != nil {
+		t.Fatalf("--force set: %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	for _, want := range []string{"# my config", "default_provider: openai # picked after testing", "# work account", "model: claude-sonnet-4-6", "idle_timeout: 45", `title: "2026"`, "thing: on"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("config after set is missing %q:\n%s", want, got)
+		}
+	}
+	var parsed struct {
+		Serve struct {
+			Title    string `yaml:"title"`
+			Telegram struct {
+				IdleTimeout int `yaml:"idle_timeout"`
+			} `yaml:"telegram"`
+		} `yaml:"serve"`
+	}
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Serve.Title != "2026" || parsed.Serve.Telegram.IdleTimeout != 45 {
+		t.Errorf("parsed serve config = %+v", parsed.Serve)
+	}
+
+	root, err := config.ReadConfigDocument(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := getYAMLValue(root, []string{"providers", "anthropic"}); err != nil || v != "model: claude-sonnet-4-6" {
+		t.Errorf("get of a mapping = %q, %v; want it as YAML", v, err)
+	}
+
+	if err := configUnset(nil, []string{"serve.telegram.idle_timeout"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := configUnset(nil, []string{"serve.telegram.idle_timeout"}); err == nil {
+		t.Fatal("unsetting a missing key succeeded")
+	}
+	data, _ = os.ReadFile(configPath)
+	got = string(data)
+	if strings.Contains(got, "telegram") || !strings.Contains(got, `title: "2026"`) || !strings.Contains(got, "# my config") {
+		t.Errorf("unset should drop the emptied telegram mapping and keep the rest:\n%s", got)
+	}
+}
+
+func TestReadConfigDocumentHandlesEmptyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	root, err := config.ReadConfigDocument(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SetYAMLValue(root, []string{"debug_logs", "enabled"}, "true"); err != nil {
+		t.Fatalf("set on an empty file: %v", err)
+	}
+}
+
 func TestEffectiveApprovalConfigValue(t *testing.T) {
 	if got, ok, err := effectiveApprovalConfigValue("chat.approval_mode", &config.Config{}); err != nil || !ok || got != "auto (builtin_default)" {
 		t.Fatalf("blank chat effective value = %q, %t, %v; want auto builtin_default", got, ok, err)
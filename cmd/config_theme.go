@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"bytes"
 	"fmt"
 	"os"
 	"strings"
@@ -12,7 +11,6 @@ import (
 	"github.com/samsaffron/term-llm/internal/config"
 	"github.com/samsaffron/term-llm/internal/ui"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 )
 
 var configThemeCmd = &cobra.Command{
@@ -83,25 +81,9 @@ func saveThemeToConfig(themeConfig ui.ThemeConfig) error {
 		return err
 	}
 
-	// Read existing file or create empty document
-	var root yaml.Node
-	data, err := os.ReadFile(configPath)
+	root, err := config.ReadConfigDocument(configPath)
 	if err != nil {
-		if os.IsNotExist(err) {
-			// Create new document with empty mapping
-			root = yaml.Node{
-				Kind: yaml.DocumentNode,
-				Content: []*yaml.Node{{
-					Kind: yaml.MappingNode,
-				}},
-			}
-		} else {
-			return err
-		}
-	} else {
-		if err := yaml.Unmarshal(data, &root); err != nil {
-			return err
-		}
+		return err
 	}
 
 	// Set each theme field
@@ -117,21 +99,11 @@ func saveThemeToConfig(themeConfig ui.ThemeConfig) error {
 	}
 
 	for _, f := range fields {
-		if err := setYAMLValue(&root, strings.Split(f.key, "."), f.value); err != nil {
+		if err := config.SetYAMLValue(root, strings.Split(f.key, "."), f.value); err != nil {
 			return err
 		}
 	}
-
-	// Write back
-	var buf bytes.Buffer
-	encoder := yaml.NewEncoder(&buf)
-	encoder.SetIndent(2)
-	if err := encoder.Encode(&root); err != nil {
-		return err
-	}
-	encoder.Close()
-
-	return os.WriteFile(configPath, buf.Bytes(), 0644)
+	return config.WriteConfigDocument(configPath, root)
 }
 
 // themeSelectorModel is the bubbletea model for theme selection
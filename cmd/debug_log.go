@@ -1,19 +1,20 @@
 package cmd
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/samsaffron/term-llm/internal/config"
+	"github.com/samsaffron/term-llm/internal/credentials"
 	"github.com/samsaffron/term-llm/internal/debuglog"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 )
 
 var debugLogCmd = &cobra.Command{
@@ -28,6 +29,7 @@ Examples:
   term-llm debug-log              # list recent sessions
   term-llm debug-log show 1       # view most recent session
   term-llm debug-log tail         # live tail of current session
+  term-llm debug-log tail --all   # merged tail of every active session
   term-llm debug-log search "error"
   term-llm debug-log clean --days 3`,
 	RunE: debugLogList, // Default to list
@@ -39,6 +41,8 @@ var (
 	debugLogRaw       bool
 	debugLogJSON      bool
 	debugLogFollow    bool
+	debugLogNoFollow  bool
+	debugLogTailAll   bool
 	debugLogRedact    bool
 	debugLogMarkdown  bool
 	debugLogDryRun    bool
@@ -46,6 +50,9 @@ var (
 	debugLogProvider  string
 	debugLogToolName  string
 	debugLogErrors    bool
+	debugLogTurn      string
+	debugLogSince     string
+	debugLogUntil     string
 )
 
 func init() {
@@ -73,15 +80,19 @@ func init() {
 	debugLogShowCmd.Flags().BoolVar(&debugLogRaw, "raw", false, "Output raw JSONL")
 	debugLogShowCmd.Flags().BoolVar(&debugLogShowTools, "tools", false, "Highlight tool calls and arguments")
 	debugLogShowCmd.Flags().BoolVar(&debugLogJSON, "json", false, "Output as pretty-printed JSON")
+	addDebugLogFilterFlags(debugLogShowCmd)
 
 	// Tail flags
 	debugLogTailCmd.Flags().BoolVarP(&debugLogFollow, "follow", "f", true, "Follow for new entries")
+	debugLogTailCmd.Flags().BoolVar(&debugLogNoFollow, "no-follow", false, "Print the current content and exit")
+	debugLogTailCmd.Flags().BoolVar(&debugLogTailAll, "all", false, "Merge all active sessions, including ones started later")
 
 	// Search flags
 	debugLogSearchCmd.Flags().StringVar(&debugLogToolName, "tool", "", "Filter by tool name")
 	debugLogSearchCmd.Flags().StringVar(&debugLogProvider, "provider", "", "Filter by provider")
 	debugLogSearchCmd.Flags().BoolVar(&debugLogErrors, "errors", false, "Show only errors")
 	debugLogSearchCmd.Flags().IntVar(&debugLogDays, "days", 7, "Search sessions from last N days")
+	debugLogSearchCmd.Flags().StringVar(&debugLogTurn, "turn", "", "Only match entries of the engine turn with this ID")
 
 	// Clean flags
 	debugLogCleanCmd.Flags().IntVar(&debugLogDays, "days", 7, "Remove logs older than N days")
@@ -89,10 +100,42 @@ func init() {
 	debugLogCleanCmd.Flags().BoolVar(&debugLogDryRun, "dry-run", false, "Show what would be deleted")
 
 	// Export flags
-	debugLogExportCmd.Flags().BoolVar(&debugLogRedact, "redact", false, "Redact sensitive content")
+	debugLogExportCmd.Flags().BoolVar(&debugLogRedact, "redact", false, "Redact API keys, tokens, stored credentials and home paths")
 	debugLogExportCmd.Flags().BoolVar(&debugLogMarkdown, "markdown", false, "Export as markdown")
 	debugLogExportCmd.Flags().BoolVar(&debugLogJSON, "json", false, "Export as JSON (default)")
 	debugLogExportCmd.Flags().BoolVar(&debugLogRaw, "raw", false, "Export raw JSONL")
+	addDebugLogFilterFlags(debugLogExportCmd)
+}
+
+func addDebugLogFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&debugLogTurn, "turn", "", "Only include turn N, turns N..M (each request starts a turn), or the engine turn with this ID")
+	cmd.Flags().StringVar(&debugLogSince, "since", "", "Only include entries at or after this time (RFC 3339, \"YYYY-MM-DD HH:MM\" or \"HH:MM\")")
+	cmd.Flags().StringVar(&debugLogUntil, "until", "", "Only include entries at or before this time")
+}
+
+// debugLogFilter builds the --turn/--since/--until filter for a session.
+// Bare clock times are taken on the day the session started.
+func debugLogFilter(session *debuglog.Session) (debuglog.FilterOptions, error) {
+	var filter debuglog.FilterOptions
+	var err error
+	if debuglog.IsTurnID(debugLogTurn) {
+		filter.TurnID = strings.ToUpper(strings.TrimSpace(debugLogTurn))
+	} else if debugLogTurn != "" {
+		if filter.FromTurn, filter.ToTurn, err = debuglog.ParseTurnRange(debugLogTurn); err != nil {
+			return filter, err
+		}
+	}
+	if debugLogSince != "" {
+		if filter.Since, err = debuglog.ParseFilterTime(debugLogSince, session.StartTime); err != nil {
+			return filter, fmt.Errorf("--since: %w", err)
+		}
+	}
+	if debugLogUntil != "" {
+		if filter.Until, err = debuglog.ParseFilterTime(debugLogUntil, session.StartTime); err != nil {
+			return filter, fmt.Errorf("--until: %w", err)
+		}
+	}
+	return filter, filter.Check(session)
 }
 
 var debugLogListCmd = &cobra.Command{
@@ -121,7 +164,11 @@ Examples:
   term-llm debug-log show           # most recent
   term-llm debug-log show 1         # by number
   term-llm debug-log show --tools   # with tool details
-  term-llm debug-log show --raw     # raw JSONL output`,
+  term-llm debug-log show --raw     # raw JSONL output
+  term-llm debug-log show 1 --turn 12        # only the 12th request and its events
+  term-llm debug-log show 1 --turn 40..45    # turns 40 through 45
+  term-llm debug-log show 1 --turn 01J9XQ3T5B8N2K7M4C6D0E1F2G  # one engine turn by ID
+  term-llm debug-log show 1 --since 14:05 --until 14:10`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: debugLogShow,
 }
@@ -133,12 +180,19 @@ var debugLogTailCmd = &cobra.Command{
 
 Great for watching what's happening in another terminal while running a command.
 
+With --all, every session written to in the last 15 minutes is shown in one
+merged view, each line prefixed with a colored session ID. Sessions that start
+later (for example a job run while you chat) are picked up automatically.
+With --no-follow it prints the last few entries of each active session once.
+
 Press Ctrl+C to stop.
 
 Examples:
   term-llm debug-log tail           # tail most recent session
   term-llm debug-log tail -f        # follow for new entries (default)
-  term-llm debug-log tail --no-follow  # show current content and exit`,
+  term-llm debug-log tail --no-follow  # show current content and exit
+  term-llm debug-log tail --all     # merged tail of all active sessions
+  term-llm debug-log tail --all --no-follow  # recent entries of each, once`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: debugLogTail,
 }
@@ -152,7 +206,8 @@ Examples:
   term-llm debug-log search "connection refused"
   term-llm debug-log search --tool read_file
   term-llm debug-log search --errors
-  term-llm debug-log search --provider anthropic`,
+  term-llm debug-log search --provider anthropic
+  term-llm debug-log search --turn 01J9XQ3T5B8N2K7M4C6D0E1F2G`,
 	RunE: debugLogSearch,
 }
 
@@ -266,27 +321,36 @@ func debugLogShow(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("session not found")
 	}
 
+	session, err := debuglog.ParseSession(summary.FilePath)
+	if err != nil {
+		return fmt.Errorf("failed to parse session: %w", err)
+	}
+	filter, err := debugLogFilter(session)
+	if err != nil {
+		return err
+	}
+
 	// Raw output
 	if debugLogRaw {
-		return debuglog.ExportRawByID(dir, summary.ID, os.Stdout)
+		return debuglog.Export(os.Stdout, session, debuglog.ExportOptions{
+			Format: debuglog.FormatRaw,
+			Filter: filter,
+		})
 	}
 
 	// JSON output
 	if debugLogJSON {
-		return debuglog.ExportSessionByID(dir, summary.ID, os.Stdout, debuglog.ExportOptions{
+		return debuglog.Export(os.Stdout, session, debuglog.ExportOptions{
 			Format: debuglog.FormatJSON,
+			Filter: filter,
 		})
 	}
 
 	// Human-readable output
-	session, err := debuglog.ParseSession(summary.FilePath)
-	if err != nil {
-		return fmt.Errorf("failed to parse session: %w", err)
-	}
-
 	debuglog.FormatSession(os.Stdout, session, debuglog.FormatOptions{
 		ShowTools:     debugLogShowTools,
 		ShowTimestamp: true,
+		Filter:        filter,
 	})
 
 	return nil
@@ -295,6 +359,14 @@ func debugLogShow(cmd *cobra.Command, args []string) error {
 // debugLogTail tails a session file
 func debugLogTail(cmd *cobra.Command, args []string) error {
 	dir := getDebugLogDir()
+	follow := debugLogFollow && !debugLogNoFollow
+
+	if debugLogTailAll {
+		if len(args) > 0 {
+			return fmt.Errorf("--all cannot be combined with a session argument")
+		}
+		return debugLogTailAllSessions(dir, follow)
+	}
 
 	// Determine which session to tail
 	var filePath string
@@ -329,7 +401,7 @@ func debugLogTail(cmd *cobra.Command, args []string) error {
 	}()
 
 	err := debuglog.Tail(ctx, filePath, os.Stdout, debuglog.TailOptions{
-		Follow: debugLogFollow,
+		Follow: follow,
 	})
 
 	if err == context.Canceled {
@@ -338,6 +410,22 @@ func debugLogTail(cmd *cobra.Command, args []string) error {
 	return err
 }
 
+// debugLogTailAllSessions merges every active session into one view.
+func debugLogTailAllSessions(dir string, follow bool) error {
+	if follow {
+		fmt.Fprintf(os.Stderr, "Watching all sessions in %s\n\n", dir)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	err := debuglog.TailAll(ctx, dir, os.Stdout, debuglog.TailAllOptions{Follow: follow})
+	if err == context.Canceled {
+		return nil
+	}
+	return err
+}
+
 // debugLogSearch searches across sessions
 func debugLogSearch(cmd *cobra.Command, args []string) error {
 	dir := getDebugLogDir()
@@ -352,12 +440,13 @@ func debugLogSearch(cmd *cobra.Command, args []string) error {
 		ToolName:   debugLogToolName,
 		Provider:   debugLogProvider,
 		ErrorsOnly: debugLogErrors,
+		TurnID:     strings.TrimSpace(debugLogTurn),
 		Days:       debugLogDays,
 	}
 
 	// Require at least one search criterion
-	if opts.Query == "" && opts.ToolName == "" && !opts.ErrorsOnly && opts.Provider == "" {
-		return fmt.Errorf("specify a search query, --tool, --provider, or --errors")
+	if opts.Query == "" && opts.ToolName == "" && !opts.ErrorsOnly && opts.Provider == "" && opts.TurnID == "" {
+		return fmt.Errorf("specify a search query, --tool, --provider, --errors, or --turn")
 	}
 
 	results, err := debuglog.Search(dir, opts)
@@ -387,33 +476,27 @@ func debugLogSearch(cmd *cobra.Command, args []string) error {
 func debugLogClean(cmd *cobra.Command, args []string) error {
 	dir := getDebugLogDir()
 
-	entries, err := os.ReadDir(dir)
+	sessions, err := debuglog.ListSessionFiles(dir)
 	if err != nil {
-		if os.IsNotExist(err) {
-			fmt.Println("No debug logs found.")
-			return nil
-		}
 		return err
 	}
+	if len(sessions) == 0 {
+		fmt.Println("No debug logs found.")
+		return nil
+	}
 
+	// Sessions are removed whole, judged by their most recently written part.
 	var toDelete []string
 	var totalSize int64
 	cutoff := time.Now().AddDate(0, 0, -debugLogDays)
 
-	for _, entry := range entries {
-		if entry.IsDir() || filepath.Ext(entry.Name()) != ".jsonl" {
-			continue
-		}
-
-		info, err := entry.Info()
-		if err != nil {
-			continue
-		}
-
-		shouldDelete := debugLogAll || info.ModTime().Before(cutoff)
+	for _, session := range sessions {
+		shouldDelete := debugLogAll || session.ModTime.Before(cutoff)
 		if shouldDelete {
-			toDelete = append(toDelete, entry.Name())
-			totalSize += info.Size()
+			for _, path := range session.Paths {
+				toDelete = append(toDelete, filepath.Base(path))
+			}
+			totalSize += session.Size
 		}
 	}
 
@@ -446,19 +529,31 @@ func debugLogClean(cmd *cobra.Command, args []string) error {
 func debugLogExport(cmd *cobra.Command, args []string) error {
 	dir := getDebugLogDir()
 
-	identifier := ""
+	var summary *debuglog.SessionSummary
+	var err error
 	if len(args) > 0 {
-		identifier = args[0]
+		summary, err = debuglog.ResolveSession(dir, args[0])
 	} else {
 		// Default to most recent
-		summary, err := debuglog.GetMostRecentSession(dir)
-		if err != nil {
-			return err
-		}
-		if summary == nil {
-			return fmt.Errorf("no debug sessions found")
+		summary, err = debuglog.GetMostRecentSession(dir)
+	}
+	if err != nil {
+		return err
+	}
+	if summary == nil {
+		if len(args) > 0 {
+			return fmt.Errorf("session not found: %s", args[0])
 		}
-		identifier = summary.ID
+		return fmt.Errorf("no debug sessions found")
+	}
+
+	session, err := debuglog.ParseSession(summary.FilePath)
+	if err != nil {
+		return err
+	}
+	filter, err := debugLogFilter(session)
+	if err != nil {
+		return err
 	}
 
 	// Determine format
@@ -470,12 +565,60 @@ func debugLogExport(cmd *cobra.Command, args []string) error {
 		format = debuglog.FormatRaw
 	}
 
-	return debuglog.ExportSessionByID(dir, identifier, os.Stdout, debuglog.ExportOptions{
-		Format: format,
-		Redact: debugLogRedact,
+	var secrets []string
+	if debugLogRedact {
+		secrets = debugLogKnownSecrets()
+	}
+
+	return debuglog.Export(os.Stdout, session, debuglog.ExportOptions{
+		Format:  format,
+		Redact:  debugLogRedact,
+		Secrets: secrets,
+		Filter:  filter,
 	})
 }
 
+// debugLogKnownSecrets collects credential values available on this machine
+// (stored OAuth tokens, configured provider keys and credential-named
+// environment variables) so --redact also catches them in formats the
+// pattern-based redaction does not recognise.
+func debugLogKnownSecrets() []string {
+	var secrets []string
+	add := func(values ...string) {
+		for _, v := range values {
+			if v != "" && !strings.HasPrefix(v, "$") {
+				secrets = append(secrets, v)
+			}
+		}
+	}
+
+	if creds, err := credentials.GetChatGPTCredentials(); err == nil {
+		add(creds.AccessToken, creds.RefreshToken)
+	}
+	if creds, err := credentials.GetCopilotCredentials(); err == nil {
+		add(creds.AccessToken)
+	}
+	if creds, err := credentials.GetGeminiOAuthCredentials(); err == nil {
+		add(creds.AccessToken, creds.RefreshToken)
+	}
+	if cfg, err := config.Load(); err == nil {
+		for _, p := range cfg.Providers {
+			add(p.APIKey, p.SecretKey, p.SessionToken)
+		}
+	}
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		name = strings.ToUpper(name)
+		for _, suffix := range []string{"_KEY", "_TOKEN", "_SECRET", "_PASSWORD"} {
+			if strings.HasSuffix(name, suffix) {
+				add(value)
+				break
+			}
+		}
+	}
+	return secrets
+}
+
 // debugLogPath prints the debug log directory
 func debugLogPath(cmd *cobra.Command, args []string) error {
 	fmt.Println(getDebugLogDir())
@@ -520,7 +663,14 @@ func debugLogStatus(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Debug logging: %s\n", enabledStr)
 	fmt.Printf("Log directory: %s\n", dir)
 	fmt.Printf("Sessions: %d\n", len(sessions))
-	fmt.Printf("Total size: %s\n", formatBytes(size))
+	if budget := int64(cfg.DebugLogs.MaxTotalMB) << 20; budget > 0 {
+		fmt.Printf("Total size: %s of %s budget (%d%%)\n", formatBytes(size), formatBytes(budget), size*100/budget)
+	} else {
+		fmt.Printf("Total size: %s (no budget)\n", formatBytes(size))
+	}
+	if cfg.DebugLogs.MaxFileMB > 0 {
+		fmt.Printf("Rotate files at: %s\n", formatBytes(int64(cfg.DebugLogs.MaxFileMB)<<20))
+	}
 
 	return nil
 }
@@ -540,58 +690,14 @@ func setDebugLogEnabled(enabled bool) error {
 	if err != nil {
 		return fmt.Errorf("failed to get config path: %w", err)
 	}
-
-	// Ensure config directory exists
-	configDir := filepath.Dir(configPath)
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
-	}
-
-	// Read existing file or create empty document
-	var root yaml.Node
-	data, err := os.ReadFile(configPath)
+	root, err := config.ReadConfigDocument(configPath)
 	if err != nil {
-		if os.IsNotExist(err) {
-			// Create new document with empty mapping
-			root = yaml.Node{
-				Kind: yaml.DocumentNode,
-				Content: []*yaml.Node{{
-					Kind: yaml.MappingNode,
-				}},
-			}
-		} else {
-			return fmt.Errorf("failed to read config: %w", err)
-		}
-	} else {
-		if err := yaml.Unmarshal(data, &root); err != nil {
-			return fmt.Errorf("failed to parse config: %w", err)
-		}
-	}
-
-	// Set debug_logs.enabled
-	value := "false"
-	if enabled {
-		value = "true"
+		return err
 	}
-
-	if err := setYAMLValue(&root, []string{"debug_logs", "enabled"}, value); err != nil {
+	if err := config.SetYAMLValue(root, []string{"debug_logs", "enabled"}, strconv.FormatBool(enabled)); err != nil {
 		return fmt.Errorf("failed to set value: %w", err)
 	}
-
-	// Write back
-	var buf bytes.Buffer
-	encoder := yaml.NewEncoder(&buf)
-	encoder.SetIndent(2)
-	if err := encoder.Encode(&root); err != nil {
-		return fmt.Errorf("failed to encode config: %w", err)
-	}
-	encoder.Close()
-
-	if err := os.WriteFile(configPath, buf.Bytes(), 0644); err != nil {
-		return fmt.Errorf("failed to write config: %w", err)
-	}
-
-	return nil
+	return config.WriteConfigDocument(configPath, root)
 }
 
 // formatBytes formats a byte count as a human-readable string
@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -18,12 +20,16 @@ import (
 	"github.com/samsaffron/term-llm/internal/prompt"
 	"github.com/samsaffron/term-llm/internal/signal"
 	"github.com/samsaffron/term-llm/internal/tools"
+	"github.com/samsaffron/term-llm/internal/udiff"
 	"github.com/samsaffron/term-llm/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
 	editDryRun     bool
+	editJSON       bool
+	editQuiet      bool
+	editApplyFrom  string
 	editDebug      bool
 	editProvider   string
 	editFiles      []string
@@ -67,16 +73,24 @@ Line range syntax:
 Context files:
   Use --context/-c to include read-only reference files that inform the edit
   but won't be modified themselves.`,
-	Args:         cobra.MinimumNArgs(1),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if editApplyFrom != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
 	SilenceUsage: true,
 	RunE:         runEdit,
 }
 
 func init() {
 	// Edit-specific flags
-	editCmd.Flags().StringArrayVarP(&editFiles, "file", "f", nil, "File(s) to edit (required, supports line ranges like file.go:10-20)")
+	editCmd.Flags().StringArrayVarP(&editFiles, "file", "f", nil, "File(s) to edit (required unless --apply-from, supports line ranges like file.go:10-20)")
 	editCmd.Flags().StringArrayVarP(&editContext, "context", "c", nil, "File(s) to include as read-only context (supports globs, 'clipboard')")
 	editCmd.Flags().BoolVar(&editDryRun, "dry-run", false, "Show what would change without applying")
+	editCmd.Flags().BoolVar(&editJSON, "json", false, "With --dry-run, print a JSON manifest of the changes instead of diffs")
+	editCmd.Flags().BoolVar(&editQuiet, "quiet", false, "Do not print the run summary when the edit finishes")
+	editCmd.Flags().StringVar(&editApplyFrom, "apply-from", "", "Apply a manifest saved from --dry-run --json without calling the LLM")
 	editCmd.Flags().StringVar(&editDiffFormat, "diff-format", "", "Force diff format: 'udiff' or 'replace' (default: auto)")
 
 	AddCommonFlags(editCmd,
@@ -96,10 +110,6 @@ func init() {
 			Skills:        &editSkills,
 		})
 
-	if err := editCmd.MarkFlagRequired("file"); err != nil {
-		panic(fmt.Sprintf("failed to mark file flag required: %v", err))
-	}
-
 	rootCmd.AddCommand(editCmd)
 }
 
@@ -126,6 +136,16 @@ func absPath(path string) string {
 }
 
 func runEdit(cmd *cobra.Command, args []string) error {
+	if editApplyFrom != "" {
+		return runEditApplyManifest(cmd, editApplyFrom)
+	}
+	if len(editFiles) == 0 {
+		return fmt.Errorf(`required flag(s) "file" not set`)
+	}
+	if editJSON && !editDryRun {
+		return fmt.Errorf("--json requires --dry-run")
+	}
+
 	request := strings.Join(args, " ")
 	ctx, stop := signal.NotifyContext()
 	defer stop()
@@ -197,7 +217,9 @@ func runEdit(cmd *cobra.Command, args []string) error {
 			}
 			return tools.RunFileApprovalUI(path, isWrite)
 		}
-		toolMgr.SetupEngine(engine)
+		if err := toolMgr.SetupEngine(engine); err != nil {
+			return err
+		}
 
 		// Wire spawn_agent runner if enabled
 		if err := WireSpawnAgentRunner(cfg, toolMgr, resolvedYolo); err != nil {
@@ -315,12 +337,19 @@ func runStreamEdit(ctx context.Context, cfg *config.Config, provider llm.Provide
 	// Get model from config (needed for diagnostics callback)
 	model := getActiveModel(cfg)
 
+	matchTier, err := udiff.ParseMatchTier(cfg.Edit.FuzzyMatch)
+	if err != nil {
+		return fmt.Errorf("edit.fuzzy_match: %w", err)
+	}
+
 	// Create executor config
 	execConfig := edit.ExecutorConfig{
 		FileContents: fileContents,
 		Guards:       guards,
+		DiffMatching: udiff.Options{MaxTier: matchTier, Similarity: cfg.Edit.FuzzyThreshold},
 		Debug:        editDebug,
 		DebugRaw:     debugRaw,
+		DryRun:       editDryRun,
 		OnFileStart: func(path string) {
 			if editDebug {
 				fmt.Printf("Editing: %s\n", path)
@@ -427,6 +456,24 @@ func runStreamEdit(ctx context.Context, cfg *config.Config, provider llm.Provide
 		}
 	}
 
+	// The run summary follows each provider request, read_context call and
+	// applied file; it is printed once the edit finishes.
+	runSummary := ui.NewRunSummary(model, 0)
+	runSummary.SetProvider(cfg.DefaultProvider)
+	execConfig.OnTurnCompleted = runSummary.ObserveTurn
+	execConfig.OnToolExecuted = func(toolName string, success bool) {
+		runSummary.ObserveToolExec(llm.Event{Type: llm.EventToolExecEnd, ToolName: toolName, ToolSuccess: success})
+	}
+	// One debug log holds the edit's provider requests and its run summary.
+	debugLogger, err := createDebugLogger(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	if debugLogger != nil {
+		defer debugLogger.Close()
+	}
+	execConfig.DebugLogger = debugLogger
+
 	// Create executor
 	executor := edit.NewStreamEditExecutor(provider, model, execConfig)
 
@@ -456,6 +503,8 @@ func runStreamEdit(ctx context.Context, cfg *config.Config, provider llm.Provide
 		userPrompt = prompt.StreamEditUserPrompt(request, files, promptSpecs, contextFiles, stdinContent, useUnifiedDiff)
 	}
 
+	systemPrompt = appendProjectContext(systemPrompt, newProjectContext(cfg, SessionSettings{}, ""))
+
 	messages := []llm.Message{
 		llm.SystemText(systemPrompt),
 		llm.UserText(userPrompt),
@@ -486,11 +535,24 @@ func runStreamEdit(ctx context.Context, cfg *config.Config, provider llm.Provide
 	if !ok {
 		return fmt.Errorf("unexpected result type")
 	}
+	defer func() {
+		finishRunSummary(os.Stderr, debugLogger, runSummary, !editQuiet && !editJSON)
+	}()
 	results := execRes.results
 
+	noEdits := func(reason string) error {
+		if editJSON {
+			if err := printEditManifest(os.Stdout, edit.NewManifest(request, nil)); err != nil {
+				return err
+			}
+		} else {
+			fmt.Println("No edits proposed")
+		}
+		return exitcode.NoEdits(reason)
+	}
+
 	if len(results) == 0 {
-		fmt.Println("No edits proposed")
-		return exitcode.NoEdits("no edits proposed")
+		return noEdits("no edits proposed")
 	}
 
 	// Consolidate results by file - use final state for each file
@@ -505,14 +567,29 @@ func runStreamEdit(ctx context.Context, cfg *config.Config, provider llm.Provide
 			fileOrder = append(fileOrder, r.Path)
 			// First result for this file - get original content
 			fileResults[r.Path] = edit.EditResult{
-				Path:       r.Path,
-				OldContent: fileContents[r.Path], // Original from disk
-				NewContent: r.NewContent,
+				Path:        r.Path,
+				OldContent:  fileContents[r.Path], // Original from disk
+				NewContent:  r.NewContent,
+				Operation:   r.Operation,
+				DiffWarning: r.DiffWarning,
 			}
 		} else {
 			// Update with latest content
 			existing := fileResults[r.Path]
 			existing.NewContent = r.NewContent
+			if r.DiffWarning != "" {
+				existing.DiffWarning = strings.TrimPrefix(existing.DiffWarning+"; "+r.DiffWarning, "; ")
+			}
+			switch {
+			case existing.Operation == llm.DiffOperationCreate && r.Operation == llm.DiffOperationDelete:
+				// Created and deleted again: nothing to do on disk.
+				existing.Operation = ""
+			case existing.Operation == llm.DiffOperationDelete && r.Operation == llm.DiffOperationCreate:
+				// Deleted and recreated: an ordinary rewrite.
+				existing.Operation = ""
+			case r.Operation != "":
+				existing.Operation = r.Operation
+			}
 			fileResults[r.Path] = existing
 		}
 	}
@@ -521,27 +598,24 @@ func runStreamEdit(ctx context.Context, cfg *config.Config, provider llm.Provide
 	var changedResults []edit.EditResult
 	for _, path := range fileOrder {
 		r := fileResults[path]
-		if r.OldContent != r.NewContent {
+		if r.OldContent != r.NewContent || r.Operation != "" {
 			changedResults = append(changedResults, r)
 		}
 	}
 
 	if len(changedResults) == 0 {
-		fmt.Println("No edits proposed")
-		return exitcode.NoEdits("no changes")
+		return noEdits("no changes")
 	}
 
-	// Show all diffs first
-	for i, r := range changedResults {
-		if i > 0 {
-			fmt.Println()
-		}
-		ui.PrintUnifiedDiff(r.Path, r.OldContent, r.NewContent)
+	// Nothing is written in a dry run, so there is nothing to approve.
+	if executor.DryRun() && editJSON {
+		return printEditManifest(os.Stdout, edit.NewManifest(request, changedResults))
 	}
 
-	// Dry run - no approval needed
-	if editDryRun {
-		fmt.Println()
+	printEditDiffs(changedResults)
+
+	if executor.DryRun() {
+		printDryRunSummary(os.Stdout, edit.NewManifest(request, changedResults))
 		return nil
 	}
 
@@ -560,16 +634,12 @@ func runStreamEdit(ctx context.Context, cfg *config.Config, provider llm.Provide
 			return exitcode.Declined("user declined edits")
 		case ui.EditApprovalYes:
 			// Apply all changes
-			var applied int
-			for _, r := range changedResults {
-				if err := os.WriteFile(r.Path, []byte(r.NewContent), 0644); err != nil {
-					fmt.Printf("  error writing %s: %s\n", r.Path, err.Error())
-					continue
-				}
-				applied++
+			written := writeEditResults(cfg, request, changedResults, os.Stdout, os.Stderr, executor.WriteResults)
+			for _, r := range written {
+				runSummary.ObserveFileChange(r.Path, r.Operation, r.OldContent, r.NewContent)
 			}
 			if len(changedResults) > 1 {
-				fmt.Printf("\r%d files updated\n", applied)
+				fmt.Printf("\r%d files updated\n", len(written))
 			}
 			fmt.Println()
 			return nil
@@ -577,6 +647,97 @@ func runStreamEdit(ctx context.Context, cfg *config.Config, provider llm.Provide
 	}
 }
 
+// printEditDiffs shows the diff for each changed file.
+func printEditDiffs(results []edit.EditResult) {
+	for i, r := range results {
+		if i > 0 {
+			fmt.Println()
+		}
+		ui.PrintUnifiedDiffWithOperation(r.Path, r.OldContent, r.NewContent, r.Operation)
+		if r.DiffWarning != "" {
+			fmt.Fprintf(os.Stderr, "warning: %s: %s; check the change before applying\n", r.Path, r.DiffWarning)
+		}
+	}
+}
+
+func printEditManifest(w io.Writer, m *edit.Manifest) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
+
+// printDryRunSummary lists the files a dry run would change.
+func printDryRunSummary(w io.Writer, m *edit.Manifest) {
+	noun := "files"
+	if len(m.Files) == 1 {
+		noun = "file"
+	}
+	fmt.Fprintf(w, "\nDry run: %d %s would change\n", len(m.Files), noun)
+	for _, f := range m.Files {
+		fmt.Fprintf(w, "  %-6s %s (+%d -%d)\n", f.Operation, f.Path, f.Added, f.Removed)
+	}
+}
+
+// runEditApplyManifest applies a manifest from a dry run. The changes were
+// reviewed when the manifest was made, so they are applied without a prompt,
+// but only if none of the files changed since.
+func runEditApplyManifest(cmd *cobra.Command, path string) error {
+	if len(editFiles) > 0 || editDryRun || editJSON {
+		return fmt.Errorf("--apply-from cannot be combined with --file, --dry-run or --json")
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	m, err := edit.ReadManifest(path)
+	if err != nil {
+		return err
+	}
+	results, err := m.Results()
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No edits in manifest")
+		return exitcode.NoEdits("no changes")
+	}
+
+	applied := len(writeEditResults(cfg, m.Request, results, cmd.OutOrStdout(), cmd.ErrOrStderr(), edit.WriteResults))
+	if applied < len(results) {
+		return fmt.Errorf("%d of %d files could not be written", len(results)-applied, len(results))
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Applied %d file(s) from %s\n", applied, path)
+	return nil
+}
+
+// writeEditResults writes approved edits through writeAll, backing up the
+// previous contents for "edit undo" unless backups are disabled. It returns
+// the results that were written.
+func writeEditResults(cfg *config.Config, request string, results []edit.EditResult, out, errOut io.Writer, writeAll func([]edit.EditResult, func(edit.EditResult) error) ([]edit.EditResult, error)) []edit.EditResult {
+	write := edit.WriteResult
+	if retention := cfg.Edit.BackupRetention; retention > 0 {
+		run, err := edit.NewBackupRun(request)
+		if err != nil {
+			fmt.Fprintf(errOut, "warning: edits will not be backed up: %v\n", err)
+		} else {
+			write = run.WriteResult
+			defer func() {
+				if err := edit.PruneBackupRuns(retention); err != nil {
+					fmt.Fprintf(errOut, "warning: failed to prune edit backups: %v\n", err)
+				}
+			}()
+		}
+	}
+
+	written, err := writeAll(results, write)
+	if err != nil {
+		for _, line := range strings.Split(err.Error(), "\n") {
+			fmt.Fprintf(out, "  error writing %s\n", line)
+		}
+	}
+	return written
+}
+
 func truncateStr(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
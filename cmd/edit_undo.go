@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/edit"
+	"github.com/spf13/cobra"
+)
+
+var (
+	editUndoRun   string
+	editUndoForce bool
+	editUndoYes   bool
+	editUndoList  bool
+)
+
+var editUndoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Revert the files changed by an edit",
+	Long: `Revert the files changed by the most recent edit, or by the run given
+with --run, using the backups saved when the edit was applied.
+
+Files changed again after the edit are skipped unless --force is given.
+
+Examples:
+  term-llm edit undo
+  term-llm edit undo --list
+  term-llm edit undo --run 20260101-120000`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runEditUndo,
+}
+
+func init() {
+	editUndoCmd.Flags().StringVar(&editUndoRun, "run", "", "Backup run to revert (default: most recent)")
+	editUndoCmd.Flags().BoolVar(&editUndoForce, "force", false, "Revert files even if they changed after the edit")
+	editUndoCmd.Flags().BoolVarP(&editUndoYes, "yes", "y", false, "Revert without asking for confirmation")
+	editUndoCmd.Flags().BoolVar(&editUndoList, "list", false, "List the backup runs that can be reverted")
+	editCmd.AddCommand(editUndoCmd)
+}
+
+func runEditUndo(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+	if editUndoList {
+		return listEditBackups(out)
+	}
+
+	run, err := edit.LoadBackupRun(editUndoRun)
+	if err != nil {
+		return err
+	}
+
+	steps := run.PlanUndo()
+	fmt.Fprintf(out, "Edit %s (%s)", run.ID, run.CreatedAt.Local().Format(time.DateTime))
+	if run.Request != "" {
+		fmt.Fprintf(out, ": %s", run.Request)
+	}
+	fmt.Fprintln(out)
+	revertable := 0
+	for _, step := range steps {
+		action := "restore"
+		if !step.Restores() {
+			action = "remove"
+		}
+		if step.Conflict != "" && !editUndoForce {
+			fmt.Fprintf(out, "  skip    %s (%s; use --force to revert)\n", step.File.Path, step.Conflict)
+			continue
+		}
+		revertable++
+		if step.Conflict != "" {
+			fmt.Fprintf(out, "  %-7s %s (%s)\n", action, step.File.Path, step.Conflict)
+		} else {
+			fmt.Fprintf(out, "  %-7s %s\n", action, step.File.Path)
+		}
+	}
+	if revertable == 0 {
+		return fmt.Errorf("nothing to revert: every file changed after the edit")
+	}
+
+	if !editUndoYes {
+		fmt.Fprintf(out, "Revert %d file(s)? [y/N]: ", revertable)
+		if !promptConfirm() {
+			fmt.Fprintln(out, "Cancelled")
+			return nil
+		}
+	}
+
+	reverted, skipped, err := run.Undo(editUndoForce)
+	for _, path := range reverted {
+		fmt.Fprintf(out, "Reverted %s\n", path)
+	}
+	if err != nil {
+		return err
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(out, "Skipped %d file(s); run again with --run %s --force to revert them\n", len(skipped), run.ID)
+	}
+	return nil
+}
+
+func listEditBackups(out io.Writer) error {
+	runs, err := edit.ListBackupRuns()
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		fmt.Fprintln(out, "No edit backups")
+		return nil
+	}
+	for _, run := range runs {
+		fmt.Fprintf(out, "%s  %s  %d file(s)  %s\n", run.ID, run.CreatedAt.Local().Format(time.DateTime), len(run.Files), truncateStr(run.Request, 60))
+	}
+	return nil
+}
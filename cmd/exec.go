@@ -164,7 +164,7 @@ func runExec(cmd *cobra.Command, args []string) error {
 	defer func() {
 		if showStats {
 			stats.Finalize()
-			setEstimatedStatsCost(stats, statsModel)
+			setEstimatedStatsCost(stats, cfg.DefaultProvider, statsModel)
 			fmt.Fprintln(cmd.ErrOrStderr(), stats.Render())
 		}
 	}()
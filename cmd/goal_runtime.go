@@ -171,7 +171,9 @@ func (rt *serveRuntime) runActiveGoalLoop(ctx context.Context, stateful bool, re
 	createTool := toolpkg.NewCreateGoalTool()
 	goalTools := []llm.Tool{updateTool, getTool, createTool}
 	for _, tool := range goalTools {
-		rt.engine.RegisterTool(tool)
+		if err := rt.engine.RegisterTool(tool); err != nil {
+			return serveRunResult{}, err
+		}
 		defer rt.engine.UnregisterTool(tool.Spec().Name)
 	}
 	goalSpecs := make([]llm.ToolSpec, 0, len(goalTools))
@@ -4,14 +4,22 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/samsaffron/term-llm/internal/input"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
@@ -20,9 +28,12 @@ var (
 	jobsServerURL string
 	jobsToken     string
 	jobsTimeout   time.Duration
+	jobsRetries   int
 	jobsJSON      bool
 	jobsListAll   bool
 
+	jobsNoValidate bool
+
 	jobsCreateFile string
 	jobsCreateData string
 
@@ -30,10 +41,21 @@ var (
 	jobsUpdateData string
 
 	jobsDeleteCancelActive bool
+	jobsTriggerFollow      bool
+	jobsWaitTimeout        time.Duration
+	jobsCancelOnTimeout    bool
 	jobsRunsLimit          int
 	jobsRunsOffset         int
+	jobsRunsStatus         string
+	jobsRunsFailed         bool
+	jobsRunsSince          string
+	jobsRunsUntil          string
 	jobsEventsLimit        int
 	jobsEventsOffset       int
+	jobsLogsStderr         bool
+	jobsLogsFollow         bool
+	jobsPreviewTimezone    string
+	jobsPreviewCount       int
 )
 
 var jobsCmd = &cobra.Command{
@@ -42,9 +64,21 @@ var jobsCmd = &cobra.Command{
 	Long: `Manage the jobs runner over the serve API.
 
 By default this talks to http://127.0.0.1:8080.
-You can override with --server / --token or env vars:
+You can override with --server / --token / --retries or env vars:
   TERM_LLM_JOBS_SERVER
-  TERM_LLM_JOBS_TOKEN`,
+  TERM_LLM_JOBS_TOKEN
+  TERM_LLM_JOBS_RETRIES
+
+Job names are cached in ~/.cache/term-llm/jobs.json for ten minutes for
+shell completion and name lookups. Commands that change a job check a cached
+name against the server first.
+
+To reach a server listening on a unix socket, pass its path as
+--server unix:///path/to/term-llm.sock.
+
+Reads, and trigger/pause/resume, are retried with exponential backoff when
+the server refuses the connection or answers 502/503 (for example while it
+restarts). Retry notices go to stderr.`,
 	Args: cobra.NoArgs,
 	RunE: runJobsList,
 }
@@ -57,8 +91,12 @@ var jobsListCmd = &cobra.Command{
 }
 
 var jobsGetCmd = &cobra.Command{
-	Use:               "get <job-id-or-name>",
-	Short:             "Get job definition",
+	Use:   "get <job-id-or-name>",
+	Short: "Get job definition",
+	Long: `Print a job definition followed by a summary of its trigger: the next
+five run times of a cron job (in its timezone and in local time), the
+countdown to a once job, or a note that a manual job only runs when
+triggered. Pass --json for the definition alone.`,
 	Args:              cobra.ExactArgs(1),
 	RunE:              runJobsGet,
 	ValidArgsFunction: jobsArgCompletion,
@@ -69,6 +107,11 @@ var jobsCreateCmd = &cobra.Command{
 	Short: "Create a job definition",
 	Long: `Create from JSON/YAML via --file or --data.
 
+The definition is checked before it is sent: unknown fields, missing
+required fields for the trigger_type and runner_type, and invalid cron
+expressions or run_at timestamps are all reported at once with their line
+numbers. Pass --no-validate to skip the check and let the server decide.
+
 Examples:
   term-llm jobs create --file job.yaml
   term-llm jobs create --data '{"name":"nightly",...}'`,
@@ -77,8 +120,25 @@ Examples:
 }
 
 var jobsUpdateCmd = &cobra.Command{
-	Use:               "update <job-id-or-name>",
-	Short:             "Update a job definition",
+	Use:   "update <job-id-or-name>",
+	Short: "Update a job definition",
+	Long: `Update a job definition with a JSON merge patch (RFC 7386).
+
+Fields left out of the patch keep their value, nested objects such as
+trigger_config are merged key by key, and null resets a field to its
+default. The patch comes from --file or --data, or is built from the
+field flags; the two cannot be mixed. The changed fields are printed
+as before → after, or the whole job with --json.
+
+--run-timeout sets the job's run timeout; --timeout is the request
+timeout, as for every jobs command.
+
+Examples:
+  term-llm jobs update nightly --cron '0 3 * * *'
+  term-llm jobs update nightly --enabled=false
+  term-llm jobs update nightly --name nightly-build --run-timeout 30m
+  term-llm jobs update nightly --data '{"runner_config":{"instructions":"..."}}'
+  term-llm jobs update nightly --data '{"labels":null}'`,
 	Args:              cobra.ExactArgs(1),
 	RunE:              runJobsUpdate,
 	ValidArgsFunction: jobsArgCompletion,
@@ -93,8 +153,18 @@ var jobsDeleteCmd = &cobra.Command{
 }
 
 var jobsTriggerCmd = &cobra.Command{
-	Use:               "trigger <job-id-or-name>",
-	Short:             "Trigger a manual run",
+	Use:   "trigger <job-id-or-name>",
+	Short: "Trigger a manual run",
+	Long: `Trigger a manual run.
+
+With --follow the command waits for the run, printing its events as they
+arrive, then prints the final status. It exits non-zero unless the run
+succeeded, so it can gate CI steps. --wait-timeout stops waiting without
+cancelling the run on the server unless --cancel-on-timeout is also set.
+
+Examples:
+  term-llm jobs trigger nightly
+  term-llm jobs trigger nightly --follow --wait-timeout 30m`,
 	Args:              cobra.ExactArgs(1),
 	RunE:              runJobsTrigger,
 	ValidArgsFunction: jobsArgCompletion,
@@ -152,6 +222,14 @@ var jobsRunCancelCmd = &cobra.Command{
 	ValidArgsFunction: runsArgCompletion,
 }
 
+var jobsRunTailCmd = &cobra.Command{
+	Use:               "tail <job-id-or-name>",
+	Short:             "Trigger a run and follow it until it finishes (same as trigger --follow)",
+	Args:              cobra.ExactArgs(1),
+	RunE:              runJobsRunTail,
+	ValidArgsFunction: jobsArgCompletion,
+}
+
 var jobsRunEventsCmd = &cobra.Command{
 	Use:               "events <run-id>",
 	Short:             "List run events",
@@ -160,26 +238,51 @@ var jobsRunEventsCmd = &cobra.Command{
 	ValidArgsFunction: runsArgCompletion,
 }
 
+var jobsRunLogsCmd = &cobra.Command{
+	Use:               "logs <run-id>",
+	Short:             "Print the captured stdout (or stderr) of a program run",
+	Args:              cobra.ExactArgs(1),
+	RunE:              runJobsRunLogs,
+	ValidArgsFunction: runsArgCompletion,
+}
+
 func init() {
-	jobsCmd.PersistentFlags().StringVar(&jobsServerURL, "server", envOr("TERM_LLM_JOBS_SERVER", "http://127.0.0.1:8080"), "Jobs API server base URL")
+	jobsCmd.PersistentFlags().StringVar(&jobsServerURL, "server", envOr("TERM_LLM_JOBS_SERVER", "http://127.0.0.1:8080"), "Jobs API server base URL, or unix:///path/to/socket")
 	jobsCmd.PersistentFlags().StringVar(&jobsToken, "token", envOr("TERM_LLM_JOBS_TOKEN", ""), "Bearer token for jobs API")
 	jobsCmd.PersistentFlags().DurationVar(&jobsTimeout, "timeout", 15*time.Second, "HTTP timeout")
+	jobsCmd.PersistentFlags().IntVar(&jobsRetries, "retries", jobsDefaultRetries(), "Retries for idempotent requests when the server is unavailable")
 	jobsCmd.PersistentFlags().BoolVar(&jobsJSON, "json", false, "Print JSON output")
 
-	jobsCreateCmd.Flags().StringVar(&jobsCreateFile, "file", "", "Path to JSON/YAML definition file")
+	jobsCreateCmd.Flags().StringVar(&jobsCreateFile, "file", "", "Path to JSON/YAML definition file (- for stdin)")
 	jobsCreateCmd.Flags().StringVar(&jobsCreateData, "data", "", "Inline JSON/YAML definition payload")
+	jobsCreateCmd.Flags().BoolVar(&jobsNoValidate, "no-validate", false, "Send the definition without client-side validation")
 
-	jobsUpdateCmd.Flags().StringVar(&jobsUpdateFile, "file", "", "Path to JSON/YAML update payload file")
-	jobsUpdateCmd.Flags().StringVar(&jobsUpdateData, "data", "", "Inline JSON/YAML update payload")
+	registerJobsUpdateFlags(jobsUpdateCmd)
 
 	jobsDeleteCmd.Flags().BoolVar(&jobsDeleteCancelActive, "cancel-active", false, "Cancel active runs before delete")
 
+	jobsTriggerCmd.Flags().BoolVar(&jobsTriggerFollow, "follow", false, "Wait for the run, streaming its events, and exit non-zero unless it succeeds")
+	for _, c := range []*cobra.Command{jobsTriggerCmd, jobsRunTailCmd} {
+		c.Flags().DurationVar(&jobsWaitTimeout, "wait-timeout", 0, "Stop following after this long (0 = wait until the run finishes)")
+		c.Flags().BoolVar(&jobsCancelOnTimeout, "cancel-on-timeout", false, "Cancel the run on the server when --wait-timeout expires")
+	}
+
 	jobsRunsCmd.Flags().IntVar(&jobsRunsLimit, "limit", 50, "Max runs to return")
 	jobsRunsCmd.Flags().IntVar(&jobsRunsOffset, "offset", 0, "Pagination offset")
+	jobsRunsCmd.Flags().StringVar(&jobsRunsStatus, "status", "", "Only show runs with these statuses (comma-separated, e.g. failed,timeout)")
+	jobsRunsCmd.Flags().BoolVar(&jobsRunsFailed, "failed", false, "Only show failed and timed-out runs (same as --status failed,timed_out)")
+	jobsRunsCmd.Flags().StringVar(&jobsRunsSince, "since", "", "Only show runs created within this duration (24h, 7d) or after this time (RFC 3339 or YYYY-MM-DD)")
+	jobsRunsCmd.Flags().StringVar(&jobsRunsUntil, "until", "", "Only show runs created before this duration ago or this time")
 
 	jobsRunEventsCmd.Flags().IntVar(&jobsEventsLimit, "limit", 200, "Max events to return")
 	jobsRunEventsCmd.Flags().IntVar(&jobsEventsOffset, "offset", 0, "Pagination offset")
 
+	jobsRunLogsCmd.Flags().BoolVar(&jobsLogsStderr, "stderr", false, "Print stderr instead of stdout")
+	jobsRunLogsCmd.Flags().BoolVarP(&jobsLogsFollow, "follow", "f", false, "Keep printing output until the run finishes")
+
+	jobsSchedulePreviewCmd.Flags().StringVar(&jobsPreviewTimezone, "timezone", "", "IANA timezone the expression is evaluated in (default: local)")
+	jobsSchedulePreviewCmd.Flags().IntVar(&jobsPreviewCount, "count", 5, "Number of upcoming run times to show")
+
 	jobsCmd.Flags().BoolVar(&jobsListAll, "all", false, "Show all jobs, including completed once-off and finished agent jobs")
 	jobsListCmd.Flags().BoolVar(&jobsListAll, "all", false, "Show all jobs, including completed once-off and finished agent jobs")
 
@@ -194,18 +297,37 @@ func init() {
 	jobsCmd.AddCommand(jobsRunsCmd)
 	jobsCmd.AddCommand(jobsActiveCmd)
 	jobsCmd.AddCommand(jobsRunCmd)
+	jobsCmd.AddCommand(jobsSchedulePreviewCmd)
 
 	jobsRunCmd.AddCommand(jobsRunGetCmd)
 	jobsRunCmd.AddCommand(jobsRunCancelCmd)
 	jobsRunCmd.AddCommand(jobsRunEventsCmd)
+	jobsRunCmd.AddCommand(jobsRunLogsCmd)
+	jobsRunCmd.AddCommand(jobsRunTailCmd)
 
 	rootCmd.AddCommand(jobsCmd)
 }
 
 type jobsClient struct {
-	baseURL string
-	token   string
-	http    *http.Client
+	baseURL    string
+	token      string
+	http       *http.Client
+	retries    int
+	retryDelay time.Duration // first backoff; doubles on each retry
+	stderr     io.Writer
+	cache      *jobsCache // nil disables the job name cache
+}
+
+const defaultJobsRetries = 2
+
+// jobsHTTPError is a non-2xx response from the jobs API.
+type jobsHTTPError struct {
+	status  int
+	message string
+}
+
+func (e *jobsHTTPError) Error() string {
+	return e.message
 }
 
 type jobsListResponse struct {
@@ -244,21 +366,130 @@ func newJobsClient() (*jobsClient, error) {
 		base = "http://127.0.0.1:8080"
 	}
 	base = strings.TrimRight(base, "/")
-	if !strings.HasPrefix(base, "http://") && !strings.HasPrefix(base, "https://") {
-		return nil, fmt.Errorf("invalid --server %q: must start with http:// or https://", base)
-	}
 	timeout := jobsTimeout
 	if timeout <= 0 {
 		timeout = 15 * time.Second
 	}
+	httpClient := &http.Client{Timeout: timeout}
+	cache := newJobsCache(base)
+	if socketPath, ok := strings.CutPrefix(base, "unix://"); ok {
+		if socketPath == "" {
+			return nil, fmt.Errorf("invalid --server %q: missing socket path", base)
+		}
+		httpClient.Transport = jobsUnixSocketTransport(socketPath)
+		base = jobsUnixSocketBaseURL
+	} else if !strings.HasPrefix(base, "http://") && !strings.HasPrefix(base, "https://") {
+		return nil, fmt.Errorf("invalid --server %q: must start with http://, https:// or unix://", base)
+	}
 	return &jobsClient{
-		baseURL: base,
-		token:   strings.TrimSpace(jobsToken),
-		http:    &http.Client{Timeout: timeout},
+		baseURL:    base,
+		token:      strings.TrimSpace(jobsToken),
+		http:       httpClient,
+		retries:    max(0, jobsRetries),
+		retryDelay: time.Second,
+		stderr:     os.Stderr,
+		cache:      cache,
 	}, nil
 }
 
+// jobsUnixSocketBaseURL is the base URL used for a unix:// server. The host
+// only fills the URL; every connection goes to the socket.
+const jobsUnixSocketBaseURL = "http://term-llm.sock"
+
+// jobsUnixSocketTransport dials socketPath for every request.
+func jobsUnixSocketTransport(socketPath string) *http.Transport {
+	var dialer net.Dialer
+	return &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, "unix", socketPath)
+			if err == nil {
+				return conn, nil
+			}
+			switch {
+			case errors.Is(err, fs.ErrNotExist):
+				return nil, fmt.Errorf("socket %s not found — is term-llm serve running?: %w", socketPath, err)
+			case errors.Is(err, fs.ErrPermission):
+				return nil, fmt.Errorf("permission denied on socket %s — check its owner and mode: %w", socketPath, err)
+			case errors.Is(err, syscall.ECONNREFUSED):
+				return nil, fmt.Errorf("nothing is listening on socket %s — is term-llm serve running?: %w", socketPath, err)
+			}
+			return nil, err
+		},
+	}
+}
+
+func jobsDefaultRetries() int {
+	if v, err := strconv.Atoi(envOr("TERM_LLM_JOBS_RETRIES", "")); err == nil && v >= 0 {
+		return v
+	}
+	return defaultJobsRetries
+}
+
+// do sends one jobs API request. GET requests are retried when the server is
+// unavailable; other methods are not, since repeating them may not be safe.
 func (c *jobsClient) do(ctx context.Context, method, path string, body []byte, out any) error {
+	return c.send(ctx, method, path, "application/json", body, out, "")
+}
+
+// mergePatch sends body to path as an RFC 7386 JSON merge patch.
+func (c *jobsClient) mergePatch(ctx context.Context, path string, body []byte, out any) error {
+	return c.send(ctx, http.MethodPatch, path, mergePatchContentType, body, out, "")
+}
+
+// doIdempotent sends a POST such as a trigger, carrying an Idempotency-Key.
+// The server keeps those keys in memory only, so a restart forgets them:
+// the POST is retried only when the connection was refused and the request
+// never reached the server, never after a 502/503 that it may have
+// answered after starting a run.
+func (c *jobsClient) doIdempotent(ctx context.Context, method, path string, body []byte, out any) error {
+	return c.send(ctx, method, path, "application/json", body, out, "jobs_"+randomSuffix())
+}
+
+func (c *jobsClient) send(ctx context.Context, method, path, contentType string, body []byte, out any, idempotencyKey string) error {
+	var retryable func(error) bool
+	switch {
+	case method == http.MethodGet:
+		retryable = isRetryableJobsError
+	case idempotencyKey != "":
+		retryable = isUnsentJobsError
+	}
+	for attempt := 0; ; attempt++ {
+		err := c.attempt(ctx, method, path, contentType, body, out, idempotencyKey)
+		if err == nil || retryable == nil || attempt >= c.retries || !retryable(err) {
+			return err
+		}
+		delay := c.retryDelay << attempt
+		if c.stderr != nil {
+			fmt.Fprintf(c.stderr, "jobs server unavailable (%v); retrying in %s…\n", err, delay)
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// isRetryableJobsError reports whether err looks like a server that is
+// restarting: a refused connection or a 502/503 from a proxy in front of it.
+func isRetryableJobsError(err error) bool {
+	if isUnsentJobsError(err) {
+		return true
+	}
+	var httpErr *jobsHTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.status == http.StatusBadGateway || httpErr.status == http.StatusServiceUnavailable
+	}
+	return false
+}
+
+// isUnsentJobsError reports whether err means the request never reached the
+// server, so repeating it cannot run anything twice.
+func isUnsentJobsError(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+func (c *jobsClient) attempt(ctx context.Context, method, path, contentType string, body []byte, out any, idempotencyKey string) error {
 	url := c.baseURL + path
 	var reader io.Reader
 	if len(body) > 0 {
@@ -269,11 +500,14 @@ func (c *jobsClient) do(ctx context.Context, method, path string, body []byte, o
 		return err
 	}
 	if len(body) > 0 {
-		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Type", contentType)
 	}
 	if c.token != "" {
 		req.Header.Set("Authorization", "Bearer "+c.token)
 	}
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
 
 	resp, err := c.http.Do(req)
 	if err != nil {
@@ -286,11 +520,7 @@ func (c *jobsClient) do(ctx context.Context, method, path string, body []byte, o
 		return err
 	}
 	if resp.StatusCode >= 400 {
-		var apiErr openAIErrorResponse
-		if err := json.Unmarshal(respBody, &apiErr); err == nil && strings.TrimSpace(apiErr.Error.Message) != "" {
-			return fmt.Errorf("%s", apiErr.Error.Message)
-		}
-		return fmt.Errorf("request failed (%d): %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+		return newJobsHTTPError(resp.StatusCode, respBody)
 	}
 	if out == nil || len(respBody) == 0 {
 		return nil
@@ -301,11 +531,51 @@ func (c *jobsClient) do(ctx context.Context, method, path string, body []byte, o
 	return nil
 }
 
+func newJobsHTTPError(status int, body []byte) *jobsHTTPError {
+	var apiErr openAIErrorResponse
+	if err := json.Unmarshal(body, &apiErr); err == nil && strings.TrimSpace(apiErr.Error.Message) != "" {
+		return &jobsHTTPError{status: status, message: apiErr.Error.Message}
+	}
+	return &jobsHTTPError{status: status, message: fmt.Sprintf("request failed (%d): %s", status, strings.TrimSpace(string(body)))}
+}
+
+// copyRunLog writes a run's captured log to out. With follow the request
+// stays open until the run finishes, so the client timeout is not applied.
+func (c *jobsClient) copyRunLog(ctx context.Context, runID, stream string, follow bool, out io.Writer) error {
+	path := fmt.Sprintf("/v2/runs/%s/logs?stream=%s", url.PathEscape(runID), stream)
+	httpClient := c.http
+	if follow {
+		path += "&follow=1"
+		streaming := *c.http
+		streaming.Timeout = 0
+		httpClient = &streaming
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return newJobsHTTPError(resp.StatusCode, body)
+	}
+	_, err = io.Copy(out, resp.Body)
+	return err
+}
+
 func (c *jobsClient) listJobs(ctx context.Context) ([]jobsV2Job, error) {
 	var resp jobsListResponse
 	if err := c.do(ctx, http.MethodGet, "/v2/jobs?limit=500", nil, &resp); err != nil {
 		return nil, err
 	}
+	c.cache.store(resp.Data)
 	return resp.Data, nil
 }
 
@@ -318,6 +588,12 @@ func (c *jobsClient) listRunSummaries(ctx context.Context, jobID string, limit,
 }
 
 func (c *jobsClient) listRunsWithSummary(ctx context.Context, jobID string, limit, offset int, summary bool) ([]jobsV2Run, error) {
+	return c.listFilteredRuns(ctx, jobID, jobsRunsFilter{}, limit, offset, summary)
+}
+
+// listFilteredRuns passes filter to the server and applies it again to the
+// result, since servers that predate the filter parameters ignore them.
+func (c *jobsClient) listFilteredRuns(ctx context.Context, jobID string, filter jobsRunsFilter, limit, offset int, summary bool) ([]jobsV2Run, error) {
 	path := fmt.Sprintf("/v2/runs?limit=%d&offset=%d", limit, offset)
 	if summary {
 		path += "&summary=true"
@@ -325,11 +601,192 @@ func (c *jobsClient) listRunsWithSummary(ctx context.Context, jobID string, limi
 	if strings.TrimSpace(jobID) != "" {
 		path += "&job_id=" + jobID
 	}
+	path += filter.query()
 	var resp jobsRunsListResponse
 	if err := c.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
 		return nil, err
 	}
-	return resp.Data, nil
+	if filter.empty() {
+		return resp.Data, nil
+	}
+	runs := resp.Data[:0]
+	for _, run := range resp.Data {
+		if filter.matches(run) {
+			runs = append(runs, run)
+		}
+	}
+	return runs, nil
+}
+
+// jobsRunsFilter holds the `jobs runs` status and time-window flags.
+type jobsRunsFilter struct {
+	Statuses []jobsV2RunStatus
+	Since    time.Time
+	Until    time.Time
+}
+
+// jobsRunStatusAliases maps the spellings people type to run statuses.
+var jobsRunStatusAliases = map[string]jobsV2RunStatus{
+	"timeout":  jobsV2RunTimedOut,
+	"timedout": jobsV2RunTimedOut,
+	"canceled": jobsV2RunCancelled,
+	"success":  jobsV2RunSucceeded,
+}
+
+func parseJobsRunsFilter(statuses string, failed bool, since, until string, now time.Time) (jobsRunsFilter, error) {
+	var filter jobsRunsFilter
+	seen := make(map[jobsV2RunStatus]bool)
+	addStatus := func(status jobsV2RunStatus) {
+		if !seen[status] {
+			seen[status] = true
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+	if failed {
+		addStatus(jobsV2RunFailed)
+		addStatus(jobsV2RunTimedOut)
+	}
+	for _, raw := range strings.Split(statuses, ",") {
+		name := strings.ToLower(strings.TrimSpace(raw))
+		if name == "" {
+			continue
+		}
+		status := jobsV2RunStatus(name)
+		if alias, ok := jobsRunStatusAliases[name]; ok {
+			status = alias
+		}
+		if !jobsV2ValidRunStatus(status) {
+			return jobsRunsFilter{}, fmt.Errorf("unknown run status %q (valid: queued, claimed, running, succeeded, failed, cancelled, cancel_requested, timed_out, skipped)", raw)
+		}
+		addStatus(status)
+	}
+	var err error
+	if filter.Since, err = parseJobsRunsTime("--since", since, now); err != nil {
+		return jobsRunsFilter{}, err
+	}
+	if filter.Until, err = parseJobsRunsTime("--until", until, now); err != nil {
+		return jobsRunsFilter{}, err
+	}
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && !filter.Until.After(filter.Since) {
+		return jobsRunsFilter{}, fmt.Errorf("--until must be later than --since")
+	}
+	return filter, nil
+}
+
+// parseJobsRunsTime accepts a duration before now (90m, 24h, 7d), an RFC 3339
+// timestamp, or a local YYYY-MM-DD date.
+func parseJobsRunsTime(flag, value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%s must be a duration (24h, 7d), an RFC 3339 time, or a YYYY-MM-DD date; got %q", flag, value)
+}
+
+func (f jobsRunsFilter) empty() bool {
+	return len(f.Statuses) == 0 && f.Since.IsZero() && f.Until.IsZero()
+}
+
+func (f jobsRunsFilter) query() string {
+	values := url.Values{}
+	if len(f.Statuses) > 0 {
+		names := make([]string, len(f.Statuses))
+		for i, status := range f.Statuses {
+			names[i] = string(status)
+		}
+		values.Set("status", strings.Join(names, ","))
+	}
+	if !f.Since.IsZero() {
+		values.Set("since", f.Since.UTC().Format(time.RFC3339))
+	}
+	if !f.Until.IsZero() {
+		values.Set("until", f.Until.UTC().Format(time.RFC3339))
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	return "&" + values.Encode()
+}
+
+func (f jobsRunsFilter) matches(run jobsV2Run) bool {
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, run.Status) {
+		return false
+	}
+	if !f.Since.IsZero() && run.CreatedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !run.CreatedAt.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// describe lists the active filters for messages, e.g.
+// "status failed,timed_out, since 2026-01-02T03:04:05Z".
+func (f jobsRunsFilter) describe() string {
+	var parts []string
+	if len(f.Statuses) > 0 {
+		names := make([]string, len(f.Statuses))
+		for i, status := range f.Statuses {
+			names[i] = string(status)
+		}
+		parts = append(parts, "status "+strings.Join(names, ","))
+	}
+	if !f.Since.IsZero() {
+		parts = append(parts, "since "+f.Since.Local().Format(time.RFC3339))
+	}
+	if !f.Until.IsZero() {
+		parts = append(parts, "until "+f.Until.Local().Format(time.RFC3339))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// jobsRunListItem is a run as printed by `jobs runs --json`.
+type jobsRunListItem struct {
+	jobsV2Run
+	DurationMS *int64 `json:"duration_ms,omitempty"`
+}
+
+// jobsRunDuration returns how long a finished run took.
+func jobsRunDuration(run jobsV2Run) (time.Duration, bool) {
+	if run.StartedAt == nil || run.FinishedAt == nil || run.FinishedAt.Before(*run.StartedAt) {
+		return 0, false
+	}
+	return run.FinishedAt.Sub(*run.StartedAt), true
+}
+
+// formatJobsRunDuration renders a run duration compactly: 850ms, 42s, 1m42s,
+// 2h5m.
+func formatJobsRunDuration(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	d = d.Round(time.Second)
+	hours := int(d / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	seconds := int(d % time.Minute / time.Second)
+	switch {
+	case hours > 0:
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%dm%ds", minutes, seconds)
+	default:
+		return fmt.Sprintf("%ds", seconds)
+	}
 }
 
 func (c *jobsClient) listActiveRuns(ctx context.Context) ([]jobsActiveRun, error) {
@@ -394,6 +851,18 @@ func isActiveRunStatus(status jobsV2RunStatus) bool {
 }
 
 func (c *jobsClient) resolveJobID(ctx context.Context, ref string) (string, error) {
+	return c.resolveJob(ctx, ref, false)
+}
+
+// resolveJobIDToChange is resolveJobID for commands that change a job. A job
+// found in the cache is fetched first to confirm ref still names it, so a
+// stale cache never points update or delete at another job; on a mismatch
+// the live listing decides and refreshes the cache.
+func (c *jobsClient) resolveJobIDToChange(ctx context.Context, ref string) (string, error) {
+	return c.resolveJob(ctx, ref, true)
+}
+
+func (c *jobsClient) resolveJob(ctx context.Context, ref string, verify bool) (string, error) {
 	ref = strings.TrimSpace(ref)
 	if ref == "" {
 		return "", fmt.Errorf("job id/name is required")
@@ -402,6 +871,15 @@ func (c *jobsClient) resolveJobID(ctx context.Context, ref string) (string, erro
 	if strings.HasPrefix(ref, "job_") {
 		return ref, nil
 	}
+	if id, ok := c.cache.resolve(ref); ok {
+		if !verify {
+			return id, nil
+		}
+		var job jobsV2Job
+		if err := c.do(ctx, http.MethodGet, "/v2/jobs/"+id, nil, &job); err == nil && job.ID == id && (job.Name == ref || strings.HasPrefix(job.ID, ref)) {
+			return id, nil
+		}
+	}
 	jobs, err := c.listJobs(ctx)
 	if err != nil {
 		return "", err
@@ -443,36 +921,54 @@ func envOr(key, fallback string) string {
 	return v
 }
 
-func readPayload(filePath, inline string) ([]byte, error) {
+// readPayloadRaw returns the payload as written, before YAML is converted to
+// JSON, so validation errors can point at source lines.
+func readPayloadRaw(filePath, inline string) ([]byte, error) {
 	filePath = strings.TrimSpace(filePath)
 	inline = strings.TrimSpace(inline)
 	if filePath != "" && inline != "" {
 		return nil, fmt.Errorf("use only one of --file or --data")
 	}
-	if filePath != "" {
+	if filePath != "" && filePath != input.StdinArg {
 		b, err := os.ReadFile(filePath)
 		if err != nil {
 			return nil, fmt.Errorf("read %s: %w", filePath, err)
 		}
-		return normalizeJSONPayload(b)
+		return b, nil
 	}
 	if inline != "" {
-		return normalizeJSONPayload([]byte(inline))
+		return []byte(inline), nil
 	}
-	stdinInfo, err := os.Stdin.Stat()
-	if err == nil && (stdinInfo.Mode()&os.ModeCharDevice) == 0 {
-		b, err := io.ReadAll(os.Stdin)
+	// Stdin is read when named with --file - or when piped; the shared
+	// reader means it is only ever consumed once per process.
+	if filePath == input.StdinArg || input.HasStdin() {
+		content, err := input.ReadStdin()
 		if err != nil {
-			return nil, fmt.Errorf("read stdin: %w", err)
+			return nil, err
 		}
-		if len(bytes.TrimSpace(b)) == 0 {
+		if strings.TrimSpace(content) == "" {
 			return nil, fmt.Errorf("empty payload from stdin")
 		}
-		return normalizeJSONPayload(b)
+		return []byte(content), nil
 	}
 	return nil, fmt.Errorf("missing payload: provide --file, --data, or stdin")
 }
 
+// readJobPayload reads a job definition for create (partial=false) or update
+// and, unless --no-validate is set, checks it before it reaches the server.
+func readJobPayload(filePath, inline string, partial bool) ([]byte, error) {
+	raw, err := readPayloadRaw(filePath, inline)
+	if err != nil {
+		return nil, err
+	}
+	if !jobsNoValidate {
+		if problems := validateJobPayload(raw, partial); len(problems) > 0 {
+			return nil, jobPayloadValidationError(problems)
+		}
+	}
+	return normalizeJSONPayload(raw)
+}
+
 func normalizeJSONPayload(data []byte) ([]byte, error) {
 	trimmed := bytes.TrimSpace(data)
 	if len(trimmed) == 0 {
@@ -695,7 +1191,12 @@ func runJobsGet(cmd *cobra.Command, args []string) error {
 	if err := client.do(cmd.Context(), http.MethodGet, "/v2/jobs/"+jobID, nil, &job); err != nil {
 		return err
 	}
-	return printJSON(job)
+	if err := printJSON(job); err != nil || jobsJSON {
+		return err
+	}
+	fmt.Println()
+	writeJobsTriggerSummary(os.Stdout, job, time.Now(), time.Local)
+	return nil
 }
 
 func runJobsCreate(cmd *cobra.Command, args []string) error {
@@ -703,7 +1204,7 @@ func runJobsCreate(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	payload, err := readPayload(jobsCreateFile, jobsCreateData)
+	payload, err := readJobPayload(jobsCreateFile, jobsCreateData, false)
 	if err != nil {
 		return err
 	}
@@ -711,6 +1212,7 @@ func runJobsCreate(cmd *cobra.Command, args []string) error {
 	if err := client.do(cmd.Context(), http.MethodPost, "/v2/jobs", payload, &job); err != nil {
 		return err
 	}
+	client.cache.update(job.ID, &job)
 	return printJSON(job)
 }
 
@@ -719,19 +1221,29 @@ func runJobsUpdate(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	jobID, err := client.resolveJobID(cmd.Context(), args[0])
+	jobID, err := client.resolveJobIDToChange(cmd.Context(), args[0])
 	if err != nil {
 		return err
 	}
-	payload, err := readPayload(jobsUpdateFile, jobsUpdateData)
+	payload, err := jobsUpdatePatch(cmd.Flags())
 	if err != nil {
 		return err
 	}
+	var before jobsV2Job
+	if !jobsJSON {
+		if err := client.do(cmd.Context(), http.MethodGet, "/v2/jobs/"+jobID, nil, &before); err != nil {
+			return err
+		}
+	}
 	var job jobsV2Job
-	if err := client.do(cmd.Context(), http.MethodPatch, "/v2/jobs/"+jobID, payload, &job); err != nil {
+	if err := client.mergePatch(cmd.Context(), "/v2/jobs/"+jobID, payload, &job); err != nil {
 		return err
 	}
-	return printJSON(job)
+	client.cache.update(jobID, &job)
+	if jobsJSON {
+		return printJSON(job)
+	}
+	return writeJobsUpdateDiff(os.Stdout, before, job)
 }
 
 func runJobsDelete(cmd *cobra.Command, args []string) error {
@@ -739,7 +1251,7 @@ func runJobsDelete(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	jobID, err := client.resolveJobID(cmd.Context(), args[0])
+	jobID, err := client.resolveJobIDToChange(cmd.Context(), args[0])
 	if err != nil {
 		return err
 	}
@@ -751,6 +1263,7 @@ func runJobsDelete(cmd *cobra.Command, args []string) error {
 	if err := client.do(cmd.Context(), http.MethodDelete, path, nil, &resp); err != nil {
 		return err
 	}
+	client.cache.update(jobID, nil)
 	return printJSON(resp)
 }
 
@@ -759,28 +1272,51 @@ func runJobsTrigger(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	jobID, err := client.resolveJobID(cmd.Context(), args[0])
+	jobID, err := client.resolveJobIDToChange(cmd.Context(), args[0])
 	if err != nil {
 		return err
 	}
+	if jobsTriggerFollow {
+		var progress io.Writer = os.Stdout
+		if jobsJSON {
+			progress = io.Discard
+		}
+		run, err := client.triggerAndFollowJob(cmd.Context(), jobID, jobsWaitTimeout, jobsCancelOnTimeout, progress)
+		if err != nil {
+			return err
+		}
+		if jobsJSON {
+			if err := printJSON(run); err != nil {
+				return err
+			}
+		} else {
+			printJobsRunSummary(os.Stdout, run)
+		}
+		return jobsRunOutcomeError(run)
+	}
 	var run jobsV2Run
-	if err := client.do(cmd.Context(), http.MethodPost, "/v2/jobs/"+jobID+"/trigger", nil, &run); err != nil {
+	if err := client.doIdempotent(cmd.Context(), http.MethodPost, "/v2/jobs/"+jobID+"/trigger", nil, &run); err != nil {
 		return err
 	}
 	return printJSON(run)
 }
 
+func runJobsRunTail(cmd *cobra.Command, args []string) error {
+	jobsTriggerFollow = true
+	return runJobsTrigger(cmd, args)
+}
+
 func runJobsPause(cmd *cobra.Command, args []string) error {
 	client, err := newJobsClient()
 	if err != nil {
 		return err
 	}
-	jobID, err := client.resolveJobID(cmd.Context(), args[0])
+	jobID, err := client.resolveJobIDToChange(cmd.Context(), args[0])
 	if err != nil {
 		return err
 	}
 	var job jobsV2Job
-	if err := client.do(cmd.Context(), http.MethodPost, "/v2/jobs/"+jobID+"/pause", nil, &job); err != nil {
+	if err := client.doIdempotent(cmd.Context(), http.MethodPost, "/v2/jobs/"+jobID+"/pause", nil, &job); err != nil {
 		return err
 	}
 	return printJSON(job)
@@ -791,12 +1327,12 @@ func runJobsResume(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	jobID, err := client.resolveJobID(cmd.Context(), args[0])
+	jobID, err := client.resolveJobIDToChange(cmd.Context(), args[0])
 	if err != nil {
 		return err
 	}
 	var job jobsV2Job
-	if err := client.do(cmd.Context(), http.MethodPost, "/v2/jobs/"+jobID+"/resume", nil, &job); err != nil {
+	if err := client.doIdempotent(cmd.Context(), http.MethodPost, "/v2/jobs/"+jobID+"/resume", nil, &job); err != nil {
 		return err
 	}
 	return printJSON(job)
@@ -846,6 +1382,10 @@ func runJobsActive(cmd *cobra.Command, args []string) error {
 }
 
 func runJobsRuns(cmd *cobra.Command, args []string) error {
+	filter, err := parseJobsRunsFilter(jobsRunsStatus, jobsRunsFailed, jobsRunsSince, jobsRunsUntil, time.Now())
+	if err != nil {
+		return err
+	}
 	client, err := newJobsClient()
 	if err != nil {
 		return err
@@ -857,25 +1397,36 @@ func runJobsRuns(cmd *cobra.Command, args []string) error {
 			return err
 		}
 	}
-	var items []jobsV2Run
-	if jobsJSON {
-		items, err = client.listRuns(cmd.Context(), jobID, jobsRunsLimit, jobsRunsOffset)
-	} else {
-		items, err = client.listRunSummaries(cmd.Context(), jobID, jobsRunsLimit, jobsRunsOffset)
-	}
+	items, err := client.listFilteredRuns(cmd.Context(), jobID, filter, jobsRunsLimit, jobsRunsOffset, !jobsJSON)
 	if err != nil {
 		return err
 	}
 	if jobsJSON {
-		return printJSON(items)
+		out := make([]jobsRunListItem, len(items))
+		for i, run := range items {
+			out[i].jobsV2Run = run
+			if d, ok := jobsRunDuration(run); ok {
+				ms := d.Milliseconds()
+				out[i].DurationMS = &ms
+			}
+		}
+		return printJSON(out)
 	}
 	if len(items) == 0 {
-		fmt.Println("No runs found.")
+		if filter.empty() {
+			fmt.Println("No runs found.")
+		} else {
+			fmt.Printf("No runs found matching %s.\n", filter.describe())
+		}
 		return nil
 	}
-	fmt.Printf("%-24s %-24s %-8s %-20s %-20s\n", "RUN_ID", "JOB_ID", "STATUS", "TRIGGER", "SCHEDULED_FOR")
+	fmt.Printf("%-24s %-24s %-9s %-20s %-20s %-8s\n", "RUN_ID", "JOB_ID", "STATUS", "TRIGGER", "SCHEDULED_FOR", "DURATION")
 	for _, run := range items {
-		fmt.Printf("%-24s %-24s %-8s %-20s %-20s\n", run.ID, run.JobID, run.Status, run.Trigger, run.ScheduledFor.Local().Format(time.RFC3339))
+		duration := "-"
+		if d, ok := jobsRunDuration(run); ok {
+			duration = formatJobsRunDuration(d)
+		}
+		fmt.Printf("%-24s %-24s %-9s %-20s %-20s %-8s\n", run.ID, run.JobID, run.Status, run.Trigger, run.ScheduledFor.Local().Format(time.RFC3339), duration)
 	}
 	return nil
 }
@@ -923,15 +1474,23 @@ func runJobsRunEvents(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 	for _, ev := range resp.Data {
-		msg := strings.TrimSpace(ev.Message)
-		if msg == "" {
-			msg = "-"
-		}
-		fmt.Printf("%s %-18s %s\n", ev.CreatedAt.Local().Format(time.RFC3339), ev.EventType, msg)
+		printJobsRunEvent(os.Stdout, ev)
 	}
 	return nil
 }
 
+func runJobsRunLogs(cmd *cobra.Command, args []string) error {
+	client, err := newJobsClient()
+	if err != nil {
+		return err
+	}
+	stream := "stdout"
+	if jobsLogsStderr {
+		stream = "stderr"
+	}
+	return client.copyRunLog(cmd.Context(), strings.TrimSpace(args[0]), stream, jobsLogsFollow, os.Stdout)
+}
+
 func jobsArgCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
 	defer cancel()
@@ -939,9 +1498,15 @@ func jobsArgCompletion(cmd *cobra.Command, args []string, toComplete string) ([]
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
-	jobs, err := client.listJobs(ctx)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveNoFileComp
+	jobs, ok := client.cache.jobs()
+	if !ok {
+		live, err := client.listJobs(ctx)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		for _, job := range live {
+			jobs = append(jobs, jobsCacheEntry{ID: job.ID, Name: job.Name})
+		}
 	}
 	prefix := strings.ToLower(toComplete)
 	completions := make([]string, 0)
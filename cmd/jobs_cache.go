@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// jobsCacheTTL is how long cached job names are trusted for completion and
+// name resolution.
+const jobsCacheTTL = 10 * time.Minute
+
+// jobsCacheFile is ~/.cache/term-llm/jobs.json: the id/name pairs last listed
+// from each jobs server, keyed by the --server value.
+type jobsCacheFile struct {
+	Servers map[string]jobsCacheSnapshot `json:"servers"`
+}
+
+type jobsCacheSnapshot struct {
+	FetchedAt time.Time        `json:"fetched_at"`
+	Jobs      []jobsCacheEntry `json:"jobs"`
+}
+
+type jobsCacheEntry struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// jobsCache reads and writes one server's entry in the cache file. A nil
+// *jobsCache is valid and caches nothing. Cache errors are never reported: a
+// missing or unreadable cache only means the next lookup goes to the server.
+type jobsCache struct {
+	path   string
+	server string
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+func newJobsCache(server string) *jobsCache {
+	cacheHome := os.Getenv("XDG_CACHE_HOME")
+	if cacheHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil
+		}
+		cacheHome = filepath.Join(home, ".cache")
+	}
+	return &jobsCache{
+		path:   filepath.Join(cacheHome, "term-llm", "jobs.json"),
+		server: server,
+		ttl:    jobsCacheTTL,
+		now:    time.Now,
+	}
+}
+
+func (c *jobsCache) read() jobsCacheFile {
+	var file jobsCacheFile
+	if data, err := os.ReadFile(c.path); err == nil {
+		_ = json.Unmarshal(data, &file)
+	}
+	if file.Servers == nil {
+		file.Servers = make(map[string]jobsCacheSnapshot)
+	}
+	return file
+}
+
+func (c *jobsCache) write(file jobsCacheFile) {
+	data, err := json.Marshal(file)
+	if err != nil {
+		return
+	}
+	dir := filepath.Dir(c.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return
+	}
+	f, err := os.CreateTemp(dir, "jobs-*.tmp")
+	if err != nil {
+		return
+	}
+	tmpPath := f.Name()
+	_, writeErr := f.Write(data)
+	closeErr := f.Close()
+	if writeErr != nil || closeErr != nil || os.Rename(tmpPath, c.path) != nil {
+		_ = os.Remove(tmpPath)
+	}
+}
+
+// jobs returns the cached jobs for this server, or false when there are none
+// younger than the TTL.
+func (c *jobsCache) jobs() ([]jobsCacheEntry, bool) {
+	if c == nil {
+		return nil, false
+	}
+	snapshot, ok := c.read().Servers[c.server]
+	if !ok || c.now().Sub(snapshot.FetchedAt) > c.ttl {
+		return nil, false
+	}
+	return snapshot.Jobs, true
+}
+
+// store replaces this server's entry with a fresh listing.
+func (c *jobsCache) store(jobs []jobsV2Job) {
+	if c == nil {
+		return
+	}
+	entries := make([]jobsCacheEntry, 0, len(jobs))
+	for _, job := range jobs {
+		entries = append(entries, jobsCacheEntry{ID: job.ID, Name: job.Name})
+	}
+	file := c.read()
+	file.Servers[c.server] = jobsCacheSnapshot{FetchedAt: c.now(), Jobs: entries}
+	c.write(file)
+}
+
+// update applies a create, rename or delete to a still-fresh entry without
+// extending its age. A nil job removes jobID.
+func (c *jobsCache) update(jobID string, job *jobsV2Job) {
+	if c == nil {
+		return
+	}
+	file := c.read()
+	snapshot, ok := file.Servers[c.server]
+	if !ok {
+		return
+	}
+	entries := make([]jobsCacheEntry, 0, len(snapshot.Jobs)+1)
+	for _, entry := range snapshot.Jobs {
+		if entry.ID != jobID {
+			entries = append(entries, entry)
+		}
+	}
+	if job != nil {
+		entries = append(entries, jobsCacheEntry{ID: job.ID, Name: job.Name})
+	}
+	snapshot.Jobs = entries
+	file.Servers[c.server] = snapshot
+	c.write(file)
+}
+
+// resolve looks ref up in the cache the same way resolveJobID does against a
+// live listing. It only answers when there is exactly one match; misses and
+// ambiguous references go to the server.
+func (c *jobsCache) resolve(ref string) (string, bool) {
+	jobs, ok := c.jobs()
+	if !ok {
+		return "", false
+	}
+	var exactName, prefixID []string
+	for _, job := range jobs {
+		if job.ID == ref {
+			return job.ID, true
+		}
+		if job.Name == ref {
+			exactName = append(exactName, job.ID)
+		}
+		if strings.HasPrefix(job.ID, ref) {
+			prefixID = append(prefixID, job.ID)
+		}
+	}
+	switch {
+	case len(exactName) == 1:
+		return exactName[0], true
+	case len(exactName) == 0 && len(prefixID) == 1:
+		return prefixID[0], true
+	}
+	return "", false
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestJobsCache(t *testing.T, now *time.Time) *jobsCache {
+	t.Helper()
+	return &jobsCache{
+		path:   filepath.Join(t.TempDir(), "jobs.json"),
+		server: "http://127.0.0.1:8080",
+		ttl:    10 * time.Minute,
+		now:    func() time.Time { return *now },
+	}
+}
+
+func TestJobsCacheResolvesWithoutServerAfterListing(t *testing.T) {
+	var listCalls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		listCalls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[{"id":"job_123","name":"nightly"},{"id":"job_456","name":"weekly"},{"id":"job_457","name":"dup"},{"id":"job_458","name":"dup"}]}`))
+	}))
+	defer srv.Close()
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	c := &jobsClient{baseURL: srv.URL, http: srv.Client(), cache: newTestJobsCache(t, &now)}
+	ctx := context.Background()
+
+	if _, err := c.listJobs(ctx); err != nil {
+		t.Fatalf("listJobs failed: %v", err)
+	}
+	for ref, want := range map[string]string{"nightly": "job_123", "weekly": "job_456"} {
+		id, err := c.resolveJobID(ctx, ref)
+		if err != nil || id != want {
+			t.Fatalf("resolveJobID(%q) = %q, %v; want %q", ref, id, err, want)
+		}
+	}
+	if got := listCalls.Load(); got != 1 {
+		t.Fatalf("list calls after cached lookups = %d, want 1", got)
+	}
+
+	// "dup" names two cached jobs, so the server decides.
+	if _, err := c.resolveJobID(ctx, "dup"); err == nil {
+		t.Fatal("expected ambiguous reference error")
+	}
+	if got := listCalls.Load(); got != 2 {
+		t.Fatalf("list calls after ambiguous lookup = %d, want 2", got)
+	}
+
+	now = now.Add(11 * time.Minute)
+	if _, ok := c.cache.jobs(); ok {
+		t.Fatal("expected cache older than the TTL to be ignored")
+	}
+	if id, err := c.resolveJobID(ctx, "weekly"); err != nil || id != "job_456" {
+		t.Fatalf("resolveJobID after TTL = %q, %v", id, err)
+	}
+	if got := listCalls.Load(); got != 3 {
+		t.Fatalf("list calls after expired lookup = %d, want 3", got)
+	}
+}
+
+func TestJobsCacheUpdateTracksCreateRenameAndDelete(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	cache := newTestJobsCache(t, &now)
+
+	// Without a listing there is nothing to keep fresh.
+	cache.update("job_new", &jobsV2Job{ID: "job_new", Name: "new"})
+	if _, ok := cache.jobs(); ok {
+		t.Fatal("update should not create a cache entry")
+	}
+
+	cache.store([]jobsV2Job{{ID: "job_1", Name: "one"}, {ID: "job_2", Name: "two"}})
+	now = now.Add(5 * time.Minute)
+	cache.update("job_3", &jobsV2Job{ID: "job_3", Name: "three"})
+	cache.update("job_1", &jobsV2Job{ID: "job_1", Name: "uno"})
+	cache.update("job_2", nil)
+
+	if _, ok := cache.resolve("one"); ok {
+		t.Fatal("renamed job still resolves by its old name")
+	}
+	if id, ok := cache.resolve("uno"); !ok || id != "job_1" {
+		t.Fatalf("resolve(uno) = %q, %t", id, ok)
+	}
+	if id, ok := cache.resolve("three"); !ok || id != "job_3" {
+		t.Fatalf("resolve(three) = %q, %t", id, ok)
+	}
+	if _, ok := cache.resolve("two"); ok {
+		t.Fatal("deleted job still resolves")
+	}
+
+	// Updates do not extend the listing's age.
+	now = now.Add(6 * time.Minute)
+	if _, ok := cache.jobs(); ok {
+		t.Fatal("expected cache to expire ten minutes after the listing")
+	}
+}
+
+func TestJobsResolveToChangeChecksStaleCacheAgainstServer(t *testing.T) {
+	var listCalls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v2/jobs/job_123":
+			_, _ = w.Write([]byte(`{"id":"job_123","name":"renamed"}`))
+		default:
+			listCalls.Add(1)
+			_, _ = w.Write([]byte(`{"data":[{"id":"job_123","name":"renamed"},{"id":"job_999","name":"nightly"}]}`))
+		}
+	}))
+	defer srv.Close()
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	c := &jobsClient{baseURL: srv.URL, http: srv.Client(), cache: newTestJobsCache(t, &now)}
+	c.cache.store([]jobsV2Job{{ID: "job_123", Name: "nightly"}})
+	ctx := context.Background()
+
+	if id, err := c.resolveJobID(ctx, "nightly"); err != nil || id != "job_123" {
+		t.Fatalf("resolveJobID(nightly) = %q, %v; want the cached job_123", id, err)
+	}
+	id, err := c.resolveJobIDToChange(ctx, "nightly")
+	if err != nil || id != "job_999" {
+		t.Fatalf("resolveJobIDToChange(nightly) = %q, %v; want job_999 from the server", id, err)
+	}
+	if got := listCalls.Load(); got != 1 {
+		t.Fatalf("list calls = %d, want 1 after the stale cache hit", got)
+	}
+	if id, ok := c.cache.resolve("nightly"); !ok || id != "job_999" {
+		t.Fatalf("cache after refresh resolves nightly to %q, %t", id, ok)
+	}
+}
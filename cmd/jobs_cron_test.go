@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCronExpression(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{expr: "*/15 * * * *"},
+		{expr: "0 9 * * 1-5"},
+		{expr: "0 0 1 1,7 *"},
+		{expr: "0 25 * * *", wantErr: "hour: value 25 out of bounds"},
+		{expr: "0 0 * * 7", wantErr: "day-of-week: value 7 out of bounds"},
+		{expr: "0 9 * * MON-FRI", wantErr: `day-of-week: invalid range "MON-FRI"`},
+		{expr: "0 0 5-1 * *", wantErr: `day-of-month: invalid range "5-1"`},
+		{expr: "*/0 * * * *", wantErr: `minute: invalid step "*/0"`},
+		{expr: "0 0 * *", wantErr: "must have 5 fields"},
+		{expr: "@daily", wantErr: "must have 5 fields"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := parseCronExpression(tt.expr)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// The preview must list exactly the times the scheduler fires, so these pin
+// the scheduler's matcher: day of month and day of week must both match, and
+// run times follow the wall clock through daylight-saving changes.
+func TestNextCronTimes(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	at := func(layout string) time.Time {
+		ts, err := time.Parse(time.RFC3339, layout)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+
+	tests := []struct {
+		name  string
+		expr  string
+		tz    string
+		after time.Time
+		want  []string
+	}{
+		{
+			name:  "weekdays",
+			expr:  "0 9 * * 1-5",
+			tz:    "UTC",
+			after: at("2026-10-16T10:00:00Z"), // Friday
+			want:  []string{"2026-10-19T09:00:00Z", "2026-10-20T09:00:00Z"},
+		},
+		{
+			name:  "day-of-month and day-of-week",
+			expr:  "0 0 1 * 0",
+			tz:    "UTC",
+			after: at("2026-10-30T00:00:00Z"),
+			want:  []string{"2026-11-01T00:00:00Z", "2027-08-01T00:00:00Z"},
+		},
+		{
+			name:  "skipped hour does not fire",
+			expr:  "30 2 * * *",
+			tz:    "America/New_York",
+			after: time.Date(2026, 3, 7, 12, 0, 0, 0, ny),
+			want:  []string{"2026-03-09T02:30:00-04:00"},
+		},
+		{
+			name:  "repeated hour fires on both passes",
+			expr:  "30 1 * * *",
+			tz:    "America/New_York",
+			after: time.Date(2026, 10, 31, 12, 0, 0, 0, ny),
+			want:  []string{"2026-11-01T01:30:00-04:00", "2026-11-01T01:30:00-05:00", "2026-11-02T01:30:00-05:00"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := nextCronTimes(tt.expr, tt.tz, tt.after, len(tt.want))
+			if err != nil {
+				t.Fatalf("nextCronTimes: %v", err)
+			}
+			for i, want := range tt.want {
+				if !got[i].Equal(at(want)) {
+					t.Errorf("run %d = %s, want %s", i, got[i].Format(time.RFC3339), want)
+				}
+			}
+		})
+	}
+
+	if _, err := nextCronTime("0 0 31 2 *", "UTC", at("2026-01-01T00:00:00Z")); err == nil {
+		t.Error("expected an error for an expression that never fires")
+	}
+}
+
+func TestWriteJobsTriggerSummary(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	next := now.Add(26 * time.Hour)
+
+	tests := []struct {
+		name string
+		job  jobsV2Job
+		want []string
+	}{
+		{
+			name: "cron",
+			job: jobsV2Job{Enabled: true, TriggerType: jobsV2TriggerCron,
+				TriggerConfig: []byte(`{"expression":"0 9 * * *","timezone":"UTC"}`)},
+			want: []string{
+				`Trigger:  cron "0 9 * * *" in UTC`,
+				"Overlap:  skip; a run due while the previous one is active is recorded as skipped",
+				"  Sat 2026-10-17 09:00 UTC  (local Sat 2026-10-17 18:00 JST)",
+				"  Wed 2026-10-21 09:00 UTC",
+			},
+		},
+		{
+			name: "once",
+			job: jobsV2Job{Enabled: true, TriggerType: jobsV2TriggerOnce, NextRunAt: &next, OverlapPolicy: jobsV2OverlapQueue,
+				TriggerConfig: []byte(`{"run_at":"` + next.Format(time.RFC3339) + `"}`)},
+			want: []string{"Trigger:  once at Sat 2026-10-17 14:00 UTC", "Overlap:  queue;", "Status:   fires in 1d 2h"},
+		},
+		{
+			name: "manual",
+			job:  jobsV2Job{Enabled: true, TriggerType: jobsV2TriggerManual},
+			want: []string{"Trigger:  manual"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeJobsTriggerSummary(&buf, tt.job, now, tokyo)
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("summary missing %q:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
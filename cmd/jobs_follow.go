@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// jobsFollowPollInterval is how often a followed run is polled for new
+// events and status changes.
+var jobsFollowPollInterval = time.Second
+
+const jobsFollowEventsPageSize = 200
+
+// jobsRunFinished reports whether a run will not change status again.
+// Skipped runs are included: they never start.
+func jobsRunFinished(status jobsV2RunStatus) bool {
+	return status == jobsV2RunSkipped || jobsV2NotifyTerminalStatus(status)
+}
+
+// followJobsRun prints events for run as they arrive until it finishes, then
+// returns the final run. When ctx ends first the last seen run is returned
+// with ctx's error.
+func (c *jobsClient) followJobsRun(ctx context.Context, run jobsV2Run, out io.Writer) (jobsV2Run, error) {
+	var lastEventID int64
+	for {
+		// Events are fetched once more after the run finishes so the ones
+		// written just before it finished are printed.
+		var err error
+		lastEventID, err = c.printRunEventsSince(ctx, run.ID, lastEventID, out)
+		if err != nil {
+			return run, err
+		}
+		if jobsRunFinished(run.Status) {
+			return run, nil
+		}
+		select {
+		case <-ctx.Done():
+			return run, ctx.Err()
+		case <-time.After(jobsFollowPollInterval):
+		}
+		if err := c.do(ctx, http.MethodGet, "/v2/runs/"+url.PathEscape(run.ID), nil, &run); err != nil {
+			return run, err
+		}
+	}
+}
+
+func (c *jobsClient) printRunEventsSince(ctx context.Context, runID string, sinceID int64, out io.Writer) (int64, error) {
+	for {
+		path := fmt.Sprintf("/v2/runs/%s/events?limit=%d&since_id=%d", url.PathEscape(runID), jobsFollowEventsPageSize, sinceID)
+		var resp jobsRunEventsListResponse
+		if err := c.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+			return sinceID, err
+		}
+		for _, ev := range resp.Data {
+			printJobsRunEvent(out, ev)
+			sinceID = max(sinceID, ev.ID)
+		}
+		if len(resp.Data) < jobsFollowEventsPageSize {
+			return sinceID, nil
+		}
+	}
+}
+
+func printJobsRunEvent(out io.Writer, ev jobsV2RunEvent) {
+	msg := strings.TrimSpace(ev.Message)
+	if msg == "" {
+		msg = "-"
+	}
+	fmt.Fprintf(out, "%s %-18s %s\n", ev.CreatedAt.Local().Format(time.RFC3339), ev.EventType, msg)
+}
+
+// printJobsRunSummary writes the final status line for a followed run.
+func printJobsRunSummary(out io.Writer, run jobsV2Run) {
+	details := make([]string, 0, 5)
+	if run.ExitReason != "" {
+		details = append(details, run.ExitReason)
+	}
+	if run.ExitCode != nil {
+		details = append(details, fmt.Sprintf("exit code %d", *run.ExitCode))
+	}
+	if run.StartedAt != nil && run.FinishedAt != nil {
+		details = append(details, run.FinishedAt.Sub(*run.StartedAt).Round(time.Millisecond).String())
+	}
+	if run.TurnCount > 0 {
+		details = append(details, fmt.Sprintf("%d turns", run.TurnCount))
+	}
+	if run.InputTokens > 0 || run.OutputTokens > 0 {
+		details = append(details, fmt.Sprintf("%d in / %d out tokens", run.InputTokens, run.OutputTokens))
+	}
+	line := fmt.Sprintf("run %s %s", run.ID, run.Status)
+	if len(details) > 0 {
+		line += " (" + strings.Join(details, ", ") + ")"
+	}
+	fmt.Fprintln(out, line)
+	if errText := strings.TrimSpace(run.Error); errText != "" {
+		fmt.Fprintf(out, "error: %s\n", errText)
+	}
+}
+
+// jobsRunOutcomeError turns a finished run into the command's exit status:
+// nil only when the run succeeded.
+func jobsRunOutcomeError(run jobsV2Run) error {
+	if run.Status == jobsV2RunSucceeded {
+		return nil
+	}
+	if errText := strings.TrimSpace(run.Error); errText != "" {
+		return fmt.Errorf("run %s %s: %s", run.ID, run.Status, errText)
+	}
+	return fmt.Errorf("run %s %s", run.ID, run.Status)
+}
+
+// triggerAndFollowJob triggers jobID and waits for the run to finish,
+// printing its events to out. waitTimeout bounds the wait on the client
+// only; the run keeps going on the server unless cancelOnTimeout is set.
+func (c *jobsClient) triggerAndFollowJob(ctx context.Context, jobID string, waitTimeout time.Duration, cancelOnTimeout bool, out io.Writer) (jobsV2Run, error) {
+	var run jobsV2Run
+	if err := c.doIdempotent(ctx, http.MethodPost, "/v2/jobs/"+jobID+"/trigger", nil, &run); err != nil {
+		return run, err
+	}
+	fmt.Fprintf(out, "triggered run %s (%s)\n", run.ID, run.Status)
+
+	waitCtx := ctx
+	if waitTimeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, waitTimeout)
+		defer cancel()
+	}
+	run, err := c.followJobsRun(waitCtx, run, out)
+	if err == nil {
+		return run, nil
+	}
+	if !errors.Is(waitCtx.Err(), context.DeadlineExceeded) || ctx.Err() != nil {
+		return run, err
+	}
+
+	if !cancelOnTimeout {
+		return run, fmt.Errorf("gave up waiting for run %s after %s; it is still %s on the server (use --cancel-on-timeout to cancel it)", run.ID, waitTimeout, run.Status)
+	}
+	cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 15*time.Second)
+	defer cancel()
+	if cancelErr := c.do(cancelCtx, http.MethodPost, "/v2/runs/"+url.PathEscape(run.ID)+"/cancel", nil, &run); cancelErr != nil {
+		return run, fmt.Errorf("gave up waiting for run %s after %s; cancel failed: %w", run.ID, waitTimeout, cancelErr)
+	}
+	return run, fmt.Errorf("gave up waiting for run %s after %s; cancel requested", run.ID, waitTimeout)
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeFollowServer serves a single triggered run whose status advances
+// through statuses on each GET, adding one event per status.
+type fakeFollowServer struct {
+	mu        sync.Mutex
+	statuses  []jobsV2RunStatus
+	polls     int
+	events    []jobsV2RunEvent
+	cancelled bool
+}
+
+func (f *fakeFollowServer) run() jobsV2Run {
+	run := jobsV2Run{ID: "run_1", JobID: "job_1", Status: f.statuses[min(f.polls, len(f.statuses)-1)]}
+	if run.Status == jobsV2RunFailed {
+		run.Error = "boom"
+	}
+	return run
+}
+
+func (f *fakeFollowServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/v2/jobs/job_1/trigger":
+		f.addEventLocked()
+		writeJSON(w, http.StatusOK, f.run())
+	case r.Method == http.MethodGet && r.URL.Path == "/v2/runs/run_1":
+		f.polls++
+		f.addEventLocked()
+		writeJSON(w, http.StatusOK, f.run())
+	case r.Method == http.MethodGet && r.URL.Path == "/v2/runs/run_1/events":
+		sinceID, _ := strconv.ParseInt(r.URL.Query().Get("since_id"), 10, 64)
+		var data []jobsV2RunEvent
+		for _, ev := range f.events {
+			if ev.ID > sinceID {
+				data = append(data, ev)
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"data": data})
+	case r.Method == http.MethodPost && r.URL.Path == "/v2/runs/run_1/cancel":
+		f.cancelled = true
+		writeJSON(w, http.StatusOK, jobsV2Run{ID: "run_1", Status: jobsV2RunCancelRequested})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeFollowServer) addEventLocked() {
+	run := f.run()
+	if n := len(f.events); n > 0 && f.events[n-1].EventType == string(run.Status) {
+		return
+	}
+	f.events = append(f.events, jobsV2RunEvent{ID: int64(len(f.events) + 1), RunID: run.ID, EventType: string(run.Status), CreatedAt: time.Now()})
+}
+
+func TestTriggerAndFollowJob(t *testing.T) {
+	oldInterval := jobsFollowPollInterval
+	jobsFollowPollInterval = time.Millisecond
+	t.Cleanup(func() { jobsFollowPollInterval = oldInterval })
+
+	tests := []struct {
+		name        string
+		statuses    []jobsV2RunStatus
+		wantStatus  jobsV2RunStatus
+		wantOutcome string // substring of jobsRunOutcomeError; empty for success
+	}{
+		{
+			name:       "succeeds",
+			statuses:   []jobsV2RunStatus{jobsV2RunQueued, jobsV2RunRunning, jobsV2RunSucceeded},
+			wantStatus: jobsV2RunSucceeded,
+		},
+		{
+			name:        "fails",
+			statuses:    []jobsV2RunStatus{jobsV2RunQueued, jobsV2RunRunning, jobsV2RunFailed},
+			wantStatus:  jobsV2RunFailed,
+			wantOutcome: "run run_1 failed: boom",
+		},
+		{
+			name:        "skipped immediately",
+			statuses:    []jobsV2RunStatus{jobsV2RunSkipped},
+			wantStatus:  jobsV2RunSkipped,
+			wantOutcome: "run run_1 skipped",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeFollowServer{statuses: tt.statuses}
+			srv := httptest.NewServer(fake)
+			defer srv.Close()
+			client := &jobsClient{baseURL: srv.URL, http: srv.Client()}
+
+			var out bytes.Buffer
+			run, err := client.triggerAndFollowJob(context.Background(), "job_1", time.Minute, false, &out)
+			if err != nil {
+				t.Fatalf("triggerAndFollowJob: %v", err)
+			}
+			if run.Status != tt.wantStatus {
+				t.Fatalf("status = %s, want %s", run.Status, tt.wantStatus)
+			}
+			for _, status := range tt.statuses {
+				if !strings.Contains(out.String(), string(status)) {
+					t.Errorf("output missing %s event:\n%s", status, out.String())
+				}
+			}
+
+			outcome := jobsRunOutcomeError(run)
+			if tt.wantOutcome == "" {
+				if outcome != nil {
+					t.Fatalf("outcome = %v, want nil", outcome)
+				}
+			} else if outcome == nil || !strings.Contains(outcome.Error(), tt.wantOutcome) {
+				t.Fatalf("outcome = %v, want %q", outcome, tt.wantOutcome)
+			}
+		})
+	}
+}
+
+func TestTriggerAndFollowJobWaitTimeout(t *testing.T) {
+	oldInterval := jobsFollowPollInterval
+	jobsFollowPollInterval = time.Millisecond
+	t.Cleanup(func() { jobsFollowPollInterval = oldInterval })
+
+	for _, cancelOnTimeout := range []bool{false, true} {
+		t.Run("cancel="+strconv.FormatBool(cancelOnTimeout), func(t *testing.T) {
+			fake := &fakeFollowServer{statuses: []jobsV2RunStatus{jobsV2RunRunning}}
+			srv := httptest.NewServer(fake)
+			defer srv.Close()
+			client := &jobsClient{baseURL: srv.URL, http: srv.Client()}
+
+			_, err := client.triggerAndFollowJob(context.Background(), "job_1", 20*time.Millisecond, cancelOnTimeout, &bytes.Buffer{})
+			if err == nil || !strings.Contains(err.Error(), "gave up waiting for run run_1") {
+				t.Fatalf("err = %v, want wait timeout", err)
+			}
+			fake.mu.Lock()
+			defer fake.mu.Unlock()
+			if fake.cancelled != cancelOnTimeout {
+				t.Fatalf("cancelled = %v, want %v", fake.cancelled, cancelOnTimeout)
+			}
+		})
+	}
+}
+
+func TestPrintJobsRunSummary(t *testing.T) {
+	started := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	finished := started.Add(90 * time.Second)
+	code := 1
+	run := jobsV2Run{
+		ID: "run_1", Status: jobsV2RunFailed, ExitReason: "exception", ExitCode: &code,
+		StartedAt: &started, FinishedAt: &finished, TurnCount: 3, InputTokens: 10, OutputTokens: 5, Error: "boom",
+	}
+	var out bytes.Buffer
+	printJobsRunSummary(&out, run)
+	want := "run run_1 failed (exception, exit code 1, 1m30s, 3 turns, 10 in / 5 out tokens)\nerror: boom\n"
+	if out.String() != want {
+		t.Fatalf("summary = %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+var (
+	jobsUpdateCron    string
+	jobsUpdateEnabled bool
+	jobsUpdateName    string
+	jobsUpdateTimeout time.Duration
+)
+
+// jobsUpdateFieldFlags are the update flags that each set one job field.
+var jobsUpdateFieldFlags = []string{"cron", "enabled", "name", "run-timeout"}
+
+func registerJobsUpdateFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&jobsUpdateFile, "file", "", "Path to JSON/YAML merge patch file (- for stdin)")
+	cmd.Flags().StringVar(&jobsUpdateData, "data", "", "Inline JSON/YAML merge patch")
+	cmd.Flags().BoolVar(&jobsNoValidate, "no-validate", false, "Send the payload without client-side validation")
+	cmd.Flags().StringVar(&jobsUpdateCron, "cron", "", "Run on this cron schedule, e.g. '0 3 * * *'")
+	cmd.Flags().BoolVar(&jobsUpdateEnabled, "enabled", true, "Enable or disable the job (--enabled=false)")
+	cmd.Flags().StringVar(&jobsUpdateName, "name", "", "Rename the job")
+	cmd.Flags().DurationVar(&jobsUpdateTimeout, "run-timeout", 0, "Job run timeout, e.g. 30m")
+}
+
+// jobsUpdatePatch builds the merge patch for jobs update, either from the
+// field flags or from --file/--data. The two cannot be mixed.
+func jobsUpdatePatch(flags *pflag.FlagSet) ([]byte, error) {
+	var used []string
+	for _, name := range jobsUpdateFieldFlags {
+		if flags.Changed(name) {
+			used = append(used, "--"+name)
+		}
+	}
+	if len(used) == 0 {
+		return readJobPayload(jobsUpdateFile, jobsUpdateData, true)
+	}
+	if strings.TrimSpace(jobsUpdateFile) != "" || strings.TrimSpace(jobsUpdateData) != "" {
+		return nil, fmt.Errorf("%s cannot be combined with --file or --data; put the field in the payload instead", strings.Join(used, ", "))
+	}
+
+	patch := map[string]any{}
+	if flags.Changed("name") {
+		if strings.TrimSpace(jobsUpdateName) == "" {
+			return nil, fmt.Errorf("--name must not be empty")
+		}
+		patch["name"] = jobsUpdateName
+	}
+	if flags.Changed("enabled") {
+		patch["enabled"] = jobsUpdateEnabled
+	}
+	if flags.Changed("cron") {
+		patch["trigger_type"] = jobsV2TriggerCron
+		patch["trigger_config"] = map[string]any{"expression": jobsUpdateCron}
+	}
+	if flags.Changed("run-timeout") {
+		if jobsUpdateTimeout < time.Second {
+			return nil, fmt.Errorf("--run-timeout must be at least 1s")
+		}
+		patch["timeout_seconds"] = int(jobsUpdateTimeout.Round(time.Second) / time.Second)
+	}
+	payload, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+	if !jobsNoValidate {
+		if problems := validateJobPayload(payload, true); len(problems) > 0 {
+			return nil, jobPayloadValidationError(problems)
+		}
+	}
+	return payload, nil
+}
+
+// applyJSONMergePatch applies patch to doc as described by RFC 7386: object
+// members of the patch replace those of doc, nested objects merge
+// recursively, and null removes a member.
+func applyJSONMergePatch(doc, patch []byte) ([]byte, error) {
+	var target, p any
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, fmt.Errorf("invalid merge patch: %w", err)
+	}
+	if _, ok := p.(map[string]any); !ok {
+		return nil, fmt.Errorf("merge patch must be a JSON object")
+	}
+	return json.Marshal(mergePatchValue(target, p))
+}
+
+func mergePatchValue(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+	for key, value := range p {
+		if value == nil {
+			delete(t, key)
+			continue
+		}
+		t[key] = mergePatchValue(t[key], value)
+	}
+	return t
+}
+
+// jobsDiffIgnored are job fields that change on every update or are not part
+// of the definition, so they are left out of the update diff.
+var jobsDiffIgnored = map[string]bool{"created_at": true, "updated_at": true, "last_run": true}
+
+// writeJobsUpdateDiff prints the fields that differ between before and after,
+// one "field: old → new" line each, with nested fields as dotted paths.
+func writeJobsUpdateDiff(w io.Writer, before, after jobsV2Job) error {
+	oldFields, err := flattenJobFields(before)
+	if err != nil {
+		return err
+	}
+	newFields, err := flattenJobFields(after)
+	if err != nil {
+		return err
+	}
+	paths := make([]string, 0, len(oldFields)+len(newFields))
+	for path := range oldFields {
+		paths = append(paths, path)
+	}
+	for path := range newFields {
+		if _, ok := oldFields[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	changed := 0
+	for _, path := range paths {
+		oldValue, hadOld := oldFields[path]
+		newValue, hasNew := newFields[path]
+		if hadOld && hasNew && oldValue == newValue {
+			continue
+		}
+		if !hadOld {
+			oldValue = "(unset)"
+		}
+		if !hasNew {
+			newValue = "(unset)"
+		}
+		if changed == 0 {
+			fmt.Fprintf(w, "Updated %s (%s):\n", after.Name, after.ID)
+		}
+		fmt.Fprintf(w, "  %s: %s → %s\n", path, oldValue, newValue)
+		changed++
+	}
+	if changed == 0 {
+		fmt.Fprintf(w, "No changes to %s (%s)\n", after.Name, after.ID)
+	}
+	return nil
+}
+
+// flattenJobFields maps the dotted path of every leaf field of job to its
+// JSON encoding. Arrays are kept whole.
+func flattenJobFields(job jobsV2Job) (map[string]string, error) {
+	raw, err := json.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	fields := make(map[string]string)
+	var walk func(prefix string, value any)
+	walk = func(prefix string, value any) {
+		if obj, ok := value.(map[string]any); ok && len(obj) > 0 {
+			for key, v := range obj {
+				walk(prefix+"."+key, v)
+			}
+			return
+		}
+		encoded, _ := json.Marshal(value)
+		fields[strings.TrimPrefix(prefix, ".")] = string(encoded)
+	}
+	for key, value := range doc {
+		if !jobsDiffIgnored[key] {
+			walk("."+key, value)
+		}
+	}
+	return fields, nil
+}
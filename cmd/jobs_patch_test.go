@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const jobsPatchTestJob = `{"id":"job_nightly","name":"nightly","enabled":true,"runner_type":"program","runner_config":{"command":"make"},"trigger_type":"cron","trigger_config":{"expression":"0 9 * * *","timezone":"UTC"},"timeout_seconds":300,"updated_at":"2026-01-01T00:00:00Z"}`
+
+// runJobsUpdateWithFlags runs jobs update against a server that records the
+// PATCH request and answers with after. It returns the PATCH body and its
+// Content-Type ("" when no PATCH was sent) along with stdout.
+func runJobsUpdateWithFlags(t *testing.T, args []string, after string) (body, contentType, output string, err error) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/v2/jobs/job_nightly" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte(jobsPatchTestJob))
+		case http.MethodPatch:
+			raw, _ := io.ReadAll(r.Body)
+			body, contentType = string(raw), r.Header.Get("Content-Type")
+			_, _ = w.Write([]byte(after))
+		default:
+			t.Errorf("unexpected method: %s", r.Method)
+		}
+	}))
+	defer srv.Close()
+
+	oldServerURL, oldToken, oldTimeout, oldJSON := jobsServerURL, jobsToken, jobsTimeout, jobsJSON
+	jobsServerURL = srv.URL
+	jobsToken = ""
+	jobsTimeout = 2 * time.Second
+	jobsJSON = false
+	t.Cleanup(func() {
+		jobsServerURL, jobsToken, jobsTimeout, jobsJSON = oldServerURL, oldToken, oldTimeout, oldJSON
+		registerJobsUpdateFlags(&cobra.Command{})
+	})
+
+	cmd := &cobra.Command{}
+	registerJobsUpdateFlags(cmd)
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatalf("parse flags: %v", err)
+	}
+	cmd.SetContext(context.Background())
+	output = captureStdout(t, func() {
+		err = runJobsUpdate(cmd, []string{"job_nightly"})
+	})
+	return body, contentType, output, err
+}
+
+func TestRunJobsUpdateSendsMergePatch(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "patch.yaml")
+	if err := os.WriteFile(file, []byte("trigger_config:\n  timezone: Europe/Paris\nlabels: null\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "cron flag",
+			args: []string{"--cron", "0 3 * * *"},
+			want: `{"trigger_config":{"expression":"0 3 * * *"},"trigger_type":"cron"}`,
+		},
+		{
+			name: "all field flags",
+			args: []string{"--enabled=false", "--name", "nightly-build", "--run-timeout", "30m", "--cron", "0 3 * * *"},
+			want: `{"enabled":false,"name":"nightly-build","timeout_seconds":1800,"trigger_config":{"expression":"0 3 * * *"},"trigger_type":"cron"}`,
+		},
+		{
+			name: "inline data keeps nulls",
+			args: []string{"--data", `{"runner_config":{"args":null}}`},
+			want: `{"runner_config":{"args":null}}`,
+		},
+		{
+			name: "yaml file",
+			args: []string{"--file", file},
+			want: `{"labels":null,"trigger_config":{"timezone":"Europe/Paris"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, contentType, _, err := runJobsUpdateWithFlags(t, tt.args, jobsPatchTestJob)
+			if err != nil {
+				t.Fatalf("runJobsUpdate: %v", err)
+			}
+			if contentType != "application/merge-patch+json" {
+				t.Fatalf("Content-Type = %q, want application/merge-patch+json", contentType)
+			}
+			if body != tt.want {
+				t.Fatalf("PATCH body = %s, want %s", body, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunJobsUpdateRejectsInvalidCombinations(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "flag with data",
+			args: []string{"--enabled=false", "--data", `{"name":"x"}`},
+			want: "--enabled cannot be combined with --file or --data",
+		},
+		{
+			name: "flags with file",
+			args: []string{"--name", "x", "--run-timeout", "1m", "--file", "patch.yaml"},
+			want: "--name, --run-timeout cannot be combined with --file or --data",
+		},
+		{
+			name: "bad cron",
+			args: []string{"--cron", "0 25 * * *"},
+			want: `trigger_config.expression: invalid cron expression "0 25 * * *"`,
+		},
+		{
+			name: "sub-second timeout",
+			args: []string{"--run-timeout", "10ms"},
+			want: "--run-timeout must be at least 1s",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _, _, err := runJobsUpdateWithFlags(t, tt.args, jobsPatchTestJob)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.want)
+			}
+			if body != "" {
+				t.Fatalf("PATCH sent despite error: %s", body)
+			}
+		})
+	}
+}
+
+func TestRunJobsUpdatePrintsChangedFields(t *testing.T) {
+	after := strings.NewReplacer(
+		`"enabled":true`, `"enabled":false`,
+		`"expression":"0 9 * * *"`, `"expression":"0 3 * * *"`,
+		`"updated_at":"2026-01-01T00:00:00Z"`, `"updated_at":"2026-02-01T00:00:00Z"`,
+	).Replace(jobsPatchTestJob)
+	_, _, output, err := runJobsUpdateWithFlags(t, []string{"--enabled=false", "--cron", "0 3 * * *"}, after)
+	if err != nil {
+		t.Fatalf("runJobsUpdate: %v", err)
+	}
+	want := "Updated nightly (job_nightly):\n" +
+		"  enabled: true → false\n" +
+		"  trigger_config.expression: \"0 9 * * *\" → \"0 3 * * *\"\n"
+	if output != want {
+		t.Fatalf("output =\n%s\nwant\n%s", output, want)
+	}
+
+	_, _, output, err = runJobsUpdateWithFlags(t, []string{"--name", "nightly"}, jobsPatchTestJob)
+	if err != nil {
+		t.Fatalf("runJobsUpdate: %v", err)
+	}
+	if output != "No changes to nightly (job_nightly)\n" {
+		t.Fatalf("output = %q, want no changes", output)
+	}
+}
+
+func TestApplyJSONMergePatch(t *testing.T) {
+	doc := `{"a":"b","c":{"d":"e","f":"g"},"list":[1,2]}`
+	got, err := applyJSONMergePatch([]byte(doc), []byte(`{"a":"z","c":{"f":null,"h":{"i":1}},"list":[3]}`))
+	if err != nil {
+		t.Fatalf("applyJSONMergePatch: %v", err)
+	}
+	if want := `{"a":"z","c":{"d":"e","h":{"i":1}},"list":[3]}`; string(got) != want {
+		t.Fatalf("merged = %s, want %s", got, want)
+	}
+	if _, err := applyJSONMergePatch([]byte(doc), []byte(`["a"]`)); err == nil {
+		t.Fatal("non-object patch should be rejected")
+	}
+}
+
+func TestJobsUpdateKeepsPersistentRequestTimeout(t *testing.T) {
+	if jobsUpdateCmd.LocalNonPersistentFlags().Lookup("timeout") != nil {
+		t.Fatal("jobs update should not shadow the persistent --timeout request timeout")
+	}
+	if jobsUpdateCmd.Flags().Lookup("run-timeout") == nil {
+		t.Fatal("jobs update should set the job run timeout with --run-timeout")
+	}
+}
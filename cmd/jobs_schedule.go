@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const jobsPreviewTimeLayout = "Mon 2006-01-02 15:04 MST"
+
+var jobsSchedulePreviewCmd = &cobra.Command{
+	Use:   "schedule-preview <cron-expression>",
+	Short: "Validate a cron expression and show its upcoming run times",
+	Long: `Validate a cron expression and print when it would fire, without
+creating a job. Times are evaluated in --timezone (default: local time) and
+also shown in local time when the two differ.
+
+Examples:
+  term-llm jobs schedule-preview '0 9 * * 1-5'
+  term-llm jobs schedule-preview '30 2 * * *' --timezone America/New_York --count 10`,
+	Args: cobra.ExactArgs(1),
+	RunE: runJobsSchedulePreview,
+}
+
+func runJobsSchedulePreview(cmd *cobra.Command, args []string) error {
+	if jobsPreviewCount <= 0 {
+		return fmt.Errorf("--count must be positive")
+	}
+	tz := strings.TrimSpace(jobsPreviewTimezone)
+	if tz == "" {
+		tz = "Local"
+	}
+	times, err := nextCronTimes(args[0], tz, time.Now(), jobsPreviewCount)
+	if err != nil {
+		return fmt.Errorf("invalid cron expression %q: %w", args[0], err)
+	}
+	if jobsJSON {
+		return printJSON(map[string]any{
+			"expression": args[0],
+			"timezone":   tz,
+			"next_runs":  times,
+		})
+	}
+	writeCronRunTimes(os.Stdout, times, time.Local)
+	return nil
+}
+
+// writeJobsTriggerSummary describes when job fires: the next cron run times,
+// the countdown to a once job, or that the job only runs when triggered.
+func writeJobsTriggerSummary(w io.Writer, job jobsV2Job, now time.Time, local *time.Location) {
+	cfg, err := parseTriggerConfig(job.TriggerType, job.TriggerConfig, job.ScheduleTimezone)
+	if err != nil {
+		fmt.Fprintf(w, "Trigger:  %s (invalid: %v)\n", job.TriggerType, err)
+		return
+	}
+	switch job.TriggerType {
+	case jobsV2TriggerManual:
+		fmt.Fprintln(w, "Trigger:  manual; runs only when triggered (term-llm jobs trigger)")
+	case jobsV2TriggerOnce:
+		runAt, _ := time.Parse(time.RFC3339, cfg.RunAt)
+		fmt.Fprintf(w, "Trigger:  once at %s\n", formatCronRunTime(runAt, local))
+		fmt.Fprintf(w, "Overlap:  %s\n", describeJobsOverlapPolicy(job.OverlapPolicy))
+		switch {
+		case !job.Enabled && job.NextRunAt == nil:
+			fmt.Fprintln(w, "Status:   already fired")
+		case runAt.After(now):
+			fmt.Fprintf(w, "Status:   fires in %s\n", formatCountdown(runAt.Sub(now)))
+		default:
+			fmt.Fprintf(w, "Status:   due %s ago\n", formatCountdown(now.Sub(runAt)))
+		}
+	case jobsV2TriggerCron:
+		fmt.Fprintf(w, "Trigger:  cron %q in %s\n", cfg.Expression, cfg.Timezone)
+		fmt.Fprintf(w, "Overlap:  %s\n", describeJobsOverlapPolicy(job.OverlapPolicy))
+		if !job.Enabled {
+			fmt.Fprintln(w, "Status:   paused; resume the job to schedule these runs")
+		}
+		times, err := nextCronTimes(cfg.Expression, cfg.Timezone, now, 5)
+		if err != nil {
+			fmt.Fprintf(w, "Next runs: unavailable (%v)\n", err)
+			return
+		}
+		fmt.Fprintln(w, "Next runs:")
+		writeCronRunTimes(w, times, local)
+	default:
+		fmt.Fprintf(w, "Trigger:  %s\n", job.TriggerType)
+	}
+}
+
+// describeJobsOverlapPolicy says what happens when a scheduled run comes due
+// while the previous one is still active. Servers that predate the field send
+// nothing, which behaves like skip.
+func describeJobsOverlapPolicy(policy string) string {
+	switch policy {
+	case jobsV2OverlapQueue:
+		return "queue; a run due while the previous one is active waits for it to finish"
+	case jobsV2OverlapCancelPrevious:
+		return "cancel_previous; a run due while the previous one is active cancels it first"
+	default:
+		return "skip; a run due while the previous one is active is recorded as skipped"
+	}
+}
+
+// writeCronRunTimes lists times in their own zone, adding the local time when
+// it reads differently.
+func writeCronRunTimes(w io.Writer, times []time.Time, local *time.Location) {
+	for _, t := range times {
+		fmt.Fprintf(w, "  %s\n", formatCronRunTime(t, local))
+	}
+}
+
+func formatCronRunTime(t time.Time, local *time.Location) string {
+	s := t.Format(jobsPreviewTimeLayout)
+	if l := t.In(local).Format(jobsPreviewTimeLayout); l != s {
+		s += "  (local " + l + ")"
+	}
+	return s
+}
+
+// formatCountdown renders d as a compact duration such as "2d 3h" or "45m".
+func formatCountdown(d time.Duration) string {
+	d = d.Round(time.Second)
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%dm", minutes)
+	default:
+		return fmt.Sprintf("%ds", int(d/time.Second))
+	}
+}
@@ -1,15 +1,20 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -31,15 +36,15 @@ func TestNormalizeJSONPayload_YAML(t *testing.T) {
 	}
 }
 
-func TestReadPayload_File(t *testing.T) {
+func TestReadJobPayload_File(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "job.yaml")
 	if err := os.WriteFile(path, []byte("name: test-job\n"), 0644); err != nil {
 		t.Fatalf("write file: %v", err)
 	}
-	out, err := readPayload(path, "")
+	out, err := readJobPayload(path, "", true)
 	if err != nil {
-		t.Fatalf("readPayload failed: %v", err)
+		t.Fatalf("readJobPayload failed: %v", err)
 	}
 	var decoded map[string]any
 	if err := json.Unmarshal(out, &decoded); err != nil {
@@ -469,3 +474,297 @@ func captureStdout(t *testing.T, fn func()) string {
 	}
 	return string(out)
 }
+
+func newRetryTestJobsClient(baseURL string, retries int) (*jobsClient, *bytes.Buffer) {
+	var stderr bytes.Buffer
+	return &jobsClient{
+		baseURL:    baseURL,
+		http:       &http.Client{Timeout: 2 * time.Second},
+		retries:    retries,
+		retryDelay: time.Millisecond,
+		stderr:     &stderr,
+	}, &stderr
+}
+
+func TestJobsClientRetriesUnavailableServer(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"job_1"}`))
+	}))
+	defer srv.Close()
+
+	client, stderr := newRetryTestJobsClient(srv.URL, 2)
+	var job jobsV2Job
+	if err := client.do(context.Background(), http.MethodGet, "/v2/jobs/job_1", nil, &job); err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	if job.ID != "job_1" || calls.Load() != 3 {
+		t.Fatalf("job = %+v after %d calls, want job_1 after 3", job, calls.Load())
+	}
+	if n := strings.Count(stderr.String(), "retrying in"); n != 2 {
+		t.Fatalf("stderr has %d retry notices, want 2: %q", n, stderr.String())
+	}
+}
+
+func TestJobsClientRetriesTriggerOnlyWhenUnsent(t *testing.T) {
+	var calls atomic.Int32
+	var keys []string
+	var keysMu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keysMu.Lock()
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		keysMu.Unlock()
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	// A 502 may come from a proxy after the server started the run, and a
+	// restarted server has forgotten the key, so the trigger is not repeated.
+	client, _ := newRetryTestJobsClient(srv.URL, 2)
+	var run jobsV2Run
+	if err := client.doIdempotent(context.Background(), http.MethodPost, "/v2/jobs/job_1/trigger", nil, &run); err == nil {
+		t.Fatal("doIdempotent succeeded, want the 502")
+	}
+	if calls.Load() != 1 || keys[0] == "" {
+		t.Fatalf("trigger sent %d times with keys %q, want once with a key", calls.Load(), keys)
+	}
+
+	// A refused connection never reached the server, so it is retried.
+	srv.Close()
+	client, stderr := newRetryTestJobsClient(srv.URL, 2)
+	if err := client.doIdempotent(context.Background(), http.MethodPost, "/v2/jobs/job_1/trigger", nil, &run); err == nil {
+		t.Fatal("doIdempotent against a stopped server succeeded")
+	}
+	if n := strings.Count(stderr.String(), "retrying in"); n != 2 {
+		t.Fatalf("stderr has %d retry notices, want 2: %q", n, stderr.String())
+	}
+}
+
+func TestJobsClientDoesNotRetryUnsafeOrClientErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		status int
+	}{
+		{name: "create POST", method: http.MethodPost, status: http.StatusServiceUnavailable},
+		{name: "GET 404", method: http.MethodGet, status: http.StatusNotFound},
+		{name: "GET 500", method: http.MethodGet, status: http.StatusInternalServerError},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(tc.status)
+			}))
+			defer srv.Close()
+
+			client, stderr := newRetryTestJobsClient(srv.URL, 3)
+			if err := client.do(context.Background(), tc.method, "/v2/jobs", nil, nil); err == nil {
+				t.Fatal("expected an error")
+			}
+			if calls.Load() != 1 || stderr.Len() != 0 {
+				t.Fatalf("calls = %d, stderr = %q; want a single attempt", calls.Load(), stderr.String())
+			}
+		})
+	}
+}
+
+func TestJobsClientRetriesConnectionRefused(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	client, stderr := newRetryTestJobsClient(url, 1)
+	if err := client.do(context.Background(), http.MethodGet, "/v2/jobs", nil, nil); err == nil {
+		t.Fatal("expected connection error")
+	}
+	if n := strings.Count(stderr.String(), "retrying in"); n != 1 {
+		t.Fatalf("stderr has %d retry notices, want 1: %q", n, stderr.String())
+	}
+}
+
+func TestJobsClientUnixSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "term-llm.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/jobs" || r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("request = %s %s (auth %q)", r.Method, r.URL.Path, r.Header.Get("Authorization"))
+		}
+		_, _ = w.Write([]byte(`{"data":[{"id":"job_1","name":"nightly"}]}`))
+	}))
+	srv.Listener = listener
+	srv.Start()
+	defer srv.Close()
+
+	oldServer, oldToken, oldRetries := jobsServerURL, jobsToken, jobsRetries
+	defer func() { jobsServerURL, jobsToken, jobsRetries = oldServer, oldToken, oldRetries }()
+	jobsServerURL, jobsToken, jobsRetries = "unix://"+socketPath, "secret", 0
+
+	c, err := newJobsClient()
+	if err != nil {
+		t.Fatalf("newJobsClient: %v", err)
+	}
+	jobs, err := c.listJobs(context.Background())
+	if err != nil {
+		t.Fatalf("listJobs over unix socket: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].ID != "job_1" {
+		t.Fatalf("jobs = %+v", jobs)
+	}
+
+	jobsServerURL = "unix://" + filepath.Join(t.TempDir(), "missing.sock")
+	c, err = newJobsClient()
+	if err != nil {
+		t.Fatalf("newJobsClient: %v", err)
+	}
+	if _, err := c.listJobs(context.Background()); err == nil || !strings.Contains(err.Error(), "not found — is term-llm serve running?") {
+		t.Fatalf("listJobs on a missing socket = %v, want a hint to start serve", err)
+	}
+
+	for _, bad := range []string{"unix://", "ftp://example.com"} {
+		jobsServerURL = bad
+		if _, err := newJobsClient(); err == nil {
+			t.Fatalf("newJobsClient(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestParseJobsRunsFilter(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	filter, err := parseJobsRunsFilter("timeout, failed,Canceled", true, "7d", "90m", now)
+	if err != nil {
+		t.Fatalf("parseJobsRunsFilter failed: %v", err)
+	}
+	wantStatuses := []jobsV2RunStatus{jobsV2RunFailed, jobsV2RunTimedOut, jobsV2RunCancelled}
+	if !reflect.DeepEqual(filter.Statuses, wantStatuses) {
+		t.Fatalf("statuses = %v, want %v", filter.Statuses, wantStatuses)
+	}
+	if !filter.Since.Equal(now.AddDate(0, 0, -7)) {
+		t.Fatalf("since = %v", filter.Since)
+	}
+	if !filter.Until.Equal(now.Add(-90 * time.Minute)) {
+		t.Fatalf("until = %v", filter.Until)
+	}
+
+	filter, err = parseJobsRunsFilter("", false, "2026-03-01T00:00:00Z", "", now)
+	if err != nil {
+		t.Fatalf("parseJobsRunsFilter RFC3339 failed: %v", err)
+	}
+	if !filter.Since.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("since = %v", filter.Since)
+	}
+
+	for _, tc := range []struct {
+		name                   string
+		statuses, since, until string
+	}{
+		{name: "unknown status", statuses: "exploded"},
+		{name: "bad since", since: "last tuesday"},
+		{name: "until before since", since: "1h", until: "2h"},
+	} {
+		if _, err := parseJobsRunsFilter(tc.statuses, false, tc.since, tc.until, now); err == nil {
+			t.Fatalf("%s: expected error", tc.name)
+		}
+	}
+}
+
+func TestFormatJobsRunDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		850 * time.Millisecond:                      "850ms",
+		42 * time.Second:                            "42s",
+		102 * time.Second:                           "1m42s",
+		2*time.Hour + 5*time.Minute + 9*time.Second: "2h5m",
+	}
+	for d, want := range tests {
+		if got := formatJobsRunDuration(d); got != want {
+			t.Fatalf("formatJobsRunDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestRunJobsRuns_FiltersClientSideAndShowsDuration(t *testing.T) {
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/runs" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		query = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		// Answer like a server that predates the filter parameters.
+		_, _ = w.Write([]byte(`{"data":[
+			{"id":"run_failed","job_id":"job_a","status":"failed","trigger":"manual","scheduled_for":"2026-03-10T11:00:00Z","started_at":"2026-03-10T11:00:00Z","finished_at":"2026-03-10T11:01:42Z","created_at":"2026-03-10T11:00:00Z"},
+			{"id":"run_ok","job_id":"job_a","status":"succeeded","trigger":"manual","scheduled_for":"2026-03-10T10:00:00Z","started_at":"2026-03-10T10:00:00Z","finished_at":"2026-03-10T10:00:05Z","created_at":"2026-03-10T10:00:00Z"}
+		]}`))
+	}))
+	defer srv.Close()
+
+	oldServerURL, oldToken, oldTimeout, oldJSON := jobsServerURL, jobsToken, jobsTimeout, jobsJSON
+	oldStatus, oldFailed, oldSince, oldUntil := jobsRunsStatus, jobsRunsFailed, jobsRunsSince, jobsRunsUntil
+	jobsServerURL = srv.URL
+	jobsToken = ""
+	jobsTimeout = 2 * time.Second
+	jobsRunsFailed = true
+	jobsRunsStatus, jobsRunsSince, jobsRunsUntil = "", "", ""
+	t.Cleanup(func() {
+		jobsServerURL, jobsToken, jobsTimeout, jobsJSON = oldServerURL, oldToken, oldTimeout, oldJSON
+		jobsRunsStatus, jobsRunsFailed, jobsRunsSince, jobsRunsUntil = oldStatus, oldFailed, oldSince, oldUntil
+	})
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+
+	jobsJSON = true
+	var runErr error
+	output := captureStdout(t, func() {
+		runErr = runJobsRuns(cmd, nil)
+	})
+	if runErr != nil {
+		t.Fatalf("runJobsRuns failed: %v", runErr)
+	}
+	if got := query.Get("status"); got != "failed,timed_out" {
+		t.Fatalf("status query = %q, want failed,timed_out", got)
+	}
+	var items []jobsRunListItem
+	if err := json.Unmarshal([]byte(output), &items); err != nil {
+		t.Fatalf("decode output: %v\noutput=%s", err, output)
+	}
+	if len(items) != 1 || items[0].ID != "run_failed" {
+		t.Fatalf("items = %+v, want only run_failed", items)
+	}
+	if items[0].DurationMS == nil || *items[0].DurationMS != 102000 {
+		t.Fatalf("duration_ms = %v, want 102000", items[0].DurationMS)
+	}
+
+	jobsJSON = false
+	output = captureStdout(t, func() {
+		runErr = runJobsRuns(cmd, nil)
+	})
+	if runErr != nil {
+		t.Fatalf("runJobsRuns table failed: %v", runErr)
+	}
+	if !strings.Contains(output, "DURATION") || !strings.Contains(output, "1m42s") || strings.Contains(output, "run_ok") {
+		t.Fatalf("unexpected table output:\n%s", output)
+	}
+
+	jobsRunsFailed = false
+	jobsRunsStatus = "skipped"
+	output = captureStdout(t, func() {
+		runErr = runJobsRuns(cmd, nil)
+	})
+	if runErr != nil {
+		t.Fatalf("runJobsRuns empty failed: %v", runErr)
+	}
+	if strings.TrimSpace(output) != "No runs found matching status skipped." {
+		t.Fatalf("empty output = %q", output)
+	}
+}
@@ -0,0 +1,348 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/jobs"
+	"gopkg.in/yaml.v3"
+)
+
+// jobPayloadProblem is one issue found by validateJobPayload. Line is the
+// 1-based source line of the field, or 0 when it is unknown.
+type jobPayloadProblem struct {
+	Field   string
+	Line    int
+	Message string
+}
+
+func (p jobPayloadProblem) String() string {
+	var b strings.Builder
+	if p.Line > 0 {
+		fmt.Fprintf(&b, "line %d: ", p.Line)
+	}
+	if p.Field != "" {
+		b.WriteString(p.Field)
+		b.WriteString(": ")
+	}
+	b.WriteString(p.Message)
+	return b.String()
+}
+
+// validateJobPayload checks a JSON or YAML job definition before it is sent
+// to the server. partial is set for updates, which are merge patches: only
+// the fields present are checked and nothing is required, since the server
+// fills the rest in from the current job. All problems are returned sorted
+// by line, with problems that have no source line (missing fields) last.
+func validateJobPayload(raw []byte, partial bool) []jobPayloadProblem {
+	normalized, err := normalizeJSONPayload(raw)
+	if err != nil {
+		return []jobPayloadProblem{{Message: err.Error()}}
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(normalized, &fields); err != nil {
+		return []jobPayloadProblem{{Message: "job definition must be an object"}}
+	}
+
+	lines := yamlFieldLines(raw)
+	var problems []jobPayloadProblem
+	add := func(field, format string, args ...any) {
+		problems = append(problems, jobPayloadProblem{Field: field, Line: lines[field], Message: fmt.Sprintf(format, args...)})
+	}
+
+	for name := range jobReadOnlyFields() {
+		delete(fields, name)
+	}
+	checkUnknownFields("", fields, reflect.TypeOf(jobsV2JobRequest{}), add)
+
+	var req jobsV2JobRequest
+	if err := json.Unmarshal(normalized, &req); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			add(typeErr.Field, "must be %s, got %s", typeErr.Type, typeErr.Value)
+		} else {
+			add("", "%v", err)
+		}
+		return sortJobPayloadProblems(problems)
+	}
+
+	if !partial {
+		if strings.TrimSpace(req.Name) == "" {
+			add("name", "is required")
+		}
+		if req.RunnerType == "" {
+			add("runner_type", "is required (llm or program)")
+		}
+		if req.TriggerType == "" {
+			add("trigger_type", "is required (manual, once or cron)")
+		}
+	}
+
+	_, hasRunnerConfig := fields["runner_config"]
+	switch req.RunnerType {
+	case "":
+	case jobsV2RunnerLLM, jobsV2RunnerProgram:
+		if hasRunnerConfig || !partial {
+			checkRunnerConfig(req.RunnerType, req.RunnerConfig, partial, add)
+		}
+	default:
+		add("runner_type", "must be one of: llm, program (got %q)", req.RunnerType)
+	}
+
+	_, hasTriggerConfig := fields["trigger_config"]
+	switch req.TriggerType {
+	case "":
+		if hasTriggerConfig {
+			checkTriggerConfig("", req.TriggerConfig, req.ScheduleTimezone, add)
+		}
+	case jobsV2TriggerManual, jobsV2TriggerOnce, jobsV2TriggerCron:
+		if partial {
+			if hasTriggerConfig {
+				checkTriggerConfig("", req.TriggerConfig, req.ScheduleTimezone, add)
+			}
+		} else {
+			checkTriggerConfig(req.TriggerType, req.TriggerConfig, req.ScheduleTimezone, add)
+		}
+	default:
+		add("trigger_type", "must be one of: manual, once, cron (got %q)", req.TriggerType)
+	}
+
+	if policy := strings.TrimSpace(req.MisfirePolicy); policy != "" {
+		if err := validateJobsV2MisfirePolicy(policy); err != nil {
+			add("misfire_policy", "must be one of: skip, run (got %q)", policy)
+		}
+	}
+	if policy := strings.TrimSpace(req.OverlapPolicy); policy != "" {
+		if err := validateJobsV2OverlapPolicy(policy); err != nil {
+			add("overlap_policy", "must be one of: skip, queue, cancel_previous (got %q)", policy)
+		}
+	}
+	return sortJobPayloadProblems(problems)
+}
+
+// jobReadOnlyFields are the fields jobs get --json emits that a job
+// definition cannot set, such as id and last_run. The server ignores them, so
+// a definition saved from jobs get can be sent back unchanged.
+func jobReadOnlyFields() map[string]bool {
+	editable := jsonFieldNames(reflect.TypeOf(jobsV2JobRequest{}))
+	readOnly := make(map[string]bool)
+	for name := range jsonFieldNames(reflect.TypeOf(jobsV2Job{})) {
+		if !editable[name] {
+			readOnly[name] = true
+		}
+	}
+	return readOnly
+}
+
+// checkRunnerConfig validates runner_config for runnerType. partial skips
+// the required-field checks, as an update's runner_config is merged into the
+// job's current one.
+func checkRunnerConfig(runnerType jobsV2RunnerType, raw json.RawMessage, partial bool, add func(field, format string, args ...any)) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(stringOrEmptyRaw(raw, "{}")), &fields); err != nil {
+		add("runner_config", "must be an object")
+		return
+	}
+	switch runnerType {
+	case jobsV2RunnerLLM:
+		checkUnknownFields("runner_config", fields, reflect.TypeOf(jobsV2LLMConfig{}), add)
+		var cfg jobsV2LLMConfig
+		if err := json.Unmarshal([]byte(stringOrEmptyRaw(raw, "{}")), &cfg); err != nil {
+			add("runner_config", "%v", err)
+			return
+		}
+		for field, value := range map[string]string{"agent_name": cfg.AgentName, "instructions": cfg.Instructions, "cwd": cfg.Cwd} {
+			if !partial && strings.TrimSpace(value) == "" {
+				add("runner_config."+field, "is required for llm jobs")
+			}
+		}
+		if err := validateJobsV2Webhooks(cfg.Notifications); err != nil {
+			field, message, _ := strings.Cut(err.Error(), " ")
+			add("runner_config."+field, "%s", message)
+		}
+	case jobsV2RunnerProgram:
+		checkUnknownFields("runner_config", fields, reflect.TypeOf(jobs.ProgramConfig{}), add)
+		var cfg jobs.ProgramConfig
+		if err := json.Unmarshal([]byte(stringOrEmptyRaw(raw, "{}")), &cfg); err != nil {
+			add("runner_config", "%v", err)
+			return
+		}
+		if !partial && strings.TrimSpace(cfg.Command) == "" {
+			add("runner_config.command", "is required for program jobs")
+		}
+	}
+}
+
+// checkTriggerConfig validates trigger_config for triggerType. An empty
+// triggerType (an update, merged into the current trigger) only checks field
+// names and the values present.
+func checkTriggerConfig(triggerType jobsV2TriggerType, raw json.RawMessage, scheduleTZ string, add func(field, format string, args ...any)) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(stringOrEmptyRaw(raw, "{}")), &fields); err != nil {
+		add("trigger_config", "must be an object")
+		return
+	}
+	checkUnknownFields("trigger_config", fields, reflect.TypeOf(jobsV2TriggerConfig{}), add)
+	var cfg jobsV2TriggerConfig
+	if err := json.Unmarshal([]byte(stringOrEmptyRaw(raw, "{}")), &cfg); err != nil {
+		add("trigger_config", "%v", err)
+		return
+	}
+
+	switch triggerType {
+	case jobsV2TriggerOnce:
+		if strings.TrimSpace(cfg.RunAt) == "" {
+			add("trigger_config.run_at", "is required for once jobs (RFC3339 timestamp)")
+			return
+		}
+		if _, err := time.Parse(time.RFC3339, cfg.RunAt); err != nil {
+			add("trigger_config.run_at", "must be an RFC3339 timestamp, e.g. 2026-01-02T15:04:05Z")
+		}
+	case jobsV2TriggerCron:
+		if strings.TrimSpace(cfg.Expression) == "" {
+			add("trigger_config.expression", "is required for cron jobs, e.g. \"0 9 * * 1-5\"")
+		} else if _, err := parseCronExpression(cfg.Expression); err != nil {
+			add("trigger_config.expression", "invalid cron expression %q: %v", cfg.Expression, err)
+		}
+		tz := effectiveCronTimezone(cfg.Timezone, scheduleTZ)
+		if tz == "" {
+			add("trigger_config.timezone", "is required for cron jobs (or set schedule_timezone)")
+		} else if _, err := time.LoadLocation(tz); err != nil {
+			add("trigger_config.timezone", "unknown timezone %q", tz)
+		}
+	case "":
+		if strings.TrimSpace(cfg.Expression) != "" {
+			if _, err := parseCronExpression(cfg.Expression); err != nil {
+				add("trigger_config.expression", "invalid cron expression %q: %v", cfg.Expression, err)
+			}
+		}
+		if strings.TrimSpace(cfg.RunAt) != "" {
+			if _, err := time.Parse(time.RFC3339, cfg.RunAt); err != nil {
+				add("trigger_config.run_at", "must be an RFC3339 timestamp, e.g. 2026-01-02T15:04:05Z")
+			}
+		}
+		if tz := strings.TrimSpace(cfg.Timezone); tz != "" {
+			if _, err := time.LoadLocation(tz); err != nil {
+				add("trigger_config.timezone", "unknown timezone %q", tz)
+			}
+		}
+	}
+}
+
+// checkUnknownFields reports keys of fields that are not JSON fields of
+// typ, suggesting the closest known name for likely typos.
+func checkUnknownFields(prefix string, fields map[string]json.RawMessage, typ reflect.Type, add func(field, format string, args ...any)) {
+	known := jsonFieldNames(typ)
+	for name := range fields {
+		if known[name] {
+			continue
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		if suggestion := closestFieldName(name, known); suggestion != "" {
+			add(path, "unknown field (did you mean %s?)", suggestion)
+		} else {
+			add(path, "unknown field")
+		}
+	}
+}
+
+func jsonFieldNames(typ reflect.Type) map[string]bool {
+	names := make(map[string]bool, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+func closestFieldName(name string, known map[string]bool) string {
+	best, bestDist := "", max(2, len(name)/3)+1
+	for candidate := range known {
+		if d := fieldNameDistance(name, candidate); d < bestDist || (d == bestDist && candidate < best) {
+			best, bestDist = candidate, d
+		}
+	}
+	return best
+}
+
+// fieldNameDistance is the Levenshtein distance between a and b.
+func fieldNameDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// yamlFieldLines maps dotted field paths to their source line. YAML is a
+// superset of JSON, so this works for both payload formats.
+func yamlFieldLines(raw []byte) map[string]int {
+	lines := make(map[string]int)
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil || len(doc.Content) == 0 {
+		return lines
+	}
+	var walk func(prefix string, node *yaml.Node)
+	walk = func(prefix string, node *yaml.Node) {
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			path := key.Value
+			if prefix != "" {
+				path = prefix + "." + key.Value
+			}
+			lines[path] = key.Line
+			walk(path, node.Content[i+1])
+		}
+	}
+	walk("", doc.Content[0])
+	return lines
+}
+
+func sortJobPayloadProblems(problems []jobPayloadProblem) []jobPayloadProblem {
+	sort.SliceStable(problems, func(i, j int) bool {
+		li, lj := problems[i].Line, problems[j].Line
+		if li != lj {
+			return lj == 0 || (li != 0 && li < lj)
+		}
+		return problems[i].Field < problems[j].Field
+	})
+	return problems
+}
+
+// jobPayloadValidationError formats problems as a single error listing each
+// one on its own line.
+func jobPayloadValidationError(problems []jobPayloadProblem) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "job definition has %d problem(s):", len(problems))
+	for _, p := range problems {
+		b.WriteString("\n  ")
+		b.WriteString(p.String())
+	}
+	b.WriteString("\n(use --no-validate to send it anyway)")
+	return errors.New(b.String())
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateJobPayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		partial bool
+		want    []string // substrings, one per expected problem, in order
+	}{
+		{
+			name:    "valid manual program job",
+			payload: "name: build\nrunner_type: program\ntrigger_type: manual\nrunner_config:\n  command: make\n",
+		},
+		{
+			name:    "valid cron llm job as JSON",
+			payload: `{"name":"nightly","runner_type":"llm","trigger_type":"cron","runner_config":{"agent_name":"a","instructions":"i","cwd":"/tmp"},"trigger_config":{"expression":"0 0 * * *","timezone":"UTC"}}`,
+		},
+		{
+			name:    "read-only fields from jobs get --json",
+			payload: `{"id":"job_1","name":"build","enabled":true,"runner_type":"program","trigger_type":"manual","runner_config":{"command":"make"},"trigger_config":{},"next_run_at":"2026-01-02T15:04:05Z","last_run":{"id":"run_1"},"created_at":"2026-01-01T00:00:00Z","updated_at":"2026-01-01T00:00:00Z"}`,
+		},
+		{
+			name:    "llm webhook with unknown event",
+			payload: "name: nightly\nrunner_type: llm\ntrigger_type: manual\nrunner_config:\n  agent_name: a\n  instructions: i\n  cwd: /tmp\n  notifications:\n    - url: https://hooks.slack.com/services/x\n      events: [on_error]\n",
+			want:    []string{`runner_config.notifications[0].events: must contain only on_failure, on_success, on_timeout (got "on_error")`},
+		},
+		{
+			name:    "unknown field with suggestion and line",
+			payload: "name: build\nrunner_type: program\ntrigger_type: manual\nrunner_cfg:\n  command: make\n",
+			want: []string{
+				"line 4: runner_cfg: unknown field (did you mean runner_config?)",
+				"runner_config.command: is required for program jobs",
+			},
+		},
+		{
+			name:    "unknown nested field",
+			payload: "name: build\nrunner_type: program\ntrigger_type: manual\nrunner_config:\n  command: make\n  argz: [a]\n",
+			want:    []string{"line 6: runner_config.argz: unknown field (did you mean args?)"},
+		},
+		{
+			name:    "cron without expression",
+			payload: "name: build\nrunner_type: program\ntrigger_type: cron\nrunner_config:\n  command: make\ntrigger_config:\n  timezone: UTC\n",
+			want:    []string{"trigger_config.expression: is required for cron jobs"},
+		},
+		{
+			name:    "bad cron syntax",
+			payload: "name: build\nrunner_type: program\ntrigger_type: cron\nrunner_config:\n  command: make\ntrigger_config:\n  expression: \"0 25 * * *\"\n  timezone: UTC\n",
+			want:    []string{`line 7: trigger_config.expression: invalid cron expression "0 25 * * *"`},
+		},
+		{
+			name:    "once without run_at",
+			payload: "name: build\nrunner_type: program\ntrigger_type: once\nrunner_config:\n  command: make\n",
+			want:    []string{"trigger_config.run_at: is required for once jobs"},
+		},
+		{
+			name:    "once with bad run_at",
+			payload: "name: build\nrunner_type: program\ntrigger_type: once\nrunner_config:\n  command: make\ntrigger_config:\n  run_at: tomorrow\n",
+			want:    []string{"line 7: trigger_config.run_at: must be an RFC3339 timestamp"},
+		},
+		{
+			name:    "all problems reported together",
+			payload: "runner_type: llm\ntrigger_type: weekly\nrunner_config:\n  agent_name: a\nmisfire_policy: later\n",
+			want: []string{
+				"line 2: trigger_type: must be one of: manual, once, cron",
+				"line 5: misfire_policy: must be one of: skip, run",
+				"name: is required",
+				"runner_config.cwd: is required for llm jobs",
+				"runner_config.instructions: is required for llm jobs",
+			},
+		},
+		{
+			name:    "bad overlap policy",
+			payload: "overlap_policy: wait\n",
+			partial: true,
+			want:    []string{"line 1: overlap_policy: must be one of: skip, queue, cancel_previous"},
+		},
+		{
+			name:    "wrong type",
+			payload: "name: build\nrunner_type: program\ntrigger_type: manual\ntimeout_seconds: soon\n",
+			want:    []string{"line 4: timeout_seconds: must be int"},
+		},
+		{
+			name:    "partial update needs nothing",
+			payload: "enabled: false\n",
+			partial: true,
+		},
+		{
+			name:    "partial update checks cron expression",
+			payload: "trigger_config:\n  expression: \"* * *\"\n",
+			partial: true,
+			want:    []string{"line 2: trigger_config.expression: invalid cron expression"},
+		},
+		{
+			name:    "partial update merges into the current trigger",
+			payload: `{"trigger_type":"cron","trigger_config":{"expression":"0 3 * * *"}}`,
+			partial: true,
+		},
+		{
+			name:    "partial update checks values present",
+			payload: "runner_type: program\nrunner_config:\n  argz: [a]\ntrigger_config:\n  timezone: Mars/Olympus\n",
+			partial: true,
+			want: []string{
+				"line 3: runner_config.argz: unknown field",
+				`line 5: trigger_config.timezone: unknown timezone "Mars/Olympus"`,
+			},
+		},
+		{
+			name:    "partial update rejects unknown fields",
+			payload: "enabeld: false\n",
+			partial: true,
+			want:    []string{"line 1: enabeld: unknown field (did you mean enabled?)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := validateJobPayload([]byte(tt.payload), tt.partial)
+			if len(problems) != len(tt.want) {
+				t.Fatalf("got %d problems, want %d: %v", len(problems), len(tt.want), problems)
+			}
+			for i, want := range tt.want {
+				if got := problems[i].String(); !strings.Contains(got, want) {
+					t.Errorf("problem %d = %q, want it to contain %q", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestJobPayloadValidationErrorListsProblems(t *testing.T) {
+	err := jobPayloadValidationError([]jobPayloadProblem{
+		{Field: "name", Message: "is required"},
+		{Field: "runner_cfg", Line: 3, Message: "unknown field"},
+	})
+	want := "job definition has 2 problem(s):\n  name: is required\n  line 3: runner_cfg: unknown field\n(use --no-validate to send it anyway)"
+	if err.Error() != want {
+		t.Fatalf("error = %q, want %q", err.Error(), want)
+	}
+}
@@ -803,7 +803,10 @@ func collectToolCallNames(msg session.Message) []string {
 
 func runExtractionRequest(ctx context.Context, engine *llm.Engine, store *memorydb.Store, agent, prompt string, stats *memoryExtractionStats) (memoryExtractionResult, error) {
 	collector := newMemoryExtractionCollector()
-	toolSpecs, cleanup := registerMemoryExtractionTools(engine, store, agent, collector)
+	toolSpecs, cleanup, err := registerMemoryExtractionTools(engine, store, agent, collector)
+	if err != nil {
+		return memoryExtractionResult{}, err
+	}
 	defer cleanup()
 	finalText, err := runExtractionRequestWithSystem(ctx, engine, memoryExtractionSystemPrompt, prompt, stats, toolSpecs...)
 	if err != nil {
@@ -204,7 +204,7 @@ func (t *memoryUpdateFragmentTool) Execute(ctx context.Context, args json.RawMes
 	return llm.TextOutput(fmt.Sprintf("updated %s", fragPath)), nil
 }
 
-func registerMemoryExtractionTools(engine *llm.Engine, store *memorydb.Store, agent string, collector *memoryExtractionCollector) ([]llm.ToolSpec, func()) {
+func registerMemoryExtractionTools(engine *llm.Engine, store *memorydb.Store, agent string, collector *memoryExtractionCollector) ([]llm.ToolSpec, func(), error) {
 	tools := []llm.Tool{
 		&memorySearchFragmentsTool{store: store, agent: agent},
 		&memoryListFragmentsTool{store: store, agent: agent},
@@ -212,15 +212,18 @@ func registerMemoryExtractionTools(engine *llm.Engine, store *memorydb.Store, ag
 		&memoryCreateFragmentTool{store: store, agent: agent, collector: collector},
 		&memoryUpdateFragmentTool{store: store, agent: agent, collector: collector},
 	}
-	specs := make([]llm.ToolSpec, 0, len(tools))
-	for _, tool := range tools {
-		engine.RegisterTool(tool)
-		specs = append(specs, tool.Spec())
-	}
 	cleanup := func() {
 		for _, tool := range tools {
 			engine.UnregisterTool(tool.Spec().Name)
 		}
 	}
-	return specs, cleanup
+	specs := make([]llm.ToolSpec, 0, len(tools))
+	for _, tool := range tools {
+		if err := engine.RegisterTool(tool); err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		specs = append(specs, tool.Spec())
+	}
+	return specs, cleanup, nil
 }
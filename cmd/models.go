@@ -113,84 +113,9 @@ func runModels(cmd *cobra.Command, args []string) error {
 			"Model listing is supported for: anthropic, openai, openrouter, nearai, sambanova, xai, venice, zen, copilot, and openai_compatible providers", providerName, providerType)
 	}
 
-	// Create provider to query models
-	var lister ModelLister
-	switch providerType {
-	case config.ProviderTypeAnthropic:
-		provider, err := llm.NewAnthropicProvider(providerCfg.ResolvedAPIKey, providerCfg.Model, providerCfg.Credentials)
-		if err != nil {
-			return fmt.Errorf("anthropic: %w", err)
-		}
-		lister = provider
-	case config.ProviderTypeOpenAI:
-		apiKey := providerCfg.ResolvedAPIKey
-		if apiKey == "" {
-			apiKey = os.Getenv("OPENAI_API_KEY")
-		}
-		if apiKey == "" {
-			return fmt.Errorf("openai API key not configured. Set OPENAI_API_KEY or configure api_key")
-		}
-		lister = llm.NewOpenAIProvider(apiKey, providerCfg.Model)
-	case config.ProviderTypeCopilot:
-		// Copilot uses OAuth - create provider which will prompt for auth if needed
-		model := ""
-		if ok {
-			model = providerCfg.Model
-		}
-		provider, err := llm.NewCopilotProvider(model)
-		if err != nil {
-			return fmt.Errorf("copilot provider: %w", err)
-		}
-		lister = provider
-	case config.ProviderTypeOpenRouter:
-		apiKey := providerCfg.ResolvedAPIKey
-		if apiKey == "" {
-			apiKey = os.Getenv("OPENROUTER_API_KEY")
-		}
-		if apiKey == "" {
-			return fmt.Errorf("openrouter API key not configured. Set OPENROUTER_API_KEY or configure api_key")
-		}
-		lister = llm.NewOpenRouterProvider(apiKey, "", providerCfg.AppURL, providerCfg.AppTitle)
-	case config.ProviderTypeOpenAICompat, config.ProviderTypeVLLM:
-		if providerCfg.BaseURL == "" {
-			return fmt.Errorf("provider '%s' requires base_url to be configured", providerName)
-		}
-		lister = llm.NewOpenAICompatProvider(providerCfg.BaseURL, providerCfg.ResolvedAPIKey, "", providerName)
-	case config.ProviderTypeZen:
-		lister = llm.NewZenProvider(providerCfg.ResolvedAPIKey, "")
-	case config.ProviderTypeXAI:
-		apiKey := providerCfg.ResolvedAPIKey
-		if apiKey == "" {
-			apiKey = os.Getenv("XAI_API_KEY")
-		}
-		if apiKey == "" {
-			return fmt.Errorf("xAI API key not configured. Set XAI_API_KEY or configure api_key")
-		}
-		lister = llm.NewXAIProvider(apiKey, providerCfg.Model)
-	case config.ProviderTypeVenice:
-		apiKey := providerCfg.ResolvedAPIKey
-		if apiKey == "" {
-			apiKey = os.Getenv("VENICE_API_KEY")
-		}
-		if apiKey == "" {
-			return fmt.Errorf("Venice API key not configured. Set VENICE_API_KEY or configure api_key")
-		}
-		lister = llm.NewVeniceProvider(apiKey, providerCfg.Model)
-	case config.ProviderTypeNearAI:
-		apiKey := providerCfg.ResolvedAPIKey
-		if apiKey == "" {
-			apiKey = os.Getenv("NEARAI_API_KEY")
-		}
-		lister = llm.NewNearAIProvider(apiKey, providerCfg.Model)
-	case config.ProviderTypeSambaNova:
-		apiKey := providerCfg.ResolvedAPIKey
-		if apiKey == "" {
-			apiKey = os.Getenv("SAMBANOVA_API_KEY")
-		}
-		if apiKey == "" {
-			return fmt.Errorf("SambaNova API key not configured. Set SAMBANOVA_API_KEY or configure api_key")
-		}
-		lister = llm.NewSambaNovaProvider(apiKey, providerCfg.Model)
+	lister, err := newModelLister(providerName, providerType, providerCfg, ok)
+	if err != nil {
+		return err
 	}
 
 	// Copilot may need interactive device auth (up to 5 minutes)
@@ -205,6 +130,9 @@ func runModels(cmd *cobra.Command, args []string) error {
 	if err == nil && providerType == config.ProviderTypeOpenRouter {
 		llm.RefreshOpenRouterCacheSync(providerCfg.ResolvedAPIKey, models)
 	}
+	if err == nil {
+		writeCompletionModels(providerName, models)
+	}
 	if err != nil {
 		// Provide helpful error messages for common issues
 		if strings.Contains(err.Error(), "connection refused") {
@@ -265,6 +193,89 @@ func runModels(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// newModelLister creates the provider used to list providerName's models.
+// configured reports whether providerName has an entry in the config.
+func newModelLister(providerName string, providerType config.ProviderType, providerCfg config.ProviderConfig, configured bool) (ModelLister, error) {
+	switch providerType {
+	case config.ProviderTypeAnthropic:
+		provider, err := llm.NewAnthropicProvider(providerCfg.ResolvedAPIKey, providerCfg.Model, providerCfg.Credentials)
+		if err != nil {
+			return nil, fmt.Errorf("anthropic: %w", err)
+		}
+		return provider, nil
+	case config.ProviderTypeOpenAI:
+		apiKey := providerCfg.ResolvedAPIKey
+		if apiKey == "" {
+			apiKey = os.Getenv("OPENAI_API_KEY")
+		}
+		if apiKey == "" {
+			return nil, fmt.Errorf("openai API key not configured. Set OPENAI_API_KEY or configure api_key")
+		}
+		return llm.NewOpenAIProvider(apiKey, providerCfg.Model), nil
+	case config.ProviderTypeCopilot:
+		// Copilot uses OAuth - create provider which will prompt for auth if needed
+		model := ""
+		if configured {
+			model = providerCfg.Model
+		}
+		provider, err := llm.NewCopilotProvider(model)
+		if err != nil {
+			return nil, fmt.Errorf("copilot provider: %w", err)
+		}
+		return provider, nil
+	case config.ProviderTypeOpenRouter:
+		apiKey := providerCfg.ResolvedAPIKey
+		if apiKey == "" {
+			apiKey = os.Getenv("OPENROUTER_API_KEY")
+		}
+		if apiKey == "" {
+			return nil, fmt.Errorf("openrouter API key not configured. Set OPENROUTER_API_KEY or configure api_key")
+		}
+		return llm.NewOpenRouterProvider(apiKey, "", providerCfg.AppURL, providerCfg.AppTitle), nil
+	case config.ProviderTypeOpenAICompat, config.ProviderTypeVLLM:
+		if providerCfg.BaseURL == "" {
+			return nil, fmt.Errorf("provider '%s' requires base_url to be configured", providerName)
+		}
+		return llm.NewOpenAICompatProvider(providerCfg.BaseURL, providerCfg.ResolvedAPIKey, "", providerName), nil
+	case config.ProviderTypeZen:
+		return llm.NewZenProvider(providerCfg.ResolvedAPIKey, ""), nil
+	case config.ProviderTypeXAI:
+		apiKey := providerCfg.ResolvedAPIKey
+		if apiKey == "" {
+			apiKey = os.Getenv("XAI_API_KEY")
+		}
+		if apiKey == "" {
+			return nil, fmt.Errorf("xAI API key not configured. Set XAI_API_KEY or configure api_key")
+		}
+		return llm.NewXAIProvider(apiKey, providerCfg.Model), nil
+	case config.ProviderTypeVenice:
+		apiKey := providerCfg.ResolvedAPIKey
+		if apiKey == "" {
+			apiKey = os.Getenv("VENICE_API_KEY")
+		}
+		if apiKey == "" {
+			return nil, fmt.Errorf("Venice API key not configured. Set VENICE_API_KEY or configure api_key")
+		}
+		return llm.NewVeniceProvider(apiKey, providerCfg.Model), nil
+	case config.ProviderTypeNearAI:
+		apiKey := providerCfg.ResolvedAPIKey
+		if apiKey == "" {
+			apiKey = os.Getenv("NEARAI_API_KEY")
+		}
+		return llm.NewNearAIProvider(apiKey, providerCfg.Model), nil
+	case config.ProviderTypeSambaNova:
+		apiKey := providerCfg.ResolvedAPIKey
+		if apiKey == "" {
+			apiKey = os.Getenv("SAMBANOVA_API_KEY")
+		}
+		if apiKey == "" {
+			return nil, fmt.Errorf("SambaNova API key not configured. Set SAMBANOVA_API_KEY or configure api_key")
+		}
+		return llm.NewSambaNovaProvider(apiKey, providerCfg.Model), nil
+	}
+	return nil, fmt.Errorf("provider '%s' (type: %s) does not support model listing", providerName, providerType)
+}
+
 // printStaticModels prints a static list of models for providers without a ListModels API
 func printStaticModels(providerName string, models []string) error {
 	if modelsJSON {
@@ -1,19 +1,25 @@
 package cmd
 
 import (
+	"fmt"
+
 	"github.com/samsaffron/term-llm/internal/agents"
 	"github.com/samsaffron/term-llm/internal/llm"
 	"github.com/samsaffron/term-llm/internal/tools"
 )
 
-func registerAgentOutputTool(cfg agents.OutputToolConfig, toolMgr *tools.ToolManager, engine *llm.Engine) *tools.SetOutputTool {
+func registerAgentOutputTool(cfg agents.OutputToolConfig, toolMgr *tools.ToolManager, engine *llm.Engine) (*tools.SetOutputTool, error) {
 	outputTool := tools.NewSetOutputTool(cfg.Name, cfg.Param, cfg.Description, cfg.Schema)
+	if err := llm.ValidateToolSpec(outputTool.Spec()); err != nil {
+		return nil, fmt.Errorf("output_tool: %w", err)
+	}
 	if toolMgr == nil {
-		engine.RegisterTool(outputTool)
-		return outputTool
+		return outputTool, engine.RegisterTool(outputTool)
 	}
 
 	toolMgr.Registry.RegisterOutputTool(outputTool)
-	toolMgr.SetupEngine(engine)
-	return outputTool
+	if err := toolMgr.SetupEngine(engine); err != nil {
+		return nil, err
+	}
+	return outputTool, nil
 }
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"strings"
 	"time"
 
@@ -233,7 +234,9 @@ func runProgressiveSession(ctx context.Context, engine *llm.Engine, req llm.Requ
 
 	updateTool := toolpkg.NewUpdateProgressTool()
 	finalizeTool := toolpkg.NewFinalizeProgressTool()
-	engine.RegisterTool(updateTool)
+	if err := engine.RegisterTool(updateTool); err != nil {
+		return progressiveRunResult{}, err
+	}
 	defer engine.UnregisterTool(updateTool.Spec().Name)
 
 	history := append([]llm.Message(nil), req.Messages...)
@@ -445,7 +448,10 @@ func attemptProgressiveFinalization(parentCtx context.Context, engine *llm.Engin
 
 	if tracker.latest != nil {
 		// State has been accumulated — ask model to write prose then call finalize_progress.
-		engine.RegisterTool(finalizeTool)
+		if err := engine.RegisterTool(finalizeTool); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: progressive finalization skipped: %v\n", err)
+			return false, ""
+		}
 		defer engine.UnregisterTool(finalizeTool.Spec().Name)
 		finalReq.Tools = []llm.ToolSpec{finalizeTool.Spec()}
 		finalReq.ToolChoice = llm.ToolChoice{Mode: llm.ToolChoiceAuto}
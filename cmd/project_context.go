@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/samsaffron/term-llm/internal/agents"
+	"github.com/samsaffron/term-llm/internal/config"
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+// newProjectContext returns the project instruction files (agents_md) to
+// inject for a run rooted at dir, the process working directory when empty.
+// It returns nil when agents_md is disabled or the resolved system prompt
+// already includes the project instructions.
+func newProjectContext(cfg *config.Config, settings SessionSettings, dir string) *agents.ProjectContext {
+	if cfg == nil || !cfg.AgentsMd.Enabled || settings.ProjectInstructions {
+		return nil
+	}
+	if strings.TrimSpace(dir) == "" {
+		cwd, err := systemPromptCWDBaseDir()
+		if err != nil {
+			return nil
+		}
+		dir = cwd
+	}
+	return agents.NewProjectContext(dir, cfg.AgentsMd.Files, cfg.AgentsMd.MaxChars)
+}
+
+// attachProjectContext makes engine append pc to the system prompt of every
+// request. The files are read per request, so edits apply on the next turn.
+func attachProjectContext(engine *llm.Engine, pc *agents.ProjectContext) {
+	if engine == nil || pc == nil {
+		return
+	}
+	engine.SetSystemContext(pc.SystemText)
+}
+
+// appendProjectContext adds pc to a system prompt that is sent once, for
+// commands that call the provider without an engine.
+func appendProjectContext(systemPrompt string, pc *agents.ProjectContext) string {
+	text := pc.SystemText()
+	if text == "" {
+		return systemPrompt
+	}
+	if strings.TrimSpace(systemPrompt) == "" {
+		return text
+	}
+	return strings.TrimRight(systemPrompt, "\n") + "\n\n" + text
+}
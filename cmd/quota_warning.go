@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/config"
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+// quotaWarningTimeout bounds the background quota lookup. Callers never
+// wait on it before a request: ask prints the warning after the run and chat
+// shows it in the footer once it arrives.
+const quotaWarningTimeout = 3 * time.Second
+
+// quotaNotice is a background quota lookup started by startQuotaWarning.
+type quotaNotice struct {
+	done    chan struct{}
+	warning string
+}
+
+// startQuotaWarning begins a background quota lookup for providers that report
+// plan quota. Lookup failures are swallowed: quota reporting is advisory and
+// must never block or fail a request.
+func startQuotaWarning(ctx context.Context, cfg *config.Config, provider llm.Provider) *quotaNotice {
+	n := &quotaNotice{done: make(chan struct{})}
+	reporter, ok := provider.(llm.QuotaReporter)
+	if !ok || cfg == nil || !cfg.Quota.Warn {
+		close(n.done)
+		return n
+	}
+	threshold := cfg.Quota.WarnThreshold
+	go func() {
+		defer close(n.done)
+		lookupCtx, cancel := context.WithTimeout(ctx, quotaWarningTimeout)
+		defer cancel()
+		report, err := reporter.GetUsage(lookupCtx)
+		if err == nil && lookupCtx.Err() == nil {
+			n.warning = formatQuotaWarning(report, threshold)
+		}
+	}()
+	return n
+}
+
+// Poll returns the warning line if the lookup has finished, without waiting.
+func (n *quotaNotice) Poll() string {
+	select {
+	case <-n.done:
+		return n.warning
+	default:
+		return ""
+	}
+}
+
+// Wait blocks until the lookup finishes or times out and returns the warning
+// line (or "").
+func (n *quotaNotice) Wait() string {
+	<-n.done
+	return n.warning
+}
+
+// formatQuotaWarning returns a warning line when the report's lowest premium
+// quota is at or below threshold percent remaining.
+func formatQuotaWarning(report *llm.QuotaReport, threshold float64) string {
+	if threshold <= 0 {
+		return ""
+	}
+	lowest := report.LowestPremium()
+	if lowest == nil || lowest.PercentRemaining > threshold {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "warning: %s %s quota low: %.1f%% remaining", quotaProviderLabel(report.Provider), lowest.Name, lowest.PercentRemaining)
+	if lowest.Entitlement > 0 {
+		fmt.Fprintf(&b, " (%s/%s)", formatQuotaNumber(lowest.Remaining), formatQuotaNumber(lowest.Entitlement))
+	}
+	if !lowest.ResetAt.IsZero() {
+		fmt.Fprintf(&b, ", resets %s", lowest.ResetAt.Local().Format("2006-01-02 15:04"))
+	}
+	return b.String()
+}
+
+func quotaProviderLabel(provider string) string {
+	switch provider {
+	case "copilot":
+		return "Copilot"
+	case "chatgpt":
+		return "ChatGPT"
+	default:
+		return provider
+	}
+}
+
+func formatQuotaNumber(v float64) string {
+	if v == float64(int64(v)) {
+		return fmt.Sprintf("%d", int64(v))
+	}
+	return fmt.Sprintf("%.1f", v)
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/config"
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+type fakeQuotaProvider struct {
+	llm.Provider
+	report  *llm.QuotaReport
+	err     error
+	release chan struct{}
+}
+
+func (p fakeQuotaProvider) GetUsage(context.Context) (*llm.QuotaReport, error) {
+	if p.release != nil {
+		<-p.release
+	}
+	return p.report, p.err
+}
+
+func TestFormatQuotaWarning(t *testing.T) {
+	report := &llm.QuotaReport{
+		Provider: "copilot",
+		Quotas: []llm.QuotaEntry{
+			{Name: "chat", Unlimited: true, Premium: false},
+			{Name: "premium_interactions", Entitlement: 300, Remaining: 24, PercentRemaining: 8, Premium: true},
+		},
+	}
+	tests := []struct {
+		name      string
+		threshold float64
+		want      string
+	}{
+		{name: "below threshold", threshold: 10, want: "Copilot premium_interactions quota low: 8.0% remaining (24/300)"},
+		{name: "above threshold", threshold: 5, want: ""},
+		{name: "disabled threshold", threshold: 0, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatQuotaWarning(report, tt.threshold)
+			if tt.want == "" {
+				if got != "" {
+					t.Fatalf("formatQuotaWarning = %q, want empty", got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Fatalf("formatQuotaWarning = %q, want substring %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartQuotaWarningSwallowsErrors(t *testing.T) {
+	cfg := &config.Config{Quota: config.QuotaConfig{Warn: true, WarnThreshold: 10}}
+	provider := fakeQuotaProvider{err: errors.New("boom")}
+	if got := startQuotaWarning(context.Background(), cfg, provider).Wait(); got != "" {
+		t.Fatalf("warning = %q, want empty on error", got)
+	}
+}
+
+func TestStartQuotaWarningRespectsConfig(t *testing.T) {
+	provider := fakeQuotaProvider{report: &llm.QuotaReport{
+		Provider: "chatgpt",
+		Quotas:   []llm.QuotaEntry{{Name: "primary_window", PercentRemaining: 3, Premium: true}},
+	}}
+	enabled := &config.Config{Quota: config.QuotaConfig{Warn: true, WarnThreshold: 10}}
+	if got := startQuotaWarning(context.Background(), enabled, provider).Wait(); !strings.Contains(got, "ChatGPT primary_window quota low") {
+		t.Fatalf("warning = %q", got)
+	}
+	disabled := &config.Config{Quota: config.QuotaConfig{Warn: false, WarnThreshold: 10}}
+	if got := startQuotaWarning(context.Background(), disabled, provider).Wait(); got != "" {
+		t.Fatalf("warning = %q, want empty when disabled", got)
+	}
+}
+
+func TestStartQuotaWarningPollDoesNotWait(t *testing.T) {
+	release := make(chan struct{})
+	provider := fakeQuotaProvider{release: release, report: &llm.QuotaReport{
+		Provider: "copilot",
+		Quotas:   []llm.QuotaEntry{{Name: "premium_interactions", PercentRemaining: 2, Premium: true}},
+	}}
+	cfg := &config.Config{Quota: config.QuotaConfig{Warn: true, WarnThreshold: 10}}
+	notice := startQuotaWarning(context.Background(), cfg, provider)
+	if got := notice.Poll(); got != "" {
+		t.Fatalf("Poll before the lookup finished = %q, want empty", got)
+	}
+	close(release)
+	if got := notice.Wait(); !strings.Contains(got, "Copilot premium_interactions quota low") {
+		t.Fatalf("Wait = %q", got)
+	}
+	if got := notice.Poll(); got == "" {
+		t.Fatal("Poll after the lookup finished should return the warning")
+	}
+}
+
+func TestWriteQuotaText(t *testing.T) {
+	report := &llm.QuotaReport{
+		Provider: "copilot",
+		Plan:     "individual",
+		ResetAt:  time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		Quotas: []llm.QuotaEntry{
+			{Name: "chat", Unlimited: true},
+			{Name: "premium_interactions", Entitlement: 300, Used: 100, Remaining: 200, PercentRemaining: 66.7},
+		},
+	}
+	var out bytes.Buffer
+	if err := writeQuotaText(&out, report); err != nil {
+		t.Fatalf("writeQuotaText: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{"Copilot Quota", "Plan: individual", "Resets:", "chat", "unlimited", "premium_interactions", "300", "200", "66.7%"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("output missing %q:\n%s", want, got)
+		}
+	}
+}
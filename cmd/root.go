@@ -13,6 +13,7 @@ import (
 
 	"github.com/samsaffron/term-llm/internal/exitcode"
 	pprofserver "github.com/samsaffron/term-llm/internal/pprof"
+	"github.com/samsaffron/term-llm/internal/procutil"
 	"github.com/samsaffron/term-llm/internal/ui"
 	"github.com/samsaffron/term-llm/internal/update"
 	"github.com/spf13/cobra"
@@ -187,19 +188,14 @@ func executeWithArgs(args []string) error {
 }
 
 func detectShell() string {
-	shell := os.Getenv("SHELL")
-	if shell == "" {
-		return "bash"
-	}
-	// Extract shell name from path (e.g., /bin/zsh -> zsh)
-	parts := strings.Split(shell, "/")
-	return parts[len(parts)-1]
+	// Shell name without its path (e.g., /bin/zsh -> zsh, pwsh.exe -> pwsh)
+	return procutil.ShellName(procutil.DefaultShell("bash"))
 }
 
 func executeCommand(command, shell string) error {
 	ui.ShowCommand(command)
 
-	cmd := exec.Command(shell, "-c", command)
+	cmd := procutil.ShellCommand(context.Background(), shell, command)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
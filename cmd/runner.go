@@ -111,6 +111,10 @@ func (r *cmdRunner) Run(ctx context.Context, req runpkg.Request, sink runpkg.Eve
 	if env.req.OnEngineDone != nil {
 		defer env.req.OnEngineDone(env.engine)
 	}
+	if env.req.OnToolExecuted != nil {
+		env.engine.SetToolExecutedCallback(env.req.OnToolExecuted)
+		defer env.engine.SetToolExecutedCallback(nil)
+	}
 
 	collector := &runnerEventCollector{sink: sink}
 	if env.req.Progressive != nil {
@@ -279,7 +283,14 @@ func (r *cmdRunner) prepare(ctx context.Context, req runpkg.Request, sink runpkg
 			toolMgr.Registry.SetPlanStore(store)
 		}
 		if agent != nil && agent.OutputTool.IsConfigured() && req.Platform != runpkg.PlatformChat {
-			registerAgentOutputTool(agent.OutputTool, toolMgr, engine)
+			if _, err := registerAgentOutputTool(agent.OutputTool, toolMgr, engine); err != nil {
+				return nil, err
+			}
+		}
+		// Jobs read the project instructions of their own cwd, like ask and
+		// chat do for the process working directory.
+		if req.Platform == runpkg.PlatformJob {
+			attachProjectContext(engine, newProjectContext(cfg, settings, settings.BaseDir))
 		}
 	}
 	if err := applyChildSkillRuntime(engine, toolMgr, req.ChildSkill); err != nil {
@@ -387,6 +398,9 @@ func (r *cmdRunner) prepare(ctx context.Context, req runpkg.Request, sink runpkg
 		SessionID:                req.SessionID,
 		WorkingDir:               settings.BaseDir,
 		Tools:                    toolSpecs,
+		AllowedTools:             req.AllowedTools,
+		AllowedToolsPresent:      req.AllowedTools != nil,
+		DeniedTools:              req.DeniedTools,
 		ToolChoice:               toolChoice,
 		LastTurnToolChoice:       lastTurnToolChoice,
 		ParallelToolCalls:        true,
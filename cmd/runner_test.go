@@ -114,6 +114,43 @@ func TestCmdRunnerPreparePropagatesWorkingDirToLLMRequest(t *testing.T) {
 	}
 }
 
+func TestCmdRunnerPrepareInjectsJobProjectContext(t *testing.T) {
+	jobDir := t.TempDir()
+	const marker = "instructions-for-this-job"
+	if err := os.WriteFile(filepath.Join(jobDir, "AGENTS.md"), []byte(marker), 0o644); err != nil {
+		t.Fatalf("write AGENTS.md: %v", err)
+	}
+	cfg := &config.Config{
+		DefaultProvider: "mock",
+		Providers: map[string]config.ProviderConfig{
+			"mock": {Model: "mock-model"},
+		},
+		AgentsMd: config.AgentsMdConfig{Enabled: true},
+	}
+	runner := newCmdRunner(cfg, cmdRunnerOptions{}).(*cmdRunner)
+
+	for _, platform := range []string{runpkg.PlatformJob, runpkg.PlatformWeb} {
+		env, err := runner.prepare(context.Background(), runpkg.Request{
+			Platform:         platform,
+			Messages:         []llm.Message{llm.UserText("hello")},
+			ProviderInstance: llm.NewMockProvider("mock"),
+			Cwd:              jobDir,
+			DeferSession:     true,
+		}, eventSinkFunc(nil))
+		if err != nil {
+			t.Fatalf("prepare %s: %v", platform, err)
+		}
+		injected := ""
+		if fn := env.engine.SystemContext(); fn != nil {
+			injected = fn()
+		}
+		env.Close()
+		if got, want := strings.Contains(injected, marker), platform == runpkg.PlatformJob; got != want {
+			t.Errorf("%s: project context injected = %v, want %v (%q)", platform, got, want, injected)
+		}
+	}
+}
+
 func TestCmdRunnerEnsureRunSessionUsesConfiguredBaseDir(t *testing.T) {
 	provider := llm.NewMockProvider("mock")
 	runner := newCmdRunner(&config.Config{}, cmdRunnerOptions{}).(*cmdRunner)
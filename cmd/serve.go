@@ -19,6 +19,7 @@ import (
 	"github.com/samsaffron/term-llm/internal/config"
 	"github.com/samsaffron/term-llm/internal/filetrack"
 	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/metrics"
 	runpkg "github.com/samsaffron/term-llm/internal/run"
 	"github.com/samsaffron/term-llm/internal/serve"
 	servehttp "github.com/samsaffron/term-llm/internal/serve/http"
@@ -70,6 +71,7 @@ var (
 	serveEnableWidgets          bool
 	serveWidgetsDir             string
 	serveResponseTimeout        time.Duration
+	serveDrainTimeout           time.Duration
 	serveHubURL                 string
 	serveHubNodeID              string
 	serveHubNodeName            string
@@ -107,6 +109,7 @@ All HTTP routes are mounted under --base-path (default /ui):
   POST {base}/v1/transcribe
   GET  {base}/v1/models
   GET  {base}/healthz
+  GET  {base}/metrics                (Prometheus; serve.disable_metrics turns it off)
   GET  {base}/                       (web UI)
   GET  {base}/images/:file
 
@@ -122,6 +125,7 @@ Jobs endpoints (also under base-path):
   GET    {base}/v2/runs
   GET    {base}/v2/runs/:id
   GET    {base}/v2/runs/:id/events
+  GET    {base}/v2/runs/:id/logs?stream=stdout|stderr
   POST   {base}/v2/runs/:id/cancel
 
 Use --setup to configure credentials for the selected platforms.`,
@@ -157,6 +161,7 @@ func init() {
 	serveCmd.Flags().BoolVar(&serveEnableWidgets, "enable-widgets", false, "Enable local widget apps proxied under {base}/widgets/<mount>/")
 	serveCmd.Flags().StringVar(&serveWidgetsDir, "widgets-dir", "", "Directory containing widget sub-directories (default: ~/.config/term-llm/widgets)")
 	serveCmd.Flags().DurationVar(&serveResponseTimeout, "response-timeout", defaultServeRequestTimeout, "Maximum duration for API/web response runs before timing out")
+	serveCmd.Flags().DurationVar(&serveDrainTimeout, "drain-timeout", defaultServeDrainTimeout, "On shutdown, how long active response streams get to finish before they are cancelled")
 	serveCmd.Flags().StringVar(&serveHubURL, "hub-url", "", "URL of the term-llm Hub this node belongs to (renders a Back to Hub link in the web UI)")
 	serveCmd.Flags().StringVar(&serveHubNodeID, "hub-node-id", "", "This node's id on the hub (used with --hub-url)")
 	serveCmd.Flags().StringVar(&serveHubNodeName, "hub-node-name", "", "This node's display name on the hub (used with --hub-url)")
@@ -354,6 +359,10 @@ func runServeLegacy(parentCtx context.Context, cmd *cobra.Command, args []string
 	if err != nil {
 		return err
 	}
+	drainTimeout, err := resolveServeDrainTimeout(cmd.Flags().Changed("drain-timeout"), serveDrainTimeout, cfg.Serve.DrainTimeout)
+	if err != nil {
+		return err
+	}
 
 	var agent *agents.Agent
 	if hasWeb || hasAPI || hasTelegram {
@@ -509,9 +518,7 @@ func runServeLegacy(parentCtx context.Context, cmd *cobra.Command, args []string
 			if !resolvedYolo {
 				runtime.toolMgr.ApprovalMgr.IgnoreProjectApprovals = true
 				runtime.toolMgr.ApprovalMgr.DebugApproval = serveDebug
-				runtime.toolMgr.ApprovalMgr.PromptUIFunc = func(path string, isWrite bool, isShell bool, workDir string) (tools.ApprovalResult, error) {
-					return runtime.awaitApproval(path, isWrite, isShell, workDir)
-				}
+				runtime.toolMgr.ApprovalMgr.PromptRequestFunc = runtime.awaitApproval
 			}
 		}
 		runtime.Touch()
@@ -644,6 +651,8 @@ func runServeLegacy(parentCtx context.Context, cmd *cobra.Command, args []string
 				basePath:                serveBasePath,
 				uiTitle:                 resolvedTitle,
 				locationSharingDisabled: locationSharingDisabled,
+				metricsDisabled:         cfg.Serve.DisableMetrics,
+				metricsPublic:           cfg.Serve.PublicMetrics,
 				sidebarSessions:         append([]string(nil), sidebarSessions...),
 				agentName:               agentName,
 				corsOrigins:             append([]string(nil), serveCORSOrigins...),
@@ -652,6 +661,9 @@ func runServeLegacy(parentCtx context.Context, cmd *cobra.Command, args []string
 				enableWidgets:           serveEnableWidgets,
 				widgetsDir:              serveWidgetsDir,
 				responseTimeout:         responseTimeout,
+				drainTimeout:            drainTimeout,
+				replayMaxEvents:         cfg.Serve.ReplayMaxEvents,
+				replayMaxBytes:          cfg.Serve.ReplayMaxBytes,
 				hubURL:                  strings.TrimSpace(serveHubURL),
 				hubNodeID:               strings.TrimSpace(serveHubNodeID),
 				hubNodeName:             strings.TrimSpace(serveHubNodeName),
@@ -672,6 +684,13 @@ func runServeLegacy(parentCtx context.Context, cmd *cobra.Command, args []string
 			}
 			s.jobsV2 = jobsV2
 		}
+		if limits := (serveRateLimits{
+			maxConcurrentRuns:        cfg.Serve.MaxConcurrentRuns,
+			sessionMessagesPerMinute: cfg.Serve.SessionMessagesPerMinute,
+			newSessionsPerMinute:     cfg.Serve.NewSessionsPerMinute,
+		}); limits.enabled() {
+			s.rateLimiter = newServeRateLimiter(limits)
+		}
 		sessionMgr.onEvict = func(rt *serveRuntime) {
 			for _, rid := range rt.getResponseIDs() {
 				s.responseToSession.Delete(rid)
@@ -772,8 +791,19 @@ func runServeLegacy(parentCtx context.Context, cmd *cobra.Command, args []string
 		cancel()
 	}
 
+	// The first signal starts a graceful shutdown; a second one exits
+	// immediately, skipping the drain.
+	stopForce := signal.OnNext(func() {
+		log.Printf("serve: second interrupt, exiting without draining")
+		os.Exit(1)
+	})
+	defer stopForce()
+
 	if s != nil {
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if drainTimeout > 0 {
+			fmt.Fprintf(cmd.ErrOrStderr(), "shutting down: draining active streams for up to %s (interrupt again to exit now)\n", humanDuration(drainTimeout))
+		}
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), drainTimeout+10*time.Second)
 		defer cancel()
 		_ = s.Stop(shutdownCtx)
 	}
@@ -988,6 +1018,8 @@ type serveServerConfig struct {
 	basePath                string // e.g. "/ui" or "/chat", always without trailing slash
 	uiTitle                 string
 	locationSharingDisabled bool
+	metricsDisabled         bool
+	metricsPublic           bool // serve /metrics without auth
 	sidebarSessions         []string
 	agentName               string
 	corsOrigins             []string
@@ -996,6 +1028,9 @@ type serveServerConfig struct {
 	enableWidgets           bool
 	widgetsDir              string
 	responseTimeout         time.Duration
+	drainTimeout            time.Duration
+	replayMaxEvents         int // per-run reconnect replay window; 0 uses the built-in default
+	replayMaxBytes          int
 	// hubURL/hubNodeID/hubNodeName describe the term-llm Hub this node
 	// belongs to. When hubURL is set, the web UI gets window.TERM_LLM_HUB and
 	// renders a Back to Hub link. The hub proxy injects the same context
@@ -1105,12 +1140,14 @@ func normalizeBasePath(raw string) (string, error) {
 type serveServer struct {
 	cfg                     serveServerConfig
 	sessionMgr              *serveSessionManager
+	rateLimiter             *serveRateLimiter // nil when no generation limits are configured
 	jobsV2                  *jobsV2Manager
 	cfgRef                  *config.Config
 	store                   session.Store
 	server                  *http.Server
 	shutdownCh              chan struct{}
 	shutdownOnce            sync.Once
+	drain                   serveDrainState
 	modelsMu                sync.Mutex
 	modelsProviders         map[string]llm.Provider // keyed by provider name
 	modelsCache             map[string]serveModelsCacheEntry
@@ -1154,12 +1191,18 @@ func (s *serveServer) fileTrackStore() *filetrack.Store {
 func (s *serveServer) Start() error {
 	s.shutdownCh = make(chan struct{})
 	s.shutdownOnce = sync.Once{}
+	s.drain.reset()
 	s.skillRunsMu.Lock()
 	s.skillRunsStopping = false
 	s.skillRunsMu.Unlock()
 	s.skillsCacheMu.Lock()
 	s.skillsByDir = nil
 	s.skillsCacheMu.Unlock()
+	if s.sessionMgr != nil {
+		sessionMgr := s.sessionMgr
+		metrics.Default.NewGaugeFunc("term_llm_active_chat_sessions", "Chat sessions held in memory by serve.",
+			func() int64 { return int64(sessionMgr.Len()) })
+	}
 	s.server = &http.Server{
 		Addr:              fmt.Sprintf("%s:%d", s.cfg.host, s.cfg.port),
 		Handler:           s.httpHandler(),
@@ -1200,16 +1243,23 @@ func (s *serveServer) httpHandler() http.Handler {
 	inner := http.NewServeMux()
 
 	inner.HandleFunc("/healthz", s.handleHealth)
+	if !s.cfg.metricsDisabled {
+		if s.cfg.metricsPublic {
+			inner.HandleFunc("/metrics", s.handleMetrics)
+		} else {
+			inner.HandleFunc("/metrics", s.auth(s.handleMetrics))
+		}
+	}
 	inner.HandleFunc("/v1/providers", s.auth(s.cors(s.handleProviders)))
 	inner.HandleFunc("/v1/models", s.auth(s.cors(s.handleModels)))
-	inner.HandleFunc("/v1/responses", s.auth(s.cors(s.handleResponses)))
+	inner.HandleFunc("/v1/responses", s.auth(s.cors(s.drainable(s.handleResponses))))
 	inner.HandleFunc("/v1/responses/", s.auth(s.cors(s.handleResponseByID)))
-	inner.HandleFunc("/v1/chat/completions", s.auth(s.cors(s.handleChatCompletions)))
-	inner.HandleFunc("/v1/messages", s.auth(s.cors(s.handleAnthropicMessages)))
+	inner.HandleFunc("/v1/chat/completions", s.auth(s.cors(s.drainable(s.handleChatCompletions))))
+	inner.HandleFunc("/v1/messages", s.auth(s.cors(s.drainable(s.handleAnthropicMessages))))
 	inner.HandleFunc("/v1/transcribe", s.auth(s.cors(s.handleTranscribe)))
 	if s.jobsV2 != nil {
-		inner.HandleFunc("/v2/jobs", s.auth(s.cors(s.handleJobsV2)))
-		inner.HandleFunc("/v2/jobs/", s.auth(s.cors(s.handleJobV2ByID)))
+		inner.HandleFunc("/v2/jobs", s.auth(s.cors(s.drainable(s.handleJobsV2))))
+		inner.HandleFunc("/v2/jobs/", s.auth(s.cors(s.drainable(s.handleJobV2ByID))))
 		inner.HandleFunc("/v2/runs", s.auth(s.cors(s.handleRunsV2)))
 		inner.HandleFunc("/v2/runs/", s.auth(s.cors(s.handleRunV2ByID)))
 	}
@@ -1281,7 +1331,13 @@ func (s *serveServer) contextWithShutdown(ctx context.Context) (context.Context,
 	return ctx, cancel
 }
 
+// Stop shuts the server down in order: drain active streams (see
+// drainActive), cancel whatever is left, wait for runs and jobs to unwind and
+// persist their state, release the session runtimes, and finally close the
+// HTTP server.
 func (s *serveServer) Stop(ctx context.Context) error {
+	s.drainActive(ctx)
+
 	// Signal all SSE handlers and direct child runs to return immediately so
 	// server shutdown cannot outlive the session store they persist into.
 	s.shutdownOnce.Do(func() {
@@ -1323,7 +1379,6 @@ func (s *serveServer) Stop(ctx context.Context) error {
 		})
 	}
 	run(func() error { return s.stopServeSkillRuns(ctx) })
-	run(func() error { return s.server.Shutdown(ctx) })
 
 	closeFileTrackingStore()
 	s.modelsMu.Lock()
@@ -1343,14 +1398,24 @@ func (s *serveServer) Stop(ctx context.Context) error {
 	}()
 	select {
 	case <-done:
-		close(errCh)
-		for err := range errCh {
-			if err != nil {
-				return err
-			}
-		}
-		return nil
 	case <-ctx.Done():
+		_ = s.server.Close()
 		return ctx.Err()
 	}
+
+	// Cancelled runs save their partial transcripts as they unwind, so the
+	// session history is complete only now.
+	if s.sessionMgr != nil {
+		s.sessionMgr.CloseContext(ctx)
+	}
+	if err := s.server.Shutdown(ctx); err != nil {
+		return err
+	}
+	close(errCh)
+	for err := range errCh {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -22,6 +22,9 @@ type serveApprovalPrompt struct {
 	IsWrite    bool                  `json:"is_write"`
 	IsShell    bool                  `json:"is_shell"`
 	WorkDir    string                `json:"work_dir,omitempty"`
+	ToolName   string                `json:"tool_name,omitempty"`
+	Args       string                `json:"args,omitempty"`
+	Diff       string                `json:"diff,omitempty"`
 	Title      string                `json:"title"`
 	Options    []serveApprovalOption `json:"options"`
 	CreatedAt  int64                 `json:"created_at"`
@@ -45,6 +48,9 @@ type servePendingApproval struct {
 	IsWrite    bool
 	IsShell    bool
 	WorkDir    string
+	ToolName   string
+	Args       string
+	Diff       string
 	Options    []tools.ApprovalOption
 	CreatedAt  time.Time
 	responseC  chan serveApprovalSubmission
@@ -64,6 +70,8 @@ func (p *servePendingApproval) snapshot() serveApprovalPrompt {
 
 	title := "Access Request"
 	switch {
+	case tools.IsURLApprovalTarget(p.Path) && !p.IsShell:
+		title = "Web Fetch Request"
 	case p.IsShell:
 		title = "Shell Command Request"
 	case p.IsWrite:
@@ -78,14 +86,37 @@ func (p *servePendingApproval) snapshot() serveApprovalPrompt {
 		IsWrite:    p.IsWrite,
 		IsShell:    p.IsShell,
 		WorkDir:    p.WorkDir,
+		ToolName:   p.ToolName,
+		Args:       p.Args,
+		Diff:       p.Diff,
 		Title:      title,
 		Options:    options,
 		CreatedAt:  p.CreatedAt.UnixMilli(),
 	}
 }
 
-func (rt *serveRuntime) awaitApproval(target string, isWrite bool, isShell bool, workDir string) (tools.ApprovalResult, error) {
+// serveShellOptions splits the command prefix option for web clients: the
+// plain choice only lasts for the session, and a separate "always" choice
+// saves the prefix for every future session.
+func serveShellOptions(options []tools.ApprovalOption) []tools.ApprovalOption {
+	out := make([]tools.ApprovalOption, 0, len(options)+1)
+	for _, opt := range options {
+		if opt.Choice != tools.ApprovalChoicePrefix {
+			out = append(out, opt)
+			continue
+		}
+		session := opt
+		session.Label = fmt.Sprintf("Allow \"%s\" commands", opt.Prefix)
+		session.Description = fmt.Sprintf("Approve commands starting with %q in any directory (session only)", opt.Prefix)
+		opt.SaveToRepo = true
+		out = append(out, session, opt)
+	}
+	return out
+}
+
+func (rt *serveRuntime) awaitApproval(req tools.ApprovalPrompt) (tools.ApprovalResult, error) {
 	approvalID := "appr_" + randomSuffix()
+	target, isWrite, isShell, workDir := req.Path, req.IsWrite, req.IsShell, req.WorkDir
 
 	var options []tools.ApprovalOption
 	if isShell {
@@ -98,7 +129,9 @@ func (rt *serveRuntime) awaitApproval(target string, isWrite bool, isShell bool,
 		if repoInfo.IsRepo {
 			repoInfoPtr = &repoInfo
 		}
-		options = tools.BuildShellOptions(target, repoInfoPtr)
+		options = serveShellOptions(tools.BuildShellOptions(target, repoInfoPtr))
+	} else if tools.IsURLApprovalTarget(target) {
+		options = tools.BuildURLOptions(target)
 	} else {
 		repoInfo := tools.DetectGitRepo(target)
 		var repoInfoPtr *tools.GitRepoInfo
@@ -132,6 +165,9 @@ func (rt *serveRuntime) awaitApproval(target string, isWrite bool, isShell bool,
 		IsWrite:    isWrite,
 		IsShell:    isShell,
 		WorkDir:    workDir,
+		ToolName:   req.ToolName,
+		Args:       req.Args,
+		Diff:       req.Diff,
 		Options:    options,
 		CreatedAt:  time.Now(),
 		responseC:  make(chan serveApprovalSubmission, 1),
@@ -156,6 +192,15 @@ func (rt *serveRuntime) awaitApproval(target string, isWrite bool, isShell bool,
 	if snap.WorkDir != "" {
 		payload["work_dir"] = snap.WorkDir
 	}
+	if snap.ToolName != "" {
+		payload["tool_name"] = snap.ToolName
+	}
+	if snap.Args != "" {
+		payload["args"] = snap.Args
+	}
+	if snap.Diff != "" {
+		payload["diff"] = snap.Diff
+	}
 	if err := eventFunc("response.approval.prompt", payload); err != nil {
 		return tools.ApprovalResult{}, fmt.Errorf("failed to emit approval event: %w", err)
 	}
@@ -170,6 +215,34 @@ func (rt *serveRuntime) awaitApproval(target string, isWrite bool, isShell bool,
 }
 
 func (rt *serveRuntime) submitApproval(approvalID string, choiceIndex int, cancelled bool) error {
+	return rt.answerApproval(approvalID, func(pending *servePendingApproval) (serveApprovalSubmission, error) {
+		if cancelled {
+			return serveApprovalSubmission{Result: tools.ApprovalResult{
+				Choice:    tools.ApprovalChoiceCancelled,
+				Cancelled: true,
+			}}, nil
+		}
+		if choiceIndex < 0 || choiceIndex >= len(pending.Options) {
+			return serveApprovalSubmission{}, errors.New("choice index out of range")
+		}
+		return serveApprovalSubmission{Result: approvalOptionResult(pending.Options[choiceIndex])}, nil
+	})
+}
+
+// submitApprovalAlwaysAllowDirectory answers with the prompt's "allow this
+// directory" option, matching the local prompt's session-wide grant.
+func (rt *serveRuntime) submitApprovalAlwaysAllowDirectory(approvalID string) error {
+	return rt.answerApproval(approvalID, func(pending *servePendingApproval) (serveApprovalSubmission, error) {
+		for _, opt := range pending.Options {
+			if opt.Choice == tools.ApprovalChoiceDirectory {
+				return serveApprovalSubmission{Result: approvalOptionResult(opt)}, nil
+			}
+		}
+		return serveApprovalSubmission{}, errors.New("approval request has no directory option")
+	})
+}
+
+func (rt *serveRuntime) answerApproval(approvalID string, answer func(*servePendingApproval) (serveApprovalSubmission, error)) error {
 	rt.approvalMu.Lock()
 	pending := rt.pendingApprovals[approvalID]
 	if pending == nil {
@@ -181,24 +254,10 @@ func (rt *serveRuntime) submitApproval(approvalID string, choiceIndex int, cance
 		return errServeApprovalAnswered
 	}
 
-	submission := serveApprovalSubmission{}
-	if cancelled {
-		submission.Result = tools.ApprovalResult{
-			Choice:    tools.ApprovalChoiceCancelled,
-			Cancelled: true,
-		}
-	} else {
-		if choiceIndex < 0 || choiceIndex >= len(pending.Options) {
-			rt.approvalMu.Unlock()
-			return errors.New("choice index out of range")
-		}
-		opt := pending.Options[choiceIndex]
-		submission.Result = tools.ApprovalResult{
-			Choice:     opt.Choice,
-			Path:       opt.Path,
-			Pattern:    opt.Pattern,
-			SaveToRepo: opt.SaveToRepo,
-		}
+	submission, err := answer(pending)
+	if err != nil {
+		rt.approvalMu.Unlock()
+		return err
 	}
 
 	pending.responded = true
@@ -212,6 +271,16 @@ func (rt *serveRuntime) submitApproval(approvalID string, choiceIndex int, cance
 	}
 }
 
+func approvalOptionResult(opt tools.ApprovalOption) tools.ApprovalResult {
+	return tools.ApprovalResult{
+		Choice:     opt.Choice,
+		Path:       opt.Path,
+		Pattern:    opt.Pattern,
+		Prefix:     opt.Prefix,
+		SaveToRepo: opt.SaveToRepo,
+	}
+}
+
 func (rt *serveRuntime) removePendingApproval(approvalID string, pending *servePendingApproval) {
 	rt.approvalMu.Lock()
 	defer rt.approvalMu.Unlock()
@@ -284,6 +353,10 @@ func approvalChoiceName(c tools.ApprovalChoice) string {
 		return "pattern"
 	case tools.ApprovalChoiceCommand:
 		return "command"
+	case tools.ApprovalChoicePrefix:
+		return "prefix"
+	case tools.ApprovalChoiceDomain:
+		return "domain"
 	case tools.ApprovalChoiceCancelled:
 		return "cancelled"
 	default:
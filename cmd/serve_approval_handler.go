@@ -10,6 +10,9 @@ type sessionApprovalRequest struct {
 	ApprovalID string `json:"approval_id"`
 	Choice     *int   `json:"choice"`
 	Cancelled  bool   `json:"cancelled,omitempty"`
+	// AlwaysAllowDirectory picks the prompt's "allow this directory" option
+	// instead of an explicit choice index.
+	AlwaysAllowDirectory bool `json:"always_allow_directory,omitempty"`
 }
 
 func (s *serveServer) handleSessionApproval(w http.ResponseWriter, r *http.Request, sessionID string) {
@@ -25,7 +28,7 @@ func (s *serveServer) handleSessionApproval(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	if !req.Cancelled && req.Choice == nil {
+	if !req.Cancelled && req.Choice == nil && !req.AlwaysAllowDirectory {
 		writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", "choice is required when not cancelled")
 		return
 	}
@@ -39,7 +42,12 @@ func (s *serveServer) handleSessionApproval(w http.ResponseWriter, r *http.Reque
 	if req.Choice != nil {
 		choiceIndex = *req.Choice
 	}
-	err := rt.submitApproval(approvalID, choiceIndex, req.Cancelled)
+	var err error
+	if req.AlwaysAllowDirectory && !req.Cancelled {
+		err = rt.submitApprovalAlwaysAllowDirectory(approvalID)
+	} else {
+		err = rt.submitApproval(approvalID, choiceIndex, req.Cancelled)
+	}
 	if err != nil {
 		switch {
 		case errors.Is(err, errServeApprovalNotPending), errors.Is(err, errServeApprovalAnswered):
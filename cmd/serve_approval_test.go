@@ -27,7 +27,7 @@ func putTestSession(mgr *serveSessionManager, id string, rt *serveRuntime) {
 func TestAwaitApproval_NoTransport_FailsFast(t *testing.T) {
 	rt := newTestRuntime()
 	// No approvalEventFunc set — should fail fast instead of hanging.
-	result, err := rt.awaitApproval("/some/path", false, false, "")
+	result, err := rt.awaitApproval(tools.ApprovalPrompt{Path: "/some/path"})
 	if err != errServeApprovalNoTransport {
 		t.Fatalf("expected errServeApprovalNoTransport, got %v", err)
 	}
@@ -60,7 +60,7 @@ func TestAwaitApproval_WithTransport_EmitsEventAndBlocks(t *testing.T) {
 	var awaitErr error
 
 	go func() {
-		result, awaitErr = rt.awaitApproval("/test/file.txt", false, false, "")
+		result, awaitErr = rt.awaitApproval(tools.ApprovalPrompt{Path: "/test/file.txt"})
 		close(done)
 	}()
 
@@ -130,7 +130,7 @@ func TestAwaitApproval_Cancellation(t *testing.T) {
 	var result tools.ApprovalResult
 
 	go func() {
-		result, _ = rt.awaitApproval("/test/file.txt", false, false, "")
+		result, _ = rt.awaitApproval(tools.ApprovalPrompt{Path: "/test/file.txt"})
 		close(done)
 	}()
 
@@ -183,7 +183,7 @@ func TestSubmitApproval_DenyFlow(t *testing.T) {
 	var result tools.ApprovalResult
 
 	go func() {
-		result, _ = rt.awaitApproval("/test/file.txt", false, false, "")
+		result, _ = rt.awaitApproval(tools.ApprovalPrompt{Path: "/test/file.txt"})
 		close(done)
 	}()
 
@@ -239,7 +239,7 @@ func TestSubmitApproval_AlreadyAnswered(t *testing.T) {
 
 	done := make(chan struct{})
 	go func() {
-		rt.awaitApproval("/test/file.txt", false, false, "")
+		rt.awaitApproval(tools.ApprovalPrompt{Path: "/test/file.txt"})
 		close(done)
 	}()
 
@@ -351,3 +351,145 @@ func TestSnapshotTitle_DerivedFromFlags(t *testing.T) {
 		})
 	}
 }
+
+func TestServeShellOptions_SessionAndAlwaysPrefixChoices(t *testing.T) {
+	var prefixOptions []tools.ApprovalOption
+	for _, opt := range serveShellOptions(tools.BuildShellOptions("go test ./...", nil)) {
+		if opt.Choice == tools.ApprovalChoicePrefix {
+			prefixOptions = append(prefixOptions, opt)
+		}
+	}
+	if len(prefixOptions) != 2 {
+		t.Fatalf("prefix options = %+v, want a session and an always choice", prefixOptions)
+	}
+	if session := prefixOptions[0]; session.SaveToRepo || session.Prefix != "go test" || session.Label != `Allow "go test" commands` {
+		t.Errorf("first prefix option = %+v, want the session-only choice", session)
+	}
+	if always := prefixOptions[1]; !always.SaveToRepo || always.Prefix != "go test" {
+		t.Errorf("second prefix option = %+v, want the remembered choice", always)
+	}
+}
+
+func TestAwaitApproval_PayloadCarriesToolArgsAndDiff(t *testing.T) {
+	rt := newTestRuntime()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	payloads := make(chan map[string]any, 1)
+	rt.approvalMu.Lock()
+	rt.approvalEventFunc = func(event string, data map[string]any) error {
+		payloads <- data
+		return nil
+	}
+	rt.approvalCtx = ctx
+	rt.approvalMu.Unlock()
+
+	diff := "--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-old\n+new"
+	go rt.awaitApproval(tools.ApprovalPrompt{
+		Path:     "/test/main.go",
+		IsWrite:  true,
+		ToolName: tools.EditFileToolName,
+		Args:     "(path:/test/main.go, old_text:old, new_text:new)",
+		Diff:     diff,
+	})
+
+	var payload map[string]any
+	select {
+	case payload = <-payloads:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for approval event")
+	}
+	if payload["tool_name"] != tools.EditFileToolName {
+		t.Errorf("tool_name = %v", payload["tool_name"])
+	}
+	if payload["args"] != "(path:/test/main.go, old_text:old, new_text:new)" {
+		t.Errorf("args = %v", payload["args"])
+	}
+	if payload["diff"] != diff {
+		t.Errorf("diff = %v", payload["diff"])
+	}
+
+	// Reconnecting clients read the same fields from the state endpoint.
+	prompts := rt.pendingApprovalPrompts()
+	if len(prompts) != 1 || prompts[0].Diff != diff || prompts[0].ToolName != tools.EditFileToolName {
+		t.Fatalf("pending prompts = %+v", prompts)
+	}
+	raw, err := json.Marshal(prompts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(raw, []byte(`"diff":`)) || !bytes.Contains(raw, []byte(`"args":`)) {
+		t.Errorf("snapshot JSON missing fields: %s", raw)
+	}
+}
+
+func TestHandleSessionApproval_AlwaysAllowDirectory(t *testing.T) {
+	rt := newTestRuntime()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	prompted := make(chan string, 1)
+	rt.approvalMu.Lock()
+	rt.approvalEventFunc = func(event string, data map[string]any) error {
+		prompted <- data["approval_id"].(string)
+		return nil
+	}
+	rt.approvalCtx = ctx
+	rt.approvalMu.Unlock()
+
+	target := t.TempDir() + "/notes.txt"
+	done := make(chan tools.ApprovalResult, 1)
+	go func() {
+		result, _ := rt.awaitApproval(tools.ApprovalPrompt{Path: target, IsWrite: true, ToolName: tools.WriteFileToolName})
+		done <- result
+	}()
+
+	var approvalID string
+	select {
+	case approvalID = <-prompted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for approval event")
+	}
+
+	mgr := newServeSessionManager(time.Hour, 10, nil)
+	defer mgr.Close()
+	putTestSession(mgr, "test", rt)
+	s := &serveServer{sessionMgr: mgr}
+
+	body := `{"approval_id": "` + approvalID + `", "always_allow_directory": true}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/sessions/test/approval", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	s.handleSessionApproval(w, req, "test")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	select {
+	case result := <-done:
+		if result.Choice != tools.ApprovalChoiceDirectory || result.Path == "" {
+			t.Fatalf("result = %+v, want directory approval", result)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("awaitApproval did not return")
+	}
+}
+
+func TestSubmitApprovalAlwaysAllowDirectory_ShellHasNoDirectoryOption(t *testing.T) {
+	rt := newTestRuntime()
+	rt.pendingApprovals = map[string]*servePendingApproval{
+		"appr_shell": {
+			ApprovalID: "appr_shell",
+			IsShell:    true,
+			Options:    tools.BuildShellOptions("ls", nil),
+			responseC:  make(chan serveApprovalSubmission, 1),
+		},
+	}
+	if err := rt.submitApprovalAlwaysAllowDirectory("appr_shell"); err == nil {
+		t.Fatal("expected error for shell approval without a directory option")
+	}
+	// The request stays answerable after the rejected shortcut.
+	if err := rt.submitApproval("appr_shell", 0, true); err != nil {
+		t.Fatalf("submitApproval after rejected shortcut: %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errServeShuttingDown is returned for work submitted after shutdown began.
+var errServeShuttingDown = errors.New("server is shutting down")
+
+const defaultServeDrainTimeout = 30 * time.Second
+
+// serveDrainState tracks the requests that start generation, so shutdown can
+// refuse new ones and wait for those already admitted.
+type serveDrainState struct {
+	mu       sync.Mutex
+	draining bool
+	active   int
+	idle     chan struct{} // closed once draining with no active requests
+}
+
+func (d *serveDrainState) enter() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return false
+	}
+	d.active++
+	return true
+}
+
+func (d *serveDrainState) leave() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.active--
+	if d.draining && d.active == 0 {
+		close(d.idle)
+	}
+}
+
+// begin stops admitting requests and returns a channel that is closed once
+// the admitted ones have finished.
+func (d *serveDrainState) begin() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.draining {
+		d.draining = true
+		d.idle = make(chan struct{})
+		if d.active == 0 {
+			close(d.idle)
+		}
+	}
+	return d.idle
+}
+
+func (d *serveDrainState) isDraining() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.draining
+}
+
+func (d *serveDrainState) reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.draining = false
+	d.active = 0
+	d.idle = nil
+}
+
+// drainable wraps handlers whose POSTs start generation or job runs. Once
+// shutdown begins they are refused with 503; until then each is tracked so
+// Stop can let its stream finish.
+func (s *serveServer) drainable(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next(w, r)
+			return
+		}
+		if !s.drain.enter() {
+			w.Header().Set("Retry-After", "5")
+			writeOpenAIError(w, http.StatusServiceUnavailable, "server_error", errServeShuttingDown.Error())
+			return
+		}
+		defer s.drain.leave()
+		next(w, r)
+	}
+}
+
+// drainActive is the first step of Stop. It refuses new sessions, runs and
+// jobs, tells chat clients with a server_shutting_down event, and gives
+// active streams up to the drain timeout to finish. Whatever is still
+// running afterwards is cancelled by the rest of Stop.
+func (s *serveServer) drainActive(ctx context.Context) {
+	idle := s.drain.begin()
+	if s.jobsV2 != nil {
+		s.jobsV2.StopClaiming()
+	}
+	timeout := s.cfg.drainTimeout
+	drainCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	finished := true
+	if s.responseRuns != nil {
+		finished = s.responseRuns.Drain(drainCtx, map[string]any{
+			"drain_timeout_ms": timeout.Milliseconds(),
+			"message":          serveShutdownMessage(timeout),
+		})
+	}
+	select {
+	case <-idle:
+	case <-drainCtx.Done():
+		finished = false
+	}
+	if !finished {
+		log.Printf("serve: active streams did not finish within the %s drain timeout; cancelling them", timeout)
+	}
+}
+
+func serveShutdownMessage(timeout time.Duration) string {
+	if timeout <= 0 {
+		return "Server is shutting down; this response will be interrupted."
+	}
+	return fmt.Sprintf("Server is shutting down; this response has up to %s to finish.", humanDuration(timeout))
+}
+
+func resolveServeDrainTimeout(flagSet bool, flagVal time.Duration, configVal string) (time.Duration, error) {
+	if flagSet {
+		if flagVal < 0 {
+			return 0, fmt.Errorf("invalid --drain-timeout %s (must be >= 0)", flagVal)
+		}
+		return flagVal, nil
+	}
+	if strings.TrimSpace(configVal) == "" {
+		return defaultServeDrainTimeout, nil
+	}
+	timeout, err := time.ParseDuration(strings.TrimSpace(configVal))
+	if err != nil {
+		return 0, fmt.Errorf("invalid serve.drain_timeout %q (use a Go duration like 30s or 2m): %w", configVal, err)
+	}
+	if timeout < 0 {
+		return 0, fmt.Errorf("invalid serve.drain_timeout %q (must be >= 0)", configVal)
+	}
+	return timeout, nil
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+// drainTestProvider streams one text delta, then blocks until released (the
+// stream ends normally) or cancelled. Both outcomes are written to log.
+type drainTestProvider struct {
+	started   chan struct{}
+	release   chan struct{}
+	cancelled chan struct{}
+	startOnce sync.Once
+	log       func(string)
+}
+
+func newDrainTestProvider(log func(string)) *drainTestProvider {
+	return &drainTestProvider{
+		started:   make(chan struct{}),
+		release:   make(chan struct{}),
+		cancelled: make(chan struct{}),
+		log:       log,
+	}
+}
+
+func (p *drainTestProvider) Name() string                   { return "drain-test" }
+func (p *drainTestProvider) Credential() string             { return "test" }
+func (p *drainTestProvider) Capabilities() llm.Capabilities { return llm.Capabilities{} }
+
+func (p *drainTestProvider) Stream(ctx context.Context, req llm.Request) (llm.Stream, error) {
+	return &drainTestStream{ctx: ctx, p: p}, nil
+}
+
+type drainTestStream struct {
+	ctx  context.Context
+	p    *drainTestProvider
+	sent bool
+}
+
+func (s *drainTestStream) Recv() (llm.Event, error) {
+	if !s.sent {
+		s.sent = true
+		s.p.startOnce.Do(func() { close(s.p.started) })
+		return llm.Event{Type: llm.EventTextDelta, Text: "partial"}, nil
+	}
+	select {
+	case <-s.p.release:
+		s.p.log("stream finished")
+		return llm.Event{}, io.EOF
+	case <-s.ctx.Done():
+		s.p.log("stream cancelled")
+		close(s.p.cancelled)
+		return llm.Event{}, s.ctx.Err()
+	}
+}
+
+func (s *drainTestStream) Close() error { return nil }
+
+type orderLog struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (l *orderLog) add(entry string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+}
+
+func (l *orderLog) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return strings.Join(l.entries, ", ")
+}
+
+// newDrainTestServer returns a server with one response run streaming from a
+// drainTestProvider, and an HTTP server whose Serve loop logs when it closes.
+func newDrainTestServer(t *testing.T, drainTimeout time.Duration) (*serveServer, *drainTestProvider, *responseRun, *orderLog, <-chan struct{}) {
+	t.Helper()
+	log := &orderLog{}
+	provider := newDrainTestProvider(log.add)
+	rt := &serveRuntime{provider: provider, engine: llm.NewEngine(provider, nil), defaultModel: "mock-model"}
+	rt.Touch()
+
+	srv := &serveServer{
+		cfg:          serveServerConfig{drainTimeout: drainTimeout},
+		responseRuns: newServeResponseRunManager(),
+		server:       &http.Server{Handler: http.NotFoundHandler()},
+		shutdownCh:   make(chan struct{}),
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	serveDone := make(chan struct{})
+	go func() {
+		_ = srv.server.Serve(ln)
+		log.add("http closed")
+		close(serveDone)
+	}()
+
+	run, err := srv.startResponseRun(rt, false, true, []llm.Message{llm.UserText("hi")}, llm.Request{Model: "mock-model"}, "", startResponseRunOptions{})
+	if err != nil {
+		t.Fatalf("startResponseRun: %v", err)
+	}
+	select {
+	case <-provider.started:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the response run to start streaming")
+	}
+	return srv, provider, run, log, serveDone
+}
+
+func runEventNames(run *responseRun) []string {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	var names []string
+	for _, ev := range run.activeEventsLocked() {
+		names = append(names, ev.Event)
+	}
+	return names
+}
+
+func hasRunEvent(run *responseRun, name string) bool {
+	for _, got := range runEventNames(run) {
+		if got == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestServeStopLetsActiveStreamFinishBeforeShutdown(t *testing.T) {
+	srv, provider, run, log, serveDone := newDrainTestServer(t, 5*time.Second)
+
+	stopErr := make(chan error, 1)
+	go func() { stopErr <- srv.Stop(context.Background()) }()
+
+	waitForServeCondition(t, time.Second, func() bool { return hasRunEvent(run, "server_shutting_down") }, "server_shutting_down event")
+	select {
+	case <-provider.cancelled:
+		t.Fatal("stream was cancelled before the drain timeout")
+	default:
+	}
+
+	// New work is refused while draining.
+	rr := httptest.NewRecorder()
+	srv.drainable(srv.handleResponses)(rr, httptest.NewRequest(http.MethodPost, "/v1/responses", strings.NewReader(`{}`)))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("POST /v1/responses while draining = %d, want 503", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	srv.handleHealth(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rr.Code != http.StatusServiceUnavailable || !strings.Contains(rr.Body.String(), `"draining"`) {
+		t.Fatalf("healthz while draining = %d %s", rr.Code, rr.Body.String())
+	}
+	if err := srv.responseRuns.start(func() {}); !errors.Is(err, errServeShuttingDown) {
+		t.Fatalf("start while draining = %v, want errServeShuttingDown", err)
+	}
+
+	close(provider.release)
+	if err := <-stopErr; err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	<-serveDone
+
+	if got, want := log.String(), "stream finished, http closed"; got != want {
+		t.Fatalf("shutdown order = %q, want %q", got, want)
+	}
+	names := runEventNames(run)
+	if last := names[len(names)-1]; last != "response.completed" {
+		t.Fatalf("run ended with %s, want response.completed (events %v)", last, names)
+	}
+}
+
+func TestServeStopCancelsStreamsAfterDrainTimeout(t *testing.T) {
+	srv, provider, run, log, serveDone := newDrainTestServer(t, 100*time.Millisecond)
+
+	started := time.Now()
+	if err := srv.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	<-serveDone
+	if elapsed := time.Since(started); elapsed < 100*time.Millisecond {
+		t.Fatalf("Stop returned after %s, before the drain timeout", elapsed)
+	}
+	select {
+	case <-provider.cancelled:
+	default:
+		t.Fatal("stream was not cancelled after the drain timeout")
+	}
+
+	if got, want := log.String(), "stream cancelled, http closed"; got != want {
+		t.Fatalf("shutdown order = %q, want %q", got, want)
+	}
+	names := strings.Join(runEventNames(run), ",")
+	shutdownAt := strings.Index(names, "server_shutting_down")
+	cancelledAt := strings.Index(names, "response.cancelled")
+	if shutdownAt < 0 || cancelledAt < shutdownAt {
+		t.Fatalf("run events = %s, want server_shutting_down before response.cancelled", names)
+	}
+}
+
+func TestResolveServeDrainTimeout(t *testing.T) {
+	if got, err := resolveServeDrainTimeout(false, 0, ""); err != nil || got != defaultServeDrainTimeout {
+		t.Fatalf("default = %s, %v", got, err)
+	}
+	if got, err := resolveServeDrainTimeout(false, 0, "2m"); err != nil || got != 2*time.Minute {
+		t.Fatalf("config 2m = %s, %v", got, err)
+	}
+	if got, err := resolveServeDrainTimeout(true, 0, "2m"); err != nil || got != 0 {
+		t.Fatalf("flag 0 = %s, %v; want the flag to win", got, err)
+	}
+	if _, err := resolveServeDrainTimeout(false, 0, "-1s"); err == nil {
+		t.Fatal("negative config timeout should be rejected")
+	}
+}
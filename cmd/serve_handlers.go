@@ -27,6 +27,7 @@ import (
 	"github.com/samsaffron/term-llm/internal/config"
 	"github.com/samsaffron/term-llm/internal/image"
 	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/metrics"
 	"github.com/samsaffron/term-llm/internal/serveui"
 	"github.com/samsaffron/term-llm/internal/session"
 	"github.com/samsaffron/term-llm/internal/sessiontitle"
@@ -45,17 +46,59 @@ func (s *serveServer) handleHealth(w http.ResponseWriter, r *http.Request) {
 		writeOpenAIError(w, http.StatusMethodNotAllowed, "invalid_request_error", "method not allowed")
 		return
 	}
-	resp := map[string]any{"status": "ok"}
-	// Identity fields (agent, version, capabilities) are only reported to
-	// trusted callers — a valid bearer token, or any caller when auth is
-	// disabled — so the unauthenticated health probe stays anonymous. The hub
+	status := http.StatusOK
+	resp := map[string]any{"status": "ok", "version": Version, "database": s.databaseStatus(r.Context())}
+	if resp["database"] == "error" {
+		status = http.StatusServiceUnavailable
+		resp["status"] = "degraded"
+	}
+	if s.drain.isDraining() {
+		// Tell load balancers and the hub prober to stop routing here.
+		status = http.StatusServiceUnavailable
+		resp["status"] = "draining"
+	}
+	// Identity fields (agent, capabilities) are only reported to trusted
+	// callers — a valid bearer token, or any caller when auth is disabled —
+	// so the unauthenticated health probe does not name the node. The hub
 	// prober sends the node token and uses these for its dashboard.
 	if s.healthIdentityTrusted(r) {
 		resp["agent"] = s.cfg.agentName
-		resp["version"] = Version
 		resp["capabilities"] = s.capabilityList()
 	}
-	writeJSON(w, http.StatusOK, resp)
+	writeJSON(w, status, resp)
+}
+
+// databaseStatus reports "ok" when the session store answers a ping,
+// "error" when it does not, and "disabled" when serve runs without one.
+func (s *serveServer) databaseStatus(ctx context.Context) string {
+	if s.store == nil {
+		return "disabled"
+	}
+	pinger, ok := s.store.(session.Pinger)
+	if !ok {
+		return "ok"
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	if err := pinger.Ping(ctx); err != nil {
+		log.Printf("healthz: %v", err)
+		return "error"
+	}
+	return "ok"
+}
+
+// handleMetrics serves the process-wide counters in the Prometheus text
+// format.
+func (s *serveServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeOpenAIError(w, http.StatusMethodNotAllowed, "invalid_request_error", "method not allowed")
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := metrics.Default.WriteText(w); err != nil {
+		s.verboseLog("write metrics: %v", err)
+	}
 }
 
 // healthIdentityTrusted reports whether the health request may receive node
@@ -1404,6 +1447,8 @@ func (s *serveServer) sessionMessageEntries(msgs []session.Message) []sessionMes
 				entry.Parts = append(entry.Parts, sessionMessagePartEntry{Type: "model_swap", Text: marker.DisplayText})
 			} else if marker, ok := llm.ParseRunErrorMarker(msg.ToLLMMessage()); ok {
 				entry.Parts = append(entry.Parts, sessionMessagePartEntry{Type: "error", Text: marker.Message})
+			} else if marker, ok := llm.ParseInterruptedMarker(msg.ToLLMMessage()); ok {
+				entry.Parts = append(entry.Parts, sessionMessagePartEntry{Type: "interrupted", Text: llm.FormatInterruptedMarker(marker)})
 			} else {
 				for _, p := range msg.Parts {
 					switch p.Type {
@@ -2630,8 +2675,9 @@ func (s *serveServer) getModelsProvider(name string) (llm.Provider, string, erro
 // The returned mu must wrap all writes to w inside the RunWithEvents callback.
 // Call stop() immediately after RunWithEvents returns; it blocks until the
 // goroutine has exited so subsequent final writes to w are safe without a lock.
-// The goroutine also exits when ctx is cancelled between writes.
-func sseKeepalive(ctx context.Context, w http.ResponseWriter, flusher http.Flusher, interval time.Duration) (mu *sync.Mutex, stop func()) {
+// The goroutine also exits when ctx is cancelled between writes, or after a
+// ping fails to write, in which case onWriteError (if non-nil) is called.
+func sseKeepalive(ctx context.Context, w http.ResponseWriter, flusher http.Flusher, interval time.Duration, onWriteError func()) (mu *sync.Mutex, stop func()) {
 	mu = &sync.Mutex{}
 	done := make(chan struct{})
 	var wg sync.WaitGroup
@@ -2644,9 +2690,14 @@ func sseKeepalive(ctx context.Context, w http.ResponseWriter, flusher http.Flush
 			select {
 			case <-ticker.C:
 				mu.Lock()
-				_, _ = io.WriteString(w, ": ping\n\n")
-				flusher.Flush()
+				err := writeSSEPing(w, flusher)
 				mu.Unlock()
+				if err != nil {
+					if onWriteError != nil {
+						onWriteError()
+					}
+					return
+				}
 			case <-ctx.Done():
 				return
 			case <-done:
@@ -2660,6 +2711,17 @@ func sseKeepalive(ctx context.Context, w http.ResponseWriter, flusher http.Flush
 	}
 }
 
+func writeSSEPing(w http.ResponseWriter, flusher http.Flusher) error {
+	if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+		return err
+	}
+	if fe, ok := flusher.(interface{ FlushError() error }); ok {
+		return fe.FlushError()
+	}
+	flusher.Flush()
+	return nil
+}
+
 // registerResponseID stores a response ID on the runtime and server-wide map,
 // pruning old IDs that exceed the per-session cap.
 func (s *serveServer) registerResponseID(rt *serveRuntime, respID, sessionID string) {
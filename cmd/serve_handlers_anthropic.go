@@ -60,6 +60,11 @@ func (s *serveServer) handleAnthropicMessages(w http.ResponseWriter, r *http.Req
 	if sessionID == "" {
 		sessionID = ensureSessionID(w)
 	}
+	release, ok := s.admitGeneration(w, r, sessionID, true)
+	if !ok {
+		return
+	}
+	defer release()
 	runtime, stateful, err := s.runtimeForRequest(ctx, sessionID)
 	if err != nil {
 		if errors.Is(err, errServeSessionBusy) || errors.Is(err, errServeSessionLimitReached) {
@@ -199,7 +204,7 @@ func (s *serveServer) streamAnthropicMessages(ctx context.Context, w http.Respon
 	ctx, cancelShutdown := s.contextWithShutdown(ctx)
 	defer cancelShutdown()
 
-	pingMu, stopPing := sseKeepalive(ctx, w, flusher, 10*time.Second)
+	pingMu, stopPing := sseKeepalive(ctx, w, flusher, serveSSEKeepaliveInterval, nil)
 
 	var (
 		blockIndex int
@@ -48,6 +48,11 @@ func (s *serveServer) handleChatCompletions(w http.ResponseWriter, r *http.Reque
 	if sessionID == "" {
 		sessionID = ensureSessionID(w)
 	}
+	release, ok := s.admitGeneration(w, r, sessionID, false)
+	if !ok {
+		return
+	}
+	defer release()
 	runtime, stateful, err := s.runtimeForRequest(ctx, sessionID)
 	if err != nil {
 		if errors.Is(err, errServeSessionBusy) || errors.Is(err, errServeSessionLimitReached) {
@@ -178,7 +183,7 @@ func (s *serveServer) streamChatCompletions(ctx context.Context, w http.Response
 	ctx, cancelShutdown := s.contextWithShutdown(ctx)
 	defer cancelShutdown()
 
-	pingMu, stopPing := sseKeepalive(ctx, w, flusher, 10*time.Second)
+	pingMu, stopPing := sseKeepalive(ctx, w, flusher, serveSSEKeepaliveInterval, nil)
 
 	first := true
 	toolCallSeen := false
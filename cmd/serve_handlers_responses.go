@@ -159,6 +159,11 @@ func (s *serveServer) handleResolvedResponses(w http.ResponseWriter, r *http.Req
 			return
 		}
 	}
+	release, ok := s.admitGeneration(w, r, sessionID, false)
+	if !ok {
+		return
+	}
+	defer release()
 	// Chained requests are locked to the persisted provider/model/
 	// reasoning_effort unless the client explicitly asks for a mid-conversation
 	// model swap. External bare session_id requests start a fresh conversation,
@@ -14,6 +14,7 @@ import (
 
 	"github.com/gorilla/websocket"
 	"github.com/samsaffron/term-llm/internal/hub"
+	"github.com/samsaffron/term-llm/internal/metrics"
 )
 
 // Reverse node connections let a private node dial out to a public Hub. The
@@ -266,6 +267,8 @@ func hubReversePingLoop(conn *websocket.Conn, writeMu *sync.Mutex, done <-chan s
 }
 
 func (c *hubReverseConnection) readLoop(done func()) {
+	metrics.WebSocketConnections.Inc()
+	defer metrics.WebSocketConnections.Dec()
 	donePing := make(chan struct{})
 	defer close(donePing)
 	defer done()
@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/samsaffron/term-llm/internal/metrics"
 )
 
 func localHubConnectBase(host string, port int) string {
@@ -95,6 +96,8 @@ func hubReverseConnectOnce(ctx context.Context, hubURL, nodeID, token, localBase
 		return err
 	}
 	defer conn.Close()
+	metrics.WebSocketConnections.Inc()
+	defer metrics.WebSocketConnections.Dec()
 	var writeMu sync.Mutex
 	donePing := make(chan struct{})
 	defer close(donePing)
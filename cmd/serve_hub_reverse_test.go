@@ -14,6 +14,7 @@ import (
 
 	"github.com/gorilla/websocket"
 	"github.com/samsaffron/term-llm/internal/hub"
+	"github.com/samsaffron/term-llm/internal/metrics"
 )
 
 func TestHubReverseConnectionNextRequestIDIsUnique(t *testing.T) {
@@ -81,6 +82,38 @@ func TestHubReverseNodeProxy(t *testing.T) {
 	}
 }
 
+func TestHubReverseConnectionCountsBothEndsInWebSocketGauge(t *testing.T) {
+	backend := httptest.NewServer(http.NotFoundHandler())
+	defer backend.Close()
+
+	node := hub.Node{ID: "artist", Name: "Artist", Connection: "reverse", BasePath: "/chat", Token: "node-token"}
+	s := newHubServer(hub.NewRegistry(fakeHubResolver{nodes: []hub.Node{node}}), nil)
+	hubTS := httptest.NewServer(s.handler())
+	defer hubTS.Close()
+
+	before := metrics.WebSocketConnections.Value()
+	waitForGauge := func(want int64) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for metrics.WebSocketConnections.Value() != want && time.Now().Before(deadline) {
+			time.Sleep(20 * time.Millisecond)
+		}
+		if got := metrics.WebSocketConnections.Value(); got != want {
+			t.Fatalf("websocket connections = %d, want %d", got, want)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go runHubReverseConnector(ctx, hubTS.URL, "artist", "node-token", backend.URL, "/chat", backend.Client())
+	c := waitForReverseNodeConnection(t, s, "artist")
+	waitForGauge(before + 2) // the node's outbound link and the hub's inbound one
+
+	cancel()
+	_ = c.conn.Close()
+	waitForGauge(before)
+}
+
 func TestHubReverseNodeSessionsSummary(t *testing.T) {
 	var sessionsAuth string
 	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -21,6 +21,7 @@ import (
 	"github.com/samsaffron/term-llm/internal/config"
 	"github.com/samsaffron/term-llm/internal/jobs"
 	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/metrics"
 	internalreasoning "github.com/samsaffron/term-llm/internal/reasoning"
 	runpkg "github.com/samsaffron/term-llm/internal/run"
 	"github.com/samsaffron/term-llm/internal/session"
@@ -60,16 +61,21 @@ const (
 
 	jobsV2MisfireSkip = "skip"
 	jobsV2MisfireRun  = "run"
+
+	jobsV2OverlapSkip           = jobs.OverlapSkip
+	jobsV2OverlapQueue          = jobs.OverlapQueue
+	jobsV2OverlapCancelPrevious = jobs.OverlapCancelPrevious
 )
 
 const (
-	exitReasonNatural    = jobs.ExitReasonNatural
-	exitReasonMaxTurns   = jobs.ExitReasonMaxTurns
-	exitReasonTimeout    = jobs.ExitReasonTimeout
-	exitReasonCancelled  = jobs.ExitReasonCancelled
-	exitReasonException  = jobs.ExitReasonException
-	exitReasonEmpty      = jobs.ExitReasonEmpty
-	exitReasonWorkerLost = jobs.ExitReasonWorkerLost
+	exitReasonNatural     = jobs.ExitReasonNatural
+	exitReasonMaxTurns    = jobs.ExitReasonMaxTurns
+	exitReasonTimeout     = jobs.ExitReasonTimeout
+	exitReasonCancelled   = jobs.ExitReasonCancelled
+	exitReasonException   = jobs.ExitReasonException
+	exitReasonEmpty       = jobs.ExitReasonEmpty
+	exitReasonWorkerLost  = jobs.ExitReasonWorkerLost
+	exitReasonInterrupted = jobs.ExitReasonInterrupted
 )
 
 type jobsV2RetryPolicy struct {
@@ -100,6 +106,7 @@ type jobsV2Job struct {
 	RetryPolicy       json.RawMessage   `json:"retry_policy,omitempty"`
 	TimeoutSeconds    int               `json:"timeout_seconds,omitempty"`
 	MisfirePolicy     string            `json:"misfire_policy,omitempty"`
+	OverlapPolicy     string            `json:"overlap_policy,omitempty"`
 	Labels            json.RawMessage   `json:"labels,omitempty"`
 	NextRunAt         *time.Time        `json:"next_run_at,omitempty"`
 	LastRun           *jobsV2Run        `json:"last_run,omitempty"`
@@ -120,6 +127,7 @@ type jobsV2JobRequest struct {
 	RetryPolicy       json.RawMessage   `json:"retry_policy,omitempty"`
 	TimeoutSeconds    int               `json:"timeout_seconds,omitempty"`
 	MisfirePolicy     string            `json:"misfire_policy,omitempty"`
+	OverlapPolicy     string            `json:"overlap_policy,omitempty"`
 	Labels            json.RawMessage   `json:"labels,omitempty"`
 }
 
@@ -141,6 +149,7 @@ func (req jobsV2JobRequest) toJob(defaultEnabled bool) jobsV2Job {
 		RetryPolicy:       req.RetryPolicy,
 		TimeoutSeconds:    req.TimeoutSeconds,
 		MisfirePolicy:     req.MisfirePolicy,
+		OverlapPolicy:     req.OverlapPolicy,
 		Labels:            req.Labels,
 	}
 }
@@ -160,6 +169,7 @@ func jobsV2JobToRequest(req jobsV2Job) jobsV2JobRequest {
 		RetryPolicy:       req.RetryPolicy,
 		TimeoutSeconds:    req.TimeoutSeconds,
 		MisfirePolicy:     req.MisfirePolicy,
+		OverlapPolicy:     req.OverlapPolicy,
 		Labels:            req.Labels,
 	}
 }
@@ -230,6 +240,10 @@ type jobsV2ProgramRunner struct{}
 var (
 	jobsV2ProgramOutputLimit int64         = jobs.ProgramOutputLimit
 	jobsV2ProgramWaitDelay   time.Duration = jobs.ProgramWaitDelay
+	jobsV2RunLogLimit        int64         = jobs.DefaultRunLogLimit
+	// jobsV2LogFollowPollInterval is how often a followed log is checked for
+	// new output.
+	jobsV2LogFollowPollInterval = 250 * time.Millisecond
 )
 
 const (
@@ -305,6 +319,8 @@ type jobsV2LLMConfig struct {
 	SessionName    string              `json:"session_name,omitempty"`
 	NotifyWhenDone bool                `json:"notify_when_done,omitempty"`
 	NotifyOrigin   *jobsV2NotifyOrigin `json:"notify_origin,omitempty"`
+	// Notifications are webhooks posted when a run finishes; see jobsV2Webhook.
+	Notifications []jobsV2Webhook `json:"notifications,omitempty"`
 
 	// cwd is REQUIRED: it roots this run's file/shell tools at a directory so a
 	// job never silently inherits the jobs server's process working directory.
@@ -319,6 +335,8 @@ type jobsV2LLMConfig struct {
 	ReadDir         []string `json:"read_dir,omitempty"`          // additional read roots
 	WriteDir        []string `json:"write_dir,omitempty"`         // additional write roots
 	Tools           string   `json:"tools,omitempty"`             // tool set override ("all" or csv)
+	AllowedTools    []string `json:"allowed_tools,omitempty"`     // only these tools, including MCP/skill tools and sub-agents
+	DeniedTools     []string `json:"denied_tools,omitempty"`      // never these tools, including in sub-agents
 	MaxTurns        int      `json:"max_turns,omitempty"`         // agentic turn cap
 	MaxOutputTokens int      `json:"max_output_tokens,omitempty"` // 0 = provider default
 	Search          bool     `json:"search,omitempty"`
@@ -356,6 +374,9 @@ func validateJobsV2RunnerConfig(runnerType jobsV2RunnerType, raw json.RawMessage
 		if strings.TrimSpace(cfg.Cwd) == "" {
 			return fmt.Errorf("llm runner_config.cwd is required")
 		}
+		if err := validateJobsV2Webhooks(cfg.Notifications); err != nil {
+			return fmt.Errorf("llm runner_config.%w", err)
+		}
 	case jobsV2RunnerProgram:
 		// Program runner config is intentionally still validated at execution time:
 		// its optional cwd remains an explicit escape hatch, unlike llm cwd.
@@ -532,17 +553,33 @@ type jobsV2Manager struct {
 	cleanupInterval      time.Duration
 	lastCleanupCompleted time.Time
 	cleanupRunning       bool
+	// Program run stdout/stderr are captured under logDir/<run-id>/; empty
+	// (in-memory databases) disables capture.
+	logDir string
 
 	enqueueMu     sync.Mutex
 	mu            sync.Mutex
 	closed        bool
+	draining      bool // set during serve shutdown: runs in flight continue, none are claimed
 	done          chan struct{}
 	schedulerWake chan struct{}
 	workerWake    chan struct{}
 	wg            sync.WaitGroup
 	cancels       map[string]context.CancelFunc
+
+	triggerMu   sync.Mutex
+	triggerKeys map[string]jobsV2TriggerKey // job ID + Idempotency-Key -> run
+}
+
+type jobsV2TriggerKey struct {
+	runID   string
+	expires time.Time
 }
 
+// jobsV2TriggerKeyTTL bounds how long a trigger Idempotency-Key is remembered;
+// it only needs to outlast a client's retries.
+const jobsV2TriggerKeyTTL = 10 * time.Minute
+
 const jobsV2Schema = `
 CREATE TABLE IF NOT EXISTS jobs_v2 (
 	id TEXT PRIMARY KEY,
@@ -558,6 +595,7 @@ CREATE TABLE IF NOT EXISTS jobs_v2 (
 	retry_policy TEXT,
 	timeout_seconds INTEGER NOT NULL DEFAULT 300,
 	misfire_policy TEXT NOT NULL DEFAULT 'skip',
+	overlap_policy TEXT NOT NULL DEFAULT 'skip',
 	labels TEXT,
 	next_run_at TIMESTAMP,
 	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
@@ -655,6 +693,7 @@ func newJobsV2ManagerWithNotifier(dbPath string, workers int, llmExec serveJobsE
 		`ALTER TABLE job_runs_v2 ADD COLUMN input_tokens INTEGER NOT NULL DEFAULT 0`,
 		`ALTER TABLE job_runs_v2 ADD COLUMN output_tokens INTEGER NOT NULL DEFAULT 0`,
 		`ALTER TABLE job_runs_v2 ADD COLUMN session_id TEXT`,
+		`ALTER TABLE jobs_v2 ADD COLUMN overlap_policy TEXT NOT NULL DEFAULT 'skip'`,
 	}
 	for _, migration := range migrations {
 		_, _ = db.Exec(migration)
@@ -679,6 +718,11 @@ func newJobsV2ManagerWithNotifier(dbPath string, workers int, llmExec serveJobsE
 	}
 	_, _ = db.Exec(`DROP INDEX IF EXISTS idx_job_runs_v2_job_id`)
 
+	logDir := ""
+	if dbPath != ":memory:" {
+		logDir = filepath.Join(filepath.Dir(dbPath), "job_logs")
+	}
+
 	mgr := &jobsV2Manager{
 		db:                 db,
 		workers:            workers,
@@ -691,6 +735,7 @@ func newJobsV2ManagerWithNotifier(dbPath string, workers int, llmExec serveJobsE
 		retentionEventDays:  30,
 		retentionMaxRunsJob: 1000,
 		cleanupInterval:     time.Hour,
+		logDir:              logDir,
 		runners: map[jobsV2RunnerType]jobsV2Runner{
 			jobsV2RunnerProgram: &jobsV2ProgramRunner{},
 			jobsV2RunnerLLM:     &jobsV2LLMRunner{exec: llmExec},
@@ -928,6 +973,20 @@ func (m *jobsV2Manager) CloseContext(ctx context.Context) error {
 	}
 }
 
+// StopClaiming stops workers from starting queued runs, which stay queued
+// for the next start. Runs already in flight are unaffected until Close.
+func (m *jobsV2Manager) StopClaiming() {
+	m.mu.Lock()
+	m.draining = true
+	m.mu.Unlock()
+}
+
+func (m *jobsV2Manager) isDraining() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.draining
+}
+
 func (m *jobsV2Manager) schedulerLoop() {
 	defer m.wg.Done()
 	timer := time.NewTimer(0)
@@ -1150,6 +1209,47 @@ func (m *jobsV2Manager) pruneOldData(now time.Time) error {
 		}
 	}
 
+	return m.pruneRunLogs()
+}
+
+// runLogDir returns the directory holding a run's captured output, or ""
+// when capture is disabled.
+func (m *jobsV2Manager) runLogDir(runID string) string {
+	if m.logDir == "" || runID == "" || runID != filepath.Base(runID) || strings.HasPrefix(runID, ".") {
+		return ""
+	}
+	return filepath.Join(m.logDir, runID)
+}
+
+// pruneRunLogs removes captured logs whose run no longer exists, so log
+// files follow the same retention as the runs themselves.
+func (m *jobsV2Manager) pruneRunLogs() error {
+	if m.logDir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(m.logDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		var exists int
+		err := m.db.QueryRow(`SELECT 1 FROM job_runs_v2 WHERE id = ?`, entry.Name()).Scan(&exists)
+		if errors.Is(err, sql.ErrNoRows) {
+			if err := os.RemoveAll(filepath.Join(m.logDir, entry.Name())); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -1161,6 +1261,12 @@ func (m *jobsV2Manager) workerLoop() {
 			return
 		default:
 		}
+		if m.isDraining() {
+			if !m.waitForWorkerWake(jobsV2WorkerIdleDelay) {
+				return
+			}
+			continue
+		}
 
 		run, ok, err := m.claimNextRun()
 		if err != nil {
@@ -1212,7 +1318,7 @@ func (m *jobsV2Manager) nextWorkerDelayWithError(now time.Time) (time.Duration,
 	delay := idleDelay
 
 	var nextRun sql.NullTime
-	err := m.db.QueryRow(`SELECT scheduled_for FROM job_runs_v2 WHERE status = ? ORDER BY scheduled_for ASC LIMIT 1`, jobsV2RunQueued).Scan(&nextRun)
+	err := m.db.QueryRow(`SELECT scheduled_for FROM job_runs_v2 WHERE status = ? AND `+jobsV2RunHasCapacitySQL+` ORDER BY scheduled_for ASC LIMIT 1`, jobsV2RunQueued, jobsV2RunClaimed, jobsV2RunRunning, jobsV2RunCancelRequested).Scan(&nextRun)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return 0, err
 	}
@@ -1250,7 +1356,7 @@ func (m *jobsV2Manager) notifyWorkers(n int) {
 }
 
 func (m *jobsV2Manager) scheduleDueRuns(now time.Time) error {
-	rows, err := m.db.Query(`SELECT id, name, enabled, runner_type, runner_config, trigger_type, trigger_config, schedule_timezone, concurrency_policy, max_concurrent_runs, retry_policy, timeout_seconds, misfire_policy, labels, next_run_at, created_at, updated_at FROM jobs_v2 WHERE enabled = 1 AND next_run_at IS NOT NULL AND next_run_at <= ? ORDER BY next_run_at ASC LIMIT 200`, now.UTC())
+	rows, err := m.db.Query(`SELECT id, name, enabled, runner_type, runner_config, trigger_type, trigger_config, schedule_timezone, concurrency_policy, max_concurrent_runs, retry_policy, timeout_seconds, misfire_policy, overlap_policy, labels, next_run_at, created_at, updated_at FROM jobs_v2 WHERE enabled = 1 AND next_run_at IS NOT NULL AND next_run_at <= ? ORDER BY next_run_at ASC LIMIT 200`, now.UTC())
 	if err != nil {
 		return err
 	}
@@ -1286,6 +1392,12 @@ func jobsV2ConcurrencyLimit(job jobsV2Job) int {
 	return 1
 }
 
+// jobsV2RunHasCapacitySQL limits claims to queued runs whose job has fewer
+// started runs than jobsV2ConcurrencyLimit allows, so a run queued behind an
+// active one (overlap_policy queue or cancel_previous) waits for it to finish.
+// It takes the claimed, running and cancel_requested statuses as arguments.
+const jobsV2RunHasCapacitySQL = `(SELECT COUNT(1) FROM job_runs_v2 AS started WHERE started.job_id = job_runs_v2.job_id AND started.status IN (?, ?, ?)) < COALESCE((SELECT CASE WHEN j.concurrency_policy = 'forbid' OR j.max_concurrent_runs <= 0 THEN 1 ELSE j.max_concurrent_runs END FROM jobs_v2 AS j WHERE j.id = job_runs_v2.job_id), 1)`
+
 func (m *jobsV2Manager) countActiveRuns(jobID string) (int, error) {
 	var active int
 	err := m.db.QueryRow(`SELECT COUNT(1) FROM job_runs_v2 WHERE job_id = ? AND status IN (?, ?, ?)`, jobID, jobsV2RunQueued, jobsV2RunClaimed, jobsV2RunRunning).Scan(&active)
@@ -1332,20 +1444,33 @@ func (m *jobsV2Manager) scheduleOne(job jobsV2Job, now time.Time) error {
 		return err
 	}
 
-	runID, _, queued, err := m.enqueueRunWithConcurrencyLimit(job, "schedule", now, func() error {
-		_, err := m.db.Exec(`UPDATE jobs_v2 SET next_run_at = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, next, job.ID)
-		return err
-	}, func(string) error {
+	advance := func(string) error {
 		if job.TriggerType == jobsV2TriggerOnce {
 			_, err := m.db.Exec(`UPDATE jobs_v2 SET enabled = 0, next_run_at = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, job.ID)
 			return err
 		}
 		_, err := m.db.Exec(`UPDATE jobs_v2 SET next_run_at = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, next, job.ID)
 		return err
-	})
+	}
+	var overlapRunID string
+	runID, _, queued, err := m.enqueueRunWithConcurrencyLimit(job, "schedule", now, func() error {
+		var err error
+		overlapRunID, err = m.applyOverlapPolicy(job, now)
+		if err != nil {
+			return err
+		}
+		if overlapRunID != "" {
+			return advance(overlapRunID)
+		}
+		_, err = m.db.Exec(`UPDATE jobs_v2 SET next_run_at = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, next, job.ID)
+		return err
+	}, advance)
 	if err != nil {
 		return err
 	}
+	if overlapRunID != "" {
+		runID, queued = overlapRunID, true
+	}
 	if queued {
 		_ = m.addRunEvent(runID, "queued", "scheduled run queued", map[string]any{"trigger": "schedule", "attempt": 1})
 		m.notifyWorkers(1)
@@ -1353,6 +1478,80 @@ func (m *jobsV2Manager) scheduleOne(job jobsV2Job, now time.Time) error {
 	return nil
 }
 
+// applyOverlapPolicy handles a scheduled run that found the job at its
+// concurrency limit. It is called with enqueueMu held. The returned run ID is
+// set when a run was queued anyway (queue, cancel_previous); skip, and a queue
+// that already has a run waiting, record a skipped run instead.
+func (m *jobsV2Manager) applyOverlapPolicy(job jobsV2Job, now time.Time) (string, error) {
+	active, err := m.activeRunIDs(job.ID)
+	if err != nil {
+		return "", err
+	}
+	switch job.OverlapPolicy {
+	case jobsV2OverlapQueue:
+		var waiting int
+		if err := m.db.QueryRow(`SELECT COUNT(1) FROM job_runs_v2 WHERE job_id = ? AND status = ?`, job.ID, jobsV2RunQueued).Scan(&waiting); err != nil {
+			return "", err
+		}
+		if waiting > 0 {
+			return "", m.recordSkippedRun(job, now, "a run is already queued behind the active run", active)
+		}
+		return m.insertQueuedRun(job.ID, "schedule", now)
+	case jobsV2OverlapCancelPrevious:
+		for _, id := range active {
+			_, _ = m.CancelRun(id)
+		}
+		runID, err := m.insertQueuedRun(job.ID, "schedule", now)
+		if err != nil {
+			return "", err
+		}
+		_ = m.addRunEvent(runID, "overlap", "cancelled the previous run before starting", map[string]any{"overlap_policy": job.OverlapPolicy, "cancelled_run_ids": active})
+		return runID, nil
+	default:
+		return "", m.recordSkippedRun(job, now, "previous run still active", active)
+	}
+}
+
+// activeRunIDs lists the job's runs that have not finished, oldest first.
+func (m *jobsV2Manager) activeRunIDs(jobID string) ([]string, error) {
+	rows, err := m.db.Query(`SELECT id FROM job_runs_v2 WHERE job_id = ? AND status IN (?, ?, ?, ?) ORDER BY created_at ASC`, jobID, jobsV2RunQueued, jobsV2RunClaimed, jobsV2RunRunning, jobsV2RunCancelRequested)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (m *jobsV2Manager) insertQueuedRun(jobID, trigger string, scheduledFor time.Time) (string, error) {
+	runID := "run_" + randomSuffix()
+	_, err := m.db.Exec(`INSERT INTO job_runs_v2 (id, job_id, attempt, trigger, scheduled_for, status, created_at, updated_at) VALUES (?, ?, 1, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`, runID, jobID, trigger, scheduledFor.UTC(), jobsV2RunQueued)
+	if err != nil {
+		return "", err
+	}
+	return runID, nil
+}
+
+// recordSkippedRun stores a finished "skipped" run so the run history shows
+// the scheduled time that did not run, and why.
+func (m *jobsV2Manager) recordSkippedRun(job jobsV2Job, scheduledFor time.Time, reason string, activeRunIDs []string) error {
+	runID := "run_" + randomSuffix()
+	now := time.Now().UTC()
+	_, err := m.db.Exec(`INSERT INTO job_runs_v2 (id, job_id, attempt, trigger, scheduled_for, status, finished_at, error, created_at, updated_at) VALUES (?, ?, 1, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`, runID, job.ID, "schedule", scheduledFor.UTC(), jobsV2RunSkipped, now, reason)
+	if err != nil {
+		return err
+	}
+	_ = m.addRunEvent(runID, "skipped", reason, map[string]any{"overlap_policy": job.OverlapPolicy, "active_run_ids": activeRunIDs})
+	return nil
+}
+
 func (m *jobsV2Manager) claimNextRun() (jobsV2Run, bool, error) {
 	tx, err := m.db.Begin()
 	if err != nil {
@@ -1361,7 +1560,7 @@ func (m *jobsV2Manager) claimNextRun() (jobsV2Run, bool, error) {
 	defer func() { _ = tx.Rollback() }()
 
 	now := time.Now().UTC()
-	row := tx.QueryRow(`SELECT id, job_id, attempt, trigger, scheduled_for, status, worker_id, session_id, started_at, finished_at, exit_code, error, stdout, stderr, thinking, response, exit_reason, truncated, turn_count, input_tokens, output_tokens, created_at, updated_at FROM job_runs_v2 WHERE status = ? AND scheduled_for <= ? ORDER BY scheduled_for ASC LIMIT 1`, jobsV2RunQueued, now)
+	row := tx.QueryRow(`SELECT id, job_id, attempt, trigger, scheduled_for, status, worker_id, session_id, started_at, finished_at, exit_code, error, stdout, stderr, thinking, response, exit_reason, truncated, turn_count, input_tokens, output_tokens, created_at, updated_at FROM job_runs_v2 WHERE status = ? AND scheduled_for <= ? AND `+jobsV2RunHasCapacitySQL+` ORDER BY scheduled_for ASC LIMIT 1`, jobsV2RunQueued, now, jobsV2RunClaimed, jobsV2RunRunning, jobsV2RunCancelRequested)
 	run, err := scanRunV2(row)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -1389,6 +1588,9 @@ func (m *jobsV2Manager) claimNextRun() (jobsV2Run, bool, error) {
 }
 
 func (m *jobsV2Manager) executeRun(run jobsV2Run) {
+	metrics.ActiveJobRuns.Inc()
+	defer metrics.ActiveJobRuns.Dec()
+
 	job, err := m.GetJob(run.JobID)
 	if err != nil {
 		m.finishRunWithRetry(run.ID, jobsV2RunFailed, jobsV2RunResult{}, fmt.Errorf("load job: %w", err), run.Attempt)
@@ -1449,12 +1651,23 @@ func (m *jobsV2Manager) executeRun(run jobsV2Run) {
 			_ = m.addRunEvent(run.ID, eventType, message, data)
 		}
 	}
+	if dir := m.runLogDir(run.ID); dir != "" && job.RunnerType == jobsV2RunnerProgram {
+		ctx = jobs.WithRunLogs(ctx, jobs.RunLogs{Dir: dir, Limit: jobsV2RunLogLimit})
+	}
 	result, runErr := runner.Run(ctx, job, pw)
 	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
 		m.finishRunWithRetry(run.ID, jobsV2RunTimedOut, result, context.DeadlineExceeded, run.Attempt)
 		return
 	}
 	if errors.Is(ctx.Err(), context.Canceled) {
+		if m.isClosed() {
+			// Shutdown cancelled the run rather than a user; say so, so the
+			// run is not mistaken for one someone stopped on purpose.
+			_ = m.addRunEvent(run.ID, "interrupted", "run interrupted by server shutdown", map[string]any{"worker_id": m.workerID})
+			result.ExitReason = exitReasonInterrupted
+			m.finishRunWithRetry(run.ID, jobsV2RunCancelled, result, fmt.Errorf("interrupted by server shutdown: %w", context.Canceled), run.Attempt)
+			return
+		}
 		m.finishRunWithRetry(run.ID, jobsV2RunCancelled, result, context.Canceled, run.Attempt)
 		return
 	}
@@ -1560,6 +1773,7 @@ func (m *jobsV2Manager) finishRun(runID string, status jobsV2RunStatus, result j
 		"output_tokens": result.OutputTokens,
 	})
 	m.enqueueRunDoneNotification(run, status, result, exitReason, truncated, errText)
+	m.enqueueRunWebhooks(run)
 
 	if status == jobsV2RunFailed || status == jobsV2RunTimedOut {
 		job, err := m.GetJob(run.JobID)
@@ -1758,18 +1972,29 @@ func validateJobsV2MisfirePolicy(policy string) error {
 	}
 }
 
-func (m *jobsV2Manager) CreateJob(req jobsV2Job) (jobsV2Job, error) {
+func validateJobsV2OverlapPolicy(policy string) error {
+	switch policy {
+	case jobsV2OverlapSkip, jobsV2OverlapQueue, jobsV2OverlapCancelPrevious:
+		return nil
+	default:
+		return fmt.Errorf("overlap_policy must be one of: skip, queue, cancel_previous")
+	}
+}
+
+// prepareJobsV2Job validates a complete job definition, fills in defaults for
+// unset policies and limits, and returns its first run time.
+func prepareJobsV2Job(req *jobsV2Job) (*time.Time, error) {
 	if strings.TrimSpace(req.Name) == "" {
-		return jobsV2Job{}, fmt.Errorf("name is required")
+		return nil, fmt.Errorf("name is required")
 	}
 	if req.RunnerType != jobsV2RunnerLLM && req.RunnerType != jobsV2RunnerProgram {
-		return jobsV2Job{}, fmt.Errorf("runner_type must be one of: llm, program")
+		return nil, fmt.Errorf("runner_type must be one of: llm, program")
 	}
 	if err := validateJobsV2RunnerConfig(req.RunnerType, req.RunnerConfig); err != nil {
-		return jobsV2Job{}, err
+		return nil, err
 	}
 	if req.TriggerType != jobsV2TriggerManual && req.TriggerType != jobsV2TriggerOnce && req.TriggerType != jobsV2TriggerCron {
-		return jobsV2Job{}, fmt.Errorf("trigger_type must be one of: manual, once, cron")
+		return nil, fmt.Errorf("trigger_type must be one of: manual, once, cron")
 	}
 	if req.MaxConcurrentRuns <= 0 {
 		req.MaxConcurrentRuns = 1
@@ -1785,18 +2010,32 @@ func (m *jobsV2Manager) CreateJob(req jobsV2Job) (jobsV2Job, error) {
 		req.MisfirePolicy = jobsV2MisfireSkip
 	}
 	if err := validateJobsV2MisfirePolicy(req.MisfirePolicy); err != nil {
-		return jobsV2Job{}, err
+		return nil, err
+	}
+	req.OverlapPolicy = strings.TrimSpace(req.OverlapPolicy)
+	if req.OverlapPolicy == "" {
+		req.OverlapPolicy = jobsV2OverlapSkip
+	}
+	if err := validateJobsV2OverlapPolicy(req.OverlapPolicy); err != nil {
+		return nil, err
 	}
 
 	cfg, err := parseTriggerConfig(req.TriggerType, req.TriggerConfig, req.ScheduleTimezone)
+	if err != nil {
+		return nil, err
+	}
+	return initialNextRun(req.TriggerType, cfg, req.ScheduleTimezone), nil
+}
+
+func (m *jobsV2Manager) CreateJob(req jobsV2Job) (jobsV2Job, error) {
+	next, err := prepareJobsV2Job(&req)
 	if err != nil {
 		return jobsV2Job{}, err
 	}
-	next := initialNextRun(req.TriggerType, cfg, req.ScheduleTimezone)
 
 	now := time.Now().UTC()
 	id := "job_" + randomSuffix()
-	_, err = m.db.Exec(`INSERT INTO jobs_v2 (id, name, enabled, runner_type, runner_config, trigger_type, trigger_config, schedule_timezone, concurrency_policy, max_concurrent_runs, retry_policy, timeout_seconds, misfire_policy, labels, next_run_at, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	_, err = m.db.Exec(`INSERT INTO jobs_v2 (id, name, enabled, runner_type, runner_config, trigger_type, trigger_config, schedule_timezone, concurrency_policy, max_concurrent_runs, retry_policy, timeout_seconds, misfire_policy, overlap_policy, labels, next_run_at, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		id,
 		req.Name,
 		boolToInt(req.Enabled),
@@ -1810,6 +2049,7 @@ func (m *jobsV2Manager) CreateJob(req jobsV2Job) (jobsV2Job, error) {
 		nullableRaw(req.RetryPolicy),
 		req.TimeoutSeconds,
 		req.MisfirePolicy,
+		req.OverlapPolicy,
 		nullableRaw(req.Labels),
 		next,
 		now,
@@ -1827,7 +2067,7 @@ func (m *jobsV2Manager) CreateJob(req jobsV2Job) (jobsV2Job, error) {
 }
 
 func (m *jobsV2Manager) GetJob(id string) (jobsV2Job, error) {
-	row := m.db.QueryRow(`SELECT id, name, enabled, runner_type, runner_config, trigger_type, trigger_config, schedule_timezone, concurrency_policy, max_concurrent_runs, retry_policy, timeout_seconds, misfire_policy, labels, next_run_at, created_at, updated_at FROM jobs_v2 WHERE id = ?`, id)
+	row := m.db.QueryRow(`SELECT id, name, enabled, runner_type, runner_config, trigger_type, trigger_config, schedule_timezone, concurrency_policy, max_concurrent_runs, retry_policy, timeout_seconds, misfire_policy, overlap_policy, labels, next_run_at, created_at, updated_at FROM jobs_v2 WHERE id = ?`, id)
 	job, err := scanJobV2(row)
 	if err != nil {
 		return jobsV2Job{}, err
@@ -1852,7 +2092,7 @@ func (m *jobsV2Manager) ListJobs(limit, offset int) ([]jobsV2Job, int, error) {
 	if err := m.db.QueryRow(`SELECT COUNT(1) FROM jobs_v2`).Scan(&total); err != nil {
 		return nil, 0, err
 	}
-	rows, err := m.db.Query(`SELECT id, name, enabled, runner_type, runner_config, trigger_type, trigger_config, schedule_timezone, concurrency_policy, max_concurrent_runs, retry_policy, timeout_seconds, misfire_policy, labels, next_run_at, created_at, updated_at FROM jobs_v2 ORDER BY created_at DESC LIMIT ? OFFSET ?`, limit, offset)
+	rows, err := m.db.Query(`SELECT id, name, enabled, runner_type, runner_config, trigger_type, trigger_config, schedule_timezone, concurrency_policy, max_concurrent_runs, retry_policy, timeout_seconds, misfire_policy, overlap_policy, labels, next_run_at, created_at, updated_at FROM jobs_v2 ORDER BY created_at DESC LIMIT ? OFFSET ?`, limit, offset)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -1936,6 +2176,7 @@ func (m *jobsV2Manager) UpdateJobPatch(id string, req jobsV2JobRequest) (jobsV2J
 	if err != nil {
 		return jobsV2Job{}, err
 	}
+	storedRunnerConfig := current.RunnerConfig
 	if strings.TrimSpace(req.Name) != "" {
 		current.Name = req.Name
 	}
@@ -1969,12 +2210,18 @@ func (m *jobsV2Manager) UpdateJobPatch(id string, req jobsV2JobRequest) (jobsV2J
 	if req.MisfirePolicy != "" {
 		current.MisfirePolicy = strings.TrimSpace(req.MisfirePolicy)
 	}
+	if req.OverlapPolicy != "" {
+		current.OverlapPolicy = strings.TrimSpace(req.OverlapPolicy)
+	}
 	if len(req.Labels) > 0 {
 		current.Labels = req.Labels
 	}
 	if req.Enabled != nil {
 		current.Enabled = *req.Enabled
 	}
+	if current.RunnerConfig, err = restoreJobsV2WebhookURLs(storedRunnerConfig, current.RunnerConfig); err != nil {
+		return jobsV2Job{}, err
+	}
 
 	cfg, err := parseTriggerConfig(current.TriggerType, current.TriggerConfig, current.ScheduleTimezone)
 	if err != nil {
@@ -1983,12 +2230,52 @@ func (m *jobsV2Manager) UpdateJobPatch(id string, req jobsV2JobRequest) (jobsV2J
 	if err := validateJobsV2MisfirePolicy(current.MisfirePolicy); err != nil {
 		return jobsV2Job{}, err
 	}
+	if err := validateJobsV2OverlapPolicy(current.OverlapPolicy); err != nil {
+		return jobsV2Job{}, err
+	}
 	if err := validateJobsV2RunnerConfig(current.RunnerType, current.RunnerConfig); err != nil {
 		return jobsV2Job{}, err
 	}
 	next := initialNextRun(current.TriggerType, cfg, current.ScheduleTimezone)
+	return m.storeJob(id, current, next)
+}
 
-	_, err = m.db.Exec(`UPDATE jobs_v2 SET name = ?, enabled = ?, runner_type = ?, runner_config = ?, trigger_type = ?, trigger_config = ?, schedule_timezone = ?, concurrency_policy = ?, max_concurrent_runs = ?, retry_policy = ?, timeout_seconds = ?, misfire_policy = ?, labels = ?, next_run_at = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+// MergePatchJob applies an RFC 7386 JSON merge patch to a job: fields absent
+// from the patch keep their value, nested objects such as trigger_config are
+// merged key by key, and null resets a field to its default. The result is
+// validated as a complete definition, as on create.
+func (m *jobsV2Manager) MergePatchJob(id string, patch []byte) (jobsV2Job, error) {
+	current, err := m.GetJob(id)
+	if err != nil {
+		return jobsV2Job{}, err
+	}
+	doc, err := json.Marshal(jobsV2JobToRequest(current))
+	if err != nil {
+		return jobsV2Job{}, err
+	}
+	merged, err := applyJSONMergePatch(doc, patch)
+	if err != nil {
+		return jobsV2Job{}, err
+	}
+	var req jobsV2JobRequest
+	if err := json.Unmarshal(merged, &req); err != nil {
+		return jobsV2Job{}, fmt.Errorf("invalid job after patch: %w", err)
+	}
+	updated := req.toJob(true)
+	if updated.RunnerConfig, err = restoreJobsV2WebhookURLs(current.RunnerConfig, updated.RunnerConfig); err != nil {
+		return jobsV2Job{}, err
+	}
+	next, err := prepareJobsV2Job(&updated)
+	if err != nil {
+		return jobsV2Job{}, err
+	}
+	return m.storeJob(id, updated, next)
+}
+
+// storeJob writes the editable fields of current to the row for id and
+// returns the stored job.
+func (m *jobsV2Manager) storeJob(id string, current jobsV2Job, next *time.Time) (jobsV2Job, error) {
+	_, err := m.db.Exec(`UPDATE jobs_v2 SET name = ?, enabled = ?, runner_type = ?, runner_config = ?, trigger_type = ?, trigger_config = ?, schedule_timezone = ?, concurrency_policy = ?, max_concurrent_runs = ?, retry_policy = ?, timeout_seconds = ?, misfire_policy = ?, overlap_policy = ?, labels = ?, next_run_at = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
 		current.Name,
 		boolToInt(current.Enabled),
 		current.RunnerType,
@@ -2001,6 +2288,7 @@ func (m *jobsV2Manager) UpdateJobPatch(id string, req jobsV2JobRequest) (jobsV2J
 		nullableRaw(current.RetryPolicy),
 		current.TimeoutSeconds,
 		current.MisfirePolicy,
+		current.OverlapPolicy,
 		nullableRaw(current.Labels),
 		next,
 		id,
@@ -2060,17 +2348,88 @@ func (m *jobsV2Manager) TriggerJob(id string) (jobsV2Run, error) {
 	return m.GetRun(runID)
 }
 
+// TriggerJobIdempotent is TriggerJob deduplicated by a client-supplied key:
+// repeating a trigger with the same key returns the run created the first
+// time instead of queueing another.
+func (m *jobsV2Manager) TriggerJobIdempotent(id, key string) (jobsV2Run, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return m.TriggerJob(id)
+	}
+	m.triggerMu.Lock()
+	defer m.triggerMu.Unlock()
+	now := time.Now()
+	for k, entry := range m.triggerKeys {
+		if now.After(entry.expires) {
+			delete(m.triggerKeys, k)
+		}
+	}
+	scoped := id + "\x00" + key
+	if entry, ok := m.triggerKeys[scoped]; ok {
+		return m.GetRun(entry.runID)
+	}
+	run, err := m.TriggerJob(id)
+	if err != nil {
+		return run, err
+	}
+	if m.triggerKeys == nil {
+		m.triggerKeys = make(map[string]jobsV2TriggerKey)
+	}
+	m.triggerKeys[scoped] = jobsV2TriggerKey{runID: run.ID, expires: now.Add(jobsV2TriggerKeyTTL)}
+	return run, nil
+}
+
 func (m *jobsV2Manager) GetRun(id string) (jobsV2Run, error) {
 	row := m.db.QueryRow(`SELECT id, job_id, attempt, trigger, scheduled_for, status, worker_id, session_id, started_at, finished_at, exit_code, error, stdout, stderr, thinking, response, exit_reason, truncated, turn_count, input_tokens, output_tokens, created_at, updated_at FROM job_runs_v2 WHERE id = ?`, id)
 	return scanRunV2(row)
 }
 
 func (m *jobsV2Manager) ListRuns(jobID string, limit, offset int) ([]jobsV2Run, int, error) {
-	return m.listRuns(jobID, limit, offset, true)
+	return m.listRuns(jobsV2RunFilter{JobID: jobID}, limit, offset, true)
 }
 
 func (m *jobsV2Manager) ListRunSummaries(jobID string, limit, offset int) ([]jobsV2Run, int, error) {
-	return m.listRuns(jobID, limit, offset, false)
+	return m.listRuns(jobsV2RunFilter{JobID: jobID}, limit, offset, false)
+}
+
+// jobsV2RunFilter narrows a run listing. Zero fields match everything; Since
+// and Until bound created_at.
+type jobsV2RunFilter struct {
+	JobID    string
+	Statuses []jobsV2RunStatus
+	Since    time.Time
+	Until    time.Time
+}
+
+// jobsV2SQLiteTimeLayout matches the CURRENT_TIMESTAMP text stored in
+// created_at, so bounds compare as strings.
+const jobsV2SQLiteTimeLayout = "2006-01-02 15:04:05"
+
+func (f jobsV2RunFilter) where() (string, []any) {
+	var clauses []string
+	var args []any
+	if jobID := strings.TrimSpace(f.JobID); jobID != "" {
+		clauses = append(clauses, "job_id = ?")
+		args = append(args, jobID)
+	}
+	if len(f.Statuses) > 0 {
+		clauses = append(clauses, "status IN (?"+strings.Repeat(", ?", len(f.Statuses)-1)+")")
+		for _, status := range f.Statuses {
+			args = append(args, status)
+		}
+	}
+	if !f.Since.IsZero() {
+		clauses = append(clauses, "created_at >= ?")
+		args = append(args, f.Since.UTC().Format(jobsV2SQLiteTimeLayout))
+	}
+	if !f.Until.IsZero() {
+		clauses = append(clauses, "created_at < ?")
+		args = append(args, f.Until.UTC().Format(jobsV2SQLiteTimeLayout))
+	}
+	if len(clauses) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(clauses, " AND "), args
 }
 
 const jobsV2RunSummaryIndexName = "idx_job_runs_v2_summary_by_job_created"
@@ -2085,7 +2444,7 @@ const jobsV2RunFullColumns = "id, job_id, attempt, trigger, scheduled_for, statu
 
 const jobsV2RunSummaryColumns = "id, job_id, attempt, trigger, scheduled_for, status, worker_id, session_id, started_at, finished_at, exit_code, error, exit_reason, truncated, turn_count, input_tokens, output_tokens, created_at, updated_at"
 
-func (m *jobsV2Manager) listRuns(jobID string, limit, offset int, includeOutput bool) ([]jobsV2Run, int, error) {
+func (m *jobsV2Manager) listRuns(filter jobsV2RunFilter, limit, offset int, includeOutput bool) ([]jobsV2Run, int, error) {
 	if limit <= 0 {
 		limit = 50
 	}
@@ -2095,12 +2454,7 @@ func (m *jobsV2Manager) listRuns(jobID string, limit, offset int, includeOutput
 	if offset < 0 {
 		offset = 0
 	}
-	where := ""
-	args := []any{}
-	if strings.TrimSpace(jobID) != "" {
-		where = " WHERE job_id = ?"
-		args = append(args, jobID)
-	}
+	where, args := filter.where()
 	countQuery := "SELECT COUNT(1) FROM job_runs_v2" + where
 	var total int
 	if err := m.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
@@ -2205,6 +2559,7 @@ func scanJobV2(scanner interface{ Scan(dest ...any) error }) (jobsV2Job, error)
 		&retryPolicy,
 		&job.TimeoutSeconds,
 		&job.MisfirePolicy,
+		&job.OverlapPolicy,
 		&labels,
 		&nextRun,
 		&job.CreatedAt,
@@ -2448,127 +2803,6 @@ func computeNextRunAt(job jobsV2Job, now time.Time) (*time.Time, error) {
 	return initialNextRun(job.TriggerType, cfg, job.ScheduleTimezone), nil
 }
 
-type cronField struct {
-	any    bool
-	values map[int]bool
-}
-
-type cronSpec struct {
-	minute cronField
-	hour   cronField
-	dom    cronField
-	month  cronField
-	dow    cronField
-}
-
-func parseCronExpression(expr string) (cronSpec, error) {
-	parts := strings.Fields(strings.TrimSpace(expr))
-	if len(parts) != 5 {
-		return cronSpec{}, fmt.Errorf("cron expression must have 5 fields")
-	}
-	minute, err := parseCronField(parts[0], 0, 59)
-	if err != nil {
-		return cronSpec{}, fmt.Errorf("minute: %w", err)
-	}
-	hour, err := parseCronField(parts[1], 0, 23)
-	if err != nil {
-		return cronSpec{}, fmt.Errorf("hour: %w", err)
-	}
-	dom, err := parseCronField(parts[2], 1, 31)
-	if err != nil {
-		return cronSpec{}, fmt.Errorf("day-of-month: %w", err)
-	}
-	month, err := parseCronField(parts[3], 1, 12)
-	if err != nil {
-		return cronSpec{}, fmt.Errorf("month: %w", err)
-	}
-	dow, err := parseCronField(parts[4], 0, 6)
-	if err != nil {
-		return cronSpec{}, fmt.Errorf("day-of-week: %w", err)
-	}
-	return cronSpec{minute: minute, hour: hour, dom: dom, month: month, dow: dow}, nil
-}
-
-func parseCronField(raw string, min, max int) (cronField, error) {
-	raw = strings.TrimSpace(raw)
-	if raw == "*" {
-		return cronField{any: true}, nil
-	}
-	values := make(map[int]bool)
-	segments := strings.Split(raw, ",")
-	for _, seg := range segments {
-		seg = strings.TrimSpace(seg)
-		if seg == "" {
-			return cronField{}, fmt.Errorf("empty segment")
-		}
-		if strings.HasPrefix(seg, "*/") {
-			step, err := strconv.Atoi(strings.TrimPrefix(seg, "*/"))
-			if err != nil || step <= 0 {
-				return cronField{}, fmt.Errorf("invalid step %q", seg)
-			}
-			for i := min; i <= max; i += step {
-				values[i] = true
-			}
-			continue
-		}
-		if strings.Contains(seg, "-") {
-			r := strings.SplitN(seg, "-", 2)
-			if len(r) != 2 {
-				return cronField{}, fmt.Errorf("invalid range %q", seg)
-			}
-			start, err1 := strconv.Atoi(strings.TrimSpace(r[0]))
-			end, err2 := strconv.Atoi(strings.TrimSpace(r[1]))
-			if err1 != nil || err2 != nil || start > end {
-				return cronField{}, fmt.Errorf("invalid range %q", seg)
-			}
-			if start < min || end > max {
-				return cronField{}, fmt.Errorf("range %q out of bounds", seg)
-			}
-			for i := start; i <= end; i++ {
-				values[i] = true
-			}
-			continue
-		}
-		v, err := strconv.Atoi(seg)
-		if err != nil {
-			return cronField{}, fmt.Errorf("invalid value %q", seg)
-		}
-		if v < min || v > max {
-			return cronField{}, fmt.Errorf("value %d out of bounds", v)
-		}
-		values[v] = true
-	}
-	return cronField{values: values}, nil
-}
-
-func (f cronField) match(v int) bool {
-	if f.any {
-		return true
-	}
-	return f.values[v]
-}
-
-func nextCronTime(expr, tz string, after time.Time) (time.Time, error) {
-	spec, err := parseCronExpression(expr)
-	if err != nil {
-		return time.Time{}, err
-	}
-	loc, err := time.LoadLocation(tz)
-	if err != nil {
-		return time.Time{}, err
-	}
-	t := after.In(loc).Truncate(time.Minute).Add(time.Minute)
-	max := t.Add(366 * 24 * time.Hour)
-	for !t.After(max) {
-		dow := int(t.Weekday())
-		if spec.minute.match(t.Minute()) && spec.hour.match(t.Hour()) && spec.month.match(int(t.Month())) && spec.dom.match(t.Day()) && spec.dow.match(dow) {
-			return t, nil
-		}
-		t = t.Add(time.Minute)
-	}
-	return time.Time{}, fmt.Errorf("no cron fire time found within one year")
-}
-
 func boolToInt(v bool) int {
 	if v {
 		return 1
@@ -2648,7 +2882,7 @@ func (s *serveServer) handleJobV2ByID(w http.ResponseWriter, r *http.Request) {
 			writeOpenAIError(w, http.StatusMethodNotAllowed, "invalid_request_error", "method not allowed")
 			return
 		}
-		run, err := s.jobsV2.TriggerJob(jobID)
+		run, err := s.jobsV2.TriggerJobIdempotent(jobID, r.Header.Get("Idempotency-Key"))
 		if err != nil {
 			writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
 			return
@@ -2667,7 +2901,7 @@ func (s *serveServer) handleJobV2ByID(w http.ResponseWriter, r *http.Request) {
 			writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
 			return
 		}
-		writeJSON(w, http.StatusOK, updated)
+		writeJSON(w, http.StatusOK, redactJobsV2WebhookURLs(updated))
 		return
 	}
 	if len(parts) == 2 && parts[1] == "resume" {
@@ -2681,7 +2915,7 @@ func (s *serveServer) handleJobV2ByID(w http.ResponseWriter, r *http.Request) {
 			writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
 			return
 		}
-		writeJSON(w, http.StatusOK, updated)
+		writeJSON(w, http.StatusOK, redactJobsV2WebhookURLs(updated))
 		return
 	}
 
@@ -2697,8 +2931,22 @@ func (s *serveServer) handleJobV2ByID(w http.ResponseWriter, r *http.Request) {
 			writeOpenAIError(w, http.StatusNotFound, "invalid_request_error", "job not found")
 			return
 		}
-		writeJSON(w, http.StatusOK, job)
+		writeJSON(w, http.StatusOK, redactJobsV2WebhookURLs(job))
 	case http.MethodPatch:
+		if isMergePatchContentType(r) {
+			var patch json.RawMessage
+			if err := decodeJSONBody(r, &patch); err != nil {
+				writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+				return
+			}
+			job, err := s.jobsV2.MergePatchJob(jobID, patch)
+			if err != nil {
+				writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+				return
+			}
+			writeJSON(w, http.StatusOK, redactJobsV2WebhookURLs(job))
+			return
+		}
 		if err := requireJSONContentType(r); err != nil {
 			writeOpenAIError(w, http.StatusUnsupportedMediaType, "invalid_request_error", err.Error())
 			return
@@ -2713,7 +2961,7 @@ func (s *serveServer) handleJobV2ByID(w http.ResponseWriter, r *http.Request) {
 			writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
 			return
 		}
-		writeJSON(w, http.StatusOK, job)
+		writeJSON(w, http.StatusOK, redactJobsV2WebhookURLs(job))
 	case http.MethodDelete:
 		cancelActive := strings.EqualFold(strings.TrimSpace(r.URL.Query().Get("cancel_active")), "true")
 		if err := s.jobsV2.DeleteJob(jobID, cancelActive); err != nil {
@@ -2756,7 +3004,7 @@ func (s *serveServer) handleCreateJobV2(w http.ResponseWriter, r *http.Request)
 		writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
 		return
 	}
-	writeJSON(w, http.StatusCreated, job)
+	writeJSON(w, http.StatusCreated, redactJobsV2WebhookURLs(job))
 }
 
 func (s *serveServer) handleListJobsV2(w http.ResponseWriter, r *http.Request) {
@@ -2778,6 +3026,9 @@ func (s *serveServer) handleListJobsV2(w http.ResponseWriter, r *http.Request) {
 	sort.Slice(items, func(i, j int) bool {
 		return items[i].CreatedAt.After(items[j].CreatedAt)
 	})
+	for i := range items {
+		items[i] = redactJobsV2WebhookURLs(items[i])
+	}
 	writeJSON(w, http.StatusOK, map[string]any{
 		"object": "list",
 		"data":   items,
@@ -2807,14 +3058,12 @@ func (s *serveServer) handleRunsV2(w http.ResponseWriter, r *http.Request) {
 		writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
 		return
 	}
-	jobID := strings.TrimSpace(r.URL.Query().Get("job_id"))
-	var items []jobsV2Run
-	var total int
-	if queryBool(r, "summary") {
-		items, total, err = s.jobsV2.ListRunSummaries(jobID, limit, offset)
-	} else {
-		items, total, err = s.jobsV2.ListRuns(jobID, limit, offset)
+	filter, err := parseJobsV2RunFilter(r)
+	if err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
 	}
+	items, total, err := s.jobsV2.listRuns(filter, limit, offset, !queryBool(r, "summary"))
 	if err != nil {
 		writeOpenAIError(w, http.StatusInternalServerError, "internal_error", err.Error())
 		return
@@ -2881,6 +3130,10 @@ func (s *serveServer) handleRunV2ByID(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	if len(parts) == 2 && parts[1] == "logs" {
+		s.handleRunV2Logs(w, r, runID)
+		return
+	}
 	if len(parts) == 2 && parts[1] == "cancel" {
 		if r.Method != http.MethodPost {
 			w.Header().Set("Allow", "POST")
@@ -2912,6 +3165,112 @@ func (s *serveServer) handleRunV2ByID(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, run)
 }
 
+// handleRunV2Logs serves a program run's captured stdout or stderr. Plain
+// requests get the file with Range support; follow=1 streams output from
+// offset as it is written until the run finishes.
+func (s *serveServer) handleRunV2Logs(w http.ResponseWriter, r *http.Request, runID string) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeOpenAIError(w, http.StatusMethodNotAllowed, "invalid_request_error", "method not allowed")
+		return
+	}
+	stream := strings.TrimSpace(r.URL.Query().Get("stream"))
+	if stream == "" {
+		stream = jobs.LogStdout
+	}
+	if !jobs.ValidLogStream(stream) {
+		writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", "stream must be stdout or stderr")
+		return
+	}
+	offset, err := parseNonNegativeIntQuery(r, "offset", 0)
+	if err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+	run, err := s.jobsV2.GetRun(runID)
+	if err != nil {
+		writeOpenAIError(w, http.StatusNotFound, "invalid_request_error", "run not found")
+		return
+	}
+	dir := s.jobsV2.runLogDir(run.ID)
+	if dir == "" {
+		writeOpenAIError(w, http.StatusNotFound, "invalid_request_error", "this server does not capture run logs")
+		return
+	}
+	path := jobs.RunLogPath(dir, stream)
+
+	if queryBool(r, "follow") {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(http.StatusOK)
+		_ = s.jobsV2.followRunLog(r.Context(), w, run.ID, path, int64(offset))
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		writeOpenAIError(w, http.StatusNotFound, "invalid_request_error", fmt.Sprintf("no %s captured for run %s", stream, run.ID))
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		writeOpenAIError(w, http.StatusInternalServerError, "internal_error", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, "", info.ModTime(), f)
+}
+
+// followRunLog copies the log at path to w from offset, flushing as output
+// arrives, until the run finishes and the log has been read to the end.
+func (m *jobsV2Manager) followRunLog(ctx context.Context, w io.Writer, runID, path string, offset int64) error {
+	flusher, _ := w.(http.Flusher)
+	var f *os.File
+	defer func() {
+		if f != nil {
+			_ = f.Close()
+		}
+	}()
+	for {
+		// Check the status before reading so output written just before the
+		// run finished is still copied on the last pass.
+		run, err := m.GetRun(runID)
+		if err != nil {
+			return err
+		}
+		finished := jobsRunFinished(run.Status)
+		if f == nil {
+			f, err = os.Open(path)
+			switch {
+			case err == nil:
+				if _, err := f.Seek(offset, io.SeekStart); err != nil {
+					return err
+				}
+			case !errors.Is(err, os.ErrNotExist):
+				return err
+			}
+		}
+		if f != nil {
+			n, err := io.Copy(w, f)
+			if err != nil {
+				return err
+			}
+			if n > 0 && flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if finished {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(jobsV2LogFollowPollInterval):
+		}
+	}
+}
+
 func newServeJobsV2Manager(cfg *config.Config, workers int, approval resolvedApprovalMode, notifyDone jobsV2RunDoneNotifier) (*jobsV2Manager, error) {
 	return newJobsV2ManagerWithNotifier("", workers, newServeJobsExecutor(cfg, approval), notifyDone)
 }
@@ -2921,6 +3280,47 @@ func queryBool(r *http.Request, key string) bool {
 	return strings.EqualFold(value, "1") || strings.EqualFold(value, "true") || strings.EqualFold(value, "yes")
 }
 
+// parseJobsV2RunFilter reads the job_id, status (comma-separated), since and
+// until (RFC 3339) query parameters of GET /v2/runs.
+func parseJobsV2RunFilter(r *http.Request) (jobsV2RunFilter, error) {
+	query := r.URL.Query()
+	filter := jobsV2RunFilter{JobID: strings.TrimSpace(query.Get("job_id"))}
+	for _, status := range strings.Split(query.Get("status"), ",") {
+		status = strings.TrimSpace(status)
+		if status == "" {
+			continue
+		}
+		if !jobsV2ValidRunStatus(jobsV2RunStatus(status)) {
+			return jobsV2RunFilter{}, fmt.Errorf("unknown run status %q", status)
+		}
+		filter.Statuses = append(filter.Statuses, jobsV2RunStatus(status))
+	}
+	for _, bound := range []struct {
+		key string
+		dst *time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		value := strings.TrimSpace(query.Get(bound.key))
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return jobsV2RunFilter{}, fmt.Errorf("%s must be an RFC 3339 timestamp", bound.key)
+		}
+		*bound.dst = t
+	}
+	return filter, nil
+}
+
+func jobsV2ValidRunStatus(status jobsV2RunStatus) bool {
+	switch status {
+	case jobsV2RunQueued, jobsV2RunClaimed, jobsV2RunRunning, jobsV2RunSucceeded, jobsV2RunFailed,
+		jobsV2RunCancelled, jobsV2RunCancelRequested, jobsV2RunTimedOut, jobsV2RunSkipped:
+		return true
+	}
+	return false
+}
+
 func parseNonNegativeIntQuery(r *http.Request, key string, defaultValue int) (int, error) {
 	value := strings.TrimSpace(r.URL.Query().Get(key))
 	if value == "" {
@@ -3132,6 +3532,8 @@ func newServeJobsExecutor(baseCfg *config.Config, approval resolvedApprovalMode)
 			Model:           cfg.Model,
 			Cwd:             cfg.Cwd,
 			Tools:           cfg.Tools,
+			AllowedTools:    append([]string(nil), cfg.AllowedTools...),
+			DeniedTools:     append([]string(nil), cfg.DeniedTools...),
 			ReadDirs:        append([]string(nil), cfg.ReadDir...),
 			WriteDirs:       append([]string(nil), cfg.WriteDir...),
 			MaxTurns:        cfg.MaxTurns,
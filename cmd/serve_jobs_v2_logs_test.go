@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newJobsV2LogsTestServer starts a file-backed manager, so run logs are
+// captured, behind a real HTTP server.
+func newJobsV2LogsTestServer(t *testing.T) (*jobsV2Manager, *httptest.Server) {
+	t.Helper()
+	mgr, err := newJobsV2Manager(filepath.Join(t.TempDir(), "jobs_v2.db"), 1, nil)
+	if err != nil {
+		t.Fatalf("newJobsV2Manager failed: %v", err)
+	}
+	t.Cleanup(func() { _ = mgr.Close() })
+	srv := &serveServer{jobsV2: mgr}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/runs/", srv.handleRunV2ByID)
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return mgr, ts
+}
+
+func triggerJobsV2ProgramRun(t *testing.T, mgr *jobsV2Manager, name, command string) jobsV2Run {
+	t.Helper()
+	cfg, _ := json.Marshal(jobsV2ProgramConfig{Command: command, Shell: true})
+	job, err := mgr.CreateJob(jobsV2Job{
+		Name:           name,
+		Enabled:        true,
+		RunnerType:     jobsV2RunnerProgram,
+		RunnerConfig:   cfg,
+		TriggerType:    jobsV2TriggerManual,
+		TriggerConfig:  json.RawMessage(`{}`),
+		TimeoutSeconds: 30,
+	})
+	if err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+	run, err := mgr.TriggerJob(job.ID)
+	if err != nil {
+		t.Fatalf("TriggerJob: %v", err)
+	}
+	return run
+}
+
+func waitJobsV2RunFinished(t *testing.T, mgr *jobsV2Manager, runID string) jobsV2Run {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		run, err := mgr.GetRun(runID)
+		if err == nil && jobsRunFinished(run.Status) {
+			return run
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for run %s", runID)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestJobsV2RunLogsCapAndRange(t *testing.T) {
+	origLimit := jobsV2RunLogLimit
+	jobsV2RunLogLimit = 8 << 10
+	defer func() { jobsV2RunLogLimit = origLimit }()
+
+	mgr, ts := newJobsV2LogsTestServer(t)
+	run := triggerJobsV2ProgramRun(t, mgr, "big-output", `head -c 100000 /dev/zero | tr '\0' a; echo warned >&2`)
+	if got := waitJobsV2RunFinished(t, mgr, run.ID); got.Status != jobsV2RunSucceeded {
+		t.Fatalf("run status = %s (%s)", got.Status, got.Error)
+	}
+
+	resp, err := http.Get(ts.URL + "/v2/runs/" + run.ID + "/logs?stream=stdout")
+	if err != nil {
+		t.Fatalf("GET logs: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain") {
+		t.Fatalf("logs status = %d content-type = %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	kept, _, truncated := strings.Cut(string(body), "\n[output truncated")
+	if !truncated || len(kept) != 8<<10 {
+		t.Fatalf("stdout log = %d bytes (truncated=%v), want 8KiB then a notice", len(body), truncated)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/v2/runs/"+run.ID+"/logs", nil)
+	req.Header.Set("Range", "bytes=100-109")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET range: %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent || string(body) != "aaaaaaaaaa" {
+		t.Fatalf("range response = %d %q, want 206 with 10 bytes", resp.StatusCode, body)
+	}
+
+	var stderr bytes.Buffer
+	client := &jobsClient{baseURL: ts.URL, http: ts.Client()}
+	if err := client.copyRunLog(context.Background(), run.ID, "stderr", false, &stderr); err != nil {
+		t.Fatalf("copyRunLog stderr: %v", err)
+	}
+	if stderr.String() != "warned\n" {
+		t.Fatalf("stderr = %q", stderr.String())
+	}
+	err = client.copyRunLog(context.Background(), run.ID, "stdin", false, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "stdout or stderr") {
+		t.Fatalf("invalid stream error = %v", err)
+	}
+
+	events, _, err := mgr.ListRunEvents(run.ID, 0, 100, 0)
+	if err != nil {
+		t.Fatalf("ListRunEvents: %v", err)
+	}
+	var captured *jobsV2RunEvent
+	for i := range events {
+		if events[i].EventType == "output_captured" {
+			captured = &events[i]
+		}
+	}
+	if captured == nil || !strings.Contains(captured.Message, "stdout: 97.7KB") {
+		t.Fatalf("output_captured event = %+v", captured)
+	}
+	var sizes map[string]any
+	if err := json.Unmarshal(captured.Data, &sizes); err != nil || sizes["stdout_bytes"] != float64(100000) || sizes["stdout_truncated"] != true {
+		t.Fatalf("event data = %s (%v)", captured.Data, err)
+	}
+}
+
+func TestJobsV2RunLogsFollowStreamsUntilRunFinishes(t *testing.T) {
+	origPoll := jobsV2LogFollowPollInterval
+	jobsV2LogFollowPollInterval = 20 * time.Millisecond
+	defer func() { jobsV2LogFollowPollInterval = origPoll }()
+
+	mgr, ts := newJobsV2LogsTestServer(t)
+	marker := filepath.Join(t.TempDir(), "release")
+	run := triggerJobsV2ProgramRun(t, mgr, "slow-output",
+		`echo first; while [ ! -e `+jobsV2ProgramTestShellQuote(marker)+` ]; do sleep 0.02; done; echo second`)
+
+	resp, err := http.Get(ts.URL + "/v2/runs/" + run.ID + "/logs?follow=1")
+	if err != nil {
+		t.Fatalf("GET follow: %v", err)
+	}
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	if err != nil || line != "first\n" {
+		t.Fatalf("first streamed line = %q, %v", line, err)
+	}
+	if current, _ := mgr.GetRun(run.ID); jobsRunFinished(current.Status) {
+		t.Fatalf("run finished before its first line was streamed")
+	}
+
+	if err := os.WriteFile(marker, nil, 0o600); err != nil {
+		t.Fatalf("release run: %v", err)
+	}
+	rest, err := io.ReadAll(reader)
+	if err != nil || string(rest) != "second\n" {
+		t.Fatalf("rest of stream = %q, %v", rest, err)
+	}
+}
+
+func TestJobsV2PruneRemovesLogsOfDeletedRuns(t *testing.T) {
+	mgr := newJobsV2ManagerWithoutLoops(t)
+	mgr.logDir = t.TempDir()
+	now := time.Now().UTC()
+	if _, err := mgr.db.Exec(`INSERT INTO jobs_v2 (id, name, enabled, runner_type, runner_config, trigger_type, trigger_config, created_at, updated_at) VALUES ('job_1', 'logs', 1, 'program', '{}', 'manual', '{}', ?, ?)`, now, now); err != nil {
+		t.Fatalf("insert job: %v", err)
+	}
+	if _, err := mgr.db.Exec(`INSERT INTO job_runs_v2 (id, job_id, attempt, trigger, scheduled_for, status) VALUES ('run_kept', 'job_1', 1, 'manual', ?, 'succeeded')`, now); err != nil {
+		t.Fatalf("insert run: %v", err)
+	}
+	for _, id := range []string{"run_kept", "run_gone"} {
+		if err := os.MkdirAll(mgr.runLogDir(id), 0o700); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+
+	if err := mgr.pruneOldData(now); err != nil {
+		t.Fatalf("pruneOldData: %v", err)
+	}
+	if _, err := os.Stat(mgr.runLogDir("run_kept")); err != nil {
+		t.Fatalf("logs of an existing run were removed: %v", err)
+	}
+	if _, err := os.Stat(mgr.runLogDir("run_gone")); !os.IsNotExist(err) {
+		t.Fatalf("logs of a deleted run were kept: %v", err)
+	}
+}
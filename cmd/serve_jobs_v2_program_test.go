@@ -9,7 +9,6 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
-	"syscall"
 	"testing"
 	"time"
 
@@ -83,8 +82,8 @@ func TestJobsV2ProgramRunner_CleansUpBackgroundChildOnSuccess(t *testing.T) {
 
 	pid := readJobsV2ProgramPID(t, pidPath)
 	defer func() {
-		if !testutil.ProcessHasExited(pid) {
-			_ = syscall.Kill(pid, syscall.SIGKILL)
+		if proc, err := os.FindProcess(pid); err == nil && !testutil.ProcessHasExited(pid) {
+			_ = proc.Kill()
 		}
 	}()
 
@@ -7,7 +7,10 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -454,6 +457,40 @@ func TestJobsV2ManualTriggerAndCancel(t *testing.T) {
 	}
 }
 
+func TestJobsV2TriggerJobIdempotentReturnsOriginalRun(t *testing.T) {
+	mgr, err := newJobsV2Manager(":memory:", 0, nil)
+	if err != nil {
+		t.Fatalf("newJobsV2Manager failed: %v", err)
+	}
+	defer func() { _ = mgr.Close() }()
+
+	job, err := mgr.CreateJob(jobsV2Job{
+		Name:          "trigger-idempotent",
+		Enabled:       true,
+		RunnerType:    jobsV2RunnerProgram,
+		RunnerConfig:  json.RawMessage(`{"command":"echo","args":["x"]}`),
+		TriggerType:   jobsV2TriggerManual,
+		TriggerConfig: json.RawMessage(`{}`),
+	})
+	if err != nil {
+		t.Fatalf("CreateJob failed: %v", err)
+	}
+	first, err := mgr.TriggerJobIdempotent(job.ID, "key-1")
+	if err != nil {
+		t.Fatalf("TriggerJobIdempotent failed: %v", err)
+	}
+	retry, err := mgr.TriggerJobIdempotent(job.ID, "key-1")
+	if err != nil {
+		t.Fatalf("retried TriggerJobIdempotent failed: %v", err)
+	}
+	if retry.ID != first.ID {
+		t.Fatalf("retry returned run %s, want original %s", retry.ID, first.ID)
+	}
+	if _, err := mgr.TriggerJobIdempotent(job.ID, "key-2"); err == nil {
+		t.Fatal("a new key should trigger again and hit the concurrency limit")
+	}
+}
+
 func TestJobsV2CancelRunAfterClaimedToRunningTransition(t *testing.T) {
 	mgr, err := newJobsV2Manager(":memory:", 0, nil)
 	if err != nil {
@@ -537,6 +574,79 @@ func TestJobsV2CancelRunAfterClaimedToRunningTransition(t *testing.T) {
 	}
 }
 
+func TestJobsV2CloseMarksInFlightRunsInterrupted(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "jobs_v2.db")
+	mgr, err := newJobsV2Manager(dbPath, 0, nil)
+	if err != nil {
+		t.Fatalf("newJobsV2Manager failed: %v", err)
+	}
+	job, err := mgr.CreateJob(jobsV2Job{
+		Name:          "interrupted-by-shutdown",
+		Enabled:       true,
+		RunnerType:    jobsV2RunnerProgram,
+		RunnerConfig:  json.RawMessage(`{"command":"echo","args":["x"]}`),
+		TriggerType:   jobsV2TriggerManual,
+		TriggerConfig: json.RawMessage(`{}`),
+	})
+	if err != nil {
+		t.Fatalf("CreateJob failed: %v", err)
+	}
+	run, err := mgr.TriggerJob(job.ID)
+	if err != nil {
+		t.Fatalf("TriggerJob failed: %v", err)
+	}
+	if _, err := mgr.db.Exec(`UPDATE job_runs_v2 SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, jobsV2RunClaimed, run.ID); err != nil {
+		t.Fatalf("mark run claimed: %v", err)
+	}
+
+	runnerStarted := make(chan struct{})
+	mgr.runners[jobsV2RunnerProgram] = jobsV2RunnerFunc(func(ctx context.Context, job jobsV2Job, pw progressWriter) (jobsV2RunResult, error) {
+		close(runnerStarted)
+		<-ctx.Done()
+		return jobsV2RunResult{Response: "partial"}, ctx.Err()
+	})
+	// Run it the way a worker does, so Close waits for it.
+	mgr.wg.Add(1)
+	go func() {
+		defer mgr.wg.Done()
+		mgr.executeRun(run)
+	}()
+	select {
+	case <-runnerStarted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("runner did not start")
+	}
+
+	mgr.StopClaiming()
+	if err := mgr.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	reopened, err := newJobsV2Manager(dbPath, 0, nil)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer func() { _ = reopened.Close() }()
+	finished, err := reopened.GetRun(run.ID)
+	if err != nil {
+		t.Fatalf("GetRun failed: %v", err)
+	}
+	if finished.Status != jobsV2RunCancelled || finished.ExitReason != exitReasonInterrupted || finished.Response != "partial" {
+		t.Fatalf("run = %s/%s response %q, want cancelled/%s with the partial response", finished.Status, finished.ExitReason, finished.Response, exitReasonInterrupted)
+	}
+	events, _, err := reopened.ListRunEvents(run.ID, 0, 100, 0)
+	if err != nil {
+		t.Fatalf("ListRunEvents failed: %v", err)
+	}
+	var types []string
+	for _, ev := range events {
+		types = append(types, ev.EventType)
+	}
+	if got := strings.Join(types, ","); !strings.Contains(got, "running,interrupted,cancelled") {
+		t.Fatalf("run events = %s, want running, interrupted, cancelled", got)
+	}
+}
+
 func TestJobsV2CancelRunDoesNotOverrideCompletedRun(t *testing.T) {
 	mgr, err := newJobsV2Manager(":memory:", 0, nil)
 	if err != nil {
@@ -798,6 +908,141 @@ func TestJobsV2PatchRespectsExplicitDisabled(t *testing.T) {
 	}
 }
 
+func TestJobsV2MergePatchMergesNestedFieldsAndRemovesNulls(t *testing.T) {
+	mgr, err := newJobsV2Manager(":memory:", 0, nil)
+	if err != nil {
+		t.Fatalf("newJobsV2Manager failed: %v", err)
+	}
+	defer func() { _ = mgr.Close() }()
+
+	srv := &serveServer{jobsV2: mgr}
+	created, err := mgr.CreateJob(jobsV2Job{
+		Name:           "merge-patch",
+		Enabled:        true,
+		RunnerType:     jobsV2RunnerProgram,
+		RunnerConfig:   json.RawMessage(`{"command":"echo","args":["x"]}`),
+		TriggerType:    jobsV2TriggerCron,
+		TriggerConfig:  json.RawMessage(`{"expression":"0 9 * * *","timezone":"Australia/Sydney"}`),
+		TimeoutSeconds: 60,
+		Labels:         json.RawMessage(`{"team":"infra"}`),
+	})
+	if err != nil {
+		t.Fatalf("CreateJob failed: %v", err)
+	}
+
+	patch := `{"trigger_config":{"expression":"0 3 * * *"},"runner_config":{"args":null},"timeout_seconds":null,"labels":null}`
+	req := httptest.NewRequest(http.MethodPatch, "/v2/jobs/"+created.ID, strings.NewReader(patch))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	rr := httptest.NewRecorder()
+	srv.handleJobV2ByID(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("patch status = %d, want 200 body=%s", rr.Code, rr.Body.String())
+	}
+	var job jobsV2Job
+	if err := json.Unmarshal(rr.Body.Bytes(), &job); err != nil {
+		t.Fatalf("decode job: %v", err)
+	}
+	if got := string(job.TriggerConfig); got != `{"expression":"0 3 * * *","timezone":"Australia/Sydney"}` {
+		t.Fatalf("trigger_config = %s, want expression replaced and timezone kept", got)
+	}
+	if got := string(job.RunnerConfig); got != `{"command":"echo"}` {
+		t.Fatalf("runner_config = %s, want args removed", got)
+	}
+	if job.TimeoutSeconds != 300 || len(job.Labels) != 0 {
+		t.Fatalf("timeout_seconds = %d, labels = %s; want defaults after null", job.TimeoutSeconds, job.Labels)
+	}
+	if job.Name != "merge-patch" || !job.Enabled {
+		t.Fatalf("untouched fields changed: %+v", job)
+	}
+
+	req = httptest.NewRequest(http.MethodPatch, "/v2/jobs/"+created.ID, strings.NewReader(`{"name":null}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	rr = httptest.NewRecorder()
+	srv.handleJobV2ByID(rr, req)
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "name is required") {
+		t.Fatalf("patch removing name status = %d body=%s, want 400 name is required", rr.Code, rr.Body.String())
+	}
+}
+
+func TestJobsV2RunsFilterByStatusAndTime(t *testing.T) {
+	mgr, err := newJobsV2Manager(":memory:", 0, nil)
+	if err != nil {
+		t.Fatalf("newJobsV2Manager failed: %v", err)
+	}
+	defer func() { _ = mgr.Close() }()
+
+	job, err := mgr.CreateJob(jobsV2Job{
+		Name:          "filtered-runs",
+		Enabled:       true,
+		RunnerType:    jobsV2RunnerProgram,
+		RunnerConfig:  json.RawMessage(`{"command":"echo","args":["x"]}`),
+		TriggerType:   jobsV2TriggerManual,
+		TriggerConfig: json.RawMessage(`{}`),
+	})
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+
+	now := time.Now().UTC()
+	for _, run := range []struct {
+		id      string
+		status  jobsV2RunStatus
+		created time.Time
+	}{
+		{"run_old_failed", jobsV2RunFailed, now.Add(-48 * time.Hour)},
+		{"run_new_failed", jobsV2RunFailed, now.Add(-2 * time.Hour)},
+		{"run_new_timeout", jobsV2RunTimedOut, now.Add(-1 * time.Hour)},
+		{"run_new_ok", jobsV2RunSucceeded, now.Add(-1 * time.Hour)},
+	} {
+		created := run.created.Format(jobsV2SQLiteTimeLayout)
+		if _, err := mgr.db.Exec(`INSERT INTO job_runs_v2 (id, job_id, attempt, trigger, scheduled_for, status, created_at, updated_at) VALUES (?, ?, 1, 'manual', ?, ?, ?, ?)`,
+			run.id, job.ID, run.created, run.status, created, created); err != nil {
+			t.Fatalf("insert run: %v", err)
+		}
+	}
+
+	srv := &serveServer{jobsV2: mgr}
+	list := func(query string) (int, []string) {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		srv.handleRunsV2(rr, httptest.NewRequest(http.MethodGet, "/v2/runs?summary=1&"+query, nil))
+		if rr.Code != http.StatusOK {
+			return rr.Code, nil
+		}
+		var resp struct {
+			Data  []jobsV2Run `json:"data"`
+			Total int         `json:"total"`
+		}
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode runs: %v", err)
+		}
+		if resp.Total != len(resp.Data) {
+			t.Fatalf("total = %d, want %d", resp.Total, len(resp.Data))
+		}
+		var ids []string
+		for _, run := range resp.Data {
+			ids = append(ids, run.ID)
+		}
+		sort.Strings(ids)
+		return rr.Code, ids
+	}
+
+	since := url.QueryEscape(now.Add(-24 * time.Hour).Format(time.RFC3339))
+	if _, ids := list("status=failed,timed_out&since=" + since); !reflect.DeepEqual(ids, []string{"run_new_failed", "run_new_timeout"}) {
+		t.Fatalf("status+since runs = %v", ids)
+	}
+	until := url.QueryEscape(now.Add(-90 * time.Minute).Format(time.RFC3339))
+	if _, ids := list("status=failed&until=" + until); !reflect.DeepEqual(ids, []string{"run_new_failed", "run_old_failed"}) {
+		t.Fatalf("status+until runs = %v", ids)
+	}
+	if code, _ := list("status=exploded"); code != http.StatusBadRequest {
+		t.Fatalf("unknown status code = %d, want 400", code)
+	}
+	if code, _ := list("since=yesterday"); code != http.StatusBadRequest {
+		t.Fatalf("bad since code = %d, want 400", code)
+	}
+}
+
 func TestJobsV2RecoverRunsCancelsCancelRequestedRuns(t *testing.T) {
 	mgr, err := newJobsV2Manager(":memory:", 0, nil)
 	if err != nil {
@@ -2247,3 +2492,162 @@ func TestJobsV2ListJobsIncludesLastRunSummary(t *testing.T) {
 		t.Fatalf("LastRun = %+v, want failed run_new with error", jobs[0].LastRun)
 	}
 }
+
+// TestJobsV2OverlapPolicyAcrossScheduledBoundary holds a run open across the
+// next scheduled time and checks what each overlap policy does with it.
+func TestJobsV2OverlapPolicyAcrossScheduledBoundary(t *testing.T) {
+	for _, policy := range []string{jobsV2OverlapSkip, jobsV2OverlapQueue, jobsV2OverlapCancelPrevious} {
+		t.Run(policy, func(t *testing.T) {
+			mgr, err := newJobsV2Manager(":memory:", 0, nil)
+			if err != nil {
+				t.Fatalf("newJobsV2Manager failed: %v", err)
+			}
+			defer func() { _ = mgr.Close() }()
+
+			started := make(chan struct{}, 4)
+			release := make(chan struct{})
+			mgr.runners[jobsV2RunnerProgram] = jobsV2RunnerFunc(func(ctx context.Context, job jobsV2Job, pw progressWriter) (jobsV2RunResult, error) {
+				started <- struct{}{}
+				<-release
+				return jobsV2RunResult{}, ctx.Err()
+			})
+
+			job, err := mgr.CreateJob(jobsV2Job{
+				Name:          "slow-" + policy,
+				Enabled:       true,
+				RunnerType:    jobsV2RunnerProgram,
+				RunnerConfig:  json.RawMessage(`{"command":"true"}`),
+				TriggerType:   jobsV2TriggerCron,
+				TriggerConfig: json.RawMessage(`{"expression":"0 0 1 1 *","timezone":"UTC"}`),
+				OverlapPolicy: policy,
+			})
+			if err != nil {
+				t.Fatalf("CreateJob failed: %v", err)
+			}
+			if job.OverlapPolicy != policy {
+				t.Fatalf("overlap_policy = %q, want %q", job.OverlapPolicy, policy)
+			}
+
+			// Both scheduled times are already due, as they are when the
+			// scheduler calls scheduleOne.
+			t0 := time.Now().UTC().Add(-10 * time.Minute).Truncate(time.Second)
+			if err := mgr.scheduleOne(job, t0); err != nil {
+				t.Fatalf("scheduleOne(first) failed: %v", err)
+			}
+			first, ok, err := mgr.claimNextRun()
+			if err != nil || !ok {
+				t.Fatalf("claim first run: ok=%t err=%v", ok, err)
+			}
+			firstDone := make(chan struct{})
+			go func() {
+				mgr.executeRun(first)
+				close(firstDone)
+			}()
+			select {
+			case <-started:
+			case <-time.After(2 * time.Second):
+				t.Fatal("first run did not start")
+			}
+
+			// The next scheduled time arrives while the first run is still going.
+			if err := mgr.scheduleOne(job, t0.Add(time.Minute)); err != nil {
+				t.Fatalf("scheduleOne(second) failed: %v", err)
+			}
+			second := jobsV2RunScheduledFor(t, mgr, job.ID, t0.Add(time.Minute))
+			if _, ok, err := mgr.claimNextRun(); err != nil || ok {
+				t.Fatalf("claimed a run while the first was active: ok=%t err=%v", ok, err)
+			}
+
+			switch policy {
+			case jobsV2OverlapSkip:
+				if second.Status != jobsV2RunSkipped || second.Error != "previous run still active" {
+					t.Fatalf("second run = %s %q, want skipped with reason", second.Status, second.Error)
+				}
+				events, _, err := mgr.ListRunEvents(second.ID, 0, 10, 0)
+				if err != nil || len(events) != 1 || events[0].EventType != "skipped" || !strings.Contains(string(events[0].Data), first.ID) {
+					t.Fatalf("skipped run events = %+v, %v", events, err)
+				}
+			case jobsV2OverlapQueue:
+				if second.Status != jobsV2RunQueued {
+					t.Fatalf("second run status = %s, want queued", second.Status)
+				}
+				// Only one run waits behind the active one.
+				if err := mgr.scheduleOne(job, t0.Add(2*time.Minute)); err != nil {
+					t.Fatalf("scheduleOne(third) failed: %v", err)
+				}
+				if third := jobsV2RunScheduledFor(t, mgr, job.ID, t0.Add(2*time.Minute)); third.Status != jobsV2RunSkipped {
+					t.Fatalf("third run status = %s, want skipped", third.Status)
+				}
+			case jobsV2OverlapCancelPrevious:
+				if second.Status != jobsV2RunQueued {
+					t.Fatalf("second run status = %s, want queued", second.Status)
+				}
+				current, err := mgr.GetRun(first.ID)
+				if err != nil || current.Status != jobsV2RunCancelRequested {
+					t.Fatalf("first run = %s, %v; want cancel_requested", current.Status, err)
+				}
+			}
+
+			close(release)
+			select {
+			case <-firstDone:
+			case <-time.After(2 * time.Second):
+				t.Fatal("first run did not finish")
+			}
+			wantFirst := jobsV2RunSucceeded
+			if policy == jobsV2OverlapCancelPrevious {
+				wantFirst = jobsV2RunCancelled
+			}
+			if current, err := mgr.GetRun(first.ID); err != nil || current.Status != wantFirst {
+				t.Fatalf("first run = %s, %v; want %s", current.Status, err, wantFirst)
+			}
+
+			next, ok, err := mgr.claimNextRun()
+			if err != nil {
+				t.Fatalf("claimNextRun failed: %v", err)
+			}
+			if policy == jobsV2OverlapSkip {
+				if ok {
+					t.Fatalf("skip policy left a run to claim: %s", next.ID)
+				}
+				return
+			}
+			if !ok || next.ID != second.ID {
+				t.Fatalf("claimed %q (ok=%t), want the waiting run %q", next.ID, ok, second.ID)
+			}
+			mgr.executeRun(next)
+			if current, err := mgr.GetRun(second.ID); err != nil || current.Status != jobsV2RunSucceeded {
+				t.Fatalf("second run = %s, %v; want succeeded", current.Status, err)
+			}
+		})
+	}
+}
+
+func jobsV2RunScheduledFor(t *testing.T, mgr *jobsV2Manager, jobID string, at time.Time) jobsV2Run {
+	t.Helper()
+	runs, _, err := mgr.ListRunSummaries(jobID, 50, 0)
+	if err != nil {
+		t.Fatalf("ListRunSummaries failed: %v", err)
+	}
+	for _, run := range runs {
+		if run.ScheduledFor.Equal(at) {
+			return run
+		}
+	}
+	t.Fatalf("no run scheduled for %s in %+v", at, runs)
+	return jobsV2Run{}
+}
+
+func TestJobsV2CreateJobRejectsUnknownOverlapPolicy(t *testing.T) {
+	mgr := newJobsV2ManagerWithoutLoops(t)
+	_, err := mgr.CreateJob(jobsV2Job{
+		Name:          "bad-overlap",
+		RunnerType:    jobsV2RunnerProgram,
+		RunnerConfig:  json.RawMessage(`{"command":"true"}`),
+		TriggerType:   jobsV2TriggerManual,
+		OverlapPolicy: "wait",
+	})
+	if err == nil || !strings.Contains(err.Error(), "overlap_policy") {
+		t.Fatalf("CreateJob error = %v, want overlap_policy validation error", err)
+	}
+}
@@ -0,0 +1,358 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Webhook events select which terminal run statuses post a notification.
+const (
+	jobsV2WebhookOnFailure = "on_failure"
+	jobsV2WebhookOnSuccess = "on_success"
+	jobsV2WebhookOnTimeout = "on_timeout"
+)
+
+var (
+	// jobsV2WebhookAttempts is how many times a delivery is tried before it is
+	// recorded as failed.
+	jobsV2WebhookAttempts = 3
+	// jobsV2WebhookRetryDelay is the wait before the first retry; it doubles
+	// after each failed attempt.
+	jobsV2WebhookRetryDelay = time.Second
+	// jobsV2WebhookTimeout bounds a single delivery attempt.
+	jobsV2WebhookTimeout = 10 * time.Second
+)
+
+// jobsV2Webhook is one entry of an llm job's runner_config.notifications.
+// Payload is a JSON template: {{job_name}}, {{job_id}}, {{run_id}},
+// {{status}}, {{duration}} and {{error}} are replaced with JSON-escaped
+// values, so they belong inside string literals. An empty payload sends
+// jobsV2WebhookDefaultPayload.
+type jobsV2Webhook struct {
+	URL     string   `json:"url"`
+	Events  []string `json:"events,omitempty"`
+	Payload string   `json:"payload,omitempty"`
+}
+
+// jobsV2WebhookDefaultPayload carries every field, plus a "text" summary that
+// Slack-compatible receivers display as-is.
+const jobsV2WebhookDefaultPayload = `{"text":"Job {{job_name}} {{status}} after {{duration}}","job_name":"{{job_name}}","job_id":"{{job_id}}","run_id":"{{run_id}}","status":"{{status}}","duration":"{{duration}}","error":"{{error}}"}`
+
+// jobsV2WebhookEventForStatus maps a terminal run status to its webhook
+// event. Cancelled runs have no event and never post.
+func jobsV2WebhookEventForStatus(status jobsV2RunStatus) string {
+	switch status {
+	case jobsV2RunFailed:
+		return jobsV2WebhookOnFailure
+	case jobsV2RunSucceeded:
+		return jobsV2WebhookOnSuccess
+	case jobsV2RunTimedOut:
+		return jobsV2WebhookOnTimeout
+	default:
+		return ""
+	}
+}
+
+// wants reports whether the webhook subscribes to event. No events means all
+// of them.
+func (w jobsV2Webhook) wants(event string) bool {
+	if event == "" {
+		return false
+	}
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if strings.TrimSpace(e) == event {
+			return true
+		}
+	}
+	return false
+}
+
+// validateJobsV2Webhooks checks notification URLs, events and that each
+// payload template renders to valid JSON.
+func validateJobsV2Webhooks(hooks []jobsV2Webhook) error {
+	for i, hook := range hooks {
+		u, err := url.Parse(strings.TrimSpace(hook.URL))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("notifications[%d].url must be an http or https URL", i)
+		}
+		for _, event := range hook.Events {
+			switch strings.TrimSpace(event) {
+			case jobsV2WebhookOnFailure, jobsV2WebhookOnSuccess, jobsV2WebhookOnTimeout:
+			default:
+				return fmt.Errorf("notifications[%d].events must contain only on_failure, on_success, on_timeout (got %q)", i, event)
+			}
+		}
+		sample := jobsV2WebhookValues{JobName: "job", JobID: "job_1", RunID: "run_1", Status: "failed", Duration: "1s", Error: `"quoted" error`}
+		if !json.Valid(renderJobsV2WebhookPayload(hook.Payload, sample)) {
+			return fmt.Errorf("notifications[%d].payload must be a JSON template with placeholders inside string values", i)
+		}
+	}
+	return nil
+}
+
+// jobsV2WebhookValues are the placeholder values for one run.
+type jobsV2WebhookValues struct {
+	JobName  string
+	JobID    string
+	RunID    string
+	Status   string
+	Duration string
+	Error    string
+}
+
+func renderJobsV2WebhookPayload(template string, v jobsV2WebhookValues) []byte {
+	if strings.TrimSpace(template) == "" {
+		template = jobsV2WebhookDefaultPayload
+	}
+	escape := func(s string) string {
+		quoted, _ := json.Marshal(s)
+		return string(quoted[1 : len(quoted)-1])
+	}
+	return []byte(strings.NewReplacer(
+		"{{job_name}}", escape(v.JobName),
+		"{{job_id}}", escape(v.JobID),
+		"{{run_id}}", escape(v.RunID),
+		"{{status}}", escape(v.Status),
+		"{{duration}}", escape(v.Duration),
+		"{{error}}", escape(v.Error),
+	).Replace(template))
+}
+
+// redactWebhookURL keeps only the scheme and host of a webhook URL. Slack,
+// Discord and most other services put the secret in the path or query.
+func redactWebhookURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return redactedWebhookURLMarker
+	}
+	redacted := u.Scheme + "://" + u.Host
+	if (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+		redacted += "/" + redactedWebhookURLMarker
+	}
+	return redacted
+}
+
+// redactJobsV2WebhookURLs returns job with notification URLs in an llm
+// runner_config redacted. Every job the API returns goes through it.
+func redactJobsV2WebhookURLs(job jobsV2Job) jobsV2Job {
+	if job.RunnerType != jobsV2RunnerLLM || len(job.RunnerConfig) == 0 {
+		return job
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(job.RunnerConfig, &obj); err != nil {
+		return job
+	}
+	var hooks []map[string]json.RawMessage
+	if err := json.Unmarshal(obj["notifications"], &hooks); err != nil || len(hooks) == 0 {
+		return job
+	}
+	for _, hook := range hooks {
+		var u string
+		if err := json.Unmarshal(hook["url"], &u); err == nil {
+			hook["url"], _ = json.Marshal(redactWebhookURL(u))
+		}
+	}
+	obj["notifications"], _ = json.Marshal(hooks)
+	if data, err := json.Marshal(obj); err == nil {
+		job.RunnerConfig = data
+	}
+	return job
+}
+
+// redactedWebhookURLMarker appears in every URL redactWebhookURL rewrites.
+const redactedWebhookURLMarker = "[REDACTED]"
+
+// restoreJobsV2WebhookURLs puts back the stored URL of each notification in
+// next whose URL is the redacted form of a stored one, so a definition read
+// from the API can be saved as is. A redacted URL that matches no stored
+// webhook is an error rather than a broken notification.
+func restoreJobsV2WebhookURLs(stored, next json.RawMessage) (json.RawMessage, error) {
+	if !bytes.Contains(next, []byte(redactedWebhookURLMarker)) {
+		return next, nil
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(next, &obj); err != nil {
+		return next, nil
+	}
+	var hooks []map[string]json.RawMessage
+	if err := json.Unmarshal(obj["notifications"], &hooks); err != nil || len(hooks) == 0 {
+		return next, nil
+	}
+	var current jobsV2LLMConfig
+	_ = json.Unmarshal([]byte(stringOrEmptyRaw(stored, "{}")), &current)
+
+	for i, hook := range hooks {
+		var u string
+		if err := json.Unmarshal(hook["url"], &u); err != nil || !strings.Contains(u, redactedWebhookURLMarker) {
+			continue
+		}
+		restored := ""
+		if i < len(current.Notifications) && redactWebhookURL(current.Notifications[i].URL) == u {
+			restored = current.Notifications[i].URL
+		}
+		for _, prev := range current.Notifications {
+			if restored == "" && redactWebhookURL(prev.URL) == u {
+				restored = prev.URL
+			}
+		}
+		if restored == "" {
+			return nil, fmt.Errorf("llm runner_config.notifications[%d].url is redacted; give the full webhook URL", i)
+		}
+		hook["url"], _ = json.Marshal(restored)
+	}
+	obj["notifications"], _ = json.Marshal(hooks)
+	return json.Marshal(obj)
+}
+
+// lastRunErrorMessage returns the error of the run's latest event that
+// carries one, skipping notification deliveries, or the run's stored error
+// when no event does.
+func (m *jobsV2Manager) lastRunErrorMessage(run jobsV2Run) string {
+	rows, err := m.db.Query(`SELECT event_type, data FROM job_run_events_v2 WHERE run_id = ? ORDER BY id DESC`, run.ID)
+	if err != nil {
+		return run.Error
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var eventType string
+		var data sql.NullString
+		if err := rows.Scan(&eventType, &data); err != nil {
+			break
+		}
+		if !data.Valid || strings.HasPrefix(eventType, "webhook_") || eventType == "notify_failed" {
+			continue
+		}
+		var payload struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal([]byte(data.String), &payload) == nil && strings.TrimSpace(payload.Error) != "" {
+			return strings.TrimSpace(payload.Error)
+		}
+	}
+	return run.Error
+}
+
+func (m *jobsV2Manager) enqueueRunWebhooks(run jobsV2Run) {
+	if m == nil || jobsV2WebhookEventForStatus(run.Status) == "" {
+		return
+	}
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.deliverRunWebhooks(run)
+	}()
+}
+
+// deliverRunWebhooks posts the run's result to every webhook of its job that
+// subscribes to the run's terminal status, recording a run event per webhook.
+func (m *jobsV2Manager) deliverRunWebhooks(run jobsV2Run) {
+	event := jobsV2WebhookEventForStatus(run.Status)
+	job, err := m.GetJob(run.JobID)
+	if err != nil || job.RunnerType != jobsV2RunnerLLM {
+		return
+	}
+	var cfg jobsV2LLMConfig
+	if err := json.Unmarshal(job.RunnerConfig, &cfg); err != nil {
+		return
+	}
+	values := jobsV2WebhookValues{
+		JobName: job.Name,
+		JobID:   job.ID,
+		RunID:   run.ID,
+		Status:  string(run.Status),
+		Error:   m.lastRunErrorMessage(run),
+	}
+	if run.StartedAt != nil && run.FinishedAt != nil {
+		values.Duration = run.FinishedAt.Sub(*run.StartedAt).Round(time.Second).String()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if m.done != nil {
+		go func() {
+			select {
+			case <-m.done:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	for _, hook := range cfg.Notifications {
+		if !hook.wants(event) {
+			continue
+		}
+		target := redactWebhookURL(hook.URL)
+		attempts, statusCode, err := postJobsV2Webhook(ctx, hook.URL, renderJobsV2WebhookPayload(hook.Payload, values))
+		data := map[string]any{
+			"url":      target,
+			"event":    event,
+			"attempts": attempts,
+		}
+		if statusCode != 0 {
+			data["status_code"] = statusCode
+		}
+		if err != nil {
+			data["error"] = err.Error()
+			_ = m.addRunEvent(run.ID, "webhook_failed", "webhook to "+target+" failed", data)
+			continue
+		}
+		_ = m.addRunEvent(run.ID, "webhook_delivered", "webhook delivered to "+target, data)
+	}
+}
+
+// postJobsV2Webhook POSTs payload to rawURL, retrying transport errors, 429
+// and 5xx responses with exponential backoff. Errors never include the URL.
+func postJobsV2Webhook(ctx context.Context, rawURL string, payload []byte) (attempts, statusCode int, err error) {
+	delay := jobsV2WebhookRetryDelay
+	for attempts = 1; ; attempts++ {
+		var retry bool
+		statusCode, retry, err = postJobsV2WebhookOnce(ctx, rawURL, payload)
+		if err == nil || !retry || attempts >= jobsV2WebhookAttempts {
+			return attempts, statusCode, err
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return attempts, statusCode, err
+		}
+		delay *= 2
+	}
+}
+
+func postJobsV2WebhookOnce(ctx context.Context, rawURL string, payload []byte) (statusCode int, retry bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, jobsV2WebhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rawURL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, false, errors.New("invalid webhook URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return 0, ctx.Err() == nil || errors.Is(ctx.Err(), context.DeadlineExceeded), err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, false, nil
+	}
+	retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+	return resp.StatusCode, retry, fmt.Errorf("HTTP %d", resp.StatusCode)
+}
@@ -0,0 +1,337 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+type webhookReceiver struct {
+	mu       sync.Mutex
+	payloads []map[string]any
+	statuses []int // responses to send in order; 200 once exhausted
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var payload map[string]any
+	_ = json.Unmarshal(body, &payload)
+	r.payloads = append(r.payloads, payload)
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (r *webhookReceiver) received() []map[string]any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]map[string]any(nil), r.payloads...)
+}
+
+func useFastJobsV2WebhookRetries(t *testing.T) {
+	t.Helper()
+	orig := jobsV2WebhookRetryDelay
+	jobsV2WebhookRetryDelay = time.Millisecond
+	t.Cleanup(func() { jobsV2WebhookRetryDelay = orig })
+}
+
+func newWebhookTestManager(t *testing.T) *jobsV2Manager {
+	t.Helper()
+	mgr, err := newJobsV2Manager(":memory:", 1, func(ctx context.Context, cfg jobsV2LLMConfig, onEvent func(llm.Event)) (serveJobsExecResult, error) {
+		if cfg.Instructions == "fail" {
+			return serveJobsExecResult{}, errors.New(`tool "deploy" exploded`)
+		}
+		onEvent(llm.Event{Type: llm.EventTextDelta, Text: "all good"})
+		return serveJobsExecResult{}, nil
+	})
+	if err != nil {
+		t.Fatalf("newJobsV2Manager: %v", err)
+	}
+	t.Cleanup(func() { _ = mgr.Close() })
+	return mgr
+}
+
+func createWebhookTestJob(t *testing.T, mgr *jobsV2Manager, name, instructions string, hooks []jobsV2Webhook) jobsV2Job {
+	t.Helper()
+	cfg, err := json.Marshal(map[string]any{
+		"agent_name":    "developer",
+		"instructions":  instructions,
+		"cwd":           ".",
+		"notifications": hooks,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	job, err := mgr.CreateJob(jobsV2Job{
+		Name:           name,
+		Enabled:        true,
+		RunnerType:     jobsV2RunnerLLM,
+		RunnerConfig:   cfg,
+		TriggerType:    jobsV2TriggerManual,
+		TriggerConfig:  json.RawMessage(`{}`),
+		TimeoutSeconds: 30,
+	})
+	if err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+	return job
+}
+
+func waitForJobsV2RunEvent(t *testing.T, mgr *jobsV2Manager, runID, eventType string) jobsV2RunEvent {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		events, _, err := mgr.ListRunEvents(runID, 0, 100, 0)
+		if err == nil {
+			for _, ev := range events {
+				if ev.EventType == eventType {
+					return ev
+				}
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s event on run %s", eventType, runID)
+	return jobsV2RunEvent{}
+}
+
+func TestJobsV2WebhookFailureOnlyFilterAndPayload(t *testing.T) {
+	receiver := &webhookReceiver{}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+	hookURL := srv.URL + "/services/T000/B000/secret-token"
+
+	mgr := newWebhookTestManager(t)
+	hooks := []jobsV2Webhook{{
+		URL:     hookURL,
+		Events:  []string{jobsV2WebhookOnFailure},
+		Payload: `{"text":"{{job_name}} {{status}}: {{error}}","run":"{{run_id}}","took":"{{duration}}"}`,
+	}}
+	okJob := createWebhookTestJob(t, mgr, "nightly-ok", "succeed", hooks)
+	failJob := createWebhookTestJob(t, mgr, "nightly-fail", "fail", hooks)
+
+	okRun, err := mgr.TriggerJob(okJob.ID)
+	if err != nil {
+		t.Fatalf("TriggerJob: %v", err)
+	}
+	waitForJobsV2RunEvent(t, mgr, okRun.ID, string(jobsV2RunSucceeded))
+
+	failRun, err := mgr.TriggerJob(failJob.ID)
+	if err != nil {
+		t.Fatalf("TriggerJob: %v", err)
+	}
+	delivered := waitForJobsV2RunEvent(t, mgr, failRun.ID, "webhook_delivered")
+	if err := mgr.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	payloads := receiver.received()
+	if len(payloads) != 1 {
+		t.Fatalf("received %d webhooks, want only the failure: %+v", len(payloads), payloads)
+	}
+	got := payloads[0]
+	if want := `nightly-fail failed: tool "deploy" exploded`; got["text"] != want {
+		t.Fatalf("text = %q, want %q", got["text"], want)
+	}
+	if got["run"] != failRun.ID {
+		t.Fatalf("run = %q, want %q", got["run"], failRun.ID)
+	}
+	if took, _ := got["took"].(string); !strings.HasSuffix(took, "s") {
+		t.Fatalf("took = %q, want a duration", got["took"])
+	}
+
+	if strings.Contains(string(delivered.Data), "secret-token") || strings.Contains(delivered.Message, "secret-token") {
+		t.Fatalf("delivery event leaks the webhook secret: %s %s", delivered.Message, delivered.Data)
+	}
+	var data map[string]any
+	if err := json.Unmarshal(delivered.Data, &data); err != nil {
+		t.Fatalf("decode event data: %v", err)
+	}
+	if data["url"] != srv.URL+"/[REDACTED]" || data["status_code"] != float64(http.StatusOK) {
+		t.Fatalf("event data = %v, want redacted url and status 200", data)
+	}
+}
+
+func TestJobsV2WebhookRetriesThenRecordsFailure(t *testing.T) {
+	useFastJobsV2WebhookRetries(t)
+	flaky := &webhookReceiver{statuses: []int{http.StatusBadGateway}}
+	flakySrv := httptest.NewServer(flaky)
+	defer flakySrv.Close()
+	down := &webhookReceiver{statuses: []int{500, 500, 500, 500}}
+	downSrv := httptest.NewServer(down)
+	defer downSrv.Close()
+
+	mgr := newWebhookTestManager(t)
+	job := createWebhookTestJob(t, mgr, "nightly", "succeed", []jobsV2Webhook{
+		{URL: flakySrv.URL + "/hook?token=abc", Events: []string{jobsV2WebhookOnSuccess}},
+		{URL: downSrv.URL + "/hook"},
+	})
+	run, err := mgr.TriggerJob(job.ID)
+	if err != nil {
+		t.Fatalf("TriggerJob: %v", err)
+	}
+	delivered := waitForJobsV2RunEvent(t, mgr, run.ID, "webhook_delivered")
+	failed := waitForJobsV2RunEvent(t, mgr, run.ID, "webhook_failed")
+
+	var data map[string]any
+	_ = json.Unmarshal(delivered.Data, &data)
+	if data["attempts"] != float64(2) {
+		t.Fatalf("delivered attempts = %v, want 2", data["attempts"])
+	}
+	_ = json.Unmarshal(failed.Data, &data)
+	if data["attempts"] != float64(jobsV2WebhookAttempts) || data["error"] != "HTTP 500" {
+		t.Fatalf("failed event data = %v, want %d attempts and HTTP 500", data, jobsV2WebhookAttempts)
+	}
+	if got := len(down.received()); got != jobsV2WebhookAttempts {
+		t.Fatalf("down receiver got %d attempts, want %d", got, jobsV2WebhookAttempts)
+	}
+	// The default payload carries the run result.
+	payload := flaky.received()[1]
+	if payload["job_name"] != "nightly" || payload["status"] != "succeeded" || payload["run_id"] != run.ID {
+		t.Fatalf("default payload = %v", payload)
+	}
+}
+
+func TestValidateJobsV2Webhooks(t *testing.T) {
+	tests := []struct {
+		name  string
+		hooks []jobsV2Webhook
+		want  string
+	}{
+		{name: "valid", hooks: []jobsV2Webhook{{URL: "https://hooks.slack.com/x", Events: []string{"on_failure", "on_timeout"}, Payload: `{"text":"{{job_name}} {{status}}"}`}}},
+		{name: "bad scheme", hooks: []jobsV2Webhook{{URL: "ftp://example.com/x"}}, want: "notifications[0].url"},
+		{name: "unknown event", hooks: []jobsV2Webhook{{URL: "https://example.com", Events: []string{"on_cancel"}}}, want: "notifications[0].events"},
+		{name: "placeholder outside string", hooks: []jobsV2Webhook{{URL: "https://example.com", Payload: `{"error":{{error}}}`}}, want: "notifications[0].payload"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateJobsV2Webhooks(tt.hooks)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %v, want mention of %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactJobsV2WebhookURLs(t *testing.T) {
+	job := jobsV2Job{
+		RunnerType:   jobsV2RunnerLLM,
+		RunnerConfig: json.RawMessage(`{"agent_name":"a","notifications":[{"url":"https://discord.com/api/webhooks/1/secret","events":["on_failure"]}]}`),
+	}
+	got := redactJobsV2WebhookURLs(job)
+	if strings.Contains(string(got.RunnerConfig), "secret") {
+		t.Fatalf("runner_config still contains the secret: %s", got.RunnerConfig)
+	}
+	if !strings.Contains(string(got.RunnerConfig), `"https://discord.com/[REDACTED]"`) || !strings.Contains(string(got.RunnerConfig), `"on_failure"`) {
+		t.Fatalf("redacted runner_config = %s", got.RunnerConfig)
+	}
+	if !strings.Contains(string(job.RunnerConfig), "secret") {
+		t.Fatal("original job was modified")
+	}
+}
+
+func TestJobsV2APIRedactsWebhookURLsAndKeepsThemOnRoundTrip(t *testing.T) {
+	mgr := newWebhookTestManager(t)
+	srv := &serveServer{jobsV2: mgr}
+	const secretURL = "https://hooks.slack.com/services/T000/B000/secret-token"
+
+	serve := func(method, path, contentType, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		rr := httptest.NewRecorder()
+		if path == "/v2/jobs" {
+			srv.handleJobsV2(rr, req)
+		} else {
+			srv.handleJobV2ByID(rr, req)
+		}
+		if strings.Contains(rr.Body.String(), "secret-token") {
+			t.Fatalf("%s %s leaks the webhook secret: %s", method, path, rr.Body.String())
+		}
+		return rr
+	}
+
+	rr := serve(http.MethodPost, "/v2/jobs", "application/json", `{"name":"nightly","enabled":true,"runner_type":"llm",`+
+		`"runner_config":{"agent_name":"developer","instructions":"do it","cwd":".","notifications":[{"url":"`+secretURL+`"}]},`+
+		`"trigger_type":"manual","trigger_config":{},"timeout_seconds":30}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create status = %d body=%s", rr.Code, rr.Body.String())
+	}
+	var job jobsV2Job
+	if err := json.Unmarshal(rr.Body.Bytes(), &job); err != nil {
+		t.Fatalf("decode job: %v", err)
+	}
+	serve(http.MethodGet, "/v2/jobs", "", "")
+	rr = serve(http.MethodGet, "/v2/jobs/"+job.ID, "", "")
+
+	// Sending back the redacted definition keeps the stored URL.
+	var got jobsV2Job
+	_ = json.Unmarshal(rr.Body.Bytes(), &got)
+	patch, _ := json.Marshal(map[string]any{"name": "nightly-2", "runner_config": got.RunnerConfig})
+	if rr := serve(http.MethodPatch, "/v2/jobs/"+job.ID, "application/json", string(patch)); rr.Code != http.StatusOK {
+		t.Fatalf("patch status = %d body=%s", rr.Code, rr.Body.String())
+	}
+	if rr := serve(http.MethodPatch, "/v2/jobs/"+job.ID, "application/merge-patch+json", string(patch)); rr.Code != http.StatusOK {
+		t.Fatalf("merge patch status = %d body=%s", rr.Code, rr.Body.String())
+	}
+	serve(http.MethodPost, "/v2/jobs/"+job.ID+"/pause", "", "")
+	serve(http.MethodPost, "/v2/jobs/"+job.ID+"/resume", "", "")
+
+	stored, err := mgr.GetJob(job.ID)
+	if err != nil {
+		t.Fatalf("GetJob: %v", err)
+	}
+	if !strings.Contains(string(stored.RunnerConfig), secretURL) {
+		t.Fatalf("stored runner_config lost the webhook URL: %s", stored.RunnerConfig)
+	}
+
+	unknown := `{"runner_config":{"agent_name":"developer","instructions":"do it","cwd":".","notifications":[{"url":"https://example.com/[REDACTED]"}]}}`
+	rr = serve(http.MethodPatch, "/v2/jobs/"+job.ID, "application/merge-patch+json", unknown)
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "notifications[0].url is redacted") {
+		t.Fatalf("unknown redacted URL: status = %d body=%s, want a 400", rr.Code, rr.Body.String())
+	}
+}
+
+func TestJobsV2LastRunErrorMessageUsesLatestErrorEvent(t *testing.T) {
+	mgr, err := newJobsV2Manager(":memory:", 0, nil)
+	if err != nil {
+		t.Fatalf("newJobsV2Manager: %v", err)
+	}
+	t.Cleanup(func() { _ = mgr.Close() })
+	job := createWebhookTestJob(t, mgr, "nightly", "fail", nil)
+	run, err := mgr.TriggerJob(job.ID)
+	if err != nil {
+		t.Fatalf("TriggerJob: %v", err)
+	}
+	run.Error = "exit status 1"
+	if got := mgr.lastRunErrorMessage(run); got != "exit status 1" {
+		t.Fatalf("without events = %q, want the run error", got)
+	}
+	_ = mgr.addRunEvent(run.ID, "tool_end", "tool failed", map[string]any{"error": "first"})
+	_ = mgr.addRunEvent(run.ID, "failed", "run finished", map[string]any{"error": `tool "deploy" exploded`})
+	_ = mgr.addRunEvent(run.ID, "webhook_failed", "webhook failed", map[string]any{"error": "HTTP 500"})
+	if got := mgr.lastRunErrorMessage(run); got != `tool "deploy" exploded` {
+		t.Fatalf("lastRunErrorMessage = %q, want the latest run error event", got)
+	}
+}
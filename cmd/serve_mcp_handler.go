@@ -191,7 +191,9 @@ func (rt *serveRuntime) registerMCPToolsForServersLocked(serverNames []string) {
 		if server == "" || !requested[server] {
 			continue
 		}
-		rt.engine.Tools().Register(mcp.NewMCPTool(rt.mcpManager, spec))
+		if err := rt.engine.Tools().Register(mcp.NewMCPTool(rt.mcpManager, spec)); err != nil {
+			log.Printf("[serve] skipping MCP %v", err)
+		}
 	}
 }
 
@@ -35,6 +35,10 @@ func TestMain(m *testing.M) {
 		fmt.Fprintf(os.Stderr, "set XDG_DATA_HOME: %v\n", err)
 		os.Exit(1)
 	}
+	if err := os.Setenv("XDG_CACHE_HOME", filepath.Join(dataHome, "cache")); err != nil {
+		fmt.Fprintf(os.Stderr, "set XDG_CACHE_HOME: %v\n", err)
+		os.Exit(1)
+	}
 	code := m.Run()
 	_ = os.RemoveAll(dataHome)
 	os.Exit(code)
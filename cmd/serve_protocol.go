@@ -624,6 +624,13 @@ func requireJSONContentType(r *http.Request) error {
 	return nil
 }
 
+// isMergePatchContentType reports whether the request body is an RFC 7386
+// JSON merge patch.
+func isMergePatchContentType(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "application/merge-patch+json"
+}
+
 func sessionOrRandomID(sessionID string) string {
 	if sessionID != "" {
 		return sanitizeID(sessionID)
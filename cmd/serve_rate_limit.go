@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const serveRateLimitWindow = time.Minute
+
+// serveRateLimits configures serveRateLimiter. A zero or negative value
+// disables that limit.
+type serveRateLimits struct {
+	maxConcurrentRuns        int // in-flight generation requests across all sessions
+	sessionMessagesPerMinute int // generation requests per session
+	newSessionsPerMinute     int // requests that start a session runtime, per source IP
+}
+
+func (l serveRateLimits) enabled() bool {
+	return l.maxConcurrentRuns > 0 || l.sessionMessagesPerMinute > 0 || l.newSessionsPerMinute > 0
+}
+
+// serveRateLimitError is returned by serveRateLimiter.acquire when a request
+// would exceed a limit. retryAfter is a hint for when the request may succeed.
+type serveRateLimitError struct {
+	limit      string
+	retryAfter time.Duration
+}
+
+func (e *serveRateLimitError) Error() string {
+	return fmt.Sprintf("rate limited: %s; retry after %ds", e.limit, e.retryAfterSeconds())
+}
+
+func (e *serveRateLimitError) retryAfterSeconds() int {
+	return max(1, int(math.Ceil(e.retryAfter.Seconds())))
+}
+
+// serveRateLimiter guards the generation endpoints against runaway clients.
+// Per-minute limits use a sliding window of request timestamps per key.
+type serveRateLimiter struct {
+	mu          sync.Mutex
+	limits      serveRateLimits
+	active      int
+	sessionHits map[string][]time.Time
+	ipHits      map[string][]time.Time
+	now         func() time.Time
+}
+
+func newServeRateLimiter(limits serveRateLimits) *serveRateLimiter {
+	return &serveRateLimiter{
+		limits:      limits,
+		sessionHits: make(map[string][]time.Time),
+		ipHits:      make(map[string][]time.Time),
+		now:         time.Now,
+	}
+}
+
+// acquire admits one generation request for sessionID from ip. newSession
+// marks requests that will create a session runtime. On success the caller
+// must call release once the request finishes.
+func (l *serveRateLimiter) acquire(sessionID, ip string, newSession bool) (release func(), limitErr *serveRateLimitError) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+
+	if limit := l.limits.maxConcurrentRuns; limit > 0 && l.active >= limit {
+		return nil, &serveRateLimitError{
+			limit:      fmt.Sprintf("%d concurrent requests in progress", limit),
+			retryAfter: time.Second,
+		}
+	}
+	var sessionWindow, ipWindow []time.Time
+	if limit := l.limits.sessionMessagesPerMinute; limit > 0 && sessionID != "" {
+		sessionWindow = pruneRateWindow(l.sessionHits[sessionID], now)
+		if len(sessionWindow) >= limit {
+			l.sessionHits[sessionID] = sessionWindow
+			return nil, &serveRateLimitError{
+				limit:      fmt.Sprintf("%d messages per minute for this session", limit),
+				retryAfter: sessionWindow[0].Add(serveRateLimitWindow).Sub(now),
+			}
+		}
+	}
+	if limit := l.limits.newSessionsPerMinute; limit > 0 && newSession && ip != "" {
+		ipWindow = pruneRateWindow(l.ipHits[ip], now)
+		if len(ipWindow) >= limit {
+			l.ipHits[ip] = ipWindow
+			return nil, &serveRateLimitError{
+				limit:      fmt.Sprintf("%d new sessions per minute from %s", limit, ip),
+				retryAfter: ipWindow[0].Add(serveRateLimitWindow).Sub(now),
+			}
+		}
+	}
+
+	if l.limits.sessionMessagesPerMinute > 0 && sessionID != "" {
+		l.sessionHits[sessionID] = append(sessionWindow, now)
+	}
+	if l.limits.newSessionsPerMinute > 0 && newSession && ip != "" {
+		l.ipHits[ip] = append(ipWindow, now)
+	}
+	l.pruneIdleLocked(now)
+	l.active++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			l.active--
+			l.mu.Unlock()
+		})
+	}, nil
+}
+
+// pruneIdleLocked drops keys whose windows have fully expired so the maps do
+// not grow with every session and client ever seen.
+func (l *serveRateLimiter) pruneIdleLocked(now time.Time) {
+	for key, hits := range l.sessionHits {
+		if len(pruneRateWindow(hits, now)) == 0 {
+			delete(l.sessionHits, key)
+		}
+	}
+	for key, hits := range l.ipHits {
+		if len(pruneRateWindow(hits, now)) == 0 {
+			delete(l.ipHits, key)
+		}
+	}
+}
+
+func pruneRateWindow(hits []time.Time, now time.Time) []time.Time {
+	cutoff := now.Add(-serveRateLimitWindow)
+	i := 0
+	for i < len(hits) && !hits[i].After(cutoff) {
+		i++
+	}
+	return hits[i:]
+}
+
+func remoteIP(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
+
+// admitGeneration applies the configured rate limits to a request that is
+// about to run the model for sessionID. When a limit is hit it writes a 429
+// with a Retry-After header and a machine-readable "rate_limited" code, and
+// returns ok=false.
+func (s *serveServer) admitGeneration(w http.ResponseWriter, r *http.Request, sessionID string, anthropic bool) (release func(), ok bool) {
+	if s.rateLimiter == nil {
+		return func() {}, true
+	}
+	newSession := true
+	if s.sessionMgr != nil {
+		_, loaded := s.sessionMgr.Get(sessionID)
+		newSession = !loaded
+	}
+	release, limitErr := s.rateLimiter.acquire(sessionID, remoteIP(r.RemoteAddr), newSession)
+	if limitErr != nil {
+		w.Header().Set("Retry-After", strconv.Itoa(limitErr.retryAfterSeconds()))
+		body := map[string]any{
+			"type":        "rate_limit_error",
+			"code":        "rate_limited",
+			"message":     limitErr.Error(),
+			"retry_after": limitErr.retryAfterSeconds(),
+		}
+		if anthropic {
+			writeJSON(w, http.StatusTooManyRequests, map[string]any{"type": "error", "error": body})
+		} else {
+			writeJSON(w, http.StatusTooManyRequests, map[string]any{"error": body})
+		}
+		return nil, false
+	}
+	return release, true
+}
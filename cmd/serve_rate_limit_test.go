@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestServeRateLimiter(limits serveRateLimits) (*serveRateLimiter, *time.Time) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newServeRateLimiter(limits)
+	limiter.now = func() time.Time { return now }
+	return limiter, &now
+}
+
+func TestServeRateLimiterConcurrentRuns(t *testing.T) {
+	limiter, _ := newTestServeRateLimiter(serveRateLimits{maxConcurrentRuns: 2})
+
+	releaseA, err := limiter.acquire("a", "10.0.0.1", false)
+	if err != nil {
+		t.Fatalf("first acquire: %v", err)
+	}
+	if _, err := limiter.acquire("b", "10.0.0.1", false); err != nil {
+		t.Fatalf("second acquire: %v", err)
+	}
+	if _, err := limiter.acquire("c", "10.0.0.2", false); err == nil {
+		t.Fatal("third concurrent acquire should be rate limited")
+	}
+
+	releaseA()
+	releaseA() // release is idempotent
+	if _, err := limiter.acquire("c", "10.0.0.2", false); err != nil {
+		t.Fatalf("acquire after release: %v", err)
+	}
+	if _, err := limiter.acquire("d", "10.0.0.2", false); err == nil {
+		t.Fatal("double release must not free two slots")
+	}
+}
+
+func TestServeRateLimiterSessionMessagesPerMinute(t *testing.T) {
+	limiter, now := newTestServeRateLimiter(serveRateLimits{sessionMessagesPerMinute: 3})
+
+	for i := 0; i < 3; i++ {
+		release, err := limiter.acquire("sess", "10.0.0.1", false)
+		if err != nil {
+			t.Fatalf("acquire %d: %v", i, err)
+		}
+		release()
+		*now = now.Add(10 * time.Second)
+	}
+	_, err := limiter.acquire("sess", "10.0.0.1", false)
+	if err == nil {
+		t.Fatal("fourth message within a minute should be rate limited")
+	}
+	// The oldest request was 30s ago, so it leaves the window in 30s.
+	if got := err.retryAfterSeconds(); got != 30 {
+		t.Fatalf("retryAfterSeconds = %d, want 30", got)
+	}
+	if release, err := limiter.acquire("other", "10.0.0.1", false); err != nil {
+		t.Fatalf("other session should not share the limit: %v", err)
+	} else {
+		release()
+	}
+
+	*now = now.Add(31 * time.Second)
+	if _, err := limiter.acquire("sess", "10.0.0.1", false); err != nil {
+		t.Fatalf("acquire after window slid: %v", err)
+	}
+}
+
+func TestServeRateLimiterNewSessionsPerIP(t *testing.T) {
+	limiter, now := newTestServeRateLimiter(serveRateLimits{newSessionsPerMinute: 2})
+
+	for _, id := range []string{"a", "b"} {
+		if _, err := limiter.acquire(id, "10.0.0.1", true); err != nil {
+			t.Fatalf("acquire %s: %v", id, err)
+		}
+	}
+	if _, err := limiter.acquire("c", "10.0.0.1", true); err == nil {
+		t.Fatal("third new session from the same IP should be rate limited")
+	}
+	if _, err := limiter.acquire("a", "10.0.0.1", false); err != nil {
+		t.Fatalf("existing sessions are not counted against the new-session limit: %v", err)
+	}
+	if _, err := limiter.acquire("d", "10.0.0.2", true); err != nil {
+		t.Fatalf("another IP has its own limit: %v", err)
+	}
+
+	*now = now.Add(2 * time.Minute)
+	if _, err := limiter.acquire("c", "10.0.0.1", true); err != nil {
+		t.Fatalf("acquire after window expired: %v", err)
+	}
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	if _, ok := limiter.ipHits["10.0.0.2"]; ok {
+		t.Fatal("expired IP windows should be pruned")
+	}
+}
+
+func TestAdmitGenerationWritesRateLimitedError(t *testing.T) {
+	s := &serveServer{rateLimiter: newServeRateLimiter(serveRateLimits{maxConcurrentRuns: 1})}
+	req := httptest.NewRequest(http.MethodPost, "/v1/responses", nil)
+
+	release, ok := s.admitGeneration(httptest.NewRecorder(), req, "sess", false)
+	if !ok {
+		t.Fatal("first request should be admitted")
+	}
+	defer release()
+
+	rec := httptest.NewRecorder()
+	if _, ok := s.admitGeneration(rec, req, "sess", false); ok {
+		t.Fatal("second concurrent request should be rejected")
+	}
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "1" {
+		t.Fatalf("Retry-After = %q, want 1", got)
+	}
+	var body struct {
+		Error struct {
+			Type       string `json:"type"`
+			Code       string `json:"code"`
+			RetryAfter int    `json:"retry_after"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.Error.Code != "rate_limited" || body.Error.Type != "rate_limit_error" || body.Error.RetryAfter != 1 {
+		t.Fatalf("error body = %+v", body.Error)
+	}
+}
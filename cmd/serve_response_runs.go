@@ -96,6 +96,8 @@ type responseRun struct {
 	eventStart         int
 	minReplayAfter     int64
 	maxRetainedEvents  int
+	maxRetainedBytes   int
+	retainedBytes      int // total len(Data) of events[eventStart:]
 	recoveryMessages   []responseRunRecoveryMessage
 	recoveryEvents     []responseRunRecoveryEvent
 	nextMessageOrdinal int64
@@ -161,6 +163,7 @@ func newResponseRun(respID, sessionID, previousResponseID, model string, created
 		created:            created,
 		status:             "in_progress",
 		maxRetainedEvents:  defaultResponseRunReplayLimit,
+		maxRetainedBytes:   defaultResponseRunReplayBytes,
 		currentAssistant:   -1,
 		currentToolGroup:   -1,
 		segmentRanges:      make(map[int]responseRunSegmentRange),
@@ -320,6 +323,7 @@ func (r *responseRun) appendEventLocked(event string, payload map[string]any, te
 // out to all live subscribers. Must be called with r.mu held.
 func (r *responseRun) storeEventLocked(stored responseRunEvent, terminal bool) {
 	r.events = append(r.events, stored)
+	r.retainedBytes += len(stored.Data)
 	r.compactEventsLocked()
 
 	// Fan out to subscribers under the lock to guarantee event ordering.
@@ -444,19 +448,35 @@ func (r *responseRun) appendTextDeltaSegmentEvent(outputIndex, segmentOrdinal in
 	return nil
 }
 
+// compactEventsLocked drops the oldest events once the replay window exceeds
+// maxRetainedEvents or maxRetainedBytes, always keeping the newest event.
+// Subscribers asking for anything before the window get snapshot_required and
+// resync from the response recovery snapshot instead.
 func (r *responseRun) compactEventsLocked() {
-	if !r.compactionEnabled || r.maxRetainedEvents <= 0 {
+	if !r.compactionEnabled {
 		return
 	}
 
-	activeLen := len(r.events) - r.eventStart
-	if activeLen <= r.maxRetainedEvents {
+	firstKept := r.eventStart
+	if r.maxRetainedEvents > 0 {
+		if activeLen := len(r.events) - firstKept; activeLen > r.maxRetainedEvents {
+			firstKept += activeLen - r.maxRetainedEvents
+		}
+	}
+	droppedBytes := 0
+	for i := r.eventStart; i < firstKept; i++ {
+		droppedBytes += len(r.events[i].Data)
+	}
+	if r.maxRetainedBytes > 0 {
+		for firstKept < len(r.events)-1 && r.retainedBytes-droppedBytes > r.maxRetainedBytes {
+			droppedBytes += len(r.events[firstKept].Data)
+			firstKept++
+		}
+	}
+	if firstKept == r.eventStart {
 		return
 	}
 
-	dropCount := activeLen - r.maxRetainedEvents
-	firstKept := r.eventStart + dropCount
-
 	nextReplayAfter := r.events[firstKept].Sequence - 1
 	if nextReplayAfter > r.minReplayAfter {
 		r.minReplayAfter = nextReplayAfter
@@ -465,6 +485,7 @@ func (r *responseRun) compactEventsLocked() {
 	for i := r.eventStart; i < firstKept; i++ {
 		r.events[i] = responseRunEvent{}
 	}
+	r.retainedBytes -= droppedBytes
 	r.eventStart = firstKept
 	r.compactEventStorageLocked()
 }
@@ -971,13 +992,17 @@ type responseRunManager struct {
 	cleanupTimers      map[string]*time.Timer
 	nextEpochBySession map[string]int64
 	terminalRetention  time.Duration
+	replayMaxEvents    int // overrides defaultResponseRunReplayLimit when > 0
+	replayMaxBytes     int // overrides defaultResponseRunReplayBytes when > 0
 	runWG              sync.WaitGroup
 	closed             bool
+	draining           bool // new runs are refused while active ones finish
 }
 
 const (
 	defaultResponseRunRetention        = 5 * time.Minute
 	defaultResponseRunReplayLimit      = 2048
+	defaultResponseRunReplayBytes      = 8 << 20
 	defaultResponseRunSubscriberBuffer = 256
 	defaultServeRequestTimeout         = 30 * time.Minute
 )
@@ -1030,6 +1055,8 @@ func (s *serveServer) ensureResponseRuns() *responseRunManager {
 	s.responseRunsOnce.Do(func() {
 		if s.responseRuns == nil {
 			s.responseRuns = newServeResponseRunManager()
+			s.responseRuns.replayMaxEvents = s.cfg.replayMaxEvents
+			s.responseRuns.replayMaxBytes = s.cfg.replayMaxBytes
 		}
 	})
 	return s.responseRuns
@@ -1062,8 +1089,8 @@ func (m *responseRunManager) createOrGetByIdempotency(run *responseRun, idempote
 	key := responseRunIdempotencyScope(run.sessionID, idempotencyKey)
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	if m.closed {
-		return nil, false, fmt.Errorf("server is shutting down")
+	if m.closed || m.draining {
+		return nil, false, errServeShuttingDown
 	}
 	if key != "" {
 		if existingID := strings.TrimSpace(m.idempotencyByKey[key]); existingID != "" {
@@ -1083,6 +1110,12 @@ func (m *responseRunManager) createOrGetByIdempotency(run *responseRun, idempote
 	}
 	m.nextEpochBySession[run.sessionID] = nextEpoch
 	run.runEpoch = nextEpoch
+	if m.replayMaxEvents > 0 {
+		run.maxRetainedEvents = m.replayMaxEvents
+	}
+	if m.replayMaxBytes > 0 {
+		run.maxRetainedBytes = m.replayMaxBytes
+	}
 	m.runs[run.id] = run
 	if key != "" {
 		m.idempotencyByKey[key] = run.id
@@ -1114,9 +1147,9 @@ func (m *responseRunManager) start(fn func()) error {
 		return fmt.Errorf("response run function is required")
 	}
 	m.mu.Lock()
-	if m.closed {
+	if m.closed || m.draining {
 		m.mu.Unlock()
-		return fmt.Errorf("server is shutting down")
+		return errServeShuttingDown
 	}
 	m.runWG.Add(1)
 	m.mu.Unlock()
@@ -1245,6 +1278,46 @@ func (m *responseRunManager) ActiveSessionIDs() map[string]bool {
 	return result
 }
 
+// Drain refuses new runs, appends a server_shutting_down event carrying
+// payload to every run still in progress, and waits until those runs finish
+// or ctx is done. It reports whether the runs finished.
+func (m *responseRunManager) Drain(ctx context.Context, payload map[string]any) bool {
+	m.mu.Lock()
+	m.draining = true
+	runs := make([]*responseRun, 0, len(m.runs))
+	for _, run := range m.runs {
+		runs = append(runs, run)
+	}
+	m.mu.Unlock()
+
+	for _, run := range runs {
+		run.mu.Lock()
+		if run.status == "in_progress" {
+			event := make(map[string]any, len(payload))
+			for k, v := range payload {
+				event[k] = v
+			}
+			if err := run.appendEventLocked("server_shutting_down", event, false); err != nil {
+				log.Printf("response run %s failed to append shutdown event: %v", run.id, err)
+			}
+		}
+		run.mu.Unlock()
+	}
+
+	// No run can start once draining is set, so waiting cannot race an Add.
+	waitDone := make(chan struct{})
+	go func() {
+		m.runWG.Wait()
+		close(waitDone)
+	}()
+	select {
+	case <-waitDone:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
 func (m *responseRunManager) Close() {
 	m.CloseContext(context.Background())
 }
@@ -1931,7 +2004,11 @@ func (s *serveServer) streamResponseRunEvents(ctx context.Context, w http.Respon
 	ch := subscription.ch
 	subscriberID := subscription.id
 
-	pingMu, stopPing := sseKeepalive(ctx, w, flusher, 10*time.Second)
+	// A failed keepalive means the client is gone even if no run event has
+	// been written since; end the stream so the subscriber is released.
+	ctx, cancelStream := context.WithCancel(ctx)
+	defer cancelStream()
+	pingMu, stopPing := sseKeepalive(ctx, w, flusher, serveSSEKeepaliveInterval, cancelStream)
 	var stopPingOnce sync.Once
 	stopKeepalive := func() {
 		stopPingOnce.Do(stopPing)
@@ -2082,6 +2159,12 @@ func (s *serveServer) startResponseRun(runtime *serveRuntime, stateful bool, rep
 	runCtx, cancel := context.WithTimeout(context.Background(), s.responseTimeout())
 	run := newResponseRun(respID, sessionID, options.previousResponseID, model, created, cancel)
 	runCtx = withResponseRunContext(runCtx, run)
+	// One turn ID covers the whole run, so the events clients see, the rows
+	// persisted for it and the debug log can be matched up.
+	if llmReq.TurnID == "" {
+		llmReq.TurnID = llm.NewTurnID()
+	}
+	runCtx = llm.ContextWithTurnID(runCtx, llmReq.TurnID)
 	s.configureResponseRunRevision(run, sessionID)
 	createdRun, duplicate, err := mgr.createOrGetByIdempotency(run, options.idempotencyKey)
 	if err != nil {
@@ -2106,6 +2189,7 @@ func (s *serveServer) startResponseRun(runtime *serveRuntime, stateful bool, rep
 		"created": created,
 		"model":   model,
 		"status":  "in_progress",
+		"turn_id": llmReq.TurnID,
 	}
 	if effort := strings.TrimSpace(llmReq.ReasoningEffort); effort != "" {
 		createdResponse["reasoning_effort"] = effort
@@ -2199,6 +2283,7 @@ func (s *serveServer) startResponseRun(runtime *serveRuntime, stateful bool, rep
 				"error": map[string]any{
 					"message": errMessage,
 					"type":    errType,
+					"turn_id": llmReq.TurnID,
 				},
 			}, errType, errMessage)
 			if options.uiSession {
@@ -7,9 +7,11 @@ import (
 	"math"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -615,6 +617,60 @@ func TestResponseRunCompleteHonorsPendingCancellation(t *testing.T) {
 	}
 }
 
+func TestResponseRunCompactionBoundsReplayBytes(t *testing.T) {
+	run := newResponseRun("resp_bytes", "sess_test", "", "mock", time.Now().Unix(), func() {})
+	delta := strings.Repeat("x", 1000)
+	for i := 0; i < 3; i++ {
+		if err := run.appendTextDeltaSegmentEvent(0, 0, delta); err != nil {
+			t.Fatalf("appendTextDeltaSegmentEvent failed at %d: %v", i, err)
+		}
+	}
+	run.mu.Lock()
+	eventBytes := len(run.events[0].Data)
+	run.mu.Unlock()
+
+	run.maxRetainedBytes = 2*eventBytes + eventBytes/2
+	for i := 0; i < 5; i++ {
+		if err := run.appendTextDeltaSegmentEvent(0, 0, delta); err != nil {
+			t.Fatalf("appendTextDeltaSegmentEvent failed at %d: %v", i, err)
+		}
+	}
+
+	run.mu.Lock()
+	activeLen := len(run.events) - run.eventStart
+	retained := 0
+	for _, ev := range run.activeEventsLocked() {
+		retained += len(ev.Data)
+	}
+	retainedBytes := run.retainedBytes
+	minReplayAfter := run.minReplayAfter
+	run.mu.Unlock()
+
+	if activeLen != 2 {
+		t.Fatalf("active retained events = %d, want 2", activeLen)
+	}
+	if retainedBytes != retained {
+		t.Fatalf("retainedBytes = %d, want %d", retainedBytes, retained)
+	}
+	if minReplayAfter != 6 {
+		t.Fatalf("minReplayAfter = %d, want 6", minReplayAfter)
+	}
+	if stale := run.subscribe(0); !stale.snapshotRequired {
+		t.Fatalf("subscribe from start = %#v, want snapshot required", stale)
+	}
+
+	// A single event larger than the byte bound is still kept so the
+	// newest state is always replayable.
+	run.maxRetainedBytes = 10
+	if err := run.appendTextDeltaSegmentEvent(0, 0, delta); err != nil {
+		t.Fatalf("appendTextDeltaSegmentEvent failed: %v", err)
+	}
+	fresh := run.subscribe(8)
+	if fresh.snapshotRequired || len(fresh.replay) != 1 || fresh.replay[0].Sequence != 9 {
+		t.Fatalf("subscribe(8) = %#v, want the newest event", fresh)
+	}
+}
+
 func TestResponseRunCompactionKeepsReplayWindowInOrder(t *testing.T) {
 	run := newResponseRun("resp_compact", "sess_test", "", "mock", time.Now().Unix(), func() {})
 	run.maxRetainedEvents = 3
@@ -1024,6 +1080,66 @@ func waitForResponseRunCondition(t *testing.T, timeout time.Duration, fn func()
 	t.Fatal(message)
 }
 
+// deadClientResponseWriter accepts writes until the client "goes away",
+// after which every write fails as it would once the write deadline expires.
+type deadClientResponseWriter struct {
+	header http.Header
+	dead   atomic.Bool
+}
+
+func (w *deadClientResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *deadClientResponseWriter) WriteHeader(statusCode int) {}
+
+func (w *deadClientResponseWriter) Write(p []byte) (int, error) {
+	if w.dead.Load() {
+		return 0, os.ErrDeadlineExceeded
+	}
+	return len(p), nil
+}
+
+func (w *deadClientResponseWriter) Flush() {}
+
+func TestStreamResponseRunEventsDetachesWhenKeepaliveFails(t *testing.T) {
+	prevInterval := serveSSEKeepaliveInterval
+	serveSSEKeepaliveInterval = 20 * time.Millisecond
+	t.Cleanup(func() { serveSSEKeepaliveInterval = prevInterval })
+
+	srv := &serveServer{shutdownCh: make(chan struct{})}
+	run := newResponseRun("resp_dead_client", "sess_test", "", "mock", time.Now().Unix(), func() {})
+	w := &deadClientResponseWriter{header: make(http.Header)}
+
+	streamDone := make(chan struct{})
+	go func() {
+		srv.streamResponseRunEvents(context.Background(), w, run, 0)
+		close(streamDone)
+	}()
+
+	waitForResponseRunCondition(t, time.Second, func() bool {
+		run.mu.Lock()
+		defer run.mu.Unlock()
+		return len(run.subscribers) == 1
+	}, "timed out waiting for stream subscriber")
+
+	// The run is idle (for example waiting on an approval), so only the
+	// keepalive ping can notice the client is gone.
+	w.dead.Store(true)
+
+	select {
+	case <-streamDone:
+	case <-time.After(time.Second):
+		t.Fatal("stream did not end after keepalive write failed")
+	}
+	run.mu.Lock()
+	subscribers := len(run.subscribers)
+	run.mu.Unlock()
+	if subscribers != 0 {
+		t.Fatalf("subscribers = %d after dead client, want 0", subscribers)
+	}
+}
+
 func TestStreamResponseRunEventsWritesTerminalErrorWhenSubscriberOverflows(t *testing.T) {
 	srv := &serveServer{shutdownCh: make(chan struct{})}
 	run := newResponseRun("resp_overflow", "sess_test", "", "mock", time.Now().Unix(), func() {})
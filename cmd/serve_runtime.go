@@ -320,6 +320,9 @@ func (rt *serveRuntime) closeLocked() {
 	if rt.toolMgr != nil && rt.toolMgr.ApprovalMgr != nil {
 		rt.toolMgr.ApprovalMgr.Close()
 	}
+	if rt.sessionMeta != nil {
+		rt.toolMgr.ForgetSession(rt.sessionMeta.ID)
+	}
 	if !rt.skipProviderCleanup {
 		if cleaner, ok := rt.provider.(interface{ CleanupMCP() }); ok {
 			cleaner.CleanupMCP()
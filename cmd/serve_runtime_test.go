@@ -410,6 +410,10 @@ func (s *serveRuntimeTestStore) UpdateMetrics(ctx context.Context, id string, ll
 	return nil
 }
 
+func (s *serveRuntimeTestStore) UpdateModelMetrics(ctx context.Context, id string, _ session.UsageModel, llmTurns, toolCalls, inputTokens, outputTokens, cachedInputTokens, cacheWriteTokens int) error {
+	return s.UpdateMetrics(ctx, id, llmTurns, toolCalls, inputTokens, outputTokens, cachedInputTokens, cacheWriteTokens)
+}
+
 func (s *serveRuntimeTestStore) UpdateContextEstimate(ctx context.Context, id string, lastTotalTokens, lastMessageCount int) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
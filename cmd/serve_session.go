@@ -545,6 +545,14 @@ func (m *serveSessionManager) ActiveSessionIDs() map[string]bool {
 	return result
 }
 
+// Len returns the number of live chat sessions, for the /metrics gauge.
+// Like ActiveSessionIDs it does not touch runtimes.
+func (m *serveSessionManager) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sessions)
+}
+
 func (m *serveSessionManager) Close() {
 	m.CloseContext(context.Background())
 }
@@ -476,7 +476,9 @@ func setupServeMainSkillRequest(runtime *serveRuntime, activation *skills.Activa
 		}
 		for _, definition := range activation.ToolDefs {
 			if tool, ok := runtime.toolMgr.Registry.Get(definition.Name); ok {
-				runtime.engine.AddDynamicTool(tool)
+				if err := runtime.engine.AddDynamicTool(tool); err != nil {
+					return err
+				}
 			}
 		}
 	}
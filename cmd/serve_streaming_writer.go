@@ -14,6 +14,10 @@ const (
 	durableResponseLookupLimit = servehttp.DurableResponseLookupLimit
 )
 
+// serveSSEKeepaliveInterval is how often idle SSE streams send a comment
+// ping. A failed ping is how a client that silently went away is noticed.
+var serveSSEKeepaliveInterval = 10 * time.Second
+
 func newStreamingResponseWriter(w http.ResponseWriter, timeout time.Duration) http.ResponseWriter {
 	return servehttp.NewStreamingResponseWriter(w, timeout)
 }
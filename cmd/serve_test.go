@@ -635,6 +635,65 @@ func TestServeHTTPHandler_MountsWebUnderBasePath(t *testing.T) {
 	}
 }
 
+func TestServeHealthReportsVersionAndDatabase(t *testing.T) {
+	store, err := session.NewSQLiteStore(session.Config{Enabled: true, Path: ":memory:"})
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	srv := &serveServer{cfg: serveServerConfig{requireAuth: true, token: "tok", agentName: "jarvis"}, store: store}
+
+	var resp map[string]any
+	rr := httptest.NewRecorder()
+	srv.handleHealth(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if rr.Code != http.StatusOK || resp["status"] != "ok" || resp["database"] != "ok" || resp["version"] != Version {
+		t.Fatalf("healthz = %d %v, want ok with version and database", rr.Code, resp)
+	}
+	if _, ok := resp["agent"]; ok {
+		t.Fatalf("anonymous healthz named the node: %v", resp)
+	}
+
+	store.Close()
+	rr = httptest.NewRecorder()
+	srv.handleHealth(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rr.Code != http.StatusServiceUnavailable || !strings.Contains(rr.Body.String(), `"database":"error"`) {
+		t.Fatalf("healthz with a closed store = %d %s, want 503 with a database error", rr.Code, rr.Body.String())
+	}
+}
+
+func TestServeMetricsEndpoint(t *testing.T) {
+	get := func(h http.Handler, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/ui/metrics", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+	cfg := serveServerConfig{api: true, basePath: "/ui", requireAuth: true, token: "tok"}
+
+	h := (&serveServer{cfg: cfg}).httpHandler()
+	if rr := get(h, ""); rr.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous /metrics status = %d, want 401", rr.Code)
+	}
+	rr := get(h, "tok")
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "# TYPE term_llm_llm_requests_total counter") {
+		t.Fatalf("/metrics = %d %q, want the Prometheus counters", rr.Code, rr.Body.String())
+	}
+
+	cfg.metricsPublic = true
+	if rr := get((&serveServer{cfg: cfg}).httpHandler(), ""); rr.Code != http.StatusOK {
+		t.Fatalf("public /metrics status = %d, want 200", rr.Code)
+	}
+	cfg.metricsDisabled = true
+	if rr := get((&serveServer{cfg: cfg}).httpHandler(), "tok"); rr.Code != http.StatusNotFound {
+		t.Fatalf("disabled /metrics status = %d, want 404", rr.Code)
+	}
+}
+
 func TestNormalizeBasePath_ProducesValidRoutes(t *testing.T) {
 	// Verify that normalizeBasePath output always produces valid route helpers
 	// and that handleUI/handleImage parse paths correctly.
@@ -6574,6 +6633,56 @@ func TestEnsurePersistedSession_SkipsRestoreWhenHistoryExists(t *testing.T) {
 	}
 }
 
+func TestServeRuntime_ResumesConversationAfterRestart(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "sessions.db")
+	ctx := context.Background()
+
+	store, err := session.NewStore(session.Config{Enabled: true, Path: dbPath})
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	first := &serveRuntime{
+		store:        store,
+		defaultModel: "mock-model",
+		provider:     llm.NewMockProvider("mock"),
+	}
+	if number := first.ensureSessionInStore(ctx, "restart-test", []llm.Message{llm.UserText("remember redis")}); number == 0 {
+		t.Fatal("ensureSessionInStore did not create a session row")
+	}
+	written := first.appendMessages(ctx, "restart-test", []llm.Message{
+		llm.UserText("remember redis"),
+		llm.AssistantText("noted"),
+	}, 1)
+	if written != 2 {
+		t.Fatalf("appendMessages wrote %d, want 2", written)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// A new process opens the same database; the runtime starts empty and
+	// must rebuild the conversation from the store.
+	reopened, err := session.NewStore(session.Config{Enabled: true, Path: dbPath})
+	if err != nil {
+		t.Fatalf("NewStore (reopen): %v", err)
+	}
+	defer reopened.Close()
+	second := &serveRuntime{
+		store:        reopened,
+		defaultModel: "mock-model",
+		provider:     llm.NewMockProvider("mock"),
+	}
+	if !second.ensurePersistedSession(ctx, "restart-test", nil) {
+		t.Fatal("ensurePersistedSession returned false after restart")
+	}
+	if len(second.history) != 2 {
+		t.Fatalf("history len = %d, want 2", len(second.history))
+	}
+	if got := second.history[1].Parts[0].Text; got != "noted" {
+		t.Fatalf("history[1].text = %q, want %q", got, "noted")
+	}
+}
+
 type testServeDelayTool struct {
 	delay time.Duration
 }
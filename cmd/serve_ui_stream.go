@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -32,6 +33,10 @@ func (s *serveServer) streamResponseRun(ctx context.Context, w http.ResponseWrit
 			options.modelSwap.markRolledBack()
 			s.restoreModelSwapRollback(ctx, sessionID, options.modelSwap, runtime, "failed", "naive")
 		}
+		if errors.Is(err, errServeShuttingDown) {
+			writeOpenAIError(w, http.StatusServiceUnavailable, "server_error", err.Error())
+			return false
+		}
 		writeOpenAIError(w, http.StatusInternalServerError, "server_error", err.Error())
 		return false
 	}
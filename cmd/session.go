@@ -49,6 +49,9 @@ type SessionSettings struct {
 	ShellAutoRun bool
 	Scripts      []string
 	Spawn        tools.SpawnConfig
+	// ToolFilter is the agent's tools.allowed/tools.denied, applied to the
+	// engine so it also covers MCP, skill and sub-agent tools.
+	ToolFilter llm.ToolFilter
 
 	// BaseDir is the unified per-session working directory for all tools. It is
 	// threaded through ToolConfig and never applied with os.Chdir.
@@ -70,6 +73,10 @@ type SessionSettings struct {
 
 	// System prompt (already expanded)
 	SystemPrompt string
+	// ProjectInstructions is set when SystemPrompt already includes the
+	// project instruction files (agents_md agents, {{agents}} templates), so
+	// they are not injected a second time.
+	ProjectInstructions bool
 
 	// Behavior
 	MaxTurns        int
@@ -124,17 +131,11 @@ func LoadAgent(agentName string, cfg *config.Config) (*agents.Agent, error) {
 		return agent, nil
 	}
 
-	registry, err := agents.NewRegistry(agents.RegistryConfig{
-		UseBuiltin:  cfg.Agents.UseBuiltin,
-		SearchPaths: cfg.Agents.SearchPaths,
-	})
+	registry, err := agents.NewRegistryFromConfig(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("create agent registry: %w", err)
 	}
 
-	// Apply agent preferences from config
-	registry.SetPreferences(cfg.Agents.Preferences)
-
 	agent, err := registry.Get(agentName)
 	if err != nil {
 		return nil, fmt.Errorf("load agent: %w", err)
@@ -149,10 +150,7 @@ func LoadAgent(agentName string, cfg *config.Config) (*agents.Agent, error) {
 
 // ListAgentNames returns all available agent names for completions.
 func ListAgentNames(cfg *config.Config) ([]string, error) {
-	registry, err := agents.NewRegistry(agents.RegistryConfig{
-		UseBuiltin:  cfg.Agents.UseBuiltin,
-		SearchPaths: cfg.Agents.SearchPaths,
-	})
+	registry, err := agents.NewRegistryFromConfig(cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -246,6 +244,11 @@ func ResolveSettingsInDir(cfg *config.Config, agent *agents.Agent, cli CLIFlags,
 		}
 		s.AgentDir = agent.SourcePath
 		s.CustomTools = agent.Tools.Custom
+		s.ToolFilter = llm.ToolFilter{
+			Allow:        append([]string(nil), agent.Tools.Allowed...),
+			AllowPresent: len(agent.Tools.Allowed) > 0,
+			Deny:         append([]string(nil), agent.Tools.Denied...),
+		}
 	}
 
 	// MCP: CLI > agent
@@ -266,6 +269,7 @@ func ResolveSettingsInDir(cfg *config.Config, agent *agents.Agent, cli CLIFlags,
 			return s, fmt.Errorf("expand --system prompt: %w", err)
 		}
 		s.SystemPrompt = expanded
+		s.ProjectInstructions = agents.UsesProjectInstructions(cli.SystemMessage)
 	} else {
 		usedAgentPrompt := false
 		if agent != nil {
@@ -291,9 +295,11 @@ func ResolveSettingsInDir(cfg *config.Config, agent *agents.Agent, cli CLIFlags,
 						return s, fmt.Errorf("expand agent system prompt: %w", err)
 					}
 					s.SystemPrompt = expanded
+					s.ProjectInstructions = agents.UsesProjectInstructions(agent.SystemPrompt)
 				}
 
 				if projectInstructions != "" {
+					s.ProjectInstructions = true
 					if strings.TrimSpace(s.SystemPrompt) == "" {
 						s.SystemPrompt = projectInstructions
 					} else {
@@ -310,6 +316,7 @@ func ResolveSettingsInDir(cfg *config.Config, agent *agents.Agent, cli CLIFlags,
 				return s, fmt.Errorf("expand config system prompt: %w", err)
 			}
 			s.SystemPrompt = expanded
+			s.ProjectInstructions = agents.UsesProjectInstructions(configInstructions)
 		}
 	}
 
@@ -471,6 +478,9 @@ func agentPromptTemplateContextAndBaseDirInDir(agent *agents.Agent, files []stri
 // SetupToolManager creates and configures a ToolManager from settings.
 // Returns nil if no tools are enabled.
 func (s *SessionSettings) SetupToolManager(cfg *config.Config, engine *llm.Engine) (*tools.ToolManager, error) {
+	if engine != nil && !s.ToolFilter.IsZero() {
+		engine.SetToolFilter(s.ToolFilter)
+	}
 	visionTarget := indirectVisionTarget(cfg, s.Provider, s.Model)
 	if s.Tools == "" && visionTarget == "" {
 		return nil, nil
@@ -534,7 +544,9 @@ func (s *SessionSettings) SetupToolManager(cfg *config.Config, engine *llm.Engin
 		}
 	}
 
-	toolMgr.SetupEngine(engine)
+	if err := toolMgr.SetupEngine(engine); err != nil {
+		return nil, err
+	}
 	return toolMgr, nil
 }
 
@@ -665,6 +677,8 @@ func sessionStoreConfig(cfg *config.Config) session.Config {
 		Enabled:          cfg.Sessions.Enabled && !noSession,
 		MaxAgeDays:       cfg.Sessions.MaxAgeDays,
 		MaxCount:         cfg.Sessions.MaxCount,
+		PurgeGraceDays:   cfg.Sessions.PurgeGraceDays,
+		HardDelete:       cfg.Sessions.HardDelete,
 		Path:             path,
 		StripImageBase64: cfg.Sessions.StripImageBase64,
 	}
@@ -711,7 +725,7 @@ func InitSessionStore(cfg *config.Config, errWriter io.Writer) (session.Store, f
 			fmt.Fprintf(errWriter, "Session database has schema errors: %v\n\n", err)
 			fmt.Fprintf(errWriter, "Would you like to reset the sessions database? [y/N]: ")
 
-			if promptForReset() {
+			if promptConfirm() {
 				if resetErr := resetSessionDatabase(storeCfg.Path); resetErr != nil {
 					fmt.Fprintf(errWriter, "warning: failed to reset database: %v\n", resetErr)
 					return nil, func() {}
@@ -772,9 +786,9 @@ func isSchemaError(err error) bool {
 	return false
 }
 
-// promptForReset asks the user if they want to reset the session database.
+// promptConfirm reads the answer to a yes/no question from the terminal.
 // Returns true if user confirms with 'y' or 'yes'.
-func promptForReset() bool {
+func promptConfirm() bool {
 	// Try to open /dev/tty directly for interactive input
 	// This works even when stdin is redirected
 	tty, err := os.Open("/dev/tty")
@@ -930,18 +944,24 @@ func RegisterSkillToolWithEngine(engine *llm.Engine, toolMgr *tools.ToolManager,
 			// so the LLM sees the tools immediately on the very next turn.
 			for _, def := range defs {
 				if tool, ok := toolMgr.Registry.Get(def.Name); ok {
-					engine.AddDynamicTool(tool)
+					if err := engine.AddDynamicTool(tool); err != nil {
+						fmt.Fprintf(os.Stderr, "warning: skill tool registration failed: %v\n", err)
+					}
 				}
 			}
 		})
 	}
-	engine.Tools().Register(skillTool)
+	if err := engine.Tools().Register(skillTool); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: skill tool registration failed: %v\n", err)
+	}
 
 	// Register search_skills whenever the skills system is available. This avoids
 	// eager startup discovery just to determine whether the prompt metadata will
 	// overflow, while still letting the model search the catalog on demand.
 	searchTool := tools.NewSearchSkillsTool(skillsSetup.Registry)
-	engine.Tools().Register(searchTool)
+	if err := engine.Tools().Register(searchTool); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: skill tool registration failed: %v\n", err)
+	}
 }
 
 // InjectSkillsMetadata appends <available_skills> metadata to instructions when available.
@@ -216,6 +216,35 @@ func TestResolveSettings_AgentSpawnConfigAppliesToToolManager(t *testing.T) {
 	}
 }
 
+func TestResolveSettings_AgentToolFilterAppliesToEngine(t *testing.T) {
+	agent, err := agents.FromPreset("reader", config.AgentPreset{
+		AllowedTools: []string{"read_file", "mcp_github_search"},
+		DeniedTools:  []string{"mcp_github_search"},
+	}, "")
+	if err != nil {
+		t.Fatalf("FromPreset() error = %v", err)
+	}
+
+	settings, err := ResolveSettings(&config.Config{}, agent, CLIFlags{}, "", "", "", 0, 20)
+	if err != nil {
+		t.Fatalf("ResolveSettings() error = %v", err)
+	}
+	engine := llm.NewEngine(nil, nil)
+	if _, err := settings.SetupToolManager(&config.Config{}, engine); err != nil {
+		t.Fatalf("SetupToolManager() error = %v", err)
+	}
+
+	filter := engine.ToolFilter()
+	if !filter.Permits("read_file") {
+		t.Fatal("read_file should be permitted")
+	}
+	for _, name := range []string{"shell", "mcp_github_search"} {
+		if filter.Permits(name) {
+			t.Fatalf("%s should not be permitted by the agent's tool filter", name)
+		}
+	}
+}
+
 func sessionTestStringSliceContains(values []string, want string) bool {
 	for _, value := range values {
 		if value == want {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"slices"
@@ -13,6 +14,7 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"github.com/samsaffron/term-llm/internal/agents/gist"
 	"github.com/samsaffron/term-llm/internal/config"
+	"github.com/samsaffron/term-llm/internal/llm"
 	internalreasoning "github.com/samsaffron/term-llm/internal/reasoning"
 	"github.com/samsaffron/term-llm/internal/session"
 	"github.com/samsaffron/term-llm/internal/tui/sessions"
@@ -50,29 +52,90 @@ var sessionsListCmd = &cobra.Command{
 var sessionsSearchCmd = &cobra.Command{
 	Use:   "search <query>",
 	Short: "Search sessions",
-	Args:  cobra.MinimumNArgs(1),
-	RunE:  runSessionsSearch,
+	Long: `Full-text search across session messages. The query is matched literally,
+so quotes, dashes and asterisks need no escaping.
+
+Examples:
+  term-llm sessions search "kubernetes"
+  term-llm sessions search term-llm --role user --limit 5
+  term-llm sessions search "rate limit" --json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSessionsSearch,
 }
 
 var sessionsShowCmd = &cobra.Command{
-	Use:   "show <number|id>",
-	Short: "Show session details",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runSessionsShow,
+	Use:               "show <number|id>",
+	Short:             "Show session details",
+	Args:              cobra.ExactArgs(1),
+	RunE:              runSessionsShow,
+	ValidArgsFunction: sessionArgCompletion,
 }
 
 var sessionsDeleteCmd = &cobra.Command{
-	Use:   "delete <number|id>",
-	Short: "Delete a session",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runSessionsDelete,
+	Use:               "delete <number|id>",
+	Short:             "Delete a session",
+	Args:              cobra.ExactArgs(1),
+	RunE:              runSessionsDelete,
+	ValidArgsFunction: sessionArgCompletion,
 }
 
 var sessionsExportCmd = &cobra.Command{
-	Use:   "export <number|id> [path]",
-	Short: "Export session as markdown",
-	Args:  cobra.RangeArgs(1, 2),
-	RunE:  runSessionsExport,
+	Use:   "export <number|id|current> [path]",
+	Short: "Export session as markdown, HTML or JSON",
+	Long: `Export a session transcript. Use "current" for the session chat last marked current.
+
+Markdown (the default) is a readable transcript. HTML is a single self-contained
+page with highlighted code, colored diffs and collapsible tool calls, ready to
+open in a browser or attach to an issue. JSON keeps every message part,
+including tool calls, tool results, durations and timestamps, and can be loaded
+back with 'term-llm sessions import'.
+
+Examples:
+  term-llm sessions export 42
+  term-llm sessions export 42 --format html
+  term-llm sessions export current --format json session.json
+  term-llm sessions export 42 --format json --blobs-dir blobs
+  term-llm sessions export 42 --include-tools=false`,
+	Args:              cobra.RangeArgs(1, 2),
+	RunE:              runSessionsExport,
+	ValidArgsFunction: sessionExportArgCompletion,
+}
+
+var sessionsRestoreCmd = &cobra.Command{
+	Use:   "restore <number|id>",
+	Short: "Unarchive a session",
+	Long: `Unarchive a session, including one archived by sessions.max_age_days or
+sessions.max_count cleanup before it is permanently deleted. Restoring counts as
+activity, so the session is not archived again until it ages out anew.
+
+Use 'term-llm sessions list --archived' to see archived sessions and when
+they will be deleted.`,
+	Args:              cobra.ExactArgs(1),
+	RunE:              runSessionsRestore,
+	ValidArgsFunction: archivedSessionArgCompletion,
+}
+
+var sessionsDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the sessions database for corruption",
+	Long: `Run SQLite's integrity check and verify that the message search index
+matches the messages table, and report the size of the write-ahead log.
+
+Exits non-zero when problems are found, so it can be used in scripts. Use
+--rebuild to repopulate the search index from the messages table when search
+returns stale or missing results.`,
+	Args: cobra.NoArgs,
+	RunE: runSessionsDoctor,
+}
+
+var sessionsImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import a session exported with --format json",
+	Long: `Recreate a session from a JSON export. The imported session gets a new
+ID and number; messages keep their original sequence numbers. Blobs written with
+--blobs-dir are read back from the sidecar directory.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSessionsImport,
 }
 
 var sessionsResetCmd = &cobra.Command{
@@ -92,8 +155,9 @@ the auto-generated summary in listings.
 
 Example:
   term-llm sessions name 42 "Auth refactor"`,
-	Args: cobra.ExactArgs(2),
-	RunE: runSessionsName,
+	Args:              cobra.ExactArgs(2),
+	RunE:              runSessionsName,
+	ValidArgsFunction: sessionArgCompletion,
 }
 
 var sessionsTagCmd = &cobra.Command{
@@ -104,8 +168,9 @@ and filter sessions.
 
 Example:
   term-llm sessions tag 42 bug feature`,
-	Args: cobra.MinimumNArgs(2),
-	RunE: runSessionsTag,
+	Args:              cobra.MinimumNArgs(2),
+	RunE:              runSessionsTag,
+	ValidArgsFunction: sessionArgCompletion,
 }
 
 var sessionsUntagCmd = &cobra.Command{
@@ -115,8 +180,9 @@ var sessionsUntagCmd = &cobra.Command{
 
 Example:
   term-llm sessions untag 42 bug`,
-	Args: cobra.MinimumNArgs(2),
-	RunE: runSessionsUntag,
+	Args:              cobra.MinimumNArgs(2),
+	RunE:              runSessionsUntag,
+	ValidArgsFunction: sessionArgCompletion,
 }
 
 var sessionsBrowseCmd = &cobra.Command{
@@ -126,12 +192,15 @@ var sessionsBrowseCmd = &cobra.Command{
 
 Key bindings:
   j/k or arrows  Navigate up/down
-  enter          Open session inspector
-  /              Search sessions
+  enter          Resume session in chat
+  i              Open session inspector
+  /              Search sessions (fuzzy match on name, summary and model)
   ctrl+f         Toggle FTS (full-text search)
   s              Cycle sort order
   f              Cycle status filter
-  d              Delete session (with confirmation)
+  p              Toggle the preview pane
+  d              Archive session (with confirmation)
+  D              Delete session (with confirmation)
   q/esc          Quit`,
 	RunE: runSessionsBrowse,
 }
@@ -150,9 +219,10 @@ Examples:
   term-llm sessions export gist 42
   term-llm sessions export gist 42 --public
   term-llm sessions export gist 42 --include-system`,
-	Args:         cobra.ExactArgs(1),
-	RunE:         runSessionsExportGist,
-	SilenceUsage: true,
+	Args:              cobra.ExactArgs(1),
+	RunE:              runSessionsExportGist,
+	ValidArgsFunction: sessionArgCompletion,
+	SilenceUsage:      true,
 }
 
 // Flags
@@ -160,12 +230,20 @@ var (
 	sessionsProvider                  string
 	sessionsLimit                     int
 	sessionsJSON                      bool
+	sessionsArchived                  bool
+	sessionsDoctorRebuild             bool
+	sessionsSearchLimit               int
+	sessionsSearchRole                string
 	sessionsStatus                    string
 	sessionsMode                      string
 	sessionsTag                       string
 	sessionsExportIncludeSystem       bool
 	sessionsExportIncludeReasoning    bool
 	sessionsExportIncludeRawReasoning bool
+	sessionsExportFormat              string
+	sessionsExportIncludeTools        bool
+	sessionsExportMaxToolResult       int
+	sessionsExportBlobsDir            string
 	sessionsGistPublic                bool
 	sessionsGistIncludeSystem         bool
 	sessionsGistIncludeReasoning      bool
@@ -179,14 +257,27 @@ func init() {
 	sessionsListCmd.Flags().StringVar(&sessionsStatus, "status", "", "Filter by status (active, complete, error, interrupted)")
 	sessionsListCmd.Flags().StringVar(&sessionsMode, "mode", "", "Filter by mode (chat, ask, plan, exec)")
 	sessionsListCmd.Flags().StringVar(&sessionsTag, "tag", "", "Filter by tag")
+	sessionsListCmd.Flags().BoolVar(&sessionsArchived, "archived", false, "List archived sessions and when they will be deleted")
+
+	// Search flags
+	sessionsSearchCmd.Flags().IntVar(&sessionsSearchLimit, "limit", 20, "Maximum number of sessions to return")
+	sessionsSearchCmd.Flags().StringVar(&sessionsSearchRole, "role", "", "Only match messages from this role (user, assistant)")
+	sessionsSearchCmd.Flags().BoolVar(&sessionsJSON, "json", false, "Output as JSON")
 
 	// Show flags
 	sessionsShowCmd.Flags().BoolVar(&sessionsJSON, "json", false, "Output as JSON")
 
+	// Doctor flags
+	sessionsDoctorCmd.Flags().BoolVar(&sessionsDoctorRebuild, "rebuild", false, "Rebuild the message search index")
+
 	// Markdown export flags
 	sessionsExportCmd.Flags().BoolVar(&sessionsExportIncludeSystem, "include-system", false, "Include system prompt in export")
 	sessionsExportCmd.Flags().BoolVar(&sessionsExportIncludeReasoning, "include-reasoning", false, "Include provider reasoning summaries in export")
 	sessionsExportCmd.Flags().BoolVar(&sessionsExportIncludeRawReasoning, "include-raw-reasoning", false, "Include raw reasoning when reasoning.raw is enabled")
+	sessionsExportCmd.Flags().StringVar(&sessionsExportFormat, "format", "md", "Export format (md, html, json)")
+	sessionsExportCmd.Flags().BoolVar(&sessionsExportIncludeTools, "include-tools", true, "Include tool calls and results")
+	sessionsExportCmd.Flags().IntVar(&sessionsExportMaxToolResult, "max-tool-result", 0, "Truncate tool results to this many characters (0 = no limit)")
+	sessionsExportCmd.Flags().StringVar(&sessionsExportBlobsDir, "blobs-dir", "", "Write inline images and files to this directory (json only)")
 
 	// Gist export flags
 	sessionsExportGistCmd.Flags().BoolVar(&sessionsGistPublic, "public", false, "Create a public Gist (default: secret/unlisted, not private)")
@@ -199,7 +290,10 @@ func init() {
 	sessionsCmd.AddCommand(sessionsSearchCmd)
 	sessionsCmd.AddCommand(sessionsShowCmd)
 	sessionsCmd.AddCommand(sessionsDeleteCmd)
+	sessionsCmd.AddCommand(sessionsRestoreCmd)
 	sessionsCmd.AddCommand(sessionsExportCmd)
+	sessionsCmd.AddCommand(sessionsImportCmd)
+	sessionsCmd.AddCommand(sessionsDoctorCmd)
 	sessionsCmd.AddCommand(sessionsResetCmd)
 	sessionsCmd.AddCommand(sessionsNameCmd)
 	sessionsCmd.AddCommand(sessionsTagCmd)
@@ -251,11 +345,12 @@ func runSessionsList(cmd *cobra.Command, args []string) error {
 
 	ctx := context.Background()
 	summaries, err := store.List(ctx, session.ListOptions{
-		Provider: sessionsProvider,
-		Mode:     session.SessionMode(sessionsMode),
-		Status:   session.SessionStatus(sessionsStatus),
-		Tag:      sessionsTag,
-		Limit:    sessionsLimit,
+		Provider:     sessionsProvider,
+		Mode:         session.SessionMode(sessionsMode),
+		Status:       session.SessionStatus(sessionsStatus),
+		Tag:          sessionsTag,
+		Limit:        sessionsLimit,
+		ArchivedOnly: sessionsArchived,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to list sessions: %w", err)
@@ -267,9 +362,9 @@ func runSessionsList(cmd *cobra.Command, args []string) error {
 	}
 
 	// Header line
-	fmt.Printf("%4s %-25s %4s %5s %5s %-11s %-8s %s\n",
-		"#", "SUMMARY", "MSGS", "TURNS", "TOOLS", "TOKENS", "STATUS", "AGE")
-	fmt.Println(strings.Repeat("-", 100))
+	fmt.Printf("%4s %-25s %-24s %4s %5s %5s %-11s %-8s %s\n",
+		"#", "SUMMARY", "MODEL", "MSGS", "TURNS", "TOOLS", "TOKENS", "STATUS", "AGE")
+	fmt.Println(strings.Repeat("-", 125))
 
 	for _, s := range summaries {
 		summary := s.PreferredShortTitle()
@@ -294,13 +389,44 @@ func runSessionsList(cmd *cobra.Command, args []string) error {
 		age := formatRelativeTime(s.UpdatedAt)
 
 		// MSGS shows actual message count (MessageCount), TURNS shows LLM API round-trips
-		fmt.Printf("%4d %-25s %4d %5d %5d %-11s %-8s %s\n",
-			s.Number, summary, s.MessageCount, s.LLMTurns, s.ToolCalls, tokens, status, age)
+		fmt.Printf("%4d %-25s %-24s %4d %5d %5d %-11s %-8s %s%s\n",
+			s.Number, summary, sessionModelLabel(s), s.MessageCount, s.LLMTurns, s.ToolCalls, tokens, status, age, sessionForkSuffix(s)+sessionPurgeSuffix(s))
 	}
 
 	return nil
 }
 
+// sessionModelLabel returns the model a session currently uses, which
+// follows /model switches, trimmed to fit the MODEL column.
+func sessionModelLabel(s session.SessionSummary) string {
+	model := strings.TrimSpace(s.Model)
+	if model == "" {
+		model = "-"
+	}
+	if len(model) > 24 {
+		model = model[:21] + "..."
+	}
+	return model
+}
+
+// sessionForkSuffix marks forked sessions in listings with their parent.
+// Child runs such as subagents also have a parent but are not forks.
+func sessionForkSuffix(s session.SessionSummary) string {
+	if s.Kind == session.KindFork && s.ParentNumber > 0 {
+		return fmt.Sprintf("  (fork of #%d)", s.ParentNumber)
+	}
+	return ""
+}
+
+// sessionPurgeSuffix marks auto-archived sessions with the date cleanup will
+// delete them.
+func sessionPurgeSuffix(s session.SessionSummary) string {
+	if s.PurgeAfter.IsZero() {
+		return ""
+	}
+	return "  (deleted after " + s.PurgeAfter.Local().Format("2006-01-02") + ")"
+}
+
 // formatSessionTokens formats input/output tokens in compact form
 func formatSessionTokens(input, output int) string {
 	if input == 0 && output == 0 {
@@ -335,13 +461,30 @@ func runSessionsSearch(cmd *cobra.Command, args []string) error {
 	}
 	defer store.Close()
 
+	role, err := parseSessionsSearchRole(sessionsSearchRole)
+	if err != nil {
+		return err
+	}
+	if sessionsSearchLimit <= 0 {
+		return fmt.Errorf("--limit must be positive")
+	}
+
 	query := strings.Join(args, " ")
 	ctx := context.Background()
-	results, err := store.Search(ctx, session.SearchOptions{Query: query, Limit: 20})
+	results, err := store.Search(ctx, session.SearchOptions{Query: query, Limit: sessionsSearchLimit, Role: role})
 	if err != nil {
 		return fmt.Errorf("search failed: %w", err)
 	}
 
+	if sessionsJSON {
+		if results == nil {
+			results = []session.SearchResult{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+
 	if len(results) == 0 {
 		fmt.Printf("No results found for '%s'\n", query)
 		return nil
@@ -349,17 +492,45 @@ func runSessionsSearch(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("Found %d matches for '%s':\n\n", len(results), query)
 	for _, r := range results {
-		name := r.SessionName
-		if name == "" {
-			name = fmt.Sprintf("#%d", r.SessionNumber)
-		}
-		fmt.Printf("**%s** (%s)\n", name, r.Provider)
-		fmt.Printf("  %s\n\n", r.Snippet)
+		fmt.Print(formatSessionSearchResult(r))
 	}
 
 	return nil
 }
 
+// parseSessionsSearchRole validates the --role filter for sessions search.
+func parseSessionsSearchRole(raw string) (llm.Role, error) {
+	switch role := strings.ToLower(strings.TrimSpace(raw)); role {
+	case "":
+		return "", nil
+	case string(llm.RoleUser), string(llm.RoleAssistant):
+		return llm.Role(role), nil
+	default:
+		return "", fmt.Errorf("invalid --role %q (want user or assistant)", raw)
+	}
+}
+
+// formatSessionSearchResult renders one search hit with enough detail to
+// resume the session directly.
+func formatSessionSearchResult(r session.SearchResult) string {
+	title := r.SessionName
+	if title == "" {
+		title = fallbackString(r.GeneratedShortTitle, r.Summary)
+	}
+	if title == "" {
+		title = "(untitled)"
+	}
+	model := r.Provider
+	if r.Model != "" {
+		model += "/" + r.Model
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "#%d %s (%s, %s)\n", r.SessionNumber, title, model, r.CreatedAt.Local().Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, "  %s\n", strings.Join(strings.Fields(r.Snippet), " "))
+	fmt.Fprintf(&b, "  resume: term-llm chat --resume %s\n\n", r.SessionID)
+	return b.String()
+}
+
 func runSessionsShow(cmd *cobra.Command, args []string) error {
 	store, err := getSessionStore()
 	if err != nil {
@@ -480,7 +651,7 @@ func runSessionsDelete(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func runSessionsExport(cmd *cobra.Command, args []string) error {
+func runSessionsRestore(cmd *cobra.Command, args []string) error {
 	store, err := getSessionStore()
 	if err != nil {
 		return err
@@ -495,12 +666,100 @@ func runSessionsExport(cmd *cobra.Command, args []string) error {
 	if sess == nil {
 		return fmt.Errorf("session '%s' not found", args[0])
 	}
+	if !sess.Archived {
+		fmt.Fprintf(cmd.OutOrStdout(), "Session #%d is not archived\n", sess.Number)
+		return nil
+	}
 
-	messages, _, err := session.LoadScrollbackWithBoundary(ctx, store, sess)
+	sess.Archived = false
+	sess.UpdatedAt = time.Now()
+	if err := store.Update(ctx, sess); err != nil {
+		return fmt.Errorf("failed to restore session: %w", err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Restored session: #%d\n", sess.Number)
+	return nil
+}
+
+func runSessionsDoctor(cmd *cobra.Command, args []string) error {
+	store, err := getSessionStore()
 	if err != nil {
-		return fmt.Errorf("failed to get messages: %w", err)
+		return err
+	}
+	defer store.Close()
+
+	sqliteStore, ok := store.(*session.SQLiteStore)
+	if !ok {
+		return fmt.Errorf("session storage is not backed by SQLite")
+	}
+
+	ctx := context.Background()
+	out := cmd.OutOrStdout()
+	report, err := sqliteStore.CheckIntegrity(ctx)
+	if err != nil {
+		return err
+	}
+	if sessionsDoctorRebuild {
+		if err := sqliteStore.RebuildSearchIndex(ctx); err != nil {
+			return err
+		}
+		fmt.Fprintln(out, "Rebuilt message search index")
+		if report, err = sqliteStore.CheckIntegrity(ctx); err != nil {
+			return err
+		}
+	}
+
+	writeIntegrityReport(out, report)
+	if !report.OK() {
+		if report.SearchIndexError != "" && !sessionsDoctorRebuild {
+			fmt.Fprintln(out, "\nRun 'term-llm sessions doctor --rebuild' to repopulate the search index.")
+		}
+		return fmt.Errorf("sessions database has problems")
+	}
+	return nil
+}
+
+func writeIntegrityReport(w io.Writer, report *session.IntegrityReport) {
+	fmt.Fprintf(w, "Database:        %s\n", report.Path)
+	fmt.Fprintf(w, "WAL size:        %s\n", formatBytes(report.WALBytes))
+	if len(report.Problems) == 0 {
+		fmt.Fprintln(w, "Integrity check: ok")
+	} else {
+		fmt.Fprintf(w, "Integrity check: %d problem(s)\n", len(report.Problems))
+		for _, problem := range report.Problems {
+			fmt.Fprintf(w, "  - %s\n", problem)
+		}
+	}
+	if report.SearchIndexError == "" {
+		fmt.Fprintln(w, "Search index:    ok")
+	} else {
+		fmt.Fprintf(w, "Search index:    out of sync (%s)\n", report.SearchIndexError)
+	}
+}
+
+func runSessionsExport(cmd *cobra.Command, args []string) error {
+	format := strings.ToLower(strings.TrimSpace(sessionsExportFormat))
+	switch format {
+	case "md", "markdown":
+		format = "md"
+	case "html", "json":
+	default:
+		return fmt.Errorf("invalid format %q: must be md, html or json", sessionsExportFormat)
+	}
+	if sessionsExportBlobsDir != "" && format != "json" {
+		return fmt.Errorf("--blobs-dir requires --format json")
+	}
+
+	store, err := getSessionStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	sess, err := resolveExportSession(ctx, store, args[0])
+	if err != nil {
+		return err
 	}
-	messages = session.VisibleExportMessages(messages)
 
 	var outputPath string
 	if len(args) > 1 {
@@ -510,22 +769,130 @@ func runSessionsExport(cmd *cobra.Command, args []string) error {
 		if name == "" {
 			name = fmt.Sprintf("session-%d", sess.Number)
 		}
-		outputPath = fmt.Sprintf("%s.md", name)
+		outputPath = fmt.Sprintf("%s.%s", name, format)
 	}
 
-	// Generate markdown with reasoning export policy applied from config and flags.
-	markdown := session.ExportToMarkdown(sess, messages, buildSessionExportOptions(
-		sess,
-		sessionsExportIncludeSystem,
-		sessionsExportIncludeReasoning,
-		sessionsExportIncludeRawReasoning,
-	))
+	var data []byte
+	var count int
+	if format == "json" {
+		// JSON keeps the raw rows, including compaction-tail context, so an
+		// import reproduces the session exactly.
+		messages, err := store.GetMessages(ctx, sess.ID, 0, 0)
+		if err != nil {
+			return fmt.Errorf("failed to get messages: %w", err)
+		}
+		archive := session.NewArchive(sess, messages, session.ExportOptions{
+			OmitTools:          !sessionsExportIncludeTools,
+			MaxToolResultChars: sessionsExportMaxToolResult,
+		})
+		if sessionsExportBlobsDir != "" {
+			if err := archive.ExtractBlobs(sessionsExportBlobsDir); err != nil {
+				return err
+			}
+			archive.BlobDir = relativeBlobDir(outputPath, sessionsExportBlobsDir)
+		}
+		data, err = json.MarshalIndent(archive, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode session: %w", err)
+		}
+		count = len(archive.Messages)
+	} else {
+		messages, _, err := session.LoadScrollbackWithBoundary(ctx, store, sess)
+		if err != nil {
+			return fmt.Errorf("failed to get messages: %w", err)
+		}
+		messages = session.VisibleExportMessages(messages)
+
+		// Apply the reasoning export policy from config and flags.
+		opts := buildSessionExportOptions(
+			sess,
+			sessionsExportIncludeSystem,
+			sessionsExportIncludeReasoning,
+			sessionsExportIncludeRawReasoning,
+		)
+		opts.OmitTools = !sessionsExportIncludeTools
+		opts.MaxToolResultChars = sessionsExportMaxToolResult
+		if format == "html" {
+			html, err := session.ExportToHTML(sess, messages, opts)
+			if err != nil {
+				return err
+			}
+			data = []byte(html)
+		} else {
+			data = []byte(session.ExportToMarkdown(sess, messages, opts))
+		}
+		count = len(messages)
+	}
 
-	if err := os.WriteFile(outputPath, []byte(markdown), 0644); err != nil {
+	if err := os.WriteFile(outputPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
-	fmt.Fprintf(cmd.OutOrStdout(), "Exported %d messages to %s\n", len(messages), outputPath)
+	fmt.Fprintf(cmd.OutOrStdout(), "Exported %d messages to %s\n", count, outputPath)
+	return nil
+}
+
+// resolveExportSession looks up a session by number or ID prefix, or the
+// most recent chat session when ref is "current".
+func resolveExportSession(ctx context.Context, store session.Store, ref string) (*session.Session, error) {
+	var sess *session.Session
+	var err error
+	if strings.EqualFold(ref, "current") {
+		sess, err = store.GetCurrent(ctx)
+	} else {
+		sess, err = store.GetByPrefix(ctx, ref)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if sess == nil {
+		return nil, fmt.Errorf("session '%s' not found", ref)
+	}
+	return sess, nil
+}
+
+// relativeBlobDir expresses blobDir relative to the directory the archive is
+// written to, so the archive and its blobs can be moved together.
+func relativeBlobDir(outputPath, blobDir string) string {
+	absOut, err := filepath.Abs(filepath.Dir(outputPath))
+	if err != nil {
+		return blobDir
+	}
+	absBlobs, err := filepath.Abs(blobDir)
+	if err != nil {
+		return blobDir
+	}
+	rel, err := filepath.Rel(absOut, absBlobs)
+	if err != nil {
+		return absBlobs
+	}
+	return rel
+}
+
+func runSessionsImport(cmd *cobra.Command, args []string) error {
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	archive, err := session.ParseArchive(data)
+	if err != nil {
+		return err
+	}
+	if err := archive.RestoreBlobs(filepath.Dir(args[0])); err != nil {
+		return err
+	}
+
+	store, err := getSessionStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	sess, err := session.ImportArchive(context.Background(), store, archive)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Imported %d messages as session #%d\n", len(archive.Messages), sess.Number)
 	return nil
 }
 
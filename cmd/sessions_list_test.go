@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/session"
+)
+
+func TestSessionForkSuffix(t *testing.T) {
+	if got := sessionForkSuffix(session.SessionSummary{Number: 7}); got != "" {
+		t.Fatalf("sessionForkSuffix(root) = %q, want empty", got)
+	}
+	if got := sessionForkSuffix(session.SessionSummary{Number: 8, ParentID: "abc", ParentNumber: 7, Kind: session.KindFork}); got != "  (fork of #7)" {
+		t.Fatalf("sessionForkSuffix(fork) = %q", got)
+	}
+	if got := sessionForkSuffix(session.SessionSummary{Number: 9, ParentID: "abc", ParentNumber: 7}); got != "" {
+		t.Fatalf("sessionForkSuffix(child run) = %q, want empty", got)
+	}
+}
+
+func TestSessionPurgeSuffix(t *testing.T) {
+	if got := sessionPurgeSuffix(session.SessionSummary{Number: 7, Archived: true}); got != "" {
+		t.Fatalf("sessionPurgeSuffix(user archived) = %q, want empty", got)
+	}
+	at := time.Date(2026, 3, 4, 12, 0, 0, 0, time.Local)
+	if got := sessionPurgeSuffix(session.SessionSummary{Number: 8, Archived: true, PurgeAfter: at}); got != "  (deleted after 2026-03-04)" {
+		t.Fatalf("sessionPurgeSuffix(auto archived) = %q", got)
+	}
+}
+
+func TestWriteIntegrityReport(t *testing.T) {
+	var b strings.Builder
+	writeIntegrityReport(&b, &session.IntegrityReport{
+		Path:             "/tmp/sessions.db",
+		WALBytes:         2048,
+		Problems:         []string{"row 3 missing from index"},
+		SearchIndexError: "database disk image is malformed",
+	})
+	out := b.String()
+	for _, want := range []string{"/tmp/sessions.db", "2.0 KB", "1 problem(s)", "row 3 missing from index", "out of sync"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestSessionModelLabel(t *testing.T) {
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{model: "gpt-5.6-luna", want: "gpt-5.6-luna"},
+		{model: "", want: "-"},
+		{model: "accounts/fireworks/models/kimi-k2-instruct", want: "accounts/fireworks/mo..."},
+	}
+	for _, tt := range tests {
+		if got := sessionModelLabel(session.SessionSummary{Model: tt.model}); got != tt.want {
+			t.Errorf("sessionModelLabel(%q) = %q, want %q", tt.model, got, tt.want)
+		}
+	}
+}
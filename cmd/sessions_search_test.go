@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/session"
+)
+
+func TestParseSessionsSearchRole(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    llm.Role
+		wantErr bool
+	}{
+		{raw: "", want: ""},
+		{raw: "user", want: llm.RoleUser},
+		{raw: " Assistant ", want: llm.RoleAssistant},
+		{raw: "tool", wantErr: true},
+		{raw: "system", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseSessionsSearchRole(tt.raw)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("parseSessionsSearchRole(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Fatalf("parseSessionsSearchRole(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestFormatSessionSearchResultIncludesResumeHint(t *testing.T) {
+	got := formatSessionSearchResult(session.SearchResult{
+		SessionID:           "sess-abc",
+		SessionNumber:       42,
+		GeneratedShortTitle: "Auth refactor",
+		Snippet:             "rotate the **token**\n  before expiry",
+		Provider:            "anthropic",
+		Model:               "claude-sonnet",
+		CreatedAt:           time.Date(2026, 3, 4, 5, 6, 0, 0, time.Local),
+	})
+	for _, want := range []string{
+		"#42 Auth refactor (anthropic/claude-sonnet, 2026-03-04 05:06)",
+		"  rotate the **token** before expiry\n",
+		"resume: term-llm chat --resume sess-abc",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("formatSessionSearchResult() missing %q:\n%s", want, got)
+		}
+	}
+}
@@ -42,17 +42,11 @@ func NewSpawnAgentRunner(cfg *config.Config, yoloMode bool, parentApprovalMgr *t
 // NewSpawnAgentRunnerWithStore creates a new SpawnAgentRunner with session tracking.
 // store is used to save subagent turns, parentSessionID links child sessions to parent.
 func NewSpawnAgentRunnerWithStore(cfg *config.Config, yoloMode bool, parentApprovalMgr *tools.ApprovalManager, store session.Store, parentSessionID string) (*SpawnAgentRunner, error) {
-	registry, err := agents.NewRegistry(agents.RegistryConfig{
-		UseBuiltin:  cfg.Agents.UseBuiltin,
-		SearchPaths: cfg.Agents.SearchPaths,
-	})
+	registry, err := agents.NewRegistryFromConfig(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("create agent registry: %w", err)
 	}
 
-	// Apply agent preferences from config
-	registry.SetPreferences(cfg.Agents.Preferences)
-
 	return &SpawnAgentRunner{
 		cfg:               cfg,
 		registry:          registry,
@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"fmt"
+	"io"
 	"strings"
 	"sync"
 
@@ -8,16 +10,31 @@ import (
 	"github.com/samsaffron/term-llm/internal/ui"
 )
 
-func setEstimatedStatsCost(stats *ui.SessionStats, model string) {
+func setEstimatedStatsCost(stats *ui.SessionStats, provider, model string) {
 	if stats == nil {
 		return
 	}
+	stats.SetProvider(provider)
 	stats.ClearEstimatedCost()
 	if cost, err := ui.EstimateSessionStatsCost(stats, model); err == nil {
 		stats.SetEstimatedCost(cost)
 	}
 }
 
+// finishRunSummary closes the run summary, prices it when pricing is known,
+// records it in the debug log and, when show is set, prints it to w.
+func finishRunSummary(w io.Writer, logger *llm.DebugLogger, summary *ui.RunSummary, show bool) {
+	if summary == nil {
+		return
+	}
+	summary.Finish()
+	_ = summary.EstimateCost()
+	logger.LogRunSummary(summary.Data())
+	if show {
+		fmt.Fprintln(w, summary.Render())
+	}
+}
+
 type compactionUsageEntry struct {
 	model string
 	usage llm.Usage
@@ -1,12 +1,16 @@
 package cmd
 
 import (
+	"errors"
+	"math"
 	"strings"
 	"sync"
 	"testing"
 
 	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/session"
 	"github.com/samsaffron/term-llm/internal/ui"
+	"github.com/samsaffron/term-llm/internal/usage"
 )
 
 func TestCompactionUsageCollectorMergesWithoutSession(t *testing.T) {
@@ -43,7 +47,7 @@ func TestSetEstimatedStatsCostSumsRequestScopedTierPricing(t *testing.T) {
 	stats.AddUsage(200_000, 10_000, 0, 0)
 	stats.AddUsage(200_000, 10_000, 0, 0)
 
-	setEstimatedStatsCost(stats, "")
+	setEstimatedStatsCost(stats, "", "")
 	out := stats.Render()
 	// 400K input at $5/M + 20K output at $30/M = $2.60.
 	if !strings.Contains(out, "$2.6000") {
@@ -57,7 +61,7 @@ func TestSetEstimatedStatsCostTracksModelSwitchPerCall(t *testing.T) {
 	stats.AddUsage(100_000, 0, 0, 0) // $0.50
 	stats.SetModel("gpt-5.6-luna")
 	stats.AddUsage(100_000, 0, 0, 0) // $0.10
-	setEstimatedStatsCost(stats, "gpt-5.6-luna")
+	setEstimatedStatsCost(stats, "", "gpt-5.6-luna")
 	if out := stats.Render(); !strings.Contains(out, "$0.6000") {
 		t.Fatalf("model-switched calls were not priced independently: %s", out)
 	}
@@ -69,19 +73,89 @@ func TestSetEstimatedStatsCostPricesGuardianWithOwnModel(t *testing.T) {
 	stats.AddUsage(100_000, 0, 0, 0)                                 // $0.50
 	stats.AddGuardianUsageForModel("gpt-5.6-luna", 100_000, 0, 0, 0) // $0.10
 
-	setEstimatedStatsCost(stats, "gpt-5.6-sol")
+	setEstimatedStatsCost(stats, "", "gpt-5.6-sol")
 	if out := stats.Render(); !strings.Contains(out, "$0.6000") {
 		t.Fatalf("guardian call was not priced with its own model: %s", out)
 	}
 }
 
+func TestSetEstimatedStatsCostUsesProviderPriceOverrides(t *testing.T) {
+	usage.SetPriceOverrides(map[string]usage.PriceOverride{"local:gpt-5.6-sol": {Input: 1}})
+	t.Cleanup(func() { usage.SetPriceOverrides(nil) })
+
+	stats := ui.NewSessionStats()
+	stats.SetModel("gpt-5.6-sol")
+	stats.AddUsage(1_000_000, 0, 0, 0)
+	setEstimatedStatsCost(stats, "local", "gpt-5.6-sol")
+	if out := stats.Render(); !strings.Contains(out, "$1.0000") {
+		t.Fatalf("stats cost should use the local:gpt-5.6-sol price: %s", out)
+	}
+}
+
 func TestSetEstimatedStatsCostOmitsResumedHistoricalUsage(t *testing.T) {
 	stats := ui.NewSessionStats()
 	stats.SeedTotals(100, 10, 0, 0, 0, 1)
 	stats.SetModel("gpt-5.6-sol")
 	stats.AddUsage(100, 10, 0, 0)
-	setEstimatedStatsCost(stats, "gpt-5.6-sol")
+	setEstimatedStatsCost(stats, "", "gpt-5.6-sol")
 	if out := stats.Render(); strings.Contains(out, "$") {
 		t.Fatalf("resumed whole-session cost should be omitted: %s", out)
 	}
 }
+
+func TestWriteToolStatsTable(t *testing.T) {
+	var b strings.Builder
+	err := writeToolStatsTable(&b, []session.ToolCallStats{
+		{Tool: "read_file", Calls: 20, P50DurationMs: 10, P95DurationMs: 19},
+		{Tool: "shell", Calls: 2, Failures: 1, FailureRate: 0.5, P50DurationMs: 1000, P95DurationMs: 3250},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{"read_file", "10ms", "19ms", "50.0%", "1.0s", "3.2s"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("table missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestSummarizeUsageStatsPricesRowsAndKeepsUnpriced(t *testing.T) {
+	rows := []session.UsageStatsRow{
+		{Day: "2026-10-14", Provider: "anthropic", Model: "claude-sonnet-4-6", Turns: 2, InputTokens: 1_000_000, OutputTokens: 100_000},
+		{Day: "2026-10-15", Provider: "anthropic", Model: "claude-sonnet-4-6", Turns: 1, InputTokens: 500_000, CachedInputTokens: 1_000_000},
+		{Day: "2026-10-15", Provider: "local", Model: "mystery", Turns: 4, InputTokens: 10, OutputTokens: 20},
+	}
+	price := func(provider string, entry usage.UsageEntry) (float64, error) {
+		if entry.Model != "claude-sonnet-4-6" {
+			return 0, errors.New("pricing not found")
+		}
+		// $3/M input, $15/M output, $0.30/M cached.
+		return float64(entry.InputTokens)*3e-6 + float64(entry.OutputTokens)*15e-6 + float64(entry.CacheReadTokens)*0.3e-6, nil
+	}
+
+	report := summarizeUsageStats(rows, price)
+	if len(report.ByModel) != 2 || report.ByModel[0].Key != "anthropic:claude-sonnet-4-6" || report.ByModel[1].Key != "local:mystery" {
+		t.Fatalf("by model = %+v", report.ByModel)
+	}
+	if got := report.ByModel[0]; got.Turns != 3 || math.Abs(got.CostUSD-6.3) > 1e-9 || len(got.Unpriced) != 0 {
+		t.Fatalf("claude group = %+v, want 3 turns costing $6.30", got)
+	}
+	if got := report.ByDay; len(got) != 2 || got[0].Key != "2026-10-14" || math.Abs(got[0].CostUSD-4.5) > 1e-9 ||
+		math.Abs(got[1].CostUSD-1.8) > 1e-9 || len(got[1].Unpriced) != 1 {
+		t.Fatalf("by day = %+v", got)
+	}
+	if total := report.Total; total.Turns != 7 || total.InputTokens != 1_500_010 || len(total.Unpriced) != 1 || total.Unpriced[0] != "local:mystery" {
+		t.Fatalf("total = %+v", total)
+	}
+
+	var b strings.Builder
+	if err := writeUsageStatsReport(&b, report); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"$6.30", "$1.80+", "local:mystery", "No price known for local:mystery"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("report missing %q:\n%s", want, b.String())
+		}
+	}
+}
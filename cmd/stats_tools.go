@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/session"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsToolsDays int
+	statsToolsJSON bool
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show statistics from saved sessions",
+}
+
+var statsToolsCmd = &cobra.Command{
+	Use:   "tools",
+	Short: "Show per-tool call counts, durations and failure rates",
+	Long: `Show how often each tool was called across saved sessions, its median
+(p50) and p95 duration, and how often it failed.
+
+Examples:
+  term-llm stats tools             # last 30 days
+  term-llm stats tools --days 7
+  term-llm stats tools --json`,
+	Args: cobra.NoArgs,
+	RunE: runStatsTools,
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.AddCommand(statsToolsCmd)
+	statsToolsCmd.Flags().IntVar(&statsToolsDays, "days", 30, "Only count tool calls from the last N days")
+	statsToolsCmd.Flags().BoolVar(&statsToolsJSON, "json", false, "Output as JSON")
+}
+
+func runStatsTools(cmd *cobra.Command, args []string) error {
+	if statsToolsDays <= 0 {
+		return fmt.Errorf("--days must be positive")
+	}
+	store, err := getSessionStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	statsStore, ok := store.(session.ToolCallStatsStore)
+	if !ok {
+		return fmt.Errorf("session store does not record tool calls")
+	}
+	since := time.Now().AddDate(0, 0, -statsToolsDays)
+	stats, err := statsStore.ToolCallStats(context.Background(), since)
+	if err != nil {
+		return fmt.Errorf("tool stats: %w", err)
+	}
+
+	if statsToolsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	}
+	if len(stats) == 0 {
+		fmt.Printf("No tool calls recorded in the last %d days\n", statsToolsDays)
+		return nil
+	}
+	return writeToolStatsTable(os.Stdout, stats)
+}
+
+func writeToolStatsTable(w io.Writer, stats []session.ToolCallStats) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Tool\tCalls\tFailed\tFail %\tp50\tp95\t")
+	for _, s := range stats {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f%%\t%s\t%s\t\n", s.Tool, s.Calls, s.Failures, s.FailureRate*100,
+			formatToolDuration(s.P50DurationMs), formatToolDuration(s.P95DurationMs))
+	}
+	return tw.Flush()
+}
+
+func formatToolDuration(ms int64) string {
+	if ms < 1000 {
+		return fmt.Sprintf("%dms", ms)
+	}
+	return fmt.Sprintf("%.1fs", float64(ms)/1000)
+}
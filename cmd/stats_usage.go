@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/session"
+	"github.com/samsaffron/term-llm/internal/usage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsUsageDays     int
+	statsUsageProvider string
+	statsUsageJSON     bool
+)
+
+var statsUsageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Show token usage and estimated cost from saved sessions",
+	Long: `Show the tokens used by saved sessions and their estimated cost, by model
+and by day. Prices come from the pricing config, bundled prices and the
+LiteLLM price table; models without a known price are listed without a cost.
+
+Examples:
+  term-llm stats usage                       # last 30 days
+  term-llm stats usage --days 7 --provider anthropic
+  term-llm stats usage --json`,
+	Args: cobra.NoArgs,
+	RunE: runStatsUsage,
+}
+
+func init() {
+	statsCmd.AddCommand(statsUsageCmd)
+	statsUsageCmd.Flags().IntVar(&statsUsageDays, "days", 30, "Only count usage from the last N days")
+	statsUsageCmd.Flags().StringVar(&statsUsageProvider, "provider", "", "Only count usage of this provider")
+	statsUsageCmd.Flags().BoolVar(&statsUsageJSON, "json", false, "Output as JSON")
+}
+
+// usageStatsGroup is the usage of one model or one day.
+type usageStatsGroup struct {
+	Key               string   `json:"key"`
+	Turns             int      `json:"turns"`
+	InputTokens       int      `json:"input_tokens"`
+	OutputTokens      int      `json:"output_tokens"`
+	CachedInputTokens int      `json:"cached_input_tokens"`
+	CacheWriteTokens  int      `json:"cache_write_tokens"`
+	CostUSD           float64  `json:"cost_usd"`
+	Unpriced          []string `json:"unpriced,omitempty"` // Models whose usage is not in CostUSD
+}
+
+type usageStatsReport struct {
+	Days    int               `json:"days"`
+	ByModel []usageStatsGroup `json:"by_model"`
+	ByDay   []usageStatsGroup `json:"by_day"`
+	Total   usageStatsGroup   `json:"total"`
+}
+
+type usagePriceFunc func(provider string, entry usage.UsageEntry) (float64, error)
+
+func runStatsUsage(cmd *cobra.Command, args []string) error {
+	if statsUsageDays <= 0 {
+		return fmt.Errorf("--days must be positive")
+	}
+	store, err := getSessionStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	statsStore, ok := store.(session.UsageStatsStore)
+	if !ok {
+		return fmt.Errorf("session store does not record usage")
+	}
+	rows, err := statsStore.UsageStats(context.Background(), session.UsageStatsOptions{
+		Since:    time.Now().AddDate(0, 0, -statsUsageDays),
+		Provider: statsUsageProvider,
+	})
+	if err != nil {
+		return fmt.Errorf("usage stats: %w", err)
+	}
+
+	report := summarizeUsageStats(rows, usage.NewPricingFetcher().CalculateProviderCost)
+	report.Days = statsUsageDays
+	if statsUsageJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	if len(rows) == 0 {
+		fmt.Printf("No usage recorded in the last %d days\n", statsUsageDays)
+		return nil
+	}
+	return writeUsageStatsReport(os.Stdout, report)
+}
+
+// summarizeUsageStats prices each row and totals the rows by model and by
+// day. A row whose model has no known price adds its tokens but no cost,
+// and the model is listed as unpriced.
+func summarizeUsageStats(rows []session.UsageStatsRow, price usagePriceFunc) usageStatsReport {
+	byModel := make(map[string]*usageStatsGroup)
+	byDay := make(map[string]*usageStatsGroup)
+	report := usageStatsReport{
+		ByModel: []usageStatsGroup{},
+		ByDay:   []usageStatsGroup{},
+		Total:   usageStatsGroup{Key: "total"},
+	}
+	group := func(groups map[string]*usageStatsGroup, key string) *usageStatsGroup {
+		g, ok := groups[key]
+		if !ok {
+			g = &usageStatsGroup{Key: key}
+			groups[key] = g
+		}
+		return g
+	}
+
+	for _, row := range rows {
+		modelKey := row.Model
+		if row.Provider != "" {
+			modelKey = row.Provider + ":" + row.Model
+		}
+		cost, err := price(row.Provider, usage.UsageEntry{
+			Model:            row.Model,
+			InputTokens:      row.InputTokens,
+			OutputTokens:     row.OutputTokens,
+			CacheReadTokens:  row.CachedInputTokens,
+			CacheWriteTokens: row.CacheWriteTokens,
+		})
+		for _, g := range []*usageStatsGroup{group(byModel, modelKey), group(byDay, row.Day), &report.Total} {
+			g.Turns += row.Turns
+			g.InputTokens += row.InputTokens
+			g.OutputTokens += row.OutputTokens
+			g.CachedInputTokens += row.CachedInputTokens
+			g.CacheWriteTokens += row.CacheWriteTokens
+			if err != nil {
+				g.addUnpriced(modelKey)
+			} else {
+				g.CostUSD += cost
+			}
+		}
+	}
+
+	for _, g := range byModel {
+		report.ByModel = append(report.ByModel, *g)
+	}
+	sort.Slice(report.ByModel, func(i, j int) bool {
+		if report.ByModel[i].CostUSD != report.ByModel[j].CostUSD {
+			return report.ByModel[i].CostUSD > report.ByModel[j].CostUSD
+		}
+		return report.ByModel[i].Key < report.ByModel[j].Key
+	})
+	for _, g := range byDay {
+		report.ByDay = append(report.ByDay, *g)
+	}
+	sort.Slice(report.ByDay, func(i, j int) bool { return report.ByDay[i].Key < report.ByDay[j].Key })
+	return report
+}
+
+func (g *usageStatsGroup) addUnpriced(model string) {
+	for _, m := range g.Unpriced {
+		if m == model {
+			return
+		}
+	}
+	g.Unpriced = append(g.Unpriced, model)
+}
+
+func writeUsageStatsReport(w io.Writer, report usageStatsReport) error {
+	writeGroups := func(title string, groups []usageStatsGroup) error {
+		fmt.Fprintf(w, "%s\n", title)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(tw, "\tTurns\tInput\tOutput\tCached\tCache Write\tCost\t")
+		writeRow := func(g usageStatsGroup) {
+			fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\t%s\t\n", g.Key, g.Turns,
+				formatTokens(g.InputTokens), formatTokens(g.OutputTokens),
+				formatTokens(g.CachedInputTokens), formatTokens(g.CacheWriteTokens), formatUsageGroupCost(g))
+		}
+		for _, g := range groups {
+			writeRow(g)
+		}
+		writeRow(report.Total)
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintln(w)
+		return nil
+	}
+	if err := writeGroups("By model:", report.ByModel); err != nil {
+		return err
+	}
+	if err := writeGroups("By day:", report.ByDay); err != nil {
+		return err
+	}
+	if len(report.Total.Unpriced) > 0 {
+		fmt.Fprintf(w, "No price known for %s; add it under pricing in the config.\n", strings.Join(report.Total.Unpriced, ", "))
+	}
+	return nil
+}
+
+func formatUsageGroupCost(g usageStatsGroup) string {
+	if len(g.Unpriced) == 0 {
+		return formatCost(g.CostUSD)
+	}
+	if g.CostUSD == 0 {
+		return "-"
+	}
+	return formatCost(g.CostUSD) + "+"
+}
@@ -42,7 +42,10 @@ func newReadURLToolForConfig(cfg *config.Config) *llm.ReadURLTool {
 // this instead of calling llm.NewEngine directly.
 func newEngine(provider llm.Provider, cfg *config.Config) *llm.Engine {
 	engine := llm.NewEngine(provider, defaultToolRegistry(cfg))
-	engine.SetMaxToolOutputChars(cfg.Tools.MaxToolOutputChars)
+	if err := engine.ApplyToolsConfig(cfg.Tools); err != nil {
+		log.Printf("Warning: %v; tool timeouts disabled", err)
+	}
+	engine.ApplyRequestsConfig(cfg.Requests)
 	return engine
 }
 
@@ -57,7 +60,13 @@ func buildToolConfig(toolsFlag string, readDirs, writeDirs, shellAllow []string,
 		ShellAutoRun:    cfg.Tools.ShellAutoRun,
 		ShellAutoRunEnv: cfg.Tools.ShellAutoRunEnv,
 		ShellNonTTYEnv:  cfg.Tools.ShellNonTTYEnv,
+		Shell:           cfg.Tools.Shell,
 		ImageProvider:   cfg.Tools.ImageProvider,
+
+		ImageMaxDimension:  cfg.Image.Attachments.MaxDimension,
+		ImageQuality:       cfg.Image.Attachments.Quality,
+		DiffFuzzyMatch:     cfg.Edit.FuzzyMatch,
+		DiffFuzzyThreshold: cfg.Edit.FuzzyThreshold,
 	}
 
 	// Override with CLI flags
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	githubcopilot "github.com/samsaffron/term-llm/internal/copilot"
+	"github.com/samsaffron/term-llm/internal/llm"
 	"github.com/samsaffron/term-llm/internal/usage"
 	"github.com/spf13/cobra"
 )
@@ -24,6 +25,7 @@ var (
 	usageJSON              bool
 	usageBreakdown         bool
 	usageIncludeExternal   bool
+	usageQuota             bool
 	usageCopilotScope      string
 	usageCopilotEntity     string
 	usageCopilotYear       int
@@ -48,11 +50,17 @@ aggregated statistics including token counts and estimated costs.
 For GitHub Copilot, it fetches AI Credit usage from GitHub's latest
 billing usage API. Set GITHUB_TOKEN or GH_TOKEN with billing permissions.
 
+With --quota, it shows plan quota for subscription providers (copilot,
+chatgpt) using their stored login: plan, reset date, and per-feature
+entitlement/used/remaining. --provider chatgpt always shows quota.
+
 Examples:
   term-llm usage                              # show last 7 days
   term-llm usage --provider claude-code       # filter to Claude Code only
   term-llm usage --provider copilot           # show personal GitHub Copilot AI Credit usage
   term-llm usage --provider copilot --copilot-scope org --copilot-entity my-org
+  term-llm usage --provider copilot --quota  # show Copilot plan quota
+  term-llm usage --provider chatgpt          # show ChatGPT plan rate limits
   term-llm usage --provider term-llm          # show term-llm direct API usage
   term-llm usage --since 20250101             # from Jan 1, 2025
   term-llm usage --json                       # output as JSON
@@ -62,11 +70,12 @@ Examples:
 
 func init() {
 	rootCmd.AddCommand(usageCmd)
-	usageCmd.Flags().StringVarP(&usageProvider, "provider", "p", "", "Filter by provider (claude-code, copilot, gemini-cli, term-llm, or all)")
+	usageCmd.Flags().StringVarP(&usageProvider, "provider", "p", "", "Filter by provider (claude-code, copilot, chatgpt, gemini-cli, term-llm, or all)")
 	usageCmd.Flags().StringVar(&usageSince, "since", "", "Start date (YYYYMMDD)")
 	usageCmd.Flags().StringVar(&usageUntil, "until", "", "End date (YYYYMMDD)")
 	usageCmd.Flags().BoolVar(&usageJSON, "json", false, "Output as JSON")
 	usageCmd.Flags().BoolVar(&usageBreakdown, "breakdown", false, "Show per-model breakdown")
+	usageCmd.Flags().BoolVar(&usageQuota, "quota", false, "Show plan quota for subscription providers (copilot, chatgpt)")
 	usageCmd.Flags().BoolVar(&usageIncludeExternal, "include-external", false, "Include externally-tracked term-llm usage (claude-bin, codex, gemini-cli calls)")
 	usageCmd.Flags().StringVar(&usageCopilotScope, "copilot-scope", "user", "Copilot billing scope (user, org, enterprise)")
 	usageCmd.Flags().StringVar(&usageCopilotEntity, "copilot-entity", "", "Copilot billing entity (username, organization, or enterprise slug; defaults to authenticated user for user scope)")
@@ -88,6 +97,10 @@ func init() {
 }
 
 func runUsage(cmd *cobra.Command, args []string) error {
+	if usageQuota || usageProvider == "chatgpt" {
+		return runQuotaUsage(cmd)
+	}
+
 	// Special handling for copilot - fetch latest AI Credit usage from GitHub's billing API
 	if usageProvider == "copilot" {
 		return runCopilotUsage()
@@ -664,3 +677,83 @@ func writeCopilotBreakdown(w io.Writer, title string, breakdown []copilotUsageBr
 	}
 	_ = tw.Flush()
 }
+
+// runQuotaUsage fetches and displays plan quota for a subscription provider
+// using its stored login credentials.
+func runQuotaUsage(cmd *cobra.Command) error {
+	providerName := usageProvider
+	switch providerName {
+	case "copilot", "chatgpt":
+	case "":
+		return fmt.Errorf("--quota requires --provider copilot or --provider chatgpt")
+	default:
+		return fmt.Errorf("quota is only available for copilot and chatgpt, not %q", providerName)
+	}
+	if copilotUsageFlagsChanged(cmd) {
+		return fmt.Errorf("Copilot AI Credit usage flags cannot be combined with --quota")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	provider, err := llm.NewProviderByName(cfg, providerName, "")
+	if err != nil {
+		return err
+	}
+	reporter, ok := provider.(llm.QuotaReporter)
+	if !ok {
+		return fmt.Errorf("provider %s does not report quota", providerName)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	report, err := reporter.GetUsage(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s quota: %w", providerName, err)
+	}
+
+	if usageJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	return writeQuotaText(os.Stdout, report)
+}
+
+func writeQuotaText(w io.Writer, report *llm.QuotaReport) error {
+	if report == nil {
+		return fmt.Errorf("nil quota report")
+	}
+
+	fmt.Fprintf(w, "%s Quota\n", quotaProviderLabel(report.Provider))
+	if report.Plan != "" {
+		fmt.Fprintf(w, "Plan: %s\n", report.Plan)
+	}
+	if !report.ResetAt.IsZero() {
+		fmt.Fprintf(w, "Resets: %s\n", report.ResetAt.Local().Format("2006-01-02 15:04"))
+	}
+	if len(report.Quotas) == 0 {
+		fmt.Fprintln(w, "\nNo quota information reported.")
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FEATURE\tENTITLEMENT\tUSED\tREMAINING\tPERCENT LEFT")
+	for _, q := range report.Quotas {
+		if q.Unlimited {
+			fmt.Fprintf(tw, "%s\tunlimited\t-\t-\t-\n", q.Name)
+			continue
+		}
+		entitlement, used, remaining := "-", "-", "-"
+		if q.Entitlement > 0 {
+			entitlement = formatQuotaNumber(q.Entitlement)
+			used = formatQuotaNumber(q.Used)
+			remaining = formatQuotaNumber(q.Remaining)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%.1f%%\n", q.Name, entitlement, used, remaining, q.PercentRemaining)
+	}
+	return tw.Flush()
+}
@@ -143,6 +143,7 @@ type AgentSource int
 const (
 	SourceLocal   AgentSource = iota // Project-local (./term-llm-agents/)
 	SourceUser                       // User-global (~/.config/term-llm/agents/)
+	SourceConfig                     // Preset in config.yaml (agents.presets)
 	SourceBuiltin                    // Embedded built-in
 )
 
@@ -153,6 +154,8 @@ func (s AgentSource) SourceName() string {
 		return "local"
 	case SourceUser:
 		return "user"
+	case SourceConfig:
+		return "config"
 	case SourceBuiltin:
 		return "builtin"
 	default:
@@ -166,6 +169,11 @@ type ToolsConfig struct {
 	Enabled []string `yaml:"enabled,omitempty"`
 	// Disabled is a deny list (all others enabled)
 	Disabled []string `yaml:"disabled,omitempty"`
+	// Allowed and Denied filter the final tool surface, including MCP and
+	// skill tools and any sub-agents. Filtered tools are hidden from the
+	// model and calls to them are rejected. Denied wins over Allowed.
+	Allowed []string `yaml:"allowed,omitempty"`
+	Denied  []string `yaml:"denied,omitempty"`
 	// Custom is a list of script-backed custom tools declared in agent.yaml
 	Custom []CustomToolDef `yaml:"custom,omitempty"`
 }
@@ -0,0 +1,62 @@
+package agents
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/samsaffron/term-llm/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// FromPreset builds an agent from an agents.presets entry in config.yaml.
+// A relative system_prompt_file is resolved against configDir.
+func FromPreset(name string, preset config.AgentPreset, configDir string) (*Agent, error) {
+	agent := &Agent{
+		Name:         name,
+		Description:  preset.Description,
+		Provider:     preset.Provider,
+		Model:        preset.Model,
+		Tools:        ToolsConfig{Enabled: preset.ToolsEnabled, Disabled: preset.ToolsDisabled, Allowed: preset.AllowedTools, Denied: preset.DeniedTools},
+		MaxTurns:     preset.MaxTurns,
+		Search:       preset.Search,
+		SystemPrompt: preset.SystemPrompt,
+		Source:       SourceConfig,
+	}
+	if file := strings.TrimSpace(preset.SystemPromptFile); file != "" {
+		if strings.HasPrefix(file, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				file = filepath.Join(home, file[2:])
+			}
+		} else if !filepath.IsAbs(file) && configDir != "" {
+			file = filepath.Join(configDir, file)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("agent preset %s: read system_prompt_file: %w", name, err)
+		}
+		agent.SystemPrompt = string(data)
+	}
+	return agent, nil
+}
+
+// copyPresetAgent writes a config preset out as an agent directory, so it
+// can be edited or exported like any other agent.
+func copyPresetAgent(src *Agent, destDir, newName string) error {
+	agent := *src
+	agent.Name = newName
+	agentYAML, err := yaml.Marshal(&agent)
+	if err != nil {
+		return fmt.Errorf("marshal agent.yaml: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(destDir, "agent.yaml"), agentYAML, 0644); err != nil {
+		return fmt.Errorf("write agent.yaml: %w", err)
+	}
+	if src.SystemPrompt != "" {
+		if err := os.WriteFile(filepath.Join(destDir, "system.md"), []byte(src.SystemPrompt), 0644); err != nil {
+			return fmt.Errorf("write system.md: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package agents
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/config"
+)
+
+// ProjectContext supplies the project instruction files found by the same
+// discovery as {{agents}} (user-level AGENTS.md, AGENTS.override.md or the
+// first of names from the repository root down to the working directory,
+// else CLAUDE.md and other fallbacks) for injection into the system prompt.
+// Files are re-read whenever they change on disk, so edits made mid-session
+// apply to the next turn. It is safe for concurrent use.
+type ProjectContext struct {
+	dir      string // Working directory
+	root     string // Repository root, looked up once
+	names    []string
+	maxChars int
+
+	mu       sync.Mutex
+	disabled bool
+	cache    map[string]projectContextEntry // path → last read
+}
+
+// ProjectContextFile describes one injected instruction file.
+type ProjectContextFile struct {
+	Path      string
+	Chars     int // Characters injected
+	Truncated bool
+}
+
+type projectContextEntry struct {
+	modTime time.Time
+	size    int64
+	content string
+}
+
+// NewProjectContext creates a ProjectContext for dir. names are the
+// instruction file names checked in each directory and default to AGENTS.md;
+// maxChars defaults to config.DefaultAgentsMdMaxChars.
+func NewProjectContext(dir string, names []string, maxChars int) *ProjectContext {
+	if maxChars <= 0 {
+		maxChars = config.DefaultAgentsMdMaxChars
+	}
+	p := &ProjectContext{
+		names:    projectInstructionNames(names),
+		maxChars: maxChars,
+		cache:    make(map[string]projectContextEntry),
+	}
+	if strings.TrimSpace(dir) == "" {
+		return p
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	// git reports the root with symlinks resolved.
+	if real, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = real
+	}
+	p.dir = dir
+	p.root = findGitRoot(dir)
+	if p.root == "" {
+		p.root = dir
+	}
+	return p
+}
+
+// Names returns the instruction file names checked in each directory.
+func (p *ProjectContext) Names() []string {
+	if p == nil {
+		return nil
+	}
+	return append([]string(nil), p.names...)
+}
+
+// Enabled reports whether the context is injected.
+func (p *ProjectContext) Enabled() bool {
+	if p == nil {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return !p.disabled
+}
+
+// SetEnabled turns injection on or off.
+func (p *ProjectContext) SetEnabled(enabled bool) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.disabled = !enabled
+	p.mu.Unlock()
+}
+
+// Files returns the instruction files currently found, whether or not
+// injection is enabled.
+func (p *ProjectContext) Files() []ProjectContextFile {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	files, _ := p.loadLocked()
+	return files
+}
+
+// SystemText returns the block appended to the system prompt, or "" when
+// injection is disabled or no instruction file exists.
+func (p *ProjectContext) SystemText() string {
+	if p == nil {
+		return ""
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.disabled {
+		return ""
+	}
+	_, text := p.loadLocked()
+	return text
+}
+
+func (p *ProjectContext) loadLocked() ([]ProjectContextFile, string) {
+	var files []ProjectContextFile
+	var b strings.Builder
+	remaining := p.maxChars
+	seen := make(map[string]bool)
+	for _, path := range projectInstructionPaths(p.dir, p.root, p.names) {
+		seen[path] = true
+		if remaining <= 0 {
+			continue
+		}
+		content, ok := p.readLocked(path)
+		if !ok {
+			continue
+		}
+		content = strings.TrimSpace(content)
+		runes := []rune(content)
+		file := ProjectContextFile{Path: path, Chars: len(runes)}
+		if len(runes) > remaining {
+			content = string(runes[:remaining]) + fmt.Sprintf("\n[truncated: %d of %d characters shown]", remaining, len(runes))
+			file.Chars, file.Truncated = remaining, true
+		}
+		remaining -= file.Chars
+		files = append(files, file)
+		fmt.Fprintf(&b, "\n<file path=%q>\n%s\n</file>\n", path, content)
+	}
+	for path := range p.cache {
+		if !seen[path] {
+			delete(p.cache, path)
+		}
+	}
+	if len(files) == 0 {
+		return nil, ""
+	}
+	return files, "<project_context>\nInstructions from the project's instruction files. Follow them when working in this project." +
+		b.String() + "</project_context>"
+}
+
+// readLocked returns the content of path, re-reading it only when its size
+// or modification time changed.
+func (p *ProjectContext) readLocked(path string) (string, bool) {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		delete(p.cache, path)
+		return "", false
+	}
+	if entry, ok := p.cache[path]; ok && entry.size == info.Size() && entry.modTime.Equal(info.ModTime()) {
+		return entry.content, true
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	p.cache[path] = projectContextEntry{modTime: info.ModTime(), size: info.Size(), content: string(data)}
+	return string(data), true
+}
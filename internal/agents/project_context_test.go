@@ -0,0 +1,143 @@
+package agents
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newProjectContextRepo(t *testing.T) (root, sub string) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root = t.TempDir()
+	sub = filepath.Join(root, "pkg", "sub")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("git", "init", root).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v\n%s", err, out)
+	}
+	return root, sub
+}
+
+func writeProjectFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProjectContextWalksFromRepoRootToDir(t *testing.T) {
+	root, sub := newProjectContextRepo(t)
+	writeProjectFile(t, filepath.Join(root, "AGENTS.md"), "root rules")
+	writeProjectFile(t, filepath.Join(sub, "CLAUDE.md"), "sub claude rules")
+	writeProjectFile(t, filepath.Join(root, "..", "AGENTS.md"), "outside the repo")
+	t.Cleanup(func() { os.Remove(filepath.Join(root, "..", "AGENTS.md")) })
+
+	text := NewProjectContext(sub, nil, 0).SystemText()
+	if !strings.Contains(text, "root rules") || strings.Contains(text, "sub claude rules") || strings.Contains(text, "outside the repo") {
+		t.Fatalf("want only AGENTS.md inside the repo, with no fallback:\n%s", text)
+	}
+	if !strings.HasPrefix(text, "<project_context>") || !strings.HasSuffix(text, "</project_context>") {
+		t.Fatalf("missing delimiter:\n%s", text)
+	}
+
+	writeProjectFile(t, filepath.Join(sub, "AGENTS.md"), "sub rules")
+	writeProjectFile(t, filepath.Join(sub, "AGENTS.override.md"), "sub override rules")
+	pc := NewProjectContext(sub, nil, 0)
+	text = pc.SystemText()
+	if strings.Index(text, "root rules") > strings.Index(text, "sub override rules") || strings.Contains(text, "sub rules") {
+		t.Fatalf("want root then the sub override, not sub AGENTS.md:\n%s", text)
+	}
+	if files := pc.Files(); len(files) != 2 {
+		t.Fatalf("files = %+v, want two", files)
+	}
+	if got := DiscoverProjectInstructionsInDir(sub); !strings.Contains(got, "sub override rules") || strings.Contains(got, "sub rules") {
+		t.Fatalf("{{agents}} discovery disagrees with the injected context:\n%s", got)
+	}
+}
+
+func TestProjectContextUsesUserAndFallbackInstructions(t *testing.T) {
+	root, sub := newProjectContextRepo(t)
+	writeProjectFile(t, filepath.Join(sub, "CLAUDE.md"), "claude rules")
+	userDir := filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "term-llm")
+	if err := os.MkdirAll(userDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeProjectFile(t, filepath.Join(userDir, "AGENTS.md"), "user rules")
+
+	text := NewProjectContext(root, nil, 0).SystemText()
+	if !strings.Contains(text, "user rules") || strings.Contains(text, "claude rules") {
+		t.Fatalf("want the user-level file only at the root:\n%s", text)
+	}
+	text = NewProjectContext(sub, nil, 0).SystemText()
+	if strings.Index(text, "user rules") > strings.Index(text, "claude rules") || !strings.Contains(text, "claude rules") {
+		t.Fatalf("want user rules then the CLAUDE.md fallback:\n%s", text)
+	}
+}
+
+func TestProjectContextPicksUpEditsAndCanBeDisabled(t *testing.T) {
+	root, _ := newProjectContextRepo(t)
+	path := filepath.Join(root, "AGENTS.md")
+	pc := NewProjectContext(root, nil, 0)
+	if text := pc.SystemText(); text != "" {
+		t.Fatalf("no file yet, got %q", text)
+	}
+
+	writeProjectFile(t, path, "first version")
+	if text := pc.SystemText(); !strings.Contains(text, "first version") {
+		t.Fatalf("new file not picked up:\n%s", text)
+	}
+	writeProjectFile(t, path, "second version, longer")
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatal(err)
+	}
+	if text := pc.SystemText(); !strings.Contains(text, "second version") {
+		t.Fatalf("edit not picked up:\n%s", text)
+	}
+
+	pc.SetEnabled(false)
+	if text := pc.SystemText(); text != "" {
+		t.Fatalf("disabled context still injected: %q", text)
+	}
+	if len(pc.Files()) != 1 {
+		t.Fatal("Files should still report what would be injected")
+	}
+}
+
+func TestProjectContextTruncatesAtCap(t *testing.T) {
+	root, _ := newProjectContextRepo(t)
+	writeProjectFile(t, filepath.Join(root, "AGENTS.md"), strings.Repeat("x", 500))
+
+	pc := NewProjectContext(root, nil, 100)
+	text := pc.SystemText()
+	if !strings.Contains(text, "\n"+strings.Repeat("x", 100)+"\n[truncated: 100 of 500 characters shown]") {
+		t.Fatalf("want 100 characters and a truncation note:\n%s", text)
+	}
+	if files := pc.Files(); len(files) != 1 || !files[0].Truncated || files[0].Chars != 100 {
+		t.Fatalf("files = %+v", files)
+	}
+}
+
+func TestProjectContextUsesConfiguredNames(t *testing.T) {
+	root, sub := newProjectContextRepo(t)
+	writeProjectFile(t, filepath.Join(root, "AGENTS.md"), "root agents rules")
+	writeProjectFile(t, filepath.Join(root, "CLAUDE.md"), "root claude rules")
+	writeProjectFile(t, filepath.Join(sub, "CLAUDE.md"), "sub claude rules")
+
+	pc := NewProjectContext(sub, []string{"CLAUDE.md", "AGENTS.md"}, 0)
+	text := pc.SystemText()
+	if !strings.Contains(text, "root claude rules") || strings.Contains(text, "root agents rules") || !strings.Contains(text, "sub claude rules") {
+		t.Fatalf("want the first configured name in each directory:\n%s", text)
+	}
+	if got := strings.Join(pc.Names(), ","); got != "CLAUDE.md,AGENTS.md" {
+		t.Fatalf("Names() = %s", got)
+	}
+	if got := strings.Join(NewProjectContext(sub, []string{" "}, 0).Names(), ","); got != "AGENTS.md" {
+		t.Fatalf("blank names should fall back to AGENTS.md, got %s", got)
+	}
+}
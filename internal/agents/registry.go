@@ -5,9 +5,11 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 
 	"github.com/samsaffron/term-llm/internal/config"
+	"github.com/samsaffron/term-llm/internal/pathutil"
 )
 
 // Registry manages agent discovery and resolution.
@@ -25,6 +27,10 @@ type Registry struct {
 
 	// Preferences to apply on top of agent configs
 	preferences map[string]config.AgentPreference
+
+	// Agents defined in config.yaml, resolved after agent directories
+	presets   map[string]config.AgentPreset
+	configDir string
 }
 
 type searchPath struct {
@@ -56,12 +62,8 @@ func NewRegistry(cfg RegistryConfig) (*Registry, error) {
 	}
 
 	// 2. User-global agents (~/.config/term-llm/agents/)
-	if home, err := os.UserHomeDir(); err == nil {
-		configDir := os.Getenv("XDG_CONFIG_HOME")
-		if configDir == "" {
-			configDir = filepath.Join(home, ".config")
-		}
-		userDir := filepath.Join(configDir, "term-llm", "agents")
+	if configDir, err := pathutil.ConfigDir(); err == nil {
+		userDir := filepath.Join(configDir, "agents")
 		r.searchPaths = append(r.searchPaths, searchPath{
 			path:   userDir,
 			source: SourceUser,
@@ -101,8 +103,41 @@ func (r *Registry) SetPreferences(prefs map[string]config.AgentPreference) {
 	r.preferences = prefs
 }
 
+// NewRegistryFromConfig creates a registry with the search paths,
+// preferences and presets from cfg.
+func NewRegistryFromConfig(cfg *config.Config) (*Registry, error) {
+	r, err := NewRegistry(RegistryConfig{
+		UseBuiltin:  cfg.Agents.UseBuiltin,
+		SearchPaths: cfg.Agents.SearchPaths,
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.SetPreferences(cfg.Agents.Preferences)
+	configDir, _ := config.GetConfigDir()
+	r.SetPresets(cfg.Agents.Presets, configDir)
+	return r, nil
+}
+
+// SetPresets sets the agents defined in config.yaml. Relative
+// system_prompt_file paths are resolved against configDir.
+func (r *Registry) SetPresets(presets map[string]config.AgentPreset, configDir string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name := range r.cache {
+		if _, ok := presets[name]; ok {
+			delete(r.cache, name)
+		}
+		if _, ok := r.presets[name]; ok {
+			delete(r.cache, name)
+		}
+	}
+	r.presets = presets
+	r.configDir = configDir
+}
+
 // Get retrieves an agent by name.
-// Resolution order: local > user > search paths > builtin
+// Resolution order: local > user > search paths > config presets > builtin
 // Preferences are applied on top of the loaded agent config.
 func (r *Registry) Get(name string) (*Agent, error) {
 	r.mu.Lock()
@@ -128,16 +163,22 @@ func (r *Registry) Get(name string) (*Agent, error) {
 		}
 	}
 
+	if agent == nil {
+		if preset, ok := r.presets[name]; ok {
+			agent, err = FromPreset(name, preset, r.configDir)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	// Check built-in agents if not found in filesystem
 	if agent == nil && r.useBuiltin {
-		agent, err = getBuiltinAgent(name)
-		if err != nil {
-			return nil, fmt.Errorf("agent not found: %s", name)
-		}
+		agent, _ = getBuiltinAgent(name)
 	}
 
 	if agent == nil {
-		return nil, fmt.Errorf("agent not found: %s", name)
+		return nil, r.notFoundError(name)
 	}
 
 	// Apply preferences on top of agent config
@@ -151,6 +192,16 @@ func (r *Registry) Get(name string) (*Agent, error) {
 	return agent, nil
 }
 
+// notFoundError reports an unknown agent name along with the names that
+// would have worked. Called with r.mu held.
+func (r *Registry) notFoundError(name string) error {
+	names, _ := r.listNames()
+	if len(names) == 0 {
+		return fmt.Errorf("agent not found: %s", name)
+	}
+	return fmt.Errorf("agent not found: %s (available: %s)", name, strings.Join(names, ", "))
+}
+
 // List returns all available agents.
 // Each agent appears only once, with first-found taking precedence.
 func (r *Registry) List() ([]*Agent, error) {
@@ -171,6 +222,19 @@ func (r *Registry) List() ([]*Agent, error) {
 		}
 	}
 
+	// Add config presets (not shadowed by agent directories)
+	for name, preset := range r.presets {
+		if seen[name] {
+			continue
+		}
+		agent, err := FromPreset(name, preset, r.configDir)
+		if err != nil {
+			continue // Skip presets whose prompt file can't be read
+		}
+		seen[name] = true
+		agents = append(agents, agent)
+	}
+
 	// Add built-in agents (not shadowed by user agents)
 	if r.useBuiltin {
 		for _, agent := range getBuiltinAgents() {
@@ -184,7 +248,7 @@ func (r *Registry) List() ([]*Agent, error) {
 	// Sort by source first (builtin first), then by name
 	sort.Slice(agents, func(i, j int) bool {
 		if agents[i].Source != agents[j].Source {
-			// Builtin (2) > User (1) > Local (0) - reverse enum order
+			// Builtin > Config > User > Local - reverse enum order
 			return agents[i].Source > agents[j].Source
 		}
 		return agents[i].Name < agents[j].Name
@@ -196,6 +260,12 @@ func (r *Registry) List() ([]*Agent, error) {
 // ListNames returns just the names of available agents without loading full content.
 // This is optimized for completions where only names are needed.
 func (r *Registry) ListNames() ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.listNames()
+}
+
+func (r *Registry) listNames() ([]string, error) {
 	seen := make(map[string]bool)
 	var names []string
 
@@ -222,6 +292,14 @@ func (r *Registry) ListNames() ([]string, error) {
 		}
 	}
 
+	// Add config preset names
+	for name := range r.presets {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
 	// Add built-in agent names
 	if r.useBuiltin {
 		for _, name := range builtinAgentNames {
@@ -291,17 +369,12 @@ func isAgentDir(dir string) bool {
 
 // GetUserAgentsDir returns the path for user-global agents.
 func GetUserAgentsDir() (string, error) {
-	home, err := os.UserHomeDir()
+	configDir, err := pathutil.ConfigDir()
 	if err != nil {
 		return "", err
 	}
 
-	configDir := os.Getenv("XDG_CONFIG_HOME")
-	if configDir == "" {
-		configDir = filepath.Join(home, ".config")
-	}
-
-	return filepath.Join(configDir, "term-llm", "agents"), nil
+	return filepath.Join(configDir, "agents"), nil
 }
 
 // GetLocalAgentsDir returns the path for project-local agents.
@@ -406,6 +479,10 @@ func CopyAgent(src *Agent, destDir, newName string) error {
 				return fmt.Errorf("write system.md: %w", err)
 			}
 		}
+	} else if src.Source == SourceConfig {
+		if err := copyPresetAgent(src, destAgentDir, newName); err != nil {
+			return err
+		}
 	} else {
 		// For built-in agents, get the embedded content
 		if err := copyBuiltinAgent(src.Name, destAgentDir, newName); err != nil {
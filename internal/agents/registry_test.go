@@ -404,3 +404,85 @@ func TestRegistry_GetConcurrentColdBuiltin(t *testing.T) {
 		wg.Wait()
 	}
 }
+
+func TestRegistry_ConfigPresets(t *testing.T) {
+	tmpDir := t.TempDir()
+	userDir := filepath.Join(tmpDir, "user-agents")
+	if err := os.MkdirAll(filepath.Join(userDir, "shadowed"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(userDir, "shadowed", "agent.yaml"), []byte("name: shadowed\ndescription: from dir"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "review.md"), []byte("Review carefully."), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &Registry{
+		cache:       make(map[string]*Agent),
+		searchPaths: []searchPath{{path: userDir, source: SourceUser}},
+	}
+	r.SetPresets(map[string]config.AgentPreset{
+		"reviewer": {
+			Description:      "Code reviewer",
+			Provider:         "anthropic",
+			Model:            "claude-sonnet-4-5",
+			ToolsEnabled:     []string{"read_file", "grep"},
+			SystemPromptFile: "review.md",
+			Search:           true,
+			MaxTurns:         5,
+		},
+		"shadowed": {Description: "from config"},
+	}, tmpDir)
+
+	agent, err := r.Get("reviewer")
+	if err != nil {
+		t.Fatalf("Get(reviewer): %v", err)
+	}
+	if agent.Source != SourceConfig || agent.Provider != "anthropic" || agent.Model != "claude-sonnet-4-5" ||
+		!agent.Search || agent.MaxTurns != 5 || strings.Join(agent.Tools.Enabled, ",") != "read_file,grep" {
+		t.Fatalf("reviewer = %+v", agent)
+	}
+	if agent.SystemPrompt != "Review carefully." {
+		t.Fatalf("SystemPrompt = %q, want contents of system_prompt_file", agent.SystemPrompt)
+	}
+
+	agent, err = r.Get("shadowed")
+	if err != nil {
+		t.Fatalf("Get(shadowed): %v", err)
+	}
+	if agent.Source != SourceUser || agent.Description != "from dir" {
+		t.Fatalf("agent directories should win over presets, got %+v", agent)
+	}
+
+	_, err = r.Get("missing")
+	if err == nil || !strings.Contains(err.Error(), "available: reviewer, shadowed") {
+		t.Fatalf("Get(missing) error = %v, want it to list available agents", err)
+	}
+
+	names, err := r.ListNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(names, ",") != "reviewer,shadowed" {
+		t.Fatalf("ListNames() = %v", names)
+	}
+}
+
+func TestCopyAgent_ConfigPreset(t *testing.T) {
+	src, err := FromPreset("reviewer", config.AgentPreset{Description: "Code reviewer", Model: "gpt-5", SystemPrompt: "Be terse."}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	destDir := t.TempDir()
+	if err := CopyAgent(src, destDir, "my-reviewer"); err != nil {
+		t.Fatalf("CopyAgent: %v", err)
+	}
+	copied, err := LoadFromDir(filepath.Join(destDir, "my-reviewer"), SourceUser)
+	if err != nil {
+		t.Fatalf("load copy: %v", err)
+	}
+	if copied.Name != "my-reviewer" || copied.Model != "gpt-5" || copied.SystemPrompt != "Be terse." {
+		t.Fatalf("copied agent = %+v", copied)
+	}
+}
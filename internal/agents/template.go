@@ -110,6 +110,12 @@ func NewTemplateContextForTemplateInDir(template, dir string) TemplateContext {
 	return newTemplateContextInDir(dir, needsGitInfo, needsGitDiffStat, needsAgents, needsHandoverDir)
 }
 
+// UsesProjectInstructions reports whether template expands {{agents}}, the
+// discovered project instructions.
+func UsesProjectInstructions(template string) bool {
+	return templateVariables(template)["agents"]
+}
+
 func templateVariables(template string) map[string]bool {
 	vars := make(map[string]bool)
 	for _, match := range templateVarPattern.FindAllStringSubmatch(template, -1) {
@@ -516,29 +522,69 @@ func loadProjectInstructions(runtimeDirs ...string) string {
 		cwd, _ = os.Getwd()
 	}
 	var parts []string
+	for _, path := range projectInstructionPaths(cwd, "", nil) {
+		if content, err := os.ReadFile(path); err == nil && len(content) > 0 {
+			parts = append(parts, string(content))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.Join(parts, "\n\n---\n\n")
+}
+
+// defaultProjectInstructionNames are the per-directory instruction file
+// names used when the caller does not configure any.
+var defaultProjectInstructionNames = []string{"AGENTS.md"}
+
+// projectInstructionNames returns names without blanks, or the default names
+// when none are left.
+func projectInstructionNames(names []string) []string {
+	var cleaned []string
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			cleaned = append(cleaned, name)
+		}
+	}
+	if len(cleaned) == 0 {
+		return defaultProjectInstructionNames
+	}
+	return cleaned
+}
+
+// projectInstructionPaths lists the non-empty instruction files the unified
+// algorithm loads for cwd, in order. repoRoot is looked up when empty; callers
+// that already know it pass it to skip the git call. names are checked in
+// each directory after AGENTS.override.md, first match wins; nil means
+// AGENTS.md.
+func projectInstructionPaths(cwd, repoRoot string, names []string) []string {
+	names = projectInstructionNames(names)
+	var paths []string
 
 	// 1. User-level AGENTS.md (~/.config/term-llm/AGENTS.md)
 	if configDir, err := config.GetConfigDir(); err == nil {
 		userAgentsPath := filepath.Join(configDir, "AGENTS.md")
-		if content, err := os.ReadFile(userAgentsPath); err == nil && len(content) > 0 {
-			parts = append(parts, string(content))
+		if nonEmptyFile(userAgentsPath) {
+			paths = append(paths, userAgentsPath)
 		}
 	}
 
 	if strings.TrimSpace(cwd) == "" {
-		return strings.Join(parts, "\n\n---\n\n")
+		return paths
 	}
 	if abs, err := filepath.Abs(cwd); err == nil {
 		cwd = abs
 	}
 
 	// 2. Project-level: hierarchical AGENTS.md from repo root → cwd
-	repoRoot := findGitRoot(cwd)
+	if repoRoot == "" {
+		repoRoot = findGitRoot(cwd)
+	}
 	if repoRoot == "" {
 		repoRoot = cwd
 	}
 
-	var projectParts []string
+	var projectPaths []string
 
 	// Build directory list from repo root → cwd (root first)
 	dirs := []string{repoRoot}
@@ -553,34 +599,43 @@ func loadProjectInstructions(runtimeDirs ...string) string {
 
 	for _, dir := range dirs {
 		// AGENTS.override.md takes precedence at each level
-		if content, err := os.ReadFile(filepath.Join(dir, "AGENTS.override.md")); err == nil && len(content) > 0 {
-			projectParts = append(projectParts, string(content))
+		if path := filepath.Join(dir, "AGENTS.override.md"); nonEmptyFile(path) {
+			projectPaths = append(projectPaths, path)
 			continue
 		}
-		if content, err := os.ReadFile(filepath.Join(dir, "AGENTS.md")); err == nil && len(content) > 0 {
-			projectParts = append(projectParts, string(content))
+		for _, name := range names {
+			if path := filepath.Join(dir, name); nonEmptyFile(path) {
+				projectPaths = append(projectPaths, path)
+				break
+			}
 		}
 	}
 
-	if len(projectParts) > 0 {
-		parts = append(parts, projectParts...)
-	} else {
+	if len(projectPaths) > 0 {
+		paths = append(paths, projectPaths...)
+	} else if fallback := findFallbackInstructionPath(cwd, repoRoot); fallback != "" {
 		// 3. Fallback: search cwd → root for first match
-		fallback := findFallbackInstructions(cwd, repoRoot)
-		if fallback != "" {
-			parts = append(parts, fallback)
-		}
+		paths = append(paths, fallback)
 	}
-
-	if len(parts) == 0 {
-		return ""
-	}
-	return strings.Join(parts, "\n\n---\n\n")
+	return paths
 }
 
 // findFallbackInstructions searches from cwd up to repoRoot for the first
 // matching fallback instruction file (CLAUDE.md, copilot-instructions.md, etc.).
 func findFallbackInstructions(cwd, repoRoot string) string {
+	path := findFallbackInstructionPath(cwd, repoRoot)
+	if path == "" {
+		return ""
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return string(content)
+}
+
+// findFallbackInstructionPath returns the path findFallbackInstructions reads.
+func findFallbackInstructionPath(cwd, repoRoot string) string {
 	// Build list: cwd first, then walk up to repo root
 	dirsToSearch := []string{cwd}
 	if repoRoot != cwd {
@@ -595,15 +650,19 @@ func findFallbackInstructions(cwd, repoRoot string) string {
 	for _, dir := range dirsToSearch {
 		for _, filename := range fallbackInstructionFiles {
 			path := filepath.Join(dir, filename)
-			content, err := os.ReadFile(path)
-			if err == nil && len(content) > 0 {
-				return string(content)
+			if nonEmptyFile(path) {
+				return path
 			}
 		}
 	}
 	return ""
 }
 
+func nonEmptyFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir() && info.Size() > 0
+}
+
 // findGitRoot returns the git repository root for the given path, or empty string if not in a repo.
 func findGitRoot(path string) string {
 	output, err := runGitOutput(path, "rev-parse", "--show-toplevel")
@@ -26,6 +26,8 @@ type CachedModel struct {
 	InputLimit  int     `json:"input_limit,omitempty"`
 	InputPrice  float64 `json:"input_price,omitempty"`
 	OutputPrice float64 `json:"output_price,omitempty"`
+
+	PremiumMultiplier float64 `json:"premium_multiplier,omitempty"`
 }
 
 func getCacheDir() (string, error) {
@@ -8,9 +8,11 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"time"
 
 	mapstructure "github.com/go-viper/mapstructure/v2"
 	"github.com/samsaffron/term-llm/internal/credentials"
+	"github.com/samsaffron/term-llm/internal/pathutil"
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
 )
@@ -60,9 +62,18 @@ var builtInProviderTypes = map[string]ProviderType{
 	"ollama":     ProviderTypeOllama,
 }
 
+// providerTypeAliases maps alternate spellings accepted in `type:` to the
+// provider type they stand for.
+var providerTypeAliases = map[ProviderType]ProviderType{
+	"openai-compatible": ProviderTypeOpenAICompat,
+}
+
 // InferProviderType returns the provider type for a given provider name
 // Explicit type takes precedence, then built-in names, then defaults to openai_compatible
 func InferProviderType(name string, explicit ProviderType) ProviderType {
+	if alias, ok := providerTypeAliases[explicit]; ok {
+		return alias
+	}
 	if explicit != "" {
 		return explicit
 	}
@@ -122,6 +133,7 @@ type ProviderModelConfig struct {
 	ThinkingParam    string   `mapstructure:"thinking_param" yaml:"thinking_param,omitempty"`
 	ReasoningEfforts []string `mapstructure:"reasoning_efforts" yaml:"reasoning_efforts,omitempty"`
 	VisionVia        string   `mapstructure:"vision_via" yaml:"vision_via,omitempty"`
+	Fallbacks        []string `mapstructure:"fallbacks" yaml:"fallbacks,omitempty"`
 }
 
 func (m ProviderModelConfig) DisplayName() string {
@@ -149,9 +161,12 @@ type ProviderConfig struct {
 	Env          map[string]string     `mapstructure:"env"`           // Extra subprocess env vars for providers that shell out (e.g. claude-bin)
 	EnableHooks  bool                  `mapstructure:"enable_hooks"`  // Opt in to Claude Code hooks for claude-bin (disabled by default)
 	UseWebSocket bool                  `mapstructure:"use_websocket"` // Enable Responses-over-WebSocket for providers that support it
-	Responses    ResponsesConfig       `mapstructure:"responses"`     // Advanced Responses API execution controls
-	FileUpload   *FileUploadConfig     `mapstructure:"file_upload"`   // Optional upload/native-file support overrides
-	VisionVia    string                `mapstructure:"vision_via"`    // Optional provider:model route for indirect image understanding
+	// Copilot: false stops requesting and resending encrypted reasoning
+	EncryptedReasoning *bool             `mapstructure:"encrypted_reasoning"`
+	Responses          ResponsesConfig   `mapstructure:"responses"`   // Advanced Responses API execution controls
+	FileUpload         *FileUploadConfig `mapstructure:"file_upload"` // Optional upload/native-file support overrides
+	VisionVia          string            `mapstructure:"vision_via"`  // Optional provider:model route for indirect image understanding
+	Fallbacks          []string          `mapstructure:"fallbacks"`   // Ordered provider:model entries tried when this provider fails
 
 	// Search behavior - nil means auto (use native if available)
 	UseNativeSearch *bool `mapstructure:"use_native_search"`
@@ -164,6 +179,8 @@ type ProviderConfig struct {
 	BaseURL           string `mapstructure:"base_url"`            // Base URL - /chat/completions is appended
 	URL               string `mapstructure:"url"`                 // Full URL - used as-is without appending endpoint
 	NoStreamOptions   bool   `mapstructure:"no_stream_options"`   // Don't send stream_options (for servers that reject it)
+	ToolCalls         *bool  `mapstructure:"tool_calls"`          // nil = send tools until the server rejects them; false = never send tools
+	StructuredOutput  *bool  `mapstructure:"structured_output"`   // Send response_format; nil = on for vllm, off for other compatible servers
 	VLLMThinkingParam string `mapstructure:"vllm_thinking_param"` // vLLM chat_template_kwargs key: "enable_thinking" (Qwen) or "thinking" (DeepSeek)
 	ParseReasoning    *bool  `mapstructure:"parse_reasoning"`     // Send parse_reasoning for OpenAI-compatible reasoning parsers
 	IncludeReasoning  *bool  `mapstructure:"include_reasoning"`   // Send include_reasoning when parse_reasoning is enabled
@@ -468,6 +485,11 @@ type Config struct {
 	AutoCompact     bool                      `mapstructure:"auto_compact"`
 	Serve           ServeConfig               `mapstructure:"serve"`
 	FileTracking    FileTrackingConfig        `mapstructure:"file_tracking"`
+	Quota           QuotaConfig               `mapstructure:"quota"`
+	Requests        RequestsConfig            `mapstructure:"requests"`
+	Pricing         []PricingConfig           `mapstructure:"pricing"`    // Price overrides for cost estimates
+	Tokenizers      []TokenizerConfig         `mapstructure:"tokenizers"` // Model → token encoding overrides for context estimates
+	Prompts         map[string]string         `mapstructure:"prompts"`    // Named chat prompt templates, used as /t <name>
 }
 
 // ApprovalConfig configures default approval behavior.
@@ -488,17 +510,25 @@ type GuardianConfig struct {
 
 // ServeConfig holds configuration for the serve command platforms.
 type ServeConfig struct {
-	Platforms              []string            `mapstructure:"platforms" yaml:"platforms,omitempty"`
-	ApprovalMode           string              `mapstructure:"approval_mode" yaml:"approval_mode,omitempty"`
-	BasePath               string              `mapstructure:"base_path" yaml:"base_path,omitempty"`
-	Title                  string              `mapstructure:"title" yaml:"title,omitempty"`
-	DisableLocationSharing bool                `mapstructure:"disable_location_sharing" yaml:"disable_location_sharing,omitempty"`
-	FilesDir               string              `mapstructure:"files_dir" yaml:"files_dir,omitempty"`
-	WidgetsDir             string              `mapstructure:"widgets_dir" yaml:"widgets_dir,omitempty"`
-	ResponseTimeout        string              `mapstructure:"response_timeout" yaml:"response_timeout,omitempty"` // Go duration string, e.g. "30m" or "1h"
-	Telegram               TelegramServeConfig `mapstructure:"telegram" yaml:"telegram,omitempty"`
-	WebPush                WebPushConfig       `mapstructure:"web_push" yaml:"web_push,omitempty"`
-	MCP                    ServeMCPConfig      `mapstructure:"mcp" yaml:"mcp,omitempty"`
+	Platforms                []string            `mapstructure:"platforms" yaml:"platforms,omitempty"`
+	ApprovalMode             string              `mapstructure:"approval_mode" yaml:"approval_mode,omitempty"`
+	BasePath                 string              `mapstructure:"base_path" yaml:"base_path,omitempty"`
+	Title                    string              `mapstructure:"title" yaml:"title,omitempty"`
+	DisableLocationSharing   bool                `mapstructure:"disable_location_sharing" yaml:"disable_location_sharing,omitempty"`
+	DisableMetrics           bool                `mapstructure:"disable_metrics" yaml:"disable_metrics,omitempty"` // Turn off /metrics
+	PublicMetrics            bool                `mapstructure:"public_metrics" yaml:"public_metrics,omitempty"`   // Serve /metrics without the bearer token
+	FilesDir                 string              `mapstructure:"files_dir" yaml:"files_dir,omitempty"`
+	WidgetsDir               string              `mapstructure:"widgets_dir" yaml:"widgets_dir,omitempty"`
+	ResponseTimeout          string              `mapstructure:"response_timeout" yaml:"response_timeout,omitempty"`                       // Go duration string, e.g. "30m" or "1h"
+	DrainTimeout             string              `mapstructure:"drain_timeout" yaml:"drain_timeout,omitempty"`                             // Go duration string; how long shutdown waits for active streams
+	ReplayMaxEvents          int                 `mapstructure:"replay_max_events" yaml:"replay_max_events,omitempty"`                     // Response events kept per run for reconnect replay
+	ReplayMaxBytes           int                 `mapstructure:"replay_max_bytes" yaml:"replay_max_bytes,omitempty"`                       // Encoded event bytes kept per run for reconnect replay
+	MaxConcurrentRuns        int                 `mapstructure:"max_concurrent_runs" yaml:"max_concurrent_runs,omitempty"`                 // In-flight API generation requests; 0 disables
+	SessionMessagesPerMinute int                 `mapstructure:"session_messages_per_minute" yaml:"session_messages_per_minute,omitempty"` // 0 disables
+	NewSessionsPerMinute     int                 `mapstructure:"new_sessions_per_minute" yaml:"new_sessions_per_minute,omitempty"`         // Per source IP; 0 disables
+	Telegram                 TelegramServeConfig `mapstructure:"telegram" yaml:"telegram,omitempty"`
+	WebPush                  WebPushConfig       `mapstructure:"web_push" yaml:"web_push,omitempty"`
+	MCP                      ServeMCPConfig      `mapstructure:"mcp" yaml:"mcp,omitempty"`
 }
 
 // ServeMCPConfig configures the standalone term-llm serve mcp surface.
@@ -527,6 +557,33 @@ type AgentsConfig struct {
 	UseBuiltin  bool                       `mapstructure:"use_builtin"`  // Enable built-in agents (default true)
 	SearchPaths []string                   `mapstructure:"search_paths"` // Additional directories to search for agents
 	Preferences map[string]AgentPreference `mapstructure:"preferences"`  // Per-agent preference overrides
+	Presets     map[string]AgentPreset     `mapstructure:"presets"`      // Agents defined inline in config.yaml
+}
+
+// AgentPreset defines a named agent directly in config.yaml, for the common
+// case of a model, tool set and system prompt that doesn't need an agent
+// directory. Agent directories with the same name take precedence.
+type AgentPreset struct {
+	Description string `mapstructure:"description,omitempty" yaml:"description,omitempty"`
+
+	Provider string `mapstructure:"provider,omitempty" yaml:"provider,omitempty"`
+	Model    string `mapstructure:"model,omitempty" yaml:"model,omitempty"`
+
+	ToolsEnabled  []string `mapstructure:"tools_enabled,omitempty" yaml:"tools_enabled,omitempty"`
+	ToolsDisabled []string `mapstructure:"tools_disabled,omitempty" yaml:"tools_disabled,omitempty"`
+
+	// AllowedTools and DeniedTools filter every tool the agent gets,
+	// including MCP and skill tools, and carry over to sub-agents.
+	AllowedTools []string `mapstructure:"allowed_tools,omitempty" yaml:"allowed_tools,omitempty"`
+	DeniedTools  []string `mapstructure:"denied_tools,omitempty" yaml:"denied_tools,omitempty"`
+
+	// SystemPrompt is the inline prompt; SystemPromptFile is read instead when
+	// set (relative paths are resolved against the config directory).
+	SystemPrompt     string `mapstructure:"system_prompt,omitempty" yaml:"system_prompt,omitempty"`
+	SystemPromptFile string `mapstructure:"system_prompt_file,omitempty" yaml:"system_prompt_file,omitempty"`
+
+	Search   bool `mapstructure:"search,omitempty" yaml:"search,omitempty"`
+	MaxTurns int  `mapstructure:"max_turns,omitempty" yaml:"max_turns,omitempty"`
 }
 
 // AgentPreference allows overriding agent settings via config.yaml.
@@ -571,20 +628,80 @@ type SkillsConfig struct {
 
 // AgentsMdConfig configures optional AGENTS.md loading
 type AgentsMdConfig struct {
-	Enabled bool `mapstructure:"enabled"` // Load AGENTS.md into system prompt
+	Enabled  bool     `mapstructure:"enabled"`   // Inject project instruction files into the system prompt
+	Files    []string `mapstructure:"files"`     // Instruction file names, first match per directory wins
+	MaxChars int      `mapstructure:"max_chars"` // Cap on injected project context
 }
 
 // ToolsConfig configures the local tool system
 type ToolsConfig struct {
-	Enabled            []string `mapstructure:"enabled"`               // Enabled tool names (CLI names)
-	ReadDirs           []string `mapstructure:"read_dirs"`             // Directories for read operations
-	WriteDirs          []string `mapstructure:"write_dirs"`            // Directories for write operations
-	ShellAllow         []string `mapstructure:"shell_allow"`           // Shell command patterns
-	ShellAutoRun       bool     `mapstructure:"shell_auto_run"`        // Auto-approve matching shell
-	ShellAutoRunEnv    string   `mapstructure:"shell_auto_run_env"`    // Env var required for auto-run
-	ShellNonTTYEnv     string   `mapstructure:"shell_non_tty_env"`     // Env var for non-TTY execution
-	ImageProvider      string   `mapstructure:"image_provider"`        // Override for image provider
-	MaxToolOutputChars int      `mapstructure:"max_tool_output_chars"` // Global max chars per tool output (default 20000)
+	Enabled            []string          `mapstructure:"enabled"`               // Enabled tool names (CLI names)
+	ReadDirs           []string          `mapstructure:"read_dirs"`             // Directories for read operations
+	WriteDirs          []string          `mapstructure:"write_dirs"`            // Directories for write operations
+	ShellAllow         []string          `mapstructure:"shell_allow"`           // Shell command patterns
+	ShellAutoRun       bool              `mapstructure:"shell_auto_run"`        // Auto-approve matching shell
+	ShellAutoRunEnv    string            `mapstructure:"shell_auto_run_env"`    // Env var required for auto-run
+	ShellNonTTYEnv     string            `mapstructure:"shell_non_tty_env"`     // Env var for non-TTY execution
+	Shell              string            `mapstructure:"shell"`                 // Shell the shell tool runs commands with (default $SHELL, or cmd.exe on Windows)
+	ImageProvider      string            `mapstructure:"image_provider"`        // Override for image provider
+	MaxToolOutputChars int               `mapstructure:"max_tool_output_chars"` // Global max chars per tool output (default 20000)
+	ResultLimits       map[string]int    `mapstructure:"result_limits"`         // Per-tool max output chars keyed by tool name; "default" overrides max_tool_output_chars
+	MaxParallel        int               `mapstructure:"max_parallel"`          // Max tool calls from one turn run at once (default 4)
+	SerialTools        []string          `mapstructure:"serial_tools"`          // Tools that never run alongside other calls (default shell)
+	LoopThreshold      int               `mapstructure:"loop_threshold"`        // Identical tool-call turns in a row before the model is told to stop (default 3, 0 = off)
+	Timeout            string            `mapstructure:"timeout"`               // Go duration a tool call may run (default none)
+	Timeouts           map[string]string `mapstructure:"timeouts"`              // Per-tool overrides of timeout keyed by tool name
+	TurnTimeout        string            `mapstructure:"turn_timeout"`          // Go duration cap on all tool calls of one turn (default none)
+	WebFetch           WebFetchConfig    `mapstructure:"web_fetch"`             // web_fetch tool limits and domain policy
+}
+
+// WebFetchConfig configures the web_fetch tool. Domains match themselves and
+// their subdomains.
+type WebFetchConfig struct {
+	AllowedDomains []string `mapstructure:"allowed_domains"` // When set, only these domains may be fetched
+	DeniedDomains  []string `mapstructure:"denied_domains"`  // Never fetched, even if allowed
+	Timeout        string   `mapstructure:"timeout"`         // Go duration per fetch (default 30s)
+	MaxBytes       int      `mapstructure:"max_bytes"`       // Max response body bytes read (default 5 MiB)
+}
+
+// ToolTimeouts parses the tool timeout settings. Empty values mean no
+// timeout.
+func (t ToolsConfig) ToolTimeouts() (timeout time.Duration, perTool map[string]time.Duration, turn time.Duration, err error) {
+	parse := func(key, value string) (time.Duration, error) {
+		value = strings.TrimSpace(value)
+		if value == "" || value == "0" {
+			return 0, nil
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return 0, fmt.Errorf("invalid %s %q: want a duration like 60s or 5m", key, value)
+		}
+		return d, nil
+	}
+	if timeout, err = parse("tools.timeout", t.Timeout); err != nil {
+		return 0, nil, 0, err
+	}
+	if turn, err = parse("tools.turn_timeout", t.TurnTimeout); err != nil {
+		return 0, nil, 0, err
+	}
+	perTool = make(map[string]time.Duration, len(t.Timeouts))
+	for name, value := range t.Timeouts {
+		d, err := parse("tools.timeouts."+name, value)
+		if err != nil {
+			return 0, nil, 0, err
+		}
+		perTool[name] = d
+	}
+	return timeout, perTool, turn, nil
+}
+
+// DefaultResultLimit returns the output cap for tools without a
+// result_limits entry.
+func (t ToolsConfig) DefaultResultLimit() int {
+	if n := t.ResultLimits["default"]; n > 0 {
+		return n
+	}
+	return t.MaxToolOutputChars
 }
 
 // DiagnosticsConfig configures diagnostic data collection
@@ -595,17 +712,28 @@ type DiagnosticsConfig struct {
 
 // DebugLogsConfig configures debug logging of LLM requests and responses
 type DebugLogsConfig struct {
-	Enabled bool   `mapstructure:"enabled"` // Enable debug logging
-	Dir     string `mapstructure:"dir"`     // Override default directory (defaults to ~/.local/share/term-llm/debug/)
+	Enabled    bool   `mapstructure:"enabled"`      // Enable debug logging
+	Dir        string `mapstructure:"dir"`          // Override default directory (defaults to ~/.local/share/term-llm/debug/)
+	MaxFileMB  int    `mapstructure:"max_file_mb"`  // Start a new numbered part when a session file reaches this size (0=unlimited)
+	MaxTotalMB int    `mapstructure:"max_total_mb"` // Delete the oldest sessions at startup to keep the directory under this size (0=unlimited)
+}
+
+// RequestsConfig configures hooks that run around every LLM provider request.
+type RequestsConfig struct {
+	Headers  map[string]string `mapstructure:"headers"`   // Added to every provider HTTP request
+	AuditLog string            `mapstructure:"audit_log"` // Append each outgoing request to this file as a JSON line
 }
 
 // SessionsConfig configures session storage
 type SessionsConfig struct {
 	Enabled          bool   `mapstructure:"enabled"`            // Master switch - set to false to disable all session storage
-	MaxAgeDays       int    `mapstructure:"max_age_days"`       // Auto-delete sessions older than N days (0=never)
-	MaxCount         int    `mapstructure:"max_count"`          // Keep at most N sessions, delete oldest (0=unlimited)
+	MaxAgeDays       int    `mapstructure:"max_age_days"`       // Auto-archive sessions older than N days (0=never)
+	MaxCount         int    `mapstructure:"max_count"`          // Keep at most N unarchived sessions, archive oldest (0=unlimited)
+	PurgeGraceDays   int    `mapstructure:"purge_grace_days"`   // Days an auto-archived session is kept before deletion
+	HardDelete       bool   `mapstructure:"hard_delete"`        // Delete immediately instead of auto-archiving
 	Path             string `mapstructure:"path"`               // Optional SQLite DB path override (supports :memory:)
 	StripImageBase64 bool   `mapstructure:"strip_image_base64"` // Store path/metadata only for images with ImagePath (smaller DB, less portable)
+	AutoTitle        bool   `mapstructure:"auto_title"`         // Title new chat sessions in the background after the first reply
 }
 
 // FileTrackingConfig configures recording of file changes made by agent tools
@@ -617,6 +745,32 @@ type FileTrackingConfig struct {
 	Path            string `mapstructure:"path"`              // Optional SQLite DB path override
 }
 
+// QuotaConfig controls low-quota warnings for subscription providers
+// (copilot, chatgpt) that report plan quota.
+type QuotaConfig struct {
+	Warn          bool    `mapstructure:"warn"`           // Show a one-time warning when premium quota is low (default true)
+	WarnThreshold float64 `mapstructure:"warn_threshold"` // Percent remaining at or below which to warn (default 10)
+}
+
+// PricingConfig sets the price of a model for cost estimates, in USD per
+// million tokens. Model is "provider:model" to price one provider's model,
+// or a bare model name. CachedInput and CacheWrite default to Input.
+type PricingConfig struct {
+	Model       string  `mapstructure:"model"`
+	Input       float64 `mapstructure:"input"`
+	Output      float64 `mapstructure:"output"`
+	CachedInput float64 `mapstructure:"cached_input"`
+	CacheWrite  float64 `mapstructure:"cache_write"`
+}
+
+// TokenizerConfig selects the token encoding used to estimate context size
+// for a model. Model is a model name, or a prefix ending in "*". Encoding is
+// cl100k_base, o200k_base, or heuristic for the bytes-per-token estimate.
+type TokenizerConfig struct {
+	Model    string `mapstructure:"model"`
+	Encoding string `mapstructure:"encoding"`
+}
+
 // ThemeConfig allows customization of UI colors
 // Colors can be ANSI color numbers (0-255) or hex codes (#RRGGBB)
 type ThemeConfig struct {
@@ -631,6 +785,7 @@ type ThemeConfig struct {
 	ReasoningSummary string `mapstructure:"reasoning_summary"` // reasoning summary body
 	ReasoningHeader  string `mapstructure:"reasoning_header"`  // reasoning header lines
 	ReasoningRaw     string `mapstructure:"reasoning_raw"`     // raw reasoning body
+	Hyperlinks       string `mapstructure:"hyperlinks"`        // OSC 8 links for URLs/paths: auto, on, off
 }
 
 type ExecConfig struct {
@@ -658,17 +813,63 @@ type ChatConfig struct {
 	TerminalTitleFormat string `mapstructure:"terminal_title_format"`                        // Optional custom terminal title template
 	TerminalProgress    bool   `mapstructure:"terminal_progress"`                            // Enable terminal progress indicators (default false)
 	ApprovalMode        string `mapstructure:"approval_mode" yaml:"approval_mode,omitempty"` // Optional approval mode: prompt or auto
+	// ScreenModes remembers the last chat screen mode ("alt" or "inline")
+	// per terminal, keyed by ChatTerminalKey.
+	ScreenModes map[string]string `mapstructure:"screen_modes" yaml:"screen_modes,omitempty"`
+}
+
+// Chat screen modes stored in chat.screen_modes.
+const (
+	ChatScreenModeAlt    = "alt"
+	ChatScreenModeInline = "inline"
+)
+
+// ChatTerminalKey identifies the terminal chat is running in, for settings
+// remembered per terminal. It prefers TERM_PROGRAM over TERM and is reduced
+// to characters that are safe inside a config key path.
+func ChatTerminalKey(getenv func(string) string) string {
+	name := strings.TrimSpace(getenv("TERM_PROGRAM"))
+	if name == "" {
+		name = strings.TrimSpace(getenv("TERM"))
+	}
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	if b.Len() == 0 {
+		return "default"
+	}
+	return b.String()
+}
+
+// ScreenMode returns the screen mode remembered for terminal, or "" when none
+// has been saved.
+func (c ChatConfig) ScreenMode(terminal string) string {
+	switch mode := strings.ToLower(strings.TrimSpace(c.ScreenModes[terminal])); mode {
+	case ChatScreenModeAlt, ChatScreenModeInline:
+		return mode
+	default:
+		return ""
+	}
 }
 
 type EditConfig struct {
-	Provider        string `mapstructure:"provider"`                                     // Override provider for edit
-	Model           string `mapstructure:"model"`                                        // Override model for edit
-	Instructions    string `mapstructure:"instructions"`                                 // Custom instructions for edits
-	ShowLineNumbers bool   `mapstructure:"show_line_numbers"`                            // Show line numbers in diff
-	ContextLines    int    `mapstructure:"context_lines"`                                // Lines of context in diff
-	Editor          string `mapstructure:"editor"`                                       // Override $EDITOR
-	DiffFormat      string `mapstructure:"diff_format"`                                  // "auto", "udiff", or "replace" (default: auto)
-	ApprovalMode    string `mapstructure:"approval_mode" yaml:"approval_mode,omitempty"` // Optional approval mode: prompt or auto
+	Provider        string  `mapstructure:"provider"`                                     // Override provider for edit
+	Model           string  `mapstructure:"model"`                                        // Override model for edit
+	Instructions    string  `mapstructure:"instructions"`                                 // Custom instructions for edits
+	ShowLineNumbers bool    `mapstructure:"show_line_numbers"`                            // Show line numbers in diff
+	ContextLines    int     `mapstructure:"context_lines"`                                // Lines of context in diff
+	Editor          string  `mapstructure:"editor"`                                       // Override $EDITOR
+	DiffFormat      string  `mapstructure:"diff_format"`                                  // "auto", "udiff", or "replace" (default: auto)
+	FuzzyMatch      string  `mapstructure:"fuzzy_match"`                                  // Loosest udiff match: "exact", "whitespace", or "similar" (default: similar)
+	FuzzyThreshold  float64 `mapstructure:"fuzzy_threshold"`                              // Average line similarity for "similar" matches (default: 0.8)
+	BackupRetention int     `mapstructure:"backup_retention"`                             // Edit runs kept for "edit undo"; 0 disables backups (default: 20)
+	ApprovalMode    string  `mapstructure:"approval_mode" yaml:"approval_mode,omitempty"` // Optional approval mode: prompt or auto
 }
 
 type LoopConfig struct {
@@ -721,6 +922,15 @@ type ImageConfig struct {
 	Flux       ImageFluxConfig       `mapstructure:"flux"`
 	OpenRouter ImageOpenRouterConfig `mapstructure:"openrouter"`
 	Debug      ImageDebugConfig      `mapstructure:"debug"`
+
+	Attachments ImageAttachmentsConfig `mapstructure:"attachments"`
+}
+
+// ImageAttachmentsConfig controls how images attached in ask and chat, and
+// images loaded by view_image, are shrunk before they are sent to a model.
+type ImageAttachmentsConfig struct {
+	MaxDimension int `mapstructure:"max_dimension"` // longest edge in pixels
+	Quality      int `mapstructure:"quality"`       // JPEG quality, 1-100
 }
 
 // ImageGeminiConfig configures Gemini image generation
@@ -978,6 +1188,12 @@ func Load() (*Config, error) {
 	if cfg.Providers == nil {
 		cfg.Providers = make(map[string]ProviderConfig)
 	}
+	for name, pc := range cfg.Providers {
+		if alias, ok := providerTypeAliases[pc.Type]; ok {
+			pc.Type = alias
+			cfg.Providers[name] = pc
+		}
+	}
 
 	if err := cfg.ResolveProviderCredentials(cfg.DefaultProvider); err != nil {
 		return nil, fmt.Errorf("%s credentials: %w", cfg.DefaultProvider, err)
@@ -1107,6 +1323,7 @@ func providerModelConfigFromMap(m map[string]any) ProviderModelConfig {
 		ThinkingParam:    stringFromMap(m, "thinking_param"),
 		ReasoningEfforts: stringSliceFromMap(m, "reasoning_efforts"),
 		VisionVia:        stringFromMap(m, "vision_via"),
+		Fallbacks:        stringSliceFromMap(m, "fallbacks"),
 	}
 }
 
@@ -1175,6 +1392,23 @@ func VisionViaForProviderModel(cfg *Config, providerName, modelName string) stri
 	return strings.TrimSpace(pc.VisionVia)
 }
 
+// FallbacksForProviderModel returns the provider:model entries to try, in
+// order, when a model fails. A per-model models[].fallbacks list overrides
+// providers.<name>.fallbacks.
+func FallbacksForProviderModel(cfg *Config, providerName, modelName string) []string {
+	if entry, ok := ModelConfigForProviderModel(cfg, providerName, modelName); ok && len(entry.Fallbacks) > 0 {
+		return entry.Fallbacks
+	}
+	if cfg == nil {
+		return nil
+	}
+	pc, ok := cfg.Providers[strings.TrimSpace(providerName)]
+	if !ok {
+		return nil
+	}
+	return pc.Fallbacks
+}
+
 func providerModelConfigMatches(entry ProviderModelConfig, modelName string) bool {
 	modelName = strings.TrimSpace(modelName)
 	if modelName == "" {
@@ -2211,17 +2445,11 @@ func NormalizeVeniceAPIKey(apiKey string) string {
 	return apiKey
 }
 
-// GetConfigDir returns the XDG config directory for term-llm.
-// Uses $XDG_CONFIG_HOME if set, otherwise ~/.config
+// GetConfigDir returns the config directory for term-llm. See
+// pathutil.ConfigDir for how TERM_LLM_CONFIG_DIR, XDG_CONFIG_HOME and
+// %APPDATA% are honoured.
 func GetConfigDir() (string, error) {
-	if xdgHome := os.Getenv("XDG_CONFIG_HOME"); xdgHome != "" {
-		return filepath.Join(xdgHome, "term-llm"), nil
-	}
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(homeDir, ".config", "term-llm"), nil
+	return pathutil.ConfigDir()
 }
 
 // GetConfigPath returns the path where the config file should be located
@@ -2275,9 +2503,23 @@ var KnownAgentPreferenceKeys = map[string]bool{
 	"search":               true,
 }
 
+// KnownAgentPresetKeys contains valid keys for agents defined in config.yaml
+var KnownAgentPresetKeys = map[string]bool{
+	"description":        true,
+	"provider":           true,
+	"model":              true,
+	"tools_enabled":      true,
+	"tools_disabled":     true,
+	"system_prompt":      true,
+	"system_prompt_file": true,
+	"search":             true,
+	"max_turns":          true,
+}
+
 // IsKnownKey checks if a key path is a known configuration key
 // For provider keys (providers.*), validates the sub-keys
 // For agent preference keys (agents.preferences.*), validates the sub-keys
+// For agent preset keys (agents.presets.*), validates the sub-keys
 func IsKnownKey(keyPath string) bool {
 	// Check direct match
 	if KnownKeys[keyPath] {
@@ -2320,6 +2562,39 @@ func IsKnownKey(keyPath string) bool {
 		}
 	}
 
+	// Check for agents.presets.* pattern
+	if strings.HasPrefix(keyPath, "agents.presets.") {
+		parts := strings.SplitN(keyPath, ".", 4)
+		if len(parts) == 3 {
+			// agents.presets.<agent-name> is always valid
+			return true
+		}
+		if len(parts) == 4 {
+			// agents.presets.<agent-name>.<key> - check if <key> is valid
+			return KnownAgentPresetKeys[parts[3]]
+		}
+	}
+
+	// requests.headers.<name> - arbitrary header names
+	if strings.HasPrefix(keyPath, "requests.headers.") {
+		return len(strings.Split(keyPath, ".")) == 3
+	}
+
+	// chat.screen_modes.<terminal> - arbitrary terminal names
+	if strings.HasPrefix(keyPath, "chat.screen_modes.") {
+		return len(strings.Split(keyPath, ".")) == 3
+	}
+
+	// tools.result_limits.<tool> and tools.timeouts.<tool> - arbitrary tool names
+	if strings.HasPrefix(keyPath, "tools.result_limits.") || strings.HasPrefix(keyPath, "tools.timeouts.") {
+		return len(strings.Split(keyPath, ".")) == 3
+	}
+
+	// prompts.<name> - arbitrary template names
+	if strings.HasPrefix(keyPath, "prompts.") {
+		return len(strings.Split(keyPath, ".")) == 2
+	}
+
 	return false
 }
 
@@ -2573,6 +2848,26 @@ func SetServeTelegramConfig(c TelegramServeConfig) error {
 	return writeConfigPreservingEnvCase(v)
 }
 
+// SetChatScreenMode remembers the chat screen mode for terminal in
+// chat.screen_modes. The file is edited in place so comments survive.
+func SetChatScreenMode(terminal, mode string) error {
+	if mode != ChatScreenModeAlt && mode != ChatScreenModeInline {
+		return fmt.Errorf("invalid chat screen mode %q", mode)
+	}
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return err
+	}
+	root, err := ReadConfigDocument(configPath)
+	if err != nil {
+		return err
+	}
+	if err := SetYAMLValue(root, []string{"chat", "screen_modes", terminal}, mode); err != nil {
+		return err
+	}
+	return WriteConfigDocument(configPath, root)
+}
+
 // SetServeWebPushConfig saves Web Push VAPID configuration using viper.
 func SetServeWebPushConfig(c WebPushConfig) error {
 	configPath, err := GetConfigPath()
@@ -7,6 +7,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
@@ -435,6 +436,7 @@ func TestInferProviderType(t *testing.T) {
 		{"groq", "", ProviderTypeOpenAICompat},
 		{"custom", ProviderTypeOpenAICompat, ProviderTypeOpenAICompat},
 		{"anthropic", ProviderTypeOpenAICompat, ProviderTypeOpenAICompat}, // explicit overrides
+		{"local", "openai-compatible", ProviderTypeOpenAICompat},
 	}
 
 	for _, tc := range tests {
@@ -618,6 +620,22 @@ func TestFileTrackingDefaultsAndKnownKeys(t *testing.T) {
 	}
 }
 
+func TestQuotaDefaultsAndKnownKeys(t *testing.T) {
+	defaults := GetDefaults()
+	checks := map[string]any{
+		"quota.warn":           true,
+		"quota.warn_threshold": 10.0,
+	}
+	for key, want := range checks {
+		if got := defaults[key]; got != want {
+			t.Fatalf("default %s = %#v, want %#v", key, got, want)
+		}
+		if !KnownKeys[key] {
+			t.Fatalf("KnownKeys missing %s", key)
+		}
+	}
+}
+
 func TestEveryDefaultIsKnownKey(t *testing.T) {
 	for key := range GetDefaults() {
 		if !IsKnownKey(key) {
@@ -664,8 +682,18 @@ func TestCanonicalDefaultsCoverage(t *testing.T) {
 		"transcription.timestamps":      false,
 		"serve.base_path":               DefaultServeBasePath,
 		"serve.response_timeout":        DefaultServeResponseTimeout,
+		"serve.drain_timeout":           DefaultServeDrainTimeout,
+		"serve.replay_max_events":       DefaultServeReplayMaxEvents,
+		"serve.replay_max_bytes":        DefaultServeReplayMaxBytes,
 		"sessions.strip_image_base64":   false,
+		"debug_logs.max_file_mb":        DefaultDebugLogsMaxFileMB,
+		"debug_logs.max_total_mb":       DefaultDebugLogsMaxTotalMB,
+		"sessions.purge_grace_days":     DefaultSessionsPurgeGraceDays,
+		"sessions.hard_delete":          false,
+		"sessions.auto_title":           DefaultSessionsAutoTitle,
 		"tools.max_tool_output_chars":   DefaultToolsMaxToolOutputChars,
+		"tools.max_parallel":            DefaultToolsMaxParallel,
+		"tools.loop_threshold":          DefaultToolsLoopThreshold,
 		"skills.metadata_budget_tokens": DefaultSkillsMetadataBudgetTokens,
 	}
 	for key, want := range checks {
@@ -695,6 +723,8 @@ func TestCanonicalDefaultsCoverage(t *testing.T) {
 		"providers.example.region",
 		"providers.example.use_websocket",
 		"providers.example.vision_via",
+		"prompts",
+		"prompts.review",
 	}
 	for _, key := range optionalKnown {
 		if !IsKnownKey(key) {
@@ -1122,6 +1152,7 @@ providers:
     api_key: test-key
     model: friendly-name
     vision_via: openai:gpt-4.1
+    fallbacks: [anthropic:claude-sonnet-4-6]
     models:
       - id: upstream/model-id
         alias: friendly-name
@@ -1132,6 +1163,7 @@ providers:
         thinking_param: enable_thinking
         reasoning_efforts: [high, max]
         vision_via: gemini:gemini-2.5-pro
+        fallbacks: [openai:gpt-5, gemini]
       - another-upstream-model
 `
 	if err := os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(configYAML), 0o600); err != nil {
@@ -1174,6 +1206,12 @@ providers:
 	if got := VisionViaForProviderModel(cfg, "custom", "another-upstream-model"); got != "openai:gpt-4.1" {
 		t.Fatalf("VisionViaForProviderModel provider fallback = %q", got)
 	}
+	if got := FallbacksForProviderModel(cfg, "custom", "friendly-name"); len(got) != 2 || got[0] != "openai:gpt-5" || got[1] != "gemini" {
+		t.Fatalf("FallbacksForProviderModel model override = %#v", got)
+	}
+	if got := FallbacksForProviderModel(cfg, "custom", "another-upstream-model"); len(got) != 1 || got[0] != "anthropic:claude-sonnet-4-6" {
+		t.Fatalf("FallbacksForProviderModel provider default = %#v", got)
+	}
 	if got := DisplayModelForProviderModel(cfg, "custom", "upstream/model-id"); got != "friendly-name" {
 		t.Fatalf("DisplayModelForProviderModel upstream id = %q", got)
 	}
@@ -1320,3 +1358,143 @@ func TestApprovalModesAreOptionalAndValidated(t *testing.T) {
 		t.Fatalf("invalid config error = %v", err)
 	}
 }
+
+func TestLoad_ToolResultLimits(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	configDir := filepath.Join(configHome, "term-llm")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatalf("mkdir config dir: %v", err)
+	}
+
+	configYAML := `default_provider: openai
+providers:
+  openai:
+    model: gpt-5.2
+tools:
+  result_limits:
+    shell: 20000
+    read_file: 60000
+`
+	if err := os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(configYAML), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := cfg.Tools.ResultLimits["shell"]; got != 20000 {
+		t.Fatalf("result_limits.shell = %d, want 20000", got)
+	}
+	if got := cfg.Tools.ResultLimits["read_file"]; got != 60000 {
+		t.Fatalf("result_limits.read_file = %d, want 60000", got)
+	}
+	if got := cfg.Tools.DefaultResultLimit(); got != DefaultToolsMaxToolOutputChars {
+		t.Fatalf("DefaultResultLimit() = %d, want max_tool_output_chars default %d", got, DefaultToolsMaxToolOutputChars)
+	}
+	if !IsKnownKey("tools.result_limits.shell") {
+		t.Fatal("tools.result_limits.shell should be a known key")
+	}
+}
+
+func TestToolsConfigDefaultResultLimit(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  ToolsConfig
+		want int
+	}{
+		{name: "global limit", cfg: ToolsConfig{MaxToolOutputChars: 20000}, want: 20000},
+		{name: "default entry overrides", cfg: ToolsConfig{MaxToolOutputChars: 20000, ResultLimits: map[string]int{"default": 5000}}, want: 5000},
+		{name: "non-positive default ignored", cfg: ToolsConfig{MaxToolOutputChars: 20000, ResultLimits: map[string]int{"default": 0}}, want: 20000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.DefaultResultLimit(); got != tt.want {
+				t.Fatalf("DefaultResultLimit() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToolsConfigToolTimeouts(t *testing.T) {
+	cfg := ToolsConfig{Timeout: "60s", Timeouts: map[string]string{"shell": "5m", "read_url": "0"}, TurnTimeout: ""}
+	timeout, perTool, turn, err := cfg.ToolTimeouts()
+	if err != nil {
+		t.Fatalf("ToolTimeouts: %v", err)
+	}
+	if timeout != time.Minute || perTool["shell"] != 5*time.Minute || perTool["read_url"] != 0 || turn != 0 {
+		t.Fatalf("ToolTimeouts = %v, %v, %v", timeout, perTool, turn)
+	}
+	if _, ok := perTool["read_url"]; !ok {
+		t.Fatal("an explicit 0 should disable the timeout for that tool")
+	}
+
+	if _, _, _, err := (ToolsConfig{Timeout: "soon"}).ToolTimeouts(); err == nil {
+		t.Fatal("expected an error for an invalid duration")
+	}
+	if !IsKnownKey("tools.timeouts.shell") {
+		t.Fatal("tools.timeouts.shell should be a known key")
+	}
+}
+
+func TestChatScreenModePerTerminal(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(key string) string { return vars[key] }
+	}
+	if got := ChatTerminalKey(env(map[string]string{"TERM_PROGRAM": "iTerm.app", "TERM": "xterm"})); got != "iterm_app" {
+		t.Fatalf("ChatTerminalKey(TERM_PROGRAM) = %q, want iterm_app", got)
+	}
+	if got := ChatTerminalKey(env(map[string]string{"TERM": "xterm-256color"})); got != "xterm-256color" {
+		t.Fatalf("ChatTerminalKey(TERM) = %q, want xterm-256color", got)
+	}
+	if got := ChatTerminalKey(env(nil)); got != "default" {
+		t.Fatalf("ChatTerminalKey() = %q, want default", got)
+	}
+
+	chat := ChatConfig{ScreenModes: map[string]string{"ghostty": "Inline", "xterm": "sideways"}}
+	if got := chat.ScreenMode("ghostty"); got != ChatScreenModeInline {
+		t.Fatalf("ScreenMode(ghostty) = %q, want inline", got)
+	}
+	if got := chat.ScreenMode("xterm"); got != "" {
+		t.Fatalf("ScreenMode(xterm) = %q, want empty for an invalid value", got)
+	}
+	if !IsKnownKey("chat.screen_modes.ghostty") {
+		t.Fatal("chat.screen_modes.<terminal> should be a known key")
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := SetChatScreenMode("ghostty", "sideways"); err == nil {
+		t.Fatal("expected an invalid screen mode to be rejected")
+	}
+	configPath, err := GetConfigPath()
+	if err != nil {
+		t.Fatalf("GetConfigPath: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(configPath, []byte("# my provider\ndefault_provider: anthropic\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetChatScreenMode("ghostty", ChatScreenModeInline); err != nil {
+		t.Fatalf("SetChatScreenMode: %v", err)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "# my provider") {
+		t.Fatalf("saving the screen mode dropped config comments:\n%s", data)
+	}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := cfg.Chat.ScreenMode("ghostty"); got != ChatScreenModeInline {
+		t.Fatalf("saved ScreenMode(ghostty) = %q, want inline", got)
+	}
+}
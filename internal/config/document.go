@@ -0,0 +1,167 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReadConfigDocument parses the config file into a YAML node tree so edits
+// keep comments and key order. A missing or empty file yields an empty
+// document.
+func ReadConfigDocument(configPath string) (*yaml.Node, error) {
+	var root yaml.Node
+	data, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(data, &root); err != nil {
+			return nil, fmt.Errorf("failed to parse config: %w", err)
+		}
+	}
+	if root.Kind == 0 {
+		// Create new document with empty mapping
+		root = yaml.Node{
+			Kind: yaml.DocumentNode,
+			Content: []*yaml.Node{{
+				Kind: yaml.MappingNode,
+			}},
+		}
+	}
+	return &root, nil
+}
+
+// WriteConfigDocument encodes root and atomically replaces the config file,
+// creating its directory if needed.
+func WriteConfigDocument(configPath string, root *yaml.Node) error {
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	encoder.Close()
+
+	if err := WriteFileAtomically(configPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// SetYAMLValue sets path to a plain scalar, creating intermediate mappings.
+func SetYAMLValue(root *yaml.Node, path []string, value string) error {
+	return SetYAMLScalar(root, path, value, "")
+}
+
+// SetYAMLScalar sets path to a scalar with the given tag; an empty tag lets
+// YAML infer the type. Comments on existing keys are kept.
+func SetYAMLScalar(root *yaml.Node, path []string, value, tag string) error {
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
+		return fmt.Errorf("invalid document structure")
+	}
+
+	current := root.Content[0]
+	if current.Kind != yaml.MappingNode {
+		return fmt.Errorf("root is not a mapping")
+	}
+
+	for i, part := range path {
+		isLast := i == len(path)-1
+
+		// Find or create the key
+		found := false
+		for j := 0; j < len(current.Content); j += 2 {
+			keyNode := current.Content[j]
+			if keyNode.Value == part {
+				if isLast {
+					// Set the value
+					valueNode := current.Content[j+1]
+					valueNode.Kind = yaml.ScalarNode
+					valueNode.Value = value
+					valueNode.Tag = tag
+					valueNode.Style = 0
+					valueNode.Content = nil
+				} else {
+					// Navigate deeper
+					current = current.Content[j+1]
+					if current.Kind != yaml.MappingNode {
+						// Convert to mapping if needed
+						current.Kind = yaml.MappingNode
+						current.Content = nil
+						current.Value = ""
+						current.Tag = ""
+						current.Style = 0
+					}
+				}
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			// Create the key
+			keyNode := &yaml.Node{
+				Kind:  yaml.ScalarNode,
+				Value: part,
+			}
+
+			if isLast {
+				// Create scalar value
+				valueNode := &yaml.Node{
+					Kind:  yaml.ScalarNode,
+					Value: value,
+					Tag:   tag,
+				}
+				current.Content = append(current.Content, keyNode, valueNode)
+			} else {
+				// Create mapping for intermediate path
+				newMapping := &yaml.Node{
+					Kind: yaml.MappingNode,
+				}
+				current.Content = append(current.Content, keyNode, newMapping)
+				current = newMapping
+			}
+		}
+	}
+
+	return nil
+}
+
+// UnsetYAMLValue removes path from the document and prunes mappings left
+// empty by the removal. It reports whether the key existed.
+func UnsetYAMLValue(root *yaml.Node, path []string) bool {
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 || len(path) == 0 {
+		return false
+	}
+	var remove func(node *yaml.Node, path []string) bool
+	remove = func(node *yaml.Node, path []string) bool {
+		if node.Kind != yaml.MappingNode {
+			return false
+		}
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if node.Content[j].Value != path[0] {
+				continue
+			}
+			child := node.Content[j+1]
+			if len(path) > 1 {
+				if !remove(child, path[1:]) {
+					return false
+				}
+				if len(child.Content) > 0 {
+					return true
+				}
+			}
+			node.Content = append(node.Content[:j], node.Content[j+2:]...)
+			return true
+		}
+		return false
+	}
+	return remove(root.Content[0], path)
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"time"
 
@@ -182,18 +183,34 @@ func lookupJSONFragment(v any, fragment string) (string, error) {
 
 // resolveCommand executes a shell command and returns its output
 func resolveCommand(cmd string) (string, error) {
-	output, err := runResolverCommand("sh", "-c", cmd)
+	output, err := runResolver(func(ctx context.Context) *exec.Cmd {
+		return procutil.ShellCommand(ctx, resolverShell(), cmd)
+	})
 	if err != nil {
 		return "", fmt.Errorf("command failed: %w", err)
 	}
 	return output, nil
 }
 
+// resolverShell runs $(...) commands: sh, or on Windows $SHELL or cmd.exe.
+func resolverShell() string {
+	if runtime.GOOS == "windows" {
+		return procutil.DefaultShell("")
+	}
+	return "sh"
+}
+
 func runResolverCommand(name string, args ...string) (string, error) {
+	return runResolver(func(ctx context.Context) *exec.Cmd {
+		return exec.CommandContext(ctx, name, args...)
+	})
+}
+
+func runResolver(newCmd func(context.Context) *exec.Cmd) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), resolveExecTimeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, name, args...)
+	cmd := newCmd(ctx)
 	cmd.WaitDelay = resolveExecWaitDelay
 
 	cleanup, prepErr := procutil.PrepareCommand(cmd)
@@ -57,6 +57,9 @@ const (
 	DefaultChatTerminalTitle     = "smart"
 	DefaultEditContextLines      = 3
 	DefaultEditDiffFormat        = "auto"
+	DefaultEditFuzzyMatch        = "similar"
+	DefaultEditFuzzyThreshold    = 0.8
+	DefaultEditBackupRetention   = 20
 
 	DefaultImageProvider         = "gemini"
 	DefaultImageOutputDir        = "~/Pictures/term-llm"
@@ -71,6 +74,9 @@ const (
 	DefaultImageOpenRouterModel  = "google/gemini-2.5-flash-image"
 	DefaultImageDebugDelay       = 0.0
 
+	DefaultImageAttachmentMaxDimension = 1568
+	DefaultImageAttachmentQuality      = 85
+
 	DefaultAudioProvider         = "venice"
 	DefaultAudioOutputDir        = "~/Music/term-llm"
 	DefaultAudioVeniceModel      = "tts-kokoro"
@@ -118,11 +124,20 @@ const (
 	DefaultToolsShellAutoRunEnv    = "TERM_LLM_ALLOW_AUTORUN"
 	DefaultToolsShellNonTTYEnv     = "TERM_LLM_ALLOW_NON_TTY"
 	DefaultToolsMaxToolOutputChars = 20000
+	DefaultToolsMaxParallel        = 4
+	DefaultToolsLoopThreshold      = 3
+	DefaultWebFetchTimeout         = "30s"
+	DefaultWebFetchMaxBytes        = 5 * 1024 * 1024
 
 	DefaultSessionsEnabled          = true
 	DefaultSessionsMaxAgeDays       = 0
 	DefaultSessionsMaxCount         = 0
+	DefaultSessionsPurgeGraceDays   = 14
 	DefaultSessionsStripImageBase64 = false
+	DefaultSessionsAutoTitle        = true
+
+	DefaultDebugLogsMaxFileMB  = 50
+	DefaultDebugLogsMaxTotalMB = 1024
 
 	DefaultFileTrackingMaxFileBytes    = 2 * 1024 * 1024
 	DefaultFileTrackingMaxSessionBytes = 100 * 1024 * 1024
@@ -131,10 +146,17 @@ const (
 	DefaultSkillsMetadataBudgetTokens = 8000
 	DefaultSkillsMaxVisibleSkills     = 50
 
+	DefaultAgentsMdMaxChars = 32000
+
 	DefaultServeBasePath        = "/ui"
 	DefaultServeResponseTimeout = "30m"
+	DefaultServeDrainTimeout    = "30s"
+	DefaultServeReplayMaxEvents = 2048
+	DefaultServeReplayMaxBytes  = 8 * 1024 * 1024
 
 	DefaultAutoCompact = true
+
+	DefaultQuotaWarnThreshold = 10.0
 )
 
 var keySpecs = []KeySpec{
@@ -168,6 +190,7 @@ var keySpecs = []KeySpec{
 	def("chat.terminal_title", DefaultChatTerminalTitle),
 	def("chat.terminal_title_format", ""),
 	def("chat.terminal_progress", false),
+	optional("chat.screen_modes", withPlaceholder(map[string]any{}), withoutResetTemplate()),
 
 	optional("edit.provider"),
 	optional("edit.model"),
@@ -177,6 +200,9 @@ var keySpecs = []KeySpec{
 	def("edit.context_lines", DefaultEditContextLines),
 	optional("edit.editor"),
 	def("edit.diff_format", DefaultEditDiffFormat),
+	def("edit.fuzzy_match", DefaultEditFuzzyMatch),
+	def("edit.fuzzy_threshold", DefaultEditFuzzyThreshold),
+	def("edit.backup_retention", DefaultEditBackupRetention),
 
 	def("image.provider", DefaultImageProvider),
 	def("image.output_dir", DefaultImageOutputDir),
@@ -197,6 +223,8 @@ var keySpecs = []KeySpec{
 	optional("image.openrouter.api_key", sensitive()),
 	def("image.openrouter.model", DefaultImageOpenRouterModel),
 	def("image.debug.delay", DefaultImageDebugDelay),
+	def("image.attachments.max_dimension", DefaultImageAttachmentMaxDimension),
+	def("image.attachments.quality", DefaultImageAttachmentQuality),
 
 	def("audio.provider", DefaultAudioProvider),
 	def("audio.output_dir", DefaultAudioOutputDir),
@@ -298,6 +326,7 @@ var keySpecs = []KeySpec{
 	optional("theme.reasoning_summary"),
 	optional("theme.reasoning_header"),
 	optional("theme.reasoning_raw"),
+	def("theme.hyperlinks", "auto"),
 
 	def("tools.enabled", []string{}),
 	def("tools.read_dirs", []string{}),
@@ -306,12 +335,28 @@ var keySpecs = []KeySpec{
 	def("tools.shell_auto_run", false),
 	def("tools.shell_auto_run_env", DefaultToolsShellAutoRunEnv),
 	def("tools.shell_non_tty_env", DefaultToolsShellNonTTYEnv),
+	optional("tools.shell"),
 	optional("tools.image_provider"),
 	def("tools.max_tool_output_chars", DefaultToolsMaxToolOutputChars),
+	optional("tools.result_limits", withPlaceholder(map[string]any{})),
+	def("tools.max_parallel", DefaultToolsMaxParallel),
+	def("tools.serial_tools", []string{"shell"}),
+	def("tools.loop_threshold", DefaultToolsLoopThreshold),
+	optional("tools.timeout"),
+	optional("tools.timeouts", withPlaceholder(map[string]any{})),
+	optional("tools.turn_timeout"),
+	def("tools.web_fetch.allowed_domains", []string{}),
+	def("tools.web_fetch.denied_domains", []string{}),
+	def("tools.web_fetch.timeout", DefaultWebFetchTimeout),
+	def("tools.web_fetch.max_bytes", DefaultWebFetchMaxBytes),
 
 	def("agents.use_builtin", true),
 	def("agents.search_paths", []string{}),
 	optional("agents.preferences", withPlaceholder(map[string]any{})),
+	optional("agents.presets", withPlaceholder(map[string]any{})),
+
+	optional("pricing", withPlaceholder([]any{})),
+	optional("tokenizers", withPlaceholder([]any{})),
 
 	def("skills.enabled", true),
 	def("skills.auto_invoke", true),
@@ -323,17 +368,24 @@ var keySpecs = []KeySpec{
 	def("skills.never_auto", []string{}),
 
 	def("agents_md.enabled", true),
+	def("agents_md.files", []string{"AGENTS.md"}),
+	def("agents_md.max_chars", DefaultAgentsMdMaxChars),
 
 	def("sessions.enabled", DefaultSessionsEnabled),
 	def("sessions.max_age_days", DefaultSessionsMaxAgeDays),
 	def("sessions.max_count", DefaultSessionsMaxCount),
+	def("sessions.purge_grace_days", DefaultSessionsPurgeGraceDays),
+	def("sessions.hard_delete", false),
 	def("sessions.path", ""),
 	def("sessions.strip_image_base64", DefaultSessionsStripImageBase64),
+	def("sessions.auto_title", DefaultSessionsAutoTitle),
 
 	def("diagnostics.enabled", false),
 	def("diagnostics.dir", ""),
 	def("debug_logs.enabled", false),
 	def("debug_logs.dir", ""),
+	def("debug_logs.max_file_mb", DefaultDebugLogsMaxFileMB),
+	def("debug_logs.max_total_mb", DefaultDebugLogsMaxTotalMB),
 
 	optional("loop.approval_mode", withoutResetTemplate()),
 
@@ -342,9 +394,17 @@ var keySpecs = []KeySpec{
 	optional("serve.approval_mode", withoutResetTemplate()),
 	optional("serve.title"),
 	def("serve.disable_location_sharing", false),
+	def("serve.disable_metrics", false),
+	def("serve.public_metrics", false),
 	optional("serve.files_dir"),
 	optional("serve.widgets_dir"),
 	def("serve.response_timeout", DefaultServeResponseTimeout),
+	def("serve.drain_timeout", DefaultServeDrainTimeout),
+	def("serve.replay_max_events", DefaultServeReplayMaxEvents),
+	def("serve.replay_max_bytes", DefaultServeReplayMaxBytes),
+	optional("serve.max_concurrent_runs", withPlaceholder(16)),
+	optional("serve.session_messages_per_minute", withPlaceholder(30)),
+	optional("serve.new_sessions_per_minute", withPlaceholder(30)),
 	optional("serve.telegram.token", sensitive()),
 	optional("serve.telegram.allowed_user_ids", withPlaceholder([]int64{})),
 	optional("serve.telegram.allowed_usernames", withPlaceholder([]string{})),
@@ -360,6 +420,12 @@ var keySpecs = []KeySpec{
 	def("file_tracking.max_session_bytes", DefaultFileTrackingMaxSessionBytes),
 	def("file_tracking.max_total_bytes", int(DefaultFileTrackingMaxTotalBytes)),
 	def("file_tracking.path", ""),
+
+	def("quota.warn", true),
+	def("quota.warn_threshold", DefaultQuotaWarnThreshold),
+
+	optional("requests.headers", withPlaceholder(map[string]any{}), withoutResetTemplate()),
+	optional("requests.audit_log"),
 }
 
 var providerFieldSpecs = []ProviderFieldSpec{
@@ -375,6 +441,7 @@ var providerFieldSpecs = []ProviderFieldSpec{
 	{Path: "env", Placeholder: map[string]any{}},
 	{Path: "enable_hooks", Placeholder: false},
 	{Path: "use_websocket", Placeholder: false},
+	{Path: "encrypted_reasoning", Placeholder: false},
 	{Path: "responses.reasoning_mode"},
 	{Path: "responses.reasoning_context"},
 	{Path: "responses.multi_agent.enabled", Placeholder: false},
@@ -388,12 +455,15 @@ var providerFieldSpecs = []ProviderFieldSpec{
 	{Path: "file_upload.text_embed_mime_types", Placeholder: []string{}},
 	{Path: "file_upload.max_text_embed_bytes", Placeholder: 0},
 	{Path: "vision_via"},
+	{Path: "fallbacks", Placeholder: []string{}},
 	{Path: "use_native_search", Placeholder: false},
 	{Path: "context_window", Placeholder: 0},
 	{Path: "max_output_tokens", Placeholder: 0},
 	{Path: "base_url"},
 	{Path: "url"},
 	{Path: "no_stream_options", Placeholder: false},
+	{Path: "tool_calls", Placeholder: false},
+	{Path: "structured_output", Placeholder: false},
 	{Path: "vllm_thinking_param"},
 	{Path: "parse_reasoning", Placeholder: false},
 	{Path: "include_reasoning", Placeholder: false},
@@ -674,6 +744,7 @@ func buildKnownKeys() map[string]bool {
 		addPathAndParents(known, spec.Path)
 	}
 	known["providers"] = true
+	known["prompts"] = true
 	return known
 }
 
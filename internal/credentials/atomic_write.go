@@ -40,6 +40,9 @@ func writeFileAtomic(path string, data []byte, perm os.FileMode) (err error) {
 	if err = tmp.Close(); err != nil {
 		return fmt.Errorf("close temporary file: %w", err)
 	}
+	if err = restrictToOwner(tmpPath); err != nil {
+		return fmt.Errorf("restrict temporary file access: %w", err)
+	}
 	if err = os.Rename(tmpPath, path); err != nil {
 		return fmt.Errorf("replace file: %w", err)
 	}
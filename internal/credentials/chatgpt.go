@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/samsaffron/term-llm/internal/oauth"
+	"github.com/samsaffron/term-llm/internal/pathutil"
 )
 
 var (
@@ -32,15 +33,11 @@ func (c *ChatGPTCredentials) IsExpired() bool {
 
 // getChatGPTCredentialsPath returns the path to the ChatGPT credentials file
 func getChatGPTCredentialsPath() (string, error) {
-	configDir := os.Getenv("XDG_CONFIG_HOME")
-	if configDir == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return "", fmt.Errorf("failed to get home directory: %w", err)
-		}
-		configDir = filepath.Join(home, ".config")
+	configDir, err := pathutil.ConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
 	}
-	return filepath.Join(configDir, "term-llm", "chatgpt_oauth.json"), nil
+	return filepath.Join(configDir, "chatgpt_oauth.json"), nil
 }
 
 // GetChatGPTCredentials retrieves the ChatGPT OAuth credentials from storage.
@@ -6,12 +6,17 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/samsaffron/term-llm/internal/pathutil"
 )
 
 // CopilotCredentials holds the OAuth token for GitHub Copilot
 type CopilotCredentials struct {
 	AccessToken string `json:"access_token"`
 	ExpiresAt   int64  `json:"expires_at"` // Unix timestamp in seconds, 0 = no expiry
+	// APIBaseURL is the Copilot API host found by probing when the token
+	// exchange does not name one (Business/Enterprise plans).
+	APIBaseURL string `json:"api_base_url,omitempty"`
 }
 
 // IsExpired returns true if the access token is expired or will expire within 5 minutes.
@@ -26,15 +31,11 @@ func (c *CopilotCredentials) IsExpired() bool {
 
 // getCopilotCredentialsPath returns the path to the Copilot credentials file
 func getCopilotCredentialsPath() (string, error) {
-	configDir := os.Getenv("XDG_CONFIG_HOME")
-	if configDir == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return "", fmt.Errorf("failed to get home directory: %w", err)
-		}
-		configDir = filepath.Join(home, ".config")
+	configDir, err := pathutil.ConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
 	}
-	return filepath.Join(configDir, "term-llm", "copilot_oauth.json"), nil
+	return filepath.Join(configDir, "copilot_oauth.json"), nil
 }
 
 // GetCopilotCredentials retrieves the Copilot OAuth credentials from storage.
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package credentials
+
+// restrictToOwner is a no-op on Unix, where the file mode already limits
+// access to the owner.
+func restrictToOwner(path string) error {
+	return nil
+}
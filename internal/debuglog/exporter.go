@@ -6,7 +6,6 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 )
 
@@ -21,19 +20,24 @@ const (
 
 // ExportOptions controls export behavior
 type ExportOptions struct {
-	Format ExportFormat
-	Redact bool // Redact sensitive content (API keys, file contents, paths)
+	Format  ExportFormat
+	Redact  bool     // Redact sensitive content (API keys, file contents, paths)
+	Secrets []string // Extra values to redact, such as stored credentials
+	Filter  FilterOptions
 }
 
 // Export exports a session in the specified format
 func Export(w io.Writer, session *Session, opts ExportOptions) error {
+	if err := opts.Filter.Check(session); err != nil {
+		return err
+	}
 	switch opts.Format {
 	case FormatJSON:
 		return exportJSON(w, session, opts)
 	case FormatMarkdown:
 		return exportMarkdown(w, session, opts)
 	case FormatRaw:
-		return exportRaw(w, session.FilePath)
+		return exportRaw(w, session.FilePath, opts)
 	default:
 		return fmt.Errorf("unknown format: %s", opts.Format)
 	}
@@ -48,14 +52,14 @@ func exportJSON(w io.Writer, session *Session, opts ExportOptions) error {
 	}
 
 	var entries []map[string]any
-	for _, line := range lines {
+	for _, line := range filterRawLines(lines, opts.Filter) {
 		var entry map[string]any
 		if err := json.Unmarshal(line, &entry); err != nil {
 			continue
 		}
 
 		if opts.Redact {
-			redactEntry(entry)
+			redactEntry(entry, opts.Secrets)
 		}
 
 		entries = append(entries, entry)
@@ -103,7 +107,7 @@ func exportMarkdown(w io.Writer, session *Session, opts ExportOptions) error {
 	fmt.Fprintf(w, "---\n\n")
 	fmt.Fprintf(w, "## Conversation Log\n\n")
 
-	for _, entry := range session.Entries {
+	for _, entry := range filterEntries(session.Entries, opts.Filter) {
 		switch e := entry.(type) {
 		case RequestEntry:
 			exportRequestMarkdown(w, e, opts)
@@ -120,6 +124,9 @@ func exportRequestMarkdown(w io.Writer, req RequestEntry, opts ExportOptions) {
 	fmt.Fprintf(w, "### Request (%s)\n\n", req.Timestamp.Local().Format("15:04:05"))
 	fmt.Fprintf(w, "- Provider: %s\n", req.Provider)
 	fmt.Fprintf(w, "- Model: %s\n", req.Model)
+	if req.TurnID != "" {
+		fmt.Fprintf(w, "- Turn: %s\n", req.TurnID)
+	}
 	if req.Request.SessionID != "" {
 		fmt.Fprintf(w, "- Session key: %s\n", req.Request.SessionID)
 	}
@@ -142,7 +149,7 @@ func exportRequestMarkdown(w io.Writer, req RequestEntry, opts ExportOptions) {
 
 		content := formatMessageContent(msg.Content, opts)
 		if opts.Redact {
-			content = redactText(content)
+			content = redactText(content, opts.Secrets)
 		}
 
 		if len(content) > 500 {
@@ -161,7 +168,7 @@ func exportEventMarkdown(w io.Writer, evt EventEntry, opts ExportOptions) {
 		fmt.Fprintf(w, "#### Tool Call: %s (%s)\n\n", name, evt.Timestamp.Local().Format("15:04:05"))
 		if args != "" {
 			if opts.Redact {
-				args = redactText(args)
+				args = redactText(args, opts.Secrets)
 			}
 			if len(args) > 500 {
 				args = args[:500] + "\n...(truncated)"
@@ -181,7 +188,7 @@ func exportEventMarkdown(w io.Writer, evt EventEntry, opts ExportOptions) {
 	case "error":
 		errMsg, _ := evt.Data["error"].(string)
 		if opts.Redact {
-			errMsg = redactText(errMsg)
+			errMsg = redactText(errMsg, opts.Secrets)
 		}
 		fmt.Fprintf(w, "**Error:** %s\n\n", errMsg)
 
@@ -207,9 +214,25 @@ func exportEventMarkdown(w io.Writer, evt EventEntry, opts ExportOptions) {
 	}
 }
 
-// exportRaw exports the raw JSONL file
-func exportRaw(w io.Writer, filePath string) error {
-	file, err := os.Open(filePath)
+// exportRaw exports the raw JSONL of every part of a session
+func exportRaw(w io.Writer, filePath string, opts ExportOptions) error {
+	if opts.Redact || !opts.Filter.IsZero() {
+		lines, err := ParseRawLines(filePath)
+		if err != nil {
+			return err
+		}
+		for _, line := range filterRawLines(lines, opts.Filter) {
+			if opts.Redact {
+				line = redactRawLine(line, opts.Secrets)
+			}
+			if _, err := fmt.Fprintf(w, "%s\n", line); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	file, err := openSession(filePath)
 	if err != nil {
 		return err
 	}
@@ -219,6 +242,21 @@ func exportRaw(w io.Writer, filePath string) error {
 	return err
 }
 
+// redactRawLine redacts one JSONL entry, keeping it on a single line. A line
+// that is not a JSON object is redacted as plain text.
+func redactRawLine(line []byte, secrets []string) []byte {
+	var entry map[string]any
+	if err := json.Unmarshal(line, &entry); err != nil {
+		return []byte(redactText(string(line), secrets))
+	}
+	redactEntry(entry, secrets)
+	redacted, err := json.Marshal(entry)
+	if err != nil {
+		return []byte(redactText(string(line), secrets))
+	}
+	return redacted
+}
+
 // formatMessageContent converts message content to a string
 func formatMessageContent(content any, opts ExportOptions) string {
 	switch c := content.(type) {
@@ -258,92 +296,6 @@ func formatMessageContent(content any, opts ExportOptions) string {
 	}
 }
 
-// redactEntry redacts sensitive data from a parsed entry
-func redactEntry(entry map[string]any) {
-	// Redact request data
-	if req, ok := entry["request"].(map[string]any); ok {
-		if messages, ok := req["messages"].([]any); ok {
-			for _, msg := range messages {
-				if m, ok := msg.(map[string]any); ok {
-					if content, ok := m["content"].(string); ok {
-						m["content"] = redactText(content)
-					}
-				}
-			}
-		}
-	}
-
-	// Redact event data
-	if data, ok := entry["data"].(map[string]any); ok {
-		if text, ok := data["text"].(string); ok {
-			data["text"] = redactText(text)
-		}
-		if args, ok := data["arguments"].(string); ok {
-			data["arguments"] = redactText(args)
-		}
-		if content, ok := data["content"].(string); ok {
-			data["content"] = redactText(content)
-		}
-		if err, ok := data["error"].(string); ok {
-			data["error"] = redactText(err)
-		}
-	}
-}
-
-// redactText redacts sensitive patterns from text
-func redactText(text string) string {
-	// API keys (common patterns)
-	text = regexp.MustCompile(`(?i)(sk-[a-zA-Z0-9]{20,})`).ReplaceAllString(text, "[REDACTED_API_KEY]")
-	text = regexp.MustCompile(`(?i)(api[_-]?key[\"']?\s*[:=]\s*[\"']?)[a-zA-Z0-9_-]{20,}`).ReplaceAllString(text, "${1}[REDACTED]")
-
-	// Home directory paths
-	homeDir, _ := os.UserHomeDir()
-	if homeDir != "" {
-		text = strings.ReplaceAll(text, homeDir, "~")
-	}
-
-	// File contents that look like code (long lines with common patterns)
-	// This is a heuristic - actual file contents in tool results
-	lines := strings.Split(text, "\n")
-	if len(lines) > 50 {
-		// Truncate very long content
-		text = strings.Join(lines[:50], "\n") + "\n[...content truncated for export...]"
-	}
-
-	return text
-}
-
-// ExportSessionByID exports a session by ID or number
-func ExportSessionByID(dir, identifier string, w io.Writer, opts ExportOptions) error {
-	summary, err := ResolveSession(dir, identifier)
-	if err != nil {
-		return err
-	}
-	if summary == nil {
-		return fmt.Errorf("session not found: %s", identifier)
-	}
-
-	session, err := ParseSession(summary.FilePath)
-	if err != nil {
-		return err
-	}
-
-	return Export(w, session, opts)
-}
-
-// ExportRawByID exports raw JSONL by session ID or number
-func ExportRawByID(dir, identifier string, w io.Writer) error {
-	summary, err := ResolveSession(dir, identifier)
-	if err != nil {
-		return err
-	}
-	if summary == nil {
-		return fmt.Errorf("session not found: %s", identifier)
-	}
-
-	return exportRaw(w, summary.FilePath)
-}
-
 // GetSessionFilePath returns the file path for a session
 func GetSessionFilePath(dir, identifier string) (string, error) {
 	summary, err := ResolveSession(dir, identifier)
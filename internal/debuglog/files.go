@@ -0,0 +1,94 @@
+package debuglog
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+// When debug_logs.max_file_mb is set, a session is split into numbered parts:
+// <session>.jsonl, then <session>.part2.jsonl, <session>.part3.jsonl and so
+// on. The layout is owned by the writer in internal/llm. Readers in this
+// package take the path of part 1 and read the remaining parts transparently.
+
+// isFirstSessionPart reports whether name is part 1 of a session, which is
+// the file that represents the session in listings.
+func isFirstSessionPart(name string) bool {
+	_, part, ok := llm.ParseDebugLogFileName(name)
+	return ok && part == 1
+}
+
+// sessionPartPaths returns filePath followed by the session's later parts in
+// order.
+func sessionPartPaths(filePath string) []string {
+	dir := filepath.Dir(filePath)
+	id, part, ok := llm.ParseDebugLogFileName(filepath.Base(filePath))
+	if !ok {
+		return []string{filePath}
+	}
+	paths := []string{filePath}
+	for {
+		part++
+		next := llm.DebugLogPartPath(dir, id, part)
+		if _, err := os.Stat(next); err != nil {
+			return paths
+		}
+		paths = append(paths, next)
+	}
+}
+
+// openSession opens every part of the session starting at filePath as one
+// continuous stream.
+func openSession(filePath string) (io.ReadCloser, error) {
+	paths := sessionPartPaths(filePath)
+	files := make(multiFileReader, 0, len(paths))
+	readers := make([]io.Reader, 0, len(paths))
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			files.Close()
+			return nil, err
+		}
+		files = append(files, file)
+		readers = append(readers, file)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(readers...), files}, nil
+}
+
+type multiFileReader []*os.File
+
+func (m multiFileReader) Close() error {
+	var firstErr error
+	for _, f := range m {
+		if err := f.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// sessionSize returns the combined size of every part of the session
+// starting at filePath.
+func sessionSize(filePath string) int64 {
+	var total int64
+	for _, path := range sessionPartPaths(filePath) {
+		if info, err := os.Stat(path); err == nil {
+			total += info.Size()
+		}
+	}
+	return total
+}
+
+// SessionFiles lists every file belonging to one session.
+type SessionFiles = llm.DebugLogSessionFiles
+
+// ListSessionFiles groups the log files in dir by session, least recently
+// written first.
+func ListSessionFiles(dir string) ([]SessionFiles, error) {
+	return llm.ListDebugLogSessionFiles(dir)
+}
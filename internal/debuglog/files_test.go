@@ -0,0 +1,168 @@
+package debuglog
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+func TestParseSessionFileName(t *testing.T) {
+	tests := []struct {
+		name     string
+		wantID   string
+		wantPart int
+		wantOK   bool
+	}{
+		{"2026-01-02T15-04-05-abc123.jsonl", "2026-01-02T15-04-05-abc123", 1, true},
+		{"2026-01-02T15-04-05-abc123.part2.jsonl", "2026-01-02T15-04-05-abc123", 2, true},
+		{"sess.part12.jsonl", "sess", 12, true},
+		{"sess.part1.jsonl", "sess.part1", 1, true},
+		{"sess.partx.jsonl", "sess.partx", 1, true},
+		{"sess.json", "", 0, false},
+		{".jsonl", "", 0, false},
+	}
+	for _, tt := range tests {
+		id, part, ok := llm.ParseDebugLogFileName(tt.name)
+		if id != tt.wantID || part != tt.wantPart || ok != tt.wantOK {
+			t.Errorf("llm.ParseDebugLogFileName(%q) = %q, %d, %v; want %q, %d, %v", tt.name, id, part, ok, tt.wantID, tt.wantPart, tt.wantOK)
+		}
+	}
+}
+
+// writeRotatedFixture writes a session split across three parts.
+func writeRotatedFixture(t *testing.T, dir, sessionID string, start time.Time) {
+	t.Helper()
+	writeDebugSearchFixture(t, dir, sessionID, start, []string{
+		debugSearchEventLine(start.Add(time.Second), sessionID, "text_delta", `{"text":"first part"}`),
+	})
+	for part, text := range map[int]string{2: "second part", 3: "needle in the third part"} {
+		line := debugSearchEventLine(start.Add(time.Duration(part)*time.Second), sessionID, "text_delta", `{"text":"`+text+`"}`) + "\n"
+		if part == 3 {
+			line += debugSearchEventLine(start.Add(4*time.Second), sessionID, "usage", `{"input_tokens":7,"output_tokens":3}`) + "\n"
+		}
+		if err := os.WriteFile(llm.DebugLogPartPath(dir, sessionID, part), []byte(line), 0600); err != nil {
+			t.Fatalf("write part %d: %v", part, err)
+		}
+	}
+}
+
+func TestRotatedSessionReadsAsOneSession(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	writeRotatedFixture(t, dir, "rotated", start)
+
+	sessions, err := ListSessions(dir)
+	if err != nil {
+		t.Fatalf("ListSessions: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != "rotated" {
+		t.Fatalf("sessions = %+v, want only the rotated session", sessions)
+	}
+	if sessions[0].Input != 7 || sessions[0].Output != 3 {
+		t.Fatalf("usage = %d/%d, want totals from the last part", sessions[0].Input, sessions[0].Output)
+	}
+	var wantSize int64
+	for part := 1; part <= 3; part++ {
+		info, err := os.Stat(llm.DebugLogPartPath(dir, "rotated", part))
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantSize += info.Size()
+	}
+	if sessions[0].FileSize != wantSize {
+		t.Fatalf("FileSize = %d, want %d", sessions[0].FileSize, wantSize)
+	}
+
+	session, err := ParseSession(sessions[0].FilePath)
+	if err != nil {
+		t.Fatalf("ParseSession: %v", err)
+	}
+	if len(session.Entries) != 5 {
+		t.Fatalf("got %d entries, want 5 across all parts", len(session.Entries))
+	}
+
+	results, err := Search(dir, SearchOptions{Query: "needle"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 1 || results[0].SessionID != "rotated" {
+		t.Fatalf("results = %+v, want one match in the rotated session", results)
+	}
+}
+
+func TestTailFollowsNewParts(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().UTC().Truncate(time.Second)
+	writeDebugSearchFixture(t, dir, "live", start, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var out lockedBuffer
+	done := make(chan error, 1)
+	go func() {
+		done <- Tail(ctx, llm.DebugLogPartPath(dir, "live", 1), &out, TailOptions{Follow: true})
+	}()
+
+	line := debugSearchEventLine(start.Add(time.Second), "live", "error", `{"error":"from part two"}`) + "\n"
+	if err := os.WriteFile(llm.DebugLogPartPath(dir, "live", 2), []byte(line), 0600); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(out.String(), "from part two") {
+		if time.Now().After(deadline) {
+			t.Fatalf("tail never printed the second part; output:\n%s", out.String())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	cancel()
+	<-done
+}
+
+func TestListSessionFilesGroupsParts(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().UTC().Truncate(time.Second)
+	writeRotatedFixture(t, dir, "rotated", start)
+	writeDebugSearchFixture(t, dir, "single", start, nil)
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "single.jsonl"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := ListSessionFiles(dir)
+	if err != nil {
+		t.Fatalf("ListSessionFiles: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("got %d sessions, want 2: %+v", len(sessions), sessions)
+	}
+	if sessions[0].ID != "single" || sessions[1].ID != "rotated" {
+		t.Fatalf("order = %s, %s; want least recently written first", sessions[0].ID, sessions[1].ID)
+	}
+	if len(sessions[1].Paths) != 3 {
+		t.Fatalf("rotated session has %d paths, want 3", len(sessions[1].Paths))
+	}
+}
+
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
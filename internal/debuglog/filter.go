@@ -0,0 +1,222 @@
+package debuglog
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FilterOptions selects part of a session by turn and/or time. Each request
+// entry starts a new turn, numbered from 1; entries logged before the first
+// request belong to turn 0 and are only shown when no turn range is set.
+// TurnID instead selects the entries stamped with one engine turn ID, which
+// may span several requests when the turn ran tools.
+type FilterOptions struct {
+	FromTurn int       // first turn to include (0 = from the start)
+	ToTurn   int       // last turn to include (0 = to the end)
+	TurnID   string    // only entries logged for this engine turn
+	Since    time.Time // drop entries before this time (zero = no limit)
+	Until    time.Time // drop entries after this time (zero = no limit)
+}
+
+// IsZero reports whether the filter selects the whole session.
+func (f FilterOptions) IsZero() bool {
+	return f.FromTurn == 0 && f.ToTurn == 0 && f.TurnID == "" && f.Since.IsZero() && f.Until.IsZero()
+}
+
+func (f FilterOptions) hasTurnRange() bool {
+	return f.FromTurn > 0 || f.ToTurn > 0
+}
+
+// Check validates the filter against a parsed session, so that a turn range
+// past the end of the session is reported instead of printing nothing.
+func (f FilterOptions) Check(session *Session) error {
+	if f.FromTurn > 0 && f.ToTurn > 0 && f.FromTurn > f.ToTurn {
+		return fmt.Errorf("invalid turn range %d..%d", f.FromTurn, f.ToTurn)
+	}
+	if f.hasTurnRange() {
+		last := f.ToTurn
+		if f.FromTurn > last {
+			last = f.FromTurn
+		}
+		if last > session.Turns {
+			return fmt.Errorf("turn %d is out of range: session has %d turns", last, session.Turns)
+		}
+	}
+	if f.TurnID != "" && !sessionHasTurnID(session, f.TurnID) {
+		return fmt.Errorf("turn %s not found in session %s", f.TurnID, session.ID)
+	}
+	if !f.Since.IsZero() && !f.Until.IsZero() && f.Until.Before(f.Since) {
+		return fmt.Errorf("--until is before --since")
+	}
+	return nil
+}
+
+func sessionHasTurnID(session *Session, turnID string) bool {
+	for _, entry := range session.Entries {
+		if entryTurnID(entry) == turnID {
+			return true
+		}
+	}
+	return false
+}
+
+func entryTurnID(entry any) string {
+	switch e := entry.(type) {
+	case RequestEntry:
+		return e.TurnID
+	case EventEntry:
+		return e.TurnID
+	}
+	return ""
+}
+
+func (f FilterOptions) includes(turn int, turnID string, ts time.Time) bool {
+	if f.TurnID != "" && turnID != f.TurnID {
+		return false
+	}
+	if f.hasTurnRange() {
+		if turn < max(f.FromTurn, 1) {
+			return false
+		}
+		if f.ToTurn > 0 && turn > f.ToTurn {
+			return false
+		}
+	}
+	if !f.Since.IsZero() && ts.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && ts.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// filterEntries returns the parsed session entries selected by f.
+func filterEntries(entries []any, f FilterOptions) []any {
+	if f.IsZero() {
+		return entries
+	}
+	var out []any
+	turn := 0
+	for _, entry := range entries {
+		var ts time.Time
+		switch e := entry.(type) {
+		case RequestEntry:
+			turn++
+			ts = e.Timestamp
+		case EventEntry:
+			ts = e.Timestamp
+		}
+		if f.includes(turn, entryTurnID(entry), ts) {
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+// filterRawLines returns the raw JSONL lines selected by f. The
+// session_start line is always kept because it describes the whole session.
+func filterRawLines(lines []json.RawMessage, f FilterOptions) []json.RawMessage {
+	if f.IsZero() {
+		return lines
+	}
+	var out []json.RawMessage
+	turn := 0
+	for _, line := range lines {
+		var entry rawEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			continue
+		}
+		switch entry.Type {
+		case "session_start":
+			out = append(out, line)
+			continue
+		case "request", "turn_request":
+			turn++
+		}
+		ts, err := time.Parse(time.RFC3339Nano, entry.Timestamp)
+		if err != nil {
+			continue
+		}
+		if f.includes(turn, entry.TurnID, ts) {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// IsTurnID reports whether s looks like an engine turn ID (a 26-character
+// ULID) rather than a turn number or range.
+func IsTurnID(s string) bool {
+	s = strings.TrimSpace(s)
+	if len(s) != 26 {
+		return false
+	}
+	for _, c := range strings.ToUpper(s) {
+		if !strings.ContainsRune("0123456789ABCDEFGHJKMNPQRSTVWXYZ", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseTurnRange parses a --turn value: "N" for a single turn, "N..M" for an
+// inclusive range, or "N.." / "..M" for an open-ended one.
+func ParseTurnRange(s string) (from, to int, err error) {
+	s = strings.TrimSpace(s)
+	parse := func(v string) (int, error) {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("invalid turn %q: must be a positive number", v)
+		}
+		return n, nil
+	}
+
+	lo, hi, isRange := strings.Cut(s, "..")
+	if !isRange {
+		n, err := parse(s)
+		return n, n, err
+	}
+	if lo == "" && hi == "" {
+		return 0, 0, fmt.Errorf("invalid turn range %q", s)
+	}
+	if lo != "" {
+		if from, err = parse(lo); err != nil {
+			return 0, 0, err
+		}
+	}
+	if hi != "" {
+		if to, err = parse(hi); err != nil {
+			return 0, 0, err
+		}
+	}
+	if from > 0 && to > 0 && from > to {
+		return 0, 0, fmt.Errorf("invalid turn range %q: start is after end", s)
+	}
+	return from, to, nil
+}
+
+// ParseFilterTime parses a --since/--until value. It accepts RFC 3339, a
+// local "YYYY-MM-DD HH:MM[:SS]" timestamp, or a bare "HH:MM[:SS]" clock
+// time, which is taken on the local day the session started.
+func ParseFilterTime(s string, sessionStart time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if clock, err := time.Parse(layout, s); err == nil {
+			day := sessionStart.Local()
+			return time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, time.Local), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use RFC 3339, \"YYYY-MM-DD HH:MM\", or \"HH:MM\"", s)
+}
@@ -0,0 +1,207 @@
+package debuglog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+func TestParseTurnRange(t *testing.T) {
+	tests := []struct {
+		in       string
+		from, to int
+		wantErr  bool
+	}{
+		{in: "3", from: 3, to: 3},
+		{in: "3..7", from: 3, to: 7},
+		{in: "3..", from: 3},
+		{in: "..7", to: 7},
+		{in: "0", wantErr: true},
+		{in: "7..3", wantErr: true},
+		{in: "..", wantErr: true},
+		{in: "a..b", wantErr: true},
+	}
+	for _, tt := range tests {
+		from, to, err := ParseTurnRange(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseTurnRange(%q) succeeded, want error", tt.in)
+			}
+			continue
+		}
+		if err != nil || from != tt.from || to != tt.to {
+			t.Errorf("ParseTurnRange(%q) = %d, %d, %v; want %d, %d", tt.in, from, to, err, tt.from, tt.to)
+		}
+	}
+}
+
+func TestParseFilterTime(t *testing.T) {
+	start := time.Date(2026, 3, 4, 10, 0, 0, 0, time.Local)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"2026-03-05T01:02:03Z", time.Date(2026, 3, 5, 1, 2, 3, 0, time.UTC)},
+		{"2026-03-05 14:05", time.Date(2026, 3, 5, 14, 5, 0, 0, time.Local)},
+		{"14:05:30", time.Date(2026, 3, 4, 14, 5, 30, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		got, err := ParseFilterTime(tt.in, start)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("ParseFilterTime(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := ParseFilterTime("yesterday", start); err == nil {
+		t.Error("ParseFilterTime(\"yesterday\") succeeded, want error")
+	}
+}
+
+// writeTurnsFixture writes a session with three turns, one minute apart,
+// each with a request and a text_delta event.
+func writeTurnsFixture(t *testing.T, dir string, start time.Time) *Session {
+	t.Helper()
+	// writeDebugSearchFixture logs the first request itself.
+	lines := []string{debugSearchEventLine(start.Add(time.Second), "turns", "text_delta", `{"text":"reply 1"}`)}
+	for turn := 2; turn <= 3; turn++ {
+		ts := start.Add(time.Duration(turn) * time.Minute)
+		lines = append(lines,
+			`{"timestamp":"`+ts.Format(time.RFC3339Nano)+`","session_id":"turns","type":"request","provider":"mock","model":"mock-model","request":{"messages":[]}}`,
+			debugSearchEventLine(ts.Add(time.Second), "turns", "text_delta", `{"text":"reply `+string(rune('0'+turn))+`"}`),
+		)
+	}
+	writeDebugSearchFixture(t, dir, "turns", start, lines)
+	session, err := ParseSession(llm.DebugLogPartPath(dir, "turns", 1))
+	if err != nil {
+		t.Fatalf("ParseSession: %v", err)
+	}
+	if session.Turns != 3 {
+		t.Fatalf("fixture has %d turns, want 3", session.Turns)
+	}
+	return session
+}
+
+func TestFilterSelectsTurnsAndTimes(t *testing.T) {
+	start := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	session := writeTurnsFixture(t, t.TempDir(), start)
+
+	tests := []struct {
+		name   string
+		filter FilterOptions
+		want   []string
+	}{
+		{"single turn", FilterOptions{FromTurn: 2, ToTurn: 2}, []string{"reply 2"}},
+		{"range", FilterOptions{FromTurn: 2, ToTurn: 3}, []string{"reply 2", "reply 3"}},
+		{"open end", FilterOptions{ToTurn: 1}, []string{"reply 1"}},
+		{"since", FilterOptions{Since: start.Add(150 * time.Second)}, []string{"reply 3"}},
+		{"until", FilterOptions{Until: start.Add(90 * time.Second)}, []string{"reply 1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.Check(session); err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+
+			var human bytes.Buffer
+			FormatSession(&human, session, FormatOptions{NoColor: true, ShowTools: true, Filter: tt.filter})
+
+			var raw bytes.Buffer
+			if err := Export(&raw, session, ExportOptions{Format: FormatRaw, Filter: tt.filter}); err != nil {
+				t.Fatalf("raw export: %v", err)
+			}
+
+			var jsonOut bytes.Buffer
+			if err := Export(&jsonOut, session, ExportOptions{Format: FormatJSON, Filter: tt.filter}); err != nil {
+				t.Fatalf("json export: %v", err)
+			}
+			var exported struct {
+				Entries []json.RawMessage `json:"entries"`
+			}
+			if err := json.Unmarshal(jsonOut.Bytes(), &exported); err != nil {
+				t.Fatalf("decode json export: %v", err)
+			}
+
+			for _, out := range []string{human.String(), raw.String(), jsonOut.String()} {
+				for i := 1; i <= 3; i++ {
+					reply := "reply " + string(rune('0'+i))
+					want := false
+					for _, w := range tt.want {
+						want = want || w == reply
+					}
+					if strings.Contains(out, reply) != want {
+						t.Errorf("output contains %q = %v, want %v:\n%s", reply, !want, want, out)
+					}
+				}
+			}
+			// Each selected turn is a request plus one event, plus the
+			// session_start line that raw and JSON output always keep.
+			if got, want := len(exported.Entries), 2*len(tt.want)+1; got != want {
+				t.Errorf("json export has %d entries, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestFilterCheckReportsTurnCount(t *testing.T) {
+	session := &Session{Turns: 42}
+	err := FilterOptions{FromTurn: 40, ToTurn: 50}.Check(session)
+	if err == nil || !strings.Contains(err.Error(), "session has 42 turns") {
+		t.Fatalf("Check = %v, want session has 42 turns", err)
+	}
+	if err := (FilterOptions{FromTurn: 40, ToTurn: 42}).Check(session); err != nil {
+		t.Fatalf("Check in range = %v", err)
+	}
+}
+
+func TestFilterAndSearchByTurnID(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	const turnID = "01J9XQ3T5B8N2K7M4C6D0E1F2G"
+	at := func(d time.Duration) string { return start.Add(d).Format(time.RFC3339Nano) }
+	writeDebugSearchFixture(t, dir, "ids", start, []string{
+		debugSearchEventLine(start.Add(time.Second), "ids", "text_delta", `{"text":"other turn"}`),
+		`{"timestamp":"` + at(time.Minute) + `","session_id":"ids","turn_id":"` + turnID + `","type":"request","provider":"mock","model":"mock-model","request":{"messages":[]}}`,
+		`{"timestamp":"` + at(time.Minute+time.Second) + `","session_id":"ids","turn_id":"` + turnID + `","type":"event","event_type":"tool_call","data":{"name":"read_file"}}`,
+		`{"timestamp":"` + at(2*time.Minute) + `","session_id":"ids","turn_id":"` + turnID + `","type":"request","provider":"mock","model":"mock-model","request":{"messages":[]}}`,
+		`{"timestamp":"` + at(2*time.Minute+time.Second) + `","session_id":"ids","turn_id":"` + turnID + `","type":"event","event_type":"text_delta","data":{"text":"tool turn reply"}}`,
+	})
+	session, err := ParseSession(llm.DebugLogPartPath(dir, "ids", 1))
+	if err != nil {
+		t.Fatalf("ParseSession: %v", err)
+	}
+
+	if !IsTurnID(strings.ToLower(turnID)) || IsTurnID("12") || IsTurnID("3..7") {
+		t.Fatal("IsTurnID misclassified a --turn value")
+	}
+
+	filter := FilterOptions{TurnID: turnID}
+	if err := filter.Check(session); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	var human bytes.Buffer
+	FormatSession(&human, session, FormatOptions{NoColor: true, ShowTools: true, Filter: filter})
+	if out := human.String(); !strings.Contains(out, "tool turn reply") || strings.Contains(out, "other turn") {
+		t.Fatalf("show --turn output:\n%s", out)
+	}
+	if !strings.Contains(human.String(), "turn "+turnID) {
+		t.Errorf("request header does not show the turn ID:\n%s", human.String())
+	}
+	if err := (FilterOptions{TurnID: "01J9XQ3T5B8N2K7M4C6D0E1F2H"}).Check(session); err == nil {
+		t.Error("Check with unknown turn ID succeeded, want error")
+	}
+
+	results, err := Search(dir, SearchOptions{TurnID: turnID, Days: 1})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("got %d results, want the turn's 4 entries: %#v", len(results), results)
+	}
+	results, err = Search(dir, SearchOptions{TurnID: turnID, ToolName: "read_file", Days: 1})
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Search with --tool = %d results, %v; want 1", len(results), err)
+	}
+}
@@ -18,6 +18,7 @@ type FormatOptions struct {
 	RequestsOnly  bool // Only show requests, not streaming events
 	NoColor       bool // Disable colors
 	ShowTimestamp bool // Show timestamp for each entry
+	Filter        FilterOptions
 }
 
 func usageDebugLine(data map[string]any) string {
@@ -217,7 +218,7 @@ func FormatSession(w io.Writer, session *Session, opts FormatOptions) {
 	fmt.Fprintln(w)
 
 	// Format entries
-	for _, entry := range session.Entries {
+	for _, entry := range filterEntries(session.Entries, opts.Filter) {
 		switch e := entry.(type) {
 		case RequestEntry:
 			formatRequestEntry(w, e, opts, styles)
@@ -236,11 +237,16 @@ func formatRequestEntry(w io.Writer, req RequestEntry, opts FormatOptions, style
 		ts = req.Timestamp.Local().Format("15:04:05") + " "
 	}
 
-	fmt.Fprintf(w, "%s%s %s/%s\n",
+	turn := ""
+	if req.TurnID != "" {
+		turn = " " + styles.Muted.Render("turn "+req.TurnID)
+	}
+	fmt.Fprintf(w, "%s%s %s/%s%s\n",
 		ts,
 		styles.Highlighted.Render("REQUEST"),
 		req.Provider,
 		req.Model,
+		turn,
 	)
 
 	// Summary of messages and tools
@@ -14,6 +14,7 @@ type rawEntry struct {
 	Timestamp string          `json:"timestamp"`
 	SessionID string          `json:"session_id"`
 	Type      string          `json:"type"`
+	TurnID    string          `json:"turn_id,omitempty"`
 	Provider  string          `json:"provider,omitempty"`
 	Model     string          `json:"model,omitempty"`
 	Request   json.RawMessage `json:"request,omitempty"`
@@ -40,7 +41,7 @@ func ListSessions(dir string) ([]SessionSummary, error) {
 
 	var sessions []SessionSummary
 	for _, entry := range entries {
-		if entry.IsDir() || filepath.Ext(entry.Name()) != ".jsonl" {
+		if entry.IsDir() || !isFirstSessionPart(entry.Name()) {
 			continue
 		}
 
@@ -60,23 +61,19 @@ func ListSessions(dir string) ([]SessionSummary, error) {
 	return sessions, nil
 }
 
-// parseSessionSummary extracts summary info from a session file
+// parseSessionSummary extracts summary info from a session file and any
+// later parts
 func parseSessionSummary(filePath string) (SessionSummary, error) {
-	file, err := os.Open(filePath)
+	file, err := openSession(filePath)
 	if err != nil {
 		return SessionSummary{}, err
 	}
 	defer file.Close()
 
-	info, err := file.Stat()
-	if err != nil {
-		return SessionSummary{}, err
-	}
-
 	summary := SessionSummary{
 		ID:       strings.TrimSuffix(filepath.Base(filePath), ".jsonl"),
 		FilePath: filePath,
-		FileSize: info.Size(),
+		FileSize: sessionSize(filePath),
 	}
 
 	scanner := newDebugLogScanner(file)
@@ -128,9 +125,10 @@ func parseSessionSummary(filePath string) (SessionSummary, error) {
 	return summary, scanner.Err()
 }
 
-// ParseSession parses a full session file into a Session struct
+// ParseSession parses a full session, including any later parts, into a
+// Session struct
 func ParseSession(filePath string) (*Session, error) {
-	file, err := os.Open(filePath)
+	file, err := openSession(filePath)
 	if err != nil {
 		return nil, err
 	}
@@ -205,6 +203,7 @@ func parseRequestEntry(entry rawEntry, ts time.Time) RequestEntry {
 	req := RequestEntry{
 		Timestamp: ts,
 		SessionID: entry.SessionID,
+		TurnID:    entry.TurnID,
 		Provider:  entry.Provider,
 		Model:     entry.Model,
 	}
@@ -221,6 +220,7 @@ func parseEventEntry(entry rawEntry, ts time.Time) EventEntry {
 	evt := EventEntry{
 		Timestamp: ts,
 		SessionID: entry.SessionID,
+		TurnID:    entry.TurnID,
 		EventType: entry.EventType,
 	}
 
@@ -301,9 +301,10 @@ func GetMostRecentSession(dir string) (*SessionSummary, error) {
 	return GetSessionByNumber(dir, 1)
 }
 
-// ParseRawLines parses a session file and returns raw JSON lines
+// ParseRawLines parses a session, including any later parts, and returns
+// raw JSON lines
 func ParseRawLines(filePath string) ([]json.RawMessage, error) {
-	file, err := os.Open(filePath)
+	file, err := openSession(filePath)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,132 @@
+package debuglog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// Well-known credential formats: OpenAI/Anthropic keys, GitHub tokens,
+	// Slack tokens, Google API keys and AWS access key IDs.
+	secretTokenPattern = regexp.MustCompile(`\b(?:sk-[A-Za-z0-9_-]{20,}|gh[opsur]_[A-Za-z0-9]{20,}|github_pat_[A-Za-z0-9_]{20,}|xox[abposr]-[A-Za-z0-9-]{10,}|AIza[0-9A-Za-z_-]{35}|AKIA[0-9A-Z]{16})\b`)
+
+	// Authorization-style headers in any dump format, e.g.
+	// "Authorization: Bearer x", "authorization":"Basic x", x-api-key=x.
+	authHeaderPattern = regexp.MustCompile(`(?i)((?:proxy-)?authorization|x-api-key|api-key|x-goog-api-key)(["']?\s*[:=]\s*["']?)((?:bearer|basic|token)\s+)?([^\s"',;]+)`)
+
+	// A bare bearer token outside of a recognisable header.
+	bearerPattern = regexp.MustCompile(`(?i)\b(bearer\s+)([A-Za-z0-9._~+/=-]{8,})`)
+
+	// NAME=value and "name": "value" where the name looks like a credential
+	// (OPENAI_API_KEY, GH_TOKEN, client_secret, DB_PASSWORD, ...).
+	secretAssignPattern = regexp.MustCompile(`(?i)\b([a-z][a-z0-9_]*(?:_key|_token|_secret|_password|apikey))(["']?\s*[:=]\s*["']?)([^\s"',;]{8,})`)
+
+	// Map keys whose string values are always treated as secrets.
+	secretKeyPattern = regexp.MustCompile(`(?i)^(?:(?:proxy-)?authorization|x-api-key|api-key|apikey|token|secret|password|[a-z0-9_-]*(?:_key|_token|_secret|_password))$`)
+)
+
+// redactPlaceholder replaces a secret with a marker that carries a short
+// hash, so repeated occurrences of the same secret stay recognisable.
+func redactPlaceholder(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return "[REDACTED:" + hex.EncodeToString(sum[:4]) + "]"
+}
+
+// redactSecrets replaces credentials in text: the given literal secrets,
+// authorization headers, credential-named assignments and well-known token
+// formats.
+func redactSecrets(text string, secrets []string) string {
+	// Longest first so a secret that contains another is replaced whole.
+	sorted := append([]string(nil), secrets...)
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	for _, secret := range sorted {
+		if len(secret) >= 8 {
+			text = strings.ReplaceAll(text, secret, redactPlaceholder(secret))
+		}
+	}
+
+	text = authHeaderPattern.ReplaceAllStringFunc(text, func(m string) string {
+		sub := authHeaderPattern.FindStringSubmatch(m)
+		if isPlaceholder(sub[4]) {
+			return m
+		}
+		return sub[1] + sub[2] + sub[3] + redactPlaceholder(sub[4])
+	})
+	text = bearerPattern.ReplaceAllStringFunc(text, func(m string) string {
+		sub := bearerPattern.FindStringSubmatch(m)
+		if isPlaceholder(sub[2]) {
+			return m
+		}
+		return sub[1] + redactPlaceholder(sub[2])
+	})
+	text = secretAssignPattern.ReplaceAllStringFunc(text, func(m string) string {
+		sub := secretAssignPattern.FindStringSubmatch(m)
+		if isPlaceholder(sub[3]) {
+			return m
+		}
+		return sub[1] + sub[2] + redactPlaceholder(sub[3])
+	})
+	return secretTokenPattern.ReplaceAllStringFunc(text, redactPlaceholder)
+}
+
+func isPlaceholder(s string) bool {
+	return strings.HasPrefix(s, "[REDACTED")
+}
+
+// redactText redacts secrets and home directory paths from text, and
+// truncates very long content such as file bodies.
+func redactText(text string, secrets []string) string {
+	text = redactSecrets(text, secrets)
+
+	// Home directory paths
+	homeDir, _ := os.UserHomeDir()
+	if homeDir != "" {
+		text = strings.ReplaceAll(text, homeDir, "~")
+	}
+
+	// File contents that look like code (long lines with common patterns)
+	// This is a heuristic - actual file contents in tool results
+	lines := strings.Split(text, "\n")
+	if len(lines) > 50 {
+		// Truncate very long content
+		text = strings.Join(lines[:50], "\n") + "\n[...content truncated for export...]"
+	}
+
+	return text
+}
+
+// redactEntry redacts every string in a parsed log entry, including request
+// messages, tool results, header dumps and provider error details. Values
+// under credential-named keys are replaced outright.
+func redactEntry(entry map[string]any, secrets []string) {
+	for key, value := range entry {
+		entry[key] = redactValue(key, value, secrets)
+	}
+}
+
+func redactValue(key string, value any, secrets []string) any {
+	switch v := value.(type) {
+	case string:
+		// Under a credential-named key, hide the whole value unless the
+		// patterns already found the secret inside it (e.g. "Bearer x"),
+		// which keeps its placeholder consistent with other occurrences.
+		if v != "" && secretKeyPattern.MatchString(key) && redactSecrets(v, secrets) == v {
+			return redactPlaceholder(v)
+		}
+		return redactText(v, secrets)
+	case map[string]any:
+		redactEntry(v, secrets)
+		return v
+	case []any:
+		for i := range v {
+			v[i] = redactValue(key, v[i], secrets)
+		}
+		return v
+	default:
+		return value
+	}
+}
@@ -15,6 +15,7 @@ type SearchOptions struct {
 	ToolName   string // Filter by tool name
 	Provider   string // Filter by provider
 	ErrorsOnly bool   // Only show sessions/entries with errors
+	TurnID     string // Only match entries logged for this engine turn
 	Days       int    // Only search sessions from last N days
 }
 
@@ -91,7 +92,7 @@ func canUseTextQueryFastPath(opts SearchOptions) bool {
 	// Plain text search is the common case for `debug-log search`. It does not
 	// need provider/error summaries, so avoid ListSessions' full JSON parse of
 	// every log before scanning the same files again.
-	return opts.Query != "" && opts.ToolName == "" && opts.Provider == "" && !opts.ErrorsOnly
+	return opts.Query != "" && opts.ToolName == "" && opts.Provider == "" && !opts.ErrorsOnly && opts.TurnID == ""
 }
 
 func searchTextQuerySessions(dir string, opts SearchOptions) ([]SearchResult, error) {
@@ -110,7 +111,7 @@ func searchTextQuerySessions(dir string, opts SearchOptions) ([]SearchResult, er
 
 	sessions := make([]SessionSummary, 0, len(entries))
 	for _, entry := range entries {
-		if entry.IsDir() || filepath.Ext(entry.Name()) != ".jsonl" {
+		if entry.IsDir() || !isFirstSessionPart(entry.Name()) {
 			continue
 		}
 
@@ -168,7 +169,7 @@ func firstSessionTimestamp(filePath string) (time.Time, error) {
 }
 
 func searchSessionTextQuery(filePath, query string) ([]SearchResult, error) {
-	file, err := os.Open(filePath)
+	file, err := openSession(filePath)
 	if err != nil {
 		return nil, err
 	}
@@ -333,7 +334,7 @@ func isASCII(s string) bool {
 
 // searchSession searches a single session file
 func searchSession(filePath string, opts SearchOptions) ([]SearchResult, error) {
-	file, err := os.Open(filePath)
+	file, err := openSession(filePath)
 	if err != nil {
 		return nil, err
 	}
@@ -379,6 +380,11 @@ func searchSession(filePath string, opts SearchOptions) ([]SearchResult, error)
 
 // matchEntry checks if an entry matches the search criteria
 func matchEntry(entry rawEntry, line []byte, opts SearchOptions) (bool, string, string) {
+	// Turn ID narrows every other criterion to one engine turn
+	if opts.TurnID != "" && !strings.EqualFold(entry.TurnID, opts.TurnID) {
+		return false, "", ""
+	}
+
 	// Tool name filter
 	if opts.ToolName != "" {
 		if entry.Type == "event" {
@@ -434,6 +440,15 @@ func matchEntry(entry rawEntry, line []byte, opts SearchOptions) (bool, string,
 		return false, "", ""
 	}
 
+	// Turn ID on its own matches every entry of the turn
+	if opts.TurnID != "" {
+		context := entry.EventType
+		if context == "" {
+			context = entry.Type
+		}
+		return true, "", context
+	}
+
 	// No filters - return nothing (user must specify something to search for)
 	return false, "", ""
 }
@@ -0,0 +1,311 @@
+package debuglog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"charm.land/lipgloss/v2"
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+// TailAllOptions controls TailAll.
+type TailAllOptions struct {
+	Follow bool // Keep watching every session, including ones created later
+
+	// ActiveWithin picks which existing sessions count as active: those
+	// written to within this window. Zero means 15 minutes.
+	ActiveWithin time.Duration
+
+	// Backlog is how many recent entries of each active session are shown
+	// before following. Zero means 10.
+	Backlog int
+
+	// PollInterval is how often files are read. Zero means 100ms; the
+	// directory is rescanned every fifth poll.
+	PollInterval time.Duration
+}
+
+const (
+	defaultTailAllActiveWithin = 15 * time.Minute
+	defaultTailAllBacklog      = 10
+	defaultTailAllPoll         = 100 * time.Millisecond
+	tailAllScanEvery           = 5
+)
+
+// tailAllColors are the ANSI colors cycled through for session prefixes.
+var tailAllColors = []string{"6", "2", "3", "5", "4", "14", "10", "11", "13", "12"}
+
+// TailAll merges the entries of every active session in dir into w, each line
+// prefixed with a short colored session ID. In follow mode it also picks up
+// sessions that start or wake up after it begins, follows rotated parts, and
+// releases sessions that go quiet.
+func TailAll(ctx context.Context, dir string, w io.Writer, opts TailAllOptions) error {
+	if opts.ActiveWithin <= 0 {
+		opts.ActiveWithin = defaultTailAllActiveWithin
+	}
+	if opts.Backlog <= 0 {
+		opts.Backlog = defaultTailAllBacklog
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultTailAllPoll
+	}
+
+	m := &tailMux{dir: dir, w: w, opts: opts, sessions: make(map[string]*muxSession)}
+	sessions, err := ListSessionFiles(dir)
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-opts.ActiveWithin)
+	for _, files := range sessions {
+		s := m.track(files)
+		if files.ModTime.Before(cutoff) {
+			continue
+		}
+		if err := m.showBacklog(s, files); err != nil {
+			return err
+		}
+	}
+	if len(m.sessions) == 0 && !opts.Follow {
+		return &NoSessionsError{}
+	}
+	if !opts.Follow {
+		return nil
+	}
+
+	ticker := time.NewTicker(opts.PollInterval)
+	defer ticker.Stop()
+	defer m.closeAll()
+	for tick := 1; ; tick++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if tick%tailAllScanEvery == 0 {
+				if err := m.scan(); err != nil {
+					return err
+				}
+			}
+			m.readActive()
+		}
+	}
+}
+
+// tailMux holds the per-session state for TailAll. Everything runs on one
+// goroutine, so entries from different sessions never interleave mid-line.
+type tailMux struct {
+	dir      string
+	w        io.Writer
+	opts     TailAllOptions
+	sessions map[string]*muxSession
+}
+
+// muxSession is one session being watched. While dormant only the position
+// of its last part is kept; it is opened again when the files change.
+type muxSession struct {
+	id         string
+	prefix     string
+	modTime    time.Time
+	part       int
+	offset     int64
+	tailer     *partTailer
+	lastOutput time.Time
+}
+
+// track records files at their current end without printing anything.
+func (m *tailMux) track(files SessionFiles) *muxSession {
+	part, offset := lastSessionPart(m.dir, files.ID)
+	s := &muxSession{
+		id:      files.ID,
+		prefix:  tailAllPrefix(files.ID),
+		modTime: files.ModTime,
+		part:    part,
+		offset:  offset,
+	}
+	m.sessions[files.ID] = s
+	return s
+}
+
+// showBacklog prints the last Backlog entries of a session and, when
+// following, keeps it open from there.
+func (m *tailMux) showBacklog(s *muxSession, files SessionFiles) error {
+	var backlog [][]byte
+	t, err := m.open(s, 1, 0, func(line []byte) {
+		backlog = append(backlog, append([]byte(nil), line...))
+		if len(backlog) > m.opts.Backlog {
+			backlog = backlog[1:]
+		}
+	})
+	if err != nil {
+		if os.IsNotExist(err) {
+			delete(m.sessions, files.ID)
+			return nil
+		}
+		return err
+	}
+	if err := t.readAvailable(); err != nil {
+		t.file.Close()
+		return err
+	}
+	for _, line := range backlog {
+		m.print(s, line)
+	}
+	if !m.opts.Follow {
+		t.file.Close()
+		return nil
+	}
+	t.emit = func(line []byte) { m.print(s, line) }
+	s.tailer = t
+	s.lastOutput = time.Now()
+	return nil
+}
+
+// scan picks up new sessions and wakes dormant ones that were written to.
+func (m *tailMux) scan() error {
+	sessions, err := ListSessionFiles(m.dir)
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]struct{}, len(sessions))
+	for _, files := range sessions {
+		seen[files.ID] = struct{}{}
+		s, ok := m.sessions[files.ID]
+		if !ok {
+			// Started after TailAll did: read from the very beginning.
+			s = &muxSession{id: files.ID, prefix: tailAllPrefix(files.ID), part: 1}
+			m.sessions[files.ID] = s
+			fmt.Fprintf(m.w, "%s new session %s\n", s.prefix, files.ID)
+		}
+		if s.tailer != nil || !files.ModTime.After(s.modTime) {
+			continue
+		}
+		s.modTime = files.ModTime
+		t, err := m.open(s, s.part, s.offset, func(line []byte) { m.print(s, line) })
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		s.tailer = t
+		s.lastOutput = time.Now()
+	}
+	for id, s := range m.sessions {
+		if _, ok := seen[id]; !ok {
+			m.release(s)
+			delete(m.sessions, id)
+		}
+	}
+	return nil
+}
+
+// readActive reads new entries from every open session and releases the
+// ones that have been quiet for longer than ActiveWithin.
+func (m *tailMux) readActive() {
+	now := time.Now()
+	for id, s := range m.sessions {
+		if s.tailer == nil {
+			continue
+		}
+		before := s.lastOutput
+		if err := s.tailer.readAvailable(); err != nil {
+			// Deleted or unreadable mid-tail: stop following it.
+			m.release(s)
+			delete(m.sessions, id)
+			continue
+		}
+		if s.lastOutput == before && now.Sub(s.lastOutput) > m.opts.ActiveWithin {
+			m.release(s)
+		}
+	}
+}
+
+// open starts a partTailer at the given part and byte offset.
+func (m *tailMux) open(s *muxSession, part int, offset int64, emit func([]byte)) (*partTailer, error) {
+	file, err := os.Open(llm.DebugLogPartPath(m.dir, s.id, part))
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		if _, err := file.Seek(offset, io.SeekStart); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return &partTailer{dir: m.dir, id: s.id, part: part, file: file, reader: bufio.NewReader(file), emit: emit}, nil
+}
+
+// release closes a session's file, remembering where reading stopped.
+func (m *tailMux) release(s *muxSession) {
+	if s.tailer == nil {
+		return
+	}
+	// Stat before the final read so a write landing in between still looks
+	// newer to the next scan.
+	if info, err := s.tailer.file.Stat(); err == nil {
+		s.modTime = info.ModTime()
+	}
+	_ = s.tailer.readAvailable()
+	s.part = s.tailer.part
+	if pos, err := s.tailer.file.Seek(0, io.SeekCurrent); err == nil {
+		s.offset = pos - int64(s.tailer.reader.Buffered())
+	}
+	s.tailer.file.Close()
+	s.tailer = nil
+}
+
+func (m *tailMux) closeAll() {
+	for _, s := range m.sessions {
+		m.release(s)
+	}
+}
+
+// print formats one entry and prefixes each resulting line.
+func (m *tailMux) print(s *muxSession, line []byte) {
+	var buf bytes.Buffer
+	FormatTailEntry(&buf, line)
+	if buf.Len() == 0 {
+		return
+	}
+	s.lastOutput = time.Now()
+	for _, out := range strings.SplitAfter(buf.String(), "\n") {
+		if out != "" {
+			fmt.Fprintf(m.w, "%s %s", s.prefix, out)
+		}
+	}
+}
+
+// lastSessionPart returns the highest part of a session and its size.
+func lastSessionPart(dir, id string) (int, int64) {
+	paths := sessionPartPaths(llm.DebugLogPartPath(dir, id, 1))
+	last := paths[len(paths)-1]
+	_, part, _ := llm.ParseDebugLogFileName(filepath.Base(last))
+	info, err := os.Stat(last)
+	if err != nil {
+		return part, 0
+	}
+	return part, info.Size()
+}
+
+// tailAllPrefix is a session's colored label, e.g. "[abc123]". IDs end in a
+// random suffix after the timestamp, which is enough to tell sessions apart.
+func tailAllPrefix(id string) string {
+	short := id
+	if i := strings.LastIndex(id, "-"); i >= 0 && i < len(id)-1 {
+		short = id[i+1:]
+	}
+	if len(short) > 8 {
+		short = short[len(short)-8:]
+	}
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	color := tailAllColors[h.Sum32()%uint32(len(tailAllColors))]
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render("[" + short + "]")
+}
@@ -0,0 +1,186 @@
+package debuglog
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+func TestTailAllNoFollowShowsActiveSessionsOnly(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().UTC().Truncate(time.Second)
+	writeDebugSearchFixture(t, dir, "2026-01-02T15-04-05-chat01", start, []string{
+		debugSearchEventLine(start.Add(time.Second), "2026-01-02T15-04-05-chat01", "error", `{"error":"from chat"}`),
+	})
+	writeDebugSearchFixture(t, dir, "2026-01-02T15-04-06-job002", start, []string{
+		debugSearchEventLine(start.Add(time.Second), "2026-01-02T15-04-06-job002", "error", `{"error":"from job"}`),
+	})
+	writeDebugSearchFixture(t, dir, "2026-01-01T00-00-00-old003", start, []string{
+		debugSearchEventLine(start.Add(time.Second), "2026-01-01T00-00-00-old003", "error", `{"error":"from yesterday"}`),
+	})
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "2026-01-01T00-00-00-old003.jsonl"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := TailAll(context.Background(), dir, &out, TailAllOptions{}); err != nil {
+		t.Fatalf("TailAll: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{"[chat01]", "from chat", "[job002]", "from job"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "from yesterday") {
+		t.Errorf("inactive session was printed:\n%s", got)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(got), "\n") {
+		if !strings.Contains(line, "[chat01]") && !strings.Contains(line, "[job002]") {
+			t.Errorf("line without session prefix: %q", line)
+		}
+	}
+}
+
+func TestTailAllNoFollowLimitsBacklog(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().UTC().Truncate(time.Second)
+	var lines []string
+	for i := 0; i < 5; i++ {
+		lines = append(lines, debugSearchEventLine(start.Add(time.Duration(i)*time.Second), "s-busy", "error", `{"error":"entry `+string(rune('a'+i))+`"}`))
+	}
+	writeDebugSearchFixture(t, dir, "s-busy", start, lines)
+
+	var out bytes.Buffer
+	if err := TailAll(context.Background(), dir, &out, TailAllOptions{Backlog: 2}); err != nil {
+		t.Fatalf("TailAll: %v", err)
+	}
+	got := out.String()
+	if strings.Contains(got, "entry c") || !strings.Contains(got, "entry d") || !strings.Contains(got, "entry e") {
+		t.Fatalf("backlog should hold only the last two entries:\n%s", got)
+	}
+}
+
+func TestTailAllFollowPicksUpNewAndRotatedSessions(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().UTC().Truncate(time.Second)
+	writeDebugSearchFixture(t, dir, "s-chat", start, nil)
+	writeDebugSearchFixture(t, dir, "s-idle", start, nil)
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "s-idle.jsonl"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var out lockedBuffer
+	done := make(chan error, 1)
+	go func() {
+		done <- TailAll(ctx, dir, &out, TailAllOptions{Follow: true, PollInterval: 10 * time.Millisecond})
+	}()
+	waitFor := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for !strings.Contains(out.String(), want) {
+			if time.Now().After(deadline) {
+				t.Fatalf("never printed %q; output:\n%s", want, out.String())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// The chat backlog marks the end of the initial scan.
+	waitFor("[chat]")
+
+	// A session that starts after the tail began.
+	writeDebugSearchFixture(t, dir, "s-job", start, []string{
+		debugSearchEventLine(start.Add(time.Second), "s-job", "error", `{"error":"job started"}`),
+	})
+	waitFor("new session s-job")
+	waitFor("job started")
+
+	// The chat session rotates to a second part.
+	line := debugSearchEventLine(start.Add(2*time.Second), "s-chat", "error", `{"error":"chat part two"}`) + "\n"
+	if err := os.WriteFile(llm.DebugLogPartPath(dir, "s-chat", 2), []byte(line), 0600); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("chat part two")
+
+	// The idle session wakes up; only the new entry is shown.
+	appendLine(t, filepath.Join(dir, "s-idle.jsonl"), debugSearchEventLine(start.Add(3*time.Second), "s-idle", "error", `{"error":"idle woke"}`))
+	waitFor("idle woke")
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("TailAll returned %v, want context.Canceled", err)
+	}
+	if got := out.String(); strings.Count(got, "REQUEST") != 2 {
+		t.Errorf("expected the request entries of the chat backlog and the new job only:\n%s", got)
+	}
+}
+
+func TestTailAllResumesQuietSessionWithoutRepeating(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().UTC().Truncate(time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var out lockedBuffer
+	done := make(chan error, 1)
+	go func() {
+		done <- TailAll(ctx, dir, &out, TailAllOptions{Follow: true, ActiveWithin: 20 * time.Millisecond, PollInterval: 5 * time.Millisecond})
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	writeDebugSearchFixture(t, dir, "s-quiet", start, nil)
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(out.String(), "REQUEST") {
+		if time.Now().After(deadline) {
+			t.Fatalf("new session never printed; output:\n%s", out.String())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Long enough for the session to be released as quiet.
+	time.Sleep(150 * time.Millisecond)
+	appendLine(t, filepath.Join(dir, "s-quiet.jsonl"), debugSearchEventLine(start.Add(time.Second), "s-quiet", "error", `{"error":"back again"}`))
+	for !strings.Contains(out.String(), "back again") {
+		if time.Now().After(deadline) {
+			t.Fatalf("woken session never printed; output:\n%s", out.String())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+	if got := out.String(); strings.Count(got, "REQUEST") != 1 {
+		t.Errorf("earlier entries were repeated after waking:\n%s", got)
+	}
+}
+
+func TestTailAllPrefixUsesSessionSuffix(t *testing.T) {
+	if got := tailAllPrefix("2026-01-02T15-04-05-abc123"); !strings.Contains(got, "[abc123]") {
+		t.Errorf("prefix = %q", got)
+	}
+	if got := tailAllPrefix("averyveryverylongsessionid"); !strings.Contains(got, "[ssionid]") && !strings.Contains(got, "[essionid]") {
+		t.Errorf("long prefix = %q", got)
+	}
+}
+
+func appendLine(t *testing.T, path, line string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -17,6 +17,7 @@ type Entry struct {
 type RequestEntry struct {
 	Timestamp time.Time
 	SessionID string
+	TurnID    string // engine turn ID; empty in logs written before turn IDs
 	Provider  string
 	Model     string
 	Request   RequestData
@@ -90,6 +91,7 @@ type Tool struct {
 type EventEntry struct {
 	Timestamp time.Time
 	SessionID string
+	TurnID    string
 	EventType string
 	Data      map[string]any
 }
@@ -7,6 +7,8 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/samsaffron/term-llm/internal/llm"
 )
 
 // TailOptions controls tail behavior
@@ -14,26 +16,23 @@ type TailOptions struct {
 	Follow bool // Keep watching for new entries
 }
 
-// Tail outputs entries from a session file, optionally following for new entries
+// Tail outputs entries from a session file, optionally following for new
+// entries. Later parts of a rotated session are read in order, and in follow
+// mode Tail moves on to each new part as it is created.
 func Tail(ctx context.Context, filePath string, w io.Writer, opts TailOptions) error {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	reader := bufio.NewReader(file)
+	id, part, _ := llm.ParseDebugLogFileName(filepath.Base(filePath))
+	t := &partTailer{dir: filepath.Dir(filePath), id: id, part: part, file: file, reader: bufio.NewReader(file), emit: func(line []byte) {
+		FormatTailEntry(w, line)
+	}}
+	defer func() { t.file.Close() }()
 
 	// First, read all existing content
-	for {
-		line, err := reader.ReadBytes('\n')
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return err
-		}
-		FormatTailEntry(w, line)
+	if err := t.readAvailable(); err != nil {
+		return err
 	}
 
 	// If not following, we're done
@@ -50,21 +49,63 @@ func Tail(ctx context.Context, filePath string, w io.Writer, opts TailOptions) e
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-ticker.C:
-			// Try to read more lines
-			for {
-				line, err := reader.ReadBytes('\n')
-				if err == io.EOF {
-					break
-				}
-				if err != nil {
-					return err
-				}
-				FormatTailEntry(w, line)
+			if err := t.readAvailable(); err != nil {
+				return err
 			}
 		}
 	}
 }
 
+// partTailer reads a session's parts in order.
+type partTailer struct {
+	dir    string
+	id     string
+	part   int
+	file   *os.File
+	reader *bufio.Reader
+	emit   func(line []byte)
+}
+
+// readAvailable writes every complete line available so far, switching to
+// later parts as they appear.
+func (t *partTailer) readAvailable() error {
+	for {
+		if err := t.drain(); err != nil {
+			return err
+		}
+		next := llm.DebugLogPartPath(t.dir, t.id, t.part+1)
+		if _, err := os.Stat(next); err != nil {
+			return nil
+		}
+		// The writer finishes a part before creating the next one, so
+		// anything written since the drain above is the last of this part.
+		if err := t.drain(); err != nil {
+			return err
+		}
+		file, err := os.Open(next)
+		if err != nil {
+			return err
+		}
+		t.file.Close()
+		t.file = file
+		t.reader.Reset(file)
+		t.part++
+	}
+}
+
+func (t *partTailer) drain() error {
+	for {
+		line, err := t.reader.ReadBytes('\n')
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		t.emit(line)
+	}
+}
+
 // TailLatest tails the most recent session file
 func TailLatest(ctx context.Context, dir string, w io.Writer, opts TailOptions) error {
 	summary, err := GetMostRecentSession(dir)
@@ -122,7 +163,7 @@ func listJSONLFiles(dir string) (map[string]struct{}, error) {
 
 	files := make(map[string]struct{})
 	for _, entry := range entries {
-		if entry.IsDir() || filepath.Ext(entry.Name()) != ".jsonl" {
+		if entry.IsDir() || !isFirstSessionPart(entry.Name()) {
 			continue
 		}
 		files[entry.Name()] = struct{}{}
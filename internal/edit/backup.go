@@ -0,0 +1,319 @@
+package edit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/appdata"
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+const (
+	// backupDirName holds one directory per edit run under the data directory.
+	backupDirName = "edit-backups"
+	// backupManifestName is the run's record of the files it changed.
+	backupManifestName = "backup.json"
+	// backupIDFormat sorts runs by the time they started.
+	backupIDFormat = "20060102-150405"
+)
+
+// BackupRun records the files one edit run changed, with copies of their
+// previous contents, so the run can be undone.
+type BackupRun struct {
+	ID        string       `json:"id"`
+	Request   string       `json:"request,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+	Files     []BackupFile `json:"files"`
+
+	dir string
+}
+
+// BackupFile is one file changed by a run.
+type BackupFile struct {
+	Path        string      `json:"path"`
+	Backup      string      `json:"backup,omitempty"` // Previous content, relative to the run directory; empty when the run created the file
+	Mode        os.FileMode `json:"mode,omitempty"`
+	AfterSHA256 string      `json:"after_sha256,omitempty"` // Content the run wrote; empty when it deleted the file
+}
+
+// BackupsDir returns the directory holding the edit backups.
+func BackupsDir() (string, error) {
+	dataDir, err := appdata.GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, backupDirName), nil
+}
+
+// NewBackupRun starts recording a run. Nothing is written until the first
+// file is changed, so a run that changes nothing leaves no backup.
+func NewBackupRun(request string) (*BackupRun, error) {
+	root, err := BackupsDir()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	id := now.Format(backupIDFormat)
+	for n := 2; ; n++ {
+		if _, err := os.Stat(filepath.Join(root, id)); os.IsNotExist(err) {
+			break
+		}
+		id = now.Format(backupIDFormat) + "-" + strconv.Itoa(n)
+	}
+	return &BackupRun{ID: id, Request: request, CreatedAt: now, dir: filepath.Join(root, id)}, nil
+}
+
+// WriteResult backs up the file r changes, then writes r like WriteResult.
+func (b *BackupRun) WriteResult(r EditResult) error {
+	path, err := filepath.Abs(r.Path)
+	if err != nil {
+		return err
+	}
+	r.Path = path
+
+	i := slices.IndexFunc(b.Files, func(f BackupFile) bool { return f.Path == path })
+	if i < 0 {
+		// Only the first write to a file is backed up: undo restores the
+		// content from before the run.
+		entry, err := b.backup(path)
+		if err != nil {
+			return fmt.Errorf("back up %s: %w", path, err)
+		}
+		b.Files = append(b.Files, entry)
+		i = len(b.Files) - 1
+	}
+
+	if err := WriteResult(r); err != nil {
+		return err
+	}
+	b.Files[i].AfterSHA256 = ""
+	if r.Operation != llm.DiffOperationDelete {
+		b.Files[i].AfterSHA256 = contentHash(r.NewContent)
+	}
+	return b.save()
+}
+
+func (b *BackupRun) backup(path string) (BackupFile, error) {
+	entry := BackupFile{Path: path}
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return entry, nil
+	}
+	if err != nil {
+		return entry, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return entry, err
+	}
+
+	entry.Backup = backupName(path)
+	entry.Mode = info.Mode().Perm()
+	target := filepath.Join(b.dir, entry.Backup)
+	if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+		return entry, err
+	}
+	if err := os.WriteFile(target, data, 0600); err != nil {
+		return entry, err
+	}
+	return entry, nil
+}
+
+// backupName mirrors an absolute path inside the run directory.
+func backupName(path string) string {
+	rel := strings.TrimPrefix(path, filepath.VolumeName(path))
+	return filepath.Join("files", strings.TrimLeft(rel, `/\`))
+}
+
+func (b *BackupRun) save() error {
+	if err := os.MkdirAll(b.dir, 0700); err != nil {
+		return fmt.Errorf("create backup directory: %w", err)
+	}
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(b.dir, backupManifestName), data, 0600); err != nil {
+		return fmt.Errorf("save backup manifest: %w", err)
+	}
+	return nil
+}
+
+// ListBackupRuns returns the recorded runs, newest first.
+func ListBackupRuns() ([]*BackupRun, error) {
+	root, err := BackupsDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read edit backups: %w", err)
+	}
+
+	var runs []*BackupRun
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		run, err := loadBackupRun(filepath.Join(root, entry.Name()))
+		if err != nil {
+			continue // An interrupted run without a manifest
+		}
+		runs = append(runs, run)
+	}
+	slices.SortFunc(runs, func(a, b *BackupRun) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(b.ID, a.ID)
+	})
+	return runs, nil
+}
+
+// LoadBackupRun loads the run with the given ID, or the most recent run
+// when id is empty.
+func LoadBackupRun(id string) (*BackupRun, error) {
+	if id == "" {
+		runs, err := ListBackupRuns()
+		if err != nil {
+			return nil, err
+		}
+		if len(runs) == 0 {
+			return nil, fmt.Errorf("no edit backups to undo")
+		}
+		return runs[0], nil
+	}
+	if id != filepath.Base(id) {
+		return nil, fmt.Errorf("invalid backup run ID %q", id)
+	}
+	root, err := BackupsDir()
+	if err != nil {
+		return nil, err
+	}
+	run, err := loadBackupRun(filepath.Join(root, id))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no edit backup run %q", id)
+	}
+	return run, err
+}
+
+func loadBackupRun(dir string) (*BackupRun, error) {
+	data, err := os.ReadFile(filepath.Join(dir, backupManifestName))
+	if err != nil {
+		return nil, err
+	}
+	var run BackupRun
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", filepath.Join(dir, backupManifestName), err)
+	}
+	run.dir = dir
+	return &run, nil
+}
+
+// PruneBackupRuns deletes all but the keep most recent runs.
+func PruneBackupRuns(keep int) error {
+	runs, err := ListBackupRuns()
+	if err != nil {
+		return err
+	}
+	for _, run := range runs[min(keep, len(runs)):] {
+		if err := os.RemoveAll(run.dir); err != nil {
+			return fmt.Errorf("remove edit backup %s: %w", run.ID, err)
+		}
+	}
+	return nil
+}
+
+// UndoStep describes what undoing a run does to one file.
+type UndoStep struct {
+	File BackupFile
+	// Conflict says why the file should not be reverted because it changed
+	// after the run; empty when reverting is safe.
+	Conflict string
+}
+
+// Restores reports whether the step writes the previous content back, as
+// opposed to removing a file the run created.
+func (s UndoStep) Restores() bool {
+	return s.File.Backup != ""
+}
+
+// PlanUndo checks every file the run changed against what the run left.
+func (b *BackupRun) PlanUndo() []UndoStep {
+	steps := make([]UndoStep, 0, len(b.Files))
+	for _, f := range b.Files {
+		step := UndoStep{File: f}
+		data, err := os.ReadFile(f.Path)
+		switch {
+		case f.AfterSHA256 == "" && err == nil:
+			step.Conflict = "recreated since the edit"
+		case f.AfterSHA256 == "":
+		case os.IsNotExist(err):
+			step.Conflict = "deleted since the edit"
+		case err != nil:
+			step.Conflict = err.Error()
+		case contentHash(string(data)) != f.AfterSHA256:
+			step.Conflict = "changed since the edit"
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// Undo reverts the run's files, skipping those that changed after the run
+// unless force is set. Reverted files are dropped from the run, and the run
+// is deleted once nothing is left to revert.
+func (b *BackupRun) Undo(force bool) (reverted []string, skipped []UndoStep, err error) {
+	var remaining []BackupFile
+	for _, step := range b.PlanUndo() {
+		if step.Conflict != "" && !force {
+			skipped = append(skipped, step)
+			remaining = append(remaining, step.File)
+			continue
+		}
+		if err := b.revert(step.File); err != nil {
+			return reverted, skipped, fmt.Errorf("revert %s: %w", step.File.Path, err)
+		}
+		reverted = append(reverted, step.File.Path)
+	}
+
+	if len(remaining) == 0 {
+		return reverted, skipped, os.RemoveAll(b.dir)
+	}
+	b.Files = remaining
+	return reverted, skipped, b.save()
+}
+
+func (b *BackupRun) revert(f BackupFile) error {
+	if f.Backup == "" {
+		if err := os.Remove(f.Path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(b.dir, f.Backup))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0755); err != nil {
+		return err
+	}
+	mode := f.Mode
+	if mode == 0 {
+		mode = 0644
+	}
+	if err := os.WriteFile(f.Path, data, mode); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of an existing file.
+	return os.Chmod(f.Path, mode)
+}
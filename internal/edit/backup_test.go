@@ -0,0 +1,113 @@
+package edit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+func TestBackupRunUndoSkipsFilesChangedAfterTheEdit(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	dir := t.TempDir()
+	untouched := filepath.Join(dir, "a.go")
+	modified := filepath.Join(dir, "sub", "b.go")
+	deleted := filepath.Join(dir, "c.go")
+	created := filepath.Join(dir, "new", "d.go")
+	for path, content := range map[string]string{untouched: "a1\n", modified: "b1\n", deleted: "c1\n"} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run, err := NewBackupRun("rewrite everything")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []EditResult{
+		{Path: untouched, NewContent: "a2\n"},
+		{Path: modified, NewContent: "b2\n"},
+		{Path: deleted, Operation: llm.DiffOperationDelete},
+		{Path: created, NewContent: "d2\n", Operation: llm.DiffOperationCreate},
+		{Path: untouched, NewContent: "a3\n"},
+	} {
+		if err := run.WriteResult(r); err != nil {
+			t.Fatalf("WriteResult(%s): %v", r.Path, err)
+		}
+	}
+
+	// Someone edits b.go after the run.
+	if err := os.WriteFile(modified, []byte("b3 by hand\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := LoadBackupRun("")
+	if err != nil {
+		t.Fatalf("LoadBackupRun: %v", err)
+	}
+	if latest.ID != run.ID || latest.Request != "rewrite everything" || len(latest.Files) != 4 {
+		t.Fatalf("latest run = %+v", latest)
+	}
+
+	reverted, skipped, err := latest.Undo(false)
+	if err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if len(reverted) != 3 || len(skipped) != 1 || skipped[0].File.Path != modified {
+		t.Fatalf("reverted = %v, skipped = %+v", reverted, skipped)
+	}
+	for path, want := range map[string]string{untouched: "a1\n", modified: "b3 by hand\n", deleted: "c1\n"} {
+		if got, err := os.ReadFile(path); err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v; want %q", filepath.Base(path), got, err, want)
+		}
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Errorf("d.go should be removed, stat err = %v", err)
+	}
+
+	// The skipped file is still recorded and can be forced back.
+	remaining, err := LoadBackupRun(run.ID)
+	if err != nil || len(remaining.Files) != 1 {
+		t.Fatalf("remaining run = %+v, %v", remaining, err)
+	}
+	if _, _, err := remaining.Undo(true); err != nil {
+		t.Fatalf("forced Undo: %v", err)
+	}
+	if got, _ := os.ReadFile(modified); string(got) != "b1\n" {
+		t.Errorf("b.go after --force = %q", got)
+	}
+	if _, err := LoadBackupRun(""); err == nil {
+		t.Error("a fully reverted run should be removed")
+	}
+}
+
+func TestPruneBackupRunsKeepsNewest(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	file := filepath.Join(t.TempDir(), "f.txt")
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		run, err := NewBackupRun("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := run.WriteResult(EditResult{Path: file, NewContent: string(rune('a' + i))}); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, run.ID)
+	}
+	if err := PruneBackupRuns(2); err != nil {
+		t.Fatal(err)
+	}
+	runs, err := ListBackupRuns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || runs[0].ID != ids[2] || runs[1].ID != ids[1] {
+		t.Fatalf("runs after prune = %v, want %v newest first", runs, ids[1:])
+	}
+}
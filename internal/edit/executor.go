@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/samsaffron/term-llm/internal/llm"
@@ -45,7 +47,8 @@ type EditResult struct {
 	Format      Format
 	MatchLevel  MatchLevel // Only for search/replace
 	Error       error
-	DiffWarning string // For unified diff with partial failures
+	DiffWarning string // For unified diff hunks that only matched loosely
+	Operation   string // llm.DiffOperationCreate or llm.DiffOperationDelete when a diff adds or removes the file
 }
 
 // ExecutorConfig configures the stream edit executor.
@@ -85,6 +88,14 @@ type ExecutorConfig struct {
 	// OnToolStart is called when a tool execution begins (e.g., read_context).
 	OnToolStart func(toolName string)
 
+	// OnToolExecuted is called after each tool call with whether it succeeded.
+	OnToolExecuted func(toolName string, success bool)
+
+	// OnTurnCompleted is called after each provider request with its token
+	// usage and tool call count. turnIndex counts requests across tool loops
+	// and retries.
+	OnTurnCompleted func(turnIndex int, metrics llm.TurnMetrics)
+
 	// OnRetry is called when an edit fails and will be retried.
 	// Provides full context for diagnostics.
 	OnRetry func(diag RetryDiagnostic)
@@ -98,10 +109,22 @@ type ExecutorConfig struct {
 	// DebugRaw enables raw request/response output.
 	DebugRaw bool
 
+	// DebugLogger, when set, records each provider request and its events in
+	// the debug log. nil disables logging.
+	DebugLogger *llm.DebugLogger
+
+	// DiffMatching sets how loosely unified diff hunks may match the file.
+	// The zero value uses udiff.DefaultOptions.
+	DiffMatching udiff.Options
+
 	// LazyContext enables on-demand context loading for guarded edits.
 	// When true, only the editable region + padding is sent initially,
 	// and the LLM can use read_context tool to fetch more.
 	LazyContext bool
+
+	// DryRun stops at the write step: Execute still streams and applies
+	// the edits in memory, but WriteResults leaves every file untouched.
+	DryRun bool
 }
 
 // StreamEditExecutor executes streaming edits with validation and retry.
@@ -117,12 +140,23 @@ type StreamEditExecutor struct {
 	retryContext *RetryContext
 	accumulated  strings.Builder // Full LLM output accumulated
 
+	// Edits kept when a unified diff partly fails. The retry only asks for
+	// the failed hunks, so later attempts start from this state.
+	carriedContents map[string]string
+	carriedResults  []EditResult
+
 	// First token tracking
 	sentFirstToken bool
+
+	// Provider requests made so far, for OnTurnCompleted.
+	turns int
 }
 
 // NewStreamEditExecutor creates a new executor.
 func NewStreamEditExecutor(provider llm.Provider, model string, config ExecutorConfig) *StreamEditExecutor {
+	if config.DiffMatching == (udiff.Options{}) {
+		config.DiffMatching = udiff.DefaultOptions()
+	}
 	return &StreamEditExecutor{
 		config:   config,
 		provider: provider,
@@ -133,6 +167,8 @@ func NewStreamEditExecutor(provider llm.Provider, model string, config ExecutorC
 // Execute runs the streaming edit with the given messages.
 // Returns the results and about text, or an error.
 func (e *StreamEditExecutor) Execute(ctx context.Context, messages []llm.Message) ([]EditResult, string, error) {
+	e.carriedContents = nil
+	e.carriedResults = nil
 	for attempt := 0; attempt < MaxRetryAttempts; attempt++ {
 		results, aboutText, retryCtx, err := e.executeOnce(ctx, messages)
 		if err == nil {
@@ -175,15 +211,18 @@ func (e *StreamEditExecutor) Execute(ctx context.Context, messages []llm.Message
 
 // executeOnce runs a single attempt at streaming edits.
 func (e *StreamEditExecutor) executeOnce(ctx context.Context, messages []llm.Message) ([]EditResult, string, *RetryContext, error) {
-	e.results = nil
+	e.results = slices.Clone(e.carriedResults)
 	e.aboutText = ""
 	e.retryContext = nil
 	e.accumulated.Reset()
 
 	// Working copy of file contents
-	workingContents := make(map[string]string)
-	for path, content := range e.config.FileContents {
-		workingContents[path] = content
+	workingContents := maps.Clone(e.carriedContents)
+	if workingContents == nil {
+		workingContents = maps.Clone(e.config.FileContents)
+	}
+	if workingContents == nil {
+		workingContents = make(map[string]string)
 	}
 
 	// Set up parser callbacks
@@ -299,6 +338,13 @@ func (e *StreamEditExecutor) executeOnce(ctx context.Context, messages []llm.Mes
 				}
 			}
 
+			// --- /dev/null and +++ /dev/null diffs create and delete files.
+			if fileDiffs, err := udiff.Parse(strings.Join(filteredLines, "\n")); err == nil && len(fileDiffs) == 1 {
+				if fd := fileDiffs[0]; fd.IsCreate() || fd.IsDelete() {
+					return e.applyFileLifecycleDiff(fd, filteredLines, workingContents)
+				}
+			}
+
 			// Try to find the file - handle both absolute and relative paths
 			content, resolvedPath, ok := findWorkingContent(workingContents, path)
 			if !ok {
@@ -350,38 +396,75 @@ func (e *StreamEditExecutor) executeOnce(ctx context.Context, messages []llm.Mes
 				return nil
 			}
 
-			// Apply hunks - collect all warnings
+			// Apply hunks, keeping the ones that match
 			oldContent := content
 			currentContent := content
-			var allWarnings []string
+			var failed []FailedHunk
+			var fuzzy []string
+			applied := 0
 
 			for _, fileDiff := range diffs {
-				result := udiff.ApplyWithWarnings(currentContent, fileDiff.Hunks)
+				result := udiff.ApplyHunks(currentContent, fileDiff.Hunks, udiff.ApplyPartially, e.config.DiffMatching)
 				currentContent = result.Content
-				allWarnings = append(allWarnings, result.Warnings...)
+				applied += len(result.Applied)
+				for _, m := range result.Fuzzy {
+					fuzzy = append(fuzzy, m.Warning())
+				}
+				for _, f := range result.Failed {
+					failed = append(failed, FailedHunk{
+						Number: f.Hunk,
+						Reason: f.Reason,
+						Detail: f.Detail,
+						Lines:  udiff.FormatHunk(fileDiff.Hunks[f.Hunk-1]),
+					})
+				}
 
 				if e.config.Debug {
-					fmt.Fprintf(os.Stderr, "[DEBUG] Applied %d hunk(s), %d warning(s)\n",
-						len(fileDiff.Hunks), len(result.Warnings))
-					for _, w := range result.Warnings {
-						fmt.Fprintf(os.Stderr, "[DEBUG]   Warning: %s\n", w)
+					fmt.Fprintf(os.Stderr, "[DEBUG] Applied %d of %d hunk(s)\n",
+						len(result.Applied), len(fileDiff.Hunks))
+					for _, f := range result.Failed {
+						fmt.Fprintf(os.Stderr, "[DEBUG]   Failed: %v\n", f)
 					}
 				}
 			}
 
-			// If ANY hunks failed, trigger retry instead of partial success
-			if len(allWarnings) > 0 {
+			if applied > 0 {
+				e.results = append(e.results, EditResult{
+					Path:        path,
+					OldContent:  oldContent,
+					NewContent:  currentContent,
+					Format:      FormatUnifiedDiff,
+					DiffWarning: strings.Join(fuzzy, "; "),
+				})
+				workingContents[path] = currentContent
+
+				if e.config.OnEditApplied != nil {
+					e.config.OnEditApplied(path, oldContent, currentContent)
+				}
+			}
+
+			// Retry with only the failed hunks; everything applied so far
+			// is kept for the next attempt.
+			if len(failed) > 0 {
 				if e.config.Debug {
-					fmt.Fprintf(os.Stderr, "[DEBUG] Hunks failed - triggering retry\n")
+					fmt.Fprintf(os.Stderr, "[DEBUG] %d hunk(s) failed - triggering retry\n", len(failed))
 				}
-				warning := strings.Join(allWarnings, "; ")
+				details := make([]string, len(failed))
+				for i, f := range failed {
+					details[i] = fmt.Sprintf("hunk %d: %s", f.Number, f.Detail)
+				}
+				warning := strings.Join(details, "; ")
 				e.retryContext = &RetryContext{
 					FilePath:      path,
 					DiffLines:     filteredLines,
-					FileContent:   content,
-					Reason:        fmt.Sprintf("some hunks failed to apply: %s", warning),
+					FileContent:   currentContent,
+					Reason:        fmt.Sprintf("%d of %d hunks failed to apply", len(failed), len(failed)+applied),
 					PartialOutput: e.accumulated.String(),
+					FailedHunks:   failed,
+					AppliedHunks:  applied,
 				}
+				e.carriedContents = maps.Clone(workingContents)
+				e.carriedResults = slices.Clone(e.results)
 				return fmt.Errorf("hunk application failed: %s", warning)
 			}
 
@@ -389,19 +472,6 @@ func (e *StreamEditExecutor) executeOnce(ctx context.Context, messages []llm.Mes
 				fmt.Fprintf(os.Stderr, "[DEBUG] All hunks applied successfully\n")
 			}
 
-			e.results = append(e.results, EditResult{
-				Path:       path,
-				OldContent: oldContent,
-				NewContent: currentContent,
-				Format:     FormatUnifiedDiff,
-			})
-
-			workingContents[path] = currentContent
-
-			if e.config.OnEditApplied != nil {
-				e.config.OnEditApplied(path, oldContent, currentContent)
-			}
-
 			return nil
 		},
 
@@ -443,6 +513,8 @@ func (e *StreamEditExecutor) executeOnce(ctx context.Context, messages []llm.Mes
 			llm.DebugRawRequest(true, e.provider.Name(), e.provider.Credential(), req, label)
 		}
 
+		e.config.DebugLogger.LogRequest(e.provider.Name(), e.model, req)
+
 		// Create cancellable context for halting
 		streamCtx, cancel := context.WithCancel(ctx)
 
@@ -455,8 +527,9 @@ func (e *StreamEditExecutor) executeOnce(ctx context.Context, messages []llm.Mes
 		// Wrap stream for debug output
 		stream := llm.WrapDebugStream(e.config.DebugRaw, rawStream)
 
-		// Collect tool calls during streaming
+		// Collect tool calls and usage during streaming
 		var toolCalls []llm.ToolCall
+		var metrics llm.TurnMetrics
 
 		// Process stream events
 		streamErr := func() error {
@@ -479,6 +552,7 @@ func (e *StreamEditExecutor) executeOnce(ctx context.Context, messages []llm.Mes
 					}
 					return fmt.Errorf("stream error: %w", err)
 				}
+				e.config.DebugLogger.LogEvent(event)
 
 				switch event.Type {
 				case llm.EventTextDelta:
@@ -498,6 +572,12 @@ func (e *StreamEditExecutor) executeOnce(ctx context.Context, messages []llm.Mes
 					}
 
 				case llm.EventUsage:
+					if event.Use != nil {
+						metrics.InputTokens += event.Use.InputTokens
+						metrics.OutputTokens += event.Use.OutputTokens
+						metrics.CachedInputTokens += event.Use.CachedInputTokens
+						metrics.CacheWriteTokens += event.Use.CacheWriteTokens
+					}
 					if e.config.OnTokens != nil && event.Use != nil {
 						e.config.OnTokens(event.Use.OutputTokens)
 					}
@@ -516,6 +596,12 @@ func (e *StreamEditExecutor) executeOnce(ctx context.Context, messages []llm.Mes
 			}
 		}()
 
+		metrics.ToolCalls = len(toolCalls)
+		if e.config.OnTurnCompleted != nil {
+			e.config.OnTurnCompleted(e.turns, metrics)
+		}
+		e.turns++
+
 		if streamErr != nil {
 			return nil, "", e.retryContext, streamErr
 		}
@@ -560,6 +646,20 @@ func (e *StreamEditExecutor) AccumulatedOutput() string {
 	return e.accumulated.String()
 }
 
+// DryRun reports whether the executor was configured for a dry run.
+func (e *StreamEditExecutor) DryRun() bool {
+	return e.config.DryRun
+}
+
+// WriteResults writes results to disk like the package-level WriteResults.
+// In a dry run it writes nothing and returns no results.
+func (e *StreamEditExecutor) WriteResults(results []EditResult, write func(EditResult) error) ([]EditResult, error) {
+	if e.config.DryRun {
+		return nil, nil
+	}
+	return WriteResults(results, write)
+}
+
 // readContextArgs holds the parsed arguments for read_context tool.
 type readContextArgs struct {
 	Path      string `json:"path"`
@@ -630,6 +730,18 @@ func (e *StreamEditExecutor) executeReadContextCalls(calls []llm.ToolCall, conte
 		results = append(results, llm.ToolResultMessage(callID, call.Name, excerpt, call.ThoughtSig))
 	}
 
+	// Each call produced one result; failures are the ones reported as
+	// "error: ..." text above.
+	if e.config.OnToolExecuted != nil {
+		for i, msg := range results {
+			content := ""
+			if len(msg.Parts) > 0 && msg.Parts[0].ToolResult != nil {
+				content = msg.Parts[0].ToolResult.Content
+			}
+			e.config.OnToolExecuted(calls[i].Name, !strings.HasPrefix(content, "error: "))
+		}
+	}
+
 	return results
 }
 
@@ -678,6 +790,67 @@ func extractLineRangeNumbered(content string, startLine, endLine int) string {
 	return strings.TrimSuffix(sb.String(), "\n")
 }
 
+// applyFileLifecycleDiff applies a diff that creates or deletes a file. The
+// file is only written or removed once the user approves the results.
+func (e *StreamEditExecutor) applyFileLifecycleDiff(fd udiff.FileDiff, diffLines []string, workingContents map[string]string) error {
+	fail := func(path, content string, err error) error {
+		e.retryContext = &RetryContext{
+			FilePath:      path,
+			DiffLines:     diffLines,
+			FileContent:   content,
+			Reason:        err.Error(),
+			PartialOutput: e.accumulated.String(),
+		}
+		return err
+	}
+
+	if fd.IsCreate() {
+		if _, ok := workingContents[fd.Path]; ok {
+			return fail(fd.Path, "", fmt.Errorf("cannot create %s: file already exists", fd.Path))
+		}
+		if _, err := os.Lstat(fd.Path); err == nil {
+			return fail(fd.Path, "", fmt.Errorf("cannot create %s: file already exists", fd.Path))
+		}
+		content, err := udiff.NewFileContent(fd.Hunks)
+		if err != nil {
+			return fail(fd.Path, "", fmt.Errorf("cannot create %s: %w", fd.Path, err))
+		}
+		e.results = append(e.results, EditResult{
+			Path:       fd.Path,
+			NewContent: content,
+			Format:     FormatUnifiedDiff,
+			Operation:  llm.DiffOperationCreate,
+		})
+		workingContents[fd.Path] = content
+		if e.config.OnEditApplied != nil {
+			e.config.OnEditApplied(fd.Path, "", content)
+		}
+		return nil
+	}
+
+	content, path, ok := findWorkingContent(workingContents, fd.Path)
+	if !ok {
+		return fail(fd.Path, "", fmt.Errorf("file not found: %s", fd.Path))
+	}
+	if _, guarded := e.config.Guards[path]; guarded {
+		return fail(path, content, fmt.Errorf("cannot delete %s: edits are limited to a line range", path))
+	}
+	if err := udiff.CheckDeletion(content, fd.Hunks); err != nil {
+		return fail(path, content, fmt.Errorf("cannot delete %s: %w", path, err))
+	}
+	e.results = append(e.results, EditResult{
+		Path:       path,
+		OldContent: content,
+		Format:     FormatUnifiedDiff,
+		Operation:  llm.DiffOperationDelete,
+	})
+	delete(workingContents, path)
+	if e.config.OnEditApplied != nil {
+		e.config.OnEditApplied(path, content, "")
+	}
+	return nil
+}
+
 // findWorkingContent finds content for a path, handling both absolute and relative paths.
 // Returns (content, resolvedPath, found).
 func findWorkingContent(contents map[string]string, path string) (string, string, bool) {
@@ -0,0 +1,107 @@
+package edit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+func TestExecutorRetriesOnlyFailedHunks(t *testing.T) {
+	original := "func a() {\n\treturn 1\n}\n\nfunc b() {\n\treturn 2\n}\n\nfunc c() {\n\treturn 3\n}\n"
+
+	provider := llm.NewMockProvider("mock").
+		AddTextResponse("--- main.go\n+++ main.go\n@@ func a @@\n-\treturn 1\n+\treturn 10\n@@ func b @@\n-\treturn 20000\n+\treturn 20\n@@ func c @@\n-\treturn 3\n+\treturn 30\n[ABOUT]\ndone\n").
+		AddTextResponse("--- main.go\n+++ main.go\n@@ func b @@\n-\treturn 2\n+\treturn 20\n[ABOUT]\nfixed\n")
+
+	var retries []RetryDiagnostic
+	executor := NewStreamEditExecutor(provider, "mock-model", ExecutorConfig{
+		FileContents: map[string]string{"main.go": original},
+		OnRetry:      func(d RetryDiagnostic) { retries = append(retries, d) },
+	})
+
+	results, _, err := executor.Execute(context.Background(), []llm.Message{llm.UserText("edit")})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	want := "func a() {\n\treturn 10\n}\n\nfunc b() {\n\treturn 20\n}\n\nfunc c() {\n\treturn 30\n}\n"
+	if got := results[len(results)-1].NewContent; got != want {
+		t.Fatalf("final content =\n%s\nwant\n%s", got, want)
+	}
+
+	if len(retries) != 1 {
+		t.Fatalf("retries = %d, want 1", len(retries))
+	}
+	retry := retries[0].RetryContext
+	if retry.AppliedHunks != 2 || len(retry.FailedHunks) != 1 || retry.FailedHunks[0].Number != 2 {
+		t.Fatalf("retry context = applied %d, failed %+v; want hunk 2 of 3 failed", retry.AppliedHunks, retry.FailedHunks)
+	}
+
+	requests := provider.RecordedRequests()
+	prompt := requests[len(requests)-1].Messages
+	retryText := prompt[len(prompt)-1].Parts[0].Text
+	if !strings.Contains(retryText, "Hunk 2 failed (no match)") || !strings.Contains(retryText, "-\treturn 20000") || strings.Contains(retryText, "+\treturn 30") {
+		t.Fatalf("retry prompt should carry only the failed hunk:\n%s", retryText)
+	}
+}
+
+func TestExecutorLogsRequestsToDebugLogger(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := llm.NewDebugLogger(dir, "edit-session")
+	if err != nil {
+		t.Fatalf("NewDebugLogger: %v", err)
+	}
+	provider := llm.NewMockProvider("mock").
+		AddTextResponse("--- main.go\n+++ main.go\n@@ func a @@\n-\treturn 1\n+\treturn 10\n[ABOUT]\ndone\n")
+	executor := NewStreamEditExecutor(provider, "mock-model", ExecutorConfig{
+		FileContents: map[string]string{"main.go": "func a() {\n\treturn 1\n}\n"},
+		DebugLogger:  logger,
+	})
+
+	if _, _, err := executor.Execute(context.Background(), []llm.Message{llm.UserText("edit")}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	logger.LogRunSummary(map[string]int{"turns": 1})
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	data, err := os.ReadFile(llm.DebugLogPartPath(dir, "edit-session", 1))
+	if err != nil {
+		t.Fatalf("read debug log: %v", err)
+	}
+	for _, want := range []string{`"type":"request"`, `"type":"event"`, `"type":"run_summary"`} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("debug log missing %s:\n%s", want, data)
+		}
+	}
+}
+
+func TestExecutorDryRunWritesNothing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	results := []EditResult{{Path: path, OldContent: "package main\n", NewContent: "package app\n"}}
+
+	dry := NewStreamEditExecutor(llm.NewMockProvider("mock"), "mock-model", ExecutorConfig{DryRun: true})
+	written, err := dry.WriteResults(results, nil)
+	if err != nil || len(written) != 0 {
+		t.Fatalf("dry run WriteResults = %v, %v; want nothing written", written, err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "package main\n" {
+		t.Fatalf("dry run changed the file to %q", data)
+	}
+
+	live := NewStreamEditExecutor(llm.NewMockProvider("mock"), "mock-model", ExecutorConfig{})
+	written, err = live.WriteResults(results, nil)
+	if err != nil || len(written) != 1 {
+		t.Fatalf("WriteResults = %v, %v; want the file written", written, err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "package app\n" {
+		t.Fatalf("file = %q, want the edit applied", data)
+	}
+}
@@ -0,0 +1,187 @@
+package edit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/llm"
+	diff "github.com/shogoki/gotextdiff"
+)
+
+// ManifestVersion is the manifest format written by NewManifest.
+const ManifestVersion = 1
+
+// Manifest lists the changes an edit would make. A dry run prints it so the
+// changes can be reviewed and later applied without another LLM call.
+type Manifest struct {
+	Version   int            `json:"version"`
+	Request   string         `json:"request,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	Files     []ManifestFile `json:"files"`
+}
+
+// ManifestFile is the change to one file.
+type ManifestFile struct {
+	Path       string `json:"path"`
+	Operation  string `json:"operation"`            // "create", "modify" or "delete"
+	OldSHA256  string `json:"old_sha256,omitempty"` // Content the change was made against; empty when creating
+	NewContent string `json:"new_content,omitempty"`
+	Diff       string `json:"diff"`
+	Added      int    `json:"added"`
+	Removed    int    `json:"removed"`
+	Warning    string `json:"warning,omitempty"`
+}
+
+// manifestModify is the manifest operation for a change to an existing file.
+const manifestModify = "modify"
+
+// NewManifest describes results, which should hold the final change for
+// each file.
+func NewManifest(request string, results []EditResult) *Manifest {
+	m := &Manifest{
+		Version:   ManifestVersion,
+		Request:   request,
+		CreatedAt: time.Now().UTC(),
+		Files:     make([]ManifestFile, 0, len(results)),
+	}
+	for _, r := range results {
+		f := ManifestFile{
+			Path:      r.Path,
+			Operation: r.Operation,
+			Warning:   r.DiffWarning,
+		}
+		if f.Operation == "" {
+			f.Operation = manifestModify
+		}
+		if f.Operation != llm.DiffOperationCreate {
+			f.OldSHA256 = contentHash(r.OldContent)
+		}
+		if f.Operation != llm.DiffOperationDelete {
+			f.NewContent = r.NewContent
+		}
+		f.Diff = string(diff.Diff(r.Path, []byte(r.OldContent), r.Path, []byte(r.NewContent)))
+		f.Added, f.Removed = countDiffLines(f.Diff)
+		m.Files = append(m.Files, f)
+	}
+	return m
+}
+
+// ReadManifest loads a manifest written from NewManifest.
+func ReadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse manifest %s: %w", path, err)
+	}
+	if m.Version != ManifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version %d (want %d)", m.Version, ManifestVersion)
+	}
+	return &m, nil
+}
+
+// Results checks that every file is still as it was when the manifest was
+// made and returns the changes as edit results. Nothing is applied if any
+// file has changed since.
+func (m *Manifest) Results() ([]EditResult, error) {
+	results := make([]EditResult, 0, len(m.Files))
+	for _, f := range m.Files {
+		if !filepath.IsAbs(f.Path) {
+			return nil, fmt.Errorf("%s: manifest paths must be absolute", f.Path)
+		}
+		data, err := os.ReadFile(f.Path)
+		exists := err == nil
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("read %s: %w", f.Path, err)
+		}
+
+		r := EditResult{Path: f.Path, OldContent: string(data), NewContent: f.NewContent, DiffWarning: f.Warning}
+		switch f.Operation {
+		case llm.DiffOperationCreate:
+			if exists {
+				return nil, fmt.Errorf("%s: file already exists", f.Path)
+			}
+			r.Operation = llm.DiffOperationCreate
+		case llm.DiffOperationDelete, manifestModify:
+			if !exists {
+				return nil, fmt.Errorf("%s: file no longer exists", f.Path)
+			}
+			if contentHash(r.OldContent) != f.OldSHA256 {
+				return nil, fmt.Errorf("%s: file changed since the manifest was made", f.Path)
+			}
+			if f.Operation == llm.DiffOperationDelete {
+				r.Operation = llm.DiffOperationDelete
+				r.NewContent = ""
+			}
+		default:
+			return nil, fmt.Errorf("%s: unknown operation %q", f.Path, f.Operation)
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// WriteResult writes an approved edit to disk, creating or removing the
+// file when the edit says so.
+func WriteResult(r EditResult) error {
+	switch r.Operation {
+	case llm.DiffOperationDelete:
+		return os.Remove(r.Path)
+	case llm.DiffOperationCreate:
+		if err := os.MkdirAll(filepath.Dir(r.Path), 0755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(r.Path, []byte(r.NewContent), 0644)
+}
+
+// WriteResults writes each result with write, or with WriteResult when
+// write is nil, and returns the results that were written. Each failure
+// is reported in the joined error as "<path>: <error>".
+func WriteResults(results []EditResult, write func(EditResult) error) ([]EditResult, error) {
+	if write == nil {
+		write = WriteResult
+	}
+	var written []EditResult
+	var errs []error
+	for _, r := range results {
+		if err := write(r); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.Path, err))
+			continue
+		}
+		written = append(written, r)
+	}
+	return written, errors.Join(errs...)
+}
+
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// countDiffLines counts the added and removed lines in a unified diff.
+func countDiffLines(text string) (added, removed int) {
+	inHunk := false
+	for _, line := range strings.Split(text, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case !inHunk:
+			// File headers
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,81 @@
+package edit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+func TestManifestRoundTripAppliesChanges(t *testing.T) {
+	dir := t.TempDir()
+	modified := filepath.Join(dir, "main.go")
+	deleted := filepath.Join(dir, "old.go")
+	created := filepath.Join(dir, "pkg", "new.go")
+	for path, content := range map[string]string{modified: "a\nb\n", deleted: "gone\n"} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := NewManifest("tidy up", []EditResult{
+		{Path: modified, OldContent: "a\nb\n", NewContent: "a\nc\n"},
+		{Path: deleted, OldContent: "gone\n", Operation: llm.DiffOperationDelete},
+		{Path: created, NewContent: "package pkg\n", Operation: llm.DiffOperationCreate},
+	})
+	if f := m.Files[0]; f.Operation != "modify" || f.Added != 1 || f.Removed != 1 || !strings.Contains(f.Diff, "+c") {
+		t.Fatalf("modified entry = %+v", f)
+	}
+
+	manifestPath := filepath.Join(dir, "manifest.json")
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(manifestPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := ReadManifest(manifestPath)
+	if err != nil {
+		t.Fatalf("ReadManifest: %v", err)
+	}
+	results, err := loaded.Results()
+	if err != nil {
+		t.Fatalf("Results: %v", err)
+	}
+	for _, r := range results {
+		if err := WriteResult(r); err != nil {
+			t.Fatalf("WriteResult(%s): %v", r.Path, err)
+		}
+	}
+
+	if got, _ := os.ReadFile(modified); string(got) != "a\nc\n" {
+		t.Errorf("main.go = %q", got)
+	}
+	if _, err := os.Stat(deleted); !os.IsNotExist(err) {
+		t.Errorf("old.go should be deleted, stat err = %v", err)
+	}
+	if got, _ := os.ReadFile(created); string(got) != "package pkg\n" {
+		t.Errorf("new.go = %q", got)
+	}
+}
+
+func TestManifestRefusesFilesChangedSince(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := NewManifest("", []EditResult{{Path: path, OldContent: "a\n", NewContent: "b\n"}})
+
+	if err := os.WriteFile(path, []byte("edited by hand\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Results(); err == nil || !strings.Contains(err.Error(), "changed since") {
+		t.Fatalf("Results error = %v, want a stale file error", err)
+	}
+}
@@ -3,6 +3,8 @@ package edit
 import (
 	"fmt"
 	"strings"
+
+	"github.com/samsaffron/term-llm/internal/udiff"
 )
 
 // RetryContext contains context for building a retry prompt after a failed edit.
@@ -14,6 +16,19 @@ type RetryContext struct {
 	Reason        string   // Why the edit failed
 	PartialOutput string   // What the LLM output before failure
 	AttemptNumber int      // Which retry attempt this is (0 = first try)
+
+	// For a unified diff that partly applied: the hunks that failed. The
+	// other hunks, and FileContent, already include the applied changes.
+	FailedHunks  []FailedHunk
+	AppliedHunks int
+}
+
+// FailedHunk is a unified diff hunk that failed to apply.
+type FailedHunk struct {
+	Number int // 1-based position of the hunk in the diff
+	Reason udiff.FailureReason
+	Detail string
+	Lines  []string // The hunk as the model wrote it, starting with its @@ header
 }
 
 // RetryDiagnostic contains full context for diagnostic logging when a retry occurs.
@@ -28,6 +43,10 @@ type RetryDiagnostic struct {
 
 // BuildRetryPrompt creates a prompt to help the LLM retry after a failed edit.
 func BuildRetryPrompt(ctx RetryContext) string {
+	if len(ctx.FailedHunks) > 0 {
+		return buildHunkRetryPrompt(ctx)
+	}
+
 	var sb strings.Builder
 
 	sb.WriteString("Edit failed. Please retry with corrected content.\n\n")
@@ -72,6 +91,60 @@ func BuildRetryPrompt(ctx RetryContext) string {
 	return sb.String()
 }
 
+// buildHunkRetryPrompt asks for only the hunks that failed, since the rest
+// of the diff and every edit before it have been applied.
+func buildHunkRetryPrompt(ctx RetryContext) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Part of your diff for %s failed to apply. ", ctx.FilePath))
+	if ctx.AppliedHunks > 0 {
+		sb.WriteString(fmt.Sprintf("The other %d hunk(s) and all edits before them have been applied; do not send them again.\n\n", ctx.AppliedHunks))
+	} else {
+		sb.WriteString("All edits before this diff have been applied; do not send them again.\n\n")
+	}
+
+	for _, hunk := range ctx.FailedHunks {
+		sb.WriteString(fmt.Sprintf("**Hunk %d failed (%s):** %s\n```diff\n", hunk.Number, hunk.Reason, hunk.Detail))
+		for _, line := range hunk.Lines {
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+		sb.WriteString("```\n\n")
+	}
+
+	if ctx.FileContent != "" {
+		nearby := findNearbyContent(ctx.FileContent, hunkSearchText(ctx.FailedHunks[0].Lines), 15)
+		if nearby != "" {
+			sb.WriteString("**Current file content near the first failed hunk:**\n```\n")
+			sb.WriteString(nearby)
+			if !strings.HasSuffix(nearby, "\n") {
+				sb.WriteString("\n")
+			}
+			sb.WriteString("```\n\n")
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("Reply with a unified diff for %s (--- and +++ headers) containing only corrected versions of the failed hunks, ", ctx.FilePath))
+	sb.WriteString("followed by any edits you had not output yet. Copy context and removed lines character-for-character from the current file.\n")
+
+	return sb.String()
+}
+
+// hunkSearchText returns the lines a hunk expects to find in the file.
+func hunkSearchText(lines []string) string {
+	var old []string
+	for _, line := range lines {
+		if strings.HasPrefix(line, "@@") || strings.HasPrefix(line, "+") || line == "-..." {
+			continue
+		}
+		if line != "" {
+			line = line[1:]
+		}
+		old = append(old, line)
+	}
+	return strings.Join(old, "\n")
+}
+
 // findNearbyContent finds the portion of the file most relevant to the failed search.
 func findNearbyContent(content, search string, contextLines int) string {
 	if search == "" {
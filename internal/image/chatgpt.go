@@ -35,11 +35,11 @@ func NewChatGPTProvider(model string) (*ChatGPTProvider, error) {
 
 	creds, err := credentials.GetChatGPTCredentials()
 	if err != nil {
-		return nil, fmt.Errorf("chatgpt image provider requires ChatGPT login — run 'term-llm ask --provider chatgpt \"hi\"' first: %w", err)
+		return nil, fmt.Errorf("chatgpt image provider requires ChatGPT login — run 'term-llm auth login chatgpt' first: %w", err)
 	}
 	if creds.IsExpired() {
 		if err := credentials.RefreshChatGPTCredentials(creds); err != nil {
-			return nil, fmt.Errorf("ChatGPT token refresh failed — re-run 'term-llm ask --provider chatgpt' to re-authenticate: %w", err)
+			return nil, fmt.Errorf("ChatGPT token refresh failed — run 'term-llm auth login chatgpt' to sign in again: %w", err)
 		}
 	}
 
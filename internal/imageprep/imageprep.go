@@ -0,0 +1,233 @@
+// Package imageprep shrinks images before they are sent to a model. It backs
+// image attachments in ask and chat and the view_image tool.
+package imageprep
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // WebP decode support
+)
+
+const (
+	// DefaultMaxDimension matches the longest edge most vision models work
+	// at; larger images are downscaled by the provider anyway.
+	DefaultMaxDimension = 1568
+	// DefaultQuality is the JPEG quality used when an image is re-encoded.
+	DefaultQuality = 85
+
+	// maxShrinkAttempts bounds the quality/size reductions tried to get
+	// under Options.MaxBytes.
+	maxShrinkAttempts = 6
+)
+
+// ErrUnsupported reports data that is not a PNG, JPEG, GIF or WebP image.
+// Callers send such attachments unchanged.
+var ErrUnsupported = errors.New("unsupported image format")
+
+// Options controls Prepare. Zero values select the defaults.
+type Options struct {
+	MaxDimension int // longest edge in pixels
+	Quality      int // JPEG quality, 1-100
+	MaxBytes     int // fail if the result is still larger; 0 means no limit
+}
+
+func (o Options) withDefaults() Options {
+	if o.MaxDimension <= 0 {
+		o.MaxDimension = DefaultMaxDimension
+	}
+	if o.Quality <= 0 || o.Quality > 100 {
+		o.Quality = DefaultQuality
+	}
+	return o
+}
+
+// Result is a prepared image. Data is the original slice when nothing needed
+// to change.
+type Result struct {
+	Data      []byte
+	MediaType string
+	Width     int
+	Height    int
+
+	OriginalBytes  int
+	OriginalWidth  int
+	OriginalHeight int
+}
+
+// Changed reports whether the image was re-encoded.
+func (r Result) Changed() bool {
+	return r.Width != r.OriginalWidth || r.Height != r.OriginalHeight || len(r.Data) != r.OriginalBytes
+}
+
+// Summary describes the image for attachment confirmations, e.g.
+// "4032×3024, 3.1MB → 1568×1176, 214KB".
+func (r Result) Summary() string {
+	final := fmt.Sprintf("%d×%d, %s", r.Width, r.Height, formatBytes(len(r.Data)))
+	if !r.Changed() {
+		return final
+	}
+	return fmt.Sprintf("%d×%d, %s → %s", r.OriginalWidth, r.OriginalHeight, formatBytes(r.OriginalBytes), final)
+}
+
+// Prepare downscales data so its longest edge is at most opts.MaxDimension
+// and re-encodes it: PNG and GIF sources stay PNG to keep transparency, the
+// rest become JPEG. Animated GIFs are reduced to their first frame. Images
+// already within limits are returned untouched. Undecodable data returns an
+// error wrapping ErrUnsupported, with Result.Data still set to data.
+func Prepare(data []byte, opts Options) (Result, error) {
+	opts = opts.withDefaults()
+	res := Result{Data: data, OriginalBytes: len(data)}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return res, fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	res.MediaType = mediaTypeForFormat(format)
+	res.OriginalWidth, res.OriginalHeight = cfg.Width, cfg.Height
+	res.Width, res.Height = cfg.Width, cfg.Height
+
+	w, h := fitWithin(cfg.Width, cfg.Height, opts.MaxDimension)
+	animated := format == "gif" && isAnimatedGIF(data)
+	if !animated && w == cfg.Width && h == cfg.Height && (opts.MaxBytes == 0 || len(data) <= opts.MaxBytes) {
+		return res, nil
+	}
+
+	// image.Decode returns the first frame of an animated GIF.
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return res, fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	scaled := src
+	if w != cfg.Width || h != cfg.Height {
+		scaled = resize(src, w, h)
+	}
+
+	quality := opts.Quality
+	var out []byte
+	mediaType := "image/jpeg"
+	if format == "png" || format == "gif" {
+		out, err = encodePNG(scaled)
+		mediaType = "image/png"
+	} else {
+		out, err = encodeJPEG(scaled, quality)
+	}
+	if err != nil {
+		return res, err
+	}
+
+	// Over the byte cap: switch to JPEG, then lower the quality, then shrink.
+	for attempt := 0; opts.MaxBytes > 0 && len(out) > opts.MaxBytes; attempt++ {
+		if attempt == maxShrinkAttempts {
+			return res, fmt.Errorf("image still exceeds %s after resizing (%s)", formatBytes(opts.MaxBytes), formatBytes(len(out)))
+		}
+		switch {
+		case mediaType != "image/jpeg":
+			mediaType = "image/jpeg"
+		case quality > 55:
+			quality -= 15
+		default:
+			w, h = max(1, w*3/4), max(1, h*3/4)
+			scaled = resize(src, w, h)
+		}
+		if out, err = encodeJPEG(scaled, quality); err != nil {
+			return res, err
+		}
+	}
+
+	res.Data = out
+	res.MediaType = mediaType
+	res.Width, res.Height = w, h
+	return res, nil
+}
+
+// MediaType returns the media type of a PNG, JPEG, GIF or WebP image, or ""
+// for anything else.
+func MediaType(data []byte) string {
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return ""
+	}
+	return mediaTypeForFormat(format)
+}
+
+func mediaTypeForFormat(format string) string {
+	switch format {
+	case "jpeg":
+		return "image/jpeg"
+	case "png":
+		return "image/png"
+	case "gif":
+		return "image/gif"
+	case "webp":
+		return "image/webp"
+	default:
+		return "image/" + format
+	}
+}
+
+// fitWithin scales width×height down, keeping the aspect ratio, so neither
+// edge exceeds limit.
+func fitWithin(width, height, limit int) (int, int) {
+	if width <= limit && height <= limit {
+		return width, height
+	}
+	if width >= height {
+		return limit, max(1, int(int64(height)*int64(limit)/int64(width)))
+	}
+	return max(1, int(int64(width)*int64(limit)/int64(height))), limit
+}
+
+func isAnimatedGIF(data []byte) bool {
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	return err == nil && len(g.Image) > 1
+}
+
+func resize(src image.Image, width, height int) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Over, nil)
+	return dst
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encode PNG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeJPEG flattens transparency onto white, which JPEG cannot represent,
+// and encodes at quality.
+func encodeJPEG(img image.Image, quality int) ([]byte, error) {
+	if o, ok := img.(interface{ Opaque() bool }); !ok || !o.Opaque() {
+		flat := image.NewRGBA(img.Bounds())
+		draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+		img = flat
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("encode JPEG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func formatBytes(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.0fKB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
@@ -0,0 +1,162 @@
+package imageprep
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// noisyImage returns a w×h image of random pixels, which compresses poorly
+// and so behaves like a photo.
+func noisyImage(w, h int) *image.RGBA {
+	rng := rand.New(rand.NewSource(1))
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(rng.Intn(256))
+	}
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 0xff
+	}
+	return img
+}
+
+func encodeTestJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodeTestPNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func decodedSize(t *testing.T, data []byte) (int, int, string) {
+	t.Helper()
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode result: %v", err)
+	}
+	return cfg.Width, cfg.Height, format
+}
+
+func TestPrepareDownscalesLargeJPEG(t *testing.T) {
+	data := encodeTestJPEG(t, noisyImage(3000, 2000))
+
+	res, err := Prepare(data, Options{})
+	if err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	w, h, format := decodedSize(t, res.Data)
+	if w != 1568 || h != 1045 || format != "jpeg" {
+		t.Fatalf("result = %dx%d %s, want 1568x1045 jpeg", w, h, format)
+	}
+	if res.Width != w || res.Height != h || res.MediaType != "image/jpeg" {
+		t.Fatalf("result metadata = %dx%d %s", res.Width, res.Height, res.MediaType)
+	}
+	if len(res.Data) >= len(data)/2 {
+		t.Fatalf("result is %d bytes, want well under the original %d", len(res.Data), len(data))
+	}
+	if !res.Changed() || !strings.Contains(res.Summary(), "3000×2000") || !strings.Contains(res.Summary(), "→ 1568×1045") {
+		t.Fatalf("summary = %q", res.Summary())
+	}
+}
+
+func TestPrepareKeepsPNGAndHonoursMaxDimension(t *testing.T) {
+	img := noisyImage(600, 1200)
+	img.Set(0, 0, color.RGBA{}) // transparent pixel
+	data := encodeTestPNG(t, img)
+
+	res, err := Prepare(data, Options{MaxDimension: 400})
+	if err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	w, h, format := decodedSize(t, res.Data)
+	if w != 200 || h != 400 || format != "png" || res.MediaType != "image/png" {
+		t.Fatalf("result = %dx%d %s (%s), want 200x400 png", w, h, format, res.MediaType)
+	}
+}
+
+func TestPrepareLeavesSmallImagesUntouched(t *testing.T) {
+	data := encodeTestPNG(t, noisyImage(64, 32))
+
+	res, err := Prepare(data, Options{})
+	if err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	if &res.Data[0] != &data[0] || res.Changed() {
+		t.Fatal("small image should be returned as-is")
+	}
+	if res.MediaType != "image/png" || res.Summary() != "64×32, "+formatBytes(len(data)) {
+		t.Fatalf("result = %s %q", res.MediaType, res.Summary())
+	}
+}
+
+func TestPrepareTakesFirstFrameOfAnimatedGIF(t *testing.T) {
+	palette := color.Palette{color.Black, color.White}
+	frame := func(c uint8) *image.Paletted {
+		img := image.NewPaletted(image.Rect(0, 0, 10, 10), palette)
+		for i := range img.Pix {
+			img.Pix[i] = c
+		}
+		return img
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, &gif.GIF{Image: []*image.Paletted{frame(1), frame(0)}, Delay: []int{10, 10}}); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Prepare(buf.Bytes(), Options{})
+	if err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	if res.MediaType != "image/png" {
+		t.Fatalf("media type = %s, want image/png", res.MediaType)
+	}
+	img, err := png.Decode(bytes.NewReader(res.Data))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if r, _, _, _ := img.At(5, 5).RGBA(); r != 0xffff {
+		t.Fatal("result should be the first (white) frame")
+	}
+}
+
+func TestPrepareEnforcesMaxBytes(t *testing.T) {
+	data := encodeTestPNG(t, noisyImage(1000, 1000))
+	limit := 200 << 10
+
+	res, err := Prepare(data, Options{MaxBytes: limit})
+	if err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	if len(res.Data) > limit || res.MediaType != "image/jpeg" {
+		t.Fatalf("result = %d bytes %s, want at most %d bytes of JPEG", len(res.Data), res.MediaType, limit)
+	}
+}
+
+func TestPrepareRejectsUnsupportedFormats(t *testing.T) {
+	data := []byte("BM not really a bitmap")
+
+	res, err := Prepare(data, Options{})
+	if !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("err = %v, want ErrUnsupported", err)
+	}
+	if !bytes.Equal(res.Data, data) {
+		t.Fatal("unsupported data should be returned untouched")
+	}
+}
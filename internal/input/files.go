@@ -2,13 +2,11 @@ package input
 
 import (
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/samsaffron/term-llm/internal/clipboard"
-	"golang.org/x/term"
 )
 
 // FileContent represents content read from a file or other source
@@ -100,36 +98,6 @@ func ReadFiles(paths []string) ([]FileContent, error) {
 	return result, nil
 }
 
-// HasStdin returns true if stdin has data available (not a TTY)
-func HasStdin() bool {
-	fi, err := os.Stdin.Stat()
-	if err != nil {
-		return false
-	}
-	// Check if stdin is a pipe or has data
-	return (fi.Mode()&os.ModeCharDevice) == 0 || fi.Size() > 0
-}
-
-// ReadStdin reads all content from stdin
-// Returns empty string if stdin is a TTY or has no data
-func ReadStdin() (string, error) {
-	if !HasStdin() {
-		return "", nil
-	}
-
-	// Check if stdin is a terminal
-	if term.IsTerminal(int(os.Stdin.Fd())) {
-		return "", nil
-	}
-
-	data, err := io.ReadAll(os.Stdin)
-	if err != nil {
-		return "", fmt.Errorf("failed to read stdin: %w", err)
-	}
-
-	return string(data), nil
-}
-
 // FormatFilesXML formats file contents with prompt-safe delimiters.
 func FormatFilesXML(files []FileContent, stdin string) string {
 	if len(files) == 0 && stdin == "" {
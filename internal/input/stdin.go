@@ -0,0 +1,84 @@
+package input
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// StdinArg is the file argument that names standard input, as in
+// "git diff | term-llm ask -f - 'review this'" or "jobs create --file -".
+const StdinArg = "-"
+
+// ErrBinaryStdin is returned by ReadStdinText when stdin is not text.
+var ErrBinaryStdin = errors.New("stdin looks like binary data; save it to a file and pass it with -f, or pipe text")
+
+var (
+	stdinOnce sync.Once
+	stdinData string
+	stdinErr  error
+)
+
+// HasStdin returns true if stdin has data available (not a TTY)
+func HasStdin() bool {
+	fi, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	// Check if stdin is a pipe or has data
+	return (fi.Mode()&os.ModeCharDevice) == 0 || fi.Size() > 0
+}
+
+// ReadStdin reads all content from stdin
+// Returns empty string if stdin is a TTY or has no data
+//
+// Stdin is read at most once per process; later calls return the same
+// content, so an explicit "-" argument and the implicit piped input of the
+// same command share it instead of the second reader seeing EOF.
+func ReadStdin() (string, error) {
+	stdinOnce.Do(func() {
+		if !HasStdin() || term.IsTerminal(int(os.Stdin.Fd())) {
+			return
+		}
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			stdinErr = fmt.Errorf("failed to read stdin: %w", err)
+			return
+		}
+		stdinData = string(data)
+	})
+	return stdinData, stdinErr
+}
+
+// ReadStdinText is ReadStdin for callers that put stdin into a prompt. It
+// returns ErrBinaryStdin rather than passing binary data along.
+func ReadStdinText() (string, error) {
+	content, err := ReadStdin()
+	if err != nil {
+		return "", err
+	}
+	if LooksBinary([]byte(content)) {
+		return "", ErrBinaryStdin
+	}
+	return content, nil
+}
+
+// LooksBinary reports whether data is likely not text: it has a NUL byte
+// or is not valid UTF-8 within its first 8KB.
+func LooksBinary(data []byte) bool {
+	const sniffLen = 8 << 10
+	if len(data) > sniffLen {
+		data = data[:sniffLen]
+		// Don't count a multi-byte rune cut off by the sniff window.
+		for i := 0; i < utf8.UTFMax-1 && len(data) > 0 && !utf8.Valid(data); i++ {
+			data = data[:len(data)-1]
+		}
+	}
+	return bytes.IndexByte(data, 0) >= 0 || !utf8.Valid(data)
+}
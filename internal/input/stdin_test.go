@@ -0,0 +1,29 @@
+package input
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLooksBinary(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"empty", nil, false},
+		{"diff", []byte("--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-old\n+new\n"), false},
+		{"utf8", []byte("héllo wörld ✓"), false},
+		{"nul byte", []byte("PK\x03\x04\x00\x00"), true},
+		{"invalid utf8", []byte{0xff, 0xfe, 'a'}, true},
+		// A rune split by the 8KB sniff window is still text.
+		{"rune at sniff boundary", []byte(strings.Repeat("a", 8<<10-1) + "✓"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LooksBinary(tt.data); got != tt.want {
+				t.Errorf("LooksBinary() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
@@ -44,6 +45,14 @@ const (
 	RunSkipped         RunStatus = "skipped"
 )
 
+// Overlap policies decide what a scheduled run does when the job's previous
+// run is still active.
+const (
+	OverlapSkip           = "skip"
+	OverlapQueue          = "queue"
+	OverlapCancelPrevious = "cancel_previous"
+)
+
 const (
 	ExitReasonNatural    = "natural_completion"
 	ExitReasonMaxTurns   = "max_turns_exceeded"
@@ -52,6 +61,9 @@ const (
 	ExitReasonException  = "exception"
 	ExitReasonEmpty      = "empty_response"
 	ExitReasonWorkerLost = "worker_lost"
+	// ExitReasonInterrupted marks a run cancelled because the server shut
+	// down while it was in flight.
+	ExitReasonInterrupted = "interrupted"
 )
 
 type RetryPolicy struct {
@@ -82,6 +94,7 @@ type Job struct {
 	RetryPolicy       json.RawMessage `json:"retry_policy,omitempty"`
 	TimeoutSeconds    int             `json:"timeout_seconds,omitempty"`
 	MisfirePolicy     string          `json:"misfire_policy,omitempty"`
+	OverlapPolicy     string          `json:"overlap_policy,omitempty"`
 	Labels            json.RawMessage `json:"labels,omitempty"`
 	NextRunAt         *time.Time      `json:"next_run_at,omitempty"`
 	LastRun           *Run            `json:"last_run,omitempty"`
@@ -102,6 +115,7 @@ type JobRequest struct {
 	RetryPolicy       json.RawMessage `json:"retry_policy,omitempty"`
 	TimeoutSeconds    int             `json:"timeout_seconds,omitempty"`
 	MisfirePolicy     string          `json:"misfire_policy,omitempty"`
+	OverlapPolicy     string          `json:"overlap_policy,omitempty"`
 	Labels            json.RawMessage `json:"labels,omitempty"`
 }
 
@@ -123,6 +137,7 @@ func (req JobRequest) ToJob(defaultEnabled bool) Job {
 		RetryPolicy:       req.RetryPolicy,
 		TimeoutSeconds:    req.TimeoutSeconds,
 		MisfirePolicy:     req.MisfirePolicy,
+		OverlapPolicy:     req.OverlapPolicy,
 		Labels:            req.Labels,
 	}
 }
@@ -214,6 +229,8 @@ type LLMConfig struct {
 	ReadDir         []string `json:"read_dir,omitempty"`
 	WriteDir        []string `json:"write_dir,omitempty"`
 	Tools           string   `json:"tools,omitempty"`
+	AllowedTools    []string `json:"allowed_tools,omitempty"`
+	DeniedTools     []string `json:"denied_tools,omitempty"`
 	MaxTurns        int      `json:"max_turns,omitempty"`
 	MaxOutputTokens int      `json:"max_output_tokens,omitempty"`
 	Search          bool     `json:"search,omitempty"`
@@ -267,6 +284,9 @@ func ClassifyRunError(err error, result RunResult) (exitReason string, truncated
 			return ExitReasonTimeout, truncated
 		}
 		if errors.Is(err, context.Canceled) {
+			if result.ExitReason == ExitReasonInterrupted {
+				return result.ExitReason, truncated
+			}
 			return ExitReasonCancelled, truncated
 		}
 		if llm.IsMaxTurnsExceeded(err) || strings.Contains(err.Error(), "max turns") {
@@ -315,7 +335,6 @@ var (
 )
 
 func (r *ProgramRunner) Run(ctx context.Context, job Job, pw ProgressWriter) (RunResult, error) {
-	_ = pw
 	var cfg ProgramConfig
 	if err := json.Unmarshal(job.RunnerConfig, &cfg); err != nil {
 		return RunResult{}, fmt.Errorf("invalid program runner config: %w", err)
@@ -326,8 +345,16 @@ func (r *ProgramRunner) Run(ctx context.Context, job Job, pw ProgressWriter) (Ru
 
 	var cmd *exec.Cmd
 	if cfg.Shell {
-		args := append([]string{"-c", cfg.Command, "--"}, cfg.Args...)
-		cmd = exec.CommandContext(ctx, detectShell(), args...)
+		shell := procutil.DefaultShell("/bin/sh")
+		switch {
+		case procutil.KindOfShell(shell) == procutil.ShellPOSIX:
+			args := append([]string{"-c", cfg.Command, "--"}, cfg.Args...)
+			cmd = exec.CommandContext(ctx, shell, args...)
+		case len(cfg.Args) > 0:
+			return RunResult{}, fmt.Errorf("program args with shell: true need a POSIX shell (got %s)", shell)
+		default:
+			cmd = procutil.ShellCommand(ctx, shell, cfg.Command)
+		}
 	} else {
 		cmd = exec.CommandContext(ctx, cfg.Command, cfg.Args...)
 	}
@@ -357,6 +384,18 @@ func (r *ProgramRunner) Run(ctx context.Context, job Job, pw ProgressWriter) (Ru
 	stderr := procutil.NewLimitedBuffer(outputLimit)
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
+	if logs, ok := runLogsFromContext(ctx); ok {
+		files, err := openRunLogs(logs)
+		if err != nil {
+			return RunResult{}, err
+		}
+		cmd.Stdout = io.MultiWriter(stdout, files.stdout)
+		cmd.Stderr = io.MultiWriter(stderr, files.stderr)
+		defer func() {
+			files.Close()
+			files.report(pw)
+		}()
+	}
 
 	err := cmd.Run()
 	exitCode := 0
@@ -389,10 +428,3 @@ func stringOrEmptyRaw(raw json.RawMessage, fallback string) string {
 	}
 	return string(raw)
 }
-
-func detectShell() string {
-	if sh := os.Getenv("SHELL"); sh != "" {
-		return sh
-	}
-	return "/bin/sh"
-}
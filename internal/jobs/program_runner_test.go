@@ -106,3 +106,55 @@ func TestProgramRunnerDoesNotLeakBackgroundChildren(t *testing.T) {
 func shellQuote(s string) string {
 	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
 }
+
+func TestProgramRunnerCapturesOutputToCappedLogFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "run_1")
+	runner := &ProgramRunner{OutputLimit: 64}
+	job := testProgramJob(t, ProgramConfig{
+		Command: `head -c 200000 /dev/zero | tr '\0' x; echo oops >&2`,
+		Shell:   true,
+	})
+	var events []map[string]any
+	var messages []string
+	pw := func(eventType, message string, data any) {
+		if eventType == "output_captured" {
+			events = append(events, data.(map[string]any))
+			messages = append(messages, message)
+		}
+	}
+	ctx := WithRunLogs(context.Background(), RunLogs{Dir: dir, Limit: 4096})
+	result, err := runner.Run(ctx, job, pw)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(result.Stdout) > 64 || !result.Truncated {
+		t.Fatalf("in-memory stdout should keep its own limit: len=%d truncated=%v", len(result.Stdout), result.Truncated)
+	}
+
+	stdout, err := os.ReadFile(RunLogPath(dir, LogStdout))
+	if err != nil {
+		t.Fatalf("read stdout log: %v", err)
+	}
+	kept, notice, ok := strings.Cut(string(stdout), "\n[output truncated")
+	if !ok || len(kept) != 4096 || strings.Trim(kept, "x") != "" {
+		t.Fatalf("stdout log should hold 4096 bytes then a notice, got %d bytes: %q", len(stdout), notice)
+	}
+	stderr, err := os.ReadFile(RunLogPath(dir, LogStderr))
+	if err != nil || string(stderr) != "oops\n" {
+		t.Fatalf("stderr log = %q, %v", stderr, err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("output_captured events = %d, want 1", len(events))
+	}
+	ev := events[0]
+	if ev["stdout_bytes"] != int64(200000) || ev["stdout_captured_bytes"] != int64(4096) || ev["stdout_truncated"] != true {
+		t.Fatalf("stdout sizes = %+v", ev)
+	}
+	if ev["stderr_bytes"] != int64(5) || ev["stderr_truncated"] != false {
+		t.Fatalf("stderr sizes = %+v", ev)
+	}
+	if want := "stdout: 195.3KB, stderr: 5B (logs capped at 4.0KB)"; messages[0] != want {
+		t.Fatalf("message = %q, want %q", messages[0], want)
+	}
+}
@@ -0,0 +1,165 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Log streams captured for program runs.
+const (
+	LogStdout = "stdout"
+	LogStderr = "stderr"
+)
+
+// DefaultRunLogLimit caps each captured log file.
+const DefaultRunLogLimit int64 = 10 << 20
+
+// RunLogs asks ProgramRunner to copy a run's stdout and stderr to files in
+// Dir, each capped at Limit bytes. The in-memory RunResult output is still
+// kept and limited separately.
+type RunLogs struct {
+	Dir   string
+	Limit int64
+}
+
+type runLogsKey struct{}
+
+// WithRunLogs returns a context that makes ProgramRunner capture output to
+// logs.Dir.
+func WithRunLogs(ctx context.Context, logs RunLogs) context.Context {
+	return context.WithValue(ctx, runLogsKey{}, logs)
+}
+
+func runLogsFromContext(ctx context.Context) (RunLogs, bool) {
+	logs, ok := ctx.Value(runLogsKey{}).(RunLogs)
+	return logs, ok && strings.TrimSpace(logs.Dir) != ""
+}
+
+// RunLogPath returns the file a stream is captured to inside a run's log dir.
+func RunLogPath(dir, stream string) string {
+	return filepath.Join(dir, stream+".log")
+}
+
+// ValidLogStream reports whether stream names a captured stream.
+func ValidLogStream(stream string) bool {
+	return stream == LogStdout || stream == LogStderr
+}
+
+// cappedLogWriter copies output to a file until limit bytes have been
+// written, then appends a truncation notice and counts the rest. Write never
+// fails, so a full disk cannot kill the program through a broken pipe; the
+// first file error is kept in err instead.
+type cappedLogWriter struct {
+	f         *os.File
+	limit     int64
+	written   int64
+	total     int64
+	truncated bool
+	err       error
+}
+
+func (w *cappedLogWriter) Write(p []byte) (int, error) {
+	w.total += int64(len(p))
+	if w.truncated || w.err != nil {
+		return len(p), nil
+	}
+	chunk := p
+	if room := w.limit - w.written; int64(len(chunk)) > room {
+		chunk = chunk[:room]
+		w.truncated = true
+	}
+	n, err := w.f.Write(chunk)
+	w.written += int64(n)
+	if err != nil {
+		w.err = err
+		return len(p), nil
+	}
+	if w.truncated {
+		if _, err := fmt.Fprintf(w.f, "\n[output truncated: log capped at %s]\n", formatLogSize(w.limit)); err != nil {
+			w.err = err
+		}
+	}
+	return len(p), nil
+}
+
+// runLogFiles holds the open capture files for one program run.
+type runLogFiles struct {
+	stdout *cappedLogWriter
+	stderr *cappedLogWriter
+}
+
+func openRunLogs(logs RunLogs) (*runLogFiles, error) {
+	if err := os.MkdirAll(logs.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create run log dir: %w", err)
+	}
+	limit := logs.Limit
+	if limit <= 0 {
+		limit = DefaultRunLogLimit
+	}
+	open := func(stream string) (*cappedLogWriter, error) {
+		f, err := os.OpenFile(RunLogPath(logs.Dir, stream), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("create %s log: %w", stream, err)
+		}
+		return &cappedLogWriter{f: f, limit: limit}, nil
+	}
+	stdout, err := open(LogStdout)
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := open(LogStderr)
+	if err != nil {
+		_ = stdout.f.Close()
+		return nil, err
+	}
+	return &runLogFiles{stdout: stdout, stderr: stderr}, nil
+}
+
+func (l *runLogFiles) Close() {
+	_ = l.stdout.f.Close()
+	_ = l.stderr.f.Close()
+}
+
+// report writes the output_captured event with the captured sizes.
+func (l *runLogFiles) report(pw ProgressWriter) {
+	if pw == nil {
+		return
+	}
+	data := map[string]any{
+		"stdout_bytes":          l.stdout.total,
+		"stderr_bytes":          l.stderr.total,
+		"stdout_captured_bytes": l.stdout.written,
+		"stderr_captured_bytes": l.stderr.written,
+		"stdout_truncated":      l.stdout.truncated,
+		"stderr_truncated":      l.stderr.truncated,
+	}
+	msg := fmt.Sprintf("stdout: %s, stderr: %s", formatLogSize(l.stdout.total), formatLogSize(l.stderr.total))
+	if l.stdout.truncated || l.stderr.truncated {
+		msg += fmt.Sprintf(" (logs capped at %s)", formatLogSize(l.stdout.limit))
+	}
+	for _, w := range []*cappedLogWriter{l.stdout, l.stderr} {
+		if w.err != nil {
+			data["error"] = w.err.Error()
+			msg += "; log write failed: " + w.err.Error()
+			break
+		}
+	}
+	pw("output_captured", msg, data)
+}
+
+// formatLogSize formats a byte count as a short human-readable size.
+func formatLogSize(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%dB", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%cB", float64(b)/float64(div), "KMGTPE"[exp])
+}
@@ -281,7 +281,7 @@ func (p *AnthropicProvider) streamStandard(ctx context.Context, req Request) (St
 		}
 
 		var lastUsage *Usage
-		var streamOpts []option.RequestOption
+		streamOpts := []option.RequestOption{option.WithMiddleware(anthropicHTTPMiddleware)}
 		if p.use1m {
 			streamOpts = append(streamOpts, option.WithHeaderAdd("anthropic-beta", the1mBetaHeader))
 		}
@@ -849,10 +849,12 @@ func buildAnthropicTools(specs []ToolSpec) []anthropic.ToolUnionParam {
 	}
 	tools := make([]anthropic.ToolUnionParam, 0, len(specs))
 	for _, spec := range specs {
+		schema := cachedToolSchemaFor(spec.Schema, ToolSchemaAnthropic)
 		inputSchema := anthropic.ToolInputSchemaParam{
-			Type:       constant.Object("object"),
-			Properties: spec.Schema["properties"],
-			Required:   schemaRequired(spec.Schema),
+			Type:        constant.Object("object"),
+			Properties:  schema["properties"],
+			Required:    schemaRequired(schema),
+			ExtraFields: anthropicSchemaExtraFields(schema),
 		}
 		tool := anthropic.ToolUnionParamOfTool(inputSchema, spec.Name)
 		if spec.Description != "" {
@@ -866,16 +868,35 @@ func buildAnthropicTools(specs []ToolSpec) []anthropic.ToolUnionParam {
 	return tools
 }
 
+// anthropicSchemaExtraFields carries root keywords other than type, properties
+// and required (such as $defs or additionalProperties) into input_schema.
+func anthropicSchemaExtraFields(schema map[string]interface{}) map[string]any {
+	var extra map[string]any
+	for key, value := range schema {
+		switch key {
+		case "type", "properties", "required":
+			continue
+		}
+		if extra == nil {
+			extra = map[string]any{}
+		}
+		extra[key] = value
+	}
+	return extra
+}
+
 func buildAnthropicBetaTools(specs []ToolSpec) []anthropic.BetaToolUnionParam {
 	if len(specs) == 0 {
 		return nil
 	}
 	tools := make([]anthropic.BetaToolUnionParam, 0, len(specs))
 	for _, spec := range specs {
+		schema := cachedToolSchemaFor(spec.Schema, ToolSchemaAnthropic)
 		inputSchema := anthropic.BetaToolInputSchemaParam{
-			Type:       constant.Object("object"),
-			Properties: spec.Schema["properties"],
-			Required:   schemaRequired(spec.Schema),
+			Type:        constant.Object("object"),
+			Properties:  schema["properties"],
+			Required:    schemaRequired(schema),
+			ExtraFields: anthropicSchemaExtraFields(schema),
 		}
 		tool := anthropic.BetaToolUnionParam{
 			OfTool: &anthropic.BetaToolParam{
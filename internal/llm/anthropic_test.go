@@ -1332,3 +1332,157 @@ func keys(m map[string]json.RawMessage) []string {
 	}
 	return out
 }
+
+// anthropicSSE joins SSE events in the Messages streaming format.
+func anthropicSSE(events ...[2]string) string {
+	var b strings.Builder
+	for _, ev := range events {
+		fmt.Fprintf(&b, "event: %s\ndata: %s\n\n", ev[0], ev[1])
+	}
+	return b.String()
+}
+
+// streamAnthropicSSE runs one request against a server replying with sse and
+// returns the decoded request body and the events received before EventDone
+// or the first error.
+func streamAnthropicSSE(t *testing.T, req Request, sse string) (map[string]json.RawMessage, []Event, error) {
+	t.Helper()
+	var body map[string]json.RawMessage
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, sse)
+	}))
+	defer ts.Close()
+
+	client := anthropic.NewClient(option.WithAPIKey("test-key"), option.WithBaseURL(ts.URL), option.WithMaxRetries(0))
+	provider := &AnthropicProvider{client: &client, model: "claude-sonnet-4-5"}
+	stream, err := provider.Stream(context.Background(), req)
+	if err != nil {
+		return body, nil, err
+	}
+	defer stream.Close()
+
+	var events []Event
+	for {
+		ev, err := stream.Recv()
+		if err != nil {
+			return body, events, err
+		}
+		if ev.Type == EventError {
+			return body, events, ev.Err
+		}
+		if ev.Type == EventDone {
+			return body, events, nil
+		}
+		events = append(events, ev)
+	}
+}
+
+func TestAnthropicStreamTextWithCachedUsage(t *testing.T) {
+	sse := anthropicSSE(
+		[2]string{"message_start", `{"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","content":[],"model":"claude-sonnet-4-5","usage":{"input_tokens":12,"cache_read_input_tokens":900,"cache_creation_input_tokens":40,"output_tokens":0}}}`},
+		[2]string{"content_block_start", `{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`},
+		[2]string{"content_block_delta", `{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}}`},
+		[2]string{"content_block_delta", `{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":" there"}}`},
+		[2]string{"content_block_stop", `{"type":"content_block_stop","index":0}`},
+		[2]string{"message_delta", `{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":5}}`},
+		[2]string{"message_stop", `{"type":"message_stop"}`},
+	)
+	body, events, err := streamAnthropicSSE(t, Request{
+		Messages: []Message{SystemText("Be brief."), UserText("hi")},
+	}, sse)
+	if err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+
+	var text strings.Builder
+	var usage *Usage
+	for _, ev := range events {
+		switch ev.Type {
+		case EventTextDelta:
+			text.WriteString(ev.Text)
+		case EventUsage:
+			usage = ev.Use
+		}
+	}
+	if text.String() != "Hello there" {
+		t.Fatalf("text = %q, want %q", text.String(), "Hello there")
+	}
+	if usage == nil {
+		t.Fatal("expected a usage event")
+	}
+	if usage.InputTokens != 12 || usage.OutputTokens != 5 || usage.CachedInputTokens != 900 || usage.CacheWriteTokens != 40 {
+		t.Fatalf("usage = %+v, want input 12, output 5, cache read 900, cache write 40", *usage)
+	}
+
+	var system []struct {
+		Text         string          `json:"text"`
+		CacheControl json.RawMessage `json:"cache_control"`
+	}
+	if err := json.Unmarshal(body["system"], &system); err != nil {
+		t.Fatalf("decode system: %v (body keys %v)", err, keys(body))
+	}
+	if len(system) != 1 || system[0].Text != "Be brief." || len(system[0].CacheControl) == 0 {
+		t.Fatalf("system = %s, want one cached top-level block", body["system"])
+	}
+	if !strings.Contains(string(body["messages"]), `"cache_control"`) {
+		t.Fatalf("messages carry no cache breakpoint: %s", body["messages"])
+	}
+}
+
+func TestAnthropicStreamToolUse(t *testing.T) {
+	sse := anthropicSSE(
+		[2]string{"message_start", `{"type":"message_start","message":{"id":"msg_2","type":"message","role":"assistant","content":[],"model":"claude-sonnet-4-5","usage":{"input_tokens":20,"output_tokens":0}}}`},
+		[2]string{"content_block_start", `{"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"toolu_1","name":"read_file","input":{}}}`},
+		[2]string{"content_block_delta", `{"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"{\"path\":"}}`},
+		[2]string{"content_block_delta", `{"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"\"main.go\"}"}}`},
+		[2]string{"content_block_stop", `{"type":"content_block_stop","index":0}`},
+		[2]string{"message_delta", `{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":9}}`},
+		[2]string{"message_stop", `{"type":"message_stop"}`},
+	)
+	_, events, err := streamAnthropicSSE(t, Request{
+		Messages: []Message{UserText("read main.go")},
+		Tools: []ToolSpec{{
+			Name:        "read_file",
+			Description: "Read a file",
+			Schema:      map[string]interface{}{"type": "object", "properties": map[string]interface{}{"path": map[string]interface{}{"type": "string"}}},
+		}},
+	}, sse)
+	if err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+
+	var calls []*ToolCall
+	for _, ev := range events {
+		if ev.Type == EventToolCall && ev.Tool != nil {
+			calls = append(calls, ev.Tool)
+		}
+	}
+	if len(calls) != 1 {
+		t.Fatalf("got %d tool calls, want 1", len(calls))
+	}
+	if calls[0].ID != "toolu_1" || calls[0].Name != "read_file" {
+		t.Fatalf("tool call = %+v", calls[0])
+	}
+	var args map[string]string
+	if err := json.Unmarshal(calls[0].Arguments, &args); err != nil || args["path"] != "main.go" {
+		t.Fatalf("tool arguments = %s (%v), want path main.go", calls[0].Arguments, err)
+	}
+}
+
+func TestAnthropicStreamOverloadedError(t *testing.T) {
+	sse := anthropicSSE(
+		[2]string{"message_start", `{"type":"message_start","message":{"id":"msg_3","type":"message","role":"assistant","content":[],"model":"claude-sonnet-4-5","usage":{"input_tokens":3,"output_tokens":0}}}`},
+		[2]string{"error", `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`},
+	)
+	_, _, err := streamAnthropicSSE(t, Request{Messages: []Message{UserText("hi")}}, sse)
+	if err == nil {
+		t.Fatal("expected an error for an overloaded_error event")
+	}
+	if !isRetryable(err) {
+		t.Fatalf("error = %v, want it to be retryable", err)
+	}
+}
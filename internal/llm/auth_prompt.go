@@ -2,11 +2,54 @@ package llm
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
+	"sync/atomic"
+
+	"golang.org/x/term"
 )
 
+// ErrAuthRequired matches every AuthRequiredError.
+var ErrAuthRequired = errors.New("authentication required")
+
+// AuthRequiredError reports that a provider's stored credentials are missing
+// or no longer usable and the user has to sign in again.
+type AuthRequiredError struct {
+	Provider string // ID accepted by `term-llm auth login`, e.g. "copilot"
+	Reason   string
+}
+
+func (e *AuthRequiredError) Error() string {
+	return fmt.Sprintf("%s; run 'term-llm auth login %s'", e.Reason, e.Provider)
+}
+
+func (e *AuthRequiredError) Unwrap() error {
+	return ErrAuthRequired
+}
+
+// authLoginRequired is the error returned when stored credentials are missing
+// or unusable and nobody is at a terminal to sign in again.
+func authLoginRequired(provider, reason string) error {
+	return &AuthRequiredError{Provider: provider, Reason: reason}
+}
+
+var interactiveAuthDisabled atomic.Bool
+
+// SetInteractiveAuth enables or disables sign-in prompts on stdin. The chat
+// TUI turns them off while it owns the terminal and handles
+// AuthRequiredError itself.
+func SetInteractiveAuth(enabled bool) {
+	interactiveAuthDisabled.Store(!enabled)
+}
+
+// canPromptForAuth reports whether a sign-in flow can ask the user anything.
+// Swapped out in tests.
+var canPromptForAuth = func() bool {
+	return !interactiveAuthDisabled.Load() && term.IsTerminal(int(os.Stdin.Fd()))
+}
+
 // waitForEnterOrInterrupt waits for the user to press Enter or Ctrl+C.
 // Returns nil on Enter, or an error on interrupt.
 func waitForEnterOrInterrupt() error {
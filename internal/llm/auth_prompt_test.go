@@ -0,0 +1,42 @@
+package llm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/credentials"
+)
+
+func TestProvidersFailFastWithoutTerminal(t *testing.T) {
+	oldCanPrompt := canPromptForAuth
+	canPromptForAuth = func() bool { return false }
+	defer func() { canPromptForAuth = oldCanPrompt }()
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if _, err := NewCopilotProvider(""); err == nil || !strings.Contains(err.Error(), "Copilot is not signed in; run 'term-llm auth login copilot'") {
+		t.Fatalf("NewCopilotProvider without credentials: %v", err)
+	}
+	_, err := NewChatGPTProvider("")
+	var authErr *AuthRequiredError
+	if !errors.As(err, &authErr) || authErr.Provider != "chatgpt" || !errors.Is(err, ErrAuthRequired) {
+		t.Fatalf("NewChatGPTProvider without credentials = %v, want an AuthRequiredError", err)
+	}
+	if !strings.Contains(err.Error(), "ChatGPT is not signed in; run 'term-llm auth login chatgpt'") {
+		t.Fatalf("NewChatGPTProvider without credentials: %v", err)
+	}
+	if shouldFailover(err) {
+		t.Fatal("a provider that needs a new sign-in should surface the error instead of failing over")
+	}
+
+	if err := credentials.SaveCopilotCredentials(&credentials.CopilotCredentials{
+		AccessToken: "gho_test",
+		ExpiresAt:   time.Now().Add(-time.Hour).Unix(),
+	}); err != nil {
+		t.Fatalf("SaveCopilotCredentials: %v", err)
+	}
+	if _, err := NewCopilotProvider(""); err == nil || !strings.Contains(err.Error(), "Copilot token expired; run 'term-llm auth login copilot'") {
+		t.Fatalf("NewCopilotProvider with expired credentials: %v", err)
+	}
+}
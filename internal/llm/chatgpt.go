@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"os"
 	"strings"
 	"time"
 
@@ -15,7 +14,6 @@ import (
 	"github.com/samsaffron/term-llm/internal/oauth"
 	"github.com/samsaffron/term-llm/internal/providerhttp"
 	"github.com/samsaffron/term-llm/internal/signal"
-	"golang.org/x/term"
 )
 
 var chatGPTDefaultModel = config.DefaultProviderModel("chatgpt")
@@ -65,8 +63,7 @@ func NewChatGPTProviderWithOptions(model string, opts ChatGPTProviderOptions) (*
 	// Try to load existing credentials
 	creds, err := credentials.GetChatGPTCredentials()
 	if err != nil {
-		// No credentials - prompt user to authenticate
-		creds, err = PromptForChatGPTAuth()
+		creds, err = promptForChatGPTAuth("ChatGPT is not signed in")
 		if err != nil {
 			return nil, err
 		}
@@ -78,8 +75,7 @@ func NewChatGPTProviderWithOptions(model string, opts ChatGPTProviderOptions) (*
 			if !errors.Is(refreshErr, oauth.ErrChatGPTRefreshTokenInvalid) {
 				return nil, fmt.Errorf("token refresh failed: %w", refreshErr)
 			}
-			fmt.Println("Token refresh failed. Re-authentication required.")
-			creds, err = PromptForChatGPTAuth()
+			creds, err = promptForChatGPTAuth("ChatGPT token refresh failed")
 			if err != nil {
 				return nil, err
 			}
@@ -125,13 +121,17 @@ func NewChatGPTProviderWithCredsAndOptions(creds *credentials.ChatGPTCredentials
 // advertise device-code support. Exported so `term-llm auth login chatgpt`
 // can drive the same flow used by lazy auth.
 func PromptForChatGPTAuth() (*credentials.ChatGPTCredentials, error) {
-	// Check if stdin is a terminal - if not, we can't do interactive auth
-	if !term.IsTerminal(int(os.Stdin.Fd())) {
-		return nil, fmt.Errorf("ChatGPT authentication required but running in non-interactive mode.\n" +
-			"Run 'term-llm auth login chatgpt' interactively first to authenticate")
+	return promptForChatGPTAuth("ChatGPT provider requires authentication")
+}
+
+// promptForChatGPTAuth runs the sign-in flow after printing reason, or fails
+// fast with a pointer to `term-llm auth login` when stdin is not a terminal.
+func promptForChatGPTAuth(reason string) (*credentials.ChatGPTCredentials, error) {
+	if !canPromptForAuth() {
+		return nil, authLoginRequired("chatgpt", reason)
 	}
 
-	fmt.Println("ChatGPT provider requires authentication.")
+	fmt.Println(reason + ".")
 
 	// Wire Ctrl-C through to the full auth wait (device-code poll OR
 	// browser callback). The 15-minute cap matches the server-side
@@ -150,14 +150,9 @@ func PromptForChatGPTAuth() (*credentials.ChatGPTCredentials, error) {
 		return nil, fmt.Errorf("authentication failed: %w", err)
 	}
 
-	creds := &credentials.ChatGPTCredentials{
-		AccessToken:  oauthCreds.AccessToken,
-		RefreshToken: oauthCreds.RefreshToken,
-		ExpiresAt:    oauthCreds.ExpiresAt,
-		AccountID:    oauthCreds.AccountID,
-	}
-	if err := credentials.SaveChatGPTCredentials(creds); err != nil {
-		return nil, fmt.Errorf("failed to save credentials: %w", err)
+	creds, err := saveChatGPTOAuthCredentials(oauthCreds)
+	if err != nil {
+		return nil, err
 	}
 
 	fmt.Println("Authentication successful!")
@@ -204,9 +199,10 @@ func (p *ChatGPTProvider) Credential() string {
 
 func (p *ChatGPTProvider) Capabilities() Capabilities {
 	return Capabilities{
-		NativeWebSearch: true,
-		NativeWebFetch:  false,
-		ToolCalls:       true,
+		NativeWebSearch:  true,
+		NativeWebFetch:   false,
+		ToolCalls:        true,
+		StructuredOutput: true,
 	}
 }
 
@@ -214,7 +210,10 @@ func (p *ChatGPTProvider) Stream(ctx context.Context, req Request) (Stream, erro
 	// Check and refresh token if needed
 	if p.creds.IsExpired() {
 		if err := credentials.RefreshChatGPTCredentials(p.creds); err != nil {
-			return nil, fmt.Errorf("token refresh failed: %w (re-run with --provider chatgpt to re-authenticate)", err)
+			if errors.Is(err, oauth.ErrChatGPTRefreshTokenInvalid) {
+				return nil, authLoginRequired("chatgpt", "ChatGPT token refresh failed")
+			}
+			return nil, fmt.Errorf("token refresh failed: %w (run 'term-llm auth login chatgpt' to sign in again)", err)
 		}
 	}
 
@@ -273,6 +272,8 @@ func (p *ChatGPTProvider) Stream(ctx context.Context, req Request) (Stream, erro
 		Store:     boolPtr(false),
 		Stream:    true,
 		SessionID: req.SessionID,
+		Ephemeral: req.Ephemeral,
+		Text:      buildResponsesText(req.ResponseFormat),
 	}
 
 	if serviceTier := p.serviceTier; req.ServiceTierSet || strings.TrimSpace(req.ServiceTier) != "" {
@@ -290,6 +291,19 @@ func (p *ChatGPTProvider) Stream(ctx context.Context, req Request) (Stream, erro
 	if req.ParallelToolCalls {
 		responsesReq.ParallelToolCalls = boolPtr(true)
 	}
+	if maxOutput := ClampOutputTokens(req.MaxOutputTokens, model); maxOutput > 0 {
+		responsesReq.MaxOutputTokens = maxOutput
+	}
+	if chatGPTAcceptsSampling(model, effort) {
+		if req.TemperatureSet || req.Temperature != 0 {
+			v := float64(req.Temperature)
+			responsesReq.Temperature = &v
+		}
+		if req.TopPSet || req.TopP != 0 {
+			v := float64(req.TopP)
+			responsesReq.TopP = &v
+		}
+	}
 	responsesReq.Reasoning = &ResponsesReasoning{Summary: "auto"}
 	if effort != "" {
 		responsesReq.Reasoning.Effort = effort
@@ -298,6 +312,23 @@ func (p *ChatGPTProvider) Stream(ctx context.Context, req Request) (Stream, erro
 	return p.responsesClient.Stream(ctx, responsesReq, req.DebugRaw)
 }
 
+// chatGPTAcceptsSampling reports whether the backend accepts temperature and
+// top_p for model. o-series and codex models reject them outright; GPT-5
+// family models only accept them when reasoning is disabled (effort "none").
+func chatGPTAcceptsSampling(model, effort string) bool {
+	model = strings.ToLower(model)
+	switch {
+	case strings.HasPrefix(model, "o1"), strings.HasPrefix(model, "o3"), strings.HasPrefix(model, "o4"):
+		return false
+	case strings.Contains(model, "codex"):
+		return false
+	case strings.HasPrefix(model, "gpt-5"):
+		return strings.EqualFold(strings.TrimSpace(effort), "none")
+	default:
+		return true
+	}
+}
+
 // ResetConversation clears server state for the Responses API client.
 func (p *ChatGPTProvider) ResetConversation() {
 	if p.responsesClient != nil {
@@ -341,7 +372,7 @@ func NewChatGPTResponsesClient(creds *credentials.ChatGPTCredentials) *Responses
 				if clearErr := credentials.ClearChatGPTCredentialsIfRefreshToken(failedRefreshToken); clearErr != nil {
 					return fmt.Errorf("ChatGPT session expired and failed to clear credentials: %w", clearErr)
 				}
-				return fmt.Errorf("ChatGPT session expired — please re-run your command to re-authenticate")
+				return authLoginRequired("chatgpt", "ChatGPT session expired")
 			}
 			return nil
 		},
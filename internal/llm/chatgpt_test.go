@@ -443,3 +443,180 @@ func TestChatGPTStream_ReasoningSummaryByOutputIndex(t *testing.T) {
 		t.Fatalf("reasoning tokens = %d, want 1", usageEvent.Use.ReasoningTokens)
 	}
 }
+
+func captureChatGPTRequestPayload(t *testing.T, model string, req Request) map[string]any {
+	t.Helper()
+	origClient := chatGPTHTTPClient
+	t.Cleanup(func() { chatGPTHTTPClient = origClient })
+
+	var body []byte
+	chatGPTHTTPClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("read request: %v", err)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(strings.NewReader(strings.Join([]string{
+				`event: response.completed`,
+				`data: {"type":"response.completed","response":{"usage":{"input_tokens":1,"output_tokens":1,"total_tokens":2}}}`,
+				`data: [DONE]`,
+			}, "\n"))),
+			Header: make(http.Header),
+		}, nil
+	})}
+
+	provider := NewChatGPTProviderWithCreds(&credentials.ChatGPTCredentials{
+		AccessToken: "test-token",
+		AccountID:   "test-account",
+		ExpiresAt:   time.Now().Add(time.Hour).Unix(),
+	}, model)
+	req.Messages = []Message{UserText("hello")}
+	stream, err := provider.Stream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("stream creation failed: %v", err)
+	}
+	defer stream.Close()
+	drainStreamToDone(t, stream)
+
+	var payload map[string]any
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("decode request: %v", err)
+	}
+	return payload
+}
+
+func TestChatGPTStream_SamplingAndOutputLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		model       string
+		req         Request
+		wantMax     any
+		wantTemp    any
+		wantTopP    any
+		wantAbsent  []string
+		wantPresent []string
+	}{
+		{
+			name:       "zero values omitted",
+			model:      "gpt-4.1",
+			req:        Request{},
+			wantAbsent: []string{"max_output_tokens", "temperature", "top_p"},
+		},
+		{
+			name:        "set values forwarded",
+			model:       "gpt-4.1",
+			req:         Request{MaxOutputTokens: 1234, Temperature: 0.25, TopP: 0.5},
+			wantMax:     float64(1234),
+			wantTemp:    0.25,
+			wantTopP:    0.5,
+			wantPresent: []string{"max_output_tokens", "temperature", "top_p"},
+		},
+		{
+			name:        "explicit zero temperature forwarded",
+			model:       "gpt-4.1",
+			req:         Request{Temperature: 0, TemperatureSet: true},
+			wantTemp:    float64(0),
+			wantPresent: []string{"temperature"},
+		},
+		{
+			name:        "reasoning model drops sampling but keeps max tokens",
+			model:       "gpt-5.5-medium",
+			req:         Request{MaxOutputTokens: 2048, Temperature: 0.7, TopP: 0.9},
+			wantMax:     float64(2048),
+			wantPresent: []string{"max_output_tokens"},
+			wantAbsent:  []string{"temperature", "top_p"},
+		},
+		{
+			name:        "gpt-5 with reasoning disabled accepts sampling",
+			model:       "gpt-5.5",
+			req:         Request{Temperature: 0.3, ReasoningEffort: "none"},
+			wantTemp:    0.3,
+			wantPresent: []string{"temperature"},
+		},
+		{
+			name:       "o-series drops sampling",
+			model:      "o4-mini",
+			req:        Request{Temperature: 0.3, TopP: 0.4},
+			wantAbsent: []string{"temperature", "top_p"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := captureChatGPTRequestPayload(t, tt.model, tt.req)
+			for _, key := range tt.wantAbsent {
+				if v, ok := payload[key]; ok {
+					t.Fatalf("payload contains %s = %#v, want omitted", key, v)
+				}
+			}
+			for _, key := range tt.wantPresent {
+				if _, ok := payload[key]; !ok {
+					t.Fatalf("payload missing %s: %#v", key, payload)
+				}
+			}
+			if tt.wantMax != nil && payload["max_output_tokens"] != tt.wantMax {
+				t.Fatalf("max_output_tokens = %#v, want %#v", payload["max_output_tokens"], tt.wantMax)
+			}
+			if tt.wantTemp != nil {
+				got, _ := payload["temperature"].(float64)
+				if diff := got - tt.wantTemp.(float64); diff > 1e-6 || diff < -1e-6 {
+					t.Fatalf("temperature = %#v, want %#v", payload["temperature"], tt.wantTemp)
+				}
+			}
+			if tt.wantTopP != nil {
+				got, _ := payload["top_p"].(float64)
+				if diff := got - tt.wantTopP.(float64); diff > 1e-6 || diff < -1e-6 {
+					t.Fatalf("top_p = %#v, want %#v", payload["top_p"], tt.wantTopP)
+				}
+			}
+		})
+	}
+}
+
+func TestChatGPTStream_SendsResponseFormatAsTextFormat(t *testing.T) {
+	origClient := chatGPTHTTPClient
+	defer func() { chatGPTHTTPClient = origClient }()
+
+	var captured ResponsesRequest
+	chatGPTHTTPClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if err := json.NewDecoder(req.Body).Decode(&captured); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(strings.NewReader(strings.Join([]string{
+				`event: response.completed`,
+				`data: {"type":"response.completed","response":{"usage":{"input_tokens":1,"output_tokens":1,"total_tokens":2}}}`,
+				`data: [DONE]`,
+			}, "\n"))),
+			Header: make(http.Header),
+		}, nil
+	})}
+
+	provider := NewChatGPTProviderWithCreds(&credentials.ChatGPTCredentials{
+		AccessToken: "test-token",
+		AccountID:   "test-account",
+		ExpiresAt:   time.Now().Add(1 * time.Hour).Unix(),
+	}, "gpt-5.5-medium")
+	if !provider.Capabilities().StructuredOutput {
+		t.Fatal("ChatGPT provider should declare structured output support")
+	}
+
+	stream, err := provider.Stream(context.Background(), Request{
+		Messages:       []Message{UserText("hello")},
+		ResponseFormat: &ResponseFormat{Type: ResponseFormatJSONObject},
+	})
+	if err != nil {
+		t.Fatalf("stream creation failed: %v", err)
+	}
+	defer stream.Close()
+	drainStreamToDone(t, stream)
+
+	if captured.Text == nil || captured.Text.Format == nil || captured.Text.Format.Type != "json_object" {
+		t.Fatalf("text = %+v, want json_object format", captured.Text)
+	}
+	if captured.Text.Format.Name != "" || captured.Text.Format.Schema != nil {
+		t.Fatalf("json_object format should carry no schema: %+v", captured.Text.Format)
+	}
+}
@@ -512,6 +512,7 @@ func (p *ClaudeBinProvider) userFacingClaudeCommandError(err *ClaudeCommandError
 
 	combined := strings.ToLower(strings.TrimSpace(err.StderrTail + "\n" + err.StdoutTail))
 	summary := fmt.Sprintf("Claude Code exited before completing the turn (exit %d)", err.ExitCode)
+	var kind error
 	switch {
 	case strings.Contains(combined, "cannot be used with root/sudo privileges"):
 		summary = "Claude Code refused permission bypass while running as root"
@@ -519,7 +520,8 @@ func (p *ClaudeBinProvider) userFacingClaudeCommandError(err *ClaudeCommandError
 			detail = "term-llm should set IS_SANDBOX=1 for root claude-bin runs"
 		}
 	case strings.Contains(combined, "not logged in") || strings.Contains(combined, "please run /login"):
-		summary = "Claude Code is not logged in"
+		summary = "Claude Code is not logged in (run `claude login`)"
+		kind = ErrCLINotAuthenticated
 	case strings.Contains(combined, "bypasspermissions") &&
 		(strings.Contains(combined, "policy") || strings.Contains(combined, "managed") || strings.Contains(combined, "disabled")):
 		summary = "Claude Code managed policy blocked permission bypass"
@@ -532,6 +534,7 @@ func (p *ClaudeBinProvider) userFacingClaudeCommandError(err *ClaudeCommandError
 		Summary: summary,
 		Detail:  detail,
 		Cause:   err,
+		Kind:    kind,
 	}
 }
 
@@ -685,6 +688,14 @@ func (p *ClaudeBinProvider) runClaudeCommand(
 	}
 
 	if err := cmd.Start(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return &UserFacingProviderError{
+				Summary: "Claude Code CLI not found",
+				Detail:  "install it (npm install -g @anthropic-ai/claude-code) and make sure `claude` is on your PATH",
+				Cause:   err,
+				Kind:    ErrCLINotInstalled,
+			}
+		}
 		return fmt.Errorf("failed to start claude: %w", err)
 	}
 
@@ -11,6 +11,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/samsaffron/term-llm/internal/procutil"
 )
 
 func TestClaudeBinProvider_ImplementsToolExecutorSetter(t *testing.T) {
@@ -148,6 +150,72 @@ func TestClaudeBinProvider_CleanupTurn_RemovesTrackedTempFiles(t *testing.T) {
 	}
 }
 
+func TestClaudeBinProvider_ProcessToolResultContent_MaterializesImages(t *testing.T) {
+	provider := NewClaudeBinProvider("sonnet", nil)
+	result := &ToolResult{
+		Name: "view_image",
+		ContentParts: []ToolContentPart{
+			{Type: ToolContentPartText, Text: "Image loaded"},
+			{Type: ToolContentPartImageData, ImageData: &ToolImageData{MediaType: "image/png", Base64: "aGVsbG8="}},
+		},
+	}
+
+	content := provider.processToolResultContent(result)
+	lines := strings.Split(content, "\n")
+	if len(lines) != 2 || lines[0] != "Image loaded" {
+		t.Fatalf("content = %q, want text line followed by image path", content)
+	}
+	path := lines[1]
+	if !strings.HasSuffix(path, ".png") {
+		t.Fatalf("image path = %q, want .png extension", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read materialized image: %v", err)
+	}
+	if string(data) != "hello" {
+		t.Fatalf("image bytes = %q, want %q", data, "hello")
+	}
+
+	provider.CleanupTurn()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected image temp file to be removed after turn, got err=%v", err)
+	}
+}
+
+func TestClaudeBinProvider_ProcessToolResultContent_DropsUnusableImages(t *testing.T) {
+	origMax := maxCLIImageTempFileBytes
+	maxCLIImageTempFileBytes = 4
+	t.Cleanup(func() { maxCLIImageTempFileBytes = origMax })
+
+	tests := []struct {
+		name   string
+		base64 string
+	}{
+		{name: "invalid base64", base64: "not base64!!"},
+		{name: "over size cap", base64: "aGVsbG8="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewClaudeBinProvider("sonnet", nil)
+			result := &ToolResult{
+				Name: "view_image",
+				ContentParts: []ToolContentPart{
+					{Type: ToolContentPartText, Text: "Image loaded"},
+					{Type: ToolContentPartImageData, ImageData: &ToolImageData{MediaType: "image/png", Base64: tt.base64}},
+				},
+			}
+
+			if got := provider.processToolResultContent(result); got != "Image loaded" {
+				t.Fatalf("content = %q, want image stripped", got)
+			}
+			if len(provider.tempFiles) != 0 {
+				t.Fatalf("expected no tracked temp files, got %v", provider.tempFiles)
+			}
+		})
+	}
+}
+
 func TestClaudeBinProvider_CleanupTurn_DoesNotStopMCPServer(t *testing.T) {
 	provider := NewClaudeBinProvider("sonnet", nil)
 	// Simulate a mid-conversation state by populating the config path field.
@@ -179,7 +247,7 @@ func TestClaudeBinProvider_prepareClaudeCommand_ConfiguresProcessGroupWaitDelayA
 	if cmd.WaitDelay != claudeCommandWaitDelay {
 		t.Fatalf("WaitDelay = %v, want %v", cmd.WaitDelay, claudeCommandWaitDelay)
 	}
-	if cmd.SysProcAttr == nil || !cmd.SysProcAttr.Setpgid {
+	if !procutil.InOwnProcessGroup(cmd) {
 		t.Fatal("expected claude subprocess to run in its own process group")
 	}
 	if cmd.Cancel == nil {
@@ -1285,6 +1353,81 @@ func TestClaudeBinProvider_UserFacingCommandErrorKeepsDiagnosticsOutOfErrorStrin
 	}
 }
 
+func TestClaudeBinProvider_StreamClassifiesPermanentCLIFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		wantKind error
+		wantMsg  string
+	}{
+		{
+			name:     "binary not on PATH",
+			wantKind: ErrCLINotInstalled,
+			wantMsg:  "Claude Code CLI not found",
+		},
+		{
+			name: "not logged in",
+			script: `#!/bin/sh
+cat > /dev/null
+echo "Invalid API key · Please run /login" >&2
+exit 1
+`,
+			wantKind: ErrCLINotAuthenticated,
+			wantMsg:  "claude login",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.script != "" {
+				if err := os.WriteFile(filepath.Join(dir, "claude"), []byte(tt.script), 0o755); err != nil {
+					t.Fatalf("write fake claude: %v", err)
+				}
+			}
+			t.Setenv("PATH", dir)
+
+			provider := NewClaudeBinProvider("sonnet", nil)
+			err := firstClaudeBinStreamError(t, provider, Request{
+				Ephemeral: true,
+				Messages:  []Message{UserText("hello")},
+			})
+			if err == nil {
+				t.Fatal("expected stream error")
+			}
+			if !errors.Is(err, tt.wantKind) {
+				t.Fatalf("error %v does not match %v", err, tt.wantKind)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Fatalf("error %q does not mention %q", err.Error(), tt.wantMsg)
+			}
+			if isRetryable(err) {
+				t.Fatalf("expected %v to be non-retryable", err)
+			}
+		})
+	}
+}
+
+func firstClaudeBinStreamError(t *testing.T, provider *ClaudeBinProvider, req Request) error {
+	t.Helper()
+	stream, err := provider.Stream(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if event.Type == EventError {
+			return event.Err
+		}
+	}
+}
+
 func TestClaudeBinProvider_CommandErrorDebugFields(t *testing.T) {
 	t.Setenv("ANTHROPIC_API_KEY", "secret")
 	p := NewClaudeBinProvider("opus-max", map[string]string{
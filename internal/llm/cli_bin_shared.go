@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -48,12 +49,22 @@ func newCLICommand(ctx context.Context, binary string, args []string, workingDir
 	return cmd, nil
 }
 
+// ErrCLINotInstalled and ErrCLINotAuthenticated classify local CLI provider
+// failures that retrying cannot fix. Match them with errors.Is.
+var (
+	ErrCLINotInstalled     = errors.New("CLI binary not installed")
+	ErrCLINotAuthenticated = errors.New("CLI not authenticated")
+)
+
 // UserFacingProviderError keeps detailed subprocess diagnostics available to
 // debug logging while presenting a concise error to users.
 type UserFacingProviderError struct {
 	Summary string
 	Detail  string
 	Cause   error
+	// Kind optionally classifies the failure (e.g. ErrCLINotAuthenticated) so
+	// callers can match it with errors.Is without parsing the message.
+	Kind error
 }
 
 func (e *UserFacingProviderError) Error() string {
@@ -73,6 +84,11 @@ func (e *UserFacingProviderError) Unwrap() error {
 	return e.Cause
 }
 
+// Is reports whether target is the error's classification Kind.
+func (e *UserFacingProviderError) Is(target error) bool {
+	return e != nil && e.Kind != nil && target == e.Kind
+}
+
 func (e *UserFacingProviderError) DebugFields() map[string]any {
 	if e == nil || e.Cause == nil {
 		return nil
@@ -380,9 +396,25 @@ func (t *tempFileTracker) trackTempFile(path string) string {
 	return path
 }
 
+// maxCLIImageTempFileBytes caps the decoded size of a single image written to
+// disk for a CLI provider. Larger images are dropped from the prompt rather than
+// handed to the CLI, which would reject or truncate them anyway.
+var maxCLIImageTempFileBytes = 20 << 20
+
+// imageDataToTempFile decodes base64 image data into a tracked temp file and
+// returns its path. It returns "" when the data is empty, fails to decode, or
+// exceeds maxCLIImageTempFileBytes; callers then omit the image from the prompt.
 func (t *tempFileTracker) imageDataToTempFile(mediaType, base64Data string) string {
+	if base64Data == "" {
+		return ""
+	}
+	if base64.StdEncoding.DecodedLen(len(base64Data)) > maxCLIImageTempFileBytes {
+		t.warnSkippedImage("image exceeds size cap", "encoded_bytes", len(base64Data))
+		return ""
+	}
 	raw, err := base64.StdEncoding.DecodeString(base64Data)
 	if err != nil {
+		t.warnSkippedImage("image data failed to decode", "err", err)
 		return ""
 	}
 	ext := mediaTypeToExt(mediaType)
@@ -398,6 +430,17 @@ func (t *tempFileTracker) imageDataToTempFile(mediaType, base64Data string) stri
 	return t.trackTempFile(f.Name())
 }
 
+func (t *tempFileTracker) displayName() string {
+	if t.logName == "" {
+		return "CLI provider"
+	}
+	return t.logName
+}
+
+func (t *tempFileTracker) warnSkippedImage(reason string, args ...any) {
+	slog.Warn(t.displayName()+" omitted image from prompt: "+reason, args...)
+}
+
 func (t *tempFileTracker) finishStreamCleanup() {
 	if t.activeRuns.Add(-1) == 0 {
 		t.cleanupTempFiles()
@@ -418,11 +461,7 @@ func (t *tempFileTracker) cleanupTempFiles() {
 
 	for _, path := range paths {
 		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
-			name := t.logName
-			if name == "" {
-				name = "CLI provider"
-			}
-			slog.Warn(name+" failed to remove temp file", "path", path, "err", err)
+			slog.Warn(t.displayName()+" failed to remove temp file", "path", path, "err", err)
 		}
 	}
 }
@@ -32,6 +32,7 @@ type CompactionConfig struct {
 	SoftThresholdRatio   float64 // Fraction where we try to checkpoint and compact cleanly (default 0.90)
 	HardThresholdRatio   float64 // Fraction where we must compact before the next tool/LLM continuation (default 0.95)
 	MaxToolResultChars   int     // Max chars per tool result when recording
+	JSONToolResults      bool    // Truncate JSON tool results structurally (keys kept, strings/arrays shortened) instead of head/tail
 	SummaryTokenBudget   int     // Max output tokens for the compaction summary
 	RecentRawTokenBudget int     // Max tokens of recent raw transcript to carry after compaction (0 = auto, <0 = disabled)
 	RecentRawTurns       int     // Max recent user turns to try preserving raw (0 = default, <0 = disabled)
@@ -45,6 +46,7 @@ func DefaultCompactionConfig() CompactionConfig {
 		SoftThresholdRatio:   defaultSoftThresholdRatio,
 		HardThresholdRatio:   defaultHardThresholdRatio,
 		MaxToolResultChars:   defaultMaxToolResultChars,
+		JSONToolResults:      true,
 		SummaryTokenBudget:   defaultSummaryTokenBudget,
 		RecentRawTokenBudget: 0, // auto-size from provider input window, capped at 8k
 		RecentRawTurns:       defaultRecentRawTurns,
@@ -121,33 +123,47 @@ func EstimateTokens(text string) int {
 // EstimateMessageTokens returns an approximate token count for a slice of
 // messages by summing all text content across parts.
 func EstimateMessageTokens(msgs []Message) int {
+	return EstimateModelMessageTokens("", msgs)
+}
+
+// EstimateModelMessageTokens is EstimateMessageTokens counted with model's
+// tokenizer when one is available (see CountTokens), falling back to the
+// byte heuristic otherwise.
+func EstimateModelMessageTokens(model string, msgs []Message) int {
 	if len(msgs) == 0 {
 		return 0
 	}
+	count := tokenCounterForModel(model)
 	total := 0
 	for _, msg := range msgs {
-		total += estimateSingleMessageTokens(msg)
+		total += countMessageTokens(msg, count)
 	}
 	return total
 }
 
 func estimateSingleMessageTokens(msg Message) int {
+	return countMessageTokens(msg, EstimateTokens)
+}
+
+func countMessageTokens(msg Message, count func(string) int) int {
 	if msg.Role == RoleEvent || len(msg.Parts) == 0 {
 		return 0
 	}
 	total := 0
 	for _, part := range msg.Parts {
-		total += EstimateTokens(part.Text)
-		total += EstimateTokens(part.ReasoningContent)
+		total += count(part.Text)
+		total += count(part.ReasoningContent)
 		for _, summaryPart := range part.ReasoningSummaryParts {
-			total += EstimateTokens(summaryPart)
+			total += count(summaryPart)
 		}
+		// Encrypted reasoning is opaque to any tokenizer; keep it on the
+		// byte heuristic.
 		total += EstimateTokens(part.ReasoningEncryptedContent)
 		if part.ToolCall != nil {
-			total += EstimateTokens(string(part.ToolCall.Arguments))
+			total += count(string(part.ToolCall.Arguments))
 		}
 		if part.ToolResult != nil {
-			total += EstimateTokens(part.ToolResult.Content)
+			total += count(part.ToolResult.Content)
 		}
 	}
 	return total
@@ -158,8 +174,15 @@ func estimateSingleMessageTokens(msg Message) int {
 // true structured messages after the summary. Keeping the split in one place
 // prevents hard/soft modes from drifting and avoids duplicating recent context
 // inside the extractive <PREVIOUS_TURNS> block.
+//
+// Pinned user messages that fall in the summarized prefix are lifted out and
+// replayed verbatim between the summary and the raw suffix. Their tokens are
+// charged against the raw-suffix budget so pinning cannot blow the input limit.
+// A pinned message inside the raw suffix is already counted there, so it is
+// charged only once.
 type preparedCompactionContext struct {
 	SummaryMessages []Message
+	PinnedMessages  []Message
 	RecentMessages  []Message
 }
 
@@ -168,7 +191,12 @@ func prepareCompactionContext(messages []Message, config CompactionConfig, skipB
 	if len(source) == 0 {
 		return preparedCompactionContext{}
 	}
+	prepared := splitCompactionContext(source, config)
+	prepared.SummaryMessages, prepared.PinnedMessages = extractPinnedMessages(prepared.SummaryMessages)
+	return prepared
+}
 
+func splitCompactionContext(source []Message, config CompactionConfig) preparedCompactionContext {
 	budget := effectiveRecentRawTokenBudget(config)
 	turns := effectiveRecentRawTurns(config)
 	if budget <= 0 || turns <= 0 || len(source) <= 1 {
@@ -195,6 +223,29 @@ func prepareCompactionContext(messages []Message, config CompactionConfig, skipB
 	return preparedCompactionContext{SummaryMessages: summaryMessages, RecentMessages: recent}
 }
 
+// extractPinnedMessages splits pinned user messages out of messages, returning
+// the remainder and sanitized copies of the pinned messages in original order.
+func extractPinnedMessages(messages []Message) ([]Message, []Message) {
+	var rest, pinned []Message
+	for _, msg := range messages {
+		if !isPinnedCompactionMessage(msg) {
+			rest = append(rest, msg)
+			continue
+		}
+		if parts := cloneParts(msg.Parts); len(parts) > 0 {
+			pinned = append(pinned, Message{Role: msg.Role, Parts: parts, Pinned: true})
+		}
+	}
+	if len(pinned) == 0 {
+		return messages, nil
+	}
+	return rest, pinned
+}
+
+func isPinnedCompactionMessage(msg Message) bool {
+	return msg.Pinned && msg.Role == RoleUser && !isInternalCompactionSummaryMessage(msg)
+}
+
 func filterCompactionControlMessages(messages []Message, skipBrief string) []Message {
 	brief := strings.TrimSpace(skipBrief)
 	filtered := FilterConversationMessages(messages)
@@ -273,8 +324,20 @@ func selectRecentRawSuffixStart(messages []Message, budget, maxTurns int) int {
 	for i := len(messages) - 1; i >= 0; i-- {
 		suffixTokens[i] = suffixTokens[i+1] + estimateSingleMessageTokens(messages[i])
 	}
+	// Pinned messages before the split are replayed verbatim after the summary,
+	// so they share the budget with the suffix.
+	pinnedPrefixTokens := make([]int, len(messages)+1)
+	for i, msg := range messages {
+		pinnedPrefixTokens[i+1] = pinnedPrefixTokens[i]
+		if isPinnedCompactionMessage(msg) {
+			pinnedPrefixTokens[i+1] += estimateSingleMessageTokens(msg)
+		}
+	}
+	fits := func(start int) bool {
+		return suffixTokens[start]+pinnedPrefixTokens[start] <= budget
+	}
 	tryStart := func(start int) bool {
-		return start > 0 && start < len(messages) && suffixTokens[start] <= budget
+		return start > 0 && start < len(messages) && fits(start)
 	}
 
 	turnCount := min(maxTurns, len(turnStarts))
@@ -292,7 +355,7 @@ func selectRecentRawSuffixStart(messages []Message, budget, maxTurns int) int {
 		if !isValidRecentRawStart(messages[i]) {
 			continue
 		}
-		if EstimateMessageTokens(messages[i:]) <= budget {
+		if fits(i) {
 			return i
 		}
 	}
@@ -333,7 +396,7 @@ func sanitizeRecentRawSuffix(messages []Message) []Message {
 		if len(parts) == 0 {
 			continue
 		}
-		copyMsg := Message{Role: msg.Role, Parts: parts}
+		copyMsg := Message{Role: msg.Role, Parts: parts, Pinned: msg.Pinned}
 		// The compacted summary is the cache anchor. Do not carry any stale anchor
 		// from a retained raw suffix into the reconstructed context.
 		copyMsg.CacheAnchor = false
@@ -509,7 +572,7 @@ func compactionResultFromBriefPrepared(systemPrompt, brief string, prepared prep
 	combined.WriteString(brief)
 	combined.WriteString(summaryClose)
 	summary := strings.TrimRight(combined.String(), "\n")
-	newMessages := reconstructHistory(systemPrompt, summary, prepared.RecentMessages)
+	newMessages := reconstructHistory(systemPrompt, summary, prepared.PinnedMessages, prepared.RecentMessages)
 	return &CompactionResult{
 		Summary:        summary,
 		NewMessages:    newMessages,
@@ -1854,13 +1917,13 @@ func truncateMessageParts(msg Message, maxChars int) Message {
 }
 
 // reconstructHistory builds the compacted message list:
-// [SystemText(systemPrompt)] + [summary(user, CacheAnchor)] + [ack if needed] + [recent raw context]
+// [SystemText(systemPrompt)] + [summary(user, CacheAnchor)] + [pinned user messages] + [ack if needed] + [recent raw context]
 //
 // The summary message is marked CacheAnchor=true so Anthropic-compatible providers
 // apply cache_control: ephemeral to it, creating a stable cache breakpoint at the
 // summary. This means subsequent turns only pay cold-prefill cost on the delta after
 // the summary, not on the full compacted context.
-func reconstructHistory(systemPrompt, summary string, recentMsgs []Message) []Message {
+func reconstructHistory(systemPrompt, summary string, pinnedMsgs, recentMsgs []Message) []Message {
 	var messages []Message
 
 	if systemPrompt != "" {
@@ -1876,6 +1939,10 @@ func reconstructHistory(systemPrompt, summary string, recentMsgs []Message) []Me
 		CacheAnchor: true,
 	})
 
+	// Pinned messages sit right after the summary so the acknowledgement below
+	// covers them too and the raw suffix still follows in chronological order.
+	messages = append(messages, pinnedMsgs...)
+
 	// Add an assistant acknowledgement unless the retained raw suffix already
 	// starts with an assistant message. This avoids consecutive assistant turns in
 	// the common split-suffix case while still preventing summary-user + recent-user
@@ -1893,6 +1960,10 @@ func reconstructHistory(systemPrompt, summary string, recentMsgs []Message) []Me
 // results, inserting a truncation marker in the middle.
 // Uses rune count to avoid splitting multi-byte UTF-8 characters.
 func TruncateToolResult(content string, maxChars int) string {
+	return truncateToolResultText(content, maxChars, "")
+}
+
+func truncateToolResultText(content string, maxChars int, note string) string {
 	runes := []rune(content)
 	if len(runes) <= maxChars {
 		return content
@@ -1904,32 +1975,14 @@ func TruncateToolResult(content string, maxChars int) string {
 	// Count lines in the truncated middle section to give the LLM more context
 	middle := string(runes[head : len(runes)-tail])
 	lines := 1 + strings.Count(middle, "\n")
-	return string(runes[:head]) + fmt.Sprintf("\n[...%d chars truncated - %d lines...]\n", truncated, lines) + string(runes[len(runes)-tail:])
+	return string(runes[:head]) + toolTruncationMarker(truncated, lines, note) + string(runes[len(runes)-tail:])
 }
 
-// isContextOverflowError checks whether an error indicates that the context
-// window was exceeded. Checks error strings across providers.
-func isContextOverflowError(err error) bool {
-	if err == nil {
-		return false
+// toolTruncationMarker formats the marker inserted where tool output was cut.
+// note, when set, names the limit that was applied.
+func toolTruncationMarker(truncated, lines int, note string) string {
+	if note != "" {
+		return fmt.Sprintf("\n[...%d chars truncated - %d lines (%s)...]\n", truncated, lines, note)
 	}
-	msg := strings.ToLower(err.Error())
-	patterns := []string{
-		"context length exceeded",
-		"maximum context length",
-		"context_length_exceeded",
-		"too many tokens",
-		"request too large",
-		"prompt is too long",
-		"input is too long",
-		"content too large",
-		"token limit",
-		"exceeds the model's maximum context",
-	}
-	for _, p := range patterns {
-		if strings.Contains(msg, p) {
-			return true
-		}
-	}
-	return false
+	return fmt.Sprintf("\n[...%d chars truncated - %d lines...]\n", truncated, lines)
 }
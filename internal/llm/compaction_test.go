@@ -113,7 +113,7 @@ func TestEstimateMessageTokensCountsReasoningReplay(t *testing.T) {
 func TestReconstructHistory(t *testing.T) {
 	recentUser := []Message{UserText("recent question")}
 
-	result := reconstructHistory("system prompt", "summary of conversation", recentUser)
+	result := reconstructHistory("system prompt", "summary of conversation", nil, recentUser)
 
 	// Should be: system + summary + assistant ack + recent user
 	if len(result) != 4 {
@@ -153,7 +153,7 @@ func TestReconstructHistory(t *testing.T) {
 }
 
 func TestReconstructHistoryNoSystem(t *testing.T) {
-	result := reconstructHistory("", "summary", []Message{UserText("q")})
+	result := reconstructHistory("", "summary", nil, []Message{UserText("q")})
 
 	// Without system prompt: summary + ack + user = 3
 	if len(result) != 3 {
@@ -165,7 +165,7 @@ func TestReconstructHistoryNoSystem(t *testing.T) {
 }
 
 func TestReconstructHistoryOmitsAckBeforeAssistantRawSuffix(t *testing.T) {
-	result := reconstructHistory("", "summary", []Message{AssistantText("continuing from retained state")})
+	result := reconstructHistory("", "summary", nil, []Message{AssistantText("continuing from retained state")})
 
 	if len(result) != 2 {
 		t.Fatalf("expected summary + retained assistant, got %d messages", len(result))
@@ -178,6 +178,105 @@ func TestReconstructHistoryOmitsAckBeforeAssistantRawSuffix(t *testing.T) {
 	}
 }
 
+func TestCompactReplaysPinnedMessagesVerbatim(t *testing.T) {
+	provider := NewMockProvider("test")
+	provider.AddTextResponse("Summary.")
+
+	pinned := UserText("API spec: GET /widgets returns {id, name}")
+	pinned.Pinned = true
+	messages := []Message{
+		pinned,
+		AssistantText("Noted the spec."),
+		UserText("build the client"),
+		AssistantText("Client built."),
+		UserText("now add tests"),
+		AssistantText("Tests added."),
+	}
+	config := DefaultCompactionConfig()
+	config.RecentRawTurns = 1
+
+	result, err := Compact(context.Background(), provider, "test-model", "", messages, config)
+	if err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	for _, msg := range provider.Requests[0].Messages {
+		if strings.Contains(MessageText(msg), "API spec") {
+			t.Fatalf("pinned message was sent for summarization: %q", MessageText(msg))
+		}
+	}
+
+	var texts []string
+	for _, msg := range result.NewMessages {
+		texts = append(texts, MessageText(msg))
+	}
+	if len(result.NewMessages) < 3 || !strings.Contains(texts[0], summaryPrefix) {
+		t.Fatalf("expected summary first, got %q", texts)
+	}
+	if got := result.NewMessages[1]; got.Role != RoleUser || !got.Pinned || MessageText(got) != MessageText(pinned) {
+		t.Fatalf("expected pinned message right after summary, got %#v", got)
+	}
+	if last := texts[len(texts)-1]; last != "Tests added." {
+		t.Fatalf("expected recent raw suffix to follow pinned content, got %q", texts)
+	}
+}
+
+func TestPrepareCompactionContextChargesPinnedTokensToRecentBudget(t *testing.T) {
+	pinned := UserText(strings.Repeat("spec ", 40))
+	pinned.Pinned = true
+	messages := []Message{
+		pinned,
+		AssistantText("ok"),
+		UserText("older question"),
+		AssistantText("older answer"),
+		UserText(strings.Repeat("recent ", 100)),
+		AssistantText("recent answer"),
+	}
+	config := CompactionConfig{RecentRawTurns: 2, RecentRawTokenBudget: EstimateMessageTokens(messages[2:])}
+
+	unpinned := prepareCompactionContext(append([]Message{UserText(MessageText(pinned))}, messages[1:]...), config, "")
+	if len(unpinned.RecentMessages) != 4 {
+		t.Fatalf("without pinning expected both recent turns retained, got %d messages", len(unpinned.RecentMessages))
+	}
+
+	prepared := prepareCompactionContext(messages, config, "")
+	if len(prepared.PinnedMessages) != 1 {
+		t.Fatalf("expected 1 pinned message, got %d", len(prepared.PinnedMessages))
+	}
+	if len(prepared.RecentMessages) >= len(unpinned.RecentMessages) {
+		t.Fatalf("expected pinned tokens to shrink the raw suffix, got %d recent messages", len(prepared.RecentMessages))
+	}
+	if got := EstimateMessageTokens(prepared.PinnedMessages) + EstimateMessageTokens(prepared.RecentMessages); got > config.RecentRawTokenBudget {
+		t.Fatalf("pinned + recent tokens = %d, want <= budget %d", got, config.RecentRawTokenBudget)
+	}
+	for _, msg := range prepared.SummaryMessages {
+		if msg.Pinned {
+			t.Fatal("pinned message should not be summarized")
+		}
+	}
+}
+
+func TestPrepareCompactionContextChargesRecentPinnedMessagesOnce(t *testing.T) {
+	recent := UserText(strings.Repeat("recent ", 100))
+	recent.Pinned = true
+	messages := []Message{
+		UserText("old question"),
+		AssistantText("old answer"),
+		UserText("older question"),
+		AssistantText("older answer"),
+		recent,
+		AssistantText("recent answer"),
+	}
+	config := CompactionConfig{RecentRawTurns: 2, RecentRawTokenBudget: EstimateMessageTokens(messages[2:])}
+
+	prepared := prepareCompactionContext(messages, config, "")
+	if len(prepared.PinnedMessages) != 0 {
+		t.Fatalf("a pinned message in the raw suffix should not be replayed again, got %d", len(prepared.PinnedMessages))
+	}
+	if len(prepared.RecentMessages) != 4 {
+		t.Fatalf("a pinned message in the raw suffix should only be counted once; got %d recent messages, want 4", len(prepared.RecentMessages))
+	}
+}
+
 func TestCompactionPromptGuardsAgainstInventedStopRequest(t *testing.T) {
 	provider := NewMockProvider("test")
 	provider.AddTextResponse("Summary.")
@@ -214,7 +313,7 @@ func TestCompactionPromptGuardsAgainstInventedStopRequest(t *testing.T) {
 }
 
 func TestSummaryPrefixMarksCompactionAsInternalNotStop(t *testing.T) {
-	result := reconstructHistory("", "summary", nil)
+	result := reconstructHistory("", "summary", nil, nil)
 	if len(result) < 1 {
 		t.Fatal("reconstructHistory returned no messages")
 	}
@@ -6,8 +6,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
-	"os"
 	"strings"
 	"time"
 
@@ -15,7 +15,6 @@ import (
 	"github.com/samsaffron/term-llm/internal/credentials"
 	"github.com/samsaffron/term-llm/internal/oauth"
 	"github.com/samsaffron/term-llm/internal/signal"
-	"golang.org/x/term"
 )
 
 var copilotDefaultModel = config.DefaultProviderModel("copilot") // Broadly available Copilot model
@@ -24,8 +23,17 @@ var copilotDefaultModel = config.DefaultProviderModel("copilot") // Broadly avai
 // Business/Enterprise accounts use api.business.githubcopilot.com instead
 const copilotDefaultAPIURL = "https://api.githubcopilot.com"
 
-// copilotTokenURL is the endpoint to exchange GitHub OAuth token for Copilot session token
-const copilotTokenURL = "https://api.github.com/copilot_internal/v2/token"
+// copilotBusinessAPIURL is the API base URL for Business/Enterprise plans.
+const copilotBusinessAPIURL = "https://api.business.githubcopilot.com"
+
+// copilotAPIBaseCandidates are the API base URLs probed, in order, when the
+// token exchange does not say which one the account uses. A variable so
+// tests can point it at fake hosts.
+var copilotAPIBaseCandidates = []string{copilotDefaultAPIURL, copilotBusinessAPIURL}
+
+// copilotTokenURL is the endpoint to exchange GitHub OAuth token for Copilot session token.
+// A variable so tests can point it at a fake token endpoint.
+var copilotTokenURL = "https://api.github.com/copilot_internal/v2/token"
 
 // Copilot API header constants.
 // These values are required to access GitHub's internal Copilot APIs, which check
@@ -54,10 +62,14 @@ type CopilotProvider struct {
 	model              string
 	effort             string            // reasoning effort: "low", "medium", "high", "xhigh", or ""
 	apiBaseURL         string            // Set from token exchange (business vs individual)
+	probeAPIBase       bool              // apiBaseURL is a guess; confirm it before the first request
 	sessionToken       string            // Copilot session token (different from OAuth token)
 	sessionTokenExpiry time.Time         // When the session token expires
 	fileUploadPolicy   *FileUploadPolicy // Provider-specific native file forwarding policy
 	responsesClient    *ResponsesClient  // Shared client for Responses API (GPT-5+, codex)
+	// noEncryptedReasoning stops requesting reasoning.encrypted_content and
+	// resending reasoning items (providers.copilot.encrypted_reasoning: false).
+	noEncryptedReasoning bool
 }
 
 // NewCopilotProvider creates a new Copilot provider.
@@ -68,23 +80,17 @@ func NewCopilotProvider(model string) (*CopilotProvider, error) {
 	}
 	actualModel, effort := ParseModelEffort(model)
 
-	// Try to load existing credentials
+	// Try to load existing credentials, signing in again when they are
+	// missing or expired (rare for GitHub tokens, but check anyway)
 	creds, err := credentials.GetCopilotCredentials()
-	if err != nil {
-		// No credentials - prompt user to authenticate
-		creds, err = PromptForCopilotAuth()
-		if err != nil {
-			return nil, err
-		}
+	switch {
+	case err != nil:
+		creds, err = promptForCopilotAuth("Copilot is not signed in")
+	case creds.IsExpired():
+		creds, err = promptForCopilotAuth("Copilot token expired")
 	}
-
-	// Check if token is expired (rare for GitHub tokens, but check anyway)
-	if creds.IsExpired() {
-		fmt.Println("Copilot token expired. Re-authentication required.")
-		creds, err = PromptForCopilotAuth()
-		if err != nil {
-			return nil, err
-		}
+	if err != nil {
+		return nil, err
 	}
 
 	return &CopilotProvider{
@@ -113,13 +119,18 @@ func NewCopilotProviderWithCreds(creds *credentials.CopilotCredentials, model st
 // Exported so `term-llm auth login copilot` can drive the same flow used by
 // lazy auth.
 func PromptForCopilotAuth() (*credentials.CopilotCredentials, error) {
-	// Check if stdin is a terminal - if not, we can't do interactive auth
-	if !term.IsTerminal(int(os.Stdin.Fd())) {
-		return nil, fmt.Errorf("Copilot authentication required but running in non-interactive mode.\n" +
-			"Run 'term-llm auth login copilot' interactively first to authenticate")
+	return promptForCopilotAuth("GitHub Copilot provider requires authentication")
+}
+
+// promptForCopilotAuth runs the device code flow after printing reason, or
+// fails fast with a pointer to `term-llm auth login` when stdin is not a
+// terminal.
+func promptForCopilotAuth(reason string) (*credentials.CopilotCredentials, error) {
+	if !canPromptForAuth() {
+		return nil, authLoginRequired("copilot", reason)
 	}
 
-	fmt.Println("GitHub Copilot provider requires authentication.")
+	fmt.Println(reason + ".")
 	fmt.Print("Press Enter to start device code authentication...")
 
 	if err := waitForEnterOrInterrupt(); err != nil {
@@ -138,15 +149,9 @@ func PromptForCopilotAuth() (*credentials.CopilotCredentials, error) {
 		return nil, fmt.Errorf("authentication failed: %w", err)
 	}
 
-	// Convert oauth credentials to stored credentials format
-	creds := &credentials.CopilotCredentials{
-		AccessToken: oauthCreds.AccessToken,
-		ExpiresAt:   oauthCreds.ExpiresAt,
-	}
-
-	// Save credentials
-	if err := credentials.SaveCopilotCredentials(creds); err != nil {
-		return nil, fmt.Errorf("failed to save credentials: %w", err)
+	creds, err := saveCopilotOAuthCredentials(oauthCreds)
+	if err != nil {
+		return nil, err
 	}
 
 	fmt.Println("Authentication successful!")
@@ -206,7 +211,7 @@ func (p *CopilotProvider) Stream(ctx context.Context, req Request) (Stream, erro
 	req.MaxOutputTokens = ClampOutputTokens(req.MaxOutputTokens, chooseModel(req.Model, p.model))
 	// Check if OAuth token is expired
 	if p.creds.IsExpired() {
-		return nil, fmt.Errorf("Copilot token expired (re-run with --provider copilot to re-authenticate)")
+		return nil, authLoginRequired("copilot", "Copilot token expired")
 	}
 
 	// Ensure we have a valid session token (refresh if expired or not initialized)
@@ -326,7 +331,7 @@ func (p *CopilotProvider) streamChatCompletions(ctx context.Context, req Request
 			}
 
 			if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
-				return fmt.Errorf("Copilot authentication failed (status %d): token may be invalid or expired. Re-run with --provider copilot to re-authenticate", resp.StatusCode)
+				return authLoginRequired("copilot", fmt.Sprintf("Copilot authentication failed (status %d): token may be invalid or expired", resp.StatusCode))
 			}
 			return newHTTPStatusError("Copilot", resp, respBody)
 		}
@@ -459,7 +464,7 @@ func (p *CopilotProvider) streamResponses(ctx context.Context, req Request, mode
 				if clearErr := credentials.ClearCopilotCredentials(); clearErr != nil {
 					return fmt.Errorf("Copilot session expired and failed to clear credentials: %w", clearErr)
 				}
-				return fmt.Errorf("Copilot session expired — please re-run your command to re-authenticate")
+				return authLoginRequired("copilot", "Copilot session expired")
 			},
 		}
 	}
@@ -467,15 +472,22 @@ func (p *CopilotProvider) streamResponses(ctx context.Context, req Request, mode
 	// Update auth header in case session token was refreshed
 	p.responsesClient.GetAuthHeader = func() string { return "Bearer " + p.sessionToken }
 
+	input := BuildResponsesInputWithFilePolicy(req.Messages, p.effectiveFileUploadPolicy())
+	include := []string{"reasoning.encrypted_content"}
+	if p.noEncryptedReasoning {
+		input = stripResponsesReasoningItems(input)
+		include = nil
+	}
 	responsesReq := ResponsesRequest{
 		Model:            model,
-		Input:            BuildResponsesInputWithFilePolicy(req.Messages, p.effectiveFileUploadPolicy()),
+		Input:            input,
 		FileUploadPolicy: p.effectiveFileUploadPolicy(),
 		Tools:            BuildResponsesTools(req.Tools),
-		Include:          []string{"reasoning.encrypted_content"},
+		Include:          include,
 		PromptCacheKey:   req.SessionID,
 		Stream:           true,
 		SessionID:        req.SessionID,
+		Ephemeral:        req.Ephemeral,
 	}
 
 	if req.ToolChoice.Mode != "" {
@@ -524,13 +536,20 @@ type copilotModel struct {
 	// Limit fields are intentionally accepted in several shapes. Current Copilot
 	// responses use capabilities.limits, but accepting flat/top-level variants
 	// keeps the parser resilient if GitHub moves fields again.
-	Limits           copilotModelLimits `json:"limits"`
-	MaxPromptTokens  int                `json:"max_prompt_tokens"`
-	MaxInputTokens   int                `json:"max_input_tokens"`
-	InputTokenLimit  int                `json:"input_token_limit"`
-	ContextWindow    int                `json:"context_window"`
-	MaxContextWindow int                `json:"max_context_window_tokens"`
-	MaxOutputTokens  int                `json:"max_output_tokens"`
+	Limits           copilotModelLimits  `json:"limits"`
+	MaxPromptTokens  int                 `json:"max_prompt_tokens"`
+	MaxInputTokens   int                 `json:"max_input_tokens"`
+	InputTokenLimit  int                 `json:"input_token_limit"`
+	ContextWindow    int                 `json:"context_window"`
+	MaxContextWindow int                 `json:"max_context_window_tokens"`
+	MaxOutputTokens  int                 `json:"max_output_tokens"`
+	Billing          copilotModelBilling `json:"billing"`
+}
+
+// copilotModelBilling describes premium request usage for a model.
+type copilotModelBilling struct {
+	IsPremium  bool    `json:"is_premium"`
+	Multiplier float64 `json:"multiplier"`
 }
 
 type copilotCapabilities struct {
@@ -548,6 +567,18 @@ type copilotModelLimits struct {
 
 const copilotPracticalOutputReserve = 20_000
 
+// premiumMultiplier returns how many premium requests one call consumes, or 0
+// when the model is included in the plan.
+func (m copilotModel) premiumMultiplier() float64 {
+	if !m.Billing.IsPremium {
+		return 0
+	}
+	if m.Billing.Multiplier > 0 {
+		return m.Billing.Multiplier
+	}
+	return 1
+}
+
 func (m copilotModel) inputLimit() int {
 	// Prefer an explicit prompt/input budget from Copilot. That is the source of
 	// truth and avoids reverse-engineering context math.
@@ -654,6 +685,8 @@ func (p *CopilotProvider) ListModels(ctx context.Context) ([]ModelInfo, error) {
 			DisplayName: displayName,
 			OwnedBy:     m.Vendor,
 			InputLimit:  m.inputLimit(),
+
+			PremiumMultiplier: m.premiumMultiplier(),
 		})
 	}
 	RefreshCopilotCacheSync(models)
@@ -689,7 +722,12 @@ func (e *copilotAPIError) Is404() bool {
 func (p *CopilotProvider) ensureValidSession(ctx context.Context) error {
 	// Check if we need to refresh (no token, missing expiry, or close to expiry).
 	if p.sessionToken == "" || p.sessionTokenExpiry.IsZero() || time.Until(p.sessionTokenExpiry) <= copilotSessionRefreshSkew {
-		return p.refreshSession(ctx)
+		if err := p.refreshSession(ctx); err != nil {
+			return err
+		}
+	}
+	if p.probeAPIBase {
+		return p.discoverAPIBase(ctx)
 	}
 	return nil
 }
@@ -700,10 +738,18 @@ func (p *CopilotProvider) refreshSession(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	if tokenResp.Endpoints.API != "" {
+	switch {
+	case tokenResp.Endpoints.API != "":
 		p.apiBaseURL = tokenResp.Endpoints.API
-	} else {
+	case p.creds.APIBaseURL != "":
+		// Discovered on an earlier run; see discoverAPIBase.
+		p.apiBaseURL = p.creds.APIBaseURL
+	case p.apiBaseURL == "":
 		p.apiBaseURL = copilotDefaultAPIURL
+		p.probeAPIBase = true
+	}
+	if p.apiBaseURL != "" && p.responsesClient != nil && p.responsesClient.BaseURL != p.apiBaseURL+"/responses" {
+		p.responsesClient = nil
 	}
 	p.sessionToken = tokenResp.Token
 	// ExpiresAt is a Unix timestamp
@@ -711,6 +757,68 @@ func (p *CopilotProvider) refreshSession(ctx context.Context) error {
 	return nil
 }
 
+// discoverAPIBase finds the API host for an account whose token exchange
+// did not name one. Individual plans are served from the default host and
+// Business/Enterprise plans from the business host; the other host answers
+// 401 or 404. The first host that lists models wins and is saved with the
+// credentials so later runs skip the probe.
+func (p *CopilotProvider) discoverAPIBase(ctx context.Context) error {
+	var failures []string
+	for _, base := range copilotAPIBaseCandidates {
+		status, err := p.probeModels(ctx, base)
+		if err != nil {
+			return err
+		}
+		switch status {
+		case http.StatusOK:
+			p.useDiscoveredAPIBase(base)
+			return nil
+		case http.StatusUnauthorized, http.StatusNotFound:
+			failures = append(failures, fmt.Sprintf("%s (HTTP %d)", base, status))
+		default:
+			// Not an endpoint mismatch; keep the default host and let the
+			// real request report the problem.
+			p.probeAPIBase = false
+			return nil
+		}
+	}
+	return fmt.Errorf("Copilot API not available at %s: check that your account has an active Copilot plan, or sign in again with 'term-llm auth login copilot'",
+		strings.Join(failures, " or "))
+}
+
+// probeModels asks base for the model list and returns the status code.
+func (p *CopilotProvider) probeModels(ctx context.Context, base string) (int, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", base+"/models", nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	p.setCopilotAPIHeaders(httpReq, p.sessionToken)
+	httpReq.Header.Set("Accept", "application/json")
+
+	resp, err := copilotHTTPClient.Do(httpReq)
+	if err != nil {
+		return 0, fmt.Errorf("Copilot API request to %s failed: %w", base, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+// useDiscoveredAPIBase switches to base and remembers it in the stored
+// credentials. Saving is best effort: if it fails the next run probes again.
+func (p *CopilotProvider) useDiscoveredAPIBase(base string) {
+	p.apiBaseURL = base
+	p.probeAPIBase = false
+	p.responsesClient = nil
+	if p.creds.APIBaseURL == base {
+		return
+	}
+	p.creds.APIBaseURL = base
+	if err := credentials.SaveCopilotCredentials(p.creds); err != nil {
+		slog.Debug("failed to save discovered Copilot API base URL", "base_url", base, "error", err)
+	}
+}
+
 // setCopilotAPIHeaders sets the standard headers required for Copilot API requests.
 func (p *CopilotProvider) setCopilotAPIHeaders(req *http.Request, token string) {
 	req.Header.Set("Content-Type", "application/json")
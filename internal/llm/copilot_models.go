@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/samsaffron/term-llm/internal/cache"
+	"github.com/samsaffron/term-llm/internal/config"
 )
 
 const copilotModelCacheKey = "copilot"
@@ -56,6 +57,32 @@ func copilotCachedInputLimit(model string) int {
 	return 0
 }
 
+// CopilotPremiumMultiplier reports how many premium requests a call to model
+// consumes through provider, using the cached Copilot model list. known is
+// false when provider is not a Copilot provider or the model is not cached.
+func CopilotPremiumMultiplier(provider, model string) (multiplier float64, known bool) {
+	if resolveProviderType(provider) != string(config.ProviderTypeCopilot) {
+		return 0, false
+	}
+	model = strings.ToLower(strings.TrimSpace(model))
+	models := GetCachedCopilotModelInfos()
+	lookup := func(id string) (float64, bool) {
+		for _, m := range models {
+			if strings.ToLower(strings.TrimSpace(m.ID)) == id {
+				return m.PremiumMultiplier, true
+			}
+		}
+		return 0, false
+	}
+	if multiplier, ok := lookup(model); ok {
+		return multiplier, true
+	}
+	if base, ok := trimKnownEffortSuffix(model); ok {
+		return lookup(base)
+	}
+	return 0, false
+}
+
 // RefreshCopilotCacheSync stores a freshly fetched Copilot model list for
 // completions and offline provider/model pickers.
 func RefreshCopilotCacheSync(models []ModelInfo) {
@@ -2,6 +2,7 @@ package llm
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -166,3 +167,229 @@ func TestCopilotListModelsFetchesLiveModelsAndCachesThem(t *testing.T) {
 		t.Fatalf("cached copilot gpt-5.5 input limit = %d, want 1030000", got)
 	}
 }
+
+func TestCopilotResponsesStreamsReasoningSummaryBeforeText(t *testing.T) {
+	origClient := copilotHTTPClient
+	t.Cleanup(func() { copilotHTTPClient = origClient })
+
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/responses" {
+			t.Fatalf("unexpected path %q", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, strings.Join([]string{
+			`event: response.output_item.added`,
+			`data: {"type":"response.output_item.added","output_index":0,"item":{"type":"reasoning","id":"rs_copilot"}}`,
+			`event: response.reasoning_summary_text.delta`,
+			`data: {"type":"response.reasoning_summary_text.delta","output_index":0,"item_id":"rs_copilot","summary_index":0,"delta":"Planning the answer"}`,
+			`event: response.output_text.delta`,
+			`data: {"type":"response.output_text.delta","output_index":1,"delta":"Hello"}`,
+			`event: response.completed`,
+			`data: {"type":"response.completed","response":{"usage":{"input_tokens":3,"output_tokens":2,"total_tokens":5}}}`,
+			`data: [DONE]`,
+			``,
+		}, "\n"))
+	}))
+	defer server.Close()
+	copilotHTTPClient = server.Client()
+
+	provider := &CopilotProvider{
+		creds:              &credentials.CopilotCredentials{AccessToken: "oauth-token"},
+		model:              "gpt-5.5",
+		apiBaseURL:         server.URL,
+		sessionToken:       "session-token",
+		sessionTokenExpiry: time.Now().Add(time.Hour),
+	}
+
+	stream, err := provider.Stream(context.Background(), Request{Messages: []Message{UserText("hi")}})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	defer stream.Close()
+
+	var order []EventType
+	var reasoning, text strings.Builder
+	for {
+		event, recvErr := stream.Recv()
+		if recvErr == io.EOF {
+			break
+		}
+		if recvErr != nil {
+			t.Fatalf("stream recv failed: %v", recvErr)
+		}
+		switch event.Type {
+		case EventReasoningDelta:
+			reasoning.WriteString(event.Text)
+		case EventTextDelta:
+			text.WriteString(event.Text)
+		}
+		if event.Type == EventReasoningDelta || event.Type == EventTextDelta {
+			order = append(order, event.Type)
+		}
+		if event.Type == EventDone {
+			break
+		}
+	}
+
+	if reasoning.String() != "Planning the answer" {
+		t.Fatalf("reasoning = %q, want summary delta", reasoning.String())
+	}
+	if text.String() != "Hello" {
+		t.Fatalf("text = %q, want only output text", text.String())
+	}
+	if len(order) == 0 || order[0] != EventReasoningDelta {
+		t.Fatalf("event order = %v, want reasoning before text", order)
+	}
+	reasoningCfg, _ := payload["reasoning"].(map[string]any)
+	if reasoningCfg["summary"] != "auto" {
+		t.Fatalf("reasoning.summary = %#v, want auto", reasoningCfg["summary"])
+	}
+}
+
+func TestCopilotResponsesWithoutEncryptedReasoning(t *testing.T) {
+	origClient := copilotHTTPClient
+	t.Cleanup(func() { copilotHTTPClient = origClient })
+
+	var payload struct {
+		Include []string         `json:"include"`
+		Input   []map[string]any `json:"input"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+	copilotHTTPClient = server.Client()
+
+	provider := &CopilotProvider{
+		creds:                &credentials.CopilotCredentials{AccessToken: "oauth-token"},
+		model:                "gpt-5.5",
+		apiBaseURL:           server.URL,
+		sessionToken:         "session-token",
+		sessionTokenExpiry:   time.Now().Add(time.Hour),
+		noEncryptedReasoning: true,
+	}
+
+	history := []Message{
+		UserText("hi"),
+		{Role: RoleAssistant, Parts: []Part{{Type: PartText, Text: "Hello", ReasoningItemID: "rs_1", ReasoningEncryptedContent: "gAAAA-secret"}}},
+		UserText("again"),
+	}
+	stream, err := provider.Stream(context.Background(), Request{Messages: history})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	defer stream.Close()
+	drainStreamToDone(t, stream)
+
+	if len(payload.Include) != 0 {
+		t.Fatalf("include = %v, want none", payload.Include)
+	}
+	for _, item := range payload.Input {
+		if item["type"] == "reasoning" {
+			t.Fatalf("input still carries a reasoning item: %v", item)
+		}
+	}
+}
+
+// fakeCopilotHosts starts a token endpoint that names no API host and two
+// API hosts standing in for the individual and business endpoints. Each
+// API host answers /models with its own status.
+func fakeCopilotHosts(t *testing.T, individualStatus, businessStatus int) (individual, business *int) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"token":"session-token","expires_at":%d}`, time.Now().Add(25*time.Minute).Unix())
+	}))
+	t.Cleanup(tokenServer.Close)
+
+	apiHost := func(status int, hits *int) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*hits++
+			if got := r.Header.Get("Authorization"); got != "Bearer session-token" {
+				t.Errorf("Authorization header = %q, want the session token", got)
+			}
+			if status != http.StatusOK {
+				http.Error(w, "not here", status)
+				return
+			}
+			_, _ = io.WriteString(w, `{"data":[{"id":"gpt-4.1","name":"GPT-4.1","vendor":"OpenAI"}]}`)
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+	individual, business = new(int), new(int)
+	individualServer := apiHost(individualStatus, individual)
+	businessServer := apiHost(businessStatus, business)
+
+	origClient, origTokenURL, origCandidates := copilotHTTPClient, copilotTokenURL, copilotAPIBaseCandidates
+	t.Cleanup(func() {
+		copilotHTTPClient, copilotTokenURL, copilotAPIBaseCandidates = origClient, origTokenURL, origCandidates
+	})
+	copilotHTTPClient = tokenServer.Client()
+	copilotTokenURL = tokenServer.URL
+	copilotAPIBaseCandidates = []string{individualServer.URL, businessServer.URL}
+	return individual, business
+}
+
+func TestCopilotFallsBackToBusinessEndpointAndRemembersIt(t *testing.T) {
+	individual, business := fakeCopilotHosts(t, http.StatusNotFound, http.StatusOK)
+
+	provider := NewCopilotProviderWithCreds(&credentials.CopilotCredentials{AccessToken: "oauth-token"}, "")
+	models, err := provider.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels: %v", err)
+	}
+	if len(models) != 1 || models[0].ID != "gpt-4.1" {
+		t.Fatalf("models = %+v, want the business host's list", models)
+	}
+	if *individual != 1 || *business != 2 {
+		t.Fatalf("requests individual=%d business=%d, want one probe of each plus the real request", *individual, *business)
+	}
+
+	stored, err := credentials.GetCopilotCredentials()
+	if err != nil {
+		t.Fatalf("GetCopilotCredentials: %v", err)
+	}
+	if stored.APIBaseURL != copilotAPIBaseCandidates[1] {
+		t.Fatalf("stored api base URL = %q, want the business host", stored.APIBaseURL)
+	}
+
+	// A later run starts from the stored credentials and goes straight to
+	// the business host.
+	*individual, *business = 0, 0
+	next := NewCopilotProviderWithCreds(stored, "")
+	if _, err := next.ListModels(context.Background()); err != nil {
+		t.Fatalf("ListModels with stored base URL: %v", err)
+	}
+	if *individual != 0 || *business != 1 {
+		t.Fatalf("requests individual=%d business=%d, want only the business host", *individual, *business)
+	}
+}
+
+func TestCopilotEndpointDiscoveryNamesBothHostsWhenNeitherWorks(t *testing.T) {
+	fakeCopilotHosts(t, http.StatusUnauthorized, http.StatusNotFound)
+
+	provider := NewCopilotProviderWithCreds(&credentials.CopilotCredentials{AccessToken: "oauth-token"}, "")
+	_, err := provider.ListModels(context.Background())
+	if err == nil {
+		t.Fatal("ListModels succeeded, want an error")
+	}
+	for _, want := range []string{copilotAPIBaseCandidates[0], copilotAPIBaseCandidates[1], "HTTP 401", "HTTP 404"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+	if _, err := credentials.GetCopilotCredentials(); err == nil {
+		t.Error("credentials were saved although no endpoint worked")
+	}
+}
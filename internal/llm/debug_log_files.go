@@ -0,0 +1,141 @@
+package llm
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Debug log file layout. Part 1 of a session is <session>.jsonl; later parts
+// written after rotation are <session>.part2.jsonl, <session>.part3.jsonl and
+// so on. internal/debuglog reads logs through the helpers below.
+
+const debugLogPartMarker = ".part"
+
+// DebugLogPartPath returns the path of the given part of a session's debug
+// log in baseDir.
+func DebugLogPartPath(baseDir, sessionID string, part int) string {
+	if part <= 1 {
+		return filepath.Join(baseDir, sessionID+".jsonl")
+	}
+	return filepath.Join(baseDir, sessionID+debugLogPartMarker+strconv.Itoa(part)+".jsonl")
+}
+
+// ParseDebugLogFileName splits a debug log file name into its session ID and
+// part number. ok is false for files that are not debug logs.
+func ParseDebugLogFileName(name string) (sessionID string, part int, ok bool) {
+	base, found := strings.CutSuffix(name, ".jsonl")
+	if !found || base == "" {
+		return "", 0, false
+	}
+	if i := strings.LastIndex(base, debugLogPartMarker); i > 0 {
+		if n, err := strconv.Atoi(base[i+len(debugLogPartMarker):]); err == nil && n > 1 {
+			return base[:i], n, true
+		}
+	}
+	return base, 1, true
+}
+
+// DebugLogSessionFiles lists every file belonging to one session.
+type DebugLogSessionFiles struct {
+	ID      string
+	Paths   []string
+	Size    int64
+	ModTime time.Time // most recent write to any part
+}
+
+// ListDebugLogSessionFiles groups the debug log files in baseDir by session,
+// least recently written first.
+func ListDebugLogSessionFiles(baseDir string) ([]DebugLogSessionFiles, error) {
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	byID := make(map[string]*DebugLogSessionFiles)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		id, _, ok := ParseDebugLogFileName(entry.Name())
+		if !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files := byID[id]
+		if files == nil {
+			files = &DebugLogSessionFiles{ID: id}
+			byID[id] = files
+		}
+		files.Paths = append(files.Paths, filepath.Join(baseDir, entry.Name()))
+		files.Size += info.Size()
+		if info.ModTime().After(files.ModTime) {
+			files.ModTime = info.ModTime()
+		}
+	}
+	sessions := make([]DebugLogSessionFiles, 0, len(byID))
+	for _, files := range byID {
+		sessions = append(sessions, *files)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].ModTime.Before(sessions[j].ModTime)
+	})
+	return sessions, nil
+}
+
+func (f DebugLogSessionFiles) remove() {
+	for _, path := range f.Paths {
+		_ = os.Remove(path)
+	}
+}
+
+// CleanupOldLogs removes debug log sessions whose newest part is older than
+// maxAge from the specified directory.
+// This prevents debug logs from accumulating indefinitely.
+func CleanupOldLogs(baseDir string, maxAge time.Duration) error {
+	sessions, err := ListDebugLogSessionFiles(baseDir)
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-maxAge)
+	for _, files := range sessions {
+		if files.ModTime.Before(cutoff) {
+			files.remove()
+		}
+	}
+	return nil
+}
+
+// EnforceDebugLogBudget deletes whole sessions, least recently written
+// first, until the debug log files in baseDir total at most maxTotalBytes.
+func EnforceDebugLogBudget(baseDir string, maxTotalBytes int64) error {
+	sessions, err := ListDebugLogSessionFiles(baseDir)
+	if err != nil {
+		return err
+	}
+	var total int64
+	for _, files := range sessions {
+		total += files.Size
+	}
+	for _, files := range sessions {
+		if total <= maxTotalBytes {
+			break
+		}
+		files.remove()
+		total -= files.Size
+	}
+	return nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
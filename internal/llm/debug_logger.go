@@ -5,30 +5,42 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 )
 
 // DebugLogger logs LLM requests and events to JSONL files for debugging.
-// Each session gets its own file based on the session ID.
+// Each session gets its own file based on the session ID, split into
+// numbered parts when DebugLogLimits.MaxFileBytes is set.
 type DebugLogger struct {
 	baseDir   string
 	sessionID string
+	limits    DebugLogLimits
 	mu        sync.Mutex
 	file      *os.File
 	writer    *bufio.Writer
+	part      int   // current part number, starting at 1
+	size      int64 // bytes in the current part, including buffered data
 	closeOnce sync.Once
 	closed    bool
 }
 
+// DebugLogLimits bounds the disk space used by debug logs. Zero disables a
+// limit.
+type DebugLogLimits struct {
+	MaxFileBytes  int64 // start a new part once a session file would exceed this
+	MaxTotalBytes int64 // at startup, delete the oldest sessions until the directory fits
+}
+
 // debugLogEntry is the common structure for all log entries
 type debugLogEntry struct {
 	Timestamp string `json:"timestamp"`
 	SessionID string `json:"session_id"`
+	TurnID    string `json:"turn_id,omitempty"`
 	Type      string `json:"type"` // "request" or "event"
 }
 
@@ -130,29 +142,83 @@ type debugSessionStartEntry struct {
 	Cwd     string   `json:"cwd"`
 }
 
-// NewDebugLogger creates a new DebugLogger.
+// debugRunSummaryEntry logs the recap printed at the end of a run
+type debugRunSummaryEntry struct {
+	debugLogEntry
+	Summary any `json:"summary"`
+}
+
+// NewDebugLogger creates a new DebugLogger without size limits.
 // The sessionID is used to create a unique filename for this session.
 // Old log files (>7 days) are automatically cleaned up.
 func NewDebugLogger(baseDir, sessionID string) (*DebugLogger, error) {
+	return NewDebugLoggerWithLimits(baseDir, sessionID, DebugLogLimits{})
+}
+
+// NewDebugLoggerWithLimits creates a DebugLogger that rotates its session
+// file at limits.MaxFileBytes and, before opening it, trims the directory to
+// limits.MaxTotalBytes by deleting the oldest sessions.
+func NewDebugLoggerWithLimits(baseDir, sessionID string, limits DebugLogLimits) (*DebugLogger, error) {
 	if err := os.MkdirAll(baseDir, 0700); err != nil {
 		return nil, err
 	}
 
 	// Clean up old log files (7-day retention)
 	_ = CleanupOldLogs(baseDir, 7*24*time.Hour)
+	if limits.MaxTotalBytes > 0 {
+		_ = EnforceDebugLogBudget(baseDir, limits.MaxTotalBytes)
+	}
 
-	filename := filepath.Join(baseDir, sessionID+".jsonl")
-	file, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
-	if err != nil {
-		return nil, err
+	// Resume the last part when a session ID is reused.
+	part := 1
+	for fileExists(DebugLogPartPath(baseDir, sessionID, part+1)) {
+		part++
 	}
 
-	return &DebugLogger{
+	l := &DebugLogger{
 		baseDir:   baseDir,
 		sessionID: sessionID,
-		file:      file,
-		writer:    bufio.NewWriter(file),
-	}, nil
+		limits:    limits,
+	}
+	if err := l.openPartLocked(part); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// openPartLocked opens part for appending. Must be called with l.mu held
+// (or before l is shared).
+func (l *DebugLogger) openPartLocked(part int) error {
+	file, err := os.OpenFile(DebugLogPartPath(l.baseDir, l.sessionID, part), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	l.file = file
+	l.writer = bufio.NewWriter(file)
+	l.part = part
+	l.size = info.Size()
+	return nil
+}
+
+// rotateLocked closes the current part and continues in the next one. Must
+// be called with l.mu held.
+func (l *DebugLogger) rotateLocked() error {
+	l.writer.Flush()
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+	if err := l.openPartLocked(l.part + 1); err != nil {
+		// Nothing is open any more; drop further entries rather than
+		// writing to a closed file.
+		l.closed = true
+		return err
+	}
+	return nil
 }
 
 // LogSessionStart logs the session start with CLI invocation details.
@@ -176,6 +242,24 @@ func (l *DebugLogger) LogSessionStart(command string, args []string, cwd string)
 	l.Flush()
 }
 
+// LogRunSummary logs the end-of-run summary (elapsed time, turns, tokens,
+// tools and files touched). summary must marshal to JSON.
+func (l *DebugLogger) LogRunSummary(summary any) {
+	if l == nil {
+		return
+	}
+
+	l.writeEntry(debugRunSummaryEntry{
+		debugLogEntry: debugLogEntry{
+			Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+			SessionID: l.sessionID,
+			Type:      "run_summary",
+		},
+		Summary: summary,
+	})
+	l.Flush()
+}
+
 // LogRequest logs an LLM request.
 func (l *DebugLogger) LogRequest(provider, model string, req Request) {
 	if l == nil {
@@ -198,33 +282,16 @@ func (l *DebugLogger) LogRequest(provider, model string, req Request) {
 			}
 		}
 	}
-	replayParts, encryptedParts := countReasoningReplayParts(req.Messages)
-
 	entry := debugRequestEntry{
 		debugLogEntry: debugLogEntry{
 			Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
 			SessionID: l.sessionID,
+			TurnID:    req.TurnID,
 			Type:      "request",
 		},
 		Provider: provider,
 		Model:    logModel,
-		Request: debugRequestData{
-			SessionID:               req.SessionID,
-			Messages:                convertMessages(req.Messages),
-			Tools:                   convertTools(req.Tools),
-			ToolChoice:              convertToolChoice(req.ToolChoice),
-			Search:                  req.Search,
-			ForceExternalSearch:     req.ForceExternalSearch,
-			DisableExternalWebFetch: req.DisableExternalWebFetch,
-			ParallelToolCalls:       req.ParallelToolCalls,
-			MaxOutputTokens:         req.MaxOutputTokens,
-			Temperature:             req.Temperature,
-			TopP:                    req.TopP,
-			ReasoningEffort:         req.ReasoningEffort,
-			ReasoningReplayParts:    replayParts,
-			ReasoningEncryptedParts: encryptedParts,
-			MaxTurns:                req.MaxTurns,
-		},
+		Request:  newDebugRequestData(req),
 	}
 
 	l.writeEntry(entry)
@@ -232,6 +299,28 @@ func (l *DebugLogger) LogRequest(provider, model string, req Request) {
 	l.Flush()
 }
 
+// newDebugRequestData converts a request to its logged form.
+func newDebugRequestData(req Request) debugRequestData {
+	replayParts, encryptedParts := countReasoningReplayParts(req.Messages)
+	return debugRequestData{
+		SessionID:               req.SessionID,
+		Messages:                convertMessages(req.Messages),
+		Tools:                   convertTools(req.Tools),
+		ToolChoice:              convertToolChoice(req.ToolChoice),
+		Search:                  req.Search,
+		ForceExternalSearch:     req.ForceExternalSearch,
+		DisableExternalWebFetch: req.DisableExternalWebFetch,
+		ParallelToolCalls:       req.ParallelToolCalls,
+		MaxOutputTokens:         req.MaxOutputTokens,
+		Temperature:             req.Temperature,
+		TopP:                    req.TopP,
+		ReasoningEffort:         req.ReasoningEffort,
+		ReasoningReplayParts:    replayParts,
+		ReasoningEncryptedParts: encryptedParts,
+		MaxTurns:                req.MaxTurns,
+	}
+}
+
 // convertToolChoice converts ToolChoice to debug format
 func convertToolChoice(tc ToolChoice) *debugToolChoice {
 	if tc.Mode == "" {
@@ -266,34 +355,17 @@ func (l *DebugLogger) LogTurnRequest(turn int, provider, model string, req Reque
 			}
 		}
 	}
-	replayParts, encryptedParts := countReasoningReplayParts(req.Messages)
-
 	entry := debugTurnRequestEntry{
 		debugLogEntry: debugLogEntry{
 			Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
 			SessionID: l.sessionID,
+			TurnID:    req.TurnID,
 			Type:      "turn_request",
 		},
 		Turn:     turn,
 		Provider: provider,
 		Model:    logModel,
-		Request: debugRequestData{
-			SessionID:               req.SessionID,
-			Messages:                convertMessages(req.Messages),
-			Tools:                   convertTools(req.Tools),
-			ToolChoice:              convertToolChoice(req.ToolChoice),
-			Search:                  req.Search,
-			ForceExternalSearch:     req.ForceExternalSearch,
-			DisableExternalWebFetch: req.DisableExternalWebFetch,
-			ParallelToolCalls:       req.ParallelToolCalls,
-			MaxOutputTokens:         req.MaxOutputTokens,
-			Temperature:             req.Temperature,
-			TopP:                    req.TopP,
-			ReasoningEffort:         req.ReasoningEffort,
-			ReasoningReplayParts:    replayParts,
-			ReasoningEncryptedParts: encryptedParts,
-			MaxTurns:                req.MaxTurns,
-		},
+		Request:  newDebugRequestData(req),
 	}
 
 	l.writeEntry(entry)
@@ -303,6 +375,11 @@ func (l *DebugLogger) LogTurnRequest(turn int, provider, model string, req Reque
 
 // LogEvent logs an LLM event.
 func (l *DebugLogger) LogEvent(event Event) {
+	l.LogTurnEvent("", event)
+}
+
+// LogTurnEvent logs an LLM event emitted during the given engine turn.
+func (l *DebugLogger) LogTurnEvent(turnID string, event Event) {
 	if l == nil {
 		return
 	}
@@ -311,6 +388,7 @@ func (l *DebugLogger) LogEvent(event Event) {
 		debugLogEntry: debugLogEntry{
 			Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
 			SessionID: l.sessionID,
+			TurnID:    turnID,
 			Type:      "event",
 		},
 		EventType: string(event.Type),
@@ -410,7 +488,8 @@ func (l *DebugLogger) LogEvent(event Event) {
 	case EventError:
 		if event.Err != nil {
 			data := map[string]any{"error": event.Err.Error()}
-			if fieldsErr, ok := event.Err.(interface{ DebugFields() map[string]any }); ok {
+			var fieldsErr interface{ DebugFields() map[string]any }
+			if errors.As(event.Err, &fieldsErr) {
 				for k, v := range fieldsErr.DebugFields() {
 					if k == "" || k == "error" {
 						continue
@@ -449,7 +528,7 @@ func (l *DebugLogger) Close() error {
 		l.mu.Lock()
 		defer l.mu.Unlock()
 
-		if l.file == nil {
+		if l.file == nil || l.closed {
 			return
 		}
 
@@ -479,8 +558,15 @@ func (l *DebugLogger) writeEntry(entry any) {
 		return
 	}
 
+	n := int64(len(data)) + 1
+	if l.limits.MaxFileBytes > 0 && l.size > 0 && l.size+n > l.limits.MaxFileBytes {
+		if err := l.rotateLocked(); err != nil {
+			return
+		}
+	}
 	l.writer.Write(data)
 	l.writer.WriteString("\n")
+	l.size += n
 }
 
 // flush flushes the buffered writer. Must be called with l.mu held.
@@ -611,36 +697,3 @@ func shortContentHash(content string) string {
 	}
 	return hexHash[:16]
 }
-
-// CleanupOldLogs removes JSONL log files older than maxAge from the specified directory.
-// This prevents debug logs from accumulating indefinitely.
-func CleanupOldLogs(baseDir string, maxAge time.Duration) error {
-	entries, err := os.ReadDir(baseDir)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
-		return err
-	}
-
-	cutoff := time.Now().Add(-maxAge)
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-		if filepath.Ext(entry.Name()) != ".jsonl" {
-			continue
-		}
-
-		info, err := entry.Info()
-		if err != nil {
-			continue
-		}
-
-		if info.ModTime().Before(cutoff) {
-			_ = os.Remove(filepath.Join(baseDir, entry.Name()))
-		}
-	}
-
-	return nil
-}
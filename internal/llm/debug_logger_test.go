@@ -2,11 +2,13 @@ package llm
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"errors"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestDebugLogger_LogRequest(t *testing.T) {
@@ -396,3 +398,108 @@ func TestDebugLogger_LogReasoningDeltaEventData(t *testing.T) {
 		t.Fatalf("expected text reasoning summary, got %#v", got)
 	}
 }
+
+func TestDebugLogger_RotatesAtMaxFileBytes(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionID := "test-rotate"
+
+	logger, err := NewDebugLoggerWithLimits(tmpDir, sessionID, DebugLogLimits{MaxFileBytes: 200})
+	if err != nil {
+		t.Fatalf("failed to create debug logger: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		logger.LogEvent(Event{Type: EventTextDelta, Text: "some text that fills the file"})
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("failed to close logger: %v", err)
+	}
+
+	var lines int
+	for part := 1; ; part++ {
+		data, err := os.ReadFile(DebugLogPartPath(tmpDir, sessionID, part))
+		if errors.Is(err, os.ErrNotExist) {
+			if part < 3 {
+				t.Fatalf("expected several parts, found %d", part-1)
+			}
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) > 200 {
+			t.Errorf("part %d is %d bytes, want at most 200", part, len(data))
+		}
+		lines += bytes.Count(data, []byte("\n"))
+	}
+	if lines != 10 {
+		t.Fatalf("got %d lines across parts, want 10", lines)
+	}
+
+	// Reopening the session appends to the last part rather than part 1.
+	logger, err = NewDebugLoggerWithLimits(tmpDir, sessionID, DebugLogLimits{MaxFileBytes: 200})
+	if err != nil {
+		t.Fatalf("failed to reopen debug logger: %v", err)
+	}
+	defer logger.Close()
+	if logger.part < 3 {
+		t.Fatalf("reopened logger writes part %d, want the last part", logger.part)
+	}
+}
+
+func TestEnforceDebugLogBudget(t *testing.T) {
+	tmpDir := t.TempDir()
+	now := time.Now()
+	write := func(name string, size int, age time.Duration) {
+		t.Helper()
+		path := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(path, bytes.Repeat([]byte("x"), size), 0600); err != nil {
+			t.Fatal(err)
+		}
+		mtime := now.Add(-age)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("oldest.jsonl", 100, 3*time.Hour)
+	write("oldest.part2.jsonl", 100, 3*time.Hour)
+	write("middle.jsonl", 100, 2*time.Hour)
+	write("newest.jsonl", 100, time.Hour)
+	write("notes.txt", 1000, 4*time.Hour)
+
+	if err := EnforceDebugLogBudget(tmpDir, 250); err != nil {
+		t.Fatalf("EnforceDebugLogBudget: %v", err)
+	}
+
+	for name, want := range map[string]bool{
+		"oldest.jsonl":       false,
+		"oldest.part2.jsonl": false,
+		"middle.jsonl":       true,
+		"newest.jsonl":       true,
+		"notes.txt":          true,
+	} {
+		_, err := os.Stat(filepath.Join(tmpDir, name))
+		if got := err == nil; got != want {
+			t.Errorf("%s exists = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestParseDebugLogFileName(t *testing.T) {
+	tests := []struct {
+		name     string
+		wantID   string
+		wantPart int
+		wantOK   bool
+	}{
+		{"sess.jsonl", "sess", 1, true},
+		{"sess.part2.jsonl", "sess", 2, true},
+		{"sess.part1.jsonl", "sess.part1", 1, true},
+		{"sess.txt", "", 0, false},
+	}
+	for _, tt := range tests {
+		id, part, ok := ParseDebugLogFileName(tt.name)
+		if id != tt.wantID || part != tt.wantPart || ok != tt.wantOK {
+			t.Errorf("ParseDebugLogFileName(%q) = %q, %d, %v; want %q, %d, %v", tt.name, id, part, ok, tt.wantID, tt.wantPart, tt.wantOK)
+		}
+	}
+}
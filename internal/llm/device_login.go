@@ -0,0 +1,101 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/credentials"
+	"github.com/samsaffron/term-llm/internal/oauth"
+)
+
+// DeviceLogin is a device-code sign-in waiting for the user to enter
+// UserCode at VerificationURL. Callers that own the terminal, like the chat
+// TUI, use it to show the code themselves instead of PromptForCopilotAuth.
+type DeviceLogin struct {
+	Provider        string
+	UserCode        string
+	VerificationURL string
+
+	wait func(ctx context.Context) error
+}
+
+// StartDeviceLogin requests a device code for an auth provider ("copilot" or
+// "chatgpt"). Nothing is printed and stdin is not read.
+func StartDeviceLogin(ctx context.Context, provider string) (*DeviceLogin, error) {
+	switch provider {
+	case "copilot":
+		dc, err := oauth.RequestCopilotDeviceCode()
+		if err != nil {
+			return nil, err
+		}
+		return &DeviceLogin{
+			Provider:        provider,
+			UserCode:        dc.UserCode,
+			VerificationURL: dc.VerificationURI,
+			wait: func(ctx context.Context) error {
+				if dc.ExpiresIn > 0 {
+					var cancel context.CancelFunc
+					ctx, cancel = context.WithTimeout(ctx, time.Duration(dc.ExpiresIn)*time.Second)
+					defer cancel()
+				}
+				token, err := oauth.PollForCopilotToken(ctx, dc.DeviceCode, dc.Interval)
+				if err != nil {
+					return err
+				}
+				_, err = saveCopilotOAuthCredentials(&oauth.CopilotCredentials{AccessToken: token.AccessToken})
+				return err
+			},
+		}, nil
+	case "chatgpt":
+		dc, err := oauth.RequestChatGPTDeviceCode(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &DeviceLogin{
+			Provider:        provider,
+			UserCode:        dc.UserCode,
+			VerificationURL: dc.VerificationURL,
+			wait: func(ctx context.Context) error {
+				creds, err := oauth.AuthenticateChatGPTDevice(ctx, dc)
+				if err != nil {
+					return err
+				}
+				_, err = saveChatGPTOAuthCredentials(creds)
+				return err
+			},
+		}, nil
+	}
+	return nil, fmt.Errorf("device sign-in is not supported for %s", provider)
+}
+
+// Wait polls until the user approves the sign-in, then saves the credentials.
+func (d *DeviceLogin) Wait(ctx context.Context) error {
+	return d.wait(ctx)
+}
+
+// saveCopilotOAuthCredentials stores the result of a Copilot sign-in.
+func saveCopilotOAuthCredentials(oauthCreds *oauth.CopilotCredentials) (*credentials.CopilotCredentials, error) {
+	creds := &credentials.CopilotCredentials{
+		AccessToken: oauthCreds.AccessToken,
+		ExpiresAt:   oauthCreds.ExpiresAt,
+	}
+	if err := credentials.SaveCopilotCredentials(creds); err != nil {
+		return nil, fmt.Errorf("failed to save credentials: %w", err)
+	}
+	return creds, nil
+}
+
+// saveChatGPTOAuthCredentials stores the result of a ChatGPT sign-in.
+func saveChatGPTOAuthCredentials(oauthCreds *oauth.ChatGPTCredentials) (*credentials.ChatGPTCredentials, error) {
+	creds := &credentials.ChatGPTCredentials{
+		AccessToken:  oauthCreds.AccessToken,
+		RefreshToken: oauthCreds.RefreshToken,
+		ExpiresAt:    oauthCreds.ExpiresAt,
+		AccountID:    oauthCreds.AccountID,
+	}
+	if err := credentials.SaveChatGPTCredentials(creds); err != nil {
+		return nil, fmt.Errorf("failed to save credentials: %w", err)
+	}
+	return creds, nil
+}
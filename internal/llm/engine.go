@@ -19,14 +19,17 @@ import (
 	"time"
 
 	"github.com/samsaffron/term-llm/internal/appdata"
+	"github.com/samsaffron/term-llm/internal/config"
+	"github.com/samsaffron/term-llm/internal/metrics"
 	internalreasoning "github.com/samsaffron/term-llm/internal/reasoning"
 	"github.com/samsaffron/term-llm/internal/usage"
 )
 
 const (
 	defaultMaxTurns                    = 50
-	defaultMaxParallelToolCalls        = 20
+	defaultMaxParallelToolCalls        = 4
 	defaultUncommittedStreamMaxRetries = 5
+	defaultTransientStreamMaxRetries   = 3
 	stopSearchToolHint                 = "IMPORTANT: Do not call any tools. Use the information already retrieved and answer directly."
 	contextContinuationPrompt          = "Continue the task from the compacted context. Follow the pending next step; do not ask the user unless blocked."
 	PhaseCompacting                    = "Compacting"
@@ -45,11 +48,19 @@ func getMaxTurns(req Request) int {
 	return defaultMaxTurns
 }
 
-func maxParallelToolWorkers(callCount int) int {
+// defaultSerialTools are run on their own, never alongside other tool calls
+// from the same turn: a shell command can touch anything the other calls
+// read or write.
+var defaultSerialTools = []string{"shell"}
+
+func maxParallelToolWorkers(callCount, limit int) int {
 	if callCount <= 0 {
 		return 0
 	}
-	return min(callCount, defaultMaxParallelToolCalls)
+	if limit <= 0 {
+		limit = defaultMaxParallelToolCalls
+	}
+	return min(callCount, limit)
 }
 
 // TurnMetrics contains metrics collected during a turn.
@@ -93,6 +104,12 @@ type AssistantSnapshotCallback func(ctx context.Context, turnIndex int, assistan
 // that is not updated here can resurrect pre-compaction history later.
 type CompactionCallback func(ctx context.Context, result *CompactionResult) error
 
+// ToolExecutedCallback is called after each locally executed tool call
+// finishes, with the same EventToolExecEnd event sent to the stream. Unlike
+// the stream event, which is dropped when the consumer is slow, the callback
+// always runs. Parallel tool calls invoke it concurrently.
+type ToolExecutedCallback func(ctx context.Context, event Event)
+
 // Engine orchestrates provider calls and external tool execution.
 type pendingRequestRuntimeSwitch struct {
 	model           string
@@ -104,15 +121,27 @@ type Engine struct {
 	tools       *ToolRegistry
 	debugLogger *DebugLogger
 
+	// middlewares run around each provider call (see Use).
+	middlewares  []Middleware
+	middlewareMu sync.RWMutex
+
 	// indirectVision routes user image parts through textual path references so
 	// text-only models can call view_image instead of receiving image bytes.
 	indirectVision atomic.Bool
 
+	// systemContext supplies request-time text appended to the system prompt
+	// (see SetSystemContext). nil = none.
+	systemContext atomic.Pointer[func() string]
+
 	// allowedTools filters which tools can be executed. A nil map means no
 	// filter; a non-nil empty map is an explicit filter allowing no tools.
 	// Used by skills with a present allowed-tools field.
 	allowedTools map[string]bool
 	allowedMu    sync.RWMutex
+	// toolFilter is an engine-wide ToolFilter, e.g. from an agent's
+	// tools.allowed/tools.denied. It applies to every request and, like a
+	// request filter, carries over to sub-agents. Protected by allowedMu.
+	toolFilter ToolFilter
 
 	// onTurnCompleted is called after each turn with messages generated.
 	// Used for incremental session saving. Protected by callbackMu.
@@ -126,14 +155,32 @@ type Engine struct {
 	onAssistantSnapshot AssistantSnapshotCallback
 	// onCompaction is called after context compaction completes.
 	onCompaction CompactionCallback
-	callbackMu   sync.RWMutex
+	// onToolExecuted is called after each local tool call finishes.
+	onToolExecuted ToolExecutedCallback
+	callbackMu     sync.RWMutex
 
 	// Global tool output truncation
-	maxToolOutputChars int // 0 = disabled; truncate tool output to this many runes
+	maxToolOutputChars int            // 0 = disabled; truncate tool output to this many runes
+	toolResultLimits   map[string]int // per-tool overrides of maxToolOutputChars, keyed by tool name
+
+	// Parallel tool execution
+	maxParallelTools int             // 0 = defaultMaxParallelToolCalls
+	serialTools      map[string]bool // nil = defaultSerialTools
+
+	// Repeated identical tool-call turns before the model is nudged; 0 = off
+	toolLoopThreshold int
+
+	// Tool timeouts
+	toolTimeout      time.Duration            // 0 = none
+	toolTimeouts     map[string]time.Duration // per-tool overrides of toolTimeout
+	turnToolTimeout  time.Duration            // cap on all tool calls of one turn; 0 = none
+	toolClock        *deadlineClock           // this engine's tool deadlines, paused during approval prompts
+	toolAbandonGrace time.Duration            // wait for a timed-out tool before abandoning it; 0 = default
 
 	// Context compaction
 	compactionConfig     *CompactionConfig // nil = compaction disabled
 	inputLimit           int               // 0 = unknown/disabled
+	tokenModel           string            // model whose tokenizer estimates use ("" = byte heuristic)
 	lastTotalTokens      int               // cached+input+output from most recent API response
 	lastMessageCount     int               // retained/persisted for compatibility; estimator anchors structurally
 	systemPrompt         string            // Captured for re-injection after compaction
@@ -212,8 +259,10 @@ func NewEngine(provider Provider, tools *ToolRegistry) *Engine {
 		tools = NewToolRegistry()
 	}
 	e := &Engine{
-		provider: provider,
-		tools:    tools,
+		provider:          provider,
+		tools:             tools,
+		toolClock:         newDeadlineClock(),
+		toolLoopThreshold: defaultToolLoopThreshold,
 	}
 
 	// Wire up tool executors for providers that expose term-llm tools over an external bridge.
@@ -266,20 +315,24 @@ func (e *Engine) IndirectVision() bool {
 	return e.indirectVision.Load()
 }
 
-// RegisterTool adds a tool to the engine's registry.
-func (e *Engine) RegisterTool(tool Tool) {
-	e.tools.Register(tool)
+// RegisterTool adds a tool to the engine's registry. It fails when the tool's
+// input schema is invalid.
+func (e *Engine) RegisterTool(tool Tool) error {
+	return e.tools.Register(tool)
 }
 
 // AddDynamicTool registers a tool and queues its spec to be injected into
 // the active agentic loop's tool list at the start of the next iteration.
 // Use this instead of engine.Tools().Register() when activating skill tools
 // mid-conversation so the LLM sees them immediately on the next turn.
-func (e *Engine) AddDynamicTool(tool Tool) {
-	e.tools.Register(tool)
+func (e *Engine) AddDynamicTool(tool Tool) error {
+	if err := e.tools.Register(tool); err != nil {
+		return err
+	}
 	e.pendingToolsMu.Lock()
 	e.pendingToolSpecs = append(e.pendingToolSpecs, tool.Spec())
 	e.pendingToolsMu.Unlock()
+	return nil
 }
 
 // drainPendingToolSpecs returns any queued tool specs and clears the queue.
@@ -329,7 +382,8 @@ func (e *Engine) ResetConversation() {
 	resetProviderConversation(e.provider)
 }
 
-// SetDebugLogger sets the debug logger for this engine.
+// SetDebugLogger sets the debug logger for this engine. Requests are logged
+// by a built-in middleware and response events by the returned streams.
 func (e *Engine) SetDebugLogger(logger *DebugLogger) {
 	e.debugLogger = logger
 }
@@ -454,6 +508,34 @@ func (e *Engine) SetAssistantSnapshotCallback(cb AssistantSnapshotCallback) {
 	e.callbackMu.Unlock()
 }
 
+// SetToolExecutedCallback sets the callback fired after each local tool call
+// finishes. Used to summarize tool activity and file changes for a run.
+// Thread-safe: can be called while streaming is in progress.
+func (e *Engine) SetToolExecutedCallback(cb ToolExecutedCallback) {
+	e.callbackMu.Lock()
+	e.onToolExecuted = cb
+	e.callbackMu.Unlock()
+}
+
+// getToolExecutedCallback returns the current tool-executed callback under read lock.
+func (e *Engine) getToolExecutedCallback() ToolExecutedCallback {
+	e.callbackMu.RLock()
+	cb := e.onToolExecuted
+	e.callbackMu.RUnlock()
+	return cb
+}
+
+// endToolExec reports a finished tool call: best-effort on the event stream,
+// so a slow consumer cannot stall tool workers, and always to the
+// tool-executed callback.
+func (e *Engine) endToolExec(ctx context.Context, send eventSender, ev Event) {
+	ev.Type = EventToolExecEnd
+	send.TrySend(ev)
+	if cb := e.getToolExecutedCallback(); cb != nil {
+		cb(ctx, ev)
+	}
+}
+
 // getTurnCallback returns the current turn callback under read lock.
 func (e *Engine) getTurnCallback() TurnCompletedCallback {
 	e.callbackMu.RLock()
@@ -557,6 +639,7 @@ func (e *Engine) ConfigureContextManagement(provider Provider, providerName, mod
 	e.callbackMu.Lock()
 	e.inputLimit = limit
 	e.compactionConfig = compactionConfig
+	e.tokenModel = modelName
 	e.callbackMu.Unlock()
 }
 
@@ -652,10 +735,11 @@ func (e *Engine) SetContextEstimateBaseline(lastTotalTokens, lastMessageCount in
 func (e *Engine) EstimateTokens(messages []Message) int {
 	e.callbackMu.RLock()
 	lastTotalTokens := e.lastTotalTokens
+	model := e.tokenModel
 	e.callbackMu.RUnlock()
 
 	if lastTotalTokens <= 0 {
-		return EstimateMessageTokens(messages)
+		return EstimateModelMessageTokens(model, messages)
 	}
 
 	afterLastAssistant := -1
@@ -669,10 +753,10 @@ func (e *Engine) EstimateTokens(messages []Message) int {
 		// A persisted baseline is only valid when it can be anchored to an
 		// assistant turn in the current transcript. Summary-only / cleared
 		// contexts should not inherit a stale pre-compaction baseline.
-		return EstimateMessageTokens(messages)
+		return EstimateModelMessageTokens(model, messages)
 	}
 
-	return lastTotalTokens + EstimateMessageTokens(messages[afterLastAssistant:])
+	return lastTotalTokens + EstimateModelMessageTokens(model, messages[afterLastAssistant:])
 }
 
 // SetCompactionCallback sets the callback for context compaction events.
@@ -692,6 +776,98 @@ func (e *Engine) SetMaxToolOutputChars(n int) {
 	e.callbackMu.Unlock()
 }
 
+// SetToolResultLimits sets per-tool output limits keyed by tool name. Tools
+// without a positive entry fall back to the SetMaxToolOutputChars limit.
+func (e *Engine) SetToolResultLimits(limits map[string]int) {
+	copied := make(map[string]int, len(limits))
+	for name, n := range limits {
+		if n > 0 {
+			copied[name] = n
+		}
+	}
+	e.callbackMu.Lock()
+	e.toolResultLimits = copied
+	e.callbackMu.Unlock()
+}
+
+// SetParallelTools limits how many tool calls from one turn run at once and
+// names the tools that must run alone. A limit of 0 uses the default; nil
+// serialTools keeps the default of running shell calls alone.
+func (e *Engine) SetParallelTools(maxParallel int, serialTools []string) {
+	var serial map[string]bool
+	if serialTools != nil {
+		serial = make(map[string]bool, len(serialTools))
+		for _, name := range serialTools {
+			serial[name] = true
+		}
+	}
+	e.callbackMu.Lock()
+	e.maxParallelTools = maxParallel
+	e.serialTools = serial
+	e.callbackMu.Unlock()
+}
+
+// SetToolLoopThreshold sets how many turns in a row may make the same tool
+// calls with the same arguments, and no text, before the engine tells the
+// model the result will not change. If the next turn repeats them again the
+// loop stops with a ToolLoopError. Pass 0 to disable detection.
+func (e *Engine) SetToolLoopThreshold(n int) {
+	e.callbackMu.Lock()
+	e.toolLoopThreshold = max(n, 0)
+	e.callbackMu.Unlock()
+}
+
+// ApplyToolsConfig applies the configured tool output limits, parallelism,
+// loop threshold and timeouts. Engines built at startup and engines rebuilt
+// for a model switch both go through it, so they behave alike. An invalid
+// timeout setting leaves tool timeouts disabled and is returned.
+func (e *Engine) ApplyToolsConfig(cfg config.ToolsConfig) error {
+	e.SetMaxToolOutputChars(cfg.DefaultResultLimit())
+	e.SetToolResultLimits(cfg.ResultLimits)
+	e.SetParallelTools(cfg.MaxParallel, cfg.SerialTools)
+	e.SetToolLoopThreshold(cfg.LoopThreshold)
+	timeout, perTool, turn, err := cfg.ToolTimeouts()
+	if err != nil {
+		return err
+	}
+	e.SetToolTimeouts(timeout, perTool, turn)
+	return nil
+}
+
+// InheritRuntime copies the state callers attach to an engine after it is
+// built (system context, debug logger, middlewares and the allowed-tools
+// filter) from old, for an engine that replaces it mid-session.
+func (e *Engine) InheritRuntime(old *Engine) {
+	if e == nil || old == nil {
+		return
+	}
+	e.SetSystemContext(old.SystemContext())
+	e.debugLogger = old.debugLogger
+	old.middlewareMu.RLock()
+	mws := append([]Middleware(nil), old.middlewares...)
+	old.middlewareMu.RUnlock()
+	e.middlewareMu.Lock()
+	e.middlewares = mws
+	e.middlewareMu.Unlock()
+	if allowed, present := old.AllowedToolsFilter(); present {
+		e.SetAllowedToolsFilter(allowed)
+	}
+	e.SetToolFilter(old.ToolFilter())
+}
+
+func (e *Engine) parallelToolSettings() (int, map[string]bool) {
+	e.callbackMu.RLock()
+	defer e.callbackMu.RUnlock()
+	serial := e.serialTools
+	if serial == nil {
+		serial = make(map[string]bool, len(defaultSerialTools))
+		for _, name := range defaultSerialTools {
+			serial[name] = true
+		}
+	}
+	return e.maxParallelTools, serial
+}
+
 // QueueRequestModelSwitch requests a same-provider model change for the next
 // provider turn in an active agentic loop. This is intended for reasoning-effort
 // suffix changes while tools are running: the Engine cannot be replaced safely
@@ -931,19 +1107,34 @@ func (e *Engine) continueWithAutoInterjections(ctx context.Context, send eventSe
 	return true, nil
 }
 
-// applyToolOutputTruncation applies global and compaction truncation limits
-// to all textual tool output, including structured content parts. Global limit
-// fires first (typically stricter), then compaction limit as a safety net.
-func (e *Engine) applyToolOutputTruncation(output ToolOutput) ToolOutput {
+// applyToolOutputTruncation applies the tool's result limit and the compaction
+// truncation limit to all textual tool output, including structured content
+// parts. The per-tool (or global) limit fires first (typically stricter), then
+// the compaction limit as a safety net.
+func (e *Engine) applyToolOutputTruncation(toolName string, output ToolOutput) ToolOutput {
 	e.callbackMu.RLock()
 	maxChars := e.maxToolOutputChars
+	note := fmt.Sprintf("limit: %d", maxChars)
+	if n, ok := e.toolResultLimits[toolName]; ok {
+		maxChars = n
+		note = fmt.Sprintf("limit for %s: %d", toolName, maxChars)
+	}
 	cc := e.compactionConfig
 	e.callbackMu.RUnlock()
 
+	// JSON-aware truncation runs first so valid JSON stays valid; the
+	// head/tail pass below is then a no-op unless the JSON could not fit.
+	jsonAware := cc != nil && cc.JSONToolResults
 	if maxChars > 0 {
-		output = truncateToolOutput(output, maxChars)
+		if jsonAware {
+			output = truncateJSONToolOutput(output, maxChars)
+		}
+		output = truncateToolOutputWithNote(output, maxChars, note)
 	}
 	if cc != nil && cc.MaxToolResultChars > 0 {
+		if jsonAware {
+			output = truncateJSONToolOutput(output, cc.MaxToolResultChars)
+		}
 		output = truncateToolOutput(output, cc.MaxToolResultChars)
 	}
 	return output
@@ -959,8 +1150,9 @@ func (e *Engine) getCompactionCallback() CompactionCallback {
 
 // estimatedTokens returns the estimated input token count for the next API
 // call. Uses total_tokens (input+output) from the last API response as the exact
-// baseline through the last assistant turn, then adds heuristic estimates only
-// for messages structurally appended after that assistant turn.
+// baseline through the last assistant turn, then adds estimates only for
+// messages structurally appended after that assistant turn. Estimates use the
+// active model's tokenizer when one is available (see CountTokens).
 func (e *Engine) estimatedTokens(messages []Message) int {
 	return e.EstimateTokens(messages)
 }
@@ -990,9 +1182,38 @@ func (e *Engine) IsToolAllowed(name string) bool {
 
 const indirectVisionInstruction = "Uploaded images are represented as local file-path references for this text-only model. When visual content matters, call the view_image tool with the referenced file_path and, if useful, a focused question. Do not claim to have inspected an image unless you have called view_image or the user-provided text is sufficient."
 
+// SetSystemContext registers fn, whose result is appended to the system
+// prompt of every provider request. It is evaluated per request and never
+// stored in the conversation, so context that changes mid-session (such as
+// project instruction files) stays current and can be switched off.
+func (e *Engine) SetSystemContext(fn func() string) {
+	if e == nil {
+		return
+	}
+	if fn == nil {
+		e.systemContext.Store(nil)
+		return
+	}
+	e.systemContext.Store(&fn)
+}
+
+// SystemContext returns the function registered with SetSystemContext.
+func (e *Engine) SystemContext() func() string {
+	if e == nil {
+		return nil
+	}
+	if fn := e.systemContext.Load(); fn != nil {
+		return *fn
+	}
+	return nil
+}
+
 func (e *Engine) prepareProviderRequest(req Request) Request {
 	prepared := req
-	prepared.Messages = providerSafeRequestMessages(req.Messages)
+	prepared.Messages = assistantImagesAsText(providerSafeRequestMessages(req.Messages))
+	if systemContext := e.SystemContext(); systemContext != nil {
+		prepared.Messages = appendSystemContext(prepared.Messages, systemContext())
+	}
 	if e.IndirectVision() {
 		normalized := ensureIndirectVisionImagePaths(prepared.Messages)
 		rewritten, changed := rewriteImagePartsAsReferences(normalized)
@@ -1198,6 +1419,24 @@ func unavailableImageText(path string) string {
 	return fmt.Sprintf("[image unavailable: saved file could not be read at %s]", path)
 }
 
+// appendSystemContext adds text as a new part of the leading system message,
+// creating one when the conversation has none. The existing parts are kept
+// as they are so their per-part metadata survives.
+func appendSystemContext(messages []Message, text string) []Message {
+	if strings.TrimSpace(text) == "" {
+		return messages
+	}
+	if len(messages) > 0 && messages[0].Role == RoleSystem {
+		out := append([]Message(nil), messages...)
+		parts := make([]Part, 0, len(out[0].Parts)+1)
+		parts = append(parts, out[0].Parts...)
+		// Providers join system parts without a separator.
+		out[0].Parts = append(parts, Part{Type: PartText, Text: "\n\n" + text})
+		return out
+	}
+	return append([]Message{SystemText(text)}, messages...)
+}
+
 func prependIndirectVisionInstruction(messages []Message) []Message {
 	for _, msg := range messages {
 		if msg.Role == RoleDeveloper && strings.Contains(collectTextParts(msg.Parts), "Uploaded images are represented as local file-path references") {
@@ -1268,7 +1507,7 @@ func (e *Engine) PrepareCompactionContext(ctx context.Context, sessionID string,
 	if e == nil || e.provider == nil || result == nil || !e.provider.Capabilities().ToolCalls {
 		return nil
 	}
-	specs = e.FilterAllowedToolSpecs(specs)
+	specs = e.filterToolSpecs(ctx, specs)
 	for _, spec := range specs {
 		tool, ok := e.tools.Get(spec.Name)
 		if !ok {
@@ -1312,9 +1551,24 @@ func (e *Engine) prepareRequestContext(ctx context.Context, req *Request) {
 // Stream returns a stream, applying external tools when needed.
 func (e *Engine) Stream(ctx context.Context, req Request) (Stream, error) {
 	req.Messages = FilterConversationMessages(req.Messages)
+	if req.TurnID == "" {
+		req.TurnID = TurnIDFromContext(ctx)
+	}
+	if req.TurnID == "" {
+		req.TurnID = NewTurnID()
+	}
+	ctx = ContextWithTurnID(ctx, req.TurnID)
+	ctx = contextWithToolFilter(ctx, e.ToolFilter())
+	ctx = contextWithToolFilter(ctx, req.ToolFilter())
 
 	caps := e.provider.Capabilities()
 
+	// Providers that cannot enforce a response format are told about it in the
+	// system prompt instead; runSimpleScratchpad validates the reply.
+	if req.ResponseFormat.structured() && !caps.StructuredOutput {
+		req.Messages = withStructuredOutputInstruction(req.Messages, req.ResponseFormat)
+	}
+
 	// 1. Handle external search/fetch tool injection
 	// If Search is enabled, add web_search and read_url tools to the tool list.
 	// The LLM will use them naturally during conversation like any other tool.
@@ -1353,7 +1607,7 @@ func (e *Engine) Stream(ctx context.Context, req Request) (Stream, error) {
 	// Keep the provider-visible tool surface aligned with execution policy. This
 	// matters for explicit-empty skill filters and for restrictions activated
 	// between agentic turns.
-	req.Tools = e.FilterAllowedToolSpecs(req.Tools)
+	req.Tools = e.filterToolSpecs(ctx, req.Tools)
 	if len(req.Tools) == 0 {
 		req.ToolChoice = ToolChoice{}
 		req.LastTurnToolChoice = nil
@@ -1368,11 +1622,6 @@ func (e *Engine) Stream(ctx context.Context, req Request) (Stream, error) {
 		e.prepareRequestContext(ctx, &req)
 	}
 
-	if req.DebugRaw {
-		debugReq := e.prepareProviderRequest(req)
-		DebugRawRequest(req.DebugRaw, e.provider.Name(), e.provider.Credential(), debugReq, "Request")
-	}
-
 	// 2. Decide if we use the agentic loop
 	// We use it if request has tools AND provider supports tool calls
 	useLoop := len(req.Tools) > 0 && caps.ToolCalls
@@ -1383,10 +1632,10 @@ func (e *Engine) Stream(ctx context.Context, req Request) (Stream, error) {
 			ctx = ContextWithSessionID(ctx, req.SessionID)
 		}
 		stream := newEventStream(ctx, func(ctx context.Context, send eventSender) error {
-			return e.runLoop(ctx, req, send)
+			return withTurnID(req.TurnID, e.runLoop(ctx, req, send))
 		})
 		stream = wrapLoggingStream(stream, e.provider.Name(), req.Model)
-		stream = e.wrapDebugLoggingStream(stream)
+		stream = e.wrapStreamMiddleware(ctx, req, stream)
 
 		// Wrap with per-turn cleanup for providers that materialize temporary
 		// prompt/image files. Conversation-scoped CleanupMCP is not invoked here;
@@ -1401,15 +1650,11 @@ func (e *Engine) Stream(ctx context.Context, req Request) (Stream, error) {
 	// 3. Simple stream (no tools or no provider support for tools). Model output is
 	// staged in an attempt-local scratchpad until the stream completes; if the
 	// transport fails first, we can discard the scratchpad and replay safely.
-	if e.debugLogger != nil {
-		debugReq := e.prepareProviderRequest(req)
-		e.debugLogger.LogRequest(e.provider.Name(), req.Model, debugReq)
-	}
 	stream := newEventStream(ctx, func(ctx context.Context, send eventSender) error {
-		return e.runSimpleScratchpad(ctx, req, send)
+		return withTurnID(req.TurnID, e.runSimpleScratchpad(ctx, req, send))
 	})
 	stream = wrapLoggingStream(stream, e.provider.Name(), req.Model)
-	stream = e.wrapDebugLoggingStream(stream)
+	stream = e.wrapStreamMiddleware(ctx, req, stream)
 	return stream, nil
 }
 
@@ -1601,6 +1846,42 @@ func isUncommittedReplayableStreamError(err error) bool {
 	return errors.As(err, &nonRecoverable)
 }
 
+// transientRetryBackoff paces engine resubmissions after transient provider
+// errors. Tests shorten it.
+var transientRetryBackoff = RetryConfig{BaseBackoff: time.Second, MaxBackoff: 10 * time.Second}
+
+// awaitTransientRetry decides whether a provider attempt that failed before
+// producing any content may be sent again. It allows up to
+// defaultTransientStreamMaxRetries resubmissions per request, counted in
+// *retries, for errors ClassifyError marks transient. When it allows one it
+// reports the retry as an EventRetry and waits out the backoff.
+func awaitTransientRetry(ctx context.Context, send eventSender, retries *int, cause error) (bool, error) {
+	class := ClassifyError(cause)
+	if !class.Transient() || *retries >= defaultTransientStreamMaxRetries {
+		return false, nil
+	}
+	*retries++
+	wait := calculateRetryBackoff(transientRetryBackoff, *retries, cause)
+	if err := send.Send(Event{
+		Type:             EventRetry,
+		RetryAttempt:     *retries,
+		RetryMaxAttempts: defaultTransientStreamMaxRetries,
+		RetryWaitSecs:    wait.Seconds(),
+		RetryReason:      class,
+	}); err != nil {
+		return false, err
+	}
+	slog.Debug("resubmitting model request after transient error", "attempt", *retries, "wait", wait, "error", cause)
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	case <-timer.C:
+		return true, nil
+	}
+}
+
 func isCommittedStreamRecoveryError(err error) bool {
 	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 		return false
@@ -1615,15 +1896,28 @@ func isCommittedStreamRecoveryError(err error) bool {
 
 func (e *Engine) runSimpleScratchpad(ctx context.Context, req Request, send eventSender) error {
 	turnCallback := e.getTurnCallback()
+	// Replies to a ResponseFormat the provider doesn't enforce are checked
+	// here, with one repair attempt before giving up.
+	checkFormat := req.ResponseFormat.structured() && !e.provider.Capabilities().StructuredOutput
+	repaired := false
 	var priorErr error
+	var transientRetries int
+	resends := 0 // retries of the current request; a repair starts a new one
 	for retry := 0; ; retry++ {
 		providerReq := e.prepareProviderRequest(req)
-		stream, err := e.provider.Stream(ctx, providerReq)
+		stream, err := e.streamWithMetrics(ctx, providerReq, -1, resends)
 		if err != nil {
+			if retried, retryErr := awaitTransientRetry(ctx, send, &transientRetries, err); retryErr != nil {
+				return retryErr
+			} else if retried {
+				resends++
+				continue
+			}
 			return err
 		}
 
 		var scratchpad []Event
+		var generatedImageParts []Part
 		var textBuilder strings.Builder
 		var reasoningBuilder strings.Builder
 		var reasoningTextItemID string
@@ -1698,6 +1992,10 @@ func (e *Engine) runSimpleScratchpad(ctx context.Context, req Request, send even
 					return err
 				}
 			case EventImageGenerated:
+				event = saveGeneratedImage(ctx, req.SessionID, event)
+				if event.ImagePath != "" {
+					generatedImageParts = append(generatedImageParts, generatedImagePart(event))
+				}
 				scratchpad = append(scratchpad, event)
 				if err := send.Send(event); err != nil {
 					_ = stream.Close()
@@ -1719,6 +2017,21 @@ func (e *Engine) runSimpleScratchpad(ctx context.Context, req Request, send even
 				return err
 			}
 			priorErr = failed
+			// Nothing visible was produced yet, so a transient failure can be
+			// resubmitted without the user seeing duplicated output.
+			if textBuilder.Len() == 0 && reasoningBuilder.Len() == 0 && len(generatedImageParts) == 0 && !isUncommittedReplayableStreamError(failed) {
+				if retried, retryErr := awaitTransientRetry(ctx, send, &transientRetries, failed); retryErr != nil {
+					return retryErr
+				} else if retried {
+					if len(scratchpad) > 0 {
+						if err := send.Send(Event{Type: EventAttemptDiscard}); err != nil {
+							return err
+						}
+					}
+					resends++
+					continue
+				}
+			}
 			if retry >= defaultUncommittedStreamMaxRetries || !isUncommittedReplayableStreamError(failed) {
 				return failed
 			}
@@ -1728,17 +2041,36 @@ func (e *Engine) runSimpleScratchpad(ctx context.Context, req Request, send even
 					return err
 				}
 			}
-			if err := send.Send(Event{Type: EventRetry, RetryAttempt: attempt, RetryMaxAttempts: defaultUncommittedStreamMaxRetries, RetryWaitSecs: 0}); err != nil {
+			if err := send.Send(Event{Type: EventRetry, RetryAttempt: attempt, RetryMaxAttempts: defaultUncommittedStreamMaxRetries, RetryWaitSecs: 0, RetryReason: ClassifyError(failed)}); err != nil {
 				return err
 			}
 			slog.Debug("retrying failed uncommitted model stream", "attempt", attempt, "error", failed)
+			resends++
 			continue
 		}
 
+		if checkFormat {
+			if err := ValidateStructuredReply(req.ResponseFormat, textBuilder.String()); err != nil {
+				if repaired {
+					return &StructuredOutputError{Reply: textBuilder.String(), Err: err}
+				}
+				repaired = true
+				slog.Debug("repairing invalid structured output", "error", err)
+				if len(scratchpad) > 0 {
+					if err := send.Send(Event{Type: EventAttemptDiscard}); err != nil {
+						return err
+					}
+				}
+				req.Messages = structuredOutputRepairMessages(req.Messages, textBuilder.String(), err)
+				resends = 0
+				continue
+			}
+		}
+
 		if textBuilder.Len() == 0 && reasoningBuilder.Len() == 0 && len(reasoningSummaryParts) == 0 && reasoningItemID == "" && reasoningEncryptedContent == "" && priorErr != nil {
 			return priorErr
 		}
-		if turnCallback != nil && (textBuilder.Len() > 0 || reasoningBuilder.Len() > 0 || len(reasoningSummaryParts) > 0 || reasoningItemID != "" || reasoningEncryptedContent != "") {
+		if turnCallback != nil && (textBuilder.Len() > 0 || reasoningBuilder.Len() > 0 || len(reasoningSummaryParts) > 0 || reasoningItemID != "" || reasoningEncryptedContent != "" || len(generatedImageParts) > 0) {
 			reasoningText := reasoningBuilder.String()
 			if reasoningText == "" && len(reasoningSummaryParts) > 0 {
 				reasoningText = strings.Join(reasoningSummaryParts, "\n\n")
@@ -1760,6 +2092,7 @@ func (e *Engine) runSimpleScratchpad(ctx context.Context, req Request, send even
 				ReasoningKind:             reasoningKind,
 				ReasoningSummaryTitle:     reasoningTitle,
 			}}}
+			finalMsg = attachGeneratedImageParts(finalMsg, generatedImageParts)
 			cbCtx, cancel := callbackContext(ctx)
 			_ = turnCallback(cbCtx, 0, []Message{finalMsg}, metrics)
 			cancel()
@@ -1800,9 +2133,15 @@ func (e *Engine) runLoop(ctx context.Context, req Request, send eventSender) err
 	responseCallback := e.getResponseCallback()
 	snapshotCallback := e.getSnapshotCallback()
 
+	// As in runSimpleScratchpad, a final reply to a ResponseFormat the
+	// provider doesn't enforce gets one repair attempt.
+	checkFormat := req.ResponseFormat.structured() && !e.provider.Capabilities().StructuredOutput
+	repairedFormat := false
+
 	e.callbackMu.RLock()
 	compactionConfig := e.compactionConfig
 	inputLimit := e.inputLimit
+	loopDetector := newToolLoopDetector(e.toolLoopThreshold)
 	e.callbackMu.RUnlock()
 
 	// Propagate provider-effective input limit into compaction config so
@@ -1832,6 +2171,7 @@ func (e *Engine) runLoop(ctx context.Context, req Request, send eventSender) err
 	var recoveredAtMessageCount = -1
 	var recoveryPriorErr error
 	var uncommittedStreamRetries int // retries for failed provider attempts whose assistant output never crossed a commit boundary
+	var transientStreamRetries int   // resubmissions after transient errors before any content; reset when an attempt completes
 	var uncommittedPriorErr error
 	var softCheckpointInjected bool
 	var softCheckpointInProgress bool
@@ -2013,7 +2353,7 @@ turnLoop:
 	for attempt := 0; attempt < maxTurns; attempt++ {
 		// A model-activated skill can tighten the filter between turns. Remove
 		// now-disallowed definitions before the next provider request.
-		req.Tools = e.FilterAllowedToolSpecs(req.Tools)
+		req.Tools = e.filterToolSpecs(ctx, req.Tools)
 		if len(req.Tools) == 0 {
 			req.ToolChoice = ToolChoice{}
 			req.LastTurnToolChoice = nil
@@ -2021,7 +2361,7 @@ turnLoop:
 
 		// Inject any tool specs registered mid-loop (e.g. via skill activation)
 		if pending := e.drainPendingToolSpecs(); len(pending) > 0 {
-			pending = e.FilterAllowedToolSpecs(pending)
+			pending = e.filterToolSpecs(ctx, pending)
 			for _, spec := range pending {
 				if !hasToolNamed(req.Tools, spec.Name) {
 					req.Tools = append(req.Tools, spec)
@@ -2093,19 +2433,17 @@ turnLoop:
 
 		providerReq := e.prepareProviderRequest(req)
 
-		// Log per-turn request state
+		// The debug middlewares log per-turn request state here.
 		// For attempt 0: captures state after applyExternalSearch modifications
 		// For attempt > 0: captures tool results appended in previous turn
-		if e.debugLogger != nil {
-			e.debugLogger.LogTurnRequest(attempt, e.provider.Name(), providerReq.Model, providerReq)
-		}
-
-		if req.DebugRaw {
-			DebugRawRequest(req.DebugRaw, e.provider.Name(), e.provider.Credential(), providerReq, fmt.Sprintf("Request (turn %d)", attempt))
-		}
-
-		stream, err := e.provider.Stream(ctx, providerReq)
+		stream, err := e.streamWithMetrics(ctx, providerReq, attempt, 0)
 		if err != nil {
+			if retried, retryErr := awaitTransientRetry(ctx, send, &transientStreamRetries, err); retryErr != nil {
+				return retryErr
+			} else if retried {
+				attempt--
+				continue
+			}
 			// Reactive compaction: if this is a context overflow error, try compacting and retrying (once)
 			if compactionConfig != nil && isContextOverflowError(err) && !reactiveCompactionDone {
 				reactiveCompactionDone = true
@@ -2148,6 +2486,7 @@ turnLoop:
 		var reasoningSummaryParts []string
 		var reasoningKind ReasoningKind
 		var providerReplayParts []Part
+		var generatedImageParts []Part
 		var turnMetrics TurnMetrics
 		var syncToolsExecuted bool     // Track if tools were executed via sync path (MCP)
 		var finishingToolExecuted bool // Track if a finishing tool was executed (agent done)
@@ -2186,6 +2525,7 @@ turnLoop:
 				reasoningKind,
 			)
 			msg = attachProviderReplayParts(msg, providerReplayParts)
+			msg = attachGeneratedImageParts(msg, generatedImageParts)
 			if len(msg.Parts) == 0 {
 				return
 			}
@@ -2244,6 +2584,7 @@ turnLoop:
 					reasoningKind,
 				)
 				assistantMsg = attachProviderReplayParts(assistantMsg, providerReplayParts)
+				assistantMsg = attachGeneratedImageParts(assistantMsg, generatedImageParts)
 				maybeCompactAfterLLMCall(append([]Message{assistantMsg}, syncToolResults...))
 				req.Messages = append(req.Messages, assistantMsg)
 				req.Messages = append(req.Messages, syncToolResults...)
@@ -2301,6 +2642,7 @@ turnLoop:
 					reasoningKind,
 				)
 				assistantMsg = attachProviderReplayParts(assistantMsg, providerReplayParts)
+				assistantMsg = attachGeneratedImageParts(assistantMsg, generatedImageParts)
 				if len(assistantMsg.Parts) > 0 {
 					maybeCompactAfterLLMCall([]Message{assistantMsg})
 					req.Messages = append(req.Messages, assistantMsg)
@@ -2324,6 +2666,7 @@ turnLoop:
 				reasoningKind,
 			)
 			assistantMsg = attachProviderReplayParts(assistantMsg, providerReplayParts)
+			assistantMsg = attachGeneratedImageParts(assistantMsg, generatedImageParts)
 			maybeCompactAfterLLMCall([]Message{assistantMsg})
 			responseHandled := callResponseCompletedCallback(ctx, responseCallback, attempt, assistantMsg, turnMetrics)
 
@@ -2406,12 +2749,33 @@ turnLoop:
 			return true, nil
 		}
 		retryUncommittedAttempt := func(cause error) (bool, error) {
-			if cause == nil || errors.Is(cause, context.Canceled) || errors.Is(cause, context.DeadlineExceeded) || !isUncommittedReplayableStreamError(cause) {
+			if cause == nil || errors.Is(cause, context.Canceled) || errors.Is(cause, context.DeadlineExceeded) {
 				return false, nil
 			}
 			if recoveredToolWork || len(toolCalls) > 0 || syncToolsExecuted || scratchpadCommitted {
 				return false, nil
 			}
+			if !isUncommittedReplayableStreamError(cause) {
+				// Other transient failures are only resubmitted while the
+				// attempt has produced no content at all.
+				if textBuilder.Len() > 0 || reasoningBuilder.Len() > 0 || len(syncToolCalls) > 0 || len(generatedImageParts) > 0 {
+					return false, nil
+				}
+				retried, err := awaitTransientRetry(ctx, send, &transientStreamRetries, cause)
+				if !retried || err != nil {
+					return false, err
+				}
+				if len(scratchpadEvents) > 0 {
+					if err := send.Send(Event{Type: EventAttemptDiscard}); err != nil {
+						return false, err
+					}
+				}
+				scratchpadEvents = nil
+				if softCheckpointInProgress {
+					softCompactionUsage = Usage{}
+				}
+				return true, nil
+			}
 			if uncommittedStreamRetries >= defaultUncommittedStreamMaxRetries {
 				return false, nil
 			}
@@ -2427,6 +2791,7 @@ turnLoop:
 				RetryAttempt:     uncommittedStreamRetries,
 				RetryMaxAttempts: defaultUncommittedStreamMaxRetries,
 				RetryWaitSecs:    0,
+				RetryReason:      ClassifyError(cause),
 			}); err != nil {
 				return false, err
 			}
@@ -2558,6 +2923,7 @@ turnLoop:
 				reasoningSummaryParts = nil
 				reasoningKind = ""
 				providerReplayParts = nil
+				generatedImageParts = nil
 				turnMetrics = TurnMetrics{}
 				if softCheckpointInProgress {
 					softCompactionUsage = Usage{}
@@ -2681,14 +3047,15 @@ turnLoop:
 					}
 
 					// Handle synchronous execution: emit events to TUI and send result back
+					started := time.Now()
 					call, result, execErr := e.handleSyncToolExecution(ctx, event, send, req.Debug, req.DebugRaw)
 					syncToolsExecuted = true
 					syncToolCalls = append(syncToolCalls, call)
 					// Build result message for this tool call
 					if execErr != nil {
-						syncToolResults = append(syncToolResults, ToolErrorMessage(call.ID, call.Name, execErr.Error(), nil))
+						syncToolResults = append(syncToolResults, finishToolResult(ToolErrorMessage(call.ID, call.Name, execErr.Error(), nil), time.Since(started)))
 					} else {
-						syncToolResults = append(syncToolResults, ToolResultMessageFromOutput(call.ID, call.Name, result, nil))
+						syncToolResults = append(syncToolResults, finishToolResult(ToolResultMessageFromOutput(call.ID, call.Name, result, nil), time.Since(started)))
 					}
 					// Check if this was a finishing tool (signals agent completion)
 					if e.tools.IsFinishingTool(event.Tool.Name) {
@@ -2735,6 +3102,10 @@ turnLoop:
 				continue
 			}
 			if event.Type == EventImageGenerated {
+				event = saveGeneratedImage(ctx, req.SessionID, event)
+				if event.ImagePath != "" {
+					generatedImageParts = append(generatedImageParts, generatedImagePart(event))
+				}
 				if err := stageOrSendModelEvent(event); err != nil {
 					return err
 				}
@@ -2753,6 +3124,7 @@ turnLoop:
 		if err := ctx.Err(); err != nil {
 			return err
 		}
+		transientStreamRetries = 0
 
 		// The stream reached its provider-defined end without an error. Commit any
 		// attempt-local assistant output before callbacks and final done/tool handling.
@@ -2776,11 +3148,27 @@ turnLoop:
 				restoredToolChoice = true
 				continue
 			}
+			if checkFormat && !softCheckpointInProgress {
+				if err := ValidateStructuredReply(req.ResponseFormat, textBuilder.String()); err != nil {
+					if repairedFormat || attempt >= maxTurns-1 {
+						return &StructuredOutputError{Reply: textBuilder.String(), Err: err}
+					}
+					repairedFormat = true
+					slog.Debug("repairing invalid structured output", "error", err)
+					if textBuilder.Len() > 0 {
+						if err := send.Send(Event{Type: EventAttemptDiscard}); err != nil {
+							return err
+						}
+					}
+					req.Messages = structuredOutputRepairMessages(req.Messages, textBuilder.String(), err)
+					continue
+				}
+			}
 			// Call turnCallback with final text-only response (no tools)
 			// Note: responseCallback is NOT called here because no tool execution follows.
 			// responseCallback is only for persisting assistant messages before tool execution.
 			var finalMsg Message
-			if textBuilder.Len() > 0 || reasoningBuilder.Len() > 0 || len(reasoningSummaryParts) > 0 || reasoningItemID != "" || reasoningEncryptedContent != "" || len(providerReplayParts) > 0 {
+			if textBuilder.Len() > 0 || reasoningBuilder.Len() > 0 || len(reasoningSummaryParts) > 0 || reasoningItemID != "" || reasoningEncryptedContent != "" || len(providerReplayParts) > 0 || len(generatedImageParts) > 0 {
 				finalMsg = buildAssistantMessageWithReasoningMetadata(
 					textBuilder.String(),
 					nil,
@@ -2791,6 +3179,7 @@ turnLoop:
 					reasoningKind,
 				)
 				finalMsg = attachProviderReplayParts(finalMsg, providerReplayParts)
+				finalMsg = attachGeneratedImageParts(finalMsg, generatedImageParts)
 				if softCheckpointInProgress {
 					brief := continuationBriefFromAssistantMessage(finalMsg)
 					if brief != "" && compactionConfig != nil && softCheckpointOriginalCount > 0 {
@@ -2887,6 +3276,7 @@ turnLoop:
 				reasoningKind,
 			)
 			assistantMsg = attachProviderReplayParts(assistantMsg, providerReplayParts)
+			assistantMsg = attachGeneratedImageParts(assistantMsg, generatedImageParts)
 			maybeCompactAfterLLMCall(append([]Message{assistantMsg}, syncToolResults...))
 			req.Messages = append(req.Messages, assistantMsg)
 			req.Messages = append(req.Messages, syncToolResults...)
@@ -3003,6 +3393,7 @@ turnLoop:
 				reasoningKind,
 			)
 			finalMsg = attachProviderReplayParts(finalMsg, providerReplayParts)
+			finalMsg = attachGeneratedImageParts(finalMsg, generatedImageParts)
 			if len(finalMsg.Parts) > 0 {
 				maybeCompactAfterLLMCall([]Message{finalMsg})
 				if turnCallback != nil {
@@ -3028,6 +3419,17 @@ turnLoop:
 			return &MaxTurnsExceededError{MaxTurns: maxTurns}
 		}
 
+		// Stop a model that keeps making the same calls after being told the
+		// result will not change, rather than letting it run to max turns.
+		loopAction := loopDetector.observe(textBuilder.String(), registered)
+		if loopAction == toolLoopAbort {
+			loopErr := &ToolLoopError{Tool: registered[0].Name, Repeats: loopDetector.repeats}
+			if err := send.Send(Event{Type: EventPhase, Text: WarningPhasePrefix + loopErr.Error() + ". Send a follow-up with different instructions."}); err != nil {
+				return err
+			}
+			return loopErr
+		}
+
 		// Build assistant message with text + tool calls + reasoning
 		// (built before tool execution so we can save it incrementally)
 		assistantMsg := buildAssistantMessageWithReasoningMetadata(
@@ -3040,6 +3442,7 @@ turnLoop:
 			reasoningKind,
 		)
 		assistantMsg = attachProviderReplayParts(assistantMsg, providerReplayParts)
+		assistantMsg = attachGeneratedImageParts(assistantMsg, generatedImageParts)
 
 		maybeCompactAfterLLMCall([]Message{assistantMsg})
 
@@ -3109,6 +3512,9 @@ turnLoop:
 
 		req.Messages = append(req.Messages, assistantMsg)
 		req.Messages = append(req.Messages, toolResults...)
+		if loopAction == toolLoopNudge {
+			req.Messages = append(req.Messages, SystemText(toolLoopNudgeText(registered)))
+		}
 		if err := applyPendingRequestModelSwitch(attempt + 1); err != nil {
 			return err
 		}
@@ -3139,6 +3545,8 @@ turnLoop:
 		// Check for user interjections queued during this turn.
 		// If present, inject them as FIFO user messages so the LLM sees them on the next turn.
 		if interjections := e.drainInterjections(); len(interjections) > 0 {
+			// New user input can make a repeated call worthwhile again.
+			loopDetector.reset()
 			interjectionMsgs := make([]Message, 0, len(interjections))
 			for _, interjection := range interjections {
 				interjectionMsg := interjection.Message
@@ -3212,6 +3620,9 @@ func buildAssistantMessageWithReasoningMetadata(text string, toolCalls []ToolCal
 }
 
 // executeToolCalls executes multiple tool calls, potentially in parallel.
+// Results are returned in call order. Serial tools (shell by default) never
+// overlap with other calls, and approval prompts are serialized by the
+// approval manager, so only one prompt is shown at a time.
 // Note: When executing in parallel, EventToolExecStart/EventToolExecEnd events
 // are emitted from concurrent goroutines. While the channel is thread-safe, events
 // may arrive in non-deterministic order. Consumers should use ToolCallID to correlate
@@ -3227,6 +3638,8 @@ func (e *Engine) executeToolCalls(ctx context.Context, calls []ToolCall, paralle
 	if err := ctx.Err(); err != nil {
 		return cancelledToolCallMessages(calls, err), nil
 	}
+	ctx, stopTurnDeadline := e.withTurnToolDeadline(ctx)
+	defer stopTurnDeadline()
 
 	// Fast path: single call, no concurrency overhead
 	if len(calls) == 1 {
@@ -3237,8 +3650,8 @@ func (e *Engine) executeToolCalls(ctx context.Context, calls []ToolCall, paralle
 		results := make([]Message, 0, len(calls))
 		toolCtx := ContextWithApprovalTranscript(ctx, transcript)
 		for i, call := range calls {
-			if err := ctx.Err(); err != nil {
-				return append(results, cancelledToolCallMessages(calls[i:], err)...), nil
+			if ctx.Err() != nil {
+				return append(results, cancelledToolCallMessages(calls[i:], context.Cause(ctx))...), nil
 			}
 			msgs, err := e.executeSingleToolCallSafe(toolCtx, call, send, debug, debugRaw)
 			if err != nil {
@@ -3262,8 +3675,11 @@ func (e *Engine) executeToolCalls(ctx context.Context, calls []ToolCall, paralle
 	}
 
 	resultChan := make(chan toolResult, len(calls))
-	workerCount := maxParallelToolWorkers(len(calls))
+	limit, serialTools := e.parallelToolSettings()
+	workerCount := maxParallelToolWorkers(len(calls), limit)
 	var nextCall atomic.Uint32
+	// Ordinary calls share the lock; a serial call takes it exclusively.
+	var exclusive sync.RWMutex
 
 	workerCtx := ContextWithApprovalTranscript(ctx, transcript)
 	for worker := 0; worker < workerCount; worker++ {
@@ -3283,7 +3699,13 @@ func (e *Engine) executeToolCalls(ctx context.Context, calls []ToolCall, paralle
 				}
 
 				call := calls[idx]
+				lock, unlock := exclusive.RLock, exclusive.RUnlock
+				if serialTools[call.Name] {
+					lock, unlock = exclusive.Lock, exclusive.Unlock
+				}
+				lock()
 				msgs, _ := e.executeSingleToolCallSafe(workerCtx, call, send, debug, debugRaw)
+				unlock()
 				msg := ToolErrorMessage(call.ID, call.Name, "tool returned no result", call.ThoughtSig)
 				if len(msgs) > 0 {
 					msg = msgs[0]
@@ -3316,7 +3738,7 @@ func (e *Engine) executeToolCalls(ctx context.Context, calls []ToolCall, paralle
 			}
 			for i := range results {
 				if results[i].Role == "" {
-					results[i] = cancelledToolCallMessage(calls[i], ctx.Err())
+					results[i] = cancelledToolCallMessage(calls[i], context.Cause(ctx))
 				}
 			}
 			return results, nil
@@ -3345,7 +3767,7 @@ func (e *Engine) executeSingleToolCallSafe(ctx context.Context, call ToolCall, s
 	defer func() {
 		if r := recover(); r != nil {
 			errMsg := fmt.Sprintf("Error: tool panicked: %v", r)
-			send.TrySend(Event{Type: EventToolExecEnd, ToolCallID: call.ID, ToolName: call.Name, ToolSuccess: false})
+			e.endToolExec(ctx, send, Event{ToolCallID: call.ID, ToolName: call.Name, ToolSuccess: false})
 			msgs = []Message{ToolErrorMessage(call.ID, call.Name, errMsg, call.ThoughtSig)}
 			err = nil
 		}
@@ -3392,7 +3814,7 @@ func (e *Engine) executeSingleToolCall(ctx context.Context, call ToolCall, send
 	if !ok {
 		errMsg := fmt.Sprintf("Error: tool not registered: %s", call.Name)
 		DebugToolResult(debug, call.ID, call.Name, errMsg)
-		send.TrySend(Event{Type: EventToolExecEnd, ToolCallID: call.ID, ToolName: call.Name, ToolInfo: e.getToolPreview(call), ToolSuccess: false})
+		e.endToolExec(ctx, send, Event{ToolCallID: call.ID, ToolName: call.Name, ToolInfo: e.getToolPreview(call), ToolSuccess: false})
 		return []Message{ToolErrorMessage(call.ID, call.Name, errMsg, call.ThoughtSig)}, nil
 	}
 
@@ -3400,7 +3822,13 @@ func (e *Engine) executeSingleToolCall(ctx context.Context, call ToolCall, send
 	if !e.IsToolAllowed(call.Name) {
 		errMsg := fmt.Sprintf("Error: tool '%s' is not in the active skill's allowed-tools list", call.Name)
 		DebugToolResult(debug, call.ID, call.Name, errMsg)
-		send.TrySend(Event{Type: EventToolExecEnd, ToolCallID: call.ID, ToolName: call.Name, ToolInfo: e.getToolPreview(call), ToolSuccess: false})
+		e.endToolExec(ctx, send, Event{ToolCallID: call.ID, ToolName: call.Name, ToolInfo: e.getToolPreview(call), ToolSuccess: false})
+		return []Message{ToolErrorMessage(call.ID, call.Name, errMsg, call.ThoughtSig)}, nil
+	}
+	if err := checkToolPermitted(ctx, call.Name); err != nil {
+		errMsg := "Error: " + err.Error()
+		DebugToolResult(debug, call.ID, call.Name, errMsg)
+		e.endToolExec(ctx, send, Event{ToolCallID: call.ID, ToolName: call.Name, ToolInfo: e.getToolPreview(call), ToolSuccess: false})
 		return []Message{ToolErrorMessage(call.ID, call.Name, errMsg, call.ThoughtSig)}, nil
 	}
 
@@ -3410,26 +3838,26 @@ func (e *Engine) executeSingleToolCall(ctx context.Context, call ToolCall, send
 	stopHeartbeat := startToolHeartbeat(ctx, call.ID, call.Name, send)
 	defer stopHeartbeat()
 
-	output, err := tool.Execute(toolCtx, call.Arguments)
+	started := time.Now()
+	output, err := e.runTool(toolCtx, tool, call)
+	elapsed := time.Since(started)
 	info := e.getToolPreview(call)
 
 	// Truncate large tool outputs (global limit, then compaction limit).
 	if err == nil {
-		output = e.applyToolOutputTruncation(output)
+		output = e.applyToolOutputTruncation(call.Name, output)
 	}
 
 	if err != nil {
 		errMsg := fmt.Sprintf("Error: %v", err)
 		DebugToolResult(debug, call.ID, call.Name, errMsg)
-		send.TrySend(Event{Type: EventToolExecEnd, ToolCallID: call.ID, ToolName: call.Name, ToolInfo: info, ToolSuccess: false})
-		return []Message{ToolErrorMessage(call.ID, call.Name, errMsg, call.ThoughtSig)}, nil
+		e.endToolExec(ctx, send, Event{ToolCallID: call.ID, ToolName: call.Name, ToolInfo: info, ToolSuccess: false})
+		return []Message{finishToolResult(ToolErrorMessage(call.ID, call.Name, errMsg, call.ThoughtSig), elapsed)}, nil
 	}
 
 	DebugToolResult(debug, call.ID, call.Name, output.Content)
 	DebugRawToolResult(debugRaw, call.ID, call.Name, output.Content)
-	// Best-effort: don't let a slow event consumer stall completed tool workers.
-	send.TrySend(Event{
-		Type:            EventToolExecEnd,
+	e.endToolExec(ctx, send, Event{
 		ToolCallID:      call.ID,
 		ToolName:        call.Name,
 		ToolInfo:        info,
@@ -3439,7 +3867,20 @@ func (e *Engine) executeSingleToolCall(ctx context.Context, call ToolCall, send
 		ToolFileChanges: output.FileChanges,
 		ToolImages:      output.Images,
 	})
-	return []Message{ToolResultMessageFromOutput(call.ID, call.Name, output, call.ThoughtSig)}, nil
+	return []Message{finishToolResult(ToolResultMessageFromOutput(call.ID, call.Name, output, call.ThoughtSig), elapsed)}, nil
+}
+
+// finishToolResult records how long the tool ran on its result message, so
+// the session store can report slow tools, and counts the execution in the
+// process-wide metrics.
+func finishToolResult(msg Message, elapsed time.Duration) Message {
+	for _, part := range msg.Parts {
+		if part.ToolResult != nil {
+			part.ToolResult.DurationMs = elapsed.Milliseconds()
+			metrics.ToolExecutions.With(part.ToolResult.Name, metrics.ToolOutcome(part.ToolResult.IsError)).Inc()
+		}
+	}
+	return msg
 }
 
 // handleSyncToolExecution handles synchronous tool execution for bridged providers.
@@ -3483,6 +3924,8 @@ func (e *Engine) handleSyncToolExecution(ctx context.Context, event Event, send
 		}
 	} else if !e.IsToolAllowed(call.Name) {
 		err = fmt.Errorf("tool '%s' is not in the active skill's allowed-tools list", call.Name)
+	} else if permitErr := checkToolPermitted(ctx, call.Name); permitErr != nil {
+		err = permitErr
 	} else {
 		toolCtx := ContextWithCallID(ctx, callID)
 		func() {
@@ -3491,13 +3934,13 @@ func (e *Engine) handleSyncToolExecution(ctx context.Context, event Event, send
 					err = fmt.Errorf("Error: tool panicked: %v", r)
 				}
 			}()
-			result, err = tool.Execute(toolCtx, call.Arguments)
+			result, err = e.runTool(toolCtx, tool, *call)
 		}()
 	}
 
 	// Truncate large tool outputs (global limit, then compaction limit).
 	if err == nil {
-		result = e.applyToolOutputTruncation(result)
+		result = e.applyToolOutputTruncation(call.Name, result)
 	}
 
 	// Debug logging
@@ -3508,8 +3951,7 @@ func (e *Engine) handleSyncToolExecution(ctx context.Context, event Event, send
 		DebugRawToolResult(debugRaw, callID, call.Name, result.Content)
 	}
 	// Emit end event to TUI (non-blocking to avoid deadlock if consumer is slow)
-	send.TrySend(Event{
-		Type:            EventToolExecEnd,
+	e.endToolExec(ctx, send, Event{
 		ToolCallID:      callID,
 		ToolName:        call.Name,
 		ToolInfo:        info,
@@ -3769,35 +4211,6 @@ func wrapLoggingStream(inner Stream, providerName, model string) Stream {
 	}
 }
 
-// wrapDebugLoggingStream wraps a stream with debug logging if enabled
-func (e *Engine) wrapDebugLoggingStream(inner Stream) Stream {
-	if e.debugLogger == nil {
-		return inner
-	}
-	return &debugLoggingStream{
-		inner:  inner,
-		logger: e.debugLogger,
-	}
-}
-
-// debugLoggingStream wraps a stream to log events for debugging
-type debugLoggingStream struct {
-	inner  Stream
-	logger *DebugLogger
-}
-
-func (s *debugLoggingStream) Recv() (Event, error) {
-	event, err := s.inner.Recv()
-	if err == nil {
-		s.logger.LogEvent(event)
-	}
-	return event, err
-}
-
-func (s *debugLoggingStream) Close() error {
-	return s.inner.Close()
-}
-
 // cleanupStream wraps a stream to call provider per-turn cleanup on terminal
 // conditions (io.EOF, EventDone, or Close). Used for per-turn resources such
 // as CLI-provider prompt/image files. MCP servers and other
@@ -0,0 +1,58 @@
+package llm
+
+import (
+	"context"
+	"strings"
+
+	"github.com/samsaffron/term-llm/internal/metrics"
+)
+
+// streamWithMetrics starts a provider stream through the engine middleware
+// and counts the request and the tokens it reports in the process-wide
+// metrics. turn is the agentic-loop turn, or -1 for a request without tools;
+// retry counts the earlier transient-error attempts of the same request.
+func (e *Engine) streamWithMetrics(ctx context.Context, req Request, turn, retry int) (Stream, error) {
+	return e.streamWithMiddleware(ctx, req, turn, retry, e.streamProvider)
+}
+
+func (e *Engine) streamProvider(ctx context.Context, req Request) (Stream, error) {
+	provider, model := metricsProviderLabels(e.provider.Name(), req.Model)
+	metrics.LLMRequests.With(provider, model).Inc()
+	stream, err := e.provider.Stream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &meteredStream{
+		Stream: stream,
+		input:  metrics.InputTokens.With(provider, model),
+		output: metrics.OutputTokens.With(provider, model),
+	}, nil
+}
+
+// metricsProviderLabels splits a provider display name such as
+// "Anthropic (claude-sonnet-4-6)" into provider and model labels. The
+// request's model wins when set.
+func metricsProviderLabels(name, model string) (string, string) {
+	provider := name
+	if before, after, ok := strings.Cut(name, " ("); ok {
+		provider = before
+		if model == "" {
+			model = strings.TrimSuffix(after, ")")
+		}
+	}
+	return provider, model
+}
+
+type meteredStream struct {
+	Stream
+	input, output *metrics.Counter
+}
+
+func (s *meteredStream) Recv() (Event, error) {
+	event, err := s.Stream.Recv()
+	if err == nil && event.Type == EventUsage && event.Use != nil {
+		s.input.Add(int64(event.Use.InputTokens + event.Use.CachedInputTokens + event.Use.CacheWriteTokens))
+		s.output.Add(int64(event.Use.OutputTokens))
+	}
+	return event, err
+}
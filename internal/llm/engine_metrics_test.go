@@ -0,0 +1,51 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/samsaffron/term-llm/internal/metrics"
+)
+
+func TestEngineRecordsRequestAndTokenMetrics(t *testing.T) {
+	provider := NewMockProvider("MetricsTest (metrics-model)").
+		AddTurn(MockTurn{Text: "ok", Usage: Usage{InputTokens: 10, CachedInputTokens: 5, OutputTokens: 7}})
+	requests := metrics.LLMRequests.With("MetricsTest", "metrics-model")
+	input := metrics.InputTokens.With("MetricsTest", "metrics-model")
+	output := metrics.OutputTokens.With("MetricsTest", "metrics-model")
+	beforeRequests, beforeInput, beforeOutput := requests.Value(), input.Value(), output.Value()
+
+	stream, err := NewEngine(provider, nil).Stream(context.Background(), Request{Messages: []Message{UserText("hi")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+	drainStream(t, stream)
+
+	if got := requests.Value() - beforeRequests; got != 1 {
+		t.Errorf("requests counted = %d, want 1", got)
+	}
+	if got := input.Value() - beforeInput; got != 15 {
+		t.Errorf("input tokens counted = %d, want 15 (fresh plus cached)", got)
+	}
+	if got := output.Value() - beforeOutput; got != 7 {
+		t.Errorf("output tokens counted = %d, want 7", got)
+	}
+}
+
+func TestMetricsProviderLabels(t *testing.T) {
+	tests := []struct {
+		name, model             string
+		wantProvider, wantModel string
+	}{
+		{"Anthropic (claude-sonnet-4-6)", "", "Anthropic", "claude-sonnet-4-6"},
+		{"Anthropic (claude-sonnet-4-6)", "claude-opus-4-1", "Anthropic", "claude-opus-4-1"},
+		{"mock", "", "mock", ""},
+	}
+	for _, tt := range tests {
+		provider, model := metricsProviderLabels(tt.name, tt.model)
+		if provider != tt.wantProvider || model != tt.wantModel {
+			t.Errorf("metricsProviderLabels(%q, %q) = %q, %q; want %q, %q", tt.name, tt.model, provider, model, tt.wantProvider, tt.wantModel)
+		}
+	}
+}
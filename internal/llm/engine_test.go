@@ -765,6 +765,9 @@ func TestEngineExternalSearchStopsAfterMaxLoops(t *testing.T) {
 	}
 
 	engine := NewEngine(provider, registry)
+	// Every call is identical; keep loop detection out of the way so the
+	// max-turns limit is what stops it.
+	engine.SetToolLoopThreshold(0)
 	req := Request{
 		Messages: []Message{UserText("latest release")},
 		Search:   true,
@@ -1395,6 +1398,81 @@ func TestEngineParallelToolExecutionRespectsDefaultLimit(t *testing.T) {
 	}
 }
 
+// overlapTracker records how many tools run at once across several tools.
+type overlapTracker struct {
+	mu          sync.Mutex
+	current     int
+	peak        int
+	serialAlone bool
+}
+
+type trackedTool struct {
+	name    string
+	serial  bool
+	tracker *overlapTracker
+}
+
+func (t *trackedTool) Spec() ToolSpec {
+	return ToolSpec{Name: t.name, Description: "tracks overlap", Schema: map[string]any{"type": "object"}}
+}
+
+func (t *trackedTool) Execute(ctx context.Context, args json.RawMessage) (ToolOutput, error) {
+	t.tracker.mu.Lock()
+	t.tracker.current++
+	t.tracker.peak = max(t.tracker.peak, t.tracker.current)
+	if t.serial && t.tracker.current != 1 {
+		t.tracker.serialAlone = false
+	}
+	t.tracker.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	t.tracker.mu.Lock()
+	if t.serial && t.tracker.current != 1 {
+		t.tracker.serialAlone = false
+	}
+	t.tracker.current--
+	t.tracker.mu.Unlock()
+	return TextOutput(t.name), nil
+}
+
+func (t *trackedTool) Preview(args json.RawMessage) string {
+	return ""
+}
+
+func TestExecuteToolCallsParallelHonorsLimitAndSerialTools(t *testing.T) {
+	t.Parallel()
+
+	tracker := &overlapTracker{serialAlone: true}
+	registry := NewToolRegistry()
+	registry.Register(&trackedTool{name: "read", tracker: tracker})
+	registry.Register(&trackedTool{name: "shell", serial: true, tracker: tracker})
+	engine := NewEngine(&fakeProvider{}, registry)
+	engine.SetParallelTools(2, nil)
+
+	names := []string{"read", "read", "shell", "read", "read", "shell", "read"}
+	calls := make([]ToolCall, len(names))
+	for i, name := range names {
+		calls[i] = ToolCall{ID: fmt.Sprintf("call-%d", i), Name: name, Arguments: json.RawMessage(`{}`)}
+	}
+
+	results, err := engine.executeToolCalls(context.Background(), calls, true, eventSender{}, false, false)
+	if err != nil {
+		t.Fatalf("executeToolCalls: %v", err)
+	}
+	for i, msg := range results {
+		if len(msg.Parts) == 0 || msg.Parts[0].ToolResult == nil || msg.Parts[0].ToolResult.ID != calls[i].ID {
+			t.Fatalf("result %d = %+v, want the result for %s", i, msg, calls[i].ID)
+		}
+	}
+	if tracker.peak != 2 {
+		t.Fatalf("peak concurrency = %d, want the limit of 2", tracker.peak)
+	}
+	if !tracker.serialAlone {
+		t.Fatal("shell calls overlapped with other tool calls")
+	}
+}
+
 func TestExecuteToolCallsParallelReturnsOnContextCancel(t *testing.T) {
 	t.Parallel()
 
@@ -4651,6 +4729,65 @@ func (t *panickingTool) Preview(args json.RawMessage) string {
 	return ""
 }
 
+func TestEngineToolExecutedCallbackReportsEveryCall(t *testing.T) {
+	t.Parallel()
+
+	counting, panicking := &countingTool{}, &panickingTool{}
+	registry := NewToolRegistry()
+	registry.Register(counting)
+	registry.Register(panicking)
+
+	provider := &fakeProvider{
+		script: func(call int, req Request) []Event {
+			if call == 0 {
+				return []Event{
+					{Type: EventToolCall, Tool: &ToolCall{ID: "call-1", Name: "count_tool", Arguments: json.RawMessage(`{}`)}},
+					{Type: EventToolCall, Tool: &ToolCall{ID: "call-2", Name: "panic_tool", Arguments: json.RawMessage(`{}`)}},
+					{Type: EventDone},
+				}
+			}
+			return []Event{{Type: EventTextDelta, Text: "done"}, {Type: EventDone}}
+		},
+	}
+
+	engine := NewEngine(provider, registry)
+	var mu sync.Mutex
+	got := map[string]Event{}
+	engine.SetToolExecutedCallback(func(ctx context.Context, event Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		got[event.ToolCallID] = event
+	})
+
+	stream, err := engine.Stream(context.Background(), Request{
+		Messages: []Message{UserText("run both")},
+		Tools:    []ToolSpec{counting.Spec(), panicking.Spec()},
+	})
+	if err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+	defer stream.Close()
+	for {
+		if _, err := stream.Recv(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("recv error: %v", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 2 {
+		t.Fatalf("callback saw %d calls, want 2: %+v", len(got), got)
+	}
+	if ev := got["call-1"]; ev.Type != EventToolExecEnd || ev.ToolName != "count_tool" || !ev.ToolSuccess {
+		t.Fatalf("count_tool event = %+v, want a successful tool_exec_end", ev)
+	}
+	if ev := got["call-2"]; ev.ToolName != "panic_tool" || ev.ToolSuccess {
+		t.Fatalf("panic_tool event = %+v, want a failed tool_exec_end", ev)
+	}
+}
+
 func TestEnginePanickingToolSingleCall(t *testing.T) {
 	t.Parallel()
 
@@ -5695,3 +5832,48 @@ func TestEngineIndirectVisionCopiesNonUploadImageToUploads(t *testing.T) {
 		t.Fatalf("copied upload bytes = %q, want hello", got)
 	}
 }
+
+func TestEngineSystemContextIsEvaluatedPerRequest(t *testing.T) {
+	t.Parallel()
+
+	provider := NewMockProvider("mock").WithCapabilities(Capabilities{})
+	provider.AddTextResponse("one").AddTextResponse("two")
+	engine := NewEngine(provider, nil)
+	injected := "<project_context>v1</project_context>"
+	engine.SetSystemContext(func() string { return injected })
+
+	run := func(messages []Message) {
+		t.Helper()
+		stream, err := engine.Stream(t.Context(), Request{Messages: messages})
+		if err != nil {
+			t.Fatalf("Stream: %v", err)
+		}
+		defer stream.Close()
+		for {
+			if _, err := stream.Recv(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("Recv: %v", err)
+			}
+		}
+	}
+
+	history := []Message{SystemText("be brief"), UserText("hi")}
+	run(history)
+	injected = ""
+	run([]Message{UserText("no system prompt")})
+
+	first := provider.Requests[0].Messages
+	if got := MessageText(first[0]); got != "be brief\n\n<project_context>v1</project_context>" {
+		t.Fatalf("system prompt = %q", got)
+	}
+	if parts := first[0].Parts; len(parts) != 2 || parts[0].Text != "be brief" {
+		t.Fatalf("system parts = %#v, want the original part kept and the context appended", parts)
+	}
+	if MessageText(history[0]) != "be brief" {
+		t.Fatal("system context leaked into the caller's history")
+	}
+	if second := provider.Requests[1].Messages; len(second) != 1 || second[0].Role != RoleUser {
+		t.Fatalf("empty context should leave the request alone, got %#v", second)
+	}
+}
@@ -0,0 +1,175 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/samsaffron/term-llm/internal/providerhttp"
+)
+
+// ErrorClass groups provider and transport errors by how the engine and the
+// retry wrapper should react to them. ClassifyError is the single place that
+// maps errors to classes.
+type ErrorClass string
+
+const (
+	ErrorClassNone             ErrorClass = ""
+	ErrorClassCanceled         ErrorClass = "canceled"          // caller cancelled or its deadline passed
+	ErrorClassContextOverflow  ErrorClass = "context_overflow"  // request exceeds the model's context window
+	ErrorClassRateLimited      ErrorClass = "rate_limited"      // 429 and provider rate-limit messages
+	ErrorClassOverloaded       ErrorClass = "overloaded"        // 5xx and "overloaded" responses
+	ErrorClassConnection       ErrorClass = "connection"        // resets, refusals, timeouts, DNS failures
+	ErrorClassIncompleteStream ErrorClass = "incomplete_stream" // stream closed before its terminal event
+	ErrorClassPermanent        ErrorClass = "permanent"         // anything that fails the same way on retry
+)
+
+// Transient reports whether an error of this class may succeed if the same
+// request is sent again.
+func (c ErrorClass) Transient() bool {
+	switch c {
+	case ErrorClassRateLimited, ErrorClassOverloaded, ErrorClassConnection, ErrorClassIncompleteStream:
+		return true
+	default:
+		return false
+	}
+}
+
+// Label is a short human-readable description for retry status lines.
+func (c ErrorClass) Label() string {
+	switch c {
+	case ErrorClassRateLimited:
+		return "rate limited"
+	case ErrorClassOverloaded:
+		return "provider overloaded"
+	case ErrorClassConnection:
+		return "connection error"
+	case ErrorClassIncompleteStream:
+		return "stream interrupted"
+	case ErrorClassContextOverflow:
+		return "context overflow"
+	case ErrorClassCanceled:
+		return "canceled"
+	case ErrorClassNone:
+		return ""
+	default:
+		return "error"
+	}
+}
+
+// contextOverflowPatterns match context-window errors across providers.
+var contextOverflowPatterns = []string{
+	"context length exceeded",
+	"maximum context length",
+	"context_length_exceeded",
+	"too many tokens",
+	"request too large",
+	"prompt is too long",
+	"input is too long",
+	"content too large",
+	"token limit",
+	"exceeds the model's maximum context",
+}
+
+// ClassifyError maps err to an ErrorClass. Structured errors (status codes,
+// rate-limit and stream errors) are checked before falling back to matching
+// the message text of providers that only return strings.
+func ClassifyError(err error) ErrorClass {
+	if err == nil {
+		return ErrorClassNone
+	}
+
+	errStr := strings.ToLower(err.Error())
+	for _, p := range contextOverflowPatterns {
+		if strings.Contains(errStr, p) {
+			return ErrorClassContextOverflow
+		}
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return ErrorClassCanceled
+	}
+
+	// A missing or unauthenticated CLI binary fails identically on every
+	// attempt, and an error the retry wrapper already gave up on should not be
+	// retried from scratch.
+	if errors.Is(err, ErrCLINotInstalled) || errors.Is(err, ErrCLINotAuthenticated) {
+		return ErrorClassPermanent
+	}
+	var exhausted *retriesExhaustedError
+	if errors.As(err, &exhausted) {
+		return ErrorClassPermanent
+	}
+
+	var incomplete *StreamIncompleteError
+	if errors.As(err, &incomplete) {
+		return ErrorClassIncompleteStream
+	}
+
+	var statusErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &statusErr) {
+		code := statusErr.HTTPStatusCode()
+		switch {
+		case code == http.StatusTooManyRequests:
+			return ErrorClassRateLimited
+		case code >= http.StatusInternalServerError:
+			return ErrorClassOverloaded
+		case providerhttp.RetryableStatus(code):
+			return ErrorClassConnection
+		default:
+			return ErrorClassPermanent
+		}
+	}
+
+	// Long Retry-After waits are handled by the retry loop's elapsed-time
+	// budget, not by classification.
+	var rle *RateLimitError
+	if errors.As(err, &rle) {
+		return ErrorClassRateLimited
+	}
+
+	if strings.Contains(errStr, "429") ||
+		strings.Contains(errStr, "rate limit") ||
+		strings.Contains(errStr, "too many requests") ||
+		strings.Contains(errStr, "high concurrency") {
+		return ErrorClassRateLimited
+	}
+
+	if containsHTTP5xxStatus(errStr) ||
+		strings.Contains(errStr, "internal server error") ||
+		strings.Contains(errStr, "bad gateway") ||
+		strings.Contains(errStr, "service unavailable") ||
+		strings.Contains(errStr, "overloaded") ||
+		strings.Contains(errStr, "api error: terminated") {
+		return ErrorClassOverloaded
+	}
+
+	if strings.Contains(errStr, "connection refused") ||
+		strings.Contains(errStr, "connection reset") ||
+		strings.Contains(errStr, "timeout") ||
+		strings.Contains(errStr, "deadline exceeded") ||
+		strings.Contains(errStr, "temporary failure") ||
+		strings.Contains(errStr, "no such host") ||
+		strings.Contains(errStr, "unexpected eof") ||
+		strings.Contains(errStr, "broken pipe") {
+		return ErrorClassConnection
+	}
+
+	return ErrorClassPermanent
+}
+
+// isContextOverflowError checks whether an error indicates that the context
+// window was exceeded.
+func isContextOverflowError(err error) bool {
+	return ClassifyError(err) == ErrorClassContextOverflow
+}
+
+// http5xxStatusRegex matches standalone HTTP 5xx status codes in legacy string
+// errors from providers that have not attached structured status metadata.
+var http5xxStatusRegex = regexp.MustCompile(`\b5\d\d\b`)
+
+func containsHTTP5xxStatus(message string) bool {
+	return http5xxStatusRegex.MatchString(message)
+}
@@ -0,0 +1,192 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorClass
+	}{
+		{"nil", nil, ErrorClassNone},
+		{"canceled", context.Canceled, ErrorClassCanceled},
+		{"wrapped deadline", fmt.Errorf("stream: %w", context.DeadlineExceeded), ErrorClassCanceled},
+		{"context overflow", errors.New("prompt is too long: 210000 tokens"), ErrorClassContextOverflow},
+		{"cli not installed", ErrCLINotInstalled, ErrorClassPermanent},
+		{"incomplete stream", &StreamIncompleteError{Transport: "SSE"}, ErrorClassIncompleteStream},
+		{"status 429", newHTTPStatusErrorString("test", http.StatusTooManyRequests, "429", nil, "slow down"), ErrorClassRateLimited},
+		{"status 502", newHTTPStatusErrorString("test", http.StatusBadGateway, "502", nil, "bad gateway"), ErrorClassOverloaded},
+		{"status 408", newHTTPStatusErrorString("test", http.StatusRequestTimeout, "408", nil, "timeout"), ErrorClassConnection},
+		{"status 400", newHTTPStatusErrorString("test", http.StatusBadRequest, "400", nil, "bad request"), ErrorClassPermanent},
+		{"rate limit error", &RateLimitError{Message: "quota"}, ErrorClassRateLimited},
+		{"overloaded text", errors.New("anthropic: overloaded_error"), ErrorClassOverloaded},
+		{"5xx text", errors.New("API error 503"), ErrorClassOverloaded},
+		{"connection reset", errors.New("read tcp: connection reset by peer"), ErrorClassConnection},
+		{"unexpected eof", io.ErrUnexpectedEOF, ErrorClassConnection},
+		{"exhausted retries", &retriesExhaustedError{errors.New("502 bad gateway")}, ErrorClassPermanent},
+		{"unknown", errors.New("invalid tool schema"), ErrorClassPermanent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.err); got != tt.want {
+				t.Fatalf("ClassifyError(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorClassTransient(t *testing.T) {
+	for _, c := range []ErrorClass{ErrorClassRateLimited, ErrorClassOverloaded, ErrorClassConnection, ErrorClassIncompleteStream} {
+		if !c.Transient() {
+			t.Fatalf("%q should be transient", c)
+		}
+	}
+	for _, c := range []ErrorClass{ErrorClassNone, ErrorClassCanceled, ErrorClassContextOverflow, ErrorClassPermanent} {
+		if c.Transient() {
+			t.Fatalf("%q should not be transient", c)
+		}
+	}
+}
+
+// flakyProvider fails stream creation for the first createFailures calls and
+// then plays script.
+type flakyProvider struct {
+	fakeProvider
+	createFailures int
+	createErr      error
+}
+
+func (p *flakyProvider) Stream(ctx context.Context, req Request) (Stream, error) {
+	if p.createFailures > 0 {
+		p.createFailures--
+		p.calls = append(p.calls, req)
+		return nil, p.createErr
+	}
+	return p.fakeProvider.Stream(ctx, req)
+}
+
+func useFastTransientRetries(t *testing.T) {
+	t.Helper()
+	orig := transientRetryBackoff
+	transientRetryBackoff = RetryConfig{BaseBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	t.Cleanup(func() { transientRetryBackoff = orig })
+}
+
+func collectEngineEvents(t *testing.T, engine *Engine, req Request) ([]Event, error) {
+	t.Helper()
+	stream, err := engine.Stream(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	var events []Event
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return events, err
+		}
+		if event.Type == EventError && event.Err != nil {
+			return events, event.Err
+		}
+		events = append(events, event)
+	}
+}
+
+func TestEngine_ResubmitsAfterTransientStreamCreationError(t *testing.T) {
+	useFastTransientRetries(t)
+	provider := &flakyProvider{
+		fakeProvider: fakeProvider{script: func(call int, req Request) []Event {
+			return []Event{{Type: EventTextDelta, Text: "hello"}, {Type: EventDone}}
+		}},
+		createFailures: 2,
+		createErr:      newHTTPStatusErrorString("test", http.StatusBadGateway, "502 Bad Gateway", nil, "upstream"),
+	}
+	engine := NewEngine(provider, NewToolRegistry())
+
+	events, err := collectEngineEvents(t, engine, Request{Messages: []Message{UserText("hi")}})
+	if err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+	var retries []Event
+	var text strings.Builder
+	for _, ev := range events {
+		switch ev.Type {
+		case EventRetry:
+			retries = append(retries, ev)
+		case EventTextDelta:
+			text.WriteString(ev.Text)
+		}
+	}
+	if len(retries) != 2 || retries[1].RetryAttempt != 2 || retries[1].RetryMaxAttempts != defaultTransientStreamMaxRetries || retries[1].RetryReason != ErrorClassOverloaded {
+		t.Fatalf("retry events = %+v, want 2 overloaded retries out of %d", retries, defaultTransientStreamMaxRetries)
+	}
+	if text.String() != "hello" {
+		t.Fatalf("text = %q, want hello", text.String())
+	}
+}
+
+func TestEngine_GivesUpAfterTransientRetryLimit(t *testing.T) {
+	useFastTransientRetries(t)
+	provider := &flakyProvider{
+		fakeProvider:   fakeProvider{script: func(int, Request) []Event { return []Event{{Type: EventDone}} }},
+		createFailures: defaultTransientStreamMaxRetries + 1,
+		createErr:      errors.New("dial tcp: connection refused"),
+	}
+	engine := NewEngine(provider, NewToolRegistry())
+
+	_, err := collectEngineEvents(t, engine, Request{Messages: []Message{UserText("hi")}})
+	if err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Fatalf("err = %v, want connection refused", err)
+	}
+	if len(provider.calls) != defaultTransientStreamMaxRetries+1 {
+		t.Fatalf("provider calls = %d, want %d", len(provider.calls), defaultTransientStreamMaxRetries+1)
+	}
+}
+
+func TestEngine_ResubmitsEarlyStreamFailureOnlyBeforeContent(t *testing.T) {
+	useFastTransientRetries(t)
+	reset := errors.New("read: connection reset by peer")
+	tool := &countingTool{}
+	registry := NewToolRegistry()
+	registry.Register(tool)
+
+	for _, tc := range []struct {
+		name      string
+		first     []Event
+		wantCalls int
+		wantErr   bool
+	}{
+		{"before content", []Event{{Type: EventError, Err: reset}}, 2, false},
+		{"after text", []Event{{Type: EventTextDelta, Text: "par"}, {Type: EventError, Err: reset}}, 1, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := &fakeProvider{script: func(call int, req Request) []Event {
+				if call == 0 {
+					return tc.first
+				}
+				return []Event{{Type: EventTextDelta, Text: "ok"}, {Type: EventDone}}
+			}}
+			engine := NewEngine(provider, registry)
+
+			_, err := collectEngineEvents(t, engine, Request{Messages: []Message{UserText("hi")}, Tools: []ToolSpec{tool.Spec()}})
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("err = %v, wantErr %t", err, tc.wantErr)
+			}
+			if len(provider.calls) != tc.wantCalls {
+				t.Fatalf("provider calls = %d, want %d", len(provider.calls), tc.wantCalls)
+			}
+		})
+	}
+}
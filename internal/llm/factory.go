@@ -64,8 +64,12 @@ func NewProvider(cfg *config.Config) (Provider, error) {
 	if err != nil {
 		return nil, err
 	}
-	// Wrap with retry logic (enabled by default)
-	return WrapWithRetry(provider, DefaultRetryConfig()), nil
+	// Wrap with retry logic (enabled by default) and any fallbacks
+	model := ""
+	if providerCfg, ok := cfg.Providers[cfg.DefaultProvider]; ok {
+		model = providerCfg.Model
+	}
+	return withConfiguredFallbacks(cfg, cfg.DefaultProvider, model, provider), nil
 }
 
 // NewProviderByName creates a provider by name from the config, with an optional model override.
@@ -73,10 +77,19 @@ func NewProvider(cfg *config.Config) (Provider, error) {
 // If the provider is a built-in type but not explicitly configured,
 // it will be created with default settings.
 func NewProviderByName(cfg *config.Config, name string, model string) (Provider, error) {
+	provider, err := newProviderByName(cfg, name, model)
+	if err != nil {
+		return nil, err
+	}
+	return withConfiguredFallbacks(cfg, name, model, provider), nil
+}
+
+// newProviderByName creates the provider for NewProviderByName without
+// retry or fallback wrappers.
+func newProviderByName(cfg *config.Config, name string, model string) (Provider, error) {
 	// Handle hidden debug provider first
 	if name == "debug" {
-		provider := NewDebugProvider(model)
-		return WrapWithRetry(provider, DefaultRetryConfig()), nil
+		return NewDebugProvider(model), nil
 	}
 
 	providerCfg, ok := cfg.Providers[name]
@@ -90,30 +103,30 @@ func NewProviderByName(cfg *config.Config, name string, model string) (Provider,
 			if err != nil {
 				return nil, fmt.Errorf("provider anthropic: %w", err)
 			}
-			return WrapWithRetry(provider, DefaultRetryConfig()), nil
+			return provider, nil
 		case config.ProviderTypeClaudeBin:
 			// claude-bin doesn't need API key, can create directly
 			if err := ValidateClaudeBinModel(model); err != nil {
 				return nil, err
 			}
 			provider := NewClaudeBinProvider(model, nil)
-			return WrapWithRetry(provider, DefaultRetryConfig()), nil
+			return provider, nil
 		case config.ProviderTypeGrokBin:
 			if err := ValidateGrokBinModel(model); err != nil {
 				return nil, err
 			}
 			provider := NewGrokBinProvider(model, nil)
-			return WrapWithRetry(provider, DefaultRetryConfig()), nil
+			return provider, nil
 		case config.ProviderTypeZen:
 			// zen can work without API key (free tier)
 			provider := NewZenProvider("", model)
-			return WrapWithRetry(provider, DefaultRetryConfig()), nil
+			return provider, nil
 		case config.ProviderTypeBedrock:
 			provider, err := NewBedrockProvider(model, "", "", "", "", "", nil)
 			if err != nil {
 				return nil, fmt.Errorf("provider bedrock: %w", err)
 			}
-			return WrapWithRetry(provider, DefaultRetryConfig()), nil
+			return provider, nil
 		case config.ProviderTypeXAI:
 			// xai can use XAI_API_KEY env var
 			apiKey := os.Getenv("XAI_API_KEY")
@@ -121,28 +134,28 @@ func NewProviderByName(cfg *config.Config, name string, model string) (Provider,
 				return nil, fmt.Errorf("provider %q requires XAI_API_KEY environment variable or explicit config", name)
 			}
 			provider := NewXAIProvider(apiKey, model)
-			return WrapWithRetry(provider, DefaultRetryConfig()), nil
+			return provider, nil
 		case config.ProviderTypeVenice:
 			apiKey := strings.TrimSpace(os.Getenv("VENICE_API_KEY"))
 			if apiKey == "" {
 				return nil, fmt.Errorf("provider %q requires VENICE_API_KEY or explicit config", name)
 			}
 			provider := NewVeniceProvider(apiKey, model)
-			return WrapWithRetry(provider, DefaultRetryConfig()), nil
+			return provider, nil
 		case config.ProviderTypeNearAI:
 			apiKey := strings.TrimSpace(os.Getenv("NEARAI_API_KEY"))
 			if apiKey == "" {
 				return nil, fmt.Errorf("provider %q requires NEARAI_API_KEY or explicit config", name)
 			}
 			provider := NewNearAIProvider(apiKey, model)
-			return WrapWithRetry(provider, DefaultRetryConfig()), nil
+			return provider, nil
 		case config.ProviderTypeSambaNova:
 			apiKey := strings.TrimSpace(os.Getenv("SAMBANOVA_API_KEY"))
 			if apiKey == "" {
 				return nil, fmt.Errorf("provider %q requires SAMBANOVA_API_KEY or explicit config", name)
 			}
 			provider := NewSambaNovaProvider(apiKey, model)
-			return WrapWithRetry(provider, DefaultRetryConfig()), nil
+			return provider, nil
 		case config.ProviderTypeGemini:
 			// gemini can use GEMINI_API_KEY env var
 			apiKey := os.Getenv("GEMINI_API_KEY")
@@ -150,21 +163,21 @@ func NewProviderByName(cfg *config.Config, name string, model string) (Provider,
 				return nil, fmt.Errorf("provider %q requires GEMINI_API_KEY environment variable or explicit config", name)
 			}
 			provider := NewGeminiProvider(apiKey, model)
-			return WrapWithRetry(provider, DefaultRetryConfig()), nil
+			return provider, nil
 		case config.ProviderTypeChatGPT:
 			// chatgpt uses native OAuth with interactive authentication
 			provider, err := NewChatGPTProvider(model)
 			if err != nil {
 				return nil, fmt.Errorf("provider chatgpt: %w", err)
 			}
-			return WrapWithRetry(provider, DefaultRetryConfig()), nil
+			return provider, nil
 		case config.ProviderTypeCopilot:
 			// copilot uses GitHub device code OAuth with interactive authentication
 			provider, err := NewCopilotProvider(model)
 			if err != nil {
 				return nil, fmt.Errorf("provider copilot: %w", err)
 			}
-			return WrapWithRetry(provider, DefaultRetryConfig()), nil
+			return provider, nil
 		case config.ProviderTypeGeminiCLI:
 			// gemini-cli uses OAuth credentials from ~/.gemini/oauth_creds.json
 			creds, err := credentials.GetGeminiOAuthCredentials()
@@ -172,11 +185,11 @@ func NewProviderByName(cfg *config.Config, name string, model string) (Provider,
 				return nil, fmt.Errorf("provider gemini-cli: %w", err)
 			}
 			provider := NewGeminiCLIProvider(creds, model)
-			return WrapWithRetry(provider, DefaultRetryConfig()), nil
+			return provider, nil
 		case config.ProviderTypeOllama:
 			// ollama connects to a local server; no credentials needed
 			provider := NewOllamaChatProvider("", model, OllamaOptions{})
-			return WrapWithRetry(provider, DefaultRetryConfig()), nil
+			return provider, nil
 		default:
 			return nil, fmt.Errorf("provider %q not configured", name)
 		}
@@ -196,7 +209,7 @@ func NewProviderByName(cfg *config.Config, name string, model string) (Provider,
 	if err != nil {
 		return nil, err
 	}
-	return WrapWithRetry(provider, DefaultRetryConfig()), nil
+	return provider, nil
 }
 
 // NewFastProvider creates a lightweight provider instance for the specified provider key.
@@ -344,6 +357,7 @@ func createProviderFromConfig(name string, cfg *config.ProviderConfig) (Provider
 			return nil, err
 		}
 		provider.fileUploadPolicy = cloneFileUploadPolicy(FileUploadPolicyOverrideForProviderConfig(name, *cfg))
+		provider.noEncryptedReasoning = cfg.EncryptedReasoning != nil && !*cfg.EncryptedReasoning
 		return provider, nil
 
 	case config.ProviderTypeOpenRouter:
@@ -451,11 +465,15 @@ func createProviderFromConfig(name string, cfg *config.ProviderConfig) (Provider
 			p := NewVLLMProviderFull(baseURL, chatURL, cfg.ResolvedAPIKey, cfg.Model, displayName)
 			p.noStreamOptions = cfg.NoStreamOptions
 			p.vllmThinkingParam = cfg.VLLMThinkingParam
+			p.SetToolCalls(cfg.ToolCalls)
+			p.SetStructuredOutput(cfg.StructuredOutput)
 			p.SetModelConfigs(cfg.ModelConfigs)
 			return p, nil
 		}
 		p := NewOpenAICompatProviderFull(baseURL, chatURL, cfg.ResolvedAPIKey, cfg.Model, displayName, nil)
 		p.noStreamOptions = cfg.NoStreamOptions
+		p.SetToolCalls(cfg.ToolCalls)
+		p.SetStructuredOutput(cfg.StructuredOutput)
 		parseReasoning, includeReasoning, thinkingParam := openAICompatReasoningParserOptions(cfg)
 		p.SetReasoningParser(parseReasoning, includeReasoning, thinkingParam)
 		p.SetModelConfigs(cfg.ModelConfigs)
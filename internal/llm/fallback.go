@@ -0,0 +1,313 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/config"
+)
+
+// fallbackChainRetryConfig is used for every provider in a fallback chain
+// but the last. Retrying a failing provider for the full default window
+// would hold the request back from a fallback that can answer now.
+func fallbackChainRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts: 2,
+		BaseBackoff: time.Second,
+		MaxBackoff:  5 * time.Second,
+	}
+}
+
+// fallbackEntry is one provider in a fallback chain. Fallbacks are built on
+// first use, so one that needs interactive authentication only prompts when
+// it is actually needed.
+type fallbackEntry struct {
+	label    string
+	build    func() (Provider, error)
+	provider Provider
+}
+
+// FallbackProvider answers with the first provider in a chain that works.
+// It moves on when a provider fails before emitting any output with an
+// error another provider may not hit: authentication, quota or rate
+// limits, and server or connection errors. Context overflow is left to
+// compaction, and an error after output has been streamed is returned
+// as is. Each failover is announced with a warning phase event.
+type FallbackProvider struct {
+	mu           sync.Mutex
+	entries      []*fallbackEntry
+	active       int
+	toolExecutor func(ctx context.Context, name string, args json.RawMessage) (ToolOutput, error)
+}
+
+// NewFallbackProvider returns a provider that tries primary, then each of
+// fallbacks in order.
+func NewFallbackProvider(primary Provider, fallbacks ...Provider) *FallbackProvider {
+	f := &FallbackProvider{}
+	for _, p := range append([]Provider{primary}, fallbacks...) {
+		f.entries = append(f.entries, &fallbackEntry{label: p.Name(), provider: p})
+	}
+	return f
+}
+
+// withConfiguredFallbacks puts the fallbacks configured for the provider
+// and model behind primary, which is not yet retry-wrapped.
+func withConfiguredFallbacks(cfg *config.Config, providerName, model string, primary Provider) Provider {
+	targets := config.FallbacksForProviderModel(cfg, providerName, model)
+	if len(targets) == 0 {
+		return WrapWithRetry(primary, DefaultRetryConfig())
+	}
+
+	f := &FallbackProvider{}
+	f.entries = append(f.entries, &fallbackEntry{
+		label:    primary.Name(),
+		provider: WrapWithRetry(primary, fallbackChainRetryConfig()),
+	})
+	for i, target := range targets {
+		retry := fallbackChainRetryConfig()
+		if i == len(targets)-1 {
+			retry = DefaultRetryConfig()
+		}
+		f.entries = append(f.entries, &fallbackEntry{
+			label: target,
+			build: func() (Provider, error) {
+				name, model, err := ParseProviderModel(target, cfg)
+				if err != nil {
+					return nil, err
+				}
+				provider, err := newProviderByName(cfg, name, model)
+				if err != nil {
+					return nil, err
+				}
+				return WrapWithRetry(provider, retry), nil
+			},
+		})
+	}
+	return f
+}
+
+// provider returns the i'th provider in the chain, building it if needed.
+func (f *FallbackProvider) provider(i int) (Provider, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	entry := f.entries[i]
+	if entry.provider != nil {
+		return entry.provider, nil
+	}
+	provider, err := entry.build()
+	if err != nil {
+		return nil, fmt.Errorf("fallback %s: %w", entry.label, err)
+	}
+	if f.toolExecutor != nil {
+		if setter, ok := provider.(ToolExecutorSetter); ok {
+			setter.SetToolExecutor(f.toolExecutor)
+		}
+	}
+	entry.provider = provider
+	return provider, nil
+}
+
+// activeProvider returns the provider that answered last, or the primary.
+func (f *FallbackProvider) activeProvider() Provider {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.entries[f.active].provider
+}
+
+// builtProviders returns the providers built so far.
+func (f *FallbackProvider) builtProviders() []Provider {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var providers []Provider
+	for _, entry := range f.entries {
+		if entry.provider != nil {
+			providers = append(providers, entry.provider)
+		}
+	}
+	return providers
+}
+
+// Name returns the name of the provider that answered last.
+func (f *FallbackProvider) Name() string {
+	return f.activeProvider().Name()
+}
+
+func (f *FallbackProvider) Credential() string {
+	return f.activeProvider().Credential()
+}
+
+func (f *FallbackProvider) Capabilities() Capabilities {
+	return f.activeProvider().Capabilities()
+}
+
+func (f *FallbackProvider) Stream(ctx context.Context, req Request) (Stream, error) {
+	return newEventStream(ctx, func(ctx context.Context, send eventSender) error {
+		var lastErr error
+		for i := range f.entries {
+			provider, err := f.provider(i)
+			if err != nil {
+				// A fallback that cannot be created is skipped; the error
+				// that matters is the one that led to it.
+				if err := send.Send(Event{Type: EventPhase, Text: WarningPhasePrefix + "skipping " + summarizeFailoverError(err)}); err != nil {
+					return err
+				}
+				continue
+			}
+			err = f.streamFrom(ctx, i, provider, req, send)
+			if err == nil {
+				return nil
+			}
+			lastErr = err
+			var committed *committedError
+			if i == len(f.entries)-1 || errors.As(err, &committed) || !shouldFailover(err) {
+				return err
+			}
+
+			notice := fmt.Sprintf("%s failed (%s); trying %s", f.entries[i].label, summarizeFailoverError(err), f.entries[i+1].label)
+			if err := send.Send(Event{Type: EventPhase, Text: WarningPhasePrefix + notice}); err != nil {
+				return err
+			}
+		}
+		return lastErr
+	}), nil
+}
+
+// streamFrom runs the request on the i'th provider. Output is held back
+// until the attempt commits, so a failed attempt leaves nothing behind.
+func (f *FallbackProvider) streamFrom(ctx context.Context, i int, provider Provider, req Request, send eventSender) error {
+	if i > 0 {
+		// The request's model and tier belong to the primary; fallbacks
+		// use the model they were configured with.
+		req.Model = ""
+		req.ServiceTier = ""
+		req.ServiceTierSet = false
+	}
+	stream, err := provider.Stream(ctx, req)
+	if err != nil {
+		return err
+	}
+	err = forwardAttempt(ctx, stream, send)
+	var committed *committedError
+	if err == nil || errors.As(err, &committed) {
+		f.mu.Lock()
+		f.active = i
+		f.mu.Unlock()
+	}
+	return err
+}
+
+// shouldFailover reports whether another provider might succeed where err
+// failed.
+func shouldFailover(err error) bool {
+	if err == nil || isContextOverflowError(err) {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	// An interactive sign-in can fix this, so surface it rather than hiding
+	// the prompt behind a fallback.
+	if errors.Is(err, ErrAuthRequired) {
+		return false
+	}
+	if errors.Is(err, ErrCLINotInstalled) || errors.Is(err, ErrCLINotAuthenticated) {
+		return true
+	}
+	var statusErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &statusErr) {
+		code := statusErr.HTTPStatusCode()
+		return code == 401 || code == 402 || code == 403 || code == 429 || code >= 500
+	}
+	msg := strings.ToLower(err.Error())
+	for _, marker := range []string{
+		"unauthorized", "forbidden", "authentication", "invalid api key", "invalid_api_key",
+		"not authenticated", "quota", "billing", "credits",
+	} {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return isRetryable(err)
+}
+
+// summarizeFailoverError shortens an error to one line for the notice.
+func summarizeFailoverError(err error) string {
+	msg := strings.Join(strings.Fields(err.Error()), " ")
+	if len(msg) > 120 {
+		msg = msg[:117] + "..."
+	}
+	return msg
+}
+
+// ResetConversation resets every provider built so far.
+func (f *FallbackProvider) ResetConversation() {
+	for _, p := range f.builtProviders() {
+		if resetter, ok := p.(interface{ ResetConversation() }); ok {
+			resetter.ResetConversation()
+		}
+	}
+}
+
+// ExportProviderState exports the state of the provider that answered last.
+func (f *FallbackProvider) ExportProviderState() ([]byte, bool) {
+	if exporter, ok := f.activeProvider().(ProviderStateExporter); ok {
+		return exporter.ExportProviderState()
+	}
+	return nil, false
+}
+
+// ImportProviderState restores state on the primary provider, which a
+// restored session uses first.
+func (f *FallbackProvider) ImportProviderState(data []byte) error {
+	primary := f.entries[0].provider
+	if importer, ok := primary.(ProviderStateImporter); ok {
+		return importer.ImportProviderState(data)
+	}
+	return fmt.Errorf("provider %q does not support provider state import", primary.Name())
+}
+
+// SetToolExecutor passes the executor to every provider in the chain,
+// including fallbacks built later.
+func (f *FallbackProvider) SetToolExecutor(executor func(ctx context.Context, name string, args json.RawMessage) (ToolOutput, error)) {
+	f.mu.Lock()
+	f.toolExecutor = executor
+	f.mu.Unlock()
+	for _, p := range f.builtProviders() {
+		if setter, ok := p.(ToolExecutorSetter); ok {
+			setter.SetToolExecutor(executor)
+		}
+	}
+}
+
+// CleanupMCP cleans up every provider built so far.
+func (f *FallbackProvider) CleanupMCP() {
+	for _, p := range f.builtProviders() {
+		if cleaner, ok := p.(ProviderCleaner); ok {
+			cleaner.CleanupMCP()
+		}
+	}
+}
+
+// CleanupTurn cleans up every provider built so far.
+func (f *FallbackProvider) CleanupTurn() {
+	for _, p := range f.builtProviders() {
+		if cleaner, ok := p.(ProviderTurnCleaner); ok {
+			cleaner.CleanupTurn()
+		}
+	}
+}
+
+// ListModels lists the primary provider's models.
+func (f *FallbackProvider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	if lister, ok := f.entries[0].provider.(interface {
+		ListModels(context.Context) ([]ModelInfo, error)
+	}); ok {
+		return lister.ListModels(ctx)
+	}
+	return nil, ErrListModelsUnsupported
+}
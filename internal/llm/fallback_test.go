@@ -0,0 +1,165 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/samsaffron/term-llm/internal/config"
+)
+
+// runFallbackEngine streams one turn through an engine backed by provider
+// and returns the text, the warning notices and the final error.
+func runFallbackEngine(t *testing.T, provider Provider) (text string, warnings []string, err error) {
+	t.Helper()
+	engine := NewEngine(provider, NewToolRegistry())
+	stream, err := engine.Stream(context.Background(), Request{
+		Messages: []Message{UserText("hello")},
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	defer stream.Close()
+
+	var b strings.Builder
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return b.String(), warnings, nil
+		}
+		if err != nil {
+			return b.String(), warnings, err
+		}
+		switch event.Type {
+		case EventTextDelta:
+			b.WriteString(event.Text)
+		case EventPhase:
+			if strings.HasPrefix(event.Text, WarningPhasePrefix) {
+				warnings = append(warnings, event.Text)
+			}
+		case EventError:
+			return b.String(), warnings, event.Err
+		}
+	}
+}
+
+func TestFallbackProviderFailsOverOnAuthError(t *testing.T) {
+	primary := NewMockProvider("primary").AddError(errors.New("401 Unauthorized: invalid api key"))
+	backup := NewMockProvider("backup").AddTextResponse("answer from backup")
+	provider := NewFallbackProvider(primary, backup)
+
+	text, warnings, err := runFallbackEngine(t, provider)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text != "answer from backup" {
+		t.Fatalf("text = %q, want the backup's answer", text)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "primary failed") || !strings.Contains(warnings[0], "trying backup") {
+		t.Fatalf("warnings = %q, want one failover notice", warnings)
+	}
+	if got := provider.Name(); got != "backup" {
+		t.Fatalf("Name() = %q, want the provider that answered", got)
+	}
+}
+
+func TestFallbackProviderDoesNotFailOverOnContextOverflow(t *testing.T) {
+	primary := NewMockProvider("primary").AddError(errors.New("prompt is too long: context length exceeded"))
+	backup := NewMockProvider("backup").AddTextResponse("should not be used")
+	provider := NewFallbackProvider(primary, backup)
+
+	_, warnings, err := runFallbackEngine(t, provider)
+	if err == nil {
+		t.Fatal("expected the overflow error to be returned")
+	}
+	if n := len(backup.RecordedRequests()); n != 0 {
+		t.Fatalf("backup received %d requests, want 0", n)
+	}
+	for _, w := range warnings {
+		if strings.Contains(w, "trying backup") {
+			t.Fatalf("unexpected failover notice %q", w)
+		}
+	}
+	if got := provider.Name(); got != "primary" {
+		t.Fatalf("Name() = %q, want primary", got)
+	}
+}
+
+func TestFallbackProviderSecondFallbackAnswers(t *testing.T) {
+	primary := NewMockProvider("primary").AddError(errors.New("insufficient_quota: you exceeded your current quota"))
+	second := NewMockProvider("second").AddError(errors.New("503 service unavailable"))
+	third := NewMockProvider("third").AddTextResponse("third time lucky")
+	provider := NewFallbackProvider(primary, second, third)
+
+	text, warnings, err := runFallbackEngine(t, provider)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text != "third time lucky" {
+		t.Fatalf("text = %q", text)
+	}
+	if len(warnings) != 2 {
+		t.Fatalf("warnings = %q, want one notice per failover", warnings)
+	}
+	if got := provider.Name(); got != "third" {
+		t.Fatalf("Name() = %q, want third", got)
+	}
+}
+
+func TestFallbackProviderDoesNotFailOverAfterOutput(t *testing.T) {
+	primary := &textThenErrorProvider{}
+	backup := NewMockProvider("backup").AddTextResponse("should not be used")
+	provider := NewFallbackProvider(primary, backup)
+
+	_, _, err := runFallbackEngine(t, provider)
+	if err == nil {
+		t.Fatal("expected the mid-stream error to be returned")
+	}
+	if n := len(backup.RecordedRequests()); n != 0 {
+		t.Fatalf("backup received %d requests, want 0", n)
+	}
+}
+
+func TestShouldFailover(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"unauthorized", errors.New("401 Unauthorized"), true},
+		{"quota", errors.New("insufficient_quota"), true},
+		{"rate limit", &RateLimitError{Message: "slow down"}, true},
+		{"server error", errors.New("502 bad gateway"), true},
+		{"cli missing", ErrCLINotInstalled, true},
+		{"context overflow", errors.New("maximum context length exceeded"), false},
+		{"canceled", context.Canceled, false},
+		{"sign-in required", fmt.Errorf("stream: %w", &AuthRequiredError{Provider: "copilot", Reason: "authentication required"}), false},
+		{"bad request", errors.New("400 invalid tool schema"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldFailover(tt.err); got != tt.want {
+				t.Fatalf("shouldFailover(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithConfiguredFallbacksSkipsUnknownFallback(t *testing.T) {
+	cfg := &config.Config{Providers: map[string]config.ProviderConfig{
+		"primary": {Fallbacks: []string{"nosuchprovider:model"}},
+	}}
+	primary := NewMockProvider("primary").AddError(errors.New("401 Unauthorized"))
+	provider := withConfiguredFallbacks(cfg, "primary", "", primary)
+
+	_, warnings, err := runFallbackEngine(t, provider)
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("err = %v, want the primary's error", err)
+	}
+	if len(warnings) < 2 || !strings.Contains(warnings[len(warnings)-1], "skipping fallback nosuchprovider:model") {
+		t.Fatalf("warnings = %q, want a notice for the unusable fallback", warnings)
+	}
+}
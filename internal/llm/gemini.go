@@ -272,10 +272,14 @@ func emitGeminiUsage(send eventSender, resp *geminiGenerateContentResponse) erro
 	if resp == nil || resp.UsageMetadata == nil {
 		return nil
 	}
-	if resp.UsageMetadata.TotalTokenCount > 0 {
+	if meta := resp.UsageMetadata; meta.TotalTokenCount > 0 {
+		// promptTokenCount includes tokens served from the context cache.
+		cached := min(meta.CachedContentTokenCount, meta.PromptTokenCount)
 		return send.Send(Event{Type: EventUsage, Use: &Usage{
-			InputTokens:  int(resp.UsageMetadata.PromptTokenCount),
-			OutputTokens: int(resp.UsageMetadata.CandidatesTokenCount + resp.UsageMetadata.ThoughtsTokenCount),
+			InputTokens:            int(meta.PromptTokenCount - cached),
+			OutputTokens:           int(meta.CandidatesTokenCount + meta.ThoughtsTokenCount),
+			CachedInputTokens:      int(cached),
+			ProviderRawInputTokens: int(meta.PromptTokenCount),
 		}})
 	}
 	return nil
@@ -287,8 +291,7 @@ func buildGeminiTools(specs []ToolSpec) []*geminiTool {
 	}
 	tools := make([]*geminiTool, 0, len(specs))
 	for _, spec := range specs {
-		// Normalize schema for Gemini's requirements (similar to OpenAI normalization)
-		schema := normalizeSchemaForGemini(spec.Schema)
+		schema := cachedToolSchemaFor(spec.Schema, ToolSchemaGemini)
 		tools = append(tools, &geminiTool{
 			FunctionDeclarations: []*geminiFunctionDeclaration{
 				{
@@ -320,6 +323,7 @@ func buildGeminiContents(messages []Message) (string, []*geminiContent) {
 	messages = sanitizeToolHistory(messages)
 
 	var systemParts []string
+	var lastToolResultContent *geminiContent
 	contents := make([]*geminiContent, 0, len(messages))
 
 	for _, msg := range messages {
@@ -340,9 +344,18 @@ func buildGeminiContents(messages []Message) (string, []*geminiContent) {
 			}
 		case RoleTool:
 			content := buildGeminiToolResultContent(msg.Parts)
-			if content != nil {
-				contents = append(contents, content)
+			if content == nil {
+				continue
+			}
+			// The API wants every response to a model turn's function calls in
+			// the one content that follows it, so fold consecutive tool messages
+			// (one per parallel call) together.
+			if n := len(contents); n > 0 && lastToolResultContent == contents[n-1] {
+				contents[n-1].Parts = append(contents[n-1].Parts, content.Parts...)
+				continue
 			}
+			contents = append(contents, content)
+			lastToolResultContent = content
 		}
 	}
 
@@ -120,6 +120,7 @@ type geminiSchema struct {
 	Properties  map[string]*geminiSchema `json:"properties,omitempty"`
 	Items       *geminiSchema            `json:"items,omitempty"`
 	Required    []string                 `json:"required,omitempty"`
+	Nullable    bool                     `json:"nullable,omitempty"`
 }
 
 type geminiGenerateContentResponse struct {
@@ -161,10 +162,11 @@ type geminiGroundingWeb struct {
 }
 
 type geminiUsageMetadata struct {
-	PromptTokenCount     int32 `json:"promptTokenCount,omitempty"`
-	CandidatesTokenCount int32 `json:"candidatesTokenCount,omitempty"`
-	ThoughtsTokenCount   int32 `json:"thoughtsTokenCount,omitempty"`
-	TotalTokenCount      int32 `json:"totalTokenCount,omitempty"`
+	PromptTokenCount        int32 `json:"promptTokenCount,omitempty"`
+	CachedContentTokenCount int32 `json:"cachedContentTokenCount,omitempty"`
+	CandidatesTokenCount    int32 `json:"candidatesTokenCount,omitempty"`
+	ThoughtsTokenCount      int32 `json:"thoughtsTokenCount,omitempty"`
+	TotalTokenCount         int32 `json:"totalTokenCount,omitempty"`
 }
 
 func streamGeminiResponses(ctx context.Context, client *http.Client, baseURL, apiKey, model string, request geminiGenerateContentRequest, handle func(*geminiGenerateContentResponse) error) error {
@@ -21,8 +21,12 @@ import (
 )
 
 // geminiCLIHTTPClient is a package-level HTTP client with a timeout to prevent
-// hung Gemini API calls from blocking the event stream goroutine forever.
-var geminiCLIHTTPClient = &http.Client{Timeout: 10 * time.Minute}
+// hung Gemini API calls from blocking the event stream goroutine forever. It
+// applies engine middleware like the shared provider client.
+var geminiCLIHTTPClient = &http.Client{
+	Timeout:   10 * time.Minute,
+	Transport: &middlewareTransport{base: http.DefaultTransport},
+}
 
 const (
 	codeAssistEndpoint             = "https://cloudcode-pa.googleapis.com"
@@ -383,12 +387,10 @@ func (p *GeminiCLIProvider) Stream(ctx context.Context, req Request) (Stream, er
 		if len(req.Tools) > 0 {
 			decls := make([]map[string]interface{}, 0, len(req.Tools))
 			for _, spec := range req.Tools {
-				// Normalize schema for Gemini's requirements (same as GeminiProvider)
-				schema := normalizeSchemaForGemini(spec.Schema)
 				decls = append(decls, map[string]interface{}{
 					"name":        spec.Name,
 					"description": spec.Description,
-					"parameters":  schema,
+					"parameters":  ToolSchemaFor(spec.Schema, ToolSchemaGemini),
 				})
 			}
 			requestInner["tools"] = []map[string]interface{}{
@@ -45,32 +45,59 @@ func geminiDeepCopySlice(s []interface{}) []interface{} {
 	return result
 }
 
+// geminiUnsupportedSchemaFields are JSON Schema keywords outside the OpenAPI
+// subset Gemini function declarations accept.
+var geminiUnsupportedSchemaFields = []string{
+	"$schema",
+	"$id",
+	"$ref",
+	"$defs",
+	"definitions",
+	"format",
+	"exclusiveMinimum",
+	"exclusiveMaximum",
+	"minimum",
+	"maximum",
+	"minLength",
+	"maxLength",
+	"minItems",
+	"maxItems",
+	"uniqueItems",
+	"pattern",
+	"patternProperties",
+	"propertyNames",
+	"default",
+	"examples",
+	"const",
+	"additionalProperties",
+	"title",
+}
+
 // normalizeGeminiSchemaRecursive applies Gemini normalization recursively
 func normalizeGeminiSchemaRecursive(schema map[string]interface{}) map[string]interface{} {
 	// Remove fields Gemini doesn't support
-	unsupportedFields := []string{
-		"$schema",
-		"format",
-		"exclusiveMinimum",
-		"exclusiveMaximum",
-		"minimum",
-		"maximum",
-		"minLength",
-		"maxLength",
-		"minItems",
-		"maxItems",
-		"uniqueItems",
-		"pattern",
-		"default",
-		"examples",
-		"const",
-		"additionalProperties",
-		"title",
-	}
-	for _, field := range unsupportedFields {
+	for _, field := range geminiUnsupportedSchemaFields {
 		delete(schema, field)
 	}
 
+	// Gemini takes a single type; a union with null becomes nullable, and any
+	// other union keeps its first type.
+	if typeNames, hadType := normalizedJSONSchemaTypeNames(schema["type"]); hadType {
+		delete(schema, "type")
+		for _, name := range typeNames {
+			if name == "null" {
+				schema["nullable"] = true
+			} else if _, set := schema["type"]; !set {
+				schema["type"] = name
+			}
+		}
+	}
+
+	// Gemini only allows enum on strings.
+	if schema["type"] != "string" {
+		delete(schema, "enum")
+	}
+
 	// Handle properties
 	if props, ok := schema["properties"].(map[string]interface{}); ok && len(props) > 0 {
 		// Recursively normalize each property
@@ -117,6 +144,9 @@ func schemaToGemini(schema map[string]interface{}) *geminiSchema {
 		Description: stringField(schema, "description"),
 		Required:    requiredFields(schema),
 	}
+	if nullable, ok := schema["nullable"].(bool); ok {
+		genSchema.Nullable = nullable
+	}
 
 	if props, ok := schema["properties"].(map[string]interface{}); ok {
 		genSchema.Properties = make(map[string]*geminiSchema, len(props))
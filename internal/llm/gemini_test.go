@@ -57,6 +57,52 @@ func TestBuildGeminiContents_DropsDanglingToolCalls(t *testing.T) {
 	}
 }
 
+func TestBuildGeminiContents_GroupsParallelFunctionResponses(t *testing.T) {
+	_, contents := buildGeminiContents([]Message{
+		UserText("Check both"),
+		{
+			Role: RoleAssistant,
+			Parts: []Part{
+				{Type: PartToolCall, ToolCall: &ToolCall{ID: "call-1", Name: "read_file", Arguments: []byte(`{"path":"a"}`)}},
+				{Type: PartToolCall, ToolCall: &ToolCall{ID: "call-2", Name: "read_file", Arguments: []byte(`{"path":"b"}`)}},
+			},
+		},
+		ToolResultMessage("call-1", "read_file", "A", nil),
+		ToolResultMessage("call-2", "read_file", "B", nil),
+		UserText("Thanks"),
+	})
+
+	if len(contents) != 4 {
+		t.Fatalf("expected 4 contents, got %d", len(contents))
+	}
+	responses := contents[2]
+	if responses.Role != geminiRoleUser || len(responses.Parts) != 2 {
+		t.Fatalf("expected both function responses in one user content, got %+v", responses)
+	}
+	for i, id := range []string{"call-1", "call-2"} {
+		if responses.Parts[i].FunctionResponse == nil || responses.Parts[i].FunctionResponse.ID != id {
+			t.Fatalf("part %d = %+v, want function response %s", i, responses.Parts[i], id)
+		}
+	}
+	if len(contents[3].Parts) != 1 || contents[3].Parts[0].Text != "Thanks" {
+		t.Fatalf("following user message merged into the responses: %+v", contents[3])
+	}
+}
+
+func TestEmitGeminiUsage_SplitsCachedPromptTokens(t *testing.T) {
+	events := make(chan Event, 1)
+	err := emitGeminiUsage(eventSender{ctx: context.Background(), ch: events}, &geminiGenerateContentResponse{
+		UsageMetadata: &geminiUsageMetadata{PromptTokenCount: 1000, CachedContentTokenCount: 800, CandidatesTokenCount: 20, ThoughtsTokenCount: 5, TotalTokenCount: 1025},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	usage := (<-events).Use
+	if usage.InputTokens != 200 || usage.CachedInputTokens != 800 || usage.OutputTokens != 25 || usage.ProviderRawInputTokens != 1000 {
+		t.Fatalf("usage = %+v", usage)
+	}
+}
+
 func TestEmitGeminiParts_StreamsTextAndToolCallsInOrder(t *testing.T) {
 	thoughtSig := []byte{1, 2, 3}
 	events := make(chan Event, 4)
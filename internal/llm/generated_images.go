@@ -0,0 +1,180 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/samsaffron/term-llm/internal/appdata"
+)
+
+// MaxGeneratedImageBytes caps a single model-generated image. Larger images
+// are reported but not saved.
+const MaxGeneratedImageBytes = 32 << 20
+
+// unsavedImagesDir holds images from requests that have no session.
+const unsavedImagesDir = "unsaved"
+
+// generatedImagesRoot returns the directory that holds one subdirectory of
+// generated images per session. Tests replace it.
+var generatedImagesRoot = func() (string, error) {
+	dataDir, err := appdata.GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "images"), nil
+}
+
+// GeneratedImagesDirName returns the directory name used for a session's
+// generated images, or "" when sessionID cannot be used as a path element.
+func GeneratedImagesDirName(sessionID string) string {
+	sessionID = strings.TrimSpace(sessionID)
+	if sessionID == "" {
+		return unsavedImagesDir
+	}
+	if sessionID == "." || sessionID == ".." || strings.ContainsAny(sessionID, `/\`) {
+		return ""
+	}
+	return sessionID
+}
+
+// saveGeneratedImage fills in ev.ImagePath for an EventImageGenerated by
+// writing the image (downloading it first when the provider only sent a URL)
+// to the session's image directory. Failures are logged and leave ImagePath
+// empty, so the event is still forwarded.
+func saveGeneratedImage(ctx context.Context, sessionID string, ev Event) Event {
+	data := ev.ImageData
+	if len(data) == 0 && ev.ImageURL != "" {
+		fetched, err := fetchGeneratedImage(ctx, ev.ImageURL)
+		if err != nil {
+			slog.Warn("download generated image failed", "url", ev.ImageURL, "error", err)
+			return ev
+		}
+		data = fetched
+		ev.ImageData = fetched
+	}
+	if len(data) == 0 {
+		return ev
+	}
+	if len(data) > MaxGeneratedImageBytes {
+		slog.Warn("generated image too large to save", "bytes", len(data), "limit", MaxGeneratedImageBytes)
+		return ev
+	}
+	if ev.ImageMimeType == "" {
+		ev.ImageMimeType = http.DetectContentType(data)
+	}
+	name := GeneratedImagesDirName(sessionID)
+	root, err := generatedImagesRoot()
+	if name == "" || err != nil {
+		slog.Warn("no directory for generated image", "session", sessionID, "error", err)
+		return ev
+	}
+	dir := filepath.Join(root, name)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		slog.Warn("create generated image dir failed", "error", err)
+		return ev
+	}
+	sum := sha256.Sum256(data)
+	path := filepath.Join(dir, fmt.Sprintf("image_%x%s", sum[:8], imageExtensionForMediaType(ev.ImageMimeType)))
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		slog.Warn("write generated image failed", "path", path, "error", err)
+		return ev
+	}
+	ev.ImagePath = path
+	return ev
+}
+
+// fetchGeneratedImage downloads an image output given by URL. data: URLs are
+// decoded in place.
+func fetchGeneratedImage(ctx context.Context, url string) ([]byte, error) {
+	if strings.HasPrefix(url, "data:") {
+		_, payload, ok := strings.Cut(url, ",")
+		if !ok {
+			return nil, fmt.Errorf("malformed data URL")
+		}
+		return base64.StdEncoding.DecodeString(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := defaultHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxGeneratedImageBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxGeneratedImageBytes {
+		return nil, fmt.Errorf("image larger than %d bytes", MaxGeneratedImageBytes)
+	}
+	return data, nil
+}
+
+// generatedImagePart is the assistant message part recorded for a saved image.
+// It keeps only the path: the file is the source of truth for exports and
+// previews, and assistant images are never sent back to providers as images.
+func generatedImagePart(ev Event) Part {
+	return Part{
+		Type:      PartImage,
+		ImagePath: ev.ImagePath,
+		ImageData: &ToolImageData{MediaType: ev.ImageMimeType},
+	}
+}
+
+func attachGeneratedImageParts(msg Message, parts []Part) Message {
+	if len(parts) == 0 {
+		return msg
+	}
+	msg.Parts = append(msg.Parts, parts...)
+	return msg
+}
+
+// assistantImagesAsText replaces image parts in assistant messages with a
+// short text note. Providers do not accept images in assistant turns, but the
+// model should still know it produced one and where it was saved.
+func assistantImagesAsText(messages []Message) []Message {
+	var out []Message
+	for i, msg := range messages {
+		if msg.Role != RoleAssistant {
+			continue
+		}
+		hasImage := false
+		for _, part := range msg.Parts {
+			if part.Type == PartImage {
+				hasImage = true
+				break
+			}
+		}
+		if !hasImage {
+			continue
+		}
+		if out == nil {
+			out = append([]Message(nil), messages...)
+		}
+		parts := make([]Part, len(msg.Parts))
+		for j, part := range msg.Parts {
+			if part.Type == PartImage {
+				part = Part{Type: PartText, Text: "[generated image saved at: " + part.ImagePath + "]"}
+			}
+			parts[j] = part
+		}
+		out[i].Parts = parts
+	}
+	if out == nil {
+		return messages
+	}
+	return out
+}
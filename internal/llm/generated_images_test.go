@@ -0,0 +1,172 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func useTempGeneratedImagesRoot(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	orig := generatedImagesRoot
+	generatedImagesRoot = func() (string, error) { return root, nil }
+	t.Cleanup(func() { generatedImagesRoot = orig })
+	return root
+}
+
+func TestSaveGeneratedImage_WritesUnderSessionDir(t *testing.T) {
+	root := useTempGeneratedImagesRoot(t)
+	data := []byte("\x89PNG\r\n\x1a\nrest")
+
+	ev := saveGeneratedImage(context.Background(), "sess-1", Event{Type: EventImageGenerated, ImageData: data, ImageMimeType: "image/png"})
+	if filepath.Dir(ev.ImagePath) != filepath.Join(root, "sess-1") {
+		t.Fatalf("ImagePath = %q, want file under %q", ev.ImagePath, filepath.Join(root, "sess-1"))
+	}
+	if filepath.Ext(ev.ImagePath) != ".png" {
+		t.Fatalf("ImagePath extension = %q, want .png", filepath.Ext(ev.ImagePath))
+	}
+	got, err := os.ReadFile(ev.ImagePath)
+	if err != nil {
+		t.Fatalf("read saved image: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("saved bytes = %q, want %q", got, data)
+	}
+}
+
+func TestSaveGeneratedImage_DecodesDataURL(t *testing.T) {
+	root := useTempGeneratedImagesRoot(t)
+	data := []byte("jpeg-bytes")
+	url := "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(data)
+
+	ev := saveGeneratedImage(context.Background(), "", Event{Type: EventImageGenerated, ImageURL: url, ImageMimeType: dataURLMediaType(url)})
+	if filepath.Dir(ev.ImagePath) != filepath.Join(root, unsavedImagesDir) {
+		t.Fatalf("ImagePath = %q, want file under unsaved dir", ev.ImagePath)
+	}
+	if ev.ImageMimeType != "image/jpeg" || filepath.Ext(ev.ImagePath) != ".jpg" {
+		t.Fatalf("mime = %q path = %q, want image/jpeg .jpg", ev.ImageMimeType, ev.ImagePath)
+	}
+}
+
+func TestSaveGeneratedImage_SkipsOversizedAndUnsafeSessions(t *testing.T) {
+	useTempGeneratedImagesRoot(t)
+
+	big := Event{Type: EventImageGenerated, ImageData: make([]byte, MaxGeneratedImageBytes+1), ImageMimeType: "image/png"}
+	if ev := saveGeneratedImage(context.Background(), "sess", big); ev.ImagePath != "" {
+		t.Fatalf("oversized image saved to %q", ev.ImagePath)
+	}
+	small := Event{Type: EventImageGenerated, ImageData: []byte("x"), ImageMimeType: "image/png"}
+	if ev := saveGeneratedImage(context.Background(), "../escape", small); ev.ImagePath != "" {
+		t.Fatalf("image for unsafe session saved to %q", ev.ImagePath)
+	}
+}
+
+func TestAssistantImagesAsText(t *testing.T) {
+	user := Message{Role: RoleUser, Parts: []Part{{Type: PartImage, ImagePath: "/u.png", ImageData: &ToolImageData{MediaType: "image/png", Base64: "eA=="}}}}
+	assistant := Message{Role: RoleAssistant, Parts: []Part{
+		{Type: PartText, Text: "here you go"},
+		generatedImagePart(Event{ImagePath: "/g.png", ImageMimeType: "image/png"}),
+	}}
+	messages := []Message{user, assistant}
+
+	got := assistantImagesAsText(messages)
+	if got[0].Parts[0].Type != PartImage {
+		t.Fatalf("user image part was rewritten: %#v", got[0].Parts[0])
+	}
+	if part := got[1].Parts[1]; part.Type != PartText || !strings.Contains(part.Text, "/g.png") {
+		t.Fatalf("assistant image part = %#v, want text note with path", part)
+	}
+	if messages[1].Parts[1].Type != PartImage {
+		t.Fatal("input messages were modified")
+	}
+}
+
+func TestEngine_SavesGeneratedImageOnAssistantMessage(t *testing.T) {
+	root := useTempGeneratedImagesRoot(t)
+	data := []byte("\x89PNG\r\n\x1a\nimage")
+
+	provider := &fakeProvider{
+		script: func(call int, req Request) []Event {
+			if call == 0 {
+				return []Event{
+					{Type: EventImageGenerated, ImageData: data, ImageMimeType: "image/png"},
+					{Type: EventTextDelta, Text: "done"},
+					{Type: EventDone},
+				}
+			}
+			return []Event{{Type: EventTextDelta, Text: "ok"}, {Type: EventDone}}
+		},
+	}
+	engine := NewEngine(provider, NewToolRegistry())
+
+	var persisted Message
+	engine.SetTurnCompletedCallback(func(ctx context.Context, turnIndex int, messages []Message, metrics TurnMetrics) error {
+		for _, msg := range messages {
+			if msg.Role == RoleAssistant {
+				persisted = msg
+			}
+		}
+		return nil
+	})
+
+	stream, err := engine.Stream(context.Background(), Request{SessionID: "sess-img", Messages: []Message{UserText("draw")}})
+	if err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+	var imagePath string
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("recv error: %v", err)
+		}
+		if event.Type == EventImageGenerated {
+			imagePath = event.ImagePath
+		}
+	}
+	stream.Close()
+
+	if filepath.Dir(imagePath) != filepath.Join(root, "sess-img") {
+		t.Fatalf("event ImagePath = %q, want file under session dir", imagePath)
+	}
+	var imagePart *Part
+	for i := range persisted.Parts {
+		if persisted.Parts[i].Type == PartImage {
+			imagePart = &persisted.Parts[i]
+		}
+	}
+	if imagePart == nil || imagePart.ImagePath != imagePath {
+		t.Fatalf("persisted parts = %#v, want image part for %q", persisted.Parts, imagePath)
+	}
+
+	// The next request carries the image as a text note, not image data.
+	stream, err = engine.Stream(context.Background(), Request{SessionID: "sess-img", Messages: []Message{UserText("draw"), persisted, UserText("thanks")}})
+	if err != nil {
+		t.Fatalf("second stream error: %v", err)
+	}
+	for {
+		if _, err := stream.Recv(); err != nil {
+			break
+		}
+	}
+	stream.Close()
+	sent := provider.calls[len(provider.calls)-1].Messages
+	for _, msg := range sent {
+		if msg.Role != RoleAssistant {
+			continue
+		}
+		for _, part := range msg.Parts {
+			if part.Type == PartImage {
+				t.Fatalf("assistant image part sent to provider: %#v", part)
+			}
+		}
+	}
+}
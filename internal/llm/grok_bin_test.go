@@ -11,6 +11,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/samsaffron/term-llm/internal/procutil"
 )
 
 func TestGrokBinProviderPrepareCommandUsesWorkingDir(t *testing.T) {
@@ -29,7 +31,7 @@ func TestGrokBinProviderPrepareCommandUsesWorkingDir(t *testing.T) {
 	if cmd.WaitDelay != grokCommandWaitDelay {
 		t.Fatalf("WaitDelay = %v, want %v", cmd.WaitDelay, grokCommandWaitDelay)
 	}
-	if cmd.SysProcAttr == nil || !cmd.SysProcAttr.Setpgid {
+	if !procutil.InOwnProcessGroup(cmd) {
 		t.Fatal("expected grok subprocess to run in its own process group")
 	}
 }
@@ -453,3 +453,56 @@ func TestScreenCapture_SaveAndLoad(t *testing.T) {
 		os.RemoveAll(dir)
 	}
 }
+
+func TestEngineHarness_PerToolResultLimits(t *testing.T) {
+	t.Parallel()
+
+	h := testutil.NewEngineHarness()
+	h.AddMockTool("shell", strings.Repeat("s", 5000))
+	h.AddMockTool("read_file", strings.Repeat("r", 800))
+	h.AddMockTool("grep", strings.Repeat("g", 1500))
+
+	h.Engine.SetMaxToolOutputChars(1000)
+	h.Engine.SetToolResultLimits(map[string]int{"shell": 200})
+
+	h.Provider.AddTurn(llm.MockTurn{
+		ToolCalls: []llm.ToolCall{
+			{ID: "call_shell", Name: "shell", Arguments: json.RawMessage(`{"command": "make"}`)},
+			{ID: "call_read", Name: "read_file", Arguments: json.RawMessage(`{"path": "a.txt"}`)},
+			{ID: "call_grep", Name: "grep", Arguments: json.RawMessage(`{"pattern": "x"}`)},
+		},
+	})
+	h.Provider.AddTextResponse("Done.")
+
+	_, err := h.Run(context.Background(), llm.Request{
+		Messages: []llm.Message{llm.UserText("Build and read")},
+		Tools:    h.Registry.AllSpecs(),
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(h.Provider.Requests) < 2 {
+		t.Fatalf("expected at least 2 requests, got %d", len(h.Provider.Requests))
+	}
+
+	results := map[string]string{}
+	for _, msg := range h.Provider.Requests[1].Messages {
+		for _, part := range msg.Parts {
+			if part.ToolResult != nil {
+				results[part.ToolResult.ID] = part.ToolResult.Content
+			}
+		}
+	}
+
+	shell := results["call_shell"]
+	if !strings.Contains(shell, "4800 chars truncated") || !strings.Contains(shell, "(limit for shell: 200)") {
+		t.Errorf("shell result should carry the shell-specific cap, got marker in %q", shell)
+	}
+	if got := results["call_read"]; got != strings.Repeat("r", 800) {
+		t.Errorf("read_file result should stay under the default limit untouched, got %d runes", len([]rune(got)))
+	}
+	grep := results["call_grep"]
+	if !strings.Contains(grep, "500 chars truncated") || !strings.Contains(grep, "(limit: 1000)") || strings.Contains(grep, "limit for") {
+		t.Errorf("grep result should carry the default limit without naming the tool, got %q", grep)
+	}
+}
@@ -0,0 +1,90 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const InterruptedEventType = "interrupted"
+
+// InterruptedMarker is a durable non-LLM transcript event recorded right after
+// the partial assistant message of a response the user cancelled. The partial
+// message stays in model context; the marker only tells readers where the
+// response stopped and is filtered from context via RoleEvent.
+type InterruptedMarker struct {
+	Type             string `json:"type"`
+	DroppedToolCalls int    `json:"dropped_tool_calls,omitempty"`
+}
+
+// InterruptedEventMessage returns a RoleEvent message suitable for durable
+// transcript storage. Like the other markers it stores structured JSON in a
+// text part so existing session storage schemas can persist it.
+func InterruptedEventMessage(marker InterruptedMarker) Message {
+	marker.Type = InterruptedEventType
+	data, err := json.Marshal(marker)
+	if err != nil {
+		data = []byte(fmt.Sprintf(`{"type":"%s"}`, InterruptedEventType))
+	}
+	return Message{Role: RoleEvent, Parts: []Part{{Type: PartText, Text: string(data)}}}
+}
+
+// ParseInterruptedMarker extracts an interrupted marker from a durable event
+// message.
+func ParseInterruptedMarker(msg Message) (InterruptedMarker, bool) {
+	if msg.Role != RoleEvent {
+		return InterruptedMarker{}, false
+	}
+	for _, part := range msg.Parts {
+		if part.Type != PartText || strings.TrimSpace(part.Text) == "" {
+			continue
+		}
+		var marker InterruptedMarker
+		if err := json.Unmarshal([]byte(part.Text), &marker); err != nil {
+			continue
+		}
+		if marker.Type == InterruptedEventType {
+			return marker, true
+		}
+	}
+	return InterruptedMarker{}, false
+}
+
+// FormatInterruptedMarker returns the transcript line shown for marker.
+func FormatInterruptedMarker(marker InterruptedMarker) string {
+	switch marker.DroppedToolCalls {
+	case 0:
+		return "⏹ interrupted"
+	case 1:
+		return "⏹ interrupted · 1 pending tool call dropped"
+	default:
+		return fmt.Sprintf("⏹ interrupted · %d pending tool calls dropped", marker.DroppedToolCalls)
+	}
+}
+
+// PartialAssistantMessage prepares the assistant message of a cancelled
+// response for history. Tool calls that were emitted but never executed are
+// dropped, since providers reject calls without results, and so is provider
+// replay state, which describes output items the response never finished.
+// Text and displayable reasoning are kept so the next request can continue
+// from them. ok is false when nothing worth keeping remains.
+func PartialAssistantMessage(msg Message) (partial Message, droppedToolCalls int, ok bool) {
+	partial = msg
+	partial.Parts = make([]Part, 0, len(msg.Parts))
+	for _, part := range msg.Parts {
+		switch part.Type {
+		case PartToolCall:
+			droppedToolCalls++
+			continue
+		case PartProviderReplay:
+			continue
+		}
+		part.ReasoningItemID = ""
+		part.ReasoningEncryptedContent = ""
+		if part.Type == PartText && part.Text == "" && part.ReasoningContent == "" {
+			continue
+		}
+		partial.Parts = append(partial.Parts, part)
+	}
+	return partial, droppedToolCalls, len(partial.Parts) > 0
+}
@@ -0,0 +1,65 @@
+package llm
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestInterruptedMarkerRoundTrip(t *testing.T) {
+	tests := []struct {
+		dropped int
+		want    string
+	}{
+		{dropped: 0, want: "⏹ interrupted"},
+		{dropped: 1, want: "⏹ interrupted · 1 pending tool call dropped"},
+		{dropped: 3, want: "⏹ interrupted · 3 pending tool calls dropped"},
+	}
+	for _, tt := range tests {
+		msg := InterruptedEventMessage(InterruptedMarker{DroppedToolCalls: tt.dropped})
+		marker, ok := ParseInterruptedMarker(msg)
+		if !ok {
+			t.Fatalf("ParseInterruptedMarker(%+v) failed", msg)
+		}
+		if got := FormatInterruptedMarker(marker); got != tt.want {
+			t.Errorf("FormatInterruptedMarker = %q, want %q", got, tt.want)
+		}
+		if _, ok := ParseModelSwapMarker(msg); ok {
+			t.Error("interrupted marker parsed as a model swap marker")
+		}
+	}
+	if len(FilterConversationMessages([]Message{InterruptedEventMessage(InterruptedMarker{})})) != 0 {
+		t.Error("interrupted marker leaked into conversation messages")
+	}
+}
+
+func TestPartialAssistantMessage(t *testing.T) {
+	msg := Message{Role: RoleAssistant, Parts: []Part{
+		{Type: PartText, Text: "Half an", ReasoningContent: "thinking", ReasoningEncryptedContent: "sig", ReasoningItemID: "rs_1"},
+		{Type: PartToolCall, ToolCall: &ToolCall{ID: "call-1", Name: "shell", Arguments: json.RawMessage(`{}`)}},
+		{Type: PartToolCall, ToolCall: &ToolCall{ID: "call-2", Name: "shell", Arguments: json.RawMessage(`{}`)}},
+		{Type: PartText},
+	}}
+
+	partial, dropped, ok := PartialAssistantMessage(msg)
+	if !ok || dropped != 2 {
+		t.Fatalf("ok=%v dropped=%d, want ok with 2 dropped calls", ok, dropped)
+	}
+	if len(partial.Parts) != 1 {
+		t.Fatalf("partial parts = %+v, want the text part only", partial.Parts)
+	}
+	part := partial.Parts[0]
+	if part.Text != "Half an" || part.ReasoningContent != "thinking" {
+		t.Errorf("partial part = %+v, want text and reasoning kept", part)
+	}
+	if part.ReasoningEncryptedContent != "" || part.ReasoningItemID != "" {
+		t.Errorf("partial part kept replay state: %+v", part)
+	}
+	if msg.Parts[0].ReasoningEncryptedContent != "sig" {
+		t.Error("PartialAssistantMessage modified its input")
+	}
+
+	_, dropped, ok = PartialAssistantMessage(Message{Role: RoleAssistant, Parts: msg.Parts[1:3]})
+	if ok || dropped != 2 {
+		t.Errorf("tool-call-only message: ok=%v dropped=%d, want !ok with 2 dropped", ok, dropped)
+	}
+}
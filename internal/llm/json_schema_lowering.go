@@ -1,10 +1,5 @@
 package llm
 
-import (
-	"reflect"
-	"sync"
-)
-
 // ToOpenResponsesParameters serializes the typed schema into the provider-neutral
 // Open Responses function-tool `parameters` shape. It intentionally avoids
 // OpenAI-specific strict-schema rewrites such as forcing every property into
@@ -71,38 +66,13 @@ func scrubUnsupportedOpenAISchemaKeywords(value interface{}) {
 	}
 }
 
-type openAIParametersCacheKey struct {
-	schemaPtr uintptr
-	strict    bool
-}
-
-type openAIParametersCacheEntry struct {
-	// Hold a strong reference to the immutable schema map so its identity cannot
-	// be reused for a different schema while the cached lowered parameters remain
-	// live.
-	schema map[string]interface{}
-	once   sync.Once
-	params map[string]interface{}
-}
-
-var openAIParametersCache sync.Map
-
+// openAIParametersFromToolSchema lowers a ToolSpec schema for an OpenAI
+// Responses function tool.
 func openAIParametersFromToolSchema(schema map[string]interface{}, strict bool) map[string]interface{} {
-	key := openAIParametersCacheKey{strict: strict}
-	if len(schema) > 0 {
-		key.schemaPtr = reflect.ValueOf(schema).Pointer()
+	if strict {
+		return ToolSchemaFor(schema, ToolSchemaOpenAIStrict)
 	}
-
-	cached, _ := openAIParametersCache.LoadOrStore(key, &openAIParametersCacheEntry{schema: schema})
-	entry := cached.(*openAIParametersCacheEntry)
-	entry.once.Do(func() {
-		parsed, err := ParseToolJSONSchemaMap(schema)
-		if err != nil {
-			parsed, _ = ParseToolJSONSchemaMap(nil)
-		}
-		entry.params = parsed.ToOpenAIParameters(strict)
-	})
-	return deepCopyOpenAIParameters(entry.params)
+	return ToolSchemaFor(schema, ToolSchemaOpenAI)
 }
 
 func deepCopyOpenAIParameters(params map[string]interface{}) map[string]interface{} {
@@ -0,0 +1,297 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go/option"
+)
+
+// Middleware observes and adjusts every request the engine sends to its
+// provider. Middlewares run in registration order around each provider call:
+// once per agentic-loop turn, and again for each transient retry.
+type Middleware interface {
+	// BeforeRequest is called before the provider is invoked. It may modify
+	// the request and add headers; returning an error vetoes the call, and
+	// the error is returned to the caller without contacting the provider.
+	BeforeRequest(ctx context.Context, req *MiddlewareRequest) error
+	// AfterResponse is called once the provider stream ends, fails or is
+	// closed. It is not called for vetoed requests.
+	AfterResponse(ctx context.Context, resp MiddlewareResponse)
+}
+
+// HTTPMiddleware is an optional extension of Middleware for hooks that need
+// the raw HTTP request. BeforeHTTPRequest is called for each HTTP request a
+// provider makes during the call, after the middleware headers are applied;
+// returning an error fails that HTTP request. Providers that do not talk
+// HTTP directly (CLI-backed providers such as claude-bin) never call it.
+type HTTPMiddleware interface {
+	Middleware
+	BeforeHTTPRequest(req *http.Request) error
+}
+
+// StreamMiddleware is an optional extension of Middleware for hooks that
+// watch the events of a whole Engine.Stream call, tool execution included,
+// rather than a single provider call.
+type StreamMiddleware interface {
+	Middleware
+	WrapStream(ctx context.Context, req Request, s Stream) Stream
+}
+
+// MiddlewareRequest is the provider-agnostic view of an outgoing request.
+type MiddlewareRequest struct {
+	Provider   string // provider display name, e.g. "Anthropic (claude-sonnet-4-6)"
+	Credential string
+	// Turn is the agentic-loop turn, or -1 for a request without tools.
+	Turn int
+	// Retry counts the transient-error retries of this call that came
+	// before it; 0 on the first attempt.
+	Retry   int
+	Request *Request
+	// Header is added to the provider's HTTP requests, replacing any values
+	// the provider set for the same keys.
+	Header http.Header
+}
+
+// MiddlewareResponse reports how a provider call ended.
+type MiddlewareResponse struct {
+	Provider string
+	Model    string
+	Turn     int
+	Usage    Usage // summed over the usage events the provider reported
+	Duration time.Duration
+	Err      error // nil when the stream ended normally or was closed early
+}
+
+// Use appends middlewares to the engine. They run after any already
+// registered, and after the built-in debug middlewares.
+func (e *Engine) Use(mws ...Middleware) {
+	e.middlewareMu.Lock()
+	defer e.middlewareMu.Unlock()
+	e.middlewares = append(e.middlewares, mws...)
+}
+
+// middlewareChain returns the built-in middlewares followed by the
+// registered ones.
+func (e *Engine) middlewareChain() []Middleware {
+	e.middlewareMu.RLock()
+	defer e.middlewareMu.RUnlock()
+	chain := []Middleware{debugRawMiddleware{}}
+	if e.debugLogger != nil {
+		chain = append(chain, debugLoggerMiddleware{logger: e.debugLogger})
+	}
+	return append(chain, e.middlewares...)
+}
+
+// wrapStreamMiddleware lets each StreamMiddleware in the chain wrap the
+// stream returned by Engine.Stream.
+func (e *Engine) wrapStreamMiddleware(ctx context.Context, req Request, s Stream) Stream {
+	for _, mw := range e.middlewareChain() {
+		if wrapper, ok := mw.(StreamMiddleware); ok {
+			s = wrapper.WrapStream(ctx, req, s)
+		}
+	}
+	return s
+}
+
+// streamWithMiddleware runs the BeforeRequest hooks, calls stream with the
+// resulting request and wraps the result so AfterResponse sees how it ended.
+func (e *Engine) streamWithMiddleware(ctx context.Context, req Request, turn, retry int, stream func(context.Context, Request) (Stream, error)) (Stream, error) {
+	chain := e.middlewareChain()
+	mreq := &MiddlewareRequest{
+		Provider:   e.provider.Name(),
+		Credential: e.provider.Credential(),
+		Turn:       turn,
+		Retry:      retry,
+		Request:    &req,
+		Header:     http.Header{},
+	}
+	var httpHooks []HTTPMiddleware
+	for _, mw := range chain {
+		if err := mw.BeforeRequest(ctx, mreq); err != nil {
+			return nil, fmt.Errorf("request blocked by middleware: %w", err)
+		}
+		if hook, ok := mw.(HTTPMiddleware); ok {
+			httpHooks = append(httpHooks, hook)
+		}
+	}
+	if len(mreq.Header) > 0 || len(httpHooks) > 0 {
+		ctx = context.WithValue(ctx, middlewareHTTPKey, &middlewareHTTPState{header: mreq.Header, hooks: httpHooks})
+	}
+
+	started := time.Now()
+	after := func(usage Usage, err error) {
+		resp := MiddlewareResponse{
+			Provider: mreq.Provider,
+			Model:    req.Model,
+			Turn:     turn,
+			Usage:    usage,
+			Duration: time.Since(started),
+			Err:      err,
+		}
+		for _, mw := range chain {
+			mw.AfterResponse(ctx, resp)
+		}
+	}
+	s, err := stream(ctx, req)
+	if err != nil {
+		after(Usage{}, err)
+		return nil, err
+	}
+	return &middlewareStream{Stream: s, after: after}, nil
+}
+
+// middlewareStream totals usage and reports the end of the stream to the
+// AfterResponse hooks exactly once.
+type middlewareStream struct {
+	Stream
+	usage Usage
+	after func(Usage, error)
+	once  sync.Once
+}
+
+func (s *middlewareStream) Recv() (Event, error) {
+	event, err := s.Stream.Recv()
+	switch {
+	case err == nil && event.Type == EventUsage && event.Use != nil:
+		s.usage.InputTokens += event.Use.InputTokens
+		s.usage.OutputTokens += event.Use.OutputTokens
+		s.usage.CachedInputTokens += event.Use.CachedInputTokens
+		s.usage.CacheWriteTokens += event.Use.CacheWriteTokens
+	case err == nil && event.Type == EventError && event.Err != nil:
+		s.finish(event.Err)
+	case errors.Is(err, io.EOF):
+		s.finish(nil)
+	case err != nil:
+		s.finish(err)
+	}
+	return event, err
+}
+
+func (s *middlewareStream) Close() error {
+	s.finish(nil)
+	return s.Stream.Close()
+}
+
+func (s *middlewareStream) finish(err error) {
+	s.once.Do(func() { s.after(s.usage, err) })
+}
+
+const middlewareHTTPKey contextKey = "middleware_http"
+
+// middlewareHTTPState carries the headers and raw-request hooks of the
+// current provider call to the HTTP transport.
+type middlewareHTTPState struct {
+	header http.Header
+	hooks  []HTTPMiddleware
+}
+
+// applyHTTPMiddleware applies the middleware headers and hooks carried by
+// ctx to an outgoing HTTP request. It returns req unchanged when there are
+// none, and otherwise a clone, as RoundTrippers must not modify requests.
+func applyHTTPMiddleware(ctx context.Context, req *http.Request) (*http.Request, error) {
+	state, _ := ctx.Value(middlewareHTTPKey).(*middlewareHTTPState)
+	if state == nil {
+		return req, nil
+	}
+	req = req.Clone(ctx)
+	for key, values := range state.header {
+		req.Header[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+	}
+	for _, hook := range state.hooks {
+		if err := hook.BeforeHTTPRequest(req); err != nil {
+			return nil, fmt.Errorf("request blocked by middleware: %w", err)
+		}
+	}
+	return req, nil
+}
+
+// middlewareTransport applies engine middleware to requests made through the
+// shared provider HTTP client.
+type middlewareTransport struct {
+	base http.RoundTripper
+}
+
+func (t *middlewareTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req, err := applyHTTPMiddleware(req.Context(), req)
+	if err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// anthropicHTTPMiddleware applies engine middleware to requests made through
+// the Anthropic SDK, which uses its own HTTP client.
+func anthropicHTTPMiddleware(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+	req, err := applyHTTPMiddleware(req.Context(), req)
+	if err != nil {
+		return nil, err
+	}
+	return next(req)
+}
+
+// debugRawMiddleware prints each request to stderr when Request.DebugRaw is
+// set (--debug-raw).
+type debugRawMiddleware struct{}
+
+func (debugRawMiddleware) BeforeRequest(_ context.Context, req *MiddlewareRequest) error {
+	if !req.Request.DebugRaw || req.Retry > 0 {
+		return nil
+	}
+	label := "Request"
+	if req.Turn >= 0 {
+		label = fmt.Sprintf("Request (turn %d)", req.Turn)
+	}
+	DebugRawRequest(true, req.Provider, req.Credential, *req.Request, label)
+	return nil
+}
+
+func (debugRawMiddleware) AfterResponse(context.Context, MiddlewareResponse) {}
+
+// debugLoggerMiddleware writes each request, and every event of the
+// response stream, to the session debug log.
+type debugLoggerMiddleware struct {
+	logger *DebugLogger
+}
+
+func (m debugLoggerMiddleware) BeforeRequest(_ context.Context, req *MiddlewareRequest) error {
+	if req.Retry > 0 {
+		return nil
+	}
+	if req.Turn < 0 {
+		m.logger.LogRequest(req.Provider, req.Request.Model, *req.Request)
+	} else {
+		m.logger.LogTurnRequest(req.Turn, req.Provider, req.Request.Model, *req.Request)
+	}
+	return nil
+}
+
+func (debugLoggerMiddleware) AfterResponse(context.Context, MiddlewareResponse) {}
+
+func (m debugLoggerMiddleware) WrapStream(_ context.Context, req Request, s Stream) Stream {
+	return &debugLoggingStream{inner: s, logger: m.logger, turnID: req.TurnID}
+}
+
+// debugLoggingStream logs each event it passes on to the debug log.
+type debugLoggingStream struct {
+	inner  Stream
+	logger *DebugLogger
+	turnID string
+}
+
+func (s *debugLoggingStream) Recv() (Event, error) {
+	event, err := s.inner.Recv()
+	if err == nil {
+		s.logger.LogTurnEvent(s.turnID, event)
+	}
+	return event, err
+}
+
+func (s *debugLoggingStream) Close() error {
+	return s.inner.Close()
+}
@@ -0,0 +1,114 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/config"
+)
+
+// ApplyRequestsConfig registers the middlewares configured under requests:
+// fixed headers for every provider HTTP request and an audit log of every
+// outgoing request. Engines rebuilt mid-session keep them through
+// InheritRuntime, so call it once, where the engine is first built.
+func (e *Engine) ApplyRequestsConfig(cfg config.RequestsConfig) {
+	if len(cfg.Headers) > 0 {
+		header := http.Header{}
+		for name, value := range cfg.Headers {
+			header.Set(name, value)
+		}
+		e.Use(headerMiddleware{header: header})
+	}
+	if path := strings.TrimSpace(cfg.AuditLog); path != "" {
+		e.Use(&auditLogMiddleware{path: path})
+	}
+}
+
+// headerMiddleware adds a fixed set of headers to every request.
+type headerMiddleware struct {
+	header http.Header
+}
+
+func (m headerMiddleware) BeforeRequest(_ context.Context, req *MiddlewareRequest) error {
+	for name, values := range m.header {
+		req.Header[name] = append([]string(nil), values...)
+	}
+	return nil
+}
+
+func (headerMiddleware) AfterResponse(context.Context, MiddlewareResponse) {}
+
+// auditLogMiddleware appends every request the engine sends, retries
+// included, to a JSON Lines file.
+type auditLogMiddleware struct {
+	path   string
+	mu     sync.Mutex
+	warned bool
+}
+
+// auditLogEntry is one line of the audit log.
+type auditLogEntry struct {
+	Timestamp string           `json:"timestamp"`
+	Provider  string           `json:"provider"`
+	Model     string           `json:"model,omitempty"`
+	TurnID    string           `json:"turn_id,omitempty"`
+	Turn      int              `json:"turn"`
+	Retry     int              `json:"retry,omitempty"`
+	Request   debugRequestData `json:"request"`
+}
+
+func (m *auditLogMiddleware) BeforeRequest(_ context.Context, req *MiddlewareRequest) error {
+	data, err := json.Marshal(auditLogEntry{
+		Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+		Provider:  req.Provider,
+		Model:     req.Request.Model,
+		TurnID:    req.Request.TurnID,
+		Turn:      req.Turn,
+		Retry:     req.Retry,
+		Request:   newDebugRequestData(*req.Request),
+	})
+	if err == nil {
+		err = m.append(append(data, '\n'))
+	}
+	if err != nil {
+		m.warnOnce(err)
+	}
+	return nil
+}
+
+func (*auditLogMiddleware) AfterResponse(context.Context, MiddlewareResponse) {}
+
+func (m *auditLogMiddleware) append(line []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(m.path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(m.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(line); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// warnOnce logs the first audit log failure; the request still goes out.
+func (m *auditLogMiddleware) warnOnce(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.warned {
+		return
+	}
+	m.warned = true
+	slog.Warn("cannot write request audit log", "path", m.path, "error", err)
+}
@@ -0,0 +1,317 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/samsaffron/term-llm/internal/config"
+)
+
+// recordingMiddleware logs every hook call into a shared log, and can add a
+// header, mutate the request or veto it.
+type recordingMiddleware struct {
+	name   string
+	log    *[]string
+	mu     *sync.Mutex
+	header string
+	veto   error
+	mutate func(*Request)
+
+	after    []MiddlewareResponse
+	httpURLs []string
+	retries  []int
+}
+
+func (m *recordingMiddleware) record(entry string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	*m.log = append(*m.log, entry)
+}
+
+func (m *recordingMiddleware) BeforeRequest(_ context.Context, req *MiddlewareRequest) error {
+	m.record(fmt.Sprintf("%s before turn=%d", m.name, req.Turn))
+	m.mu.Lock()
+	m.retries = append(m.retries, req.Retry)
+	m.mu.Unlock()
+	if m.header != "" {
+		req.Header.Set("X-Org-Policy", m.header)
+	}
+	if m.mutate != nil {
+		m.mutate(req.Request)
+	}
+	return m.veto
+}
+
+func (m *recordingMiddleware) AfterResponse(_ context.Context, resp MiddlewareResponse) {
+	m.record(fmt.Sprintf("%s after turn=%d", m.name, resp.Turn))
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.after = append(m.after, resp)
+}
+
+func (m *recordingMiddleware) BeforeHTTPRequest(req *http.Request) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.httpURLs = append(m.httpURLs, req.URL.Path+" "+req.Header.Get("X-Org-Policy"))
+	return nil
+}
+
+func newRecordingMiddlewares(names ...string) ([]*recordingMiddleware, *[]string) {
+	log := &[]string{}
+	mu := &sync.Mutex{}
+	var mws []*recordingMiddleware
+	for _, name := range names {
+		mws = append(mws, &recordingMiddleware{name: name, log: log, mu: mu})
+	}
+	return mws, log
+}
+
+func TestEngineMiddlewareRunsInOrderAroundEachTurn(t *testing.T) {
+	provider := NewMockProvider("mock").
+		AddToolCall("call-1", "test_tool", map[string]any{}).
+		AddTurn(MockTurn{Text: "done", Usage: Usage{InputTokens: 120, OutputTokens: 7}})
+	registry := NewToolRegistry()
+	registry.Register(&mockTool{name: "test_tool", result: "tool output"})
+	engine := NewEngine(provider, registry)
+
+	mws, log := newRecordingMiddlewares("audit", "policy")
+	mws[1].mutate = func(req *Request) { req.Temperature = 0.25 }
+	engine.Use(mws[0], mws[1])
+
+	stream, err := engine.Stream(context.Background(), Request{
+		Messages: []Message{UserText("use the tool")},
+		Tools:    []ToolSpec{{Name: "test_tool"}},
+	})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	drainStream(t, stream)
+	stream.Close()
+
+	want := []string{
+		"audit before turn=0", "policy before turn=0", "audit after turn=0", "policy after turn=0",
+		"audit before turn=1", "policy before turn=1", "audit after turn=1", "policy after turn=1",
+	}
+	if got := strings.Join(*log, "\n"); got != strings.Join(want, "\n") {
+		t.Fatalf("hook calls:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+	}
+	for i, req := range provider.RecordedRequests() {
+		if req.Temperature != 0.25 {
+			t.Fatalf("request %d temperature = %v, want the middleware's 0.25", i, req.Temperature)
+		}
+	}
+	last := mws[0].after[1]
+	if last.Usage.InputTokens != 120 || last.Usage.OutputTokens != 7 || last.Err != nil || last.Provider != "mock" {
+		t.Fatalf("after turn 1 = %+v, want usage 120/7 and no error", last)
+	}
+}
+
+func TestEngineMiddlewareVetoSkipsProvider(t *testing.T) {
+	provider := NewMockProvider("mock").AddTextResponse("should not be sent")
+	engine := NewEngine(provider, nil)
+
+	mws, log := newRecordingMiddlewares("audit", "policy", "late")
+	mws[1].veto = errors.New("model not approved")
+	engine.Use(mws[0], mws[1], mws[2])
+
+	stream, err := engine.Stream(context.Background(), Request{Messages: []Message{UserText("hi")}})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	err = drainStreamErr(t, stream)
+	if err == nil || !strings.Contains(err.Error(), "request blocked by middleware: model not approved") {
+		t.Fatalf("stream error = %v, want the veto", err)
+	}
+	if n := len(provider.RecordedRequests()); n != 0 {
+		t.Fatalf("provider received %d requests after a veto", n)
+	}
+	if got, want := strings.Join(*log, ","), "audit before turn=-1,policy before turn=-1"; got != want {
+		t.Fatalf("hook calls = %s, want %s", got, want)
+	}
+}
+
+func TestEngineMiddlewareHeadersReachHTTPProviders(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		provider func(url string) Provider
+	}{
+		{
+			name:     "openai compatible",
+			response: "data: {\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n\ndata: [DONE]\n\n",
+			provider: func(url string) Provider { return NewOpenAICompatProvider(url, "", "m", "Compat") },
+		},
+		{
+			name:     "anthropic",
+			response: minimalAnthropicSSE(),
+			provider: func(url string) Provider {
+				client := anthropic.NewClient(option.WithAPIKey("test-key"), option.WithBaseURL(url), option.WithMaxRetries(0))
+				return &AnthropicProvider{client: &client, model: "claude-test"}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotHeader string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotHeader = r.Header.Get("X-Org-Policy")
+				w.Header().Set("Content-Type", "text/event-stream")
+				_, _ = io.WriteString(w, tt.response)
+			}))
+			defer srv.Close()
+
+			engine := NewEngine(tt.provider(srv.URL), nil)
+			mws, _ := newRecordingMiddlewares("policy")
+			mws[0].header = "restricted"
+			engine.Use(mws[0])
+
+			stream, err := engine.Stream(context.Background(), Request{Messages: []Message{UserText("hi")}})
+			if err != nil {
+				t.Fatalf("Stream: %v", err)
+			}
+			drainStream(t, stream)
+			stream.Close()
+
+			if gotHeader != "restricted" {
+				t.Fatalf("server saw X-Org-Policy %q, want restricted", gotHeader)
+			}
+			if len(mws[0].httpURLs) != 1 || !strings.HasSuffix(mws[0].httpURLs[0], " restricted") {
+				t.Fatalf("raw HTTP hook saw %q, want one request carrying the header", mws[0].httpURLs)
+			}
+		})
+	}
+}
+
+func TestEngineMiddlewareCountsSimpleRetries(t *testing.T) {
+	useFastTransientRetries(t)
+	provider := &flakyProvider{
+		fakeProvider: fakeProvider{script: func(call int, req Request) []Event {
+			return []Event{{Type: EventTextDelta, Text: "hello"}, {Type: EventDone}}
+		}},
+		createFailures: 1,
+		createErr:      newHTTPStatusErrorString("test", http.StatusBadGateway, "502 Bad Gateway", nil, "upstream"),
+	}
+	engine := NewEngine(provider, nil)
+	mws, _ := newRecordingMiddlewares("audit")
+	engine.Use(mws[0])
+
+	if _, err := collectEngineEvents(t, engine, Request{Messages: []Message{UserText("hi")}}); err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+	if got := fmt.Sprint(mws[0].retries); got != "[0 1]" {
+		t.Fatalf("retries seen by middleware = %s, want [0 1] so loggers can skip the resend", got)
+	}
+}
+
+func TestApplyRequestsConfigAddsHeadersAndAuditLog(t *testing.T) {
+	var gotHeader string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("X-Org-Id")
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "data: {\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n\ndata: [DONE]\n\n")
+	}))
+	defer srv.Close()
+
+	auditPath := filepath.Join(t.TempDir(), "audit", "requests.jsonl")
+	engine := NewEngine(NewOpenAICompatProvider(srv.URL, "", "m", "Compat"), nil)
+	// Viper lowercases map keys, so configured header names arrive that way.
+	engine.ApplyRequestsConfig(config.RequestsConfig{
+		Headers:  map[string]string{"x-org-id": "acme"},
+		AuditLog: auditPath,
+	})
+
+	stream, err := engine.Stream(context.Background(), Request{Messages: []Message{UserText("audit me")}})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	drainStream(t, stream)
+	stream.Close()
+
+	if gotHeader != "acme" {
+		t.Fatalf("server saw X-Org-Id %q, want acme", gotHeader)
+	}
+	data, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatalf("read audit log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	var entry auditLogEntry
+	if len(lines) != 1 || json.Unmarshal([]byte(lines[0]), &entry) != nil {
+		t.Fatalf("audit log = %q, want one JSON line", data)
+	}
+	if entry.Provider != "Compat (m)" || entry.Turn != -1 || len(entry.Request.Messages) != 1 {
+		t.Fatalf("audit entry = %+v, want the one outgoing request", entry)
+	}
+}
+
+func TestApplyHTTPMiddlewareLeavesRequestsWithoutStateAlone(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "https://example.com/v1", nil)
+	got, err := applyHTTPMiddleware(context.Background(), req)
+	if err != nil || got != req {
+		t.Fatalf("applyHTTPMiddleware without state = %p, %v; want the same request", got, err)
+	}
+}
+
+func TestEngineRebuiltForModelSwitchKeepsConfigAndMiddlewares(t *testing.T) {
+	registry := NewToolRegistry()
+	registry.Register(&mockTool{name: "test_tool", result: "ok"})
+	old := NewEngine(NewMockProvider("old"), registry)
+	mws, log := newRecordingMiddlewares("audit")
+	old.Use(mws[0])
+	old.SetSystemContext(func() string { return "project rules" })
+	old.SetAllowedToolsFilter([]string{"test_tool"})
+
+	cfg := config.ToolsConfig{
+		MaxToolOutputChars: 1234,
+		ResultLimits:       map[string]int{"shell": 99},
+		MaxParallel:        2,
+		SerialTools:        []string{"test_tool"},
+		LoopThreshold:      5,
+		Timeout:            "45s",
+	}
+	provider := NewMockProvider("new").AddTextResponse("hi")
+	engine := NewEngine(provider, old.Tools())
+	if err := engine.ApplyToolsConfig(cfg); err != nil {
+		t.Fatalf("ApplyToolsConfig: %v", err)
+	}
+	engine.InheritRuntime(old)
+
+	maxParallel, serial := engine.parallelToolSettings()
+	if engine.maxToolOutputChars != 1234 || engine.toolResultLimits["shell"] != 99 || maxParallel != 2 || !serial["test_tool"] ||
+		engine.toolLoopThreshold != 5 || engine.toolTimeoutFor("test_tool") != 45*time.Second {
+		t.Fatalf("tool settings not applied: max=%d limits=%v parallel=%d serial=%v loop=%d", engine.maxToolOutputChars, engine.toolResultLimits, maxParallel, serial, engine.toolLoopThreshold)
+	}
+	if got := engine.SystemContext(); got == nil || got() != "project rules" {
+		t.Fatal("system context was not carried over")
+	}
+	if allowed, present := engine.AllowedToolsFilter(); !present || len(allowed) != 1 || allowed[0] != "test_tool" {
+		t.Fatalf("allowed tools = %v (present %v), want the old filter", allowed, present)
+	}
+
+	stream, err := engine.Stream(context.Background(), Request{Messages: []Message{UserText("hello")}})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	drainStream(t, stream)
+	stream.Close()
+	if len(*log) != 2 {
+		t.Fatalf("middleware calls = %v, want the inherited middleware around the request", *log)
+	}
+
+	if err := engine.ApplyToolsConfig(config.ToolsConfig{Timeout: "soon"}); err == nil {
+		t.Fatal("invalid timeout should be reported")
+	}
+}
@@ -16,6 +16,26 @@ type MockTurn struct {
 	Usage     Usage         // Token usage to report
 	Delay     time.Duration // Optional delay before responding (for timeout tests)
 	Error     error         // Return this error instead of responding
+
+	// Events scripts the turn step by step. When set it replaces Text,
+	// ToolCalls and Usage, and the events are emitted exactly in order.
+	Events []MockEvent
+
+	// OnRequest, when set, is called with the request that consumed this
+	// turn before anything is streamed, so tests can assert on the
+	// messages, tools and tool choice the engine sent.
+	OnRequest func(Request)
+}
+
+// MockEvent is one step of a scripted MockTurn. Set one of Text,
+// Reasoning, ToolCall, Usage or Error; Delay is waited out first.
+type MockEvent struct {
+	Delay     time.Duration
+	Text      string    // Emitted as a single text delta
+	Reasoning string    // Emitted as a single reasoning delta
+	ToolCall  *ToolCall // Emitted as a tool call
+	Usage     *Usage    // Emitted as a usage event
+	Error     error     // Fails the stream mid-turn
 }
 
 // MockProvider is a configurable provider for testing.
@@ -91,6 +111,21 @@ func (m *MockProvider) AddError(err error) *MockProvider {
 	return m.AddTurn(MockTurn{Error: err})
 }
 
+// AddEvents is a convenience method to add a turn scripted event by event.
+func (m *MockProvider) AddEvents(events ...MockEvent) *MockProvider {
+	return m.AddTurn(MockTurn{Events: events})
+}
+
+// LastRequest returns the most recent request the provider received.
+func (m *MockProvider) LastRequest() (Request, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.Requests) == 0 {
+		return Request{}, false
+	}
+	return m.Requests[len(m.Requests)-1], true
+}
+
 // RecordedRequests returns a snapshot of requests observed by the provider.
 func (m *MockProvider) RecordedRequests() []Request {
 	m.mu.Lock()
@@ -142,6 +177,10 @@ func (m *MockProvider) Stream(ctx context.Context, req Request) (Stream, error)
 	m.turnIndex++
 	m.mu.Unlock()
 
+	if turn.OnRequest != nil {
+		turn.OnRequest(req)
+	}
+
 	return newEventStream(ctx, func(ctx context.Context, send eventSender) error {
 		// Apply delay if configured
 		if turn.Delay > 0 {
@@ -157,6 +196,10 @@ func (m *MockProvider) Stream(ctx context.Context, req Request) (Stream, error)
 			return turn.Error
 		}
 
+		if len(turn.Events) > 0 {
+			return streamMockEvents(ctx, turn.Events, send)
+		}
+
 		// Emit text in chunks (simulates realistic streaming)
 		if turn.Text != "" {
 			for _, chunk := range chunkText(turn.Text, 10) {
@@ -178,6 +221,37 @@ func (m *MockProvider) Stream(ctx context.Context, req Request) (Stream, error)
 	}), nil
 }
 
+// streamMockEvents emits a scripted turn step by step.
+func streamMockEvents(ctx context.Context, events []MockEvent, send eventSender) error {
+	for i := range events {
+		ev := events[i]
+		if ev.Delay > 0 {
+			if err := sleepWithContext(ctx, ev.Delay); err != nil {
+				return err
+			}
+		}
+		var out Event
+		switch {
+		case ev.Error != nil:
+			return ev.Error
+		case ev.Text != "":
+			out = Event{Type: EventTextDelta, Text: ev.Text}
+		case ev.Reasoning != "":
+			out = Event{Type: EventReasoningDelta, Text: ev.Reasoning}
+		case ev.ToolCall != nil:
+			out = Event{Type: EventToolCall, Tool: ev.ToolCall}
+		case ev.Usage != nil:
+			out = Event{Type: EventUsage, Use: ev.Usage}
+		default:
+			continue
+		}
+		if err := send.Send(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // chunkText splits text into chunks of approximately the given size.
 // It tries to break at word boundaries when possible and always splits
 // on valid rune boundaries so that every chunk is valid UTF-8.
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"strings"
 	"testing"
 	"time"
 	"unicode/utf8"
@@ -345,6 +347,124 @@ func TestMockProvider_CancelDuringDelay(t *testing.T) {
 	}
 }
 
+func TestMockProvider_ScriptedEvents(t *testing.T) {
+	t.Parallel()
+
+	p := NewMockProvider("test").AddEvents(
+		MockEvent{Reasoning: "thinking"},
+		MockEvent{Text: "Hel"},
+		MockEvent{Delay: 30 * time.Millisecond, Text: "lo"},
+		MockEvent{Usage: &Usage{InputTokens: 3, OutputTokens: 2}},
+		MockEvent{Error: errors.New("connection reset")},
+		MockEvent{Text: "never sent"},
+	)
+
+	start := time.Now()
+	stream, err := p.Stream(context.Background(), Request{})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	defer stream.Close()
+
+	var got []string
+	var streamErr error
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			streamErr = err
+			break
+		}
+		switch event.Type {
+		case EventTextDelta, EventReasoningDelta:
+			got = append(got, string(event.Type)+":"+event.Text)
+		case EventUsage:
+			got = append(got, fmt.Sprintf("usage:%d/%d", event.Use.InputTokens, event.Use.OutputTokens))
+		case EventError:
+			streamErr = event.Err
+		}
+		if streamErr != nil {
+			break
+		}
+	}
+
+	want := []string{"reasoning_delta:thinking", "text_delta:Hel", "text_delta:lo", "usage:3/2"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+	if streamErr == nil || !strings.Contains(streamErr.Error(), "connection reset") {
+		t.Errorf("stream error = %v, want the scripted mid-stream error", streamErr)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("expected the scripted delay, stream finished in %v", elapsed)
+	}
+}
+
+func TestMockProvider_ScriptedToolLoop(t *testing.T) {
+	t.Parallel()
+
+	registry := NewToolRegistry()
+	registry.Register(&mockTool{name: "read_file", result: "package main"})
+	var secondTurn Request
+	p := NewMockProvider("test").
+		AddTurn(MockTurn{
+			Events: []MockEvent{{ToolCall: &ToolCall{ID: "call-1", Name: "read_file", Arguments: json.RawMessage(`{"path":"main.go"}`)}}},
+			OnRequest: func(req Request) {
+				if len(req.Tools) != 1 || req.Tools[0].Name != "read_file" {
+					t.Errorf("first turn tools = %+v, want read_file", req.Tools)
+				}
+			},
+		}).
+		AddTurn(MockTurn{Text: "It is a main package.", OnRequest: func(req Request) { secondTurn = req }})
+
+	engine := NewEngine(p, registry)
+	stream, err := engine.Stream(context.Background(), Request{
+		Messages: []Message{UserText("what is main.go?")},
+		Tools:    []ToolSpec{{Name: "read_file"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+	var text strings.Builder
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv() error = %v", err)
+		}
+		if event.Type == EventTextDelta {
+			text.WriteString(event.Text)
+		}
+	}
+
+	if text.String() != "It is a main package." {
+		t.Errorf("final text = %q", text.String())
+	}
+	if p.CurrentTurn() != 2 {
+		t.Fatalf("turns consumed = %d, want 2", p.CurrentTurn())
+	}
+	last, ok := p.LastRequest()
+	if !ok || len(last.Messages) != len(secondTurn.Messages) {
+		t.Fatalf("LastRequest() = %v, %v; want the second turn's request", ok, len(last.Messages))
+	}
+	var sawResult bool
+	for _, msg := range secondTurn.Messages {
+		for _, part := range msg.Parts {
+			if part.ToolResult != nil && part.ToolResult.ID == "call-1" && part.ToolResult.Content == "package main" {
+				sawResult = true
+			}
+		}
+	}
+	if !sawResult {
+		t.Errorf("second turn did not carry the read_file result: %+v", secondTurn.Messages)
+	}
+}
+
 func TestMockProvider_Reset(t *testing.T) {
 	t.Parallel()
 
@@ -279,7 +279,7 @@ func buildOllamaTools(specs []ToolSpec) ([]ollamaTool, error) {
 	}
 	tools := make([]ollamaTool, 0, len(specs))
 	for _, spec := range specs {
-		schema, err := cachedToolSchemaJSON(spec.Schema)
+		schema, err := toolSchemaJSONFor(spec.Schema, ToolSchemaCanonical)
 		if err != nil {
 			return nil, fmt.Errorf("marshal tool schema %s: %w", spec.Name, err)
 		}
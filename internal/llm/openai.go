@@ -82,6 +82,7 @@ func (p *OpenAIProvider) Capabilities() Capabilities {
 		NativeWebFetch:     false, // No native URL fetch
 		ToolCalls:          true,
 		SupportsToolChoice: true,
+		StructuredOutput:   true,
 	}
 }
 
@@ -147,6 +148,8 @@ func (p *OpenAIProvider) Stream(ctx context.Context, req Request) (Stream, error
 		Include:          []string{"reasoning.encrypted_content"},
 		Stream:           true,
 		SessionID:        req.SessionID,
+		Ephemeral:        req.Ephemeral,
+		Text:             buildResponsesText(req.ResponseFormat),
 	}
 
 	if serviceTier := p.serviceTier; req.ServiceTierSet || strings.TrimSpace(req.ServiceTier) != "" {
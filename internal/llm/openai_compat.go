@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -12,6 +13,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/samsaffron/term-llm/internal/config"
@@ -30,7 +32,7 @@ func newStreamingHTTPClient() *http.Client {
 	transport.ResponseHeaderTimeout = 2 * time.Minute
 	transport.IdleConnTimeout = 90 * time.Second
 	transport.MaxIdleConnsPerHost = 100
-	return &http.Client{Transport: transport}
+	return &http.Client{Transport: &middlewareTransport{base: transport}}
 }
 
 // defaultHTTPClient is a shared HTTP client with transport-level timeouts.
@@ -61,6 +63,9 @@ type OpenAICompatProvider struct {
 	includeReasoning  *bool                        // Optional include_reasoning request flag for compatible reasoning parsers
 	thinkingParam     string                       // Optional chat_template_kwargs key set to true when reasoning effort is requested
 	modelConfigs      []config.ProviderModelConfig // Optional per-model aliases/metadata from config
+	toolCalls         *bool                        // nil = send tools until the server rejects them; false = never send tools
+	toolsRejected     atomic.Bool                  // Set once the server answers that the model does not support tools
+	structuredOutput  bool                         // Send response_format; otherwise the engine prompts for and validates JSON
 }
 
 func NewOpenAICompatProvider(baseURL, apiKey, model, name string) *OpenAICompatProvider {
@@ -137,13 +142,49 @@ func cloneProviderModelConfigs(modelConfigs []config.ProviderModelConfig) []conf
 	return out
 }
 
+// SetToolCalls sets whether tools are sent to the server. nil sends them
+// until the server rejects them for the model.
+func (p *OpenAICompatProvider) SetToolCalls(enabled *bool) {
+	p.toolCalls = enabled
+}
+
+// SetStructuredOutput sets whether the server enforces response_format. nil
+// keeps the backend's default; servers that do not enforce it get the JSON
+// instruction in the system prompt and their replies are validated instead.
+func (p *OpenAICompatProvider) SetStructuredOutput(enabled *bool) {
+	if enabled != nil {
+		p.structuredOutput = *enabled
+	}
+}
+
+func (p *OpenAICompatProvider) toolCallsEnabled() bool {
+	if p.toolCalls != nil {
+		return *p.toolCalls
+	}
+	return !p.toolsRejected.Load()
+}
+
 func (p *OpenAICompatProvider) Capabilities() Capabilities {
+	toolCalls := p.toolCallsEnabled()
 	return Capabilities{
 		NativeWebSearch:    false,
 		NativeWebFetch:     false,
-		ToolCalls:          true,
-		SupportsToolChoice: true, // OpenAI API supports tool_choice
+		ToolCalls:          toolCalls,
+		SupportsToolChoice: toolCalls, // OpenAI API supports tool_choice
+		StructuredOutput:   p.structuredOutput,
+	}
+}
+
+// isToolsUnsupportedError reports whether a chat request was rejected
+// because the model cannot call tools, as Ollama and llama.cpp do for
+// models without a tool-calling template.
+func isToolsUnsupportedError(err error) bool {
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadRequest {
+		return false
 	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "does not support tools") || strings.Contains(msg, "tools are not supported") || strings.Contains(msg, "tool calling is not supported")
 }
 
 // OpenAI-compatible request/response structures
@@ -165,6 +206,7 @@ type oaiChatRequest struct {
 	ParseReasoning      *bool                  `json:"parse_reasoning,omitempty"`
 	IncludeReasoning    *bool                  `json:"include_reasoning,omitempty"`
 	VeniceParameters    map[string]interface{} `json:"venice_parameters,omitempty"`
+	ResponseFormat      *oaiResponseFormat     `json:"response_format,omitempty"`
 }
 
 type oaiStreamOptions struct {
@@ -573,9 +615,12 @@ func (p *OpenAICompatProvider) Stream(ctx context.Context, req Request) (Stream,
 		return nil, fmt.Errorf("no messages provided")
 	}
 
-	tools, err := buildCompatTools(req.Tools)
-	if err != nil {
-		return nil, err
+	var tools []oaiTool
+	if p.toolCallsEnabled() {
+		var err error
+		if tools, err = buildCompatTools(req.Tools); err != nil {
+			return nil, err
+		}
 	}
 
 	chatReq := oaiChatRequest{
@@ -585,6 +630,9 @@ func (p *OpenAICompatProvider) Stream(ctx context.Context, req Request) (Stream,
 		Stream:          true,
 		ReasoningEffort: effort,
 	}
+	if p.structuredOutput {
+		chatReq.ResponseFormat = buildCompatResponseFormat(req.ResponseFormat)
+	}
 	if p.vllmThinking {
 		kwargs, budget, vllmReasoningEffort := vLLMThinkingSettings(model, effort, p.vllmThinkingParam)
 		chatReq.ReasoningEffort = vllmReasoningEffort
@@ -609,7 +657,7 @@ func (p *OpenAICompatProvider) Stream(ctx context.Context, req Request) (Stream,
 		chatReq.StreamOptions = &oaiStreamOptions{IncludeUsage: true}
 	}
 
-	if req.ToolChoice.Mode != "" {
+	if req.ToolChoice.Mode != "" && len(tools) > 0 {
 		chatReq.ToolChoice = buildCompatToolChoice(req.ToolChoice)
 	}
 	if len(tools) > 0 {
@@ -649,7 +697,22 @@ func (p *OpenAICompatProvider) Stream(ctx context.Context, req Request) (Stream,
 
 	// Check for error responses synchronously so retry logic can handle them
 	if resp.StatusCode != 200 {
-		return nil, newOpenAICompatStatusErrorFromResponse(p.name, resp)
+		statusErr := newOpenAICompatStatusErrorFromResponse(p.name, resp)
+		if len(tools) == 0 || p.toolCalls != nil || !isToolsUnsupportedError(statusErr) {
+			return nil, statusErr
+		}
+		// The model has no tool-calling support: stop advertising tools
+		// and answer this turn without them.
+		p.toolsRejected.Store(true)
+		chatReq.Tools = nil
+		chatReq.ToolChoice = nil
+		chatReq.ParallelToolCalls = nil
+		if resp, err = p.makeChatRequest(ctx, chatReq); err != nil {
+			return nil, fmt.Errorf("%s API request failed: %w", p.name, err)
+		}
+		if resp.StatusCode != 200 {
+			return nil, newOpenAICompatStatusErrorFromResponse(p.name, resp)
+		}
 	}
 
 	// Only create async stream for successful HTTP responses
@@ -661,6 +724,7 @@ func (p *OpenAICompatProvider) Stream(ctx context.Context, req Request) (Stream,
 		toolState := newCompatToolState()
 		var lastUsage *Usage
 		var reasoningBuilder strings.Builder
+		outputBytes := 0
 		sawVisibleText := false
 		sawToolCallsFinish := false
 
@@ -720,6 +784,7 @@ func (p *OpenAICompatProvider) Stream(ctx context.Context, req Request) (Stream,
 						// globally trim leading whitespace: only suppress this known reasoning
 						// artifact, and preserve whitespace once visible text has started.
 						isReasoningWhitespaceArtifact := isLeadingReasoningWhitespaceArtifact(content, reasoningDelta, sawVisibleText)
+						outputBytes += len(content)
 						if !isReasoningWhitespaceArtifact {
 							if hasVisibleTextDelta(content) {
 								sawVisibleText = true
@@ -730,12 +795,16 @@ func (p *OpenAICompatProvider) Stream(ctx context.Context, req Request) (Stream,
 						}
 					}
 					if reasoningDelta != "" {
+						outputBytes += len(reasoningDelta)
 						reasoningBuilder.WriteString(reasoningDelta)
 						if err := send.Send(Event{Type: EventReasoningDelta, Text: reasoningDelta, ReasoningKind: ReasoningKindRaw}); err != nil {
 							return err
 						}
 					}
 					if len(choice.Delta.ToolCalls) > 0 {
+						for _, call := range choice.Delta.ToolCalls {
+							outputBytes += len(call.Function.Name) + len(call.Function.Arguments)
+						}
 						toolState.Add(choice.Delta.ToolCalls)
 					}
 				}
@@ -757,6 +826,14 @@ func (p *OpenAICompatProvider) Stream(ctx context.Context, req Request) (Stream,
 				return err
 			}
 		}
+		if lastUsage == nil && sawDone {
+			// Many local servers never report usage in the stream; estimate it
+			// so the context indicator still moves.
+			lastUsage = &Usage{
+				InputTokens:  EstimateModelMessageTokens(model, req.Messages),
+				OutputTokens: (outputBytes + approxBytesPerToken - 1) / approxBytesPerToken,
+			}
+		}
 		if lastUsage != nil {
 			if err := send.Send(Event{Type: EventUsage, Use: lastUsage}); err != nil {
 				return err
@@ -947,7 +1024,7 @@ func buildCompatTools(specs []ToolSpec) ([]oaiTool, error) {
 	}
 	tools := make([]oaiTool, 0, len(specs))
 	for _, spec := range specs {
-		schema, err := cachedToolSchemaJSON(spec.Schema)
+		schema, err := toolSchemaJSONFor(spec.Schema, ToolSchemaCanonical)
 		if err != nil {
 			return nil, fmt.Errorf("marshal tool schema %s: %w", spec.Name, err)
 		}
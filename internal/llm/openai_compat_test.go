@@ -37,9 +37,13 @@ func TestProviderHTTPClients_UseHTTP2WithLargerIdlePool(t *testing.T) {
 	}
 	for name, providerClient := range clients {
 		t.Run(name, func(t *testing.T) {
-			providerTransport, ok := providerClient.Transport.(*http.Transport)
+			mw, ok := providerClient.Transport.(*middlewareTransport)
 			if !ok {
-				t.Fatalf("transport type = %T, want *http.Transport", providerClient.Transport)
+				t.Fatalf("transport type = %T, want *middlewareTransport", providerClient.Transport)
+			}
+			providerTransport, ok := mw.base.(*http.Transport)
+			if !ok {
+				t.Fatalf("base transport type = %T, want *http.Transport", mw.base)
 			}
 			if !providerTransport.ForceAttemptHTTP2 {
 				t.Fatal("HTTP/2 is not enabled")
@@ -2472,3 +2476,218 @@ func TestOpenAICompatStream_EmitsToolCallBeforeIncompleteError(t *testing.T) {
 		t.Fatalf("expected StreamIncompleteError, got %T %v", event.Err, event.Err)
 	}
 }
+
+func TestOpenAICompatStream_OllamaStyleStream(t *testing.T) {
+	var gotBodies []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("path = %q, want /v1/chat/completions", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("Authorization = %q, want none without an api_key", auth)
+		}
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		gotBodies = append(gotBodies, body)
+
+		if _, ok := body["tools"]; ok && len(gotBodies) == 1 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"error":{"message":"registry.ollama.ai/library/gemma3:4b does not support tools","type":"api_error"}}`)
+			return
+		}
+		// Ollama streams chunks without a usage block.
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w,
+			`data: {"id":"chatcmpl-1","object":"chat.completion.chunk","model":"gemma3:4b","choices":[{"index":0,"delta":{"role":"assistant","content":"Hello"},"finish_reason":null}]}`+"\n\n"+
+				`data: {"id":"chatcmpl-1","object":"chat.completion.chunk","model":"gemma3:4b","choices":[{"index":0,"delta":{"role":"assistant","content":" there"},"finish_reason":"stop"}]}`+"\n\n"+
+				"data: [DONE]\n\n",
+		)
+	}))
+	defer server.Close()
+
+	provider := NewOpenAICompatProvider(server.URL+"/v1", "", "gemma3:4b", "Ollama")
+	if !provider.Capabilities().ToolCalls {
+		t.Fatal("tool calls should be assumed until the server rejects them")
+	}
+	req := Request{
+		Messages:        []Message{UserText("say hello")},
+		Tools:           []ToolSpec{{Name: "read_file", Schema: map[string]interface{}{"type": "object"}}},
+		ToolChoice:      ToolChoice{Mode: ToolChoiceAuto},
+		MaxOutputTokens: 64,
+		Temperature:     0.5,
+		TemperatureSet:  true,
+	}
+	stream, err := provider.Stream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	defer stream.Close()
+
+	var text string
+	var usage *Usage
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("recv: %v", err)
+		}
+		switch event.Type {
+		case EventTextDelta:
+			text += event.Text
+		case EventUsage:
+			usage = event.Use
+		case EventError:
+			t.Fatalf("unexpected stream error: %v", event.Err)
+		}
+	}
+
+	if text != "Hello there" {
+		t.Fatalf("text = %q", text)
+	}
+	if len(gotBodies) != 2 {
+		t.Fatalf("requests = %d, want a retry without tools", len(gotBodies))
+	}
+	retry := gotBodies[1]
+	if _, ok := retry["tools"]; ok {
+		t.Fatal("retry should not send tools")
+	}
+	if _, ok := retry["tool_choice"]; ok {
+		t.Fatal("retry should not send tool_choice")
+	}
+	if retry["max_tokens"] != float64(64) || retry["temperature"] != 0.5 {
+		t.Fatalf("max_tokens/temperature = %v/%v", retry["max_tokens"], retry["temperature"])
+	}
+	if provider.Capabilities().ToolCalls {
+		t.Fatal("tool calls should be disabled after the server rejected them")
+	}
+	if usage == nil || usage.InputTokens != EstimateMessageTokens(req.Messages) || usage.OutputTokens != EstimateTokens("Hello there") {
+		t.Fatalf("usage = %+v, want an estimate", usage)
+	}
+}
+
+func TestOpenAICompatProvider_ToolCallsDisabledByConfig(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, `data: {"choices":[{"delta":{"content":"ok"}}],"usage":{"prompt_tokens":7,"completion_tokens":1,"total_tokens":8}}`+"\n\ndata: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	provider := NewOpenAICompatProvider(server.URL, "", "local", "Local")
+	disabled := false
+	provider.SetToolCalls(&disabled)
+	if provider.Capabilities().ToolCalls {
+		t.Fatal("Capabilities().ToolCalls should follow tool_calls: false")
+	}
+	stream, err := provider.Stream(context.Background(), Request{
+		Messages: []Message{UserText("hi")},
+		Tools:    []ToolSpec{{Name: "read_file", Schema: map[string]interface{}{"type": "object"}}},
+	})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	var usage *Usage
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("recv: %v", err)
+		}
+		if event.Type == EventUsage {
+			usage = event.Use
+		}
+	}
+	stream.Close()
+	if _, ok := body["tools"]; ok {
+		t.Fatal("tools should not be sent when tool calls are disabled")
+	}
+	if usage == nil || usage.InputTokens != 7 {
+		t.Fatalf("usage = %+v, want the server-reported usage", usage)
+	}
+}
+
+func TestOpenAICompatStream_SendsResponseFormat(t *testing.T) {
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"title": map[string]interface{}{"type": "string"}},
+		"required":   []interface{}{"title"},
+	}
+	tests := []struct {
+		name   string
+		format *ResponseFormat
+		want   string
+	}{
+		{"none", nil, ``},
+		{"json_object", &ResponseFormat{Type: ResponseFormatJSONObject}, `{"type":"json_object"}`},
+		{"json_schema", &ResponseFormat{Type: ResponseFormatJSONSchema, Name: "title", Schema: schema, Strict: true},
+			`{"type":"json_schema","json_schema":{"name":"title","schema":{"properties":{"title":{"type":"string"}},"required":["title"],"type":"object"},"strict":true}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]json.RawMessage
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Fatalf("decode request: %v", err)
+				}
+				w.Header().Set("Content-Type", "text/event-stream")
+				_, _ = io.WriteString(w, "data: {\"choices\":[{\"delta\":{\"content\":\"{}\"}}]}\n\ndata: [DONE]\n\n")
+			}))
+			defer server.Close()
+
+			provider := NewOpenAICompatProvider(server.URL, "", "model", "Compat")
+			enabled := true
+			provider.SetStructuredOutput(&enabled)
+			if !provider.Capabilities().StructuredOutput {
+				t.Fatal("structured_output: true should declare structured output support")
+			}
+			stream, err := provider.Stream(context.Background(), Request{Messages: []Message{UserText("hello")}, ResponseFormat: tt.format})
+			if err != nil {
+				t.Fatalf("Stream: %v", err)
+			}
+			defer stream.Close()
+			drainStreamToDone(t, stream)
+
+			if string(got["response_format"]) != tt.want {
+				t.Fatalf("response_format = %s, want %s", got["response_format"], tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenAICompatStream_OmitsResponseFormatByDefault(t *testing.T) {
+	var got map[string]json.RawMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "data: {\"choices\":[{\"delta\":{\"content\":\"{}\"}}]}\n\ndata: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	provider := NewOpenAICompatProvider(server.URL, "", "model", "Compat")
+	if provider.Capabilities().StructuredOutput {
+		t.Fatal("a generic compatible server should not be assumed to enforce response_format")
+	}
+	if !NewVLLMProvider(server.URL, "", "model", "vLLM").Capabilities().StructuredOutput {
+		t.Fatal("vLLM should enforce response_format by default")
+	}
+	stream, err := provider.Stream(context.Background(), Request{Messages: []Message{UserText("hello")}, ResponseFormat: &ResponseFormat{Type: ResponseFormatJSONObject}})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	defer stream.Close()
+	drainStreamToDone(t, stream)
+
+	if raw, ok := got["response_format"]; ok {
+		t.Fatalf("response_format = %s, want it left to the engine fallback", raw)
+	}
+}
@@ -482,3 +482,51 @@ func TestOpenAIProviderStreamMapsGPT56AdvancedResponsesControls(t *testing.T) {
 		t.Fatalf("PTC marker tool = %#v", got.Tools[1])
 	}
 }
+
+func TestOpenAIProviderStreamSendsResponseFormatAsTextFormat(t *testing.T) {
+	var got struct {
+		Text *ResponsesText `json:"text,omitempty"`
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("event: response.completed\n"))
+		_, _ = w.Write([]byte("data: {\"type\":\"response.completed\",\"response\":{\"id\":\"resp_1\"}}\n\n"))
+	}))
+	defer ts.Close()
+
+	provider := &OpenAIProvider{
+		apiKey: "test-key",
+		model:  "gpt-4.1",
+		responsesClient: &ResponsesClient{
+			BaseURL:       ts.URL,
+			GetAuthHeader: func() string { return "Bearer test-key" },
+			HTTPClient:    ts.Client(),
+		},
+	}
+	if !provider.Capabilities().StructuredOutput {
+		t.Fatal("OpenAI provider should declare structured output support")
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": map[string]interface{}{"ok": map[string]interface{}{"type": "boolean"}}}
+	stream, err := provider.Stream(context.Background(), Request{
+		Messages:       []Message{UserText("check")},
+		ResponseFormat: &ResponseFormat{Type: ResponseFormatJSONSchema, Name: "check", Schema: schema, Strict: true},
+	})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	defer stream.Close()
+	drainStreamToDone(t, stream)
+
+	if got.Text == nil || got.Text.Format == nil {
+		t.Fatalf("text.format missing: %+v", got.Text)
+	}
+	format := got.Text.Format
+	if format.Type != "json_schema" || format.Name != "check" || !format.Strict || format.Schema["type"] != "object" {
+		t.Fatalf("text.format = %+v, want strict json_schema named check", format)
+	}
+}
@@ -17,5 +17,6 @@ func NewOpenRouterProvider(apiKey, model, appURL, appTitle string) *OpenAICompat
 	actualModel, effort := ParseModelEffort(model)
 	p := NewOpenAICompatProviderWithHeaders(openRouterBaseURL, apiKey, actualModel, "OpenRouter", headers)
 	p.effort = effort
+	p.structuredOutput = true
 	return p
 }
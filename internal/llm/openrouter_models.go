@@ -120,6 +120,8 @@ func modelInfosFromCache(cached *cache.ModelCache) []ModelInfo {
 				InputLimit:  m.InputLimit,
 				InputPrice:  m.InputPrice,
 				OutputPrice: m.OutputPrice,
+
+				PremiumMultiplier: m.PremiumMultiplier,
 			})
 		}
 		return models
@@ -147,6 +149,8 @@ func modelInfosToCache(models []ModelInfo) []cache.CachedModel {
 			InputLimit:  m.InputLimit,
 			InputPrice:  m.InputPrice,
 			OutputPrice: m.OutputPrice,
+
+			PremiumMultiplier: m.PremiumMultiplier,
 		})
 	}
 	return cached
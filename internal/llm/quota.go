@@ -0,0 +1,265 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/credentials"
+)
+
+// copilotUserURL is the entitlement endpoint used by Copilot editor clients to
+// report plan and premium-request quota snapshots for the signed-in user.
+const copilotUserURL = "https://api.github.com/copilot_internal/user"
+
+// chatGPTUsageURL reports the ChatGPT plan's rolling Codex rate-limit windows.
+const chatGPTUsageURL = "https://chatgpt.com/backend-api/wham/usage"
+
+// ErrQuotaUnsupported is returned when a provider cannot report plan quota.
+var ErrQuotaUnsupported = errors.New("provider does not report quota")
+
+// QuotaReporter is implemented by subscription-backed providers that can
+// report the remaining plan quota for the authenticated account.
+type QuotaReporter interface {
+	GetUsage(ctx context.Context) (*QuotaReport, error)
+}
+
+// QuotaReport is a provider-neutral snapshot of plan quota.
+type QuotaReport struct {
+	Provider string       `json:"provider"`
+	Plan     string       `json:"plan,omitempty"`
+	ResetAt  time.Time    `json:"resetAt,omitempty"`
+	Quotas   []QuotaEntry `json:"quotas"`
+}
+
+// QuotaEntry describes one metered feature. Entitlement, Used, and Remaining
+// are zero when the provider only reports percentages.
+type QuotaEntry struct {
+	Name             string    `json:"name"`
+	Entitlement      float64   `json:"entitlement,omitempty"`
+	Used             float64   `json:"used,omitempty"`
+	Remaining        float64   `json:"remaining,omitempty"`
+	PercentRemaining float64   `json:"percentRemaining"`
+	Unlimited        bool      `json:"unlimited,omitempty"`
+	ResetAt          time.Time `json:"resetAt,omitempty"`
+	// Premium marks the entry whose exhaustion blocks chat requests. Low-quota
+	// warnings only consider premium entries.
+	Premium bool `json:"premium,omitempty"`
+}
+
+// LowestPremium returns the metered premium entry with the least remaining
+// quota, or nil when every premium entry is unlimited.
+func (r *QuotaReport) LowestPremium() *QuotaEntry {
+	if r == nil {
+		return nil
+	}
+	var lowest *QuotaEntry
+	for i := range r.Quotas {
+		q := &r.Quotas[i]
+		if !q.Premium || q.Unlimited {
+			continue
+		}
+		if lowest == nil || q.PercentRemaining < lowest.PercentRemaining {
+			lowest = q
+		}
+	}
+	return lowest
+}
+
+// GetUsage forwards to the inner provider when it implements QuotaReporter.
+func (r *RetryProvider) GetUsage(ctx context.Context) (*QuotaReport, error) {
+	if reporter, ok := r.inner.(QuotaReporter); ok {
+		return reporter.GetUsage(ctx)
+	}
+	return nil, ErrQuotaUnsupported
+}
+
+// GetUsage reports the quota of the provider that answered last, which is
+// the one a quota warning applies to.
+func (f *FallbackProvider) GetUsage(ctx context.Context) (*QuotaReport, error) {
+	if reporter, ok := f.activeProvider().(QuotaReporter); ok {
+		return reporter.GetUsage(ctx)
+	}
+	return nil, ErrQuotaUnsupported
+}
+
+type copilotQuotaSnapshot struct {
+	Entitlement      float64 `json:"entitlement"`
+	Remaining        float64 `json:"remaining"`
+	PercentRemaining float64 `json:"percent_remaining"`
+	Unlimited        bool    `json:"unlimited"`
+}
+
+type copilotUserResponse struct {
+	CopilotPlan       string                          `json:"copilot_plan"`
+	QuotaResetDate    string                          `json:"quota_reset_date"`
+	QuotaResetDateUTC string                          `json:"quota_reset_date_utc"`
+	QuotaSnapshots    map[string]copilotQuotaSnapshot `json:"quota_snapshots"`
+}
+
+// GetUsage reports the Copilot plan and per-feature quota snapshots.
+func (p *CopilotProvider) GetUsage(ctx context.Context) (*QuotaReport, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", copilotUserURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	p.setGitHubAPIHeaders(httpReq)
+
+	resp, err := copilotHTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("Copilot usage request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newHTTPStatusError("Copilot", resp, body)
+	}
+
+	var userResp copilotUserResponse
+	if err := json.Unmarshal(body, &userResp); err != nil {
+		return nil, fmt.Errorf("failed to decode Copilot usage response: %w", err)
+	}
+	return userResp.report(), nil
+}
+
+func (r copilotUserResponse) report() *QuotaReport {
+	report := &QuotaReport{
+		Provider: "copilot",
+		Plan:     r.CopilotPlan,
+		ResetAt:  parseCopilotResetDate(r.QuotaResetDateUTC, r.QuotaResetDate),
+	}
+	names := make([]string, 0, len(r.QuotaSnapshots))
+	for name := range r.QuotaSnapshots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		snap := r.QuotaSnapshots[name]
+		entry := QuotaEntry{
+			Name:             name,
+			Entitlement:      snap.Entitlement,
+			Remaining:        snap.Remaining,
+			PercentRemaining: snap.PercentRemaining,
+			Unlimited:        snap.Unlimited,
+			ResetAt:          report.ResetAt,
+			Premium:          name == "premium_interactions",
+		}
+		if snap.Entitlement > 0 {
+			entry.Used = snap.Entitlement - snap.Remaining
+		}
+		report.Quotas = append(report.Quotas, entry)
+	}
+	return report
+}
+
+func parseCopilotResetDate(values ...string) time.Time {
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t
+		}
+		if t, err := time.Parse("2006-01-02", v); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+type chatGPTUsageWindow struct {
+	UsedPercent        float64 `json:"used_percent"`
+	LimitWindowSeconds int64   `json:"limit_window_seconds"`
+	ResetAfterSeconds  int64   `json:"reset_after_seconds"`
+	ResetAt            int64   `json:"reset_at"`
+}
+
+type chatGPTUsageResponse struct {
+	PlanType  string `json:"plan_type"`
+	RateLimit *struct {
+		PrimaryWindow   *chatGPTUsageWindow `json:"primary_window"`
+		SecondaryWindow *chatGPTUsageWindow `json:"secondary_window"`
+	} `json:"rate_limit"`
+}
+
+// GetUsage reports the ChatGPT plan and rolling rate-limit windows.
+func (p *ChatGPTProvider) GetUsage(ctx context.Context) (*QuotaReport, error) {
+	if p.creds.IsExpired() {
+		if err := credentials.RefreshChatGPTCredentials(p.creds); err != nil {
+			return nil, fmt.Errorf("token refresh failed: %w", err)
+		}
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", chatGPTUsageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+p.creds.AccessToken)
+	httpReq.Header.Set("ChatGPT-Account-ID", p.creds.AccountID)
+	httpReq.Header.Set("originator", chatGPTCodexOriginator)
+	httpReq.Header.Set("User-Agent", chatGPTCodexUserAgent)
+	httpReq.Header.Set("Accept", "application/json")
+
+	resp, err := chatGPTHTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("ChatGPT usage request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newHTTPStatusError("ChatGPT", resp, body)
+	}
+
+	var usageResp chatGPTUsageResponse
+	if err := json.Unmarshal(body, &usageResp); err != nil {
+		return nil, fmt.Errorf("failed to decode ChatGPT usage response: %w", err)
+	}
+	return usageResp.report(time.Now()), nil
+}
+
+func (r chatGPTUsageResponse) report(now time.Time) *QuotaReport {
+	report := &QuotaReport{Provider: "chatgpt", Plan: r.PlanType}
+	if r.RateLimit == nil {
+		return report
+	}
+	add := func(name string, w *chatGPTUsageWindow) {
+		if w == nil {
+			return
+		}
+		entry := QuotaEntry{
+			Name:             name,
+			PercentRemaining: 100 - w.UsedPercent,
+			Premium:          true,
+		}
+		if entry.PercentRemaining < 0 {
+			entry.PercentRemaining = 0
+		}
+		switch {
+		case w.ResetAt > 0:
+			entry.ResetAt = time.Unix(w.ResetAt, 0)
+		case w.ResetAfterSeconds > 0:
+			entry.ResetAt = now.Add(time.Duration(w.ResetAfterSeconds) * time.Second)
+		}
+		if report.ResetAt.IsZero() || (!entry.ResetAt.IsZero() && entry.ResetAt.After(report.ResetAt)) {
+			report.ResetAt = entry.ResetAt
+		}
+		report.Quotas = append(report.Quotas, entry)
+	}
+	add("primary_window", r.RateLimit.PrimaryWindow)
+	add("weekly_window", r.RateLimit.SecondaryWindow)
+	return report
+}
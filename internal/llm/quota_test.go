@@ -0,0 +1,129 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/credentials"
+)
+
+func TestCopilotGetUsageParsesQuotaSnapshots(t *testing.T) {
+	origClient := copilotHTTPClient
+	t.Cleanup(func() { copilotHTTPClient = origClient })
+	copilotHTTPClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.String() != copilotUserURL {
+			t.Fatalf("URL = %q, want %q", r.URL.String(), copilotUserURL)
+		}
+		if got := r.Header.Get("Authorization"); got != "token oauth-token" {
+			t.Fatalf("Authorization header = %q, want GitHub OAuth token", got)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body: io.NopCloser(strings.NewReader(`{
+				"copilot_plan": "individual_pro",
+				"quota_reset_date": "2026-11-01",
+				"quota_snapshots": {
+					"chat": {"entitlement": 0, "remaining": 0, "percent_remaining": 100, "unlimited": true},
+					"premium_interactions": {"entitlement": 300, "remaining": 24, "percent_remaining": 8, "unlimited": false}
+				}
+			}`)),
+		}, nil
+	})}
+
+	p := &CopilotProvider{creds: &credentials.CopilotCredentials{AccessToken: "oauth-token"}}
+	report, err := p.GetUsage(context.Background())
+	if err != nil {
+		t.Fatalf("GetUsage: %v", err)
+	}
+	if report.Plan != "individual_pro" {
+		t.Fatalf("Plan = %q", report.Plan)
+	}
+	if want := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC); !report.ResetAt.Equal(want) {
+		t.Fatalf("ResetAt = %v, want %v", report.ResetAt, want)
+	}
+	if len(report.Quotas) != 2 {
+		t.Fatalf("len(Quotas) = %d, want 2", len(report.Quotas))
+	}
+	lowest := report.LowestPremium()
+	if lowest == nil || lowest.Name != "premium_interactions" {
+		t.Fatalf("LowestPremium = %#v, want premium_interactions", lowest)
+	}
+	if lowest.Used != 276 || lowest.Remaining != 24 || lowest.PercentRemaining != 8 {
+		t.Fatalf("premium entry = %#v", lowest)
+	}
+}
+
+func TestChatGPTUsageReportFromRateLimitWindows(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	resp := chatGPTUsageResponse{PlanType: "plus"}
+	resp.RateLimit = &struct {
+		PrimaryWindow   *chatGPTUsageWindow `json:"primary_window"`
+		SecondaryWindow *chatGPTUsageWindow `json:"secondary_window"`
+	}{
+		PrimaryWindow:   &chatGPTUsageWindow{UsedPercent: 40, ResetAfterSeconds: 600},
+		SecondaryWindow: &chatGPTUsageWindow{UsedPercent: 95, ResetAt: now.Add(48 * time.Hour).Unix()},
+	}
+
+	report := resp.report(now)
+	if report.Plan != "plus" || len(report.Quotas) != 2 {
+		t.Fatalf("report = %#v", report)
+	}
+	if got := report.Quotas[0].ResetAt; !got.Equal(now.Add(10 * time.Minute)) {
+		t.Fatalf("primary ResetAt = %v", got)
+	}
+	lowest := report.LowestPremium()
+	if lowest == nil || lowest.Name != "weekly_window" || lowest.PercentRemaining != 5 {
+		t.Fatalf("LowestPremium = %#v, want weekly_window at 5%%", lowest)
+	}
+	if !report.ResetAt.Equal(now.Add(48 * time.Hour)) {
+		t.Fatalf("report ResetAt = %v", report.ResetAt)
+	}
+}
+
+func TestRetryProviderGetUsageUnsupported(t *testing.T) {
+	p := WrapWithRetry(NewMockProvider("mock"), DefaultRetryConfig())
+	reporter, ok := p.(QuotaReporter)
+	if !ok {
+		t.Fatal("RetryProvider should implement QuotaReporter")
+	}
+	if _, err := reporter.GetUsage(context.Background()); err != ErrQuotaUnsupported {
+		t.Fatalf("GetUsage error = %v, want ErrQuotaUnsupported", err)
+	}
+}
+
+// quotaMockProvider is a mock provider with a fixed quota report.
+type quotaMockProvider struct {
+	*MockProvider
+	report *QuotaReport
+}
+
+func (p *quotaMockProvider) GetUsage(context.Context) (*QuotaReport, error) {
+	return p.report, nil
+}
+
+func TestFallbackProviderGetUsageForwardsToActiveProvider(t *testing.T) {
+	primary := &quotaMockProvider{
+		MockProvider: NewMockProvider("primary").AddError(errors.New("429 quota exceeded")),
+		report:       &QuotaReport{Plan: "primary-plan"},
+	}
+	backup := NewMockProvider("backup").AddTextResponse("ok")
+	provider := NewFallbackProvider(WrapWithRetry(primary, RetryConfig{MaxAttempts: 1}), backup)
+
+	report, err := provider.GetUsage(context.Background())
+	if err != nil || report == nil || report.Plan != "primary-plan" {
+		t.Fatalf("GetUsage = %#v, %v; want the primary's report through the retry wrapper", report, err)
+	}
+
+	if _, _, err := runFallbackEngine(t, provider); err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	if _, err := provider.GetUsage(context.Background()); err != ErrQuotaUnsupported {
+		t.Fatalf("GetUsage after failover error = %v, want ErrQuotaUnsupported from the backup", err)
+	}
+}
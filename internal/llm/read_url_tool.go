@@ -278,30 +278,54 @@ func cloneReadURLTransport(rt http.RoundTripper) *http.Transport {
 	return transport.Clone()
 }
 
-func normalizeReadURLTarget(ctx context.Context, rawURL string) (readURLTarget, error) {
+// NormalizeFetchURL parses a URL given to a fetch tool, defaulting to https
+// when no scheme is given, and rejects non-http(s) schemes and host names
+// that always point at local or metadata services. Addresses are checked
+// separately, once the host is resolved or dialed, with IsBlockedFetchIP.
+func NormalizeFetchURL(rawURL string) (*url.URL, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+	_, parsedURL, err := normalizeFetchURL(rawURL)
+	return parsedURL, err
+}
+
+// normalizeFetchURL implements NormalizeFetchURL, also returning the URL
+// string with the default scheme applied.
+func normalizeFetchURL(rawURL string) (string, *url.URL, error) {
 	targetURL := rawURL
-	if !strings.HasPrefix(strings.ToLower(targetURL), "http://") && !strings.HasPrefix(strings.ToLower(targetURL), "https://") {
+	if !strings.Contains(targetURL, "://") {
 		targetURL = "https://" + targetURL
 	}
 
 	parsedURL, err := url.Parse(targetURL)
 	if err != nil {
-		return readURLTarget{}, fmt.Errorf("invalid url: %w", err)
+		return "", nil, fmt.Errorf("invalid url: %w", err)
 	}
 	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
-		return readURLTarget{}, fmt.Errorf("url scheme must be http or https")
+		return "", nil, fmt.Errorf("url scheme must be http or https")
 	}
 
 	host := strings.TrimSuffix(strings.ToLower(parsedURL.Hostname()), ".")
 	if host == "" {
-		return readURLTarget{}, fmt.Errorf("url host is required")
+		return "", nil, fmt.Errorf("url host is required")
 	}
-	if isBlockedReadURLHost(host) {
-		return readURLTarget{}, fmt.Errorf("url host is not allowed")
+	if IsBlockedFetchHost(host) {
+		return "", nil, fmt.Errorf("url host is not allowed")
+	}
+	return targetURL, parsedURL, nil
+}
+
+func normalizeReadURLTarget(ctx context.Context, rawURL string) (readURLTarget, error) {
+	targetURL, parsedURL, err := normalizeFetchURL(rawURL)
+	if err != nil {
+		return readURLTarget{}, err
 	}
+	host := strings.TrimSuffix(strings.ToLower(parsedURL.Hostname()), ".")
 
 	if ip := net.ParseIP(host); ip != nil {
-		if isBlockedReadURLIP(ip) {
+		if IsBlockedFetchIP(ip) {
 			return readURLTarget{}, fmt.Errorf("url host is not allowed")
 		}
 		return readURLTarget{url: targetURL, ips: []net.IP{ip}}, nil
@@ -315,7 +339,7 @@ func normalizeReadURLTarget(ctx context.Context, rawURL string) (readURLTarget,
 		return readURLTarget{}, fmt.Errorf("resolve url host: no IP addresses found")
 	}
 	for _, ip := range ips {
-		if isBlockedReadURLIP(ip) {
+		if IsBlockedFetchIP(ip) {
 			return readURLTarget{}, fmt.Errorf("url host is not allowed")
 		}
 	}
@@ -323,7 +347,9 @@ func normalizeReadURLTarget(ctx context.Context, rawURL string) (readURLTarget,
 	return readURLTarget{url: targetURL, ips: ips}, nil
 }
 
-func isBlockedReadURLHost(host string) bool {
+// IsBlockedFetchHost reports whether host names a local or cloud metadata
+// service that fetch tools never contact.
+func IsBlockedFetchHost(host string) bool {
 	switch host {
 	case "localhost", "metadata.google.internal", "metadata.goog":
 		return true
@@ -332,8 +358,17 @@ func isBlockedReadURLHost(host string) bool {
 	return strings.HasSuffix(host, ".localhost")
 }
 
-func isBlockedReadURLIP(ip net.IP) bool {
+// sharedAddressSpace is 100.64.0.0/10 (RFC 6598), used for carrier-grade NAT
+// and by Tailscale and similar overlays for internal services.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0).To4(), Mask: net.CIDRMask(10, 32)}
+
+// IsBlockedFetchIP reports whether fetch tools refuse to connect to ip:
+// loopback, private, shared (CGNAT), link-local and other non-public
+// addresses, so a prompt injection cannot reach local services or cloud
+// metadata endpoints.
+func IsBlockedFetchIP(ip net.IP) bool {
 	return ip.IsLoopback() ||
+		sharedAddressSpace.Contains(ip) ||
 		ip.IsPrivate() ||
 		ip.IsLinkLocalUnicast() ||
 		ip.IsLinkLocalMulticast() ||
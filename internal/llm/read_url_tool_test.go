@@ -394,3 +394,24 @@ func TestResolveReadURLTargetPinsValidatedIPsDuringRedirectCheck(t *testing.T) {
 }
 
 var _ io.ReadCloser = (*failAfterNReadCloser)(nil)
+
+func TestFetchURLBlocklists(t *testing.T) {
+	for _, ip := range []string{"127.0.0.1", "10.1.2.3", "100.64.0.1", "100.127.255.254", "169.254.169.254", "::1", "fd00::1"} {
+		if !IsBlockedFetchIP(net.ParseIP(ip)) {
+			t.Errorf("IsBlockedFetchIP(%s) = false, want blocked", ip)
+		}
+	}
+	for _, ip := range []string{"100.128.0.1", "8.8.8.8", "2606:4700::1111"} {
+		if IsBlockedFetchIP(net.ParseIP(ip)) {
+			t.Errorf("IsBlockedFetchIP(%s) = true, want allowed", ip)
+		}
+	}
+	for _, raw := range []string{"ftp://example.com/file", "http://localhost:8080/", "https://metadata.google.internal/", ""} {
+		if _, err := NormalizeFetchURL(raw); err == nil {
+			t.Errorf("NormalizeFetchURL(%q) succeeded, want an error", raw)
+		}
+	}
+	if u, err := NormalizeFetchURL("example.com/docs"); err != nil || u.String() != "https://example.com/docs" {
+		t.Errorf("NormalizeFetchURL(example.com/docs) = %v, %v", u, err)
+	}
+}
@@ -71,6 +71,12 @@ type ResponsesClient struct {
 	responseStateMu         sync.Mutex
 	responseStateGeneration uint64
 	responseStateSessionID  string
+
+	// reasoningStripped remembers, per session and model, that the backend
+	// rejected resent reasoning items, so later requests leave them out
+	// instead of failing once per turn first.
+	reasoningStripMu  sync.Mutex
+	reasoningStripped map[string]bool
 }
 
 // ResponsesRequest follows the Open Responses spec
@@ -95,10 +101,12 @@ type ResponsesRequest struct {
 	Generate                        *bool                        `json:"generate,omitempty"` // WebSocket warmup support; omitted for normal HTTP/WS requests
 	Stream                          bool                         `json:"stream"`
 	StreamOptions                   *ResponsesStreamOptions      `json:"stream_options,omitempty"`
+	Text                            *ResponsesText               `json:"text,omitempty"`
 	PreviousResponseID              string                       `json:"previous_response_id,omitempty"`
 	ServiceTier                     string                       `json:"service_tier,omitempty"`
 	SessionID                       string                       `json:"-"`
 	ForceHTTP                       bool                         `json:"-"` // Bypass WebSocket for request features that require HTTP/SSE.
+	Ephemeral                       bool                         `json:"-"` // One-off side request: neither chains on nor updates the conversation's response state.
 	ExtraHeaders                    map[string]string            `json:"-"` // Request-scoped headers (combined with client headers).
 	FileUploadPolicy                *FileUploadPolicy            `json:"-"`
 }
@@ -227,6 +235,7 @@ type responsesOutputItem struct {
 	// For image_generation_call
 	Result        string `json:"result,omitempty"`         // base64-encoded image payload
 	RevisedPrompt string `json:"revised_prompt,omitempty"` // model's revised prompt
+	OutputFormat  string `json:"output_format,omitempty"`  // "png", "jpeg" or "webp"
 }
 
 type responsesReasoningSummaryPart struct {
@@ -237,9 +246,10 @@ type responsesReasoningSummaryPart struct {
 type responsesReasoningSummary []responsesReasoningSummaryPart
 
 type responsesOutputContent struct {
-	Type    string `json:"type"` // "output_text" or "refusal"
-	Text    string `json:"text,omitempty"`
-	Refusal string `json:"refusal,omitempty"`
+	Type     string `json:"type"` // "output_text", "refusal" or "output_image"
+	Text     string `json:"text,omitempty"`
+	Refusal  string `json:"refusal,omitempty"`
+	ImageURL string `json:"image_url,omitempty"` // for output_image: data: or https URL
 }
 
 type responsesUsage struct {
@@ -832,6 +842,12 @@ func (c *ResponsesClient) Stream(ctx context.Context, req ResponsesRequest, debu
 	if lastResponseID != "" && responseStateSessionID != req.SessionID {
 		lastResponseID = ""
 	}
+	if req.Ephemeral {
+		// Side requests such as title generation share the client with the
+		// conversation; keep them off its response chain and WebSocket.
+		lastResponseID = ""
+		req.ForceHTTP = true
+	}
 
 	wsReq := req
 	httpPayload := req
@@ -929,7 +945,99 @@ func (c *ResponsesClient) Stream(ctx context.Context, req ResponsesRequest, debu
 	return c.streamHTTPPrepared(ctx, httpPayload, buildFullInput, responseStateGeneration, debugRaw)
 }
 
+// newHTTPStreamRequest builds the SSE POST for payload, whose marshalled form
+// is body.
+func (c *ResponsesClient) newHTTPStreamRequest(ctx context.Context, body []byte, payload ResponsesRequest) (*http.Request, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.BaseURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "text/event-stream")
+	if c.GetAuthHeader != nil {
+		httpReq.Header.Set("Authorization", c.GetAuthHeader())
+	}
+	if payload.SessionID != "" {
+		httpReq.Header.Set("session_id", payload.SessionID)
+	}
+	applyResponsesHeaders(httpReq.Header, c.ExtraHeaders, payload.ExtraHeaders)
+	return httpReq, nil
+}
+
+// isResponsesReasoningItemError reports whether the API rejected the request
+// because of a resent reasoning item it no longer recognizes, such as
+// "Item with id 'rs_…' not found" or encrypted content it cannot decrypt.
+func isResponsesReasoningItemError(statusCode int, body []byte) bool {
+	if statusCode != http.StatusBadRequest && statusCode != http.StatusNotFound {
+		return false
+	}
+	text := strings.ToLower(string(body))
+	if !strings.Contains(text, "reasoning") && !strings.Contains(text, "'rs_") && !strings.Contains(text, "\"rs_") && !strings.Contains(text, "encrypted") {
+		return false
+	}
+	for _, marker := range []string{"not found", "could not be found", "invalid_encrypted_content", "could not be decrypted", "could not be verified"} {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// isResponsesReasoningItem reports whether item is a reasoning item, including
+// provider output replayed verbatim.
+func isResponsesReasoningItem(item ResponsesInputItem) bool {
+	if len(item.Raw) == 0 {
+		return item.Type == "reasoning"
+	}
+	var raw struct {
+		Type string `json:"type"`
+	}
+	return json.Unmarshal(item.Raw, &raw) == nil && raw.Type == "reasoning"
+}
+
+func responsesInputHasReasoning(items []ResponsesInputItem) bool {
+	for _, item := range items {
+		if isResponsesReasoningItem(item) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripResponsesReasoningItems returns items without reasoning items.
+func stripResponsesReasoningItems(items []ResponsesInputItem) []ResponsesInputItem {
+	out := make([]ResponsesInputItem, 0, len(items))
+	for _, item := range items {
+		if !isResponsesReasoningItem(item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func reasoningStripKey(payload ResponsesRequest) string {
+	return payload.SessionID + "\x00" + payload.Model
+}
+
+func (c *ResponsesClient) stripsReasoning(payload ResponsesRequest) bool {
+	c.reasoningStripMu.Lock()
+	defer c.reasoningStripMu.Unlock()
+	return c.reasoningStripped[reasoningStripKey(payload)]
+}
+
+func (c *ResponsesClient) rememberReasoningStrip(payload ResponsesRequest) {
+	c.reasoningStripMu.Lock()
+	defer c.reasoningStripMu.Unlock()
+	if c.reasoningStripped == nil {
+		c.reasoningStripped = make(map[string]bool)
+	}
+	c.reasoningStripped[reasoningStripKey(payload)] = true
+}
+
 func (c *ResponsesClient) streamHTTPPrepared(ctx context.Context, httpPayload ResponsesRequest, buildFullInput func() []ResponsesInputItem, responseStateGeneration uint64, debugRaw bool) (Stream, error) {
+	if httpPayload.PreviousResponseID == "" && c.stripsReasoning(httpPayload) {
+		httpPayload.Input = stripResponsesReasoningItems(httpPayload.Input)
+	}
 	body, err := json.Marshal(httpPayload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
@@ -941,22 +1049,11 @@ func (c *ResponsesClient) streamHTTPPrepared(ctx context.Context, httpPayload Re
 		DebugRawSection(debugRaw, "Responses API Request", prettyBody.String())
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.BaseURL, bytes.NewReader(body))
+	httpReq, err := c.newHTTPStreamRequest(ctx, body, httpPayload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// Set headers
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Accept", "text/event-stream")
-	if c.GetAuthHeader != nil {
-		httpReq.Header.Set("Authorization", c.GetAuthHeader())
-	}
-	if httpPayload.SessionID != "" {
-		httpReq.Header.Set("session_id", httpPayload.SessionID)
-	}
-	applyResponsesHeaders(httpReq.Header, c.ExtraHeaders, httpPayload.ExtraHeaders)
-
 	httpClient := c.HTTPClient
 	if httpClient == nil {
 		httpClient = defaultHTTPClient
@@ -1009,19 +1106,37 @@ func (c *ResponsesClient) streamHTTPPrepared(ctx context.Context, httpPayload Re
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal retry request: %w", err)
 			}
-			httpReq, err = http.NewRequestWithContext(ctx, "POST", c.BaseURL, bytes.NewReader(body))
+			httpReq, err = c.newHTTPStreamRequest(ctx, body, retryPayload)
 			if err != nil {
 				return nil, fmt.Errorf("failed to create retry request: %w", err)
 			}
-			httpReq.Header.Set("Content-Type", "application/json")
-			httpReq.Header.Set("Accept", "text/event-stream")
-			if c.GetAuthHeader != nil {
-				httpReq.Header.Set("Authorization", c.GetAuthHeader())
+			resp, err = httpClient.Do(httpReq)
+			if err != nil {
+				return nil, fmt.Errorf("Responses API retry request failed: %w", err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				retryBody := readResponsesAPIErrorBody(resp)
+				return nil, newHTTPStatusErrorMessagef(resp, retryBody, "Responses API error (status %d): %s", resp.StatusCode, string(retryBody))
+			}
+		} else if httpPayload.PreviousResponseID == "" && isResponsesReasoningItemError(resp.StatusCode, respBody) && responsesInputHasReasoning(httpPayload.Input) {
+			// Without server state every request resends the reasoning items
+			// from earlier turns, and the backend sometimes no longer knows
+			// them. Drop them and try once more rather than failing the turn,
+			// and keep dropping them for this session and model.
+			c.rememberReasoningStrip(httpPayload)
+			retryPayload := httpPayload
+			retryPayload.Input = stripResponsesReasoningItems(httpPayload.Input)
+			if debugRaw {
+				DebugRawSection(debugRaw, "Responses API Reasoning Retry", fmt.Sprintf("status %d rejected reasoning items; retrying once without them", resp.StatusCode))
 			}
-			if retryPayload.SessionID != "" {
-				httpReq.Header.Set("session_id", retryPayload.SessionID)
+			body, err = json.Marshal(retryPayload)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal retry request: %w", err)
+			}
+			httpReq, err = c.newHTTPStreamRequest(ctx, body, retryPayload)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create retry request: %w", err)
 			}
-			applyResponsesHeaders(httpReq.Header, c.ExtraHeaders, retryPayload.ExtraHeaders)
 			resp, err = httpClient.Do(httpReq)
 			if err != nil {
 				return nil, fmt.Errorf("Responses API retry request failed: %w", err)
@@ -1036,19 +1151,10 @@ func (c *ResponsesClient) streamHTTPPrepared(ctx context.Context, httpPayload Re
 					return nil, err
 				}
 				// Retry with refreshed credentials
-				httpReq, err = http.NewRequestWithContext(ctx, "POST", c.BaseURL, bytes.NewReader(body))
+				httpReq, err = c.newHTTPStreamRequest(ctx, body, httpPayload)
 				if err != nil {
 					return nil, fmt.Errorf("failed to create auth retry request: %w", err)
 				}
-				httpReq.Header.Set("Content-Type", "application/json")
-				httpReq.Header.Set("Accept", "text/event-stream")
-				if c.GetAuthHeader != nil {
-					httpReq.Header.Set("Authorization", c.GetAuthHeader())
-				}
-				if httpPayload.SessionID != "" {
-					httpReq.Header.Set("session_id", httpPayload.SessionID)
-				}
-				applyResponsesHeaders(httpReq.Header, c.ExtraHeaders, httpPayload.ExtraHeaders)
 				resp, err = httpClient.Do(httpReq)
 				if err != nil {
 					return nil, fmt.Errorf("Responses API auth retry request failed: %w", err)
@@ -1076,7 +1182,7 @@ func (c *ResponsesClient) streamHTTPPrepared(ctx context.Context, httpPayload Re
 
 		var lastEventType string
 		var eventData []byte
-		handler := newResponsesStreamEventHandler(client, responseStateGeneration, debugRaw, "Responses API SSE", !client.DisableServerState, httpPayload.SessionID, httpPayload.suppressReasoningSummaryDeltas())
+		handler := newResponsesStreamEventHandler(client, responseStateGeneration, debugRaw, "Responses API SSE", !client.DisableServerState && !httpPayload.Ephemeral, httpPayload.SessionID, httpPayload.suppressReasoningSummaryDeltas())
 		sawTerminal := false
 
 		flushEvent := func() (bool, error) {
@@ -1162,6 +1162,58 @@ func TestResponsesClientStreamClearsPreviousResponseIDForDifferentSession(t *tes
 	}
 }
 
+func TestResponsesClientStreamEphemeralLeavesResponseStateAlone(t *testing.T) {
+	captured := make(chan map[string]any, 1)
+	httpClient := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			defer r.Body.Close()
+			var payload map[string]any
+			body, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &payload)
+			captured <- payload
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Status:     "200 OK",
+				Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+				Body: io.NopCloser(strings.NewReader(
+					"data: {\"type\":\"response.completed\",\"response\":{\"id\":\"resp_title\"}}\n\n",
+				)),
+			}, nil
+		}),
+	}
+	client := &ResponsesClient{
+		BaseURL:                "https://example.test/v1/responses",
+		HTTPClient:             httpClient,
+		LastResponseID:         "resp_chat",
+		responseStateSessionID: "session-1",
+	}
+
+	stream, err := client.Stream(context.Background(), ResponsesRequest{
+		Model:     "gpt-test",
+		SessionID: "session-1",
+		Ephemeral: true,
+		Input:     []ResponsesInputItem{{Type: "message", Role: "user", Content: "title this"}},
+		Stream:    true,
+	}, false)
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	defer stream.Close()
+	drainStreamToDone(t, stream)
+
+	select {
+	case payload := <-captured:
+		if _, ok := payload["previous_response_id"]; ok {
+			t.Fatalf("ephemeral request chained on the conversation: %#v", payload)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for request capture")
+	}
+	if got, _, _ := client.responseState(); got != "resp_chat" {
+		t.Fatalf("LastResponseID = %q, want the conversation's resp_chat kept", got)
+	}
+}
+
 func TestResponsesClientStream_CloseReturnsPromptlyWhenConsumerStopsDraining(t *testing.T) {
 	var sse strings.Builder
 	for i := 0; i < 32; i++ {
@@ -2057,6 +2109,143 @@ func TestResponsesClientStream_DoesNotRetry404WithoutPreviousResponseID(t *testi
 	}
 }
 
+func TestResponsesClientStream_RetriesWithoutUnknownReasoningItems(t *testing.T) {
+	var requests [][]map[string]any
+	httpClient := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			defer r.Body.Close()
+			var payload struct {
+				Input []map[string]any `json:"input"`
+			}
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read request body: %w", err)
+			}
+			if err := json.Unmarshal(body, &payload); err != nil {
+				return nil, fmt.Errorf("failed to decode request body: %w", err)
+			}
+			requests = append(requests, payload.Input)
+			for _, item := range payload.Input {
+				if item["type"] == "reasoning" {
+					return &http.Response{
+						StatusCode: http.StatusBadRequest,
+						Status:     "400 Bad Request",
+						Header:     http.Header{"Content-Type": []string{"application/json"}},
+						Body:       io.NopCloser(strings.NewReader(`{"error":{"message":"Item with id 'rs_old' not found. Items are not persisted when store is false.","type":"invalid_request_error"}}`)),
+					}, nil
+				}
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Status:     "200 OK",
+				Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+				Body:       io.NopCloser(strings.NewReader("data: [DONE]\n\n")),
+			}, nil
+		}),
+	}
+
+	client := &ResponsesClient{
+		BaseURL:            "https://example.test/v1/responses",
+		GetAuthHeader:      func() string { return "Bearer test-token" },
+		HTTPClient:         httpClient,
+		DisableServerState: true,
+	}
+
+	stream, err := client.Stream(context.Background(), ResponsesRequest{
+		Model: "gpt-5.2",
+		Input: []ResponsesInputItem{
+			{Type: "message", Role: "user", Content: "list the files"},
+			{Type: "reasoning", ID: "rs_old", EncryptedContent: "gAAAA-stale"},
+			{Raw: json.RawMessage(`{"type":"reasoning","id":"rs_raw","encrypted_content":"gAAAA-raw","summary":[]}`)},
+			{Type: "function_call", CallID: "call_1", Name: "glob", Arguments: `{"pattern":"*"}`},
+			{Type: "function_call_output", CallID: "call_1", Output: "main.go"},
+		},
+		Include: []string{"reasoning.encrypted_content"},
+		Stream:  true,
+	}, false)
+	if err != nil {
+		t.Fatalf("expected stream to succeed after dropping reasoning items, got error: %v", err)
+	}
+	defer stream.Close()
+	drainStreamToDone(t, stream)
+
+	if len(requests) != 2 {
+		t.Fatalf("expected initial request and one retry, got %d requests", len(requests))
+	}
+	if len(requests[0]) != 5 {
+		t.Fatalf("expected initial request to carry all 5 items, got %d", len(requests[0]))
+	}
+	var types []string
+	for _, item := range requests[1] {
+		types = append(types, fmt.Sprint(item["type"]))
+	}
+	if got := strings.Join(types, ","); got != "message,function_call,function_call_output" {
+		t.Fatalf("retry input types = %s", got)
+	}
+
+	// The next request for the same session and model leaves reasoning items
+	// out up front instead of failing once first.
+	stream, err = client.Stream(context.Background(), ResponsesRequest{
+		Model: "gpt-5.2",
+		Input: []ResponsesInputItem{
+			{Type: "message", Role: "user", Content: "list the files"},
+			{Type: "reasoning", ID: "rs_new", EncryptedContent: "gAAAA-new"},
+			{Type: "message", Role: "user", Content: "and again"},
+		},
+		Include: []string{"reasoning.encrypted_content"},
+		Stream:  true,
+	}, false)
+	if err != nil {
+		t.Fatalf("second stream failed: %v", err)
+	}
+	defer stream.Close()
+	drainStreamToDone(t, stream)
+	if len(requests) != 3 {
+		t.Fatalf("expected the second turn to need no retry, got %d requests in total", len(requests))
+	}
+	if len(requests[2]) != 2 {
+		t.Fatalf("second turn input = %v, want reasoning items left out", requests[2])
+	}
+}
+
+func TestResponsesClientStream_ReasoningRetryHappensOnce(t *testing.T) {
+	callCount := 0
+	httpClient := &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			callCount++
+			_ = r.Body.Close()
+			return &http.Response{
+				StatusCode: http.StatusBadRequest,
+				Status:     "400 Bad Request",
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"error":{"message":"Item with id 'rs_old' not found."}}`)),
+			}, nil
+		}),
+	}
+
+	client := &ResponsesClient{
+		BaseURL:            "https://example.test/v1/responses",
+		GetAuthHeader:      func() string { return "Bearer test-token" },
+		HTTPClient:         httpClient,
+		DisableServerState: true,
+	}
+
+	_, err := client.Stream(context.Background(), ResponsesRequest{
+		Model: "gpt-5.2",
+		Input: []ResponsesInputItem{
+			{Type: "reasoning", ID: "rs_old", EncryptedContent: "gAAAA-stale"},
+			{Type: "message", Role: "user", Content: "hello"},
+		},
+		Stream: true,
+	}, false)
+	if err == nil {
+		t.Fatal("expected the error from the retry to be returned")
+	}
+	if callCount != 2 {
+		t.Fatalf("expected exactly one retry, got %d calls", callCount)
+	}
+}
+
 func TestResponsesClient_OnAuthRetry_RefreshesAndRetries(t *testing.T) {
 	callCount := 0
 	httpClient := &http.Client{
@@ -287,6 +287,11 @@ func (h *responsesStreamEventHandler) HandleJSONEvent(data []byte, eventType str
 					if err := sendEvent(Event{Type: EventTextDelta, Text: content.Refusal}); err != nil {
 						return false, err
 					}
+				} else if content.Type == "output_image" && content.ImageURL != "" {
+					// The engine decodes or downloads the URL when it saves the image.
+					if err := sendEvent(Event{Type: EventImageGenerated, ImageURL: content.ImageURL, ImageMimeType: dataURLMediaType(content.ImageURL)}); err != nil {
+						return false, err
+					}
 				}
 			}
 		} else if doneEvent.Item.Type == "image_generation_call" {
@@ -295,7 +300,8 @@ func (h *responsesStreamEventHandler) HandleJSONEvent(data []byte, eventType str
 				if err != nil {
 					return false, fmt.Errorf("decode image_generation_call result: %w", err)
 				}
-				if err := sendEvent(Event{Type: EventImageGenerated, ImageData: decoded, ImageMimeType: "image/png", RevisedPrompt: doneEvent.Item.RevisedPrompt}); err != nil {
+				mimeType := imageMediaTypeForFormat(doneEvent.Item.OutputFormat)
+				if err := sendEvent(Event{Type: EventImageGenerated, ImageData: decoded, ImageMimeType: mimeType, RevisedPrompt: doneEvent.Item.RevisedPrompt}); err != nil {
 					return false, err
 				}
 			}
@@ -517,3 +523,28 @@ func (h *responsesStreamEventHandler) Finish(send eventSender) error {
 	}
 	return send.Send(Event{Type: EventDone})
 }
+
+// imageMediaTypeForFormat maps an image_generation output_format to a media
+// type. The tool defaults to PNG.
+func imageMediaTypeForFormat(format string) string {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "jpeg", "jpg":
+		return "image/jpeg"
+	case "webp":
+		return "image/webp"
+	default:
+		return "image/png"
+	}
+}
+
+// dataURLMediaType returns the media type of a data: URL, or "" for other
+// URLs so the engine sniffs it from the downloaded bytes.
+func dataURLMediaType(url string) string {
+	rest, ok := strings.CutPrefix(url, "data:")
+	if !ok {
+		return ""
+	}
+	mediaType, _, _ := strings.Cut(rest, ";")
+	mediaType, _, _ = strings.Cut(mediaType, ",")
+	return mediaType
+}
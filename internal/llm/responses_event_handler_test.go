@@ -82,3 +82,29 @@ func TestResponsesUsageClampsInconsistentUncachedInput(t *testing.T) {
 		t.Fatalf("lastUsage = %+v, want clamped uncached input", handler.lastUsage)
 	}
 }
+
+func TestResponsesOutputImagesEmitImageGenerated(t *testing.T) {
+	handler := newResponsesStreamEventHandler(&ResponsesClient{}, 0, false, "test", false, "", false)
+	events := make(chan Event, 4)
+	send := eventSender{ctx: context.Background(), ch: events}
+
+	if _, err := handler.HandleJSONEvent([]byte(`{
+		"type":"response.output_item.done",
+		"item":{"type":"image_generation_call","result":"aW1n","output_format":"webp"}
+	}`), "response.output_item.done", send); err != nil {
+		t.Fatalf("image_generation_call: %v", err)
+	}
+	if event := <-events; event.Type != EventImageGenerated || event.ImageMimeType != "image/webp" || string(event.ImageData) != "img" {
+		t.Fatalf("image_generation_call event = %+v, want webp image", event)
+	}
+
+	if _, err := handler.HandleJSONEvent([]byte(`{
+		"type":"response.output_item.done",
+		"item":{"type":"message","content":[{"type":"output_image","image_url":"data:image/jpeg;base64,aW1n"}]}
+	}`), "response.output_item.done", send); err != nil {
+		t.Fatalf("output_image: %v", err)
+	}
+	if event := <-events; event.Type != EventImageGenerated || event.ImageMimeType != "image/jpeg" || event.ImageURL == "" {
+		t.Fatalf("output_image event = %+v, want jpeg image URL", event)
+	}
+}
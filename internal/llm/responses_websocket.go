@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/samsaffron/term-llm/internal/metrics"
 )
 
 const responsesWebSocketBetaHeader = "responses_websockets=2026-02-06"
@@ -57,6 +58,7 @@ type responsesWSRequest struct {
 	Store              *bool                        `json:"store,omitempty"`
 	Generate           *bool                        `json:"generate,omitempty"`
 	StreamOptions      *ResponsesStreamOptions      `json:"stream_options,omitempty"`
+	Text               *ResponsesText               `json:"text,omitempty"`
 	PreviousResponseID string                       `json:"previous_response_id,omitempty"`
 }
 
@@ -80,6 +82,7 @@ func newResponsesWSRequest(req ResponsesRequest) responsesWSRequest {
 		Store:              req.Store,
 		Generate:           req.Generate,
 		StreamOptions:      req.StreamOptions,
+		Text:               req.Text,
 		PreviousResponseID: req.PreviousResponseID,
 	}
 }
@@ -335,7 +338,8 @@ func responsesRequestNonInputEqual(prev, current ResponsesRequest) bool {
 		prev.PromptCacheKey == current.PromptCacheKey &&
 		reflect.DeepEqual(prev.Store, current.Store) &&
 		reflect.DeepEqual(prev.Generate, current.Generate) &&
-		reflect.DeepEqual(prev.StreamOptions, current.StreamOptions)
+		reflect.DeepEqual(prev.StreamOptions, current.StreamOptions) &&
+		reflect.DeepEqual(prev.Text, current.Text)
 }
 
 func jsonLikeEqualForCompare(a, b any) bool {
@@ -672,6 +676,7 @@ func (c *ResponsesClient) ensureWebSocket(ctx context.Context, req ResponsesRequ
 					conn, retryResp, retryErr := dialOnce(retryCtx, headerWithFreshAuth(header, c))
 					if retryErr == nil {
 						c.wsConn = conn
+						metrics.WebSocketConnections.Inc()
 						c.wsConnSessionID = req.SessionID
 						c.wsConnBetaHeader = betaHeader
 						return conn, false, nil
@@ -691,6 +696,7 @@ func (c *ResponsesClient) ensureWebSocket(ctx context.Context, req ResponsesRequ
 		return nil, false, fmt.Errorf("connect Responses WebSocket: %w", err)
 	}
 	c.wsConn = conn
+	metrics.WebSocketConnections.Inc()
 	c.wsConnSessionID = req.SessionID
 	c.wsConnBetaHeader = betaHeader
 	return conn, false, nil
@@ -726,6 +732,7 @@ func (c *ResponsesClient) discardWebSocketLocked() {
 	_ = c.wsConn.SetWriteDeadline(time.Now().Add(closeTimeout))
 	_ = c.wsConn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
 	_ = c.wsConn.Close()
+	metrics.WebSocketConnections.Dec()
 	c.wsConn = nil
 	c.wsConnSessionID = ""
 	c.wsConnBetaHeader = ""
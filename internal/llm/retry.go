@@ -193,7 +193,7 @@ func (r *RetryProvider) Stream(ctx context.Context, req Request) (Stream, error)
 			if err != nil {
 				return struct{}{}, err
 			}
-			return struct{}{}, r.forwardAttempt(ctx, stream, send)
+			return struct{}{}, forwardAttempt(ctx, stream, send)
 		}, func(info retryInfo) error {
 			// Emit retry event so UI can show progress. RetryMaxAttempts==0 means
 			// time-budgeted retry with no fixed attempt ceiling.
@@ -202,6 +202,7 @@ func (r *RetryProvider) Stream(ctx context.Context, req Request) (Stream, error)
 				RetryAttempt:     info.Attempt,
 				RetryMaxAttempts: info.MaxAttempts,
 				RetryWaitSecs:    info.Wait.Seconds(),
+				RetryReason:      info.Class,
 			})
 		})
 		return err
@@ -212,6 +213,7 @@ type retryInfo struct {
 	Attempt     int
 	MaxAttempts int
 	Wait        time.Duration
+	Class       ErrorClass
 }
 
 func retryCall[T any](ctx context.Context, config RetryConfig, run func() (T, error), onRetry func(retryInfo) error) (T, error) {
@@ -246,11 +248,11 @@ func retryCall[T any](ctx context.Context, config RetryConfig, run func() (T, er
 
 		wait := calculateRetryBackoff(config, attempt, lastErr)
 		if err := checkRetryBudget(started, config.MaxElapsedTime, wait, lastErr); err != nil {
-			return zero, err
+			return zero, &retriesExhaustedError{err}
 		}
 
 		if onRetry != nil {
-			if err := onRetry(retryInfo{Attempt: attempt, MaxAttempts: config.MaxAttempts, Wait: wait}); err != nil {
+			if err := onRetry(retryInfo{Attempt: attempt, MaxAttempts: config.MaxAttempts, Wait: wait, Class: ClassifyError(lastErr)}); err != nil {
 				return zero, err
 			}
 		}
@@ -261,9 +263,20 @@ func retryCall[T any](ctx context.Context, config RetryConfig, run func() (T, er
 		}
 	}
 
+	if config.MaxAttempts > 1 {
+		return zero, &retriesExhaustedError{lastErr}
+	}
 	return zero, lastErr
 }
 
+// retriesExhaustedError wraps a transient error that retryCall already
+// retried until its attempt or time budget ran out. ClassifyError treats it
+// as permanent so an outer retry layer does not start the budget over.
+type retriesExhaustedError struct{ err error }
+
+func (e *retriesExhaustedError) Error() string { return e.err.Error() }
+func (e *retriesExhaustedError) Unwrap() error { return e.err }
+
 func checkRetryBudget(started time.Time, maxElapsedTime time.Duration, wait time.Duration, lastErr error) error {
 	if maxElapsedTime <= 0 {
 		return nil
@@ -307,7 +320,7 @@ func (e *committedError) Unwrap() error { return e.err }
 // After that point the attempt has already escaped, so retrying would duplicate
 // visible output or side effects. Any subsequent error is wrapped in
 // committedError so the retry loop will not retry.
-func (r *RetryProvider) forwardAttempt(ctx context.Context, stream Stream, send eventSender) error {
+func forwardAttempt(ctx context.Context, stream Stream, send eventSender) error {
 	defer stream.Close()
 
 	var buffered []Event
@@ -386,75 +399,12 @@ func flushEvents(send eventSender, buffered []Event) error {
 
 // isRetryable returns true if the error is a transient error worth retrying.
 func isRetryable(err error) bool {
-	if err == nil {
-		return false
-	}
-
 	// Never retry if events were already committed to the outer stream.
 	var ce *committedError
 	if errors.As(err, &ce) {
 		return false
 	}
-
-	// Never retry if the context itself has been cancelled or deadline exceeded.
-	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
-		return false
-	}
-
-	// Stream framing / terminal marker failures are transient transport failures.
-	var incomplete *StreamIncompleteError
-	if errors.As(err, &incomplete) {
-		return true
-	}
-
-	// Structured HTTP status errors from providers.
-	var statusErr interface{ HTTPStatusCode() int }
-	if errors.As(err, &statusErr) {
-		return providerhttp.RetryableStatus(statusErr.HTTPStatusCode())
-	}
-
-	// Check for structured rate-limit errors. Long waits are handled by the
-	// elapsed-time budget in the retry loop rather than by retryability.
-	var rle *RateLimitError
-	if errors.As(err, &rle) {
-		return true
-	}
-
-	errStr := strings.ToLower(err.Error())
-
-	// HTTP status codes and rate limit messages
-	if strings.Contains(errStr, "429") ||
-		strings.Contains(errStr, "rate limit") ||
-		strings.Contains(errStr, "too many requests") ||
-		strings.Contains(errStr, "high concurrency") ||
-		containsHTTP5xxStatus(errStr) ||
-		strings.Contains(errStr, "internal server error") ||
-		strings.Contains(errStr, "bad gateway") ||
-		strings.Contains(errStr, "service unavailable") ||
-		strings.Contains(errStr, "overloaded") ||
-		strings.Contains(errStr, "api error: terminated") {
-		return true
-	}
-
-	// Connection errors
-	if strings.Contains(errStr, "connection refused") ||
-		strings.Contains(errStr, "connection reset") ||
-		strings.Contains(errStr, "timeout") ||
-		strings.Contains(errStr, "deadline exceeded") ||
-		strings.Contains(errStr, "temporary failure") ||
-		strings.Contains(errStr, "no such host") {
-		return true
-	}
-
-	return false
-}
-
-// http5xxStatusRegex matches standalone HTTP 5xx status codes in legacy string
-// errors from providers that have not attached structured status metadata.
-var http5xxStatusRegex = regexp.MustCompile(`\b5\d\d\b`)
-
-func containsHTTP5xxStatus(message string) bool {
-	return http5xxStatusRegex.MatchString(message)
+	return ClassifyError(err).Transient()
 }
 
 // retryAfterHeaderRegex matches Retry-After header-like values in error messages.
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"strings"
@@ -197,6 +198,22 @@ func TestIsRetryable_APIErrorTerminated(t *testing.T) {
 	}
 }
 
+func TestIsRetryable_PermanentCLIFailures(t *testing.T) {
+	for _, kind := range []error{ErrCLINotInstalled, ErrCLINotAuthenticated} {
+		err := &UserFacingProviderError{
+			Summary: "CLI failed",
+			Detail:  "connection timeout while checking credentials",
+			Kind:    kind,
+		}
+		if isRetryable(err) {
+			t.Errorf("isRetryable(%v) = true, want false", kind)
+		}
+		if !errors.Is(fmt.Errorf("wrapped: %w", err), kind) {
+			t.Errorf("expected wrapped error to match %v", kind)
+		}
+	}
+}
+
 // toolThenErrorProvider emits a synchronous tool call then a retryable error.
 // The retry loop must NOT retry after the tool call has been committed.
 type toolThenErrorProvider struct {
@@ -317,6 +334,10 @@ func TestRetryProvider_StopsWhenRetryAfterExceedsElapsedBudget(t *testing.T) {
 	if !strings.Contains(event.Err.Error(), "exceeds remaining retry window") {
 		t.Fatalf("error = %v, want retry window context", event.Err)
 	}
+	// The engine must not start another round of retries on top.
+	if class := ClassifyError(event.Err); class.Transient() {
+		t.Fatalf("exhausted retry error classified %q, want non-transient", class)
+	}
 	if inner.attempts != 1 {
 		t.Fatalf("attempts = %d, want 1", inner.attempts)
 	}
@@ -0,0 +1,200 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// ResponseFormatType selects how a reply must be formatted.
+type ResponseFormatType string
+
+const (
+	ResponseFormatText       ResponseFormatType = ""            // Free text (no constraint)
+	ResponseFormatJSONObject ResponseFormatType = "json_object" // Any JSON object
+	ResponseFormatJSONSchema ResponseFormatType = "json_schema" // JSON matching Schema
+)
+
+// defaultResponseFormatName is the schema name sent when ResponseFormat.Name
+// is empty; OpenAI requires one.
+const defaultResponseFormatName = "response"
+
+// ResponseFormat asks the model for a machine-readable reply.
+type ResponseFormat struct {
+	Type   ResponseFormatType
+	Name   string                 // Schema name for json_schema; defaults to "response"
+	Schema map[string]interface{} // JSON Schema for json_schema
+	// Strict asks providers that support it to enforce the schema exactly.
+	// OpenAI then requires every property to be listed in required and
+	// additionalProperties to be false.
+	Strict bool
+}
+
+// structured reports whether the format constrains the reply at all.
+func (f *ResponseFormat) structured() bool {
+	return f != nil && (f.Type == ResponseFormatJSONObject || f.Type == ResponseFormatJSONSchema)
+}
+
+func (f *ResponseFormat) name() string {
+	if name := strings.TrimSpace(f.Name); name != "" {
+		return name
+	}
+	return defaultResponseFormatName
+}
+
+// oaiResponseFormat is the Chat Completions response_format value.
+type oaiResponseFormat struct {
+	Type       string               `json:"type"`
+	JSONSchema *oaiJSONSchemaFormat `json:"json_schema,omitempty"`
+}
+
+type oaiJSONSchemaFormat struct {
+	Name   string                 `json:"name"`
+	Schema map[string]interface{} `json:"schema"`
+	Strict bool                   `json:"strict,omitempty"`
+}
+
+// buildCompatResponseFormat converts a ResponseFormat for Chat Completions.
+func buildCompatResponseFormat(f *ResponseFormat) *oaiResponseFormat {
+	if !f.structured() {
+		return nil
+	}
+	if f.Type == ResponseFormatJSONObject || len(f.Schema) == 0 {
+		return &oaiResponseFormat{Type: string(ResponseFormatJSONObject)}
+	}
+	return &oaiResponseFormat{
+		Type:       string(ResponseFormatJSONSchema),
+		JSONSchema: &oaiJSONSchemaFormat{Name: f.name(), Schema: f.Schema, Strict: f.Strict},
+	}
+}
+
+// ResponsesText is the Responses API text configuration.
+type ResponsesText struct {
+	Format *ResponsesTextFormat `json:"format,omitempty"`
+}
+
+// ResponsesTextFormat is the Responses API text.format value. Unlike Chat
+// Completions, the schema fields sit directly on the format object.
+type ResponsesTextFormat struct {
+	Type   string                 `json:"type"`
+	Name   string                 `json:"name,omitempty"`
+	Schema map[string]interface{} `json:"schema,omitempty"`
+	Strict bool                   `json:"strict,omitempty"`
+}
+
+// buildResponsesText converts a ResponseFormat for the Responses API.
+func buildResponsesText(f *ResponseFormat) *ResponsesText {
+	if !f.structured() {
+		return nil
+	}
+	if f.Type == ResponseFormatJSONObject || len(f.Schema) == 0 {
+		return &ResponsesText{Format: &ResponsesTextFormat{Type: string(ResponseFormatJSONObject)}}
+	}
+	return &ResponsesText{Format: &ResponsesTextFormat{
+		Type:   string(ResponseFormatJSONSchema),
+		Name:   f.name(),
+		Schema: f.Schema,
+		Strict: f.Strict,
+	}}
+}
+
+// structuredOutputInstruction is the system prompt suffix used for providers
+// that cannot enforce a ResponseFormat themselves.
+func structuredOutputInstruction(f *ResponseFormat) string {
+	if f.Type == ResponseFormatJSONSchema && len(f.Schema) > 0 {
+		schema, err := json.Marshal(f.Schema)
+		if err == nil {
+			return "Respond ONLY with a single JSON value matching this JSON Schema, with no prose and no code fences:\n" + string(schema)
+		}
+	}
+	return "Respond ONLY with a single JSON object, with no prose and no code fences."
+}
+
+// withStructuredOutputInstruction appends the JSON instruction to the first
+// system message, or adds a system message when there is none.
+func withStructuredOutputInstruction(messages []Message, f *ResponseFormat) []Message {
+	instruction := structuredOutputInstruction(f)
+	out := make([]Message, len(messages))
+	copy(out, messages)
+	for i, msg := range out {
+		if msg.Role != RoleSystem {
+			continue
+		}
+		parts := make([]Part, len(msg.Parts), len(msg.Parts)+1)
+		copy(parts, msg.Parts)
+		out[i].Parts = append(parts, Part{Type: PartText, Text: "\n\n" + instruction})
+		return out
+	}
+	return append([]Message{SystemText(instruction)}, out...)
+}
+
+// structuredOutputRepairMessages extends a conversation with the invalid
+// reply and a request to fix it.
+func structuredOutputRepairMessages(messages []Message, reply string, err error) []Message {
+	out := append([]Message(nil), messages...)
+	out = append(out,
+		Message{Role: RoleAssistant, Parts: []Part{{Type: PartText, Text: reply}}},
+		UserText(fmt.Sprintf("That reply was not valid: %v. Reply again with only the corrected JSON, no prose and no code fences.", err)),
+	)
+	return out
+}
+
+// StructuredOutputError reports a reply that still did not match the
+// requested ResponseFormat after the repair attempt.
+type StructuredOutputError struct {
+	Reply string
+	Err   error
+}
+
+func (e *StructuredOutputError) Error() string {
+	return fmt.Sprintf("model reply is not valid structured output: %v", e.Err)
+}
+
+func (e *StructuredOutputError) Unwrap() error { return e.Err }
+
+// ExtractJSONReply returns the JSON in a model reply, dropping surrounding
+// whitespace and a Markdown code fence if the model added one anyway.
+func ExtractJSONReply(reply string) string {
+	text := strings.TrimSpace(reply)
+	if strings.HasPrefix(text, "```") {
+		if nl := strings.IndexByte(text, '\n'); nl >= 0 {
+			text = text[nl+1:]
+		} else {
+			text = strings.TrimPrefix(text, "```")
+		}
+		text = strings.TrimSuffix(strings.TrimSpace(text), "```")
+	}
+	return strings.TrimSpace(text)
+}
+
+// ValidateStructuredReply checks a reply against a ResponseFormat.
+func ValidateStructuredReply(f *ResponseFormat, reply string) error {
+	if !f.structured() {
+		return nil
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(ExtractJSONReply(reply)), &value); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if f.Type == ResponseFormatJSONObject || len(f.Schema) == 0 {
+		if _, ok := value.(map[string]interface{}); !ok {
+			return fmt.Errorf("expected a JSON object")
+		}
+		return nil
+	}
+	raw, err := json.Marshal(f.Schema)
+	if err != nil {
+		return fmt.Errorf("encode schema: %w", err)
+	}
+	var schema jsonschema.Schema
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return fmt.Errorf("parse schema: %w", err)
+	}
+	resolved, err := schema.Resolve(nil)
+	if err != nil {
+		return fmt.Errorf("resolve schema: %w", err)
+	}
+	return resolved.Validate(value)
+}
@@ -0,0 +1,221 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+var titleSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"title":      map[string]interface{}{"type": "string"},
+		"confidence": map[string]interface{}{"type": "number"},
+	},
+	"required":             []interface{}{"title", "confidence"},
+	"additionalProperties": false,
+}
+
+func TestValidateStructuredReply(t *testing.T) {
+	schemaFormat := &ResponseFormat{Type: ResponseFormatJSONSchema, Schema: titleSchema}
+	tests := []struct {
+		name    string
+		format  *ResponseFormat
+		reply   string
+		wantErr string
+	}{
+		{"no format", nil, "plain prose", ""},
+		{"object", &ResponseFormat{Type: ResponseFormatJSONObject}, `{"a":1}`, ""},
+		{"object rejects array", &ResponseFormat{Type: ResponseFormatJSONObject}, `[1]`, "expected a JSON object"},
+		{"not json", &ResponseFormat{Type: ResponseFormatJSONObject}, `Sure! Here it is`, "invalid JSON"},
+		{"schema match", schemaFormat, `{"title":"Fix login","confidence":0.9}`, ""},
+		{"schema match in fence", schemaFormat, "```json\n{\"title\":\"Fix login\",\"confidence\":0.9}\n```", ""},
+		{"schema missing field", schemaFormat, `{"title":"Fix login"}`, "confidence"},
+		{"schema wrong type", schemaFormat, `{"title":3,"confidence":0.9}`, "title"},
+		{"schema extra field", schemaFormat, `{"title":"x","confidence":1,"extra":true}`, "extra"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateStructuredReply(tt.format, tt.reply)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidateStructuredReply() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ValidateStructuredReply() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func collectEngineText(t *testing.T, stream Stream) (string, []EventType, error) {
+	t.Helper()
+	defer stream.Close()
+	var text strings.Builder
+	var types []EventType
+	for {
+		ev, err := stream.Recv()
+		if err == io.EOF {
+			return text.String(), types, nil
+		}
+		if err != nil {
+			return text.String(), types, err
+		}
+		types = append(types, ev.Type)
+		switch ev.Type {
+		case EventTextDelta:
+			text.WriteString(ev.Text)
+		case EventAttemptDiscard:
+			text.Reset()
+		case EventError:
+			return text.String(), types, ev.Err
+		}
+	}
+}
+
+func systemPromptText(req Request) string {
+	for _, msg := range req.Messages {
+		if msg.Role == RoleSystem {
+			return collectTextParts(msg.Parts)
+		}
+	}
+	return ""
+}
+
+func TestEngineStructuredOutputFallbackRepairsInvalidReply(t *testing.T) {
+	provider := NewMockProvider("plain").
+		AddTextResponse("Here you go: title is Fix login").
+		AddTextResponse(`{"title":"Fix login","confidence":0.8}`)
+	engine := NewEngine(provider, nil)
+
+	stream, err := engine.Stream(context.Background(), Request{
+		Messages:       []Message{SystemText("You write titles."), UserText("title this")},
+		ResponseFormat: &ResponseFormat{Type: ResponseFormatJSONSchema, Schema: titleSchema},
+	})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	text, types, err := collectEngineText(t, stream)
+	if err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+	if text != `{"title":"Fix login","confidence":0.8}` {
+		t.Fatalf("final text = %q, want the repaired JSON", text)
+	}
+	discarded := false
+	for _, typ := range types {
+		if typ == EventAttemptDiscard {
+			discarded = true
+		}
+	}
+	if !discarded {
+		t.Fatalf("expected the invalid attempt to be discarded, events: %v", types)
+	}
+
+	requests := provider.RecordedRequests()
+	if len(requests) != 2 {
+		t.Fatalf("provider requests = %d, want 2", len(requests))
+	}
+	system := systemPromptText(requests[0])
+	if !strings.HasPrefix(system, "You write titles.") || !strings.Contains(system, "Respond ONLY with a single JSON value matching this JSON Schema") || !strings.Contains(system, `"confidence"`) {
+		t.Fatalf("system prompt missing schema instruction: %q", system)
+	}
+	repair := requests[1].Messages
+	if n := len(repair); n < 2 || repair[n-2].Role != RoleAssistant || repair[n-1].Role != RoleUser ||
+		!strings.Contains(collectTextParts(repair[n-1].Parts), "not valid") {
+		t.Fatalf("repair request should end with the bad reply and a correction request: %+v", repair)
+	}
+}
+
+func TestEngineStructuredOutputFallbackFailsAfterOneRepair(t *testing.T) {
+	provider := NewMockProvider("plain").
+		AddTextResponse("not json").
+		AddTextResponse("still not json").
+		AddTextResponse(`{"never":"reached"}`)
+	engine := NewEngine(provider, nil)
+
+	stream, err := engine.Stream(context.Background(), Request{
+		Messages:       []Message{UserText("json please")},
+		ResponseFormat: &ResponseFormat{Type: ResponseFormatJSONObject},
+	})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	_, _, err = collectEngineText(t, stream)
+	var structErr *StructuredOutputError
+	if !errors.As(err, &structErr) {
+		t.Fatalf("error = %v, want StructuredOutputError", err)
+	}
+	if structErr.Reply != "still not json" {
+		t.Fatalf("reported reply = %q", structErr.Reply)
+	}
+	if used := len(provider.RecordedRequests()); used != 2 {
+		t.Fatalf("provider requests = %d, want one attempt and one repair", used)
+	}
+	if system := systemPromptText(provider.RecordedRequests()[0]); !strings.Contains(system, "single JSON object") {
+		t.Fatalf("expected a system message with the JSON instruction, got %q", system)
+	}
+}
+
+func TestEngineStructuredOutputFallbackRepairsInvalidReplyInToolLoop(t *testing.T) {
+	provider := NewMockProvider("plain").
+		AddTextResponse("Sure: Fix login").
+		AddTextResponse(`{"title":"Fix login","confidence":0.8}`)
+	engine := NewEngine(provider, nil)
+
+	stream, err := engine.Stream(context.Background(), Request{
+		Messages:       []Message{UserText("title this")},
+		Tools:          []ToolSpec{{Name: "lookup", Schema: map[string]any{"type": "object"}}},
+		ResponseFormat: &ResponseFormat{Type: ResponseFormatJSONSchema, Schema: titleSchema},
+	})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	text, _, err := collectEngineText(t, stream)
+	if err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+	if text != `{"title":"Fix login","confidence":0.8}` {
+		t.Fatalf("final text = %q, want the repaired JSON", text)
+	}
+	requests := provider.RecordedRequests()
+	if len(requests) != 2 {
+		t.Fatalf("provider requests = %d, want one attempt and one repair", len(requests))
+	}
+	repair := requests[1].Messages
+	if n := len(repair); n < 2 || !strings.Contains(collectTextParts(repair[n-1].Parts), "not valid") {
+		t.Fatalf("repair request should end with a correction request: %+v", repair)
+	}
+}
+
+func TestEngineStructuredOutputNativeProviderSkipsFallback(t *testing.T) {
+	provider := NewMockProvider("native").
+		WithCapabilities(Capabilities{StructuredOutput: true}).
+		AddTextResponse("not json, but the provider is trusted")
+	engine := NewEngine(provider, nil)
+
+	stream, err := engine.Stream(context.Background(), Request{
+		Messages:       []Message{SystemText("sys"), UserText("json please")},
+		ResponseFormat: &ResponseFormat{Type: ResponseFormatJSONObject},
+	})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	if _, _, err := collectEngineText(t, stream); err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+	requests := provider.RecordedRequests()
+	if len(requests) != 1 {
+		t.Fatalf("provider requests = %d, want 1", len(requests))
+	}
+	if system := systemPromptText(requests[0]); system != "sys" {
+		t.Fatalf("system prompt = %q, want it untouched", system)
+	}
+	if requests[0].ResponseFormat == nil || requests[0].ResponseFormat.Type != ResponseFormatJSONObject {
+		t.Fatalf("response format not passed to provider: %+v", requests[0].ResponseFormat)
+	}
+}
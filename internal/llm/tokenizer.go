@@ -0,0 +1,571 @@
+package llm
+
+import (
+	"log/slog"
+	"math"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token encodings understood by CountTokens. Rank tables are supplied by a
+// backend registered at init (see tokenizer_tiktoken.go); builds with the
+// notiktoken tag have none and every count falls back to EstimateTokens.
+const (
+	EncodingCL100K = "cl100k_base"
+	EncodingO200K  = "o200k_base"
+)
+
+// maxBPEPieceBytes bounds the quadratic merge loop. Longer pre-tokenized
+// pieces (minified blobs, base64) are merged in chunks, which can differ from
+// the exact count by a token per chunk boundary.
+const maxBPEPieceBytes = 512
+
+// builtinTokenizerModels maps model-name prefixes to encodings, most specific
+// first. Names are matched after stripping any "vendor/" routing prefix.
+var builtinTokenizerModels = []struct {
+	prefix   string
+	encoding string
+}{
+	{"gpt-4o", EncodingO200K},
+	{"chatgpt-4o", EncodingO200K},
+	{"gpt-4.1", EncodingO200K},
+	{"gpt-4.5", EncodingO200K},
+	{"gpt-5", EncodingO200K},
+	{"gpt-oss", EncodingO200K},
+	{"codex-", EncodingO200K},
+	{"o1", EncodingO200K},
+	{"o3", EncodingO200K},
+	{"o4", EncodingO200K},
+	{"gpt-4", EncodingCL100K},
+	{"gpt-3.5", EncodingCL100K},
+	{"gpt-35", EncodingCL100K},
+	{"text-embedding-3", EncodingCL100K},
+	{"text-embedding-ada-002", EncodingCL100K},
+}
+
+var (
+	tokenizerMu sync.RWMutex
+	// tokenEncodingLoaders holds the rank-table loader for each encoding the
+	// build supports. Populated by init in the backend file.
+	tokenEncodingLoaders = map[string]func() (map[string]int, error){}
+	// tokenEncoders caches one lazily loaded encoder per encoding.
+	tokenEncoders = map[string]*tokenEncoder{}
+	// configTokenizerModels holds user-configured model patterns → encoding,
+	// checked before the built-in table. Populated by RegisterTokenizerModels.
+	configTokenizerModels []tokenizerModelPattern
+)
+
+type tokenizerModelPattern struct {
+	pattern  string // lower-cased; may end in "*" for a prefix match
+	encoding string
+}
+
+// registerTokenEncoding makes an encoding available to CountTokens. The loader
+// runs at most once, on first use.
+func registerTokenEncoding(name string, load func() (map[string]int, error)) {
+	tokenizerMu.Lock()
+	tokenEncodingLoaders[name] = load
+	tokenizerMu.Unlock()
+}
+
+// RegisterTokenizerModels sets user-configured model → encoding mappings, so
+// custom or self-hosted models served with a known vocabulary count tokens
+// exactly. Keys are model names, optionally ending in "*" to match a prefix;
+// an empty encoding or "heuristic" forces the byte heuristic for that model.
+// Passing nil clears previous registrations.
+func RegisterTokenizerModels(models map[string]string) {
+	patterns := make([]tokenizerModelPattern, 0, len(models))
+	for pattern, encoding := range models {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		patterns = append(patterns, tokenizerModelPattern{
+			pattern:  pattern,
+			encoding: strings.ToLower(strings.TrimSpace(encoding)),
+		})
+	}
+	// Longest pattern first so "gpt-4o-mini" beats "gpt-4*" regardless of
+	// map iteration order.
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i].pattern) != len(patterns[j].pattern) {
+			return len(patterns[i].pattern) > len(patterns[j].pattern)
+		}
+		return patterns[i].pattern < patterns[j].pattern
+	})
+
+	tokenizerMu.Lock()
+	configTokenizerModels = patterns
+	tokenizerMu.Unlock()
+}
+
+// TokenEncodingForModel returns the encoding CountTokens uses for model, or ""
+// when the model has no known tokenizer.
+func TokenEncodingForModel(model string) string {
+	model = strings.ToLower(strings.TrimSpace(model))
+	if model == "" {
+		return ""
+	}
+
+	tokenizerMu.RLock()
+	patterns := configTokenizerModels
+	tokenizerMu.RUnlock()
+	bare := path.Base(model)
+	for _, p := range patterns {
+		if matchTokenizerPattern(p.pattern, model) || matchTokenizerPattern(p.pattern, bare) {
+			if p.encoding == "heuristic" {
+				return ""
+			}
+			return p.encoding
+		}
+	}
+
+	for _, m := range builtinTokenizerModels {
+		if strings.HasPrefix(bare, m.prefix) {
+			return m.encoding
+		}
+	}
+	return ""
+}
+
+func matchTokenizerPattern(pattern, model string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(model, prefix)
+	}
+	return pattern == model
+}
+
+// HasTokenizer reports whether CountTokens counts model exactly rather than
+// falling back to the byte heuristic.
+func HasTokenizer(model string) bool {
+	return encoderForModel(model) != nil
+}
+
+// CountTokens returns the number of tokens text encodes to for model. Models
+// without a tokenizer (or whose encoding is unavailable in this build) use
+// the EstimateTokens heuristic. The encoder is loaded on first use and cached.
+func CountTokens(model, text string) int {
+	if text == "" {
+		return 0
+	}
+	return tokenCounterForModel(model)(text)
+}
+
+// tokenCounterForModel resolves model's encoder once for callers counting
+// many strings.
+func tokenCounterForModel(model string) func(string) int {
+	if enc := encoderForModel(model); enc != nil {
+		return enc.count
+	}
+	return EstimateTokens
+}
+
+func encoderForModel(model string) *tokenEncoder {
+	encoding := TokenEncodingForModel(model)
+	if encoding == "" {
+		return nil
+	}
+
+	tokenizerMu.RLock()
+	enc := tokenEncoders[encoding]
+	tokenizerMu.RUnlock()
+	if enc == nil {
+		tokenizerMu.Lock()
+		if enc = tokenEncoders[encoding]; enc == nil {
+			load, ok := tokenEncodingLoaders[encoding]
+			if !ok {
+				tokenizerMu.Unlock()
+				return nil
+			}
+			enc = &tokenEncoder{name: encoding, load: load, split: splitterForEncoding(encoding)}
+			tokenEncoders[encoding] = enc
+		}
+		tokenizerMu.Unlock()
+	}
+	if !enc.ready() {
+		return nil
+	}
+	return enc
+}
+
+// tokenEncoder counts byte-pair-encoded tokens for one tiktoken encoding.
+type tokenEncoder struct {
+	name  string
+	load  func() (map[string]int, error)
+	split func(text string, yield func(piece string))
+
+	once  sync.Once
+	ranks map[string]int
+}
+
+func (e *tokenEncoder) ready() bool {
+	e.once.Do(func() {
+		ranks, err := e.load()
+		if err != nil || len(ranks) == 0 {
+			slog.Warn("token encoding unavailable, using estimate", "encoding", e.name, "error", err)
+			return
+		}
+		e.ranks = ranks
+	})
+	return e.ranks != nil
+}
+
+func (e *tokenEncoder) count(text string) int {
+	if text == "" {
+		return 0
+	}
+	total := 0
+	// Source text repeats the same identifiers and indentation over and over;
+	// a small per-call memo stays in cache where the rank table does not.
+	memo := make(map[string]int, min(len(text)/64, 1024))
+	e.split(text, func(piece string) {
+		if n, ok := memo[piece]; ok {
+			total += n
+			return
+		}
+		n := 0
+		key := piece
+		for len(piece) > maxBPEPieceBytes {
+			cut := maxBPEPieceBytes
+			for cut > 0 && !utf8.RuneStart(piece[cut]) {
+				cut--
+			}
+			if cut == 0 {
+				cut = maxBPEPieceBytes
+			}
+			n += e.countPiece(piece[:cut])
+			piece = piece[cut:]
+		}
+		n += e.countPiece(piece)
+		memo[key] = n
+		total += n
+	})
+	return total
+}
+
+// countPiece runs tiktoken's byte-pair merge over one pre-tokenized piece and
+// returns how many tokens remain.
+func (e *tokenEncoder) countPiece(piece string) int {
+	if len(piece) <= 1 {
+		return len(piece)
+	}
+	if _, ok := e.ranks[piece]; ok {
+		return 1
+	}
+
+	// starts[i] is the byte offset of part i; ranks[i] is the rank of the
+	// merged pair parts[i]+parts[i+1] (math.MaxInt when not mergeable).
+	var startsBuf, ranksBuf [64]int
+	starts := startsBuf[:0]
+	ranks := ranksBuf[:0]
+	for i := 0; i <= len(piece); i++ {
+		starts = append(starts, i)
+	}
+	pairRank := func(i int) int {
+		if i+2 >= len(starts) {
+			return math.MaxInt
+		}
+		if r, ok := e.ranks[piece[starts[i]:starts[i+2]]]; ok {
+			return r
+		}
+		return math.MaxInt
+	}
+	for i := 0; i < len(starts)-1; i++ {
+		ranks = append(ranks, pairRank(i))
+	}
+
+	for len(starts) > 2 {
+		best, bestRank := -1, math.MaxInt
+		for i, r := range ranks[:len(ranks)-1] {
+			if r < bestRank {
+				best, bestRank = i, r
+			}
+		}
+		if best < 0 {
+			break
+		}
+		starts = append(starts[:best+1], starts[best+2:]...)
+		ranks = append(ranks[:best+1], ranks[best+2:]...)
+		ranks[best] = pairRank(best)
+		if best > 0 {
+			ranks[best-1] = pairRank(best - 1)
+		}
+	}
+	return len(starts) - 1
+}
+
+func splitterForEncoding(encoding string) func(string, func(string)) {
+	if encoding == EncodingO200K {
+		return splitO200K
+	}
+	return splitCL100K
+}
+
+// splitCL100K pre-tokenizes text the way cl100k_base's pattern does:
+//
+//	(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}|
+//	 ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+(?!\S)|\s+
+//
+// It is hand-written because a backtracking regexp engine dominates the cost
+// of counting.
+func splitCL100K(text string, yield func(string)) {
+	for i := 0; i < len(text); {
+		end := contractionEnd(text, i)
+		if end < 0 {
+			end = cl100kWordEnd(text, i)
+		}
+		if end < 0 {
+			end = numberEnd(text, i)
+		}
+		if end < 0 {
+			end = punctEnd(text, i, false)
+		}
+		if end < 0 {
+			end = whitespaceEnd(text, i)
+		}
+		yield(text[i:end])
+		i = end
+	}
+}
+
+// splitO200K pre-tokenizes text the way o200k_base's pattern does:
+//
+//	[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]*[\p{Ll}\p{Lm}\p{Lo}\p{M}]+(?i:'s|'t|'re|'ve|'m|'ll|'d)?|
+//	[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]+[\p{Ll}\p{Lm}\p{Lo}\p{M}]*(?i:'s|'t|'re|'ve|'m|'ll|'d)?|
+//	\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n/]*|\s*[\r\n]+|\s+(?!\S)|\s+
+func splitO200K(text string, yield func(string)) {
+	for i := 0; i < len(text); {
+		end := o200kWordEnd(text, i, true)
+		if end < 0 {
+			end = o200kWordEnd(text, i, false)
+		}
+		if end < 0 {
+			end = numberEnd(text, i)
+		}
+		if end < 0 {
+			end = punctEnd(text, i, true)
+		}
+		if end < 0 {
+			end = whitespaceEnd(text, i)
+		}
+		yield(text[i:end])
+		i = end
+	}
+}
+
+// Each *End helper tries one alternative of the pattern at byte offset i and
+// returns the end offset of the match, or -1 when it does not match.
+
+func contractionEnd(text string, i int) int {
+	if i >= len(text) || text[i] != '\'' || i+1 >= len(text) {
+		return -1
+	}
+	switch text[i+1] | 0x20 {
+	case 's', 't', 'm', 'd':
+		return i + 2
+	case 'r', 'v':
+		if i+2 < len(text) && text[i+2]|0x20 == 'e' {
+			return i + 3
+		}
+	case 'l':
+		if i+2 < len(text) && text[i+2]|0x20 == 'l' {
+			return i + 3
+		}
+	}
+	return -1
+}
+
+// wordPrefixEnd consumes the optional [^\r\n\p{L}\p{N}] that may lead a word.
+func wordPrefixEnd(text string, i int) int {
+	cls, size := tokClassAt(text, i)
+	if cls&(clsLetter|clsNumber|clsNewline) != 0 {
+		return -1
+	}
+	return i + size
+}
+
+func cl100kWordEnd(text string, i int) int {
+	if j := wordPrefixEnd(text, i); j >= 0 {
+		if end := runEnd(text, j, clsLetter); end > j {
+			return end
+		}
+	}
+	if end := runEnd(text, i, clsLetter); end > i {
+		return end
+	}
+	return -1
+}
+
+// o200kWordEnd matches the two case-aware word alternatives of o200k: first
+// upper* lower+ then upper+ lower*, each optionally led by a prefix rune and
+// followed by a contraction.
+func o200kWordEnd(text string, i int, lowerTail bool) int {
+	match := func(j int) int {
+		upperEnd := runEnd(text, j, clsUpper)
+		end := -1
+		if lowerTail {
+			if e := runEnd(text, upperEnd, clsLower); e > upperEnd {
+				end = e
+			} else {
+				// Backtrack: the upper run may give back runes that are
+				// also in the lower class (Lm, Lo, M).
+				for k := upperEnd; k > j; {
+					r, size := utf8.DecodeLastRuneInString(text[j:k])
+					k -= size
+					if runeTokClass(r)&clsLower != 0 {
+						end = k + size
+						break
+					}
+				}
+			}
+		} else if upperEnd > j {
+			end = runEnd(text, upperEnd, clsLower)
+		}
+		if end < 0 {
+			return -1
+		}
+		if c := contractionEnd(text, end); c >= 0 {
+			return c
+		}
+		return end
+	}
+	if j := wordPrefixEnd(text, i); j >= 0 {
+		if end := match(j); end >= 0 {
+			return end
+		}
+	}
+	return match(i)
+}
+
+func numberEnd(text string, i int) int {
+	end := i
+	for n := 0; n < 3 && end < len(text); n++ {
+		cls, size := tokClassAt(text, end)
+		if cls&clsNumber == 0 {
+			break
+		}
+		end += size
+	}
+	if end == i {
+		return -1
+	}
+	return end
+}
+
+// punctEnd matches " ?[^\s\p{L}\p{N}]+[\r\n]*", also allowing '/' in the
+// trailing run for o200k.
+func punctEnd(text string, i int, slashTail bool) int {
+	j := i
+	if text[j] == ' ' {
+		j++
+	}
+	// No need to retry without the optional space: a space is never
+	// punctuation, so that attempt cannot match either.
+	end := runEnd(text, j, clsPunct)
+	if end == j {
+		return -1
+	}
+	for end < len(text) && (text[end] == '\r' || text[end] == '\n' || (slashTail && text[end] == '/')) {
+		end++
+	}
+	return end
+}
+
+// whitespaceEnd matches the three trailing whitespace alternatives:
+// \s*[\r\n]+ (through the last newline of the run), then \s+(?!\S) (the run
+// minus the rune that leads the next word), then \s+.
+func whitespaceEnd(text string, i int) int {
+	end := runEnd(text, i, clsSpace)
+	if end == i {
+		// Not reachable for well-formed input since every rune matches some
+		// alternative, but never stall on an unexpected one.
+		_, size := utf8.DecodeRuneInString(text[i:])
+		return i + size
+	}
+	if nl := strings.LastIndexAny(text[i:end], "\r\n"); nl >= 0 {
+		return i + nl + 1
+	}
+	if end < len(text) {
+		_, size := utf8.DecodeLastRuneInString(text[i:end])
+		if end-size > i {
+			return end - size
+		}
+	}
+	return end
+}
+
+// Character classes used by the pre-tokenizers. Upper and lower follow
+// o200k: [\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}] and [\p{Ll}\p{Lm}\p{Lo}\p{M}].
+const (
+	clsLetter = 1 << iota
+	clsUpper
+	clsLower
+	clsNumber
+	clsSpace
+	clsNewline
+	clsPunct // [^\s\p{L}\p{N}]
+)
+
+var asciiTokClass [utf8.RuneSelf]uint8
+
+func init() {
+	for r := rune(0); r < utf8.RuneSelf; r++ {
+		asciiTokClass[r] = runeTokClass(r)
+	}
+}
+
+func runeTokClass(r rune) uint8 {
+	var cls uint8
+	switch {
+	case unicode.IsLetter(r):
+		cls |= clsLetter
+	case unicode.IsNumber(r):
+		cls |= clsNumber
+	case unicode.IsSpace(r):
+		cls |= clsSpace
+		if r == '\r' || r == '\n' {
+			cls |= clsNewline
+		}
+	default:
+		cls |= clsPunct
+	}
+	if unicode.In(r, unicode.Lu, unicode.Lt, unicode.Lm, unicode.Lo, unicode.M) {
+		cls |= clsUpper
+	}
+	if unicode.In(r, unicode.Ll, unicode.Lm, unicode.Lo, unicode.M) {
+		cls |= clsLower
+	}
+	return cls
+}
+
+func tokClassAt(text string, i int) (uint8, int) {
+	if c := text[i]; c < utf8.RuneSelf {
+		return asciiTokClass[c], 1
+	}
+	r, size := utf8.DecodeRuneInString(text[i:])
+	return runeTokClass(r), size
+}
+
+// runEnd returns the end of the run of runes starting at i whose class
+// intersects mask.
+func runEnd(text string, i int, mask uint8) int {
+	for i < len(text) {
+		if c := text[i]; c < utf8.RuneSelf {
+			if asciiTokClass[c]&mask == 0 {
+				return i
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if runeTokClass(r)&mask == 0 {
+			return i
+		}
+		i += size
+	}
+	return i
+}
@@ -0,0 +1,60 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTokenEncodingForModel(t *testing.T) {
+	t.Cleanup(func() { RegisterTokenizerModels(nil) })
+
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{"gpt-4o-mini", EncodingO200K},
+		{"GPT-5.2", EncodingO200K},
+		{"openai/gpt-4.1", EncodingO200K},
+		{"o3-pro", EncodingO200K},
+		{"gpt-4-turbo", EncodingCL100K},
+		{"gpt-3.5-turbo", EncodingCL100K},
+		{"claude-sonnet-4-6", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := TokenEncodingForModel(tt.model); got != tt.want {
+			t.Errorf("TokenEncodingForModel(%q) = %q, want %q", tt.model, got, tt.want)
+		}
+	}
+
+	RegisterTokenizerModels(map[string]string{
+		"qwen*":           "cl100k_base",
+		"qwen3-coder-30b": "O200K_BASE",
+		"gpt-4o*":         "heuristic",
+	})
+	for model, want := range map[string]string{
+		"qwen2.5-coder":          EncodingCL100K,
+		"Qwen3-Coder-30B":        EncodingO200K,
+		"openrouter/qwen-plus":   EncodingCL100K,
+		"gpt-4o":                 "",
+		"gpt-4-turbo":            EncodingCL100K,
+		"llama-3.3-70b-instruct": "",
+	} {
+		if got := TokenEncodingForModel(model); got != want {
+			t.Errorf("with config, TokenEncodingForModel(%q) = %q, want %q", model, got, want)
+		}
+	}
+}
+
+func TestCountTokensFallsBackToHeuristic(t *testing.T) {
+	text := strings.Repeat("func main() {}\n", 20)
+	if got, want := CountTokens("claude-sonnet-4-6", text), EstimateTokens(text); got != want {
+		t.Fatalf("CountTokens for a model without a tokenizer = %d, want the heuristic %d", got, want)
+	}
+	if HasTokenizer("claude-sonnet-4-6") {
+		t.Fatal("HasTokenizer should be false for a model without an encoding")
+	}
+	if got := CountTokens("gpt-4o", ""); got != 0 {
+		t.Fatalf("CountTokens of empty text = %d, want 0", got)
+	}
+}
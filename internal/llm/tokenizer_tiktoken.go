@@ -0,0 +1,17 @@
+//go:build !notiktoken
+
+package llm
+
+import tiktoken_loader "github.com/pkoukk/tiktoken-go-loader"
+
+// The tiktoken rank tables add several megabytes to the binary. Build with
+// -tags notiktoken to leave them out; token counts then use EstimateTokens.
+func init() {
+	loader := tiktoken_loader.NewOfflineLoader()
+	for _, encoding := range []string{EncodingCL100K, EncodingO200K} {
+		file := encoding + ".tiktoken"
+		registerTokenEncoding(encoding, func() (map[string]int, error) {
+			return loader.LoadTiktokenBpe(file)
+		})
+	}
+}
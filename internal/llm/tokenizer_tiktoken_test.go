@@ -0,0 +1,95 @@
+//go:build !notiktoken
+
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// Expected counts were produced by tiktoken's encode_ordinary.
+var tokenCountFixtures = []struct {
+	text   string
+	cl100k int
+	o200k  int
+}{
+	{"hello world", 2, 2},
+	{"tiktoken is great!", 6, 6},
+	{"I'm sure they'll say it's FINE, aren't they?", 15, 11},
+	{"func (e *Engine) EstimateTokens(messages []Message) int {\n\treturn len(messages)\n}\n", 19, 19},
+	{"    if x == 1234567:\n        print('big')  \n\n\n", 15, 15},
+	{"HTTPServer parseJSON getURLPath XMLHttpRequest", 8, 9},
+	{"Grüße aus Zürich — naïve café, 東京都の天気は晴れです。", 25, 20},
+	{"emoji 😀👍🏽 and path/to//file.go // comment", 16, 13},
+	{"{\"path\": \"/tmp/a b.txt\", \"lines\": [1, 22, 333, 4444]}\r\n", 26, 26},
+}
+
+func TestCountTokensMatchesTiktokenFixtures(t *testing.T) {
+	for _, fx := range tokenCountFixtures {
+		if got := CountTokens("gpt-4-turbo", fx.text); got != fx.cl100k {
+			t.Errorf("cl100k_base %q = %d, want %d", fx.text, got, fx.cl100k)
+		}
+		if got := CountTokens("gpt-4o", fx.text); got != fx.o200k {
+			t.Errorf("o200k_base %q = %d, want %d", fx.text, got, fx.o200k)
+		}
+	}
+	if !HasTokenizer("gpt-5") {
+		t.Fatal("HasTokenizer(gpt-5) = false, want true")
+	}
+}
+
+func TestEstimateModelMessageTokensUsesTokenizer(t *testing.T) {
+	msgs := []Message{
+		UserText("HTTPServer parseJSON getURLPath XMLHttpRequest"),
+		{Role: RoleAssistant, Parts: []Part{{Type: PartToolCall, ToolCall: &ToolCall{ID: "1", Name: "read_file", Arguments: []byte(`{"path":"main.go"}`)}}}},
+		{Role: RoleEvent, Parts: []Part{{Type: PartText, Text: "ignored"}}},
+	}
+	want := CountTokens("gpt-4o", msgs[0].Parts[0].Text) + CountTokens("gpt-4o", `{"path":"main.go"}`)
+	if got := EstimateModelMessageTokens("gpt-4o", msgs); got != want {
+		t.Fatalf("EstimateModelMessageTokens = %d, want %d", got, want)
+	}
+	if got, want := EstimateModelMessageTokens("", msgs), EstimateMessageTokens(msgs); got != want {
+		t.Fatalf("without a model = %d, want the heuristic %d", got, want)
+	}
+
+	e := NewEngine(&fakeProvider{}, nil)
+	e.ConfigureContextManagement(nil, "openai", "gpt-4o", false)
+	if got := e.EstimateTokens(msgs); got != want {
+		t.Fatalf("Engine.EstimateTokens = %d, want %d from the active model's tokenizer", got, want)
+	}
+}
+
+func TestCountTokensChunksVeryLongPieces(t *testing.T) {
+	// A single unbroken run is merged in bounded chunks rather than
+	// quadratically; the count must stay close to the per-chunk optimum.
+	text := strings.Repeat("ab", 50_000)
+	got := CountTokens("gpt-4o", text)
+	if got <= 0 || got > len(text)/2 {
+		t.Fatalf("CountTokens of a 100KB run = %d, want between 1 and %d", got, len(text)/2)
+	}
+}
+
+func benchmarkCountTokensText() string {
+	var b strings.Builder
+	for i := 0; b.Len() < 100*1024; i++ {
+		fmt.Fprintf(&b, "// handle%d processes request %d and returns the HTTP status.\n", i, i*7)
+		fmt.Fprintf(&b, "func handle%d(ctx context.Context, req *Request) (int, error) {\n", i)
+		fmt.Fprintf(&b, "\tif req.ID == %d {\n\t\treturn 404, fmt.Errorf(\"not found: %%s\", req.Path)\n\t}\n", i*31)
+		b.WriteString("\treturn 200, nil\n}\n\n")
+	}
+	return b.String()[:100*1024]
+}
+
+func BenchmarkCountTokens100KB(b *testing.B) {
+	text := benchmarkCountTokensText()
+	for _, model := range []string{"gpt-4o", "gpt-4-turbo"} {
+		CountTokens(model, "warm up the encoder")
+		b.Run(TokenEncodingForModel(model), func(b *testing.B) {
+			b.SetBytes(int64(len(text)))
+			for i := 0; i < b.N; i++ {
+				CountTokens(model, text)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ToolFilter restricts the tools a request may see and call. It is applied
+// on top of the engine's own allowlist (see SetAllowedToolsFilter), so it can
+// only narrow the tool surface.
+type ToolFilter struct {
+	// Allow lists the permitted tools when AllowPresent is set. A present
+	// empty Allow permits no tools at all.
+	Allow        []string
+	AllowPresent bool
+	// Deny lists tools that are never permitted, even if allowed.
+	Deny []string
+}
+
+// ToolFilter returns the request's allowlist and denylist as a filter.
+func (r Request) ToolFilter() ToolFilter {
+	return ToolFilter{Allow: r.AllowedTools, AllowPresent: r.AllowedToolsPresent, Deny: r.DeniedTools}
+}
+
+// IsZero reports whether the filter permits every tool.
+func (f ToolFilter) IsZero() bool {
+	return !f.AllowPresent && len(f.Deny) == 0
+}
+
+// Permits reports whether the filter allows the named tool.
+func (f ToolFilter) Permits(name string) bool {
+	for _, denied := range f.Deny {
+		if denied == name {
+			return false
+		}
+	}
+	if !f.AllowPresent {
+		return true
+	}
+	for _, allowed := range f.Allow {
+		if allowed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterSpecs returns the specs the filter permits. A zero filter returns
+// specs unchanged.
+func (f ToolFilter) FilterSpecs(specs []ToolSpec) []ToolSpec {
+	if f.IsZero() {
+		return specs
+	}
+	filtered := make([]ToolSpec, 0, len(specs))
+	for _, spec := range specs {
+		if f.Permits(spec.Name) {
+			filtered = append(filtered, spec)
+		}
+	}
+	return filtered
+}
+
+// SetToolFilter applies f to every request the engine streams. Unlike
+// SetAllowedToolsFilter, f is not intersected with the registered tools, so
+// it also covers MCP and skill tools registered later.
+func (e *Engine) SetToolFilter(f ToolFilter) {
+	e.allowedMu.Lock()
+	defer e.allowedMu.Unlock()
+	e.toolFilter = ToolFilter{
+		Allow:        append([]string(nil), f.Allow...),
+		AllowPresent: f.AllowPresent,
+		Deny:         append([]string(nil), f.Deny...),
+	}
+}
+
+// ToolFilter returns the engine-wide filter set by SetToolFilter.
+func (e *Engine) ToolFilter() ToolFilter {
+	e.allowedMu.RLock()
+	defer e.allowedMu.RUnlock()
+	return e.toolFilter
+}
+
+// ErrToolNotPermitted matches a ToolNotPermittedError with errors.Is.
+var ErrToolNotPermitted = errors.New("tool not permitted")
+
+// ToolNotPermittedError reports a tool call rejected by a request's tool
+// filter. The tool never runs; the model gets the error as the tool result.
+type ToolNotPermittedError struct {
+	Tool string
+}
+
+func (e *ToolNotPermittedError) Error() string {
+	return fmt.Sprintf("tool '%s' is not permitted for this request", e.Tool)
+}
+
+func (e *ToolNotPermittedError) Is(target error) bool {
+	return target == ErrToolNotPermitted
+}
+
+const toolFiltersKey contextKey = "tool_filters"
+
+// contextWithToolFilter adds f to the filters carried by ctx. Filters
+// accumulate, so a sub-agent started from a restricted request can never
+// call a tool its parent could not.
+func contextWithToolFilter(ctx context.Context, f ToolFilter) context.Context {
+	if f.IsZero() {
+		return ctx
+	}
+	parent := toolFiltersFromContext(ctx)
+	filters := make([]ToolFilter, 0, len(parent)+1)
+	filters = append(append(filters, parent...), f)
+	return context.WithValue(ctx, toolFiltersKey, filters)
+}
+
+func toolFiltersFromContext(ctx context.Context) []ToolFilter {
+	if ctx == nil {
+		return nil
+	}
+	filters, _ := ctx.Value(toolFiltersKey).([]ToolFilter)
+	return filters
+}
+
+// checkToolPermitted returns a ToolNotPermittedError when a filter carried by
+// ctx rejects the named tool.
+func checkToolPermitted(ctx context.Context, name string) error {
+	for _, f := range toolFiltersFromContext(ctx) {
+		if !f.Permits(name) {
+			return &ToolNotPermittedError{Tool: name}
+		}
+	}
+	return nil
+}
+
+// filterToolSpecs applies the engine allowlist, the engine tool filter and
+// the request filters carried by ctx.
+func (e *Engine) filterToolSpecs(ctx context.Context, specs []ToolSpec) []ToolSpec {
+	specs = e.ToolFilter().FilterSpecs(e.FilterAllowedToolSpecs(specs))
+	for _, f := range toolFiltersFromContext(ctx) {
+		specs = f.FilterSpecs(specs)
+	}
+	return specs
+}
@@ -0,0 +1,149 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type filterProbeTool struct {
+	name  string
+	calls atomic.Int32
+}
+
+func (p *filterProbeTool) Spec() ToolSpec { return ToolSpec{Name: p.name} }
+
+func (p *filterProbeTool) Execute(ctx context.Context, args json.RawMessage) (ToolOutput, error) {
+	p.calls.Add(1)
+	return TextOutput("ran " + p.name), nil
+}
+
+func (p *filterProbeTool) Preview(args json.RawMessage) string { return "" }
+
+func specNames(specs []ToolSpec) []string {
+	names := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		names = append(names, spec.Name)
+	}
+	return names
+}
+
+func TestStreamRequestToolFilterHidesAndRejectsTools(t *testing.T) {
+	readFile := &filterProbeTool{name: "read_file"}
+	shell := &filterProbeTool{name: "shell"}
+	webFetch := &filterProbeTool{name: "web_fetch"}
+	registry := NewToolRegistry()
+	for _, tool := range []*filterProbeTool{readFile, shell, webFetch} {
+		registry.Register(tool)
+	}
+	provider := NewMockProvider("mock").
+		AddToolCall("call-1", "shell", map[string]string{"command": "rm -rf /"}).
+		AddTextResponse("done")
+	engine := NewEngine(provider, registry)
+
+	stream, err := engine.Stream(context.Background(), Request{
+		Messages:            []Message{UserText("hi")},
+		Tools:               registry.AllSpecs(),
+		AllowedTools:        []string{"read_file", "shell"},
+		AllowedToolsPresent: true,
+		DeniedTools:         []string{"shell"},
+	})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	defer stream.Close()
+	drainStream(t, stream)
+
+	if n := shell.calls.Load(); n != 0 {
+		t.Fatalf("denied tool executed %d times", n)
+	}
+	requests := provider.RecordedRequests()
+	if len(requests) != 2 {
+		t.Fatalf("provider saw %d requests, want 2", len(requests))
+	}
+	for i, req := range requests {
+		if got := strings.Join(specNames(req.Tools), ","); got != "read_file" {
+			t.Fatalf("request %d tools = %q, want only read_file", i, got)
+		}
+	}
+	var result *ToolResult
+	for _, msg := range requests[1].Messages {
+		for _, part := range msg.Parts {
+			if part.Type == PartToolResult {
+				result = part.ToolResult
+			}
+		}
+	}
+	if result == nil || !result.IsError || !strings.Contains(result.Content, "tool 'shell' is not permitted") {
+		t.Fatalf("tool result = %+v, want a not-permitted error", result)
+	}
+}
+
+func TestToolFilterContextNarrowsForNestedRequests(t *testing.T) {
+	parent := contextWithToolFilter(context.Background(), ToolFilter{Allow: []string{"read_file", "grep"}, AllowPresent: true})
+	child := contextWithToolFilter(parent, ToolFilter{Allow: []string{"grep", "shell"}, AllowPresent: true})
+
+	if err := checkToolPermitted(child, "grep"); err != nil {
+		t.Fatalf("grep: %v", err)
+	}
+	for _, name := range []string{"read_file", "shell"} {
+		err := checkToolPermitted(child, name)
+		var notPermitted *ToolNotPermittedError
+		if !errors.Is(err, ErrToolNotPermitted) || !errors.As(err, &notPermitted) || notPermitted.Tool != name {
+			t.Fatalf("%s: err = %v, want ToolNotPermittedError", name, err)
+		}
+	}
+	if err := checkToolPermitted(parent, "read_file"); err != nil {
+		t.Fatalf("parent filter should be unchanged: %v", err)
+	}
+}
+
+func TestToolFilterPresentEmptyAllowlistPermitsNothing(t *testing.T) {
+	filter := Request{AllowedToolsPresent: true}.ToolFilter()
+	if filter.IsZero() || filter.Permits("read_file") {
+		t.Fatal("present empty allowlist should permit no tools")
+	}
+	if got := filter.FilterSpecs([]ToolSpec{{Name: "read_file"}}); len(got) != 0 {
+		t.Fatalf("FilterSpecs = %v, want none", got)
+	}
+	if !(Request{}).ToolFilter().IsZero() {
+		t.Fatal("request without lists should have a zero filter")
+	}
+}
+
+func TestStreamEngineToolFilterAppliesToEveryRequest(t *testing.T) {
+	readFile := &filterProbeTool{name: "read_file"}
+	shell := &filterProbeTool{name: "shell"}
+	registry := NewToolRegistry()
+	registry.Register(readFile)
+	registry.Register(shell)
+	provider := NewMockProvider("mock").
+		AddToolCall("call-1", "shell", map[string]string{"command": "ls"}).
+		AddTextResponse("done")
+	old := NewEngine(provider, registry)
+	old.SetToolFilter(ToolFilter{Deny: []string{"shell"}})
+	engine := NewEngine(provider, registry)
+	engine.InheritRuntime(old)
+
+	stream, err := engine.Stream(context.Background(), Request{
+		Messages: []Message{UserText("hi")},
+		Tools:    registry.AllSpecs(),
+	})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	defer stream.Close()
+	drainStream(t, stream)
+
+	if n := shell.calls.Load(); n != 0 {
+		t.Fatalf("denied tool executed %d times", n)
+	}
+	for i, req := range provider.RecordedRequests() {
+		if got := strings.Join(specNames(req.Tools), ","); got != "read_file" {
+			t.Fatalf("request %d tools = %q, want only read_file", i, got)
+		}
+	}
+}
@@ -211,6 +211,7 @@ func sanitizeToolHistorySlow(messages []Message) []Message {
 				Role:        msg.Role,
 				Parts:       cloneParts(msg.Parts),
 				CacheAnchor: msg.CacheAnchor,
+				Pinned:      msg.Pinned,
 			})
 		}
 	}
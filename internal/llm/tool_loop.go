@@ -0,0 +1,123 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// defaultToolLoopThreshold is how many consecutive turns may repeat the same
+// tool calls before the engine nudges the model.
+const defaultToolLoopThreshold = 3
+
+// ToolLoopError reports that the model kept repeating identical tool calls
+// after being told the result would not change.
+type ToolLoopError struct {
+	Tool    string
+	Repeats int
+}
+
+func (e *ToolLoopError) Error() string {
+	return fmt.Sprintf("agent stuck in a tool-call loop: %s called with the same arguments %d times in a row", e.Tool, e.Repeats)
+}
+
+// IsToolLoop reports whether err is a ToolLoopError.
+func IsToolLoop(err error) bool {
+	var loopErr *ToolLoopError
+	return errors.As(err, &loopErr)
+}
+
+type toolLoopAction int
+
+const (
+	toolLoopNone toolLoopAction = iota
+	toolLoopNudge
+	toolLoopAbort
+)
+
+// toolLoopDetector watches the tool calls of consecutive turns. A turn that
+// makes exactly the same calls as the one before it, with no assistant text,
+// extends the streak; any other turn starts a new one. When the streak
+// reaches the threshold the model is nudged once, and the next repeat aborts.
+type toolLoopDetector struct {
+	threshold int // 0 disables detection
+	lastKey   string
+	repeats   int
+	nudged    bool
+}
+
+func newToolLoopDetector(threshold int) *toolLoopDetector {
+	return &toolLoopDetector{threshold: threshold}
+}
+
+// observe records one turn's text and tool calls and says what to do.
+func (d *toolLoopDetector) observe(text string, calls []ToolCall) toolLoopAction {
+	if d.threshold <= 0 || len(calls) == 0 {
+		return toolLoopNone
+	}
+	key := toolCallsLoopKey(calls)
+	if key != d.lastKey || strings.TrimSpace(text) != "" {
+		d.lastKey = key
+		d.repeats = 1
+		d.nudged = false
+		return toolLoopNone
+	}
+	d.repeats++
+	switch {
+	case d.repeats < d.threshold:
+		return toolLoopNone
+	case !d.nudged:
+		d.nudged = true
+		return toolLoopNudge
+	default:
+		return toolLoopAbort
+	}
+}
+
+// reset starts over, e.g. after the user interjects.
+func (d *toolLoopDetector) reset() {
+	d.lastKey = ""
+	d.repeats = 0
+	d.nudged = false
+}
+
+// toolLoopNudgeText is the message added after the tool results once a streak
+// reaches the threshold.
+func toolLoopNudgeText(calls []ToolCall) string {
+	names := make([]string, 0, len(calls))
+	for _, call := range calls {
+		if !containsString(names, call.Name) {
+			names = append(names, call.Name)
+		}
+	}
+	return fmt.Sprintf("You already called %s with these exact arguments several times in a row; the result will not change. Use the results you have, try a different approach, or explain what is blocking you.", strings.Join(names, ", "))
+}
+
+// toolCallsLoopKey identifies a turn's calls independent of call IDs, call
+// order and JSON formatting.
+func toolCallsLoopKey(calls []ToolCall) string {
+	keys := make([]string, len(calls))
+	for i, call := range calls {
+		keys[i] = call.Name + "\x00" + normalizeToolLoopArgs(call.Arguments)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, "\x01")
+}
+
+// normalizeToolLoopArgs re-encodes JSON arguments so key order and whitespace
+// do not matter. Arguments that are not JSON are compared as-is.
+func normalizeToolLoopArgs(args json.RawMessage) string {
+	trimmed := bytes.TrimSpace(args)
+	var v any
+	if err := json.Unmarshal(trimmed, &v); err != nil {
+		return string(trimmed)
+	}
+	normalized, err := json.Marshal(v)
+	if err != nil {
+		return string(trimmed)
+	}
+	return string(normalized)
+}
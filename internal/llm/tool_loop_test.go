@@ -0,0 +1,125 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestToolLoopDetector(t *testing.T) {
+	call := func(name, args string) []ToolCall {
+		return []ToolCall{{ID: "ignored", Name: name, Arguments: json.RawMessage(args)}}
+	}
+
+	d := newToolLoopDetector(3)
+	got := []toolLoopAction{
+		d.observe("", call("read_file", `{"path":"a.go","limit":10}`)),
+		d.observe("", call("read_file", `{ "limit": 10, "path": "a.go" }`)),
+		d.observe("", call("read_file", `{"path":"a.go","limit":10}`)),
+		d.observe("", call("read_file", `{"path":"a.go","limit":10}`)),
+	}
+	want := []toolLoopAction{toolLoopNone, toolLoopNone, toolLoopNudge, toolLoopAbort}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("identical calls: actions = %v, want %v", got, want)
+		}
+	}
+
+	d = newToolLoopDetector(3)
+	for i := 0; i < 6; i++ {
+		if action := d.observe("", call("read_file", fmt.Sprintf(`{"path":"%d.go"}`, i))); action != toolLoopNone {
+			t.Fatalf("different arguments: turn %d action = %v", i, action)
+		}
+	}
+
+	d = newToolLoopDetector(3)
+	for i := 0; i < 6; i++ {
+		if action := d.observe("Checking again.", call("shell", `{"command":"make"}`)); action != toolLoopNone {
+			t.Fatalf("calls with text: turn %d action = %v", i, action)
+		}
+	}
+
+	d = newToolLoopDetector(3)
+	d.observe("", call("shell", `{"command":"make"}`))
+	d.observe("", call("shell", `{"command":"make"}`))
+	d.reset()
+	if action := d.observe("", call("shell", `{"command":"make"}`)); action != toolLoopNone {
+		t.Fatalf("after reset action = %v", action)
+	}
+
+	d = newToolLoopDetector(0)
+	for i := 0; i < 6; i++ {
+		if action := d.observe("", call("shell", `{"command":"make"}`)); action != toolLoopNone {
+			t.Fatalf("disabled detector: turn %d action = %v", i, action)
+		}
+	}
+}
+
+func TestEngineBreaksToolCallLoop(t *testing.T) {
+	registry := NewToolRegistry()
+	registry.Register(&mockTool{name: "loop_tool", result: "same result"})
+
+	provider := NewMockProvider("test")
+	for i := 0; i < 10; i++ {
+		provider.AddToolCall(fmt.Sprintf("id-%d", i), "loop_tool", map[string]any{"query": "status"})
+	}
+
+	engine := NewEngine(provider, registry)
+	stream, err := engine.Stream(context.Background(), Request{
+		Messages: []Message{UserText("check the status")},
+		Tools:    []ToolSpec{{Name: "loop_tool"}},
+		MaxTurns: 10,
+	})
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	defer stream.Close()
+
+	var gotErr error
+	var warning string
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			break
+		}
+		if event.Type == EventPhase && strings.Contains(event.Text, "tool-call loop") {
+			warning = event.Text
+		}
+		if event.Type == EventError {
+			gotErr = event.Err
+		}
+	}
+
+	if !IsToolLoop(gotErr) {
+		t.Fatalf("expected ToolLoopError, got %T %v", gotErr, gotErr)
+	}
+	if !strings.HasPrefix(warning, WarningPhasePrefix) {
+		t.Fatalf("expected loop warning phase, got %q", warning)
+	}
+	requests := provider.RecordedRequests()
+	if len(requests) != 4 {
+		t.Fatalf("provider requests = %d, want 4 (three repeats, then one after the nudge)", len(requests))
+	}
+	toolResults := 0
+	for _, msg := range requests[3].Messages {
+		if msg.Role == RoleTool {
+			toolResults++
+		}
+	}
+	if toolResults != 3 {
+		t.Fatalf("tool results = %d, want 3", toolResults)
+	}
+	last := requests[3].Messages[len(requests[3].Messages)-1]
+	if last.Role != RoleSystem || !strings.Contains(MessageText(last), "loop_tool") || !strings.Contains(MessageText(last), "will not change") {
+		t.Fatalf("expected nudge before the fourth request, got %s %q", last.Role, MessageText(last))
+	}
+	for i, req := range requests[:3] {
+		for _, msg := range req.Messages {
+			if msg.Role == RoleSystem && strings.Contains(MessageText(msg), "will not change") {
+				t.Fatalf("request %d already carried the nudge", i)
+			}
+		}
+	}
+}
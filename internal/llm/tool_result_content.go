@@ -46,15 +46,21 @@ func toolResultTextContent(result *ToolResult) string {
 // content parts. Images and part ordering are preserved, while the retained
 // head and tail text use the same marker as plain tool output truncation.
 func truncateToolOutput(output ToolOutput, maxChars int) ToolOutput {
-	content, parts := truncateToolResultContent(output.Content, output.ContentParts, maxChars)
+	return truncateToolOutputWithNote(output, maxChars, "")
+}
+
+// truncateToolOutputWithNote is truncateToolOutput with note (e.g. the applied
+// limit) appended inside the truncation marker.
+func truncateToolOutputWithNote(output ToolOutput, maxChars int, note string) ToolOutput {
+	content, parts := truncateToolResultContent(output.Content, output.ContentParts, maxChars, note)
 	output.Content = content
 	output.ContentParts = parts
 	return output
 }
 
-func truncateToolResultContent(content string, parts []ToolContentPart, maxChars int) (string, []ToolContentPart) {
+func truncateToolResultContent(content string, parts []ToolContentPart, maxChars int, note string) (string, []ToolContentPart) {
 	if len(parts) == 0 {
-		return TruncateToolResult(content, maxChars), nil
+		return truncateToolResultText(content, maxChars, note), nil
 	}
 
 	parts = cloneToolContentParts(parts)
@@ -68,7 +74,7 @@ func truncateToolResultContent(content string, parts []ToolContentPart, maxChars
 	textRunes := []rune(text.String())
 	if len(textRunes) == 0 {
 		// Image-only structured results may still carry a textual fallback.
-		return TruncateToolResult(content, maxChars), parts
+		return truncateToolResultText(content, maxChars, note), parts
 	}
 	if len(textRunes) <= maxChars {
 		// Structured text is canonical. Do not retain a divergent, potentially
@@ -79,7 +85,7 @@ func truncateToolResultContent(content string, parts []ToolContentPart, maxChars
 	head := maxChars / 2
 	tail := maxChars - head
 	middle := string(textRunes[head : len(textRunes)-tail])
-	marker := fmt.Sprintf("\n[...%d chars truncated - %d lines...]\n", len(textRunes)-maxChars, 1+strings.Count(middle, "\n"))
+	marker := toolTruncationMarker(len(textRunes)-maxChars, 1+strings.Count(middle, "\n"), note)
 	tailStart := len(textRunes) - tail
 	textOffset := 0
 	markerAdded := false
@@ -120,7 +126,7 @@ func truncateToolResultContent(content string, parts []ToolContentPart, maxChars
 }
 
 func truncateToolResult(result ToolResult, maxChars int) ToolResult {
-	result.Content, result.ContentParts = truncateToolResultContent(result.Content, result.ContentParts, maxChars)
+	result.Content, result.ContentParts = truncateToolResultContent(result.Content, result.ContentParts, maxChars, "")
 	return result
 }
 
@@ -72,7 +72,7 @@ func TestEngineApplyCompactionToolResultLimitCoversContentParts(t *testing.T) {
 	config.MaxToolResultChars = 6
 	engine.SetCompaction(1000, config)
 
-	got := engine.applyToolOutputTruncation(ToolOutput{
+	got := engine.applyToolOutputTruncation("read_file", ToolOutput{
 		ContentParts: []ToolContentPart{
 			{Type: ToolContentPartText, Text: "abcdef"},
 			{Type: ToolContentPartImageData, ImageData: &ToolImageData{MediaType: "image/png", Base64: "aGVsbG8="}},
@@ -92,7 +92,7 @@ func TestEngineApplyToolOutputTruncationCoversContentParts(t *testing.T) {
 	engine := NewEngine(nil, nil)
 	engine.SetMaxToolOutputChars(8)
 
-	got := engine.applyToolOutputTruncation(ToolOutput{
+	got := engine.applyToolOutputTruncation("read_file", ToolOutput{
 		Content: "stale and unbounded",
 		ContentParts: []ToolContentPart{
 			{Type: ToolContentPartText, Text: "123456"},
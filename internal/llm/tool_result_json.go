@@ -0,0 +1,241 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// minJSONStringRunes and minJSONArrayItems are the floors the structural
+	// truncator shrinks toward before giving up and falling back to head/tail.
+	minJSONStringRunes = 16
+	minJSONArrayItems  = 1
+)
+
+// jsonNode is an order-preserving JSON tree. encoding/json maps lose key
+// order, which makes truncated output harder for the model to line up with
+// the tool's documented shape.
+type jsonNode struct {
+	kind     byte // '{', '[', 's' (string) or 'v' (number, bool, null)
+	text     string
+	keys     []string
+	children []*jsonNode
+}
+
+// TruncateJSONToolResult shrinks a JSON object or array to fit maxChars while
+// keeping it valid JSON. Every object key is kept; long string values are cut
+// with a truncation marker and long arrays are capped with an "…and N more
+// items" sentinel element. It reports false when content is not a JSON object
+// or array, or when even the tightest limits cannot fit the budget, so callers
+// can fall back to TruncateToolResult.
+func TruncateJSONToolResult(content string, maxChars int) (string, bool) {
+	if maxChars <= 0 {
+		return "", false
+	}
+	if utf8.RuneCountInString(content) <= maxChars {
+		return content, true
+	}
+	trimmed := strings.TrimSpace(content)
+	if trimmed == "" || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return "", false
+	}
+	root, err := parseJSONNode(trimmed)
+	if err != nil {
+		return "", false
+	}
+
+	strLimit, arrLimit := jsonNodeExtents(root)
+	for {
+		var b strings.Builder
+		writeTruncatedJSON(&b, root, strLimit, arrLimit)
+		if out := b.String(); utf8.RuneCountInString(out) <= maxChars {
+			return out, true
+		}
+		if strLimit <= minJSONStringRunes && arrLimit <= minJSONArrayItems {
+			return "", false
+		}
+		strLimit = max(minJSONStringRunes, strLimit/2)
+		arrLimit = max(minJSONArrayItems, arrLimit/2)
+	}
+}
+
+func parseJSONNode(content string) (*jsonNode, error) {
+	dec := json.NewDecoder(strings.NewReader(content))
+	dec.UseNumber()
+	root, err := decodeJSONNode(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("trailing data after JSON value")
+	}
+	return root, nil
+}
+
+func decodeJSONNode(dec *json.Decoder) (*jsonNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch v := tok.(type) {
+	case json.Delim:
+		switch v {
+		case '{':
+			node := &jsonNode{kind: '{'}
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, ok := keyTok.(string)
+				if !ok {
+					return nil, fmt.Errorf("unexpected object key %v", keyTok)
+				}
+				child, err := decodeJSONNode(dec)
+				if err != nil {
+					return nil, err
+				}
+				node.keys = append(node.keys, key)
+				node.children = append(node.children, child)
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			return node, nil
+		case '[':
+			node := &jsonNode{kind: '['}
+			for dec.More() {
+				child, err := decodeJSONNode(dec)
+				if err != nil {
+					return nil, err
+				}
+				node.children = append(node.children, child)
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			return node, nil
+		}
+		return nil, fmt.Errorf("unexpected delimiter %v", v)
+	case string:
+		return &jsonNode{kind: 's', text: v}, nil
+	case json.Number:
+		return &jsonNode{kind: 'v', text: v.String()}, nil
+	case bool:
+		if v {
+			return &jsonNode{kind: 'v', text: "true"}, nil
+		}
+		return &jsonNode{kind: 'v', text: "false"}, nil
+	case nil:
+		return &jsonNode{kind: 'v', text: "null"}, nil
+	}
+	return nil, fmt.Errorf("unexpected JSON token %v", tok)
+}
+
+// jsonNodeExtents returns the longest string (in runes) and longest array in
+// the tree, which seed the shrinking limits.
+func jsonNodeExtents(n *jsonNode) (longestString, longestArray int) {
+	switch n.kind {
+	case 's':
+		return utf8.RuneCountInString(n.text), 0
+	case '[':
+		longestArray = len(n.children)
+	}
+	for _, child := range n.children {
+		s, a := jsonNodeExtents(child)
+		longestString = max(longestString, s)
+		longestArray = max(longestArray, a)
+	}
+	return longestString, longestArray
+}
+
+func writeTruncatedJSON(b *strings.Builder, n *jsonNode, strLimit, arrLimit int) {
+	switch n.kind {
+	case 's':
+		writeJSONString(b, truncateJSONStringValue(n.text, strLimit))
+	case 'v':
+		b.WriteString(n.text)
+	case '{':
+		b.WriteByte('{')
+		for i, key := range n.keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeJSONString(b, key)
+			b.WriteByte(':')
+			writeTruncatedJSON(b, n.children[i], strLimit, arrLimit)
+		}
+		b.WriteByte('}')
+	case '[':
+		b.WriteByte('[')
+		kept := min(len(n.children), arrLimit)
+		for i := 0; i < kept; i++ {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeTruncatedJSON(b, n.children[i], strLimit, arrLimit)
+		}
+		if omitted := len(n.children) - kept; omitted > 0 {
+			if kept > 0 {
+				b.WriteByte(',')
+			}
+			writeJSONString(b, fmt.Sprintf("…and %d more items", omitted))
+		}
+		b.WriteByte(']')
+	}
+}
+
+func truncateJSONStringValue(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit]) + fmt.Sprintf("…[%d chars truncated]", len(runes)-limit)
+}
+
+func writeJSONString(b *strings.Builder, s string) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	b.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
+
+// truncateJSONToolOutput applies TruncateJSONToolResult to tool output whose
+// text lives in a single place (Content, or exactly one text content part).
+// Outputs with several text parts are left to the head/tail truncator, since
+// their concatenation is not a single JSON document.
+func truncateJSONToolOutput(output ToolOutput, maxChars int) ToolOutput {
+	if len(output.ContentParts) == 0 {
+		if truncated, ok := TruncateJSONToolResult(output.Content, maxChars); ok {
+			output.Content = truncated
+		}
+		return output
+	}
+
+	textIdx := -1
+	for i, part := range output.ContentParts {
+		if part.Type != ToolContentPartText {
+			continue
+		}
+		if textIdx >= 0 {
+			return output
+		}
+		textIdx = i
+	}
+	if textIdx < 0 {
+		return output
+	}
+	truncated, ok := TruncateJSONToolResult(output.ContentParts[textIdx].Text, maxChars)
+	if !ok {
+		return output
+	}
+	output.ContentParts = cloneToolContentParts(output.ContentParts)
+	output.ContentParts[textIdx].Text = truncated
+	output.Content = truncated
+	return output
+}
@@ -0,0 +1,140 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateJSONToolResult(t *testing.T) {
+	items := make([]string, 0, 140)
+	for i := 0; i < 140; i++ {
+		items = append(items, `{"id":`+strings.Repeat("7", 3)+`,"name":"item"}`)
+	}
+	tests := []struct {
+		name     string
+		content  string
+		maxChars int
+		wantKeys []string
+		contains []string
+	}{
+		{
+			name:     "long string value",
+			content:  `{"path":"a.go","body":"` + strings.Repeat("x", 500) + `"}`,
+			maxChars: 200,
+			wantKeys: []string{"path", "body"},
+			contains: []string{`"path":"a.go"`, "chars truncated]"},
+		},
+		{
+			name:     "long array",
+			content:  `{"total":140,"items":[` + strings.Join(items, ",") + `]}`,
+			maxChars: 400,
+			wantKeys: []string{"total", "items"},
+			contains: []string{`"total":140`, "more items"},
+		},
+		{
+			name: "nested structures",
+			content: `{"outer":{"inner":{"list":[` + strings.Repeat(`"`+strings.Repeat("n", 50)+`",`, 30) +
+				`"end"],"flag":true,"none":null}},"after":1.5}`,
+			maxChars: 300,
+			wantKeys: []string{"outer", "after"},
+			contains: []string{`"flag":true`, `"none":null`, `"after":1.5`},
+		},
+		{
+			name:     "unicode strings",
+			content:  `{"text":"` + strings.Repeat("héllo 世界 🎉 ", 100) + `","tag":"<b>"}`,
+			maxChars: 120,
+			wantKeys: []string{"text", "tag"},
+			contains: []string{`"tag":"<b>"`, "héllo 世界"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := TruncateJSONToolResult(tt.content, tt.maxChars)
+			if !ok {
+				t.Fatalf("TruncateJSONToolResult returned ok=false")
+			}
+			if n := utf8.RuneCountInString(got); n > tt.maxChars {
+				t.Fatalf("result is %d chars, want <= %d: %s", n, tt.maxChars, got)
+			}
+			if !utf8.ValidString(got) {
+				t.Fatalf("result is not valid UTF-8: %q", got)
+			}
+			var decoded map[string]any
+			if err := json.Unmarshal([]byte(got), &decoded); err != nil {
+				t.Fatalf("result is not valid JSON: %v\n%s", err, got)
+			}
+			for _, key := range tt.wantKeys {
+				if _, ok := decoded[key]; !ok {
+					t.Errorf("key %q dropped: %s", key, got)
+				}
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("result missing %q: %s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestTruncateJSONToolResultPreservesKeyOrderAndCounts(t *testing.T) {
+	content := `{"z":1,"a":[1,2,3,4,5,6,7,8,9,10,11,12,13,14,15,16,17,18,19,20],"m":"` + strings.Repeat("q", 80) + `"}`
+	got, ok := TruncateJSONToolResult(content, 100)
+	if !ok {
+		t.Fatalf("TruncateJSONToolResult returned ok=false")
+	}
+	if zi, ai, mi := strings.Index(got, `"z"`), strings.Index(got, `"a"`), strings.Index(got, `"m"`); !(zi < ai && ai < mi) {
+		t.Fatalf("key order not preserved: %s", got)
+	}
+	var decoded struct {
+		A []any `json:"a"`
+	}
+	if err := json.Unmarshal([]byte(got), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, got)
+	}
+	sentinel, _ := decoded.A[len(decoded.A)-1].(string)
+	kept := len(decoded.A) - 1
+	if want := fmt.Sprintf("…and %d more items", 20-kept); sentinel != want {
+		t.Fatalf("sentinel = %q, want %q (result %s)", sentinel, want, got)
+	}
+}
+
+func TestTruncateJSONToolResultRejectsNonJSON(t *testing.T) {
+	for _, content := range []string{
+		strings.Repeat("plain text ", 50),
+		`"` + strings.Repeat("s", 200) + `"`,
+		`{"broken": ` + strings.Repeat("1", 200),
+		`{"a":1} trailing` + strings.Repeat(" ", 200),
+	} {
+		if got, ok := TruncateJSONToolResult(content, 50); ok {
+			t.Errorf("TruncateJSONToolResult(%.20q) = %q, true; want fallback", content, got)
+		}
+	}
+}
+
+func TestEngineApplyToolOutputTruncationKeepsJSONValid(t *testing.T) {
+	engine := NewEngine(nil, nil)
+	engine.SetCompaction(1000, DefaultCompactionConfig())
+	engine.SetMaxToolOutputChars(200)
+
+	content := `{"matches":[` + strings.Repeat(`{"file":"main.go","line":12},`, 40) + `{"file":"x.go","line":1}]}`
+	got := engine.applyToolOutputTruncation("read_file", ToolOutput{Content: content})
+	if !json.Valid([]byte(got.Content)) {
+		t.Fatalf("JSON tool output was not kept valid: %s", got.Content)
+	}
+	if !strings.Contains(got.Content, "more items") {
+		t.Fatalf("expected array sentinel: %s", got.Content)
+	}
+
+	config := DefaultCompactionConfig()
+	config.JSONToolResults = false
+	engine.SetCompaction(1000, config)
+	got = engine.applyToolOutputTruncation("read_file", ToolOutput{Content: content})
+	if !strings.Contains(got.Content, "chars truncated -") {
+		t.Fatalf("disabled JSON mode should use head/tail truncation: %s", got.Content)
+	}
+}
@@ -0,0 +1,340 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// ToolSchemaDialect names the flavour of JSON Schema a provider accepts for
+// tool input parameters. Every dialect is lowered from the same canonical form:
+// ToolSpec.Schema after ParseToolJSONSchemaMap sanitization.
+type ToolSchemaDialect int
+
+const (
+	// ToolSchemaCanonical is the provider-neutral Open Responses shape, also
+	// sent as-is to OpenAI-compatible chat completions APIs and Ollama.
+	ToolSchemaCanonical ToolSchemaDialect = iota
+	// ToolSchemaOpenAI is the OpenAI Responses API shape for non-strict tools.
+	ToolSchemaOpenAI
+	// ToolSchemaOpenAIStrict is OpenAI's strict structured-output subset: every
+	// property required, every object closed, no type unions.
+	ToolSchemaOpenAIStrict
+	// ToolSchemaAnthropic is the canonical form rooted at an object, as
+	// Anthropic's input_schema requires.
+	ToolSchemaAnthropic
+	// ToolSchemaGemini is the OpenAPI subset Gemini function declarations
+	// accept: single types with nullable, and no validation keywords.
+	ToolSchemaGemini
+)
+
+// ToolSchemaDialects returns every dialect, in declaration order.
+func ToolSchemaDialects() []ToolSchemaDialect {
+	return []ToolSchemaDialect{ToolSchemaCanonical, ToolSchemaOpenAI, ToolSchemaOpenAIStrict, ToolSchemaAnthropic, ToolSchemaGemini}
+}
+
+func (d ToolSchemaDialect) String() string {
+	switch d {
+	case ToolSchemaCanonical:
+		return "canonical"
+	case ToolSchemaOpenAI:
+		return "openai"
+	case ToolSchemaOpenAIStrict:
+		return "openai-strict"
+	case ToolSchemaAnthropic:
+		return "anthropic"
+	case ToolSchemaGemini:
+		return "gemini"
+	default:
+		return fmt.Sprintf("dialect(%d)", int(d))
+	}
+}
+
+// ToolSchemaFor returns schema lowered for dialect. The result is a private
+// copy the caller may mutate; the lowering itself is cached.
+func ToolSchemaFor(schema map[string]interface{}, dialect ToolSchemaDialect) map[string]interface{} {
+	return deepCopyOpenAIParameters(cachedToolSchemaFor(schema, dialect))
+}
+
+// toolSchemaJSONFor returns the JSON encoding of schema lowered for dialect.
+// The bytes are shared with the cache and must be treated as immutable.
+func toolSchemaJSONFor(schema map[string]interface{}, dialect ToolSchemaDialect) (json.RawMessage, error) {
+	return cachedToolSchemaJSON(cachedToolSchemaFor(schema, dialect))
+}
+
+const maxToolSchemaDialectCacheEntries = 4096
+
+type toolSchemaDialectCacheKey struct {
+	schemaPtr uintptr
+	dialect   ToolSchemaDialect
+}
+
+type toolSchemaDialectCacheEntry struct {
+	// Hold a strong reference to the immutable schema map so its identity cannot
+	// be reused for a different schema while the lowered schema remains live.
+	schema  map[string]interface{}
+	lowered map[string]interface{}
+}
+
+var toolSchemaDialectCache = struct {
+	mu      sync.Mutex
+	entries map[toolSchemaDialectCacheKey]*toolSchemaDialectCacheEntry
+	order   []toolSchemaDialectCacheKey
+}{
+	entries: make(map[toolSchemaDialectCacheKey]*toolSchemaDialectCacheEntry),
+}
+
+// cachedToolSchemaFor lowers schema once per map identity and dialect, matching
+// ToolRegistry's convention of sharing schema maps across request turns. The
+// returned map is shared: callers must copy it before handing it to code that
+// may mutate it. Its identity is stable while cached, so cachedToolSchemaJSON
+// can key on it in turn.
+func cachedToolSchemaFor(schema map[string]interface{}, dialect ToolSchemaDialect) map[string]interface{} {
+	key := toolSchemaDialectCacheKey{dialect: dialect}
+	if len(schema) > 0 {
+		key.schemaPtr = reflect.ValueOf(schema).Pointer()
+	}
+
+	toolSchemaDialectCache.mu.Lock()
+	if entry := toolSchemaDialectCache.entries[key]; entry != nil {
+		lowered := entry.lowered
+		toolSchemaDialectCache.mu.Unlock()
+		return lowered
+	}
+	toolSchemaDialectCache.mu.Unlock()
+
+	lowered := lowerToolSchema(schema, dialect)
+
+	toolSchemaDialectCache.mu.Lock()
+	defer toolSchemaDialectCache.mu.Unlock()
+	if entry := toolSchemaDialectCache.entries[key]; entry != nil {
+		return entry.lowered
+	}
+	if len(toolSchemaDialectCache.order) >= maxToolSchemaDialectCacheEntries {
+		oldest := toolSchemaDialectCache.order[0]
+		delete(toolSchemaDialectCache.entries, oldest)
+		copy(toolSchemaDialectCache.order, toolSchemaDialectCache.order[1:])
+		toolSchemaDialectCache.order = toolSchemaDialectCache.order[:len(toolSchemaDialectCache.order)-1]
+	}
+	toolSchemaDialectCache.entries[key] = &toolSchemaDialectCacheEntry{schema: schema, lowered: lowered}
+	toolSchemaDialectCache.order = append(toolSchemaDialectCache.order, key)
+	return lowered
+}
+
+func lowerToolSchema(schema map[string]interface{}, dialect ToolSchemaDialect) map[string]interface{} {
+	parsed, err := ParseToolJSONSchemaMap(schema)
+	if err != nil {
+		parsed, _ = ParseToolJSONSchemaMap(nil)
+	}
+	switch dialect {
+	case ToolSchemaOpenAI:
+		return parsed.ToOpenAIParameters(false)
+	case ToolSchemaOpenAIStrict:
+		return parsed.ToOpenAIParameters(true)
+	case ToolSchemaAnthropic:
+		params := parsed.ToOpenResponsesParameters()
+		params["type"] = "object"
+		if _, ok := params["properties"].(map[string]interface{}); !ok {
+			params["properties"] = map[string]interface{}{}
+		}
+		return params
+	case ToolSchemaGemini:
+		return normalizeSchemaForGemini(parsed.ToOpenResponsesParameters())
+	default:
+		return parsed.ToOpenResponsesParameters()
+	}
+}
+
+// ValidateToolSpec validates spec.Schema, naming the tool in the error.
+func ValidateToolSpec(spec ToolSpec) error {
+	if err := ValidateToolSchema(spec.Schema); err != nil {
+		return fmt.Errorf("tool %q: invalid input schema: %w", spec.Name, err)
+	}
+	return nil
+}
+
+// ValidateToolSchema checks a tool input schema when the tool is registered,
+// so a malformed schema fails loudly instead of being quietly coerced into
+// something different by each provider. The schema must be valid JSON Schema
+// describing an object, and must lower cleanly into every dialect. A nil or
+// empty schema is valid: the tool takes no arguments.
+func ValidateToolSchema(schema map[string]interface{}) error {
+	if len(schema) == 0 {
+		return nil
+	}
+	if err := validateJSONSchemaDocument(schema); err != nil {
+		return err
+	}
+	if typeNames, ok := normalizedJSONSchemaTypeNames(schema["type"]); ok && (len(typeNames) != 1 || typeNames[0] != "object") {
+		return fmt.Errorf("root type must be \"object\", got %v", schema["type"])
+	}
+	for _, dialect := range ToolSchemaDialects() {
+		if err := CheckToolSchemaDialect(cachedToolSchemaFor(schema, dialect), dialect); err != nil {
+			return fmt.Errorf("%s: %w", dialect, err)
+		}
+	}
+	return nil
+}
+
+func validateJSONSchemaDocument(schema map[string]interface{}) error {
+	raw, err := json.Marshal(schema)
+	if err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+	var parsed jsonschema.Schema
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return fmt.Errorf("parse: %w", err)
+	}
+	if _, err := parsed.Resolve(nil); err != nil {
+		return err
+	}
+	// Resolve accepts any string as a type name; providers do not.
+	return walkToolSchema(schema, "#", func(node map[string]interface{}) error {
+		var names []interface{}
+		switch t := node["type"].(type) {
+		case nil:
+		case string:
+			names = []interface{}{t}
+		case []interface{}:
+			names = t
+		case []string:
+			for _, name := range t {
+				names = append(names, name)
+			}
+		default:
+			return fmt.Errorf("type = %v, want a type name or a list of them", t)
+		}
+		for _, name := range names {
+			if s, ok := name.(string); !ok || !isSupportedJSONSchemaType(s) {
+				return fmt.Errorf("unknown type %v", name)
+			}
+		}
+		return nil
+	})
+}
+
+// CheckToolSchemaDialect reports whether a lowered schema is valid JSON Schema
+// that also meets the constraints its dialect's provider enforces.
+func CheckToolSchemaDialect(schema map[string]interface{}, dialect ToolSchemaDialect) error {
+	if err := validateJSONSchemaDocument(schema); err != nil {
+		return err
+	}
+	if schema["type"] != "object" {
+		return fmt.Errorf("root type = %v, want object", schema["type"])
+	}
+	return walkToolSchema(schema, "#", func(node map[string]interface{}) error {
+		switch dialect {
+		case ToolSchemaOpenAI:
+			return checkOpenAISchemaNode(node)
+		case ToolSchemaOpenAIStrict:
+			if err := checkOpenAISchemaNode(node); err != nil {
+				return err
+			}
+			return checkOpenAIStrictSchemaNode(node)
+		case ToolSchemaGemini:
+			return checkGeminiSchemaNode(node)
+		}
+		return nil
+	})
+}
+
+// walkToolSchema calls fn for schema and every subschema below it, stopping
+// at the first error, which is prefixed with the subschema's JSON pointer.
+func walkToolSchema(schema map[string]interface{}, path string, fn func(node map[string]interface{}) error) error {
+	if err := fn(schema); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for _, key := range []string{"properties", "$defs", "definitions"} {
+		children, ok := schema[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		names := make([]string, 0, len(children))
+		for name := range children {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if child, ok := children[name].(map[string]interface{}); ok {
+				if err := walkToolSchema(child, path+"/"+key+"/"+name, fn); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for _, key := range []string{"items", "additionalProperties"} {
+		if child, ok := schema[key].(map[string]interface{}); ok {
+			if err := walkToolSchema(child, path+"/"+key, fn); err != nil {
+				return err
+			}
+		}
+	}
+	for _, key := range []string{"anyOf", "oneOf", "allOf"} {
+		if branches, ok := schema[key].([]interface{}); ok {
+			for i, branch := range branches {
+				if child, ok := branch.(map[string]interface{}); ok {
+					if err := walkToolSchema(child, fmt.Sprintf("%s/%s/%d", path, key, i), fn); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func checkOpenAISchemaNode(node map[string]interface{}) error {
+	for key := range node {
+		if unsupportedOpenAISchemaKeywords[key] {
+			return fmt.Errorf("unsupported keyword %q", key)
+		}
+	}
+	return nil
+}
+
+func checkOpenAIStrictSchemaNode(node map[string]interface{}) error {
+	if _, ok := node["type"].(string); !ok {
+		if _, isUnion := node["anyOf"]; !isUnion {
+			return fmt.Errorf("type = %v, want a single type", node["type"])
+		}
+	}
+	if node["type"] != "object" {
+		return nil
+	}
+	if node["additionalProperties"] != false {
+		return fmt.Errorf("additionalProperties = %v, want false", node["additionalProperties"])
+	}
+	props, _ := node["properties"].(map[string]interface{})
+	required := map[string]bool{}
+	for _, name := range schemaRequired(node) {
+		required[name] = true
+	}
+	for name := range props {
+		if !required[name] {
+			return fmt.Errorf("property %q is not required", name)
+		}
+	}
+	return nil
+}
+
+func checkGeminiSchemaNode(node map[string]interface{}) error {
+	for _, key := range geminiUnsupportedSchemaFields {
+		if _, ok := node[key]; ok {
+			return fmt.Errorf("unsupported keyword %q", key)
+		}
+	}
+	if t, ok := node["type"]; ok {
+		name, isString := t.(string)
+		if !isString || !isSupportedJSONSchemaType(name) || name == "null" {
+			return fmt.Errorf("type = %v, want a single non-null type", t)
+		}
+		if _, hasEnum := node["enum"]; hasEnum && name != "string" {
+			return fmt.Errorf("enum on %s; Gemini only allows string enums", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func nullableNoteSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"$defs": map[string]interface{}{
+			"level": map[string]interface{}{"type": "integer", "enum": []interface{}{1, 2, 3}},
+		},
+		"properties": map[string]interface{}{
+			"path":  map[string]interface{}{"type": "string", "format": "uri"},
+			"note":  map[string]interface{}{"type": []interface{}{"string", "null"}},
+			"level": map[string]interface{}{"$ref": "#/$defs/level"},
+		},
+		"required": []interface{}{"path"},
+	}
+}
+
+func TestValidateToolSpecNamesToolAndRejectsInvalidSchemas(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema map[string]interface{}
+		want   string
+	}{
+		{"unknown type", map[string]interface{}{"type": "object", "properties": map[string]interface{}{"n": map[string]interface{}{"type": "strng"}}}, "strng"},
+		{"non-object root", map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}}, "root type"},
+		{"dangling ref", map[string]interface{}{"type": "object", "properties": map[string]interface{}{"n": map[string]interface{}{"$ref": "#/$defs/missing"}}}, "missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateToolSpec(ToolSpec{Name: "broken_tool", Schema: tt.schema})
+			if err == nil {
+				t.Fatal("ValidateToolSpec accepted an invalid schema")
+			}
+			if !strings.Contains(err.Error(), `tool "broken_tool"`) || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %q, want the tool name and %q", err, tt.want)
+			}
+		})
+	}
+
+	for _, schema := range []map[string]interface{}{nil, {}, nullableNoteSchema()} {
+		if err := ValidateToolSpec(ToolSpec{Name: "ok", Schema: schema}); err != nil {
+			t.Fatalf("ValidateToolSpec(%v) = %v, want nil", schema, err)
+		}
+	}
+}
+
+type schemaOnlyTool struct{ spec ToolSpec }
+
+func (t schemaOnlyTool) Spec() ToolSpec { return t.spec }
+func (t schemaOnlyTool) Execute(context.Context, json.RawMessage) (ToolOutput, error) {
+	return ToolOutput{}, nil
+}
+func (t schemaOnlyTool) Preview(json.RawMessage) string { return "" }
+
+func TestToolRegistryRejectsInvalidSchemaAtRegistration(t *testing.T) {
+	registry := NewToolRegistry()
+	bad := schemaOnlyTool{ToolSpec{Name: "bad", Schema: map[string]interface{}{"type": "strng"}}}
+	if err := registry.Register(bad); err == nil || !strings.Contains(err.Error(), `"bad"`) {
+		t.Fatalf("Register(bad) = %v, want an error naming the tool", err)
+	}
+	if _, ok := registry.Get("bad"); ok {
+		t.Fatal("a tool with an invalid schema must not be registered")
+	}
+	if err := registry.Register(schemaOnlyTool{ToolSpec{Name: "good", Schema: nullableNoteSchema()}}); err != nil {
+		t.Fatalf("Register(good) = %v", err)
+	}
+}
+
+func TestToolSchemaDialectsLowerNullableOptionalFields(t *testing.T) {
+	schema := nullableNoteSchema()
+
+	strict := ToolSchemaFor(schema, ToolSchemaOpenAIStrict)
+	if got := schemaRequired(strict); len(got) != 3 {
+		t.Fatalf("strict required = %v, want every property", got)
+	}
+	note := strict["properties"].(map[string]interface{})["note"].(map[string]interface{})
+	if _, ok := note["anyOf"]; !ok || note["type"] != nil {
+		t.Fatalf("strict note = %#v, want the type union as anyOf", note)
+	}
+	if _, ok := strict["$defs"]; ok {
+		t.Fatalf("strict schema kept $defs: %#v", strict)
+	}
+
+	gemini := ToolSchemaFor(schema, ToolSchemaGemini)
+	props := gemini["properties"].(map[string]interface{})
+	if note := props["note"].(map[string]interface{}); note["type"] != "string" || note["nullable"] != true {
+		t.Fatalf("gemini note = %#v, want type string and nullable", note)
+	}
+	if path := props["path"].(map[string]interface{}); path["format"] != nil {
+		t.Fatalf("gemini path kept format: %#v", path)
+	}
+	if _, ok := gemini["$defs"]; ok {
+		t.Fatalf("gemini schema kept $defs: %#v", gemini)
+	}
+	if decl := schemaToGemini(gemini).Properties["note"]; !decl.Nullable {
+		t.Fatalf("gemini declaration for note should be nullable: %#v", decl)
+	}
+
+	anthropic := ToolSchemaFor(schema, ToolSchemaAnthropic)
+	if got := schemaRequired(anthropic); len(got) != 1 || got[0] != "path" {
+		t.Fatalf("anthropic required = %v, want only path", got)
+	}
+	tools := buildAnthropicTools([]ToolSpec{{Name: "note", Schema: schema}})
+	raw, err := json.Marshal(tools[0].OfTool.InputSchema)
+	if err != nil {
+		t.Fatalf("marshal anthropic input schema: %v", err)
+	}
+	if !strings.Contains(string(raw), `"$defs"`) || !strings.Contains(string(raw), `"$ref":"#/$defs/level"`) {
+		t.Fatalf("anthropic input_schema should keep $defs for its $ref: %s", raw)
+	}
+
+	for _, dialect := range ToolSchemaDialects() {
+		if err := CheckToolSchemaDialect(ToolSchemaFor(schema, dialect), dialect); err != nil {
+			t.Fatalf("%s: %v", dialect, err)
+		}
+	}
+}
+
+func TestBuildCompatToolsSendsCanonicalSchema(t *testing.T) {
+	tools, err := buildCompatTools([]ToolSpec{{Name: "noargs"}})
+	if err != nil {
+		t.Fatalf("buildCompatTools: %v", err)
+	}
+	var params map[string]interface{}
+	if err := json.Unmarshal(tools[0].Function.Parameters, &params); err != nil {
+		t.Fatalf("parameters %s: %v", tools[0].Function.Parameters, err)
+	}
+	if params["type"] != "object" {
+		t.Fatalf("parameters = %s, want an empty object schema rather than null", tools[0].Function.Parameters)
+	}
+}
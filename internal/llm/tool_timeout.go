@@ -0,0 +1,286 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// untimedTools wait on the user or on other agents by design, so the default
+// tool timeout does not apply to them. A per-tool timeout still does.
+var untimedTools = map[string]bool{
+	"ask_user":         true,
+	"spawn_agent":      true,
+	"wait_for_jobs":    true,
+	"run_agent_script": true,
+	"hub_delegate":     true,
+}
+
+// ToolTimeoutError is the cause a tool's context is cancelled with when its
+// deadline, or the deadline for all tool calls of a turn, passes.
+type ToolTimeoutError struct {
+	Tool  string // Empty for the per-turn deadline
+	After time.Duration
+}
+
+func (e *ToolTimeoutError) Error() string {
+	if e.Tool == "" {
+		return fmt.Sprintf("tool calls for this turn timed out after %s", formatTimeout(e.After))
+	}
+	return fmt.Sprintf("tool %s timed out after %s", e.Tool, formatTimeout(e.After))
+}
+
+// formatTimeout renders whole minutes as "5m", other times of a second or
+// more in seconds, and shorter ones as Go durations.
+func formatTimeout(d time.Duration) string {
+	if d < time.Second {
+		return d.String()
+	}
+	if d >= time.Minute && d%time.Minute == 0 {
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	}
+	return fmt.Sprintf("%ds", int(d.Round(time.Second)/time.Second))
+}
+
+// toolTimeoutCause returns the timeout ctx was cancelled for, if any.
+func toolTimeoutCause(ctx context.Context) (*ToolTimeoutError, bool) {
+	var timeoutErr *ToolTimeoutError
+	if errors.As(context.Cause(ctx), &timeoutErr) {
+		return timeoutErr, true
+	}
+	return nil, false
+}
+
+// defaultToolAbandonGrace is how long a timed-out tool gets to notice its
+// cancelled context before the turn moves on without it.
+const defaultToolAbandonGrace = 5 * time.Second
+
+// timedOutToolOutput is the result recorded for a tool stopped by a deadline,
+// worded so the model can change course. abandoned marks a tool that ignored
+// cancellation and may still be running, so its side effects can land later.
+func timedOutToolOutput(err *ToolTimeoutError, abandoned bool) ToolOutput {
+	content := fmt.Sprintf("Error: %s and was stopped. Try a narrower or faster approach.", err.Error())
+	if abandoned {
+		content = fmt.Sprintf("Error: %s and did not stop when cancelled; it may still be running and changing files. Check its effects before retrying.", err.Error())
+	}
+	return ToolOutput{
+		Content:  content,
+		TimedOut: true,
+	}
+}
+
+// deadlineClock tracks the running tool deadlines of one engine so they
+// can all be paused while the user answers an approval prompt: time spent
+// deciding should not count against a tool.
+type deadlineClock struct {
+	mu     sync.Mutex
+	paused int
+	active map[*pausableDeadline]struct{}
+}
+
+type pausableDeadline struct {
+	remaining time.Duration
+	started   time.Time
+	timer     *time.Timer
+	fire      func()
+}
+
+func (d *pausableDeadline) run() {
+	d.started = time.Now()
+	d.timer = time.AfterFunc(d.remaining, d.fire)
+}
+
+// start calls fire once after the given running time. The returned func
+// stops the deadline.
+func newDeadlineClock() *deadlineClock {
+	return &deadlineClock{active: make(map[*pausableDeadline]struct{})}
+}
+
+func (c *deadlineClock) start(after time.Duration, fire func()) (stop func()) {
+	d := &pausableDeadline{remaining: after, fire: fire}
+	c.mu.Lock()
+	c.active[d] = struct{}{}
+	if c.paused == 0 {
+		d.run()
+	}
+	c.mu.Unlock()
+	return func() {
+		c.mu.Lock()
+		delete(c.active, d)
+		if d.timer != nil {
+			d.timer.Stop()
+		}
+		c.mu.Unlock()
+	}
+}
+
+func (c *deadlineClock) pause() (resume func()) {
+	c.mu.Lock()
+	c.paused++
+	if c.paused == 1 {
+		for d := range c.active {
+			if d.timer != nil && d.timer.Stop() {
+				d.remaining -= time.Since(d.started)
+				d.timer = nil
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			c.paused--
+			if c.paused == 0 {
+				for d := range c.active {
+					if d.timer == nil {
+						d.run()
+					}
+				}
+			}
+			c.mu.Unlock()
+		})
+	}
+}
+
+// PauseToolTimeouts stops the clock on this engine's running tool deadlines
+// until the returned func is called. Approval prompts use it while they wait
+// on the user; other engines in the process keep their own clocks.
+func (e *Engine) PauseToolTimeouts() (resume func()) {
+	if e == nil || e.toolClock == nil {
+		return func() {}
+	}
+	return e.toolClock.pause()
+}
+
+func (e *Engine) deadlines() *deadlineClock {
+	if e.toolClock == nil {
+		// Engines built without NewEngine still get working deadlines; they
+		// just cannot be paused.
+		return newDeadlineClock()
+	}
+	return e.toolClock
+}
+
+// SetToolTimeouts sets how long a tool call may run before it is stopped
+// and a timeout result is recorded. defaultTimeout applies to every tool
+// without an entry in perTool, except tools that wait on the user or other
+// agents; 0 disables it. turnTimeout caps all the tool calls of one turn
+// together. Time spent in approval prompts does not count.
+func (e *Engine) SetToolTimeouts(defaultTimeout time.Duration, perTool map[string]time.Duration, turnTimeout time.Duration) {
+	copied := make(map[string]time.Duration, len(perTool))
+	for name, d := range perTool {
+		copied[name] = d
+	}
+	e.callbackMu.Lock()
+	e.toolTimeout = defaultTimeout
+	e.toolTimeouts = copied
+	e.turnToolTimeout = turnTimeout
+	e.callbackMu.Unlock()
+}
+
+func (e *Engine) abandonGrace() time.Duration {
+	if e.toolAbandonGrace > 0 {
+		return e.toolAbandonGrace
+	}
+	return defaultToolAbandonGrace
+}
+
+func (e *Engine) toolTimeoutFor(name string) time.Duration {
+	e.callbackMu.RLock()
+	defer e.callbackMu.RUnlock()
+	if d, ok := e.toolTimeouts[name]; ok {
+		return d
+	}
+	if untimedTools[name] {
+		return 0
+	}
+	return e.toolTimeout
+}
+
+// withTurnToolDeadline bounds the tool calls of one turn when a turn
+// timeout is set.
+func (e *Engine) withTurnToolDeadline(ctx context.Context) (context.Context, func()) {
+	e.callbackMu.RLock()
+	turnTimeout := e.turnToolTimeout
+	e.callbackMu.RUnlock()
+	if turnTimeout <= 0 {
+		return ctx, func() {}
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	stop := e.deadlines().start(turnTimeout, func() { cancel(&ToolTimeoutError{After: turnTimeout}) })
+	return ctx, func() {
+		stop()
+		cancel(nil)
+	}
+}
+
+type toolRun struct {
+	output ToolOutput
+	err    error
+	panic  any
+}
+
+// runTool executes tool under its deadline. When a deadline passes, the
+// tool's context is cancelled, which kills a shell command's process group,
+// and the tool gets a short grace period to return. A tool that ignores
+// cancellation past that is abandoned: the timeout result says so and the
+// abandonment is logged. Other cancellations still wait for the tool.
+func (e *Engine) runTool(ctx context.Context, tool Tool, call ToolCall) (ToolOutput, error) {
+	if timeoutErr, ok := toolTimeoutCause(ctx); ok {
+		return timedOutToolOutput(timeoutErr, false), nil
+	}
+
+	toolCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	if timeout := e.toolTimeoutFor(call.Name); timeout > 0 {
+		stop := e.deadlines().start(timeout, func() { cancel(&ToolTimeoutError{Tool: call.Name, After: timeout}) })
+		defer stop()
+	}
+
+	done := make(chan toolRun, 1)
+	go func() {
+		var run toolRun
+		defer func() {
+			if r := recover(); r != nil {
+				run.panic = r
+			}
+			done <- run
+		}()
+		run.output, run.err = tool.Execute(toolCtx, call.Arguments)
+	}()
+
+	var run toolRun
+	select {
+	case run = <-done:
+	case <-toolCtx.Done():
+		timeoutErr, ok := toolTimeoutCause(toolCtx)
+		if !ok {
+			run = <-done
+			break
+		}
+		grace := time.NewTimer(e.abandonGrace())
+		defer grace.Stop()
+		select {
+		case run = <-done:
+			if run.panic != nil {
+				panic(run.panic)
+			}
+			return timedOutToolOutput(timeoutErr, false), nil
+		case <-grace.C:
+			slog.Warn("abandoning tool that ignored its timeout", "tool", call.Name, "call_id", call.ID, "grace", e.abandonGrace())
+			return timedOutToolOutput(timeoutErr, true), nil
+		}
+	}
+	if run.panic != nil {
+		panic(run.panic)
+	}
+	if timeoutErr, ok := toolTimeoutCause(toolCtx); ok && run.err != nil {
+		return timedOutToolOutput(timeoutErr, false), nil
+	}
+	return run.output, run.err
+}
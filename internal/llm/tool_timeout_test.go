@@ -0,0 +1,209 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stuckTool ignores cancellation, like a tool blocked on a read with no
+// deadline.
+type stuckTool struct {
+	release chan struct{}
+}
+
+func (t *stuckTool) Spec() ToolSpec {
+	return ToolSpec{Name: "stuck_tool", Description: "never returns", Schema: map[string]any{"type": "object"}}
+}
+
+func (t *stuckTool) Execute(ctx context.Context, args json.RawMessage) (ToolOutput, error) {
+	<-t.release
+	return TextOutput("finally"), nil
+}
+
+func (t *stuckTool) Preview(args json.RawMessage) string {
+	return ""
+}
+
+// streamToolThenAnswer runs a turn where the model calls the given tools,
+// then answers with the tool results it saw.
+func streamToolThenAnswer(t *testing.T, engine *Engine, names ...string) string {
+	t.Helper()
+	var seen atomic.Value
+	provider := &fakeProvider{
+		script: func(call int, req Request) []Event {
+			if call == 0 {
+				events := make([]Event, 0, len(names)+1)
+				for i, name := range names {
+					events = append(events, Event{Type: EventToolCall, Tool: &ToolCall{ID: fmt.Sprintf("call-%d", i), Name: name, Arguments: json.RawMessage(`{}`)}})
+				}
+				return append(events, Event{Type: EventDone})
+			}
+			var results []string
+			for _, msg := range req.Messages {
+				for _, part := range msg.Parts {
+					if part.ToolResult != nil {
+						results = append(results, part.ToolResult.Content)
+					}
+				}
+			}
+			seen.Store(strings.Join(results, "\n"))
+			return []Event{{Type: EventTextDelta, Text: "ok"}, {Type: EventDone}}
+		},
+	}
+	engine.provider = provider
+
+	stream, err := engine.Stream(context.Background(), Request{
+		Messages:          []Message{UserText("go")},
+		Tools:             []ToolSpec{{Name: names[0], Schema: map[string]any{"type": "object"}}},
+		ParallelToolCalls: true,
+	})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	defer stream.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		for {
+			event, err := stream.Recv()
+			if err == io.EOF {
+				done <- nil
+				return
+			}
+			if err != nil {
+				done <- err
+				return
+			}
+			if event.Type == EventError {
+				done <- event.Err
+				return
+			}
+		}
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("stream error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the turn did not continue after the tool timed out")
+	}
+	results, _ := seen.Load().(string)
+	return results
+}
+
+func TestEngineToolTimeoutRecordsResultAndContinues(t *testing.T) {
+	t.Parallel()
+
+	tool := &stuckTool{release: make(chan struct{})}
+	defer close(tool.release)
+	registry := NewToolRegistry()
+	registry.Register(tool)
+	engine := NewEngine(nil, registry)
+	engine.toolAbandonGrace = 10 * time.Millisecond
+	engine.SetToolTimeouts(time.Hour, map[string]time.Duration{"stuck_tool": 50 * time.Millisecond}, 0)
+
+	results := streamToolThenAnswer(t, engine, "stuck_tool")
+	if !strings.Contains(results, "tool stuck_tool timed out after 50ms") {
+		t.Fatalf("tool results seen by the model = %q, want a timeout result", results)
+	}
+	if !strings.Contains(results, "may still be running") {
+		t.Fatalf("tool results seen by the model = %q, want the abandoned tool called out", results)
+	}
+}
+
+func TestEngineTurnToolTimeoutStopsRemainingCalls(t *testing.T) {
+	t.Parallel()
+
+	tool := &stuckTool{release: make(chan struct{})}
+	defer close(tool.release)
+	registry := NewToolRegistry()
+	registry.Register(tool)
+	engine := NewEngine(nil, registry)
+	engine.toolAbandonGrace = 10 * time.Millisecond
+	engine.SetParallelTools(1, nil)
+	engine.SetToolTimeouts(0, nil, 50*time.Millisecond)
+
+	results := streamToolThenAnswer(t, engine, "stuck_tool", "stuck_tool")
+	if got := strings.Count(results, "tool calls for this turn timed out"); got != 2 {
+		t.Fatalf("tool results seen by the model = %q, want both calls timed out", results)
+	}
+}
+
+func TestEngineToolTimeoutSkipsUntimedTools(t *testing.T) {
+	engine := NewEngine(&fakeProvider{}, nil)
+	engine.SetToolTimeouts(time.Minute, map[string]time.Duration{"spawn_agent": time.Hour}, 0)
+	if got := engine.toolTimeoutFor("read_file"); got != time.Minute {
+		t.Fatalf("read_file timeout = %v, want the default", got)
+	}
+	if got := engine.toolTimeoutFor("ask_user"); got != 0 {
+		t.Fatalf("ask_user timeout = %v, want none", got)
+	}
+	if got := engine.toolTimeoutFor("spawn_agent"); got != time.Hour {
+		t.Fatalf("spawn_agent timeout = %v, want the per-tool override", got)
+	}
+}
+
+func TestPauseToolTimeoutsHoldsDeadlines(t *testing.T) {
+	engine := NewEngine(&fakeProvider{}, nil)
+	other := NewEngine(&fakeProvider{}, nil)
+	fired := make(chan struct{})
+	stop := engine.toolClock.start(30*time.Millisecond, func() { close(fired) })
+	defer stop()
+	otherFired := make(chan struct{})
+	stopOther := other.toolClock.start(30*time.Millisecond, func() { close(otherFired) })
+	defer stopOther()
+
+	resume := engine.PauseToolTimeouts()
+	select {
+	case <-otherFired:
+	case <-time.After(time.Second):
+		t.Fatal("pausing one engine held another engine's deadline")
+	}
+	select {
+	case <-fired:
+		t.Fatal("deadline fired while paused")
+	case <-time.After(80 * time.Millisecond):
+	}
+	resume()
+	select {
+	case <-fired:
+	case <-time.After(time.Second):
+		t.Fatal("deadline did not fire after resuming")
+	}
+}
+
+// cooperativeTool returns promptly once its context is cancelled.
+type cooperativeTool struct{}
+
+func (cooperativeTool) Spec() ToolSpec {
+	return ToolSpec{Name: "cooperative_tool", Description: "waits for cancellation", Schema: map[string]any{"type": "object"}}
+}
+
+func (cooperativeTool) Execute(ctx context.Context, args json.RawMessage) (ToolOutput, error) {
+	<-ctx.Done()
+	return ToolOutput{}, ctx.Err()
+}
+
+func (cooperativeTool) Preview(args json.RawMessage) string {
+	return ""
+}
+
+func TestEngineToolTimeoutWaitsForCooperativeTool(t *testing.T) {
+	engine := NewEngine(&fakeProvider{}, nil)
+	engine.SetToolTimeouts(20*time.Millisecond, nil, 0)
+
+	output, err := engine.runTool(context.Background(), cooperativeTool{}, ToolCall{ID: "call-1", Name: "cooperative_tool"})
+	if err != nil {
+		t.Fatalf("runTool: %v", err)
+	}
+	if !output.TimedOut || strings.Contains(output.Content, "may still be running") {
+		t.Fatalf("output = %+v, want a plain timeout result", output)
+	}
+}
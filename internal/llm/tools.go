@@ -60,13 +60,20 @@ func NewToolRegistry() *ToolRegistry {
 	return &ToolRegistry{tools: make(map[string]Tool), specsDirty: true}
 }
 
-func (r *ToolRegistry) Register(tool Tool) {
+// Register adds tool, replacing any tool of the same name. A tool whose input
+// schema fails ValidateToolSpec is rejected, so a bad schema surfaces here
+// rather than as a provider-specific request failure later.
+func (r *ToolRegistry) Register(tool Tool) error {
 	spec := tool.Spec()
+	if err := ValidateToolSpec(spec); err != nil {
+		return err
+	}
 
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.tools[spec.Name] = tool
 	r.specsDirty = true
+	return nil
 }
 
 func (r *ToolRegistry) Get(name string) (Tool, bool) {
@@ -227,7 +234,8 @@ Rules:
 2. Context lines (space prefix) anchor the position - must match file exactly
 3. Use -... ONLY when replacing 10+ lines; for small changes list all - lines explicitly
 4. After -... always include the closing line (e.g., -}) as the end anchor
-5. Multiple files: use separate --- +++ blocks for each file`
+5. Multiple files: use separate --- +++ blocks for each file
+6. New file: --- /dev/null then +++ path, with every line as +. Delete a file: --- path then +++ /dev/null`
 
 // UnifiedDiffToolSpec returns the tool spec for unified diff edits.
 func UnifiedDiffToolSpec() ToolSpec {
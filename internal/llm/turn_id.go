@@ -0,0 +1,136 @@
+package llm
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+)
+
+type turnIDContextKey struct{}
+
+// crockfordAlphabet is the ULID base32 alphabet (no I, L, O or U).
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var (
+	turnIDMu       sync.Mutex
+	lastTurnIDTime int64
+	lastTurnIDRand [10]byte
+)
+
+// NewTurnID returns a new ULID: 26 Crockford base32 characters encoding a
+// millisecond timestamp and 80 random bits. IDs sort by creation time, and
+// IDs made in the same millisecond stay ordered by incrementing the random
+// part, so a turn ID doubles as a coarse timestamp when grepping logs.
+func NewTurnID() string {
+	return newTurnID(time.Now())
+}
+
+func newTurnID(now time.Time) string {
+	ms := now.UnixMilli()
+
+	turnIDMu.Lock()
+	entropy := lastTurnIDRand
+	if ms == lastTurnIDTime {
+		for i := len(entropy) - 1; i >= 0; i-- {
+			entropy[i]++
+			if entropy[i] != 0 {
+				break
+			}
+		}
+	} else {
+		_, _ = rand.Read(entropy[:])
+	}
+	lastTurnIDTime, lastTurnIDRand = ms, entropy
+	turnIDMu.Unlock()
+
+	var raw [16]byte
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(ms))
+	copy(raw[:6], ts[2:])
+	copy(raw[6:], entropy[:])
+	return encodeULID(raw)
+}
+
+// encodeULID renders 128 bits as 26 base32 characters, most significant
+// first; the leading character carries only the top 3 bits.
+func encodeULID(raw [16]byte) string {
+	hi := binary.BigEndian.Uint64(raw[:8])
+	lo := binary.BigEndian.Uint64(raw[8:])
+	out := make([]byte, 26)
+	for i := 25; i >= 0; i-- {
+		out[i] = crockfordAlphabet[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out)
+}
+
+// ContextWithTurnID returns a context carrying the engine turn ID. Session
+// stores stamp it on rows added under that context, so persisted messages
+// can be matched to their debug-log entries.
+func ContextWithTurnID(ctx context.Context, turnID string) context.Context {
+	return context.WithValue(ctx, turnIDContextKey{}, turnID)
+}
+
+// TurnIDFromContext returns the turn ID set by ContextWithTurnID, or "".
+func TurnIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(turnIDContextKey{}).(string)
+	return id
+}
+
+// TurnError tags a stream failure with the turn it happened in. Error()
+// is the wrapped message unchanged so serve responses and logs keep their
+// wording; FormatTurnError adds the ID where the error is shown to users.
+type TurnError struct {
+	TurnID string
+	Err    error
+}
+
+func (e *TurnError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TurnError) Unwrap() error {
+	return e.Err
+}
+
+// withTurnID wraps err in a TurnError. Cancellation is left alone: it is the
+// user's own action, and callers compare it directly.
+func withTurnID(turnID string, err error) error {
+	if err == nil || turnID == "" || errors.Is(err, context.Canceled) {
+		return err
+	}
+	var turnErr *TurnError
+	if errors.As(err, &turnErr) {
+		return err
+	}
+	return &TurnError{TurnID: turnID, Err: err}
+}
+
+// FormatTurnError renders err for display, prefixed with its turn ID when it
+// has one ("turn 01J9X…: request failed"), so the message leads straight to
+// the matching `debug-log show --turn` output.
+func FormatTurnError(err error) string {
+	if err == nil {
+		return ""
+	}
+	if turnID := TurnIDFromError(err); turnID != "" {
+		return "turn " + turnID + ": " + err.Error()
+	}
+	return err.Error()
+}
+
+// TurnIDFromError returns the turn ID attached to err, or "".
+func TurnIDFromError(err error) string {
+	var turnErr *TurnError
+	if errors.As(err, &turnErr) {
+		return turnErr.TurnID
+	}
+	return ""
+}
@@ -0,0 +1,64 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewTurnIDIsSortableULID(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	first := newTurnID(now)
+	second := newTurnID(now)
+	later := newTurnID(now.Add(time.Millisecond))
+
+	for _, id := range []string{first, second, later} {
+		if len(id) != 26 {
+			t.Fatalf("turn ID %q has length %d, want 26", id, len(id))
+		}
+		for _, c := range id {
+			if !strings.ContainsRune(crockfordAlphabet, c) {
+				t.Fatalf("turn ID %q contains %q outside the ULID alphabet", id, c)
+			}
+		}
+	}
+	if first[:10] != second[:10] {
+		t.Errorf("same-millisecond IDs have different timestamps: %s, %s", first, second)
+	}
+	if !(first < second && second < later) {
+		t.Errorf("turn IDs are not ordered: %s, %s, %s", first, second, later)
+	}
+	if got := encodeULID([16]byte{15: 1}); got != "00000000000000000000000001" {
+		t.Errorf("encodeULID(1) = %q", got)
+	}
+}
+
+func TestWithTurnID(t *testing.T) {
+	base := errors.New("request failed")
+	err := withTurnID("01J9XQ3T5B8N2K7M4C6D0E1F2G", base)
+	if got, want := err.Error(), "request failed"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if got, want := FormatTurnError(err), "turn 01J9XQ3T5B8N2K7M4C6D0E1F2G: request failed"; got != want {
+		t.Errorf("FormatTurnError = %q, want %q", got, want)
+	}
+	if got := FormatTurnError(base); got != "request failed" {
+		t.Errorf("FormatTurnError without turn = %q", got)
+	}
+	if !errors.Is(err, base) || TurnIDFromError(err) != "01J9XQ3T5B8N2K7M4C6D0E1F2G" {
+		t.Errorf("wrapped error lost its cause or turn ID: %v", err)
+	}
+	if again := withTurnID("other", err); again != err {
+		t.Errorf("re-wrapping changed the error: %v", again)
+	}
+	if got := withTurnID("01J9XQ3T5B8N2K7M4C6D0E1F2G", context.Canceled); got != context.Canceled {
+		t.Errorf("cancellation was wrapped: %v", got)
+	}
+
+	ctx := ContextWithTurnID(context.Background(), "01J9XQ3T5B8N2K7M4C6D0E1F2G")
+	if got := TurnIDFromContext(ctx); got != "01J9XQ3T5B8N2K7M4C6D0E1F2G" {
+		t.Errorf("TurnIDFromContext = %q", got)
+	}
+}
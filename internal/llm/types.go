@@ -102,6 +102,7 @@ type Capabilities struct {
 	SupportsToolChoice bool // Provider supports tool_choice to force specific tool use
 	ManagesOwnContext  bool // Provider manages its own context window (skip compaction)
 	InlineToolLoop     bool // Provider completes its MCP/tool loop inside one Stream invocation
+	StructuredOutput   bool // Provider enforces Request.ResponseFormat natively
 }
 
 // Stream yields events until io.EOF.
@@ -147,8 +148,11 @@ func (o ResponsesOptions) IsZero() bool {
 
 // Request represents a single model turn.
 type Request struct {
-	Model      string
-	SessionID  string // Optional session ID for provider-side continuity/caching hints
+	Model     string
+	SessionID string // Optional session ID for provider-side continuity/caching hints
+	// TurnID identifies one Engine.Stream call across debug-log entries,
+	// session rows and serve events. Engine.Stream fills it in when empty.
+	TurnID     string
 	WorkingDir string // Optional working directory for local subprocess providers
 	// Ephemeral marks one-shot internal requests (title generation, summaries,
 	// vision helpers) that must not participate in provider-side conversation/session
@@ -161,16 +165,20 @@ type Request struct {
 	ToolChoice               ToolChoice
 	LastTurnToolChoice       *ToolChoice // If set, force this tool choice on the last agentic turn
 	ParallelToolCalls        bool
-	// AllowedToolsPresent applies an internal, request-scoped execution filter.
-	// It is consumed by runtimes before calling Engine.Stream and is not provider metadata.
-	// A present empty AllowedTools slice intentionally blocks every callable tool.
+	// AllowedToolsPresent applies an internal, request-scoped tool filter (see
+	// ToolFilter). Engine.Stream hides filtered tools from the model and rejects
+	// calls to them; it is not provider metadata. A present empty AllowedTools
+	// slice intentionally blocks every callable tool. DeniedTools wins over
+	// AllowedTools. Both also restrict sub-agents started by this request.
 	AllowedTools            []string
 	AllowedToolsPresent     bool
+	DeniedTools             []string
 	Search                  bool
 	ForceExternalSearch     bool // If true, use external search even if provider supports native
 	DisableExternalWebFetch bool // If true, do not inject external read_url even when provider lacks native fetch
 	ReasoningEffort         string
 	Responses               *ResponsesOptions // Advanced Responses API controls; nil uses provider defaults.
+	ResponseFormat          *ResponseFormat   // Optional JSON reply format; nil means free text.
 	MaxOutputTokens         int
 	Temperature             float32
 	TemperatureSet          bool // If true, Temperature was explicitly provided, including zero
@@ -225,6 +233,7 @@ type Message struct {
 	AssistantSegmentOrdinal int    `json:",omitempty"` // Response-scoped assistant segment identity; -1 when not applicable.
 	SegmentStartSequence    int64  `json:",omitempty"` // First response event sequence for this assistant segment, when known.
 	SegmentEndSequence      int64  `json:",omitempty"` // Last response event sequence covered by this persisted segment, when known.
+	Pinned                  bool   `json:",omitempty"` // User-pinned: compaction replays it verbatim instead of summarizing it.
 }
 
 // ReasoningKind classifies provider reasoning/thinking payloads for safe display.
@@ -403,6 +412,7 @@ type ToolCall struct {
 // Diff operation identifiers for structured diff rendering.
 const (
 	DiffOperationCreate = "create"
+	DiffOperationDelete = "delete"
 )
 
 // DiffData represents structured diff information from edit/write tools.
@@ -486,6 +496,7 @@ type ToolResult struct {
 	Images       []string          `json:"images,omitempty"` // Image paths
 	IsError      bool              // True if this result represents a tool execution error
 	Caller       string            `json:",omitempty"` // PTC caller provenance.
+	DurationMs   int64             `json:",omitempty"` // How long the tool ran; recorded for tool analytics
 	ThoughtSig   []byte            // Gemini 3 thought signature (passed through from ToolCall)
 }
 
@@ -507,7 +518,7 @@ const (
 	EventAttemptDiscard EventType = "attempt_discard" // Discard provisional assistant output from the current streamed attempt
 	EventInterjection   EventType = "interjection"    // User interjected a message mid-stream
 	EventModelSwitch    EventType = "model_switch"    // Request model changed at a provider-turn boundary
-	EventImageGenerated EventType = "image_generated" // Emitted when a built-in image_generation tool returns an image
+	EventImageGenerated EventType = "image_generated" // Emitted when the model returns an image (built-in image_generation tool or image output)
 	EventProviderReplay EventType = "provider_replay" // Internal-only opaque Responses output item.
 )
 
@@ -555,6 +566,7 @@ type Event struct {
 	RetryAttempt     int
 	RetryMaxAttempts int
 	RetryWaitSecs    float64
+	RetryReason      ErrorClass // class of the error being retried, when known
 	// ToolResponse is set when a provider needs synchronous bridged tool execution.
 	// The engine will execute the tool and send the result back on this channel.
 	ToolResponse   chan<- ToolExecutionResponse
@@ -563,6 +575,8 @@ type Event struct {
 	ImageData     []byte // Raw decoded image bytes
 	ImageMimeType string // e.g. "image/png"
 	RevisedPrompt string // Model's revised prompt, if any
+	ImageURL      string // Set by providers that return a URL instead of bytes; the engine downloads it
+	ImagePath     string // Local file the engine saved the image to; empty if it could not be saved
 }
 
 // Usage captures token usage if available.
@@ -652,6 +666,7 @@ type ModelInfo struct {
 	ReasoningEfforts       []string           `json:"reasoning_efforts,omitempty"`
 	DefaultReasoningEffort string             `json:"default_reasoning_effort,omitempty"`
 	ReasoningModes         []string           `json:"reasoning_modes,omitempty"`
+	PremiumMultiplier      float64            `json:"premium_multiplier,omitempty"` // Copilot premium requests per call (0 = included in plan)
 }
 
 func SystemText(text string) Message {
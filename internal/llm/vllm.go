@@ -16,6 +16,7 @@ func NewVLLMProviderFull(baseURL, chatURL, apiKey, model, name string) *VLLMProv
 	p := NewOpenAICompatProviderFull(baseURL, chatURL, apiKey, actualModel, name, nil)
 	p.effort = effort
 	p.vllmThinking = true
+	p.structuredOutput = true // vLLM enforces response_format with guided decoding
 	return &VLLMProvider{OpenAICompatProvider: p}
 }
 
@@ -3,7 +3,6 @@ package mcp
 import (
 	"context"
 	"encoding/base64"
-	"errors"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -11,12 +10,13 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
-	"syscall"
 	"testing"
 	"time"
 
 	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/procutil"
+	"github.com/samsaffron/term-llm/internal/testutil"
 )
 
 type roundTripFunc func(*http.Request) (*http.Response, error)
@@ -268,7 +268,7 @@ func TestCreateStdioTransport_ConfiguresProcessGroupCancellation(t *testing.T) {
 	if ct.Command.Cancel == nil {
 		t.Fatalf("expected subprocess cancel hook to be configured")
 	}
-	if ct.Command.SysProcAttr == nil || !ct.Command.SysProcAttr.Setpgid {
+	if !procutil.InOwnProcessGroup(ct.Command) {
 		t.Fatalf("expected subprocess to run in its own process group")
 	}
 	if ct.Command.WaitDelay != time.Second {
@@ -344,7 +344,7 @@ func waitForMCPProcessExit(t *testing.T, pid int) {
 
 	deadline := time.Now().Add(5 * time.Second)
 	for time.Now().Before(deadline) {
-		if mcpProcessHasExited(pid) {
+		if testutil.ProcessHasExited(pid) {
 			return
 		}
 		time.Sleep(20 * time.Millisecond)
@@ -354,39 +354,12 @@ func waitForMCPProcessExit(t *testing.T, pid int) {
 }
 
 func killProcessIfRunning(pid int) {
-	if pid <= 0 || mcpProcessHasExited(pid) {
+	if pid <= 0 || testutil.ProcessHasExited(pid) {
 		return
 	}
-	_ = syscall.Kill(pid, syscall.SIGKILL)
-}
-
-func mcpProcessHasExited(pid int) bool {
-	err := syscall.Kill(pid, 0)
-	if err != nil {
-		return errors.Is(err, syscall.ESRCH)
-	}
-	if runtime.GOOS == "linux" {
-		state, ok := linuxMCPProcState(pid)
-		return ok && state == 'Z'
-	}
-	return false
-}
-
-func linuxMCPProcState(pid int) (byte, bool) {
-	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
-	if err != nil {
-		return 0, false
-	}
-	return mcpProcStatState(data)
-}
-
-func mcpProcStatState(data []byte) (byte, bool) {
-	stat := string(data)
-	end := strings.LastIndex(stat, ")")
-	if end == -1 || end+2 >= len(stat) {
-		return 0, false
+	if proc, err := os.FindProcess(pid); err == nil {
+		_ = proc.Kill()
 	}
-	return stat[end+2], true
 }
 
 func TestFormatContent(t *testing.T) {
@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+
+	"github.com/samsaffron/term-llm/internal/pathutil"
 )
 
 // Config represents the mcp.json configuration file.
@@ -87,15 +89,11 @@ func (c *ServerConfig) Validate() error {
 
 // DefaultConfigPath returns the default path for mcp.json.
 func DefaultConfigPath() (string, error) {
-	configDir := os.Getenv("XDG_CONFIG_HOME")
-	if configDir == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return "", err
-		}
-		configDir = filepath.Join(home, ".config")
+	configDir, err := pathutil.ConfigDir()
+	if err != nil {
+		return "", err
 	}
-	return filepath.Join(configDir, "term-llm", "mcp.json"), nil
+	return filepath.Join(configDir, "mcp.json"), nil
 }
 
 // LoadConfig loads the MCP configuration from the default path.
@@ -3,6 +3,7 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 
 	"github.com/samsaffron/term-llm/internal/llm"
 )
@@ -41,12 +42,16 @@ func (t *MCPTool) Execute(ctx context.Context, args json.RawMessage) (llm.ToolOu
 }
 
 // RegisterMCPTools registers all MCP tools from the manager into the tool registry.
-func RegisterMCPTools(manager *Manager, registry *llm.ToolRegistry) {
-	tools := manager.AllTools()
-	for _, spec := range tools {
-		tool := NewMCPTool(manager, spec)
-		registry.Register(tool)
+// Tools with an invalid input schema are skipped; their errors are returned
+// joined once every valid tool is registered.
+func RegisterMCPTools(manager *Manager, registry *llm.ToolRegistry) error {
+	var errs []error
+	for _, spec := range manager.AllTools() {
+		if err := registry.Register(NewMCPTool(manager, spec)); err != nil {
+			errs = append(errs, err)
+		}
 	}
+	return errors.Join(errs...)
 }
 
 // GetMCPToolSpecs returns LLM tool specs for all running MCP tools.
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+
+	"github.com/samsaffron/term-llm/internal/pathutil"
 )
 
 // toolCache is the on-disk format for cached tool lists per server.
@@ -12,15 +14,11 @@ type toolCache struct {
 }
 
 func toolCachePath() (string, error) {
-	configDir := os.Getenv("XDG_CONFIG_HOME")
-	if configDir == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return "", err
-		}
-		configDir = filepath.Join(home, ".config")
+	configDir, err := pathutil.ConfigDir()
+	if err != nil {
+		return "", err
 	}
-	return filepath.Join(configDir, "term-llm", "mcp-tools-cache.json"), nil
+	return filepath.Join(configDir, "mcp-tools-cache.json"), nil
 }
 
 // CacheTools writes the tool list for a server to the cache file.
@@ -0,0 +1,27 @@
+package metrics
+
+// Default is the process-wide registry served by term-llm serve's /metrics.
+var Default = NewRegistry()
+
+var (
+	LLMRequests = Default.NewCounterVec("term_llm_llm_requests_total",
+		"LLM requests started, by provider and model.", "provider", "model")
+	InputTokens = Default.NewCounterVec("term_llm_input_tokens_total",
+		"Input tokens reported by providers, by provider and model.", "provider", "model")
+	OutputTokens = Default.NewCounterVec("term_llm_output_tokens_total",
+		"Output tokens reported by providers, by provider and model.", "provider", "model")
+	ToolExecutions = Default.NewCounterVec("term_llm_tool_executions_total",
+		"Tool executions, by tool name and outcome (success or error).", "tool", "outcome")
+	ActiveJobRuns = Default.NewGauge("term_llm_active_job_runs",
+		"Job runs currently executing.")
+	WebSocketConnections = Default.NewGauge("term_llm_websocket_connections",
+		"Open WebSocket connections, inbound and outbound (provider streams and hub links).")
+)
+
+// ToolOutcome returns the outcome label for a tool execution.
+func ToolOutcome(failed bool) string {
+	if failed {
+		return "error"
+	}
+	return "success"
+}
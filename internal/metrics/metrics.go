@@ -0,0 +1,186 @@
+// Package metrics holds process-wide counters for term-llm serve and
+// renders them in the Prometheus text exposition format. Everything is an
+// atomic so instrumented hot paths (streams, tool calls) stay cheap, and
+// the package has no dependencies so the engine and providers can record
+// into it directly.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Counter is a monotonically increasing value.
+type Counter struct {
+	v atomic.Int64
+}
+
+// Inc adds one.
+func (c *Counter) Inc() { c.v.Add(1) }
+
+// Add adds n; negative values are ignored.
+func (c *Counter) Add(n int64) {
+	if n > 0 {
+		c.v.Add(n)
+	}
+}
+
+// Value returns the current count.
+func (c *Counter) Value() int64 { return c.v.Load() }
+
+// Gauge is a value that can go up and down.
+type Gauge struct {
+	v atomic.Int64
+}
+
+func (g *Gauge) Inc()         { g.v.Add(1) }
+func (g *Gauge) Dec()         { g.v.Add(-1) }
+func (g *Gauge) Set(n int64)  { g.v.Store(n) }
+func (g *Gauge) Value() int64 { return g.v.Load() }
+
+// CounterVec is a set of counters partitioned by label values.
+type CounterVec struct {
+	labels []string
+	m      sync.Map // joined label values → *Counter
+}
+
+// With returns the counter for the given label values, in the order the
+// labels were declared. Missing values are recorded as empty strings.
+func (v *CounterVec) With(values ...string) *Counter {
+	key := strings.Join(v.normalize(values), "\x00")
+	if c, ok := v.m.Load(key); ok {
+		return c.(*Counter)
+	}
+	c, _ := v.m.LoadOrStore(key, &Counter{})
+	return c.(*Counter)
+}
+
+func (v *CounterVec) normalize(values []string) []string {
+	out := make([]string, len(v.labels))
+	for i := range out {
+		if i < len(values) {
+			out[i] = strings.ReplaceAll(values[i], "\x00", "")
+		}
+	}
+	return out
+}
+
+type metricKind string
+
+const (
+	kindCounter metricKind = "counter"
+	kindGauge   metricKind = "gauge"
+)
+
+type metric struct {
+	name  string
+	help  string
+	kind  metricKind
+	value func() int64 // scalar metrics
+	vec   *CounterVec  // labelled metrics
+}
+
+// Registry is a named collection of metrics.
+type Registry struct {
+	mu      sync.Mutex
+	metrics map[string]*metric
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]*metric)}
+}
+
+func (r *Registry) register(m *metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.metrics[m.name]; exists {
+		panic("metrics: duplicate metric " + m.name)
+	}
+	r.metrics[m.name] = m
+}
+
+// NewCounter registers and returns a counter.
+func (r *Registry) NewCounter(name, help string) *Counter {
+	c := &Counter{}
+	r.register(&metric{name: name, help: help, kind: kindCounter, value: c.Value})
+	return c
+}
+
+// NewGauge registers and returns a gauge.
+func (r *Registry) NewGauge(name, help string) *Gauge {
+	g := &Gauge{}
+	r.register(&metric{name: name, help: help, kind: kindGauge, value: g.Value})
+	return g
+}
+
+// NewGaugeFunc registers a gauge whose value is read from fn at scrape
+// time, for values another component already tracks (such as the number of
+// live sessions). Registering the same name again replaces fn.
+func (r *Registry) NewGaugeFunc(name, help string, fn func() int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics[name] = &metric{name: name, help: help, kind: kindGauge, value: fn}
+}
+
+// NewCounterVec registers and returns a labelled counter.
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	v := &CounterVec{labels: labels}
+	r.register(&metric{name: name, help: help, kind: kindCounter, vec: v})
+	return v
+}
+
+// WriteText writes every metric in the Prometheus text exposition format,
+// sorted by name and then by label values so scrapes are stable.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	metrics := make([]*metric, 0, len(r.metrics))
+	for _, m := range r.metrics {
+		metrics = append(metrics, m)
+	}
+	r.mu.Unlock()
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].name < metrics[j].name })
+
+	var b strings.Builder
+	for _, m := range metrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n", m.name, escapeHelp(m.help))
+		fmt.Fprintf(&b, "# TYPE %s %s\n", m.name, m.kind)
+		if m.vec == nil {
+			fmt.Fprintf(&b, "%s %d\n", m.name, m.value())
+			continue
+		}
+		type sample struct {
+			key   string
+			value int64
+		}
+		var samples []sample
+		m.vec.m.Range(func(k, v any) bool {
+			samples = append(samples, sample{key: k.(string), value: v.(*Counter).Value()})
+			return true
+		})
+		sort.Slice(samples, func(i, j int) bool { return samples[i].key < samples[j].key })
+		for _, s := range samples {
+			values := strings.Split(s.key, "\x00")
+			pairs := make([]string, len(m.vec.labels))
+			for i, label := range m.vec.labels {
+				pairs[i] = label + `="` + escapeLabelValue(values[i]) + `"`
+			}
+			fmt.Fprintf(&b, "%s{%s} %d\n", m.name, strings.Join(pairs, ","), s.value)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabelValue(s string) string { return labelEscaper.Replace(strings.ToValidUTF8(s, "?")) }
+
+func escapeHelp(s string) string { return helpEscaper.Replace(s) }
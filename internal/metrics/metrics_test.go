@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistryWriteText(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounterVec("test_requests_total", "Requests by provider.", "provider", "model")
+	active := r.NewGauge("test_active", "Active things.")
+	r.NewGaugeFunc("test_sessions", "Sessions.", func() int64 { return 3 })
+
+	requests.With("openai", "gpt-5").Inc()
+	requests.With("openai", "gpt-5").Add(2)
+	requests.With("anthropic", `odd"name\`).Inc()
+	requests.With("anthropic", "claude").Add(-5) // ignored: counters only go up
+	active.Inc()
+	active.Inc()
+	active.Dec()
+
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP test_active Active things.
+# TYPE test_active gauge
+test_active 1
+# HELP test_requests_total Requests by provider.
+# TYPE test_requests_total counter
+test_requests_total{provider="anthropic",model="claude"} 0
+test_requests_total{provider="anthropic",model="odd\"name\\"} 1
+test_requests_total{provider="openai",model="gpt-5"} 3
+# HELP test_sessions Sessions.
+# TYPE test_sessions gauge
+test_sessions 3
+`
+	if b.String() != want {
+		t.Fatalf("WriteText =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestRegistryRejectsDuplicateNames(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("dup", "")
+	defer func() {
+		if recover() == nil {
+			t.Fatal("registering a duplicate metric did not panic")
+		}
+	}()
+	r.NewGauge("dup", "")
+}
@@ -0,0 +1,55 @@
+package pathutil
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// ConfigDirEnvVar overrides the term-llm config directory on every platform.
+const ConfigDirEnvVar = "TERM_LLM_CONFIG_DIR"
+
+// ConfigDir returns term-llm's config directory, which holds config.yaml,
+// credentials, agents, skills and MCP settings. In order of precedence:
+//
+//   - $TERM_LLM_CONFIG_DIR, used as-is
+//   - $XDG_CONFIG_HOME/term-llm
+//   - on Windows, %APPDATA%\term-llm, unless ~/.config/term-llm already
+//     exists from an earlier install
+//   - ~/.config/term-llm
+func ConfigDir() (string, error) {
+	home, homeErr := os.UserHomeDir()
+	dir := configDirFor(runtime.GOOS, os.Getenv, home, func(path string) bool {
+		info, err := os.Stat(path)
+		return err == nil && info.IsDir()
+	})
+	if dir == "" {
+		if homeErr == nil {
+			homeErr = errors.New("home directory is not set")
+		}
+		return "", homeErr
+	}
+	return dir, nil
+}
+
+// configDirFor resolves ConfigDir for goos. It returns "" when no candidate
+// can be built.
+func configDirFor(goos string, getenv func(string) string, home string, isDir func(string) bool) string {
+	if dir := getenv(ConfigDirEnvVar); dir != "" {
+		return dir
+	}
+	if xdg := getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "term-llm")
+	}
+	legacy := ""
+	if home != "" {
+		legacy = filepath.Join(home, ".config", "term-llm")
+	}
+	if goos == "windows" {
+		if appData := getenv("APPDATA"); appData != "" && (legacy == "" || !isDir(legacy)) {
+			return filepath.Join(appData, "term-llm")
+		}
+	}
+	return legacy
+}
@@ -0,0 +1,89 @@
+package pathutil
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigDirFor(t *testing.T) {
+	home := filepath.Join("home", "me")
+	legacy := filepath.Join(home, ".config", "term-llm")
+	tests := []struct {
+		name      string
+		goos      string
+		env       map[string]string
+		home      string
+		legacyDir bool
+		want      string
+	}{
+		{
+			name: "explicit override wins everywhere",
+			goos: "windows",
+			env:  map[string]string{ConfigDirEnvVar: "custom", "XDG_CONFIG_HOME": "xdg", "APPDATA": "appdata"},
+			home: home,
+			want: "custom",
+		},
+		{
+			name: "xdg on linux",
+			goos: "linux",
+			env:  map[string]string{"XDG_CONFIG_HOME": "xdg"},
+			home: home,
+			want: filepath.Join("xdg", "term-llm"),
+		},
+		{
+			name: "home on linux",
+			goos: "linux",
+			home: home,
+			want: legacy,
+		},
+		{
+			name: "appdata ignored off windows",
+			goos: "darwin",
+			env:  map[string]string{"APPDATA": "appdata"},
+			home: home,
+			want: legacy,
+		},
+		{
+			name: "appdata on windows",
+			goos: "windows",
+			env:  map[string]string{"APPDATA": "appdata"},
+			home: home,
+			want: filepath.Join("appdata", "term-llm"),
+		},
+		{
+			name:      "existing legacy dir kept on windows",
+			goos:      "windows",
+			env:       map[string]string{"APPDATA": "appdata"},
+			home:      home,
+			legacyDir: true,
+			want:      legacy,
+		},
+		{
+			name: "xdg still honoured on windows",
+			goos: "windows",
+			env:  map[string]string{"XDG_CONFIG_HOME": "xdg", "APPDATA": "appdata"},
+			home: home,
+			want: filepath.Join("xdg", "term-llm"),
+		},
+		{
+			name: "windows without appdata falls back to home",
+			goos: "windows",
+			home: home,
+			want: legacy,
+		},
+		{
+			name: "nothing to build from",
+			goos: "linux",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			isDir := func(path string) bool { return tt.legacyDir && path == legacy }
+			if got := configDirFor(tt.goos, getenv, tt.home, isDir); got != tt.want {
+				t.Errorf("configDirFor() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package pathutil
+
+import (
+	"path"
+	"runtime"
+	"strings"
+)
+
+// IsWithin reports whether target is dir or lies beneath it. Both paths
+// should already be absolute and cleaned. On Windows the comparison ignores
+// case and treats / and \ alike, so C:\Repo and c:/repo/src match.
+func IsWithin(target, dir string) bool {
+	return isWithinFor(runtime.GOOS, target, dir)
+}
+
+func isWithinFor(goos, target, dir string) bool {
+	target = comparablePath(goos, target)
+	dir = comparablePath(goos, dir)
+	if target == "" || dir == "" {
+		return false
+	}
+	if target == dir {
+		return true
+	}
+	if !strings.HasSuffix(dir, "/") {
+		dir += "/"
+	}
+	return strings.HasPrefix(target, dir)
+}
+
+// comparablePath normalizes p for prefix comparison on goos: slash
+// separated and cleaned, and on Windows lower-cased without a \\?\ prefix.
+func comparablePath(goos, p string) string {
+	if p == "" {
+		return ""
+	}
+	if goos == "windows" {
+		p = strings.TrimPrefix(p, `\\?\`)
+		p = strings.ToLower(strings.ReplaceAll(p, `\`, "/"))
+	}
+	return path.Clean(p)
+}
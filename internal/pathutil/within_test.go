@@ -0,0 +1,42 @@
+package pathutil
+
+import "testing"
+
+func TestIsWithinFor(t *testing.T) {
+	tests := []struct {
+		name        string
+		goos        string
+		target, dir string
+		want        bool
+	}{
+		{"unix same dir", "linux", "/repo", "/repo", true},
+		{"unix child", "linux", "/repo/src/main.go", "/repo", true},
+		{"unix sibling prefix", "linux", "/repository/x", "/repo", false},
+		{"unix case sensitive", "linux", "/Repo/src", "/repo", false},
+		{"unix trailing slash", "linux", "/repo/src", "/repo/", true},
+		{"unix root", "linux", "/etc/passwd", "/", true},
+		{"unix backslash is a name char", "linux", `/repo\src`, "/repo", false},
+		{"darwin child", "darwin", "/Users/me/x", "/Users/me", true},
+
+		{"windows same dir", "windows", `C:\Repo`, `C:\Repo`, true},
+		{"windows child", "windows", `C:\Repo\src\main.go`, `C:\Repo`, true},
+		{"windows case insensitive", "windows", `c:\repo\SRC`, `C:\Repo`, true},
+		{"windows drive letter case", "windows", `c:\Repo`, `C:\Repo`, true},
+		{"windows mixed separators", "windows", `C:/Repo/src`, `C:\Repo\`, true},
+		{"windows other drive", "windows", `D:\Repo\src`, `C:\Repo`, false},
+		{"windows sibling prefix", "windows", `C:\Repository`, `C:\Repo`, false},
+		{"windows drive root", "windows", `C:\Users\me`, `C:\`, true},
+		{"windows long path prefix", "windows", `\\?\C:\Repo\src`, `C:\Repo`, true},
+		{"windows unc share", "windows", `\\server\share\dir`, `\\Server\Share`, true},
+
+		{"empty dir", "linux", "/repo", "", false},
+		{"empty target", "windows", "", `C:\Repo`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isWithinFor(tt.goos, tt.target, tt.dir); got != tt.want {
+				t.Errorf("isWithinFor(%q, %q, %q) = %v, want %v", tt.goos, tt.target, tt.dir, got, tt.want)
+			}
+		})
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"os"
 	"os/exec"
-	"syscall"
 )
 
 type LimitedBuffer struct {
@@ -58,13 +57,3 @@ func PrepareCommand(cmd *exec.Cmd) (func(), error) {
 	ConfigureCommandProcessGroup(cmd)
 	return func() {}, nil
 }
-
-func ConfigureCommandProcessGroup(cmd *exec.Cmd) {
-	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
-	cmd.Cancel = func() error {
-		if cmd.Process == nil {
-			return nil
-		}
-		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
-	}
-}
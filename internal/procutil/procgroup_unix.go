@@ -0,0 +1,34 @@
+//go:build !windows
+
+package procutil
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// ConfigureCommandProcessGroup starts cmd in its own process group and makes
+// cancellation kill the whole group, so children the command spawned do not
+// outlive it.
+func ConfigureCommandProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		if cmd.Process == nil {
+			return nil
+		}
+		return KillProcessTree(cmd.Process.Pid)
+	}
+}
+
+// InOwnProcessGroup reports whether cmd is configured to start in a new
+// process group.
+func InOwnProcessGroup(cmd *exec.Cmd) bool {
+	return cmd.SysProcAttr != nil && cmd.SysProcAttr.Setpgid
+}
+
+func setShellCmdLine(cmd *exec.Cmd, shell, command string) {}
+
+// KillProcessTree kills the process group led by pid.
+func KillProcessTree(pid int) error {
+	return syscall.Kill(-pid, syscall.SIGKILL)
+}
@@ -0,0 +1,57 @@
+//go:build windows
+
+package procutil
+
+import (
+	"os/exec"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// ConfigureCommandProcessGroup starts cmd in its own process group, so a
+// console Ctrl+C aimed at term-llm does not reach it, and makes cancellation
+// kill the command together with every process it spawned.
+func ConfigureCommandProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= windows.CREATE_NEW_PROCESS_GROUP
+	cmd.Cancel = func() error {
+		if cmd.Process == nil {
+			return nil
+		}
+		if err := KillProcessTree(cmd.Process.Pid); err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+}
+
+// InOwnProcessGroup reports whether cmd is configured to start in a new
+// process group.
+func InOwnProcessGroup(cmd *exec.Cmd) bool {
+	return cmd.SysProcAttr != nil && cmd.SysProcAttr.CreationFlags&windows.CREATE_NEW_PROCESS_GROUP != 0
+}
+
+// setShellCmdLine passes a cmd.exe command line through verbatim. cmd.exe
+// does not parse arguments the way Go quotes them, so "/S /C" plus a quoted
+// command string is the only reliable form.
+func setShellCmdLine(cmd *exec.Cmd, shell, command string) {
+	if KindOfShell(shell) != ShellCmd {
+		return
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CmdLine = syscall.EscapeArg(cmd.Path) + ` /D /S /C "` + command + `"`
+}
+
+// KillProcessTree kills pid and its descendants. Windows has no process
+// groups to signal, so this asks taskkill to walk the tree.
+func KillProcessTree(pid int) error {
+	kill := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(pid))
+	kill.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	return kill.Run()
+}
@@ -0,0 +1,83 @@
+package procutil
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ShellKind groups shells by how they are told to run a command string.
+type ShellKind int
+
+const (
+	ShellPOSIX      ShellKind = iota // sh, bash, zsh, ...: -c
+	ShellCmd                         // cmd.exe: /C
+	ShellPowerShell                  // pwsh, powershell: -Command
+)
+
+// DefaultShell returns the shell used to run command strings: $SHELL when
+// set, otherwise %ComSpec% (cmd.exe) on Windows, otherwise fallback.
+func DefaultShell(fallback string) string {
+	return defaultShellFor(runtime.GOOS, os.Getenv, fallback)
+}
+
+func defaultShellFor(goos string, getenv func(string) string, fallback string) string {
+	if shell := getenv("SHELL"); shell != "" {
+		return shell
+	}
+	if goos == "windows" {
+		if comspec := getenv("ComSpec"); comspec != "" {
+			return comspec
+		}
+		return "cmd.exe"
+	}
+	return fallback
+}
+
+// ShellName returns the bare name of shell, e.g. "zsh" for /bin/zsh and
+// "pwsh" for C:\Program Files\PowerShell\7\pwsh.exe.
+func ShellName(shell string) string {
+	base := shell
+	if i := strings.LastIndexAny(base, `/\`); i >= 0 {
+		base = base[i+1:]
+	}
+	if ext := filepath.Ext(base); strings.EqualFold(ext, ".exe") {
+		base = base[:len(base)-len(ext)]
+	}
+	return strings.ToLower(base)
+}
+
+// KindOfShell classifies shell by name. Unknown shells are assumed to be
+// POSIX-compatible.
+func KindOfShell(shell string) ShellKind {
+	switch ShellName(shell) {
+	case "cmd":
+		return ShellCmd
+	case "pwsh", "powershell":
+		return ShellPowerShell
+	default:
+		return ShellPOSIX
+	}
+}
+
+// ShellArgs returns the arguments that make shell run command.
+func ShellArgs(shell, command string) []string {
+	switch KindOfShell(shell) {
+	case ShellCmd:
+		return []string{"/D", "/S", "/C", command}
+	case ShellPowerShell:
+		return []string{"-NoProfile", "-NonInteractive", "-Command", command}
+	default:
+		return []string{"-c", command}
+	}
+}
+
+// ShellCommand returns a command that runs command through shell.
+func ShellCommand(ctx context.Context, shell, command string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, shell, ShellArgs(shell, command)...)
+	setShellCmdLine(cmd, shell, command)
+	return cmd
+}
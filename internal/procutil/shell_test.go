@@ -0,0 +1,56 @@
+package procutil
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDefaultShellFor(t *testing.T) {
+	tests := []struct {
+		name string
+		goos string
+		env  map[string]string
+		want string
+	}{
+		{"shell env on linux", "linux", map[string]string{"SHELL": "/bin/zsh"}, "/bin/zsh"},
+		{"fallback on linux", "linux", nil, "bash"},
+		{"comspec ignored off windows", "darwin", map[string]string{"ComSpec": `C:\Windows\system32\cmd.exe`}, "bash"},
+		{"shell env wins on windows", "windows", map[string]string{"SHELL": "/usr/bin/bash", "ComSpec": "cmd.exe"}, "/usr/bin/bash"},
+		{"comspec on windows", "windows", map[string]string{"ComSpec": `C:\Windows\system32\cmd.exe`}, `C:\Windows\system32\cmd.exe`},
+		{"cmd.exe on windows", "windows", nil, "cmd.exe"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			if got := defaultShellFor(tt.goos, getenv, "bash"); got != tt.want {
+				t.Errorf("defaultShellFor() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShellArgs(t *testing.T) {
+	tests := []struct {
+		shell    string
+		wantName string
+		want     []string
+	}{
+		{"/bin/bash", "bash", []string{"-c", "echo hi"}},
+		{"zsh", "zsh", []string{"-c", "echo hi"}},
+		{`C:\Windows\System32\cmd.exe`, "cmd", []string{"/D", "/S", "/C", "echo hi"}},
+		{"CMD.EXE", "cmd", []string{"/D", "/S", "/C", "echo hi"}},
+		{`C:\Program Files\PowerShell\7\pwsh.exe`, "pwsh", []string{"-NoProfile", "-NonInteractive", "-Command", "echo hi"}},
+		{"powershell", "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", "echo hi"}},
+		{`C:\Program Files\Git\bin\bash.exe`, "bash", []string{"-c", "echo hi"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			if got := ShellName(tt.shell); got != tt.wantName {
+				t.Errorf("ShellName(%q) = %q, want %q", tt.shell, got, tt.wantName)
+			}
+			if got := ShellArgs(tt.shell, "echo hi"); !slices.Equal(got, tt.want) {
+				t.Errorf("ShellArgs(%q) = %q, want %q", tt.shell, got, tt.want)
+			}
+		})
+	}
+}
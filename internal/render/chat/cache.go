@@ -14,15 +14,25 @@ type blockCacheKey struct {
 	messageID      int64
 	width          int
 	toolsExpanded  bool
+	showStats      bool
 	partsSignature uint64
 }
 
+// anyWidth drops the width from a key. It indexes the latest render of a
+// message at any width, so a resize can keep showing off-screen blocks until
+// they are reflowed.
+func (k blockCacheKey) anyWidth() blockCacheKey {
+	k.width = 0
+	return k
+}
+
 // BlockCache is an LRU cache for rendered MessageBlocks.
 // It keeps memory bounded while avoiding re-rendering unchanged messages.
 type BlockCache struct {
 	mu      sync.RWMutex
 	maxSize int
 	cache   map[blockCacheKey]*list.Element
+	latest  map[blockCacheKey]*list.Element // anyWidth key → most recently stored width
 	lruList *list.List
 }
 
@@ -43,6 +53,7 @@ func NewBlockCache(maxSize int) *BlockCache {
 	return &BlockCache{
 		maxSize: maxSize,
 		cache:   make(map[blockCacheKey]*list.Element),
+		latest:  make(map[blockCacheKey]*list.Element),
 		lruList: list.New(),
 	}
 }
@@ -61,6 +72,24 @@ func (c *BlockCache) Get(key blockCacheKey) *MessageBlock {
 	return nil
 }
 
+// GetAnyWidth retrieves a block for key, falling back to the latest render
+// of the same message content at another width. exact reports whether the
+// block was rendered at key's width.
+func (c *BlockCache) GetAnyWidth(key blockCacheKey) (block *MessageBlock, exact bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.cache[key]; ok {
+		c.lruList.MoveToFront(elem)
+		return elem.Value.(*cacheEntry).block, true
+	}
+	if elem, ok := c.latest[key.anyWidth()]; ok {
+		c.lruList.MoveToFront(elem)
+		return elem.Value.(*cacheEntry).block, false
+	}
+	return nil, false
+}
+
 // Put adds a block to the cache, evicting the least recently used
 // block if the cache is at capacity.
 func (c *BlockCache) Put(key blockCacheKey, block *MessageBlock) {
@@ -72,6 +101,7 @@ func (c *BlockCache) Put(key blockCacheKey, block *MessageBlock) {
 		// Update existing entry and move to front
 		c.lruList.MoveToFront(elem)
 		elem.Value.(*cacheEntry).block = block
+		c.latest[key.anyWidth()] = elem
 		return
 	}
 
@@ -84,6 +114,7 @@ func (c *BlockCache) Put(key blockCacheKey, block *MessageBlock) {
 	entry := &cacheEntry{key: key, block: block}
 	elem := c.lruList.PushFront(entry)
 	c.cache[key] = elem
+	c.latest[key.anyWidth()] = elem
 }
 
 // evictOldest removes the least recently used entry.
@@ -92,8 +123,7 @@ func (c *BlockCache) evictOldest() {
 	oldest := c.lruList.Back()
 	if oldest != nil {
 		entry := oldest.Value.(*cacheEntry)
-		delete(c.cache, entry.key)
-		c.lruList.Remove(oldest)
+		c.removeElement(entry.key, oldest)
 	}
 }
 
@@ -103,8 +133,29 @@ func (c *BlockCache) Remove(key blockCacheKey) {
 	defer c.mu.Unlock()
 
 	if elem, ok := c.cache[key]; ok {
-		delete(c.cache, key)
-		c.lruList.Remove(elem)
+		c.removeElement(key, elem)
+	}
+}
+
+// removeElement drops an entry from every index.
+// Must be called with lock held.
+func (c *BlockCache) removeElement(key blockCacheKey, elem *list.Element) {
+	delete(c.cache, key)
+	if c.latest[key.anyWidth()] == elem {
+		delete(c.latest, key.anyWidth())
+	}
+	c.lruList.Remove(elem)
+}
+
+// RemoveMessage removes every cached render of a message, at any width.
+func (c *BlockCache) RemoveMessage(messageID int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, elem := range c.cache {
+		if key.messageID == messageID {
+			c.removeElement(key, elem)
+		}
 	}
 }
 
@@ -134,12 +185,13 @@ func (c *BlockCache) MaxSize() int {
 }
 
 // InvalidateAll clears the entire cache.
-// Call this on terminal resize when all cached renders are invalid.
+// Call this when cached renders are invalid at every width.
 func (c *BlockCache) InvalidateAll() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	c.cache = make(map[blockCacheKey]*list.Element)
+	c.latest = make(map[blockCacheKey]*list.Element)
 	c.lruList.Init()
 }
 
@@ -156,6 +156,35 @@ func TestBlockCache_InvalidateAll(t *testing.T) {
 	}
 }
 
+func TestBlockCache_GetAnyWidthFallsBackToLatestWidth(t *testing.T) {
+	cache := NewBlockCache(10)
+
+	narrow := testBlockCacheKey(1)
+	narrow.width = 60
+	wide := testBlockCacheKey(1)
+	wide.width = 120
+	cache.Put(narrow, &MessageBlock{MessageID: 1, Width: 60})
+	cache.Put(testBlockCacheKey(1), &MessageBlock{MessageID: 1, Width: 80})
+
+	if block, exact := cache.GetAnyWidth(narrow); block == nil || !exact || block.Width != 60 {
+		t.Fatalf("GetAnyWidth(narrow) = %+v, exact=%v; want the exact 60-column block", block, exact)
+	}
+	if block, exact := cache.GetAnyWidth(wide); block == nil || exact || block.Width != 80 {
+		t.Fatalf("GetAnyWidth(wide) = %+v, exact=%v; want the latest (80-column) block as a fallback", block, exact)
+	}
+
+	cache.Remove(testBlockCacheKey(1))
+	if block, _ := cache.GetAnyWidth(wide); block != nil {
+		t.Fatalf("GetAnyWidth after removing the latest width = %+v, want nil", block)
+	}
+
+	other := testBlockCacheKey(2)
+	other.width = 120
+	if block, _ := cache.GetAnyWidth(other); block != nil {
+		t.Fatalf("GetAnyWidth for different content = %+v, want nil", block)
+	}
+}
+
 func TestBlockCache_ConcurrentAccess(t *testing.T) {
 	cache := NewBlockCache(100)
 	done := make(chan bool)
@@ -0,0 +1,123 @@
+package chat
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/samsaffron/term-llm/internal/ui/ansisafe"
+)
+
+// Search highlight backgrounds: a dim amber for every match and a brighter
+// one for the current match. Foreground colors are left alone.
+const (
+	findMatchBg   = "\033[48;2;90;75;20m"
+	findCurrentBg = "\033[48;2;170;110;0m"
+)
+
+// FindMatch is one occurrence of a search query in rendered output. Columns
+// are visual cells in the line, end exclusive.
+type FindMatch struct {
+	Line     int
+	StartCol int
+	EndCol   int
+}
+
+// FindInLines returns every case-insensitive occurrence of query in the plain
+// text of lines, in reading order. Matches don't overlap and never span lines.
+func FindInLines(lines []string, query string) []FindMatch {
+	needle := foldRunes(query)
+	if len(needle) == 0 {
+		return nil
+	}
+	var matches []FindMatch
+	for i, line := range lines {
+		for _, span := range findInLine(line, needle) {
+			matches = append(matches, FindMatch{Line: i, StartCol: span[0], EndCol: span[1]})
+		}
+	}
+	return matches
+}
+
+// CountMatches returns how many times query occurs in text, ignoring case.
+func CountMatches(text, query string) int {
+	needle := foldRunes(query)
+	if len(needle) == 0 {
+		return 0
+	}
+	count := 0
+	for _, line := range strings.Split(text, "\n") {
+		count += len(findInLine(line, needle))
+	}
+	return count
+}
+
+// HighlightMatches highlights every occurrence of query in output without
+// disturbing its ANSI styling. lineOffset is the line number of output's
+// first line, so the match equal to current gets the current-match color.
+func HighlightMatches(output, query string, lineOffset int, current FindMatch) string {
+	needle := foldRunes(query)
+	if len(needle) == 0 || output == "" {
+		return output
+	}
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		found := findInLine(line, needle)
+		if len(found) == 0 {
+			continue
+		}
+		spans := make([]ansisafe.HighlightSpan, len(found))
+		for j, span := range found {
+			spans[j] = ansisafe.HighlightSpan{Start: span[0], End: span[1], Bg: findMatchBg}
+			if lineOffset+i == current.Line && span[0] == current.StartCol {
+				spans[j].Bg = findCurrentBg
+			}
+		}
+		lines[i] = ansisafe.ApplyHighlights(line, spans)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// findInLine returns the [start, end) visual column spans of needle in the
+// plain text of line.
+func findInLine(line string, needle []rune) [][2]int {
+	plain := ansi.Strip(line)
+	if len(plain) < len(needle) {
+		return nil
+	}
+	haystack := []rune(plain)
+	// cols[i] is the visual column where rune i starts.
+	cols := make([]int, len(haystack)+1)
+	for i, r := range haystack {
+		cols[i+1] = cols[i] + ansi.StringWidth(string(r))
+		haystack[i] = unicode.ToLower(r)
+	}
+
+	var spans [][2]int
+	for i := 0; i+len(needle) <= len(haystack); {
+		if runesEqual(haystack[i:i+len(needle)], needle) {
+			spans = append(spans, [2]int{cols[i], cols[i+len(needle)]})
+			i += len(needle)
+			continue
+		}
+		i++
+	}
+	return spans
+}
+
+func foldRunes(s string) []rune {
+	runes := []rune(s)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
+
+func runesEqual(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
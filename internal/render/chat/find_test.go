@@ -0,0 +1,105 @@
+package chat
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/samsaffron/term-llm/internal/ui"
+)
+
+func TestFindInLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		query string
+		want  []FindMatch
+	}{
+		{name: "empty query", lines: []string{"abc"}, query: "", want: nil},
+		{name: "case insensitive", lines: []string{"Foo foo FOO"}, query: "foo", want: []FindMatch{{0, 0, 3}, {0, 4, 7}, {0, 8, 11}}},
+		{name: "across lines", lines: []string{"no", "a needle", "needle"}, query: "Needle", want: []FindMatch{{1, 2, 8}, {2, 0, 6}}},
+		{name: "ansi is transparent", lines: []string{"\x1b[31mre\x1b[0md \x1b[1mred\x1b[0m"}, query: "red", want: []FindMatch{{0, 0, 3}, {0, 4, 7}}},
+		{name: "no overlap", lines: []string{"aaaa"}, query: "aa", want: []FindMatch{{0, 0, 2}, {0, 2, 4}}},
+		{name: "wide runes use cells", lines: []string{"日本 go"}, query: "go", want: []FindMatch{{0, 5, 7}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindInLines(tt.lines, tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindInLines(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountMatches(t *testing.T) {
+	if got := CountMatches("Error: error\nERRORS", "error"); got != 3 {
+		t.Fatalf("CountMatches = %d, want 3", got)
+	}
+}
+
+var sgrPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+func TestHighlightMatchesKeepsCodeBlockStyling(t *testing.T) {
+	rendered := ui.RenderMarkdown("Handle it:\n\n```go\nfunc handleRequest() { return nil }\n```\n", 60)
+	if !strings.Contains(rendered, "\x1b[") {
+		t.Fatal("expected styled markdown output; test setup is invalid")
+	}
+
+	lines := strings.Split(rendered, "\n")
+	matches := FindInLines(lines, "REQUEST")
+	if len(matches) != 1 {
+		t.Fatalf("matches = %v, want one in the code block", matches)
+	}
+	got := HighlightMatches(rendered, "REQUEST", 0, matches[0])
+
+	if ansi.Strip(got) != ansi.Strip(rendered) {
+		t.Fatalf("highlighting changed the text:\n%q\nwant\n%q", ansi.Strip(got), ansi.Strip(rendered))
+	}
+	if escapes, sgrs := strings.Count(got, "\x1b["), len(sgrPattern.FindAllString(got, -1)); escapes != sgrs {
+		t.Fatalf("found %d escape sequences but only %d well-formed SGR sequences in %q", escapes, sgrs, got)
+	}
+	if !strings.Contains(got, findCurrentBg) {
+		t.Fatalf("current match is not highlighted: %q", got)
+	}
+
+	// The code block background must be back in effect after the match.
+	line := strings.Split(got, "\n")[matches[0].Line]
+	if bg := backgroundAt(line, '('); bg != "\x1b[48;5;236m" {
+		t.Fatalf("background of the text after the match = %q, want the code block background", bg)
+	}
+}
+
+// backgroundAt returns the last background SGR sequence in effect at the
+// first visible occurrence of r in line.
+func backgroundAt(line string, r byte) string {
+	bg := ""
+	for i := 0; i < len(line); i++ {
+		if loc := sgrPattern.FindStringIndex(line[i:]); loc != nil && loc[0] == 0 {
+			seq := line[i : i+loc[1]]
+			if strings.HasPrefix(seq, "\x1b[48;") || seq == "\x1b[49m" || seq == "\x1b[0m" {
+				bg = seq
+			}
+			i += loc[1] - 1
+			continue
+		}
+		if line[i] == r {
+			return bg
+		}
+	}
+	return ""
+}
+
+func TestHighlightMatchesMarksOnlyTheCurrentMatch(t *testing.T) {
+	out := HighlightMatches("foo\nfoo foo", "foo", 10, FindMatch{Line: 11, StartCol: 4, EndCol: 7})
+	if got := strings.Count(out, findCurrentBg); got != 1 {
+		t.Fatalf("current highlight count = %d, want 1 in %q", got, out)
+	}
+	if got := strings.Count(out, findMatchBg); got != 2 {
+		t.Fatalf("match highlight count = %d, want 2 in %q", got, out)
+	}
+	if !strings.HasPrefix(strings.Split(out, "\n")[1], findMatchBg+"foo") {
+		t.Fatalf("first match on the current line should use the match color: %q", out)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/muesli/reflow/wordwrap"
 	"github.com/samsaffron/term-llm/internal/config"
 	"github.com/samsaffron/term-llm/internal/llm"
+	planpkg "github.com/samsaffron/term-llm/internal/plan"
 	internalreasoning "github.com/samsaffron/term-llm/internal/reasoning"
 	"github.com/samsaffron/term-llm/internal/session"
 	"github.com/samsaffron/term-llm/internal/ui"
@@ -48,8 +49,19 @@ type MessageBlock struct {
 	// ReasoningLineOffsets are zero-based line offsets, relative to Rendered, for
 	// each actual reasoning/thought header rendered in this block.
 	ReasoningLineOffsets []int
+
+	// CollapsedResultLineOffsets are zero-based line offsets, relative to
+	// Rendered, of the "+N lines" marker of each collapsed tool result.
+	CollapsedResultLineOffsets []int
 }
 
+// With tools expanded, tool results longer than toolResultCollapseLines show
+// only their first and last toolResultPreviewLines lines until expanded.
+const (
+	toolResultCollapseLines = 20
+	toolResultPreviewLines  = 4
+)
+
 // MessageBlockRenderer renders session messages to MessageBlocks.
 type MessageBlockRenderer struct {
 	width                  int
@@ -64,6 +76,9 @@ type MessageBlockRenderer struct {
 	reasoningExpandByIndex map[int]bool
 	reasoningRenderedCount int
 	reasoningLineOffsets   []int
+	toolResultsExpanded    bool
+	showStats              bool
+	collapsedResultOffsets []int
 	firstSegmentType       ui.SegmentType
 	lastSegmentType        ui.SegmentType
 	hasSegmentTypes        bool
@@ -115,10 +130,22 @@ func (r *MessageBlockRenderer) SetReasoningExpansionOverrides(base int, override
 	r.reasoningExpandByIndex = overrides
 }
 
+// SetToolResultsExpanded shows long tool results in full instead of
+// collapsing them.
+func (r *MessageBlockRenderer) SetToolResultsExpanded(v bool) {
+	r.toolResultsExpanded = v
+}
+
+// SetShowStats adds a timing and token usage line under assistant messages.
+func (r *MessageBlockRenderer) SetShowStats(v bool) {
+	r.showStats = v
+}
+
 // Render converts a session.Message to a MessageBlock.
 func (r *MessageBlockRenderer) Render(msg *session.Message) *MessageBlock {
 	r.reasoningRenderedCount = 0
 	r.reasoningLineOffsets = nil
+	r.collapsedResultOffsets = nil
 	r.firstSegmentType = ui.SegmentText
 	r.lastSegmentType = ui.SegmentText
 	r.hasSegmentTypes = false
@@ -150,15 +177,16 @@ func (r *MessageBlockRenderer) Render(msg *session.Message) *MessageBlock {
 	}
 
 	return &MessageBlock{
-		MessageID:            msg.ID,
-		Rendered:             content,
-		Height:               countLines(content),
-		Width:                r.width,
-		FirstSegmentType:     r.firstSegmentType,
-		LastSegmentType:      r.lastSegmentType,
-		HasSegmentTypes:      r.hasSegmentTypes,
-		ReasoningCount:       r.reasoningRenderedCount,
-		ReasoningLineOffsets: append([]int(nil), r.reasoningLineOffsets...),
+		MessageID:                  msg.ID,
+		Rendered:                   content,
+		Height:                     countLines(content),
+		Width:                      r.width,
+		FirstSegmentType:           r.firstSegmentType,
+		LastSegmentType:            r.lastSegmentType,
+		HasSegmentTypes:            r.hasSegmentTypes,
+		ReasoningCount:             r.reasoningRenderedCount,
+		ReasoningLineOffsets:       append([]int(nil), r.reasoningLineOffsets...),
+		CollapsedResultLineOffsets: r.collapsedResultOffsets,
 	}
 }
 
@@ -223,6 +251,8 @@ func (r *MessageBlockRenderer) renderEventMessage(msg *session.Message) string {
 		text = marker.DisplayText
 	} else if marker, ok := llm.ParseRunErrorMarker(msg.ToLLMMessage()); ok {
 		text = marker.Message
+	} else if marker, ok := llm.ParseInterruptedMarker(msg.ToLLMMessage()); ok {
+		text = llm.FormatInterruptedMarker(marker)
 	}
 	if text == "" {
 		text = "↔ Session event"
@@ -422,7 +452,11 @@ func (r *MessageBlockRenderer) renderAssistantMessage(msg *session.Message) stri
 				hasContent = true
 			}
 		case llm.PartImage:
-			b.WriteString("[Image]\n\n")
+			if part.ImagePath != "" {
+				b.WriteString(r.renderToolImages([]string{part.ImagePath}))
+			} else {
+				b.WriteString("[Image]\n\n")
+			}
 			r.noteRenderedSegment(ui.SegmentImage)
 			hasContent = true
 		case llm.PartToolCall:
@@ -437,6 +471,14 @@ func (r *MessageBlockRenderer) renderAssistantMessage(msg *session.Message) stri
 				r.noteRenderedSegment(ui.SegmentTool)
 				hasContent = true
 
+				if r.toolsExpanded && result != nil && showsToolResultOutput(part.ToolCall.Name) {
+					output, markerLine := r.renderToolResultOutput(result.Content)
+					if markerLine >= 0 {
+						r.collapsedResultOffsets = append(r.collapsedResultOffsets, strings.Count(b.String(), "\n")+markerLine)
+					}
+					b.WriteString(output)
+				}
+
 				if result != nil && len(result.Images) > 0 {
 					b.WriteString(r.renderToolImages(result.Images))
 					r.noteRenderedSegment(ui.SegmentImage)
@@ -493,9 +535,66 @@ func (r *MessageBlockRenderer) renderAssistantMessage(msg *session.Message) stri
 	// Keep tool-only assistant blocks compact: they already include line breaks.
 	// Text parts append paragraph spacing above.
 
+	if r.showStats {
+		if stats := msg.StatsLine(); stats != "" {
+			style := lipgloss.NewStyle().Foreground(r.theme.Muted)
+			b.WriteString(style.Render(stats))
+			b.WriteString("\n\n")
+		}
+	}
+
 	return b.String()
 }
 
+// showsToolResultOutput reports whether a tool's result text is shown under
+// its call when tools are expanded. Tools that render diffs show those
+// instead, always in full, and the plan tool renders as a checklist.
+func showsToolResultOutput(toolName string) bool {
+	switch toolName {
+	case "edit_file", "unified_diff", "spawn_agent", "write_file", planpkg.ToolName:
+		return false
+	}
+	return true
+}
+
+// renderToolResultOutput renders tool output as muted, indented lines
+// truncated to the width. Unless tool results are expanded, long output is
+// collapsed to its first and last lines around a marker, whose line offset
+// is returned (-1 when nothing is collapsed).
+func (r *MessageBlockRenderer) renderToolResultOutput(content string) (string, int) {
+	content = strings.TrimRight(ansi.Strip(content), "\n")
+	if strings.TrimSpace(content) == "" {
+		return "", -1
+	}
+	lines := strings.Split(content, "\n")
+	markerLine := -1
+	if !r.toolResultsExpanded && len(lines) > toolResultCollapseLines {
+		hidden := len(lines) - 2*toolResultPreviewLines
+		collapsed := make([]string, 0, 2*toolResultPreviewLines+1)
+		collapsed = append(collapsed, lines[:toolResultPreviewLines]...)
+		collapsed = append(collapsed, fmt.Sprintf("… (+%d lines, press alt+o to expand)", hidden))
+		lines = append(collapsed, lines[len(lines)-toolResultPreviewLines:]...)
+		markerLine = toolResultPreviewLines
+	}
+
+	outputStyle := lipgloss.NewStyle().Foreground(r.theme.Muted)
+	markerStyle := outputStyle.Italic(true)
+	var b strings.Builder
+	for i, line := range lines {
+		line = "  " + strings.ReplaceAll(line, "\t", "    ")
+		if r.width > 0 {
+			line = ansi.Truncate(line, r.width, "…")
+		}
+		if i == markerLine {
+			b.WriteString(markerStyle.Render(line))
+		} else {
+			b.WriteString(outputStyle.Render(line))
+		}
+		b.WriteString("\n")
+	}
+	return b.String(), markerLine
+}
+
 // reasoningAppendHeaderLineOffset returns the pre-wrap newline offset for a
 // reasoning header about to be appended to current. ANSI escape sequences do
 // not contain newlines, so byte-level newline counting is intentional here.
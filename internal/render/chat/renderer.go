@@ -34,6 +34,7 @@ type ViewportState struct {
 	Height       int // Visible height in lines
 	ScrollOffset int // Scroll offset from bottom (0 = bottom)
 	AtBottom     bool
+	LinesBelow   int // Alt-screen: history lines below the viewport's bottom edge
 }
 
 // InputState tracks input area state for rendering
@@ -91,7 +92,7 @@ type ChatRenderer interface {
 	HandleEvent(event RenderEvent) tea.Cmd
 
 	// SetSize updates the terminal dimensions.
-	// This invalidates caches as needed.
+	// Blocks cached at the old width are reflowed lazily.
 	SetSize(width, height int)
 
 	// Flush returns content that should be printed to scrollback
@@ -133,15 +134,26 @@ type Renderer struct {
 	blockCache *BlockCache
 	sigCache   map[int64]sigCacheEntry // message ID → cached parts signature
 
+	// staleBlocks counts history blocks in the last render that were reused
+	// from an earlier width rather than reflowed.
+	staleBlocks int
+
 	// Streaming state
 	streaming *StreamingBlock
 
 	lastReasoningLineOrdinals map[int]int
 	lastReasoningHeaderCount  int
+	lastCollapsedResultLines  map[int]int64 // history line → message ID
+	lastMessageStartLines     map[int]int   // message index → first history line
+
+	// expandedToolResults holds the messages whose long tool results are shown
+	// in full. It lives as long as the renderer and is never persisted.
+	expandedToolResults map[int64]bool
 
 	// Configuration
 	markdownRenderer MarkdownRenderer
 	toolsExpanded    bool
+	showStats        bool
 	imageRenderer    ui.ImageArtifactRenderer
 	reasoningConfig  config.ReasoningConfig
 }
@@ -166,18 +178,16 @@ func NewRenderer(width, height int) *Renderer {
 	return r
 }
 
-// SetSize updates the terminal dimensions and invalidates width-dependent caches.
+// SetSize updates the terminal dimensions. Cached blocks are keyed by width,
+// so nothing is invalidated: blocks are reflowed as they come into view and
+// off-screen ones keep their old render until RefreshStaleBlocks reaches them.
 func (r *Renderer) SetSize(width, height int) {
 	widthChanged := r.width != width
 	r.width = width
 	r.height = height
 
-	if widthChanged {
-		// Width affects all rendered content - invalidate everything
-		r.blockCache.InvalidateAll()
-		if r.streaming != nil {
-			r.streaming.Resize(width)
-		}
+	if widthChanged && r.streaming != nil {
+		r.streaming.Resize(width)
 	}
 }
 
@@ -195,6 +205,17 @@ func (r *Renderer) SetToolsExpanded(v bool) {
 	}
 }
 
+// SetShowStats toggles the per-message timing and usage line under
+// assistant messages.
+func (r *Renderer) SetShowStats(v bool) {
+	r.showStats = v
+}
+
+// ShowStats reports whether assistant messages are annotated with stats.
+func (r *Renderer) ShowStats() bool {
+	return r.showStats
+}
+
 // SetImageRenderer configures how generated-image artifacts are rendered.
 func (r *Renderer) SetImageRenderer(renderer ui.ImageArtifactRenderer) {
 	r.imageRenderer = renderer
@@ -214,6 +235,33 @@ func (r *Renderer) SetReasoningConfig(cfg config.ReasoningConfig) {
 	clear(r.sigCache)
 }
 
+// ExpandToolResults shows the collapsed tool results of a message in full.
+// Only that message's block is re-rendered.
+func (r *Renderer) ExpandToolResults(messageID int64) {
+	if r.expandedToolResults[messageID] {
+		return
+	}
+	if r.expandedToolResults == nil {
+		r.expandedToolResults = make(map[int64]bool)
+	}
+	r.expandedToolResults[messageID] = true
+	r.blockCache.RemoveMessage(messageID)
+}
+
+// LastCollapsedToolResult returns the message whose collapsed tool result
+// marker is the lowest one within history lines [first, last] of the last
+// render.
+func (r *Renderer) LastCollapsedToolResult(first, last int) (int64, bool) {
+	bestLine := -1
+	var messageID int64
+	for line, id := range r.lastCollapsedResultLines {
+		if line >= first && line <= last && line > bestLine {
+			bestLine, messageID = line, id
+		}
+	}
+	return messageID, bestLine >= 0
+}
+
 // InvalidateCache forces re-rendering of all cached content.
 func (r *Renderer) InvalidateCache() {
 	r.blockCache.InvalidateAll()
@@ -366,9 +414,8 @@ func (r *Renderer) renderAltScreen(state RenderState) string {
 
 // renderHistory renders the message history with virtualization.
 func (r *Renderer) renderHistory(state RenderState) string {
-	r.lastReasoningLineOrdinals = make(map[int]int)
-	r.lastReasoningHeaderCount = 0
 	if len(state.Messages) == 0 {
+		r.renderHistoryRange(state, 0, 0)
 		return ""
 	}
 
@@ -377,15 +424,49 @@ func (r *Renderer) renderHistory(state RenderState) string {
 	if state.Mode != RenderModeAltScreen {
 		// Inline mode keeps message-window virtualization while using scroll offset.
 		vp := NewVirtualViewport(r.width, state.Viewport.Height)
-		start, end = vp.GetVisibleRange(state.Messages, state.Viewport.ScrollOffset)
+		start, end = vp.GetVisibleRangeWithHeights(state.Messages, r.historyHeights(vp, state.Messages), state.Viewport.ScrollOffset)
+	}
+	return r.renderHistoryRange(state, start, end)
+}
+
+// RenderScrollback renders state.Messages[from:] as history blocks for
+// printing into terminal scrollback, as when leaving alt-screen mode. It goes
+// through the block cache, so blocks already rendered for the alt-screen
+// viewport are reused.
+func (r *Renderer) RenderScrollback(state RenderState, from int) string {
+	from = min(max(from, 0), len(state.Messages))
+	state.Mode = RenderModeInline
+	return r.renderHistoryRange(state, from, len(state.Messages))
+}
+
+// MessageStartLine returns the first history line of the message at index in
+// the most recent render, or false if that message did not render.
+func (r *Renderer) MessageStartLine(index int) (int, bool) {
+	if r == nil {
+		return 0, false
+	}
+	line, ok := r.lastMessageStartLines[index]
+	return line, ok
+}
+
+// renderHistoryRange renders state.Messages[start:end] using the block cache.
+func (r *Renderer) renderHistoryRange(state RenderState, start, end int) string {
+	r.lastReasoningLineOrdinals = make(map[int]int)
+	r.lastReasoningHeaderCount = 0
+	clear(r.lastCollapsedResultLines)
+	clear(r.lastMessageStartLines)
+	if start >= end {
+		return ""
 	}
 
 	// Alt-screen renders the full history into Bubble Tea's viewport. A viewport-sized
 	// cache thrashes in that mode because one render pass evicts blocks needed by
 	// the next pass. Grow the cache to cover the current rendered range (bounded by
 	// maxBlockCacheSize) so warm frames reuse rendered markdown instead of
-	// re-rendering every message on each View().
-	r.blockCache.EnsureCapacity(end - start)
+	// re-rendering every message on each View(). Room for two widths keeps the
+	// old renders around while a resize is reflowed lazily.
+	r.blockCache.EnsureCapacity(2 * (end - start))
+	blocks := r.historyBlocks(state, start, end)
 
 	// Render only visible messages using cache
 	// Skip system and tool messages (they render as empty anyway)
@@ -398,15 +479,13 @@ func (r *Renderer) renderHistory(state RenderState) string {
 	var previousLastType ui.SegmentType
 	hasPreviousRenderedSegment := false
 	for i := start; i < end; i++ {
-		msg := &state.Messages[i]
-		if msg.CompactionTail {
+		block := blocks[i-start]
+		if block == nil {
 			continue
 		}
-		// Skip non-renderable roles
-		if msg.Role != "user" && msg.Role != "assistant" && msg.Role != "event" {
-			continue
+		if reasoningOverridesAffectBlock(reasoningOrdinal, block.ReasoningCount, state.ReasoningExpansionOverrides) {
+			block = r.renderMessageBlockWithReasoningOverrides(&state.Messages[i], i, state.Messages, reasoningOrdinal, state.ReasoningExpansionOverrides)
 		}
-		block := r.getOrRenderBlock(msg, i, state.Messages, reasoningOrdinal, state.ReasoningExpansionOverrides)
 		if block.Rendered != "" {
 			if hasPreviousRenderedSegment && block.HasSegmentTypes {
 				padding := ui.NewlinePadding(trailingNewlines, ui.SegmentBoundaryTrailingNewlines(previousLastType, block.FirstSegmentType))
@@ -415,9 +494,19 @@ func (r *Renderer) renderHistory(state RenderState) string {
 				trailingNewlines += len(padding)
 			}
 			blockStartLine := lineCursor
+			if r.lastMessageStartLines == nil {
+				r.lastMessageStartLines = make(map[int]int)
+			}
+			r.lastMessageStartLines[i] = blockStartLine
 			for offsetIdx, offset := range block.ReasoningLineOffsets {
 				r.lastReasoningLineOrdinals[blockStartLine+offset] = reasoningOrdinal + offsetIdx
 			}
+			for _, offset := range block.CollapsedResultLineOffsets {
+				if r.lastCollapsedResultLines == nil {
+					r.lastCollapsedResultLines = make(map[int]int64)
+				}
+				r.lastCollapsedResultLines[blockStartLine+offset] = block.MessageID
+			}
 			b.WriteString(block.Rendered)
 			lineCursor += strings.Count(block.Rendered, "\n")
 			trailingNewlines = ui.CountTrailingNewlines(block.Rendered)
@@ -504,18 +593,103 @@ func (r *Renderer) CachedHistorySignature(messages []session.Message) uint64 {
 	return h
 }
 
-// getOrRenderBlock gets a rendered block from cache or renders it.
-func (r *Renderer) getOrRenderBlock(msg *session.Message, index int, messages []session.Message, reasoningOrdinalBase int, reasoningOverrides map[int]bool) *MessageBlock {
-	cacheKey := r.blockCacheKey(msg, index)
-	block := r.blockCache.Get(cacheKey)
-	if block == nil {
-		block = r.renderMessageBlock(msg, index, messages)
-		r.blockCache.Put(cacheKey, block)
+// historyBlocks returns the cached or freshly rendered block for each message
+// in messages[start:end], nil for messages that don't render. It walks up
+// from the end of history: in alt-screen mode, blocks more than a screen
+// above the viewport may reuse a render from an earlier width, so a resize
+// only reflows what is on or near the screen.
+func (r *Renderer) historyBlocks(state RenderState, start, end int) []*MessageBlock {
+	blocks := make([]*MessageBlock, end-start)
+	freshLines := -1
+	if state.Mode == RenderModeAltScreen {
+		freshLines = state.Viewport.LinesBelow + 2*max(state.Viewport.Height, 1)
+	}
+	r.staleBlocks = 0
+	lines := 0
+	for i := end - 1; i >= start; i-- {
+		msg := &state.Messages[i]
+		if !isHistoryBlockMessage(msg) {
+			continue
+		}
+		cacheKey := r.blockCacheKey(msg, i)
+		block, exact := r.blockCache.GetAnyWidth(cacheKey)
+		if block != nil && !exact && (freshLines < 0 || lines <= freshLines) {
+			block = nil
+		}
+		if block == nil {
+			block = r.renderMessageBlock(msg, i, state.Messages)
+			r.blockCache.Put(cacheKey, block)
+		} else if !exact {
+			r.staleBlocks++
+		}
+		blocks[i-start] = block
+		lines += block.Height
+	}
+	return blocks
+}
+
+// historyHeights returns the rendered height of every message for viewport
+// calculations: the cached height at the current width, a cached height from
+// an earlier width scaled to the current one, or an estimate.
+func (r *Renderer) historyHeights(vp *VirtualViewport, messages []session.Message) []int {
+	heights := make([]int, len(messages))
+	for i := range messages {
+		msg := &messages[i]
+		if !isHistoryBlockMessage(msg) {
+			continue
+		}
+		block, exact := r.blockCache.GetAnyWidth(r.blockCacheKey(msg, i))
+		switch {
+		case block == nil:
+			heights[i] = vp.EstimateMessageHeight(msg)
+		case exact:
+			heights[i] = block.Height
+		default:
+			heights[i] = vp.ScaleHeight(block.Height, block.Width)
+		}
+	}
+	return heights
+}
+
+// isHistoryBlockMessage reports whether a message renders as a history block.
+// System and tool messages render as empty, and compaction tails are hidden.
+func isHistoryBlockMessage(msg *session.Message) bool {
+	if msg.CompactionTail {
+		return false
 	}
-	if !reasoningOverridesAffectBlock(reasoningOrdinalBase, block.ReasoningCount, reasoningOverrides) {
-		return block
+	return msg.Role == "user" || msg.Role == "assistant" || msg.Role == "event"
+}
+
+// StaleBlocks returns how many blocks the last render reused from an earlier
+// width. The caller can reflow them in the background with RefreshStaleBlocks.
+func (r *Renderer) StaleBlocks() int {
+	return r.staleBlocks
+}
+
+// RefreshStaleBlocks reflows up to limit cached blocks that were last
+// rendered at an earlier width, nearest the end of history first, and returns
+// how many it reflowed. messages must be the slice last passed to Render.
+func (r *Renderer) RefreshStaleBlocks(messages []session.Message, limit int) int {
+	refreshed := 0
+	for i := len(messages) - 1; i >= 0 && refreshed < limit; i-- {
+		msg := &messages[i]
+		if !isHistoryBlockMessage(msg) {
+			continue
+		}
+		cacheKey := r.blockCacheKey(msg, i)
+		if block, exact := r.blockCache.GetAnyWidth(cacheKey); block == nil || exact {
+			continue
+		}
+		r.blockCache.Put(cacheKey, r.renderMessageBlock(msg, i, messages))
+		refreshed++
+	}
+	if refreshed < limit {
+		// The whole history was scanned.
+		r.staleBlocks = 0
+	} else {
+		r.staleBlocks = max(r.staleBlocks-refreshed, 0)
 	}
-	return r.renderMessageBlockWithReasoningOverrides(msg, index, messages, reasoningOrdinalBase, reasoningOverrides)
+	return refreshed
 }
 
 func reasoningOverridesAffectBlock(baseOrdinal, reasoningCount int, overrides map[int]bool) bool {
@@ -539,6 +713,7 @@ func (r *Renderer) blockCacheKey(msg *session.Message, index int) blockCacheKey
 		messageID:      msg.ID,
 		width:          r.width,
 		toolsExpanded:  r.toolsExpanded,
+		showStats:      r.showStats,
 		partsSignature: r.cachedPartsSignature(msg),
 	}
 }
@@ -701,6 +876,8 @@ func (r *Renderer) renderMessageBlockWithReasoningOverrides(msg *session.Message
 	rb.SetImageRenderer(r.imageRenderer)
 	rb.SetReasoningConfig(r.reasoningConfig)
 	rb.SetReasoningExpansionOverrides(reasoningOrdinalBase, reasoningOverrides)
+	rb.SetToolResultsExpanded(r.expandedToolResults[msg.ID])
+	rb.SetShowStats(r.showStats)
 	return rb.Render(msg)
 }
 
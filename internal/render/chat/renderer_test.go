@@ -132,6 +132,44 @@ func TestRenderer_BlockCacheKeyChangesWhenMessageContentChanges(t *testing.T) {
 	}
 }
 
+func TestRenderer_ShowStatsAnnotatesAssistantMessages(t *testing.T) {
+	renderer := NewRenderer(80, 24)
+	renderer.SetMarkdownRenderer(simpleMarkdownRenderer)
+
+	msg := session.Message{
+		ID:           7,
+		Role:         llm.RoleAssistant,
+		TextContent:  "reply",
+		Parts:        []llm.Part{{Type: llm.PartText, Text: "reply"}},
+		DurationMs:   4200,
+		InputTokens:  1832,
+		OutputTokens: 412,
+		Model:        "gpt-5.2-codex",
+	}
+	state := RenderState{
+		Messages: []session.Message{msg},
+		Viewport: ViewportState{Height: 24},
+		Mode:     RenderModeAltScreen,
+		Width:    80,
+		Height:   24,
+	}
+	const stats = "4.2s · 1,832 in / 412 out · gpt-5.2-codex"
+
+	if out := ui.StripANSI(renderer.Render(state)); strings.Contains(out, stats) {
+		t.Fatalf("stats shown while disabled:\n%s", out)
+	}
+	hidden := renderer.blockCacheKey(&msg, 0)
+
+	renderer.SetShowStats(true)
+	renderer.InvalidateCache()
+	if out := ui.StripANSI(renderer.Render(state)); !strings.Contains(out, stats) {
+		t.Fatalf("stats line %q missing when enabled:\n%s", stats, out)
+	}
+	if renderer.blockCacheKey(&msg, 0) == hidden {
+		t.Fatal("block cache key does not include the stats mode")
+	}
+}
+
 func TestHistorySignatureChangesWhenSameCountMessagesChange(t *testing.T) {
 	messages := []session.Message{
 		{ID: 1, Role: llm.RoleUser, TextContent: "prompt", Parts: []llm.Part{{Type: llm.PartText, Text: "prompt"}}, Sequence: 0},
@@ -378,40 +416,81 @@ func TestRenderer_AltScreenLargeHistoryCacheDoesNotThrash(t *testing.T) {
 	}
 }
 
-func TestRenderer_CacheInvalidateOnResize(t *testing.T) {
+func TestRenderer_ResizeReflowsVisibleBlocksLazily(t *testing.T) {
 	renderer := NewRenderer(80, 24)
-	renderer.SetMarkdownRenderer(simpleMarkdownRenderer)
+	var widths []int
+	renderer.SetMarkdownRenderer(func(content string, width int) string {
+		widths = append(widths, width)
+		return simpleMarkdownRenderer(content, width)
+	})
 
-	messages := generateMessages(5)
+	messages := generateMessages(120)
 	state := RenderState{
 		Messages: messages,
-		Viewport: ViewportState{Height: 24},
+		Viewport: ViewportState{Height: 24, AtBottom: true},
 		Mode:     RenderModeAltScreen,
 		Width:    80,
 		Height:   24,
 	}
+	renderer.Render(state)
+	fullRenderCalls := len(widths)
+	if renderer.StaleBlocks() != 0 {
+		t.Fatalf("first render reported %d stale blocks", renderer.StaleBlocks())
+	}
 
-	// First render
-	_ = renderer.Render(state)
-	cacheSize1 := renderer.blockCache.Size()
+	renderer.SetSize(100, 24)
+	state.Width = 100
+	widths = nil
+	output := renderer.Render(state)
+	resizeCalls := len(widths)
+	if resizeCalls == 0 || resizeCalls >= fullRenderCalls {
+		t.Fatalf("resize render made %d markdown calls, want only the blocks near the viewport", len(widths))
+	}
+	for _, w := range widths {
+		if w == 80 {
+			t.Fatal("resize render should not re-render at the old width")
+		}
+	}
+	if !strings.Contains(output, "user message 0") || !strings.Contains(output, "assistant message 119") {
+		t.Fatal("resize render should still include the whole history")
+	}
+	if renderer.StaleBlocks() == 0 {
+		t.Fatal("expected off-screen blocks to keep their old-width render")
+	}
+
+	widths = nil
+	for renderer.RefreshStaleBlocks(messages, 10) > 0 {
+	}
+	if len(widths) != fullRenderCalls-resizeCalls {
+		t.Fatalf("background refresh made %d markdown calls, want the remaining %d", len(widths), fullRenderCalls-resizeCalls)
+	}
+	widths = nil
+	renderer.Render(state)
+	if len(widths) != 0 || renderer.StaleBlocks() != 0 {
+		t.Fatalf("render after refresh made %d markdown calls with %d stale blocks, want a fully warm cache", len(widths), renderer.StaleBlocks())
+	}
+}
 
-	// Resize
-	renderer.SetSize(100, 30)
+func TestRenderer_ScrolledUpResizeReflowsTheViewport(t *testing.T) {
+	renderer := NewRenderer(80, 24)
+	renderer.SetMarkdownRenderer(simpleMarkdownRenderer)
 
-	// Cache should be invalidated
-	cacheSize2 := renderer.blockCache.Size()
-	if cacheSize2 != 0 {
-		t.Errorf("Cache should be empty after resize, got %d", cacheSize2)
+	messages := generateMessages(120)
+	state := RenderState{
+		Messages: messages,
+		Viewport: ViewportState{Height: 24, AtBottom: true},
+		Mode:     RenderModeAltScreen,
+		Width:    80,
+		Height:   24,
 	}
+	renderer.Render(state)
 
-	// Render again with new size
+	renderer.SetSize(100, 24)
 	state.Width = 100
-	state.Height = 30
-	_ = renderer.Render(state)
-	cacheSize3 := renderer.blockCache.Size()
-
-	if cacheSize3 != cacheSize1 {
-		t.Logf("Cache rebuilt after resize: %d entries", cacheSize3)
+	state.Viewport = ViewportState{Height: 24, LinesBelow: 1 << 20}
+	renderer.Render(state)
+	if got := renderer.StaleBlocks(); got != 0 {
+		t.Fatalf("viewport at the top of history left %d stale blocks", got)
 	}
 }
 
@@ -441,6 +520,64 @@ func TestRenderer_RenderAltScreen_IncludesFullHistory(t *testing.T) {
 	}
 }
 
+func TestRenderer_ScreenModeRoundTripKeepsEveryBlockOnce(t *testing.T) {
+	renderer := NewRenderer(80, 24)
+	renderer.SetMarkdownRenderer(simpleMarkdownRenderer)
+
+	messages := generateMessages(10)
+	altState := func(msgs []session.Message) RenderState {
+		return RenderState{
+			Messages: msgs,
+			Viewport: ViewportState{Height: 24, AtBottom: true},
+			Mode:     RenderModeAltScreen,
+			Width:    80,
+			Height:   24,
+		}
+	}
+
+	// Inline: the first six messages are already in scrollback.
+	scrollback := renderer.RenderScrollback(altState(messages[:6]), 0)
+
+	// Alt-screen: the full history is rebuilt and four more messages arrive.
+	entered := renderer.Render(altState(messages[:6]))
+	if entered != scrollback {
+		t.Fatalf("alt-screen history differs from the scrollback it replaces:\n%q\n%q", entered, scrollback)
+	}
+	altFull := renderer.Render(altState(messages))
+
+	// Back to inline: only the messages added in alt-screen are flushed.
+	scrollback += "\n" + renderer.RenderScrollback(altState(messages), 6)
+
+	for i := range messages {
+		marker := fmt.Sprintf("user message %d with", i)
+		if i%2 == 1 {
+			marker = fmt.Sprintf("assistant message %d.", i)
+		}
+		if got := strings.Count(scrollback, marker); got != 1 {
+			t.Fatalf("scrollback contains %q %d times, want once", marker, got)
+		}
+	}
+
+	// Alt-screen again: the rebuilt view matches the one left behind.
+	if again := renderer.Render(altState(messages)); again != altFull {
+		t.Fatal("re-entering alt-screen rendered different history")
+	}
+	prev := -1
+	for i := range messages {
+		line, ok := renderer.MessageStartLine(i)
+		if !ok {
+			t.Fatalf("MessageStartLine(%d) not recorded", i)
+		}
+		if line <= prev {
+			t.Fatalf("MessageStartLine(%d) = %d, want > %d", i, line, prev)
+		}
+		prev = line
+	}
+	if _, ok := renderer.MessageStartLine(len(messages)); ok {
+		t.Fatal("MessageStartLine reported a message that was not rendered")
+	}
+}
+
 func TestRenderer_HistoricalToolCallUsesErrorCircleForFailedResult(t *testing.T) {
 	renderer := NewRenderer(80, 24)
 	renderer.SetMarkdownRenderer(simpleMarkdownRenderer)
@@ -676,6 +813,31 @@ func TestVirtualViewport_GetVisibleRange(t *testing.T) {
 	}
 }
 
+func TestVirtualViewport_ScaleHeight(t *testing.T) {
+	vp := NewVirtualViewport(100, 24)
+	tests := []struct {
+		name          string
+		height        int
+		renderedWidth int
+		want          int
+	}{
+		{"same width", 10, 100, 10},
+		{"unknown width", 10, 0, 10},
+		{"rendered narrower", 10, 50, 5},
+		{"rendered wider", 10, 200, 20},
+		{"rounds up", 3, 150, 5},
+		{"never collapses", 1, 10, 1},
+		{"empty", 0, 50, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := vp.ScaleHeight(tt.height, tt.renderedWidth); got != tt.want {
+				t.Errorf("ScaleHeight(%d, %d) = %d, want %d", tt.height, tt.renderedWidth, got, tt.want)
+			}
+		})
+	}
+}
+
 // BenchmarkRender500Messages benchmarks rendering 500 messages.
 // Target: <16ms for 60fps rendering.
 func BenchmarkRender500Messages(b *testing.B) {
@@ -728,6 +890,51 @@ func BenchmarkRender500MessagesCached(b *testing.B) {
 	}
 }
 
+// BenchmarkResize1000Messages compares the first alt-screen render after a
+// width change when every block is reflowed with reflowing only the blocks
+// near the viewport.
+func BenchmarkResize1000Messages(b *testing.B) {
+	messages := generateMessages(1000)
+	state := RenderState{
+		Messages: messages,
+		Viewport: ViewportState{Height: 40, AtBottom: true},
+		Mode:     RenderModeAltScreen,
+		Width:    120,
+		Height:   40,
+	}
+	widths := []int{120, 100}
+
+	b.Run("full-reflow", func(b *testing.B) {
+		renderer := NewRenderer(120, 40)
+		renderer.SetMarkdownRenderer(simpleMarkdownRenderer)
+		renderer.Render(state)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			renderer.SetSize(widths[i%2], 40)
+			renderer.InvalidateCache()
+			renderer.Render(state)
+		}
+	})
+
+	b.Run("lazy", func(b *testing.B) {
+		renderer := NewRenderer(120, 40)
+		renderer.SetMarkdownRenderer(simpleMarkdownRenderer)
+		renderer.Render(state)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			// Each iteration starts with the whole history cached at the
+			// other width, as after a long idle stretch at that size.
+			renderer.InvalidateCache()
+			renderer.SetSize(widths[(i+1)%2], 40)
+			renderer.Render(state)
+			b.StartTimer()
+			renderer.SetSize(widths[i%2], 40)
+			renderer.Render(state)
+		}
+	})
+}
+
 func BenchmarkMessageHistorySignature500(b *testing.B) {
 	messages := generateMessages(500)
 	b.ReportAllocs()
@@ -1267,3 +1474,121 @@ func TestRendererReasoningOverrideIgnoresHiddenUnknownReasoningForOrdinal(t *tes
 		t.Fatalf("unknown reasoning should remain hidden, got %q", expanded)
 	}
 }
+
+// toolTurnMessages returns an assistant tool call followed by its result.
+func toolTurnMessages(id int64, name, args, content string) []session.Message {
+	callID := fmt.Sprintf("call-%d", id)
+	return []session.Message{
+		{
+			ID:   id,
+			Role: llm.RoleAssistant,
+			Parts: []llm.Part{{
+				Type:     llm.PartToolCall,
+				ToolCall: &llm.ToolCall{ID: callID, Name: name, Arguments: []byte(args)},
+			}},
+		},
+		{
+			ID:   id + 1,
+			Role: llm.RoleTool,
+			Parts: []llm.Part{{
+				Type:       llm.PartToolResult,
+				ToolResult: &llm.ToolResult{ID: callID, Name: name, Content: content},
+			}},
+		},
+	}
+}
+
+func numberedLines(prefix string, n int) string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%s %d", prefix, i+1)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestMessageBlockRenderer_CollapsesLongToolResults(t *testing.T) {
+	messages := toolTurnMessages(1, "shell", `{"command":"make"}`, numberedLines("out", 400))
+
+	hidden := NewMessageBlockRendererWithContext(80, simpleMarkdownRenderer, messages, 0, false).Render(&messages[0])
+	if strings.Contains(ui.StripANSI(hidden.Rendered), "out 1") {
+		t.Fatalf("tool output should only show with tools expanded, got:\n%s", ui.StripANSI(hidden.Rendered))
+	}
+
+	rb := NewMessageBlockRendererWithContext(80, simpleMarkdownRenderer, messages, 0, true)
+	block := rb.Render(&messages[0])
+	plain := ui.StripANSI(block.Rendered)
+	for _, want := range []string{"out 1\n", "out 4\n", "(+392 lines, press alt+o to expand)", "out 397\n", "out 400\n"} {
+		if !strings.Contains(plain, want) {
+			t.Errorf("collapsed output missing %q:\n%s", want, plain)
+		}
+	}
+	if strings.Contains(plain, "out 5\n") || strings.Contains(plain, "out 396\n") {
+		t.Errorf("collapsed output should hide the middle lines:\n%s", plain)
+	}
+	if len(block.CollapsedResultLineOffsets) != 1 {
+		t.Fatalf("CollapsedResultLineOffsets = %v, want one marker", block.CollapsedResultLineOffsets)
+	}
+	if line := strings.Split(plain, "\n")[block.CollapsedResultLineOffsets[0]]; !strings.Contains(line, "press alt+o to expand") {
+		t.Errorf("marker offset points at %q", line)
+	}
+
+	rb.SetToolResultsExpanded(true)
+	full := rb.Render(&messages[0])
+	if !strings.Contains(ui.StripANSI(full.Rendered), "out 200\n") || len(full.CollapsedResultLineOffsets) != 0 {
+		t.Errorf("expanded output should show every line")
+	}
+}
+
+func TestMessageBlockRenderer_EditDiffsAreNeverCollapsed(t *testing.T) {
+	oldText := numberedLines("old", 30)
+	newText := numberedLines("new", 30)
+	messages := toolTurnMessages(1, "edit_file", `{"path":"a.go"}`, "Edit applied.")
+	messages[1].Parts[0].ToolResult.Diffs = []llm.DiffData{{File: "a.go", Old: oldText, New: newText, Line: 1}}
+
+	block := NewMessageBlockRendererWithContext(80, simpleMarkdownRenderer, messages, 0, true).Render(&messages[0])
+	plain := ui.StripANSI(block.Rendered)
+	if strings.Contains(plain, "press alt+o to expand") || strings.Contains(plain, "Edit applied.") {
+		t.Fatalf("edit_file should show its diff, not collapsed output:\n%s", plain)
+	}
+	if !strings.Contains(plain, "new 15") {
+		t.Fatalf("diff should be fully visible:\n%s", plain)
+	}
+}
+
+func TestRenderer_ExpandToolResultsRerendersOnlyThatBlock(t *testing.T) {
+	renderer := NewRenderer(80, 200)
+	renderer.SetMarkdownRenderer(simpleMarkdownRenderer)
+	renderer.SetToolsExpanded(true)
+	messages := append(
+		toolTurnMessages(1, "shell", `{"command":"a"}`, numberedLines("first", 50)),
+		toolTurnMessages(3, "shell", `{"command":"b"}`, numberedLines("second", 50))...,
+	)
+	state := RenderState{Messages: messages, Mode: RenderModeAltScreen, Width: 80, Height: 200}
+	out := ui.StripANSI(renderer.Render(state))
+	if strings.Count(out, "press alt+o to expand") != 2 {
+		t.Fatalf("expected two collapsed results:\n%s", out)
+	}
+
+	messageID, ok := renderer.LastCollapsedToolResult(0, strings.Count(out, "\n"))
+	if !ok || messageID != 3 {
+		t.Fatalf("LastCollapsedToolResult = %d, %v; want the lower result (message 3)", messageID, ok)
+	}
+	if _, ok := renderer.LastCollapsedToolResult(0, 2); ok {
+		t.Fatal("no marker should be found above the first marker line")
+	}
+
+	firstKey := renderer.blockCacheKey(&messages[0], 0)
+	firstBlock := renderer.blockCache.Get(firstKey)
+	renderer.ExpandToolResults(messageID)
+	if renderer.blockCache.Get(firstKey) != firstBlock {
+		t.Fatal("expanding one message should keep other cached blocks")
+	}
+	if renderer.blockCache.Get(renderer.blockCacheKey(&messages[2], 2)) != nil {
+		t.Fatal("expanding a message should drop its cached block")
+	}
+
+	out = ui.StripANSI(renderer.Render(state))
+	if strings.Count(out, "press alt+o to expand") != 1 || !strings.Contains(out, "second 25\n") || strings.Contains(out, "first 25\n") {
+		t.Fatalf("only the second result should be expanded:\n%s", out)
+	}
+}
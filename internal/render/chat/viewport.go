@@ -154,6 +154,20 @@ func (v *VirtualViewport) EstimateMessageHeight(msg *session.Message) int {
 	}
 }
 
+// ScaleHeight estimates the height at the viewport's width of a block that
+// was rendered at renderedWidth, such as one cached before a resize. Wrapped
+// text grows as the width shrinks, so the height scales with the width ratio.
+func (v *VirtualViewport) ScaleHeight(height, renderedWidth int) int {
+	if height <= 0 || renderedWidth <= 0 || v.width <= 0 || renderedWidth == v.width {
+		return height
+	}
+	scaled := (height*renderedWidth + v.width - 1) / v.width
+	if scaled < 1 {
+		scaled = 1
+	}
+	return scaled
+}
+
 // CalculateTotalHeight calculates the total height of all messages.
 // This is used for scroll calculations.
 func (v *VirtualViewport) CalculateTotalHeight(heights []int) int {
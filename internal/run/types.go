@@ -81,6 +81,12 @@ type Request struct {
 	MCP        string
 	Skills     string
 
+	// AllowedTools and DeniedTools filter the final tool surface, including
+	// MCP, skill and spawn_agent tools, and carry over to sub-agents. A nil
+	// AllowedTools means no allowlist.
+	AllowedTools []string
+	DeniedTools  []string
+
 	SystemMessage               string
 	MaxTurns                    int
 	MaxTurnsSet                 bool
@@ -108,6 +114,7 @@ type Request struct {
 	OnResponseCompleted    llm.ResponseCompletedCallback
 	OnTurnCompleted        llm.TurnCompletedCallback
 	OnCompaction           llm.CompactionCallback
+	OnToolExecuted         llm.ToolExecutedCallback
 	OnSyntheticUserMessage func(context.Context, llm.Message) error
 	OnEngineReady          func(*llm.Engine)
 	OnEngineDone           func(*llm.Engine)
@@ -37,11 +37,15 @@ func (w *streamingResponseWriter) Write(p []byte) (int, error) {
 }
 
 func (w *streamingResponseWriter) Flush() {
+	_ = w.FlushError()
+}
+
+// FlushError flushes like Flush but reports failures, such as the write
+// deadline expiring on a client that stopped reading.
+func (w *streamingResponseWriter) FlushError() error {
 	w.setDeadline()
 	defer w.clearDeadline()
-	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
-		flusher.Flush()
-	}
+	return w.controller.Flush()
 }
 
 func (w *streamingResponseWriter) setDeadline() {
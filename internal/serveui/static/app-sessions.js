@@ -10,7 +10,7 @@ const {
   clearActiveResponseTracking, setStreaming, resumeActiveResponse, renderSidebar, renderMessages, renderProviderOptions, renderModelOptions, normalizeSelectedProvider,
   autoGrowPrompt, updateVoiceUI, toggleVoiceRecording, fetchProviders, fetchModels, addErrorMessage, sendMessage, openSidebar, closeSidebar, closeSidebarIfMobile,
   connectToken, submitAskUserModal, cancelActiveResponse, handleFiles, noteUserScrollIntent, noteScrollPositionChanged, shouldDisableAutoScrollForKey,
-  openApprovalModal, closeApprovalModal, submitApprovalModal, registerServiceWorker, subscribeToPush, refreshNotificationUI,
+  openApprovalModal, approvalPromptDetails, closeApprovalModal, submitApprovalModal, registerServiceWorker, subscribeToPush, refreshNotificationUI,
   requestNotificationPermission, shouldAutoSubscribeToPush, detachResponseStream, HEARTBEAT_STALE_THRESHOLD,
   applyDesktopSidebarState, toggleSidebarCollapsed, flushStreamPersistence, requestHeaders, normalizeError, discardPendingAttachments,
   updateSidebarStatus, sessionHasInProgressState, hasAnySessionInProgressState, setSessionServerActiveRun, setSessionOptimisticBusy,
@@ -930,7 +930,7 @@ const convertServerMessages = (serverMessages, options = {}) => {
         }, msg));
         continue;
       }
-      const marker = parts.find((part) => part.type === 'model_swap') || parts.find((part) => part.type === 'text');
+      const marker = parts.find((part) => part.type === 'model_swap' || part.type === 'interrupted') || parts.find((part) => part.type === 'text');
       result.push(addDurableSource({
         id: baseId,
         role: 'model-swap',
@@ -1887,7 +1887,8 @@ const syncActiveSessionFromServer = async (session, pollOnActive = false, { skip
       && state.approval.approvalId === pendingApproval.approval_id;
     if (!sameApproval) {
       openApprovalModal(session.id, pendingApproval.approval_id, pendingApproval.path,
-        pendingApproval.is_shell, pendingApproval.title, pendingApproval.options);
+        pendingApproval.is_shell, pendingApproval.title, pendingApproval.options,
+        approvalPromptDetails(pendingApproval));
     }
   } else if (state.approval?.sessionId === session.id) {
     closeApprovalModal();
@@ -1137,6 +1137,15 @@ const applyResponseStreamEvent = (session, streamState, event, payload) => {
     return { terminal: false };
   }
 
+  if (event === 'server_shutting_down') {
+    const message = String(payload?.message || '').trim() || 'Server is shutting down…';
+    const responseId = responseStreamOwnerId(session, payload);
+    if (responseId) {
+      setProviderRetryStatus(String(session?.id || '').trim(), responseId, message);
+    }
+    return { terminal: false };
+  }
+
   if (event === 'response.output_text.new_segment') {
     clearProviderRetryForEvent(session, payload);
     streamState.closeToolGroup();
@@ -1280,7 +1289,7 @@ const applyResponseStreamEvent = (session, streamState, event, payload) => {
     const approvalId = String(payload.approval_id || '').trim();
     const options = Array.isArray(payload.options) ? payload.options : [];
     if (approvalId && options.length > 0) {
-      openApprovalModal(session.id, approvalId, payload.path, payload.is_shell, payload.title, options);
+      openApprovalModal(session.id, approvalId, payload.path, payload.is_shell, payload.title, options, approvalPromptDetails(payload));
     }
     return { terminal: false };
   }
@@ -2882,8 +2891,43 @@ const submitAskUserModal = async (cancelled = false) => {
 };
 
 // ===== Approval modal =====
-const openApprovalModal = (sessionId, approvalId, path, isShell, title, options) => {
-  state.approval = { sessionId, approvalId, path, isShell, title, options, selectedIndex: 0 };
+const approvalPromptDetails = (payload) => ({
+  toolName: String(payload?.tool_name || ''),
+  args: String(payload?.args || ''),
+  diff: String(payload?.diff || '')
+});
+
+// Renders the tool call and, for edits, the proposed unified diff so remote
+// users can see what they are approving.
+const renderApprovalDetails = (container, details) => {
+  if (!details) return;
+  if (details.toolName || details.args) {
+    const call = document.createElement('div');
+    call.className = 'approval-call';
+    call.textContent = `${details.toolName}${details.args ? ' ' + details.args : ''}`.trim();
+    container.appendChild(call);
+  }
+  if (!details.diff) return;
+  const pre = document.createElement('pre');
+  pre.className = 'approval-diff';
+  // File headers only precede the first hunk; inside one, a removed "-- x"
+  // or added "++ x" line looks just like them.
+  let inHunk = false;
+  details.diff.split('\n').forEach((line) => {
+    if (line.startsWith('@@')) inHunk = true;
+    if (!inHunk && (line.startsWith('--- ') || line.startsWith('+++ '))) return;
+    const row = document.createElement('span');
+    if (line.startsWith('@@')) row.className = 'approval-diff-hunk';
+    else if (line.startsWith('+')) row.className = 'approval-diff-add';
+    else if (line.startsWith('-')) row.className = 'approval-diff-del';
+    row.textContent = line + '\n';
+    pre.appendChild(row);
+  });
+  container.appendChild(pre);
+};
+
+const openApprovalModal = (sessionId, approvalId, path, isShell, title, options, details = null) => {
+  state.approval = { sessionId, approvalId, path, isShell, title, options, details, selectedIndex: 0 };
 
   elements.approvalTitle.textContent = title || 'Access Request';
   elements.approvalPath.textContent = path || '';
@@ -2896,6 +2940,7 @@ const openApprovalModal = (sessionId, approvalId, path, isShell, title, options)
   // Build radio options as a vertical list
   const body = elements.approvalBody;
   body.innerHTML = '';
+  renderApprovalDetails(body, details);
   const group = document.createElement('div');
   group.className = 'approval-options';
   options.forEach((opt, i) => {
@@ -2960,7 +3005,10 @@ const submitApprovalModal = async (denied = false) => {
   const denyOpt = prompt.options.find(o => o.choice === 'deny');
   const denyIndex = denyOpt ? denyOpt.index : prompt.options.length - 1;
   const choiceIndex = denied ? denyIndex : prompt.selectedIndex;
-  const body = { approval_id: prompt.approvalId, choice: choiceIndex };
+  const selected = prompt.options.find(o => o.index === choiceIndex);
+  const body = !denied && selected?.choice === 'directory'
+    ? { approval_id: prompt.approvalId, always_allow_directory: true }
+    : { approval_id: prompt.approvalId, choice: choiceIndex };
 
   try {
     const response = await fetch(`${UI_PREFIX}/v1/sessions/${encodeURIComponent(prompt.sessionId)}/approval`, {
@@ -5669,6 +5717,7 @@ Object.assign(app, {
   cancelActiveResponse,
   closeAskUserModal,
   openApprovalModal,
+  approvalPromptDetails,
   closeApprovalModal,
   submitApprovalModal,
   askUserSummaryFromAnswers,
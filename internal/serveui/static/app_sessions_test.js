@@ -276,6 +276,7 @@ function defaultAppStubs(app, overrides = {}) {
     cancelActiveResponse: async () => {},
     handleFiles() {},
     openApprovalModal() {},
+    approvalPromptDetails() { return null; },
     closeApprovalModal() {},
     submitApprovalModal: async () => {},
     registerServiceWorker: async () => null,
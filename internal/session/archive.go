@@ -0,0 +1,249 @@
+package session
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+// ArchiveVersion is the format version written by NewArchive.
+const ArchiveVersion = 1
+
+// Archive is the JSON export format for a session. Messages carry their full
+// Parts in the same encoding as Message.PartsJSON, together with sequences,
+// durations and timestamps, so ImportArchive can recreate the transcript.
+type Archive struct {
+	Version    int           `json:"version"`
+	ExportedAt time.Time     `json:"exported_at"`
+	Session    *Session      `json:"session"`
+	Messages   []Message     `json:"messages"`
+	BlobDir    string        `json:"blob_dir,omitempty"` // Sidecar directory for extracted blobs, relative to the archive file
+	Blobs      []ArchiveBlob `json:"blobs,omitempty"`
+}
+
+// ArchiveBlob records base64 data moved out of a message part into a sidecar
+// file by Archive.ExtractBlobs.
+type ArchiveBlob struct {
+	Sequence    int    `json:"sequence"`
+	Part        int    `json:"part"`
+	ContentPart int    `json:"content_part"` // Index into ToolResult.ContentParts, or -1 for the part's own image/file data
+	Path        string `json:"path"`         // File name inside BlobDir
+}
+
+// SessionImporter is an optional Store capability for recreating an archived
+// session with its original message sequences and compaction boundary.
+type SessionImporter interface {
+	Import(ctx context.Context, sess *Session, messages []Message) (*Session, error)
+}
+
+// NewArchive builds a JSON export of sess. Tool handling follows opts
+// (OmitTools, MaxToolResultChars); everything else is kept verbatim.
+func NewArchive(sess *Session, messages []Message, opts ExportOptions) *Archive {
+	return &Archive{
+		Version:    ArchiveVersion,
+		ExportedAt: time.Now(),
+		Session:    sess,
+		Messages:   applyExportToolOptions(messages, opts),
+	}
+}
+
+// ParseArchive decodes an archive written by NewArchive.
+func ParseArchive(data []byte) (*Archive, error) {
+	var archive Archive
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return nil, fmt.Errorf("parse session archive: %w", err)
+	}
+	if archive.Version < 1 || archive.Version > ArchiveVersion {
+		return nil, fmt.Errorf("unsupported session archive version %d", archive.Version)
+	}
+	if archive.Session == nil {
+		return nil, fmt.Errorf("session archive has no session")
+	}
+	return &archive, nil
+}
+
+// ExtractBlobs moves inline base64 image and file data into files under dir,
+// leaving the parts in the archive with empty Base64 fields.
+func (a *Archive) ExtractBlobs(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create blob directory: %w", err)
+	}
+	write := func(seq, part, contentPart int, mediaType, data string) error {
+		raw, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return fmt.Errorf("decode blob in message %d: %w", seq, err)
+		}
+		name := fmt.Sprintf("%d-%d", seq, part)
+		if contentPart >= 0 {
+			name += fmt.Sprintf("-%d", contentPart)
+		}
+		name += blobExtension(mediaType)
+		if err := os.WriteFile(filepath.Join(dir, name), raw, 0644); err != nil {
+			return fmt.Errorf("write blob: %w", err)
+		}
+		a.Blobs = append(a.Blobs, ArchiveBlob{Sequence: seq, Part: part, ContentPart: contentPart, Path: name})
+		return nil
+	}
+
+	// Messages and their parts may share backing arrays with the slice the
+	// archive was built from; copy them so the caller's messages keep their data.
+	a.Messages = append([]Message(nil), a.Messages...)
+	for i := range a.Messages {
+		msg := &a.Messages[i]
+		msg.Parts = append([]llm.Part(nil), msg.Parts...)
+		for j := range msg.Parts {
+			part := &msg.Parts[j]
+			if part.ImageData != nil && part.ImageData.Base64 != "" {
+				if err := write(msg.Sequence, j, -1, part.ImageData.MediaType, part.ImageData.Base64); err != nil {
+					return err
+				}
+				data := *part.ImageData
+				data.Base64 = ""
+				part.ImageData = &data
+			}
+			if part.FileData != nil && part.FileData.Base64 != "" {
+				if err := write(msg.Sequence, j, -1, part.FileData.MediaType, part.FileData.Base64); err != nil {
+					return err
+				}
+				data := *part.FileData
+				data.Base64 = ""
+				part.FileData = &data
+			}
+			if part.ToolResult == nil {
+				continue
+			}
+			for k, cp := range part.ToolResult.ContentParts {
+				if cp.ImageData == nil || cp.ImageData.Base64 == "" {
+					continue
+				}
+				if err := write(msg.Sequence, j, k, cp.ImageData.MediaType, cp.ImageData.Base64); err != nil {
+					return err
+				}
+				result := *part.ToolResult
+				result.ContentParts = append([]llm.ToolContentPart(nil), result.ContentParts...)
+				data := *cp.ImageData
+				data.Base64 = ""
+				result.ContentParts[k].ImageData = &data
+				part.ToolResult = &result
+			}
+		}
+	}
+	a.BlobDir = dir
+	return nil
+}
+
+// RestoreBlobs reads extracted blobs back into their parts. A relative
+// BlobDir is resolved against baseDir, normally the archive file's directory.
+func (a *Archive) RestoreBlobs(baseDir string) error {
+	if len(a.Blobs) == 0 {
+		return nil
+	}
+	dir := a.BlobDir
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(baseDir, dir)
+	}
+	bySeq := make(map[int]int, len(a.Messages))
+	for i, msg := range a.Messages {
+		bySeq[msg.Sequence] = i
+	}
+	for _, blob := range a.Blobs {
+		idx, ok := bySeq[blob.Sequence]
+		if !ok || blob.Part < 0 || blob.Part >= len(a.Messages[idx].Parts) {
+			return fmt.Errorf("blob %s does not match a message part", blob.Path)
+		}
+		raw, err := os.ReadFile(filepath.Join(dir, filepath.Base(blob.Path)))
+		if err != nil {
+			return fmt.Errorf("read blob: %w", err)
+		}
+		data := base64.StdEncoding.EncodeToString(raw)
+		part := &a.Messages[idx].Parts[blob.Part]
+		switch {
+		case blob.ContentPart >= 0:
+			if part.ToolResult == nil || blob.ContentPart >= len(part.ToolResult.ContentParts) || part.ToolResult.ContentParts[blob.ContentPart].ImageData == nil {
+				return fmt.Errorf("blob %s does not match a tool result image", blob.Path)
+			}
+			part.ToolResult.ContentParts[blob.ContentPart].ImageData.Base64 = data
+		case part.ImageData != nil:
+			part.ImageData.Base64 = data
+		case part.FileData != nil:
+			part.FileData.Base64 = data
+		default:
+			return fmt.Errorf("blob %s does not match an image or file part", blob.Path)
+		}
+	}
+	return nil
+}
+
+// ImportArchive recreates the archived session in store under a new ID and
+// session number. Messages keep their archived sequence numbers.
+func ImportArchive(ctx context.Context, store Store, archive *Archive) (*Session, error) {
+	importer, ok := store.(SessionImporter)
+	if !ok {
+		return nil, fmt.Errorf("session store does not support import")
+	}
+	messages := append([]Message(nil), archive.Messages...)
+	sort.SliceStable(messages, func(i, j int) bool { return messages[i].Sequence < messages[j].Sequence })
+	for i, msg := range messages {
+		if msg.Sequence < 0 {
+			return nil, fmt.Errorf("message %d has negative sequence %d", i, msg.Sequence)
+		}
+		if i > 0 && messages[i-1].Sequence == msg.Sequence {
+			return nil, fmt.Errorf("duplicate message sequence %d", msg.Sequence)
+		}
+	}
+	return importer.Import(ctx, archive.Session, messages)
+}
+
+// applyExportToolOptions returns messages with tool parts removed or their
+// results shortened according to opts. The input slice is not modified.
+func applyExportToolOptions(messages []Message, opts ExportOptions) []Message {
+	if !opts.OmitTools && opts.MaxToolResultChars <= 0 {
+		return messages
+	}
+	out := make([]Message, 0, len(messages))
+	for _, msg := range messages {
+		parts := make([]llm.Part, 0, len(msg.Parts))
+		for _, part := range msg.Parts {
+			if opts.OmitTools && (part.Type == llm.PartToolCall || part.Type == llm.PartToolResult) {
+				continue
+			}
+			if part.ToolResult != nil && opts.MaxToolResultChars > 0 {
+				result := *part.ToolResult
+				result.Content = llm.TruncateToolResult(result.Content, opts.MaxToolResultChars)
+				part.ToolResult = &result
+			}
+			parts = append(parts, part)
+		}
+		if len(parts) == 0 && len(msg.Parts) > 0 {
+			continue
+		}
+		msg.Parts = parts
+		out = append(out, msg)
+	}
+	return out
+}
+
+func blobExtension(mediaType string) string {
+	switch mediaType {
+	case "image/png":
+		return ".png"
+	case "image/jpeg":
+		return ".jpg"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	case "application/pdf":
+		return ".pdf"
+	case "text/plain":
+		return ".txt"
+	}
+	return ".bin"
+}
@@ -0,0 +1,156 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+func archiveTestMessages(sessionID string) []Message {
+	call := &llm.ToolCall{ID: "call_1", Name: "read_file", Arguments: json.RawMessage(`{"path":"main.go"}`)}
+	transcript := []llm.Message{
+		{Role: llm.RoleUser, Parts: []llm.Part{
+			{Type: llm.PartText, Text: "what is in main.go?"},
+			{Type: llm.PartImage, ImageData: &llm.ToolImageData{MediaType: "image/png", Base64: "aGVsbG8="}},
+		}},
+		{Role: llm.RoleAssistant, Parts: []llm.Part{{Type: llm.PartToolCall, ToolCall: call}}},
+		{Role: llm.RoleTool, Parts: []llm.Part{{Type: llm.PartToolResult, ToolResult: &llm.ToolResult{ID: "call_1", Name: "read_file", Content: strings.Repeat("package main\n", 50)}}}},
+		llm.AssistantText("It declares package main."),
+	}
+	messages := make([]Message, 0, len(transcript))
+	for i, msg := range transcript {
+		row := NewMessage(sessionID, msg, i*2)
+		row.DurationMs = int64(i * 100)
+		messages = append(messages, *row)
+	}
+	return messages
+}
+
+func TestArchiveRoundTripThroughImport(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	store, err := NewSQLiteStore(DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	orig := &Session{ID: NewID(), Name: "Archived", Provider: "test", Model: "test-model", Mode: ModeChat, Agent: "coder"}
+	if err := store.Create(ctx, orig); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	for _, msg := range archiveTestMessages(orig.ID) {
+		if err := store.AddMessage(ctx, orig.ID, &msg); err != nil {
+			t.Fatalf("AddMessage: %v", err)
+		}
+	}
+	sess, err := store.Get(ctx, orig.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	messages, err := store.GetMessages(ctx, orig.ID, 0, 0)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+
+	dir := t.TempDir()
+	archive := NewArchive(sess, messages, ExportOptions{})
+	if err := archive.ExtractBlobs(filepath.Join(dir, "blobs")); err != nil {
+		t.Fatalf("ExtractBlobs: %v", err)
+	}
+	archive.BlobDir = "blobs"
+	if len(archive.Blobs) != 1 {
+		t.Fatalf("blobs = %+v, want one image", archive.Blobs)
+	}
+	if raw, err := os.ReadFile(filepath.Join(dir, "blobs", archive.Blobs[0].Path)); err != nil || string(raw) != "hello" {
+		t.Fatalf("blob file = %q, %v; want decoded image data", raw, err)
+	}
+	if messages[0].Parts[1].ImageData.Base64 == "" {
+		t.Fatal("ExtractBlobs must not modify the caller's messages")
+	}
+
+	data, err := json.Marshal(archive)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if strings.Contains(string(data), "aGVsbG8=") {
+		t.Fatal("archive still contains extracted base64 data")
+	}
+	parsed, err := ParseArchive(data)
+	if err != nil {
+		t.Fatalf("ParseArchive: %v", err)
+	}
+	if err := parsed.RestoreBlobs(dir); err != nil {
+		t.Fatalf("RestoreBlobs: %v", err)
+	}
+
+	imported, err := ImportArchive(ctx, store, parsed)
+	if err != nil {
+		t.Fatalf("ImportArchive: %v", err)
+	}
+	if imported.ID == orig.ID || imported.Number == sess.Number {
+		t.Fatalf("import reused identity: %+v", imported)
+	}
+	if imported.Name != "Archived" || imported.Agent != "coder" {
+		t.Fatalf("import lost session settings: %+v", imported)
+	}
+	got, err := store.GetMessages(ctx, imported.ID, 0, 0)
+	if err != nil {
+		t.Fatalf("GetMessages(imported): %v", err)
+	}
+	if len(got) != len(messages) {
+		t.Fatalf("imported %d messages, want %d", len(got), len(messages))
+	}
+	for i := range messages {
+		want, _ := messages[i].PartsJSON()
+		have, _ := got[i].PartsJSON()
+		if have != want {
+			t.Errorf("message %d parts = %s, want %s", i, have, want)
+		}
+		if got[i].Sequence != messages[i].Sequence || got[i].DurationMs != messages[i].DurationMs || !got[i].CreatedAt.Equal(messages[i].CreatedAt) {
+			t.Errorf("message %d = seq %d dur %d at %v, want seq %d dur %d at %v", i,
+				got[i].Sequence, got[i].DurationMs, got[i].CreatedAt, messages[i].Sequence, messages[i].DurationMs, messages[i].CreatedAt)
+		}
+	}
+}
+
+func TestNewArchiveToolOptions(t *testing.T) {
+	sess := &Session{ID: "s1"}
+	messages := archiveTestMessages(sess.ID)
+
+	omitted := NewArchive(sess, messages, ExportOptions{OmitTools: true})
+	if len(omitted.Messages) != 2 {
+		t.Fatalf("OmitTools kept %d messages, want user and final assistant", len(omitted.Messages))
+	}
+	for _, msg := range omitted.Messages {
+		for _, part := range msg.Parts {
+			if part.Type == llm.PartToolCall || part.Type == llm.PartToolResult {
+				t.Fatalf("OmitTools kept a tool part: %+v", part)
+			}
+		}
+	}
+
+	truncated := NewArchive(sess, messages, ExportOptions{MaxToolResultChars: 40})
+	content := truncated.Messages[2].Parts[0].ToolResult.Content
+	if !strings.Contains(content, "chars truncated") {
+		t.Fatalf("tool result not truncated: %q", content)
+	}
+	if messages[2].Parts[0].ToolResult.Content == content {
+		t.Fatal("truncation must not modify the caller's messages")
+	}
+}
+
+func TestParseArchiveRejectsUnknownVersion(t *testing.T) {
+	if _, err := ParseArchive([]byte(`{"version":99,"session":{"id":"x"}}`)); err == nil {
+		t.Fatal("expected error for unsupported version")
+	}
+	if _, err := ParseArchive([]byte(`{"version":1}`)); err == nil {
+		t.Fatal("expected error for missing session")
+	}
+}
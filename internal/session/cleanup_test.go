@@ -0,0 +1,205 @@
+package session
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newCleanupTestStore(t *testing.T, cfg Config) *SQLiteStore {
+	t.Helper()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	store, err := NewSQLiteStore(cfg)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func createAgedSession(t *testing.T, store *SQLiteStore, age time.Duration, archived bool) *Session {
+	t.Helper()
+	at := time.Now().Add(-age)
+	sess := &Session{ID: NewID(), Provider: "test", Model: "m", CreatedAt: at, UpdatedAt: at, Archived: archived}
+	if err := store.Create(context.Background(), sess); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return sess
+}
+
+func TestSQLiteStoreCleanupArchivesBeforePurging(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxAgeDays = 30
+	cfg.PurgeGraceDays = 14
+	store := newCleanupTestStore(t, cfg)
+	ctx := context.Background()
+
+	old := createAgedSession(t, store, 40*24*time.Hour, false)
+	fresh := createAgedSession(t, store, time.Hour, false)
+	userArchived := createAgedSession(t, store, 400*24*time.Hour, true)
+
+	if err := store.cleanup(); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	got, err := store.Get(ctx, old.ID)
+	if err != nil || got == nil {
+		t.Fatalf("old session was deleted on first cleanup: %v", err)
+	}
+	if !got.Archived {
+		t.Fatal("old session should be archived")
+	}
+	if got, _ := store.Get(ctx, fresh.ID); got == nil || got.Archived {
+		t.Fatal("fresh session should be untouched")
+	}
+
+	archived, err := store.List(ctx, ListOptions{ArchivedOnly: true})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(archived) != 2 {
+		t.Fatalf("ArchivedOnly returned %d sessions, want 2", len(archived))
+	}
+	for _, sum := range archived {
+		switch sum.ID {
+		case old.ID:
+			if until := time.Until(sum.PurgeAfter); until < 13*24*time.Hour || until > 15*24*time.Hour {
+				t.Fatalf("PurgeAfter = %v, want about 14 days out", sum.PurgeAfter)
+			}
+		case userArchived.ID:
+			if !sum.PurgeAfter.IsZero() {
+				t.Fatalf("user-archived session has PurgeAfter %v", sum.PurgeAfter)
+			}
+		}
+	}
+
+	// Once the grace window has passed the auto-archived session is deleted;
+	// sessions the user archived themselves are kept.
+	if _, err := store.db.Exec("UPDATE sessions SET auto_archived_at = ? WHERE id = ?", time.Now().AddDate(0, 0, -15), old.ID); err != nil {
+		t.Fatalf("backdate auto_archived_at: %v", err)
+	}
+	if err := store.cleanup(); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	if got, _ := store.Get(ctx, old.ID); got != nil {
+		t.Fatal("auto-archived session should be purged after the grace window")
+	}
+	if got, _ := store.Get(ctx, userArchived.ID); got == nil {
+		t.Fatal("user-archived session must never be purged")
+	}
+}
+
+func TestSQLiteStoreCleanupRestoreCancelsPurge(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxCount = 1
+	store := newCleanupTestStore(t, cfg)
+	ctx := context.Background()
+
+	older := createAgedSession(t, store, 2*time.Hour, false)
+	createAgedSession(t, store, time.Hour, false)
+	if err := store.cleanup(); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	sess, err := store.Get(ctx, older.ID)
+	if err != nil || sess == nil || !sess.Archived {
+		t.Fatalf("older session should be archived by max_count: %+v, %v", sess, err)
+	}
+
+	sess.Archived = false
+	sess.UpdatedAt = time.Now()
+	if err := store.Update(ctx, sess); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	var pending int
+	if err := store.db.QueryRow("SELECT COUNT(*) FROM sessions WHERE auto_archived_at IS NOT NULL").Scan(&pending); err != nil {
+		t.Fatalf("count pending purges: %v", err)
+	}
+	if pending != 0 {
+		t.Fatalf("restored session still pending purge (%d rows)", pending)
+	}
+}
+
+func TestSQLiteStoreCleanupHardDelete(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxAgeDays = 30
+	cfg.HardDelete = true
+	store := newCleanupTestStore(t, cfg)
+
+	old := createAgedSession(t, store, 40*24*time.Hour, false)
+	if err := store.cleanup(); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	if got, _ := store.Get(context.Background(), old.ID); got != nil {
+		t.Fatal("hard_delete should delete expired sessions immediately")
+	}
+}
+
+func TestSQLiteStoreDeleteRemovesGeneratedImages(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	store, err := NewSQLiteStore(DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	ctx := context.Background()
+	kept := createAgedSession(t, store, time.Hour, false)
+	deleted := createAgedSession(t, store, time.Hour, false)
+	if store.imagesDir == "" {
+		t.Fatal("default store has no images dir")
+	}
+	for _, name := range []string{kept.ID, deleted.ID, "orphan", "unsaved"} {
+		if err := os.MkdirAll(filepath.Join(store.imagesDir, name), 0o700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oldUnsaved := filepath.Join(store.imagesDir, "unsaved", "old.png")
+	newUnsaved := filepath.Join(store.imagesDir, "unsaved", "new.png")
+	for _, path := range []string{oldUnsaved, newUnsaved} {
+		if err := os.WriteFile(path, []byte("png"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-unsavedImagesMaxAge - time.Hour)
+	if err := os.Chtimes(oldUnsaved, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Delete(ctx, deleted.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(store.imagesDir, deleted.ID)); !os.IsNotExist(err) {
+		t.Fatalf("deleted session images still present: %v", err)
+	}
+	store.Close()
+
+	// Reopening sweeps directories of sessions that no longer exist and old
+	// images from requests without a session.
+	store, err = NewSQLiteStore(DefaultConfig())
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer store.Close()
+	for name, want := range map[string]bool{kept.ID: true, "orphan": false, "unsaved": true, "unsaved/old.png": false, "unsaved/new.png": true} {
+		_, err := os.Stat(filepath.Join(store.imagesDir, name))
+		if got := err == nil; got != want {
+			t.Fatalf("images dir %s present = %t, want %t", name, got, want)
+		}
+	}
+
+	// The sweep runs at most once per interval.
+	if err := os.MkdirAll(filepath.Join(store.imagesDir, "orphan"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	store.maintainImages(ctx)
+	if _, err := os.Stat(filepath.Join(store.imagesDir, "orphan")); err != nil {
+		t.Fatalf("orphan swept again within the prune interval: %v", err)
+	}
+	stale := time.Now().Add(-imagesPruneInterval - time.Hour)
+	if err := os.Chtimes(filepath.Join(store.imagesDir, imagesPruneStamp), stale, stale); err != nil {
+		t.Fatal(err)
+	}
+	store.maintainImages(ctx)
+	if _, err := os.Stat(filepath.Join(store.imagesDir, "orphan")); !os.IsNotExist(err) {
+		t.Fatalf("orphan not swept once the interval passed: %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// IntegrityReport describes the health of the sessions database.
+type IntegrityReport struct {
+	Path             string   // Database file path (":memory:" for in-memory stores)
+	WALBytes         int64    // Size of the write-ahead log, 0 when absent
+	Problems         []string // PRAGMA integrity_check findings; empty when the database is sound
+	SearchIndexError string   // messages_fts integrity-check failure; empty when the index matches messages
+}
+
+// OK reports whether no corruption was found.
+func (r *IntegrityReport) OK() bool {
+	return len(r.Problems) == 0 && r.SearchIndexError == ""
+}
+
+// CheckIntegrity runs SQLite's integrity_check and the FTS5 integrity-check
+// for the message search index, which also verifies the index against the
+// messages table.
+func (s *SQLiteStore) CheckIntegrity(ctx context.Context) (*IntegrityReport, error) {
+	path, err := ResolveDBPath(s.cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("get db path: %w", err)
+	}
+	report := &IntegrityReport{Path: path}
+	if path != ":memory:" {
+		if info, err := os.Stat(path + "-wal"); err == nil {
+			report.WALBytes = info.Size()
+		}
+	}
+
+	rows, err := s.db.QueryContext(ctx, "PRAGMA integrity_check")
+	if err != nil {
+		return nil, fmt.Errorf("integrity check: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, fmt.Errorf("scan integrity check: %w", err)
+		}
+		if line != "ok" {
+			report.Problems = append(report.Problems, line)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("integrity check: %w", err)
+	}
+
+	// FTS5 signals an out-of-sync index by failing the special insert with
+	// SQLITE_CORRUPT_VTAB rather than returning rows. For external-content
+	// tables the index is only compared with messages when rank is 1.
+	if _, err := s.db.ExecContext(ctx, "INSERT INTO messages_fts(messages_fts, rank) VALUES('integrity-check', 1)"); err != nil {
+		report.SearchIndexError = err.Error()
+	}
+	return report, nil
+}
+
+// RebuildSearchIndex repopulates messages_fts from the messages table in a
+// single transaction.
+func (s *SQLiteStore) RebuildSearchIndex(ctx context.Context) error {
+	return retryOnBusy(ctx, 5, func() error {
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.ExecContext(ctx, "INSERT INTO messages_fts(messages_fts) VALUES('rebuild')"); err != nil {
+			return fmt.Errorf("rebuild search index: %w", err)
+		}
+		return tx.Commit()
+	})
+}
@@ -0,0 +1,72 @@
+package session
+
+import (
+	"context"
+	"testing"
+
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+func TestSQLiteStoreCheckIntegrityDetectsAndRebuildsStaleSearchIndex(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	store, err := NewSQLiteStore(DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	sess := &Session{ID: NewID(), Provider: "test", Model: "m"}
+	if err := store.Create(ctx, sess); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	for i, text := range []string{"alpha kubernetes", "beta postgres"} {
+		if err := store.AddMessage(ctx, sess.ID, NewMessage(sess.ID, llm.UserText(text), i)); err != nil {
+			t.Fatalf("AddMessage: %v", err)
+		}
+	}
+
+	report, err := store.CheckIntegrity(ctx)
+	if err != nil {
+		t.Fatalf("CheckIntegrity: %v", err)
+	}
+	if !report.OK() {
+		t.Fatalf("fresh database reported problems: %+v", report)
+	}
+	if report.Path == "" {
+		t.Fatal("report should include the database path")
+	}
+
+	// Simulate a write that bypassed the FTS triggers.
+	if _, err := store.db.Exec("DROP TRIGGER messages_au"); err != nil {
+		t.Fatalf("drop trigger: %v", err)
+	}
+	if _, err := store.db.Exec("UPDATE messages SET text_content = 'gamma redis' WHERE session_id = ? AND sequence = 0", sess.ID); err != nil {
+		t.Fatalf("update message: %v", err)
+	}
+	report, err = store.CheckIntegrity(ctx)
+	if err != nil {
+		t.Fatalf("CheckIntegrity: %v", err)
+	}
+	if report.SearchIndexError == "" || report.OK() {
+		t.Fatalf("stale search index not detected: %+v", report)
+	}
+
+	if err := store.RebuildSearchIndex(ctx); err != nil {
+		t.Fatalf("RebuildSearchIndex: %v", err)
+	}
+	report, err = store.CheckIntegrity(ctx)
+	if err != nil {
+		t.Fatalf("CheckIntegrity: %v", err)
+	}
+	if !report.OK() {
+		t.Fatalf("rebuild did not repair the index: %+v", report)
+	}
+	results, err := store.Search(ctx, SearchOptions{Query: "redis"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Search(redis) returned %d results after rebuild, want 1", len(results))
+	}
+}
@@ -14,6 +14,8 @@ type ExportOptions struct {
 	IncludeSystem             bool // Include system prompt in export
 	IncludeReasoningSummaries bool // Include provider-sanctioned reasoning summaries
 	IncludeRawReasoning       bool // Include raw reasoning; caller must enforce safety gate
+	OmitTools                 bool // Drop tool calls and tool results
+	MaxToolResultChars        int  // Truncate tool result content beyond this many chars (0 = unlimited)
 }
 
 // escapeTableCell escapes special characters for markdown table cells.
@@ -27,6 +29,7 @@ func escapeTableCell(s string) string {
 // ExportToMarkdown exports a session and its messages to a pretty markdown format.
 func ExportToMarkdown(sess *Session, messages []Message, opts ExportOptions) string {
 	var b strings.Builder
+	messages = applyExportToolOptions(messages, opts)
 
 	// Title
 	title := sess.PreferredShortTitle()
@@ -166,6 +169,9 @@ func ExportToMarkdown(sess *Session, messages []Message, opts ExportOptions) str
 						pendingText.WriteString("\n\n")
 					}
 				}
+				if part.Type == llm.PartImage && part.ImagePath != "" {
+					pendingText.WriteString(fmt.Sprintf("![generated image](%s)\n\n", part.ImagePath))
+				}
 				if part.Type == llm.PartToolCall && part.ToolCall != nil {
 					pendingToolCalls = append(pendingToolCalls, part.ToolCall)
 					toolCalls[part.ToolCall.ID] = part.ToolCall
@@ -7,12 +7,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"net/http"
+	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
 
 	"github.com/samsaffron/term-llm/internal/llm"
 	internalreasoning "github.com/samsaffron/term-llm/internal/reasoning"
@@ -88,10 +92,9 @@ type htmlExportTool struct {
 }
 
 type htmlExportDiff struct {
-	File string
-	Old  string
-	New  string
-	Line int
+	File  string
+	Line  int
+	Lines []htmlExportDiffLine
 }
 
 type htmlExportImage struct {
@@ -123,11 +126,13 @@ func VisibleExportMessages(messages []Message) []Message {
 	return visible
 }
 
-// ExportToHTML renders a self-contained, interactive transcript document.
+// ExportToHTML renders a self-contained, interactive transcript document:
+// inline CSS, highlighted code, and images embedded as data URLs.
 func ExportToHTML(sess *Session, messages []Message, opts ExportOptions) (string, error) {
 	if sess == nil {
 		return "", fmt.Errorf("session is required")
 	}
+	messages = applyExportToolOptions(messages, opts)
 
 	view := buildHTMLExportView(sess, messages, opts)
 	tmpl, err := template.New("export_html.tmpl").ParseFS(exportHTMLTemplateFS, "export_html.tmpl")
@@ -189,7 +194,10 @@ func htmlProviderModelLabel(provider, model string) string {
 }
 
 func buildHTMLExportMessages(messages []Message, opts ExportOptions) ([]htmlExportMessage, int) {
-	markdown := goldmark.New(goldmark.WithExtensions(extension.GFM))
+	markdown := goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithRendererOptions(renderer.WithNodeRenderers(util.Prioritized(htmlCodeBlockRenderer{}, 100))),
+	)
 	views := make([]htmlExportMessage, 0, len(messages))
 	pending := make(map[string]pendingHTMLTool)
 	inlineBytes := 0
@@ -220,7 +228,7 @@ func buildHTMLExportMessages(messages []Message, opts ExportOptions) ([]htmlExpo
 					view.Blocks = append(view.Blocks, htmlExportBlock{Kind: "markdown", HTML: renderSafeMarkdown(markdown, part.Text)})
 				}
 			case llm.PartImage:
-				image := buildHTMLImage(part.ImageData, &inlineBytes)
+				image := buildHTMLImage(imageDataWithFile(part), &inlineBytes)
 				view.Blocks = append(view.Blocks, htmlExportBlock{Kind: "image", Image: &image})
 			case llm.PartFile:
 				view.Blocks = append(view.Blocks, htmlExportBlock{Kind: "file", File: buildHTMLFile(part)})
@@ -370,7 +378,7 @@ func buildHTMLTool(call *llm.ToolCall, result *llm.ToolResult, inlineBytes *int)
 			tool.ID = result.ID
 		}
 		for _, diff := range result.Diffs {
-			tool.Diffs = append(tool.Diffs, htmlExportDiff{File: diff.File, Old: diff.Old, New: diff.New, Line: diff.Line})
+			tool.Diffs = append(tool.Diffs, htmlExportDiff{File: diff.File, Line: diff.Line, Lines: buildHTMLDiffLines(diff.File, diff.Old, diff.New)})
 		}
 		for _, part := range result.ContentParts {
 			switch part.Type {
@@ -426,6 +434,30 @@ func buildHTMLImage(data *llm.ToolImageData, inlineBytes *int) htmlExportImage {
 	return image
 }
 
+// imageDataWithFile returns part's image data, loading the bytes from
+// ImagePath when only the path was stored (generated images keep just that).
+func imageDataWithFile(part llm.Part) *llm.ToolImageData {
+	if part.ImagePath == "" || (part.ImageData != nil && part.ImageData.Base64 != "") {
+		return part.ImageData
+	}
+	info, err := os.Stat(part.ImagePath)
+	if err != nil || info.Size() > maxHTMLExportInlineImageBytes {
+		return part.ImageData
+	}
+	raw, err := os.ReadFile(part.ImagePath)
+	if err != nil {
+		return part.ImageData
+	}
+	data := &llm.ToolImageData{Base64: base64.StdEncoding.EncodeToString(raw)}
+	if part.ImageData != nil {
+		data.MediaType = part.ImageData.MediaType
+	}
+	if data.MediaType == "" {
+		data.MediaType = http.DetectContentType(raw)
+	}
+	return data
+}
+
 func buildHTMLFile(part llm.Part) *htmlExportFile {
 	file := &htmlExportFile{Filename: "File attachment"}
 	if part.FileData == nil {
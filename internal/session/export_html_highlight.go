@@ -0,0 +1,154 @@
+package session
+
+import (
+	"bytes"
+	"html/template"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	diff "github.com/shogoki/gotextdiff"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// htmlExportCodeStyle matches the dark code panels in export_html.tmpl and the
+// terminal diff view.
+const htmlExportCodeStyle = "monokai"
+
+// htmlCodeBlockRenderer replaces Goldmark's fenced code block output with
+// chroma-highlighted HTML using inline styles, so the exported file needs no
+// stylesheet beyond its own.
+type htmlCodeBlockRenderer struct{}
+
+func (htmlCodeBlockRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, renderHTMLFencedCodeBlock)
+}
+
+func renderHTMLFencedCodeBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	block := node.(*ast.FencedCodeBlock)
+	language := string(block.Language(source))
+	var code bytes.Buffer
+	lines := block.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		code.Write(line.Value(source))
+	}
+	_, _ = w.WriteString("<pre class=\"chroma\"><code")
+	if language != "" {
+		_, _ = w.WriteString(" class=\"language-" + template.HTMLEscapeString(language) + "\"")
+	}
+	_, _ = w.WriteString(">")
+	_, _ = w.WriteString(string(highlightHTMLCode(lexers.Get(language), code.String())))
+	_, _ = w.WriteString("</code></pre>\n")
+	return ast.WalkSkipChildren, nil
+}
+
+// highlightHTMLCode returns code as escaped HTML, with token colors when a
+// lexer is known.
+func highlightHTMLCode(lexer chroma.Lexer, code string) template.HTML {
+	return highlightHTML(lexer, code, false)
+}
+
+// highlightHTMLLine is highlightHTMLCode for a single diff line, without the
+// trailing newline some lexers add.
+func highlightHTMLLine(lexer chroma.Lexer, line string) template.HTML {
+	return highlightHTML(lexer, line, true)
+}
+
+func highlightHTML(lexer chroma.Lexer, code string, singleLine bool) template.HTML {
+	if lexer == nil {
+		return template.HTML(template.HTMLEscapeString(code)) // #nosec G203 -- escaped above.
+	}
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if err != nil {
+		return template.HTML(template.HTMLEscapeString(code)) // #nosec G203 -- escaped above.
+	}
+	if singleLine {
+		tokens := iterator.Tokens()
+		for len(tokens) > 0 {
+			last := &tokens[len(tokens)-1]
+			last.Value = strings.TrimSuffix(last.Value, "\n")
+			if last.Value != "" {
+				break
+			}
+			tokens = tokens[:len(tokens)-1]
+		}
+		iterator = chroma.Literator(tokens...)
+	}
+	style := styles.Get(htmlExportCodeStyle)
+	if style == nil {
+		style = styles.Fallback
+	}
+	formatter := chromahtml.New(chromahtml.WithClasses(false), chromahtml.PreventSurroundingPre(true))
+	var out bytes.Buffer
+	if err := formatter.Format(&out, style, iterator); err != nil {
+		return template.HTML(template.HTMLEscapeString(code)) // #nosec G203 -- escaped above.
+	}
+	return template.HTML(out.String()) // #nosec G203 -- chroma escapes every token.
+}
+
+type htmlExportDiffLine struct {
+	Kind string // "add", "del", "ctx" or "hunk"
+	Old  string
+	New  string
+	Sign string
+	HTML template.HTML
+}
+
+var htmlExportHunkRe = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// buildHTMLDiffLines renders an edit as unified diff rows, the same layout the
+// terminal uses, with each line highlighted for the file's language.
+func buildHTMLDiffLines(file, oldContent, newContent string) []htmlExportDiffLine {
+	if oldContent == newContent {
+		return nil
+	}
+	unified := diff.Diff(file, []byte(oldContent), file, []byte(newContent))
+	if len(unified) == 0 {
+		return nil
+	}
+	lexer := lexers.Match(file)
+	var lines []htmlExportDiffLine
+	var oldNum, newNum int
+	inHunk := false
+	for _, line := range strings.Split(string(unified), "\n") {
+		if line == "" || strings.HasPrefix(line, `\`) {
+			continue
+		}
+		// File headers only precede the first hunk; inside one, a removed
+		// "-- x" or added "++ x" line looks just like them.
+		if !inHunk && !strings.HasPrefix(line, "@@") {
+			continue
+		}
+		content := line[1:]
+		switch line[0] {
+		case '@':
+			inHunk = true
+			if m := htmlExportHunkRe.FindStringSubmatch(line); m != nil {
+				oldNum, _ = strconv.Atoi(m[1])
+				newNum, _ = strconv.Atoi(m[2])
+			}
+			lines = append(lines, htmlExportDiffLine{Kind: "hunk", HTML: template.HTML(template.HTMLEscapeString(line))}) // #nosec G203 -- escaped.
+		case '-':
+			lines = append(lines, htmlExportDiffLine{Kind: "del", Old: strconv.Itoa(oldNum), Sign: "-", HTML: highlightHTMLLine(lexer, content)})
+			oldNum++
+		case '+':
+			lines = append(lines, htmlExportDiffLine{Kind: "add", New: strconv.Itoa(newNum), Sign: "+", HTML: highlightHTMLLine(lexer, content)})
+			newNum++
+		default:
+			lines = append(lines, htmlExportDiffLine{Kind: "ctx", Old: strconv.Itoa(oldNum), New: strconv.Itoa(newNum), Sign: " ", HTML: highlightHTMLLine(lexer, content)})
+			oldNum++
+			newNum++
+		}
+	}
+	return lines
+}
@@ -2,6 +2,9 @@ package session
 
 import (
 	"encoding/base64"
+	"flag"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -9,6 +12,64 @@ import (
 	"github.com/samsaffron/term-llm/internal/llm"
 )
 
+var updateHTMLGolden = flag.Bool("update", false, "rewrite testdata/*.golden from the current output")
+
+// TestExportToHTMLGolden pins the whole document for a fixture session. Run
+// with -update after an intentional template change and review the diff.
+func TestExportToHTMLGolden(t *testing.T) {
+	now := time.Date(2026, 7, 10, 12, 34, 0, 0, time.UTC)
+	png := base64.StdEncoding.EncodeToString([]byte("\x89PNG fixture"))
+	sess := &Session{
+		ID: "0123456789ab", Name: "Fix the greeting", Provider: "anthropic", Model: "claude-test",
+		Mode: ModeChat, Status: StatusComplete, CreatedAt: now, UpdatedAt: now.Add(2 * time.Minute),
+		UserTurns: 1, LLMTurns: 2, ToolCalls: 2, InputTokens: 5400, OutputTokens: 320,
+	}
+	messages := []Message{
+		{Role: llm.RoleUser, CreatedAt: now, Parts: []llm.Part{
+			{Type: llm.PartText, Text: "The greeting in `main.go` is wrong, see the screenshot."},
+			{Type: llm.PartImage, ImageData: &llm.ToolImageData{MediaType: "image/png", Base64: png}},
+		}},
+		{Role: llm.RoleAssistant, CreatedAt: now.Add(time.Second), DurationMs: 2400, Parts: []llm.Part{
+			{Type: llm.PartText, Text: "Let me look at it."},
+			{Type: llm.PartToolCall, ToolCall: &llm.ToolCall{ID: "call-read", Name: "read_file", Arguments: []byte(`{"path":"main.go"}`)}},
+		}},
+		{Role: llm.RoleTool, CreatedAt: now.Add(2 * time.Second), Parts: []llm.Part{{Type: llm.PartToolResult, ToolResult: &llm.ToolResult{
+			ID: "call-read", Name: "read_file", Content: "package main\n\nfunc main() {\n\tprintln(\"helo\")\n}\n",
+		}}}},
+		{Role: llm.RoleAssistant, CreatedAt: now.Add(3 * time.Second), DurationMs: 1800, Parts: []llm.Part{
+			{Type: llm.PartToolCall, ToolCall: &llm.ToolCall{ID: "call-edit", Name: "edit_file", Arguments: []byte(`{"path":"main.go","old_text":"helo","new_text":"hello"}`)}},
+		}},
+		{Role: llm.RoleTool, CreatedAt: now.Add(4 * time.Second), Parts: []llm.Part{{Type: llm.PartToolResult, ToolResult: &llm.ToolResult{
+			ID: "call-edit", Name: "edit_file", Content: "Edited main.go",
+			Diffs: []llm.DiffData{{File: "main.go", Line: 3, Old: "func main() {\n\tprintln(\"helo\")\n}\n", New: "func main() {\n\tprintln(\"hello\")\n}\n"}},
+		}}}},
+		{Role: llm.RoleAssistant, CreatedAt: now.Add(5 * time.Second), DurationMs: 900, Parts: []llm.Part{
+			{Type: llm.PartText, Text: "Fixed. The program now reads:\n\n```go\nfunc main() {\n\tprintln(\"hello\")\n}\n```"},
+		}},
+	}
+
+	got, err := ExportToHTML(sess, messages, ExportOptions{})
+	if err != nil {
+		t.Fatalf("ExportToHTML: %v", err)
+	}
+	path := filepath.Join("testdata", "export_html.golden")
+	if *updateHTMLGolden {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden (run with -update to create it): %v", err)
+	}
+	if got != string(want) {
+		t.Fatalf("HTML export differs from %s; run go test ./internal/session -run TestExportToHTMLGolden -update and review the diff", path)
+	}
+}
+
 func TestExportToHTMLRendersTranscriptAndMetadata(t *testing.T) {
 	now := time.Date(2026, 7, 10, 12, 34, 0, 0, time.UTC)
 	sess := &Session{
@@ -27,12 +88,12 @@ func TestExportToHTMLRendersTranscriptAndMetadata(t *testing.T) {
 	if err != nil {
 		t.Fatalf("ExportToHTML: %v", err)
 	}
-	for _, want := range []string{"<!doctype html>", "HTML export", "OpenAI", "gpt-test", "developer", "1,200", "Hello <strong>world</strong>", "language-go", "fmt.Println", "shell", "go test", "ok", "1.25s", "data-action=\"theme\"", "prefers-color-scheme:dark", "@media print", "<details"} {
+	for _, want := range []string{"<!doctype html>", "HTML export", "OpenAI", "gpt-test", "developer", "1,200", "Hello <strong>world</strong>", "language-go", "Println", "shell", "go test", "ok", "1.25s", "data-action=\"theme\"", "prefers-color-scheme:dark", "@media print", "<details"} {
 		if !strings.Contains(html, want) {
 			t.Errorf("HTML missing %q", want)
 		}
 	}
-	if strings.Index(html, "Hello") > strings.Index(html, "fmt.Println") {
+	if strings.Index(html, "Hello") > strings.Index(html, "Println") {
 		t.Error("message role ordering was not preserved")
 	}
 }
@@ -147,6 +208,7 @@ func TestExportToHTMLPreventsTranscriptXSS(t *testing.T) {
 			Role: llm.RoleUser,
 			Parts: []llm.Part{
 				{Type: llm.PartText, Text: `<script>alert("message")</script><img src=x onerror=alert(2)> [bad](javascript:alert(3))`},
+				{Type: llm.PartText, Text: "```go\"onmouseover=alert(9)\n</code><script>alert(10)</script>\n```"},
 				{Type: llm.PartImage, ImageData: &llm.ToolImageData{MediaType: `image/svg+xml" onload="alert(4)`, Base64: "PHN2Zz4="}},
 			},
 		},
@@ -164,7 +226,7 @@ func TestExportToHTMLPreventsTranscriptXSS(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	for _, bad := range []string{"alert(\"title\")</script>", "<img src=x onerror=", "javascript:alert", `image/svg+xml" onload=`, "alert(5)</script>", "alert(6)</script>"} {
+	for _, bad := range []string{"alert(\"title\")</script>", "<img src=x onerror=", "javascript:alert", `image/svg+xml" onload=`, "alert(5)</script>", "alert(6)</script>", `"onmouseover=`, "alert(10)</script>"} {
 		if strings.Contains(html, bad) {
 			t.Errorf("HTML contains executable transcript fragment %q", bad)
 		}
@@ -192,3 +254,14 @@ func TestVisibleExportMessages(t *testing.T) {
 		t.Fatal("input slice was mutated")
 	}
 }
+
+func TestBuildHTMLDiffLinesKeepsHeaderLikeLinesInsideHunks(t *testing.T) {
+	lines := buildHTMLDiffLines("notes.md", "a\n-- old\nb\n", "a\n++ new\nb\n")
+	var kinds []string
+	for _, line := range lines {
+		kinds = append(kinds, line.Kind)
+	}
+	if got := strings.Join(kinds, ","); got != "hunk,ctx,del,add,ctx" {
+		t.Fatalf("diff line kinds = %s, want hunk,ctx,del,add,ctx", got)
+	}
+}
@@ -0,0 +1,186 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+func TestSQLiteStoreForkCopiesPrefixAndSurvivesParentDelete(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	store, err := NewSQLiteStore(DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	parent := &Session{ID: NewID(), Provider: "test", Model: "test-model", Mode: ModeChat, Agent: "coder", Tools: "read_file"}
+	if err := store.Create(ctx, parent); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	transcript := []llm.Message{
+		llm.UserText("first question"),
+		llm.AssistantText("first answer"),
+		llm.UserText("second question"),
+		llm.AssistantText("second answer"),
+	}
+	for i, msg := range transcript {
+		row := NewMessage(parent.ID, msg, i)
+		row.Pinned = i == 0
+		if err := store.AddMessage(ctx, parent.ID, row); err != nil {
+			t.Fatalf("AddMessage(%d): %v", i, err)
+		}
+	}
+
+	fork, err := store.Fork(ctx, parent.ID, 1)
+	if err != nil {
+		t.Fatalf("Fork: %v", err)
+	}
+	if fork.ParentID != parent.ID || fork.ID == parent.ID {
+		t.Fatalf("fork ParentID = %q (id %q), want parent %q", fork.ParentID, fork.ID, parent.ID)
+	}
+	if fork.Kind != KindFork {
+		t.Fatalf("fork Kind = %q, want %q", fork.Kind, KindFork)
+	}
+	if fork.Agent != "coder" || fork.Tools != "read_file" || fork.Model != "test-model" {
+		t.Fatalf("fork did not inherit settings: %+v", fork)
+	}
+	msgs, err := store.GetMessages(ctx, fork.ID, 0, 0)
+	if err != nil {
+		t.Fatalf("GetMessages(fork): %v", err)
+	}
+	if len(msgs) != 2 || msgs[0].TextContent != "first question" || msgs[1].TextContent != "first answer" {
+		t.Fatalf("fork messages = %+v, want first exchange only", msgs)
+	}
+	if !msgs[0].Pinned {
+		t.Fatal("fork should keep message pins")
+	}
+	if fork.UserTurns != 1 {
+		t.Fatalf("fork user_turns = %d, want 1", fork.UserTurns)
+	}
+
+	results, err := store.Search(ctx, SearchOptions{Query: "first answer", Limit: 10})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	foundFork := false
+	for _, r := range results {
+		foundFork = foundFork || r.SessionID == fork.ID
+	}
+	if !foundFork {
+		t.Fatal("forked messages should be indexed for search")
+	}
+
+	summaries, err := store.List(ctx, ListOptions{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	for _, sum := range summaries {
+		if sum.ID == fork.ID && (sum.ParentID != parent.ID || sum.ParentNumber != parent.Number || sum.Kind != KindFork) {
+			t.Fatalf("fork summary parent = %q/#%d kind %q, want %q/#%d kind fork", sum.ParentID, sum.ParentNumber, sum.Kind, parent.ID, parent.Number)
+		}
+	}
+
+	childRun := &Session{ID: NewID(), Provider: "test", Model: "test-model", Mode: ModeChat, ParentID: parent.ID}
+	if err := store.Create(ctx, childRun); err != nil {
+		t.Fatalf("Create(child run): %v", err)
+	}
+	if got, _ := store.Get(ctx, childRun.ID); got == nil || got.Kind != KindDefault {
+		t.Fatalf("child run Kind = %+v, want default", got)
+	}
+
+	whole, err := store.Fork(ctx, parent.ID, -1)
+	if err != nil {
+		t.Fatalf("Fork(all): %v", err)
+	}
+	if all, _ := store.GetMessages(ctx, whole.ID, 0, 0); len(all) != len(transcript) {
+		t.Fatalf("Fork(-1) copied %d messages, want %d", len(all), len(transcript))
+	}
+
+	if err := store.Delete(ctx, parent.ID); err != nil {
+		t.Fatalf("Delete(parent): %v", err)
+	}
+	kept, err := store.Get(ctx, fork.ID)
+	if err != nil || kept == nil {
+		t.Fatalf("fork should survive parent delete: %v, %v", kept, err)
+	}
+	if kept.ParentID != "" {
+		t.Fatalf("fork ParentID after parent delete = %q, want empty", kept.ParentID)
+	}
+	if msgs, _ := store.GetMessages(ctx, fork.ID, 0, 0); len(msgs) != 2 {
+		t.Fatalf("fork messages after parent delete = %d, want 2", len(msgs))
+	}
+}
+
+func TestSQLiteStoreForkMissingSession(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	store, err := NewSQLiteStore(DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+
+	if _, err := store.Fork(context.Background(), "missing", -1); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Fork(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSQLiteStoreForksListsForksOldestFirst(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	store, err := NewSQLiteStore(DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	base := time.Now().Add(-time.Hour)
+	create := func(name, parentID string, offset time.Duration, archived bool) *Session {
+		t.Helper()
+		sess := &Session{ID: NewID(), Name: name, Provider: "test", Model: "test-model", Mode: ModeChat,
+			ParentID: parentID, Archived: archived, CreatedAt: base.Add(offset), UpdatedAt: base.Add(offset)}
+		if parentID != "" && name != "subagent" {
+			sess.Kind = KindFork
+		}
+		if err := store.Create(ctx, sess); err != nil {
+			t.Fatalf("Create(%s): %v", name, err)
+		}
+		return sess
+	}
+	root := create("root", "", 0, false)
+	late := create("late", root.ID, 3*time.Minute, false)
+	early := create("early", root.ID, time.Minute, true)
+	create("grandchild", early.ID, 4*time.Minute, false)
+	create("unrelated", "", 2*time.Minute, false)
+	create("subagent", root.ID, 2*time.Minute, false)
+
+	children, err := store.Forks(ctx, root.ID)
+	if err != nil {
+		t.Fatalf("Forks: %v", err)
+	}
+	var names []string
+	for _, c := range children {
+		names = append(names, c.Name)
+		if c.ParentID != root.ID {
+			t.Fatalf("child %s ParentID = %q, want %q", c.Name, c.ParentID, root.ID)
+		}
+	}
+	if strings.Join(names, ",") != "early,late" {
+		t.Fatalf("Forks(root) = %v, want [early late] (archived included, oldest first, child runs left out)", names)
+	}
+
+	if got, err := store.Forks(ctx, late.ID); err != nil || len(got) != 0 {
+		t.Fatalf("Forks(leaf) = %v, %v; want none", got, err)
+	}
+	if got, err := store.Forks(ctx, ""); err != nil || len(got) != 0 {
+		t.Fatalf("Forks(\"\") = %v, %v; want none", got, err)
+	}
+}
@@ -0,0 +1,115 @@
+package session
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+// generatedImagesDirFor returns where the engine saves generated images for
+// sessions stored in dbPath. Only the default data directory has one, so a
+// database kept elsewhere never touches an unrelated "images" directory.
+func generatedImagesDirFor(dbPath string) string {
+	if dbPath == ":memory:" {
+		return ""
+	}
+	dataDir, err := GetDataDir()
+	if err != nil || filepath.Clean(filepath.Dir(dbPath)) != filepath.Clean(dataDir) {
+		return ""
+	}
+	return filepath.Join(dataDir, "images")
+}
+
+// removeSessionImages deletes the images the model generated in session id.
+func (s *SQLiteStore) removeSessionImages(id string) {
+	if s.imagesDir == "" {
+		return
+	}
+	name := llm.GeneratedImagesDirName(id)
+	if name == "" || name == llm.GeneratedImagesDirName("") {
+		return
+	}
+	if err := os.RemoveAll(filepath.Join(s.imagesDir, name)); err != nil {
+		slog.Warn("remove session images failed", "session", id, "error", err)
+	}
+}
+
+const (
+	// imagesPruneInterval is how often opening a store sweeps the images
+	// directory; every command opens one, so the sweep must not run each time.
+	imagesPruneInterval = 24 * time.Hour
+	// unsavedImagesMaxAge is how long images from requests without a session
+	// are kept.
+	unsavedImagesMaxAge = 30 * 24 * time.Hour
+	// imagesPruneStamp records when the images directory was last swept.
+	imagesPruneStamp = ".pruned"
+)
+
+// maintainImages sweeps the generated-images directory at most once per
+// imagesPruneInterval: it removes directories of sessions that no longer
+// exist and images from requests without a session older than
+// unsavedImagesMaxAge.
+func (s *SQLiteStore) maintainImages(ctx context.Context) {
+	if s.imagesDir == "" {
+		return
+	}
+	stamp := filepath.Join(s.imagesDir, imagesPruneStamp)
+	now := time.Now()
+	if info, err := os.Stat(stamp); err == nil && now.Sub(info.ModTime()) < imagesPruneInterval {
+		return
+	} else if os.IsNotExist(err) {
+		if _, err := os.Stat(s.imagesDir); err != nil {
+			return
+		}
+	}
+	if err := os.WriteFile(stamp, nil, 0o600); err != nil {
+		return
+	}
+	_ = os.Chtimes(stamp, now, now)
+	s.pruneOrphanImages(ctx)
+	pruneUnsavedImages(filepath.Join(s.imagesDir, llm.GeneratedImagesDirName("")), now.Add(-unsavedImagesMaxAge))
+}
+
+// pruneUnsavedImages removes files in dir last modified before cutoff.
+func pruneUnsavedImages(dir string, cutoff time.Time) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			slog.Warn("remove unsaved image failed", "path", filepath.Join(dir, entry.Name()), "error", err)
+		}
+	}
+}
+
+// pruneOrphanImages removes generated-image directories whose session no
+// longer exists, such as sessions purged by cleanup or deleted by an older
+// binary. Images from requests without a session are left alone.
+func (s *SQLiteStore) pruneOrphanImages(ctx context.Context) {
+	entries, err := os.ReadDir(s.imagesDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || name == llm.GeneratedImagesDirName("") {
+			continue
+		}
+		var exists int
+		if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sessions WHERE id = ?", name).Scan(&exists); err != nil {
+			return
+		}
+		if exists == 0 {
+			s.removeSessionImages(name)
+		}
+	}
+}
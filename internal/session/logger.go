@@ -55,6 +55,30 @@ func (s *LoggingStore) Create(ctx context.Context, sess *Session) error {
 	return err
 }
 
+// Fork delegates the optional fork capability when available.
+func (s *LoggingStore) Fork(ctx context.Context, sessionID string, atMessageSequence int) (*Session, error) {
+	forker, ok := s.Store.(Forker)
+	if !ok {
+		return nil, ErrForkUnsupported
+	}
+	sess, err := forker.Fork(ctx, sessionID, atMessageSequence)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		s.logOnce("Fork", err)
+	}
+	return sess, err
+}
+
+// Forks delegates the optional fork capability when available.
+func (s *LoggingStore) Forks(ctx context.Context, parentID string) ([]SessionSummary, error) {
+	forker, ok := s.Store.(Forker)
+	if !ok {
+		return nil, nil
+	}
+	forks, err := forker.Forks(ctx, parentID)
+	s.logOnce("Forks", err)
+	return forks, err
+}
+
 // Update wraps Store.Update with error logging.
 func (s *LoggingStore) Update(ctx context.Context, sess *Session) error {
 	err := s.Store.Update(ctx, sess)
@@ -62,6 +86,14 @@ func (s *LoggingStore) Update(ctx context.Context, sess *Session) error {
 	return err
 }
 
+// Ping delegates the optional health-check capability when available.
+func (s *LoggingStore) Ping(ctx context.Context) error {
+	if pinger, ok := s.Store.(Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
 // LoadPlanSnapshot delegates the optional latest-plan capability when available.
 func (s *LoggingStore) LoadPlanSnapshot(ctx context.Context, sessionID string) (planpkg.Snapshot, int64, error) {
 	store, ok := s.Store.(PlanSnapshotStore)
@@ -136,6 +168,20 @@ func (s *LoggingStore) UpdateShare(ctx context.Context, id string, share *ShareS
 	return err
 }
 
+// SetMessagePinned forwards to the wrapped store's pin update when supported.
+// Stores without pin persistence (e.g. NoopStore) succeed as a no-op.
+func (s *LoggingStore) SetMessagePinned(ctx context.Context, sessionID string, messageID int64, pinned bool) error {
+	updater, ok := s.Store.(MessagePinUpdater)
+	if !ok {
+		return nil
+	}
+	err := updater.SetMessagePinned(ctx, sessionID, messageID, pinned)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		s.logOnce("SetMessagePinned", err)
+	}
+	return err
+}
+
 // AddMessage wraps Store.AddMessage with error logging.
 func (s *LoggingStore) AddMessage(ctx context.Context, sessionID string, msg *Message) error {
 	err := s.Store.AddMessage(ctx, sessionID, msg)
@@ -414,6 +460,14 @@ func (s *LoggingStore) DeleteProviderState(ctx context.Context, sessionID, provi
 	return err
 }
 
+// TruncateMessages wraps TruncateMessages with error logging, keeping the
+// fallback for wrapped stores without a fast path.
+func (s *LoggingStore) TruncateMessages(ctx context.Context, sessionID string, fromSequence int) error {
+	err := TruncateMessages(ctx, s.Store, sessionID, fromSequence)
+	s.logOnce("TruncateMessages", err)
+	return err
+}
+
 // ReplaceCompactedMessages wraps optional Store.ReplaceCompactedMessages with error logging.
 func (s *LoggingStore) ReplaceCompactedMessages(ctx context.Context, sessionID string, messages []Message) error {
 	replacer, ok := s.Store.(interface {
@@ -434,6 +488,13 @@ func (s *LoggingStore) UpdateMetrics(ctx context.Context, id string, llmTurns, t
 	return err
 }
 
+// UpdateModelMetrics wraps Store.UpdateModelMetrics with error logging.
+func (s *LoggingStore) UpdateModelMetrics(ctx context.Context, id string, um UsageModel, llmTurns, toolCalls, inputTokens, outputTokens, cachedInputTokens, cacheWriteTokens int) error {
+	err := s.Store.UpdateModelMetrics(ctx, id, um, llmTurns, toolCalls, inputTokens, outputTokens, cachedInputTokens, cacheWriteTokens)
+	s.logOnce("UpdateModelMetrics", err)
+	return err
+}
+
 // UpdateContextEstimate wraps Store.UpdateContextEstimate with error logging.
 func (s *LoggingStore) UpdateContextEstimate(ctx context.Context, id string, lastTotalTokens, lastMessageCount int) error {
 	err := s.Store.UpdateContextEstimate(ctx, id, lastTotalTokens, lastMessageCount)
@@ -0,0 +1,102 @@
+package session
+
+import (
+	"context"
+	"testing"
+
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+func TestMessageStatsLine(t *testing.T) {
+	call := func(id string) llm.Part {
+		return llm.Part{Type: llm.PartToolCall, ToolCall: &llm.ToolCall{ID: id, Name: "shell"}}
+	}
+	tests := []struct {
+		name string
+		msg  Message
+		want string
+	}{
+		{
+			name: "all fields",
+			msg: Message{Role: llm.RoleAssistant, DurationMs: 4200, InputTokens: 1832, OutputTokens: 412, Model: "gpt-5.2-codex",
+				Parts: []llm.Part{call("a"), call("b"), call("c")}},
+			want: "4.2s · 1,832 in / 412 out · gpt-5.2-codex · 3 tool calls",
+		},
+		{
+			name: "single tool call",
+			msg:  Message{Role: llm.RoleAssistant, DurationMs: 900, Parts: []llm.Part{call("a")}},
+			want: "0.9s · 1 tool call",
+		},
+		{
+			name: "large counts",
+			msg:  Message{Role: llm.RoleAssistant, InputTokens: 1234567, OutputTokens: 12},
+			want: "1,234,567 in / 12 out",
+		},
+		{
+			name: "nothing recorded",
+			msg:  Message{Role: llm.RoleAssistant},
+			want: "",
+		},
+		{
+			name: "user message",
+			msg:  Message{Role: llm.RoleUser, DurationMs: 1000, Model: "m"},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.msg.StatsLine(); got != tt.want {
+				t.Fatalf("StatsLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLiteStorePersistsMessageUsage(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	store, err := NewSQLiteStore(DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	sess := &Session{ID: NewID(), Provider: "test", Model: "test-model", Mode: ModeChat}
+	if err := store.Create(ctx, sess); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	msg := NewMessage(sess.ID, llm.AssistantText("done"), -1)
+	msg.DurationMs = 4200
+	msg.InputTokens = 1832
+	msg.OutputTokens = 412
+	msg.Model = "gpt-5.2-codex"
+	if err := store.AddMessage(ctx, sess.ID, msg); err != nil {
+		t.Fatalf("AddMessage: %v", err)
+	}
+
+	msgs, err := store.GetMessages(ctx, sess.ID, 0, 0)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	if len(msgs) != 1 {
+		t.Fatalf("got %d messages, want 1", len(msgs))
+	}
+	got := msgs[0]
+	if got.InputTokens != 1832 || got.OutputTokens != 412 || got.Model != "gpt-5.2-codex" {
+		t.Fatalf("usage = %d in / %d out model %q, want 1832 / 412 gpt-5.2-codex", got.InputTokens, got.OutputTokens, got.Model)
+	}
+
+	got.OutputTokens = 500
+	if err := store.UpdateMessage(ctx, sess.ID, &got); err != nil {
+		t.Fatalf("UpdateMessage: %v", err)
+	}
+	msgs, err = store.GetMessages(ctx, sess.ID, 0, 0)
+	if err != nil {
+		t.Fatalf("GetMessages after update: %v", err)
+	}
+	if msgs[0].OutputTokens != 500 {
+		t.Fatalf("OutputTokens after update = %d, want 500", msgs[0].OutputTokens)
+	}
+}
@@ -96,6 +96,10 @@ func (s *NoopStore) UpdateMetrics(ctx context.Context, id string, llmTurns, tool
 	return nil
 }
 
+func (s *NoopStore) UpdateModelMetrics(ctx context.Context, id string, um UsageModel, llmTurns, toolCalls, inputTokens, outputTokens, cachedInputTokens, cacheWriteTokens int) error {
+	return nil
+}
+
 func (s *NoopStore) UpdateContextEstimate(ctx context.Context, id string, lastTotalTokens, lastMessageCount int) error {
 	return nil
 }
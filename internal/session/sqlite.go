@@ -9,6 +9,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -23,31 +24,41 @@ import (
 type SQLiteStore struct {
 	db                       *sql.DB
 	cfg                      Config
-	hasGeneratedTitles       bool // true if sessions table has generated title columns
-	hasCompactionSeq         bool // true if sessions table has compaction_seq column
-	hasCompactionCount       bool // true if sessions table has compaction_count column
-	hasCacheWriteTokens      bool // true if sessions table has cache_write_tokens column
-	hasOrigin                bool // true if sessions table has origin column
-	hasPinned                bool // true if sessions table has pinned column
-	hasTitleSkippedAt        bool // true if sessions table has title_skipped_at column
-	hasLastUserMessageAt     bool // true if sessions table has last_user_message_at column
-	hasLastMessageAt         bool // true if sessions table has last_message_at column
-	hasLastTotalTokens       bool // true if sessions table has last_total_tokens column
-	hasLastMessageCount      bool // true if sessions table has last_message_count column
-	hasMessageCount          bool // true if sessions table has message_count column
-	hasReasoningEffort       bool // true if sessions table has reasoning_effort column
-	hasReasoningMode         bool // true if sessions table has reasoning_mode column
-	hasApprovalMode          bool // true if sessions table has approval_mode column
-	hasWorktreeDir           bool // true if sessions table has worktree_dir column
-	hasGoal                  bool // true if sessions table has goal column
-	hasShare                 bool // true if sessions table has share column
-	hasTranscriptRev         bool // true if sessions table has transcript_rev column
-	hasMessagesTable         bool // true if the messages table exists
-	hasMessageCompactionTail bool // true if messages table has compaction_tail column
-	hasMessageStreamIdentity bool // true if messages table has response-scoped segment identity columns
+	imagesDir                string // generated images, one subdirectory per session; "" for in-memory stores
+	hasGeneratedTitles       bool   // true if sessions table has generated title columns
+	hasCompactionSeq         bool   // true if sessions table has compaction_seq column
+	hasCompactionCount       bool   // true if sessions table has compaction_count column
+	hasCacheWriteTokens      bool   // true if sessions table has cache_write_tokens column
+	hasOrigin                bool   // true if sessions table has origin column
+	hasPinned                bool   // true if sessions table has pinned column
+	hasTitleSkippedAt        bool   // true if sessions table has title_skipped_at column
+	hasLastUserMessageAt     bool   // true if sessions table has last_user_message_at column
+	hasLastMessageAt         bool   // true if sessions table has last_message_at column
+	hasLastTotalTokens       bool   // true if sessions table has last_total_tokens column
+	hasLastMessageCount      bool   // true if sessions table has last_message_count column
+	hasMessageCount          bool   // true if sessions table has message_count column
+	hasReasoningEffort       bool   // true if sessions table has reasoning_effort column
+	hasReasoningMode         bool   // true if sessions table has reasoning_mode column
+	hasApprovalMode          bool   // true if sessions table has approval_mode column
+	hasWorktreeDir           bool   // true if sessions table has worktree_dir column
+	hasGoal                  bool   // true if sessions table has goal column
+	hasShare                 bool   // true if sessions table has share column
+	hasAutoArchivedAt        bool   // true if sessions table has auto_archived_at column
+	hasTranscriptRev         bool   // true if sessions table has transcript_rev column
+	hasParentKind            bool   // true if sessions table has parent_kind column
+	hasMessagesTable         bool   // true if the messages table exists
+	hasMessageCompactionTail bool   // true if messages table has compaction_tail column
+	hasMessageStreamIdentity bool   // true if messages table has response-scoped segment identity columns
+	hasMessagePinned         bool   // true if messages table has pinned column
+	hasMessageTurnID         bool   // true if messages table has turn_id column
+	hasMessageUsage          bool   // true if messages table has input_tokens/output_tokens/model columns
 }
 
 var _ MessageSequenceStore = (*SQLiteStore)(nil)
+var _ MessagePinUpdater = (*SQLiteStore)(nil)
+var _ SessionImporter = (*SQLiteStore)(nil)
+var _ ToolCallStatsStore = (*SQLiteStore)(nil)
+var _ UsageStatsStore = (*SQLiteStore)(nil)
 
 // Schema for the sessions database.
 const schema = `
@@ -99,7 +110,9 @@ CREATE TABLE IF NOT EXISTS sessions (
     share TEXT,
     compaction_seq INTEGER DEFAULT -1,
     compaction_count INTEGER DEFAULT 0,
-    transcript_rev INTEGER NOT NULL DEFAULT 0
+    transcript_rev INTEGER NOT NULL DEFAULT 0,
+    auto_archived_at TIMESTAMP,
+    parent_kind TEXT NOT NULL DEFAULT ''
 );
 
 CREATE TABLE IF NOT EXISTS messages (
@@ -116,7 +129,12 @@ CREATE TABLE IF NOT EXISTS messages (
     response_id TEXT NOT NULL DEFAULT '',
     assistant_segment_ordinal INTEGER NOT NULL DEFAULT -1,
     segment_start_sequence INTEGER NOT NULL DEFAULT 0,
-    segment_end_sequence INTEGER NOT NULL DEFAULT 0
+    segment_end_sequence INTEGER NOT NULL DEFAULT 0,
+    pinned BOOLEAN NOT NULL DEFAULT FALSE,
+    turn_id TEXT NOT NULL DEFAULT '',
+    input_tokens INTEGER NOT NULL DEFAULT 0,
+    output_tokens INTEGER NOT NULL DEFAULT 0,
+    model TEXT NOT NULL DEFAULT ''
 );
 
 CREATE INDEX IF NOT EXISTS idx_sessions_updated_at ON sessions(updated_at DESC);
@@ -141,6 +159,39 @@ CREATE TABLE IF NOT EXISTS session_plans (
     updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
 
+-- One row per tool call, for tool analytics. Filled in from the tool call
+-- and tool result parts as messages are saved.
+CREATE TABLE IF NOT EXISTS tool_calls (
+    session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    call_id TEXT NOT NULL,
+    message_id INTEGER,
+    tool_name TEXT NOT NULL,
+    args_hash TEXT NOT NULL DEFAULT '',
+    duration_ms INTEGER,
+    success BOOLEAN,
+    error_text TEXT NOT NULL DEFAULT '',
+    completed_at TIMESTAMP,
+    PRIMARY KEY (session_id, call_id)
+);
+CREATE INDEX IF NOT EXISTS idx_tool_calls_completed ON tool_calls(completed_at);
+
+-- Token usage of each provider turn, for usage and cost reports. Kept apart
+-- from messages so compaction and history rewrites do not drop it.
+CREATE TABLE IF NOT EXISTS message_usage (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    message_id INTEGER,
+    provider TEXT NOT NULL DEFAULT '',
+    model TEXT NOT NULL DEFAULT '',
+    input_tokens INTEGER NOT NULL DEFAULT 0,
+    output_tokens INTEGER NOT NULL DEFAULT 0,
+    cached_input_tokens INTEGER NOT NULL DEFAULT 0,
+    cache_write_tokens INTEGER NOT NULL DEFAULT 0,
+    day TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_message_usage_created ON message_usage(created_at);
+
 -- Metadata table for current session tracking
 CREATE TABLE IF NOT EXISTS metadata (
     key TEXT PRIMARY KEY,
@@ -176,6 +227,11 @@ CREATE TRIGGER IF NOT EXISTS messages_au AFTER UPDATE OF text_content ON message
     INSERT INTO messages_fts(messages_fts, rowid, text_content) VALUES ('delete', old.id, old.text_content);
     INSERT INTO messages_fts(rowid, text_content) VALUES (new.id, new.text_content);
 END;
+
+-- Forks outlive their parent: detach them instead of failing the parent delete
+CREATE TRIGGER IF NOT EXISTS sessions_detach_forks BEFORE DELETE ON sessions BEGIN
+    UPDATE sessions SET parent_id = NULL WHERE parent_id = old.id;
+END;
 `
 
 const messagesTableSchema = `
@@ -193,7 +249,12 @@ CREATE TABLE messages (
     response_id TEXT NOT NULL DEFAULT '',
     assistant_segment_ordinal INTEGER NOT NULL DEFAULT -1,
     segment_start_sequence INTEGER NOT NULL DEFAULT 0,
-    segment_end_sequence INTEGER NOT NULL DEFAULT 0
+    segment_end_sequence INTEGER NOT NULL DEFAULT 0,
+    pinned BOOLEAN NOT NULL DEFAULT FALSE,
+    turn_id TEXT NOT NULL DEFAULT '',
+    input_tokens INTEGER NOT NULL DEFAULT 0,
+    output_tokens INTEGER NOT NULL DEFAULT 0,
+    model TEXT NOT NULL DEFAULT ''
 )`
 
 // NewSQLiteStore creates a new SQLite-based session store.
@@ -250,6 +311,7 @@ func NewSQLiteStore(cfg Config) (*SQLiteStore, error) {
 	}
 
 	store := &SQLiteStore{db: db, cfg: cfg}
+	store.imagesDir = generatedImagesDirFor(dbPath)
 
 	// Read-write stores have just created or migrated the schema above, so the
 	// current optional columns are known to be present. Read-only stores skip
@@ -268,6 +330,7 @@ func NewSQLiteStore(cfg Config) (*SQLiteStore, error) {
 			// Log but don't fail
 			fmt.Fprintf(os.Stderr, "warning: session cleanup failed: %v\n", err)
 		}
+		store.maintainImages(context.Background())
 	}
 
 	return store, nil
@@ -277,7 +340,7 @@ func NewSQLiteStore(cfg Config) (*SQLiteStore, error) {
 // - Fresh databases get the full schema from `schema` const and start at this version
 // - Existing databases run migrations to reach this version
 // Increment when adding new migrations.
-const schemaVersion = 42
+const schemaVersion = 50
 
 // migration represents a schema migration.
 type migration struct {
@@ -1149,6 +1212,124 @@ var migrations = []migration{
 			return nil
 		},
 	},
+	{
+		version:     43,
+		description: "add message pinned flag",
+		up: func(db schemaExecutor) error {
+			_, err := db.Exec("ALTER TABLE messages ADD COLUMN pinned BOOLEAN NOT NULL DEFAULT FALSE")
+			if err != nil && !isDuplicateColumnError(err) {
+				return err
+			}
+			return nil
+		},
+	},
+	{
+		version:     44,
+		description: "detach forked sessions when their parent is deleted",
+		up: func(db schemaExecutor) error {
+			_, err := db.Exec(`CREATE TRIGGER IF NOT EXISTS sessions_detach_forks BEFORE DELETE ON sessions BEGIN
+    UPDATE sessions SET parent_id = NULL WHERE parent_id = old.id;
+END`)
+			return err
+		},
+	},
+	{
+		version:     45,
+		description: "add auto_archived_at for soft-deleted sessions",
+		up: func(db schemaExecutor) error {
+			_, err := db.Exec("ALTER TABLE sessions ADD COLUMN auto_archived_at TIMESTAMP")
+			if err != nil && !isDuplicateColumnError(err) {
+				return err
+			}
+			return nil
+		},
+	},
+	{
+		version:     46,
+		description: "create tool_calls table for tool analytics",
+		up: func(db schemaExecutor) error {
+			if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS tool_calls (
+				session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+				call_id TEXT NOT NULL,
+				message_id INTEGER,
+				tool_name TEXT NOT NULL,
+				args_hash TEXT NOT NULL DEFAULT '',
+				duration_ms INTEGER,
+				success BOOLEAN,
+				error_text TEXT NOT NULL DEFAULT '',
+				completed_at TIMESTAMP,
+				PRIMARY KEY (session_id, call_id)
+			)`); err != nil {
+				return err
+			}
+			_, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_tool_calls_completed ON tool_calls(completed_at)`)
+			return err
+		},
+	},
+	{
+		version:     47,
+		description: "create message_usage table for usage and cost reports",
+		up: func(db schemaExecutor) error {
+			if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS message_usage (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+				message_id INTEGER,
+				provider TEXT NOT NULL DEFAULT '',
+				model TEXT NOT NULL DEFAULT '',
+				input_tokens INTEGER NOT NULL DEFAULT 0,
+				output_tokens INTEGER NOT NULL DEFAULT 0,
+				cached_input_tokens INTEGER NOT NULL DEFAULT 0,
+				cache_write_tokens INTEGER NOT NULL DEFAULT 0,
+				day TEXT NOT NULL,
+				created_at TIMESTAMP NOT NULL
+			)`); err != nil {
+				return err
+			}
+			_, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_message_usage_created ON message_usage(created_at)`)
+			return err
+		},
+	},
+	{
+		version:     48,
+		description: "add message turn_id for correlating rows with debug logs",
+		up: func(db schemaExecutor) error {
+			_, err := db.Exec("ALTER TABLE messages ADD COLUMN turn_id TEXT NOT NULL DEFAULT ''")
+			if err != nil && !isDuplicateColumnError(err) {
+				return err
+			}
+			return nil
+		},
+	},
+	{
+		version:     49,
+		description: "add per-message token usage and model for transcript stats",
+		up: func(db schemaExecutor) error {
+			statements := []string{
+				"ALTER TABLE messages ADD COLUMN input_tokens INTEGER NOT NULL DEFAULT 0",
+				"ALTER TABLE messages ADD COLUMN output_tokens INTEGER NOT NULL DEFAULT 0",
+				"ALTER TABLE messages ADD COLUMN model TEXT NOT NULL DEFAULT ''",
+			}
+			for _, statement := range statements {
+				if _, err := db.Exec(statement); err != nil && !isDuplicateColumnError(err) {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		version:     50,
+		description: "add sessions.parent_kind to tell forks from child runs",
+		up: func(db schemaExecutor) error {
+			// Not "kind": databases from the retired side-session rework may still
+			// carry a kind column constrained to root/side. Forks made before this
+			// migration can't be told apart from child runs, so they stay unmarked.
+			if _, err := db.Exec("ALTER TABLE sessions ADD COLUMN parent_kind TEXT NOT NULL DEFAULT ''"); err != nil && !isDuplicateColumnError(err) {
+				return err
+			}
+			return nil
+		},
+	},
 }
 
 // Keep in sync with llm.IsInternalCompactionSummaryText. SQLite migrations and
@@ -1431,11 +1612,61 @@ func isDuplicateColumnError(err error) bool {
 		strings.Contains(errStr, "already exists")
 }
 
-// cleanup removes old sessions based on configuration.
+// cleanup retires old sessions based on configuration. Sessions past
+// max_age_days or beyond max_count are archived first and only deleted once
+// they have stayed archived for purge_grace_days, so `sessions restore` can
+// bring them back. With hard_delete they are deleted immediately instead.
 func (s *SQLiteStore) cleanup() error {
 	ctx := context.Background()
+	if s.cfg.HardDelete {
+		return s.hardDeleteExpired(ctx)
+	}
+
+	now := time.Now()
+	// A session used again after being auto-archived is live; unarchive it so
+	// the age and count checks below see it afresh.
+	if _, err := s.db.ExecContext(ctx, `
+		UPDATE sessions SET archived = FALSE, auto_archived_at = NULL
+		WHERE auto_archived_at IS NOT NULL AND updated_at > auto_archived_at`); err != nil {
+		return fmt.Errorf("unarchive reused sessions: %w", err)
+	}
+
+	if s.cfg.MaxAgeDays > 0 {
+		cutoff := now.AddDate(0, 0, -s.cfg.MaxAgeDays)
+		_, err := s.db.ExecContext(ctx,
+			"UPDATE sessions SET archived = TRUE, auto_archived_at = ? WHERE updated_at < ? AND archived = FALSE",
+			now, cutoff)
+		if err != nil {
+			return fmt.Errorf("archive old sessions: %w", err)
+		}
+	}
+
+	if s.cfg.MaxCount > 0 {
+		_, err := s.db.ExecContext(ctx, `
+			UPDATE sessions SET archived = TRUE, auto_archived_at = ? WHERE id IN (
+				SELECT id FROM sessions
+				WHERE archived = FALSE
+				ORDER BY updated_at DESC
+				LIMIT -1 OFFSET ?
+			)`, now, s.cfg.MaxCount)
+		if err != nil {
+			return fmt.Errorf("enforce max count: %w", err)
+		}
+	}
 
-	// Delete old sessions
+	// Sessions archived by the user have no auto_archived_at and are never purged.
+	purgeBefore := now.AddDate(0, 0, -max(0, s.cfg.PurgeGraceDays))
+	if _, err := s.db.ExecContext(ctx,
+		"DELETE FROM sessions WHERE archived = TRUE AND auto_archived_at IS NOT NULL AND auto_archived_at < ?",
+		purgeBefore); err != nil {
+		return fmt.Errorf("purge archived sessions: %w", err)
+	}
+	return nil
+}
+
+// hardDeleteExpired deletes sessions past max_age_days or beyond max_count
+// without an archive stage (sessions.hard_delete).
+func (s *SQLiteStore) hardDeleteExpired(ctx context.Context) error {
 	if s.cfg.MaxAgeDays > 0 {
 		cutoff := time.Now().AddDate(0, 0, -s.cfg.MaxAgeDays)
 		_, err := s.db.ExecContext(ctx,
@@ -1446,7 +1677,6 @@ func (s *SQLiteStore) cleanup() error {
 		}
 	}
 
-	// Keep only max_count sessions
 	if s.cfg.MaxCount > 0 {
 		_, err := s.db.ExecContext(ctx, `
 			DELETE FROM sessions WHERE id IN (
@@ -1536,6 +1766,14 @@ func (s *SQLiteStore) Create(ctx context.Context, sess *Session) error {
 			sharePlaceholder = ", ?"
 			shareArgs = []any{shareJSONString(sess.Share)}
 		}
+		kindCol := ""
+		kindPlaceholder := ""
+		var kindArgs []any
+		if s.hasParentKind {
+			kindCol = ", parent_kind"
+			kindPlaceholder = ", ?"
+			kindArgs = []any{string(sess.Kind)}
+		}
 		insertArgs := []any{
 			sess.ID, sess.Name, sess.Summary, nullString(sess.GeneratedShortTitle), nullString(sess.GeneratedLongTitle), nullString(string(sess.TitleSource)), nullTime(sess.TitleGeneratedAt), sess.TitleBasisMsgSeq, nullTime(sess.TitleSkippedAt),
 			sess.Provider, nullString(sess.ProviderKey), sess.Model, string(sess.Mode),
@@ -1553,14 +1791,15 @@ func (s *SQLiteStore) Create(ctx context.Context, sess *Session) error {
 		)
 		insertArgs = append(insertArgs, goalArgs...)
 		insertArgs = append(insertArgs, shareArgs...)
+		insertArgs = append(insertArgs, kindArgs...)
 		insertArgs = append(insertArgs, reasoningEffortArgs...)
 		insertArgs = append(insertArgs, reasoningModeArgs...)
 		result, err := s.db.ExecContext(ctx, `
 			INSERT INTO sessions (id, number, name, summary, generated_short_title, generated_long_title, title_source, title_generated_at, title_basis_msg_seq, title_skipped_at,
 			                      provider, provider_key, model, mode`+approvalModeCol+`, origin, agent, cwd`+worktreeDirCol+`, created_at, updated_at, archived, pinned, parent_id, search, tools, mcp,
 			                      user_turns, llm_turns, tool_calls, input_tokens, cached_input_tokens, cache_write_tokens, output_tokens,
-				                      last_total_tokens, last_message_count, status, tags`+goalCol+shareCol+reasoningEffortCol+reasoningModeCol+`)
-			VALUES (?, (SELECT COALESCE(MAX(number), 0) + 1 FROM sessions), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?`+approvalModePlaceholder+`, ?, ?, ?`+worktreeDirPlaceholder+`, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?`+goalPlaceholder+sharePlaceholder+reasoningEffortPlaceholder+reasoningModePlaceholder+`)`,
+				                      last_total_tokens, last_message_count, status, tags`+goalCol+shareCol+kindCol+reasoningEffortCol+reasoningModeCol+`)
+			VALUES (?, (SELECT COALESCE(MAX(number), 0) + 1 FROM sessions), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?`+approvalModePlaceholder+`, ?, ?, ?`+worktreeDirPlaceholder+`, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?`+goalPlaceholder+sharePlaceholder+kindPlaceholder+reasoningEffortPlaceholder+reasoningModePlaceholder+`)`,
 			insertArgs...)
 		if err != nil {
 			return fmt.Errorf("insert session: %w", err)
@@ -1685,12 +1924,17 @@ func (s *SQLiteStore) Update(ctx context.Context, sess *Session) error {
 	if s.hasShare {
 		shareClause = ", share = ?"
 	}
+	autoArchivedClause := ""
+	if s.hasAutoArchivedAt {
+		// Unarchiving cancels a pending purge; staying archived keeps it.
+		autoArchivedClause = ", auto_archived_at = CASE WHEN ? THEN auto_archived_at ELSE NULL END"
+	}
 	query := `
 		UPDATE sessions SET name = ?, summary = ?, generated_short_title = ?, generated_long_title = ?, title_source = ?, title_generated_at = ?, title_basis_msg_seq = ?` +
 		titleSkippedAtClause + `,
 		       provider = ?, provider_key = ?, model = ?` + reasoningEffortClause + reasoningModeClause + `, mode = ?` + approvalModeClause + `, origin = ?, agent = ?, cwd = ?` + worktreeDirClause + `,
 		       updated_at = ?, archived = ?, pinned = ?, parent_id = ?, search = ?, tools = ?, mcp = ?,
-		       status = ?, tags = ?` + goalClause + shareClause + `
+		       status = ?, tags = ?` + goalClause + shareClause + autoArchivedClause + `
 		WHERE id = ?`
 
 	args := []any{
@@ -1731,6 +1975,9 @@ func (s *SQLiteStore) Update(ctx context.Context, sess *Session) error {
 	if s.hasShare {
 		args = append(args, shareJSONString(sess.Share))
 	}
+	if s.hasAutoArchivedAt {
+		args = append(args, sess.Archived)
+	}
 	args = append(args, sess.ID)
 
 	result, err := s.db.ExecContext(ctx, query, args...)
@@ -1769,6 +2016,23 @@ func (s *SQLiteStore) UpdateGoal(ctx context.Context, id string, goal *Goal) err
 	return nil
 }
 
+// SetMessagePinned sets or clears the pinned flag on one persisted message.
+func (s *SQLiteStore) SetMessagePinned(ctx context.Context, sessionID string, messageID int64, pinned bool) error {
+	if !s.hasMessagePinned {
+		return fmt.Errorf("message pinned column is unavailable")
+	}
+	result, err := s.db.ExecContext(ctx,
+		"UPDATE messages SET pinned = ? WHERE id = ? AND session_id = ?", pinned, messageID, sessionID)
+	if err != nil {
+		return fmt.Errorf("update message pinned: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("message %d not found: %w", messageID, ErrNotFound)
+	}
+	return nil
+}
+
 // UpdateShare updates only persisted share metadata for a session.
 func (s *SQLiteStore) UpdateShare(ctx context.Context, id string, share *ShareState) error {
 	if !s.hasShare {
@@ -2000,8 +2264,20 @@ func (s *SQLiteStore) DeleteProviderState(ctx context.Context, sessionID, provid
 // UpdateMetrics atomically increments the metrics fields for a session.
 // All token counters use += to avoid clobbering concurrent accumulation.
 func (s *SQLiteStore) UpdateMetrics(ctx context.Context, id string, llmTurns, toolCalls, inputTokens, outputTokens, cachedInputTokens, cacheWriteTokens int) error {
+	return s.UpdateModelMetrics(ctx, id, UsageModel{}, llmTurns, toolCalls, inputTokens, outputTokens, cachedInputTokens, cacheWriteTokens)
+}
+
+// UpdateModelMetrics is UpdateMetrics with the token usage attributed to um.
+func (s *SQLiteStore) UpdateModelMetrics(ctx context.Context, id string, um UsageModel, llmTurns, toolCalls, inputTokens, outputTokens, cachedInputTokens, cacheWriteTokens int) error {
 	return retryOnBusy(ctx, 5, func() error {
-		_, err := s.db.ExecContext(ctx, `
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		now := time.Now()
+		if _, err := tx.ExecContext(ctx, `
 			UPDATE sessions SET
 			       llm_turns = llm_turns + ?,
 			       tool_calls = tool_calls + ?,
@@ -2011,8 +2287,15 @@ func (s *SQLiteStore) UpdateMetrics(ctx context.Context, id string, llmTurns, to
 			       output_tokens = output_tokens + ?,
 			       updated_at = ?
 			WHERE id = ?`,
-			llmTurns, toolCalls, inputTokens, cachedInputTokens, cacheWriteTokens, outputTokens, time.Now(), id)
-		return err
+			llmTurns, toolCalls, inputTokens, cachedInputTokens, cacheWriteTokens, outputTokens, now, id); err != nil {
+			return err
+		}
+		if inputTokens != 0 || outputTokens != 0 || cachedInputTokens != 0 || cacheWriteTokens != 0 {
+			if err := recordMessageUsage(ctx, tx, id, um, inputTokens, outputTokens, cachedInputTokens, cacheWriteTokens, now); err != nil {
+				return err
+			}
+		}
+		return tx.Commit()
 	})
 }
 
@@ -2081,9 +2364,175 @@ func (s *SQLiteStore) Delete(ctx context.Context, id string) error {
 	if rows == 0 {
 		return fmt.Errorf("session not found: %s", id)
 	}
+	s.removeSessionImages(id)
 	return nil
 }
 
+// Fork creates a new session whose transcript copies sessionID's messages up
+// to and including atMessageSequence (a negative value copies every message).
+// The fork inherits the parent's provider, model, agent and settings, records
+// the parent in ParentID, and keeps the compaction boundary when the copied
+// range reaches it.
+func (s *SQLiteStore) Fork(ctx context.Context, sessionID string, atMessageSequence int) (*Session, error) {
+	parent, err := s.Get(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("get session: %w", err)
+	}
+	if parent == nil {
+		return nil, fmt.Errorf("fork session %s: %w", sessionID, ErrNotFound)
+	}
+
+	now := time.Now()
+	child := &Session{
+		ID:              NewID(),
+		Summary:         parent.Summary,
+		Provider:        parent.Provider,
+		ProviderKey:     parent.ProviderKey,
+		Model:           parent.Model,
+		ReasoningEffort: parent.ReasoningEffort,
+		ReasoningMode:   parent.ReasoningMode,
+		Mode:            parent.Mode,
+		ApprovalMode:    parent.ApprovalMode,
+		Origin:          parent.Origin,
+		Agent:           parent.Agent,
+		CWD:             parent.CWD,
+		WorktreeDir:     parent.WorktreeDir,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		ParentID:        parent.ID,
+		Kind:            KindFork,
+		Search:          parent.Search,
+		Tools:           parent.Tools,
+		MCP:             parent.MCP,
+		Status:          StatusActive,
+		Tags:            parent.Tags,
+		Goal:            parent.Goal,
+	}
+	keepBoundary := parent.CompactionSeq >= 0 && (atMessageSequence < 0 || parent.CompactionSeq <= atMessageSequence)
+	if err := s.Create(ctx, child); err != nil {
+		return nil, err
+	}
+
+	err = retryOnBusy(ctx, 5, func() error {
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO messages (session_id, role, parts, text_content, duration_ms, turn_index, created_at, sequence, compaction_tail, response_id, assistant_segment_ordinal, segment_start_sequence, segment_end_sequence, pinned, turn_id, input_tokens, output_tokens, model)
+			SELECT ?, role, parts, text_content, duration_ms, turn_index, created_at, sequence, CASE WHEN ? THEN compaction_tail ELSE FALSE END, response_id, assistant_segment_ordinal, segment_start_sequence, segment_end_sequence, pinned, turn_id, input_tokens, output_tokens, model
+			FROM messages
+			WHERE session_id = ? AND (? < 0 OR sequence <= ?)
+			ORDER BY sequence`,
+			child.ID, keepBoundary, parent.ID, atMessageSequence, atMessageSequence); err != nil {
+			return fmt.Errorf("copy messages: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE sessions SET user_turns = (SELECT COUNT(*) FROM messages WHERE session_id = ? AND role = 'user') WHERE id = ?",
+			child.ID, child.ID); err != nil {
+			return fmt.Errorf("update fork user turns: %w", err)
+		}
+		if keepBoundary && s.hasCompactionSeq {
+			if _, err := tx.ExecContext(ctx, "UPDATE sessions SET compaction_seq = ? WHERE id = ?", parent.CompactionSeq, child.ID); err != nil {
+				return fmt.Errorf("copy compaction boundary: %w", err)
+			}
+			if s.hasCompactionCount {
+				if _, err := tx.ExecContext(ctx, "UPDATE sessions SET compaction_count = ? WHERE id = ?", parent.CompactionCount, child.ID); err != nil {
+					return fmt.Errorf("copy compaction count: %w", err)
+				}
+			}
+		}
+		if err := s.updateReplaceMessagesSessionMetadata(ctx, tx, child.ID, now, false); err != nil {
+			return err
+		}
+		if _, err := s.bumpTranscriptRev(ctx, tx, child.ID); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+	if err != nil {
+		// Don't leave an empty fork behind when the copy fails.
+		_ = s.Delete(context.WithoutCancel(ctx), child.ID)
+		return nil, fmt.Errorf("fork session: %w", err)
+	}
+	return s.Get(ctx, child.ID)
+}
+
+// Import creates a copy of sess under a new ID and session number and inserts
+// messages with their given sequence numbers, restoring the compaction
+// boundary from sess. Share metadata and ParentID are not carried over.
+func (s *SQLiteStore) Import(ctx context.Context, sess *Session, messages []Message) (*Session, error) {
+	imported := *sess
+	imported.ID = NewID()
+	imported.Number = 0
+	imported.ParentID = ""
+	imported.Share = nil
+	if err := s.Create(ctx, &imported); err != nil {
+		return nil, err
+	}
+
+	err := retryOnBusy(ctx, 5, func() error {
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		for i := range messages {
+			msg := messages[i]
+			if msg.CreatedAt.IsZero() {
+				msg.CreatedAt = time.Now()
+			}
+			partsJSON, err := msg.PartsJSONForStorage(s.cfg.StripImageBase64)
+			if err != nil {
+				return fmt.Errorf("serialize parts for message %d: %w", msg.Sequence, err)
+			}
+			if _, err := s.insertMessageAndBumpSession(ctx, tx, imported.ID, &msg, partsJSON, msg.Sequence); err != nil {
+				return err
+			}
+		}
+		if imported.CompactionSeq >= 0 && s.hasCompactionSeq {
+			if _, err := tx.ExecContext(ctx, "UPDATE sessions SET compaction_seq = ? WHERE id = ?", imported.CompactionSeq, imported.ID); err != nil {
+				return fmt.Errorf("restore compaction boundary: %w", err)
+			}
+			if s.hasCompactionCount {
+				if _, err := tx.ExecContext(ctx, "UPDATE sessions SET compaction_count = ? WHERE id = ?", imported.CompactionCount, imported.ID); err != nil {
+					return fmt.Errorf("restore compaction count: %w", err)
+				}
+			}
+		}
+		if err := s.updateReplaceMessagesSessionMetadata(ctx, tx, imported.ID, time.Now(), false); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+	if err != nil {
+		// Don't leave a half-imported session behind.
+		_ = s.Delete(context.WithoutCancel(ctx), imported.ID)
+		return nil, fmt.Errorf("import session: %w", err)
+	}
+	return s.Get(ctx, imported.ID)
+}
+
+// Forks returns the sessions forked from parentID, oldest first. Child runs
+// such as subagents share the ParentID but are not forks, so they are left
+// out.
+func (s *SQLiteStore) Forks(ctx context.Context, parentID string) ([]SessionSummary, error) {
+	if parentID == "" {
+		return nil, nil
+	}
+	forks, err := s.List(ctx, ListOptions{ParentID: parentID, Kind: KindFork, Archived: true, Limit: -1})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(forks, func(a, b SessionSummary) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return forks, nil
+}
+
 // List returns sessions matching the options.
 func (s *SQLiteStore) List(ctx context.Context, opts ListOptions) ([]SessionSummary, error) {
 	cacheWriteCol := "0"
@@ -2127,6 +2576,14 @@ func (s *SQLiteStore) List(ctx context.Context, opts ListOptions) ([]SessionSumm
 	if s.hasTranscriptRev {
 		transcriptRevCol = "COALESCE(s.transcript_rev, 0)"
 	}
+	autoArchivedAtCol := "NULL"
+	if s.hasAutoArchivedAt {
+		autoArchivedAtCol = "s.auto_archived_at"
+	}
+	kindCol := "''"
+	if s.hasParentKind {
+		kindCol = "COALESCE(s.parent_kind, '')"
+	}
 	fromClause := "FROM sessions s"
 	if opts.SortByNumberDesc {
 		// Completed-session walks page by descending session number. Force the
@@ -2138,7 +2595,8 @@ func (s *SQLiteStore) List(ctx context.Context, opts ListOptions) ([]SessionSumm
 		SELECT s.id, s.number, s.name, s.summary, ` + generatedShortCol + `, ` + generatedLongCol + `, ` + titleSourceCol + `,
 		       s.provider, COALESCE(s.provider_key, ''), s.model, s.mode, ` + originCol + `, s.archived, ` + pinnedCol + `, s.created_at, s.updated_at, ` + lastMessageAtCol + `,
 		       ` + messageCountCol + ` as message_count, ` + transcriptRevCol + ` as transcript_rev,
-		       s.user_turns, s.llm_turns, s.tool_calls, s.input_tokens, s.cached_input_tokens, ` + cacheWriteCol + `, s.output_tokens, s.status, s.tags, ` + worktreeDirCol + `, ` + goalCol + `, ` + shareCol + `
+		       s.user_turns, s.llm_turns, s.tool_calls, s.input_tokens, s.cached_input_tokens, ` + cacheWriteCol + `, s.output_tokens, s.status, s.tags, ` + worktreeDirCol + `, ` + goalCol + `, ` + shareCol + `,
+		       COALESCE(s.parent_id, ''), (SELECT p.number FROM sessions p WHERE p.id = s.parent_id), ` + autoArchivedAtCol + `, ` + kindCol + `
 		` + fromClause + `
 		WHERE 1=1`
 	args := []any{}
@@ -2163,6 +2621,14 @@ func (s *SQLiteStore) List(ctx context.Context, opts ListOptions) ([]SessionSumm
 		query += " AND s.status = ?"
 		args = append(args, string(opts.Status))
 	}
+	if opts.ParentID != "" {
+		query += " AND s.parent_id = ?"
+		args = append(args, opts.ParentID)
+	}
+	if opts.Kind != "" {
+		query += " AND " + kindCol + " = ?"
+		args = append(args, string(opts.Kind))
+	}
 	if opts.Tag != "" {
 		// Substring match on comma-separated tags
 		query += " AND (',' || s.tags || ',' LIKE '%,' || ? || ',%')"
@@ -2207,7 +2673,9 @@ func (s *SQLiteStore) List(ctx context.Context, opts ListOptions) ([]SessionSumm
 		query += " AND s.number < ?"
 		args = append(args, opts.BeforeNumber)
 	}
-	if !opts.Archived {
+	if opts.ArchivedOnly {
+		query += " AND s.archived = TRUE"
+	} else if !opts.Archived {
 		query += " AND s.archived = FALSE"
 	}
 
@@ -2253,16 +2721,22 @@ func (s *SQLiteStore) List(ctx context.Context, opts ListOptions) ([]SessionSumm
 	var results []SessionSummary
 	for rows.Next() {
 		var sum SessionSummary
-		var number sql.NullInt64
+		var number, parentNumber sql.NullInt64
 		var mode, status, tags, generatedShortTitle, generatedLongTitle, titleSource, origin, worktreeDir, goalRaw, shareRaw sql.NullString
-		var lastMessageAt sql.NullTime
+		var lastMessageAt, autoArchivedAt sql.NullTime
 		err := rows.Scan(&sum.ID, &number, &sum.Name, &sum.Summary, &generatedShortTitle, &generatedLongTitle, &titleSource, &sum.Provider, &sum.ProviderKey, &sum.Model, &mode,
 			&origin, &sum.Archived, &sum.Pinned, &sum.CreatedAt, &sum.UpdatedAt, &lastMessageAt, &sum.MessageCount, &sum.TranscriptRev,
 			&sum.UserTurns, &sum.LLMTurns, &sum.ToolCalls, &sum.InputTokens, &sum.CachedInputTokens, &sum.CacheWriteTokens, &sum.OutputTokens,
-			&status, &tags, &worktreeDir, &goalRaw, &shareRaw)
+			&status, &tags, &worktreeDir, &goalRaw, &shareRaw, &sum.ParentID, &parentNumber, &autoArchivedAt, &sum.Kind)
 		if err != nil {
 			return nil, fmt.Errorf("scan session summary: %w", err)
 		}
+		if autoArchivedAt.Valid && sum.Archived && !s.cfg.HardDelete {
+			sum.PurgeAfter = autoArchivedAt.Time.AddDate(0, 0, max(0, s.cfg.PurgeGraceDays))
+		}
+		if parentNumber.Valid {
+			sum.ParentNumber = parentNumber.Int64
+		}
 		if lastMessageAt.Valid {
 			sum.LastMessageAt = lastMessageAt.Time
 		}
@@ -2384,6 +2858,10 @@ func (s *SQLiteStore) Search(ctx context.Context, opts SearchOptions) ([]SearchR
 	if !opts.Archived {
 		filterClause += " AND s.archived = FALSE"
 	}
+	if opts.Role != "" {
+		filterClause += " AND m.role = ?"
+		args = append(args, string(opts.Role))
+	}
 	args = append(args, opts.Limit)
 
 	rows, err := s.db.QueryContext(ctx, `
@@ -2475,6 +2953,9 @@ func (s *SQLiteStore) AddMessage(ctx context.Context, sessionID string, msg *Mes
 	if msg.CreatedAt.IsZero() {
 		msg.CreatedAt = time.Now()
 	}
+	if msg.TurnID == "" {
+		msg.TurnID = llm.TurnIDFromContext(ctx)
+	}
 
 	partsJSON, err := msg.PartsJSONForStorage(s.cfg.StripImageBase64)
 	if err != nil {
@@ -2588,14 +3069,18 @@ func (s *SQLiteStore) bumpTranscriptRev(ctx context.Context, execer sqliteQueryE
 
 func (s *SQLiteStore) insertMessageAndBumpSession(ctx context.Context, execer sqliteQueryExecer, sessionID string, msg *Message, partsJSON string, sequence int) (int64, error) {
 	result, err := execer.ExecContext(ctx, `
-		INSERT INTO messages (session_id, role, parts, text_content, duration_ms, turn_index, created_at, sequence, compaction_tail, response_id, assistant_segment_ordinal, segment_start_sequence, segment_end_sequence)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		INSERT INTO messages (session_id, role, parts, text_content, duration_ms, turn_index, created_at, sequence, compaction_tail, response_id, assistant_segment_ordinal, segment_start_sequence, segment_end_sequence, pinned, turn_id, input_tokens, output_tokens, model)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		sessionID, string(msg.Role), partsJSON, msg.TextContent, msg.DurationMs, msg.TurnIndex, msg.CreatedAt, sequence, msg.CompactionTail,
-		msg.ResponseID, msg.AssistantSegmentOrdinal, msg.SegmentStartSequence, msg.SegmentEndSequence)
+		msg.ResponseID, msg.AssistantSegmentOrdinal, msg.SegmentStartSequence, msg.SegmentEndSequence, msg.Pinned, msg.TurnID,
+		msg.InputTokens, msg.OutputTokens, msg.Model)
 	if err != nil {
 		return 0, fmt.Errorf("insert message: %w", err)
 	}
 	id, _ := result.LastInsertId()
+	if err := recordToolCalls(ctx, execer, sessionID, id, msg); err != nil {
+		return 0, err
+	}
 
 	// Update session's updated_at. Preserve the existing sidebar activity
 	// semantics here: user/assistant role rows bump last_message_at, while
@@ -2655,9 +3140,10 @@ func (s *SQLiteStore) updateMessage(ctx context.Context, sessionID string, msg *
 		query += `, text_content = ?`
 		args = append(args, msg.TextContent)
 	}
-	query += `, duration_ms = ?, turn_index = ?, compaction_tail = ?, response_id = ?, assistant_segment_ordinal = ?, segment_start_sequence = ?, segment_end_sequence = ?
+	query += `, duration_ms = ?, turn_index = ?, compaction_tail = ?, response_id = ?, assistant_segment_ordinal = ?, segment_start_sequence = ?, segment_end_sequence = ?, input_tokens = ?, output_tokens = ?, model = ?
 			WHERE id = ? AND session_id = ?`
-	args = append(args, msg.DurationMs, msg.TurnIndex, msg.CompactionTail, msg.ResponseID, msg.AssistantSegmentOrdinal, msg.SegmentStartSequence, msg.SegmentEndSequence, msg.ID, sessionID)
+	args = append(args, msg.DurationMs, msg.TurnIndex, msg.CompactionTail, msg.ResponseID, msg.AssistantSegmentOrdinal, msg.SegmentStartSequence, msg.SegmentEndSequence,
+		msg.InputTokens, msg.OutputTokens, msg.Model, msg.ID, sessionID)
 
 	return retryOnBusy(ctx, 5, func() error {
 		tx, err := s.db.BeginTx(ctx, nil)
@@ -2677,6 +3163,9 @@ func (s *SQLiteStore) updateMessage(ctx context.Context, sessionID string, msg *
 		if rowsAffected == 0 {
 			return ErrNotFound
 		}
+		if err := recordToolCalls(ctx, tx, sessionID, msg.ID, msg); err != nil {
+			return err
+		}
 
 		// Bump session updated_at so sidebar sort reflects the snapshot.
 		// Intentionally do NOT touch last_message_at — the message was
@@ -2821,8 +3310,8 @@ func (s *SQLiteStore) ReplaceMessages(ctx context.Context, sessionID string, mes
 
 		if commonPrefix < len(messages) {
 			insertStmt, err := tx.PrepareContext(ctx, `
-				INSERT INTO messages (session_id, role, parts, text_content, duration_ms, turn_index, created_at, sequence, compaction_tail, response_id, assistant_segment_ordinal, segment_start_sequence, segment_end_sequence)
-				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+				INSERT INTO messages (session_id, role, parts, text_content, duration_ms, turn_index, created_at, sequence, compaction_tail, response_id, assistant_segment_ordinal, segment_start_sequence, segment_end_sequence, pinned, turn_id, input_tokens, output_tokens, model)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
 			if err != nil {
 				return fmt.Errorf("prepare message insert: %w", err)
 			}
@@ -2836,7 +3325,8 @@ func (s *SQLiteStore) ReplaceMessages(ctx context.Context, sessionID string, mes
 				}
 				_, err = insertStmt.ExecContext(ctx,
 					sessionID, string(msg.Role), partsJSON[i], msg.TextContent, msg.DurationMs, msg.TurnIndex, createdAt, i, false,
-					msg.ResponseID, msg.AssistantSegmentOrdinal, msg.SegmentStartSequence, msg.SegmentEndSequence)
+					msg.ResponseID, msg.AssistantSegmentOrdinal, msg.SegmentStartSequence, msg.SegmentEndSequence, msg.Pinned, msg.TurnID,
+					msg.InputTokens, msg.OutputTokens, msg.Model)
 				if err != nil {
 					return fmt.Errorf("insert message %d: %w", i, err)
 				}
@@ -2860,6 +3350,48 @@ func (s *SQLiteStore) ReplaceMessages(ctx context.Context, sessionID string, mes
 	})
 }
 
+// TruncateMessages deletes a session's messages at or after fromSequence and
+// recounts user turns. A compaction boundary inside the deleted tail is
+// cleared, since it would otherwise point past the end of the remaining rows.
+func (s *SQLiteStore) TruncateMessages(ctx context.Context, sessionID string, fromSequence int) error {
+	if fromSequence < 0 {
+		fromSequence = 0
+	}
+	return retryOnBusy(ctx, 5, func() error {
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		result, err := tx.ExecContext(ctx, "DELETE FROM messages WHERE session_id = ? AND sequence >= ?", sessionID, fromSequence)
+		if err != nil {
+			return fmt.Errorf("delete messages: %w", err)
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return nil
+		}
+
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE sessions SET user_turns = (SELECT COUNT(*) FROM messages WHERE session_id = ? AND role = 'user') WHERE id = ?",
+			sessionID, sessionID); err != nil {
+			return fmt.Errorf("update user turns: %w", err)
+		}
+		if s.hasCompactionSeq {
+			if _, err := tx.ExecContext(ctx, "UPDATE sessions SET compaction_seq = -1 WHERE id = ? AND compaction_seq >= ?", sessionID, fromSequence); err != nil {
+				return fmt.Errorf("clear compaction boundary: %w", err)
+			}
+		}
+		if err := s.updateReplaceMessagesSessionMetadata(ctx, tx, sessionID, time.Now(), false); err != nil {
+			return err
+		}
+		if _, err := s.bumpTranscriptRev(ctx, tx, sessionID); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
 // ReplaceCompactedMessages reconciles the active post-compaction history for a
 // session while preserving pre-compaction scrollback and the compaction boundary.
 // It must only be used with snapshots that start at the current compaction_seq.
@@ -2897,8 +3429,8 @@ func (s *SQLiteStore) ReplaceCompactedMessages(ctx context.Context, sessionID st
 
 		if commonPrefix < len(messages) {
 			insertStmt, err := tx.PrepareContext(ctx, `
-				INSERT INTO messages (session_id, role, parts, text_content, duration_ms, turn_index, created_at, sequence, compaction_tail, response_id, assistant_segment_ordinal, segment_start_sequence, segment_end_sequence)
-				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+				INSERT INTO messages (session_id, role, parts, text_content, duration_ms, turn_index, created_at, sequence, compaction_tail, response_id, assistant_segment_ordinal, segment_start_sequence, segment_end_sequence, pinned, turn_id, input_tokens, output_tokens, model)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
 			if err != nil {
 				return fmt.Errorf("prepare compacted message insert: %w", err)
 			}
@@ -2912,7 +3444,8 @@ func (s *SQLiteStore) ReplaceCompactedMessages(ctx context.Context, sessionID st
 				}
 				_, err = insertStmt.ExecContext(ctx,
 					sessionID, string(msg.Role), partsJSON[i], msg.TextContent, msg.DurationMs, msg.TurnIndex, createdAt, startSeq+i, msg.CompactionTail,
-					msg.ResponseID, msg.AssistantSegmentOrdinal, msg.SegmentStartSequence, msg.SegmentEndSequence)
+					msg.ResponseID, msg.AssistantSegmentOrdinal, msg.SegmentStartSequence, msg.SegmentEndSequence, msg.Pinned, msg.TurnID,
+					msg.InputTokens, msg.OutputTokens, msg.Model)
 				if err != nil {
 					return fmt.Errorf("insert compacted message %d: %w", i, err)
 				}
@@ -3190,8 +3723,8 @@ func (s *SQLiteStore) CompactMessages(ctx context.Context, sessionID string, mes
 		startSeq := maxSeq + 1
 
 		insertStmt, err := tx.PrepareContext(ctx, `
-			INSERT INTO messages (session_id, role, parts, text_content, duration_ms, turn_index, created_at, sequence, compaction_tail)
-			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+			INSERT INTO messages (session_id, role, parts, text_content, duration_ms, turn_index, created_at, sequence, compaction_tail, pinned, turn_id, input_tokens, output_tokens, model)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
 		if err != nil {
 			return fmt.Errorf("prepare message insert: %w", err)
 		}
@@ -3211,7 +3744,8 @@ func (s *SQLiteStore) CompactMessages(ctx context.Context, sessionID string, mes
 			}
 
 			_, err = insertStmt.ExecContext(ctx,
-				sessionID, string(msg.Role), partsJSON, msg.TextContent, msg.DurationMs, msg.TurnIndex, msg.CreatedAt, msg.Sequence, msg.CompactionTail)
+				sessionID, string(msg.Role), partsJSON, msg.TextContent, msg.DurationMs, msg.TurnIndex, msg.CreatedAt, msg.Sequence, msg.CompactionTail, msg.Pinned, msg.TurnID,
+				msg.InputTokens, msg.OutputTokens, msg.Model)
 			if err != nil {
 				return fmt.Errorf("insert message %d: %w", i, err)
 			}
@@ -3248,7 +3782,19 @@ func (s *SQLiteStore) messageSelectCols() string {
 	if s.hasMessageStreamIdentity {
 		streamIdentityCols = "COALESCE(response_id, '') AS response_id, COALESCE(assistant_segment_ordinal, -1) AS assistant_segment_ordinal, COALESCE(segment_start_sequence, 0) AS segment_start_sequence, COALESCE(segment_end_sequence, 0) AS segment_end_sequence"
 	}
-	return `id, session_id, role, parts, text_content, duration_ms, turn_index, created_at, sequence, ` + compactionTailCol + `, ` + streamIdentityCols
+	pinnedCol := "FALSE AS pinned"
+	if s.hasMessagePinned {
+		pinnedCol = "COALESCE(pinned, FALSE) AS pinned"
+	}
+	turnIDCol := "'' AS turn_id"
+	if s.hasMessageTurnID {
+		turnIDCol = "COALESCE(turn_id, '') AS turn_id"
+	}
+	usageCols := "0 AS input_tokens, 0 AS output_tokens, '' AS model"
+	if s.hasMessageUsage {
+		usageCols = "COALESCE(input_tokens, 0) AS input_tokens, COALESCE(output_tokens, 0) AS output_tokens, COALESCE(model, '') AS model"
+	}
+	return `id, session_id, role, parts, text_content, duration_ms, turn_index, created_at, sequence, ` + compactionTailCol + `, ` + streamIdentityCols + `, ` + pinnedCol + `, ` + turnIDCol + `, ` + usageCols
 }
 
 // TranscriptVersioned reports whether this database has durable transcript
@@ -3294,6 +3840,9 @@ func transcriptRowHasDisplayBody(role llm.Role, parts []llm.Part, planToolCalls
 		if _, ok := llm.ParseRunErrorMarker(msg); ok {
 			return true
 		}
+		if _, ok := llm.ParseInterruptedMarker(msg); ok {
+			return true
+		}
 	}
 	for _, part := range parts {
 		switch part.Type {
@@ -3569,7 +4118,8 @@ func scanMessageRows(rows *sql.Rows) ([]Message, error) {
 		var durationMs sql.NullInt64
 		err := rows.Scan(&msg.ID, &msg.SessionID, &msg.Role, &partsJSON,
 			&msg.TextContent, &durationMs, &msg.TurnIndex, &msg.CreatedAt, &msg.Sequence, &msg.CompactionTail,
-			&msg.ResponseID, &msg.AssistantSegmentOrdinal, &msg.SegmentStartSequence, &msg.SegmentEndSequence)
+			&msg.ResponseID, &msg.AssistantSegmentOrdinal, &msg.SegmentStartSequence, &msg.SegmentEndSequence, &msg.Pinned, &msg.TurnID,
+			&msg.InputTokens, &msg.OutputTokens, &msg.Model)
 		if err != nil {
 			return nil, fmt.Errorf("scan message: %w", err)
 		}
@@ -3623,7 +4173,8 @@ func (s *SQLiteStore) GetMessageByID(ctx context.Context, msgID int64) (*Message
 	var durationMs sql.NullInt64
 	err := row.Scan(&msg.ID, &msg.SessionID, &msg.Role, &partsJSON,
 		&msg.TextContent, &durationMs, &msg.TurnIndex, &msg.CreatedAt, &msg.Sequence, &msg.CompactionTail,
-		&msg.ResponseID, &msg.AssistantSegmentOrdinal, &msg.SegmentStartSequence, &msg.SegmentEndSequence)
+		&msg.ResponseID, &msg.AssistantSegmentOrdinal, &msg.SegmentStartSequence, &msg.SegmentEndSequence, &msg.Pinned, &msg.TurnID,
+		&msg.InputTokens, &msg.OutputTokens, &msg.Model)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, ErrNotFound
 	}
@@ -3872,6 +4423,15 @@ func (s *SQLiteStore) Close() error {
 	return s.db.Close()
 }
 
+// Ping checks that the database still answers queries.
+func (s *SQLiteStore) Ping(ctx context.Context) error {
+	var one int
+	if err := s.db.QueryRowContext(ctx, `SELECT 1`).Scan(&one); err != nil {
+		return fmt.Errorf("ping session database: %w", err)
+	}
+	return nil
+}
+
 // setCurrentColumns records optional columns that are guaranteed to exist after
 // read-write initialization/migration reaches the current schema.
 func (s *SQLiteStore) setCurrentColumns() {
@@ -3893,10 +4453,15 @@ func (s *SQLiteStore) setCurrentColumns() {
 	s.hasWorktreeDir = true
 	s.hasGoal = true
 	s.hasShare = true
+	s.hasAutoArchivedAt = true
 	s.hasTranscriptRev = true
+	s.hasParentKind = true
 	s.hasMessagesTable = true
 	s.hasMessageCompactionTail = true
 	s.hasMessageStreamIdentity = true
+	s.hasMessagePinned = true
+	s.hasMessageTurnID = true
+	s.hasMessageUsage = true
 }
 
 // probeSessionColumns checks optional session columns in a single PRAGMA scan.
@@ -3956,8 +4521,12 @@ func (s *SQLiteStore) probeSessionColumns() {
 			s.hasGoal = true
 		case "share":
 			s.hasShare = true
+		case "auto_archived_at":
+			s.hasAutoArchivedAt = true
 		case "transcript_rev":
 			s.hasTranscriptRev = true
+		case "parent_kind":
+			s.hasParentKind = true
 		}
 	}
 }
@@ -3984,6 +4553,12 @@ func (s *SQLiteStore) probeMessageColumns() {
 			s.hasMessageCompactionTail = true
 		case "response_id":
 			s.hasMessageStreamIdentity = true
+		case "pinned":
+			s.hasMessagePinned = true
+		case "turn_id":
+			s.hasMessageTurnID = true
+		case "input_tokens":
+			s.hasMessageUsage = true
 		}
 	}
 }
@@ -4058,6 +4633,11 @@ func (s *SQLiteStore) sessionSelectCols() string {
 	} else {
 		base += ", NULL AS share"
 	}
+	if s.hasParentKind {
+		base += ", parent_kind"
+	} else {
+		base += ", '' AS parent_kind"
+	}
 	if s.hasCompactionSeq {
 		base += ", compaction_seq"
 	}
@@ -4075,7 +4655,7 @@ func scanSessionRow(row *sql.Row, hasGeneratedTitles, hasCacheWriteTokens, hasCo
 	var name, summary, cwd, worktreeDir sql.NullString
 	var generatedShortTitle, generatedLongTitle, titleSource sql.NullString
 	var titleGeneratedAt, titleSkippedAt sql.NullTime
-	var mode, approvalMode, origin, agent, parentID, tools, mcp, status, tags, providerKey, reasoningEffort, reasoningMode, goalRaw, shareRaw sql.NullString
+	var mode, approvalMode, origin, agent, parentID, tools, mcp, status, tags, providerKey, reasoningEffort, reasoningMode, goalRaw, shareRaw, kind sql.NullString
 
 	var scanArgs []any
 	scanArgs = append(scanArgs, &sess.ID, &number, &name, &summary)
@@ -4102,7 +4682,7 @@ func scanSessionRow(row *sql.Row, hasGeneratedTitles, hasCacheWriteTokens, hasCo
 		scanArgs = append(scanArgs, &sess.LastMessageCount)
 	}
 	scanArgs = append(scanArgs, &sess.MessageCount)
-	scanArgs = append(scanArgs, &status, &tags, &goalRaw, &shareRaw)
+	scanArgs = append(scanArgs, &status, &tags, &goalRaw, &shareRaw, &kind)
 	if hasCompactionSeq {
 		scanArgs = append(scanArgs, &sess.CompactionSeq)
 	}
@@ -4180,6 +4760,9 @@ func scanSessionRow(row *sql.Row, hasGeneratedTitles, hasCacheWriteTokens, hasCo
 	if parentID.Valid {
 		sess.ParentID = parentID.String
 	}
+	if kind.Valid {
+		sess.Kind = SessionKind(kind.String)
+	}
 	if tools.Valid {
 		sess.Tools = tools.String
 	}
@@ -1550,6 +1550,43 @@ func TestSQLiteStorePersistsEventMessages(t *testing.T) {
 	}
 }
 
+func TestSQLiteStoreAddMessageStampsTurnIDFromContext(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	store, err := NewSQLiteStore(DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	sess := &Session{ID: NewID(), Provider: "test", Model: "test-model", Mode: ModeChat}
+	if err := store.Create(ctx, sess); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	user := NewMessage(sess.ID, llm.Message{Role: llm.RoleUser, Parts: []llm.Part{{Type: llm.PartText, Text: "hi"}}}, -1)
+	if err := store.AddMessage(ctx, sess.ID, user); err != nil {
+		t.Fatalf("AddMessage user: %v", err)
+	}
+	turnCtx := llm.ContextWithTurnID(ctx, "01J9XQ3T5B8N2K7M4C6D0E1F2G")
+	reply := NewMessage(sess.ID, llm.Message{Role: llm.RoleAssistant, Parts: []llm.Part{{Type: llm.PartText, Text: "hello"}}}, -1)
+	if err := store.AddMessage(turnCtx, sess.ID, reply); err != nil {
+		t.Fatalf("AddMessage assistant: %v", err)
+	}
+
+	msgs, err := store.GetMessages(ctx, sess.ID, 0, 0)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	if len(msgs) != 2 {
+		t.Fatalf("got %d messages, want 2", len(msgs))
+	}
+	if msgs[0].TurnID != "" || msgs[1].TurnID != "01J9XQ3T5B8N2K7M4C6D0E1F2G" {
+		t.Fatalf("turn IDs = %q, %q; want empty and the context turn", msgs[0].TurnID, msgs[1].TurnID)
+	}
+}
+
 func TestSQLiteStoreAddMessageBumpsLastMessageAt(t *testing.T) {
 	t.Setenv("XDG_DATA_HOME", t.TempDir())
 
@@ -2916,3 +2953,51 @@ func TestSQLiteStoreMigratesLegacyMessagesToResponseSegmentIdentity(t *testing.T
 		t.Fatalf("identified row after migration = %+v", got)
 	}
 }
+
+func TestSQLiteStoreSearchFiltersByRole(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	store, err := NewSQLiteStore(DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	userSess := &Session{ID: "user-session", Provider: "test", Model: "test-model", Mode: ModeChat}
+	assistantSess := &Session{ID: "assistant-session", Provider: "test", Model: "test-model", Mode: ModeChat}
+	for _, sess := range []*Session{userSess, assistantSess} {
+		if err := store.Create(ctx, sess); err != nil {
+			t.Fatalf("Create(%s): %v", sess.ID, err)
+		}
+	}
+	if err := store.AddMessage(ctx, userSess.ID, NewMessage(userSess.ID, llm.UserText("how do I tune the widget cache?"), 0)); err != nil {
+		t.Fatalf("AddMessage(user): %v", err)
+	}
+	if err := store.AddMessage(ctx, assistantSess.ID, NewMessage(assistantSess.ID, llm.AssistantText("the widget cache lives in config"), 0)); err != nil {
+		t.Fatalf("AddMessage(assistant): %v", err)
+	}
+
+	tests := []struct {
+		role llm.Role
+		want []string
+	}{
+		{role: "", want: []string{"assistant-session", "user-session"}},
+		{role: llm.RoleUser, want: []string{"user-session"}},
+		{role: llm.RoleAssistant, want: []string{"assistant-session"}},
+	}
+	for _, tt := range tests {
+		results, err := store.Search(ctx, SearchOptions{Query: `"widget" cache*`, Limit: 10, Role: tt.role})
+		if err != nil {
+			t.Fatalf("Search(role=%q) error = %v", tt.role, err)
+		}
+		var got []string
+		for _, r := range results {
+			got = append(got, r.SessionID)
+		}
+		sort.Strings(got)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Fatalf("Search(role=%q) sessions = %v, want %v", tt.role, got, tt.want)
+		}
+	}
+}
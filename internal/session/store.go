@@ -20,6 +20,9 @@ import (
 // exist (e.g., UpdateMessage against a deleted/never-persisted message ID).
 var ErrNotFound = errors.New("session: not found")
 
+// ErrForkUnsupported is returned when the store cannot fork sessions.
+var ErrForkUnsupported = errors.New("session: store does not support forking")
+
 // Store is the interface for session persistence.
 type Store interface {
 	// Session CRUD
@@ -53,6 +56,9 @@ type Store interface {
 
 	// Metrics operations (for incremental session saving)
 	UpdateMetrics(ctx context.Context, id string, llmTurns, toolCalls, inputTokens, outputTokens, cachedInputTokens, cacheWriteTokens int) error
+	// UpdateModelMetrics is UpdateMetrics with the token usage attributed to
+	// the given provider and model instead of the session's current ones.
+	UpdateModelMetrics(ctx context.Context, id string, um UsageModel, llmTurns, toolCalls, inputTokens, outputTokens, cachedInputTokens, cacheWriteTokens int) error
 	UpdateContextEstimate(ctx context.Context, id string, lastTotalTokens, lastMessageCount int) error
 	UpdateStatus(ctx context.Context, id string, status SessionStatus) error
 	IncrementUserTurns(ctx context.Context, id string) error
@@ -169,6 +175,73 @@ func UpdateStreamingMessage(ctx context.Context, store Store, sessionID string,
 	return store.UpdateMessage(ctx, sessionID, msg)
 }
 
+// MessagePinUpdater is an optional Store capability for flagging a persisted
+// message as pinned, so compaction replays it verbatim after a reload.
+type MessagePinUpdater interface {
+	SetMessagePinned(ctx context.Context, sessionID string, messageID int64, pinned bool) error
+}
+
+// ToolCallStatsStore is an optional Store capability for per-tool analytics
+// across sessions: how often each tool ran, how long it took and how often
+// it failed.
+type ToolCallStatsStore interface {
+	ToolCallStats(ctx context.Context, since time.Time) ([]ToolCallStats, error)
+}
+
+// UsageStatsStore is an optional Store capability for token usage reports
+// across sessions.
+type UsageStatsStore interface {
+	UsageStats(ctx context.Context, opts UsageStatsOptions) ([]UsageStatsRow, error)
+}
+
+// Pinger is an optional Store capability for health checks: Ping reports
+// whether the backing database is reachable.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Forker is an optional Store capability for branching a conversation. Fork
+// copies a session's messages up to and including atMessageSequence
+// (negative = all) into a new session of kind KindFork whose ParentID is
+// sessionID. Forks lists the forks of parentID, archived ones included,
+// oldest first; child runs that share the ParentID are left out.
+type Forker interface {
+	Fork(ctx context.Context, sessionID string, atMessageSequence int) (*Session, error)
+	Forks(ctx context.Context, parentID string) ([]SessionSummary, error)
+}
+
+// MessageTruncater is an optional Store capability for dropping the tail of a
+// session's history, e.g. the last response before it is regenerated.
+type MessageTruncater interface {
+	TruncateMessages(ctx context.Context, sessionID string, fromSequence int) error
+}
+
+// TruncateMessages deletes a session's messages at or after fromSequence using
+// the store's fast path when available, and falls back to Store.GetMessages +
+// Store.ReplaceMessages for custom stores.
+func TruncateMessages(ctx context.Context, store Store, sessionID string, fromSequence int) error {
+	if store == nil || strings.TrimSpace(sessionID) == "" {
+		return nil
+	}
+	if truncater, ok := store.(MessageTruncater); ok {
+		return truncater.TruncateMessages(ctx, sessionID, fromSequence)
+	}
+	messages, err := store.GetMessages(ctx, sessionID, 0, 0)
+	if err != nil {
+		return err
+	}
+	kept := messages[:0]
+	for _, msg := range messages {
+		if msg.Sequence < fromSequence {
+			kept = append(kept, msg)
+		}
+	}
+	if len(kept) == len(messages) {
+		return nil
+	}
+	return store.ReplaceMessages(ctx, sessionID, kept)
+}
+
 // PlanSnapshotStore is an optional Store capability for the authoritative latest
 // update_plan snapshot. Transcript tool-call/result parts remain the durable
 // replay record; this narrow store supports efficient resume restoration.
@@ -299,8 +372,10 @@ type PushSubscription struct {
 // Config holds session storage configuration.
 type Config struct {
 	Enabled          bool   `mapstructure:"enabled"`            // Master switch
-	MaxAgeDays       int    `mapstructure:"max_age_days"`       // Auto-delete after N days (0=never)
-	MaxCount         int    `mapstructure:"max_count"`          // Keep at most N sessions (0=unlimited)
+	MaxAgeDays       int    `mapstructure:"max_age_days"`       // Auto-archive after N days (0=never)
+	MaxCount         int    `mapstructure:"max_count"`          // Keep at most N unarchived sessions (0=unlimited)
+	PurgeGraceDays   int    `mapstructure:"purge_grace_days"`   // Days an auto-archived session is kept before deletion
+	HardDelete       bool   `mapstructure:"hard_delete"`        // Delete instead of auto-archiving (legacy behaviour)
 	Path             string `mapstructure:"path"`               // Optional DB path override (supports :memory:)
 	StripImageBase64 bool   `mapstructure:"strip_image_base64"` // Store path/metadata only for images with ImagePath (smaller DB, less portable)
 	ReadOnly         bool   `mapstructure:"-"`                  // Open DB in read-only mode (skip schema init/cleanup)
@@ -309,10 +384,11 @@ type Config struct {
 // DefaultConfig returns the default session configuration.
 func DefaultConfig() Config {
 	return Config{
-		Enabled:    true,
-		MaxAgeDays: 0, // Never auto-delete
-		MaxCount:   0, // Unlimited
-		Path:       "",
+		Enabled:        true,
+		MaxAgeDays:     0, // Never auto-archive
+		MaxCount:       0, // Unlimited
+		PurgeGraceDays: 14,
+		Path:           "",
 	}
 }
 
@@ -0,0 +1,151 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+	"unicode/utf8"
+)
+
+// maxToolErrorText bounds the error text kept per failed tool call; the full
+// result stays in the message parts.
+const maxToolErrorText = 500
+
+// ToolCallStats aggregates the completed calls of one tool.
+type ToolCallStats struct {
+	Tool          string  `json:"tool"`
+	Calls         int     `json:"calls"`
+	Failures      int     `json:"failures"`
+	FailureRate   float64 `json:"failure_rate"`
+	P50DurationMs int64   `json:"p50_duration_ms"`
+	P95DurationMs int64   `json:"p95_duration_ms"`
+}
+
+// recordToolCalls keeps the tool_calls table in step with a saved message.
+// Tool call parts record the tool and a hash of its arguments; tool result
+// parts complete the row with duration, outcome and error text. It runs in
+// the transaction that saves the message, so tool execution never waits on
+// a separate write.
+func recordToolCalls(ctx context.Context, execer sqliteExecer, sessionID string, messageID int64, msg *Message) error {
+	for _, part := range msg.Parts {
+		if call := part.ToolCall; call != nil && call.ID != "" {
+			if _, err := execer.ExecContext(ctx, `
+				INSERT INTO tool_calls (session_id, call_id, tool_name, args_hash)
+				VALUES (?, ?, ?, ?)
+				ON CONFLICT(session_id, call_id) DO UPDATE SET args_hash = excluded.args_hash`,
+				sessionID, call.ID, call.Name, hashToolArgs(call.Arguments)); err != nil {
+				return fmt.Errorf("record tool call: %w", err)
+			}
+		}
+		if result := part.ToolResult; result != nil && result.ID != "" {
+			errorText := ""
+			if result.IsError {
+				errorText = truncateToolErrorText(result.Content)
+			}
+			if _, err := execer.ExecContext(ctx, `
+				INSERT INTO tool_calls (session_id, call_id, message_id, tool_name, duration_ms, success, error_text, completed_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT(session_id, call_id) DO UPDATE SET
+				    message_id = excluded.message_id,
+				    duration_ms = excluded.duration_ms,
+				    success = excluded.success,
+				    error_text = excluded.error_text,
+				    completed_at = excluded.completed_at`,
+				sessionID, result.ID, messageID, result.Name, result.DurationMs, !result.IsError, errorText, msg.CreatedAt); err != nil {
+				return fmt.Errorf("record tool result: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// hashToolArgs identifies a tool call's arguments without storing them, so
+// repeated identical calls can be spotted.
+func hashToolArgs(args json.RawMessage) string {
+	if len(args) == 0 {
+		return ""
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, args); err == nil {
+		args = compact.Bytes()
+	}
+	sum := sha256.Sum256(args)
+	return hex.EncodeToString(sum[:8])
+}
+
+func truncateToolErrorText(text string) string {
+	if len(text) <= maxToolErrorText {
+		return text
+	}
+	cut := maxToolErrorText
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + "..."
+}
+
+// ToolCallStats returns per-tool counts, failure rates and p50/p95
+// durations for tool calls completed since the given time, busiest first.
+func (s *SQLiteStore) ToolCallStats(ctx context.Context, since time.Time) ([]ToolCallStats, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT tool_name, COALESCE(duration_ms, 0), success
+		FROM tool_calls
+		WHERE success IS NOT NULL AND completed_at >= ?`, since)
+	if err != nil {
+		return nil, fmt.Errorf("query tool calls: %w", err)
+	}
+	defer rows.Close()
+
+	durations := make(map[string][]int64)
+	failures := make(map[string]int)
+	for rows.Next() {
+		var name string
+		var duration int64
+		var success bool
+		if err := rows.Scan(&name, &duration, &success); err != nil {
+			return nil, fmt.Errorf("scan tool call: %w", err)
+		}
+		durations[name] = append(durations[name], duration)
+		if !success {
+			failures[name]++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tool calls: %w", err)
+	}
+
+	stats := make([]ToolCallStats, 0, len(durations))
+	for name, ds := range durations {
+		sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+		stats = append(stats, ToolCallStats{
+			Tool:          name,
+			Calls:         len(ds),
+			Failures:      failures[name],
+			FailureRate:   float64(failures[name]) / float64(len(ds)),
+			P50DurationMs: percentile(ds, 0.50),
+			P95DurationMs: percentile(ds, 0.95),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Calls != stats[j].Calls {
+			return stats[i].Calls > stats[j].Calls
+		}
+		return stats[i].Tool < stats[j].Tool
+	})
+	return stats, nil
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(sorted []int64, p float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(rank, 0)]
+}
@@ -0,0 +1,89 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+func TestSQLiteStoreToolCallStats(t *testing.T) {
+	store, err := NewSQLiteStore(Config{Enabled: true, Path: ":memory:"})
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	sess := &Session{ID: NewID(), Provider: "test", Model: "test-model", Mode: ModeChat}
+	if err := store.Create(ctx, sess); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	addCall := func(id, name string, durationMs int64, failed bool) {
+		t.Helper()
+		call := NewMessage(sess.ID, llm.Message{
+			Role:  llm.RoleAssistant,
+			Parts: []llm.Part{{Type: llm.PartToolCall, ToolCall: &llm.ToolCall{ID: id, Name: name, Arguments: json.RawMessage(`{"path": "a.go"}`)}}},
+		}, -1)
+		if err := store.AddMessage(ctx, sess.ID, call); err != nil {
+			t.Fatalf("AddMessage(call): %v", err)
+		}
+		content := "ok"
+		if failed {
+			content = "Error: " + strings.Repeat("x", 2*maxToolErrorText)
+		}
+		result := NewMessage(sess.ID, llm.Message{
+			Role:  llm.RoleTool,
+			Parts: []llm.Part{{Type: llm.PartToolResult, ToolResult: &llm.ToolResult{ID: id, Name: name, Content: content, IsError: failed, DurationMs: durationMs}}},
+		}, -1)
+		if err := store.AddMessage(ctx, sess.ID, result); err != nil {
+			t.Fatalf("AddMessage(result): %v", err)
+		}
+	}
+	for i := 1; i <= 20; i++ {
+		addCall(fmt.Sprintf("read-%d", i), "read_file", int64(i), false)
+	}
+	addCall("shell-1", "shell", 1000, false)
+	addCall("shell-2", "shell", 3000, true)
+
+	// A call still waiting on its result is not counted.
+	pending := NewMessage(sess.ID, llm.Message{
+		Role:  llm.RoleAssistant,
+		Parts: []llm.Part{{Type: llm.PartToolCall, ToolCall: &llm.ToolCall{ID: "pending", Name: "shell"}}},
+	}, -1)
+	if err := store.AddMessage(ctx, sess.ID, pending); err != nil {
+		t.Fatalf("AddMessage(pending): %v", err)
+	}
+
+	stats, err := store.ToolCallStats(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("ToolCallStats: %v", err)
+	}
+	want := []ToolCallStats{
+		{Tool: "read_file", Calls: 20, P50DurationMs: 10, P95DurationMs: 19},
+		{Tool: "shell", Calls: 2, Failures: 1, FailureRate: 0.5, P50DurationMs: 1000, P95DurationMs: 3000},
+	}
+	if fmt.Sprint(stats) != fmt.Sprint(want) {
+		t.Fatalf("stats = %+v, want %+v", stats, want)
+	}
+
+	var argsHash, errorText string
+	if err := store.db.QueryRow(`SELECT args_hash, error_text FROM tool_calls WHERE call_id = 'shell-2'`).Scan(&argsHash, &errorText); err != nil {
+		t.Fatalf("query tool call: %v", err)
+	}
+	if argsHash != hashToolArgs(json.RawMessage(`{"path":"a.go"}`)) {
+		t.Errorf("args_hash = %q, want the hash of the compacted arguments", argsHash)
+	}
+	if len(errorText) != maxToolErrorText+len("...") {
+		t.Errorf("error_text length = %d, want it truncated", len(errorText))
+	}
+
+	if stats, err := store.ToolCallStats(ctx, time.Now().Add(time.Hour)); err != nil || len(stats) != 0 {
+		t.Fatalf("stats for a future window = %+v, %v; want none", stats, err)
+	}
+}
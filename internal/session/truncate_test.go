@@ -0,0 +1,94 @@
+package session
+
+import (
+	"context"
+	"testing"
+
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+func TestSQLiteStoreTruncateMessagesDropsTail(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	store, err := NewSQLiteStore(DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	sess := &Session{ID: NewID(), Provider: "test", Model: "test-model", Mode: ModeChat}
+	if err := store.Create(ctx, sess); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	transcript := []llm.Message{
+		llm.UserText("first question"),
+		llm.AssistantText("first answer"),
+		llm.UserText("second question"),
+		llm.AssistantText("second answer"),
+	}
+	for i, msg := range transcript {
+		if err := store.AddMessage(ctx, sess.ID, NewMessage(sess.ID, msg, i)); err != nil {
+			t.Fatalf("AddMessage(%d): %v", i, err)
+		}
+	}
+
+	if err := TruncateMessages(ctx, store, sess.ID, 3); err != nil {
+		t.Fatalf("TruncateMessages: %v", err)
+	}
+	msgs, err := store.GetMessages(ctx, sess.ID, 0, 0)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	if len(msgs) != 3 || msgs[2].TextContent != "second question" {
+		t.Fatalf("messages = %+v, want the last answer dropped", msgs)
+	}
+
+	// Appending after a truncate reuses the freed sequence.
+	next := NewMessage(sess.ID, llm.AssistantText("regenerated answer"), -1)
+	if err := store.AddMessage(ctx, sess.ID, next); err != nil {
+		t.Fatalf("AddMessage(regenerated): %v", err)
+	}
+	if next.Sequence != 3 {
+		t.Fatalf("regenerated sequence = %d, want 3", next.Sequence)
+	}
+
+	if err := TruncateMessages(ctx, store, sess.ID, 2); err != nil {
+		t.Fatalf("TruncateMessages: %v", err)
+	}
+	got, err := store.Get(ctx, sess.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.UserTurns != 1 {
+		t.Fatalf("user_turns = %d, want 1 after dropping the second question", got.UserTurns)
+	}
+}
+
+func TestTruncateMessagesFallsBackToReplace(t *testing.T) {
+	store := &replaceOnlyStore{messages: []Message{
+		{Sequence: 0, TextContent: "question"},
+		{Sequence: 1, TextContent: "answer"},
+	}}
+	if err := TruncateMessages(context.Background(), store, "sess", 1); err != nil {
+		t.Fatalf("TruncateMessages: %v", err)
+	}
+	if len(store.replaced) != 1 || store.replaced[0].TextContent != "question" {
+		t.Fatalf("replaced = %+v, want only the question", store.replaced)
+	}
+}
+
+type replaceOnlyStore struct {
+	NoopStore
+	messages []Message
+	replaced []Message
+}
+
+func (s *replaceOnlyStore) GetMessages(context.Context, string, int, int) ([]Message, error) {
+	return append([]Message(nil), s.messages...), nil
+}
+
+func (s *replaceOnlyStore) ReplaceMessages(_ context.Context, _ string, messages []Message) error {
+	s.replaced = messages
+	return nil
+}
@@ -2,7 +2,9 @@ package session
 
 import (
 	"encoding/json"
+	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -45,6 +47,14 @@ const (
 	OriginTelegram SessionOrigin = "telegram"
 )
 
+// SessionKind says how a session with a ParentID relates to its parent.
+type SessionKind string
+
+const (
+	KindDefault SessionKind = ""     // Top-level session or a child run (subagent, delegated job)
+	KindFork    SessionKind = "fork" // Copy of the parent's transcript made by /fork or /edit
+)
+
 type SessionTitleSource string
 
 const (
@@ -83,6 +93,7 @@ type Session struct {
 	Archived        bool                `json:"archived,omitempty"`
 	Pinned          bool                `json:"pinned,omitempty"`
 	ParentID        string              `json:"parent_id,omitempty"`   // For session branching
+	Kind            SessionKind         `json:"kind,omitempty"`        // How this session relates to ParentID
 	IsSubagent      bool                `json:"is_subagent,omitempty"` // True if this is a subagent session
 
 	// Session settings (restored on resume unless overridden)
@@ -127,6 +138,11 @@ type Message struct {
 	AssistantSegmentOrdinal int        `json:"assistant_segment_ordinal"` // Response-scoped; -1 when the row is not an assistant segment.
 	SegmentStartSequence    int64      `json:"segment_start_sequence,omitempty"`
 	SegmentEndSequence      int64      `json:"segment_end_sequence,omitempty"`
+	Pinned                  bool       `json:"pinned,omitempty"`        // User-pinned: compaction replays it verbatim
+	TurnID                  string     `json:"turn_id,omitempty"`       // Engine turn that produced the row; matches debug-log turn_id
+	InputTokens             int        `json:"input_tokens,omitempty"`  // Assistant rows: input tokens of the LLM call, cached included
+	OutputTokens            int        `json:"output_tokens,omitempty"` // Assistant rows: output tokens of the LLM call
+	Model                   string     `json:"model,omitempty"`         // Assistant rows: model that produced the row
 }
 
 // SessionSummary is a lightweight view of a session for listing.
@@ -159,6 +175,10 @@ type SessionSummary struct {
 	WorktreeDir         string             `json:"worktree_dir,omitempty"`
 	Goal                *Goal              `json:"goal,omitempty"`
 	Share               *ShareState        `json:"share,omitempty"`
+	ParentID            string             `json:"parent_id,omitempty"`     // Session this one was forked from or runs under
+	ParentNumber        int64              `json:"parent_number,omitempty"` // Sequential number of the parent session
+	Kind                SessionKind        `json:"kind,omitempty"`          // How this session relates to ParentID
+	PurgeAfter          time.Time          `json:"purge_after,omitempty"`   // When an auto-archived session will be deleted
 	CreatedAt           time.Time          `json:"created_at"`
 	UpdatedAt           time.Time          `json:"updated_at"`
 	LastMessageAt       time.Time          `json:"last_message_at,omitempty"`
@@ -172,12 +192,15 @@ type ListOptions struct {
 	Mode             SessionMode   // Filter by mode (chat, ask, plan, exec)
 	Status           SessionStatus // Filter by status
 	Tag              string        // Filter by tag (substring match)
+	ParentID         string        // Filter by parent session
+	Kind             SessionKind   // Filter by kind relative to the parent (empty = any)
 	Categories       []string      // Sidebar/web categories (all, chat, web, ask, plan, exec)
 	Limit            int           // Max results (0 = use default)
 	Offset           int           // Pagination offset
 	BeforeNumber     int64         // Keyset cursor: only sessions with number < this value
 	SortByNumberDesc bool          // Order by session number descending instead of activity sort
 	Archived         bool          // Include archived sessions
+	ArchivedOnly     bool          // Only archived sessions
 	SortByActivity   bool          // Sort by last_message_at (web sidebar); defaults to last_user_message_at
 }
 
@@ -187,6 +210,7 @@ type SearchOptions struct {
 	Categories []string // Sidebar/web categories (all, chat, web, ask, plan, exec)
 	Limit      int      // Max results (0 = use default)
 	Archived   bool     // Include archived sessions
+	Role       llm.Role // Only match messages with this role (empty = any)
 }
 
 // SearchResult represents a search match.
@@ -227,6 +251,7 @@ func NewMessage(sessionID string, msg llm.Message, sequence int) *Message {
 		AssistantSegmentOrdinal: msg.AssistantSegmentOrdinal,
 		SegmentStartSequence:    msg.SegmentStartSequence,
 		SegmentEndSequence:      msg.SegmentEndSequence,
+		Pinned:                  msg.Pinned,
 	}
 	if msg.Role != llm.RoleAssistant && msg.AssistantSegmentOrdinal == 0 {
 		m.AssistantSegmentOrdinal = -1
@@ -249,6 +274,47 @@ func (m *Message) ExtractTextContent() string {
 	return text
 }
 
+// StatsLine summarizes an assistant message's timing and usage, e.g.
+// "4.2s · 1,832 in / 412 out · gpt-5.2-codex · 3 tool calls". Fields that
+// were not recorded are left out; it returns "" when none were.
+func (m *Message) StatsLine() string {
+	if m == nil || m.Role != llm.RoleAssistant {
+		return ""
+	}
+	var fields []string
+	if m.DurationMs > 0 {
+		fields = append(fields, fmt.Sprintf("%.1fs", float64(m.DurationMs)/1000))
+	}
+	if m.InputTokens > 0 || m.OutputTokens > 0 {
+		fields = append(fields, fmt.Sprintf("%s in / %s out", formatThousands(m.InputTokens), formatThousands(m.OutputTokens)))
+	}
+	if m.Model != "" {
+		fields = append(fields, m.Model)
+	}
+	toolCalls := 0
+	for _, part := range m.Parts {
+		if part.Type == llm.PartToolCall && part.ToolCall != nil {
+			toolCalls++
+		}
+	}
+	switch {
+	case toolCalls == 1:
+		fields = append(fields, "1 tool call")
+	case toolCalls > 1:
+		fields = append(fields, fmt.Sprintf("%d tool calls", toolCalls))
+	}
+	return strings.Join(fields, " · ")
+}
+
+// formatThousands formats a non-negative n with comma thousands separators.
+func formatThousands(n int) string {
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
 // ToLLMMessage converts a Message back to an llm.Message.
 func (m *Message) ToLLMMessage() llm.Message {
 	if m == nil {
@@ -261,6 +327,7 @@ func (m *Message) ToLLMMessage() llm.Message {
 		AssistantSegmentOrdinal: m.AssistantSegmentOrdinal,
 		SegmentStartSequence:    m.SegmentStartSequence,
 		SegmentEndSequence:      m.SegmentEndSequence,
+		Pinned:                  m.Pinned,
 	}
 	if m.isInternalCompactionSummary() {
 		msg.CacheAnchor = true
@@ -348,3 +348,89 @@ func TestSQLiteStoreUpdateMessageConcurrent(t *testing.T) {
 		t.Errorf("final text = %q, want %q", msgs[0].Parts[0].Text, "concurrent")
 	}
 }
+
+func TestSQLiteStoreSetMessagePinnedSurvivesCompaction(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	store, err := NewSQLiteStore(DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	sess := &Session{ID: NewID(), Provider: "test", Model: "test-model", Mode: ModeChat}
+	if err := store.Create(ctx, sess); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	msg := NewMessage(sess.ID, llm.UserText("schema: widgets(id, name)"), -1)
+	if err := store.AddMessage(ctx, sess.ID, msg); err != nil {
+		t.Fatalf("AddMessage: %v", err)
+	}
+
+	if err := store.SetMessagePinned(ctx, sess.ID, msg.ID, true); err != nil {
+		t.Fatalf("SetMessagePinned: %v", err)
+	}
+	msgs, err := store.GetMessages(ctx, sess.ID, 0, 0)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	if len(msgs) != 1 || !msgs[0].Pinned || !msgs[0].ToLLMMessage().Pinned {
+		t.Fatalf("expected pinned message after reload, got %#v", msgs)
+	}
+
+	// Compaction rows carry the flag so pins survive the next compaction too.
+	if err := store.CompactMessages(ctx, sess.ID, []Message{*NewMessage(sess.ID, msgs[0].ToLLMMessage(), -1)}); err != nil {
+		t.Fatalf("CompactMessages: %v", err)
+	}
+	msgs, err = store.GetMessages(ctx, sess.ID, 0, 0)
+	if err != nil {
+		t.Fatalf("GetMessages after compaction: %v", err)
+	}
+	if last := msgs[len(msgs)-1]; !last.Pinned {
+		t.Fatalf("compacted copy lost pinned flag: %#v", last)
+	}
+
+	if err := store.SetMessagePinned(ctx, sess.ID, msg.ID, false); err != nil {
+		t.Fatalf("unpin: %v", err)
+	}
+	if err := store.SetMessagePinned(ctx, sess.ID, 999999, true); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("pin missing message err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestLoggingStoreForwardsSetMessagePinned(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	store, err := NewSQLiteStore(DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	sess := &Session{ID: NewID(), Provider: "test", Model: "test-model", Mode: ModeChat}
+	if err := store.Create(ctx, sess); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	msg := NewMessage(sess.ID, llm.UserText("pin me"), -1)
+	if err := store.AddMessage(ctx, sess.ID, msg); err != nil {
+		t.Fatalf("AddMessage: %v", err)
+	}
+
+	var logged Store = NewLoggingStore(store, nil)
+	updater, ok := logged.(MessagePinUpdater)
+	if !ok {
+		t.Fatal("LoggingStore should implement MessagePinUpdater")
+	}
+	if err := updater.SetMessagePinned(ctx, sess.ID, msg.ID, true); err != nil {
+		t.Fatalf("SetMessagePinned: %v", err)
+	}
+	got, err := store.GetMessageByID(ctx, msg.ID)
+	if err != nil {
+		t.Fatalf("GetMessageByID: %v", err)
+	}
+	if !got.Pinned {
+		t.Fatal("pin was not persisted through LoggingStore")
+	}
+}
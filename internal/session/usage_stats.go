@@ -0,0 +1,89 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// UsageStatsOptions filters UsageStats.
+type UsageStatsOptions struct {
+	Since    time.Time
+	Provider string // Provider key; empty for all
+}
+
+// UsageStatsRow is the token usage of one provider and model on one day.
+type UsageStatsRow struct {
+	Day               string `json:"day"` // Local date, YYYY-MM-DD
+	Provider          string `json:"provider"`
+	Model             string `json:"model"`
+	Turns             int    `json:"turns"`
+	InputTokens       int    `json:"input_tokens"`
+	OutputTokens      int    `json:"output_tokens"`
+	CachedInputTokens int    `json:"cached_input_tokens"`
+	CacheWriteTokens  int    `json:"cache_write_tokens"`
+}
+
+// UsageModel names the provider and model that produced the tokens passed to
+// UpdateModelMetrics. Usage stats are attributed to them rather than to the
+// session's current provider and model, which may have changed since the
+// request started (for example after /model) or may never have served it
+// (guardian and compaction calls). Empty fields fall back to the session's.
+type UsageModel struct {
+	Provider string
+	Model    string
+}
+
+// recordMessageUsage stores one turn's token usage, linked to the latest
+// assistant message and attributed to um.
+func recordMessageUsage(ctx context.Context, execer sqliteExecer, sessionID string, um UsageModel, inputTokens, outputTokens, cachedInputTokens, cacheWriteTokens int, now time.Time) error {
+	if _, err := execer.ExecContext(ctx, `
+		INSERT INTO message_usage (session_id, message_id, provider, model, input_tokens, output_tokens, cached_input_tokens, cache_write_tokens, day, created_at)
+		SELECT s.id,
+		       (SELECT MAX(m.id) FROM messages m WHERE m.session_id = s.id AND m.role = 'assistant'),
+		       COALESCE(NULLIF(?, ''), NULLIF(s.provider_key, ''), s.provider), COALESCE(NULLIF(?, ''), s.model), ?, ?, ?, ?, ?, ?
+		FROM sessions s WHERE s.id = ?`,
+		strings.TrimSpace(um.Provider), strings.TrimSpace(um.Model), inputTokens, outputTokens, cachedInputTokens, cacheWriteTokens, now.Format(time.DateOnly), now, sessionID); err != nil {
+		return fmt.Errorf("record message usage: %w", err)
+	}
+	return nil
+}
+
+// UsageStats returns token usage since opts.Since grouped by day, provider
+// and model, oldest day first.
+func (s *SQLiteStore) UsageStats(ctx context.Context, opts UsageStatsOptions) ([]UsageStatsRow, error) {
+	query := `
+		SELECT day, provider, model, COUNT(*),
+		       SUM(input_tokens), SUM(output_tokens), SUM(cached_input_tokens), SUM(cache_write_tokens)
+		FROM message_usage
+		WHERE created_at >= ?`
+	args := []any{opts.Since}
+	if opts.Provider != "" {
+		query += ` AND provider = ?`
+		args = append(args, opts.Provider)
+	}
+	query += `
+		GROUP BY day, provider, model
+		ORDER BY day, provider, model`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query usage: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []UsageStatsRow{}
+	for rows.Next() {
+		var row UsageStatsRow
+		if err := rows.Scan(&row.Day, &row.Provider, &row.Model, &row.Turns,
+			&row.InputTokens, &row.OutputTokens, &row.CachedInputTokens, &row.CacheWriteTokens); err != nil {
+			return nil, fmt.Errorf("scan usage: %w", err)
+		}
+		stats = append(stats, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate usage: %w", err)
+	}
+	return stats, nil
+}
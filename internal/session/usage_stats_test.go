@@ -0,0 +1,133 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+func TestSQLiteStoreUsageStats(t *testing.T) {
+	store, err := NewSQLiteStore(Config{Enabled: true, Path: ":memory:"})
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	claude := &Session{ID: NewID(), Provider: "Anthropic (claude-sonnet-4-6)", ProviderKey: "anthropic", Model: "claude-sonnet-4-6", Mode: ModeChat}
+	gpt := &Session{ID: NewID(), Provider: "OpenAI (gpt-5.6-luna)", ProviderKey: "openai", Model: "gpt-5.6-luna", Mode: ModeChat}
+	for _, sess := range []*Session{claude, gpt} {
+		if err := store.Create(ctx, sess); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	reply := NewMessage(claude.ID, llm.AssistantText("hi"), -1)
+	if err := store.AddMessage(ctx, claude.ID, reply); err != nil {
+		t.Fatalf("AddMessage: %v", err)
+	}
+
+	for _, turn := range []struct{ input, output, cached int }{{100, 10, 1000}, {200, 20, 0}} {
+		if err := store.UpdateMetrics(ctx, claude.ID, 1, 0, turn.input, turn.output, turn.cached, 0); err != nil {
+			t.Fatalf("UpdateMetrics: %v", err)
+		}
+	}
+	if err := store.UpdateMetrics(ctx, gpt.ID, 1, 0, 50, 5, 0, 0); err != nil {
+		t.Fatalf("UpdateMetrics: %v", err)
+	}
+	// A turn without token usage records nothing.
+	if err := store.UpdateMetrics(ctx, gpt.ID, 1, 2, 0, 0, 0, 0); err != nil {
+		t.Fatalf("UpdateMetrics: %v", err)
+	}
+	// An older turn lands on its own day and drops out of a shorter window.
+	old := time.Now().AddDate(0, 0, -3)
+	if err := recordMessageUsage(ctx, store.db, gpt.ID, UsageModel{}, 7, 3, 0, 0, old); err != nil {
+		t.Fatalf("recordMessageUsage: %v", err)
+	}
+
+	today := time.Now().Format(time.DateOnly)
+	rows, err := store.UsageStats(ctx, UsageStatsOptions{Since: time.Now().AddDate(0, 0, -7)})
+	if err != nil {
+		t.Fatalf("UsageStats: %v", err)
+	}
+	want := []UsageStatsRow{
+		{Day: old.Format(time.DateOnly), Provider: "openai", Model: "gpt-5.6-luna", Turns: 1, InputTokens: 7, OutputTokens: 3},
+		{Day: today, Provider: "anthropic", Model: "claude-sonnet-4-6", Turns: 2, InputTokens: 300, OutputTokens: 30, CachedInputTokens: 1000},
+		{Day: today, Provider: "openai", Model: "gpt-5.6-luna", Turns: 1, InputTokens: 50, OutputTokens: 5},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("rows = %+v, want %+v", rows, want)
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, rows[i], want[i])
+		}
+	}
+
+	rows, err = store.UsageStats(ctx, UsageStatsOptions{Since: time.Now().AddDate(0, 0, -1), Provider: "openai"})
+	if err != nil {
+		t.Fatalf("UsageStats(provider): %v", err)
+	}
+	if len(rows) != 1 || rows[0] != want[2] {
+		t.Fatalf("openai rows for the last day = %+v, want %+v", rows, want[2:])
+	}
+
+	var linked int
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM message_usage WHERE message_id = ?`, reply.ID).Scan(&linked); err != nil {
+		t.Fatal(err)
+	}
+	if linked != 2 {
+		t.Fatalf("usage rows linked to the assistant message = %d, want 2", linked)
+	}
+}
+
+func TestSQLiteStoreUsageStatsAttributesTokensToTheModelUsed(t *testing.T) {
+	store, err := NewSQLiteStore(Config{Enabled: true, Path: ":memory:"})
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	sess := &Session{ID: NewID(), Provider: "Anthropic (claude-sonnet-4-6)", ProviderKey: "anthropic", Model: "claude-sonnet-4-6", Mode: ModeChat}
+	if err := store.Create(ctx, sess); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// A turn that started on Claude finishes after /model moved the session
+	// to GPT; a later turn runs on GPT, and a compaction call uses a cheaper
+	// model from the session's provider.
+	startedOnClaude := UsageModel{Provider: "anthropic", Model: "claude-sonnet-4-6"}
+	sess.Provider, sess.ProviderKey, sess.Model = "OpenAI (gpt-5.6-luna)", "openai", "gpt-5.6-luna"
+	if err := store.Update(ctx, sess); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := store.UpdateModelMetrics(ctx, sess.ID, startedOnClaude, 1, 0, 100, 10, 0, 0); err != nil {
+		t.Fatalf("UpdateModelMetrics: %v", err)
+	}
+	if err := store.UpdateMetrics(ctx, sess.ID, 1, 0, 50, 5, 0, 0); err != nil {
+		t.Fatalf("UpdateMetrics: %v", err)
+	}
+	if err := store.UpdateModelMetrics(ctx, sess.ID, UsageModel{Model: "gpt-5.6-mini"}, 0, 0, 20, 2, 0, 0); err != nil {
+		t.Fatalf("UpdateModelMetrics: %v", err)
+	}
+
+	rows, err := store.UsageStats(ctx, UsageStatsOptions{Since: time.Now().AddDate(0, 0, -1)})
+	if err != nil {
+		t.Fatalf("UsageStats: %v", err)
+	}
+	got := map[string]int{}
+	for _, row := range rows {
+		got[row.Provider+":"+row.Model] += row.InputTokens
+	}
+	want := map[string]int{"anthropic:claude-sonnet-4-6": 100, "openai:gpt-5.6-luna": 50, "openai:gpt-5.6-mini": 20}
+	if len(got) != len(want) {
+		t.Fatalf("usage by model = %v, want %v", got, want)
+	}
+	for key, tokens := range want {
+		if got[key] != tokens {
+			t.Errorf("%s input tokens = %d, want %d", key, got[key], tokens)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 )
 
@@ -22,3 +23,26 @@ func NotifyContextWithParent(parent context.Context) (context.Context, context.C
 	}
 	return signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
 }
+
+// OnNext calls fn, in its own goroutine, when the next SIGINT or SIGTERM
+// arrives. Use it once a NotifyContext has fired so a repeated signal can cut
+// a graceful shutdown short. The returned stop function cancels the watch.
+func OnNext(fn func()) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ch:
+			fn()
+		case <-done:
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+
+	"github.com/samsaffron/term-llm/internal/pathutil"
 )
 
 // Registry manages skill discovery and resolution.
@@ -119,10 +121,7 @@ func (r *Registry) buildSearchPaths() error {
 	}
 
 	home, _ := os.UserHomeDir()
-	configDir := os.Getenv("XDG_CONFIG_HOME")
-	if configDir == "" && home != "" {
-		configDir = filepath.Join(home, ".config")
-	}
+	configDir, _ := pathutil.ConfigDir()
 
 	// 1. Project-local paths (if enabled)
 	if r.config.IncludeProjectSkills {
@@ -213,7 +212,7 @@ func (r *Registry) addUserPaths(home, configDir string) {
 	// term-llm user skills (highest user-scope precedence)
 	if configDir != "" {
 		r.searchPaths = append(r.searchPaths, searchPath{
-			path:   filepath.Join(configDir, "term-llm", "skills"),
+			path:   filepath.Join(configDir, "skills"),
 			source: SourceUser,
 		})
 	}
@@ -831,17 +830,12 @@ func (r *Registry) IsAlwaysEnabled(name string) bool {
 
 // GetUserSkillsDir returns the path for user-global skills.
 func GetUserSkillsDir() (string, error) {
-	home, err := os.UserHomeDir()
+	configDir, err := pathutil.ConfigDir()
 	if err != nil {
 		return "", err
 	}
 
-	configDir := os.Getenv("XDG_CONFIG_HOME")
-	if configDir == "" {
-		configDir = filepath.Join(home, ".config")
-	}
-
-	return filepath.Join(configDir, "term-llm", "skills"), nil
+	return filepath.Join(configDir, "skills"), nil
 }
 
 // GetLocalSkillsDir returns the path for project-local skills.
@@ -1,32 +1,14 @@
 package testutil
 
 import (
-	"errors"
 	"os"
 	"path/filepath"
-	"runtime"
 	"strconv"
 	"strings"
-	"syscall"
 	"testing"
 	"time"
 )
 
-// ProcessHasExited reports whether pid no longer has executable code. On Linux,
-// zombies count as exited because kill(pid, 0) continues to report them until
-// their parent or init process reaps them.
-func ProcessHasExited(pid int) bool {
-	err := syscall.Kill(pid, 0)
-	if err != nil {
-		return errors.Is(err, syscall.ESRCH)
-	}
-	if runtime.GOOS != "linux" {
-		return false
-	}
-	state, ok := linuxProcessState(pid)
-	return ok && state == 'Z'
-}
-
 // WaitForProcessExit waits until pid is gone or is a zombie.
 func WaitForProcessExit(t testing.TB, pid int, timeout time.Duration) {
 	t.Helper()
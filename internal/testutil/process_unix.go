@@ -0,0 +1,24 @@
+//go:build !windows
+
+package testutil
+
+import (
+	"errors"
+	"runtime"
+	"syscall"
+)
+
+// ProcessHasExited reports whether pid no longer has executable code. On Linux,
+// zombies count as exited because kill(pid, 0) continues to report them until
+// their parent or init process reaps them.
+func ProcessHasExited(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	if err != nil {
+		return errors.Is(err, syscall.ESRCH)
+	}
+	if runtime.GOOS != "linux" {
+		return false
+	}
+	state, ok := linuxProcessState(pid)
+	return ok && state == 'Z'
+}
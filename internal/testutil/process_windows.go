@@ -0,0 +1,22 @@
+//go:build windows
+
+package testutil
+
+import "golang.org/x/sys/windows"
+
+// stillActive is the exit code Windows reports for a running process.
+const stillActive = 259
+
+// ProcessHasExited reports whether pid has exited.
+func ProcessHasExited(pid int) bool {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return true
+	}
+	defer windows.CloseHandle(h)
+	var code uint32
+	if err := windows.GetExitCodeProcess(h, &code); err != nil {
+		return false
+	}
+	return code != stillActive
+}
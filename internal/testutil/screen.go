@@ -197,7 +197,7 @@ func (s *ScreenCapture) Dump() string {
 }
 
 // ansiRegex matches ANSI escape sequences.
-var ansiRegex = regexp.MustCompile(`\x1b\[[0-9;]*[a-zA-Z]|\x1b\][^\x07]*\x07`)
+var ansiRegex = regexp.MustCompile(`\x1b\[[0-9;]*[a-zA-Z]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)`)
 
 // StripANSI removes ANSI escape sequences from a string.
 func StripANSI(s string) string {
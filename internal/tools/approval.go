@@ -8,11 +8,13 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
 
 	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/pathutil"
 )
 
 // ApprovalCache provides session-scoped caching for tool+path decisions.
@@ -130,7 +132,7 @@ func (c *DirCache) IsPathInApprovedDir(path string, isWrite bool) bool {
 func matchApprovedPath(absPath string, dirs map[string]ConfirmOutcome) bool {
 	for dir, outcome := range dirs {
 		if outcome == ProceedAlways || outcome == ProceedAlwaysAndSave {
-			if strings.HasPrefix(absPath, dir+string(filepath.Separator)) || absPath == dir {
+			if pathutil.IsWithin(absPath, dir) {
 				return true
 			}
 		}
@@ -237,6 +239,13 @@ func (c *ShellApprovalCache) IsCommandApproved(command, workDir string) bool {
 	return false
 }
 
+// RemovePattern drops a glob pattern from the session cache.
+func (c *ShellApprovalCache) RemovePattern(pattern string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.patterns = slices.DeleteFunc(c.patterns, func(p string) bool { return p == pattern })
+}
+
 // GetPatterns returns all session-approved patterns.
 func (c *ShellApprovalCache) GetPatterns() []string {
 	c.mu.RLock()
@@ -357,6 +366,9 @@ type ApprovalManager struct {
 	toolAllowMu  sync.RWMutex
 	toolReadDirs map[string][]string // per-tool read allowlist, e.g. routed view_image uploads
 
+	domainMu sync.RWMutex
+	domains  map[string]struct{} // web_fetch domains approved for the session
+
 	// promptMu serializes interactive approval prompts.
 	// When tools execute in parallel, multiple may need approval simultaneously.
 	// This mutex ensures only one prompt is shown at a time to avoid UI conflicts.
@@ -378,6 +390,11 @@ type ApprovalManager struct {
 	// Used in serve mode so the web UI user is always prompted.
 	IgnoreProjectApprovals bool
 
+	// Always-allowed shell command prefixes, loaded on first use. Sub-agents
+	// share their root manager's rules.
+	shellPrefixOnce  sync.Once
+	shellPrefixRules *ShellPrefixRules
+
 	// DebugApproval when true, logs approval decision details to stderr.
 	DebugApproval bool
 
@@ -391,6 +408,11 @@ type ApprovalManager struct {
 	// If nil, falls back to PromptFunc.
 	PromptUIFunc func(path string, isWrite bool, isShell bool, workDir string) (ApprovalResult, error)
 
+	// PromptRequestFunc is PromptUIFunc with the full request: tool name,
+	// argument preview and, for edits, a diff of the proposed change.
+	// When set it takes precedence over PromptUIFunc at the same level.
+	PromptRequestFunc func(req ApprovalPrompt) (ApprovalResult, error)
+
 	// GuardianEventFunc receives structured audit events for auto approvals/denials.
 	GuardianEventFunc func(event GuardianEvent)
 
@@ -399,6 +421,10 @@ type ApprovalManager struct {
 	// PromptUIFunc if local is nil. This enables sub-agents to inherit
 	// the parent session's approvals and prompting capability.
 	parent *ApprovalManager
+
+	// pauseTimeouts stops the owning engine's tool deadlines while a prompt
+	// waits on the user. Set by ToolManager.SetupEngine.
+	pauseTimeouts func() (resume func())
 }
 
 // NewApprovalManager creates a new ApprovalManager.
@@ -411,6 +437,7 @@ func NewApprovalManager(perms *ToolPermissions) *ApprovalManager {
 		permissions:                 perms,
 		projectCache:                make(map[string]*ProjectApprovals),
 		toolReadDirs:                make(map[string][]string),
+		domains:                     make(map[string]struct{}),
 		guardianCircuitBreakerLimit: 3,
 	}
 }
@@ -452,7 +479,7 @@ func (m *ApprovalManager) isPathAllowedForToolRead(toolName, path string) bool {
 	dirs := append([]string(nil), m.toolReadDirs[toolName]...)
 	m.toolAllowMu.RUnlock()
 	for _, dir := range dirs {
-		if strings.HasPrefix(resolved, dir+string(filepath.Separator)) || resolved == dir {
+		if pathutil.IsWithin(resolved, dir) {
 			return true
 		}
 	}
@@ -462,6 +489,60 @@ func (m *ApprovalManager) isPathAllowedForToolRead(toolName, path string) bool {
 	return false
 }
 
+// ShellPrefixRules returns the user's always-allowed shell command prefixes.
+func (m *ApprovalManager) ShellPrefixRules() *ShellPrefixRules {
+	root := m.root()
+	if root == nil {
+		return nil
+	}
+	root.shellPrefixOnce.Do(func() {
+		rules, err := LoadShellPrefixRules()
+		if err != nil {
+			log.Printf("[approval] failed to load allowed command prefixes: %v", err)
+			rules = &ShellPrefixRules{Prefixes: []string{}}
+		}
+		root.shellPrefixRules = rules
+	})
+	return root.shellPrefixRules
+}
+
+// SetToolTimeoutPauser sets the hook that pauses the owning engine's tool
+// deadlines while a prompt waits on the user.
+func (m *ApprovalManager) SetToolTimeoutPauser(pause func() (resume func())) {
+	if m == nil {
+		return
+	}
+	m.pauseTimeouts = pause
+}
+
+// pauseToolTimeouts pauses the tool deadlines of this manager's engine and
+// of every parent's, since a sub-agent's prompt also blocks the parent tool
+// that spawned it.
+func (m *ApprovalManager) pauseToolTimeouts() (resume func()) {
+	var resumes []func()
+	for p := m; p != nil; p = p.parent {
+		if p.pauseTimeouts != nil {
+			resumes = append(resumes, p.pauseTimeouts())
+		}
+	}
+	return func() {
+		for _, resume := range resumes {
+			resume()
+		}
+	}
+}
+
+// RemoveShellPrefix stops always allowing prefix, both in the saved rules and
+// in this session.
+func (m *ApprovalManager) RemoveShellPrefix(prefix string) error {
+	prefix = strings.Join(strings.Fields(prefix), " ")
+	if err := m.ShellPrefixRules().Remove(prefix); err != nil {
+		return err
+	}
+	m.root().shellCache.RemovePattern(prefix + " *")
+	return nil
+}
+
 // SetParent sets the parent ApprovalManager for inheritance.
 // When set, this manager will check parent's session caches (dirCache, shellCache)
 // and use parent's PromptUIFunc if local is nil.
@@ -504,10 +585,18 @@ func (m *ApprovalManager) root() *ApprovalManager {
 	return m
 }
 
-func (m *ApprovalManager) lookupPromptUIFunc() func(path string, isWrite bool, isShell bool, workDir string) (ApprovalResult, error) {
+// lookupPromptUIFunc returns the nearest prompt callback (local, then
+// ancestors), adapting a legacy PromptUIFunc to the request form.
+func (m *ApprovalManager) lookupPromptUIFunc() func(req ApprovalPrompt) (ApprovalResult, error) {
 	for cur := m; cur != nil; cur = cur.parent {
+		if cur.PromptRequestFunc != nil {
+			return cur.PromptRequestFunc
+		}
 		if cur.PromptUIFunc != nil {
-			return cur.PromptUIFunc
+			legacy := cur.PromptUIFunc
+			return func(req ApprovalPrompt) (ApprovalResult, error) {
+				return legacy(req.Path, req.IsWrite, req.IsShell, req.WorkDir)
+			}
 		}
 	}
 	return nil
@@ -876,6 +965,9 @@ func (m *ApprovalManager) checkShellApprovalNoPrompt(command, workDir string) (C
 	// Check project-level approvals (persisted) — use the command's
 	// working directory so approvals attach to the correct repo.
 	if !m.IgnoreProjectApprovals {
+		if m.ShellPrefixRules().Allows(command) {
+			return ProceedAlways, true
+		}
 		dir := workDir
 		if dir == "" {
 			dir, _ = os.Getwd()
@@ -894,6 +986,12 @@ func (m *ApprovalManager) checkShellApprovalNoPrompt(command, workDir string) (C
 // for one tool allows all tools to access files within it.
 // toolInfo is optional context for display (e.g., filename being accessed).
 func (m *ApprovalManager) CheckPathApproval(toolName, path, toolInfo string, isWrite bool) (ConfirmOutcome, error) {
+	return m.CheckPathApprovalWithDetails(toolName, path, toolInfo, isWrite, ApprovalDetails{})
+}
+
+// CheckPathApprovalWithDetails is CheckPathApproval with extra context for
+// the prompt, such as the diff an edit would apply.
+func (m *ApprovalManager) CheckPathApprovalWithDetails(toolName, path, toolInfo string, isWrite bool, details ApprovalDetails) (ConfirmOutcome, error) {
 	// 0. Yolo mode - auto-approve everything
 	if m.YoloEnabled() {
 		if m.DebugApproval {
@@ -933,6 +1031,9 @@ func (m *ApprovalManager) CheckPathApproval(toolName, path, toolInfo string, isW
 
 	// 4. Need to prompt user - serialize prompts to avoid UI conflicts
 	// Use shared lock (via PromptLock()) to prevent concurrent prompts across parent/child managers
+	// Tool timeouts don't run while the user decides.
+	resumeTimeouts := m.pauseToolTimeouts()
+	defer resumeTimeouts()
 	promptLock := m.PromptLock()
 	promptLock.Lock()
 	defer promptLock.Unlock()
@@ -972,7 +1073,20 @@ func (m *ApprovalManager) CheckPathApproval(toolName, path, toolInfo string, isW
 		if m.DebugApproval {
 			log.Printf("[approval] CheckPathApproval tool=%s path=%q → calling PromptUIFunc", toolName, absPath)
 		}
-		result, err := promptUIFunc(absPath, isWrite, false, "")
+		args := details.Args
+		if args == "" {
+			args = toolInfo
+		}
+		prompt := ApprovalPrompt{
+			Path:     absPath,
+			IsWrite:  isWrite,
+			ToolName: toolName,
+			Args:     args,
+		}
+		if details.Diff != nil {
+			prompt.Diff = details.Diff()
+		}
+		result, err := promptUIFunc(prompt)
 		if err != nil {
 			if m.DebugApproval {
 				log.Printf("[approval] CheckPathApproval tool=%s path=%q → PromptUIFunc error: %v", toolName, absPath, err)
@@ -1143,6 +1257,9 @@ func (m *ApprovalManager) checkShellApprovalWithContext(ctx context.Context, com
 
 	// Need to prompt - serialize prompts to avoid UI conflicts
 	// Use shared lock (via PromptLock()) to prevent concurrent prompts across parent/child managers
+	// Tool timeouts don't run while the user decides.
+	resumeTimeouts := m.pauseToolTimeouts()
+	defer resumeTimeouts()
 	promptLock := m.PromptLock()
 	promptLock.Lock()
 	defer promptLock.Unlock()
@@ -1184,7 +1301,12 @@ func (m *ApprovalManager) checkShellApprovalWithContext(ctx context.Context, com
 		if m.DebugApproval {
 			log.Printf("[approval] CheckShellApproval cmd=%q → calling PromptUIFunc", command)
 		}
-		result, err := promptUIFunc(command, false, true, workDir)
+		result, err := promptUIFunc(ApprovalPrompt{
+			Path:     command,
+			IsShell:  true,
+			WorkDir:  workDir,
+			ToolName: ShellToolName,
+		})
 		if err != nil {
 			return Cancel, err
 		}
@@ -1449,6 +1571,31 @@ func (m *ApprovalManager) handleShellApprovalResult(result ApprovalResult, comma
 		m.resetGuardianDenials()
 		return ProceedAlways, nil
 
+	case ApprovalChoicePrefix:
+		// Allow the command prefix in any directory. The prefix is re-derived
+		// so a client can't widen it to a dangerous one. The session entry
+		// lives on the root manager so /allow remove can revoke it.
+		prefix, ok := ShellCommandPrefix(command)
+		if !ok || (result.Prefix != "" && result.Prefix != prefix) {
+			log.Printf("[approval] command %q has no allowable prefix; using one-time approval", command)
+			m.resetGuardianDenials()
+			return ProceedOnce, nil
+		}
+		if err := m.root().shellCache.AddPattern(prefix + " *"); err != nil {
+			log.Printf("[approval] failed to remember command prefix %q; using one-time approval: %v", prefix, err)
+			m.resetGuardianDenials()
+			return ProceedOnce, nil
+		}
+		// Web clients ignore persisted approvals, so their prefix approvals
+		// stay in the session unless the user picked the remembered option.
+		if result.SaveToRepo || !m.IgnoreProjectApprovals {
+			if err := m.ShellPrefixRules().Add(prefix); err != nil {
+				log.Printf("[approval] failed to persist command prefix %q; using session-only approval: %v", prefix, err)
+			}
+		}
+		m.resetGuardianDenials()
+		return ProceedAlways, nil
+
 	case ApprovalChoicePattern:
 		// Approve pattern in repo (persisted if in repo, session otherwise)
 		pattern := result.Pattern
@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/samsaffron/term-llm/internal/llm"
+	diff "github.com/shogoki/gotextdiff"
+)
+
+// ApprovalPrompt is everything a prompt callback needs to ask the user.
+type ApprovalPrompt struct {
+	Path     string // File path, shell command or URL being approved
+	IsWrite  bool
+	IsShell  bool
+	WorkDir  string // Shell commands only: where the command will run
+	ToolName string
+	Args     string // Truncated argument preview, e.g. "(path:main.go, ...)"
+	Diff     string // Unified diff of the proposed change, when known
+}
+
+// ApprovalDetails is optional prompt context a tool can attach to a path
+// check. Diff is only called when the user is actually prompted.
+type ApprovalDetails struct {
+	Args string
+	Diff func() string
+}
+
+// maxApprovalDiffLines caps the diff sent with a prompt; huge rewrites are
+// still reviewable from the first screenful and the line count.
+const maxApprovalDiffLines = 400
+
+// approvalArgsPreview formats tool arguments the same way the engine shows
+// tool calls in the transcript.
+func approvalArgsPreview(toolName string, args json.RawMessage) string {
+	return llm.ExtractToolInfo(llm.ToolCall{Name: toolName, Arguments: args})
+}
+
+// approvalDiff renders a unified diff for a prompt, truncated to
+// maxApprovalDiffLines. Returns "" when nothing changes.
+func approvalDiff(path, oldContent, newContent string) string {
+	if oldContent == newContent {
+		return ""
+	}
+	unified := string(diff.Diff(path, []byte(oldContent), path, []byte(newContent)))
+	lines := strings.Split(strings.TrimRight(unified, "\n"), "\n")
+	if len(lines) <= maxApprovalDiffLines {
+		return strings.Join(lines, "\n")
+	}
+	omitted := len(lines) - maxApprovalDiffLines
+	return strings.Join(lines[:maxApprovalDiffLines], "\n") + "\n... (" + strconv.Itoa(omitted) + " more lines)"
+}
+
+// editApprovalDiff previews an edit_file old_text/new_text replacement. When
+// old_text is found verbatim the diff is against the whole file so line
+// numbers are real; otherwise (fuzzy matches, missing file) it falls back to
+// diffing the two snippets.
+func editApprovalDiff(path, oldText, newText string) string {
+	if data, err := os.ReadFile(path); err == nil {
+		content := string(data)
+		if oldText != "" && strings.Contains(content, oldText) {
+			return approvalDiff(path, content, strings.Replace(content, oldText, newText, 1))
+		}
+	}
+	return approvalDiff(path, withTrailingNewline(oldText), withTrailingNewline(newText))
+}
+
+// writeApprovalDiff previews write_file against the current file contents,
+// or against an empty file when it does not exist yet.
+func writeApprovalDiff(path, content string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		data = nil
+	}
+	return approvalDiff(path, string(data), content)
+}
+
+func withTrailingNewline(s string) string {
+	if s == "" || strings.HasSuffix(s, "\n") {
+		return s
+	}
+	return s + "\n"
+}
@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEditFilePromptIncludesArgsAndDiff(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n\nfunc main() {\n\tprintln(\"old\")\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr := NewApprovalManager(NewToolPermissions())
+	var got ApprovalPrompt
+	mgr.PromptRequestFunc = func(req ApprovalPrompt) (ApprovalResult, error) {
+		got = req
+		return ApprovalResult{Choice: ApprovalChoiceDeny}, nil
+	}
+
+	args, _ := json.Marshal(EditFileArgs{Path: path, OldText: `println("old")`, NewText: `println("new")`})
+	if _, err := NewEditFileTool(mgr).Execute(context.Background(), args); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	if got.ToolName != EditFileToolName || !got.IsWrite {
+		t.Fatalf("prompt = %+v, want edit_file write", got)
+	}
+	if !strings.Contains(got.Args, "old_text:") {
+		t.Errorf("Args = %q, want argument preview", got.Args)
+	}
+	for _, want := range []string{"@@ -1,5 +1,5 @@", `-	println("old")`, `+	println("new")`} {
+		if !strings.Contains(got.Diff, want) {
+			t.Errorf("Diff missing %q:\n%s", want, got.Diff)
+		}
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "old") {
+		t.Error("denied edit modified the file")
+	}
+}
+
+func TestLegacyPromptUIFuncStillCalled(t *testing.T) {
+	dir := t.TempDir()
+	mgr := NewApprovalManager(NewToolPermissions())
+	var gotPath string
+	var gotWrite bool
+	mgr.PromptUIFunc = func(path string, isWrite bool, isShell bool, workDir string) (ApprovalResult, error) {
+		gotPath, gotWrite = path, isWrite
+		return ApprovalResult{Choice: ApprovalChoiceOnce}, nil
+	}
+
+	target := filepath.Join(dir, "new.txt")
+	args, _ := json.Marshal(WriteFileArgs{Path: target, Content: "hello\n"})
+	if _, err := NewWriteFileTool(mgr).Execute(context.Background(), args); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !gotWrite || filepath.Base(gotPath) != "new.txt" {
+		t.Fatalf("legacy prompt got path=%q isWrite=%v", gotPath, gotWrite)
+	}
+}
+
+func TestApprovalDiffTruncatesLongChanges(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < maxApprovalDiffLines*2; i++ {
+		b.WriteString("line\n")
+	}
+	got := approvalDiff("big.txt", "", b.String())
+	lines := strings.Split(got, "\n")
+	if len(lines) != maxApprovalDiffLines+1 {
+		t.Fatalf("got %d lines, want %d", len(lines), maxApprovalDiffLines+1)
+	}
+	if !strings.HasSuffix(got, "more lines)") {
+		t.Errorf("missing truncation marker: %q", lines[len(lines)-1])
+	}
+}
@@ -1139,6 +1139,25 @@ func TestDirCache_IsPathInApprovedDir(t *testing.T) {
 	}
 }
 
+func TestDirCache_NewPathsBelowSymlinkUseTheTarget(t *testing.T) {
+	requireSymlinks(t)
+	projectDir := t.TempDir()
+	outsideDir := t.TempDir()
+	if err := os.Symlink(outsideDir, filepath.Join(projectDir, "etc")); err != nil {
+		t.Fatalf("Symlink: %v", err)
+	}
+
+	cache := NewDirCache()
+	cache.Set(projectDir, ProceedAlways, true)
+
+	if !cache.IsPathInApprovedDir(filepath.Join(projectDir, "new", "deep", "file.txt"), true) {
+		t.Error("a new path inside the approved directory should be approved")
+	}
+	if cache.IsPathInApprovedDir(filepath.Join(projectDir, "etc", "new", "deep", "file.txt"), true) {
+		t.Error("a new path below a symlink to another directory should not be approved")
+	}
+}
+
 func TestDirCache_ReadWriteSeparation(t *testing.T) {
 	cache := NewDirCache()
 	projectDir := t.TempDir()
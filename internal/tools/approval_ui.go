@@ -26,6 +26,8 @@ const (
 	ApprovalChoicePattern                         // Allow shell pattern in repo (remembered)
 	ApprovalChoiceCommand                         // Allow this specific command (session)
 	ApprovalChoiceCancelled                       // User cancelled with esc/ctrl+c
+	ApprovalChoicePrefix                          // Always allow this command prefix (remembered)
+	ApprovalChoiceDomain                          // Allow fetching from this domain (session)
 )
 
 // ApprovalResult contains the result of an approval prompt.
@@ -33,7 +35,8 @@ type ApprovalResult struct {
 	Choice     ApprovalChoice
 	Path       string // Selected path (for file/directory)
 	Pattern    string // Selected pattern (for shell)
-	SaveToRepo bool   // Whether to save to project approvals
+	Prefix     string // Selected command prefix (for shell)
+	SaveToRepo bool   // Whether to save to project approvals (or remember a command prefix)
 	Cancelled  bool   // Whether user cancelled
 }
 
@@ -44,7 +47,8 @@ type ApprovalOption struct {
 	Choice      ApprovalChoice // The choice this option represents
 	Path        string         // Path for directory/file choices
 	Pattern     string         // Pattern for shell choices
-	SaveToRepo  bool           // Whether this saves to project
+	Prefix      string         // Command prefix for shell choices
+	SaveToRepo  bool           // Whether this saves to project (or remembers a command prefix)
 }
 
 // Theme colors for approval UI
@@ -89,6 +93,10 @@ func (m *ApprovalModel) SetWidth(width int) {
 
 // NewEmbeddedApprovalModel creates an approval model for file access that can be embedded in a parent TUI.
 func NewEmbeddedApprovalModel(path string, isWrite bool, width int) *ApprovalModel {
+	if IsURLApprovalTarget(path) {
+		return newURLApprovalModel(path, width)
+	}
+
 	// Detect git repo
 	repoInfo := DetectGitRepo(path)
 	var repoInfoPtr *GitRepoInfo
@@ -195,8 +203,24 @@ func newShellApprovalModel(command, workDir string, repoInfo *GitRepoInfo) *Appr
 	}
 }
 
-// BuildFileOptions creates the options for a file access prompt.
+// newURLApprovalModel creates an approval model for a web_fetch URL.
+func newURLApprovalModel(rawURL string, width int) *ApprovalModel {
+	return &ApprovalModel{
+		title:       "Web Fetch Request",
+		path:        rawURL,
+		options:     BuildURLOptions(rawURL),
+		cursor:      0,
+		width:       width,
+		accentColor: approvalReadColor,
+	}
+}
+
+// BuildFileOptions creates the options for a file access prompt. URL targets
+// (see IsURLApprovalTarget) get the web fetch options instead.
 func BuildFileOptions(path string, repoInfo *GitRepoInfo, isWrite bool) []ApprovalOption {
+	if IsURLApprovalTarget(path) {
+		return BuildURLOptions(path)
+	}
 	var options []ApprovalOption
 	accessType := "read"
 	if isWrite {
@@ -298,6 +322,16 @@ func BuildShellOptions(command string, repoInfo *GitRepoInfo) []ApprovalOption {
 		})
 	}
 
+	// Option: Always allow the command prefix (remembered everywhere)
+	if prefix, ok := ShellCommandPrefix(command); ok {
+		options = append(options, ApprovalOption{
+			Label:       fmt.Sprintf("Always allow \"%s\" commands", prefix),
+			Description: fmt.Sprintf("Approve commands starting with %q in any directory (remembered)", prefix),
+			Choice:      ApprovalChoicePrefix,
+			Prefix:      prefix,
+		})
+	}
+
 	// Option: Allow this specific command (session only)
 	options = append(options, ApprovalOption{
 		Label:       "Allow this specific command",
@@ -326,6 +360,37 @@ func BuildShellOptions(command string, repoInfo *GitRepoInfo) []ApprovalOption {
 	return options
 }
 
+// IsURLApprovalTarget reports whether an approval target is a URL requested
+// by web_fetch rather than a file path. File targets are always absolute
+// paths, so the two never overlap.
+func IsURLApprovalTarget(target string) bool {
+	lower := strings.ToLower(target)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// BuildURLOptions creates the options for a web fetch prompt.
+func BuildURLOptions(rawURL string) []ApprovalOption {
+	domain := URLDomain(rawURL)
+	return []ApprovalOption{
+		{
+			Label:       fmt.Sprintf("Allow fetching from %s", domain),
+			Description: fmt.Sprintf("Approve all URLs on %s (session only)", domain),
+			Choice:      ApprovalChoiceDomain,
+			Path:        domain,
+		},
+		{
+			Label:       "Allow once",
+			Description: "Single fetch, no memory",
+			Choice:      ApprovalChoiceOnce,
+		},
+		{
+			Label:       "Deny",
+			Description: "Block this fetch",
+			Choice:      ApprovalChoiceDeny,
+		},
+	}
+}
+
 func (m *ApprovalModel) applyMessage(msg tea.Msg) bool {
 	switch msg := msg.(type) {
 	case tea.KeyPressMsg:
@@ -359,6 +424,7 @@ func (m *ApprovalModel) applyMessage(msg tea.Msg) bool {
 				Choice:     opt.Choice,
 				Path:       opt.Path,
 				Pattern:    opt.Pattern,
+				Prefix:     opt.Prefix,
 				SaveToRepo: opt.SaveToRepo,
 			}
 			return true
@@ -374,6 +440,7 @@ func (m *ApprovalModel) applyMessage(msg tea.Msg) bool {
 					Choice:     opt.Choice,
 					Path:       opt.Path,
 					Pattern:    opt.Pattern,
+					Prefix:     opt.Prefix,
 					SaveToRepo: opt.SaveToRepo,
 				}
 				return true
@@ -515,21 +582,25 @@ func RunFileApprovalUI(path string, isWrite bool) (ApprovalResult, error) {
 	}
 	defer tty.Close()
 
-	// Detect git repo
-	repoInfo := DetectGitRepo(path)
-	var repoInfoPtr *GitRepoInfo
-	if repoInfo.IsRepo {
-		repoInfoPtr = &repoInfo
-	}
-
 	// Get terminal width
 	width := 80
 	if w, _, err := term.GetSize(int(tty.Fd())); err == nil && w > 0 {
 		width = w
 	}
 
-	m := newApprovalModel(path, repoInfoPtr, isWrite)
-	m.width = width
+	var m *ApprovalModel
+	if IsURLApprovalTarget(path) {
+		m = newURLApprovalModel(path, width)
+	} else {
+		// Detect git repo
+		repoInfo := DetectGitRepo(path)
+		var repoInfoPtr *GitRepoInfo
+		if repoInfo.IsRepo {
+			repoInfoPtr = &repoInfo
+		}
+		m = newApprovalModel(path, repoInfoPtr, isWrite)
+		m.width = width
+	}
 
 	p := tea.NewProgram(m, tea.WithInput(tty), tea.WithOutput(tty))
 
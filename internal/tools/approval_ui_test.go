@@ -118,9 +118,9 @@ func TestBuildShellOptions_InGitRepo(t *testing.T) {
 
 	options := BuildShellOptions(command, repoInfo)
 
-	// Should have 4 options: pattern, command, once, deny
-	if len(options) != 4 {
-		t.Errorf("expected 4 options for shell in git repo, got %d", len(options))
+	// Should have 5 options: pattern, prefix, command, once, deny
+	if len(options) != 5 {
+		t.Fatalf("expected 5 options for shell in git repo, got %d", len(options))
 	}
 
 	// First option should be pattern (remembered)
@@ -134,22 +134,30 @@ func TestBuildShellOptions_InGitRepo(t *testing.T) {
 		t.Error("pattern option should have a pattern set")
 	}
 
-	// Second option should be specific command (session only)
-	if options[1].Choice != ApprovalChoiceCommand {
-		t.Errorf("second option should be ApprovalChoiceCommand, got %v", options[1].Choice)
+	// Second option should be the command prefix (remembered everywhere)
+	if options[1].Choice != ApprovalChoicePrefix || options[1].Prefix != "go test" {
+		t.Errorf("second option should be ApprovalChoicePrefix for go test, got %v %q", options[1].Choice, options[1].Prefix)
 	}
 	if options[1].SaveToRepo {
+		t.Error("prefix option should have SaveToRepo=false")
+	}
+
+	// Third option should be specific command (session only)
+	if options[2].Choice != ApprovalChoiceCommand {
+		t.Errorf("third option should be ApprovalChoiceCommand, got %v", options[2].Choice)
+	}
+	if options[2].SaveToRepo {
 		t.Error("command option should have SaveToRepo=false")
 	}
 
-	// Third should be once
-	if options[2].Choice != ApprovalChoiceOnce {
-		t.Errorf("third option should be ApprovalChoiceOnce, got %v", options[2].Choice)
+	// Fourth should be once
+	if options[3].Choice != ApprovalChoiceOnce {
+		t.Errorf("fourth option should be ApprovalChoiceOnce, got %v", options[3].Choice)
 	}
 
-	// Fourth should be deny
-	if options[3].Choice != ApprovalChoiceDeny {
-		t.Errorf("fourth option should be ApprovalChoiceDeny, got %v", options[3].Choice)
+	// Fifth should be deny
+	if options[4].Choice != ApprovalChoiceDeny {
+		t.Errorf("fifth option should be ApprovalChoiceDeny, got %v", options[4].Choice)
 	}
 }
 
@@ -159,14 +167,14 @@ func TestBuildShellOptions_NotInGitRepo(t *testing.T) {
 	// No git repo
 	options := BuildShellOptions(command, nil)
 
-	// Should have 3 options: command, once, deny (no pattern option)
-	if len(options) != 3 {
-		t.Errorf("expected 3 options outside git repo, got %d", len(options))
+	// Should have 4 options: prefix, command, once, deny (no pattern option)
+	if len(options) != 4 {
+		t.Fatalf("expected 4 options outside git repo, got %d", len(options))
 	}
 
-	// First option should be command (not pattern)
-	if options[0].Choice != ApprovalChoiceCommand {
-		t.Errorf("first option outside repo should be ApprovalChoiceCommand, got %v", options[0].Choice)
+	// First option should be the prefix (not pattern)
+	if options[0].Choice != ApprovalChoicePrefix {
+		t.Errorf("first option outside repo should be ApprovalChoicePrefix, got %v", options[0].Choice)
 	}
 
 	// No option should have SaveToRepo=true
@@ -177,6 +185,23 @@ func TestBuildShellOptions_NotInGitRepo(t *testing.T) {
 	}
 }
 
+func TestBuildFileOptions_URLTargetOffersDomainApproval(t *testing.T) {
+	options := BuildFileOptions("https://Docs.Example.com/guide?x=1", nil, false)
+
+	// Should have 3 options: domain, once, deny
+	if len(options) != 3 {
+		t.Fatalf("expected 3 options for a URL, got %d", len(options))
+	}
+	if options[0].Choice != ApprovalChoiceDomain || options[0].Path != "docs.example.com" {
+		t.Errorf("first option = %+v, want the docs.example.com domain", options[0])
+	}
+
+	m := NewEmbeddedApprovalModel("https://docs.example.com/guide", false, 80)
+	if m.title != "Web Fetch Request" {
+		t.Errorf("title = %q, want Web Fetch Request", m.title)
+	}
+}
+
 func TestApprovalModel_Keyboard(t *testing.T) {
 	// Create a model and test keyboard navigation
 	repoInfo := &GitRepoInfo{
@@ -297,6 +322,7 @@ func TestApprovalChoiceValues(t *testing.T) {
 		ApprovalChoicePattern,
 		ApprovalChoiceCommand,
 		ApprovalChoiceCancelled,
+		ApprovalChoicePrefix,
 	}
 
 	seen := make(map[ApprovalChoice]bool)
@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+)
+
+// URLDomain returns the normalized host of rawURL used for domain approvals,
+// or "" when rawURL has no host.
+func URLDomain(rawURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+}
+
+// CheckURLApproval checks whether rawURL may be fetched. Approvals are
+// domain-scoped: the first fetch from a domain prompts, and choosing to allow
+// the domain covers later fetches from it for the rest of the session.
+func (m *ApprovalManager) CheckURLApproval(toolName, rawURL string) (ConfirmOutcome, error) {
+	domain := URLDomain(rawURL)
+	if domain == "" {
+		return Cancel, NewToolErrorf(ErrInvalidParams, "url has no host: %s", rawURL)
+	}
+	if m.YoloEnabled() {
+		return ProceedOnce, nil
+	}
+	if m.isDomainApproved(domain) {
+		if m.DebugApproval {
+			log.Printf("[approval] CheckURLApproval tool=%s domain=%q → session approved", toolName, domain)
+		}
+		return ProceedAlways, nil
+	}
+
+	// Tool timeouts don't run while the user decides.
+	resumeTimeouts := m.pauseToolTimeouts()
+	defer resumeTimeouts()
+	promptLock := m.PromptLock()
+	promptLock.Lock()
+	defer promptLock.Unlock()
+
+	// Recheck now that we hold the prompt lock; a parallel fetch may have
+	// just approved the same domain.
+	if m.YoloEnabled() {
+		return ProceedOnce, nil
+	}
+	if m.isDomainApproved(domain) {
+		return ProceedAlways, nil
+	}
+
+	if promptUIFunc := m.lookupPromptUIFunc(); promptUIFunc != nil {
+		result, err := promptUIFunc(ApprovalPrompt{Path: rawURL, ToolName: toolName})
+		if err != nil {
+			return Cancel, err
+		}
+		if m.DebugApproval {
+			log.Printf("[approval] CheckURLApproval tool=%s domain=%q → PromptUIFunc result: choice=%v cancelled=%v", toolName, domain, result.Choice, result.Cancelled)
+		}
+		if result.Cancelled {
+			return Cancel, nil
+		}
+		switch result.Choice {
+		case ApprovalChoiceDomain:
+			m.approveDomain(domain)
+			return ProceedAlways, nil
+		case ApprovalChoiceOnce:
+			return ProceedOnce, nil
+		default:
+			return Cancel, nil
+		}
+	}
+
+	promptFunc := m.lookupPromptFunc()
+	if promptFunc == nil {
+		return Cancel, NewToolError(ErrPermissionDenied, "domain not approved and no TTY for approval")
+	}
+	outcome, _ := promptFunc(&ApprovalRequest{
+		ToolName:    toolName,
+		Path:        domain,
+		Description: fmt.Sprintf("Allow fetching from domain: %s", domain),
+		ToolInfo:    rawURL,
+	})
+	if outcome == ProceedAlways || outcome == ProceedAlwaysAndSave {
+		m.approveDomain(domain)
+	}
+	return outcome, nil
+}
+
+// isDomainApproved reports whether domain was approved in this session or
+// by a parent session.
+func (m *ApprovalManager) isDomainApproved(domain string) bool {
+	for cur := m; cur != nil; cur = cur.parent {
+		cur.domainMu.RLock()
+		_, ok := cur.domains[domain]
+		cur.domainMu.RUnlock()
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *ApprovalManager) approveDomain(domain string) {
+	m.domainMu.Lock()
+	m.domains[domain] = struct{}{}
+	m.domainMu.Unlock()
+}
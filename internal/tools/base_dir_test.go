@@ -64,7 +64,7 @@ func TestToolsResolveRelativePathsAgainstBaseDir(t *testing.T) {
 	if err != nil {
 		t.Fatalf("shell Execute: %v", err)
 	}
-	if !strings.Contains(shellOut.Content, base) {
+	if !strings.Contains(shellOut.Content, "stdout:\n"+base) {
 		t.Fatalf("shell output = %q, want pwd in BaseDir %q", shellOut.Content, base)
 	}
 }
@@ -0,0 +1,228 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/samsaffron/term-llm/internal/appdata"
+)
+
+// shellPrefixRulesFile is stored in the data directory next to the chat's
+// approved_dirs.json.
+const shellPrefixRulesFile = "approved_commands.json"
+
+// subcommandTools are matched on their first two words, so approving
+// "git log" does not approve "git push".
+var subcommandTools = map[string]bool{
+	"git": true, "gh": true, "go": true, "cargo": true, "rustup": true,
+	"npm": true, "npx": true, "yarn": true, "pnpm": true, "bun": true, "bunx": true, "deno": true,
+	"pip": true, "pip3": true, "uv": true, "uvx": true, "poetry": true,
+	"bundle": true, "rails": true, "rake": true, "mix": true, "dotnet": true, "swift": true,
+	"make": true, "docker": true, "podman": true, "kubectl": true, "helm": true,
+	"terraform": true, "brew": true, "apt": true, "systemctl": true,
+}
+
+// dangerousCommands can destroy data, overwrite files, escalate privileges or
+// run arbitrary code, so they are never offered as an always-allowed prefix.
+var dangerousCommands = map[string]bool{
+	"rm": true, "rmdir": true, "dd": true, "shred": true, "truncate": true, "mkfs": true,
+	"fdisk": true, "parted": true, "wipefs": true,
+	"sudo": true, "su": true, "doas": true, "chmod": true, "chown": true, "chgrp": true,
+	"curl": true, "wget": true, "ssh": true, "scp": true, "nc": true, "ncat": true,
+	"sh": true, "bash": true, "zsh": true, "fish": true, "dash": true, "ksh": true,
+	"eval": true, "exec": true, "source": true, "xargs": true, "env": true, "find": true,
+	"nohup": true, "timeout": true, "watch": true, "nice": true, "time": true,
+	"python": true, "python3": true, "node": true, "ruby": true, "perl": true, "php": true,
+	"awk": true, "osascript": true, "pwsh": true, "powershell": true,
+	"kill": true, "pkill": true, "killall": true, "shutdown": true, "reboot": true,
+	"halt": true, "poweroff": true, "crontab": true,
+	"mv": true, "cp": true, "ln": true, "tee": true, "sed": true, "install": true, "rsync": true,
+}
+
+// dangerousSubcommands are two-word prefixes of otherwise eligible tools that
+// discard work, publish or delete remote state, change configuration, or run
+// arbitrary code (including package install scripts and containers).
+var dangerousSubcommands = map[string]bool{
+	"git push": true, "git reset": true, "git clean": true, "git checkout": true,
+	"git restore": true, "git rm": true, "git rebase": true, "git filter-branch": true,
+	"gh api": true, "gh repo": true, "go run": true,
+	"npm publish": true, "cargo publish": true, "yarn publish": true, "pnpm publish": true,
+	"docker rm": true, "docker rmi": true, "docker system": true, "docker exec": true,
+	"podman rm": true, "podman rmi": true, "podman system": true, "podman exec": true,
+	"kubectl delete": true, "kubectl exec": true, "kubectl apply": true,
+	"helm uninstall": true, "terraform apply": true, "terraform destroy": true,
+	"apt remove": true, "apt purge": true,
+	"git config": true, "npm install": true, "npm i": true, "npm exec": true,
+	"yarn add": true, "pnpm add": true, "pnpm install": true, "bun add": true, "bun install": true,
+	"pip install": true, "pip3 install": true, "uv pip": true, "cargo install": true, "go install": true,
+	"docker run": true, "podman run": true, "kubectl run": true,
+}
+
+// plainShellWord matches words that are safe to use literally in a prefix
+// rule: no paths, globs, assignments or options.
+var plainShellWord = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+-]*$`)
+
+// ShellCommandPrefix returns the prefix an "always allow" rule for command
+// would cover: its first word, or its first two words for tools with
+// subcommands such as git. ok is false when the command is not eligible:
+// dangerous commands, commands using shell operators, and commands whose
+// first words are not plain names.
+func ShellCommandPrefix(command string) (prefix string, ok bool) {
+	command = strings.TrimSpace(command)
+	if command == "" || hasUnsafeShellSyntax(command) {
+		return "", false
+	}
+	words, err := splitShellWords(command)
+	if err != nil || len(words) == 0 {
+		return "", false
+	}
+
+	name := words[0]
+	if !plainShellWord.MatchString(name) || dangerousCommands[name] || strings.HasPrefix(name, "mkfs.") {
+		return "", false
+	}
+	if !subcommandTools[name] {
+		return name, true
+	}
+	if len(words) < 2 || !plainShellWord.MatchString(words[1]) {
+		return "", false
+	}
+	prefix = name + " " + words[1]
+	if dangerousSubcommands[prefix] {
+		return "", false
+	}
+	return prefix, true
+}
+
+// ShellPrefixRules are the shell command prefixes the user chose to always
+// allow. Unlike repo shell patterns they apply everywhere and persist in the
+// data directory.
+type ShellPrefixRules struct {
+	UpdatedAt time.Time `json:"updated_at"`
+	Prefixes  []string  `json:"prefixes"`
+
+	filePath string
+	mu       sync.Mutex
+}
+
+// LoadShellPrefixRules loads the always-allowed prefixes from the data
+// directory. A missing file yields an empty rule set.
+func LoadShellPrefixRules() (*ShellPrefixRules, error) {
+	dataDir, err := appdata.GetDataDir()
+	if err != nil {
+		return nil, err
+	}
+	return loadShellPrefixRules(filepath.Join(dataDir, shellPrefixRulesFile))
+}
+
+func loadShellPrefixRules(path string) (*ShellPrefixRules, error) {
+	rules := &ShellPrefixRules{filePath: path, Prefixes: []string{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return rules, nil
+		}
+		return nil, fmt.Errorf("read allowed command prefixes: %w", err)
+	}
+	if err := json.Unmarshal(data, rules); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if rules.Prefixes == nil {
+		rules.Prefixes = []string{}
+	}
+	return rules, nil
+}
+
+// Allows reports whether command is covered by the rules. Compound commands
+// are allowed only when every command in them is covered, apart from safe
+// pipe targets such as grep or head.
+func (r *ShellPrefixRules) Allows(command string) bool {
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	patterns := make([]string, 0, len(r.Prefixes))
+	for _, prefix := range r.Prefixes {
+		// Skip hand-edited entries that would not be offered.
+		if canonical, ok := ShellCommandPrefix(prefix); ok && canonical == prefix {
+			patterns = append(patterns, prefix+" *")
+		}
+	}
+	r.mu.Unlock()
+	return len(patterns) > 0 && matchAnyShellPattern(patterns, command)
+}
+
+// List returns the allowed prefixes in sorted order.
+func (r *ShellPrefixRules) List() []string {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prefixes := slices.Clone(r.Prefixes)
+	slices.Sort(prefixes)
+	return prefixes
+}
+
+// Add allows prefix and saves the rules.
+func (r *ShellPrefixRules) Add(prefix string) error {
+	prefix = strings.Join(strings.Fields(prefix), " ")
+	if canonical, ok := ShellCommandPrefix(prefix); !ok || canonical != prefix {
+		return fmt.Errorf("%q cannot be always allowed", prefix)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if slices.Contains(r.Prefixes, prefix) {
+		return nil
+	}
+	previous := r.Prefixes
+	r.Prefixes = append(slices.Clone(r.Prefixes), prefix)
+	if err := r.saveLocked(); err != nil {
+		r.Prefixes = previous
+		return err
+	}
+	return nil
+}
+
+// Remove stops allowing prefix and saves the rules.
+func (r *ShellPrefixRules) Remove(prefix string) error {
+	prefix = strings.Join(strings.Fields(prefix), " ")
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := slices.Index(r.Prefixes, prefix)
+	if i < 0 {
+		return fmt.Errorf("%q is not an allowed command prefix", prefix)
+	}
+	previous := r.Prefixes
+	r.Prefixes = slices.Delete(slices.Clone(r.Prefixes), i, i+1)
+	if err := r.saveLocked(); err != nil {
+		r.Prefixes = previous
+		return err
+	}
+	return nil
+}
+
+func (r *ShellPrefixRules) saveLocked() error {
+	if r.filePath == "" {
+		return nil
+	}
+	r.UpdatedAt = time.Now()
+	if err := os.MkdirAll(filepath.Dir(r.filePath), 0755); err != nil {
+		return fmt.Errorf("create data directory: %w", err)
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(r.filePath, data, 0600); err != nil {
+		return fmt.Errorf("save allowed command prefixes: %w", err)
+	}
+	return nil
+}
@@ -9,24 +9,30 @@ import (
 	"sync"
 
 	"github.com/samsaffron/term-llm/internal/pathutil"
+	"github.com/samsaffron/term-llm/internal/udiff"
 )
 
 // ToolConfig holds configuration for the local tool system.
 type ToolConfig struct {
 	mu *sync.RWMutex `mapstructure:"-"`
 
-	Enabled         []string    `mapstructure:"enabled"`            // Enabled tool spec names
-	ReadDirs        []string    `mapstructure:"read_dirs"`          // Directories for read operations
-	WriteDirs       []string    `mapstructure:"write_dirs"`         // Directories for write operations
-	ShellAllow      []string    `mapstructure:"shell_allow"`        // Shell command patterns
-	ScriptCommands  []string    `mapstructure:"script_commands"`    // Exact script commands (auto-approved)
-	ShellAutoRun    bool        `mapstructure:"shell_auto_run"`     // Auto-approve matching shell
-	ShellAutoRunEnv string      `mapstructure:"shell_auto_run_env"` // Env var required for auto-run
-	ShellNonTTYEnv  string      `mapstructure:"shell_non_tty_env"`  // Env var for non-TTY execution
-	ImageProvider   string      `mapstructure:"image_provider"`     // Override for image provider
-	Spawn           SpawnConfig `mapstructure:"spawn"`              // Spawn agent configuration
-	AgentDir        string      `mapstructure:"-"`                  // Agent source directory (set at runtime)
-	PlanGuidance    bool        `mapstructure:"-"`                  // Add built-in developer guidance only when update_plan is callable
+	Enabled            []string    `mapstructure:"enabled"`            // Enabled tool spec names
+	ReadDirs           []string    `mapstructure:"read_dirs"`          // Directories for read operations
+	WriteDirs          []string    `mapstructure:"write_dirs"`         // Directories for write operations
+	ShellAllow         []string    `mapstructure:"shell_allow"`        // Shell command patterns
+	ScriptCommands     []string    `mapstructure:"script_commands"`    // Exact script commands (auto-approved)
+	ShellAutoRun       bool        `mapstructure:"shell_auto_run"`     // Auto-approve matching shell
+	ShellAutoRunEnv    string      `mapstructure:"shell_auto_run_env"` // Env var required for auto-run
+	ShellNonTTYEnv     string      `mapstructure:"shell_non_tty_env"`  // Env var for non-TTY execution
+	Shell              string      `mapstructure:"shell"`              // Shell for the shell tool; "" = $SHELL or cmd.exe on Windows
+	ImageProvider      string      `mapstructure:"image_provider"`     // Override for image provider
+	ImageMaxDimension  int         `mapstructure:"-"`                  // view_image longest edge; 0 = imageprep default
+	ImageQuality       int         `mapstructure:"-"`                  // view_image JPEG quality; 0 = imageprep default
+	DiffFuzzyMatch     string      `mapstructure:"-"`                  // unified_diff loosest match tier (edit.fuzzy_match); "" = similar
+	DiffFuzzyThreshold float64     `mapstructure:"-"`                  // unified_diff similarity (edit.fuzzy_threshold); 0 = udiff default
+	Spawn              SpawnConfig `mapstructure:"spawn"`              // Spawn agent configuration
+	AgentDir           string      `mapstructure:"-"`                  // Agent source directory (set at runtime)
+	PlanGuidance       bool        `mapstructure:"-"`                  // Add built-in developer guidance only when update_plan is callable
 	// BaseDir, when set, is the per-run/session working directory used to
 	// resolve relative tool paths and default process-spawn directories. It is
 	// deliberately implemented through explicit path resolution / exec.Cmd.Dir;
@@ -156,9 +162,24 @@ func (c ToolConfig) Merge(other ToolConfig) ToolConfig {
 	if other.ShellNonTTYEnv != "" {
 		result.ShellNonTTYEnv = other.ShellNonTTYEnv
 	}
+	if other.Shell != "" {
+		result.Shell = other.Shell
+	}
 	if other.ImageProvider != "" {
 		result.ImageProvider = other.ImageProvider
 	}
+	if other.ImageMaxDimension > 0 {
+		result.ImageMaxDimension = other.ImageMaxDimension
+	}
+	if other.ImageQuality > 0 {
+		result.ImageQuality = other.ImageQuality
+	}
+	if other.DiffFuzzyMatch != "" {
+		result.DiffFuzzyMatch = other.DiffFuzzyMatch
+	}
+	if other.DiffFuzzyThreshold > 0 {
+		result.DiffFuzzyThreshold = other.DiffFuzzyThreshold
+	}
 
 	if other.AgentDir != "" {
 		result.AgentDir = other.AgentDir
@@ -205,6 +226,7 @@ func (c *ToolConfig) Validate() []error {
 	readDirs := append([]string(nil), c.ReadDirs...)
 	writeDirs := append([]string(nil), c.WriteDirs...)
 	shellAllow := append([]string(nil), c.ShellAllow...)
+	diffFuzzyMatch := c.DiffFuzzyMatch
 	mu.RUnlock()
 
 	// Validate tool names
@@ -214,6 +236,10 @@ func (c *ToolConfig) Validate() []error {
 		}
 	}
 
+	if _, err := udiff.ParseMatchTier(diffFuzzyMatch); err != nil {
+		errs = append(errs, fmt.Errorf("edit.fuzzy_match: %w", err))
+	}
+
 	// Validate shell patterns
 	for _, pattern := range shellAllow {
 		if err := validateShellApprovalPattern(pattern); err != nil {
@@ -331,6 +331,9 @@ func (r *LocalToolRegistry) RegisterCustomTools(defs []agents.CustomToolDef, age
 		}
 
 		tool := newCustomScriptTool(def, agentDir, r.limits, r.config)
+		if err := llm.ValidateToolSpec(tool.Spec()); err != nil {
+			return fmt.Errorf("custom %w", err)
+		}
 		r.tools[def.Name] = tool
 	}
 	return nil
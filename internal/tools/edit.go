@@ -124,7 +124,11 @@ func (t *EditFileTool) Execute(ctx context.Context, args json.RawMessage) (llm.T
 
 	// Check permissions via approval manager
 	if t.approval != nil {
-		outcome, err := t.approval.CheckPathApproval(EditFileToolName, absPath, a.Path, true)
+		details := ApprovalDetails{Args: approvalArgsPreview(EditFileToolName, args)}
+		if a.Instructions == "" {
+			details.Diff = func() string { return editApprovalDiff(absPath, a.OldText, a.NewText) }
+		}
+		outcome, err := t.approval.CheckPathApprovalWithDetails(EditFileToolName, absPath, a.Path, true, details)
 		if err != nil {
 			if toolErr, ok := err.(*ToolError); ok {
 				return textOutput(formatToolError(toolErr)), nil
@@ -301,13 +305,8 @@ func (t *UnifiedDiffTool) Preview(args json.RawMessage) string {
 		return ""
 	}
 	// Extract first filename from diff for preview
-	lines := strings.Split(a.Diff, "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, "--- ") {
-			path := strings.TrimPrefix(line, "--- ")
-			path = strings.TrimPrefix(path, "a/")
-			return path
-		}
+	if fileDiffs, err := udiff.Parse(a.Diff); err == nil && len(fileDiffs) > 0 {
+		return fileDiffs[0].Path
 	}
 	return "multiple files"
 }
@@ -393,10 +392,31 @@ func (t *UnifiedDiffTool) Execute(ctx context.Context, args json.RawMessage) (ll
 	return llm.ToolOutput{Content: sb.String(), Diffs: diffs, FileChanges: fileChanges}, nil
 }
 
+// matchOptions returns how loosely hunks may match, from edit.fuzzy_match and
+// edit.fuzzy_threshold.
+func (t *UnifiedDiffTool) matchOptions() udiff.Options {
+	opts := udiff.DefaultOptions()
+	if t.config == nil {
+		return opts
+	}
+	// Validate reports a bad tier; fall back to the default here.
+	if tier, err := udiff.ParseMatchTier(t.config.DiffFuzzyMatch); err == nil {
+		opts.MaxTier = tier
+	}
+	if t.config.DiffFuzzyThreshold > 0 {
+		opts.Similarity = t.config.DiffFuzzyThreshold
+	}
+	return opts
+}
+
 // applyFileDiff applies a single file's hunks while holding a per-path lock.
 func (t *UnifiedDiffTool) applyFileDiff(ctx context.Context, absPath string, fd udiff.FileDiff) (status string, warnings []string, diffData *llm.DiffData, fileChange *llm.FileChange) {
 	defer lockFilePath(absPath)()
 
+	if fd.IsCreate() {
+		return t.createFile(ctx, absPath, fd)
+	}
+
 	fileInfo, err := os.Stat(absPath)
 	if err != nil {
 		return "", []string{fmt.Sprintf("%s: %v", fd.Path, err)}, nil, nil
@@ -408,9 +428,16 @@ func (t *UnifiedDiffTool) applyFileDiff(ctx context.Context, absPath string, fd
 	}
 	content := string(data)
 
-	result := udiff.ApplyWithWarnings(content, fd.Hunks)
-	if len(result.Warnings) > 0 {
-		warnings = append(warnings, result.Warnings...)
+	if fd.IsDelete() {
+		return t.deleteFile(ctx, absPath, fd, data)
+	}
+
+	result := udiff.ApplyHunks(content, fd.Hunks, udiff.ApplyPartially, t.matchOptions())
+	for _, failure := range result.Failed {
+		warnings = append(warnings, failure.Error())
+	}
+	for _, m := range result.Fuzzy {
+		warnings = append(warnings, fmt.Sprintf("%s: %s", fd.Path, m.Warning()))
 	}
 
 	if result.Content == content {
@@ -419,50 +446,103 @@ func (t *UnifiedDiffTool) applyFileDiff(ctx context.Context, absPath string, fd
 
 	// Follow symlinks so the atomic rename writes through the link instead
 	// of replacing it.
-	writePath := resolveWriteTarget(absPath)
-	dir := filepath.Dir(writePath)
-	base := filepath.Base(writePath)
+	if err := writeFileAtomically(resolveWriteTarget(absPath), result.Content, fileMode); err != nil {
+		return "", append(warnings, fmt.Sprintf("%s: %v", fd.Path, err)), nil, nil
+	}
+
+	fileChange = recordFileChange(ctx, t.recorder, UnifiedDiffToolName, absPath, data, []byte(result.Content), false, false)
+
+	oldLines := countLines(content)
+	newLines := countLines(result.Content)
+	status = fmt.Sprintf("Applied changes to %s: %d lines -> %d lines.\n", fd.Path, oldLines, newLines)
+
+	if len(content) < diff.MaxDiffSize && len(result.Content) < diff.MaxDiffSize {
+		diffData = &llm.DiffData{
+			File: absPath, Old: content, New: result.Content, Line: 1,
+		}
+	}
+
+	return status, warnings, diffData, fileChange
+}
+
+// createFile writes the file a --- /dev/null diff creates. It never
+// overwrites an existing file.
+func (t *UnifiedDiffTool) createFile(ctx context.Context, absPath string, fd udiff.FileDiff) (status string, warnings []string, diffData *llm.DiffData, fileChange *llm.FileChange) {
+	if _, err := os.Lstat(absPath); err == nil {
+		return "", []string{fmt.Sprintf("%s: cannot create, file already exists", fd.Path)}, nil, nil
+	} else if !os.IsNotExist(err) {
+		return "", []string{fmt.Sprintf("%s: %v", fd.Path, err)}, nil, nil
+	}
+	content, err := udiff.NewFileContent(fd.Hunks)
+	if err != nil {
+		return "", []string{fmt.Sprintf("%s: %v", fd.Path, err)}, nil, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(absPath), 0755); err != nil {
+		return "", []string{fmt.Sprintf("%s: failed to create directory: %v", fd.Path, err)}, nil, nil
+	}
+	if err := writeFileAtomically(absPath, content, 0644); err != nil {
+		return "", []string{fmt.Sprintf("%s: %v", fd.Path, err)}, nil, nil
+	}
+
+	fileChange = recordFileChange(ctx, t.recorder, UnifiedDiffToolName, absPath, nil, []byte(content), true, false)
+	status = fmt.Sprintf("Created %s (%d lines).\n", fd.Path, countLines(content))
+	if len(content) < diff.MaxDiffSize {
+		diffData = &llm.DiffData{File: absPath, Old: "", New: content, Line: 1, Operation: llm.DiffOperationCreate}
+	}
+	return status, nil, diffData, fileChange
+}
+
+// deleteFile removes the file a +++ /dev/null diff deletes, after checking
+// that any hunks remove its whole content.
+func (t *UnifiedDiffTool) deleteFile(ctx context.Context, absPath string, fd udiff.FileDiff, data []byte) (status string, warnings []string, diffData *llm.DiffData, fileChange *llm.FileChange) {
+	content := string(data)
+	if err := udiff.CheckDeletion(content, fd.Hunks); err != nil {
+		return "", []string{fmt.Sprintf("%s: not deleted: %v", fd.Path, err)}, nil, nil
+	}
+	if err := os.Remove(absPath); err != nil {
+		return "", []string{fmt.Sprintf("%s: failed to delete: %v", fd.Path, err)}, nil, nil
+	}
+
+	fileChange = recordFileChange(ctx, t.recorder, UnifiedDiffToolName, absPath, data, nil, false, true)
+	status = fmt.Sprintf("Deleted %s (%d lines).\n", fd.Path, countLines(content))
+	if len(content) < diff.MaxDiffSize {
+		diffData = &llm.DiffData{File: absPath, Old: content, New: "", Line: 1, Operation: llm.DiffOperationDelete}
+	}
+	return status, nil, diffData, fileChange
+}
+
+// writeFileAtomically replaces path with content through a temp file in the
+// same directory.
+func writeFileAtomically(path, content string, mode os.FileMode) error {
+	dir := filepath.Dir(path)
+	base := filepath.Base(path)
 	tempFile, err := os.CreateTemp(dir, "."+base+".*.tmp")
 	if err != nil {
-		return "", append(warnings, fmt.Sprintf("%s: failed to create temp file: %v", fd.Path, err)), nil, nil
+		return fmt.Errorf("failed to create temp file: %w", err)
 	}
 	tempPath := tempFile.Name()
 
-	if _, err := tempFile.WriteString(result.Content); err != nil {
+	if _, err := tempFile.WriteString(content); err != nil {
 		tempFile.Close()
 		os.Remove(tempPath)
-		return "", append(warnings, fmt.Sprintf("%s: failed to write temp file: %v", fd.Path, err)), nil, nil
+		return fmt.Errorf("failed to write temp file: %w", err)
 	}
 	if err := tempFile.Sync(); err != nil {
 		tempFile.Close()
 		os.Remove(tempPath)
-		return "", append(warnings, fmt.Sprintf("%s: failed to sync temp file: %v", fd.Path, err)), nil, nil
+		return fmt.Errorf("failed to sync temp file: %w", err)
 	}
 	tempFile.Close()
 
-	if err := os.Chmod(tempPath, fileMode); err != nil {
+	if err := os.Chmod(tempPath, mode); err != nil {
 		os.Remove(tempPath)
-		return "", append(warnings, fmt.Sprintf("%s: failed to set permissions: %v", fd.Path, err)), nil, nil
+		return fmt.Errorf("failed to set permissions: %w", err)
 	}
-
-	if err := os.Rename(tempPath, writePath); err != nil {
+	if err := os.Rename(tempPath, path); err != nil {
 		os.Remove(tempPath)
-		return "", append(warnings, fmt.Sprintf("%s: failed to rename: %v", fd.Path, err)), nil, nil
-	}
-
-	fileChange = recordFileChange(ctx, t.recorder, UnifiedDiffToolName, absPath, data, []byte(result.Content), false, false)
-
-	oldLines := countLines(content)
-	newLines := countLines(result.Content)
-	status = fmt.Sprintf("Applied changes to %s: %d lines -> %d lines.\n", fd.Path, oldLines, newLines)
-
-	if len(content) < diff.MaxDiffSize && len(result.Content) < diff.MaxDiffSize {
-		diffData = &llm.DiffData{
-			File: absPath, Old: content, New: result.Content, Line: 1,
-		}
+		return fmt.Errorf("failed to rename: %w", err)
 	}
-
-	return status, warnings, diffData, fileChange
+	return nil
 }
 
 // GenerateDiff creates a unified diff between old and new content.
@@ -0,0 +1,151 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+func TestUnifiedDiffToolCreatesAndDeletesFiles(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.txt")
+	newPath := filepath.Join(dir, "sub", "new.txt")
+	if err := os.WriteFile(oldPath, []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := &fakeFileRecorder{}
+	tool := NewUnifiedDiffTool(nil)
+	tool.recorder = recorder
+
+	// A rename is a deletion plus a creation.
+	diffText := fmt.Sprintf(`--- a/%s
++++ /dev/null
+@@ -1,2 +0,0 @@
+-one
+-two
+--- /dev/null
++++ b/%s
+@@ -0,0 +1,2 @@
++one
++two
+`, oldPath, newPath)
+
+	args, _ := json.Marshal(UnifiedDiffArgs{Diff: diffText})
+	output, err := tool.Execute(trackingContext(), args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(output.Content, "Warnings") {
+		t.Fatalf("unexpected warnings:\n%s", output.Content)
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Fatalf("old.txt should be deleted, stat err = %v", err)
+	}
+	if got, err := os.ReadFile(newPath); err != nil || string(got) != "one\ntwo\n" {
+		t.Fatalf("new.txt = %q, %v", got, err)
+	}
+
+	if len(output.Diffs) != 2 {
+		t.Fatalf("diffs = %+v, want two", output.Diffs)
+	}
+	if output.Diffs[0].Operation != llm.DiffOperationDelete || output.Diffs[1].Operation != llm.DiffOperationCreate {
+		t.Fatalf("diff operations = %q, %q; want delete, create", output.Diffs[0].Operation, output.Diffs[1].Operation)
+	}
+	if deleted := recorder.findRecord(t, oldPath); !deleted.AfterMissing {
+		t.Fatal("deletion should be recorded with the file missing afterwards")
+	}
+	if created := recorder.findRecord(t, newPath); !created.BeforeMissing {
+		t.Fatal("creation should be recorded with the file missing before")
+	}
+}
+
+func TestUnifiedDiffToolRefusesUnsafeCreateAndDelete(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.txt")
+	if err := os.WriteFile(existing, []byte("keep\nme\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tool := NewUnifiedDiffTool(nil)
+
+	tests := []struct {
+		name string
+		diff string
+		want string
+	}{
+		{
+			name: "create over existing file",
+			diff: fmt.Sprintf("--- /dev/null\n+++ b/%s\n@@\n+replaced\n", existing),
+			want: "file already exists",
+		},
+		{
+			name: "delete with content that does not match",
+			diff: fmt.Sprintf("--- a/%s\n+++ /dev/null\n@@\n-keep\n", existing),
+			want: "not deleted",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, _ := json.Marshal(UnifiedDiffArgs{Diff: tt.diff})
+			output, err := tool.Execute(trackingContext(), args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(output.Content, tt.want) {
+				t.Fatalf("output = %q, want %q", output.Content, tt.want)
+			}
+			if got, _ := os.ReadFile(existing); string(got) != "keep\nme\n" {
+				t.Fatalf("existing.txt changed to %q", got)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiffToolHonoursFuzzyMatchConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	original := "func main() {\n\tfmt.Println(\"hello, world\")\n}\n"
+	diffText := fmt.Sprintf("--- a/%s\n+++ b/%s\n@@\n func main() {\n-\tfmt.Println(\"hello world\")\n+\tfmt.Println(\"goodbye\")\n }\n", path, path)
+	args, _ := json.Marshal(UnifiedDiffArgs{Diff: diffText})
+
+	tests := []struct {
+		name       string
+		config     *ToolConfig
+		wantOutput string
+		wantFile   string
+	}{
+		{
+			name:       "default reports the similar match",
+			wantOutput: "hunk 1 matched lines that differ slightly from the diff",
+			wantFile:   "func main() {\n\tfmt.Println(\"goodbye\")\n}\n",
+		},
+		{
+			name:       "exact only rejects it",
+			config:     &ToolConfig{DiffFuzzyMatch: "exact"},
+			wantOutput: "hunk 1: could not find matching lines",
+			wantFile:   original,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+				t.Fatal(err)
+			}
+			tool := NewUnifiedDiffTool(nil, tt.config)
+			output, err := tool.Execute(trackingContext(), args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(output.Content, tt.wantOutput) {
+				t.Fatalf("output = %q, want %q", output.Content, tt.wantOutput)
+			}
+			if got, _ := os.ReadFile(path); string(got) != tt.wantFile {
+				t.Fatalf("file = %q, want %q", got, tt.wantFile)
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ import (
 
 	"github.com/bmatcuk/doublestar/v4"
 	"github.com/samsaffron/term-llm/internal/pathutil"
+	"github.com/samsaffron/term-llm/internal/procutil"
 )
 
 // shellNonceEnvVar is the environment variable name used to tag every process
@@ -77,9 +78,9 @@ func prepareToolCommand(cmd *exec.Cmd) (func(), error) {
 		// failure, timeout, or cancellation) reap every descendant it spawned.
 		//
 		// First pass: SIGKILL the process group so `nohup foo &` style children
-		// that stayed in our pgid die immediately.
+		// that stayed in our pgid die immediately (on Windows, the process tree).
 		if cmd.Process != nil {
-			_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+			_ = procutil.KillProcessTree(cmd.Process.Pid)
 		}
 		// Second pass: walk /proc for any process still alive that inherited
 		// the nonce env var. This catches descendants that escaped the pgroup
@@ -141,7 +142,9 @@ func killTaggedDescendants(nonce string) {
 			return
 		}
 		for _, pid := range pids {
-			_ = syscall.Kill(pid, syscall.SIGKILL)
+			if proc, err := os.FindProcess(pid); err == nil {
+				_ = proc.Kill()
+			}
 		}
 		time.Sleep(20 * time.Millisecond)
 	}
@@ -181,18 +184,12 @@ func PrepareCommand(cmd *exec.Cmd) (func(), error) {
 }
 
 func configureCommandProcessGroup(cmd *exec.Cmd) {
-	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	procutil.ConfigureCommandProcessGroup(cmd)
 	// WaitDelay unblocks cmd.Wait() when the main process has exited but a
 	// backgrounded descendant still holds the stdout/stderr pipe open. After
 	// the delay Go closes the pipes and returns from Wait, preventing the
 	// tool from hanging indefinitely.
 	cmd.WaitDelay = 2 * time.Second
-	cmd.Cancel = func() error {
-		if cmd.Process == nil {
-			return nil
-		}
-		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
-	}
 }
 
 func splitShellWords(input string) ([]string, error) {
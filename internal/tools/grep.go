@@ -93,8 +93,9 @@ func NewGrepTool(approval *ApprovalManager, limits OutputLimits, configs ...*Too
 	}
 }
 
-// ripgrepAvailable checks if ripgrep (rg) is available.
-func ripgrepAvailable() bool {
+// ripgrepAvailable checks if ripgrep (rg) is available. Tests replace it to
+// exercise the Go fallback.
+var ripgrepAvailable = func() bool {
 	_, err := exec.LookPath("rg")
 	return err == nil
 }
@@ -154,8 +155,11 @@ func buildRipgrepArgs(a GrepArgs, searchPath string, contextLines, maxResults in
 	if a.Multiline {
 		args = append(args, "--multiline", "--multiline-dotall")
 	}
+	if a.Literal {
+		args = append(args, "--fixed-strings")
+	}
 
-	args = append(args, a.Pattern, searchPath)
+	args = append(args, "--regexp", a.Pattern, searchPath)
 	return args
 }
 
@@ -886,6 +890,7 @@ type GrepArgs struct {
 	ContextLines     int    `json:"context_lines,omitempty"`      // lines of context around match (default 2)
 	FilesWithMatches bool   `json:"files_with_matches,omitempty"` // return filenames only
 	Multiline        bool   `json:"multiline,omitempty"`          // allow matches to span line boundaries
+	Literal          bool   `json:"literal,omitempty"`            // match pattern as plain text, not a regex
 }
 
 // GrepMatch represents a single grep match.
@@ -899,7 +904,7 @@ type GrepMatch struct {
 func (t *GrepTool) Spec() llm.ToolSpec {
 	return llm.ToolSpec{
 		Name:        GrepToolName,
-		Description: "Search file contents using regex patterns (RE2 syntax). Returns matches with context.",
+		Description: "Search file contents using regex patterns (RE2 syntax) or literal text. Files ignored by .gitignore are skipped. Returns matches grouped by file with line numbers and context.",
 		Schema: map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -935,6 +940,10 @@ func (t *GrepTool) Spec() llm.ToolSpec {
 					"type":        "boolean",
 					"description": "Allow regex matches to span line boundaries (default: false)",
 				},
+				"literal": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Treat pattern as plain text instead of a regex, e.g. for 'foo(' or 'a.b' (default: false)",
+				},
 				"max_results": map[string]interface{}{
 					"type":        "integer",
 					"description": "Maximum number of results (default: 100)",
@@ -956,6 +965,9 @@ func (t *GrepTool) Preview(args json.RawMessage) string {
 		pattern = pattern[:27] + "..."
 	}
 	result := fmt.Sprintf("/%s/", pattern)
+	if a.Literal {
+		result = strconv.Quote(pattern)
+	}
 	if a.Path != "" {
 		result += " in " + a.Path
 	}
@@ -980,10 +992,10 @@ func (t *GrepTool) Execute(ctx context.Context, args json.RawMessage) (llm.ToolO
 
 	warning := WarnUnknownParams(args, []string{
 		"pattern", "path", "include", "exclude", "type",
-		"max_results", "context_lines", "files_with_matches", "multiline",
+		"max_results", "context_lines", "files_with_matches", "multiline", "literal",
 	})
 	textOutput := func(message string) llm.ToolOutput {
-		return llm.TextOutput(warning + message)
+		return llm.TextOutput(warning + llm.TruncateToolResult(message, maxOutputBytes))
 	}
 
 	var a GrepArgs
@@ -1085,7 +1097,11 @@ func (t *GrepTool) Execute(ctx context.Context, args json.RawMessage) (llm.ToolO
 
 	// Fallback: Go implementation
 	// Compile regex
-	re, err := regexp.Compile(a.Pattern)
+	pattern := a.Pattern
+	if a.Literal {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	re, err := regexp.Compile(pattern)
 	if err != nil {
 		return textOutput(formatToolError(NewToolErrorf(ErrInvalidParams, "invalid regex pattern: %v", err))), nil
 	}
@@ -1095,6 +1111,7 @@ func (t *GrepTool) Execute(ctx context.Context, args json.RawMessage) (llm.ToolO
 	if err != nil {
 		return textOutput(formatToolError(NewToolErrorf(ErrExecutionFailed, "failed to collect files: %v", err))), nil
 	}
+	files = withoutGitIgnored(ctx, resolvedSearchPath, files)
 
 	// Sort by modification time (newest first)
 	sortFilesByMtime(files)
@@ -1186,6 +1203,27 @@ func collectFiles(searchPath, include, exclude string) ([]string, error) {
 	return files, err
 }
 
+// withoutGitIgnored drops files Git ignores when searchPath is inside a
+// repository, matching ripgrep's default behavior. Outside a repository, or
+// when git is unavailable, files are returned unchanged.
+func withoutGitIgnored(ctx context.Context, searchPath string, files []string) []string {
+	repo := DetectGitRepo(searchPath)
+	if !repo.IsRepo || len(files) == 0 {
+		return files
+	}
+	ignored := gitIgnoredCandidates(ctx, repo.Root, files)
+	if len(ignored) == 0 {
+		return files
+	}
+	kept := files[:0]
+	for _, file := range files {
+		if !ignored[filepath.Clean(file)] {
+			kept = append(kept, file)
+		}
+	}
+	return kept
+}
+
 // sortGrepMatchesByMtime reorders matches so that matches from the
 // most-recently-modified file appear first.  Within a file the original
 // line order is preserved.  Files that cannot be stat'd are sorted last.
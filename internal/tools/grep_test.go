@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -1094,3 +1095,69 @@ func BenchmarkGrepToolAutoEnrichSingleMatchLargeTree(b *testing.B) {
 		}
 	}
 }
+
+// newGrepFixtureRepo creates a git repository with a .gitignore'd build
+// directory and returns its root.
+func newGrepFixtureRepo(t *testing.T) string {
+	t.Helper()
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exec.Command("git", "-C", dir, "init", "-q").Run(); err != nil {
+		t.Skipf("git init failed, skipping: %v", err)
+	}
+	files := map[string]string{
+		".gitignore":       "build/\n*.log\n",
+		"src/main.go":      "package main\n\nfunc main() {\n\tcfg.Load(path)\n}\n",
+		"src/util.go":      "package main\n\n// cfgXLoad is not a call\n",
+		"build/gen.go":     "package build\n\nvar _ = cfg.Load(path)\n",
+		"debug.log":        "cfg.Load(path) failed\n",
+		"docs/readme.text": "call cfg.Load(path) first\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestGrepTool_LiteralSearchSkipsGitIgnored(t *testing.T) {
+	backends := map[string]bool{"ripgrep": true, "go": false}
+	for name, useRipgrep := range backends {
+		t.Run(name, func(t *testing.T) {
+			if useRipgrep && !ripgrepAvailable() {
+				t.Skip("ripgrep not available")
+			}
+			if !useRipgrep {
+				old := ripgrepAvailable
+				ripgrepAvailable = func() bool { return false }
+				t.Cleanup(func() { ripgrepAvailable = old })
+			}
+			dir := newGrepFixtureRepo(t)
+
+			tool := NewGrepTool(nil, DefaultOutputLimits())
+			args, _ := json.Marshal(GrepArgs{Pattern: "cfg.Load(path)", Path: dir, Literal: true, ContextLines: 1})
+			output, err := tool.Execute(context.Background(), args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, want := range []string{"src/main.go", "4:", "docs/readme.text"} {
+				if !strings.Contains(output.Content, want) {
+					t.Errorf("output missing %q:\n%s", want, output.Content)
+				}
+			}
+			for _, unwanted := range []string{"util.go", "gen.go", "debug.log"} {
+				if strings.Contains(output.Content, unwanted) {
+					t.Errorf("output contains %q:\n%s", unwanted, output.Content)
+				}
+			}
+		})
+	}
+}
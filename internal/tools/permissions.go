@@ -7,6 +7,7 @@ import (
 	"sync"
 
 	"github.com/samsaffron/term-llm/internal/appdata"
+	"github.com/samsaffron/term-llm/internal/pathutil"
 )
 
 // ToolPermissions manages allowlists for tool access.
@@ -202,7 +203,7 @@ func (p *ToolPermissions) isPathInDirs(resolvedPath string, dirs []string) bool
 			resolvedDir = absDir
 		}
 
-		if strings.HasPrefix(resolvedPath, resolvedDir+string(filepath.Separator)) || resolvedPath == resolvedDir {
+		if pathutil.IsWithin(resolvedPath, resolvedDir) {
 			return true
 		}
 	}
@@ -229,7 +230,7 @@ func canonicalizePath(path string) (string, error) {
 }
 
 // canonicalizePathForWrite resolves a path for write operations.
-// If the file doesn't exist, it resolves the parent directory.
+// If the file doesn't exist, it resolves the part of the path that does.
 func canonicalizePathForWrite(path string) (string, error) {
 	abs, err := filepath.Abs(path)
 	if err != nil {
@@ -240,8 +241,7 @@ func canonicalizePathForWrite(path string) (string, error) {
 	resolved, err := filepath.EvalSymlinks(abs)
 	if err != nil {
 		if os.IsNotExist(err) {
-			// File doesn't exist - resolve parent
-			return resolveParentSymlinks(abs)
+			return resolveMissingPath(abs)
 		}
 		return "", NewToolErrorf(ErrInvalidParams, "cannot evaluate symlinks: %v", err)
 	}
@@ -249,22 +249,34 @@ func canonicalizePathForWrite(path string) (string, error) {
 	return filepath.Clean(resolved), nil
 }
 
-// resolveParentSymlinks resolves symlinks in the parent directory.
-func resolveParentSymlinks(abs string) (string, error) {
-	parent := filepath.Dir(abs)
-	filename := filepath.Base(abs)
+// ResolveApprovalPath returns path the way approval checks see it: absolute,
+// with symlinks resolved even when the path doesn't exist yet.
+func ResolveApprovalPath(path string) (string, error) {
+	return canonicalizePathForWrite(path)
+}
 
-	resolvedParent, err := filepath.EvalSymlinks(parent)
-	if err != nil {
-		if os.IsNotExist(err) {
-			// Parent doesn't exist either - this is fine for write operations
-			// that will create directories
+// resolveMissingPath resolves symlinks in the deepest existing ancestor of a
+// path that doesn't exist, so a new file several directories below a symlink
+// is attributed to the link's target rather than to the link's directory.
+// A dangling symlink is kept as is; writes replace it rather than follow it.
+func resolveMissingPath(abs string) (string, error) {
+	dir, rest := filepath.Dir(abs), filepath.Base(abs)
+	for {
+		resolved, err := filepath.EvalSymlinks(dir)
+		if err == nil {
+			return filepath.Join(resolved, rest), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", NewToolErrorf(ErrInvalidParams, "cannot evaluate parent symlinks: %v", err)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			// Nothing exists - fine for writes that create directories.
 			return abs, nil
 		}
-		return "", NewToolErrorf(ErrInvalidParams, "cannot evaluate parent symlinks: %v", err)
+		rest = filepath.Join(filepath.Base(dir), rest)
+		dir = parent
 	}
-
-	return filepath.Join(resolvedParent, filename), nil
 }
 
 // ExtractCommandPrefix extracts a shell command prefix for policy learning.
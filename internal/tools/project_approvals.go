@@ -9,6 +9,8 @@ import (
 	"unicode"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/samsaffron/term-llm/internal/pathutil"
 )
 
 const approvalRepoIDPrefixLen = 16
@@ -31,15 +33,11 @@ type ProjectApprovals struct {
 
 // getProjectsDir returns the directory for storing project approvals.
 func getProjectsDir() (string, error) {
-	configDir := os.Getenv("XDG_CONFIG_HOME")
-	if configDir == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return "", err
-		}
-		configDir = filepath.Join(home, ".config")
+	configDir, err := pathutil.ConfigDir()
+	if err != nil {
+		return "", err
 	}
-	return filepath.Join(configDir, "term-llm", "projects"), nil
+	return filepath.Join(configDir, "projects"), nil
 }
 
 func projectApprovalsFilePath(repoRoot string) (string, error) {
@@ -250,7 +248,7 @@ func (p *ProjectApprovals) IsPathApproved(path string, isWrite bool) bool {
 	relPath := GetRelativePath(resolvedPath, p.RepoRoot)
 	for _, approved := range p.ApprovedPaths {
 		// Check exact match or if path is under approved directory
-		if relPath == approved || strings.HasPrefix(relPath, approved+string(filepath.Separator)) {
+		if pathutil.IsWithin(relPath, approved) {
 			return true
 		}
 	}
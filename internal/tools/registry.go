@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"sync"
@@ -163,6 +164,8 @@ func (r *LocalToolRegistry) registerTool(specName string) error {
 		tool = NewGrepTool(r.approval, r.limits, r.config)
 	case GlobToolName:
 		tool = NewGlobTool(r.approval, r.config)
+	case WebFetchToolName:
+		tool = NewWebFetchTool(r.approval, r.appConfig)
 	case ViewImageToolName:
 		tool = NewViewImageTool(r.approval, r.config)
 	case ShowImageToolName:
@@ -221,14 +224,19 @@ func stringSliceContains(values []string, needle string) bool {
 	return false
 }
 
-// RegisterWithEngine registers all enabled tools with the LLM engine.
-func (r *LocalToolRegistry) RegisterWithEngine(engine *llm.Engine) {
+// RegisterWithEngine registers all enabled tools with the LLM engine. Tools
+// the engine rejects are skipped; the returned error names each of them.
+func (r *LocalToolRegistry) RegisterWithEngine(engine *llm.Engine) error {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	var errs []error
 	for _, tool := range r.tools {
-		engine.RegisterTool(tool)
+		if err := engine.RegisterTool(tool); err != nil {
+			errs = append(errs, err)
+		}
 	}
+	return errors.Join(errs...)
 }
 
 // SetPlanStore wires durable latest-snapshot persistence only when update_plan
@@ -426,9 +434,11 @@ func (m *ToolManager) BaseDir() string {
 	return m.Registry.BaseDir()
 }
 
-// SetupEngine registers tools with the engine.
-func (m *ToolManager) SetupEngine(engine *llm.Engine) {
-	m.Registry.RegisterWithEngine(engine)
+// SetupEngine registers tools with the engine and lets approval prompts
+// pause its tool timeouts.
+func (m *ToolManager) SetupEngine(engine *llm.Engine) error {
+	m.ApprovalMgr.SetToolTimeoutPauser(engine.PauseToolTimeouts)
+	return m.Registry.RegisterWithEngine(engine)
 }
 
 // GetSpecs returns all tool specs for the request.
@@ -436,6 +446,30 @@ func (m *ToolManager) GetSpecs() []llm.ToolSpec {
 	return m.Registry.GetSpecs()
 }
 
+// SpecsFor returns the tool specs permitted by filter.
+func (m *ToolManager) SpecsFor(filter llm.ToolFilter) []llm.ToolSpec {
+	return filter.FilterSpecs(m.GetSpecs())
+}
+
+// ForgetSession drops per-session tool state, such as the shell's working
+// directory, once sessionID has ended.
+func (m *ToolManager) ForgetSession(sessionID string) {
+	if m == nil || m.Registry == nil {
+		return
+	}
+	m.Registry.ForgetSession(sessionID)
+}
+
+// ForgetSession drops per-session tool state kept for sessionID.
+func (r *LocalToolRegistry) ForgetSession(sessionID string) {
+	r.mu.RLock()
+	tool := r.tools[ShellToolName]
+	r.mu.RUnlock()
+	if st, ok := tool.(*ShellTool); ok {
+		st.ForgetSession(sessionID)
+	}
+}
+
 // GetSpawnAgentTool returns the spawn_agent tool if enabled, for runner configuration.
 func (m *ToolManager) GetSpawnAgentTool() *SpawnAgentTool {
 	return m.Registry.GetSpawnAgentTool()
@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+// builtinToolSpecs collects the specs of every first-party tool: everything the
+// local registry can enable plus the tools wired up outside it.
+func builtinToolSpecs(t *testing.T) []llm.ToolSpec {
+	t.Helper()
+	cfg := DefaultToolConfig()
+	cfg.Enabled = ValidToolNames()
+	registry, err := NewLocalToolRegistry(&cfg, nil, nil)
+	if err != nil {
+		t.Fatalf("NewLocalToolRegistry: %v", err)
+	}
+	specs := registry.GetSpecs()
+	for _, tool := range []llm.Tool{
+		NewSetOutputTool("set_commit_message", "message", "Set the commit message", nil),
+		NewSetOutputTool("set_review", "", "Record the review", map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"verdict":  map[string]interface{}{"type": "string", "enum": []interface{}{"approve", "reject"}},
+				"comments": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+			},
+			"required": []interface{}{"verdict"},
+		}),
+		NewActivateSkillTool(nil, nil),
+		NewSearchSkillsTool(nil),
+		NewCreateGoalTool(),
+		NewUpdateGoalTool(),
+		NewGetGoalTool(nil),
+		NewUpdateProgressTool(),
+		NewFinalizeProgressTool(),
+		llm.NewWebSearchTool(nil),
+		llm.NewReadURLTool(),
+	} {
+		specs = append(specs, tool.Spec())
+	}
+	return append(specs, llm.EditToolSpec(), llm.UnifiedDiffToolSpec(), llm.SuggestCommandsToolSpec(3))
+}
+
+func TestBuiltinToolSchemasConformToEveryDialect(t *testing.T) {
+	for _, spec := range builtinToolSpecs(t) {
+		t.Run(spec.Name, func(t *testing.T) {
+			if err := llm.ValidateToolSpec(spec); err != nil {
+				t.Fatal(err)
+			}
+			for _, dialect := range llm.ToolSchemaDialects() {
+				lowered := llm.ToolSchemaFor(spec.Schema, dialect)
+				raw, err := json.Marshal(lowered)
+				if err != nil {
+					t.Fatalf("%s: marshal: %v", dialect, err)
+				}
+				var decoded map[string]interface{}
+				if err := json.Unmarshal(raw, &decoded); err != nil {
+					t.Fatalf("%s: unmarshal: %v", dialect, err)
+				}
+				if err := llm.CheckToolSchemaDialect(decoded, dialect); err != nil {
+					t.Fatalf("%s: %v\nschema: %s", dialect, err, raw)
+				}
+			}
+
+			// The canonical form is a fixed point: lowering it again, even
+			// after a trip through JSON, changes nothing.
+			canonical := llm.ToolSchemaFor(spec.Schema, llm.ToolSchemaCanonical)
+			raw, _ := json.Marshal(canonical)
+			var decoded map[string]interface{}
+			if err := json.Unmarshal(raw, &decoded); err != nil {
+				t.Fatalf("unmarshal canonical: %v", err)
+			}
+			again, _ := json.Marshal(llm.ToolSchemaFor(decoded, llm.ToolSchemaCanonical))
+			var want, got interface{}
+			_ = json.Unmarshal(raw, &want)
+			_ = json.Unmarshal(again, &got)
+			if !reflect.DeepEqual(want, got) {
+				t.Fatalf("canonical schema is not stable:\nfirst:  %s\nsecond: %s", raw, again)
+			}
+		})
+	}
+}
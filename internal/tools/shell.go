@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/procutil"
 )
 
 // ShellTool implements the shell tool.
@@ -22,6 +23,7 @@ type ShellTool struct {
 	limits    OutputLimits
 	shellPath string
 	recorder  FileChangeRecorder
+	sessions  shellSessions
 }
 
 func shellApprovalTranscriptFromContext(ctx context.Context) []TranscriptEntry {
@@ -126,7 +128,7 @@ func NewShellTool(approval *ApprovalManager, config *ToolConfig, limits OutputLi
 		approval:  approval,
 		config:    config,
 		limits:    limits,
-		shellPath: detectShell(),
+		shellPath: detectShell(config),
 	}
 }
 
@@ -175,6 +177,7 @@ type ShellArgs struct {
 
 // ShellResult contains the result of a shell command.
 type ShellResult struct {
+	WorkingDir      string `json:"working_dir,omitempty"`
 	Stdout          string `json:"stdout"`
 	Stderr          string `json:"stderr"`
 	ExitCode        int    `json:"exit_code"`
@@ -186,7 +189,7 @@ type ShellResult struct {
 func (t *ShellTool) Spec() llm.ToolSpec {
 	return llm.ToolSpec{
 		Name:        ShellToolName,
-		Description: "Execute a shell command. Returns stdout, stderr, and exit code.",
+		Description: "Execute a shell command. Returns the working directory, stdout, stderr, and exit code. The working directory left by cd and variables set with export carry over to later calls in this session; working_dir and env apply to one call only.",
 		Schema: map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
@@ -196,7 +199,7 @@ func (t *ShellTool) Spec() llm.ToolSpec {
 				},
 				"working_dir": map[string]interface{}{
 					"type":        "string",
-					"description": "Working directory (defaults to current directory)",
+					"description": "Working directory for this call only (defaults to the directory the previous command finished in)",
 				},
 				"timeout_seconds": map[string]interface{}{
 					"type":        "integer",
@@ -245,6 +248,13 @@ func (t *ShellTool) Preview(args json.RawMessage) string {
 	return cmd
 }
 
+// ForgetSession drops the working directory and exported variables kept for
+// sessionID. Call it when the session ends so long-running servers do not
+// accumulate state for every session they have served.
+func (t *ShellTool) ForgetSession(sessionID string) {
+	t.sessions.forget(sessionID)
+}
+
 func (t *ShellTool) Execute(ctx context.Context, args json.RawMessage) (llm.ToolOutput, error) {
 	warning := WarnUnknownParams(args, []string{"command", "working_dir", "timeout_seconds", "description", "env", "affected_paths"})
 	textOutput := func(message string) llm.ToolOutput {
@@ -269,6 +279,10 @@ func (t *ShellTool) Execute(ctx context.Context, args json.RawMessage) (llm.Tool
 	// are scoped to the same directory exec.Cmd.Dir will use. Precedence:
 	// explicit working_dir (resolved against BaseDir), then ShellWorkingDir, then
 	// BaseDir, then process cwd.
+	// Without working_dir, the directory the session's previous command
+	// finished in comes before ShellWorkingDir.
+	sessionID := llm.SessionIDFromContext(ctx)
+	state := t.sessions.get(sessionID)
 	workDir := ""
 	if a.WorkingDir != "" {
 		if t.config != nil {
@@ -276,18 +290,37 @@ func (t *ShellTool) Execute(ctx context.Context, args json.RawMessage) (llm.Tool
 		} else {
 			workDir = resolvePathAgainstBase(a.WorkingDir, "")
 		}
-	} else if t.config != nil {
-		workDir = t.config.ShellDir()
 	} else {
-		var err error
-		workDir, err = os.Getwd()
-		if err != nil {
-			return errorOutput(formatToolError(NewToolErrorf(ErrExecutionFailed, "cannot get working directory: %v", err))), nil
+		defaultDir := ""
+		if t.config != nil {
+			defaultDir = t.config.ShellDir()
+		} else {
+			var err error
+			defaultDir, err = os.Getwd()
+			if err != nil {
+				return errorOutput(formatToolError(NewToolErrorf(ErrExecutionFailed, "cannot get working directory: %v", err))), nil
+			}
+		}
+		workDir = defaultDir
+		if state.cwd != "" {
+			if info, err := os.Stat(state.cwd); err != nil || !info.IsDir() {
+				t.sessions.resetCwd(sessionID)
+			} else if toolErr := t.checkSessionDir(state.cwd, defaultDir); toolErr != nil {
+				// Fall back to the default directory on the next call rather
+				// than asking again for every command.
+				t.sessions.resetCwd(sessionID)
+				return errorOutput(formatToolError(toolErr)), nil
+			} else {
+				workDir = state.cwd
+			}
 		}
 	}
 
 	// Strip leading "cd <dir> && " and fold into WorkingDir so that
 	// the approval prompt shows only the real command, not the cd prefix.
+	// startDir is where the command would have run without the cd; a
+	// folded cd still has to carry over to the next call.
+	startDir := workDir
 	a.Command, workDir = extractLeadingCd(a.Command, workDir)
 
 	// Check permissions — pass both command and working directory so the
@@ -335,13 +368,33 @@ func (t *ShellTool) Execute(ctx context.Context, args json.RawMessage) (llm.Tool
 	execCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(execCtx, t.shellPath, "-c", a.Command)
-	cmd.Dir = workDir
-	overrides := make(map[string]struct{}, len(a.Env))
-	for key := range a.Env {
-		overrides[key] = struct{}{}
+	// Variables exported earlier in the session apply first; per-call env
+	// overrides them.
+	overrides := make(map[string]string, len(state.env)+len(a.Env))
+	for key, value := range state.env {
+		overrides[key] = value
+	}
+	for key, value := range a.Env {
+		overrides[key] = value
+	}
+
+	// On POSIX shells the command runs under a wrapper that reports where it
+	// finished and what it exported, so the next call can continue from there.
+	command := a.Command
+	stateFile := ""
+	if shellSupportsStateWrapper(t.shellPath) {
+		if f, err := os.CreateTemp("", "term-llm-shell-*"); err == nil {
+			stateFile = f.Name()
+			_ = f.Close()
+			defer os.Remove(stateFile)
+			command = wrapShellCommand(a.Command)
+			overrides[shellStateEnvVar] = stateFile
+		}
 	}
-	cmd.Env = make([]string, 0, len(os.Environ())+len(a.Env))
+
+	cmd := procutil.ShellCommand(execCtx, t.shellPath, command)
+	cmd.Dir = workDir
+	cmd.Env = make([]string, 0, len(os.Environ())+len(overrides))
 	for _, e := range os.Environ() {
 		if k, _, ok := strings.Cut(e, "="); ok {
 			if _, shadowed := overrides[k]; shadowed {
@@ -350,7 +403,7 @@ func (t *ShellTool) Execute(ctx context.Context, args json.RawMessage) (llm.Tool
 		}
 		cmd.Env = append(cmd.Env, e)
 	}
-	for key, value := range a.Env {
+	for key, value := range overrides {
 		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
 	}
 
@@ -359,6 +412,7 @@ func (t *ShellTool) Execute(ctx context.Context, args json.RawMessage) (llm.Tool
 		return errorOutput(formatToolError(NewToolErrorf(ErrExecutionFailed, "command setup error: %v", prepErr))), nil
 	}
 	defer cleanup()
+	launchedEnv := envSliceToMap(cmd.Env)
 
 	stdout := newLimitedBuffer(t.limits.MaxBytes)
 	stderr := newLimitedBuffer(t.limits.MaxBytes)
@@ -375,7 +429,26 @@ func (t *ShellTool) Execute(ctx context.Context, args json.RawMessage) (llm.Tool
 	// are real changes.
 	fileChanges := postShellChanges(ctx, t.recorder, snap)
 
+	if stateFile != "" && execCtx.Err() == nil {
+		if data, readErr := os.ReadFile(stateFile); readErr == nil {
+			if cwd, env, ok := parseShellState(data); ok {
+				next := shellSession{cwd: state.cwd, env: state.env}
+				if !samePath(cwd, startDir) {
+					next.cwd = cwd
+				}
+				if !samePath(cwd, workDir) {
+					workDir = cwd
+				}
+				if env != nil {
+					next.env = nextShellEnv(state.env, launchedEnv, env)
+				}
+				t.sessions.set(sessionID, next)
+			}
+		}
+	}
+
 	result := ShellResult{
+		WorkingDir:      workDir,
 		Stdout:          stdout.String(),
 		Stderr:          stderr.String(),
 		ExitCode:        0,
@@ -407,6 +480,26 @@ func (t *ShellTool) Execute(ctx context.Context, args json.RawMessage) (llm.Tool
 	return output, nil
 }
 
+// checkSessionDir asks for read access to dir when a previous command left
+// the session outside defaultDir. It returns nil when the directory may be
+// used.
+func (t *ShellTool) checkSessionDir(dir, defaultDir string) *ToolError {
+	if t.approval == nil || pathWithinDir(dir, defaultDir) {
+		return nil
+	}
+	outcome, err := t.approval.CheckPathApproval(ShellToolName, dir, dir, false)
+	if err != nil {
+		if toolErr, ok := err.(*ToolError); ok {
+			return toolErr
+		}
+		return NewToolError(ErrPermissionDenied, err.Error())
+	}
+	if outcome == Cancel {
+		return NewToolErrorf(ErrPermissionDenied, "working directory %s is outside approved directories; the next command runs in %s", dir, defaultDir)
+	}
+	return nil
+}
+
 // formatShellResult formats the shell result for the LLM.
 func formatShellResult(result ShellResult, limits OutputLimits) string {
 	var sb strings.Builder
@@ -429,6 +522,12 @@ func formatShellResult(result ShellResult, limits OutputLimits) string {
 		sb.WriteString("[Command timed out]\n\n")
 	}
 
+	if result.WorkingDir != "" {
+		sb.WriteString("cwd: ")
+		sb.WriteString(result.WorkingDir)
+		sb.WriteString("\n\n")
+	}
+
 	if stdout != "" {
 		sb.WriteString("stdout:\n")
 		sb.WriteString(stdout)
@@ -457,14 +556,12 @@ func formatShellResult(result ShellResult, limits OutputLimits) string {
 	return sb.String()
 }
 
-// detectShell returns the user's shell.
-func detectShell() string {
-	shell := os.Getenv("SHELL")
-	if shell == "" {
-		return "bash"
+// detectShell returns the configured shell, falling back to the user's shell.
+func detectShell(config *ToolConfig) string {
+	if config != nil && strings.TrimSpace(config.Shell) != "" {
+		return strings.TrimSpace(config.Shell)
 	}
-	// Use full path for execution
-	return shell
+	return procutil.DefaultShell("bash")
 }
 
 // expandTilde resolves a tilde prefix in a path.
@@ -0,0 +1,181 @@
+package tools
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/samsaffron/term-llm/internal/procutil"
+)
+
+// shellStateEnvVar names the file the shell wrapper writes its final working
+// directory and environment to.
+const shellStateEnvVar = "TERM_LLM_SHELL_STATE"
+
+// shellStateIgnoredEnv are variables the shell maintains itself; they never
+// carry over between calls.
+var shellStateIgnoredEnv = map[string]bool{
+	"PWD":            true,
+	"OLDPWD":         true,
+	"SHLVL":          true,
+	"_":              true,
+	shellStateEnvVar: true,
+}
+
+// shellSession is what the shell tool remembers between calls in one chat
+// session: the directory the last command finished in and the variables
+// commands have exported. Explicit working_dir and env arguments apply to a
+// single call and are not remembered.
+type shellSession struct {
+	cwd string
+	env map[string]string
+}
+
+// shellSessions holds shellSession state keyed by session ID. A new session
+// ID (after /clear or /new) starts from the configured defaults.
+type shellSessions struct {
+	mu       sync.Mutex
+	sessions map[string]*shellSession
+}
+
+func (s *shellSessions) get(sessionID string) shellSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.sessions[sessionID]
+	if state == nil {
+		return shellSession{}
+	}
+	env := make(map[string]string, len(state.env))
+	for k, v := range state.env {
+		env[k] = v
+	}
+	return shellSession{cwd: state.cwd, env: env}
+}
+
+func (s *shellSessions) set(sessionID string, state shellSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessions == nil {
+		s.sessions = make(map[string]*shellSession)
+	}
+	s.sessions[sessionID] = &state
+}
+
+// forget drops everything remembered for sessionID.
+func (s *shellSessions) forget(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, sessionID)
+}
+
+func (s *shellSessions) resetCwd(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if state := s.sessions[sessionID]; state != nil {
+		state.cwd = ""
+	}
+}
+
+// shellSupportsStateWrapper reports whether shellPath is a POSIX-style shell
+// that understands the EXIT trap used by wrapShellCommand.
+func shellSupportsStateWrapper(shellPath string) bool {
+	switch procutil.ShellName(shellPath) {
+	case "sh", "bash", "zsh", "dash", "ksh", "mksh", "ash":
+		return true
+	default:
+		return false
+	}
+}
+
+// wrapShellCommand runs command with an EXIT trap that records the final
+// working directory and environment to the file named by shellStateEnvVar,
+// preserving the command's exit status. Commands that replace the shell with
+// exec leave the file empty and the session state unchanged.
+func wrapShellCommand(command string) string {
+	return `trap '__term_llm_status=$?; { pwd; printf "\000"; env -0; } >"$` + shellStateEnvVar + `" 2>/dev/null; exit $__term_llm_status' EXIT` + "\n" + command
+}
+
+// parseShellState parses the state file written by wrapShellCommand. ok is
+// false when the shell exited without running the trap. env is nil when env
+// -0 is unavailable, in which case exported variables are not tracked.
+func parseShellState(data []byte) (cwd string, env map[string]string, ok bool) {
+	dir, rest, found := bytes.Cut(data, []byte{0})
+	if !found {
+		return "", nil, false
+	}
+	cwd = strings.TrimSuffix(string(dir), "\n")
+	if cwd == "" {
+		return "", nil, false
+	}
+	if len(rest) == 0 {
+		return cwd, nil, true
+	}
+	env = make(map[string]string)
+	for _, entry := range bytes.Split(rest, []byte{0}) {
+		if k, v, ok := strings.Cut(string(entry), "="); ok && k != "" {
+			env[k] = v
+		}
+	}
+	return cwd, env, true
+}
+
+// envSliceToMap converts KEY=value entries; later entries win, as in exec.
+func envSliceToMap(entries []string) map[string]string {
+	m := make(map[string]string, len(entries))
+	for _, e := range entries {
+		if k, v, ok := strings.Cut(e, "="); ok {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+// nextShellEnv returns the persisted variables after a command that started
+// with launched and finished with final. Variables the command set or changed
+// are added, and previously persisted variables it unset are dropped.
+func nextShellEnv(prev, launched, final map[string]string) map[string]string {
+	next := make(map[string]string, len(prev))
+	for k, v := range prev {
+		if _, still := final[k]; still {
+			next[k] = v
+		}
+	}
+	for k, v := range final {
+		if shellStateIgnoredEnv[k] {
+			continue
+		}
+		if old, ok := launched[k]; ok && old == v {
+			continue
+		}
+		next[k] = v
+	}
+	return next
+}
+
+// samePath reports whether a and b name the same directory, following
+// symlinks so that a shell's logical pwd matches the directory it started in.
+func samePath(a, b string) bool {
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(ai, bi)
+}
+
+// pathWithinDir reports whether path is dir or below it.
+func pathWithinDir(path, dir string) bool {
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
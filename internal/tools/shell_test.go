@@ -9,6 +9,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/samsaffron/term-llm/internal/llm"
 )
 
 func TestShellTool_Spec(t *testing.T) {
@@ -228,7 +230,7 @@ func TestShellTool_WorkingDir(t *testing.T) {
 	}
 
 	text := output.Content
-	if !strings.Contains(text, dir) {
+	if !strings.Contains(text, "stdout:\n"+dir) {
 		t.Errorf("expected working dir %q in output, got: %s", dir, text)
 	}
 	if !strings.Contains(text, "exit_code: 0") {
@@ -251,7 +253,7 @@ func TestShellTool_DefaultWorkingDirFromConfig(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Execute returned error: %v", err)
 		}
-		if !strings.Contains(output.Content, rootDir) {
+		if !strings.Contains(output.Content, "stdout:\n"+rootDir) {
 			t.Errorf("expected shell rooted at config dir %q, got: %s", rootDir, output.Content)
 		}
 		if !strings.Contains(output.Content, "exit_code: 0") {
@@ -268,7 +270,7 @@ func TestShellTool_DefaultWorkingDirFromConfig(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Execute returned error: %v", err)
 		}
-		if !strings.Contains(output.Content, callDir) {
+		if !strings.Contains(output.Content, "stdout:\n"+callDir) {
 			t.Errorf("expected explicit working_dir %q to win over config dir, got: %s", callDir, output.Content)
 		}
 	})
@@ -721,7 +723,7 @@ func TestShellTool_ExecuteCdPrefix(t *testing.T) {
 	}
 
 	text := output.Content
-	if !strings.Contains(text, dir) {
+	if !strings.Contains(text, "stdout:\n"+dir) {
 		t.Errorf("expected output to contain %q (from cd extraction), got: %s", dir, text)
 	}
 	if !strings.Contains(text, "exit_code: 0") {
@@ -729,6 +731,176 @@ func TestShellTool_ExecuteCdPrefix(t *testing.T) {
 	}
 }
 
+func TestShellTool_SessionKeepsWorkingDir(t *testing.T) {
+	rootDir := t.TempDir()
+	subDir := filepath.Join(rootDir, "sub")
+	if err := os.Mkdir(subDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tool := NewShellTool(nil, &ToolConfig{ShellWorkingDir: rootDir}, DefaultOutputLimits())
+	tool.shellPath = "sh"
+	ctx := llm.ContextWithSessionID(context.Background(), "sess-a")
+
+	if _, err := tool.Execute(ctx, mustMarshalShellArgs(ShellArgs{Command: "cd sub"})); err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	output, err := tool.Execute(ctx, mustMarshalShellArgs(ShellArgs{Command: "pwd"}))
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if !strings.HasPrefix(output.Content, "cwd: "+subDir+"\n") || !strings.Contains(output.Content, "stdout:\n"+subDir) {
+		t.Fatalf("expected second call to run in %q, got: %s", subDir, output.Content)
+	}
+
+	// An explicit working_dir applies to one call only.
+	output, err = tool.Execute(ctx, mustMarshalShellArgs(ShellArgs{Command: "pwd", WorkingDir: rootDir}))
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if !strings.Contains(output.Content, "stdout:\n"+rootDir+"\n") {
+		t.Fatalf("expected explicit working_dir %q, got: %s", rootDir, output.Content)
+	}
+	output, err = tool.Execute(ctx, mustMarshalShellArgs(ShellArgs{Command: "pwd"}))
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if !strings.Contains(output.Content, "stdout:\n"+subDir) {
+		t.Fatalf("expected session directory %q after one-off working_dir, got: %s", subDir, output.Content)
+	}
+
+	// A different session (e.g. after /clear) starts from the default.
+	other := llm.ContextWithSessionID(context.Background(), "sess-b")
+	output, err = tool.Execute(other, mustMarshalShellArgs(ShellArgs{Command: "pwd"}))
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if !strings.Contains(output.Content, "stdout:\n"+rootDir+"\n") {
+		t.Fatalf("expected new session to start in %q, got: %s", rootDir, output.Content)
+	}
+}
+
+func TestShellTool_SessionKeepsFoldedCdPrefix(t *testing.T) {
+	rootDir := t.TempDir()
+	buildDir := filepath.Join(rootDir, "build")
+	if err := os.Mkdir(buildDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(buildDir, "run"), []byte("#!/bin/sh\necho ran\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tool := NewShellTool(nil, &ToolConfig{ShellWorkingDir: rootDir}, DefaultOutputLimits())
+	tool.shellPath = "sh"
+	ctx := llm.ContextWithSessionID(context.Background(), "sess-cd")
+
+	// The leading cd is folded into the working directory before the
+	// command runs, but it must still carry over to the next call.
+	if _, err := tool.Execute(ctx, mustMarshalShellArgs(ShellArgs{Command: "cd build && true"})); err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	output, err := tool.Execute(ctx, mustMarshalShellArgs(ShellArgs{Command: "./run"}))
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if !strings.HasPrefix(output.Content, "cwd: "+buildDir+"\n") || !strings.Contains(output.Content, "stdout:\nran") {
+		t.Fatalf("expected ./run to run in %q, got: %s", buildDir, output.Content)
+	}
+
+	tool.ForgetSession("sess-cd")
+	output, err = tool.Execute(ctx, mustMarshalShellArgs(ShellArgs{Command: "pwd"}))
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if !strings.Contains(output.Content, "stdout:\n"+rootDir+"\n") {
+		t.Fatalf("expected a forgotten session to start in %q, got: %s", rootDir, output.Content)
+	}
+}
+
+func TestShellTool_SessionKeepsExportedEnv(t *testing.T) {
+	tool := NewShellTool(nil, nil, DefaultOutputLimits())
+	tool.shellPath = "sh"
+	ctx := llm.ContextWithSessionID(context.Background(), "sess-env")
+
+	t.Setenv("TL_INHERITED", "base")
+	if _, err := tool.Execute(ctx, mustMarshalShellArgs(ShellArgs{
+		Command: "export TL_KEPT=kept TL_INHERITED=changed",
+		Env:     EnvMap{"TL_ONCE": "once"},
+	})); err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	output, err := tool.Execute(ctx, mustMarshalShellArgs(ShellArgs{Command: `echo "$TL_KEPT/$TL_INHERITED/${TL_ONCE:-unset}"`}))
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if !strings.Contains(output.Content, "stdout:\nkept/changed/unset\n") {
+		t.Fatalf("expected exported variables to persist and per-call env not to, got: %s", output.Content)
+	}
+
+	if _, err := tool.Execute(ctx, mustMarshalShellArgs(ShellArgs{Command: "unset TL_KEPT"})); err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	output, err = tool.Execute(ctx, mustMarshalShellArgs(ShellArgs{Command: `echo "${TL_KEPT:-gone}"`}))
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if !strings.Contains(output.Content, "stdout:\ngone\n") {
+		t.Fatalf("expected unset variable to be dropped, got: %s", output.Content)
+	}
+
+	// Exit status is preserved by the wrapper.
+	output, err = tool.Execute(ctx, mustMarshalShellArgs(ShellArgs{Command: "exit 3"}))
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if !strings.Contains(output.Content, "exit_code: 3") {
+		t.Fatalf("expected exit_code: 3, got: %s", output.Content)
+	}
+}
+
+func TestShellTool_SessionDirOutsideApprovedDirsNeedsApproval(t *testing.T) {
+	rootDir := t.TempDir()
+	outside := t.TempDir()
+	perms := NewToolPermissions()
+	if err := perms.AddShellPattern("*"); err != nil {
+		t.Fatal(err)
+	}
+	if err := perms.AddReadDir(rootDir); err != nil {
+		t.Fatal(err)
+	}
+	mgr := NewApprovalManager(perms)
+	mgr.IgnoreProjectApprovals = true
+	var prompted []string
+	mgr.PromptFunc = func(req *ApprovalRequest) (ConfirmOutcome, string) {
+		prompted = append(prompted, req.Path)
+		return Cancel, ""
+	}
+	tool := NewShellTool(mgr, &ToolConfig{ShellWorkingDir: rootDir}, DefaultOutputLimits())
+	tool.shellPath = "sh"
+	ctx := llm.ContextWithSessionID(context.Background(), "sess-escape")
+
+	if _, err := tool.Execute(ctx, mustMarshalShellArgs(ShellArgs{Command: "cd " + outside})); err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	output, err := tool.Execute(ctx, mustMarshalShellArgs(ShellArgs{Command: "pwd"}))
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if !output.IsError || !strings.Contains(output.Content, "outside approved directories") {
+		t.Fatalf("expected denial for directory outside approved dirs, got: %s", output.Content)
+	}
+	if len(prompted) != 1 || prompted[0] != outside {
+		t.Fatalf("prompted for %v, want [%s]", prompted, outside)
+	}
+
+	// After a denial the session falls back to the default directory.
+	output, err = tool.Execute(ctx, mustMarshalShellArgs(ShellArgs{Command: "pwd"}))
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if !strings.Contains(output.Content, "stdout:\n"+rootDir+"\n") {
+		t.Fatalf("expected fallback to %q, got: %s", rootDir, output.Content)
+	}
+}
+
 func mustMarshalShellArgs(args ShellArgs) json.RawMessage {
 	data, err := json.Marshal(args)
 	if err != nil {
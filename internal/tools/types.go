@@ -123,6 +123,7 @@ const (
 	ShellToolName            = "shell"
 	GrepToolName             = "grep"
 	GlobToolName             = "glob"
+	WebFetchToolName         = "web_fetch"
 	ViewImageToolName        = "view_image"
 	ShowImageToolName        = "show_image"
 	ImageGenerateToolName    = "image_generate"
@@ -152,6 +153,7 @@ func StandardToolNames() []string {
 		ShellToolName,
 		GrepToolName,
 		GlobToolName,
+		WebFetchToolName,
 		ViewImageToolName,
 		ShowImageToolName,
 		ImageGenerateToolName,
@@ -198,6 +200,7 @@ var validToolNames = map[string]bool{
 	ShellToolName:              true,
 	GrepToolName:               true,
 	GlobToolName:               true,
+	WebFetchToolName:           true,
 	ViewImageToolName:          true,
 	ShowImageToolName:          true,
 	ImageGenerateToolName:      true,
@@ -220,7 +223,7 @@ func ValidToolName(name string) bool {
 // GetToolKind returns the kind for a tool spec name.
 func GetToolKind(specName string) ToolKind {
 	switch specName {
-	case ReadFileToolName, ViewImageToolName:
+	case ReadFileToolName, ViewImageToolName, WebFetchToolName:
 		return KindRead
 	case WriteFileToolName, EditFileToolName, UnifiedDiffToolName:
 		return KindEdit
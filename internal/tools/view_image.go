@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"image"
 	"image/draw"
@@ -17,6 +18,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/samsaffron/term-llm/internal/imageprep"
 	"github.com/samsaffron/term-llm/internal/llm"
 	xdraw "golang.org/x/image/draw"
 	_ "golang.org/x/image/webp" // WebP decode support
@@ -61,7 +63,6 @@ type ViewImageArgs struct {
 
 const (
 	maxImageSize    = 5 * 1024 * 1024 // 5MB - Anthropic API limit
-	maxDimension    = 1568            // Anthropic recommended max for optimal performance
 	maxAbsDimension = 8000            // Anthropic absolute max
 	jpegQuality     = 85              // JPEG quality for re-encoding
 )
@@ -204,7 +205,7 @@ func (t *ViewImageTool) Execute(ctx context.Context, args json.RawMessage) (llm.
 	}
 
 	// Process image: resize if needed, ensure under size limit
-	processedData, processedMime, resized, err := processImage(data, mimeType)
+	processedData, processedMime, resized, err := t.processImage(data, mimeType)
 	if err != nil {
 		return llm.TextOutput(formatToolError(NewToolErrorf(ErrExecutionFailed, "failed to process image: %v", err))), nil
 	}
@@ -423,96 +424,26 @@ func encodeViewedImage(img image.Image, format, fallbackMime string) ([]byte, st
 	}
 }
 
-// processImage checks if an image needs resizing and processes it accordingly.
+// processImage shrinks an image for the model through the same pipeline as
+// ask/chat attachments, capped at the provider size limit.
 // Returns the (possibly resized) image data, mime type, whether it was resized, and any error.
-func processImage(data []byte, originalMime string) ([]byte, string, bool, error) {
-	// Decode image to check dimensions
-	img, format, err := image.Decode(bytes.NewReader(data))
-	if err != nil {
-		return nil, "", false, fmt.Errorf("failed to decode image: %w", err)
-	}
-
-	detectedMime := mimeTypeFromDecodedFormat(format)
-	if detectedMime == "" {
-		detectedMime = originalMime
+func (t *ViewImageTool) processImage(data []byte, originalMime string) ([]byte, string, bool, error) {
+	opts := imageprep.Options{MaxBytes: maxImageSize}
+	if t.config != nil {
+		opts.MaxDimension = t.config.ImageMaxDimension
+		opts.Quality = t.config.ImageQuality
 	}
-
-	bounds := img.Bounds()
-	width := bounds.Dx()
-	height := bounds.Dy()
-
-	// Check if resizing is needed
-	needsResize := width > maxDimension || height > maxDimension || len(data) > maxImageSize
-
-	if !needsResize {
-		return data, detectedMime, false, nil
-	}
-
-	// Calculate new dimensions maintaining aspect ratio
-	newWidth, newHeight := width, height
-	if width > maxDimension || height > maxDimension {
-		if width > height {
-			newWidth = maxDimension
-			newHeight = int(float64(height) * float64(maxDimension) / float64(width))
-		} else {
-			newHeight = maxDimension
-			newWidth = int(float64(width) * float64(maxDimension) / float64(height))
-		}
-	}
-
-	// Resize the image
-	resized := resizeImage(img, newWidth, newHeight)
-
-	// Encode to appropriate format
-	// Use JPEG for most cases (better compression), PNG if original was PNG/GIF (transparency)
-	var buf bytes.Buffer
-	var outputMime string
-
-	switch format {
-	case "png", "gif":
-		// Keep PNG for formats that might have transparency
-		if err := png.Encode(&buf, resized); err != nil {
-			return nil, "", false, fmt.Errorf("failed to encode PNG: %w", err)
-		}
-		outputMime = "image/png"
-	default:
-		// Use JPEG for better compression
-		if err := jpeg.Encode(&buf, resized, &jpeg.Options{Quality: jpegQuality}); err != nil {
-			return nil, "", false, fmt.Errorf("failed to encode JPEG: %w", err)
-		}
-		outputMime = "image/jpeg"
-	}
-
-	result := buf.Bytes()
-
-	// If still too large after resizing, try more aggressive compression
-	if len(result) > maxImageSize {
-		// Try JPEG with lower quality
-		buf.Reset()
-		if err := jpeg.Encode(&buf, resized, &jpeg.Options{Quality: 70}); err != nil {
-			return nil, "", false, fmt.Errorf("failed to encode JPEG: %w", err)
-		}
-		result = buf.Bytes()
-		outputMime = "image/jpeg"
-
-		// If still too large, reduce dimensions further
-		if len(result) > maxImageSize {
-			smallerWidth := newWidth * 3 / 4
-			smallerHeight := newHeight * 3 / 4
-			resized = resizeImage(img, smallerWidth, smallerHeight)
-			buf.Reset()
-			if err := jpeg.Encode(&buf, resized, &jpeg.Options{Quality: 70}); err != nil {
-				return nil, "", false, fmt.Errorf("failed to encode JPEG: %w", err)
-			}
-			result = buf.Bytes()
+	res, err := imageprep.Prepare(data, opts)
+	if err != nil {
+		if errors.Is(err, imageprep.ErrUnsupported) {
+			return nil, "", false, fmt.Errorf("failed to decode image: %w", err)
 		}
+		return nil, "", false, err
 	}
-
-	if len(result) > maxImageSize {
-		return nil, "", false, fmt.Errorf("image still exceeds 5MB after resizing (%d bytes)", len(result))
+	if res.MediaType == "" {
+		res.MediaType = originalMime
 	}
-
-	return result, outputMime, true, nil
+	return res.Data, res.MediaType, res.Changed(), nil
 }
 
 func mimeTypeFromDecodedFormat(format string) string {
@@ -0,0 +1,309 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/html/charset"
+
+	"github.com/samsaffron/term-llm/internal/config"
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+const maxWebFetchRedirects = 10
+
+// webFetchBlockedIP reports whether web_fetch refuses to connect to ip. It
+// shares read_url's blocklist; tests swap it to reach httptest servers.
+var webFetchBlockedIP = llm.IsBlockedFetchIP
+
+// WebFetchTool implements the web_fetch tool: it fetches a URL directly and
+// returns its readable text. HTML is reduced to markdown-like text; other
+// text formats pass through unchanged.
+type WebFetchTool struct {
+	approval *ApprovalManager
+	allowed  []string
+	denied   []string
+	timeout  time.Duration
+	maxBytes int64
+	client   *http.Client
+}
+
+// WebFetchArgs are the arguments for web_fetch.
+type WebFetchArgs struct {
+	URL string `json:"url"`
+}
+
+// NewWebFetchTool creates a WebFetchTool using the tools.web_fetch settings
+// of appConfig. Missing or invalid settings fall back to the defaults.
+func NewWebFetchTool(approval *ApprovalManager, appConfig *config.Config) *WebFetchTool {
+	t := &WebFetchTool{
+		approval: approval,
+		maxBytes: config.DefaultWebFetchMaxBytes,
+	}
+	t.timeout, _ = time.ParseDuration(config.DefaultWebFetchTimeout)
+	if appConfig != nil {
+		cfg := appConfig.Tools.WebFetch
+		t.allowed = normalizeDomains(cfg.AllowedDomains)
+		t.denied = normalizeDomains(cfg.DeniedDomains)
+		if d, err := time.ParseDuration(strings.TrimSpace(cfg.Timeout)); err == nil && d > 0 {
+			t.timeout = d
+		}
+		if cfg.MaxBytes > 0 {
+			t.maxBytes = int64(cfg.MaxBytes)
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Connect directly: through HTTPS_PROXY the dial check below would see
+	// the proxy's address instead of the target's.
+	transport.Proxy = nil
+	dialer := &net.Dialer{Timeout: 30 * time.Second, Control: webFetchDialControl}
+	transport.DialContext = dialer.DialContext
+	t.client = &http.Client{Transport: transport, CheckRedirect: t.checkRedirect}
+	return t
+}
+
+// webFetchDialControl rejects connections to blocked addresses. Checking at
+// dial time covers redirects and DNS answers that change between lookups.
+func webFetchDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip != nil && webFetchBlockedIP(ip) {
+		return fmt.Errorf("address %s is not allowed", host)
+	}
+	return nil
+}
+
+func (t *WebFetchTool) Spec() llm.ToolSpec {
+	return llm.ToolSpec{
+		Name:        WebFetchToolName,
+		Description: "Fetch a URL and return its readable content. HTML pages are converted to markdown-like text (headings, links and code kept; navigation and scripts removed); JSON and plain text are returned as-is. Binary content is rejected.",
+		Schema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"url": map[string]interface{}{
+					"type":        "string",
+					"description": "The http or https URL to fetch",
+				},
+			},
+			"required":             []string{"url"},
+			"additionalProperties": false,
+		},
+	}
+}
+
+func (t *WebFetchTool) Preview(args json.RawMessage) string {
+	var a WebFetchArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return ""
+	}
+	return a.URL
+}
+
+func (t *WebFetchTool) Execute(ctx context.Context, args json.RawMessage) (llm.ToolOutput, error) {
+	warning := WarnUnknownParams(args, []string{"url"})
+	errorOutput := func(err *ToolError) llm.ToolOutput {
+		return llm.TextOutput(warning + formatToolError(err))
+	}
+
+	var a WebFetchArgs
+	if err := json.Unmarshal(args, &a); err != nil {
+		return errorOutput(NewToolError(ErrInvalidParams, err.Error())), nil
+	}
+	target, err := normalizeWebFetchURL(a.URL)
+	if err != nil {
+		return errorOutput(NewToolError(ErrInvalidParams, err.Error())), nil
+	}
+	if err := t.authorize(target); err != nil {
+		return errorOutput(asToolError(err, ErrPermissionDenied)), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return errorOutput(NewToolErrorf(ErrInvalidParams, "invalid url: %v", err)), nil
+	}
+	req.Header.Set("User-Agent", "term-llm web_fetch")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/plain;q=0.9,application/json;q=0.9,*/*;q=0.5")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		var toolErr *ToolError
+		if errors.As(err, &toolErr) {
+			return errorOutput(toolErr), nil
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return errorOutput(NewToolErrorf(ErrTimeout, "fetching %s timed out after %s", target, t.timeout)), nil
+		}
+		return errorOutput(NewToolErrorf(ErrExecutionFailed, "fetch %s: %v", target, err)), nil
+	}
+	defer resp.Body.Close()
+	finalURL := resp.Request.URL
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errorOutput(NewToolErrorf(ErrExecutionFailed, "HTTP %s fetching %s", resp.Status, finalURL)), nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, t.maxBytes+1))
+	if err != nil {
+		return errorOutput(NewToolErrorf(ErrExecutionFailed, "read response from %s: %v", finalURL, err)), nil
+	}
+	truncated := int64(len(body)) > t.maxBytes
+	if truncated {
+		body = body[:t.maxBytes]
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "" || mediaType == "application/octet-stream" {
+		contentType = http.DetectContentType(body)
+		mediaType, _, _ = mime.ParseMediaType(contentType)
+	}
+	isHTML := mediaType == "text/html" || mediaType == "application/xhtml+xml"
+	if !isHTML && !isTextMediaType(mediaType) {
+		return errorOutput(NewToolErrorf(ErrUnsupportedFormat, "%s returned binary content (%s); web_fetch only reads text", finalURL, mediaType)), nil
+	}
+
+	text := decodeWebFetchBody(body, contentType)
+	var out strings.Builder
+	out.WriteString(warning)
+	fmt.Fprintf(&out, "URL: %s\nContent-Type: %s\n", finalURL, mediaType)
+	if isHTML {
+		title, content := htmlToMarkdown(text, finalURL)
+		if title != "" {
+			fmt.Fprintf(&out, "Title: %s\n", title)
+		}
+		text = content
+	}
+	out.WriteString("\n")
+	out.WriteString(strings.TrimSpace(text))
+	if truncated {
+		fmt.Fprintf(&out, "\n\n[Response truncated at %d bytes]", t.maxBytes)
+	}
+	return llm.TextOutput(out.String()), nil
+}
+
+// authorize applies the domain allow/deny lists and asks for approval the
+// first time a domain is fetched in the session.
+func (t *WebFetchTool) authorize(target *url.URL) error {
+	domain := URLDomain(target.String())
+	if matchesDomain(domain, t.denied) {
+		return NewToolErrorf(ErrPermissionDenied, "domain %s is in tools.web_fetch.denied_domains", domain)
+	}
+	if len(t.allowed) > 0 && !matchesDomain(domain, t.allowed) {
+		return NewToolErrorf(ErrPermissionDenied, "domain %s is not in tools.web_fetch.allowed_domains", domain)
+	}
+	if t.approval == nil {
+		return nil
+	}
+	outcome, err := t.approval.CheckURLApproval(WebFetchToolName, target.String())
+	if err != nil {
+		return err
+	}
+	if outcome == Cancel {
+		return NewToolErrorf(ErrPermissionDenied, "fetching from %s was not approved", domain)
+	}
+	return nil
+}
+
+// checkRedirect authorizes each redirect target like the original URL, so a
+// redirect cannot reach a denied or unapproved domain.
+func (t *WebFetchTool) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxWebFetchRedirects {
+		return NewToolErrorf(ErrExecutionFailed, "stopped after %d redirects", maxWebFetchRedirects)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return NewToolErrorf(ErrPermissionDenied, "redirect to unsupported scheme %q", req.URL.Scheme)
+	}
+	if _, err := llm.NormalizeFetchURL(req.URL.String()); err != nil {
+		return NewToolErrorf(ErrPermissionDenied, "redirect to %s: %v", req.URL.Redacted(), err)
+	}
+	if URLDomain(req.URL.String()) == URLDomain(via[len(via)-1].URL.String()) {
+		return nil
+	}
+	return t.authorize(req.URL)
+}
+
+// normalizeWebFetchURL parses rawURL with read_url's rules and drops the
+// fragment, which is never sent.
+func normalizeWebFetchURL(rawURL string) (*url.URL, error) {
+	parsed, err := llm.NormalizeFetchURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	parsed.Fragment = ""
+	return parsed, nil
+}
+
+// normalizeDomains lowercases configured domains and strips "*." and "."
+// prefixes; every entry already matches its subdomains.
+func normalizeDomains(domains []string) []string {
+	var out []string
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimSpace(d))
+		d = strings.TrimPrefix(strings.TrimPrefix(d, "*"), ".")
+		if d != "" {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// matchesDomain reports whether host is one of domains or a subdomain of one.
+func matchesDomain(host string, domains []string) bool {
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+func isTextMediaType(mediaType string) bool {
+	if strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml") {
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript", "application/x-javascript",
+		"application/ecmascript", "application/yaml", "application/x-yaml", "application/toml",
+		"application/x-sh", "application/sql", "application/graphql":
+		return true
+	}
+	return false
+}
+
+// decodeWebFetchBody converts body to UTF-8 using the charset from the
+// Content-Type header or, for HTML, the page's meta tags.
+func decodeWebFetchBody(body []byte, contentType string) string {
+	r, err := charset.NewReader(bytes.NewReader(body), contentType)
+	if err == nil {
+		if decoded, err := io.ReadAll(r); err == nil {
+			body = decoded
+		}
+	}
+	return strings.ToValidUTF8(string(body), "�")
+}
+
+// asToolError returns err as a *ToolError, wrapping other errors with
+// fallback.
+func asToolError(err error, fallback ToolErrorType) *ToolError {
+	var toolErr *ToolError
+	if errors.As(err, &toolErr) {
+		return toolErr
+	}
+	return NewToolError(fallback, err.Error())
+}
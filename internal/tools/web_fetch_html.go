@@ -0,0 +1,392 @@
+package tools
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// webFetchSkipped are elements whose content is page chrome or not text.
+var webFetchSkipped = map[atom.Atom]bool{
+	atom.Head: true, atom.Script: true, atom.Style: true, atom.Noscript: true,
+	atom.Template: true, atom.Svg: true, atom.Nav: true, atom.Footer: true,
+	atom.Aside: true, atom.Form: true, atom.Iframe: true, atom.Button: true,
+	atom.Select: true, atom.Object: true, atom.Canvas: true, atom.Dialog: true,
+}
+
+// webFetchBlocks are elements rendered as separate paragraphs.
+var webFetchBlocks = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true,
+	atom.Main: true, atom.Header: true, atom.Figure: true, atom.Figcaption: true,
+	atom.Dl: true, atom.Dt: true, atom.Dd: true, atom.Details: true, atom.Summary: true,
+	atom.Address: true,
+}
+
+var webFetchHeadings = map[atom.Atom]int{
+	atom.H1: 1, atom.H2: 2, atom.H3: 3, atom.H4: 4, atom.H5: 5, atom.H6: 6,
+}
+
+// htmlToMarkdown extracts the readable content of an HTML page as
+// markdown-like text. Page chrome is dropped, and a single <article> or the
+// <main> element is preferred over the whole body. Relative links are
+// resolved against base.
+func htmlToMarkdown(page string, base *url.URL) (title, text string) {
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		return "", page
+	}
+	if n := findElement(doc, atom.Title); n != nil {
+		title = strings.Join(strings.Fields(nodeText(n)), " ")
+	}
+
+	root := findElement(doc, atom.Body)
+	if articles := findElements(doc, atom.Article); len(articles) == 1 {
+		root = articles[0]
+	} else if mainEl := findElement(doc, atom.Main); mainEl != nil {
+		root = mainEl
+	}
+	if root == nil {
+		root = doc
+	}
+
+	w := &markdownWriter{base: base}
+	w.children(root)
+	return title, cleanMarkdown(w.b.String())
+}
+
+type markdownWriter struct {
+	b     strings.Builder
+	base  *url.URL
+	pre   int
+	lists []markdownList
+}
+
+type markdownList struct {
+	ordered bool
+	n       int
+}
+
+func (w *markdownWriter) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.node(c)
+	}
+}
+
+func (w *markdownWriter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.text(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		w.children(n)
+		return
+	}
+	if webFetchSkipped[n.DataAtom] || hasAttr(n, "hidden") || attr(n, "aria-hidden") == "true" {
+		return
+	}
+
+	switch a := n.DataAtom; {
+	case webFetchHeadings[a] > 0:
+		if heading := w.inline(n); heading != "" {
+			w.blockBreak()
+			w.b.WriteString(strings.Repeat("#", webFetchHeadings[a]) + " " + heading)
+			w.blockBreak()
+		}
+	case webFetchBlocks[a]:
+		w.blockBreak()
+		w.children(n)
+		w.blockBreak()
+	case a == atom.Br:
+		w.b.WriteString("\n")
+	case a == atom.Hr:
+		w.blockBreak()
+		w.b.WriteString("---")
+		w.blockBreak()
+	case a == atom.Pre:
+		w.blockBreak()
+		w.b.WriteString("```" + codeLanguage(n) + "\n")
+		w.pre++
+		w.children(n)
+		w.pre--
+		w.lineBreak()
+		w.b.WriteString("```")
+		w.blockBreak()
+	case a == atom.Code || a == atom.Kbd || a == atom.Samp:
+		if w.pre > 0 {
+			w.children(n)
+		} else if code := strings.Join(strings.Fields(nodeText(n)), " "); code != "" {
+			w.b.WriteString("`" + code + "`")
+		}
+	case a == atom.A:
+		w.link(n)
+	case a == atom.Strong || a == atom.B:
+		w.wrap(n, "**")
+	case a == atom.Em || a == atom.I:
+		w.wrap(n, "_")
+	case a == atom.Ul || a == atom.Ol:
+		w.list(n, a == atom.Ol)
+	case a == atom.Li:
+		w.listItem(n)
+	case a == atom.Blockquote:
+		w.blockquote(n)
+	case a == atom.Table:
+		w.blockBreak()
+		w.children(n)
+		w.blockBreak()
+	case a == atom.Tr:
+		w.tableRow(n)
+	case a == atom.Img:
+		// Images carry no text worth fetching; alt text is usually noise.
+	default:
+		w.children(n)
+	}
+}
+
+func (w *markdownWriter) text(data string) {
+	if w.pre > 0 {
+		w.b.WriteString(data)
+		return
+	}
+	text := strings.Join(strings.Fields(data), " ")
+	if text == "" {
+		if len(data) > 0 && !w.atSpace() {
+			w.b.WriteString(" ")
+		}
+		return
+	}
+	if isSpace(data[0]) && !w.atSpace() {
+		w.b.WriteString(" ")
+	}
+	w.b.WriteString(text)
+	if isSpace(data[len(data)-1]) {
+		w.b.WriteString(" ")
+	}
+}
+
+// inline renders n's children on a single line.
+func (w *markdownWriter) inline(n *html.Node) string {
+	sub := &markdownWriter{base: w.base}
+	sub.children(n)
+	return strings.Join(strings.Fields(sub.b.String()), " ")
+}
+
+func (w *markdownWriter) wrap(n *html.Node, marker string) {
+	if text := w.inline(n); text != "" {
+		w.b.WriteString(marker + text + marker)
+	}
+}
+
+func (w *markdownWriter) link(n *html.Node) {
+	text := w.inline(n)
+	if text == "" {
+		return
+	}
+	href := strings.TrimSpace(attr(n, "href"))
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		w.b.WriteString(text)
+		return
+	}
+	if w.base != nil {
+		if ref, err := url.Parse(href); err == nil {
+			href = w.base.ResolveReference(ref).String()
+		}
+	}
+	w.b.WriteString("[" + text + "](" + href + ")")
+}
+
+func (w *markdownWriter) list(n *html.Node, ordered bool) {
+	if len(w.lists) == 0 {
+		w.blockBreak()
+	} else {
+		w.lineBreak()
+	}
+	w.lists = append(w.lists, markdownList{ordered: ordered})
+	w.children(n)
+	w.lists = w.lists[:len(w.lists)-1]
+	if len(w.lists) == 0 {
+		w.blockBreak()
+	} else {
+		w.lineBreak()
+	}
+}
+
+func (w *markdownWriter) listItem(n *html.Node) {
+	w.lineBreak()
+	marker := "- "
+	if depth := len(w.lists); depth > 0 {
+		list := &w.lists[depth-1]
+		w.b.WriteString(strings.Repeat("  ", depth-1))
+		if list.ordered {
+			list.n++
+			marker = fmt.Sprintf("%d. ", list.n)
+		}
+	}
+	w.b.WriteString(marker)
+	w.children(n)
+	w.lineBreak()
+}
+
+func (w *markdownWriter) blockquote(n *html.Node) {
+	sub := &markdownWriter{base: w.base}
+	sub.children(n)
+	quoted := cleanMarkdown(sub.b.String())
+	if quoted == "" {
+		return
+	}
+	w.blockBreak()
+	for i, line := range strings.Split(quoted, "\n") {
+		if i > 0 {
+			w.b.WriteString("\n")
+		}
+		w.b.WriteString(strings.TrimRight("> "+line, " "))
+	}
+	w.blockBreak()
+}
+
+func (w *markdownWriter) tableRow(n *html.Node) {
+	var cells []string
+	header := false
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || (c.DataAtom != atom.Td && c.DataAtom != atom.Th) {
+			continue
+		}
+		header = header || c.DataAtom == atom.Th
+		cells = append(cells, strings.ReplaceAll(w.inline(c), "|", `\|`))
+	}
+	if len(cells) == 0 {
+		return
+	}
+	w.lineBreak()
+	w.b.WriteString("| " + strings.Join(cells, " | ") + " |")
+	if header {
+		w.b.WriteString("\n|" + strings.Repeat(" --- |", len(cells)))
+	}
+	w.lineBreak()
+}
+
+func (w *markdownWriter) atSpace() bool {
+	s := w.b.String()
+	return s == "" || isSpace(s[len(s)-1])
+}
+
+func (w *markdownWriter) lineBreak() {
+	s := w.b.String()
+	if s != "" && !strings.HasSuffix(s, "\n") {
+		w.b.WriteString("\n")
+	}
+}
+
+func (w *markdownWriter) blockBreak() {
+	s := w.b.String()
+	switch {
+	case s == "" || strings.HasSuffix(s, "\n\n"):
+	case strings.HasSuffix(s, "\n"):
+		w.b.WriteString("\n")
+	default:
+		w.b.WriteString("\n\n")
+	}
+}
+
+// cleanMarkdown trims trailing spaces and collapses runs of blank lines
+// outside code fences.
+func cleanMarkdown(s string) string {
+	var out []string
+	inFence, blank := false, false
+	for _, line := range strings.Split(s, "\n") {
+		if strings.HasPrefix(line, "```") {
+			inFence = !inFence
+		}
+		if !inFence {
+			line = strings.TrimRight(line, " \t")
+			if line == "" {
+				if blank {
+					continue
+				}
+				blank = true
+			} else {
+				blank = false
+			}
+		}
+		out = append(out, line)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
+
+// codeLanguage returns the language of a <pre> block from a "language-x"
+// or "lang-x" class on it or its <code> child.
+func codeLanguage(pre *html.Node) string {
+	nodes := []*html.Node{pre}
+	if c := pre.FirstChild; c != nil && c.DataAtom == atom.Code {
+		nodes = append(nodes, c)
+	}
+	for _, n := range nodes {
+		for _, class := range strings.Fields(attr(n, "class")) {
+			for _, prefix := range []string{"language-", "lang-"} {
+				if lang, ok := strings.CutPrefix(class, prefix); ok && lang != "" {
+					return lang
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findElement(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func findElements(n *html.Node, a atom.Atom) []*html.Node {
+	var found []*html.Node
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return append(found, n)
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		found = append(found, findElements(c, a)...)
+	}
+	return found
+}
+
+func nodeText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(nodeText(c))
+	}
+	return b.String()
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\t' || c == '\r' || c == '\f'
+}
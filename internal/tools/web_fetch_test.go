@@ -0,0 +1,166 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/samsaffron/term-llm/internal/config"
+)
+
+// allowLoopbackFetches lets web_fetch reach httptest servers.
+func allowLoopbackFetches(t *testing.T) {
+	t.Helper()
+	old := webFetchBlockedIP
+	webFetchBlockedIP = func(net.IP) bool { return false }
+	t.Cleanup(func() { webFetchBlockedIP = old })
+}
+
+func runWebFetch(t *testing.T, tool *WebFetchTool, url string) string {
+	t.Helper()
+	args, _ := json.Marshal(WebFetchArgs{URL: url})
+	out, err := tool.Execute(context.Background(), args)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	return out.Content
+}
+
+const webFetchTestPage = `<!doctype html>
+<html><head><title>Release notes</title><script>var tracking = 1;</script></head>
+<body>
+<nav><a href="/">Home</a> <a href="/blog">Blog</a></nav>
+<main>
+<h1>Version 2.0</h1>
+<p>See the <a href="/docs/upgrade">upgrade guide</a> before   updating.</p>
+<ul><li>Faster <strong>startup</strong></li><li>New <code>--json</code> flag</li></ul>
+<pre><code class="language-go">func main() {
+	run()
+}</code></pre>
+</main>
+<footer>Copyright</footer>
+</body></html>`
+
+func TestWebFetchExtractsReadableHTMLAfterRedirect(t *testing.T) {
+	allowLoopbackFetches(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/notes", http.StatusFound)
+	})
+	mux.HandleFunc("/notes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(webFetchTestPage))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	out := runWebFetch(t, NewWebFetchTool(nil, nil), srv.URL+"/old")
+
+	for _, want := range []string{
+		"URL: " + srv.URL + "/notes\n",
+		"Title: Release notes",
+		"# Version 2.0",
+		"See the [upgrade guide](" + srv.URL + "/docs/upgrade) before updating.",
+		"- Faster **startup**",
+		"- New `--json` flag",
+		"```go\nfunc main() {\n\trun()\n}\n```",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"tracking", "Blog", "Copyright"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("output contains page chrome %q:\n%s", unwanted, out)
+		}
+	}
+}
+
+func TestWebFetchContentTypes(t *testing.T) {
+	allowLoopbackFetches(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/data.json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"ok": true}`))
+		case "/logo.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("\x89PNG\r\n\x1a\n\x00\x00"))
+		case "/big.txt":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(strings.Repeat("a", 100)))
+		}
+	}))
+	defer srv.Close()
+
+	tool := NewWebFetchTool(nil, &config.Config{Tools: config.ToolsConfig{WebFetch: config.WebFetchConfig{MaxBytes: 10}}})
+
+	if out := runWebFetch(t, NewWebFetchTool(nil, nil), srv.URL+"/data.json"); !strings.HasSuffix(out, "\n\n{\"ok\": true}") {
+		t.Errorf("JSON was not passed through:\n%s", out)
+	}
+	if out := runWebFetch(t, tool, srv.URL+"/logo.png"); !strings.Contains(out, "UNSUPPORTED_FORMAT") || !strings.Contains(out, "image/png") {
+		t.Errorf("binary response = %q, want an error naming the content type", out)
+	}
+	if out := runWebFetch(t, tool, srv.URL+"/big.txt"); !strings.Contains(out, "\n\naaaaaaaaaa\n\n[Response truncated at 10 bytes]") {
+		t.Errorf("oversized response was not truncated:\n%s", out)
+	}
+}
+
+func TestWebFetchAsksOncePerDomainAndHonorsDenylist(t *testing.T) {
+	allowLoopbackFetches(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	approval := NewApprovalManager(nil)
+	var prompts []string
+	approval.PromptUIFunc = func(path string, isWrite, isShell bool, workDir string) (ApprovalResult, error) {
+		prompts = append(prompts, path)
+		return ApprovalResult{Choice: ApprovalChoiceDomain}, nil
+	}
+	tool := NewWebFetchTool(approval, nil)
+	for _, path := range []string{"/a", "/b"} {
+		if out := runWebFetch(t, tool, srv.URL+path); !strings.HasSuffix(out, "\n\nhello") {
+			t.Fatalf("fetch %s = %q", path, out)
+		}
+	}
+	if len(prompts) != 1 || prompts[0] != srv.URL+"/a" {
+		t.Fatalf("prompts = %v, want one prompt for the first URL", prompts)
+	}
+
+	denied := NewWebFetchTool(NewApprovalManager(nil), &config.Config{Tools: config.ToolsConfig{WebFetch: config.WebFetchConfig{DeniedDomains: []string{"127.0.0.1"}}}})
+	if out := runWebFetch(t, denied, srv.URL+"/a"); !strings.Contains(out, "denied_domains") {
+		t.Fatalf("denied domain fetch = %q", out)
+	}
+}
+
+func TestWebFetchBlocksPrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("secret"))
+	}))
+	defer srv.Close()
+
+	out := runWebFetch(t, NewWebFetchTool(nil, nil), srv.URL)
+	if strings.Contains(out, "secret") || !strings.Contains(out, "not allowed") {
+		t.Fatalf("loopback fetch = %q, want it blocked", out)
+	}
+}
+
+func TestWebFetchIgnoresProxyForDialChecks(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "http://127.0.0.1:1")
+	t.Setenv("HTTP_PROXY", "http://127.0.0.1:1")
+	tool := NewWebFetchTool(nil, nil)
+	transport := tool.client.Transport.(*http.Transport)
+	if transport.Proxy != nil {
+		t.Fatal("web_fetch must dial targets directly so its address checks see them")
+	}
+	if !webFetchBlockedIP(net.ParseIP("100.100.100.100")) {
+		t.Fatal("shared address space (100.64.0.0/10) should be blocked")
+	}
+}
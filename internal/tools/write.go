@@ -88,7 +88,10 @@ func (t *WriteFileTool) Execute(ctx context.Context, args json.RawMessage) (llm.
 
 	// Check permissions via approval manager
 	if t.approval != nil {
-		outcome, err := t.approval.CheckPathApproval(WriteFileToolName, absPath, a.Path, true)
+		outcome, err := t.approval.CheckPathApprovalWithDetails(WriteFileToolName, absPath, a.Path, true, ApprovalDetails{
+			Args: approvalArgsPreview(WriteFileToolName, args),
+			Diff: func() string { return writeApprovalDiff(absPath, a.Content) },
+		})
 		if err != nil {
 			if toolErr, ok := err.(*ToolError); ok {
 				return textOutput(formatToolError(toolErr)), nil
@@ -0,0 +1,130 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/samsaffron/term-llm/internal/agents"
+	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/session"
+)
+
+// cmdAgent switches the current session to another agent and relaunches chat
+// on it. Unlike /handover the conversation is kept as is: the stored system
+// prompt is replaced with the new agent's, and tools, search and MCP servers
+// are set up again from the agent when chat restarts.
+func (m *Model) cmdAgent(args []string) (tea.Model, tea.Cmd) {
+	if len(args) != 1 {
+		current := m.agentName
+		if current == "" {
+			current = "none"
+		}
+		return m.showSystemMessage(fmt.Sprintf("Current agent: %s\nUsage: /agent <name>", current))
+	}
+	if m.agentResolver == nil {
+		return m.showSystemMessage("Agent resolver not configured.")
+	}
+	if m.store == nil || m.sess == nil {
+		return m.showSystemMessage("Switching agents requires session storage.")
+	}
+	if m.streaming {
+		return m.showFooterWarning("Wait for the response to finish before switching agents.")
+	}
+
+	name := strings.TrimPrefix(strings.TrimSpace(args[0]), "@")
+	agent, err := m.agentResolver(name, m.config)
+	if err != nil {
+		return m.showSystemMessage(fmt.Sprintf("Failed to load agent %s: %v", name, err))
+	}
+	if agent == nil {
+		return m.showSystemMessage(fmt.Sprintf("Agent %s not found.", name))
+	}
+
+	updated := *m.sess
+	applyAgentToSession(&updated, agent)
+	prompt, err := m.resolveAgentSystemPrompt(agent, &updated)
+	if err != nil {
+		return m.showFooterError(fmt.Sprintf("Agent switch failed to resolve system prompt: %v", err))
+	}
+
+	ctx := context.Background()
+	if err := m.replaceStoredSystemPrompt(ctx, prompt); err != nil {
+		return m.showFooterError(fmt.Sprintf("Agent switch failed: %v", err))
+	}
+	if err := m.store.Update(ctx, &updated); err != nil {
+		return m.showFooterError(fmt.Sprintf("Agent switch failed: %v", err))
+	}
+	*m.sess = updated
+	m.setTextareaValue("")
+	return m.requestResumeSession(m.sess.ID)
+}
+
+// resolveAgentSystemPrompt builds agent's system prompt through the same
+// resolvers /handover uses, for the session's directory and model.
+func (m *Model) resolveAgentSystemPrompt(agent *agents.Agent, sess *session.Session) (string, error) {
+	dir := strings.TrimSpace(sess.WorktreeDir)
+	if dir == "" {
+		dir = strings.TrimSpace(sess.CWD)
+	}
+	if m.runtimeSystemContextResolver != nil {
+		resolved, err := m.runtimeSystemContextResolver(agent, sess.ProviderKey, sess.Model, dir)
+		return resolved.SystemPrompt, err
+	}
+	if m.handoverSystemPromptResolver != nil {
+		return m.handoverSystemPromptResolver(agent, sess.ProviderKey, sess.Model)
+	}
+	return "", fmt.Errorf("resolver is not configured")
+}
+
+// replaceStoredSystemPrompt rewrites the system message in the active context
+// in place, so message sequences and any compaction boundary are unchanged.
+// When the session has no system message yet, the restarted chat adds one.
+func (m *Model) replaceStoredSystemPrompt(ctx context.Context, prompt string) error {
+	fromSeq := m.sess.CompactionSeq
+	if fromSeq < 0 {
+		fromSeq = 0
+	}
+	messages, err := m.store.GetMessagesFrom(ctx, m.sess.ID, fromSeq, 0)
+	if err != nil {
+		return err
+	}
+	for i := range messages {
+		msg := &messages[i]
+		if msg.Role != llm.RoleSystem {
+			continue
+		}
+		msg.Parts = []llm.Part{{Type: llm.PartText, Text: prompt}}
+		msg.TextContent = prompt
+		return m.store.UpdateMessage(ctx, m.sess.ID, msg)
+	}
+	return nil
+}
+
+// applyAgentToSession records agent and the settings chat restores on resume.
+// The provider and model only change when the agent sets them.
+func applyAgentToSession(sess *session.Session, agent *agents.Agent) {
+	sess.Agent = agent.Name
+	sess.Search = agent.Search
+	sess.Tools = resolveAgentTools(agent)
+	sess.MCP = strings.Join(agent.GetMCPServerNames(), ",")
+
+	providerKey, modelName := sess.ProviderKey, sess.Model
+	if agent.Provider != "" {
+		providerKey = agent.Provider
+	}
+	if agent.Model != "" {
+		modelName = agent.Model
+	}
+	if providerKey == sess.ProviderKey && modelName == sess.Model {
+		return
+	}
+	providerLabel := providerKey
+	if modelName != "" {
+		providerLabel = fmt.Sprintf("%s (%s)", providerKey, modelName)
+	}
+	sess.Provider = providerLabel
+	sess.ProviderKey = providerKey
+	sess.Model = modelName
+}
@@ -0,0 +1,78 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/samsaffron/term-llm/internal/agents"
+	"github.com/samsaffron/term-llm/internal/config"
+	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/session"
+)
+
+func TestCmdAgentSwitchesSessionAgentAndRelaunches(t *testing.T) {
+	store, err := session.NewSQLiteStore(session.Config{Enabled: true, Path: filepath.Join(t.TempDir(), "sessions.db")})
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	sess := &session.Session{ID: session.NewID(), Provider: "openai (gpt-5)", ProviderKey: "openai", Model: "gpt-5", Agent: "developer", Tools: "shell", CompactionSeq: -1}
+	if err := store.Create(ctx, sess); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	for _, msg := range []llm.Message{llm.SystemText("You are a developer."), llm.UserText("hi"), llm.AssistantText("hello")} {
+		if err := store.AddMessage(ctx, sess.ID, session.NewMessage(sess.ID, msg, -1)); err != nil {
+			t.Fatalf("AddMessage: %v", err)
+		}
+	}
+
+	m := newCmdTestModel(store)
+	m.sess = sess
+	m.agentName = "developer"
+	m.SetAgentResolver(func(name string, cfg *config.Config) (*agents.Agent, error) {
+		if name != "reviewer" {
+			return nil, fmt.Errorf("agent not found: %s (available: developer, reviewer)", name)
+		}
+		return &agents.Agent{
+			Name:         "reviewer",
+			Model:        "gpt-5-mini",
+			Search:       true,
+			Tools:        agents.ToolsConfig{Enabled: []string{"read_file", "grep"}},
+			SystemPrompt: "You review code.",
+		}, nil
+	})
+
+	result, _ := m.ExecuteCommand("/agent nobody")
+	m = result.(*Model)
+	if !strings.Contains(m.footerMessage, "available: developer, reviewer") || m.RequestedResumeSessionID() != "" {
+		t.Fatalf("unknown agent should list available agents without relaunching, footer=%q", m.footerMessage)
+	}
+
+	result, _ = m.ExecuteCommand("/agent reviewer")
+	m = result.(*Model)
+	if got := m.RequestedResumeSessionID(); got != sess.ID {
+		t.Fatalf("RequestedResumeSessionID() = %q, want the same session", got)
+	}
+
+	stored, err := store.Get(ctx, sess.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if stored.Agent != "reviewer" || stored.Tools != "read_file,grep" || !stored.Search ||
+		stored.ProviderKey != "openai" || stored.Model != "gpt-5-mini" {
+		t.Fatalf("stored session = agent %q tools %q search %v provider %q model %q",
+			stored.Agent, stored.Tools, stored.Search, stored.ProviderKey, stored.Model)
+	}
+	msgs, err := store.GetMessages(ctx, sess.ID, 0, 0)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	if len(msgs) != 3 || msgs[0].Role != llm.RoleSystem || msgs[0].TextContent != "You review code." || msgs[2].TextContent != "hello" {
+		t.Fatalf("messages after switch = %+v, want the conversation kept under the new system prompt", msgs)
+	}
+}
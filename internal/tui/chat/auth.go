@@ -0,0 +1,150 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+// authLoginState tracks a sign-in started from inside the TUI after a
+// provider reported llm.AuthRequiredError. Providers never prompt on stdin
+// while the TUI owns the terminal, so the chat model runs the device-code
+// flow itself.
+type authLoginState struct {
+	pending *llm.AuthRequiredError // Awaiting confirmation
+	target  string                 // provider:model to rebuild once signed in
+	login   *llm.DeviceLogin       // Non-nil while polling for approval
+	cancel  context.CancelFunc
+}
+
+type (
+	authDeviceCodeMsg struct {
+		login *llm.DeviceLogin
+		err   error
+	}
+	authLoginDoneMsg struct {
+		login *llm.DeviceLogin
+		err   error
+	}
+)
+
+// startDeviceLogin and waitDeviceLogin are swapped out in tests.
+var (
+	startDeviceLogin = llm.StartDeviceLogin
+	waitDeviceLogin  = func(ctx context.Context, login *llm.DeviceLogin) error { return login.Wait(ctx) }
+)
+
+func authProviderLabel(provider string) string {
+	switch provider {
+	case "copilot":
+		return "GitHub Copilot"
+	case "chatgpt":
+		return "ChatGPT"
+	}
+	return provider
+}
+
+// promptForAuthIfRequired opens the sign-in dialog when err says the
+// provider needs a new login. target is the provider:model to rebuild
+// afterwards; empty means the current one. Returns false for other errors.
+func (m *Model) promptForAuthIfRequired(err error, target string) bool {
+	var authErr *llm.AuthRequiredError
+	if !errors.As(err, &authErr) || m.dialog == nil || m.authLogin.login != nil {
+		return false
+	}
+	if target == "" {
+		provider, model := m.currentProviderAndModel()
+		target = provider + ":" + model
+	}
+	m.authLogin.pending = authErr
+	m.authLogin.target = target
+	m.dialog.ShowAuthRequired(
+		"Sign in to "+authProviderLabel(authErr.Provider),
+		authErr.Reason+". Sign in now with a device code? You will get a code to enter in your browser.",
+	)
+	return true
+}
+
+// resolveAuthPrompt handles the answer to the sign-in dialog.
+func (m *Model) resolveAuthPrompt(proceed bool) (tea.Model, tea.Cmd) {
+	pending := m.authLogin.pending
+	m.authLogin.pending = nil
+	m.dialog.Close()
+	if pending == nil {
+		return m, nil
+	}
+	if !proceed {
+		m.authLogin.target = ""
+		return m.showFooterMuted(fmt.Sprintf("Not signed in. Run 'term-llm auth login %s' to sign in later.", pending.Provider))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.authLogin.cancel = cancel
+	provider := pending.Provider
+	_, footerCmd := m.showFooterMuted("Requesting a sign-in code from " + authProviderLabel(provider) + "...")
+	return m, tea.Batch(footerCmd, func() tea.Msg {
+		login, err := startDeviceLogin(ctx, provider)
+		return authDeviceCodeMsg{login: login, err: err}
+	})
+}
+
+func (m *Model) handleAuthDeviceCode(msg authDeviceCodeMsg) (tea.Model, tea.Cmd) {
+	if m.authLogin.cancel == nil {
+		// Cancelled while the code was being requested.
+		return m, nil
+	}
+	if msg.err != nil {
+		m.cancelAuthLogin()
+		return m.showFooterError(fmt.Sprintf("Sign-in failed: %v", msg.err))
+	}
+
+	m.authLogin.login = msg.login
+	m.dialog.ShowAuthDeviceCode("Sign in to "+authProviderLabel(msg.login.Provider), msg.login.VerificationURL, msg.login.UserCode)
+	m.dialog.SetStatus(m.spinner.View() + " Waiting for approval...")
+	m.clearFooterMessage()
+
+	// The code request is done; polling gets its own cancel.
+	m.authLogin.cancel()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.authLogin.cancel = cancel
+	login := msg.login
+	return m, tea.Batch(m.spinner.Tick, func() tea.Msg {
+		return authLoginDoneMsg{login: login, err: waitDeviceLogin(ctx, login)}
+	})
+}
+
+func (m *Model) handleAuthLoginDone(msg authLoginDoneMsg) (tea.Model, tea.Cmd) {
+	if m.authLogin.login != msg.login {
+		// A cancelled sign-in finishing late.
+		return m, nil
+	}
+	target := m.authLogin.target
+	m.cancelAuthLogin()
+	if m.dialog.Type() == DialogAuthDeviceCode {
+		m.dialog.Close()
+	}
+	if msg.err != nil {
+		return m.showFooterError(fmt.Sprintf("Sign-in failed: %v", msg.err))
+	}
+
+	// Rebuild the provider so it picks up the new credentials.
+	label := authProviderLabel(msg.login.Provider)
+	before := m.provider
+	_, switchCmd := m.switchModel(target)
+	if m.provider == before {
+		return m, switchCmd
+	}
+	_, footerCmd := m.showFooterMuted("Signed in to " + label + ". Send your message again or use /retry.")
+	return m, tea.Batch(switchCmd, footerCmd)
+}
+
+// cancelAuthLogin stops any sign-in in progress and forgets its state.
+func (m *Model) cancelAuthLogin() {
+	if m.authLogin.cancel != nil {
+		m.authLogin.cancel()
+	}
+	m.authLogin = authLoginState{}
+}
@@ -0,0 +1,86 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/ui"
+)
+
+func TestAuthRequiredErrorRunsDeviceLoginInTUI(t *testing.T) {
+	oldStart, oldWait := startDeviceLogin, waitDeviceLogin
+	defer func() { startDeviceLogin, waitDeviceLogin = oldStart, oldWait }()
+	login := &llm.DeviceLogin{Provider: "copilot", UserCode: "ABCD-1234", VerificationURL: "https://github.com/login/device"}
+	startDeviceLogin = func(ctx context.Context, provider string) (*llm.DeviceLogin, error) {
+		if provider != "copilot" {
+			t.Fatalf("device login for %q, want copilot", provider)
+		}
+		return login, nil
+	}
+	waitDeviceLogin = func(ctx context.Context, l *llm.DeviceLogin) error {
+		return errors.New("authorization denied by user")
+	}
+
+	m := newTestChatModel(false)
+	m.width = 100
+	if m.promptForAuthIfRequired(errors.New("rate limited"), "") {
+		t.Fatal("an unrelated error opened the sign-in dialog")
+	}
+	streamErr := fmt.Errorf("stream: %w", &llm.AuthRequiredError{Provider: "copilot", Reason: "Copilot session expired"})
+	if !m.promptForAuthIfRequired(streamErr, "") || m.dialog.Type() != DialogAuthRequired {
+		t.Fatalf("dialog type = %v, want the sign-in confirmation", m.dialog.Type())
+	}
+	if view := ui.StripANSI(m.dialog.View()); !strings.Contains(view, "Copilot session expired") {
+		t.Fatalf("sign-in dialog %q does not explain why", view)
+	}
+
+	_, cmd := m.resolveAuthPrompt(true)
+	if cmd == nil || m.dialog.IsOpen() {
+		t.Fatal("confirming should close the dialog and request a device code")
+	}
+	_, cmd = m.handleAuthDeviceCode(authDeviceCodeMsg{login: login})
+	if cmd == nil || m.dialog.Type() != DialogAuthDeviceCode {
+		t.Fatalf("dialog type = %v, want the device code", m.dialog.Type())
+	}
+	view := ui.StripANSI(m.dialog.View())
+	for _, want := range []string{"ABCD-1234", "https://github.com/login/device", "Waiting for approval"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("device code dialog %q is missing %q", view, want)
+		}
+	}
+
+	m.handleAuthLoginDone(authLoginDoneMsg{login: login, err: waitDeviceLogin(context.Background(), login)})
+	if m.dialog.IsOpen() || m.authLogin.login != nil {
+		t.Fatal("a finished sign-in should close the dialog and clear its state")
+	}
+	if !strings.Contains(m.footerMessage, "authorization denied") || m.footerMessageTone != "error" {
+		t.Fatalf("footer = %q (%s), want the sign-in error", m.footerMessage, m.footerMessageTone)
+	}
+}
+
+func TestAuthDeviceCodeDialogCancelsOnEsc(t *testing.T) {
+	m := newTestChatModel(false)
+	cancelled := false
+	login := &llm.DeviceLogin{Provider: "chatgpt", UserCode: "XYZ", VerificationURL: "https://auth.openai.com/codex/device"}
+	m.authLogin = authLoginState{login: login, cancel: func() { cancelled = true }}
+	m.dialog.ShowAuthDeviceCode("Sign in to ChatGPT", login.VerificationURL, login.UserCode)
+
+	m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if !cancelled || m.dialog.IsOpen() || m.authLogin.login != nil {
+		t.Fatalf("esc should cancel polling and close the dialog (cancelled=%v open=%v)", cancelled, m.dialog.IsOpen())
+	}
+	if !strings.Contains(m.footerMessage, "term-llm auth login chatgpt") {
+		t.Fatalf("footer = %q, want a pointer to auth login", m.footerMessage)
+	}
+
+	// A poll finishing after the cancel is ignored.
+	m.handleAuthLoginDone(authLoginDoneMsg{login: login})
+	if strings.Contains(m.footerMessage, "Signed in") {
+		t.Fatal("a cancelled sign-in reported success")
+	}
+}
@@ -0,0 +1,307 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/samsaffron/term-llm/internal/session"
+	"github.com/samsaffron/term-llm/internal/ui"
+)
+
+// branchEntry is one row of /branches: the session this one was forked from,
+// every fork of that parent (this session included), and this session's own
+// forks.
+type branchEntry struct {
+	id           string
+	number       int64
+	title        string
+	relation     string // "parent", "current", "sibling" or "fork"
+	messageCount int
+	updatedAt    time.Time
+}
+
+// loadBranches returns the branch tree around the current session in display
+// order: parent, then siblings oldest first, then this session's forks. The
+// parent and siblings are only listed when this session is itself a fork;
+// child runs such as subagents have a parent but are not its branches.
+func (m *Model) loadBranches(ctx context.Context) ([]branchEntry, error) {
+	forker, ok := m.store.(session.Forker)
+	if !ok {
+		return nil, session.ErrForkUnsupported
+	}
+	var entries []branchEntry
+	fromSummary := func(sum session.SessionSummary, relation string) branchEntry {
+		if sum.ID == m.sess.ID {
+			relation = "current"
+		}
+		return branchEntry{
+			id:           sum.ID,
+			number:       sum.Number,
+			title:        sum.PreferredShortTitle(),
+			relation:     relation,
+			messageCount: sum.MessageCount,
+			updatedAt:    sum.UpdatedAt,
+		}
+	}
+
+	if parentID := strings.TrimSpace(m.sess.ParentID); parentID != "" && m.sess.Kind == session.KindFork {
+		parent, err := m.store.Get(ctx, parentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent != nil {
+			entries = append(entries, branchEntry{
+				id:           parent.ID,
+				number:       parent.Number,
+				title:        parent.PreferredShortTitle(),
+				relation:     "parent",
+				messageCount: parent.MessageCount,
+				updatedAt:    parent.UpdatedAt,
+			})
+			siblings, err := forker.Forks(ctx, parent.ID)
+			if err != nil {
+				return nil, err
+			}
+			for _, sum := range siblings {
+				entries = append(entries, fromSummary(sum, "sibling"))
+			}
+		}
+	}
+	if !branchEntriesContain(entries, m.sess.ID) {
+		entries = append(entries, branchEntry{
+			id:           m.sess.ID,
+			number:       m.sess.Number,
+			title:        m.sess.PreferredShortTitle(),
+			relation:     "current",
+			messageCount: m.sess.MessageCount,
+			updatedAt:    m.sess.UpdatedAt,
+		})
+	}
+
+	forks, err := forker.Forks(ctx, m.sess.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, sum := range forks {
+		entries = append(entries, fromSummary(sum, "fork"))
+	}
+	return entries, nil
+}
+
+func branchEntriesContain(entries []branchEntry, id string) bool {
+	for _, e := range entries {
+		if e.id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// label renders an entry for the /branches dialog; index is 1-based.
+func (e branchEntry) label(index int) string {
+	title := e.title
+	if title == "" {
+		title = "(untitled)"
+	}
+	title = ui.Truncate(title, 28)
+	marker := " "
+	if e.relation == "current" {
+		marker = "●"
+	}
+	return fmt.Sprintf("%s %d. #%d %s · %s · %d msgs · %s", marker, index, e.number, title, e.relation, e.messageCount, resumeFormatAge(e.updatedAt))
+}
+
+// cmdBranches opens the branch tree around the current session. Enter
+// switches to the selected branch in place; d deletes it after a confirmation.
+func (m *Model) cmdBranches() (tea.Model, tea.Cmd) {
+	m.setTextareaValue("")
+	if m.store == nil || m.sess == nil {
+		return m.showSystemMessage("Session storage is disabled.")
+	}
+	entries, err := m.loadBranches(context.Background())
+	if err != nil {
+		return m.showFooterError(fmt.Sprintf("Failed to load branches: %v", err))
+	}
+	if len(entries) < 2 {
+		return m.showFooterMuted("This session has no parent or forks. Use /fork to branch it.")
+	}
+	m.showBranchesDialog(entries)
+	return m, nil
+}
+
+func (m *Model) showBranchesDialog(entries []branchEntry) {
+	items := make([]DialogItem, 0, len(entries))
+	for i, e := range entries {
+		items = append(items, DialogItem{ID: e.id, Label: e.label(i + 1)})
+	}
+	m.dialog.ShowBranches(items, m.sess.ID)
+}
+
+// cmdSwitch switches to another session in place. n picks the nth entry of
+// /branches; #number or an ID prefix picks any session.
+func (m *Model) cmdSwitch(args []string) (tea.Model, tea.Cmd) {
+	if len(args) != 1 {
+		return m.showSystemMessage("Usage: /switch <n|#number|id>")
+	}
+	m.setTextareaValue("")
+	if m.store == nil || m.sess == nil {
+		return m.showSystemMessage("Session storage is disabled.")
+	}
+	ctx := context.Background()
+	target := strings.TrimSpace(args[0])
+	if n, err := strconv.Atoi(target); err == nil && n >= 1 {
+		entries, err := m.loadBranches(ctx)
+		if err != nil {
+			return m.showFooterError(fmt.Sprintf("Failed to load branches: %v", err))
+		}
+		if n <= len(entries) {
+			return m.switchToSession(entries[n-1].id)
+		}
+	}
+	sess, err := m.store.GetByPrefix(ctx, target)
+	if err != nil {
+		return m.showFooterError(fmt.Sprintf("Failed to find session: %v", err))
+	}
+	if sess == nil {
+		return m.showFooterError(fmt.Sprintf("Session '%s' not found.", target))
+	}
+	return m.switchToSession(sess.ID)
+}
+
+// switchToSession saves the current session and loads sessionID in its place,
+// leaving the composer untouched. Sessions bound to another agent or worktree
+// need a fresh tool setup, so those relaunch chat as /resume does.
+func (m *Model) switchToSession(sessionID string) (tea.Model, tea.Cmd) {
+	if m.streaming {
+		return m.showFooterWarning("Wait for the response to finish before switching branches.")
+	}
+	if m.worktreeOperationBusy() {
+		return m.showFooterWarning("Wait for the current worktree operation to finish before switching branches.")
+	}
+	if sessionID == m.sess.ID {
+		return m.showFooterMuted("Already on this branch.")
+	}
+	ctx := context.Background()
+	target, err := m.store.Get(ctx, sessionID)
+	if err != nil {
+		return m.showFooterError(fmt.Sprintf("Switch failed: %v", err))
+	}
+	if target == nil {
+		return m.showFooterError("Switch failed: session not found.")
+	}
+	if target.Agent != m.sess.Agent || target.WorktreeDir != m.sess.WorktreeDir {
+		m.setTextareaValue("")
+		return m.requestResumeSession(target.ID)
+	}
+	messages, compactionIdx, err := loadSessionMessagesForScrollback(ctx, m.store, target)
+	if err != nil {
+		return m.showFooterError(fmt.Sprintf("Switch failed: %v", err))
+	}
+
+	m.clearSideQuestionHistory()
+	m.pauseGoalForLocalAction("paused because another branch was opened")
+	m.clearPendingStreamModelSwitch()
+	m.pendingEdit = nil
+	m.persistContextEstimate(ctx)
+	_ = m.store.UpdateStatus(ctx, m.sess.ID, session.StatusComplete)
+
+	m.sess = target
+	m.messagesMu.Lock()
+	m.messages = messages
+	m.compactionIdx = compactionIdx
+	m.messagesMu.Unlock()
+	m.olderScrollbackLoaded = true
+	m.scrollOffset = 0
+	_ = m.store.SetCurrent(ctx, target.ID)
+
+	if m.engine != nil {
+		m.engine.ResetConversation()
+	}
+	m.currentResponse.Reset()
+	m.currentTokens = 0
+	m.webSearchUsed = false
+	m.retryStatus = ""
+	if m.tracker != nil {
+		m.resetTracker()
+	}
+	if m.smoothBuffer != nil {
+		m.smoothBuffer.Reset()
+	}
+	m.smoothTickPending = false
+	m.streamRenderTickPending = false
+	if m.stats != nil {
+		m.stats = ui.NewSessionStats()
+		m.seedStatsFromSession()
+	}
+	m.configureContextManagementForSession()
+	m.resetTitleGenerationStateForSession()
+
+	ui.ClearRenderedImages()
+	m.resetImageUploadState()
+	m.reasoningExpansionOverrides = nil
+	m.viewCache.completedStream = ""
+	m.invalidateHistoryCache()
+	m.forceHistoryRerender()
+
+	title := target.PreferredShortTitle()
+	if title == "" {
+		title = "(untitled)"
+	}
+	updated, footerCmd := m.showFooterSuccess(fmt.Sprintf("Switched to #%d %s.", target.Number, title))
+	return updated, tea.Batch(footerCmd, m.terminalTitleCmd())
+}
+
+// confirmBranchDelete asks before deleting the branch selected in /branches.
+func (m *Model) confirmBranchDelete(id string) (tea.Model, tea.Cmd) {
+	if id == m.sess.ID {
+		return m.showFooterWarning("Switch to another branch before deleting this one.")
+	}
+	entries, err := m.loadBranches(context.Background())
+	if err != nil {
+		m.dialog.Close()
+		return m.showFooterError(fmt.Sprintf("Failed to load branches: %v", err))
+	}
+	for i, e := range entries {
+		if e.id != id {
+			continue
+		}
+		question := fmt.Sprintf("Delete %s? Its messages are removed; its own forks are kept.", strings.TrimSpace(e.label(i+1)))
+		m.pendingBranchDelete = id
+		m.dialog.ShowBranchDeleteConfirmation(question)
+		return m, nil
+	}
+	return m, nil
+}
+
+// resolveBranchDelete deletes the pending branch when confirmed and returns
+// to the /branches list.
+func (m *Model) resolveBranchDelete(confirmed bool) (tea.Model, tea.Cmd) {
+	id := m.pendingBranchDelete
+	m.pendingBranchDelete = ""
+	m.dialog.Close()
+	if !confirmed || id == "" {
+		return m.reopenBranches("")
+	}
+	if err := m.store.Delete(context.Background(), id); err != nil {
+		return m.showFooterError(fmt.Sprintf("Delete failed: %v", err))
+	}
+	return m.reopenBranches("Branch deleted.")
+}
+
+func (m *Model) reopenBranches(notice string) (tea.Model, tea.Cmd) {
+	entries, err := m.loadBranches(context.Background())
+	if err != nil {
+		return m.showFooterError(fmt.Sprintf("Failed to load branches: %v", err))
+	}
+	if len(entries) >= 2 {
+		m.showBranchesDialog(entries)
+	}
+	if notice == "" {
+		return m, nil
+	}
+	return m.showFooterSuccess(notice)
+}
@@ -0,0 +1,202 @@
+package chat
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/session"
+	"github.com/samsaffron/term-llm/internal/ui"
+)
+
+// newBranchesTestModel builds a parent session with two forks in a temp
+// SQLite store and returns a chat model on the first fork.
+func newBranchesTestModel(t *testing.T, altScreen bool) (*Model, *session.SQLiteStore, *session.Session, []*session.Session) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	store, err := session.NewSQLiteStore(session.Config{Enabled: true, Path: filepath.Join(t.TempDir(), "sessions.db")})
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	ctx := context.Background()
+	parent := &session.Session{ID: session.NewID(), Name: "root", Provider: "mock", Model: "mock-model", CompactionSeq: -1}
+	if err := store.Create(ctx, parent); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	for i, msg := range []llm.Message{llm.UserText("question"), llm.AssistantText("answer")} {
+		if err := store.AddMessage(ctx, parent.ID, session.NewMessage(parent.ID, msg, i)); err != nil {
+			t.Fatalf("AddMessage: %v", err)
+		}
+	}
+	var forks []*session.Session
+	for _, reply := range []string{"first branch", "second branch"} {
+		fork, err := store.Fork(ctx, parent.ID, -1)
+		if err != nil {
+			t.Fatalf("Fork: %v", err)
+		}
+		if err := store.AddMessage(ctx, fork.ID, session.NewMessage(fork.ID, llm.UserText(reply), 2)); err != nil {
+			t.Fatalf("AddMessage(fork): %v", err)
+		}
+		if fork, err = store.Get(ctx, fork.ID); err != nil {
+			t.Fatalf("Get(fork): %v", err)
+		}
+		forks = append(forks, fork)
+	}
+
+	messages, err := store.GetMessages(ctx, forks[0].ID, 0, 0)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	m := newTestChatModel(altScreen)
+	m.store = store
+	m.sess = forks[0]
+	m.messages = messages
+	return m, store, parent, forks
+}
+
+func TestLoadBranchesListsParentSiblingsAndForks(t *testing.T) {
+	m, store, parent, forks := newBranchesTestModel(t, false)
+	ctx := context.Background()
+	grandchild, err := store.Fork(ctx, forks[0].ID, -1)
+	if err != nil {
+		t.Fatalf("Fork(grandchild): %v", err)
+	}
+	for _, parentID := range []string{parent.ID, forks[0].ID} {
+		subagent := &session.Session{ID: session.NewID(), Provider: "mock", Model: "mock-model", ParentID: parentID}
+		if err := store.Create(ctx, subagent); err != nil {
+			t.Fatalf("Create(subagent): %v", err)
+		}
+	}
+
+	entries, err := m.loadBranches(ctx)
+	if err != nil {
+		t.Fatalf("loadBranches: %v", err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.relation)
+	}
+	if strings.Join(got, ",") != "parent,current,sibling,fork" {
+		t.Fatalf("relations = %v, want parent,current,sibling,fork", got)
+	}
+	wantIDs := []string{parent.ID, forks[0].ID, forks[1].ID, grandchild.ID}
+	for i, e := range entries {
+		if e.id != wantIDs[i] {
+			t.Fatalf("entry %d id = %q, want %q", i, e.id, wantIDs[i])
+		}
+	}
+	if entries[0].messageCount != 2 || entries[1].messageCount != 3 {
+		t.Fatalf("message counts = %d/%d, want 2/3", entries[0].messageCount, entries[1].messageCount)
+	}
+
+	result, _ := m.ExecuteCommand("/branches")
+	m = result.(*Model)
+	if m.dialog.Type() != DialogBranches {
+		t.Fatalf("/branches should open the branches dialog, footer=%q", m.footerMessage)
+	}
+	if sel := m.dialog.Selected(); sel == nil || sel.ID != forks[0].ID {
+		t.Fatalf("branches dialog should start on the current session, got %+v", sel)
+	}
+}
+
+func TestSwitchLoadsSiblingInPlaceAndKeepsComposer(t *testing.T) {
+	m, store, _, forks := newBranchesTestModel(t, true)
+	if view := ui.StripANSI(m.View().Content); !strings.Contains(view, "first branch") {
+		t.Fatalf("initial view should render the first fork, got %q", view)
+	}
+
+	result, _ := m.ExecuteCommand("/switch 3")
+	m = result.(*Model)
+	if m.RequestedResumeSessionID() != "" || m.quitting {
+		t.Fatal("/switch should load the branch in place, not relaunch chat")
+	}
+	if m.sess.ID != forks[1].ID {
+		t.Fatalf("session = %q, want sibling %q (footer=%q)", m.sess.ID, forks[1].ID, m.footerMessage)
+	}
+	if n := len(m.messages); n != 3 || m.messages[2].TextContent != "second branch" {
+		t.Fatalf("messages after switch = %d, want the sibling's 3", n)
+	}
+	if view := ui.StripANSI(m.View().Content); strings.Contains(view, "first branch") || !strings.Contains(view, "second branch") {
+		t.Fatalf("view should render only the sibling's history, got %q", view)
+	}
+	if cur, err := store.GetCurrent(context.Background()); err != nil || cur == nil || cur.ID != forks[1].ID {
+		t.Fatalf("current session = %+v, %v; want %q", cur, err, forks[1].ID)
+	}
+
+	result, _ = m.ExecuteCommand("/branches")
+	m = result.(*Model)
+	m.setTextareaValue("half-written draft")
+	m.dialog.SetCursor(1) // the first fork
+	result, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEnter})
+	m = result.(*Model)
+	if m.sess.ID != forks[0].ID {
+		t.Fatalf("enter in /branches should switch to %q, got %q", forks[0].ID, m.sess.ID)
+	}
+	if got := m.textarea.Value(); got != "half-written draft" {
+		t.Fatalf("composer = %q, want the draft kept across the switch", got)
+	}
+}
+
+func TestBranchesDeleteAsksBeforeDeleting(t *testing.T) {
+	m, store, _, forks := newBranchesTestModel(t, false)
+	ctx := context.Background()
+
+	result, _ := m.ExecuteCommand("/branches")
+	m = result.(*Model)
+	m.dialog.SetCursor(2) // the sibling
+	result, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: 'd', Text: "d"})
+	m = result.(*Model)
+	if m.dialog.Type() != DialogBranchDelete || m.pendingBranchDelete != forks[1].ID {
+		t.Fatalf("d should ask to delete the sibling, dialog=%v pending=%q", m.dialog.Type(), m.pendingBranchDelete)
+	}
+	result, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEscape})
+	m = result.(*Model)
+	if sess, _ := store.Get(ctx, forks[1].ID); sess == nil {
+		t.Fatal("declining the confirmation should keep the branch")
+	}
+	if m.dialog.Type() != DialogBranches {
+		t.Fatalf("declining should return to the branches list, got %v", m.dialog.Type())
+	}
+
+	m.dialog.SetCursor(2)
+	result, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: 'd', Text: "d"})
+	m = result.(*Model)
+	result, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEnter})
+	m = result.(*Model)
+	if sess, _ := store.Get(ctx, forks[1].ID); sess != nil {
+		t.Fatal("confirming should delete the branch")
+	}
+	if m.sess.ID != forks[0].ID {
+		t.Fatalf("deleting a sibling should stay on the current session, got %q", m.sess.ID)
+	}
+
+	m.dialog.SetCursor(1)
+	result, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: 'd', Text: "d"})
+	m = result.(*Model)
+	if m.pendingBranchDelete != "" || !strings.Contains(m.footerMessage, "Switch to another branch") {
+		t.Fatalf("the current session must not be deletable, pending=%q footer=%q", m.pendingBranchDelete, m.footerMessage)
+	}
+}
+
+func TestLoadBranchesSkipsParentOfChildRun(t *testing.T) {
+	m, store, parent, _ := newBranchesTestModel(t, false)
+	ctx := context.Background()
+	subagent := &session.Session{ID: session.NewID(), Name: "subagent", Provider: "mock", Model: "mock-model", ParentID: parent.ID}
+	if err := store.Create(ctx, subagent); err != nil {
+		t.Fatalf("Create(subagent): %v", err)
+	}
+	m.sess = subagent
+
+	entries, err := m.loadBranches(ctx)
+	if err != nil {
+		t.Fatalf("loadBranches: %v", err)
+	}
+	if len(entries) != 1 || entries[0].relation != "current" {
+		t.Fatalf("entries = %+v, want only the current session for a child run", entries)
+	}
+}
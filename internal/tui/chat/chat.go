@@ -70,6 +70,9 @@ type RuntimeSystemContext struct {
 	SystemPrompt string
 	ApplySkills  func(engine *llm.Engine, toolMgr *tools.ToolManager)
 	Skills       *skills.Setup
+	// ProjectContext holds the project instruction files injected into each
+	// request for this directory; nil when none are injected.
+	ProjectContext *agents.ProjectContext
 }
 
 type Model struct {
@@ -98,6 +101,7 @@ type Model struct {
 	messagesMu            sync.Mutex // Protects messages from concurrent compaction callback
 	streaming             bool
 	shareInFlight         bool
+	authLogin             authLoginState
 	pendingShare          *shareRequest
 	phase                 string // "Thinking", "Searching", "Reading", "Responding"
 
@@ -164,6 +168,7 @@ type Model struct {
 	smoothBuffer            *ui.SmoothBuffer
 	smoothTickPending       bool
 	streamRenderTickPending bool
+	historyReflowPending    bool
 	newlineCompactor        *ui.StreamingNewlineCompactor
 
 	// External UI state
@@ -232,6 +237,8 @@ type Model struct {
 	selectedImage           int            // -1 means no image chip selected
 	pasteChunks             map[int]string // Collapsed paste placeholders → actual content
 	pasteSeq                int            // Incrementing ID for paste placeholders
+	pasteBurstLast          time.Time      // Last text keystroke, for unbracketed paste detection
+	pasteBurstKeys          int            // Rapid keystrokes in the current burst
 	searchEnabled           bool           // Web search toggle
 	fastMode                bool           // Effective ChatGPT/OpenAI fast service-tier state shown in the footer
 	fastProviderDefault     bool           // Provider config requests fast by default; inherited unless overridden in-session
@@ -258,6 +265,11 @@ type Model struct {
 	ctrlCExitArmedUntil     time.Time // Second Ctrl+C before this time exits the TUI
 	promptHistory           promptHistoryState
 	promptHistoryLookupSeq  uint64
+
+	// Messages submitted with QueueSend while streaming, sent one per turn
+	// once the current response finishes.
+	queuedMessages []queuedMessage
+
 	// MCP (Model Context Protocol)
 	mcpManager    *mcp.Manager
 	mcpStatusChan chan mcp.StatusUpdate
@@ -266,6 +278,8 @@ type Model struct {
 	// Directory approval
 	approvedDirs    *ApprovedDirs
 	pendingFilePath string // File waiting for directory approval
+	// /t command to re-run once pendingFilePath's directory is approved
+	pendingTemplateCommand string
 
 	// History scroll
 	scrollOffset int
@@ -312,6 +326,7 @@ type Model struct {
 	showStats  bool
 	stats      *ui.SessionStats
 	streamPerf *streamPerfTelemetry
+	costCache  statusCostCache
 
 	// Terminal/window title state
 	titleMode      TerminalTitleMode
@@ -324,6 +339,7 @@ type Model struct {
 	titleGenerationAttempts         int
 	titleGenerationLastMessageCount int
 	titleGenerationInFlight         bool
+	autoTitleOff                    bool // sessions.auto_title: false
 	titleManualEditVersion          uint64
 
 	// Inspector mode
@@ -342,9 +358,14 @@ type Model struct {
 
 	// Alt screen mode (full-screen rendering)
 	altScreen               bool
+	altScreenShared         atomic.Bool // altScreen as seen by AltScreen callers off the UI goroutine
 	mouseMode               bool
 	viewport                viewport.Model // Scrollable viewport for alt screen mode
 	scrollToBottom          bool           // Flag to scroll to bottom after response completes
+	screenTerminal          string         // Terminal key the screen mode is saved under; empty disables /screen
+	screenScrollPending     bool           // Scroll to screenScrollMessage on the next alt-screen render
+	screenScrollMessage     int
+	inlineFlushedMessages   int // Messages already printed to terminal scrollback
 	streamRenderMinInterval time.Duration
 
 	// Render cache for alt screen mode (avoids re-rendering unchanged content)
@@ -355,6 +376,7 @@ type Model struct {
 		historyWidth        int      // Width when cache was built
 		historyScrollOffset int      // Scroll offset when cache was built
 		historyValid        bool     // Whether cache has been populated
+		keepLinesFromBottom bool     // Hold the view's distance from the bottom across the next history rebuild
 		lastViewportView    string   // Cached viewport.View() output
 		lastYOffset         int      // Viewport Y offset when view was cached
 		lastVPWidth         int      // Viewport width when view was cached
@@ -396,6 +418,12 @@ type Model struct {
 	toolsExpanded bool
 	// Whether the Ctrl+E discovery hint has been shown in this chat session.
 	toolExpandHintShown bool
+	// Show timing and token stats under assistant messages (Alt+I, /stats messages).
+	messageStats bool
+	// User message being revised with /edit; nil when not editing.
+	pendingEdit *pendingMessageEdit
+	// Session picked for deletion in /branches, awaiting confirmation.
+	pendingBranchDelete string
 
 	// Per-history reasoning block click overrides, keyed by rendered reasoning ordinal.
 	reasoningExpansionOverrides map[int]bool
@@ -444,10 +472,11 @@ type Model struct {
 	selection               Selection
 	contentLines            []string // full viewport content split by \n
 	copyStatus              string   // transient status message after copy attempt
-	footerMessage           string   // transient footer message for short system notices
-	footerMessageTone       string   // "", "muted", "success", "warning", or "error"
-	footerMessageSeq        uint64   // monotonically increasing footer message timer token
-	worktreeOperation       string   // non-empty while an async /worktree operation is running
+	find                    findState
+	footerMessage           string // transient footer message for short system notices
+	footerMessageTone       string // "", "muted", "success", "warning", or "error"
+	footerMessageSeq        uint64 // monotonically increasing footer message timer token
+	worktreeOperation       string // non-empty while an async /worktree operation is running
 	pendingWorktreeRecovery *pendingWorktreeRecovery
 
 	attemptInput          int
@@ -498,6 +527,7 @@ type (
 	}
 	tickMsg               time.Time
 	streamRenderTickMsg   struct{}
+	historyReflowTickMsg  struct{}
 	footerMessageClearMsg struct {
 		Seq uint64
 	}
@@ -512,6 +542,9 @@ type (
 	compactDoneMsg    struct {
 		result *llm.CompactionResult
 		err    error
+		// tokensBefore is the estimated token count of the history that was
+		// compacted; zero when unknown.
+		tokensBefore int
 	}
 	handoverDoneMsg struct {
 		result       *llm.HandoverResult
@@ -573,6 +606,12 @@ type SubagentProgressMsg struct {
 // ResumeFromExternalUIMsg signals that external UI (ask_user/approval) is done
 type ResumeFromExternalUIMsg struct{}
 
+// FooterWarningMsg shows a warning in the footer, for notices that arrive
+// after the chat has started (e.g. a low quota warning).
+type FooterWarningMsg struct {
+	Text string
+}
+
 // autoSendMsg triggers automatic message send (for benchmarking mode)
 type autoSendMsg struct{}
 
@@ -802,6 +841,10 @@ func NewWithFastProviderAndApproval(cfg *config.Config, provider llm.Provider, f
 	if approvedDirs == nil {
 		approvedDirs = &ApprovedDirs{Directories: []string{}}
 	}
+	if cwd, err := os.Getwd(); err == nil {
+		// A broken project file is reported by /dirs.
+		_ = approvedDirs.LoadProject(cwd)
+	}
 
 	// Create completions and dialog
 	completions := NewCompletionsModel(styles)
@@ -960,6 +1003,7 @@ func NewWithFastProviderAndApproval(cfg *config.Config, provider llm.Provider, f
 		model.SetFooterWarning("Raw reasoning display is disabled. Set reasoning.raw=true or TERM_LLM_SHOW_RAW_REASONING=1 to allow it.")
 		model.reasoningRawWarned = true
 	}
+	model.altScreenShared.Store(altScreen)
 	model.configureImageRenderer()
 	model.configureContextManagementForSession()
 	return model
@@ -1097,10 +1141,13 @@ func (m *Model) applyWindowSize(msg tea.WindowSizeMsg) {
 	// Also invalidate history cache because renderHistory() skips the last turn
 	// when completedStream is non-empty — clearing it without rebuilding history
 	// would leave a stale cache that excludes the last assistant message.
+	// Rendered history blocks stay cached: they are keyed by width, and the
+	// renderer reflows them lazily.
 	m.resetAltScreenStreamingAppendCache()
 	if m.viewCache.completedStream != "" {
 		m.viewCache.completedStream = ""
-		m.invalidateHistoryCache()
+		m.viewCache.historyValid = false
+		m.bumpContentVersion()
 	} else {
 		m.bumpContentVersion()
 	}
@@ -1124,10 +1171,12 @@ func (m *Model) applyWindowSize(msg tea.WindowSizeMsg) {
 		viewportHeightChanged := oldViewportHeight > 0 && oldViewportHeight != m.viewport.Height()
 		widthChanged := oldWidth > 0 && oldWidth != m.width
 		if widthChanged || viewportHeightChanged {
+			renderedImages := len(m.viewportImageArtifacts) > 0
 			m.imageGeneration++
 			termimage.ClearCache()
 			termimage.Debugf(termimage.DefaultEnvironment(), "chat resize width %d->%d viewport_h %d->%d model_h=%d generation=%d: invalidate image viewport render", oldWidth, m.width, oldViewportHeight, m.viewport.Height(), m.height, m.imageGeneration)
-			if m.chatRenderer != nil {
+			// Cached blocks embed image placeholders for the old layout.
+			if m.chatRenderer != nil && renderedImages {
 				m.chatRenderer.InvalidateCache()
 			}
 			m.viewCache.lastSetContentAt = time.Time{}
@@ -1167,9 +1216,12 @@ func formatStreamErrorFooter(err error) string {
 	}
 	var incomplete *llm.StreamIncompleteError
 	if errors.As(err, &incomplete) {
+		if turnID := llm.TurnIDFromError(err); turnID != "" {
+			return "Stream interrupted before completion (turn " + turnID + ")."
+		}
 		return "Stream interrupted before completion."
 	}
-	return "Stream failed: " + err.Error()
+	return "Stream failed: " + llm.FormatTurnError(err)
 }
 
 func (m *Model) resetAttemptUsage() {
@@ -1316,6 +1368,9 @@ type interruptedAssistantSalvageResult struct {
 	ok               bool
 	persisted        bool
 	replaceMessageID int64
+	// droppedToolCalls counts tool calls the interrupted response emitted but
+	// never ran; they are left out of the salvaged message.
+	droppedToolCalls int
 }
 
 func (m *Model) salvageInterruptedAssistantMessage() interruptedAssistantSalvageResult {
@@ -1326,6 +1381,10 @@ func (m *Model) salvageInterruptedAssistantMessage() interruptedAssistantSalvage
 	if !ok {
 		return interruptedAssistantSalvageResult{}
 	}
+	assistantMsg, droppedToolCalls, ok := llm.PartialAssistantMessage(assistantMsg)
+	if !ok {
+		return interruptedAssistantSalvageResult{droppedToolCalls: droppedToolCalls}
+	}
 
 	sessionMsg := session.NewMessageWithReasoningPolicy(m.sess.ID, assistantMsg, -1, m.effectiveReasoningConfig())
 	sessionMsg.DurationMs = time.Since(m.streamStartTime).Milliseconds()
@@ -1338,7 +1397,7 @@ func (m *Model) salvageInterruptedAssistantMessage() interruptedAssistantSalvage
 	m.messagesMu.Unlock()
 	m.invalidateHistoryCache()
 
-	result := interruptedAssistantSalvageResult{message: localMsg, ok: true}
+	result := interruptedAssistantSalvageResult{message: localMsg, ok: true, droppedToolCalls: droppedToolCalls}
 
 	if m.store == nil {
 		result.persisted = true
@@ -1421,6 +1480,33 @@ func (m *Model) mergeUnpersistedInterruptedAssistant(result interruptedAssistant
 	}
 }
 
+// appendInterruptedMarker records where a cancelled response stopped, right
+// after its salvaged partial message, and returns the commands printing the
+// marker in inline mode. Responses that produced nothing get no marker.
+func (m *Model) appendInterruptedMarker(result interruptedAssistantSalvageResult) []tea.Cmd {
+	if m.sess == nil || (!result.ok && result.droppedToolCalls == 0) {
+		return nil
+	}
+	marker := llm.InterruptedMarker{DroppedToolCalls: result.droppedToolCalls}
+	msg := llm.InterruptedEventMessage(marker)
+	sm := *session.NewMessage(m.sess.ID, msg, -1)
+	if m.store != nil {
+		dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = m.store.AddMessage(dbCtx, m.sess.ID, &sm)
+	}
+	m.messagesMu.Lock()
+	sm.Sequence = len(m.messages)
+	m.messages = append(m.messages, sm)
+	m.messagesMu.Unlock()
+	m.invalidateViewCache()
+	if m.altScreen {
+		return nil
+	}
+	style := lipgloss.NewStyle().Foreground(m.styles.Theme().Muted).Italic(true)
+	return ui.ScrollbackPrintlnCommands(style.Render(llm.FormatInterruptedMarker(marker)), true)
+}
+
 // SetAgentResolver configures the function used to resolve agent names
 // during /handover. The function should match cmd.LoadAgent's signature.
 func (m *Model) SetAgentResolver(resolver func(name string, cfg *config.Config) (*agents.Agent, error)) {
@@ -1542,6 +1628,7 @@ func (m *Model) Init() tea.Cmd {
 	if m.chatRenderer != nil {
 		m.chatRenderer.SetMarkdownRenderer(m.renderMd)
 		m.chatRenderer.SetToolsExpanded(m.toolsExpanded)
+		m.chatRenderer.SetShowStats(m.messageStats)
 	}
 
 	// Handover auto-send: send the target agent's default prompt after restart
@@ -1706,6 +1793,7 @@ func isParentChatMessage(msg tea.Msg) bool {
 	case streamEventMsg,
 		tickMsg,
 		streamRenderTickMsg,
+		historyReflowTickMsg,
 		ui.SmoothTickMsg,
 		ui.WaveTickMsg,
 		ui.WavePauseMsg,
@@ -1833,10 +1921,11 @@ func (m *Model) Update(msg tea.Msg) (model tea.Model, cmd tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.applyWindowSize(msg)
 
-		// In alt screen mode, just clear screen (View() renders history)
-		// In inline mode, reprint history to scrollback after clearing
+		// In alt screen mode, View() renders history; blocks far above the
+		// viewport keep their old width until the background reflow reaches
+		// them. In inline mode, reprint history to scrollback after clearing.
 		if m.altScreen {
-			return m, nil
+			return m, m.scheduleHistoryReflow()
 		}
 		if len(m.messages) > 0 {
 			history := m.renderHistory()
@@ -1908,10 +1997,13 @@ func (m *Model) Update(msg tea.Msg) (model tea.Model, cmd tea.Cmd) {
 		return m, nil
 
 	case spinner.TickMsg:
-		if (m.streaming || m.sideQuestion.Running) && !m.pausedForExternalUI {
+		if (m.streaming || m.sideQuestion.Running || m.authLogin.login != nil) && !m.pausedForExternalUI {
 			var cmd tea.Cmd
 			m.spinner, cmd = m.spinner.Update(msg)
 			cmds = append(cmds, cmd)
+			if m.authLogin.login != nil && m.dialog.Type() == DialogAuthDeviceCode {
+				m.dialog.SetStatus(m.spinner.View() + " Waiting for approval...")
+			}
 		}
 
 	case tickMsg:
@@ -1919,6 +2011,9 @@ func (m *Model) Update(msg tea.Msg) (model tea.Model, cmd tea.Cmd) {
 			cmds = append(cmds, m.tickEvery())
 		}
 
+	case historyReflowTickMsg:
+		return m.handleHistoryReflowTick()
+
 	case streamRenderTickMsg:
 		m.streamRenderTickPending = false
 		// No explicit action is needed here: Bubble Tea re-renders after each Update.
@@ -1957,6 +2052,12 @@ func (m *Model) Update(msg tea.Msg) (model tea.Model, cmd tea.Cmd) {
 	case shareDoneMsg:
 		return m.handleShareDone(msg)
 
+	case authDeviceCodeMsg:
+		return m.handleAuthDeviceCode(msg)
+
+	case authLoginDoneMsg:
+		return m.handleAuthLoginDone(msg)
+
 	case chatGPTModelsLoadedMsg:
 		return m.applyChatGPTModelsLoaded(msg)
 
@@ -2047,6 +2148,11 @@ func (m *Model) Update(msg tea.Msg) (model tea.Model, cmd tea.Cmd) {
 			m.engine.ResetConversation()
 			m.engine.SetContextEstimateBaseline(0, 0)
 		}
+		if msg.tokensBefore > 0 {
+			tokensAfter := llm.EstimateMessageTokens(msg.result.ActiveMessages())
+			return m.showFooterSuccess(fmt.Sprintf("Conversation compacted (~%s → ~%s tokens).",
+				ui.FormatTokenCount(msg.tokensBefore), ui.FormatTokenCount(tokensAfter)))
+		}
 		return m.showFooterSuccess("Conversation compacted.")
 
 	case handoverDoneMsg:
@@ -2294,6 +2400,7 @@ func (m *Model) Update(msg tea.Msg) (model tea.Model, cmd tea.Cmd) {
 				var footerCmd tea.Cmd
 				if !errors.Is(ev.Err, context.Canceled) {
 					_, footerCmd = m.showFooterMessageWithTone(formatStreamErrorFooter(ev.Err), "error")
+					m.promptForAuthIfRequired(ev.Err, "")
 				}
 				// Stream errors are transient status, not durable conversation content.
 				// Force the viewport to repaint now so stale streamed rows do not linger
@@ -2325,6 +2432,9 @@ func (m *Model) Update(msg tea.Msg) (model tea.Model, cmd tea.Cmd) {
 						m.mergeUnpersistedInterruptedAssistant(salvageResult)
 					}
 				}
+				if errors.Is(ev.Err, context.Canceled) {
+					errorOutputCmds = append(errorOutputCmds, m.appendInterruptedMarker(salvageResult)...)
+				}
 
 				m.flushPendingSkillResults()
 				if cmd := m.applyPendingStreamModelSwitch(); cmd != nil {
@@ -2577,7 +2687,7 @@ func (m *Model) Update(msg tea.Msg) (model tea.Model, cmd tea.Cmd) {
 			if m.stats != nil {
 				m.stats.ScheduleRetryStart(ev.RetryWait)
 			}
-			m.setRetryStatus(ev.RetryStatus("Retrying stream", 1, "..."))
+			m.setRetryStatus(ev.RetryStatus(ui.RetryLabel(ev.RetryReason, "Retrying stream"), 1, "..."))
 
 		case ui.StreamEventImage:
 			m.setRetryStatus("")
@@ -2710,7 +2820,9 @@ func (m *Model) Update(msg tea.Msg) (model tea.Model, cmd tea.Cmd) {
 			// Clear callbacks
 			m.clearStreamCallbacks()
 
-			// Mark all text segments as complete and render
+			// Mark all text segments as complete and render. Inline mode
+			// prints the rest of the response to scrollback.
+			var doneScrollback []tea.Cmd
 			m.commitCurrentReasoningToStream()
 			if m.tracker != nil {
 				m.tracker.CompleteTextSegments(func(text string) string {
@@ -2735,10 +2847,10 @@ func (m *Model) Update(msg tea.Msg) (model tea.Model, cmd tea.Cmd) {
 					// In inline mode, print remaining content to scrollback
 					m.tracker.ForceCompletePendingTools()
 					result := m.tracker.FlushAllRemaining(m.width, 0, m.renderMd)
-					cmds = append(cmds, ui.ScrollbackPrintlnCommands(result.ToPrint, true)...)
+					doneScrollback = ui.ScrollbackPrintlnCommands(result.ToPrint, true)
 				}
 			} else if !m.altScreen {
-				cmds = append(cmds, ui.ScrollbackPrintlnCommands("", true)...)
+				doneScrollback = ui.ScrollbackPrintlnCommands("", true)
 			}
 
 			// Sync in-memory messages with persisted state.
@@ -2880,6 +2992,15 @@ func (m *Model) Update(msg tea.Msg) (model tea.Model, cmd tea.Cmd) {
 			if m.activeInterruptSeq == 0 {
 				m.clearPendingInterjection()
 			}
+
+			// Messages queued during this response go out as the next turn,
+			// after the response has reached scrollback. Cancelled and failed
+			// responses leave the queue alone.
+			if cmd := m.sendNextQueuedMessage(); cmd != nil {
+				cmds = append(cmds, tea.Sequence(append(doneScrollback, cmd)...))
+			} else {
+				cmds = append(cmds, doneScrollback...)
+			}
 		}
 
 		// Continue listening for more events unless we're done or got an error.
@@ -2988,6 +3109,10 @@ func (m *Model) Update(msg tea.Msg) (model tea.Model, cmd tea.Cmd) {
 		close(msg.Done)
 		return m, nil
 
+	case FooterWarningMsg:
+		m.SetFooterWarning(msg.Text)
+		return m, nil
+
 	case ResumeFromExternalUIMsg:
 		// Resume from external UI (ask_user or approval)
 		m.pausedForExternalUI = false
@@ -67,7 +67,15 @@ func AllCommands() []Command {
 			Name:        "stats",
 			Aliases:     []string{"st"},
 			Description: "Show current chat usage, cost, and context breakdown",
-			Usage:       "/stats",
+			Usage:       "/stats [messages [on|off]]",
+			Subcommands: []Subcommand{
+				{Name: "messages", Description: "Toggle timing and token stats under each response"},
+			},
+		},
+		{
+			Name:        "summary",
+			Description: "Show turns, tokens, tools and files changed in this session",
+			Usage:       "/summary",
 		},
 		{
 			Name:        "goal",
@@ -159,8 +167,8 @@ func AllCommands() []Command {
 		},
 		{
 			Name:        "export",
-			Description: "Export conversation as markdown",
-			Usage:       "/export [path]",
+			Description: "Export conversation as markdown or HTML",
+			Usage:       "/export [--stats] [html] [path]",
 		},
 		{
 			Name:        "thinking",
@@ -168,11 +176,29 @@ func AllCommands() []Command {
 			Description: "Toggle reasoning summary display for this session",
 			Usage:       "/thinking [off|status|collapsed|expanded|raw]",
 		},
+		{
+			Name:        "screen",
+			Description: "Switch between inline and full-screen rendering",
+			Usage:       "/screen [inline|alt]",
+			Subcommands: []Subcommand{
+				{Name: "inline", Description: "Render in the terminal's normal scrollback"},
+				{Name: "alt", Description: "Render full-screen with a scrollable viewport"},
+			},
+		},
 		{
 			Name:        "system",
 			Description: "Set custom system prompt",
 			Usage:       "/system <prompt>",
 		},
+		{
+			Name:        "context",
+			Description: "Show or toggle the injected project instructions (AGENTS.md)",
+			Usage:       "/context [on|off]",
+			Subcommands: []Subcommand{
+				{Name: "on", Description: "Inject project instructions again"},
+				{Name: "off", Description: "Stop injecting project instructions for this session"},
+			},
+		},
 		{
 			Name:        "file",
 			Aliases:     []string{"f"},
@@ -190,6 +216,15 @@ func AllCommands() []Command {
 			Description: "Manage approved directories",
 			Usage:       "/dirs [add|remove <path>]",
 		},
+		{
+			Name:        "allow",
+			Description: "Manage always-allowed shell command prefixes",
+			Usage:       "/allow [list|remove <prefix>]",
+			Subcommands: []Subcommand{
+				{Name: "list", Description: "List always-allowed command prefixes"},
+				{Name: "remove", Description: "Stop always allowing a command prefix"},
+			},
+		},
 		{
 			Name:        "worktree",
 			Aliases:     []string{"wt"},
@@ -246,9 +281,80 @@ func AllCommands() []Command {
 				{Name: "hard", Description: "Create a full summary of conversation history"},
 			},
 		},
+		{
+			Name:        "template",
+			Aliases:     []string{"t"},
+			Description: "Send a prompt template from config, filled in with a message",
+			Usage:       "/t <name> [message]",
+		},
+		{
+			Name:        "templates",
+			Description: "List prompt templates from config",
+			Usage:       "/templates",
+		},
+		{
+			Name:        "find",
+			Description: "Search the conversation; Ctrl+G/Alt+G step through matches",
+			Usage:       "/find <text>",
+		},
+		{
+			Name:        "paste",
+			Description: "Show or discard collapsed pasted text",
+			Usage:       "/paste [show|clear]",
+			Subcommands: []Subcommand{
+				{Name: "show", Description: "Show the text behind [Pasted text] placeholders (default)"},
+				{Name: "clear", Description: "Discard collapsed pastes"},
+			},
+		},
+		{
+			Name:        "fork",
+			Description: "Continue in a copy of this session, optionally from user message n",
+			Usage:       "/fork [n]",
+		},
+		{
+			Name:        "branches",
+			Description: "List this session's parent, sibling forks and forks; switch or delete one",
+			Usage:       "/branches",
+		},
+		{
+			Name:        "switch",
+			Description: "Switch in place to entry n of /branches, or to a session by #number or id",
+			Usage:       "/switch <n|#number|id>",
+		},
+		{
+			Name:        "pin",
+			Description: "Pin a user message so compaction keeps it verbatim",
+			Usage:       "/pin [n]",
+		},
+		{
+			Name:        "unpin",
+			Description: "Unpin a previously pinned user message",
+			Usage:       "/unpin [n]",
+		},
+		{
+			Name:        "edit",
+			Description: "Revise user message n (default: last) and replay the conversation from it",
+			Usage:       "/edit [--fork] [n]",
+		},
+		{
+			Name:        "retry",
+			Aliases:     []string{"regen"},
+			Description: "Regenerate the last response, optionally with another model",
+			Usage:       "/retry [provider:model]",
+		},
+		{
+			Name:        "continue",
+			Description: "Resume a response that was interrupted",
+			Usage:       "/continue",
+		},
+		{
+			Name:        "undo",
+			Description: "Remove the last exchange from the conversation",
+			Usage:       "/undo",
+		},
 		{
 			Name:        "resume",
-			Aliases:     []string{"r"},
+			Aliases:     []string{"r", "load"},
 			Description: "Browse and resume a previous session",
 			Usage:       "/resume [number|id]",
 		},
@@ -263,6 +369,11 @@ func AllCommands() []Command {
 			Description: "Hand conversation to another agent",
 			Usage:       "/handover @agent [provider:model]",
 		},
+		{
+			Name:        "agent",
+			Description: "Switch this conversation to another agent",
+			Usage:       "/agent <name>",
+		},
 	}
 }
 
@@ -416,6 +527,7 @@ func isStreamingLocalSlashCommand(input string) bool {
 		"pro":       true,
 		"title":     true,
 		"autotitle": true,
+		"paste":     true,
 	}
 	return localCommands[name]
 }
@@ -513,7 +625,12 @@ func (m *Model) ExecuteCommand(input string) (tea.Model, tea.Cmd) {
 	case "side":
 		return m.cmdSide(rawArgs)
 	case "stats":
+		if len(args) > 0 && strings.EqualFold(args[0], "messages") {
+			return m.cmdMessageStats(args[1:])
+		}
 		return m.cmdStats()
+	case "summary":
+		return m.cmdSummary()
 	case "goal":
 		return m.cmdGoal(args, rawArgs)
 	case "clear":
@@ -544,14 +661,20 @@ func (m *Model) ExecuteCommand(input string) (tea.Model, tea.Cmd) {
 		return m.cmdShare(args)
 	case "thinking":
 		return m.cmdThinking(args)
+	case "screen":
+		return m.cmdScreen(args)
 	case "system":
 		return m.cmdSystem(args)
+	case "context":
+		return m.cmdContext(args)
 	case "file":
 		return m.cmdFile(args)
 	case "shell":
 		return m.cmdShell(rawArgs)
 	case "dirs":
 		return m.cmdDirs(args)
+	case "allow":
+		return m.cmdAllow(args)
 	case "worktree":
 		return m.cmdWorktree(args)
 	case "mcp":
@@ -562,12 +685,40 @@ func (m *Model) ExecuteCommand(input string) (tea.Model, tea.Cmd) {
 		return m.cmdInspect()
 	case "compact":
 		return m.cmdCompress(args...)
+	case "template":
+		return m.cmdTemplate(rawArgs)
+	case "templates":
+		return m.cmdTemplates()
+	case "paste":
+		return m.cmdPaste(args, rawArgs)
+	case "find":
+		return m.cmdFind(rawArgs)
+	case "fork":
+		return m.cmdFork(args)
+	case "branches":
+		return m.cmdBranches()
+	case "switch":
+		return m.cmdSwitch(args)
+	case "pin":
+		return m.cmdPin(args, true)
+	case "unpin":
+		return m.cmdPin(args, false)
+	case "edit":
+		return m.cmdEdit(args)
+	case "retry":
+		return m.cmdRetry(args)
+	case "continue":
+		return m.cmdContinue(args)
+	case "undo":
+		return m.cmdUndo(args)
 	case "resume":
 		return m.cmdResume(args)
 	case "reload":
 		return m.cmdReload()
 	case "handover":
 		return m.cmdHandover(args)
+	case "agent":
+		return m.cmdAgent(args)
 	default:
 		return m.showSystemMessage(fmt.Sprintf("Command /%s is not yet implemented.", cmd.Name))
 	}
@@ -910,12 +1061,16 @@ func (m *Model) showHelpModal() (tea.Model, tea.Cmd) {
 				{"Ctrl+T", "MCP servers (tools)"},
 				{"Ctrl+O", "Inspect conversation context"},
 				{"Ctrl+E", "Expand/collapse tool and reasoning details"},
+				{"Alt+I", "Show/hide timing and token stats under responses"},
+				{"Alt+O", "Show a long tool result in full (tools expanded)"},
 			},
 		},
 		{
 			title: "Composer",
 			rows: [][2]string{
 				{"Enter", "Send message; while streaming, queue interjection"},
+				{"Ctrl+Q", "While streaming, queue message to send after the response"},
+				{"Ctrl+X", "Clear queued messages"},
 				{"Ctrl+J / Alt+Enter / Shift+Enter", "Insert newline"},
 				{"\\ + Enter", "Turn trailing backslash into a newline"},
 				{"/", "Open slash-command completions from an empty composer"},
@@ -932,6 +1087,8 @@ func (m *Model) showHelpModal() (tea.Model, tea.Cmd) {
 				{"PageUp / PageDown", "Scroll conversation"},
 				{"Up / Down", "Scroll when composer is empty; select queued interjections while streaming"},
 				{"Ctrl+Y", "Copy selected conversation text"},
+				{"Ctrl+F", "Find in conversation (/find)"},
+				{"Ctrl+G / Alt+G", "Next / previous match while searching; Esc closes the search"},
 			},
 		},
 		{
@@ -963,6 +1120,9 @@ func (m *Model) cmdClear() (tea.Model, tea.Cmd) {
 	if m.store != nil && m.sess != nil {
 		_ = m.store.UpdateStatus(context.Background(), m.sess.ID, session.StatusComplete)
 	}
+	if m.sess != nil {
+		m.toolMgr.ForgetSession(m.sess.ID)
+	}
 
 	// Create a new session to clear the conversation
 	// This preserves the old session in history while starting fresh
@@ -1876,6 +2036,9 @@ func (m *Model) cmdNew() (tea.Model, tea.Cmd) {
 	if m.store != nil && m.sess != nil {
 		_ = m.store.UpdateStatus(context.Background(), m.sess.ID, session.StatusComplete)
 	}
+	if m.sess != nil {
+		m.toolMgr.ForgetSession(m.sess.ID)
+	}
 
 	// Create new session with current settings
 	m.sess = &session.Session{
@@ -1991,8 +2154,8 @@ func (m *Model) cmdAutotitle() (tea.Model, tea.Cmd) {
 	if m.titleGenerationInFlight {
 		return m.showFooterError("Title generation is already running.")
 	}
-	if m.fastProvider == nil {
-		return m.showFooterError("Fast title generation is unavailable.")
+	if m.titleProvider() == nil {
+		return m.showFooterError("Title generation is unavailable.")
 	}
 	if m.store == nil {
 		return m.showFooterError("Session storage is disabled. Enable it in config with `sessions.enabled: true`.")
@@ -2197,14 +2360,48 @@ func (m *Model) cmdExport(args []string) (tea.Model, tea.Cmd) {
 		return m.showSystemMessage("No messages to export.")
 	}
 
+	// "--stats" adds each response's timing and token line to markdown exports.
+	includeStats := false
+	args = slices.DeleteFunc(slices.Clone(args), func(arg string) bool {
+		if arg == "--stats" {
+			includeStats = true
+			return true
+		}
+		return false
+	})
+
+	// "/export html [path]" or a path ending in .html picks the HTML format.
+	asHTML := false
+	if len(args) > 0 && strings.EqualFold(args[0], "html") {
+		asHTML = true
+		args = args[1:]
+	}
+
 	// Determine output path
 	var outputPath string
 	if len(args) > 0 {
 		outputPath = strings.Join(args, " ")
+		ext := strings.ToLower(filepath.Ext(outputPath))
+		asHTML = asHTML || ext == ".html" || ext == ".htm"
 	} else {
 		// Generate default filename
 		timestamp := time.Now().Format("2006-01-02_15-04-05")
-		outputPath = fmt.Sprintf("chat-export-%s.md", timestamp)
+		ext := "md"
+		if asHTML {
+			ext = "html"
+		}
+		outputPath = fmt.Sprintf("chat-export-%s.%s", timestamp, ext)
+	}
+
+	exportReasoningCfg := m.effectiveReasoningConfig()
+	includeReasoningSummaries := internalreasoning.ExportSummaries(exportReasoningCfg)
+	includeRawReasoning := internalreasoning.ExportRaw(exportReasoningCfg)
+	rawReasoningOmitted := strings.EqualFold(strings.TrimSpace(exportReasoningCfg.Export), config.ReasoningExportRaw) && !exportReasoningCfg.Raw
+	if asHTML {
+		return m.exportHTML(outputPath, session.ExportOptions{
+			IncludeReasoningSummaries: includeReasoningSummaries,
+			IncludeRawReasoning:       includeRawReasoning,
+		}, rawReasoningOmitted)
 	}
 
 	// Build markdown content
@@ -2220,10 +2417,6 @@ func (m *Model) cmdExport(args []string) (tea.Model, tea.Cmd) {
 	b.WriteString("\n---\n\n")
 
 	// Messages
-	exportReasoningCfg := m.effectiveReasoningConfig()
-	includeReasoningSummaries := internalreasoning.ExportSummaries(exportReasoningCfg)
-	includeRawReasoning := internalreasoning.ExportRaw(exportReasoningCfg)
-	rawReasoningOmitted := strings.EqualFold(strings.TrimSpace(exportReasoningCfg.Export), config.ReasoningExportRaw) && !exportReasoningCfg.Raw
 	for _, msg := range m.messages {
 		// Role header
 		if msg.Role == llm.RoleUser {
@@ -2263,6 +2456,9 @@ func (m *Model) cmdExport(args []string) (tea.Model, tea.Cmd) {
 				b.WriteString(msg.TextContent)
 				b.WriteString("\n\n")
 			}
+			if stats := msg.StatsLine(); includeStats && stats != "" {
+				b.WriteString(fmt.Sprintf("*%s*\n\n", stats))
+			}
 		}
 		b.WriteString("---\n\n")
 	}
@@ -2272,6 +2468,27 @@ func (m *Model) cmdExport(args []string) (tea.Model, tea.Cmd) {
 		return m.showSystemMessage(fmt.Sprintf("Failed to export: %v", err))
 	}
 
+	return m.finishExport(outputPath, rawReasoningOmitted)
+}
+
+// exportHTML writes the conversation as a self-contained HTML page, the same
+// document 'term-llm sessions export --format html' produces.
+func (m *Model) exportHTML(outputPath string, opts session.ExportOptions, rawReasoningOmitted bool) (tea.Model, tea.Cmd) {
+	sess := m.sess
+	if sess == nil {
+		sess = &session.Session{Provider: m.providerName, Model: m.modelName, CreatedAt: time.Now()}
+	}
+	html, err := session.ExportToHTML(sess, m.messages, opts)
+	if err == nil {
+		err = os.WriteFile(outputPath, []byte(html), 0644)
+	}
+	if err != nil {
+		return m.showSystemMessage(fmt.Sprintf("Failed to export: %v", err))
+	}
+	return m.finishExport(outputPath, rawReasoningOmitted)
+}
+
+func (m *Model) finishExport(outputPath string, rawReasoningOmitted bool) (tea.Model, tea.Cmd) {
 	m.setTextareaValue("")
 	message := fmt.Sprintf("Exported %d messages to %s.", len(m.messages), outputPath)
 	if rawReasoningOmitted {
@@ -2342,19 +2559,10 @@ func (m *Model) cmdFile(args []string) (tea.Model, tea.Cmd) {
 func (m *Model) cmdDirs(args []string) (tea.Model, tea.Cmd) {
 	if len(args) == 0 {
 		// List approved directories
-		if len(m.approvedDirs.Directories) == 0 {
-			return m.showSystemMessage("No approved directories.\n\nUse `/dirs add <path>` to approve a directory,\nor attach a file to be prompted for approval.")
+		if !m.approvedDirs.hasEntries() {
+			return m.showSystemMessage("No approved directories.\n\nUse `/dirs add <path>` to approve a directory,\nattach a file to be prompted for approval,\nor list project directories in `.term-llm/approvals.yaml`.")
 		}
-
-		var b strings.Builder
-		b.WriteString("## Approved Directories\n\n")
-		for _, dir := range m.approvedDirs.Directories {
-			b.WriteString(fmt.Sprintf("- `%s`\n", dir))
-		}
-		b.WriteString("\n**Commands:**\n")
-		b.WriteString("- `/dirs add <path>` - Approve a directory\n")
-		b.WriteString("- `/dirs remove <path>` - Revoke approval")
-		return m.showSystemMessage(b.String())
+		return m.showSystemMessage(approvedDirsListing(m.approvedDirs))
 	}
 
 	subCmd := strings.ToLower(args[0])
@@ -2388,6 +2596,36 @@ func (m *Model) cmdDirs(args []string) (tea.Model, tea.Cmd) {
 	}
 }
 
+func (m *Model) cmdAllow(args []string) (tea.Model, tea.Cmd) {
+	rules := m.shellPrefixRules()
+	if rules == nil {
+		return m.showSystemMessage("Allowed command prefixes are unavailable.")
+	}
+	if len(args) == 0 || strings.EqualFold(args[0], "list") {
+		return m.showSystemMessage(allowedPrefixesListing(rules.List()))
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "remove", "rm", "delete":
+		if len(args) < 2 {
+			return m.showSystemMessage("Usage: `/allow remove <prefix>`")
+		}
+		prefix := strings.Join(args[1:], " ")
+		remove := rules.Remove
+		if m.approvalMgr != nil {
+			remove = m.approvalMgr.RemoveShellPrefix
+		}
+		if err := remove(prefix); err != nil {
+			return m.showSystemMessage(fmt.Sprintf("Failed to remove prefix: %v", err))
+		}
+		m.setTextareaValue("")
+		return m.showFooterSuccess(fmt.Sprintf("No longer always allowing: %s", prefix))
+
+	default:
+		return m.showSystemMessage(fmt.Sprintf("Unknown subcommand: %s\n\nUsage:\n- `/allow` - List always-allowed command prefixes\n- `/allow remove <prefix>` - Stop always allowing a prefix", args[0]))
+	}
+}
+
 func (m *Model) cmdMcp(args []string) (tea.Model, tea.Cmd) {
 	// No args - open the MCP picker dialog
 	if len(args) == 0 {
@@ -2882,6 +3120,7 @@ func (m *Model) cmdCompress(args ...string) (tea.Model, tea.Cmd) {
 	}
 	model := m.modelName
 	provider := m.provider
+	tokensBefore := llm.EstimateMessageTokens(llmMessages)
 	phase := llm.PhaseCompacting
 	if mode == "hard" {
 		phase = llm.PhaseCompactingSummarizeHistory
@@ -2912,7 +3151,7 @@ func (m *Model) cmdCompress(args ...string) (tea.Model, tea.Cmd) {
 			} else {
 				result, err = llm.SoftCompact(ctx, provider, model, systemPrompt, llmMessages, compactConfig)
 			}
-			return compactDoneMsg{result: result, err: err}
+			return compactDoneMsg{result: result, err: err, tokensBefore: tokensBefore}
 		},
 		m.spinner.Tick,
 		m.tickEvery(),
@@ -2946,6 +3185,10 @@ func (m *Model) switchModelWithOptions(providerModel string, opts switchModelOpt
 	// Create new provider using the centralized factory
 	provider, err := llm.NewProviderByName(m.config, providerName, modelName)
 	if err != nil {
+		if m.promptForAuthIfRequired(err, providerModel) {
+			m.setTextareaValue("")
+			return m, nil
+		}
 		return m.showSystemMessage(fmt.Sprintf("Failed to switch model: %v", err))
 	}
 	m.clearPendingStreamModelSwitch()
@@ -2956,7 +3199,19 @@ func (m *Model) switchModelWithOptions(providerModel string, opts switchModelOpt
 	// Update model state
 	m.provider = provider
 	// Preserve existing tool registry when creating new engine
-	m.engine = llm.NewEngine(provider, m.engine.Tools())
+	oldEngine := m.engine
+	m.engine = llm.NewEngine(provider, oldEngine.Tools())
+	if m.config != nil {
+		// An invalid timeout setting was already reported at startup.
+		_ = m.engine.ApplyToolsConfig(m.config.Tools)
+	}
+	m.engine.InheritRuntime(oldEngine)
+	// Context windows differ per provider/model; recompute limits so the status
+	// line and auto-compaction track the new model, keeping the usage baseline.
+	if m.config != nil {
+		m.engine.ConfigureContextManagement(provider, providerName, modelName, m.config.AutoCompact)
+		m.engine.SetContextEstimateBaseline(oldEngine.ContextEstimateBaseline())
+	}
 	m.providerName = provider.Name()
 	m.providerKey = providerName
 	m.modelName = modelName
@@ -2989,7 +3244,10 @@ func (m *Model) switchModelWithOptions(providerModel string, opts switchModelOpt
 	m.setTextareaValue("")
 	guardianErr := m.refreshGuardianReviewer(providerName, modelName)
 
-	if m.sess != nil && len(m.messages) > 0 {
+	// Re-selecting the current model (e.g. to pick up new credentials) is
+	// not a swap worth marking in the conversation.
+	sameModel := oldProvider == providerName && oldModel == modelName
+	if m.sess != nil && len(m.messages) > 0 && !sameModel {
 		marker := llm.ModelSwapMarker{
 			FromProvider: oldProvider,
 			FromModel:    oldModel,
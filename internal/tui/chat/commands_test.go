@@ -11,7 +11,6 @@ import (
 	"slices"
 	"strconv"
 	"strings"
-	"syscall"
 	"testing"
 	"time"
 
@@ -21,9 +20,11 @@ import (
 	"github.com/samsaffron/term-llm/internal/agents/gist"
 	"github.com/samsaffron/term-llm/internal/config"
 	"github.com/samsaffron/term-llm/internal/llm"
+	render "github.com/samsaffron/term-llm/internal/render/chat"
 	runpkg "github.com/samsaffron/term-llm/internal/run"
 	"github.com/samsaffron/term-llm/internal/session"
 	"github.com/samsaffron/term-llm/internal/skills"
+	"github.com/samsaffron/term-llm/internal/testutil"
 	"github.com/samsaffron/term-llm/internal/tools"
 	"github.com/samsaffron/term-llm/internal/ui"
 	"github.com/samsaffron/term-llm/internal/worktree"
@@ -98,8 +99,8 @@ func TestAllCommandsIncludesTitleCommands(t *testing.T) {
 func TestAllCommandsIncludesStats(t *testing.T) {
 	for _, cmd := range AllCommands() {
 		if cmd.Name == "stats" {
-			if cmd.Usage != "/stats" {
-				t.Fatalf("stats usage = %q, want /stats", cmd.Usage)
+			if cmd.Usage != "/stats [messages [on|off]]" {
+				t.Fatalf("stats usage = %q, want /stats [messages [on|off]]", cmd.Usage)
 			}
 			return
 		}
@@ -210,6 +211,22 @@ type mockStore struct {
 	compactSession   string
 	compactErr       error
 	metricUpdates    []metricUpdate
+	forks            []forkCall
+	truncations      []int
+}
+
+type forkCall struct {
+	sessionID string
+	atSeq     int
+}
+
+func (s *mockStore) Fork(_ context.Context, sessionID string, atMessageSequence int) (*session.Session, error) {
+	s.forks = append(s.forks, forkCall{sessionID: sessionID, atSeq: atMessageSequence})
+	return &session.Session{ID: fmt.Sprintf("fork-%d", len(s.forks)), ParentID: sessionID, Kind: session.KindFork}, nil
+}
+
+func (s *mockStore) Forks(_ context.Context, parentID string) ([]session.SessionSummary, error) {
+	return nil, nil
 }
 
 type metricUpdate struct {
@@ -227,6 +244,10 @@ func (s *mockStore) UpdateMetrics(_ context.Context, id string, llmTurns, toolCa
 	return nil
 }
 
+func (s *mockStore) UpdateModelMetrics(ctx context.Context, id string, _ session.UsageModel, llmTurns, toolCalls, inputTokens, outputTokens, cachedInputTokens, cacheWriteTokens int) error {
+	return s.UpdateMetrics(ctx, id, llmTurns, toolCalls, inputTokens, outputTokens, cachedInputTokens, cacheWriteTokens)
+}
+
 func (s *mockStore) Get(_ context.Context, id string) (*session.Session, error) {
 	if s.getErr != nil {
 		return nil, s.getErr
@@ -359,6 +380,18 @@ func (s *mockStore) UpdateStatus(_ context.Context, id string, status session.Se
 	return nil
 }
 
+func (s *mockStore) TruncateMessages(_ context.Context, sessionID string, fromSequence int) error {
+	s.truncations = append(s.truncations, fromSequence)
+	var kept []session.Message
+	for _, msg := range s.messages[sessionID] {
+		if msg.Sequence < fromSequence {
+			kept = append(kept, msg)
+		}
+	}
+	s.messages[sessionID] = kept
+	return nil
+}
+
 func (s *mockStore) CompactMessages(_ context.Context, sessionID string, messages []session.Message) error {
 	if s.compactErr != nil {
 		return s.compactErr
@@ -808,14 +841,16 @@ func TestCmdAutotitleWhileInFlightShowsFooterError(t *testing.T) {
 	}
 }
 
-func TestCmdAutotitleWithoutFastProviderShowsFooterError(t *testing.T) {
+func TestCmdAutotitleWithoutProviderShowsFooterError(t *testing.T) {
 	m := newCmdTestModel(&mockStore{})
+	m.fastProvider = nil
+	m.provider = nil
 	m.sess = &session.Session{ID: "autotitle-no-fast", Provider: "mock", Model: "mock-model", Mode: session.ModeChat}
 	m.messages = []session.Message{{SessionID: m.sess.ID, Role: llm.RoleUser, TextContent: "Name this later", Sequence: 0}}
 
 	result, cmd := m.ExecuteCommand("/autotitle")
 	m = result.(*Model)
-	if got := m.footerMessage; got != "Fast title generation is unavailable." {
+	if got := m.footerMessage; got != "Title generation is unavailable." {
 		t.Fatalf("footer = %q", got)
 	}
 	if got := m.footerMessageTone; got != "error" {
@@ -2107,6 +2142,21 @@ func TestUpdate_CompactDone_SuccessUsesFooterMessage(t *testing.T) {
 	}
 }
 
+func TestUpdate_CompactDone_ReportsTokenEstimates(t *testing.T) {
+	m := newTestChatModel(false)
+	m.streaming = true
+
+	result, _ := m.Update(compactDoneMsg{
+		result:       &llm.CompactionResult{NewMessages: []llm.Message{llm.UserText("summary")}},
+		tokensBefore: 12500,
+	})
+	rm := result.(*Model)
+
+	if got := rm.footerMessage; !strings.HasPrefix(got, "Conversation compacted (~12k → ~") || !strings.HasSuffix(got, " tokens).") {
+		t.Fatalf("footer = %q, want before/after token estimates", got)
+	}
+}
+
 func TestUpdate_CompactDone_PersistErrorDoesNotMutateMemory(t *testing.T) {
 	store := &mockStore{compactErr: errors.New("disk full")}
 	m := newTestChatModel(false)
@@ -2807,6 +2857,27 @@ func TestSwitchModel_UpdatesSessionMetadata(t *testing.T) {
 	}
 }
 
+func TestSwitchModel_RecomputesContextLimit(t *testing.T) {
+	llm.RegisterConfigLimits([]llm.ConfigModelLimit{{Provider: "debug", Model: "fast", InputLimit: 50_000}})
+	defer llm.RegisterConfigLimits(nil)
+
+	m := newCmdTestModel(&mockStore{})
+	m.config = &config.Config{}
+	m.engine = llm.NewEngine(llm.NewMockProvider("old"), nil)
+	m.engine.SetCompaction(1_000_000, llm.DefaultCompactionConfig())
+	m.engine.SetContextEstimateBaseline(12_000, 3)
+
+	result, _ := m.switchModel("debug:fast")
+	rm := result.(*Model)
+
+	if got := rm.engine.InputLimit(); got != 50_000 {
+		t.Fatalf("input limit after switch = %d, want 50000", got)
+	}
+	if total, _ := rm.engine.ContextEstimateBaseline(); total != 12_000 {
+		t.Fatalf("context baseline after switch = %d, want 12000", total)
+	}
+}
+
 func TestSwitchModel_WithExistingHistoryPersistsModelSwapEventMarker(t *testing.T) {
 	store := &mockStore{}
 	m := newCmdTestModel(store)
@@ -3108,23 +3179,7 @@ func waitForProcessExit(t *testing.T, pid int) {
 }
 
 func processHasExited(pid int) bool {
-	err := syscall.Kill(pid, 0)
-	if err != nil {
-		return errors.Is(err, syscall.ESRCH)
-	}
-	if runtime.GOOS == "linux" {
-		state, ok := linuxProcState(pid)
-		return ok && state == 'Z'
-	}
-	return false
-}
-
-func linuxProcState(pid int) (byte, bool) {
-	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
-	if err != nil {
-		return 0, false
-	}
-	return procStatState(data)
+	return testutil.ProcessHasExited(pid)
 }
 
 func procStatState(data []byte) (byte, bool) {
@@ -4865,3 +4920,268 @@ func TestHandleShareDonePublicUpdateExplainsVisibility(t *testing.T) {
 		t.Fatalf("dialog did not explain visibility: %q", m.dialog.Content())
 	}
 }
+
+func TestCmdPinTogglesUserMessagePin(t *testing.T) {
+	m := newCmdTestModel(&mockStore{})
+	m.sess = &session.Session{ID: "sess-pin"}
+	m.messages = []session.Message{
+		*session.NewMessage(m.sess.ID, llm.UserText("keep this spec"), 0),
+		*session.NewMessage(m.sess.ID, llm.AssistantText("ok"), 1),
+		*session.NewMessage(m.sess.ID, llm.UserText("latest question"), 2),
+	}
+
+	m.ExecuteCommand("/pin")
+	if !m.messages[2].Pinned || m.messages[0].Pinned {
+		t.Fatalf("/pin should pin only the latest user message, got %+v", []bool{m.messages[0].Pinned, m.messages[2].Pinned})
+	}
+
+	m.ExecuteCommand("/pin 1")
+	if !m.messages[0].Pinned {
+		t.Fatal("/pin 1 should pin the first user message")
+	}
+
+	m.ExecuteCommand("/unpin 2")
+	if m.messages[2].Pinned {
+		t.Fatal("/unpin 2 should clear the pin on the second user message")
+	}
+
+	m.ExecuteCommand("/pin 5")
+	if !strings.Contains(m.footerMessage, "between 1 and 2") {
+		t.Fatalf("footer = %q, want range error", m.footerMessage)
+	}
+}
+
+func TestCmdRetryDropsLastResponseAndRestreams(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	store := &mockStore{}
+	m := newTestChatModel(false)
+	m.store = store
+	m.sess = &session.Session{ID: "sess-retry"}
+	transcript := []llm.Message{
+		llm.UserText("first"),
+		llm.AssistantText("one"),
+		llm.UserText("second"),
+		{Role: llm.RoleAssistant, Parts: []llm.Part{{Type: llm.PartToolCall, ToolCall: &llm.ToolCall{ID: "call-1", Name: "shell"}}}},
+		{Role: llm.RoleTool, Parts: []llm.Part{{Type: llm.PartToolResult, ToolResult: &llm.ToolResult{ID: "call-1", Name: "shell", Content: "ok"}}}},
+		llm.AssistantText("two"),
+	}
+	for i, msg := range transcript {
+		row := session.NewMessage(m.sess.ID, msg, i)
+		row.ID = int64(i + 1)
+		m.messages = append(m.messages, *row)
+		store.ensureMessages()
+		store.messages[m.sess.ID] = append(store.messages[m.sess.ID], *row)
+	}
+
+	result, _ := m.ExecuteCommand("/retry")
+	m = result.(*Model)
+	if !m.streaming {
+		t.Fatalf("/retry should start a new response; footer=%q", m.footerMessage)
+	}
+	if len(m.messages) != 3 || m.messages[2].TextContent != "second" {
+		t.Fatalf("messages = %+v, want history up to the last user message", m.messages)
+	}
+	if len(store.truncations) != 1 || store.truncations[0] != 3 {
+		t.Fatalf("truncations = %v, want one from sequence 3", store.truncations)
+	}
+	if got := len(store.messages[m.sess.ID]); got != 3 {
+		t.Fatalf("store kept %d messages, want 3", got)
+	}
+
+	m.ExecuteCommand("/retry")
+	if !strings.Contains(m.footerMessage, "Wait for the response") {
+		t.Fatalf("footer = %q, want streaming guard", m.footerMessage)
+	}
+
+	m.streaming = false
+	m.ExecuteCommand("/regen")
+	if !strings.Contains(m.footerMessage, "no response to retry") {
+		t.Fatalf("footer = %q, want no-response guard", m.footerMessage)
+	}
+	if len(store.truncations) != 1 {
+		t.Fatalf("guarded /retry should not touch the store, truncations = %v", store.truncations)
+	}
+}
+
+func TestCmdUndoWalksBackExchanges(t *testing.T) {
+	store := &mockStore{}
+	m := newCmdTestModel(store)
+	m.sess = &session.Session{ID: "sess-undo", UserTurns: 2}
+	transcript := []llm.Message{
+		llm.UserText("first"),
+		llm.AssistantText("one"),
+		llm.UserText("wrong paste"),
+		{Role: llm.RoleAssistant, Parts: []llm.Part{{Type: llm.PartToolCall, ToolCall: &llm.ToolCall{ID: "call-1", Name: "shell"}}}},
+		{Role: llm.RoleTool, Parts: []llm.Part{{Type: llm.PartToolResult, ToolResult: &llm.ToolResult{ID: "call-1", Name: "shell", Content: "ok"}}}},
+		llm.AssistantText("two"),
+	}
+	for i, msg := range transcript {
+		row := session.NewMessage(m.sess.ID, msg, i)
+		m.messages = append(m.messages, *row)
+		store.ensureMessages()
+		store.messages[m.sess.ID] = append(store.messages[m.sess.ID], *row)
+	}
+
+	m.ExecuteCommand("/undo")
+	if len(m.messages) != 2 || m.messages[1].TextContent != "one" {
+		t.Fatalf("messages = %+v, want the first exchange only", m.messages)
+	}
+	if len(store.truncations) != 1 || store.truncations[0] != 2 {
+		t.Fatalf("truncations = %v, want one from sequence 2", store.truncations)
+	}
+	if m.sess.UserTurns != 1 {
+		t.Fatalf("UserTurns = %d, want 1", m.sess.UserTurns)
+	}
+
+	m.ExecuteCommand("/undo")
+	if len(m.messages) != 0 || len(store.messages[m.sess.ID]) != 0 {
+		t.Fatalf("second /undo should remove the first exchange, got %d local / %d stored", len(m.messages), len(store.messages[m.sess.ID]))
+	}
+
+	m.ExecuteCommand("/undo")
+	if !strings.Contains(m.footerMessage, "Nothing to undo") {
+		t.Fatalf("footer = %q, want nothing-to-undo warning", m.footerMessage)
+	}
+
+	m.streaming = true
+	m.messages = []session.Message{*session.NewMessage(m.sess.ID, llm.UserText("pending"), 0)}
+	m.ExecuteCommand("/undo")
+	if len(m.messages) != 1 || !strings.Contains(m.footerMessage, "Wait for the response") {
+		t.Fatalf("/undo while streaming should be blocked; footer = %q", m.footerMessage)
+	}
+}
+
+func TestUndoSummaryDescribesExchange(t *testing.T) {
+	removed := []session.Message{
+		*session.NewMessage("s", llm.UserText("wrong paste"), 0),
+		*session.NewMessage("s", llm.Message{Role: llm.RoleAssistant, Parts: []llm.Part{{Type: llm.PartToolCall, ToolCall: &llm.ToolCall{ID: "1", Name: "shell"}}}}, 1),
+		*session.NewMessage("s", llm.AssistantText("done"), 2),
+	}
+	got := undoSummary(removed)
+	for _, want := range []string{`"wrong paste"`, `"done"`, "(1 tool call)"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("undoSummary = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestCmdForkRelaunchesOnForkedSession(t *testing.T) {
+	store := &mockStore{}
+	m := newCmdTestModel(store)
+	m.sess = &session.Session{ID: "sess-fork"}
+	m.messages = []session.Message{
+		*session.NewMessage(m.sess.ID, llm.UserText("first"), 0),
+		*session.NewMessage(m.sess.ID, llm.AssistantText("one"), 1),
+		*session.NewMessage(m.sess.ID, llm.UserText("second"), 2),
+		*session.NewMessage(m.sess.ID, llm.AssistantText("two"), 3),
+	}
+
+	result, _ := m.ExecuteCommand("/fork 1")
+	rm := result.(*Model)
+	if len(store.forks) != 1 || store.forks[0] != (forkCall{sessionID: "sess-fork", atSeq: 1}) {
+		t.Fatalf("forks = %+v, want fork of sess-fork at sequence 1", store.forks)
+	}
+	if got := rm.RequestedResumeSessionID(); got != "fork-1" {
+		t.Fatalf("RequestedResumeSessionID() = %q, want fork-1", got)
+	}
+
+	m = newCmdTestModel(store)
+	m.sess = &session.Session{ID: "sess-fork"}
+	m.messages = rm.messages
+	m.ExecuteCommand("/fork")
+	if store.forks[1].atSeq != -1 {
+		t.Fatalf("/fork without n should copy everything, got atSeq %d", store.forks[1].atSeq)
+	}
+
+	m = newCmdTestModel(store)
+	m.sess = &session.Session{ID: "sess-fork"}
+	m.messages = rm.messages
+	m.ExecuteCommand("/fork 3")
+	if len(store.forks) != 2 || !strings.Contains(m.footerMessage, "between 1 and 2") {
+		t.Fatalf("out-of-range /fork should not fork; forks=%d footer=%q", len(store.forks), m.footerMessage)
+	}
+}
+
+func TestAllowCommandListsAndRemovesPrefixes(t *testing.T) {
+	m := newTestChatModel(false)
+	m.SetApprovalManager(tools.NewApprovalManager(tools.NewToolPermissions()))
+	rules := m.approvalMgr.ShellPrefixRules()
+	if err := rules.Add("go test"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	t.Cleanup(func() { _ = rules.Remove("go test") })
+
+	if got := allowedPrefixesListing(rules.List()); !strings.Contains(got, "`go test`") {
+		t.Fatalf("listing = %q, want go test", got)
+	}
+
+	result, _ := m.ExecuteCommand("/allow remove go test")
+	m = result.(*Model)
+	if !strings.Contains(m.footerMessage, "No longer always allowing: go test") {
+		t.Fatalf("footer = %q", m.footerMessage)
+	}
+	if m.approvalMgr.ShellPrefixRules().Allows("go test ./...") {
+		t.Fatal("removed prefix should no longer skip the approval prompt")
+	}
+	if got := allowedPrefixesListing(rules.List()); !strings.Contains(got, "No always-allowed command prefixes") {
+		t.Fatalf("listing after remove = %q", got)
+	}
+}
+
+func TestCmdExportHTMLWritesSelfContainedPage(t *testing.T) {
+	m := newCmdTestModel(&mockStore{})
+	m.sess = &session.Session{ID: "export-html", Name: "Export me", CreatedAt: time.Now()}
+	m.messages = []session.Message{
+		{Role: llm.RoleUser, TextContent: "show code", Parts: []llm.Part{{Type: llm.PartText, Text: "show code"}}},
+		{Role: llm.RoleAssistant, Parts: []llm.Part{{Type: llm.PartText, Text: "```go\nfunc main() {}\n```"}}},
+	}
+	dir := t.TempDir()
+
+	explicit := filepath.Join(dir, "chat.txt")
+	m.ExecuteCommand("/export html " + explicit)
+	data, err := os.ReadFile(explicit)
+	if err != nil {
+		t.Fatalf("read export: %v", err)
+	}
+	for _, want := range []string{"<!doctype html>", "Export me", "show code", `class="language-go"`, "<span style="} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("HTML export missing %q", want)
+		}
+	}
+	if got := m.footerMessage; got != "Exported 2 messages to "+explicit+"." {
+		t.Fatalf("footer = %q", got)
+	}
+
+	byExtension := filepath.Join(dir, "chat.html")
+	m.ExecuteCommand("/export " + byExtension)
+	if data, err := os.ReadFile(byExtension); err != nil || !strings.Contains(string(data), "<!doctype html>") {
+		t.Fatalf(".html path did not produce HTML: %v", err)
+	}
+}
+
+func TestCmdStatsMessagesTogglesMessageStats(t *testing.T) {
+	m := newCmdTestModel(&mockStore{})
+	m.chatRenderer = render.NewRenderer(80, 24)
+
+	result, _ := m.ExecuteCommand("/stats messages")
+	m = result.(*Model)
+	if !m.messageStats || !m.chatRenderer.ShowStats() {
+		t.Fatalf("/stats messages should turn stats on (model %v, renderer %v)", m.messageStats, m.chatRenderer.ShowStats())
+	}
+	if m.dialog.IsOpen() {
+		t.Fatal("/stats messages should not open the stats dialog")
+	}
+
+	result, _ = m.ExecuteCommand("/stats messages on")
+	m = result.(*Model)
+	if !m.messageStats {
+		t.Fatal("/stats messages on should leave stats on")
+	}
+
+	result, _ = m.ExecuteCommand("/stats messages off")
+	m = result.(*Model)
+	if m.messageStats || m.chatRenderer.ShowStats() {
+		t.Fatal("/stats messages off should turn stats off")
+	}
+}
@@ -0,0 +1,51 @@
+package chat
+
+import (
+	tea "charm.land/bubbletea/v2"
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+// continuePrompt asks the model to pick up an interrupted answer. The partial
+// answer is already in history, so the model sees exactly where it stopped.
+const continuePrompt = "Your previous response was interrupted. Continue exactly where it stopped, without repeating what you already wrote."
+
+// cmdContinue resumes a response that was cancelled mid-stream.
+func (m *Model) cmdContinue(args []string) (tea.Model, tea.Cmd) {
+	m.setTextareaValue("")
+	if len(args) > 0 {
+		return m.showSystemMessage("Usage: /continue")
+	}
+	if m.streaming {
+		return m.showFooterWarning("Wait for the response to finish before using /continue.")
+	}
+	if m.sess == nil {
+		return m.showFooterWarning("Nothing to continue.")
+	}
+
+	m.messagesMu.Lock()
+	interrupted := m.lastResponseInterrupted()
+	m.messagesMu.Unlock()
+	if !interrupted {
+		return m.showFooterWarning("The last response was not interrupted; nothing to continue.")
+	}
+	return m.sendMessage(continuePrompt)
+}
+
+// lastResponseInterrupted reports whether the conversation ends with an
+// interrupted marker. Later model-switch markers are skipped, so switching
+// model and then continuing works. Callers must hold messagesMu.
+func (m *Model) lastResponseInterrupted() bool {
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		msg := m.messages[i].ToLLMMessage()
+		if msg.Role != llm.RoleEvent {
+			return false
+		}
+		if _, ok := llm.ParseInterruptedMarker(msg); ok {
+			return true
+		}
+		if _, ok := llm.ParseModelSwapMarker(msg); !ok {
+			return false
+		}
+	}
+	return false
+}
@@ -27,6 +27,10 @@ const (
 	DialogWorktreeRecovery
 	DialogShareChoice
 	DialogContent
+	DialogAuthRequired
+	DialogAuthDeviceCode
+	DialogBranches
+	DialogBranchDelete
 )
 
 // DialogModel handles modal dialogs
@@ -45,8 +49,16 @@ type DialogModel struct {
 	dirApprovalPath    string
 	dirApprovalOptions []string
 
-	// Worktree recovery confirmation specific
-	worktreeRecoveryQuestion string
+	// Question shown above the options of a confirmation dialog (worktree
+	// recovery, sign-in)
+	question string
+
+	// Sign-in progress line, updated while a device-code login is polling
+	status string
+
+	// Model picker annotations (context limit, efforts, premium quota),
+	// computed on first render of each item and keyed by item ID.
+	modelDetails map[string]string
 
 	// Static content modal specific
 	contentLines  []string
@@ -100,7 +112,9 @@ func (d *DialogModel) Close() {
 	d.contentLines = nil
 	d.contentScroll = 0
 	d.contentFooter = ""
-	d.worktreeRecoveryQuestion = ""
+	d.question = ""
+	d.status = ""
+	d.modelDetails = nil
 }
 
 // ShowModelPicker opens the model picker dialog.
@@ -113,6 +127,7 @@ func (d *DialogModel) ShowModelPicker(currentProviderModel string, providers []P
 	d.cursor = 0
 	d.query = ""
 	d.items = nil
+	d.modelDetails = make(map[string]string)
 
 	for _, p := range providers {
 		for _, model := range p.Models {
@@ -178,6 +193,20 @@ func (d *DialogModel) ShowSessionList(items []DialogItem, currentSessionID strin
 	d.filtered = d.items
 }
 
+// ShowBranches opens the /branches list with the current session selected.
+func (d *DialogModel) ShowBranches(items []DialogItem, currentSessionID string) {
+	d.ShowSessionList(items, currentSessionID)
+	d.dialogType = DialogBranches
+	d.title = "Branches"
+}
+
+// ShowBranchDeleteConfirmation asks whether to delete a branch picked in
+// /branches.
+func (d *DialogModel) ShowBranchDeleteConfirmation(question string) {
+	d.ShowWorktreeConfirmation("Delete branch", question, "Yes — delete it", "No — keep it")
+	d.dialogType = DialogBranchDelete
+}
+
 // ShowDirApproval opens the directory approval dialog
 func (d *DialogModel) ShowDirApproval(filePath string, options []string) {
 	d.dialogType = DialogDirApproval
@@ -225,7 +254,7 @@ func (d *DialogModel) ShowWorktreeConfirmation(title, question, yesLabel, noLabe
 	d.contentLines = nil
 	d.contentScroll = 0
 	d.contentFooter = ""
-	d.worktreeRecoveryQuestion = strings.TrimSpace(question)
+	d.question = strings.TrimSpace(question)
 	d.items = []DialogItem{
 		{ID: "yes", Label: yesLabel},
 		{ID: "no", Label: noLabel},
@@ -233,6 +262,39 @@ func (d *DialogModel) ShowWorktreeConfirmation(title, question, yesLabel, noLabe
 	d.filtered = d.items
 }
 
+// ShowAuthRequired asks whether to sign in to a provider whose credentials
+// are missing or expired.
+func (d *DialogModel) ShowAuthRequired(title, question string) {
+	d.ShowWorktreeConfirmation(title, question, "Yes — sign in now", "No — not now")
+	d.dialogType = DialogAuthRequired
+}
+
+// ShowAuthDeviceCode shows the code to enter at url while a device-code
+// sign-in is polling. SetStatus updates the progress line below it.
+func (d *DialogModel) ShowAuthDeviceCode(title, url, code string) {
+	d.dialogType = DialogAuthDeviceCode
+	d.title = title
+	d.cursor = 0
+	d.query = ""
+	d.question = fmt.Sprintf("1. Open %s in any browser\n2. Enter the code %s", url, code)
+	d.status = ""
+	d.items = []DialogItem{{ID: "cancel", Label: "Cancel sign-in"}}
+	d.filtered = d.items
+}
+
+// SetStatus sets the progress line of a sign-in dialog.
+func (d *DialogModel) SetStatus(status string) {
+	d.status = status
+}
+
+func (d *DialogModel) hasQuestion() bool {
+	switch d.dialogType {
+	case DialogWorktreeRecovery, DialogAuthRequired, DialogAuthDeviceCode, DialogBranchDelete:
+		return true
+	}
+	return false
+}
+
 // ShowShareChoice asks how an already-shared session should be shared.
 func (d *DialogModel) ShowShareChoice() {
 	d.dialogType = DialogShareChoice
@@ -519,6 +581,9 @@ func (d *DialogModel) viewModelPicker() string {
 		if item.Selected {
 			b.WriteString(mutedStyle.Render(" (current)"))
 		}
+		if detail := d.modelDetail(item); detail != "" {
+			b.WriteString(mutedStyle.Render("  " + detail))
+		}
 
 		if i < len(items)-1 {
 			b.WriteString("\n")
@@ -528,6 +593,50 @@ func (d *DialogModel) viewModelPicker() string {
 	return borderStyle.Render(b.String())
 }
 
+// modelDetail returns the cached annotation for a model picker item,
+// computing it the first time the item is shown. Only visible rows are
+// looked up, so opening the picker stays fast with long model lists.
+func (d *DialogModel) modelDetail(item DialogItem) string {
+	if detail, ok := d.modelDetails[item.ID]; ok {
+		return detail
+	}
+	detail := modelPickerDetail(item.Category, strings.TrimPrefix(item.ID, item.Category+":"))
+	if d.modelDetails != nil {
+		d.modelDetails[item.ID] = detail
+	}
+	return detail
+}
+
+// modelPickerDetail describes a model for the picker: its effective input
+// limit, the reasoning efforts it accepts, and for Copilot whether it uses
+// premium requests. Facts that are unknown for the provider are left out, so
+// the result may be empty.
+func modelPickerDetail(provider, model string) string {
+	var parts []string
+	if limit := llm.FormatTokenCount(llm.InputLimitForProviderModel(provider, model)); limit != "" {
+		parts = append(parts, limit+" ctx")
+	}
+	if _, effort := llm.BaseModelAndEffortForProvider(provider, model); effort != "" {
+		parts = append(parts, effort+" effort")
+	} else {
+		efforts := llm.ReasoningEffortsForProviderModel(provider, model)
+		if len(efforts) == 0 {
+			efforts = llm.EffortVariantsFor(model)
+		}
+		if len(efforts) > 0 {
+			parts = append(parts, "efforts: "+strings.Join(efforts, "/"))
+		}
+	}
+	if multiplier, known := llm.CopilotPremiumMultiplier(provider, model); known {
+		if multiplier > 0 {
+			parts = append(parts, fmt.Sprintf("premium ×%g", multiplier))
+		} else {
+			parts = append(parts, "included")
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
 func (d *DialogModel) contentWidth() int {
 	if d.width <= 0 {
 		return 100
@@ -654,12 +763,16 @@ func (d *DialogModel) viewStandardDialog() string {
 		b.WriteString("\n\n")
 	}
 
-	// Worktree recovery asks an explicit yes/no question.
-	if d.dialogType == DialogWorktreeRecovery && d.worktreeRecoveryQuestion != "" {
+	// Worktree recovery and sign-in ask an explicit question.
+	if d.hasQuestion() && d.question != "" {
 		b.WriteString("\n")
 		questionWidth := max(20, dialogWidth-6)
 		questionStyle := lipgloss.NewStyle().Width(questionWidth)
-		b.WriteString(questionStyle.Render(wrap.String(d.worktreeRecoveryQuestion, questionWidth)))
+		b.WriteString(questionStyle.Render(wrap.String(d.question, questionWidth)))
+		b.WriteString("\n\n")
+	}
+	if d.dialogType == DialogAuthDeviceCode && d.status != "" {
+		b.WriteString(mutedStyle.Render(d.status))
 		b.WriteString("\n\n")
 	}
 
@@ -683,7 +796,11 @@ func (d *DialogModel) viewStandardDialog() string {
 	}
 
 	b.WriteString("\n\n")
-	b.WriteString(mutedStyle.Render("j/k navigate · enter select · esc cancel"))
+	if d.dialogType == DialogBranches {
+		b.WriteString(mutedStyle.Render("j/k navigate · enter switch · d delete · esc close"))
+	} else {
+		b.WriteString(mutedStyle.Render("j/k navigate · enter select · esc cancel"))
+	}
 
 	return borderStyle.Render(b.String())
 }
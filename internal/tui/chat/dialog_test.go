@@ -244,3 +244,27 @@ func TestShowModelPicker_DuplicateModelName(t *testing.T) {
 		t.Fatalf("expected selected beta:shared-model, got %v", sel)
 	}
 }
+
+func TestModelPickerShowsModelDetails(t *testing.T) {
+	d := NewDialogModel(nil)
+	d.ShowModelPicker("openai:gpt-5.5", []ProviderInfo{
+		{Name: "openai", Models: []string{"gpt-5.5", "gpt-5.5-high"}},
+		{Name: "no-such-provider", Models: []string{"mystery-model"}},
+	}, nil)
+	if len(d.modelDetails) != 0 {
+		t.Fatalf("details computed before render: %v", d.modelDetails)
+	}
+
+	view := d.View()
+	for _, want := range []string{"922K ctx", "efforts: ", "high effort"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+	if detail := d.modelDetails["no-such-provider:mystery-model"]; detail != "" {
+		t.Errorf("unknown model detail = %q, want empty", detail)
+	}
+	if !strings.Contains(view, "no-such-provider:mystery-model") {
+		t.Errorf("unknown model missing from view:\n%s", view)
+	}
+}
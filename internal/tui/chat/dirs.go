@@ -7,12 +7,40 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/samsaffron/term-llm/internal/pathutil"
+	"github.com/samsaffron/term-llm/internal/tools"
+	"gopkg.in/yaml.v3"
 )
 
-// ApprovedDirs stores the list of approved directories
+// projectApprovalsPath is where a project lists its own approved
+// directories, relative to the project root.
+var projectApprovalsPath = filepath.Join(".term-llm", "approvals.yaml")
+
+// ApprovedDirs stores the list of approved directories. Entries may be
+// wildcard patterns such as ~/src/*/docs, where each * matches one path
+// element.
 type ApprovedDirs struct {
 	ApprovedAt  time.Time `json:"approved_at"`
 	Directories []string  `json:"directories"`
+
+	// ProjectFile is the .term-llm/approvals.yaml found above the working
+	// directory, if any. Its ProjectDirectories are merged with Directories
+	// but are never saved to or removed from the global store.
+	ProjectFile        string   `json:"-"`
+	ProjectDirectories []string `json:"-"`
+
+	projectErr error
+	// projectIgnored are project entries outside the project root. A
+	// checked-out repository must not be able to approve the rest of the
+	// filesystem, so they are reported by /dirs instead.
+	projectIgnored []string
+}
+
+// projectApprovals is the format of .term-llm/approvals.yaml. Relative
+// directories are resolved against the project root.
+type projectApprovals struct {
+	Directories []string `yaml:"directories"`
 }
 
 // getApprovedDirsPath returns the path to the approved_dirs.json file
@@ -82,9 +110,112 @@ func SaveApprovedDirs(dirs *ApprovedDirs) error {
 	return nil
 }
 
+// LoadProject merges the approved directories of the nearest
+// .term-llm/approvals.yaml at or above workDir. Entries that can't be
+// approved, such as the filesystem root, are skipped, and entries outside
+// the project root are ignored. A load error is also kept for ProjectError.
+func (d *ApprovedDirs) LoadProject(workDir string) error {
+	d.projectErr = d.loadProject(workDir)
+	return d.projectErr
+}
+
+// ProjectError returns the error from the last LoadProject, if any.
+func (d *ApprovedDirs) ProjectError() error {
+	return d.projectErr
+}
+
+func (d *ApprovedDirs) loadProject(workDir string) error {
+	path, ok := findProjectApprovals(workDir)
+	if !ok {
+		return nil
+	}
+	d.ProjectFile = path
+	d.ProjectDirectories = nil
+	d.projectIgnored = nil
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var cfg projectApprovals
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	root, err := canonicalApprovedDir(filepath.Dir(filepath.Dir(path)))
+	if err != nil {
+		return err
+	}
+	for _, raw := range cfg.Directories {
+		entry := strings.TrimSpace(raw)
+		if entry == "" {
+			continue
+		}
+		if expanded, err := ExpandUserPath(entry); err == nil && !filepath.IsAbs(expanded) {
+			entry = filepath.Join(root, expanded)
+		}
+		dir, err := canonicalApprovedDir(entry)
+		if err != nil || isFilesystemRoot(approvedDirBase(dir)) {
+			continue
+		}
+		if !pathutil.IsWithin(approvedDirBase(dir), root) {
+			d.projectIgnored = append(d.projectIgnored, strings.TrimSpace(raw))
+			continue
+		}
+		d.ProjectDirectories = append(d.ProjectDirectories, dir)
+	}
+	return nil
+}
+
+// findProjectApprovals walks up from dir looking for .term-llm/approvals.yaml.
+func findProjectApprovals(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		path := filepath.Join(dir, projectApprovalsPath)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// isApprovedDirPattern reports whether an approved directory entry is a
+// wildcard pattern.
+func isApprovedDirPattern(dir string) bool {
+	return strings.ContainsAny(dir, "*?[")
+}
+
+// approvedDirBase returns the part of an approved directory entry before
+// its first wildcard element: the entry itself when it has no wildcards.
+func approvedDirBase(dir string) string {
+	if !isApprovedDirPattern(dir) {
+		return dir
+	}
+	elems := strings.Split(dir, string(filepath.Separator))
+	for i, elem := range elems {
+		if isApprovedDirPattern(elem) {
+			base := strings.Join(elems[:i], string(filepath.Separator))
+			if base == "" {
+				return string(filepath.Separator)
+			}
+			return base
+		}
+	}
+	return dir
+}
+
 // canonicalApprovedDir expands and canonicalizes a directory for storage in the
 // approved directory list. If the directory exists, symlinks are resolved at
-// approval time so later symlink retargeting does not move the approval.
+// approval time so later symlink retargeting does not move the approval. For
+// a wildcard pattern, symlinks are resolved in the part before the first
+// wildcard.
 func canonicalApprovedDir(dir string) (string, error) {
 	dir, err := ExpandUserPath(dir)
 	if err != nil {
@@ -94,12 +225,39 @@ func canonicalApprovedDir(dir string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("failed to resolve path: %w", err)
 	}
+	absDir = filepath.Clean(absDir)
+	if isApprovedDirPattern(absDir) {
+		if _, err := filepath.Match(absDir, ""); err != nil {
+			return "", fmt.Errorf("invalid pattern %q: %w", dir, err)
+		}
+		base := approvedDirBase(absDir)
+		if realBase, err := filepath.EvalSymlinks(base); err == nil {
+			absDir = filepath.Join(realBase, strings.TrimPrefix(absDir, base))
+		}
+		return absDir, nil
+	}
 	if realDir, err := filepath.EvalSymlinks(absDir); err == nil {
 		absDir = realDir
 	}
 	return filepath.Clean(absDir), nil
 }
 
+// pathUnderApprovedDir reports whether path is dir or inside it. A wildcard
+// dir matches path's leading elements element by element.
+func pathUnderApprovedDir(path, dir string) bool {
+	if !isApprovedDirPattern(dir) {
+		return strings.HasPrefix(path, dir+string(filepath.Separator)) || path == dir
+	}
+	sep := string(filepath.Separator)
+	pathElems := strings.Split(path, sep)
+	dirElems := strings.Split(dir, sep)
+	if len(pathElems) < len(dirElems) {
+		return false
+	}
+	matched, err := filepath.Match(dir, strings.Join(pathElems[:len(dirElems)], sep))
+	return err == nil && matched
+}
+
 func isFilesystemRoot(path string) bool {
 	path = filepath.Clean(path)
 	return filepath.Dir(path) == path
@@ -111,30 +269,23 @@ func (d *ApprovedDirs) IsPathApproved(path string) bool {
 	if err != nil {
 		return false
 	}
-	// Resolve to absolute path
-	absPath, err := filepath.Abs(path)
+	// Resolve symlinks, including those above a path that doesn't exist yet,
+	// so a link inside an approved directory can't lead outside it.
+	realPath, err := tools.ResolveApprovalPath(path)
 	if err != nil {
 		return false
 	}
 
-	// Resolve symlinks
-	realPath, err := filepath.EvalSymlinks(absPath)
-	if err != nil {
-		// If file doesn't exist yet, use the parent directory
-		realPath = absPath
-	}
-
 	// Check against approved directories
-	for _, dir := range d.Directories {
-		// Resolve the approved directory too
-		absDir, err := filepath.Abs(dir)
-		if err != nil {
-			continue
-		}
-
-		// Check if path is under this directory
-		if strings.HasPrefix(realPath, absDir+string(filepath.Separator)) || realPath == absDir {
-			return true
+	for _, dirs := range [][]string{d.Directories, d.ProjectDirectories} {
+		for _, dir := range dirs {
+			absDir, err := filepath.Abs(dir)
+			if err != nil {
+				continue
+			}
+			if pathUnderApprovedDir(realPath, absDir) {
+				return true
+			}
 		}
 	}
 
@@ -148,8 +299,9 @@ func (d *ApprovedDirs) AddDirectory(dir string) error {
 		return err
 	}
 
-	// Block root directory after canonicalization, so symlinks to root cannot be approved.
-	if isFilesystemRoot(absDir) {
+	// Block root directory after canonicalization, so symlinks to root cannot be
+	// approved. Patterns can't start with a wildcard either: /* covers everything.
+	if isFilesystemRoot(approvedDirBase(absDir)) {
 		return fmt.Errorf("cannot approve root directory")
 	}
 
@@ -196,6 +348,11 @@ func (d *ApprovedDirs) RemoveDirectory(dir string) error {
 	}
 
 	if !found {
+		for _, projectDir := range d.ProjectDirectories {
+			if projectDir == absDir {
+				return fmt.Errorf("%s is approved by %s; edit that file to remove it", dir, d.ProjectFile)
+			}
+		}
 		return fmt.Errorf("directory not in approved list: %s", dir)
 	}
 
@@ -239,3 +396,66 @@ type DirApprovalRequest struct {
 	OnApprove func(dir string)
 	OnDeny    func()
 }
+
+func (d *ApprovedDirs) hasEntries() bool {
+	return len(d.Directories) > 0 || d.ProjectFile != ""
+}
+
+// approvedDirsListing renders /dirs output, grouping entries by where they
+// come from.
+func approvedDirsListing(d *ApprovedDirs) string {
+	var b strings.Builder
+	b.WriteString("## Approved Directories\n\n")
+	if len(d.Directories) > 0 {
+		b.WriteString("**Global:**\n")
+		for _, dir := range d.Directories {
+			b.WriteString(fmt.Sprintf("- `%s`\n", dir))
+		}
+	}
+	if d.ProjectFile != "" {
+		if len(d.Directories) > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(fmt.Sprintf("**Project** (`%s`, read-only):\n", d.ProjectFile))
+		if d.projectErr != nil {
+			b.WriteString(fmt.Sprintf("- Failed to load: %v\n", d.projectErr))
+		}
+		for _, dir := range d.ProjectDirectories {
+			b.WriteString(fmt.Sprintf("- `%s`\n", dir))
+		}
+		for _, entry := range d.projectIgnored {
+			b.WriteString(fmt.Sprintf("- Ignored `%s`: outside the project root\n", entry))
+		}
+	}
+	b.WriteString("\n**Commands:**\n")
+	b.WriteString("- `/dirs add <path>` - Approve a directory or pattern such as `~/src/*/docs`\n")
+	b.WriteString("- `/dirs remove <path>` - Revoke approval")
+	return b.String()
+}
+
+// shellPrefixRules returns the always-allowed command prefixes, shared with
+// the approval manager so removals take effect immediately.
+func (m *Model) shellPrefixRules() *tools.ShellPrefixRules {
+	if m.approvalMgr != nil {
+		return m.approvalMgr.ShellPrefixRules()
+	}
+	rules, err := tools.LoadShellPrefixRules()
+	if err != nil {
+		return nil
+	}
+	return rules
+}
+
+// allowedPrefixesListing renders /allow output.
+func allowedPrefixesListing(prefixes []string) string {
+	if len(prefixes) == 0 {
+		return "No always-allowed command prefixes.\n\nChoose **Always allow** when approving a shell command to add one."
+	}
+	var b strings.Builder
+	b.WriteString("**Always-allowed command prefixes:**\n")
+	for _, prefix := range prefixes {
+		fmt.Fprintf(&b, "- `%s`\n", prefix)
+	}
+	b.WriteString("\nUse `/allow remove <prefix>` to stop allowing one.")
+	return b.String()
+}
@@ -0,0 +1,193 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/session"
+)
+
+// pendingMessageEdit is a user message loaded into the composer by /edit. The
+// next send replaces it and drops everything after it.
+type pendingMessageEdit struct {
+	index     int   // position in m.messages
+	messageID int64 // guards against the history changing underneath the edit
+	position  int   // 1-based number among active user messages, for notices
+	fork      bool  // replay in a forked session instead of truncating this one
+}
+
+// cmdEdit loads a user message into the composer for revision. With no number
+// it takes the latest user message; otherwise n is the 1-based position among
+// active user messages, as with /pin. Sending the edited text removes the
+// original message and everything after it, then submits the new text, so the
+// conversation replays from that point. --fork leaves this session untouched
+// and replays in a copy instead.
+func (m *Model) cmdEdit(args []string) (tea.Model, tea.Cmd) {
+	m.setTextareaValue("")
+	fork := false
+	var rest []string
+	for _, arg := range args {
+		if arg == "--fork" {
+			fork = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	if len(rest) > 1 {
+		return m.showSystemMessage("Usage: /edit [--fork] [n]")
+	}
+	if m.streaming {
+		return m.showFooterWarning("Wait for the response to finish before using /edit.")
+	}
+	if m.worktreeOperationBusy() {
+		return m.showFooterWarning("Wait for the current worktree operation to finish before using /edit.")
+	}
+	if m.sess == nil {
+		return m.showFooterWarning("No user messages to edit.")
+	}
+	if fork && m.store == nil {
+		return m.showSystemMessage("Session storage is disabled.")
+	}
+
+	m.messagesMu.Lock()
+	candidates := m.pinCandidateIndexes()
+	if len(candidates) == 0 {
+		m.messagesMu.Unlock()
+		return m.showFooterWarning("No user messages to edit.")
+	}
+	pos := len(candidates)
+	if len(rest) == 1 {
+		n, err := strconv.Atoi(strings.TrimSpace(rest[0]))
+		if err != nil || n < 1 || n > len(candidates) {
+			m.messagesMu.Unlock()
+			return m.showFooterError(fmt.Sprintf("Message number must be between 1 and %d.", len(candidates)))
+		}
+		pos = n
+	}
+	idx := candidates[pos-1]
+	target := m.messages[idx]
+	m.messagesMu.Unlock()
+
+	m.pendingEdit = &pendingMessageEdit{index: idx, messageID: target.ID, position: pos, fork: fork}
+	m.setTextareaValue(target.TextContent)
+	m.textarea.Focus()
+	hint := "Enter replays from here, dropping later messages; Esc cancels."
+	if fork {
+		hint = "Enter replays from here in a forked session; Esc cancels."
+	}
+	return m.showFooterMuted(fmt.Sprintf("Editing message %d. %s", pos, hint))
+}
+
+// cancelEdit abandons a pending /edit and clears the composer.
+func (m *Model) cancelEdit() (tea.Model, tea.Cmd) {
+	m.pendingEdit = nil
+	m.setTextareaValue("")
+	m.pasteChunks = nil
+	return m.showFooterMuted("Edit cancelled.")
+}
+
+// submitEdit replaces the message being edited with content and replays the
+// conversation from it.
+func (m *Model) submitEdit(content string) (tea.Model, tea.Cmd) {
+	edit := m.pendingEdit
+	if m.streaming {
+		return m.showFooterWarning("Wait for the response to finish before replaying an edit.")
+	}
+	if m.worktreeOperationBusy() {
+		return m.showFooterWarning("Wait for the current worktree operation to finish before sending.")
+	}
+	if strings.TrimSpace(content) == "" {
+		return m.showFooterWarning("The edited message is empty; Esc cancels the edit.")
+	}
+
+	m.messagesMu.Lock()
+	if edit.index >= len(m.messages) || m.messages[edit.index].ID != edit.messageID || m.messages[edit.index].Role != llm.RoleUser {
+		m.messagesMu.Unlock()
+		m.pendingEdit = nil
+		return m.showFooterWarning("The message being edited is no longer in the conversation; press Enter again to send as a new message.")
+	}
+	target := m.messages[edit.index]
+	removed := append([]session.Message(nil), m.messages[edit.index:]...)
+	m.messagesMu.Unlock()
+
+	seq, err := m.storedSequence(target)
+	if err != nil {
+		return m.showFooterError(fmt.Sprintf("Edit failed: %v", err))
+	}
+	m.pendingEdit = nil
+
+	if edit.fork {
+		return m.forkAndReplay(seq, content)
+	}
+
+	// The session summary comes from the first user message; let the edited
+	// text replace it.
+	editsFirst := edit.index == m.firstUserMessageIndex()
+	if err := m.truncateHistory(edit.index, seq); err != nil {
+		return m.showFooterError(fmt.Sprintf("Edit failed: %v", err))
+	}
+	for _, msg := range removed {
+		if msg.Role == llm.RoleUser && m.sess.UserTurns > 0 {
+			m.sess.UserTurns--
+		}
+	}
+	if editsFirst {
+		m.sess.Summary = ""
+	}
+
+	notice := fmt.Sprintf("✎ Edited message %d", edit.position)
+	if dropped := len(removed) - 1; dropped > 0 {
+		notice += fmt.Sprintf(", dropped %d later message", dropped)
+		if dropped > 1 {
+			notice += "s"
+		}
+	}
+	model, cmd := m.sendMessage(content)
+	if m.altScreen {
+		return model, cmd
+	}
+	theme := m.styles.Theme()
+	return model, tea.Sequence(tea.Println(lipgloss.NewStyle().Foreground(theme.Muted).Render(notice)), cmd)
+}
+
+// forkAndReplay copies the session without the message at fromSequence and
+// anything after it, then relaunches chat on the copy and sends content there.
+func (m *Model) forkAndReplay(fromSequence int, content string) (tea.Model, tea.Cmd) {
+	if fromSequence < 0 {
+		return m.showFooterError("Edit failed: the message has not been saved yet.")
+	}
+	forker, ok := m.store.(session.Forker)
+	if !ok {
+		return m.showFooterError("Edit failed: this session store does not support forking.")
+	}
+	ctx := context.Background()
+	fork, err := forker.Fork(ctx, m.sess.ID, -1)
+	if err == nil {
+		err = session.TruncateMessages(ctx, m.store, fork.ID, fromSequence)
+	}
+	if err != nil {
+		return m.showFooterError(fmt.Sprintf("Fork failed: %v", err))
+	}
+	m.setTextareaValue("")
+	// The relaunched chat sends this as its first message, as after /handover.
+	m.pendingHandoverAutoSend = content
+	return m.requestResumeSession(fork.ID)
+}
+
+// firstUserMessageIndex returns the index of the first user message in
+// m.messages, or -1.
+func (m *Model) firstUserMessageIndex() int {
+	m.messagesMu.Lock()
+	defer m.messagesMu.Unlock()
+	for i, msg := range m.messages {
+		if msg.Role == llm.RoleUser && !llm.IsInternalCompactionSummaryText(msg.TextContent) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,165 @@
+package chat
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/session"
+)
+
+func newEditTestModel(t *testing.T) (*Model, *session.SQLiteStore) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	store, err := session.NewSQLiteStore(session.Config{Enabled: true, Path: filepath.Join(t.TempDir(), "sessions.db")})
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	ctx := context.Background()
+	sess := &session.Session{ID: session.NewID(), Provider: "mock", Model: "mock-model", Summary: "first", CompactionSeq: -1}
+	if err := store.Create(ctx, sess); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	transcript := []llm.Message{
+		llm.UserText("first"),
+		llm.AssistantText("one"),
+		llm.UserText("secnod with a typo"),
+		{Role: llm.RoleAssistant, Parts: []llm.Part{{Type: llm.PartToolCall, ToolCall: &llm.ToolCall{ID: "call-1", Name: "shell"}}}},
+		{Role: llm.RoleTool, Parts: []llm.Part{{Type: llm.PartToolResult, ToolResult: &llm.ToolResult{ID: "call-1", Name: "shell", Content: "ok"}}}},
+		llm.AssistantText("two"),
+		llm.UserText("third"),
+		llm.AssistantText("three"),
+	}
+	for _, msg := range transcript {
+		if err := store.AddMessage(ctx, sess.ID, session.NewMessage(sess.ID, msg, -1)); err != nil {
+			t.Fatalf("AddMessage: %v", err)
+		}
+	}
+	sess.UserTurns = 3
+	messages, err := store.GetMessages(ctx, sess.ID, 0, 0)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+
+	m := newTestChatModel(false)
+	m.store = store
+	m.sess = sess
+	m.messages = messages
+	return m, store
+}
+
+func TestCmdEditTruncatesAndReplaysThroughStore(t *testing.T) {
+	m, store := newEditTestModel(t)
+
+	result, _ := m.ExecuteCommand("/edit 2")
+	m = result.(*Model)
+	if m.pendingEdit == nil || m.textarea.Value() != "secnod with a typo" {
+		t.Fatalf("/edit 2 should load the second user message, textarea=%q pending=%+v", m.textarea.Value(), m.pendingEdit)
+	}
+
+	m.setTextareaValue("second, fixed")
+	result, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEnter})
+	m = result.(*Model)
+	if !m.streaming {
+		t.Fatalf("submitting the edit should start a response; footer=%q", m.footerMessage)
+	}
+	if m.pendingEdit != nil {
+		t.Fatal("pending edit should be cleared after submit")
+	}
+
+	var texts []string
+	for _, msg := range m.messages {
+		texts = append(texts, msg.TextContent)
+	}
+	if want := []string{"first", "one", "second, fixed"}; strings.Join(texts, "|") != strings.Join(want, "|") {
+		t.Fatalf("in-memory messages = %q, want %q", texts, want)
+	}
+
+	stored, err := store.GetMessages(context.Background(), m.sess.ID, 0, 0)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	texts = texts[:0]
+	for _, msg := range stored {
+		texts = append(texts, msg.TextContent)
+	}
+	if want := []string{"first", "one", "second, fixed"}; strings.Join(texts, "|") != strings.Join(want, "|") {
+		t.Fatalf("stored messages = %q, want %q", texts, want)
+	}
+	if m.sess.UserTurns != 2 {
+		t.Fatalf("UserTurns = %d, want 2", m.sess.UserTurns)
+	}
+	if got, _ := store.Get(context.Background(), m.sess.ID); got == nil || got.UserTurns != 2 {
+		t.Fatalf("stored session user turns = %+v, want 2", got)
+	}
+}
+
+func TestCmdEditResetsEngineConversation(t *testing.T) {
+	m, _ := newEditTestModel(t)
+	provider := useResetCountingEngine(m)
+
+	m.ExecuteCommand("/edit 2")
+	m.setTextareaValue("second, fixed")
+	result, _ := m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEnter})
+	m = result.(*Model)
+	if !m.streaming {
+		t.Fatalf("submitting the edit should start a response; footer=%q", m.footerMessage)
+	}
+	if got := provider.resets.Load(); got != 1 {
+		t.Fatalf("provider conversation resets = %d, want 1 so the replaced turns are not chained on", got)
+	}
+}
+
+func TestCmdEditForkLeavesOriginalAndRelaunchesOnCopy(t *testing.T) {
+	m, store := newEditTestModel(t)
+	ctx := context.Background()
+
+	m.ExecuteCommand("/edit --fork 1")
+	m.setTextareaValue("first, reworded")
+	m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEnter})
+
+	forkID := m.RequestedResumeSessionID()
+	if forkID == "" || forkID == m.sess.ID {
+		t.Fatalf("resume session = %q, want a new fork", forkID)
+	}
+	if got := m.RequestedHandoverAutoSend(); got != "first, reworded" {
+		t.Fatalf("auto-send = %q, want the edited text", got)
+	}
+	if original, _ := store.GetMessages(ctx, m.sess.ID, 0, 0); len(original) != 8 {
+		t.Fatalf("original session has %d messages, want all 8", len(original))
+	}
+	if forked, _ := store.GetMessages(ctx, forkID, 0, 0); len(forked) != 0 {
+		t.Fatalf("fork has %d messages, want none before the edited first message", len(forked))
+	}
+	if fork, _ := store.Get(ctx, forkID); fork == nil || fork.ParentID != m.sess.ID {
+		t.Fatalf("fork = %+v, want parent %s", fork, m.sess.ID)
+	}
+}
+
+func TestCmdEditRefusedWhileStreamingAndCancelledByEsc(t *testing.T) {
+	m, _ := newEditTestModel(t)
+
+	m.streaming = true
+	m.ExecuteCommand("/edit")
+	if m.pendingEdit != nil || !strings.Contains(m.footerMessage, "Wait for the response") {
+		t.Fatalf("/edit while streaming: pending=%+v footer=%q", m.pendingEdit, m.footerMessage)
+	}
+	m.streaming = false
+
+	m.ExecuteCommand("/edit")
+	if m.textarea.Value() != "third" {
+		t.Fatalf("/edit should default to the last user message, got %q", m.textarea.Value())
+	}
+	m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if m.pendingEdit != nil || m.textarea.Value() != "" {
+		t.Fatalf("Esc should cancel the edit, pending=%+v textarea=%q", m.pendingEdit, m.textarea.Value())
+	}
+	if len(m.messages) != 8 {
+		t.Fatalf("cancelled edit changed history: %d messages", len(m.messages))
+	}
+}
@@ -272,6 +272,9 @@ func (m *Model) attachFile(path string) (tea.Model, tea.Cmd) {
 	}
 
 	// Path is approved, attach the file
+	if isImageFile(path) {
+		return m.attachImageFile(path)
+	}
 	attachment, err := AttachFile(path)
 	if err != nil {
 		return m.showSystemMessage(fmt.Sprintf("Failed to attach file: %v", err))
@@ -315,6 +318,17 @@ func (m *Model) attachFiles(pattern string) (tea.Model, tea.Cmd) {
 	var attached []string
 	var totalSize int64
 	for _, path := range paths {
+		if isImageFile(path) {
+			data, err := os.ReadFile(path)
+			if err != nil || len(data) > maxPastedImageSize {
+				continue
+			}
+			m.addImageAttachment(data, detectImageMediaType(data))
+			m.selectedImage = -1
+			attached = append(attached, filepath.Base(path))
+			totalSize += int64(len(data))
+			continue
+		}
 		attachment, err := AttachFile(path)
 		if err != nil {
 			continue // Skip files that can't be read
@@ -225,3 +225,133 @@ func TestAttachFileRejectsOverTwentyMegabytes(t *testing.T) {
 		t.Fatalf("AttachFile() error = %v, want 20MB limit", err)
 	}
 }
+
+func TestApprovedDirsWildcardPatterns(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	dirs := &ApprovedDirs{}
+	if err := dirs.AddDirectory("~/src/*/docs"); err != nil {
+		t.Fatalf("AddDirectory(pattern) error = %v", err)
+	}
+	realHome, err := filepath.EvalSymlinks(home)
+	if err != nil {
+		realHome = home
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "~/src/app/docs/guide.md", want: true},
+		{path: "~/src/app/docs/deep/new/file.md", want: true},
+		{path: "~/src/app/docs", want: true},
+		{path: "~/src/app/src/main.go", want: false},
+		{path: "~/src/app/nested/docs/guide.md", want: false},
+		{path: filepath.Join(realHome, "src", "lib", "docs", "x.md"), want: true},
+	}
+	for _, tt := range tests {
+		if got := dirs.IsPathApproved(tt.path); got != tt.want {
+			t.Errorf("IsPathApproved(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+
+	if err := dirs.AddDirectory("/*/docs"); err == nil {
+		t.Error("AddDirectory(/*/docs) error = nil, want error for a pattern covering the root")
+	}
+	if err := dirs.RemoveDirectory("~/src/*/docs"); err != nil || len(dirs.Directories) != 0 {
+		t.Errorf("RemoveDirectory(pattern) = %v, left %#v", err, dirs.Directories)
+	}
+}
+
+func TestApprovedDirsDoNotFollowSymlinksOutOfApprovedDir(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	projectDir := t.TempDir()
+	outsideDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outsideDir, "secret.txt"), []byte("x"), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	if err := os.Symlink(outsideDir, filepath.Join(projectDir, "etc")); err != nil {
+		t.Skipf("symlink unavailable: %v", err)
+	}
+
+	dirs := &ApprovedDirs{}
+	if err := dirs.AddDirectory(projectDir); err != nil {
+		t.Fatalf("AddDirectory() error = %v", err)
+	}
+	if dirs.IsPathApproved(filepath.Join(projectDir, "etc", "secret.txt")) {
+		t.Error("an existing file through a symlink out of the approved directory should not be approved")
+	}
+	if dirs.IsPathApproved(filepath.Join(projectDir, "etc", "new", "file.txt")) {
+		t.Error("a new path through a symlink out of the approved directory should not be approved")
+	}
+	if !dirs.IsPathApproved(filepath.Join(projectDir, "new", "file.txt")) {
+		t.Error("a new path inside the approved directory should be approved")
+	}
+}
+
+func TestApprovedDirsLoadProjectFile(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	root := t.TempDir()
+	shared := t.TempDir()
+	workDir := filepath.Join(root, "pkg", "sub")
+	if err := os.MkdirAll(filepath.Join(root, ".term-llm"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.MkdirAll(workDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	config := "directories:\n  - .\n  - " + shared + "\n  - /\n"
+	if err := os.WriteFile(filepath.Join(root, ".term-llm", "approvals.yaml"), []byte(config), 0o600); err != nil {
+		t.Fatalf("write approvals: %v", err)
+	}
+
+	dirs := &ApprovedDirs{Directories: []string{}}
+	if err := dirs.LoadProject(workDir); err != nil {
+		t.Fatalf("LoadProject() error = %v", err)
+	}
+	if len(dirs.ProjectDirectories) != 1 {
+		t.Fatalf("ProjectDirectories = %#v, want only the project root (outside entries ignored)", dirs.ProjectDirectories)
+	}
+	if !dirs.IsPathApproved(filepath.Join(workDir, "main.go")) {
+		t.Fatal("project entries should be approved")
+	}
+	if dirs.IsPathApproved(filepath.Join(shared, "notes.md")) {
+		t.Fatal("a project file must not approve directories outside the project")
+	}
+	if dirs.IsPathApproved(filepath.Join(t.TempDir(), "other.txt")) {
+		t.Fatal("paths outside the project entries should not be approved")
+	}
+
+	err := dirs.RemoveDirectory(root)
+	if err == nil || !strings.Contains(err.Error(), "approvals.yaml") {
+		t.Fatalf("RemoveDirectory(project entry) error = %v, want a hint to edit the project file", err)
+	}
+	if len(dirs.ProjectDirectories) != 1 {
+		t.Fatal("project entries must not be removed")
+	}
+
+	listing := approvedDirsListing(dirs)
+	if !strings.Contains(listing, "**Project** (`"+filepath.Join(root, ".term-llm", "approvals.yaml")+"`, read-only)") {
+		t.Fatalf("/dirs should label project entries, got:\n%s", listing)
+	}
+	if !strings.Contains(listing, "Ignored `"+shared+"`: outside the project root") {
+		t.Fatalf("/dirs should warn about the ignored entry, got:\n%s", listing)
+	}
+}
+
+func TestApprovedDirsLoadProjectReportsInvalidFile(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".term-llm"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".term-llm", "approvals.yaml"), []byte("directories: [\n"), 0o600); err != nil {
+		t.Fatalf("write approvals: %v", err)
+	}
+	dirs := &ApprovedDirs{}
+	if err := dirs.LoadProject(root); err == nil || dirs.ProjectError() == nil {
+		t.Fatal("LoadProject() should report an unparseable file")
+	}
+}
@@ -0,0 +1,146 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/samsaffron/term-llm/internal/llm"
+	render "github.com/samsaffron/term-llm/internal/render/chat"
+)
+
+// findState is the in-conversation search started by /find. In alt-screen
+// mode matches are positions in the viewport content; inline, history lives
+// in the terminal scrollback, so each match's Line is a message index.
+type findState struct {
+	query   string
+	matches []render.FindMatch
+	current int
+}
+
+func (f *findState) active() bool {
+	return f.query != ""
+}
+
+// cmdFind searches the conversation and jumps to the most recent match.
+// Without a query it steps to the next (older) match of the active search.
+func (m *Model) cmdFind(rawArgs string) (tea.Model, tea.Cmd) {
+	m.setTextareaValue("")
+	query := strings.TrimSpace(rawArgs)
+	if query == "" {
+		if m.find.active() {
+			return m.stepFind(-1)
+		}
+		return m.showSystemMessage("Usage: /find <text>")
+	}
+
+	m.find = findState{query: query}
+	m.refreshFindMatches()
+	if len(m.find.matches) == 0 {
+		m.find = findState{}
+		return m.showFooterWarning(fmt.Sprintf("No matches for %q.", query))
+	}
+	m.find.current = len(m.find.matches) - 1
+	m.jumpToFindMatch()
+	return m, nil
+}
+
+// stepFind moves to the match delta steps away, wrapping around. Negative
+// deltas move up the conversation.
+func (m *Model) stepFind(delta int) (tea.Model, tea.Cmd) {
+	m.refreshFindMatches()
+	n := len(m.find.matches)
+	if n == 0 {
+		return m.showFooterWarning(fmt.Sprintf("No matches for %q.", m.find.query))
+	}
+	m.find.current = ((m.find.current+delta)%n + n) % n
+	m.jumpToFindMatch()
+	return m, nil
+}
+
+func (m *Model) clearFind() {
+	m.find = findState{}
+}
+
+// refreshFindMatches recomputes matches against the current conversation,
+// keeping the current match when it still exists.
+func (m *Model) refreshFindMatches() {
+	var previous render.FindMatch
+	hadCurrent := m.find.current >= 0 && m.find.current < len(m.find.matches)
+	if hadCurrent {
+		previous = m.find.matches[m.find.current]
+	}
+
+	if m.altScreen {
+		m.find.matches = render.FindInLines(m.viewportContentLines(), m.find.query)
+	} else {
+		m.find.matches = m.find.matches[:0]
+		m.messagesMu.Lock()
+		for i := range m.messages {
+			msg := &m.messages[i]
+			if msg.Role != llm.RoleUser && msg.Role != llm.RoleAssistant {
+				continue
+			}
+			for n := render.CountMatches(msg.TextContent, m.find.query); n > 0; n-- {
+				m.find.matches = append(m.find.matches, render.FindMatch{Line: i})
+			}
+		}
+		m.messagesMu.Unlock()
+	}
+
+	if hadCurrent {
+		for i, match := range m.find.matches {
+			if match == previous {
+				m.find.current = i
+				return
+			}
+		}
+	}
+	m.find.current = min(max(m.find.current, 0), max(len(m.find.matches)-1, 0))
+}
+
+// jumpToFindMatch scrolls so the current match is visible: centred in the
+// alt-screen viewport, or as the last history message when scrolled inline.
+func (m *Model) jumpToFindMatch() {
+	m.clearFooterMessage()
+	if len(m.find.matches) == 0 {
+		return
+	}
+	match := m.find.matches[m.find.current]
+	if m.altScreen {
+		m.scrollToBottom = false
+		m.viewport.SetYOffset(max(match.Line-m.viewport.Height()/2, 0))
+		return
+	}
+	m.scrollOffset = max(len(m.messages)-match.Line-1, 0)
+}
+
+// applyFindHighlight highlights matches of the active search in rendered
+// output whose first line is content line lineOffset.
+func (m *Model) applyFindHighlight(output string, lineOffset int) string {
+	if !m.find.active() {
+		return output
+	}
+	current := render.FindMatch{Line: -1}
+	if m.altScreen && m.find.current < len(m.find.matches) {
+		current = m.find.matches[m.find.current]
+	}
+	return render.HighlightMatches(output, m.find.query, lineOffset, current)
+}
+
+// findStatus is the status line segment for the active search.
+func (m *Model) findStatus() string {
+	if len(m.find.matches) == 0 {
+		return fmt.Sprintf("find %q: no matches · esc:close", m.find.query)
+	}
+	return fmt.Sprintf("find %q: match %d/%d · ctrl+g/alt+g:next/prev · esc:close", m.find.query, m.find.current+1, len(m.find.matches))
+}
+
+// viewportContentLines returns the alt-screen viewport content split into
+// lines, rebuilding the split lazily.
+func (m *Model) viewportContentLines() []string {
+	if m.contentLines == nil && m.viewCache.lastContentStr != "" {
+		m.contentLines = strings.Split(m.viewCache.lastContentStr, "\n")
+	}
+	return m.contentLines
+}
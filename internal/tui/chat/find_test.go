@@ -0,0 +1,126 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/session"
+)
+
+func findTestMessages(count int, needleAt map[int]bool) []session.Message {
+	messages := make([]session.Message, 0, count)
+	for i := 0; i < count; i++ {
+		role := llm.RoleUser
+		if i%2 == 1 {
+			role = llm.RoleAssistant
+		}
+		text := fmt.Sprintf("message %d about nothing in particular", i)
+		if needleAt[i] {
+			text = fmt.Sprintf("message %d mentions the Needle here", i)
+		}
+		messages = append(messages, session.Message{
+			ID:          int64(i + 1),
+			Role:        role,
+			TextContent: text,
+			Parts:       []llm.Part{{Type: llm.PartText, Text: text}},
+		})
+	}
+	return messages
+}
+
+func TestFindAltScreenJumpsBetweenMatches(t *testing.T) {
+	m := newTestChatModel(true)
+	m.applyWindowSize(tea.WindowSizeMsg{Width: 80, Height: 20})
+	m.messages = findTestMessages(60, map[int]bool{3: true, 30: true, 57: true})
+	_ = m.View()
+
+	result, _ := m.ExecuteCommand("/find needle")
+	m = result.(*Model)
+	if got := len(m.find.matches); got != 3 {
+		t.Fatalf("matches = %d, want 3", got)
+	}
+	if m.find.current != 2 {
+		t.Fatalf("current = %d, want the most recent match", m.find.current)
+	}
+	if status := m.findStatus(); !strings.Contains(status, "match 3/3") {
+		t.Fatalf("status = %q, want match 3/3", status)
+	}
+
+	_, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: 'g', Mod: tea.ModCtrl})
+	if m.find.current != 1 {
+		t.Fatalf("after ctrl+g, current = %d, want 1", m.find.current)
+	}
+	line := m.find.matches[1].Line
+	if top := m.viewport.YOffset(); line < top || line >= top+m.viewport.Height() {
+		t.Fatalf("match line %d not visible in viewport [%d, %d)", line, top, top+m.viewport.Height())
+	}
+	view := m.View().Content
+	if !strings.Contains(view, "\x1b[48;2;170;110;0m") {
+		t.Fatal("expected the current match to be highlighted in the viewport")
+	}
+	if !strings.Contains(view, "message 30 mentions") {
+		t.Fatal("expected the viewport to show the current match")
+	}
+
+	_, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: 'g', Mod: tea.ModAlt})
+	_, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: 'g', Mod: tea.ModAlt})
+	if m.find.current != 0 {
+		t.Fatalf("alt+g should wrap around to the first match, current = %d", m.find.current)
+	}
+
+	_, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: 'n', Text: "n"})
+	if m.find.current != 0 || m.textarea.Value() != "n" {
+		t.Fatalf("n while searching should type into the composer, current = %d, composer = %q", m.find.current, m.textarea.Value())
+	}
+	m.setTextareaValue("")
+
+	_, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if m.find.active() {
+		t.Fatal("Esc with an empty composer should close the search")
+	}
+	_, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: 'n', Text: "n"})
+	if m.textarea.Value() != "n" {
+		t.Fatalf("after closing the search n should type, got %q", m.textarea.Value())
+	}
+}
+
+func TestFindInlineScrollsToMatchingMessage(t *testing.T) {
+	m := newTestChatModel(false)
+	m.messages = findTestMessages(40, map[int]bool{10: true, 25: true})
+
+	result, _ := m.ExecuteCommand("/find NEEDLE")
+	m = result.(*Model)
+	if m.scrollOffset != 40-25-1 {
+		t.Fatalf("scrollOffset = %d, want message 25 as the last rendered", m.scrollOffset)
+	}
+	history := m.View().Content
+	if !strings.Contains(history, "message 25 mentions") || strings.Contains(history, "message 26 ") {
+		t.Fatalf("inline history should end at the matching message:\n%s", history)
+	}
+	if !strings.Contains(history, "\x1b[48;2;90;75;20m") {
+		t.Fatal("expected matches in the rendered inline history to be highlighted")
+	}
+
+	result, _ = m.ExecuteCommand("/find")
+	m = result.(*Model)
+	if m.scrollOffset != 40-10-1 {
+		t.Fatalf("/find without a query should step to the older match, scrollOffset = %d", m.scrollOffset)
+	}
+}
+
+func TestFindReportsNoMatches(t *testing.T) {
+	m := newTestChatModel(false)
+	m.messages = findTestMessages(4, nil)
+
+	result, _ := m.ExecuteCommand("/find needle")
+	m = result.(*Model)
+	if m.find.active() {
+		t.Fatal("a search without matches should not stay active")
+	}
+	if !strings.Contains(m.footerMessage, `No matches for "needle"`) {
+		t.Fatalf("footer = %q", m.footerMessage)
+	}
+}
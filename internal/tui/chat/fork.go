@@ -0,0 +1,75 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/session"
+)
+
+// cmdFork copies the conversation into a new session and relaunches chat on
+// the copy, leaving the original untouched. With no argument the fork includes
+// everything so far; /fork N keeps the first N user turns and their replies.
+func (m *Model) cmdFork(args []string) (tea.Model, tea.Cmd) {
+	if len(args) > 1 {
+		return m.showSystemMessage("Usage: /fork [n]")
+	}
+	if m.store == nil || m.sess == nil {
+		return m.showSystemMessage("Session storage is disabled.")
+	}
+	if m.streaming {
+		return m.showFooterWarning("Wait for the response to finish before forking.")
+	}
+
+	atSeq := -1
+	if len(args) == 1 {
+		n, err := strconv.Atoi(strings.TrimSpace(args[0]))
+		if err != nil || n < 1 {
+			return m.showFooterError("Usage: /fork [n] where n is a user message number.")
+		}
+		m.messagesMu.Lock()
+		seq, turns, ok := m.forkSequenceAfterUserTurn(n)
+		m.messagesMu.Unlock()
+		if !ok {
+			return m.showFooterError(fmt.Sprintf("Message number must be between 1 and %d.", turns))
+		}
+		atSeq = seq
+	}
+
+	forker, ok := m.store.(session.Forker)
+	if !ok {
+		return m.showSystemMessage("This session store does not support forking.")
+	}
+	ctx := context.Background()
+	fork, err := forker.Fork(ctx, m.sess.ID, atSeq)
+	if err != nil {
+		return m.showFooterError(fmt.Sprintf("Fork failed: %v", err))
+	}
+	m.setTextareaValue("")
+	return m.requestResumeSession(fork.ID)
+}
+
+// forkSequenceAfterUserTurn returns the last message sequence belonging to the
+// nth user turn, or -1 when that turn is the latest. turns is the number of
+// user turns available. Callers must hold messagesMu.
+func (m *Model) forkSequenceAfterUserTurn(n int) (seq, turns int, ok bool) {
+	var userIdx []int
+	for i, msg := range m.messages {
+		if msg.Role != llm.RoleUser || msg.CompactionTail || llm.IsInternalCompactionSummaryText(msg.TextContent) {
+			continue
+		}
+		userIdx = append(userIdx, i)
+	}
+	turns = len(userIdx)
+	if n > turns {
+		return 0, turns, false
+	}
+	if n == turns {
+		return -1, turns, true
+	}
+	return m.messages[userIdx[n]].Sequence - 1, turns, true
+}
@@ -377,6 +377,9 @@ func (m *Model) handleCtrlC() (tea.Model, tea.Cmd) {
 			m.ctrlCExitArmedUntil = time.Time{}
 			return m.resolveWorktreeRecoveryPrompt(false)
 		}
+		if m.dialog.Type() == DialogAuthRequired || m.dialog.Type() == DialogAuthDeviceCode {
+			m.cancelAuthLogin()
+		}
 		m.dialog.Close()
 	}
 	_, footerCmd := m.showFooterMessageWithToneFor("Press Ctrl-C again to exit.", "warning", ctrlCExitConfirmWindow)
@@ -384,6 +387,8 @@ func (m *Model) handleCtrlC() (tea.Model, tea.Cmd) {
 }
 
 func (m *Model) handleKeyMsg(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	inPasteBurst := m.notePasteBurstKey(msg, time.Now())
+
 	if isChaosMonkeyKey(msg) {
 		if m.streaming && m.engine != nil {
 			m.engine.TriggerChaosFailure()
@@ -491,8 +496,8 @@ func (m *Model) handleKeyMsg(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 	}
 
 	// Bracketed paste and Ctrl+V image attach support for the composer.
-	if m.maybeAttachImageFromPaste(msg) {
-		return m, nil
+	if handled, cmd := m.maybeAttachImageFromPaste(msg); handled {
+		return m, cmd
 	}
 
 	// When image chips are present, allow keyboard selection/removal with arrows + backspace/delete.
@@ -612,6 +617,65 @@ func (m *Model) handleKeyMsg(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		if m.dialog.Type() == DialogAuthRequired {
+			switch {
+			case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+				selected := m.dialog.Selected()
+				return m.resolveAuthPrompt(selected != nil && selected.ID == "yes")
+			case key.Matches(msg, key.NewBinding(key.WithKeys("esc", "q"))):
+				return m.resolveAuthPrompt(false)
+			case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k", "down", "j"))):
+				m.dialog.Update(msg)
+			}
+			return m, nil
+		}
+
+		if m.dialog.Type() == DialogAuthDeviceCode {
+			if key.Matches(msg, key.NewBinding(key.WithKeys("enter", "esc", "q"))) {
+				provider := m.authLogin.login.Provider
+				m.cancelAuthLogin()
+				m.dialog.Close()
+				return m.showFooterMuted(fmt.Sprintf("Sign-in cancelled. Run 'term-llm auth login %s' to sign in later.", provider))
+			}
+			return m, nil
+		}
+
+		if m.dialog.Type() == DialogBranches {
+			switch {
+			case key.Matches(msg, key.NewBinding(key.WithKeys("enter", "tab"))):
+				selected := m.dialog.Selected()
+				m.dialog.Close()
+				if selected == nil {
+					return m, nil
+				}
+				return m.switchToSession(selected.ID)
+			case key.Matches(msg, key.NewBinding(key.WithKeys("d", "delete"))):
+				if selected := m.dialog.Selected(); selected != nil {
+					return m.confirmBranchDelete(selected.ID)
+				}
+				return m, nil
+			case key.Matches(msg, key.NewBinding(key.WithKeys("esc", "q"))):
+				m.dialog.Close()
+				return m, nil
+			default:
+				m.dialog.Update(msg)
+				return m, nil
+			}
+		}
+
+		if m.dialog.Type() == DialogBranchDelete {
+			switch {
+			case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+				selected := m.dialog.Selected()
+				return m.resolveBranchDelete(selected != nil && selected.ID == "yes")
+			case key.Matches(msg, key.NewBinding(key.WithKeys("esc", "q"))):
+				return m.resolveBranchDelete(false)
+			case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k", "down", "j"))):
+				m.dialog.Update(msg)
+			}
+			return m, nil
+		}
+
 		if m.dialog.Type() == DialogWorktreeRecovery {
 			switch {
 			case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
@@ -654,6 +718,7 @@ func (m *Model) handleKeyMsg(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 				case DialogDirApproval:
 					if selected.ID == "__deny__" {
 						m.pendingFilePath = ""
+						m.pendingTemplateCommand = ""
 						m.dialog.Close()
 						return m.showSystemMessage("File access denied.")
 					}
@@ -662,10 +727,15 @@ func (m *Model) handleKeyMsg(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 						m.dialog.Close()
 						return m.showSystemMessage("Failed to approve directory: " + err.Error())
 					}
-					// Now try to attach the file again
+					// Now try to attach the file again, or re-run the
+					// template that referenced it
 					filePath := m.pendingFilePath
 					m.pendingFilePath = ""
 					m.dialog.Close()
+					if templateCommand := m.pendingTemplateCommand; templateCommand != "" {
+						m.pendingTemplateCommand = ""
+						return m.ExecuteCommand(templateCommand)
+					}
 					return m.attachFile(filePath)
 				}
 			}
@@ -673,6 +743,7 @@ func (m *Model) handleKeyMsg(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		case key.Matches(msg, key.NewBinding(key.WithKeys("esc", "q"))):
 			m.pendingFilePath = ""
+			m.pendingTemplateCommand = ""
 			m.pendingShare = nil
 			if m.dialog.Type() == DialogWorktreeRecovery {
 				return m.resolveWorktreeRecoveryPrompt(false)
@@ -808,11 +879,38 @@ func (m *Model) handleKeyMsg(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			m.selection = Selection{}
 			return m, nil
 		}
-		// Clear input if not empty
+		// Esc abandons an /edit along with its text.
+		if m.pendingEdit != nil {
+			return m.cancelEdit()
+		}
+		// Clear input if not empty, then close an active search
 		if m.textarea.Value() != "" {
 			m.setTextareaValue("")
 			m.pasteChunks = nil
+		} else if m.find.active() {
+			m.clearFind()
+		}
+		return m, nil
+	}
+
+	// In-conversation search: Ctrl+F starts /find; Ctrl+G and Alt+G step up
+	// and down through the matches. Plain keys stay with the composer.
+	if key.Matches(msg, m.keyMap.Find) {
+		m.setTextareaValue("/find " + m.find.query)
+		m.completions.Hide()
+		return m, nil
+	}
+	if m.find.active() {
+		if key.Matches(msg, m.keyMap.FindNext) {
+			return m.stepFind(-1)
+		}
+		if key.Matches(msg, m.keyMap.FindPrev) {
+			return m.stepFind(1)
 		}
+	}
+
+	// Alt+O expands a collapsed tool result on screen.
+	if key.Matches(msg, m.keyMap.ExpandResult) && m.expandVisibleToolResult() {
 		return m, nil
 	}
 
@@ -827,6 +925,11 @@ func (m *Model) handleKeyMsg(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// Switch between inline and alt-screen rendering (Alt+S).
+	if key.Matches(msg, m.keyMap.ToggleScreen) {
+		return m.switchScreenMode(!m.altScreen)
+	}
+
 	// Toggle expanded tool display (Ctrl+E) - works even during streaming.
 	// If the cursor is on a collapsed paste placeholder in the composer, expand
 	// that placeholder instead and don't bubble through to the global tool toggle.
@@ -865,6 +968,11 @@ func (m *Model) handleKeyMsg(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// Toggle per-message timing and token stats (Alt+I).
+	if key.Matches(msg, m.keyMap.MessageStats) {
+		return m.setMessageStats(!m.messageStats)
+	}
+
 	// Allow viewport scrolling even while streaming (in alt screen mode)
 	if m.altScreen {
 		if key.Matches(msg, m.keyMap.PageUp) {
@@ -902,7 +1010,10 @@ func (m *Model) handleKeyMsg(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 	// Newline insertion (ctrl+j, alt+enter, shift+enter) — works in both the
 	// streaming interjection composer and the normal composer. Must precede
 	// any Send handler so shift+enter is caught before a plain "enter" match.
-	if key.Matches(msg, m.keyMap.Newline) || key.Matches(msg, m.keyMap.NewlineAlt) {
+	// Enter inside a burst of rapid keystrokes is a newline from a paste that
+	// arrived without bracketed-paste markers; never send mid-paste.
+	if key.Matches(msg, m.keyMap.Newline) || key.Matches(msg, m.keyMap.NewlineAlt) ||
+		(inPasteBurst && key.Matches(msg, m.keyMap.Send)) {
 		m.textarea.InsertString("\n")
 		m.updateTextareaHeight()
 		return m, nil
@@ -920,6 +1031,12 @@ func (m *Model) handleKeyMsg(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		if key.Matches(msg, m.keyMap.CycleEffort) {
 			return m.cycleEffort()
 		}
+		if key.Matches(msg, m.keyMap.QueueSend) {
+			return m.queueComposerMessage()
+		}
+	}
+	if len(m.queuedMessages) > 0 && key.Matches(msg, m.keyMap.ClearQueue) {
+		return m.clearQueuedMessages()
 	}
 
 	// During streaming: allow local slash commands, typing, image attachments,
@@ -1120,10 +1237,19 @@ func (m *Model) handleKeyMsg(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		// Expand inline paste placeholders back to real content before sending.
 		content = m.expandPastePlaceholders(content)
 
+		if m.pendingEdit != nil {
+			return m.submitEdit(content)
+		}
+
 		// Send message if not empty, or if there are pasted image attachments.
 		if content != "" || len(m.images) > 0 {
 			return m.sendMessage(content)
 		}
+		// An empty Enter sends the next message left queued by a cancelled
+		// response.
+		if cmd := m.sendNextQueuedMessage(); cmd != nil {
+			return m, cmd
+		}
 		return m, nil
 	}
 
@@ -1243,10 +1369,8 @@ func (m *Model) handlePasteMsg(msg tea.PasteMsg) (tea.Model, tea.Cmd) {
 		return m.handleDialogPasteMsg(msg)
 	}
 	if msg.Content == "" {
-		if m.maybeAttachImageFromClipboard() {
-			return m, nil
-		}
-		return m, nil
+		_, cmd := m.maybeAttachImageFromClipboard()
+		return m, cmd
 	}
 	text := msg.Content
 	if shouldCollapsePaste(text) {
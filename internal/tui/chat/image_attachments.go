@@ -10,6 +10,7 @@ import (
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/samsaffron/term-llm/internal/clipboard"
+	"github.com/samsaffron/term-llm/internal/imageprep"
 	"github.com/samsaffron/term-llm/internal/llm"
 	"github.com/samsaffron/term-llm/internal/session"
 )
@@ -25,34 +26,94 @@ type ImageAttachment struct {
 	Path      string
 }
 
-func (m *Model) maybeAttachImageFromPaste(msg tea.KeyPressMsg) bool {
+func (m *Model) maybeAttachImageFromPaste(msg tea.KeyPressMsg) (bool, tea.Cmd) {
 	if !isImagePasteAttempt(msg) {
-		return false
+		return false, nil
 	}
 	return m.maybeAttachImageFromClipboard()
 }
 
-func (m *Model) maybeAttachImageFromClipboard() bool {
+func (m *Model) maybeAttachImageFromClipboard() (bool, tea.Cmd) {
 	imgData, err := readClipboardImage()
 	if err != nil || len(imgData) == 0 {
-		return false
+		return false, nil
 	}
 
 	if len(imgData) > maxPastedImageSize {
-		return true
+		return true, nil
 	}
 
 	mediaType := detectImageMediaType(imgData)
 	if !strings.HasPrefix(mediaType, "image/") {
-		return false
+		return false, nil
 	}
 
-	m.images = append(m.images, ImageAttachment{
-		MediaType: mediaType,
-		Data:      imgData,
-	})
+	note, ok := m.addImageAttachment(imgData, mediaType)
 	m.selectedImage = -1
-	return true
+	if !ok {
+		_, cmd := m.showFooterWarning(fmt.Sprintf("Attached image %d unchanged: %s", len(m.images), note))
+		return true, cmd
+	}
+	_, cmd := m.showFooterSuccess(fmt.Sprintf("Attached image %d (%s).", len(m.images), note))
+	return true, cmd
+}
+
+// addImageAttachment shrinks an image for the model and queues it for the
+// next message. It returns the size summary, or, with ok false, why the
+// image is sent unchanged.
+func (m *Model) addImageAttachment(data []byte, mediaType string) (note string, ok bool) {
+	res, err := imageprep.Prepare(data, m.imagePrepOptions())
+	if err != nil {
+		m.images = append(m.images, ImageAttachment{MediaType: mediaType, Data: data})
+		return err.Error(), false
+	}
+	m.images = append(m.images, ImageAttachment{MediaType: res.MediaType, Data: res.Data})
+	return res.Summary(), true
+}
+
+// attachImageFile queues the image file at path, as /file does for text.
+func (m *Model) attachImageFile(path string) (tea.Model, tea.Cmd) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return m.showSystemMessage(fmt.Sprintf("Failed to attach file: %v", err))
+	}
+	if info.Size() > maxPastedImageSize {
+		return m.showSystemMessage(fmt.Sprintf("Failed to attach file: image too large: %s (%s, max %s)",
+			path, FormatFileSize(info.Size()), FormatFileSize(maxPastedImageSize)))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return m.showSystemMessage(fmt.Sprintf("Failed to attach file: %v", err))
+	}
+	note, ok := m.addImageAttachment(data, detectImageMediaType(data))
+	m.selectedImage = -1
+	name := filepath.Base(path)
+	if !ok {
+		return m.showFooterWarning(fmt.Sprintf("Attached %s unchanged: %s", name, note))
+	}
+	return m.showFooterSuccess(fmt.Sprintf("Attached %s (%s).", name, note))
+}
+
+func (m *Model) imagePrepOptions() imageprep.Options {
+	if m.config == nil {
+		return imageprep.Options{}
+	}
+	return imageprep.Options{
+		MaxDimension: m.config.Image.Attachments.MaxDimension,
+		Quality:      m.config.Image.Attachments.Quality,
+	}
+}
+
+// isImageFile reports whether the file at path starts like an image.
+func isImageFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	head := make([]byte, 512)
+	n, _ := f.Read(head)
+	return n > 0 && strings.HasPrefix(http.DetectContentType(head[:n]), "image/")
 }
 
 func isImagePasteAttempt(msg tea.KeyPressMsg) bool {
@@ -1,7 +1,13 @@
 package chat
 
 import (
+	"bytes"
 	"encoding/base64"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	tea "charm.land/bubbletea/v2"
@@ -82,6 +88,68 @@ func TestHandlePasteMsg_EmptyPasteAttachesImageFromClipboard(t *testing.T) {
 	}
 }
 
+func testJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 7)
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestHandlePasteMsg_DownscalesPastedImage(t *testing.T) {
+	m := newTestChatModel(false)
+	m.config.Image.Attachments.MaxDimension = 100
+	original := testJPEG(t, 400, 200)
+
+	orig := readClipboardImage
+	readClipboardImage = func() ([]byte, error) { return original, nil }
+	defer func() { readClipboardImage = orig }()
+
+	_, _ = m.Update(tea.PasteMsg{})
+
+	if len(m.images) != 1 {
+		t.Fatalf("expected 1 attached image, got %d", len(m.images))
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(m.images[0].Data))
+	if err != nil {
+		t.Fatalf("decode attachment: %v", err)
+	}
+	if cfg.Width != 100 || cfg.Height != 50 || len(m.images[0].Data) >= len(original) {
+		t.Fatalf("attachment = %dx%d, %d bytes; want 100x50 and smaller than %d", cfg.Width, cfg.Height, len(m.images[0].Data), len(original))
+	}
+	if !strings.Contains(m.footerMessage, "400×200") || !strings.Contains(m.footerMessage, "→ 100×50") {
+		t.Fatalf("footer = %q, want the original and final sizes", m.footerMessage)
+	}
+}
+
+func TestAttachFile_ImageBecomesImageAttachment(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	m := newTestChatModel(false)
+	m.config.Image.Attachments.MaxDimension = 100
+	dir := t.TempDir()
+	path := filepath.Join(dir, "shot.jpg")
+	if err := os.WriteFile(path, testJPEG(t, 300, 300), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.approvedDirs.AddDirectory(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	m.attachFile(path)
+
+	if len(m.files) != 0 || len(m.images) != 1 {
+		t.Fatalf("files=%d images=%d, want the image as an image attachment", len(m.files), len(m.images))
+	}
+	if !strings.Contains(m.footerMessage, "Attached shot.jpg (300×300") {
+		t.Fatalf("footer = %q", m.footerMessage)
+	}
+}
+
 func TestHandleKeyMsg_ImageSelectionAndRemoval(t *testing.T) {
 	m := newTestChatModel(false)
 	m.images = []ImageAttachment{{MediaType: "image/png", Data: []byte("a")}, {MediaType: "image/png", Data: []byte("b")}}
@@ -18,23 +18,31 @@ type KeyMap struct {
 	HistoryUp   key.Binding
 	HistoryDown key.Binding
 	Tab         key.Binding
+	QueueSend   key.Binding
+	ClearQueue  key.Binding
 
 	// History navigation
 	PageUp   key.Binding
 	PageDown key.Binding
 
 	// Shortcuts
-	Help        key.Binding
-	SwitchModel key.Binding
-	CycleEffort key.Binding
-	ToggleWeb   key.Binding
-	ToggleYolo  key.Binding
-	Clear       key.Binding
-	NewSession  key.Binding
-	MCPPicker   key.Binding
-	Inspector   key.Binding
-	ExpandTools key.Binding
-	Copy        key.Binding
+	Help         key.Binding
+	SwitchModel  key.Binding
+	CycleEffort  key.Binding
+	ToggleWeb    key.Binding
+	ToggleYolo   key.Binding
+	Clear        key.Binding
+	NewSession   key.Binding
+	MCPPicker    key.Binding
+	Inspector    key.Binding
+	ExpandTools  key.Binding
+	ExpandResult key.Binding
+	MessageStats key.Binding
+	Copy         key.Binding
+	Find         key.Binding
+	FindNext     key.Binding
+	FindPrev     key.Binding
+	ToggleScreen key.Binding
 }
 
 // DefaultKeyMap returns the default keybindings
@@ -75,6 +83,14 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("esc"),
 			key.WithHelp("esc", "cancel"),
 		),
+		QueueSend: key.NewBinding(
+			key.WithKeys("ctrl+q"),
+			key.WithHelp("ctrl+q", "send after response"),
+		),
+		ClearQueue: key.NewBinding(
+			key.WithKeys("ctrl+x"),
+			key.WithHelp("ctrl+x", "clear queued messages"),
+		),
 		HistoryUp: key.NewBinding(
 			key.WithKeys("up"),
 			key.WithHelp("up", "history up"),
@@ -135,9 +151,33 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("ctrl+e"),
 			key.WithHelp("ctrl+e", "expand details"),
 		),
+		ExpandResult: key.NewBinding(
+			key.WithKeys("alt+o"),
+			key.WithHelp("alt+o", "show tool result in full"),
+		),
+		MessageStats: key.NewBinding(
+			key.WithKeys("alt+i"),
+			key.WithHelp("alt+i", "message stats"),
+		),
 		Copy: key.NewBinding(
 			key.WithKeys("ctrl+y"),
 			key.WithHelp("ctrl+y", "copy selection"),
 		),
+		Find: key.NewBinding(
+			key.WithKeys("ctrl+f"),
+			key.WithHelp("ctrl+f", "find"),
+		),
+		FindNext: key.NewBinding(
+			key.WithKeys("ctrl+g"),
+			key.WithHelp("ctrl+g", "next match"),
+		),
+		FindPrev: key.NewBinding(
+			key.WithKeys("alt+g"),
+			key.WithHelp("alt+g", "previous match"),
+		),
+		ToggleScreen: key.NewBinding(
+			key.WithKeys("alt+s"),
+			key.WithHelp("alt+s", "inline/full screen"),
+		),
 	}
 }
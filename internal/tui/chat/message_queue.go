@@ -0,0 +1,96 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// queuedMessage is a message typed while a response was streaming that should
+// be sent as its own turn once the response finishes. Unlike interjections it
+// is never shown to the model mid-turn, and it is only stored in the session
+// when it is actually sent.
+type queuedMessage struct {
+	Text   string
+	Images []ImageAttachment
+}
+
+// label is the one-line preview shown in the queued list.
+func (q queuedMessage) label() string {
+	text := strings.Join(strings.Fields(q.Text), " ")
+	if len(q.Images) > 0 {
+		images := fmt.Sprintf("[%d image(s)]", len(q.Images))
+		if text == "" {
+			return images
+		}
+		text += " " + images
+	}
+	return text
+}
+
+// queueComposerMessage moves the composer contents to the back of the send
+// queue. Slash commands are not queued; they keep their streaming behaviour.
+func (m *Model) queueComposerMessage() (tea.Model, tea.Cmd) {
+	raw := strings.TrimSpace(m.textarea.Value())
+	if strings.HasPrefix(raw, "/") && m.isSlashCommandLike(raw) {
+		m.phase = "Slash commands can't be queued; press Enter to run it now"
+		return m, nil
+	}
+	content := m.expandPastePlaceholders(raw)
+	if content == "" && len(m.images) == 0 {
+		m.phase = "Type a message to queue it, or press Esc to cancel"
+		return m, nil
+	}
+	m.queuedMessages = append(m.queuedMessages, queuedMessage{Text: content, Images: m.images})
+	m.setTextareaValue("")
+	m.images = nil
+	m.selectedImage = -1
+	m.pasteChunks = nil
+	m.resetPromptHistory()
+	m.interruptNotice = fmt.Sprintf("queued message %d — sends when this response finishes", len(m.queuedMessages))
+	return m, nil
+}
+
+// clearQueuedMessages drops every queued message.
+func (m *Model) clearQueuedMessages() (tea.Model, tea.Cmd) {
+	n := len(m.queuedMessages)
+	m.queuedMessages = nil
+	if n == 1 {
+		m.interruptNotice = "cleared 1 queued message"
+	} else {
+		m.interruptNotice = fmt.Sprintf("cleared %d queued messages", n)
+	}
+	return m, nil
+}
+
+// sendNextQueuedMessage sends the oldest queued message as a new turn. Any
+// draft in the composer, including a restored interjection, is kept for the
+// user to finish. Returns nil when nothing was sent.
+func (m *Model) sendNextQueuedMessage() tea.Cmd {
+	if len(m.queuedMessages) == 0 || m.streaming {
+		return nil
+	}
+	next := m.queuedMessages[0]
+	m.queuedMessages = m.queuedMessages[1:]
+	if len(m.queuedMessages) == 0 {
+		m.queuedMessages = nil
+	}
+
+	draft := m.textarea.Value()
+	draftImages, draftFiles, draftChunks := m.images, m.files, m.pasteChunks
+	m.images = next.Images
+	m.files = nil
+	_, cmd := m.sendMessage(next.Text)
+	if !m.streaming {
+		// The send was refused (e.g. a worktree operation is running): put the
+		// message back so it is not lost.
+		m.queuedMessages = append([]queuedMessage{next}, m.queuedMessages...)
+	}
+	m.images, m.files, m.pasteChunks = draftImages, draftFiles, draftChunks
+	if len(m.images) == 0 {
+		m.selectedImage = -1
+	}
+	m.setTextareaValue(draft)
+	return cmd
+}
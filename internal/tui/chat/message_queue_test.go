@@ -0,0 +1,250 @@
+package chat
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/session"
+	"github.com/samsaffron/term-llm/internal/ui"
+)
+
+// newQueueTestModel returns a chat model backed by a real session store and
+// an engine streaming from provider.
+func newQueueTestModel(t *testing.T, provider *llm.MockProvider) (*Model, session.Store) {
+	t.Helper()
+	registry := llm.NewToolRegistry()
+	registry.Register(&interjectionTestTool{})
+
+	store, err := session.NewStore(session.Config{Enabled: true, Path: filepath.Join(t.TempDir(), "sessions.db")})
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	sess := &session.Session{ID: "queue-test", CreatedAt: time.Now()}
+	if err := store.Create(context.Background(), sess); err != nil {
+		t.Fatalf("Create session: %v", err)
+	}
+
+	m := newTestChatModel(false)
+	m.engine = llm.NewEngine(provider, registry)
+	m.store = store
+	m.sess = sess
+	return m, store
+}
+
+// runQueueTestStream drives the stream started by the last send, calling
+// during after the first event, and returns the final event's command.
+func runQueueTestStream(t *testing.T, m *Model, during func()) (ui.StreamEventType, tea.Cmd) {
+	t.Helper()
+	msg := m.startStream("")()
+	for i := 0; ; i++ {
+		ev, ok := msg.(streamEventMsg)
+		if !ok {
+			t.Fatalf("stream returned %T, want streamEventMsg", msg)
+		}
+		_, cmd := m.Update(ev)
+		if ev.event.Type == ui.StreamEventDone || ev.event.Type == ui.StreamEventError {
+			return ev.event.Type, cmd
+		}
+		if i == 0 && during != nil {
+			during()
+		}
+		msg = m.listenForStreamEventsSync(m.streamGeneration)
+	}
+}
+
+func typeAndQueue(t *testing.T, m *Model, text string) {
+	t.Helper()
+	m.setTextareaValue(text)
+	m.handleKeyMsg(tea.KeyPressMsg{Code: 'q', Mod: tea.ModCtrl})
+	if got := m.textarea.Value(); got != "" {
+		t.Fatalf("composer = %q after queueing, want empty", got)
+	}
+}
+
+func storedUserTexts(t *testing.T, store session.Store, sessionID string) []string {
+	t.Helper()
+	msgs, err := store.GetMessages(context.Background(), sessionID, 0, 0)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	var texts []string
+	for _, msg := range msgs {
+		if msg.Role == llm.RoleUser {
+			texts = append(texts, msg.TextContent)
+		}
+	}
+	return texts
+}
+
+func lastUserText(req llm.Request) string {
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == llm.RoleUser {
+			for _, part := range req.Messages[i].Parts {
+				if part.Type == llm.PartText {
+					return part.Text
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func TestQueuedMessagesSendInOrderAfterToolLoop(t *testing.T) {
+	slow := func(text string) llm.MockTurn {
+		return llm.MockTurn{Events: []llm.MockEvent{
+			{Delay: 20 * time.Millisecond, Text: text[:3]},
+			{Delay: 20 * time.Millisecond, Text: text[3:]},
+		}}
+	}
+	provider := llm.NewMockProvider("mock").
+		AddToolCall("call-1", "noop_tool", map[string]any{}).
+		AddTurn(slow("first answer")).
+		AddTurn(slow("second answer")).
+		AddTurn(slow("third answer"))
+	m, store := newQueueTestModel(t, provider)
+
+	m.sendMessage("first")
+	typ, cmd := runQueueTestStream(t, m, func() {
+		typeAndQueue(t, m, "second")
+		typeAndQueue(t, m, "third")
+		if got := storedUserTexts(t, store, m.sess.ID); len(got) != 1 {
+			t.Fatalf("stored user messages while streaming = %q, want only the first", got)
+		}
+		if view := m.View().Content; !strings.Contains(view, "second (queued)") || !strings.Contains(view, "third (queued)") {
+			t.Fatalf("queued messages not shown under the input:\n%s", view)
+		}
+	})
+	if typ != ui.StreamEventDone || cmd == nil {
+		t.Fatalf("first turn ended with %v (cmd=%v), want done with follow-up", typ, cmd != nil)
+	}
+	if !m.streaming || len(m.queuedMessages) != 1 {
+		t.Fatalf("after first turn: streaming=%v queued=%d, want second sent and third waiting", m.streaming, len(m.queuedMessages))
+	}
+
+	if typ, _ := runQueueTestStream(t, m, nil); typ != ui.StreamEventDone {
+		t.Fatalf("second turn ended with %v", typ)
+	}
+	if !m.streaming || len(m.queuedMessages) != 0 {
+		t.Fatalf("after second turn: streaming=%v queued=%d, want third sent", m.streaming, len(m.queuedMessages))
+	}
+	if typ, _ := runQueueTestStream(t, m, nil); typ != ui.StreamEventDone {
+		t.Fatalf("third turn ended with %v", typ)
+	}
+	if m.streaming {
+		t.Fatal("expected chat to be idle once the queue is empty")
+	}
+
+	var sent []string
+	for _, req := range provider.RecordedRequests() {
+		sent = append(sent, lastUserText(req))
+	}
+	if want := []string{"first", "first", "second", "third"}; strings.Join(sent, ",") != strings.Join(want, ",") {
+		t.Fatalf("provider requests by last user message = %q, want %q", sent, want)
+	}
+	if got := storedUserTexts(t, store, m.sess.ID); strings.Join(got, ",") != "first,second,third" {
+		t.Fatalf("stored user messages = %q, want first, second, third", got)
+	}
+}
+
+func TestQueuedMessagesSurviveCancelAndSendOnEmptyEnter(t *testing.T) {
+	provider := llm.NewMockProvider("mock").
+		AddTurn(llm.MockTurn{Events: []llm.MockEvent{
+			{Text: "partial"},
+			{Delay: 5 * time.Second, Text: " never"},
+		}}).
+		AddTextResponse("queued answer")
+	m, store := newQueueTestModel(t, provider)
+
+	m.sendMessage("first")
+	typ, _ := runQueueTestStream(t, m, func() {
+		typeAndQueue(t, m, "queued")
+		m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEscape})
+	})
+	if typ != ui.StreamEventError {
+		t.Fatalf("cancelled turn ended with %v, want error", typ)
+	}
+	if m.streaming {
+		t.Fatal("cancel must not auto-send the queue")
+	}
+	if len(m.queuedMessages) != 1 || m.queuedMessages[0].Text != "queued" {
+		t.Fatalf("queue after cancel = %#v, want the queued message kept", m.queuedMessages)
+	}
+	if got := storedUserTexts(t, store, m.sess.ID); strings.Join(got, ",") != "first" {
+		t.Fatalf("stored user messages after cancel = %q, want only first", got)
+	}
+
+	m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if !m.streaming || len(m.queuedMessages) != 0 {
+		t.Fatalf("empty Enter: streaming=%v queued=%d, want the queued message sent", m.streaming, len(m.queuedMessages))
+	}
+	if typ, _ := runQueueTestStream(t, m, nil); typ != ui.StreamEventDone {
+		t.Fatalf("queued turn ended with %v", typ)
+	}
+	if got := storedUserTexts(t, store, m.sess.ID); strings.Join(got, ",") != "first,queued" {
+		t.Fatalf("stored user messages = %q, want first, queued", got)
+	}
+}
+
+func TestQueuedMessageKeepsComposerDraft(t *testing.T) {
+	m := newTestChatModel(false)
+	m.queuedMessages = []queuedMessage{{Text: "queued"}}
+	m.setTextareaValue("half-written")
+
+	if cmd := m.sendNextQueuedMessage(); cmd == nil {
+		t.Fatal("expected queued message to be sent")
+	}
+	if got := m.textarea.Value(); got != "half-written" {
+		t.Fatalf("composer = %q, want draft kept", got)
+	}
+	if last := m.messages[len(m.messages)-1]; last.TextContent != "queued" {
+		t.Fatalf("sent message = %q, want queued", last.TextContent)
+	}
+}
+
+func TestClearQueueKey(t *testing.T) {
+	m := newTestChatModel(false)
+	m.streaming = true
+	typeAndQueue(t, m, "one")
+	typeAndQueue(t, m, "two")
+
+	m.handleKeyMsg(tea.KeyPressMsg{Code: 'x', Mod: tea.ModCtrl})
+	if len(m.queuedMessages) != 0 {
+		t.Fatalf("queue = %#v, want cleared", m.queuedMessages)
+	}
+	if m.interruptNotice != "cleared 2 queued messages" {
+		t.Fatalf("notice = %q", m.interruptNotice)
+	}
+}
+
+func TestQueueSendIgnoresEmptyComposer(t *testing.T) {
+	m := newTestChatModel(false)
+	m.streaming = true
+
+	m.handleKeyMsg(tea.KeyPressMsg{Code: 'q', Mod: tea.ModCtrl})
+	if len(m.queuedMessages) != 0 {
+		t.Fatalf("queue = %#v, want nothing queued", m.queuedMessages)
+	}
+}
+
+func TestQueuedMessagesSurvivePendingModelSwitch(t *testing.T) {
+	m, _ := newEffortCmdTestModel("openai", "gpt-5.4-medium")
+	prepareEffortShortcutTestModel(m)
+	m.streaming = true
+	typeAndQueue(t, m, "after the switch")
+	m.pendingStreamModelSwitch = &pendingStreamModelSwitch{provider: "openai", model: "gpt-5.4-high"}
+	oldEngine := m.engine
+
+	m.applyPendingStreamModelSwitch()
+	if m.engine == oldEngine || m.modelName != "gpt-5.4-high" {
+		t.Fatalf("switch did not apply: model=%q", m.modelName)
+	}
+	if len(m.queuedMessages) != 1 || m.queuedMessages[0].Text != "after the switch" {
+		t.Fatalf("queue after switch = %#v", m.queuedMessages)
+	}
+}
@@ -0,0 +1,88 @@
+package chat
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+const (
+	// pasteBurstGap is the longest pause between keystrokes that still counts
+	// as one burst. Terminals without bracketed paste deliver a paste as a run
+	// of keystrokes with almost no gap; typing leaves far longer pauses.
+	pasteBurstGap = 20 * time.Millisecond
+	// pasteBurstMinKeys is how many rapid keystrokes must precede Enter before
+	// it is taken as a pasted newline instead of a send.
+	pasteBurstMinKeys = 8
+)
+
+// notePasteBurstKey records a text or Enter keystroke and reports whether it
+// continues a burst of rapid keys, i.e. an unbracketed paste. Any other key
+// ends the burst.
+func (m *Model) notePasteBurstKey(msg tea.KeyPressMsg, now time.Time) bool {
+	if msg.Text == "" && msg.Code != tea.KeyEnter {
+		m.pasteBurstKeys = 0
+		return false
+	}
+	if !m.pasteBurstLast.IsZero() && now.Sub(m.pasteBurstLast) <= pasteBurstGap {
+		m.pasteBurstKeys++
+	} else {
+		m.pasteBurstKeys = 1
+	}
+	m.pasteBurstLast = now
+	return m.pasteBurstKeys > pasteBurstMinKeys
+}
+
+// cmdPaste shows or discards the collapsed paste buffer. Anything after the
+// subcommand is the draft the command was typed in front of; it stays in the
+// composer so the buffer can be checked without clearing the draft first.
+func (m *Model) cmdPaste(args []string, rawArgs string) (tea.Model, tea.Cmd) {
+	sub := "show"
+	draft := rawArgs
+	if len(args) > 0 {
+		switch strings.ToLower(args[0]) {
+		case "show", "clear":
+			sub = strings.ToLower(args[0])
+			draft = strings.TrimSpace(rawArgs[len(args[0]):])
+		}
+	}
+	m.setTextareaValue(draft)
+	if sub == "clear" {
+		count := len(m.pasteChunks)
+		m.pasteChunks = nil
+		if count == 0 {
+			return m.showFooterMuted("No collapsed pastes.")
+		}
+		return m.showFooterSuccess(fmt.Sprintf("Discarded %d collapsed paste(s).", count))
+	}
+	if len(m.pasteChunks) == 0 {
+		return m.showFooterMuted("No collapsed pastes. Large multi-line pastes are collapsed into [Pasted text #n] placeholders.")
+	}
+	m.dialog.ShowContent("Pasted text", m.pasteBufferContent())
+	return m, nil
+}
+
+// pasteBufferContent renders every collapsed paste under its placeholder, in
+// paste order.
+func (m *Model) pasteBufferContent() string {
+	ids := make([]int, 0, len(m.pasteChunks))
+	for id := range m.pasteChunks {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var b strings.Builder
+	for i, id := range ids {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		text := m.pasteChunks[id]
+		b.WriteString(pastePlaceholder(id, text))
+		b.WriteString("\n\n")
+		b.WriteString(strings.TrimRight(text, "\n"))
+	}
+	return b.String()
+}
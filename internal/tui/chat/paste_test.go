@@ -0,0 +1,144 @@
+package chat
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+func TestNotePasteBurstKey(t *testing.T) {
+	m := newTestChatModel(false)
+	start := time.Now()
+	char := tea.KeyPressMsg{Code: 'a', Text: "a"}
+	enter := tea.KeyPressMsg{Code: tea.KeyEnter}
+
+	for i := 0; i < pasteBurstMinKeys; i++ {
+		m.notePasteBurstKey(char, start.Add(time.Duration(i)*time.Millisecond))
+	}
+	if !m.notePasteBurstKey(enter, start.Add(pasteBurstMinKeys*time.Millisecond)) {
+		t.Fatal("Enter right after a burst of keys should count as pasted")
+	}
+
+	if m.notePasteBurstKey(enter, start.Add(time.Second)) {
+		t.Fatal("Enter after a pause should not count as pasted")
+	}
+
+	for i := 0; i < pasteBurstMinKeys; i++ {
+		m.notePasteBurstKey(char, start.Add(2*time.Second+time.Duration(i)*time.Millisecond))
+	}
+	m.notePasteBurstKey(tea.KeyPressMsg{Code: tea.KeyLeft}, start.Add(2*time.Second+pasteBurstMinKeys*time.Millisecond))
+	if m.notePasteBurstKey(enter, start.Add(2*time.Second+(pasteBurstMinKeys+1)*time.Millisecond)) {
+		t.Fatal("a non-text key should end the burst")
+	}
+}
+
+func TestUnbracketedPasteEnterInsertsNewline(t *testing.T) {
+	m := newTestChatModel(false)
+
+	// Keystrokes delivered back to back, as a terminal without bracketed paste
+	// delivers a paste.
+	for _, r := range "first line" {
+		_, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	_, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEnter})
+	for _, r := range "second line" {
+		_, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+
+	if m.streaming || len(m.messages) != 0 {
+		t.Fatal("Enter inside a paste must not send the message")
+	}
+	if got := m.textarea.Value(); got != "first line\nsecond line" {
+		t.Fatalf("textarea = %q, want both pasted lines", got)
+	}
+}
+
+func TestPastePlaceholderExpandsInSentMessage(t *testing.T) {
+	stubClipboard(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	m := newTestChatModel(false)
+
+	pasted := strings.Repeat("log line\n", 20)
+	_, _ = m.handlePasteMsg(tea.PasteMsg{Content: pasted})
+	m.textarea.InsertString(" what went wrong?")
+	if strings.Contains(m.textarea.Value(), "log line") {
+		t.Fatalf("textarea = %q, want the paste collapsed", m.textarea.Value())
+	}
+
+	m.pasteBurstLast = time.Time{}
+	_, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEnter})
+
+	if len(m.messages) == 0 {
+		t.Fatal("expected the message to be sent")
+	}
+	last := m.messages[len(m.messages)-1]
+	if last.Role != llm.RoleUser {
+		t.Fatalf("last message role = %q, want user", last.Role)
+	}
+	want := strings.TrimSpace(pasted + " what went wrong?")
+	if last.TextContent != want {
+		t.Fatalf("sent %q, want %q", last.TextContent, want)
+	}
+	if len(m.pasteChunks) != 0 {
+		t.Fatal("paste buffer should be emptied once sent")
+	}
+}
+
+func TestCmdPasteShowAndClear(t *testing.T) {
+	stubClipboard(t)
+	m := newTestChatModel(false)
+
+	result, _ := m.ExecuteCommand("/paste")
+	m = result.(*Model)
+	if !strings.Contains(m.footerMessage, "No collapsed pastes") {
+		t.Fatalf("footer = %q, want empty-buffer notice", m.footerMessage)
+	}
+
+	pasted := strings.Repeat("0123456789", 11) + "\nsecond"
+	_, _ = m.handlePasteMsg(tea.PasteMsg{Content: pasted})
+
+	result, _ = m.ExecuteCommand("/paste show")
+	m = result.(*Model)
+	if m.dialog.Type() != DialogContent {
+		t.Fatalf("dialog = %v, want content dialog", m.dialog.Type())
+	}
+	if got := m.pasteBufferContent(); !strings.Contains(got, "[Pasted text #1 +2 lines]") || !strings.Contains(got, "second") {
+		t.Fatalf("paste buffer content = %q", got)
+	}
+	m.dialog.Close()
+
+	result, _ = m.ExecuteCommand("/paste clear")
+	m = result.(*Model)
+	if len(m.pasteChunks) != 0 {
+		t.Fatal("expected /paste clear to drop the buffer")
+	}
+}
+
+func TestCmdPasteShowKeepsComposerDraft(t *testing.T) {
+	stubClipboard(t)
+	m := newTestChatModel(false)
+
+	pasted := strings.Repeat("0123456789", 11) + "\nsecond"
+	_, _ = m.handlePasteMsg(tea.PasteMsg{Content: pasted})
+	m.textarea.InsertString(" what is this?")
+	draft := m.textarea.Value()
+
+	// Type the command in front of the draft and press Enter.
+	m.setTextareaValue("/paste show " + draft)
+	m.pasteBurstLast = time.Time{}
+	result, _ := m.handleKeyMsg(tea.KeyPressMsg{Code: tea.KeyEnter})
+	m = result.(*Model)
+
+	if m.dialog.Type() != DialogContent {
+		t.Fatalf("dialog = %v, want content dialog", m.dialog.Type())
+	}
+	if got := m.textarea.Value(); got != draft {
+		t.Fatalf("composer = %q, want the draft %q kept", got, draft)
+	}
+	if len(m.messages) != 0 || len(m.pasteChunks) != 1 {
+		t.Fatalf("messages = %d, pastes = %d; want nothing sent and the paste kept", len(m.messages), len(m.pasteChunks))
+	}
+}
@@ -0,0 +1,92 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/session"
+)
+
+// pinPreviewRunes bounds the message preview shown after /pin and /unpin.
+const pinPreviewRunes = 60
+
+// cmdPin sets or clears the pinned flag on a user message in the active
+// context. With no argument it targets the latest user message; otherwise the
+// argument is the 1-based position among active user messages. Pinned messages
+// are replayed verbatim by compaction instead of being summarized.
+func (m *Model) cmdPin(args []string, pinned bool) (tea.Model, tea.Cmd) {
+	m.setTextareaValue("")
+	name := "pin"
+	if !pinned {
+		name = "unpin"
+	}
+	if len(args) > 1 {
+		return m.showSystemMessage(fmt.Sprintf("Usage: /%s [n]", name))
+	}
+	if m.streaming {
+		return m.showFooterWarning(fmt.Sprintf("Wait for the response to finish before using /%s.", name))
+	}
+
+	m.messagesMu.Lock()
+	candidates := m.pinCandidateIndexes()
+	if len(candidates) == 0 {
+		m.messagesMu.Unlock()
+		return m.showFooterWarning("No user messages to " + name + ".")
+	}
+	pos := len(candidates)
+	if len(args) == 1 {
+		n, err := strconv.Atoi(strings.TrimSpace(args[0]))
+		if err != nil || n < 1 || n > len(candidates) {
+			m.messagesMu.Unlock()
+			return m.showFooterError(fmt.Sprintf("Message number must be between 1 and %d.", len(candidates)))
+		}
+		pos = n
+	}
+	idx := candidates[pos-1]
+	m.messages[idx].Pinned = pinned
+	target := m.messages[idx]
+	m.messagesMu.Unlock()
+
+	if updater, ok := m.store.(session.MessagePinUpdater); ok && m.sess != nil && target.ID != 0 {
+		if err := updater.SetMessagePinned(context.Background(), m.sess.ID, target.ID, pinned); err != nil {
+			return m.showFooterError(fmt.Sprintf("Failed to save %s: %v", name, err))
+		}
+	}
+	m.invalidateHistoryCache()
+
+	verb := "Pinned"
+	if !pinned {
+		verb = "Unpinned"
+	}
+	return m.showFooterSuccess(fmt.Sprintf("%s message %d: %s", verb, pos, pinPreview(target.TextContent)))
+}
+
+// pinCandidateIndexes returns indexes into m.messages of real user messages in
+// the active (post-compaction) context. Callers must hold messagesMu.
+func (m *Model) pinCandidateIndexes() []int {
+	var indexes []int
+	for i := m.compactionIdx; i < len(m.messages); i++ {
+		msg := m.messages[i]
+		if msg.Role != llm.RoleUser || llm.IsInternalCompactionSummaryText(msg.TextContent) {
+			continue
+		}
+		if strings.TrimSpace(msg.TextContent) == "" {
+			continue
+		}
+		indexes = append(indexes, i)
+	}
+	return indexes
+}
+
+func pinPreview(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= pinPreviewRunes {
+		return text
+	}
+	return string(runes[:pinPreviewRunes]) + "…"
+}
@@ -0,0 +1,72 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/samsaffron/term-llm/internal/agents"
+)
+
+// useProjectContext switches the engine to next, e.g. after the session
+// moved to another directory. A /context off from prev carries over.
+func (m *Model) useProjectContext(next, prev *agents.ProjectContext) {
+	if next != nil && prev != nil {
+		next.SetEnabled(prev.Enabled())
+	}
+	if m.engine == nil {
+		return
+	}
+	if next == nil {
+		m.engine.SetSystemContext(nil)
+		return
+	}
+	m.engine.SetSystemContext(next.SystemText)
+}
+
+func (m *Model) cmdContext(args []string) (tea.Model, tea.Cmd) {
+	pc := m.runtimeSystemContext.ProjectContext
+	if pc == nil {
+		return m.showSystemMessage("No project context is injected: `agents_md.enabled` is off, or the system prompt already includes the project instructions.")
+	}
+
+	sub := ""
+	if len(args) > 0 {
+		sub = strings.ToLower(args[0])
+	}
+	switch sub {
+	case "":
+		return m.showSystemMessage(projectContextListing(pc))
+	case "on":
+		pc.SetEnabled(true)
+		m.setTextareaValue("")
+		return m.showFooterSuccess("Project context enabled for the next turn.")
+	case "off":
+		pc.SetEnabled(false)
+		m.setTextareaValue("")
+		return m.showFooterMuted("Project context disabled for this session.")
+	default:
+		return m.showSystemMessage(fmt.Sprintf("Unknown subcommand: %s\n\nUsage:\n- `/context` - Show injected project instructions\n- `/context off` - Stop injecting them for this session\n- `/context on` - Inject them again", sub))
+	}
+}
+
+func projectContextListing(pc *agents.ProjectContext) string {
+	files := pc.Files()
+	if len(files) == 0 {
+		return fmt.Sprintf("No project instruction files found (looked for AGENTS.override.md or %s from the repository root down, then CLAUDE.md and other fallbacks).", strings.Join(pc.Names(), ", "))
+	}
+	var b strings.Builder
+	if pc.Enabled() {
+		b.WriteString("Project context injected each turn:\n")
+	} else {
+		b.WriteString("Project context is off for this session (`/context on` to inject it again). Found:\n")
+	}
+	for _, f := range files {
+		note := ""
+		if f.Truncated {
+			note = ", truncated"
+		}
+		fmt.Fprintf(&b, "\n- `%s` (%d chars%s)", f.Path, f.Chars, note)
+	}
+	return b.String()
+}
@@ -0,0 +1,50 @@
+package chat
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/samsaffron/term-llm/internal/agents"
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+func TestCmdContextTogglesProjectContext(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "AGENTS.md"), []byte("use tabs"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	pc := agents.NewProjectContext(dir, nil, 0)
+	m := newCmdTestModel(&mockStore{})
+	m.engine = llm.NewEngine(llm.NewMockProvider("mock"), nil)
+	m.runtimeSystemContext.ProjectContext = pc
+	m.useProjectContext(pc, nil)
+
+	if listing := projectContextListing(pc); !strings.Contains(listing, filepath.Join(dir, "AGENTS.md")) || !strings.Contains(listing, "injected each turn") {
+		t.Fatalf("listing = %q", listing)
+	}
+
+	result, _ := m.ExecuteCommand("/context off")
+	m = result.(*Model)
+	if pc.Enabled() || m.engine.SystemContext()() != "" {
+		t.Fatal("/context off should stop injecting the project context")
+	}
+	if listing := projectContextListing(pc); !strings.Contains(listing, "off for this session") {
+		t.Fatalf("listing = %q", listing)
+	}
+
+	m.ExecuteCommand("/context on")
+	if !strings.Contains(m.engine.SystemContext()(), "use tabs") {
+		t.Fatal("/context on should inject the project context again")
+	}
+
+	// Moving to another directory keeps the session's choice.
+	pc.SetEnabled(false)
+	next := agents.NewProjectContext(t.TempDir(), nil, 0)
+	m.useProjectContext(next, pc)
+	if next.Enabled() {
+		t.Fatal("a /context off should carry over to the new directory")
+	}
+}
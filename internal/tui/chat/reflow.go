@@ -0,0 +1,50 @@
+package chat
+
+import (
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// After an alt-screen resize only the history near the viewport is reflowed
+// right away. The rest is reflowed in the background, historyReflowBatch
+// blocks per tick, so a long session never stalls the resize.
+const (
+	historyReflowInterval = 30 * time.Millisecond
+	historyReflowBatch    = 20
+)
+
+// scheduleHistoryReflow starts the background reflow loop unless a tick is
+// already pending.
+func (m *Model) scheduleHistoryReflow() tea.Cmd {
+	if m.historyReflowPending || !m.altScreen || m.chatRenderer == nil {
+		return nil
+	}
+	m.historyReflowPending = true
+	return tea.Tick(historyReflowInterval, func(time.Time) tea.Msg {
+		return historyReflowTickMsg{}
+	})
+}
+
+// handleHistoryReflowTick reflows a batch of history blocks that the last
+// render reused from an earlier width. The blocks are all above the viewport,
+// so the next history rebuild keeps the view's distance from the bottom
+// instead of letting the changed heights shift it.
+func (m *Model) handleHistoryReflowTick() (tea.Model, tea.Cmd) {
+	m.historyReflowPending = false
+	if !m.altScreen || m.chatRenderer == nil || m.chatRenderer.StaleBlocks() == 0 {
+		return m, nil
+	}
+	// A history rebuild resets the incremental streaming render; wait it out.
+	if m.streaming || m.activeSkillRunCount() > 0 {
+		return m, m.scheduleHistoryReflow()
+	}
+
+	messages, _ := m.historyRenderMessages()
+	if m.chatRenderer.RefreshStaleBlocks(messages, historyReflowBatch) > 0 {
+		m.viewCache.historyValid = false
+		m.viewCache.keepLinesFromBottom = true
+		m.bumpContentVersion()
+	}
+	return m, m.scheduleHistoryReflow()
+}
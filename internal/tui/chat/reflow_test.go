@@ -0,0 +1,58 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/session"
+)
+
+func TestAltScreenResizeReflowsHistoryInBackground(t *testing.T) {
+	m := newTestChatModel(true)
+	m.applyWindowSize(tea.WindowSizeMsg{Width: 100, Height: 30})
+	for i := 0; i < 200; i++ {
+		role := llm.RoleUser
+		if i%2 == 1 {
+			role = llm.RoleAssistant
+		}
+		text := fmt.Sprintf("message %d %s", i, strings.Repeat("word ", 30))
+		m.messages = append(m.messages, session.Message{
+			ID:          int64(i + 1),
+			Role:        role,
+			TextContent: text,
+			Parts:       []llm.Part{{Type: llm.PartText, Text: text}},
+		})
+	}
+	m.View()
+
+	_, cmd := m.Update(tea.WindowSizeMsg{Width: 70, Height: 30})
+	if cmd == nil {
+		t.Fatal("alt-screen resize should schedule the background reflow")
+	}
+	m.View()
+	if m.chatRenderer.StaleBlocks() == 0 {
+		t.Fatal("expected history far above the viewport to keep its old width after resize")
+	}
+	atBottom := m.viewport.AtBottom()
+
+	for i := 0; m.chatRenderer.StaleBlocks() > 0; i++ {
+		if i > 50 {
+			t.Fatalf("background reflow did not finish; %d blocks still stale", m.chatRenderer.StaleBlocks())
+		}
+		_, cmd = m.Update(historyReflowTickMsg{})
+		if cmd == nil {
+			t.Fatal("reflow should keep ticking while stale blocks remain")
+		}
+		m.View()
+		if m.viewport.AtBottom() != atBottom {
+			t.Fatal("background reflow moved the viewport away from the bottom")
+		}
+	}
+
+	if _, cmd = m.Update(historyReflowTickMsg{}); cmd != nil {
+		t.Fatal("reflow should stop once history is current")
+	}
+}
@@ -79,7 +79,7 @@ func (m *Model) View() tea.View {
 
 	// History (if scrolling)
 	if m.scrollOffset > 0 {
-		history := m.renderHistory()
+		history := m.applyFindHighlight(m.renderHistory(), 0)
 		b.WriteString(history)
 		renderedLines += lipgloss.Height(history)
 		b.WriteString("\n")
@@ -316,12 +316,17 @@ func (m *Model) viewAltScreen() string {
 		// Check if user is at bottom BEFORE setting content (which changes maxYOffset)
 		wasAtBottom := m.viewport.AtBottom()
 		firstRender := m.viewCache.lastViewportView == ""
+		linesFromBottom := m.viewport.TotalLineCount() - m.viewport.YOffset()
 		setContentStart := time.Now()
 		if usedIncrementalAppend {
 			m.viewport.SetContentLines(contentLines)
 		} else {
 			m.viewport.SetContent(contentStr)
 		}
+		if m.viewCache.keepLinesFromBottom {
+			m.viewport.SetYOffset(m.viewport.TotalLineCount() - linesFromBottom)
+			m.viewCache.keepLinesFromBottom = false
+		}
 		setContentEnd := time.Now()
 		if m.streamPerf != nil {
 			m.streamPerf.RecordDuration(durationMetricSetContent, setContentEnd.Sub(setContentStart))
@@ -339,6 +344,7 @@ func (m *Model) viewAltScreen() string {
 			m.viewport.GotoBottom()
 			m.scrollToBottom = false
 		}
+		m.applyScreenScrollTarget()
 	}
 
 	// Scroll to bottom after response completes (regardless of previous scroll position)
@@ -412,7 +418,7 @@ func (m *Model) viewAltScreen() string {
 		}
 	}
 	// Post-process: apply selection highlight
-	viewOutput := m.viewCache.lastViewportView
+	viewOutput := m.applyFindHighlight(m.viewCache.lastViewportView, m.viewport.YOffset())
 	if m.selection.Active {
 		viewOutput = m.applySelectionHighlight(viewOutput)
 	}
@@ -641,6 +647,23 @@ func (m *Model) buildFooterLayout() footerLayout {
 		appendMetaRow(pendingStyle.Render("  ⏳ " + pendingText + " (" + label + ")"))
 	}
 
+	if len(m.queuedMessages) > 0 {
+		queuedStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+		for i, queued := range m.queuedMessages {
+			queuedText := queued.label()
+			maxLen := m.width - 20 // account for prefix/suffix
+			if maxLen > 0 && len(queuedText) > maxLen {
+				queuedText = queuedText[:maxLen] + "…"
+			}
+			appendMetaRow(queuedStyle.Render(fmt.Sprintf("  %d. %s (queued)", i+1, queuedText)))
+		}
+		hint := "sends when this response finishes · ctrl+x clears"
+		if !m.streaming {
+			hint = "enter on an empty prompt sends the next · ctrl+x clears"
+		}
+		appendMetaRow(queuedStyle.Italic(true).Render("     " + hint))
+	}
+
 	if len(m.files) > 0 {
 		var fileNames []string
 		for _, f := range m.files {
@@ -1015,7 +1038,11 @@ func (m *Model) renderStatusLine() string {
 		return statusSegment{text: text, width: lipgloss.Width(text), priority: priority, essential: essential}
 	}
 
-	usageLong, usageShort := m.statusLineUsageParts()
+	usageLong, usageShort, overCompactThreshold := m.statusLineUsageParts()
+	usageStyle := mutedStyle
+	if overCompactThreshold {
+		usageStyle = warningStyle
+	}
 
 	baseSegments := make([]statusSegment, 0, 10)
 	if m.agentName != "" {
@@ -1065,7 +1092,7 @@ func (m *Model) renderStatusLine() string {
 		baseSegments = append(baseSegments, seg(mutedStyle.Render(fmt.Sprintf("%d image(s)", len(m.images))), 55, false))
 	}
 	if usageLong != "" {
-		usageSeg := seg(mutedStyle.Render(usageLong), 50, true)
+		usageSeg := seg(usageStyle.Render(usageLong), 50, true)
 		usageSeg.isUsage = true
 		baseSegments = append(baseSegments, usageSeg)
 	}
@@ -1134,7 +1161,7 @@ func (m *Model) renderStatusLine() string {
 		if usage == "" {
 			continue
 		}
-		rendered := mutedStyle.Render(usage)
+		rendered := usageStyle.Render(usage)
 		renderedUsage[usage] = statusSegment{text: rendered, width: lipgloss.Width(rendered)}
 	}
 	for _, text := range toolOptions {
@@ -1167,10 +1194,10 @@ func (m *Model) renderStatusLine() string {
 		}
 	}
 	if usageLong != "" {
-		candidates = append(candidates, []statusSegment{seg(mutedStyle.Render(usageLong), 50, false)})
+		candidates = append(candidates, []statusSegment{seg(usageStyle.Render(usageLong), 50, false)})
 	}
 	if usageShort != "" && usageShort != usageLong {
-		candidates = append(candidates, []statusSegment{seg(mutedStyle.Render(usageShort), 50, false)})
+		candidates = append(candidates, []statusSegment{seg(usageStyle.Render(usageShort), 50, false)})
 	}
 	if usageLong != "" {
 		for _, base := range baseVariants {
@@ -1210,6 +1237,12 @@ func (m *Model) renderStatusLine() string {
 			candidates[i] = append(candidates[i], copySeg)
 		}
 	}
+	if m.find.active() {
+		findSeg := seg(warningStyle.Render(m.findStatus()), 60, false)
+		for i := range candidates {
+			candidates[i] = append(candidates[i], findSeg)
+		}
+	}
 
 	rightWidths := make([]int, len(rightVariants))
 	for i, right := range rightVariants {
@@ -1252,8 +1285,13 @@ func (m *Model) statusLineWorktreePart() string {
 	return "wt:" + name
 }
 
-func (m *Model) statusLineUsageParts() (string, string) {
+// statusLineUsageParts returns long and short context-usage labels. The final
+// value reports whether usage has reached the compaction threshold, in which
+// case the long label also hints at /compact.
+func (m *Model) statusLineUsageParts() (string, string, bool) {
 	usageBase := ""
+	var details []string
+	overThreshold := false
 	if m.engine != nil && m.engine.InputLimit() > 0 {
 		contextTokens := 0
 		if !m.streaming {
@@ -1269,26 +1307,49 @@ func (m *Model) statusLineUsageParts() (string, string) {
 				used = "0"
 			}
 			usageBase = fmt.Sprintf("~%s/%s", used, llm.FormatTokenCount(limit))
+			details = append(details, fmt.Sprintf("%d%%", contextTokens*100/limit))
+			overThreshold = contextTokens >= m.statusLineCompactThreshold(limit)
 		}
 	}
 
-	cachedInputTokens := 0
+	cachedLabel := ""
 	if m.stats != nil && m.stats.CachedInputTokens > 0 {
-		cachedInputTokens = m.stats.CachedInputTokens
+		cachedLabel = llm.FormatTokenCount(m.stats.CachedInputTokens)
 	}
-	if cachedInputTokens <= 0 {
-		return usageBase, usageBase
+	usageShort := usageBase
+	if cachedLabel != "" {
+		details = append(details, cachedLabel+" cached")
+		if usageBase != "" {
+			usageShort = fmt.Sprintf("%s (%s C)", usageBase, cachedLabel)
+		} else {
+			usageShort = cachedLabel + " C"
+		}
 	}
-	cachedLabel := llm.FormatTokenCount(cachedInputTokens)
-	if cachedLabel == "" {
-		return usageBase, usageBase
+	if cost := m.statusLineCost(); cost != "" {
+		details = append(details, cost)
 	}
-	longCache := fmt.Sprintf("%s cached", cachedLabel)
-	shortCache := fmt.Sprintf("%s C", cachedLabel)
-	if usageBase != "" {
-		return fmt.Sprintf("%s (%s)", usageBase, longCache), fmt.Sprintf("%s (%s)", usageBase, shortCache)
+	usageLong := usageBase
+	if len(details) > 0 {
+		if usageBase != "" {
+			usageLong = fmt.Sprintf("%s (%s)", usageBase, strings.Join(details, ", "))
+		} else {
+			usageLong = strings.Join(details, ", ")
+		}
+	}
+	if overThreshold {
+		usageLong += " · /compact"
 	}
-	return longCache, shortCache
+	return usageLong, usageShort, overThreshold
+}
+
+// statusLineCompactThreshold returns the token count at which the status line
+// flags context pressure: the engine's soft compaction threshold, or the
+// default ratio when auto-compaction is disabled.
+func (m *Model) statusLineCompactThreshold(limit int) int {
+	if soft, _, enabled := m.engine.CompactionThresholds(); enabled && soft > 0 {
+		return soft
+	}
+	return int(float64(limit) * llm.DefaultCompactionConfig().ThresholdRatio)
 }
 
 func (m *Model) statusLineToolsParts(successStyle lipgloss.Style) (string, string) {
@@ -1707,31 +1768,12 @@ func (m *Model) renderHistory() string {
 		mode = render.RenderModeInline
 	}
 
-	// Prepare messages to render
-	// In alt-screen mode, viewport handles scrolling via YOffset, so render all messages
-	// In inline mode, use message-based scrollOffset to slice visible messages
-	messages := m.messages
-	scrollOffset := 0
-	if !m.altScreen && m.scrollOffset > 0 {
-		// Inline mode: pre-slice messages based on scroll offset
-		endIdx := len(messages) - m.scrollOffset
-		if endIdx < 1 {
-			endIdx = 1
-		}
-		messages = messages[:endIdx]
-		scrollOffset = m.scrollOffset
-	}
-
-	// In alt screen mode, skip all messages from the last turn if completedStream is showing it.
-	// completedStream contains everything from the tracker (all turns since the last user message).
-	if m.altScreen && m.viewCache.completedStream != "" && len(messages) > 0 {
-		i := len(messages) - 1
-		// Skip all assistant and tool messages at the end of the list
-		for i >= 0 && (messages[i].Role == llm.RoleAssistant || messages[i].Role == llm.RoleTool) {
-			i--
-		}
-		// Include up to the last user message
-		messages = messages[:i+1]
+	messages, scrollOffset := m.historyRenderMessages()
+	linesBelow := 0
+	if m.altScreen {
+		// Measured against the previous history render; the renderer keeps a
+		// screen of margin for the difference.
+		linesBelow = max(len(m.viewCache.historyLines)-m.viewport.YOffset()-m.viewport.Height(), 0)
 	}
 
 	state := render.RenderState{
@@ -1740,6 +1782,7 @@ func (m *Model) renderHistory() string {
 			Height:       m.viewportRows,
 			ScrollOffset: scrollOffset,
 			AtBottom:     scrollOffset == 0,
+			LinesBelow:   linesBelow,
 		},
 		Mode:                        mode,
 		Width:                       m.width,
@@ -1760,6 +1803,33 @@ func (m *Model) renderHistory() string {
 	return b.String()
 }
 
+// historyRenderMessages returns the messages renderHistory passes to the chat
+// renderer and the inline scroll offset to render them at.
+func (m *Model) historyRenderMessages() ([]session.Message, int) {
+	// In alt-screen mode, viewport handles scrolling via YOffset, so render all messages.
+	// In inline mode the renderer ends the visible window scrollOffset messages
+	// before the last one.
+	messages := m.messages
+	scrollOffset := 0
+	if !m.altScreen {
+		scrollOffset = min(m.scrollOffset, max(len(messages)-1, 0))
+	}
+
+	// In alt screen mode, skip all messages from the last turn if completedStream is showing it.
+	// completedStream contains everything from the tracker (all turns since the last user message).
+	if m.altScreen && m.viewCache.completedStream != "" && len(messages) > 0 {
+		i := len(messages) - 1
+		// Skip all assistant and tool messages at the end of the list
+		for i >= 0 && (messages[i].Role == llm.RoleAssistant || messages[i].Role == llm.RoleTool) {
+			i--
+		}
+		// Include up to the last user message
+		messages = messages[:i+1]
+	}
+
+	return messages, scrollOffset
+}
+
 func (m *Model) renderMarkdown(content string) string {
 	if content == "" {
 		return ""
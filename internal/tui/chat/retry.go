@@ -0,0 +1,161 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/session"
+)
+
+// cmdRetry regenerates the last assistant response. It drops everything after
+// the latest user message (the answer plus any tool calls and results of that
+// turn) from memory and from the session store, then streams a new response to
+// the same message. An optional argument switches model first, so
+// "/retry gpt-5-high" asks a different model the same question.
+func (m *Model) cmdRetry(args []string) (tea.Model, tea.Cmd) {
+	m.setTextareaValue("")
+	if len(args) > 1 {
+		return m.showSystemMessage("Usage: /retry [provider:model]")
+	}
+	if m.streaming {
+		return m.showFooterWarning("Wait for the response to finish before using /retry.")
+	}
+	if m.worktreeOperationBusy() {
+		return m.showFooterWarning("Wait for the current worktree operation to finish before retrying.")
+	}
+	if m.sess == nil {
+		return m.showFooterWarning("Nothing to retry.")
+	}
+
+	m.messagesMu.Lock()
+	idx, ok := m.lastUserMessageIndex()
+	if !ok {
+		m.messagesMu.Unlock()
+		return m.showFooterWarning("Nothing to retry.")
+	}
+	if !m.hasResponseAfter(idx) {
+		m.messagesMu.Unlock()
+		return m.showFooterWarning("The last message has no response to retry yet.")
+	}
+	userMsg := m.messages[idx]
+	m.messagesMu.Unlock()
+
+	var preSendCmds []tea.Cmd
+	if len(args) == 1 {
+		cmd, ok := m.switchModelForRetry(args[0])
+		if !ok {
+			return m, cmd
+		}
+		if cmd != nil {
+			preSendCmds = append(preSendCmds, cmd)
+		}
+	}
+
+	seq, err := m.storedSequence(userMsg)
+	if err == nil {
+		if seq >= 0 {
+			seq++
+		}
+		err = m.truncateHistory(idx+1, seq)
+	}
+	if err != nil {
+		return m.showFooterError(fmt.Sprintf("Failed to drop the last response: %v", err))
+	}
+	// A model-switch marker belongs between the question and the regenerated
+	// answer, so it is appended only once the old answer is gone.
+	m.appendPendingModelSwitchMarker()
+	m.recordCurrentModelUse()
+
+	theme := m.styles.Theme()
+	notice := lipgloss.NewStyle().Foreground(theme.Muted).Render(
+		fmt.Sprintf("↻ Regenerating response with %s", m.modelName))
+	return m.beginAssistantTurn(userMsg.TextContent, tea.Println(notice), preSendCmds)
+}
+
+// lastUserMessageIndex returns the index in m.messages of the latest real user
+// message in the active context. Callers must hold messagesMu.
+func (m *Model) lastUserMessageIndex() (int, bool) {
+	candidates := m.pinCandidateIndexes()
+	if len(candidates) == 0 {
+		return 0, false
+	}
+	return candidates[len(candidates)-1], true
+}
+
+// hasResponseAfter reports whether an assistant or tool message follows the
+// message at idx. Event rows such as model-switch markers do not count.
+// Callers must hold messagesMu.
+func (m *Model) hasResponseAfter(idx int) bool {
+	for _, msg := range m.messages[idx+1:] {
+		if msg.Role == llm.RoleAssistant || msg.Role == llm.RoleTool {
+			return true
+		}
+	}
+	return false
+}
+
+// switchModelForRetry resolves and switches to the model named by a /retry
+// argument, deferring the switch marker until the old answer is dropped. When
+// ok is false the switch failed and cmd reports why.
+func (m *Model) switchModelForRetry(modelArg string) (cmd tea.Cmd, ok bool) {
+	provider, model := m.currentProviderAndModel()
+	resolved, valid := resolveProviderModelArg(modelArg, m.config, provider)
+	if !valid {
+		_, cmd = m.showSystemMessage(fmt.Sprintf("Invalid model format: %s", modelArg))
+		return cmd, false
+	}
+	if resolved == provider+":"+model {
+		return nil, true
+	}
+	m.pauseGoalForLocalAction("paused for model switch")
+	_, cmd = m.switchModelWithOptions(resolved, switchModelOptions{deferMarker: true})
+	newProvider, newModel := m.currentProviderAndModel()
+	return cmd, newProvider+":"+newModel == resolved
+}
+
+// truncateHistory drops m.messages[keep:] locally and every stored message at
+// or after fromSequence, so a reload does not bring the removed messages back.
+// A negative fromSequence (never persisted) leaves the store alone. The
+// engine's conversation is reset too, so providers that chain requests
+// server-side (Responses API previous_response_id) resend the shortened
+// history instead of continuing from the dropped turns.
+func (m *Model) truncateHistory(keep, fromSequence int) error {
+	if m.store != nil && fromSequence >= 0 {
+		if err := session.TruncateMessages(context.Background(), m.store, m.sess.ID, fromSequence); err != nil {
+			return err
+		}
+	}
+
+	m.messagesMu.Lock()
+	if keep < len(m.messages) {
+		m.messages = m.messages[:keep]
+	}
+	m.messagesMu.Unlock()
+	if m.engine != nil {
+		m.engine.ResetConversation()
+	}
+	m.invalidateHistoryCache()
+	m.invalidateViewCache()
+	return nil
+}
+
+// storedSequence returns the sequence the store assigned to msg, or -1 if it
+// was never persisted. Local copies do not always carry the stored sequence,
+// so persisted messages are looked up by ID.
+func (m *Model) storedSequence(msg session.Message) (int, error) {
+	if m.store == nil || msg.ID == 0 {
+		return msg.Sequence, nil
+	}
+	stored, err := m.store.GetMessageByID(context.Background(), msg.ID)
+	if errors.Is(err, session.ErrNotFound) || (err == nil && stored == nil) {
+		return msg.Sequence, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return stored.Sequence, nil
+}
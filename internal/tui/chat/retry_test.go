@@ -0,0 +1,80 @@
+package chat
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/session"
+)
+
+// resetCountingProvider records provider-side conversation resets, standing
+// in for a Responses API provider that chains on previous_response_id.
+type resetCountingProvider struct {
+	*llm.MockProvider
+	resets atomic.Int32
+}
+
+func (p *resetCountingProvider) ResetConversation() {
+	p.resets.Add(1)
+}
+
+// useResetCountingEngine swaps m's engine for one whose provider counts
+// conversation resets.
+func useResetCountingEngine(m *Model) *resetCountingProvider {
+	provider := &resetCountingProvider{MockProvider: llm.NewMockProvider("mock")}
+	m.provider = provider
+	m.engine = llm.NewEngine(provider, nil)
+	return provider
+}
+
+func seedChatTranscript(m *Model, store *mockStore, transcript []llm.Message) {
+	for i, msg := range transcript {
+		row := session.NewMessage(m.sess.ID, msg, i)
+		row.ID = int64(i + 1)
+		m.messages = append(m.messages, *row)
+		store.ensureMessages()
+		store.messages[m.sess.ID] = append(store.messages[m.sess.ID], *row)
+	}
+}
+
+func TestCmdRetryResetsEngineConversation(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	store := &mockStore{}
+	m := newTestChatModel(false)
+	m.store = store
+	m.sess = &session.Session{ID: "sess-retry-reset"}
+	provider := useResetCountingEngine(m)
+	seedChatTranscript(m, store, []llm.Message{llm.UserText("question"), llm.AssistantText("bad answer")})
+
+	result, _ := m.ExecuteCommand("/retry")
+	m = result.(*Model)
+	if !m.streaming {
+		t.Fatalf("/retry should start a new response; footer=%q", m.footerMessage)
+	}
+	if got := provider.resets.Load(); got != 1 {
+		t.Fatalf("provider conversation resets = %d, want 1 so the dropped answer is not chained on", got)
+	}
+}
+
+func TestCmdUndoResetsEngineConversation(t *testing.T) {
+	store := &mockStore{}
+	m := newCmdTestModel(store)
+	m.sess = &session.Session{ID: "sess-undo-reset", UserTurns: 2}
+	provider := useResetCountingEngine(m)
+	seedChatTranscript(m, store, []llm.Message{
+		llm.UserText("first"),
+		llm.AssistantText("one"),
+		llm.UserText("wrong paste"),
+		llm.AssistantText("two"),
+	})
+
+	m.ExecuteCommand("/undo")
+	if len(m.messages) != 2 {
+		t.Fatalf("messages = %+v, want the first exchange only", m.messages)
+	}
+	if got := provider.resets.Load(); got != 1 {
+		t.Fatalf("provider conversation resets = %d, want 1 so the undone exchange is forgotten server-side", got)
+	}
+}
@@ -0,0 +1,162 @@
+package chat
+
+import (
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/samsaffron/term-llm/internal/config"
+	render "github.com/samsaffron/term-llm/internal/render/chat"
+	"github.com/samsaffron/term-llm/internal/ui"
+)
+
+// SetScreenModeTerminal enables live switching between inline and alt-screen
+// rendering and saves the chosen mode under terminal (see
+// config.ChatTerminalKey). Leave it unset when output is not a terminal.
+func (m *Model) SetScreenModeTerminal(terminal string) {
+	m.screenTerminal = terminal
+}
+
+// AltScreen reports whether chat currently renders in alt-screen mode. It is
+// safe to call from tool goroutines; the mode only changes between turns, so
+// prompts during a turn see a stable value.
+func (m *Model) AltScreen() bool {
+	return m.altScreenShared.Load()
+}
+
+func (m *Model) cmdScreen(args []string) (tea.Model, tea.Cmd) {
+	m.setTextareaValue("")
+	if len(args) > 1 {
+		return m.showFooterWarning("Usage: /screen [inline|alt]")
+	}
+	altScreen := !m.altScreen
+	if len(args) == 1 {
+		switch strings.ToLower(args[0]) {
+		case config.ChatScreenModeAlt, "altscreen", "full":
+			altScreen = true
+		case config.ChatScreenModeInline:
+			altScreen = false
+		default:
+			return m.showFooterWarning("Usage: /screen [inline|alt]")
+		}
+	}
+	return m.switchScreenMode(altScreen)
+}
+
+// switchScreenMode moves chat between inline and alt-screen rendering.
+// Entering alt-screen rebuilds the history viewport from the renderer's block
+// cache, scrolled to the message the inline view was showing. Leaving it
+// prints the messages the terminal scrollback has not seen yet, so the
+// conversation reads the same as if it had been inline all along.
+func (m *Model) switchScreenMode(altScreen bool) (tea.Model, tea.Cmd) {
+	if m.screenTerminal == "" || m.autoSendQueue != nil {
+		return m.showFooterWarning("Screen mode can only be switched in an interactive terminal.")
+	}
+	if m.streaming || m.activeSkillRunCount() > 0 || m.approvalModel != nil || m.askUserModel != nil || m.handoverPreview != nil {
+		return m.showFooterWarning("Finish or cancel the current response before switching screen mode.")
+	}
+	if altScreen == m.altScreen {
+		return m.showFooterMessage("Already in " + screenModeName(altScreen) + " mode.")
+	}
+
+	m.selection = Selection{}
+	var cmd tea.Cmd
+	if altScreen {
+		m.enterAltScreen()
+	} else {
+		cmd = m.leaveAltScreen()
+	}
+
+	mode := screenModeName(altScreen)
+	if err := config.SetChatScreenMode(m.screenTerminal, mode); err != nil {
+		_, footerCmd := m.showFooterWarning("Switched to " + mode + " mode, but could not save it: " + err.Error())
+		return m, tea.Batch(cmd, footerCmd)
+	}
+	if m.config != nil {
+		if m.config.Chat.ScreenModes == nil {
+			m.config.Chat.ScreenModes = make(map[string]string)
+		}
+		m.config.Chat.ScreenModes[m.screenTerminal] = mode
+	}
+	_, footerCmd := m.showFooterSuccess("Screen mode: " + mode)
+	return m, tea.Batch(cmd, footerCmd)
+}
+
+func screenModeName(altScreen bool) string {
+	if altScreen {
+		return config.ChatScreenModeAlt
+	}
+	return config.ChatScreenModeInline
+}
+
+// enterAltScreen switches to alt-screen rendering. Everything in history is
+// already in the terminal scrollback at this point.
+func (m *Model) enterAltScreen() {
+	m.inlineFlushedMessages = len(m.messages)
+	// An inline scroll offset counts messages back from the newest one; keep
+	// that message in view rather than jumping to the bottom.
+	if m.scrollOffset > 0 && len(m.messages) > 0 {
+		m.screenScrollMessage = max(len(m.messages)-1-m.scrollOffset, 0)
+		m.screenScrollPending = true
+	}
+	m.scrollOffset = 0
+	m.altScreen = true
+	m.altScreenShared.Store(true)
+	m.syncAltScreenViewportHeight(m.buildFooterLayout().height)
+	m.forceHistoryRerenderPreservingBlockCache()
+	m.viewCache.lastViewportView = ""
+	m.scrollToBottom = !m.screenScrollPending
+}
+
+// leaveAltScreen switches to inline rendering and returns the command that
+// prints the messages added since chat last left inline mode.
+func (m *Model) leaveAltScreen() tea.Cmd {
+	m.altScreen = false
+	m.altScreenShared.Store(false)
+	m.scrollOffset = 0
+	m.screenScrollPending = false
+	m.viewportRows = ui.RemainingLines(m.height, 8)
+	// Inline mode prints finished turns straight from history, so drop the
+	// alt-screen copy of the last turn and the tracker kept for it.
+	m.viewCache.completedStream = ""
+	m.viewCache.historyValid = false
+	m.resetAltScreenStreamingAppendCache()
+	if m.tracker != nil {
+		m.resetTracker()
+	}
+	if m.chatRenderer == nil {
+		m.inlineFlushedMessages = len(m.messages)
+		return nil
+	}
+	m.chatRenderer.SetSize(m.width, m.height)
+
+	from := min(m.inlineFlushedMessages, len(m.messages))
+	m.inlineFlushedMessages = len(m.messages)
+	history := m.chatRenderer.RenderScrollback(render.RenderState{
+		Messages:                    m.messages,
+		Mode:                        render.RenderModeInline,
+		Width:                       m.width,
+		Height:                      m.height,
+		ReasoningExpansionOverrides: m.reasoningExpansionOverrides,
+	}, from)
+	if strings.TrimSpace(history) == "" {
+		return nil
+	}
+	return tea.Println(strings.TrimRight(history, "\n"))
+}
+
+// applyScreenScrollTarget scrolls the alt-screen viewport to the message the
+// inline view was showing when chat switched screens. It runs after the first
+// history render so the renderer knows where each message starts.
+func (m *Model) applyScreenScrollTarget() {
+	if !m.screenScrollPending || m.chatRenderer == nil {
+		return
+	}
+	m.screenScrollPending = false
+	for i := m.screenScrollMessage; i >= 0; i-- {
+		if line, ok := m.chatRenderer.MessageStartLine(i); ok {
+			m.viewport.SetYOffset(line)
+			return
+		}
+	}
+	m.viewport.GotoBottom()
+}
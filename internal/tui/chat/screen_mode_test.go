@@ -0,0 +1,102 @@
+package chat
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/session"
+)
+
+func screenModeTestMessages(n int) []session.Message {
+	messages := make([]session.Message, n)
+	for i := range messages {
+		role := llm.RoleUser
+		if i%2 == 1 {
+			role = llm.RoleAssistant
+		}
+		text := "message " + string(rune('a'+i))
+		messages[i] = session.Message{
+			ID:          int64(i + 1),
+			Role:        role,
+			TextContent: text,
+			Parts:       []llm.Part{{Type: llm.PartText, Text: text}},
+			Sequence:    i,
+		}
+	}
+	return messages
+}
+
+func TestSwitchScreenModeRoundTrip(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	m := newTestChatModel(false)
+	m.SetScreenModeTerminal("xterm-256color")
+	m.applyWindowSize(tea.WindowSizeMsg{Width: 80, Height: 24})
+	m.messages = screenModeTestMessages(6)
+	m.scrollOffset = 2
+
+	m.switchScreenMode(true)
+	if !m.AltScreen() {
+		t.Fatal("expected alt-screen mode after switching")
+	}
+	if m.inlineFlushedMessages != 6 {
+		t.Fatalf("inlineFlushedMessages = %d, want 6", m.inlineFlushedMessages)
+	}
+	if m.scrollOffset != 0 || !m.screenScrollPending || m.screenScrollMessage != 3 {
+		t.Fatalf("inline scroll offset not translated: offset=%d pending=%v message=%d", m.scrollOffset, m.screenScrollPending, m.screenScrollMessage)
+	}
+	if view := m.View(); !view.AltScreen {
+		t.Fatal("expected view to request the alt screen")
+	}
+	if m.screenScrollPending {
+		t.Fatal("expected the scroll target to be applied by the first alt-screen render")
+	}
+
+	m.messages = append(m.messages, screenModeTestMessages(8)[6:]...)
+	_, cmd := m.switchScreenMode(false)
+	if m.AltScreen() {
+		t.Fatal("expected inline mode after switching back")
+	}
+	if cmd == nil {
+		t.Fatal("expected a command printing the new messages to scrollback")
+	}
+	if m.inlineFlushedMessages != 8 {
+		t.Fatalf("inlineFlushedMessages = %d, want 8", m.inlineFlushedMessages)
+	}
+
+	data, err := os.ReadFile(filepath.Join(configHome, "term-llm", "config.yaml"))
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	if !strings.Contains(string(data), "xterm-256color: inline") {
+		t.Fatalf("screen mode not saved:\n%s", data)
+	}
+	if got := m.config.Chat.ScreenMode("xterm-256color"); got != "inline" {
+		t.Fatalf("in-memory screen mode = %q, want inline", got)
+	}
+}
+
+func TestSwitchScreenModeRefusedWhileStreaming(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	m := newTestChatModel(true)
+	m.SetScreenModeTerminal("xterm")
+	m.streaming = true
+	m.switchScreenMode(false)
+	if !m.AltScreen() {
+		t.Fatal("screen mode changed during a stream")
+	}
+}
+
+func TestSwitchScreenModeRequiresTerminal(t *testing.T) {
+	m := newTestChatModel(true)
+	m.switchScreenMode(false)
+	if !m.AltScreen() {
+		t.Fatal("screen mode changed without a terminal")
+	}
+}
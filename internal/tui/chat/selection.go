@@ -188,10 +188,7 @@ func (m *Model) extractSelectedText() string {
 	// Lazily rebuild base viewport content only when that is the selection source.
 	contentLines := m.sideQuestion.selectionLines
 	if !m.selection.SideQuestion {
-		if m.contentLines == nil && m.viewCache.lastContentStr != "" {
-			m.contentLines = strings.Split(m.viewCache.lastContentStr, "\n")
-		}
-		contentLines = m.contentLines
+		contentLines = m.viewportContentLines()
 	}
 	if len(contentLines) == 0 {
 		return ""
@@ -2,6 +2,7 @@ package chat
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -259,7 +260,11 @@ func (m *Model) registerSkillActivationTools(activation *skills.Activation) erro
 	}
 	for _, definition := range activation.ToolDefs {
 		if tool, ok := m.toolMgr.Registry.Get(definition.Name); ok {
-			m.engine.AddDynamicTool(tool)
+			if err := m.engine.AddDynamicTool(tool); err != nil {
+				_ = m.restoreSkillEngineTools(names, enginePrevious)
+				m.toolMgr.Registry.RestoreSkillTools(names, registryPrevious)
+				return fmt.Errorf("register tools for skill %q: %w", activation.Skill.Name, err)
+			}
 		}
 	}
 	m.skillDynamicToolNames = names
@@ -268,6 +273,21 @@ func (m *Model) registerSkillActivationTools(activation *skills.Activation) erro
 	return nil
 }
 
+// restoreSkillEngineTools removes the named skill tools from the engine and
+// re-registers the tools they replaced.
+func (m *Model) restoreSkillEngineTools(names []string, previous map[string]llm.Tool) error {
+	var errs []error
+	for _, name := range names {
+		m.engine.UnregisterTool(name)
+		if tool := previous[name]; tool != nil {
+			if err := m.engine.RegisterTool(tool); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func (m *Model) applySkillAllowedTools(activation *skills.Activation) {
 	if activation == nil || !activation.AllowedToolsPresent || m.engine == nil {
 		return
@@ -312,11 +332,8 @@ func (m *Model) restoreSkillAllowedTools() {
 
 	if len(m.skillDynamicToolNames) > 0 {
 		if m.engine != nil {
-			for _, name := range m.skillDynamicToolNames {
-				m.engine.UnregisterTool(name)
-				if previous := m.skillDynamicEnginePrevious[name]; previous != nil {
-					m.engine.RegisterTool(previous)
-				}
+			if err := m.restoreSkillEngineTools(m.skillDynamicToolNames, m.skillDynamicEnginePrevious); err != nil {
+				m.SetFooterWarning(fmt.Sprintf("Could not restore tools after skill: %v", err))
 			}
 		}
 		if m.toolMgr != nil && m.toolMgr.Registry != nil {
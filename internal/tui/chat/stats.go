@@ -45,6 +45,69 @@ func (m *Model) cmdStats() (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// cmdSummary handles "/summary": the recap printed after ask and edit runs,
+// rebuilt from this session's history.
+func (m *Model) cmdSummary() (tea.Model, tea.Cmd) {
+	m.setTextareaValue("")
+	m.dialog.ShowContent("Session Summary", m.sessionRunSummary().Text())
+	return m, nil
+}
+
+// sessionRunSummary replays the session history into a run summary. Tools
+// and files come from stored tool results; tokens, cost and elapsed time come
+// from the live stats accumulator, which also covers resumed sessions.
+func (m *Model) sessionRunSummary() ui.RunSummaryData {
+	summary := ui.NewRunSummary(m.statsPricingModel(), 0)
+	all, _ := m.messageSnapshotsForStats()
+	messages := make([]llm.Message, 0, len(all))
+	for _, msg := range all {
+		messages = append(messages, msg.ToLLMMessage())
+	}
+	summary.ObserveMessages(messages)
+	if m.stats != nil {
+		summary.SetStart(m.stats.StartTime)
+		summary.SetTokens(m.stats.InputTokens, m.stats.OutputTokens, m.stats.CachedInputTokens, m.stats.CacheWriteTokens)
+		if cost, err := statsCostEstimator(m.statsPricingModel(), m.stats); err == nil {
+			summary.SetEstimatedCost(cost)
+		}
+	}
+	return summary.Data()
+}
+
+// cmdMessageStats handles "/stats messages [on|off]".
+func (m *Model) cmdMessageStats(args []string) (tea.Model, tea.Cmd) {
+	show := !m.messageStats
+	if len(args) > 0 {
+		switch strings.ToLower(args[0]) {
+		case "on":
+			show = true
+		case "off":
+			show = false
+		default:
+			return m.showSystemMessage("Usage: /stats messages [on|off]")
+		}
+	}
+	m.setTextareaValue("")
+	return m.setMessageStats(show)
+}
+
+// setMessageStats shows or hides the timing and token line under each
+// assistant message. Blocks are cached per mode, so switching back is cheap.
+func (m *Model) setMessageStats(show bool) (tea.Model, tea.Cmd) {
+	m.messageStats = show
+	if m.chatRenderer != nil {
+		m.chatRenderer.SetShowStats(show)
+	}
+	m.forceHistoryRerenderPreservingBlockCache()
+	if !show {
+		return m.showFooterSuccess("Message stats hidden")
+	}
+	if !m.altScreen {
+		return m.showFooterSuccess("Message stats shown for messages printed from now on")
+	}
+	return m.showFooterSuccess("Message stats shown")
+}
+
 func (m *Model) renderStatsModal() string {
 	limit := 0
 	if m.engine != nil {
@@ -232,7 +295,7 @@ func (m *Model) recordGuardianUsage(ctx context.Context, model string, u llm.Usa
 	m.stats.AddGuardianUsageForModel(model, u.InputTokens, u.OutputTokens, u.CachedInputTokens, u.CacheWriteTokens)
 	sessionID := sessionIDOf(m.sess)
 	if m.store != nil && sessionID != "" {
-		_ = m.store.UpdateMetrics(ctx, sessionID, 0, 0, u.InputTokens, u.OutputTokens, u.CachedInputTokens, u.CacheWriteTokens)
+		_ = m.store.UpdateModelMetrics(ctx, sessionID, session.UsageModel{Model: model}, 0, 0, u.InputTokens, u.OutputTokens, u.CachedInputTokens, u.CacheWriteTokens)
 	}
 	if m.sess != nil {
 		m.sess.InputTokens += u.InputTokens
@@ -248,7 +311,7 @@ func (m *Model) recordCompactionUsage(ctx context.Context, sessionID, model stri
 	}
 	m.stats.AddCompactionUsageForModel(model, u.InputTokens, u.OutputTokens, u.CachedInputTokens, u.CacheWriteTokens)
 	if !u.BillableCountersZero() && m.store != nil && sessionID != "" {
-		_ = m.store.UpdateMetrics(ctx, sessionID, 0, 0, u.InputTokens, u.OutputTokens, u.CachedInputTokens, u.CacheWriteTokens)
+		_ = m.store.UpdateModelMetrics(ctx, sessionID, session.UsageModel{Model: model}, 0, 0, u.InputTokens, u.OutputTokens, u.CachedInputTokens, u.CacheWriteTokens)
 	}
 	if !u.BillableCountersZero() && m.sess != nil && (sessionID == "" || m.sess.ID == sessionID) {
 		m.sess.InputTokens += u.InputTokens
@@ -284,6 +347,29 @@ func formatCompactionUsage(stats *ui.SessionStats) string {
 	return strings.Join(parts, ", ")
 }
 
+// statusCostCache holds the status line's cost label, priced again only when
+// the session makes another provider call.
+type statusCostCache struct {
+	stats *ui.SessionStats
+	calls int
+	label string
+}
+
+// statusLineCost returns the running estimated cost of this session's
+// provider calls, or "" when any of them cannot be priced.
+func (m *Model) statusLineCost() string {
+	if m.stats == nil || m.stats.LLMCallCount == 0 {
+		return ""
+	}
+	if m.costCache.stats != m.stats || m.costCache.calls != m.stats.LLMCallCount {
+		m.costCache = statusCostCache{stats: m.stats, calls: m.stats.LLMCallCount}
+		if cost, err := statsCostEstimator(m.statsPricingModel(), m.stats); err == nil {
+			m.costCache.label = fmt.Sprintf("$%.2f", cost)
+		}
+	}
+	return m.costCache.label
+}
+
 func estimateStatsCost(model string, stats *ui.SessionStats) (float64, error) {
 	return ui.EstimateSessionStatsCost(stats, model)
 }
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/samsaffron/term-llm/internal/llm"
 	"github.com/samsaffron/term-llm/internal/session"
 	"github.com/samsaffron/term-llm/internal/ui"
 )
@@ -78,3 +79,32 @@ func TestExitStatsSummaryUsesBalancedOutputAndOmitsUnavailableCost(t *testing.T)
 		t.Fatalf("unavailable cost should be omitted: %s", out)
 	}
 }
+
+func TestSummaryCommandShowsSessionToolsAndFiles(t *testing.T) {
+	oldEstimator := statsCostEstimator
+	statsCostEstimator = func(string, *ui.SessionStats) (float64, error) { return 0.5, nil }
+	t.Cleanup(func() { statsCostEstimator = oldEstimator })
+
+	m := newTestChatModel(false)
+	m.stats = ui.NewSessionStats()
+	m.stats.AddUsage(2000, 100, 0, 0)
+	for i, msg := range []llm.Message{
+		llm.UserText("write notes"),
+		{Role: llm.RoleAssistant, Parts: []llm.Part{{Type: llm.PartToolCall, ToolCall: &llm.ToolCall{ID: "c1", Name: "write_file"}}}},
+		{Role: llm.RoleTool, Parts: []llm.Part{{Type: llm.PartToolResult, ToolResult: &llm.ToolResult{
+			ID: "c1", Name: "write_file", Diffs: []llm.DiffData{{File: "notes.md", New: "a\nb\nc\n", Operation: llm.DiffOperationCreate}},
+		}}}},
+		llm.AssistantText("done"),
+	} {
+		m.messages = append(m.messages, *session.NewMessage("s", msg, i))
+	}
+
+	result, _ := m.ExecuteCommand("/summary")
+	m = result.(*Model)
+	content := m.dialog.Content()
+	for _, want := range []string{"Turns    2", "2.0K in → 100 out ($0.5000)", "write_file ×1", "created  notes.md +3 -0"} {
+		if !strings.Contains(content, want) {
+			t.Fatalf("/summary missing %q:\n%s", want, content)
+		}
+	}
+}
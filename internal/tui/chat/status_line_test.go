@@ -1,6 +1,7 @@
 package chat
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
@@ -25,6 +26,65 @@ func TestRenderStatusLineShowsConfiguredContextWindow(t *testing.T) {
 	}
 }
 
+func TestRenderStatusLineFlagsContextPastCompactionThreshold(t *testing.T) {
+	m := newTestChatModel(false)
+	m.width = 160
+	m.engine.SetCompaction(100_000, llm.CompactionConfig{ThresholdRatio: 0.8})
+
+	m.engine.SetContextEstimateBaseline(30_000, 0)
+	line := ui.StripANSI(m.renderStatusLine())
+	if !strings.Contains(line, "~30K/100K (30%)") {
+		t.Fatalf("status line %q does not show context percentage", line)
+	}
+	if strings.Contains(line, "/compact") {
+		t.Fatalf("status line %q hints /compact below threshold", line)
+	}
+
+	m.engine.SetContextEstimateBaseline(85_000, 0)
+	line = ui.StripANSI(m.renderStatusLine())
+	if !strings.Contains(line, "~85K/100K (85%) · /compact") {
+		t.Fatalf("status line %q does not hint /compact past threshold", line)
+	}
+}
+
+func TestRenderStatusLineShowsRunningCostWhenPriced(t *testing.T) {
+	oldEstimator := statsCostEstimator
+	defer func() { statsCostEstimator = oldEstimator }()
+	priced := true
+	estimates := 0
+	statsCostEstimator = func(model string, stats *ui.SessionStats) (float64, error) {
+		estimates++
+		if !priced {
+			return 0, fmt.Errorf("pricing not found")
+		}
+		return 0.4213, nil
+	}
+
+	m := newTestChatModel(false)
+	m.width = 160
+	m.engine.SetCompaction(100_000, llm.CompactionConfig{ThresholdRatio: 0.8})
+	m.stats = ui.NewSessionStats()
+	if line := ui.StripANSI(m.renderStatusLine()); strings.Contains(line, "$") {
+		t.Fatalf("status line %q shows a cost before any usage", line)
+	}
+
+	m.stats.AddUsage(1000, 200, 0, 0)
+	line := ui.StripANSI(m.renderStatusLine())
+	if !strings.Contains(line, "$0.42") {
+		t.Fatalf("status line %q does not show the session cost", line)
+	}
+	_ = m.renderStatusLine()
+	if estimates != 1 {
+		t.Fatalf("cost estimated %d times, want once per provider call", estimates)
+	}
+
+	priced = false
+	m.stats.AddUsage(10, 20, 0, 0)
+	if line := ui.StripANSI(m.renderStatusLine()); strings.Contains(line, "$") {
+		t.Fatalf("status line %q shows a cost without pricing", line)
+	}
+}
+
 func TestRenderStatusLineShowsExactlyOneApprovalMode(t *testing.T) {
 	m := newTestChatModel(false)
 	m.width = 120
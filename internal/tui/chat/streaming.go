@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	tea "charm.land/bubbletea/v2"
@@ -120,15 +121,31 @@ func (m *Model) streamPersistenceCallbacks(streamStart time.Time) (llm.Assistant
 		streamSessionID = streamSess.ID
 	}
 	reasoningCfg := m.effectiveReasoningConfig()
+	// Usage is attributed to the model this stream runs on, even if /model
+	// moves the session to another one before the turn finishes.
+	streamProvider, streamModel := m.currentProviderAndModel()
 	staleStreamSession := func() bool {
 		return streamSessionID != "" && (m.sess == nil || m.sess.ID != streamSessionID)
 	}
-	persistPendingAssistant := func(ctx context.Context, assistantMsg llm.Message, finalizeText bool) {
+	// rowStart is when the current assistant row's turn began: the stream
+	// start for the first turn, then the end of the turn before it.
+	var rowStart atomic.Int64
+	rowStart.Store(streamStart.UnixNano())
+	// newAssistantRow stamps the timing, usage and model shown by /stats.
+	// Snapshots taken before the response completes carry no usage yet.
+	newAssistantRow := func(assistantMsg llm.Message, metrics llm.TurnMetrics) *session.Message {
+		sessionMsg := session.NewMessageWithReasoningPolicy(streamSess.ID, assistantMsg, -1, reasoningCfg)
+		sessionMsg.DurationMs = time.Since(time.Unix(0, rowStart.Load())).Milliseconds()
+		sessionMsg.InputTokens = metrics.InputTokens + metrics.CachedInputTokens + metrics.CacheWriteTokens
+		sessionMsg.OutputTokens = metrics.OutputTokens
+		sessionMsg.Model = streamModel
+		return sessionMsg
+	}
+	persistPendingAssistant := func(ctx context.Context, assistantMsg llm.Message, finalizeText bool, metrics llm.TurnMetrics) {
 		if m.store == nil || streamSess == nil || staleStreamSession() {
 			return
 		}
-		sessionMsg := session.NewMessageWithReasoningPolicy(streamSess.ID, assistantMsg, -1, reasoningCfg)
-		sessionMsg.DurationMs = time.Since(streamStart).Milliseconds()
+		sessionMsg := newAssistantRow(assistantMsg, metrics)
 		m.pendingMu.Lock()
 		m.pendingAssistantSnapshot = assistantMsg
 		m.pendingAssistantSnapshotSet = true
@@ -149,8 +166,7 @@ func (m *Model) streamPersistenceCallbacks(streamStart time.Time) (llm.Assistant
 			m.pendingAssistantTextSet = false
 			m.pendingAssistantSnapshot = assistantMsg
 			m.pendingAssistantSnapshotSet = true
-			sessionMsg = session.NewMessageWithReasoningPolicy(streamSess.ID, assistantMsg, -1, reasoningCfg)
-			sessionMsg.DurationMs = time.Since(streamStart).Milliseconds()
+			sessionMsg = newAssistantRow(assistantMsg, metrics)
 		}
 		if err := m.store.AddMessage(ctx, streamSess.ID, sessionMsg); err != nil {
 			return
@@ -164,15 +180,15 @@ func (m *Model) streamPersistenceCallbacks(streamStart time.Time) (llm.Assistant
 			return nil
 		}
 		m.updateStreamingContextAssistant(assistantMsg)
-		persistPendingAssistant(ctx, assistantMsg, false)
+		persistPendingAssistant(ctx, assistantMsg, false, llm.TurnMetrics{})
 		return nil
 	}
-	responseCompleted := func(ctx context.Context, _ int, assistantMsg llm.Message, _ llm.TurnMetrics) error {
+	responseCompleted := func(ctx context.Context, _ int, assistantMsg llm.Message, metrics llm.TurnMetrics) error {
 		if staleStreamSession() {
 			return nil
 		}
 		m.updateStreamingContextAssistant(assistantMsg)
-		persistPendingAssistant(ctx, assistantMsg, true)
+		persistPendingAssistant(ctx, assistantMsg, true, metrics)
 		return nil
 	}
 	turnCompleted := func(ctx context.Context, _ int, turnMessages []llm.Message, metrics llm.TurnMetrics) error {
@@ -186,7 +202,7 @@ func (m *Model) streamPersistenceCallbacks(streamStart time.Time) (llm.Assistant
 			m.pendingMu.Lock()
 			finalizeText := !m.pendingAssistantTextSet
 			m.pendingMu.Unlock()
-			persistPendingAssistant(ctx, turnMessages[0], finalizeText)
+			persistPendingAssistant(ctx, turnMessages[0], finalizeText, metrics)
 			appendStart = 1
 		}
 		if m.store != nil && streamSess != nil {
@@ -207,8 +223,9 @@ func (m *Model) streamPersistenceCallbacks(streamStart time.Time) (llm.Assistant
 			m.completedAssistantTurns++
 		}
 		m.pendingMu.Unlock()
+		rowStart.Store(time.Now().UnixNano())
 		if m.store != nil && streamSess != nil {
-			_ = m.store.UpdateMetrics(ctx, streamSess.ID, 1, metrics.ToolCalls, metrics.InputTokens, metrics.OutputTokens, metrics.CachedInputTokens, metrics.CacheWriteTokens)
+			_ = m.store.UpdateModelMetrics(ctx, streamSess.ID, session.UsageModel{Provider: streamProvider, Model: streamModel}, 1, metrics.ToolCalls, metrics.InputTokens, metrics.OutputTokens, metrics.CachedInputTokens, metrics.CacheWriteTokens)
 			m.persistContextEstimate(ctx)
 		}
 		return nil
@@ -491,7 +508,13 @@ func (m *Model) sendMessage(content string) (tea.Model, tea.Cmd) {
 	m.selectedImage = -1
 	m.pasteChunks = nil
 
-	// Start streaming
+	return m.beginAssistantTurn(fullContent, tea.Println(userDisplay.String()), preSendCmds)
+}
+
+// beginAssistantTurn resets the per-turn streaming state and starts a response
+// to the conversation as it currently stands. In inline mode scrollbackCmd, if
+// set, prints to scrollback before the stream; alt screen renders history.
+func (m *Model) beginAssistantTurn(content string, scrollbackCmd tea.Cmd, preSendCmds []tea.Cmd) (tea.Model, tea.Cmd) {
 	m.streaming = true
 	// The previous turn's tracker is kept alive after stream-done so its
 	// reasoning headers stay click-toggleable; clear it now that a fresh
@@ -501,6 +524,7 @@ func (m *Model) sendMessage(content string) (tea.Model, tea.Cmd) {
 	m.streamStartTime = time.Now()
 	if m.stats != nil {
 		m.stats.SetModel(m.statsPricingModel())
+		m.stats.SetProvider(m.providerKey)
 		m.stats.RequestStart()
 	}
 	if m.altScreen {
@@ -535,33 +559,52 @@ func (m *Model) sendMessage(content string) (tea.Model, tea.Cmd) {
 	// Start the stream
 	// In alt screen mode, View() renders history including user message
 	// In inline mode, print user message to scrollback first
-	if m.altScreen {
-		cmds := []tea.Cmd{
-			m.startStream(fullContent),
-			m.spinner.Tick,
-			m.tickEvery(),
-		}
-		cmds = append(preSendCmds, cmds...)
-		m.appendTerminalTitleCmd(&cmds)
-		return m, tea.Batch(cmds...)
+	cmds := append([]tea.Cmd(nil), preSendCmds...)
+	if !m.altScreen && scrollbackCmd != nil {
+		cmds = append(cmds, scrollbackCmd)
 	}
-	cmds := []tea.Cmd{
-		tea.Println(userDisplay.String()),
-		m.startStream(fullContent),
+	cmds = append(cmds,
+		m.startStream(content),
 		m.spinner.Tick,
 		m.tickEvery(),
-	}
-	cmds = append(preSendCmds, cmds...)
+	)
 	m.appendTerminalTitleCmd(&cmds)
 	return m, tea.Batch(cmds...)
 }
 
+// registerMCPTools registers every MCP tool with the engine and returns the
+// specs to send. A tool whose schema the engine rejects is left out of the
+// request and named in a footer warning.
+func (m *Model) registerMCPTools() []llm.ToolSpec {
+	if m.mcpManager == nil {
+		return nil
+	}
+	var specs []llm.ToolSpec
+	var rejected []string
+	for _, t := range m.mcpManager.AllTools() {
+		if err := m.engine.RegisterTool(mcp.NewMCPTool(m.mcpManager, t)); err != nil {
+			rejected = append(rejected, t.Name)
+			continue
+		}
+		specs = append(specs, llm.ToolSpec{
+			Name:        t.Name,
+			Description: t.Description,
+			Schema:      t.Schema,
+		})
+	}
+	if len(rejected) > 0 {
+		m.SetFooterWarning(fmt.Sprintf("MCP tools skipped (invalid input schema): %s", strings.Join(rejected, ", ")))
+	}
+	return specs
+}
+
 func (m *Model) startStream(content string) tea.Cmd {
 	ctx, cancel := context.WithCancel(m.rootContext())
 	m.streamGeneration++
 	streamGeneration := m.streamGeneration
 	m.streamCancelFunc = cancel
 	m.setStreamCancelRequested(false)
+	mcpSpecs := m.registerMCPTools()
 
 	return func() tea.Msg {
 		// Mark session as active when starting a new stream
@@ -578,20 +621,7 @@ func (m *Model) startStream(content string) tea.Cmd {
 		messages := m.buildMessagesForStream()
 		m.setStreamingContextMessages(messages)
 
-		// Collect MCP tools if available and register them with the engine
-		var reqTools []llm.ToolSpec
-		if m.mcpManager != nil {
-			mcpTools := m.mcpManager.AllTools()
-			for _, t := range mcpTools {
-				reqTools = append(reqTools, llm.ToolSpec{
-					Name:        t.Name,
-					Description: t.Description,
-					Schema:      t.Schema,
-				})
-				// Register MCP tool with engine for execution
-				m.engine.RegisterTool(mcp.NewMCPTool(m.mcpManager, t))
-			}
-		}
+		reqTools := append([]llm.ToolSpec(nil), mcpSpecs...)
 
 		// Add local tools (read_file, write_file, shell, etc.) if enabled
 		// These are already registered in the engine, we just need their specs
@@ -517,8 +517,11 @@ func TestListenForStreamEventsSync_CancelledClosedStreamUsesInterruptedCleanup(t
 
 	_, _ = m.Update(msg)
 
-	if len(m.messages) != 2 {
-		t.Fatalf("in-memory message count = %d, want 2", len(m.messages))
+	if len(m.messages) != 3 {
+		t.Fatalf("in-memory message count = %d, want partial answer and interrupted marker", len(m.messages))
+	}
+	if _, ok := llm.ParseInterruptedMarker(m.messages[2].ToLLMMessage()); !ok {
+		t.Fatalf("message[2] = %#v, want interrupted marker", m.messages[2])
 	}
 	if got := m.messages[1].TextContent; got != "partial answer" {
 		t.Fatalf("in-memory assistant text = %q, want %q", got, "partial answer")
@@ -564,8 +567,8 @@ func TestUpdate_StreamErrorSalvagesPartialAssistantReply(t *testing.T) {
 
 	_, _ = m.Update(streamEventMsg{event: ui.ErrorEvent(context.Canceled)})
 
-	if len(m.messages) != 2 {
-		t.Fatalf("in-memory message count = %d, want 2", len(m.messages))
+	if len(m.messages) != 3 {
+		t.Fatalf("in-memory message count = %d, want partial answer and interrupted marker", len(m.messages))
 	}
 	if got := m.messages[1].TextContent; got != "partial answer" {
 		t.Fatalf("in-memory assistant text = %q, want %q", got, "partial answer")
@@ -575,8 +578,8 @@ func TestUpdate_StreamErrorSalvagesPartialAssistantReply(t *testing.T) {
 	if err != nil {
 		t.Fatalf("GetMessages: %v", err)
 	}
-	if len(persisted) != 2 {
-		t.Fatalf("persisted message count = %d, want 2", len(persisted))
+	if len(persisted) != 3 {
+		t.Fatalf("persisted message count = %d, want 3", len(persisted))
 	}
 	if persisted[1].Role != llm.RoleAssistant || persisted[1].TextContent != "partial answer" {
 		t.Fatalf("persisted assistant = (%s, %q), want (%s, %q)", persisted[1].Role, persisted[1].TextContent, llm.RoleAssistant, "partial answer")
@@ -780,8 +783,8 @@ func TestUpdate_StreamErrorReloadsPersistedToolTurnBeforeNextPrompt(t *testing.T
 
 	_, _ = m.Update(streamEventMsg{event: ui.ErrorEvent(context.Canceled)})
 
-	if len(m.messages) != 4 {
-		t.Fatalf("in-memory message count after interrupt = %d, want all 4 persisted rows; messages=%#v", len(m.messages), m.messages)
+	if len(m.messages) != 5 {
+		t.Fatalf("in-memory message count after interrupt = %d, want all 4 persisted rows plus the interrupted marker; messages=%#v", len(m.messages), m.messages)
 	}
 	if got := m.messages[1].TextContent; got != "first turn before tool" {
 		t.Fatalf("message[1] text = %q, want completed assistant", got)
@@ -835,8 +838,8 @@ func TestUpdate_StreamErrorPreservesUnpersistedSalvageWhenStoreUpdateFails(t *te
 
 	_, _ = m.Update(streamEventMsg{event: ui.ErrorEvent(context.Canceled)})
 
-	if len(m.messages) != 4 {
-		t.Fatalf("in-memory message count after interrupt = %d, want 4; messages=%#v", len(m.messages), m.messages)
+	if len(m.messages) != 5 {
+		t.Fatalf("in-memory message count after interrupt = %d, want 4 plus the interrupted marker; messages=%#v", len(m.messages), m.messages)
 	}
 	if got := m.messages[3].TextContent; got != "second turn partial" {
 		t.Fatalf("interrupted assistant text = %q, want salvaged partial despite store update failure", got)
@@ -929,3 +932,85 @@ func TestStreamEventCoalescerDeliversMergedTextOnClose(t *testing.T) {
 		t.Fatal("expected closure on subsequent read")
 	}
 }
+
+func TestUpdate_StreamCancelSalvagesPartialAnswerAndDropsPendingToolCalls(t *testing.T) {
+	store, err := session.NewStore(session.Config{Enabled: true, Path: filepath.Join(t.TempDir(), "sessions.db")})
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	defer store.Close()
+
+	sess := &session.Session{ID: "stream-cancel-partial", CreatedAt: time.Now()}
+	if err := store.Create(context.Background(), sess); err != nil {
+		t.Fatalf("Create session: %v", err)
+	}
+	userMsg := session.NewMessage(sess.ID, llm.UserText("explain"), -1)
+	if err := store.AddMessage(context.Background(), sess.ID, userMsg); err != nil {
+		t.Fatalf("AddMessage(user): %v", err)
+	}
+
+	m := newTestChatModel(false)
+	m.store = store
+	m.sess = sess
+	m.messages = []session.Message{*userMsg}
+	m.pendingAssistantSnapshot = llm.Message{Role: llm.RoleAssistant, Parts: []llm.Part{
+		{Type: llm.PartText, Text: "The first half of the"},
+		{Type: llm.PartToolCall, ToolCall: &llm.ToolCall{ID: "call-1", Name: "read_file", Arguments: json.RawMessage(`{"path":"main.go"}`)}},
+	}}
+	m.pendingAssistantSnapshotSet = true
+	m.streaming = true
+	m.streamStartTime = time.Now()
+
+	_, _ = m.Update(streamEventMsg{event: ui.ErrorEvent(context.Canceled)})
+
+	persisted, err := store.GetMessages(context.Background(), sess.ID, 0, 0)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	if len(persisted) != 3 {
+		t.Fatalf("persisted message count = %d, want 3", len(persisted))
+	}
+	partial := persisted[1].ToLLMMessage()
+	if partial.Role != llm.RoleAssistant || len(partial.Parts) != 1 || partial.Parts[0].Text != "The first half of the" {
+		t.Fatalf("partial assistant = %+v, want text only", partial)
+	}
+	marker, ok := llm.ParseInterruptedMarker(persisted[2].ToLLMMessage())
+	if !ok || marker.DroppedToolCalls != 1 {
+		t.Fatalf("marker = %+v ok=%v, want interrupted marker with 1 dropped call", marker, ok)
+	}
+
+	history := llm.FilterConversationMessages(m.buildMessages())
+	if got := history[len(history)-1]; got.Role != llm.RoleAssistant || got.Parts[0].Text != "The first half of the" {
+		t.Fatalf("next request history ends with %+v, want the partial answer", got)
+	}
+	if !m.lastResponseInterrupted() {
+		t.Fatal("expected /continue to find the interrupted response")
+	}
+}
+
+func TestStreamPersistenceTimesEachAssistantRowFromItsOwnTurn(t *testing.T) {
+	store := &mockStore{}
+	m := newTestChatModel(false)
+	m.store = store
+	m.sess = &session.Session{ID: "sess-row-duration"}
+
+	_, responseCompleted, turnCompleted := m.streamPersistenceCallbacks(time.Now().Add(-10 * time.Second))
+	ctx := context.Background()
+	if err := turnCompleted(ctx, 0, []llm.Message{llm.AssistantText("first")}, llm.TurnMetrics{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := responseCompleted(ctx, 1, llm.AssistantText("second"), llm.TurnMetrics{}); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := store.messages[m.sess.ID]
+	if len(rows) != 2 {
+		t.Fatalf("rows = %d, want 2", len(rows))
+	}
+	if rows[0].DurationMs < 10_000 {
+		t.Fatalf("first row DurationMs = %d, want it timed from the stream start", rows[0].DurationMs)
+	}
+	if rows[1].DurationMs >= 10_000 {
+		t.Fatalf("second row DurationMs = %d, want it timed from the end of the first turn", rows[1].DurationMs)
+	}
+}
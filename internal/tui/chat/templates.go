@@ -0,0 +1,191 @@
+package chat
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/sahilm/fuzzy"
+)
+
+// expandedTemplate is a prompt template with its placeholders filled in.
+type expandedTemplate struct {
+	Text  string
+	Files []string // paths from {{file:path}} placeholders, in order of appearance
+}
+
+// expandPromptTemplate fills in a prompt template. {{input}} is replaced with
+// input; when the template has no {{input}}, a non-empty input is appended
+// after a blank line so nothing the user typed is lost. {{file:path}} is
+// replaced with the path and the path is returned for attachment. Unknown
+// placeholders are left as written, and a brace run such as "{{{input}}}"
+// keeps the extra braces around the expanded value.
+func expandPromptTemplate(tmpl, input string) (expandedTemplate, error) {
+	var out strings.Builder
+	var files []string
+	usedInput := false
+
+	rest := tmpl
+	for {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			out.WriteString(rest)
+			break
+		}
+		out.WriteString(rest[:start])
+		end := strings.Index(rest[start+2:], "}}")
+		if end < 0 {
+			out.WriteString(rest[start:])
+			break
+		}
+		inner := rest[start+2 : start+2+end]
+		if strings.Contains(inner, "{") {
+			// Another opening brace before the closing pair: the first brace
+			// is literal, so rescan from the next one.
+			out.WriteByte('{')
+			rest = rest[start+1:]
+			continue
+		}
+		placeholder := rest[start : start+2+end+2]
+		rest = rest[start+2+end+2:]
+
+		name := strings.TrimSpace(inner)
+		switch {
+		case name == "input":
+			out.WriteString(input)
+			usedInput = true
+		case strings.HasPrefix(name, "file:"):
+			path := strings.TrimSpace(strings.TrimPrefix(name, "file:"))
+			if path == "" {
+				return expandedTemplate{}, fmt.Errorf("empty path in %s", placeholder)
+			}
+			files = append(files, path)
+			out.WriteString(path)
+		default:
+			out.WriteString(placeholder)
+		}
+	}
+
+	text := out.String()
+	if !usedInput && strings.TrimSpace(input) != "" {
+		text = strings.TrimRight(text, " \t\n") + "\n\n" + input
+	}
+	return expandedTemplate{Text: strings.TrimSpace(text), Files: files}, nil
+}
+
+// promptTemplateNames returns the configured template names in sorted order.
+func (m *Model) promptTemplateNames() []string {
+	if m.config == nil {
+		return nil
+	}
+	names := make([]string, 0, len(m.config.Prompts))
+	for name := range m.config.Prompts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupPromptTemplate finds a template by name. Config keys are lowercased
+// when loaded, so the match ignores case.
+func (m *Model) lookupPromptTemplate(name string) (string, bool) {
+	if m.config == nil {
+		return "", false
+	}
+	for key, tmpl := range m.config.Prompts {
+		if strings.EqualFold(key, name) {
+			return tmpl, true
+		}
+	}
+	return "", false
+}
+
+// cmdTemplates lists the configured prompt templates.
+func (m *Model) cmdTemplates() (tea.Model, tea.Cmd) {
+	m.setTextareaValue("")
+	names := m.promptTemplateNames()
+	if len(names) == 0 {
+		return m.showSystemMessage("No prompt templates configured.\n\nAdd a `prompts:` map to config.yaml, then use `/t <name> [message]`.")
+	}
+	var b strings.Builder
+	b.WriteString("## Prompt Templates\n\n")
+	for _, name := range names {
+		preview := strings.Join(strings.Fields(m.config.Prompts[name]), " ")
+		b.WriteString(fmt.Sprintf("- `%s` — %s\n", name, pinPreview(preview)))
+	}
+	b.WriteString("\nUse `/t <name> [message]` to send one.")
+	return m.showSystemMessage(b.String())
+}
+
+// cmdTemplate expands a prompt template and sends it. Files named by
+// {{file:path}} placeholders are attached like /file attachments, so each
+// must be in an approved directory; an unapproved one opens the approval
+// dialog and the command runs again once it is approved.
+func (m *Model) cmdTemplate(rawArgs string) (tea.Model, tea.Cmd) {
+	name, input, _ := strings.Cut(strings.TrimSpace(rawArgs), " ")
+	if name == "" {
+		return m.cmdTemplates()
+	}
+	if m.streaming {
+		return m.showFooterWarning("Wait for the response to finish before using /t.")
+	}
+
+	tmpl, ok := m.lookupPromptTemplate(name)
+	if !ok {
+		names := m.promptTemplateNames()
+		if len(names) == 0 {
+			return m.showFooterError(fmt.Sprintf("Unknown template %q: no prompt templates configured.", name))
+		}
+		var suggestions []string
+		for _, match := range fuzzy.Find(name, names) {
+			suggestions = append(suggestions, match.Str)
+			if len(suggestions) == 3 {
+				break
+			}
+		}
+		if len(suggestions) == 0 {
+			return m.showFooterError(fmt.Sprintf("Unknown template %q. Available: %s", name, strings.Join(names, ", ")))
+		}
+		return m.showFooterError(fmt.Sprintf("Unknown template %q. Did you mean: %s?", name, strings.Join(suggestions, ", ")))
+	}
+
+	expanded, err := expandPromptTemplate(tmpl, strings.TrimSpace(input))
+	if err != nil {
+		return m.showSystemMessage(fmt.Sprintf("Template %s: %v", name, err))
+	}
+
+	paths := make([]string, 0, len(expanded.Files))
+	for _, file := range expanded.Files {
+		path, err := ExpandUserPath(file)
+		if err != nil {
+			return m.showSystemMessage(fmt.Sprintf("Failed to expand path: %v", err))
+		}
+		if !m.approvedDirs.IsPathApproved(path) {
+			m.pendingFilePath = path
+			m.pendingTemplateCommand = "/t " + strings.TrimSpace(rawArgs)
+			m.dialog.ShowDirApproval(path, GetParentOptions(path))
+			return m, nil
+		}
+		paths = append(paths, path)
+	}
+	for _, path := range paths {
+		attachment, err := AttachFile(path)
+		if err != nil {
+			return m.showSystemMessage(fmt.Sprintf("Failed to attach file: %v", err))
+		}
+		if !m.hasAttachedFile(attachment.Path) {
+			m.files = append(m.files, *attachment)
+		}
+	}
+	return m.sendMessage(expanded.Text)
+}
+
+func (m *Model) hasAttachedFile(path string) bool {
+	for _, f := range m.files {
+		if f.Path == path {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package chat
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandPromptTemplate(t *testing.T) {
+	tests := []struct {
+		name      string
+		tmpl      string
+		input     string
+		want      string
+		wantFiles []string
+		wantErr   bool
+	}{
+		{name: "input", tmpl: "Review this:\n{{input}}", input: "func main() {}", want: "Review this:\nfunc main() {}"},
+		{name: "spaced placeholder", tmpl: "Summarize {{ input }} briefly", input: "the log", want: "Summarize the log briefly"},
+		{name: "repeated input", tmpl: "{{input}} / {{input}}", input: "x", want: "x / x"},
+		{name: "missing input placeholder appends", tmpl: "Review the diff.", input: "focus on errors", want: "Review the diff.\n\nfocus on errors"},
+		{name: "missing input placeholder and no input", tmpl: "Review the diff.", want: "Review the diff."},
+		{name: "empty input", tmpl: "Explain {{input}}", want: "Explain"},
+		{name: "file", tmpl: "Check {{file:./main.go}} and {{file: ~/notes.md }}", want: "Check ./main.go and ~/notes.md", wantFiles: []string{"./main.go", "~/notes.md"}},
+		{name: "unknown placeholder kept", tmpl: "Hi {{name}}: {{input}}", input: "x", want: "Hi {{name}}: x"},
+		{name: "triple braces", tmpl: "{{{input}}}", input: "x", want: "{x}"},
+		{name: "nested placeholder", tmpl: "{{ {{input}} }}", input: "x", want: "{{ x }}"},
+		{name: "go template braces", tmpl: "Use {{.Name}} in {{input}}", input: "a.tmpl", want: "Use {{.Name}} in a.tmpl"},
+		{name: "unterminated", tmpl: "Oops {{input", input: "x", want: "Oops {{input\n\nx"},
+		{name: "input containing braces is not rescanned", tmpl: "{{input}}", input: "{{file:/etc/passwd}}", want: "{{file:/etc/passwd}}"},
+		{name: "empty file path", tmpl: "{{file:}}", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandPromptTemplate(tt.tmpl, tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expandPromptTemplate(%q) succeeded, want error", tt.tmpl)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expandPromptTemplate(%q): %v", tt.tmpl, err)
+			}
+			if got.Text != tt.want {
+				t.Errorf("text = %q, want %q", got.Text, tt.want)
+			}
+			if !reflect.DeepEqual(got.Files, tt.wantFiles) {
+				t.Errorf("files = %q, want %q", got.Files, tt.wantFiles)
+			}
+		})
+	}
+}
+
+func TestCmdTemplateSendsExpandedPromptWithFiles(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "style.md")
+	if err := os.WriteFile(path, []byte("no tabs"), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	m := newTestChatModel(false)
+	m.config.Prompts = map[string]string{
+		"review": "Review against {{file:" + path + "}}:\n{{input}}",
+	}
+	m.approvedDirs = &ApprovedDirs{}
+
+	result, _ := m.ExecuteCommand("/t review  the new parser")
+	m = result.(*Model)
+	if m.dialog.Type() != DialogDirApproval || m.pendingFilePath != path {
+		t.Fatalf("expected directory approval for %s, dialog=%v pending=%q", path, m.dialog.Type(), m.pendingFilePath)
+	}
+	if m.streaming {
+		t.Fatal("template should not be sent before its file is approved")
+	}
+
+	if err := m.approvedDirs.AddDirectory(dir); err != nil {
+		t.Fatalf("AddDirectory: %v", err)
+	}
+	m.dialog.Close()
+	result, _ = m.ExecuteCommand(m.pendingTemplateCommand)
+	m = result.(*Model)
+	if !m.streaming {
+		t.Fatalf("template was not sent; footer=%q", m.footerMessage)
+	}
+	last := m.messages[len(m.messages)-1]
+	if !strings.Contains(last.TextContent, "Review against "+path+":\nthe new parser") {
+		t.Fatalf("sent %q, want the expanded template", last.TextContent)
+	}
+	if !strings.Contains(last.TextContent, "no tabs") {
+		t.Fatalf("sent %q, want the file contents attached", last.TextContent)
+	}
+}
+
+func TestCmdTemplateSuggestsCloseNames(t *testing.T) {
+	m := newTestChatModel(false)
+	m.config.Prompts = map[string]string{"review": "r", "summarize": "s"}
+
+	result, _ := m.ExecuteCommand("/t revew")
+	m = result.(*Model)
+	if !strings.Contains(m.footerMessage, "Did you mean: review?") {
+		t.Fatalf("footer = %q, want a suggestion for review", m.footerMessage)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"unicode/utf8"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/samsaffron/term-llm/internal/llm"
 	"github.com/samsaffron/term-llm/internal/session"
 	"github.com/samsaffron/term-llm/internal/sessiontitle"
 )
@@ -579,8 +580,23 @@ func (m *Model) resetTitleGenerationStateForSession() {
 	m.titleGenerationInFlight = false
 }
 
+// SetAutoTitle turns background session titling on or off. /autotitle
+// still works when it is off.
+func (m *Model) SetAutoTitle(enabled bool) {
+	m.autoTitleOff = !enabled
+}
+
+// titleProvider returns the provider used to title sessions: the fast
+// provider when one is configured, otherwise the chat provider.
+func (m *Model) titleProvider() llm.Provider {
+	if m.fastProvider != nil {
+		return m.fastProvider
+	}
+	return m.provider
+}
+
 func (m *Model) scheduleTitleFallbackCmd() tea.Cmd {
-	if m == nil || m.sess == nil || m.fastProvider == nil || m.store == nil {
+	if m == nil || m.autoTitleOff || m.sess == nil || m.titleProvider() == nil || m.store == nil {
 		return nil
 	}
 	if strings.TrimSpace(m.sess.GeneratedShortTitle) != "" {
@@ -596,11 +612,14 @@ func (m *Model) scheduleTitleFallbackCmd() tea.Cmd {
 }
 
 func (m *Model) maybeGenerateSessionTitleCmd() tea.Cmd {
+	if m == nil || m.autoTitleOff {
+		return nil
+	}
 	return m.generateSessionTitleCmd(false, false, 0)
 }
 
 func (m *Model) generateSessionTitleCmd(force bool, clearManualName bool, manualEditVersion uint64) tea.Cmd {
-	if m == nil || m.sess == nil || m.fastProvider == nil || m.store == nil {
+	if m == nil || m.sess == nil || m.titleProvider() == nil || m.store == nil {
 		return nil
 	}
 	sessionID := strings.TrimSpace(m.sess.ID)
@@ -630,7 +649,7 @@ func (m *Model) generateSessionTitleCmd(force bool, clearManualName bool, manual
 		return nil
 	}
 
-	provider := m.fastProvider
+	provider := m.titleProvider()
 	store := m.store
 	sessCopy := *m.sess
 	rootCtx := m.rootContext()
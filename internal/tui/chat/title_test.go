@@ -546,3 +546,48 @@ func TestTitleGeneratedMsgIgnoresSwitchedSession(t *testing.T) {
 		t.Fatalf("switched session title = %q, want unchanged", got)
 	}
 }
+
+func TestMaybeGenerateSessionTitleFallsBackToChatProvider(t *testing.T) {
+	m := newTestChatModel(false)
+	store := &mockStore{}
+	m.store = store
+	m.sess = &session.Session{ID: "chat-provider-title", Provider: "mock", Model: "mock-model", Mode: session.ModeChat}
+	m.fastProvider = nil
+	m.provider = llm.NewMockProvider("chat").AddTextResponse(`{"short_title":"Tune Postgres Vacuum","long_title":"Tuning autovacuum settings for a busy Postgres table","confidence":0.9}`)
+	m.messages = []session.Message{
+		{SessionID: m.sess.ID, Role: llm.RoleUser, TextContent: "Autovacuum never keeps up on the events table.", Sequence: 0},
+		{SessionID: m.sess.ID, Role: llm.RoleAssistant, TextContent: "Lower the scale factor for that table and raise the cost limit.", Sequence: 1},
+	}
+
+	cmd := m.maybeGenerateSessionTitleCmd()
+	if cmd == nil {
+		t.Fatal("expected title generation with the chat provider")
+	}
+	updated, _ := m.Update(cmd())
+	m = updated.(*Model)
+	if store.updated == nil || store.updated.GeneratedShortTitle != "Tune Postgres Vacuum" {
+		t.Fatalf("stored title = %+v", store.updated)
+	}
+	if got := m.sess.GeneratedShortTitle; got != "Tune Postgres Vacuum" {
+		t.Fatalf("model GeneratedShortTitle = %q", got)
+	}
+}
+
+func TestAutoTitleOffSkipsBackgroundTitles(t *testing.T) {
+	m := newTestChatModel(false)
+	m.store = &mockStore{}
+	m.sess = &session.Session{ID: "auto-title-off", Provider: "mock", Model: "mock-model", Mode: session.ModeChat}
+	m.fastProvider = llm.NewMockProvider("fast").AddTextResponse(`{"short_title":"Should Not Run","long_title":"Should not run when auto titles are off","confidence":0.9}`)
+	m.messages = []session.Message{{SessionID: m.sess.ID, Role: llm.RoleUser, TextContent: "hi", Sequence: 0}}
+	m.SetAutoTitle(false)
+
+	if cmd := m.maybeGenerateSessionTitleCmd(); cmd != nil {
+		t.Fatal("did not expect a background title with auto titles off")
+	}
+	if cmd := m.scheduleTitleFallbackCmd(); cmd != nil {
+		t.Fatal("did not expect a fallback tick with auto titles off")
+	}
+	if cmd := m.generateSessionTitleCmd(true, false, m.titleManualEditVersion); cmd == nil {
+		t.Fatal("expected /autotitle generation to still work")
+	}
+}
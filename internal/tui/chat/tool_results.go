@@ -0,0 +1,27 @@
+package chat
+
+import "math"
+
+// expandVisibleToolResult shows the lowest collapsed tool result on screen in
+// full and reports whether there was one. Tool output is only rendered with
+// tools expanded, and inline only while scrolled back through history.
+func (m *Model) expandVisibleToolResult() bool {
+	if m.chatRenderer == nil || !m.toolsExpanded {
+		return false
+	}
+	first, last := 0, math.MaxInt
+	if m.altScreen {
+		// History is the top of the viewport content.
+		first = m.viewport.YOffset()
+		last = first + m.viewport.Height() - 1
+	} else if m.scrollOffset == 0 {
+		return false
+	}
+	messageID, ok := m.chatRenderer.LastCollapsedToolResult(first, last)
+	if !ok {
+		return false
+	}
+	m.chatRenderer.ExpandToolResults(messageID)
+	m.forceHistoryRerenderPreservingBlockCache()
+	return true
+}
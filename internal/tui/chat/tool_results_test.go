@@ -0,0 +1,63 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/session"
+	"github.com/samsaffron/term-llm/internal/ui"
+)
+
+func TestAltOExpandsCollapsedToolResultOnScreen(t *testing.T) {
+	m := newTestChatModel(true)
+	m.applyWindowSize(tea.WindowSizeMsg{Width: 80, Height: 40})
+
+	lines := make([]string, 100)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("build line %d", i+1)
+	}
+	m.messages = []session.Message{
+		{ID: 1, Role: llm.RoleUser, TextContent: "run the build", Parts: []llm.Part{{Type: llm.PartText, Text: "run the build"}}},
+		{ID: 2, Role: llm.RoleAssistant, Parts: []llm.Part{{
+			Type:     llm.PartToolCall,
+			ToolCall: &llm.ToolCall{ID: "call-1", Name: "shell", Arguments: []byte(`{"command":"make"}`)},
+		}}},
+		{ID: 3, Role: llm.RoleTool, Parts: []llm.Part{{
+			Type:       llm.PartToolResult,
+			ToolResult: &llm.ToolResult{ID: "call-1", Name: "shell", Content: strings.Join(lines, "\n")},
+		}}},
+	}
+
+	_, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: 'o', Text: "o"})
+	if m.textarea.Value() != "o" {
+		t.Fatalf("with tools collapsed o should type, got %q", m.textarea.Value())
+	}
+	m.setTextareaValue("")
+
+	_, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: 'e', Mod: tea.ModCtrl})
+	if !m.toolsExpanded {
+		t.Fatal("Ctrl+E should expand tools")
+	}
+	view := ui.StripANSI(m.View().Content)
+	if !strings.Contains(view, "(+92 lines, press alt+o to expand)") || strings.Contains(view, "build line 50\n") {
+		t.Fatalf("expected the long result to be collapsed:\n%s", view)
+	}
+
+	_, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: 'o', Text: "o"})
+	if m.textarea.Value() != "o" {
+		t.Fatalf("o should type even with a collapsed result on screen, got %q", m.textarea.Value())
+	}
+
+	_, _ = m.handleKeyMsg(tea.KeyPressMsg{Code: 'o', Mod: tea.ModAlt})
+	if m.textarea.Value() != "o" {
+		t.Fatalf("alt+o should expand the result instead of typing, got %q", m.textarea.Value())
+	}
+	_ = m.View()
+	content := ui.StripANSI(m.viewCache.lastContentStr)
+	if strings.Contains(content, "press alt+o to expand") || !strings.Contains(content, "build line 50") {
+		t.Fatalf("expected the result in full:\n%s", content)
+	}
+}
@@ -0,0 +1,96 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/session"
+)
+
+// cmdUndo removes the last exchange (the latest user message and everything
+// after it, including tool calls and results) from the conversation and the
+// session store, so the model never sees it again. Repeated calls walk back
+// further exchanges, stopping at the compaction boundary.
+func (m *Model) cmdUndo(args []string) (tea.Model, tea.Cmd) {
+	m.setTextareaValue("")
+	if len(args) > 0 {
+		return m.showSystemMessage("Usage: /undo")
+	}
+	if m.streaming {
+		return m.showFooterWarning("Wait for the response to finish before using /undo.")
+	}
+	if m.worktreeOperationBusy() {
+		return m.showFooterWarning("Wait for the current worktree operation to finish before using /undo.")
+	}
+	if m.sess == nil {
+		return m.showFooterWarning("Nothing to undo.")
+	}
+
+	m.messagesMu.Lock()
+	idx, ok := m.lastUserMessageIndex()
+	if !ok {
+		m.messagesMu.Unlock()
+		return m.showFooterWarning("Nothing to undo.")
+	}
+	removed := append([]session.Message(nil), m.messages[idx:]...)
+	userMsg := m.messages[idx]
+	m.messagesMu.Unlock()
+
+	seq, err := m.storedSequence(userMsg)
+	if err == nil {
+		err = m.truncateHistory(idx, seq)
+	}
+	if err != nil {
+		return m.showFooterError(fmt.Sprintf("Undo failed: %v", err))
+	}
+	if m.sess.UserTurns > 0 {
+		m.sess.UserTurns--
+	}
+
+	summary := undoSummary(removed)
+	if m.altScreen {
+		return m.showFooterMuted(summary)
+	}
+	theme := m.styles.Theme()
+	m.clearFooterMessage()
+	return m, tea.Println(lipgloss.NewStyle().Foreground(theme.Muted).Render(summary))
+}
+
+// undoSummary describes an undone exchange: the user message, the last reply
+// text and how many tool calls went with it.
+func undoSummary(removed []session.Message) string {
+	var user, reply string
+	toolCalls := 0
+	for _, msg := range removed {
+		switch msg.Role {
+		case llm.RoleUser:
+			if user == "" {
+				user = msg.TextContent
+			}
+		case llm.RoleAssistant:
+			for _, part := range msg.Parts {
+				if part.Type == llm.PartToolCall {
+					toolCalls++
+				}
+			}
+			if strings.TrimSpace(msg.TextContent) != "" {
+				reply = msg.TextContent
+			}
+		}
+	}
+
+	summary := fmt.Sprintf("↶ Removed from history: %q", pinPreview(user))
+	if reply != "" {
+		summary += fmt.Sprintf(" → %q", pinPreview(reply))
+	}
+	switch {
+	case toolCalls == 1:
+		summary += " (1 tool call)"
+	case toolCalls > 1:
+		summary += fmt.Sprintf(" (%d tool calls)", toolCalls)
+	}
+	return summary
+}
@@ -403,6 +403,7 @@ func (m *Model) applyRuntimeDirectory(dir, worktreeDir string) error {
 		candidate.ApplySkills(m.engine, m.toolMgr)
 	}
 	m.SetSkillsSetup(candidate.Skills)
+	m.useProjectContext(candidate.ProjectContext, oldContext.ProjectContext)
 	m.runtimeSystemContext = candidate
 	if m.config != nil && !m.systemPromptOverridden {
 		m.config.Chat.Instructions = prompt
@@ -628,6 +628,12 @@ func isInspectorCompactionSummaryMessage(msg session.Message) bool {
 	return false
 }
 
+// RenderMessage renders a single message the way the inspector shows it,
+// for previews outside the inspector.
+func (r *ContentRenderer) RenderMessage(msg session.Message) string {
+	return r.renderMessage(msg)
+}
+
 // renderMessage renders a single message with its parts (backward compat, no item tracking)
 func (r *ContentRenderer) renderMessage(msg session.Message) string {
 	content, _, _ := r.renderMessageWithItems(msg, "", 0)
@@ -11,6 +11,7 @@ import (
 	"charm.land/bubbles/v2/textinput"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/sahilm/fuzzy"
 	"github.com/samsaffron/term-llm/internal/session"
 	"github.com/samsaffron/term-llm/internal/tui/inspector"
 	"github.com/samsaffron/term-llm/internal/ui"
@@ -84,6 +85,11 @@ type DeleteConfirmMsg struct {
 	SessionID string
 }
 
+// ArchiveConfirmMsg signals an archive was confirmed
+type ArchiveConfirmMsg struct {
+	SessionID string
+}
+
 // RefreshMsg signals the list should be refreshed
 type RefreshMsg struct{}
 
@@ -110,10 +116,15 @@ type Model struct {
 	sortOrder    SortOrder
 	statusFilter StatusFilter
 
-	// Delete confirmation
-	deleteConfirm bool
-	deleteID      string
-	deleteNumber  int64
+	// Archive/delete confirmation
+	deleteConfirm  bool
+	archiveConfirm bool // The pending confirmation archives rather than deletes
+	deleteID       string
+	deleteNumber   int64
+
+	// Preview pane, cached per session
+	showPreview bool
+	previews    map[previewKey]string
 
 	// Inspector state
 	inspecting bool
@@ -153,6 +164,7 @@ func New(store session.Store, width, height int, styles *ui.Styles) *Model {
 		searchInput: ti,
 		styles:      styles,
 		keyMap:      DefaultKeyMap(),
+		showPreview: true,
 	}
 
 	return m
@@ -214,7 +226,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyPressMsg:
-		return m.handleKeyMsg(msg)
+		model, cmd := m.handleKeyMsg(msg)
+		m.ensurePreview()
+		return model, cmd
 
 	case tea.PasteMsg:
 		if m.searching {
@@ -227,10 +241,13 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		m.ensurePreview()
 		return m, nil
 
 	case RefreshMsg:
-		return m.doRefresh()
+		model, cmd := m.doRefresh()
+		m.ensurePreview()
+		return model, cmd
 
 	case InspectMsg:
 		return m.openInspector(msg.SessionID)
@@ -240,7 +257,14 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Quit
 
 	case DeleteConfirmMsg:
-		return m.doDelete(msg.SessionID)
+		model, cmd := m.doDelete(msg.SessionID)
+		m.ensurePreview()
+		return model, cmd
+
+	case ArchiveConfirmMsg:
+		model, cmd := m.doArchive(msg.SessionID)
+		m.ensurePreview()
+		return model, cmd
 	}
 
 	return m, nil
@@ -271,7 +295,11 @@ func (m *Model) handleKeyMsg(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		switch msg.String() {
 		case "y", "Y":
 			m.deleteConfirm = false
-			return m, func() tea.Msg { return DeleteConfirmMsg{SessionID: m.deleteID} }
+			id := m.deleteID
+			if m.archiveConfirm {
+				return m, func() tea.Msg { return ArchiveConfirmMsg{SessionID: id} }
+			}
+			return m, func() tea.Msg { return DeleteConfirmMsg{SessionID: id} }
 		case "n", "N", "esc":
 			m.deleteConfirm = false
 			m.deleteID = ""
@@ -318,13 +346,17 @@ func (m *Model) handleKeyMsg(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			return m, func() tea.Msg { return InspectMsg{SessionID: m.sessions[m.cursor].ID} }
 		}
 
-	case key.Matches(msg, m.keyMap.Delete):
+	case key.Matches(msg, m.keyMap.Archive), key.Matches(msg, m.keyMap.Delete):
 		if len(m.sessions) > 0 && m.cursor < len(m.sessions) {
 			m.deleteConfirm = true
+			m.archiveConfirm = key.Matches(msg, m.keyMap.Archive)
 			m.deleteID = m.sessions[m.cursor].ID
 			m.deleteNumber = m.sessions[m.cursor].Number
 		}
 
+	case key.Matches(msg, m.keyMap.TogglePreview):
+		m.showPreview = !m.showPreview
+
 	case key.Matches(msg, m.keyMap.Search):
 		m.searching = true
 		m.searchInput.Focus()
@@ -427,17 +459,9 @@ func (m *Model) doRefresh() (tea.Model, tea.Cmd) {
 			m.err = err
 			return m, nil
 		}
-		// Filter by search query (simple substring match on summary/name)
+		// Filter by search query (fuzzy match on name, summary and model)
 		if m.searchQuery != "" {
-			query := strings.ToLower(m.searchQuery)
-			var filtered []session.SessionSummary
-			for _, s := range summaries {
-				if strings.Contains(strings.ToLower(s.Summary), query) ||
-					strings.Contains(strings.ToLower(s.Name), query) {
-					filtered = append(filtered, s)
-				}
-			}
-			summaries = filtered
+			summaries = fuzzyFilterSessions(summaries, m.searchQuery)
 		}
 		m.sessions = summaries
 	}
@@ -512,6 +536,47 @@ func (m *Model) doDelete(sessionID string) (tea.Model, tea.Cmd) {
 	return m.doRefresh()
 }
 
+// doArchive archives a session; 'term-llm sessions restore' brings it back.
+func (m *Model) doArchive(sessionID string) (tea.Model, tea.Cmd) {
+	ctx := context.Background()
+	sess, err := m.store.Get(ctx, sessionID)
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	if sess == nil {
+		m.err = fmt.Errorf("session %s not found", sessionID)
+		return m, nil
+	}
+	sess.Archived = true
+	if err := m.store.Update(ctx, sess); err != nil {
+		m.err = err
+		return m, nil
+	}
+	return m.doRefresh()
+}
+
+// fuzzyFilterSessions keeps the sessions whose name, summary or model
+// fuzzy-match query, in their original order.
+func fuzzyFilterSessions(summaries []session.SessionSummary, query string) []session.SessionSummary {
+	targets := make([]string, len(summaries))
+	for i, s := range summaries {
+		targets[i] = strings.Join([]string{s.Name, s.Summary, s.PreferredLongTitle(), s.Model}, " ")
+	}
+	matches := fuzzy.Find(query, targets)
+	keep := make([]bool, len(summaries))
+	for _, match := range matches {
+		keep[match.Index] = true
+	}
+	var filtered []session.SessionSummary
+	for i, s := range summaries {
+		if keep[i] {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
+
 // View renders the model
 func (m *Model) View() tea.View {
 	// If inspecting, show inspector
@@ -577,46 +642,64 @@ func (m *Model) View() tea.View {
 	b.WriteString(filterStyle.Render(fitToDisplayWidth(strings.Join(filterParts, " "), renderWidth)))
 	b.WriteString("\n")
 
-	cols := sessionColumnWidths(renderWidth)
-	b.WriteString(mutedStyle.Render(fitToDisplayWidth(renderSessionColumnsHeader(cols), renderWidth)))
-	b.WriteString("\n")
+	// With room for it, the list shares the width with a preview of the
+	// highlighted session.
+	listWidth, previewWidth := renderWidth, 0
+	showPreview := m.previewVisible()
+	if showPreview {
+		listWidth, previewWidth = previewWidths(renderWidth)
+	}
+
+	cols := sessionColumnWidths(listWidth)
+	rows := []string{mutedStyle.Render(fitToDisplayWidth(renderSessionColumnsHeader(cols), listWidth))}
 
 	// Session list
 	vpHeight := m.viewportHeight()
 	start, end := ui.VisibleRange(len(m.sessions), m.cursor, vpHeight)
 
 	for i := start; i < end; i++ {
-		row := fitToDisplayWidth(renderSessionRow(m.sessions[i], i == m.cursor, cols), renderWidth)
+		row := fitToDisplayWidth(renderSessionRow(m.sessions[i], i == m.cursor, cols), listWidth)
 		if i == m.cursor {
-			b.WriteString(selectedStyle.Render(row))
+			rows = append(rows, selectedStyle.Render(row))
 		} else {
-			b.WriteString(normalStyle.Render(row))
+			rows = append(rows, normalStyle.Render(row))
 		}
-		b.WriteString("\n")
 	}
 
 	// Pad remaining rows
-	rendered := end - start
-	for i := rendered; i < vpHeight; i++ {
-		b.WriteString(strings.Repeat(" ", renderWidth) + "\n")
+	for i := end - start; i < vpHeight; i++ {
+		rows = append(rows, strings.Repeat(" ", listWidth))
+	}
+
+	if showPreview {
+		divider := lipgloss.NewStyle().Foreground(theme.Border).Render("│")
+		rows = joinPreviewColumns(rows, m.currentPreview(), previewWidth, divider)
+	}
+	for _, row := range rows {
+		b.WriteString(row)
+		b.WriteString("\n")
 	}
 
 	// Footer
 	b.WriteString(strings.Repeat("─", renderWidth))
 	b.WriteString("\n")
 
-	// Delete confirmation
+	// Archive/delete confirmation
 	if m.deleteConfirm {
 		confirmStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Error)
-		b.WriteString(confirmStyle.Render(fitToDisplayWidth(fmt.Sprintf("Delete session #%d? (y/n)", m.deleteNumber), renderWidth)))
+		prompt := fmt.Sprintf("Delete session #%d permanently? (y/n)", m.deleteNumber)
+		if m.archiveConfirm {
+			prompt = fmt.Sprintf("Archive session #%d? Restore it with 'term-llm sessions restore'. (y/n)", m.deleteNumber)
+		}
+		b.WriteString(confirmStyle.Render(fitToDisplayWidth(prompt, renderWidth)))
 	} else if m.err != nil {
 		errorStyle := lipgloss.NewStyle().Foreground(theme.Error)
 		b.WriteString(errorStyle.Render(fitToDisplayWidth(fmt.Sprintf("Error: %v", m.err), renderWidth)))
 	} else {
 		// Help
-		help := "[enter] chat  [i] inspect  [d] delete  [/] search  [s] sort  [f] filter  [q] quit"
+		help := "[enter] chat  [i] inspect  [d] archive  [D] delete  [/] search  [s] sort  [f] filter  [p] preview  [q] quit"
 		if m.embedded {
-			help = "[enter] resume  [i] inspect  [d] delete  [/] search  [s] sort  [f] filter  [q] back"
+			help = "[enter] resume  [i] inspect  [d] archive  [D] delete  [/] search  [s] sort  [f] filter  [p] preview  [q] back"
 		}
 		b.WriteString(mutedStyle.Render(fitToDisplayWidth(help, renderWidth)))
 	}
@@ -4,20 +4,22 @@ import "charm.land/bubbles/v2/key"
 
 // KeyMap defines keybindings for the sessions browser
 type KeyMap struct {
-	Quit       key.Binding
-	Up         key.Binding
-	Down       key.Binding
-	PageUp     key.Binding
-	PageDown   key.Binding
-	GoToTop    key.Binding
-	GoToBottom key.Binding
-	Select     key.Binding
-	Inspect    key.Binding
-	Delete     key.Binding
-	Search     key.Binding
-	Sort       key.Binding
-	Filter     key.Binding
-	ToggleFTS  key.Binding
+	Quit          key.Binding
+	Up            key.Binding
+	Down          key.Binding
+	PageUp        key.Binding
+	PageDown      key.Binding
+	GoToTop       key.Binding
+	GoToBottom    key.Binding
+	Select        key.Binding
+	Inspect       key.Binding
+	Archive       key.Binding
+	Delete        key.Binding
+	Search        key.Binding
+	Sort          key.Binding
+	Filter        key.Binding
+	ToggleFTS     key.Binding
+	TogglePreview key.Binding
 }
 
 // DefaultKeyMap returns the default keybindings
@@ -59,9 +61,13 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("i"),
 			key.WithHelp("i", "inspect"),
 		),
-		Delete: key.NewBinding(
+		Archive: key.NewBinding(
 			key.WithKeys("d"),
-			key.WithHelp("d", "delete"),
+			key.WithHelp("d", "archive"),
+		),
+		Delete: key.NewBinding(
+			key.WithKeys("D"),
+			key.WithHelp("D", "delete"),
 		),
 		Search: key.NewBinding(
 			key.WithKeys("/", "tab"),
@@ -79,19 +85,23 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("ctrl+f"),
 			key.WithHelp("ctrl+f", "FTS"),
 		),
+		TogglePreview: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "preview"),
+		),
 	}
 }
 
 // ShortHelp returns keybindings for the short help view
 func (k KeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Select, k.Inspect, k.Delete, k.Search, k.Sort, k.Filter, k.Quit}
+	return []key.Binding{k.Select, k.Inspect, k.Archive, k.Search, k.Sort, k.Filter, k.Quit}
 }
 
 // FullHelp returns keybindings for the full help view
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.PageUp, k.PageDown},
-		{k.GoToTop, k.GoToBottom, k.Select, k.Inspect, k.Delete},
-		{k.Search, k.Sort, k.Filter, k.ToggleFTS, k.Quit},
+		{k.GoToTop, k.GoToBottom, k.Select, k.Inspect, k.Archive, k.Delete},
+		{k.Search, k.Sort, k.Filter, k.ToggleFTS, k.TogglePreview, k.Quit},
 	}
 }
@@ -0,0 +1,164 @@
+package sessions
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	appconfig "github.com/samsaffron/term-llm/internal/config"
+	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/session"
+	"github.com/samsaffron/term-llm/internal/tui/inspector"
+)
+
+const (
+	previewHeadMessages = 2   // Messages shown from the start of a session
+	previewTailMessages = 3   // Messages shown from the end of a session
+	previewMinWidth     = 110 // Narrower terminals keep the full-width list
+)
+
+// previewKey identifies a rendered preview. UpdatedAt is part of the key so
+// a session that changed since it was rendered is rendered again.
+type previewKey struct {
+	sessionID string
+	updatedAt time.Time
+	width     int
+}
+
+// previewVisible reports whether the layout has room for the preview pane.
+func (m *Model) previewVisible() bool {
+	return m.showPreview && m.width >= previewMinWidth && m.store != nil
+}
+
+// previewWidths splits the render width between the list and the preview,
+// leaving one column for the divider.
+func previewWidths(renderWidth int) (listWidth, previewWidth int) {
+	listWidth = renderWidth * 11 / 20
+	return listWidth, renderWidth - listWidth - 1
+}
+
+// ensurePreview renders the highlighted session's preview if it is not
+// cached yet. Previews are cached per session so moving the cursor back
+// and forth neither re-queries the store nor re-renders.
+func (m *Model) ensurePreview() {
+	if !m.previewVisible() || m.cursor >= len(m.sessions) {
+		return
+	}
+	s := m.sessions[m.cursor]
+	_, width := previewWidths(m.width)
+	key := previewKey{sessionID: s.ID, updatedAt: s.UpdatedAt, width: width}
+	if _, ok := m.previews[key]; ok {
+		return
+	}
+	if m.previews == nil {
+		m.previews = make(map[previewKey]string)
+	}
+	m.previews[key] = m.renderPreview(s, width)
+}
+
+// currentPreview returns the cached preview for the highlighted session.
+func (m *Model) currentPreview() string {
+	if m.cursor >= len(m.sessions) {
+		return ""
+	}
+	s := m.sessions[m.cursor]
+	_, width := previewWidths(m.width)
+	return m.previews[previewKey{sessionID: s.ID, updatedAt: s.UpdatedAt, width: width}]
+}
+
+func (m *Model) renderPreview(s session.SessionSummary, width int) string {
+	theme := m.styles.Theme()
+	mutedStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+
+	head, tail, err := loadPreviewMessages(context.Background(), m.store, s.ID)
+	if err != nil {
+		return mutedStyle.Render(fmt.Sprintf("Preview unavailable: %v", err))
+	}
+	if len(head) == 0 {
+		return mutedStyle.Render("No messages yet.")
+	}
+
+	renderer := inspector.NewContentRenderer(width, m.styles, nil, nil, "", "", nil, appconfig.ReasoningConfig{})
+	var blocks []string
+	for _, msg := range head {
+		blocks = append(blocks, renderer.RenderMessage(msg))
+	}
+	if skipped := s.MessageCount - len(head) - len(tail); len(tail) > 0 && skipped > 0 {
+		blocks = append(blocks, mutedStyle.Render(fmt.Sprintf("⋯ %d more message%s ⋯", skipped, pluralS(skipped))))
+	}
+	for _, msg := range tail {
+		blocks = append(blocks, renderer.RenderMessage(msg))
+	}
+	return strings.Join(blocks, "\n")
+}
+
+// loadPreviewMessages returns the first and last few non-system messages
+// of a session. tail is empty when head already covers the session.
+func loadPreviewMessages(ctx context.Context, store session.Store, sessionID string) (head, tail []session.Message, err error) {
+	want := previewHeadMessages + previewTailMessages
+	first, err := store.GetMessages(ctx, sessionID, want, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	complete := len(first) < want
+	first = withoutSystemMessages(first)
+	if complete || len(first) <= previewHeadMessages {
+		return first, nil, nil
+	}
+	head = first[:previewHeadMessages]
+
+	var last []session.Message
+	if pager, ok := store.(session.MessagesDescendingPager); ok {
+		last, err = pager.GetMessagesPageDescending(ctx, sessionID, 0, previewTailMessages)
+		slices.Reverse(last)
+	} else {
+		last, err = store.GetMessages(ctx, sessionID, 0, 0)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	last = withoutSystemMessages(last)
+	if len(last) > previewTailMessages {
+		last = last[len(last)-previewTailMessages:]
+	}
+	// Drop anything head already shows.
+	for len(last) > 0 && last[0].Sequence <= head[len(head)-1].Sequence {
+		last = last[1:]
+	}
+	return head, last, nil
+}
+
+func withoutSystemMessages(messages []session.Message) []session.Message {
+	out := messages[:0:0]
+	for _, msg := range messages {
+		if msg.Role != llm.RoleSystem {
+			out = append(out, msg)
+		}
+	}
+	return out
+}
+
+// joinPreviewColumns lays the list rows and preview lines side by side.
+func joinPreviewColumns(listRows []string, preview string, previewWidth int, divider string) []string {
+	previewLines := strings.Split(preview, "\n")
+	out := make([]string, len(listRows))
+	for i, row := range listRows {
+		line := ""
+		if i < len(previewLines) {
+			line = ansi.Truncate(previewLines[i], previewWidth, "…")
+		}
+		out[i] = row + divider + line
+	}
+	return out
+}
+
+func pluralS(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
@@ -0,0 +1,192 @@
+package sessions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/samsaffron/term-llm/internal/llm"
+	"github.com/samsaffron/term-llm/internal/session"
+	"github.com/samsaffron/term-llm/internal/ui"
+)
+
+type previewStore struct {
+	session.NoopStore
+	sessions         map[string]*session.Session
+	messages         map[string][]session.Message
+	getMessagesCalls int
+}
+
+func newPreviewStore() *previewStore {
+	return &previewStore{sessions: map[string]*session.Session{}, messages: map[string][]session.Message{}}
+}
+
+func (s *previewStore) add(id string, number int64, name string, texts ...string) {
+	s.sessions[id] = &session.Session{ID: id, Number: number, Name: name, Model: "gpt-5", UpdatedAt: time.Unix(int64(number), 0)}
+	for i, text := range texts {
+		role := llm.RoleUser
+		if i%2 == 1 {
+			role = llm.RoleAssistant
+		}
+		s.messages[id] = append(s.messages[id], *session.NewMessage(id, llm.Message{Role: role, Parts: []llm.Part{{Type: llm.PartText, Text: text}}}, i))
+		s.messages[id][i].Sequence = i
+	}
+}
+
+func (s *previewStore) List(ctx context.Context, opts session.ListOptions) ([]session.SessionSummary, error) {
+	var out []session.SessionSummary
+	for _, sess := range s.sessions {
+		if sess.Archived {
+			continue
+		}
+		out = append(out, session.SessionSummary{
+			ID: sess.ID, Number: sess.Number, Name: sess.Name, Model: sess.Model,
+			MessageCount: len(s.messages[sess.ID]), UpdatedAt: sess.UpdatedAt,
+		})
+	}
+	return out, nil
+}
+
+func (s *previewStore) Get(ctx context.Context, id string) (*session.Session, error) {
+	sess, ok := s.sessions[id]
+	if !ok {
+		return nil, session.ErrNotFound
+	}
+	copied := *sess
+	return &copied, nil
+}
+
+func (s *previewStore) Update(ctx context.Context, sess *session.Session) error {
+	s.sessions[sess.ID] = sess
+	return nil
+}
+
+func (s *previewStore) GetMessages(ctx context.Context, sessionID string, limit, offset int) ([]session.Message, error) {
+	s.getMessagesCalls++
+	msgs := s.messages[sessionID]
+	if offset > len(msgs) {
+		return nil, nil
+	}
+	msgs = msgs[offset:]
+	if limit > 0 && len(msgs) > limit {
+		msgs = msgs[:limit]
+	}
+	return msgs, nil
+}
+
+func TestPreviewShowsHeadAndTailAndIsCached(t *testing.T) {
+	store := newPreviewStore()
+	var texts []string
+	for i := 1; i <= 8; i++ {
+		texts = append(texts, fmt.Sprintf("message-%d", i))
+	}
+	store.add("long", 2, "long chat", texts...)
+	store.add("short", 1, "short chat", "only question")
+
+	m := New(store, 140, 30, nil)
+	m.Init()()
+	updated, _ := m.Update(RefreshMsg{})
+	m = updated.(*Model)
+
+	view := ui.StripANSI(m.View().Content)
+	for _, want := range []string{"message-1", "message-2", "3 more messages", "message-6", "message-8"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("preview is missing %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "message-4") {
+		t.Fatalf("preview shows a middle message:\n%s", view)
+	}
+
+	calls := store.getMessagesCalls
+	m.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+	m.Update(tea.KeyPressMsg{Code: tea.KeyUp})
+	m.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+	if got := store.getMessagesCalls - calls; got != 1 {
+		t.Fatalf("moving between two sessions loaded messages %d times, want 1", got)
+	}
+	if view := ui.StripANSI(m.View().Content); !strings.Contains(view, "only question") {
+		t.Fatalf("preview did not follow the cursor:\n%s", view)
+	}
+}
+
+func TestPreviewHiddenOnNarrowTerminalsAndToggle(t *testing.T) {
+	store := newPreviewStore()
+	store.add("a", 1, "chat", "hello there")
+
+	m := New(store, 80, 30, nil)
+	m.Update(RefreshMsg{})
+	if store.getMessagesCalls != 0 || strings.Contains(ui.StripANSI(m.View().Content), "hello there") {
+		t.Fatal("narrow terminals should not load or show a preview")
+	}
+
+	m.Update(tea.WindowSizeMsg{Width: 140, Height: 30})
+	if !strings.Contains(ui.StripANSI(m.View().Content), "hello there") {
+		t.Fatal("preview should appear once the terminal is wide enough")
+	}
+	m.Update(tea.KeyPressMsg{Code: 'p', Text: "p"})
+	if strings.Contains(ui.StripANSI(m.View().Content), "hello there") {
+		t.Fatal("p should hide the preview")
+	}
+}
+
+func TestArchiveKeyConfirmsAndArchives(t *testing.T) {
+	store := newPreviewStore()
+	store.add("a", 1, "keep me", "hi")
+	store.add("b", 2, "archive me", "bye")
+
+	m := New(store, 80, 30, nil)
+	m.Update(RefreshMsg{})
+	if m.sessions[0].ID != "b" {
+		t.Fatalf("first session = %q, want the most recent", m.sessions[0].ID)
+	}
+
+	m.Update(tea.KeyPressMsg{Code: 'd', Text: "d"})
+	if !m.deleteConfirm || !m.archiveConfirm {
+		t.Fatal("d should ask to archive")
+	}
+	if view := ui.StripANSI(m.View().Content); !strings.Contains(view, "Archive session #2?") {
+		t.Fatalf("confirmation prompt missing:\n%s", view)
+	}
+	_, cmd := m.Update(tea.KeyPressMsg{Code: 'y', Text: "y"})
+	if cmd == nil {
+		t.Fatal("confirming should archive")
+	}
+	m.Update(cmd())
+
+	if !store.sessions["b"].Archived {
+		t.Fatal("session was not archived")
+	}
+	if len(m.sessions) != 1 || m.sessions[0].ID != "a" {
+		t.Fatalf("sessions after archive = %+v, want only a", m.sessions)
+	}
+}
+
+func TestFuzzyFilterSessions(t *testing.T) {
+	summaries := []session.SessionSummary{
+		{ID: "1", Name: "investigate auth flow"},
+		{ID: "2", Summary: "release notes draft"},
+		{ID: "3", Name: "misc", Model: "claude-sonnet"},
+	}
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"authflow", []string{"1"}},
+		{"rlsnotes", []string{"2"}},
+		{"sonnet", []string{"3"}},
+		{"zzz", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, s := range fuzzyFilterSessions(summaries, tt.query) {
+			got = append(got, s.ID)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("fuzzyFilterSessions(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
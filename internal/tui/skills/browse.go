@@ -15,6 +15,7 @@ import (
 	"charm.land/bubbles/v2/textinput"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/samsaffron/term-llm/internal/pathutil"
 	"github.com/samsaffron/term-llm/internal/skills"
 	"github.com/samsaffron/term-llm/internal/ui"
 	"golang.org/x/term"
@@ -202,10 +203,7 @@ func New(initialQuery string, useAISearch bool) *Model {
 // Exported for use by add.go.
 func BuildInstallPaths() []InstallPath {
 	home, _ := os.UserHomeDir()
-	configDir := os.Getenv("XDG_CONFIG_HOME")
-	if configDir == "" && home != "" {
-		configDir = filepath.Join(home, ".config")
-	}
+	configDir, _ := pathutil.ConfigDir()
 
 	cwd, _ := os.Getwd()
 	inProject := isProjectDirectory(cwd, home)
@@ -216,7 +214,7 @@ func BuildInstallPaths() []InstallPath {
 	paths = append(paths, InstallPath{
 		ID:       "term-llm",
 		Label:    "term-llm global",
-		Path:     filepath.Join(configDir, "term-llm", "skills"),
+		Path:     filepath.Join(configDir, "skills"),
 		Selected: true, // Default selected
 	})
 
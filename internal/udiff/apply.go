@@ -2,6 +2,7 @@ package udiff
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -12,6 +13,182 @@ type ApplyResult struct {
 	Warnings []string // Warnings for hunks that failed to apply
 }
 
+// FailureReason classifies why a hunk failed to apply.
+type FailureReason string
+
+const (
+	// FailureContextNotFound means the @@ header text is not in the file.
+	FailureContextNotFound FailureReason = "context not found"
+	// FailureNoMatch means the hunk's context and removed lines are not in the file.
+	FailureNoMatch FailureReason = "no match"
+	// FailureAnchorNotFound means the lines before an elision are not in the file.
+	FailureAnchorNotFound FailureReason = "elision start not found"
+	// FailureBraceMatch means no end anchor follows an elision at the same brace depth.
+	FailureBraceMatch FailureReason = "elision end not found"
+	// FailureAmbiguous means a fuzzy tier matched the hunk in several places.
+	FailureAmbiguous FailureReason = "ambiguous match"
+	// FailureInvalidHunk means the hunk itself is malformed.
+	FailureInvalidHunk FailureReason = "invalid hunk"
+)
+
+// MatchTier is how loosely a hunk's lines were compared with the file to
+// find where it applies. Tiers are tried in order, loosest last.
+type MatchTier int
+
+const (
+	// MatchExact compares lines exactly.
+	MatchExact MatchTier = iota
+	// MatchWhitespace ignores leading and trailing whitespace.
+	MatchWhitespace
+	// MatchSimilar accepts lines whose average similarity reaches
+	// Options.Similarity.
+	MatchSimilar
+)
+
+func (t MatchTier) String() string {
+	switch t {
+	case MatchExact:
+		return "exact"
+	case MatchWhitespace:
+		return "whitespace"
+	case MatchSimilar:
+		return "similar"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseMatchTier parses a tier name as used in configuration. An empty
+// name selects the loosest tier.
+func ParseMatchTier(name string) (MatchTier, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "exact":
+		return MatchExact, nil
+	case "whitespace":
+		return MatchWhitespace, nil
+	case "", "similar":
+		return MatchSimilar, nil
+	default:
+		return MatchExact, fmt.Errorf("unknown match tier %q (want exact, whitespace or similar)", name)
+	}
+}
+
+// DefaultSimilarity is the average Levenshtein ratio the lines of a hunk need
+// in the MatchSimilar tier.
+const DefaultSimilarity = 0.8
+
+// minLineSimilarity is the ratio below which a single line rules out a
+// MatchSimilar match whatever the average.
+const minLineSimilarity = 0.5
+
+// Options configures how hunks are located in the file.
+type Options struct {
+	MaxTier    MatchTier // Loosest tier tried
+	Similarity float64   // Average line similarity for MatchSimilar; 0 means DefaultSimilarity
+}
+
+// DefaultOptions tries every tier with DefaultSimilarity.
+func DefaultOptions() Options {
+	return Options{MaxTier: MatchSimilar, Similarity: DefaultSimilarity}
+}
+
+func (o Options) similarity() float64 {
+	if o.Similarity <= 0 {
+		return DefaultSimilarity
+	}
+	return o.Similarity
+}
+
+// FuzzyMatch records a hunk that only applied with a loose tier, so the
+// caller can warn that the file differed from what the diff expected.
+type FuzzyMatch struct {
+	Hunk int // 1-based position of the hunk in the diff
+	Tier MatchTier
+}
+
+// Warning describes the match for the user, usually a sign that the model's
+// view of the file was stale.
+func (m FuzzyMatch) Warning() string {
+	if m.Tier == MatchWhitespace {
+		return fmt.Sprintf("hunk %d matched only after ignoring whitespace", m.Hunk)
+	}
+	return fmt.Sprintf("hunk %d matched lines that differ slightly from the diff", m.Hunk)
+}
+
+// HunkError reports a hunk that failed to apply.
+type HunkError struct {
+	Hunk   int // 1-based position of the hunk in the diff
+	Reason FailureReason
+	Detail string
+}
+
+func (e *HunkError) Error() string {
+	return fmt.Sprintf("hunk %d: %s", e.Hunk, e.Detail)
+}
+
+// matchError is a hunk failure before it is numbered.
+type matchError struct {
+	reason FailureReason
+	detail string
+}
+
+func (e *matchError) Error() string { return e.detail }
+
+func failf(reason FailureReason, format string, args ...any) error {
+	return &matchError{reason: reason, detail: fmt.Sprintf(format, args...)}
+}
+
+// ApplyMode selects what ApplyHunks does when some hunks fail.
+type ApplyMode int
+
+const (
+	// ApplyPartially keeps the hunks that applied.
+	ApplyPartially ApplyMode = iota
+	// ApplyAtomically returns the content unchanged unless every hunk applied.
+	ApplyAtomically
+)
+
+// HunksResult is the outcome of ApplyHunks.
+type HunksResult struct {
+	Content string       // Content with the applied hunks
+	Applied []int        // 1-based positions of the hunks that applied
+	Failed  []*HunkError // Hunks that failed, in order
+	Fuzzy   []FuzzyMatch // Applied hunks that did not match exactly
+}
+
+// ApplyHunks applies hunks in order, reporting each failure. Every hunk is
+// tried, so all failures are reported even in atomic mode, where a failure
+// leaves Content as the original content and Applied empty.
+func ApplyHunks(content string, hunks []Hunk, mode ApplyMode, opts Options) HunksResult {
+	lines := strings.Split(content, "\n")
+	var result HunksResult
+	for i, hunk := range hunks {
+		newLines, tier, err := applyHunk(lines, hunk, opts)
+		if err != nil {
+			hunkErr := &HunkError{Hunk: i + 1, Reason: FailureInvalidHunk, Detail: err.Error()}
+			if me, ok := err.(*matchError); ok {
+				hunkErr.Reason = me.reason
+			}
+			result.Failed = append(result.Failed, hunkErr)
+			continue
+		}
+		lines = newLines
+		result.Applied = append(result.Applied, i+1)
+		if tier != MatchExact {
+			result.Fuzzy = append(result.Fuzzy, FuzzyMatch{Hunk: i + 1, Tier: tier})
+		}
+	}
+
+	if mode == ApplyAtomically && len(result.Failed) > 0 {
+		result.Content = content
+		result.Applied = nil
+		result.Fuzzy = nil
+		return result
+	}
+	result.Content = strings.Join(lines, "\n")
+	return result
+}
+
 // Apply applies the hunks to the given content and returns the modified content.
 // Returns an error on the first failed hunk (strict mode).
 func Apply(content string, hunks []Hunk) (string, error) {
@@ -20,7 +197,7 @@ func Apply(content string, hunks []Hunk) (string, error) {
 	// Apply hunks in order
 	for i, hunk := range hunks {
 		var err error
-		lines, err = applyHunk(lines, hunk)
+		lines, _, err = applyHunk(lines, hunk, DefaultOptions())
 		if err != nil {
 			return "", fmt.Errorf("hunk %d: %w", i+1, err)
 		}
@@ -32,55 +209,122 @@ func Apply(content string, hunks []Hunk) (string, error) {
 // ApplyWithWarnings applies hunks, skipping failures and collecting warnings.
 // Returns modified content with all successful hunks applied.
 func ApplyWithWarnings(content string, hunks []Hunk) ApplyResult {
-	lines := strings.Split(content, "\n")
+	result := ApplyHunks(content, hunks, ApplyPartially, DefaultOptions())
 	var warnings []string
-
-	// Apply hunks in order, collecting warnings for failures
-	for i, hunk := range hunks {
-		newLines, err := applyHunk(lines, hunk)
-		if err != nil {
-			warnings = append(warnings, fmt.Sprintf("hunk %d: %v", i+1, err))
-			continue
-		}
-		lines = newLines
+	for _, failure := range result.Failed {
+		warnings = append(warnings, failure.Error())
 	}
-
 	return ApplyResult{
-		Content:  strings.Join(lines, "\n"),
+		Content:  result.Content,
 		Warnings: warnings,
 	}
 }
 
+// FilesResult is the outcome of ApplyFileDiffs.
+type FilesResult struct {
+	Files   map[string]string // Every file that exists after the diffs, with its content
+	Created []string          // Paths created by the diffs
+	Deleted []string          // Paths deleted by the diffs; callers remove them
+}
+
 // ApplyFileDiffs applies multiple file diffs to a map of file contents.
-// Returns a new map with the modified contents.
-func ApplyFileDiffs(files map[string]string, diffs []FileDiff) (map[string]string, error) {
-	result := make(map[string]string)
+// The input map is not modified. Creating a file that exists, or changing
+// or deleting one that doesn't, is an error.
+func ApplyFileDiffs(files map[string]string, diffs []FileDiff) (FilesResult, error) {
+	result := FilesResult{Files: make(map[string]string, len(files))}
 
 	// Copy all existing files
 	for path, content := range files {
-		result[path] = content
+		result.Files[path] = content
 	}
 
 	// Apply each diff
 	for _, diff := range diffs {
-		content, ok := result[diff.Path]
-		if !ok {
-			return nil, fmt.Errorf("file not found: %s", diff.Path)
-		}
+		content, exists := result.Files[diff.Path]
+		switch {
+		case diff.IsCreate():
+			if exists {
+				return FilesResult{}, fmt.Errorf("file already exists: %s", diff.Path)
+			}
+			created, err := NewFileContent(diff.Hunks)
+			if err != nil {
+				return FilesResult{}, fmt.Errorf("%s: %w", diff.Path, err)
+			}
+			result.Files[diff.Path] = created
+			result.Deleted = slices.DeleteFunc(result.Deleted, func(p string) bool { return p == diff.Path })
+			result.Created = append(result.Created, diff.Path)
 
-		modified, err := Apply(content, diff.Hunks)
-		if err != nil {
-			return nil, fmt.Errorf("%s: %w", diff.Path, err)
-		}
+		case !exists:
+			return FilesResult{}, fmt.Errorf("file not found: %s", diff.Path)
 
-		result[diff.Path] = modified
+		case diff.IsDelete():
+			if err := CheckDeletion(content, diff.Hunks); err != nil {
+				return FilesResult{}, fmt.Errorf("%s: %w", diff.Path, err)
+			}
+			delete(result.Files, diff.Path)
+			result.Created = slices.DeleteFunc(result.Created, func(p string) bool { return p == diff.Path })
+			result.Deleted = append(result.Deleted, diff.Path)
+
+		default:
+			modified, err := Apply(content, diff.Hunks)
+			if err != nil {
+				return FilesResult{}, fmt.Errorf("%s: %w", diff.Path, err)
+			}
+			result.Files[diff.Path] = modified
+		}
 	}
 
 	return result, nil
 }
 
-// applyHunk applies a single hunk to the content lines.
-func applyHunk(lines []string, hunk Hunk) ([]string, error) {
+// NewFileContent returns the content of a file created by hunks, which may
+// only add lines. Blank lines without a + prefix are kept as blank lines.
+func NewFileContent(hunks []Hunk) (string, error) {
+	var lines []string
+	for i, hunk := range hunks {
+		for _, line := range hunk.Lines {
+			switch {
+			case line.Type == Add:
+				lines = append(lines, line.Content)
+			case line.Type == Context && line.Content == "":
+				lines = append(lines, "")
+			default:
+				return "", fmt.Errorf("hunk %d: a new file can only have added lines, got %s line %q", i+1, line.Type, line.Content)
+			}
+		}
+	}
+	if len(lines) == 0 {
+		return "", nil
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// CheckDeletion verifies that the hunks of a deletion diff remove all of
+// content. A deletion without hunks removes the file whatever it contains.
+func CheckDeletion(content string, hunks []Hunk) error {
+	if len(hunks) == 0 {
+		return nil
+	}
+	for i, hunk := range hunks {
+		for _, line := range hunk.Lines {
+			if line.Type == Add {
+				return fmt.Errorf("hunk %d: a deleted file cannot have added lines", i+1)
+			}
+		}
+	}
+	remaining, err := Apply(content, hunks)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(remaining) != "" {
+		return fmt.Errorf("deletion does not remove the whole file")
+	}
+	return nil
+}
+
+// applyHunk applies a single hunk to the content lines, returning the tier
+// that located it.
+func applyHunk(lines []string, hunk Hunk, opts Options) ([]string, MatchTier, error) {
 	// Find the starting position using the @@ context header
 	startPos := 0
 	if hunk.Context != "" {
@@ -98,7 +342,7 @@ func applyHunk(lines []string, hunk Hunk) ([]string, error) {
 			// Try context-based matching (e.g., "func Name")
 			pos := findContext(lines, hunk.Context, 0)
 			if pos < 0 {
-				return nil, fmt.Errorf("context not found: %q", hunk.Context)
+				return nil, MatchExact, failf(FailureContextNotFound, "context not found: %q", hunk.Context)
 			}
 			startPos = pos
 		}
@@ -108,9 +352,9 @@ func applyHunk(lines []string, hunk Hunk) ([]string, error) {
 	oldSeq, newSeq, hasElision, elisionEndAnchor := extractSequences(hunk.Lines)
 
 	// Find where to apply the change
-	matchStart, matchEnd, err := findMatch(lines, oldSeq, startPos, hasElision, elisionEndAnchor)
+	matchStart, matchEnd, tier, err := findMatch(lines, oldSeq, startPos, hasElision, elisionEndAnchor, opts)
 	if err != nil {
-		return nil, err
+		return nil, tier, err
 	}
 
 	// Build the result
@@ -119,7 +363,7 @@ func applyHunk(lines []string, hunk Hunk) ([]string, error) {
 	result = append(result, newSeq...)
 	result = append(result, lines[matchEnd:]...)
 
-	return result, nil
+	return result, tier, nil
 }
 
 // extractSequences extracts the old and new line sequences from hunk lines.
@@ -226,76 +470,76 @@ func findContext(lines []string, context string, pos int) int {
 }
 
 // findMatch finds where the old sequence matches in the content.
-// Returns (startIndex, endIndex, error).
+// Returns (startIndex, endIndex, tier, error).
 // With elision, it finds the start anchor and end anchor with brace tracking.
-func findMatch(lines []string, oldSeq []string, startPos int, hasElision bool, elisionEndAnchor string) (int, int, error) {
+func findMatch(lines []string, oldSeq []string, startPos int, hasElision bool, elisionEndAnchor string, opts Options) (int, int, MatchTier, error) {
 	if len(oldSeq) == 0 {
 		// Pure addition - insert at startPos
-		return startPos, startPos, nil
+		return startPos, startPos, MatchExact, nil
 	}
 
 	if hasElision {
-		return findMatchWithElision(lines, oldSeq, startPos, elisionEndAnchor)
+		return findMatchWithElision(lines, oldSeq, startPos, elisionEndAnchor, opts)
 	}
 
-	return findExactMatch(lines, oldSeq, startPos)
-}
-
-// findExactMatch finds an exact sequence match (with fuzzy and similarity fallbacks).
-// First tries from startPos, then falls back to searching from beginning of file.
-func findExactMatch(lines []string, oldSeq []string, startPos int) (int, int, error) {
-	// Try exact match first from startPos
-	for i := startPos; i <= len(lines)-len(oldSeq); i++ {
-		if matchSequence(lines[i:], oldSeq, false) {
-			return i, i + len(oldSeq), nil
-		}
+	pos, tier, err := findSequence(lines, oldSeq, startPos, opts)
+	if err != nil {
+		return 0, 0, tier, err
 	}
-
-	// Try fuzzy match (trimmed whitespace) from startPos
-	for i := startPos; i <= len(lines)-len(oldSeq); i++ {
-		if matchSequence(lines[i:], oldSeq, true) {
-			return i, i + len(oldSeq), nil
-		}
-	}
-
-	// Try similarity-based matching (Levenshtein) from startPos
-	for i := startPos; i <= len(lines)-len(oldSeq); i++ {
-		if matchSequenceSimilar(lines[i:], oldSeq) {
-			return i, i + len(oldSeq), nil
-		}
+	if pos < 0 {
+		return 0, 0, tier, failf(FailureNoMatch, "could not find matching lines:\n%s", strings.Join(oldSeq, "\n"))
 	}
+	return pos, pos + len(oldSeq), tier, nil
+}
 
-	// If startPos > 0, try searching from the beginning of the file
-	// (LLM may have gotten line numbers wrong)
+// findSequence finds where seq occurs, trying each tier up to opts.MaxTier,
+// first from startPos and then before it (the LLM may have gotten line
+// numbers wrong). The first exact match wins, but a looser tier must find a
+// single candidate: picking one of several near-matches would edit the
+// wrong place. Returns -1 when nothing matches.
+func findSequence(lines []string, seq []string, startPos int, opts Options) (int, MatchTier, error) {
+	last := len(lines) - len(seq)
+	ranges := [][2]int{{startPos, last}}
 	if startPos > 0 {
-		// Try exact match from beginning
-		for i := 0; i < startPos && i <= len(lines)-len(oldSeq); i++ {
-			if matchSequence(lines[i:], oldSeq, false) {
-				return i, i + len(oldSeq), nil
+		ranges = append(ranges, [2]int{0, min(startPos-1, last)})
+	}
+
+	for _, r := range ranges {
+		for tier := MatchExact; tier <= opts.MaxTier; tier++ {
+			var candidates []int
+			for i := r[0]; i <= r[1]; i++ {
+				if !matchSequenceTier(lines[i:], seq, tier, opts.similarity()) {
+					continue
+				}
+				candidates = append(candidates, i)
+				if tier == MatchExact {
+					break
+				}
 			}
-		}
-
-		// Try fuzzy match from beginning
-		for i := 0; i < startPos && i <= len(lines)-len(oldSeq); i++ {
-			if matchSequence(lines[i:], oldSeq, true) {
-				return i, i + len(oldSeq), nil
-			}
-		}
-
-		// Try similarity from beginning
-		for i := 0; i < startPos && i <= len(lines)-len(oldSeq); i++ {
-			if matchSequenceSimilar(lines[i:], oldSeq) {
-				return i, i + len(oldSeq), nil
+			switch {
+			case len(candidates) == 1:
+				return candidates[0], tier, nil
+			case len(candidates) > 1:
+				return -1, tier, failf(FailureAmbiguous, "lines %s all match with the %s tier; include more context to pick one:\n%s",
+					formatLineNumbers(candidates), tier, strings.Join(seq, "\n"))
 			}
 		}
 	}
+	return -1, MatchExact, nil
+}
 
-	return 0, 0, fmt.Errorf("could not find matching lines:\n%s", strings.Join(oldSeq, "\n"))
+// formatLineNumbers formats 0-based indexes as 1-based line numbers.
+func formatLineNumbers(indexes []int) string {
+	numbers := make([]string, len(indexes))
+	for i, idx := range indexes {
+		numbers[i] = strconv.Itoa(idx + 1)
+	}
+	return strings.Join(numbers, ", ")
 }
 
 // findMatchWithElision finds a match where elision is used.
 // It matches the start anchor, then uses brace tracking to find the end.
-func findMatchWithElision(lines []string, oldSeq []string, startPos int, endAnchor string) (int, int, error) {
+func findMatchWithElision(lines []string, oldSeq []string, startPos int, endAnchor string, opts Options) (int, int, MatchTier, error) {
 	// Find lines before elision (the start anchors)
 	var startAnchors []string
 	for _, s := range oldSeq {
@@ -306,38 +550,32 @@ func findMatchWithElision(lines []string, oldSeq []string, startPos int, endAnch
 	}
 
 	if len(startAnchors) == 0 {
-		return 0, 0, fmt.Errorf("elision requires at least one start anchor line")
+		return 0, 0, MatchExact, failf(FailureInvalidHunk, "elision requires at least one start anchor line")
 	}
 
-	// Find the start anchor
-	matchStart := -1
-	for i := startPos; i <= len(lines)-len(startAnchors); i++ {
-		if matchSequence(lines[i:], startAnchors, false) {
-			matchStart = i
-			break
-		}
+	// The start anchor is usually a lone signature line, where a similar
+	// line is more likely another function than drift, so stop at
+	// whitespace-insensitive matching.
+	anchorOpts := opts
+	if anchorOpts.MaxTier > MatchWhitespace {
+		anchorOpts.MaxTier = MatchWhitespace
 	}
-	if matchStart < 0 {
-		// Try fuzzy
-		for i := startPos; i <= len(lines)-len(startAnchors); i++ {
-			if matchSequence(lines[i:], startAnchors, true) {
-				matchStart = i
-				break
-			}
-		}
+	matchStart, tier, err := findSequence(lines, startAnchors, startPos, anchorOpts)
+	if err != nil {
+		return 0, 0, tier, err
 	}
 	if matchStart < 0 {
-		return 0, 0, fmt.Errorf("could not find start anchor: %q", startAnchors[0])
+		return 0, 0, tier, failf(FailureAnchorNotFound, "could not find start anchor: %q", startAnchors[0])
 	}
 
 	// Find the end position using brace tracking
 	searchStart := matchStart + len(startAnchors)
 	endPos, err := findElisionEnd(lines, searchStart, endAnchor)
 	if err != nil {
-		return 0, 0, err
+		return 0, 0, tier, err
 	}
 
-	return matchStart, endPos, nil
+	return matchStart, endPos, tier, nil
 }
 
 // findElisionEnd finds where the elision ends by matching the end anchor.
@@ -369,7 +607,7 @@ func findElisionEnd(lines []string, startPos int, endAnchor string) (int, error)
 		}
 	}
 
-	return 0, fmt.Errorf("could not find end anchor: %q (brace depth never returned to 0)", endAnchor)
+	return 0, failf(FailureBraceMatch, "could not find end anchor: %q (brace depth never returned to 0)", endAnchor)
 }
 
 // updateBraceDepth updates the brace depth for a line, respecting strings and comments.
@@ -471,15 +709,23 @@ func matchSequence(content []string, pattern []string, fuzzy bool) bool {
 	return true
 }
 
-// Similarity thresholds for fuzzy matching.
-const (
-	similarityThresholdAvg  = 0.8 // Average similarity required across all lines
-	similarityThresholdLine = 0.5 // Per-line minimum (more lenient for context lines)
-)
+// matchSequenceTier checks if the lines starting at content match the
+// pattern under the given tier.
+func matchSequenceTier(content []string, pattern []string, tier MatchTier, similarity float64) bool {
+	switch tier {
+	case MatchExact:
+		return matchSequence(content, pattern, false)
+	case MatchWhitespace:
+		return matchSequence(content, pattern, true)
+	default:
+		return matchSequenceSimilar(content, pattern, similarity)
+	}
+}
 
-// matchSequenceSimilar checks if lines match pattern using similarity scoring.
-// Returns true if average similarity exceeds threshold and no line is too different.
-func matchSequenceSimilar(content []string, pattern []string) bool {
+// matchSequenceSimilar checks if the lines of pattern are on average at
+// least threshold similar to the corresponding content lines, with no line
+// below minLineSimilarity.
+func matchSequenceSimilar(content []string, pattern []string, threshold float64) bool {
 	if len(content) < len(pattern) {
 		return false
 	}
@@ -487,15 +733,12 @@ func matchSequenceSimilar(content []string, pattern []string) bool {
 	totalSim := 0.0
 	for i, p := range pattern {
 		sim := lineSimilarity(content[i], p)
-		if sim < similarityThresholdLine {
+		if sim < minLineSimilarity {
 			return false // Early exit if any line is way too different
 		}
 		totalSim += sim
 	}
-
-	// Check average similarity
-	avgSim := totalSim / float64(len(pattern))
-	return avgSim >= similarityThresholdAvg
+	return totalSim/float64(len(pattern)) >= threshold
 }
 
 // lineSimilarity computes similarity ratio between two strings (0.0 to 1.0).
@@ -577,6 +577,195 @@ line3`
 	}
 }
 
+func TestApplyHunksWhenHunkTwoOfThreeFails(t *testing.T) {
+	content := "one\ntwo\nthree\nfour\nfive"
+	hunks := []Hunk{
+		{Lines: []Line{{Type: Remove, Content: "one"}, {Type: Add, Content: "ONE"}}},
+		{Context: "func missing", Lines: []Line{{Type: Remove, Content: "three"}, {Type: Add, Content: "THREE"}}},
+		{Lines: []Line{{Type: Remove, Content: "five"}, {Type: Add, Content: "FIVE"}}},
+	}
+
+	partial := ApplyHunks(content, hunks, ApplyPartially, DefaultOptions())
+	if partial.Content != "ONE\ntwo\nthree\nfour\nFIVE" {
+		t.Errorf("partial content = %q", partial.Content)
+	}
+	if len(partial.Applied) != 2 || partial.Applied[0] != 1 || partial.Applied[1] != 3 {
+		t.Errorf("applied = %v, want [1 3]", partial.Applied)
+	}
+	if len(partial.Failed) != 1 {
+		t.Fatalf("failed = %v, want one failure", partial.Failed)
+	}
+	if f := partial.Failed[0]; f.Hunk != 2 || f.Reason != FailureContextNotFound {
+		t.Errorf("failure = %+v, want hunk 2 with context not found", f)
+	}
+
+	atomic := ApplyHunks(content, hunks, ApplyAtomically, DefaultOptions())
+	if atomic.Content != content || len(atomic.Applied) != 0 || len(atomic.Failed) != 1 {
+		t.Errorf("atomic result = %+v, want the original content and one failure", atomic)
+	}
+	if _, err := Apply(content, hunks); err == nil || err.Error() != partial.Failed[0].Error() {
+		t.Errorf("Apply error = %v, want %v", err, partial.Failed[0])
+	}
+}
+
+func TestApplyHunksFailureReasons(t *testing.T) {
+	content := "func A() {\n\tx := 1\n\treturn x\n"
+	tests := []struct {
+		name string
+		hunk Hunk
+		want FailureReason
+	}{
+		{name: "no match", hunk: Hunk{Lines: []Line{{Type: Remove, Content: "nope"}}}, want: FailureNoMatch},
+		{name: "context", hunk: Hunk{Context: "func Missing", Lines: []Line{{Type: Add, Content: "x"}}}, want: FailureContextNotFound},
+		{name: "elision start", hunk: Hunk{Lines: []Line{{Type: Remove, Content: "func B() {"}, {Type: Elision}, {Type: Remove, Content: "}"}}}, want: FailureAnchorNotFound},
+		{name: "brace matching", hunk: Hunk{Lines: []Line{{Type: Remove, Content: "func A() {"}, {Type: Elision}, {Type: Remove, Content: "}"}}}, want: FailureBraceMatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ApplyHunks(content, []Hunk{tt.hunk}, ApplyPartially, DefaultOptions())
+			if len(result.Failed) != 1 || result.Failed[0].Reason != tt.want {
+				t.Fatalf("failed = %+v, want reason %q", result.Failed, tt.want)
+			}
+		})
+	}
+}
+
+// === Fuzzy Matching Tests ===
+
+func TestApplyHunksFuzzyTiers(t *testing.T) {
+	content := "func Total(items []int) int {\n\ttotal := 0 \n\t// add up every item\n\tfor _, n := range items {\n\t\ttotal += n\n\t}\n\treturn total\n}"
+	tests := []struct {
+		name     string
+		lines    []Line
+		opts     Options
+		wantTier MatchTier
+		want     FailureReason
+	}{
+		{
+			name: "trailing whitespace drift",
+			lines: []Line{
+				{Type: Context, Content: "\ttotal := 0"},
+				{Type: Remove, Content: "\t// add up every item"},
+				{Type: Add, Content: "\t// sum the items"},
+			},
+			opts:     DefaultOptions(),
+			wantTier: MatchWhitespace,
+		},
+		{
+			name: "reworded comment",
+			lines: []Line{
+				{Type: Context, Content: "\t// adds up every item"},
+				{Type: Remove, Content: "\tfor _, n := range items {"},
+				{Type: Add, Content: "\tfor _, n := range slices.Values(items) {"},
+			},
+			opts:     DefaultOptions(),
+			wantTier: MatchSimilar,
+		},
+		{
+			name: "one edited line among exact ones",
+			lines: []Line{
+				{Type: Context, Content: "\t// add every item"},
+				{Type: Remove, Content: "\tfor _, n := range items {"},
+				{Type: Add, Content: "\tfor _, n := range slices.Values(items) {"},
+			},
+			opts:     DefaultOptions(),
+			wantTier: MatchSimilar,
+		},
+		{
+			name: "one edited line with a stricter threshold",
+			lines: []Line{
+				{Type: Context, Content: "\t// add every item"},
+				{Type: Remove, Content: "\tfor _, n := range items {"},
+			},
+			opts: Options{MaxTier: MatchSimilar, Similarity: 0.95},
+			want: FailureNoMatch,
+		},
+		{
+			name: "renamed variable in a context line",
+			lines: []Line{
+				{Type: Context, Content: "\tsum := 0"},
+				{Type: Remove, Content: "\t// add up every item"},
+			},
+			opts: DefaultOptions(),
+			want: FailureNoMatch,
+		},
+		{
+			name: "whitespace drift with fuzzy matching disabled",
+			lines: []Line{
+				{Type: Remove, Content: "\ttotal := 0"},
+				{Type: Add, Content: "\tvar total int"},
+			},
+			opts: Options{MaxTier: MatchExact},
+			want: FailureNoMatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ApplyHunks(content, []Hunk{{Lines: tt.lines}}, ApplyPartially, tt.opts)
+			if tt.want != "" {
+				if len(result.Failed) != 1 || result.Failed[0].Reason != tt.want {
+					t.Fatalf("failed = %+v, want reason %q", result.Failed, tt.want)
+				}
+				return
+			}
+			if len(result.Failed) != 0 {
+				t.Fatalf("unexpected failure: %v", result.Failed[0])
+			}
+			if len(result.Fuzzy) != 1 || result.Fuzzy[0] != (FuzzyMatch{Hunk: 1, Tier: tt.wantTier}) {
+				t.Fatalf("fuzzy = %+v, want hunk 1 at tier %s", result.Fuzzy, tt.wantTier)
+			}
+		})
+	}
+}
+
+func TestApplyHunksExactMatchIsNotReportedAsFuzzy(t *testing.T) {
+	result := ApplyHunks("a\nb", []Hunk{{Lines: []Line{{Type: Remove, Content: "b"}, {Type: Add, Content: "c"}}}}, ApplyPartially, DefaultOptions())
+	if result.Content != "a\nc" || len(result.Fuzzy) != 0 {
+		t.Fatalf("result = %+v, want an exact match", result)
+	}
+}
+
+func TestApplyHunksRejectsAmbiguousFuzzyMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		lines   []Line
+	}{
+		{
+			name:    "whitespace",
+			content: "func A() {\n    reset()\n}\n\nfunc B() {\n\treset()\n}",
+			lines:   []Line{{Type: Remove, Content: "  reset()"}, {Type: Add, Content: "  clear()"}},
+		},
+		{
+			name:    "similar",
+			content: "// load the config file\nload(a)\n// load the config fils\nload(b)",
+			lines:   []Line{{Type: Remove, Content: "// load the config fil"}, {Type: Add, Content: "// read the config"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ApplyHunks(tt.content, []Hunk{{Lines: tt.lines}}, ApplyPartially, DefaultOptions())
+			if len(result.Failed) != 1 || result.Failed[0].Reason != FailureAmbiguous {
+				t.Fatalf("failed = %+v, want an ambiguous match", result.Failed)
+			}
+			if result.Content != tt.content {
+				t.Fatalf("content changed to %q", result.Content)
+			}
+		})
+	}
+}
+
+func TestParseMatchTier(t *testing.T) {
+	for name, want := range map[string]MatchTier{"": MatchSimilar, "exact": MatchExact, "Whitespace": MatchWhitespace, "similar": MatchSimilar} {
+		if got, err := ParseMatchTier(name); err != nil || got != want {
+			t.Errorf("ParseMatchTier(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseMatchTier("loose"); err == nil {
+		t.Error("ParseMatchTier(loose) should fail")
+	}
+}
+
 // === ApplyFileDiffs Tests ===
 
 func TestApplyFileDiffs(t *testing.T) {
@@ -601,11 +790,11 @@ func TestApplyFileDiffs(t *testing.T) {
 		t.Fatalf("ApplyFileDiffs failed: %v", err)
 	}
 
-	if result["a.go"] != "func A() { new() }" {
-		t.Errorf("a.go not updated: %s", result["a.go"])
+	if result.Files["a.go"] != "func A() { new() }" {
+		t.Errorf("a.go not updated: %s", result.Files["a.go"])
 	}
-	if result["b.go"] != "func B() { keep() }" {
-		t.Errorf("b.go should be unchanged: %s", result["b.go"])
+	if result.Files["b.go"] != "func B() { keep() }" {
+		t.Errorf("b.go should be unchanged: %s", result.Files["b.go"])
 	}
 }
 
@@ -625,6 +814,106 @@ func TestApplyFileDiffsFileNotFound(t *testing.T) {
 	}
 }
 
+func TestApplyFileDiffsCreatesFile(t *testing.T) {
+	diffs, err := Parse("--- /dev/null\n+++ b/hello.go\n@@ -0,0 +1,3 @@\n+package hello\n\n+func Hello() {}\n")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	result, err := ApplyFileDiffs(map[string]string{"other.go": "x"}, diffs)
+	if err != nil {
+		t.Fatalf("ApplyFileDiffs: %v", err)
+	}
+	if got, want := result.Files["hello.go"], "package hello\n\nfunc Hello() {}\n"; got != want {
+		t.Errorf("created content = %q, want %q", got, want)
+	}
+	if len(result.Created) != 1 || result.Created[0] != "hello.go" || len(result.Deleted) != 0 {
+		t.Errorf("created = %v, deleted = %v", result.Created, result.Deleted)
+	}
+
+	if _, err := ApplyFileDiffs(map[string]string{"hello.go": "old"}, diffs); err == nil {
+		t.Error("expected an error creating a file that already exists")
+	}
+
+	bad := []FileDiff{{Path: "bad.go", OldPath: DevNull, NewPath: "bad.go", Hunks: []Hunk{{Lines: []Line{{Type: Remove, Content: "x"}}}}}}
+	if _, err := ApplyFileDiffs(nil, bad); err == nil {
+		t.Error("expected an error for a new file with removed lines")
+	}
+}
+
+func TestApplyFileDiffsDeletesFile(t *testing.T) {
+	files := map[string]string{"old.go": "package old\n\nfunc Old() {}\n", "keep.go": "k"}
+
+	tests := []struct {
+		name    string
+		diff    string
+		wantErr bool
+	}{
+		{name: "full content", diff: "--- a/old.go\n+++ /dev/null\n@@ -1,3 +0,0 @@\n-package old\n-\n-func Old() {}\n"},
+		{name: "no hunks", diff: "--- a/old.go\n+++ /dev/null\n"},
+		{name: "partial content", diff: "--- a/old.go\n+++ /dev/null\n@@\n-package old\n", wantErr: true},
+		{name: "missing file", diff: "--- a/gone.go\n+++ /dev/null\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs, err := Parse(tt.diff)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			result, err := ApplyFileDiffs(files, diffs)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyFileDiffs: %v", err)
+			}
+			if _, ok := result.Files["old.go"]; ok {
+				t.Error("old.go should be gone from the result")
+			}
+			if len(result.Deleted) != 1 || result.Deleted[0] != "old.go" {
+				t.Errorf("deleted = %v, want [old.go]", result.Deleted)
+			}
+			if result.Files["keep.go"] != "k" || files["old.go"] == "" {
+				t.Error("other files and the input map must be left alone")
+			}
+		})
+	}
+}
+
+func TestApplyFileDiffsRenameAsDeleteAndCreate(t *testing.T) {
+	diff := `--- a/old_name.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-package demo
+-func Name() {}
+--- /dev/null
++++ b/new_name.go
+@@ -0,0 +1,2 @@
++package demo
++func Name() {}
+`
+	diffs, err := Parse(diff)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	result, err := ApplyFileDiffs(map[string]string{"old_name.go": "package demo\nfunc Name() {}\n"}, diffs)
+	if err != nil {
+		t.Fatalf("ApplyFileDiffs: %v", err)
+	}
+	if _, ok := result.Files["old_name.go"]; ok {
+		t.Error("old_name.go should be deleted")
+	}
+	if got := result.Files["new_name.go"]; got != "package demo\nfunc Name() {}\n" {
+		t.Errorf("new_name.go = %q", got)
+	}
+	if len(result.Deleted) != 1 || result.Deleted[0] != "old_name.go" || len(result.Created) != 1 || result.Created[0] != "new_name.go" {
+		t.Errorf("created = %v, deleted = %v", result.Created, result.Deleted)
+	}
+}
+
 // === Brace Depth Tracking Tests ===
 
 func TestUpdateBraceDepth(t *testing.T) {
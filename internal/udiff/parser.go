@@ -38,10 +38,26 @@ type Hunk struct {
 	Lines   []Line // All lines in order
 }
 
+// DevNull is the header path that marks a created (---) or deleted (+++)
+// file.
+const DevNull = "/dev/null"
+
 // FileDiff represents all changes to a single file.
 type FileDiff struct {
-	Path  string // File path from --- and +++ headers
-	Hunks []Hunk
+	Path    string // The file being changed: the new path, or the old one for deletions
+	OldPath string // Path from the --- header, DevNull for a new file
+	NewPath string // Path from the +++ header, DevNull for a deleted file
+	Hunks   []Hunk
+}
+
+// IsCreate reports whether the diff creates Path.
+func (d FileDiff) IsCreate() bool {
+	return d.OldPath == DevNull && d.NewPath != DevNull
+}
+
+// IsDelete reports whether the diff deletes Path.
+func (d FileDiff) IsDelete() bool {
+	return d.NewPath == DevNull && d.OldPath != DevNull
 }
 
 // Parse parses a unified diff string into a slice of FileDiff.
@@ -55,7 +71,8 @@ type FileDiff struct {
 //	+added line
 //	-...
 //
-// Multiple files can be included in a single diff.
+// Multiple files can be included in a single diff. A --- /dev/null header
+// creates the +++ path, and +++ /dev/null deletes the --- path.
 func Parse(diff string) ([]FileDiff, error) {
 	lines := strings.Split(diff, "\n")
 	var result []FileDiff
@@ -92,7 +109,7 @@ func Parse(diff string) ([]FileDiff, error) {
 			// Handle a/path and b/path prefixes from git diff
 			path = strings.TrimPrefix(path, "a/")
 
-			currentFile = &FileDiff{Path: path}
+			currentFile = &FileDiff{Path: path, OldPath: path, NewPath: path}
 			currentHunk = nil
 			i++
 
@@ -103,14 +120,13 @@ func Parse(diff string) ([]FileDiff, error) {
 
 			// Expect +++ line next
 			if i < len(lines) && strings.HasPrefix(lines[i], "+++ ") {
-				// Verify paths match (ignoring a/ b/ prefixes)
 				plusPath := strings.TrimPrefix(lines[i], "+++ ")
 				plusPath = strings.TrimPrefix(plusPath, "b/")
-				if plusPath != path {
-					// Use the +++ path if different (some diffs have /dev/null for new files)
-					if path == "/dev/null" {
-						currentFile.Path = plusPath
-					}
+				currentFile.NewPath = plusPath
+				// A new file is named by the +++ header; otherwise keep the
+				// --- path, which is also the one a deletion removes.
+				if path == DevNull {
+					currentFile.Path = plusPath
 				}
 				i++
 			}
@@ -252,3 +268,26 @@ func trimTrailingEmptyLines(h *Hunk) {
 		}
 	}
 }
+
+// FormatHunk renders a hunk back into diff lines, starting with its @@
+// header.
+func FormatHunk(h Hunk) []string {
+	header := "@@"
+	if h.Context != "" {
+		header = "@@ " + h.Context + " @@"
+	}
+	lines := []string{header}
+	for _, line := range h.Lines {
+		switch line.Type {
+		case Remove:
+			lines = append(lines, "-"+line.Content)
+		case Add:
+			lines = append(lines, "+"+line.Content)
+		case Elision:
+			lines = append(lines, "-...")
+		default:
+			lines = append(lines, " "+line.Content)
+		}
+	}
+	return lines
+}
@@ -1,6 +1,7 @@
 package udiff
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -404,3 +405,47 @@ func TestParseComplexDiff(t *testing.T) {
 		t.Fatalf("expected 1 hunk in second file, got %d", len(files[1].Hunks))
 	}
 }
+
+func TestParseRecordsOldAndNewPaths(t *testing.T) {
+	tests := []struct {
+		name       string
+		diff       string
+		path       string
+		oldPath    string
+		newPath    string
+		create     bool
+		deleteFile bool
+	}{
+		{name: "modify", diff: "--- a/x.go\n+++ b/x.go\n@@\n-a\n+b\n", path: "x.go", oldPath: "x.go", newPath: "x.go"},
+		{name: "create", diff: "--- /dev/null\n+++ b/x.go\n@@\n+a\n", path: "x.go", oldPath: DevNull, newPath: "x.go", create: true},
+		{name: "delete", diff: "--- a/x.go\n+++ /dev/null\n@@\n-a\n", path: "x.go", oldPath: "x.go", newPath: DevNull, deleteFile: true},
+		{name: "no plus header", diff: "--- x.go\n@@\n-a\n+b\n", path: "x.go", oldPath: "x.go", newPath: "x.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs, err := Parse(tt.diff)
+			if err != nil || len(diffs) != 1 {
+				t.Fatalf("Parse = %v, %v", diffs, err)
+			}
+			d := diffs[0]
+			if d.Path != tt.path || d.OldPath != tt.oldPath || d.NewPath != tt.newPath {
+				t.Errorf("paths = %q %q %q, want %q %q %q", d.Path, d.OldPath, d.NewPath, tt.path, tt.oldPath, tt.newPath)
+			}
+			if d.IsCreate() != tt.create || d.IsDelete() != tt.deleteFile {
+				t.Errorf("IsCreate = %v, IsDelete = %v", d.IsCreate(), d.IsDelete())
+			}
+		})
+	}
+}
+
+func TestFormatHunkRoundTrips(t *testing.T) {
+	diff := "--- a.go\n+++ a.go\n@@ func A @@\n keep\n-old\n-...\n-}\n+new\n"
+	diffs, err := Parse(diff)
+	if err != nil || len(diffs) != 1 || len(diffs[0].Hunks) != 1 {
+		t.Fatalf("Parse = %+v, %v", diffs, err)
+	}
+	want := []string{"@@ func A @@", " keep", "-old", "-...", "-}", "+new"}
+	if got := FormatHunk(diffs[0].Hunks[0]); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("FormatHunk = %q, want %q", got, want)
+	}
+}
@@ -2,6 +2,7 @@ package ansisafe
 
 import (
 	"strings"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/x/ansi"
 )
@@ -112,3 +113,105 @@ func ApplyPartialReverseVideo(line string, startCol, endCol int) string {
 
 	return b.String()
 }
+
+// HighlightSpan is a visual column range [Start, End) to paint with the
+// background SGR sequence Bg.
+type HighlightSpan struct {
+	Start int
+	End   int
+	Bg    string
+}
+
+// ApplyHighlights paints spans (sorted by Start, non-overlapping) onto line in
+// a single pass. Inside a span the background is re-asserted after every SGR
+// sequence; after it, the line's own SGR state since the last reset is
+// replayed so styling such as a code block background continues unchanged.
+// Columns are measured in visible (cell-width) units.
+func ApplyHighlights(line string, spans []HighlightSpan) string {
+	if line == "" || len(spans) == 0 {
+		return line
+	}
+
+	var b strings.Builder
+	b.Grow(len(line) + len(spans)*48)
+	var active []string // SGR sequences in effect since the last reset
+	next := 0           // index of the next span to open
+	open := false
+	col := 0
+
+	closeSpan := func() {
+		b.WriteString(selBgOff)
+		for _, seq := range active {
+			b.WriteString(seq)
+		}
+		open = false
+		next++
+	}
+
+	for i := 0; i < len(line); {
+		if line[i] == 0x1B && i+1 < len(line) {
+			j := escapeEnd(line, i)
+			seq := line[i:j]
+			b.WriteString(seq)
+			if line[i+1] == '[' && seq[len(seq)-1] == 'm' {
+				if params := seq[2 : len(seq)-1]; params == "" || params == "0" {
+					active = active[:0]
+				} else {
+					active = append(active, seq)
+				}
+				if open {
+					b.WriteString(spans[next].Bg)
+				}
+			}
+			i = j
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(line[i:])
+		for !open && next < len(spans) && spans[next].End <= col {
+			next++
+		}
+		if !open && next < len(spans) && spans[next].Start <= col {
+			b.WriteString(spans[next].Bg)
+			open = true
+		}
+		b.WriteString(line[i : i+size])
+		col += ansi.StringWidth(string(r))
+		i += size
+		if open && col >= spans[next].End {
+			closeSpan()
+		}
+	}
+	if open {
+		b.WriteString(selBgOff)
+	}
+	return b.String()
+}
+
+// escapeEnd returns the index just past the escape sequence starting at i:
+// a CSI sequence, an OSC string ended by BEL or ST, or a two-byte escape.
+func escapeEnd(s string, i int) int {
+	switch s[i+1] {
+	case '[':
+		j := i + 2
+		for j < len(s) && (s[j] < 0x40 || s[j] > 0x7E) {
+			j++
+		}
+		if j < len(s) {
+			j++
+		}
+		return j
+	case ']':
+		for j := i + 2; j < len(s); j++ {
+			if s[j] == 0x07 {
+				return j + 1
+			}
+			if s[j] == 0x1B && j+1 < len(s) && s[j+1] == '\\' {
+				return j + 2
+			}
+		}
+		return len(s)
+	default:
+		return i + 2
+	}
+}
@@ -153,3 +153,55 @@ func TestInjectSelectionBg(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyHighlights(t *testing.T) {
+	const bg = "\033[43m"
+	tests := []struct {
+		name  string
+		line  string
+		spans []HighlightSpan
+		want  string
+	}{
+		{
+			name:  "plain",
+			line:  "foo bar foo",
+			spans: []HighlightSpan{{0, 3, bg}, {8, 11, bg}},
+			want:  bg + "foo" + selBgOff + " bar " + bg + "foo" + selBgOff,
+		},
+		{
+			name:  "restores styling after span",
+			line:  "\033[48;5;236m\033[31mfoo bar\033[0m",
+			spans: []HighlightSpan{{0, 3, bg}},
+			want:  "\033[48;5;236m\033[31m" + bg + "foo" + selBgOff + "\033[48;5;236m\033[31m bar\033[0m",
+		},
+		{
+			name:  "reasserts inside span",
+			line:  "f\033[0moo",
+			spans: []HighlightSpan{{0, 3, bg}},
+			want:  bg + "f\033[0m" + bg + "oo" + selBgOff,
+		},
+		{
+			name:  "osc hyperlink passes through",
+			line:  "\033]8;;https://x.dev\033\\link\033]8;;\033\\",
+			spans: []HighlightSpan{{0, 4, bg}},
+			want:  "\033]8;;https://x.dev\033\\" + bg + "link" + selBgOff + "\033]8;;\033\\",
+		},
+		{
+			name:  "wide runes",
+			line:  "日本go",
+			spans: []HighlightSpan{{4, 6, bg}},
+			want:  "日本" + bg + "go" + selBgOff,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ApplyHighlights(tt.line, tt.spans)
+			if got != tt.want {
+				t.Errorf("ApplyHighlights() = %q, want %q", got, tt.want)
+			}
+			if ansi.Strip(got) != ansi.Strip(tt.line) {
+				t.Errorf("visible text changed: %q", ansi.Strip(got))
+			}
+		})
+	}
+}
@@ -228,6 +228,11 @@ func ansiDisplayWidth(s string, startCol int) int {
 
 	for i := 0; i < len(s); {
 		b := s[i]
+		if b == '\x1b' && i+1 < len(s) && s[i+1] == ']' {
+			// OSC (e.g. an OSC 8 hyperlink) runs to BEL or ESC \.
+			i = escapeSequenceEnd(s, i)
+			continue
+		}
 		if b == '\x1b' {
 			inEscape = true
 			i++
@@ -258,8 +263,9 @@ func ansiDisplayWidth(s string, startCol int) int {
 	return col - startCol
 }
 
-// ANSI escape code pattern for stripping/measuring
-var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+// ANSI escape code pattern for stripping/measuring: SGR sequences and OSC
+// sequences such as OSC 8 hyperlinks.
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*m|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)`)
 
 // StripANSI removes all ANSI escape codes from a string
 func StripANSI(s string) string {
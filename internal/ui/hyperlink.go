@@ -0,0 +1,382 @@
+package ui
+
+import (
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Hyperlink modes for the theme.hyperlinks setting.
+const (
+	HyperlinksAuto = "auto"
+	HyperlinksOn   = "on"
+	HyperlinksOff  = "off"
+)
+
+// hyperlinksEnv overrides theme.hyperlinks, mostly for terminals the
+// heuristics get wrong.
+const hyperlinksEnv = "TERM_LLM_HYPERLINKS"
+
+var hyperlinkEnabled atomic.Bool
+
+func init() {
+	SetHyperlinkMode(HyperlinksAuto)
+}
+
+// SetHyperlinkMode sets whether rendered output wraps URLs and file paths in
+// OSC 8 hyperlinks: "on", "off", or "auto" to detect terminal support (and
+// stay off when stdout is not a terminal). The TERM_LLM_HYPERLINKS
+// environment variable takes precedence.
+func SetHyperlinkMode(mode string) {
+	if env := strings.TrimSpace(os.Getenv(hyperlinksEnv)); env != "" {
+		if strings.EqualFold(env, HyperlinksAuto) {
+			mode = HyperlinksAuto
+		} else if ParseBoolDefault(env, true) {
+			mode = HyperlinksOn
+		} else {
+			mode = HyperlinksOff
+		}
+	}
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case HyperlinksOn:
+		hyperlinkEnabled.Store(true)
+	case HyperlinksOff:
+		hyperlinkEnabled.Store(false)
+	default:
+		hyperlinkEnabled.Store(term.IsTerminal(int(os.Stdout.Fd())) && terminalSupportsHyperlinks(os.Getenv))
+	}
+}
+
+// HyperlinksEnabled reports whether Linkify adds OSC 8 sequences.
+func HyperlinksEnabled() bool {
+	return hyperlinkEnabled.Load()
+}
+
+// terminalSupportsHyperlinks guesses OSC 8 support from the environment.
+// Terminals that don't understand OSC 8 generally swallow it, but a few older
+// ones print the URL, so unknown terminals stay off.
+func terminalSupportsHyperlinks(getenv func(string) string) bool {
+	if getenv("NO_COLOR") != "" || getenv("TERM") == "dumb" {
+		return false
+	}
+	// tmux and screen only pass OSC 8 through when configured to.
+	if getenv("TMUX") != "" || strings.HasPrefix(getenv("TERM"), "screen") {
+		return false
+	}
+	switch getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "ghostty", "Hyper", "Tabby", "rio":
+		return true
+	}
+	if getenv("WT_SESSION") != "" || getenv("KITTY_WINDOW_ID") != "" ||
+		getenv("KONSOLE_VERSION") != "" || getenv("WEZTERM_EXECUTABLE") != "" {
+		return true
+	}
+	// GNOME Terminal and other VTE terminals since 0.50.
+	if vte, err := strconv.Atoi(getenv("VTE_VERSION")); err == nil && vte >= 5000 {
+		return true
+	}
+	termName := getenv("TERM")
+	for _, name := range []string{"kitty", "alacritty", "foot", "ghostty", "wezterm"} {
+		if strings.Contains(termName, name) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// linkURLPattern matches http(s) URLs; trailing punctuation is trimmed
+	// separately so sentences ending in a link still work.
+	linkURLPattern = regexp.MustCompile(`https?://[^\s<>"'` + "`" + `]+`)
+	// linkPathPattern matches absolute paths that start a word or follow an
+	// opening bracket or quote. Whether the file exists is checked later.
+	linkPathPattern = regexp.MustCompile(`(?:^|[\s(\[{"'` + "`" + `])(/[^\s:<>"'` + "`" + `()\[\]{}]+)`)
+)
+
+// Linkify wraps http(s) URLs and absolute paths of existing files in OSC 8
+// hyperlinks when HyperlinksEnabled. Escape sequences already in s are left
+// alone and never end up inside a link, so styled text keeps its styling and
+// width calculations that skip escape sequences are unaffected.
+func Linkify(s string) string {
+	return LinkifyWrapped(s, "")
+}
+
+// LinkifyWrapped is Linkify for text wrapped from source. A URL from source
+// that wrapping split across lines is linked to its full target on every
+// line it spans, rather than to the fragment on each line.
+func LinkifyWrapped(s, source string) string {
+	if !HyperlinksEnabled() {
+		return s
+	}
+	state := &linkState{exists: cachedFileExists}
+	if source != "" {
+		for _, m := range linkURLPattern.FindAllString(source, -1) {
+			state.urls = append(state.urls, trimURLPunctuation(m))
+		}
+	}
+	return linkify(s, state)
+}
+
+// linkState carries what linkify needs across the escape sequences and line
+// breaks of one text: the full URLs of the source and the rest of a URL
+// whose first part ended a wrapped line.
+type linkState struct {
+	exists  func(string) bool
+	urls    []string
+	target  string
+	pending string
+}
+
+// wrappedURL returns the source URL that fragment, ending a line, is the
+// start of.
+func (st *linkState) wrappedURL(fragment string) (string, bool) {
+	for _, u := range st.urls {
+		if len(u) > len(fragment) && strings.HasPrefix(u, fragment) {
+			return u, true
+		}
+	}
+	return "", false
+}
+
+func linkify(s string, state *linkState) string {
+	if !strings.Contains(s, "://") && !strings.Contains(s, "/") {
+		return s
+	}
+	var b strings.Builder
+	changed := false
+	for i := 0; i < len(s); {
+		if s[i] == '\x1b' {
+			end := escapeSequenceEnd(s, i)
+			if strings.HasPrefix(s[i:], "\x1b]8;") {
+				// Already linked: copy the whole link untouched.
+				if close := strings.Index(s[end:], "\x1b]8;;"); close >= 0 {
+					end = escapeSequenceEnd(s, end+close)
+				}
+			}
+			b.WriteString(s[i:end])
+			i = end
+			continue
+		}
+		next := strings.IndexByte(s[i:], '\x1b')
+		if next < 0 {
+			next = len(s)
+		} else {
+			next += i
+		}
+		text := linkifyText(s[i:next], state)
+		if text != s[i:next] {
+			changed = true
+		}
+		b.WriteString(text)
+		i = next
+	}
+	if !changed {
+		return s
+	}
+	return b.String()
+}
+
+// linkSpan is one run of text to wrap in a hyperlink.
+type linkSpan struct {
+	start, end int
+	target     string
+}
+
+// linkifyText links URLs and paths in text containing no escape sequences.
+func linkifyText(text string, state *linkState) string {
+	var spans []linkSpan
+	for lineStart := 0; lineStart < len(text); {
+		lineEnd := strings.IndexByte(text[lineStart:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(text)
+		} else {
+			lineEnd += lineStart
+		}
+		spans = linkifyLine(text, lineStart, lineEnd, state, spans)
+		lineStart = lineEnd + 1
+	}
+	for _, m := range linkPathPattern.FindAllStringSubmatchIndex(text, -1) {
+		start := m[2]
+		path := strings.TrimRight(text[start:m[3]], ".,;!?")
+		if insideLinkSpan(spans, start) || len(path) < 2 || !state.exists(path) {
+			continue
+		}
+		spans = append(spans, linkSpan{start, start + len(path), fileURL(path)})
+	}
+	if len(spans) == 0 {
+		return text
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var b strings.Builder
+	last := 0
+	for _, sp := range spans {
+		b.WriteString(text[last:sp.start])
+		b.WriteString(hyperlinkOpen(sp.target))
+		b.WriteString(text[sp.start:sp.end])
+		b.WriteString(hyperlinkClose)
+		last = sp.end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// linkifyLine adds the URL spans of text[start:end], one line or the part of
+// one between escape sequences, continuing a URL wrapped from the line before.
+func linkifyLine(text string, start, end int, state *linkState, spans []linkSpan) []linkSpan {
+	if state.pending != "" {
+		// The rest of a wrapped URL starts the next line after its indent.
+		pos := start + len(text[start:end]) - len(strings.TrimLeft(text[start:end], " \t"))
+		if pos == end {
+			return spans
+		}
+		n := 0
+		for n < len(state.pending) && pos+n < end && text[pos+n] == state.pending[n] {
+			n++
+		}
+		if n > 0 {
+			spans = append(spans, linkSpan{pos, pos + n, state.target})
+			start = pos + n
+		}
+		state.pending = state.pending[n:]
+		if n == 0 || start < end {
+			state.pending = ""
+		}
+	}
+	for _, m := range linkURLPattern.FindAllStringIndex(text[start:end], -1) {
+		m0, m1 := start+m[0], start+m[1]
+		raw := text[m0:m1]
+		if m1 == end {
+			if full, ok := state.wrappedURL(raw); ok {
+				spans = append(spans, linkSpan{m0, m1, full})
+				state.target, state.pending = full, full[len(raw):]
+				continue
+			}
+		}
+		if e := m0 + len(trimURLPunctuation(raw)); e > m0+len("https://") {
+			spans = append(spans, linkSpan{m0, e, text[m0:e]})
+		}
+	}
+	return spans
+}
+
+func insideLinkSpan(spans []linkSpan, pos int) bool {
+	for _, sp := range spans {
+		if pos >= sp.start && pos < sp.end {
+			return true
+		}
+	}
+	return false
+}
+
+// trimURLPunctuation drops sentence punctuation after a URL, keeping a
+// closing paren that balances one inside the URL (as in Wikipedia links).
+func trimURLPunctuation(u string) string {
+	for len(u) > 0 {
+		last := u[len(u)-1]
+		switch last {
+		case '.', ',', ';', ':', '!', '?', '*', '_', ']', '}':
+			u = u[:len(u)-1]
+		case ')':
+			if strings.Count(u, "(") >= strings.Count(u, ")") {
+				return u
+			}
+			u = u[:len(u)-1]
+		default:
+			return u
+		}
+	}
+	return u
+}
+
+const hyperlinkClose = "\x1b]8;;\x1b\\"
+
+func hyperlinkOpen(target string) string {
+	return "\x1b]8;;" + target + "\x1b\\"
+}
+
+var (
+	linkHostOnce sync.Once
+	linkHost     string
+)
+
+// fileURL builds a file:// URL naming this host, so terminals can tell local
+// files from ones on the other end of an ssh session.
+func fileURL(path string) string {
+	linkHostOnce.Do(func() {
+		linkHost, _ = os.Hostname()
+	})
+	return (&url.URL{Scheme: "file", Host: linkHost, Path: path}).String()
+}
+
+// fileExistsTTL bounds how long a cached stat result is trusted: streaming
+// re-renders the same paths many times a second, but a file the model just
+// wrote should still get linked.
+const fileExistsTTL = 5 * time.Second
+
+type fileExistsEntry struct {
+	exists bool
+	at     time.Time
+}
+
+var (
+	fileExistsMu    sync.Mutex
+	fileExistsCache = make(map[string]fileExistsEntry)
+)
+
+// cachedFileExists reports whether path exists, reusing recent answers.
+func cachedFileExists(path string) bool {
+	now := time.Now()
+	fileExistsMu.Lock()
+	entry, ok := fileExistsCache[path]
+	fileExistsMu.Unlock()
+	if ok && now.Sub(entry.at) < fileExistsTTL {
+		return entry.exists
+	}
+	_, err := os.Stat(path)
+	exists := err == nil
+	fileExistsMu.Lock()
+	if len(fileExistsCache) >= 4096 {
+		clear(fileExistsCache)
+	}
+	fileExistsCache[path] = fileExistsEntry{exists: exists, at: now}
+	fileExistsMu.Unlock()
+	return exists
+}
+
+// escapeSequenceEnd returns the index just past the escape sequence at
+// s[i]: CSI sequences end at a final byte, OSC sequences at BEL or ESC \.
+func escapeSequenceEnd(s string, i int) int {
+	if i+1 >= len(s) {
+		return len(s)
+	}
+	switch s[i+1] {
+	case '[':
+		for j := i + 2; j < len(s); j++ {
+			if s[j] >= 0x40 && s[j] <= 0x7e {
+				return j + 1
+			}
+		}
+		return len(s)
+	case ']':
+		for j := i + 2; j < len(s); j++ {
+			if s[j] == '\x07' {
+				return j + 1
+			}
+			if s[j] == '\x1b' && j+1 < len(s) && s[j+1] == '\\' {
+				return j + 2
+			}
+		}
+		return len(s)
+	default:
+		return i + 2
+	}
+}
@@ -0,0 +1,160 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func withHyperlinks(t *testing.T, mode string) {
+	t.Helper()
+	t.Setenv(hyperlinksEnv, "")
+	SetHyperlinkMode(mode)
+	t.Cleanup(func() { SetHyperlinkMode(HyperlinksAuto) })
+}
+
+func TestLinkifyURLsByteExact(t *testing.T) {
+	withHyperlinks(t, HyperlinksOn)
+	in := "See https://example.com/docs?q=1. Or (https://en.wikipedia.org/wiki/Go_(language))."
+	want := "See \x1b]8;;https://example.com/docs?q=1\x1b\\https://example.com/docs?q=1\x1b]8;;\x1b\\. " +
+		"Or (\x1b]8;;https://en.wikipedia.org/wiki/Go_(language)\x1b\\https://en.wikipedia.org/wiki/Go_(language)\x1b]8;;\x1b\\)."
+	if got := Linkify(in); got != want {
+		t.Errorf("Linkify() =\n%q\nwant\n%q", got, want)
+	}
+	if got := StripANSI(Linkify(in)); got != in {
+		t.Errorf("StripANSI(Linkify()) = %q, want %q", got, in)
+	}
+}
+
+func TestLinkifyDisabledIsIdentity(t *testing.T) {
+	withHyperlinks(t, HyperlinksOff)
+	in := "\x1b[36mhttps://example.com\x1b[0m and /etc"
+	if got := Linkify(in); got != in {
+		t.Errorf("Linkify() with links off = %q, want input unchanged", got)
+	}
+}
+
+func TestLinkifyKeepsStylingOutsideLinks(t *testing.T) {
+	withHyperlinks(t, HyperlinksOn)
+	in := "Docs: \x1b[4;36mhttps://example.com\x1b[0m"
+	want := "Docs: \x1b[4;36m\x1b]8;;https://example.com\x1b\\https://example.com\x1b]8;;\x1b\\\x1b[0m"
+	if got := Linkify(in); got != want {
+		t.Errorf("Linkify() =\n%q\nwant\n%q", got, want)
+	}
+	if ANSILen(Linkify(in)) != ANSILen(in) {
+		t.Errorf("ANSILen changed: %d vs %d", ANSILen(Linkify(in)), ANSILen(in))
+	}
+	// Already linked text is not linked twice.
+	if got := Linkify(want); got != want {
+		t.Errorf("Linkify() on linked text = %q", got)
+	}
+}
+
+func TestLinkifyFilePathsOnlyWhenTheyExist(t *testing.T) {
+	withHyperlinks(t, HyperlinksOn)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	host, _ := os.Hostname()
+
+	in := "(" + path + ") and " + filepath.Join(dir, "missing.go") + "."
+	want := "(\x1b]8;;file://" + host + path + "\x1b\\" + path + "\x1b]8;;\x1b\\) and " + filepath.Join(dir, "missing.go") + "."
+	if got := Linkify(in); got != want {
+		t.Errorf("Linkify() =\n%q\nwant\n%q", got, want)
+	}
+	if got := StripANSI(Linkify(in)); got != in {
+		t.Errorf("StripANSI(Linkify()) = %q, want %q", got, in)
+	}
+	// A URL path is part of the URL, not a separate file link.
+	if got := Linkify("https://example.com" + path); strings.Count(got, "\x1b]8;;file") != 0 {
+		t.Errorf("URL path linked as a file: %q", got)
+	}
+}
+
+func TestRenderMarkdownLinksAfterWrapping(t *testing.T) {
+	withHyperlinks(t, HyperlinksOff)
+	plain := RenderMarkdown("Read [the docs](https://example.com/docs) first.", 80)
+
+	withHyperlinks(t, HyperlinksOn)
+	linked := RenderMarkdown("Read [the docs](https://example.com/docs) first.", 80)
+	if !strings.Contains(linked, "\x1b]8;;https://example.com/docs\x1b\\") {
+		t.Fatalf("rendered markdown has no hyperlink: %q", linked)
+	}
+	if StripANSI(linked) != StripANSI(plain) {
+		t.Errorf("visible text differs:\n%q\n%q", StripANSI(linked), StripANSI(plain))
+	}
+	if ANSILen(linked) != ANSILen(plain) {
+		t.Errorf("width differs: %d vs %d", ANSILen(linked), ANSILen(plain))
+	}
+}
+
+func TestTerminalSupportsHyperlinks(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{"iterm", map[string]string{"TERM_PROGRAM": "iTerm.app"}, true},
+		{"kitty term", map[string]string{"TERM": "xterm-kitty"}, true},
+		{"new vte", map[string]string{"VTE_VERSION": "7600"}, true},
+		{"old vte", map[string]string{"VTE_VERSION": "4803"}, false},
+		{"windows terminal", map[string]string{"WT_SESSION": "x"}, true},
+		{"tmux", map[string]string{"TERM_PROGRAM": "iTerm.app", "TMUX": "/tmp/tmux"}, false},
+		{"no color", map[string]string{"TERM_PROGRAM": "WezTerm", "NO_COLOR": "1"}, false},
+		{"plain xterm", map[string]string{"TERM": "xterm-256color"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(k string) string { return tt.env[k] }
+			if got := terminalSupportsHyperlinks(getenv); got != tt.want {
+				t.Errorf("terminalSupportsHyperlinks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHyperlinkEnvOverridesConfig(t *testing.T) {
+	t.Setenv(hyperlinksEnv, "0")
+	SetHyperlinkMode(HyperlinksOn)
+	t.Cleanup(func() { SetHyperlinkMode(HyperlinksAuto) })
+	if HyperlinksEnabled() {
+		t.Fatal("TERM_LLM_HYPERLINKS=0 should override on")
+	}
+}
+
+func TestLinkifyWrappedLinksEveryLineToTheFullURL(t *testing.T) {
+	withHyperlinks(t, HyperlinksOn)
+	full := "https://example.com/a/very/long/path/that/wraps/onto/the/next/line?with=query"
+	source := "See " + full + " for details."
+	wrapped := "See https://example.com/a/very/long/path/that/\n  wraps/onto/the/next/line?with=query for\ndetails."
+	got := LinkifyWrapped(wrapped, source)
+	if n := strings.Count(got, hyperlinkOpen(full)); n != 2 {
+		t.Fatalf("want both lines linked to the full URL, got %d links:\n%q", n, got)
+	}
+	if strings.Contains(got, hyperlinkOpen("https://example.com/a/very/long/path/that/")) {
+		t.Errorf("first line linked to the fragment: %q", got)
+	}
+	if StripANSI(got) != wrapped {
+		t.Errorf("visible text changed: %q", StripANSI(got))
+	}
+	if !strings.Contains(got, "wraps/onto/the/next/line?with=query"+hyperlinkClose+" for") {
+		t.Errorf("continuation link should end with the URL: %q", got)
+	}
+}
+
+func TestRenderMarkdownLinksWrappedURLToFullTarget(t *testing.T) {
+	withHyperlinks(t, HyperlinksOn)
+	full := "https://example.com/" + strings.Repeat("segment/", 12) + "end"
+	linked := RenderMarkdown("Read "+full+" first.", 40)
+	if strings.Count(StripANSI(linked), "\n") == 0 {
+		t.Fatalf("URL was not wrapped: %q", linked)
+	}
+	for _, line := range strings.Split(linked, "\n") {
+		if strings.Contains(line, "\x1b]8;;https://") && !strings.Contains(line, hyperlinkOpen(full)) {
+			t.Errorf("line links to a fragment instead of %s: %q", full, line)
+		}
+	}
+}
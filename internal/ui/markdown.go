@@ -89,7 +89,7 @@ func RenderMarkdownWithOptionsError(content string, width int, options MarkdownR
 		return "", nil
 	}
 
-	return rendermarkdown.RenderString(content, rendermarkdown.Config{
+	rendered, err := rendermarkdown.RenderString(content, rendermarkdown.Config{
 		Palette:            currentMarkdownPalette(),
 		Width:              width,
 		WrapOffset:         options.WrapOffset,
@@ -98,4 +98,23 @@ func RenderMarkdownWithOptionsError(content string, width int, options MarkdownR
 		TrimSpace:          true,
 		EnsureTrailingLine: options.EnsureTrailingLine,
 	})
+	if err != nil {
+		return rendered, err
+	}
+	return LinkifyWrapped(rendered, content), nil
+}
+
+// linkingRenderer adds hyperlinks to each block the streaming renderer
+// produces. Links are added after wrapping, so they never affect layout; a
+// URL wrapping split is linked to its full target from source.
+type linkingRenderer struct {
+	rendermarkdown.Renderer
+}
+
+func (r linkingRenderer) Render(source []byte) ([]byte, error) {
+	out, err := r.Renderer.Render(source)
+	if err != nil {
+		return out, err
+	}
+	return []byte(LinkifyWrapped(string(out), string(source))), nil
 }
@@ -0,0 +1,449 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/samsaffron/term-llm/internal/filetrack"
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+// Run summary file statuses.
+const (
+	RunFileCreated  = "created"
+	RunFileModified = "modified"
+	RunFileDeleted  = "deleted"
+)
+
+// runSummaryMaxFiles caps the files listed in the rendered box; the debug log
+// entry always carries all of them.
+const runSummaryMaxFiles = 12
+
+// RunToolStat counts the calls of one tool during a run.
+type RunToolStat struct {
+	Name     string `json:"name"`
+	Calls    int    `json:"calls"`
+	Failures int    `json:"failures,omitempty"`
+}
+
+// RunFileStat describes one file a run changed, with the lines its changes
+// added and removed.
+type RunFileStat struct {
+	Path   string `json:"path"`
+	Status string `json:"status"` // RunFileCreated, RunFileModified or RunFileDeleted
+	Adds   int    `json:"adds"`
+	Dels   int    `json:"dels"`
+}
+
+// RunSummaryData is a point-in-time copy of a RunSummary. It is what gets
+// rendered and what is written to the debug log.
+type RunSummaryData struct {
+	ElapsedMs         int64         `json:"elapsed_ms"`
+	Turns             int           `json:"turns"`
+	MaxTurns          int           `json:"max_turns,omitempty"`
+	InputTokens       int           `json:"input_tokens"`
+	OutputTokens      int           `json:"output_tokens"`
+	CachedInputTokens int           `json:"cached_input_tokens,omitempty"`
+	CacheWriteTokens  int           `json:"cache_write_tokens,omitempty"`
+	EstimatedCostUSD  *float64      `json:"estimated_cost_usd,omitempty"`
+	Tools             []RunToolStat `json:"tools,omitempty"`
+	Files             []RunFileStat `json:"files,omitempty"`
+}
+
+// RunSummary collects a recap of an agentic run: elapsed time, turns, token
+// usage, tool calls and the files they changed. It is fed from engine
+// callbacks (ObserveTurn, ObserveToolExec) or replayed from stored history
+// (ObserveMessages), so ask, edit and chat share one summary. Safe for
+// concurrent use; parallel tool calls report concurrently.
+type RunSummary struct {
+	mu       sync.Mutex
+	model    string
+	provider string
+	start    time.Time
+	end      time.Time
+	maxTurns int
+	turns    int
+	lastTurn int
+	tokens   UsageCall
+	calls    []UsageCall
+	cost     *float64
+	tools    []RunToolStat
+	files    []RunFileStat
+}
+
+// NewRunSummary starts a summary for a run against model limited to maxTurns
+// turns (0 = no limit shown).
+func NewRunSummary(model string, maxTurns int) *RunSummary {
+	return &RunSummary{model: strings.TrimSpace(model), start: time.Now(), maxTurns: maxTurns, lastTurn: -1}
+}
+
+// SetProvider sets the provider whose configured prices apply to the run.
+func (s *RunSummary) SetProvider(provider string) {
+	s.mu.Lock()
+	s.provider = strings.TrimSpace(provider)
+	s.mu.Unlock()
+}
+
+// SetStart moves the start of the elapsed-time window, e.g. to when a chat
+// session began rather than when the summary was built.
+func (s *RunSummary) SetStart(t time.Time) {
+	s.mu.Lock()
+	s.start = t
+	s.mu.Unlock()
+}
+
+// ObserveTurn records a completed turn and its token usage; the arguments
+// match llm.TurnCompletedCallback so it can be called from there. The engine
+// also reports injected messages against the turn that just finished with
+// empty metrics; those add no turn.
+func (s *RunSummary) ObserveTurn(turnIndex int, metrics llm.TurnMetrics) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if metrics != (llm.TurnMetrics{}) || turnIndex != s.lastTurn {
+		s.turns++
+	}
+	s.lastTurn = turnIndex
+	call := UsageCall{
+		Model:             s.model,
+		InputTokens:       metrics.InputTokens,
+		OutputTokens:      metrics.OutputTokens,
+		CachedInputTokens: metrics.CachedInputTokens,
+		CacheWriteTokens:  metrics.CacheWriteTokens,
+	}
+	s.addTokensLocked(call)
+	if call.InputTokens != 0 || call.OutputTokens != 0 || call.CachedInputTokens != 0 || call.CacheWriteTokens != 0 {
+		s.calls = append(s.calls, call)
+	}
+}
+
+// SetTokens replaces the token totals with ones tracked elsewhere (chat keeps
+// them in SessionStats). Cost must then be supplied with SetEstimatedCost.
+func (s *RunSummary) SetTokens(input, output, cached, cacheWrite int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = UsageCall{InputTokens: input, OutputTokens: output, CachedInputTokens: cached, CacheWriteTokens: cacheWrite}
+	s.calls = nil
+	s.cost = nil
+}
+
+func (s *RunSummary) addTokensLocked(call UsageCall) {
+	s.tokens.InputTokens += call.InputTokens
+	s.tokens.OutputTokens += call.OutputTokens
+	s.tokens.CachedInputTokens += call.CachedInputTokens
+	s.tokens.CacheWriteTokens += call.CacheWriteTokens
+}
+
+// ObserveToolExec records a finished tool call from its EventToolExecEnd
+// event, including the files it changed. It matches
+// llm.ToolExecutedCallback's event so it can be called from there.
+func (s *RunSummary) ObserveToolExec(event llm.Event) {
+	if event.ToolName == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addToolLocked(event.ToolName, !event.ToolSuccess)
+	if len(event.ToolFileChanges) > 0 {
+		for _, change := range event.ToolFileChanges {
+			s.addFileLocked(change.Path, change.Kind, change.Adds, change.Dels)
+		}
+		return
+	}
+	s.addDiffsLocked(event.ToolDiffs)
+}
+
+// ObserveMessages replays stored history: every assistant message counts as
+// a turn and every tool result as a tool call, with file changes taken from
+// the result's diffs. Token totals are not part of history; use SetTokens.
+func (s *RunSummary) ObserveMessages(messages []llm.Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, msg := range messages {
+		if msg.Role == llm.RoleAssistant {
+			s.turns++
+		}
+		for _, part := range msg.Parts {
+			result := part.ToolResult
+			if result == nil || result.Name == "" {
+				continue
+			}
+			s.addToolLocked(result.Name, result.IsError)
+			s.addDiffsLocked(result.Diffs)
+		}
+	}
+}
+
+// ObserveFileChange records a file change made outside a tool call, such as
+// an edit applied by the edit command. operation is an llm.DiffOperation*
+// value or empty for a modification.
+func (s *RunSummary) ObserveFileChange(path, operation, oldContent, newContent string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addDiffsLocked([]llm.DiffData{{File: path, Old: oldContent, New: newContent, Operation: operation}})
+}
+
+func (s *RunSummary) addToolLocked(name string, failed bool) {
+	idx := -1
+	for i := range s.tools {
+		if s.tools[i].Name == name {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		s.tools = append(s.tools, RunToolStat{Name: name})
+		idx = len(s.tools) - 1
+	}
+	s.tools[idx].Calls++
+	if failed {
+		s.tools[idx].Failures++
+	}
+}
+
+func (s *RunSummary) addDiffsLocked(diffs []llm.DiffData) {
+	for _, d := range diffs {
+		kind := "modify"
+		switch d.Operation {
+		case llm.DiffOperationCreate:
+			kind = "create"
+		case llm.DiffOperationDelete:
+			kind = "delete"
+		}
+		adds, dels := filetrack.CountAddsDels([]byte(d.Old), []byte(d.New))
+		s.addFileLocked(d.File, kind, adds, dels)
+	}
+}
+
+// addFileLocked merges one change into the file's entry. kind uses the
+// llm.FileChange vocabulary: create, modify or delete.
+func (s *RunSummary) addFileLocked(path, kind string, adds, dels int) {
+	if path == "" {
+		return
+	}
+	for i := range s.files {
+		f := &s.files[i]
+		if f.Path != path {
+			continue
+		}
+		f.Adds += adds
+		f.Dels += dels
+		switch {
+		case kind == "delete":
+			f.Status = RunFileDeleted
+		case f.Status == RunFileDeleted:
+			// Deleted and written again: the file still exists, changed.
+			f.Status = RunFileModified
+		}
+		return
+	}
+	status := RunFileModified
+	switch kind {
+	case "create":
+		status = RunFileCreated
+	case "delete":
+		status = RunFileDeleted
+	}
+	s.files = append(s.files, RunFileStat{Path: path, Status: status, Adds: adds, Dels: dels})
+}
+
+// Finish freezes the elapsed time. Calling it again moves the end forward.
+func (s *RunSummary) Finish() {
+	s.mu.Lock()
+	s.end = time.Now()
+	s.mu.Unlock()
+}
+
+// SetEstimatedCost attaches a cost computed elsewhere.
+func (s *RunSummary) SetEstimatedCost(cost float64) {
+	if cost < 0 {
+		return
+	}
+	s.mu.Lock()
+	s.cost = &cost
+	s.mu.Unlock()
+}
+
+// EstimateCost prices the observed turns with bundled or cached pricing. On
+// error (unknown model or pricing) the summary is shown without a cost.
+func (s *RunSummary) EstimateCost() error {
+	s.mu.Lock()
+	calls := append([]UsageCall(nil), s.calls...)
+	provider, model := s.provider, s.model
+	s.mu.Unlock()
+	cost, err := estimateUsageCallsCost(calls, provider, model)
+	if err != nil {
+		return err
+	}
+	s.SetEstimatedCost(cost)
+	return nil
+}
+
+// HasToolActivity reports whether any tool ran or any file changed.
+func (s *RunSummary) HasToolActivity() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.tools) > 0 || len(s.files) > 0
+}
+
+// Data returns a copy of the collected summary.
+func (s *RunSummary) Data() RunSummaryData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	end := s.end
+	if end.IsZero() {
+		end = time.Now()
+	}
+	data := RunSummaryData{
+		ElapsedMs:         end.Sub(s.start).Milliseconds(),
+		Turns:             s.turns,
+		MaxTurns:          s.maxTurns,
+		InputTokens:       s.tokens.InputTokens,
+		OutputTokens:      s.tokens.OutputTokens,
+		CachedInputTokens: s.tokens.CachedInputTokens,
+		CacheWriteTokens:  s.tokens.CacheWriteTokens,
+		Tools:             append([]RunToolStat(nil), s.tools...),
+		Files:             append([]RunFileStat(nil), s.files...),
+	}
+	if s.cost != nil {
+		cost := *s.cost
+		data.EstimatedCostUSD = &cost
+	}
+	return data
+}
+
+// Render draws the summary as a compact box for the end of a run.
+func (s *RunSummary) Render() string {
+	return s.Data().Render()
+}
+
+// Render draws the summary as a compact box.
+func (d RunSummaryData) Render() string {
+	lines := d.lines()
+	width := 0
+	for _, line := range lines {
+		width = max(width, runewidth.StringWidth(line))
+	}
+	title := "─ Summary "
+	width = max(width, runewidth.StringWidth(title))
+	var b strings.Builder
+	b.WriteString("╭" + title + strings.Repeat("─", width+2-runewidth.StringWidth(title)) + "╮\n")
+	for _, line := range lines {
+		b.WriteString("│ " + runewidth.FillRight(line, width) + " │\n")
+	}
+	b.WriteString("╰" + strings.Repeat("─", width+2) + "╯")
+	return b.String()
+}
+
+// Text returns the summary rows without the surrounding box, for places
+// that already frame it such as a chat dialog.
+func (d RunSummaryData) Text() string {
+	return strings.Join(d.lines(), "\n")
+}
+
+// lines lays the summary out as label/value rows. Paths under the working
+// directory are shown relative to it.
+func (d RunSummaryData) lines() []string {
+	type row struct{ label, value string }
+	var rows []row
+
+	rows = append(rows, row{"Elapsed", FormatElapsedDuration(time.Duration(d.ElapsedMs) * time.Millisecond)})
+	turns := fmt.Sprintf("%d", d.Turns)
+	if d.MaxTurns > 0 {
+		turns = fmt.Sprintf("%d/%d", d.Turns, d.MaxTurns)
+	}
+	rows = append(rows, row{"Turns", turns})
+
+	tokenParts := []string{formatStatsTokenCount(d.InputTokens) + " in"}
+	if d.CachedInputTokens > 0 {
+		tokenParts = append(tokenParts, formatStatsTokenCount(d.CachedInputTokens)+" cached")
+	}
+	if d.CacheWriteTokens > 0 {
+		tokenParts = append(tokenParts, formatStatsTokenCount(d.CacheWriteTokens)+" cache write")
+	}
+	tokens := fmt.Sprintf("%s → %s out", strings.Join(tokenParts, " + "), formatStatsTokenCount(d.OutputTokens))
+	if d.EstimatedCostUSD != nil {
+		tokens += fmt.Sprintf(" ($%.4f)", *d.EstimatedCostUSD)
+	}
+	rows = append(rows, row{"Tokens", tokens})
+
+	if len(d.Tools) > 0 {
+		items := make([]string, 0, len(d.Tools))
+		for _, t := range d.Tools {
+			item := fmt.Sprintf("%s ×%d", t.Name, t.Calls)
+			if t.Failures > 0 {
+				item += fmt.Sprintf(" (%d failed)", t.Failures)
+			}
+			items = append(items, item)
+		}
+		for i, line := range wrapRunSummaryItems(items, 60) {
+			label := ""
+			if i == 0 {
+				label = "Tools"
+			}
+			rows = append(rows, row{label, line})
+		}
+	}
+
+	if len(d.Files) > 0 {
+		cwd, _ := os.Getwd()
+		for i, f := range d.Files {
+			label := ""
+			if i == 0 {
+				label = "Files"
+			}
+			if i == runSummaryMaxFiles {
+				rows = append(rows, row{label, fmt.Sprintf("… %d more", len(d.Files)-i)})
+				break
+			}
+			rows = append(rows, row{label, fmt.Sprintf("%-8s %s +%d -%d", f.Status, displayRunSummaryPath(cwd, f.Path), f.Adds, f.Dels)})
+		}
+	}
+
+	labelWidth := 0
+	for _, r := range rows {
+		labelWidth = max(labelWidth, runewidth.StringWidth(r.label))
+	}
+	lines := make([]string, len(rows))
+	for i, r := range rows {
+		lines[i] = runewidth.FillRight(r.label, labelWidth) + "  " + r.value
+	}
+	return lines
+}
+
+// wrapRunSummaryItems joins items with ", " into lines of at most width
+// columns, never splitting an item.
+func wrapRunSummaryItems(items []string, width int) []string {
+	var lines []string
+	current := ""
+	for _, item := range items {
+		switch {
+		case current == "":
+			current = item
+		case runewidth.StringWidth(current)+2+runewidth.StringWidth(item) > width:
+			lines = append(lines, current+",")
+			current = item
+		default:
+			current += ", " + item
+		}
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+func displayRunSummaryPath(cwd, path string) string {
+	if cwd == "" || !filepath.IsAbs(path) {
+		return path
+	}
+	rel, err := filepath.Rel(cwd, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return rel
+}
@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/samsaffron/term-llm/internal/llm"
+)
+
+func TestRunSummaryCollectsTurnsToolsAndFiles(t *testing.T) {
+	s := NewRunSummary("mock-model", 50)
+	s.ObserveTurn(0, llm.TurnMetrics{InputTokens: 1000, OutputTokens: 200, ToolCalls: 3})
+	s.ObserveToolExec(llm.Event{Type: llm.EventToolExecEnd, ToolName: "read_file", ToolSuccess: true})
+	s.ObserveToolExec(llm.Event{Type: llm.EventToolExecEnd, ToolName: "edit_file", ToolSuccess: true, ToolDiffs: []llm.DiffData{
+		{File: "/src/main.go", Old: "a\nb\n", New: "a\nc\nd\n"},
+	}})
+	s.ObserveToolExec(llm.Event{Type: llm.EventToolExecEnd, ToolName: "edit_file", ToolSuccess: false})
+	// Injected messages are reported against the finished turn with no usage.
+	s.ObserveTurn(0, llm.TurnMetrics{})
+	s.ObserveTurn(1, llm.TurnMetrics{InputTokens: 1500, OutputTokens: 50, CachedInputTokens: 900})
+	s.ObserveToolExec(llm.Event{Type: llm.EventToolExecEnd, ToolName: "write_file", ToolSuccess: true, ToolFileChanges: []llm.FileChange{
+		{Path: "/src/new.go", Kind: "create", Adds: 12},
+	}})
+	s.ObserveToolExec(llm.Event{Type: llm.EventToolExecEnd, ToolName: "edit_file", ToolSuccess: true, ToolDiffs: []llm.DiffData{
+		{File: "/src/main.go", Old: "d\n", New: "", Operation: llm.DiffOperationDelete},
+	}})
+	s.Finish()
+
+	d := s.Data()
+	if d.Turns != 2 || d.MaxTurns != 50 {
+		t.Fatalf("turns = %d/%d, want 2/50", d.Turns, d.MaxTurns)
+	}
+	if d.InputTokens != 2500 || d.OutputTokens != 250 || d.CachedInputTokens != 900 {
+		t.Fatalf("tokens = %+v", d)
+	}
+	wantTools := []RunToolStat{{"read_file", 1, 0}, {"edit_file", 3, 1}, {"write_file", 1, 0}}
+	if len(d.Tools) != len(wantTools) {
+		t.Fatalf("tools = %+v, want %+v", d.Tools, wantTools)
+	}
+	for i, want := range wantTools {
+		if d.Tools[i] != want {
+			t.Fatalf("tools[%d] = %+v, want %+v", i, d.Tools[i], want)
+		}
+	}
+	wantFiles := []RunFileStat{
+		{Path: "/src/main.go", Status: RunFileDeleted, Adds: 2, Dels: 2},
+		{Path: "/src/new.go", Status: RunFileCreated, Adds: 12},
+	}
+	if len(d.Files) != len(wantFiles) || d.Files[0] != wantFiles[0] || d.Files[1] != wantFiles[1] {
+		t.Fatalf("files = %+v, want %+v", d.Files, wantFiles)
+	}
+
+	raw, err := json.Marshal(d)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if !strings.Contains(string(raw), `"tools":[{"name":"read_file","calls":1}`) {
+		t.Fatalf("debug log entry = %s", raw)
+	}
+}
+
+func TestRunSummaryReplaysHistoryAndRendersBox(t *testing.T) {
+	s := NewRunSummary("", 0)
+	s.ObserveMessages([]llm.Message{
+		llm.UserText("fix it"),
+		{Role: llm.RoleAssistant, Parts: []llm.Part{{Type: llm.PartToolCall, ToolCall: &llm.ToolCall{ID: "1", Name: "edit_file"}}}},
+		{Role: llm.RoleTool, Parts: []llm.Part{{Type: llm.PartToolResult, ToolResult: &llm.ToolResult{
+			ID: "1", Name: "edit_file", Diffs: []llm.DiffData{{File: "notes.txt", New: "one\ntwo\n", Operation: llm.DiffOperationCreate}},
+		}}}},
+		{Role: llm.RoleTool, Parts: []llm.Part{{Type: llm.PartToolResult, ToolResult: &llm.ToolResult{ID: "2", Name: "shell", IsError: true}}}},
+		llm.AssistantText("done"),
+	})
+	s.SetTokens(1200, 300, 0, 0)
+	s.SetEstimatedCost(0.0123)
+
+	box := s.Render()
+	for _, want := range []string{"Turns    2", "1.2K in → 300 out ($0.0123)", "edit_file ×1, shell ×1 (1 failed)", "created  notes.txt +2 -0"} {
+		if !strings.Contains(box, want) {
+			t.Fatalf("summary box missing %q:\n%s", want, box)
+		}
+	}
+	lines := strings.Split(box, "\n")
+	if !strings.HasPrefix(lines[0], "╭─ Summary ") || !strings.HasPrefix(lines[len(lines)-1], "╰") {
+		t.Fatalf("summary should be boxed:\n%s", box)
+	}
+	for _, line := range lines[1:] {
+		if runewidth.StringWidth(line) != runewidth.StringWidth(lines[0]) {
+			t.Fatalf("box lines differ in width:\n%s", box)
+		}
+	}
+	if strings.Contains(s.Data().Text(), "│") {
+		t.Fatalf("Text should not draw the box:\n%s", s.Data().Text())
+	}
+}
@@ -373,7 +373,7 @@ func renderToolSegmentBase(seg *Segment, wavePos int, width int, expanded bool)
 		// Tool name normal, params slightly muted (with space before info if present)
 		info = truncateToolInfo(seg.ToolName, info, renderWidth)
 		if info != "" {
-			return appendToolExpandHint(SuccessCircle()+" "+seg.ToolName+" "+paramStyle.Render(info), seg, expanded)
+			return appendToolExpandHint(SuccessCircle()+" "+seg.ToolName+" "+Linkify(paramStyle.Render(info)), seg, expanded)
 		}
 		return appendToolExpandHint(SuccessCircle()+" "+seg.ToolName, seg, expanded)
 	case ToolError:
@@ -384,7 +384,7 @@ func renderToolSegmentBase(seg *Segment, wavePos int, width int, expanded bool)
 		// Tool name normal, params slightly muted (with space before info if present)
 		info = truncateToolInfo(seg.ToolName, info, renderWidth)
 		if info != "" {
-			return appendToolExpandHint(ErrorCircle()+" "+seg.ToolName+" "+paramStyle.Render(info), seg, expanded)
+			return appendToolExpandHint(ErrorCircle()+" "+seg.ToolName+" "+Linkify(paramStyle.Render(info)), seg, expanded)
 		}
 		return appendToolExpandHint(ErrorCircle()+" "+seg.ToolName, seg, expanded)
 	}
@@ -56,6 +56,7 @@ type SessionStats struct {
 	inTool        bool
 
 	currentModel       string
+	provider           string
 	requestStartTime   time.Time
 	firstActivityTime  time.Time
 	activityStartTime  time.Time
@@ -96,6 +97,10 @@ func (s *SessionStats) SeedTotals(input, output, cached, cacheWrite, toolCalls,
 // SetModel sets the model attached to subsequently completed usage calls.
 func (s *SessionStats) SetModel(model string) { s.currentModel = strings.TrimSpace(model) }
 
+// SetProvider sets the provider whose configured prices apply to the
+// session's usage calls.
+func (s *SessionStats) SetProvider(provider string) { s.provider = strings.TrimSpace(provider) }
+
 func (s *SessionStats) AddUsage(input, output, cached, cacheWrite int) {
 	s.addUsageAt(input, output, cached, cacheWrite, time.Now(), true)
 }
@@ -17,6 +17,14 @@ func EstimateSessionStatsCost(stats *SessionStats, fallbackModel string) (float6
 	if !complete {
 		return 0, fmt.Errorf("resumed session has unpriced historical usage")
 	}
+	return estimateUsageCallsCost(calls, stats.provider, fallbackModel)
+}
+
+// estimateUsageCallsCost prices each provider request separately, using
+// fallbackModel for calls that did not record their model. Prices are looked
+// up as stats usage does, so a price configured for provider:model wins;
+// guardian calls run on their own provider and use bare model prices.
+func estimateUsageCallsCost(calls []UsageCall, provider, fallbackModel string) (float64, error) {
 	if len(calls) == 0 {
 		return 0, fmt.Errorf("no current-process usage recorded")
 	}
@@ -34,7 +42,11 @@ func EstimateSessionStatsCost(stats *SessionStats, fallbackModel string) (float6
 		if model == "" {
 			return 0, fmt.Errorf("model unknown")
 		}
-		cost, err := fetcher.CalculateCostLocal(usage.UsageEntry{
+		callProvider := provider
+		if call.Guardian {
+			callProvider = ""
+		}
+		cost, err := fetcher.CalculateProviderCostLocal(callProvider, usage.UsageEntry{
 			Model:            model,
 			InputTokens:      call.InputTokens,
 			OutputTokens:     call.OutputTokens,
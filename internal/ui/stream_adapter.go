@@ -311,9 +311,20 @@ func (a *StreamAdapter) ProcessStream(ctx context.Context, stream llm.Stream) {
 				}
 			}
 
+		case llm.EventImageGenerated:
+			// Model-generated images are saved by the engine; unsaved ones
+			// have no path to show.
+			if event.ImagePath != "" {
+				if !emit(ImageEvent(event.ImagePath)) {
+					return
+				}
+			}
+
 		case llm.EventRetry:
 			a.updateStats(func(stats *SessionStats) { stats.ScheduleRetryStart(event.RetryWaitSecs) })
-			if !emit(RetryEvent(event.RetryAttempt, event.RetryMaxAttempts, event.RetryWaitSecs)) {
+			retry := RetryEvent(event.RetryAttempt, event.RetryMaxAttempts, event.RetryWaitSecs)
+			retry.RetryReason = event.RetryReason.Label()
+			if !emit(retry) {
 				return
 			}
 
@@ -67,6 +67,7 @@ type StreamEvent struct {
 	RetryAttempt int
 	RetryMax     int
 	RetryWait    float64
+	RetryReason  string // human-readable cause, e.g. "connection error"
 
 	// Completion (for StreamEventDone)
 	Done   bool
@@ -202,6 +203,15 @@ func (e StreamEvent) RetryStatus(label string, precision int, suffix string) str
 	return FormatRetryStatus(label, e.RetryAttempt, e.RetryMax, e.RetryWait, precision, suffix)
 }
 
+// RetryLabel returns the status label for a retry caused by reason, or
+// fallback when the cause is unknown.
+func RetryLabel(reason, fallback string) string {
+	if reason == "" {
+		return fallback
+	}
+	return "Retrying after " + reason
+}
+
 // RetryEvent creates a retry notification event
 func RetryEvent(attempt, max int, waitSecs float64) StreamEvent {
 	return StreamEvent{
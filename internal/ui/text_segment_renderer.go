@@ -27,10 +27,13 @@ func (*snapshotSink) Reset()                      {}
 // Uses partial flowing snapshots (no cursor control) since Bubble Tea owns the terminal.
 func NewTextSegmentRenderer(width int) (*TextSegmentRenderer, error) {
 	var output snapshotSink
-	renderer := rendermarkdown.NewANSI(rendermarkdown.Config{
+	var renderer rendermarkdown.Renderer = rendermarkdown.NewANSI(rendermarkdown.Config{
 		Palette: currentMarkdownPalette(),
 		Width:   width,
 	})
+	if HyperlinksEnabled() {
+		renderer = linkingRenderer{renderer}
+	}
 
 	sr, err := streaming.NewRendererWithOptions(
 		&output,
@@ -14,23 +14,32 @@ import (
 // PrintUnifiedDiff prints a clean unified diff between old and new content
 // If multiFile is true, shows the filename header (for multi-file diffs)
 func PrintUnifiedDiff(filePath, oldContent, newContent string) {
-	printUnifiedDiffInternal(filePath, oldContent, newContent, false)
+	printUnifiedDiffInternal(filePath, oldContent, newContent, "")
 }
 
 // PrintUnifiedDiffMulti prints a diff with filename header (for multi-file edits)
 func PrintUnifiedDiffMulti(filePath, oldContent, newContent string) {
-	printUnifiedDiffInternal(filePath, oldContent, newContent, true)
+	printUnifiedDiffInternal(filePath, oldContent, newContent, "")
 }
 
-func printUnifiedDiffInternal(filePath, oldContent, newContent string, _ bool) {
+// PrintUnifiedDiffWithOperation prints a diff whose header names the
+// operation, e.g. "Create:" for llm.DiffOperationCreate.
+func PrintUnifiedDiffWithOperation(filePath, oldContent, newContent, operation string) {
+	printUnifiedDiffInternal(filePath, oldContent, newContent, operation)
+}
+
+func printUnifiedDiffInternal(filePath, oldContent, newContent, operation string) {
+	styles := DefaultStyles()
 	if oldContent == newContent {
+		// An empty file being created or deleted still needs a header.
+		if operation != "" {
+			fmt.Printf("%s %s\n", styles.Bold.Render(diffSegmentHeader(operation)), filePath)
+		}
 		return
 	}
 
-	styles := DefaultStyles()
-
 	// Print header
-	fmt.Printf("%s %s\n", styles.Bold.Render("Edit:"), filePath)
+	fmt.Printf("%s %s\n", styles.Bold.Render(diffSegmentHeader(operation)), filePath)
 
 	// Generate unified diff using gotextdiff
 	diffBytes := diff.Diff(filePath, []byte(oldContent), filePath, []byte(newContent))
@@ -807,8 +816,11 @@ type bufferedRemoval struct {
 }
 
 func diffSegmentHeader(operation string) string {
-	if strings.EqualFold(operation, llm.DiffOperationCreate) {
+	switch {
+	case strings.EqualFold(operation, llm.DiffOperationCreate):
 		return "Create:"
+	case strings.EqualFold(operation, llm.DiffOperationDelete):
+		return "Delete:"
 	}
 	return "Edit:"
 }
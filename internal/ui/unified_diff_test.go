@@ -288,6 +288,15 @@ func TestRenderDiffSegmentCreateHeader(t *testing.T) {
 	}
 }
 
+func TestRenderDiffSegmentDeleteHeader(t *testing.T) {
+	result := RenderDiffSegmentWithOperation("demo.rb", "puts \"bye\"\n", "", 120, 1, llm.DiffOperationDelete)
+
+	plain := ansiRegexp.ReplaceAllString(result, "")
+	if !strings.Contains(plain, "Delete: demo.rb") {
+		t.Fatalf("expected delete header, got:\n%s", plain)
+	}
+}
+
 func TestRenderDiffSegmentDefaultHeader(t *testing.T) {
 	result := RenderDiffSegment("demo.rb", "old\n", "new\n", 120, 1)
 
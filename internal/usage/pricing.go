@@ -128,9 +128,69 @@ func gpt56Pricing(input, read, write, output float64) ModelPricing {
 	}
 }
 
+// PriceOverride is a configured price for a model in USD per million
+// tokens. CachedInput and CacheWrite fall back to the input price when unset.
+type PriceOverride struct {
+	Input       float64
+	Output      float64
+	CachedInput float64
+	CacheWrite  float64
+}
+
+func (o PriceOverride) modelPricing() ModelPricing {
+	const perMillion = 1_000_000
+	cachedInput, cacheWrite := o.CachedInput, o.CacheWrite
+	if cachedInput == 0 {
+		cachedInput = o.Input
+	}
+	if cacheWrite == 0 {
+		cacheWrite = o.Input
+	}
+	return ModelPricing{
+		InputCostPerToken:           o.Input / perMillion,
+		OutputCostPerToken:          o.Output / perMillion,
+		CacheReadInputTokenCost:     cachedInput / perMillion,
+		CacheCreationInputTokenCost: cacheWrite / perMillion,
+	}
+}
+
+var (
+	priceOverridesMu sync.RWMutex
+	priceOverrides   map[string]ModelPricing
+)
+
+// SetPriceOverrides replaces the configured prices. Keys are either
+// "provider:model", which applies only to that provider, or a bare model
+// name. Overrides win over bundled and fetched pricing.
+func SetPriceOverrides(overrides map[string]PriceOverride) {
+	converted := make(map[string]ModelPricing, len(overrides))
+	for key, o := range overrides {
+		converted[strings.TrimSpace(key)] = o.modelPricing()
+	}
+	priceOverridesMu.Lock()
+	priceOverrides = converted
+	priceOverridesMu.Unlock()
+}
+
+func lookupPriceOverride(key string) (ModelPricing, bool) {
+	priceOverridesMu.RLock()
+	defer priceOverridesMu.RUnlock()
+	pricing, ok := priceOverrides[key]
+	return pricing, ok
+}
+
+// lookupFixedPricing returns configured or bundled pricing, neither of which
+// needs the LiteLLM table.
+func lookupFixedPricing(modelName string) (ModelPricing, bool) {
+	if pricing, ok := lookupPriceOverride(modelName); ok {
+		return pricing, true
+	}
+	return lookupBundledPricing(modelName)
+}
+
 // GetPricing returns pricing for a model, fetching if necessary
 func (p *PricingFetcher) GetPricing(modelName string) (ModelPricing, error) {
-	if pricing, ok := lookupBundledPricing(modelName); ok {
+	if pricing, ok := lookupFixedPricing(modelName); ok {
 		return pricing, nil
 	}
 
@@ -264,7 +324,7 @@ func (p *PricingFetcher) parseData(data []byte) error {
 // cache. It never performs a network request and accepts stale cache data: exit
 // summaries must remain immediate even when the network is unavailable.
 func (p *PricingFetcher) GetPricingLocal(modelName string) (ModelPricing, error) {
-	if pricing, ok := lookupBundledPricing(modelName); ok {
+	if pricing, ok := lookupFixedPricing(modelName); ok {
 		return pricing, nil
 	}
 	p.localOnce.Do(func() {
@@ -310,6 +370,32 @@ func (p *PricingFetcher) lookupLoadedPricing(modelName string) (ModelPricing, er
 	return ModelPricing{}, fmt.Errorf("pricing not found for model: %s", modelName)
 }
 
+// CalculateProviderCostLocal is CalculateCostLocal for usage served by the
+// named provider: a price configured for "provider:model" wins over one for
+// the bare model name.
+func (p *PricingFetcher) CalculateProviderCostLocal(provider string, entry UsageEntry) (float64, error) {
+	if pricing, ok := lookupProviderPriceOverride(provider, entry.Model); ok {
+		return calculateCostWithPricing(entry, pricing), nil
+	}
+	return p.CalculateCostLocal(entry)
+}
+
+// CalculateProviderCost is CalculateProviderCostLocal, fetching pricing when
+// it is not available locally.
+func (p *PricingFetcher) CalculateProviderCost(provider string, entry UsageEntry) (float64, error) {
+	if pricing, ok := lookupProviderPriceOverride(provider, entry.Model); ok {
+		return calculateCostWithPricing(entry, pricing), nil
+	}
+	return p.CalculateCost(entry)
+}
+
+func lookupProviderPriceOverride(provider, model string) (ModelPricing, bool) {
+	if provider == "" || model == "" {
+		return ModelPricing{}, false
+	}
+	return lookupPriceOverride(provider + ":" + model)
+}
+
 // CalculateCostLocal calculates cost without fetching pricing from the network.
 func (p *PricingFetcher) CalculateCostLocal(entry UsageEntry) (float64, error) {
 	if entry.Model == "" {
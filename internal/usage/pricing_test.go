@@ -196,3 +196,45 @@ func TestGPT56LongContextPricingRepricesWholeRequest(t *testing.T) {
 		t.Fatalf("above-threshold cost = %g, want %g", aboveThreshold, wantLong)
 	}
 }
+
+func TestPriceOverrides(t *testing.T) {
+	SetPriceOverrides(map[string]PriceOverride{
+		"my-model":            {Input: 2, Output: 10, CachedInput: 0.5},
+		"local:my-model":      {Input: 0, Output: 0},
+		"gpt-5.6-luna":        {Input: 3, Output: 12},
+		"acme:no-cache-price": {Input: 4, Output: 8},
+	})
+	t.Cleanup(func() { SetPriceOverrides(nil) })
+
+	fetcher := NewPricingFetcher()
+	fetcher.cacheDir = t.TempDir()
+	fetcher.httpClient = nil
+
+	entry := UsageEntry{Model: "my-model", InputTokens: 1_000_000, OutputTokens: 100_000, CacheReadTokens: 2_000_000}
+	tests := []struct {
+		name     string
+		provider string
+		entry    UsageEntry
+		want     float64
+		wantErr  bool
+	}{
+		// 1M input at $2, 0.1M output at $10, 2M cached at $0.50.
+		{"bare model", "anthropic", entry, 2 + 1 + 1, false},
+		{"provider-specific wins", "local", entry, 0, false},
+		{"override beats bundled", "", UsageEntry{Model: "gpt-5.6-luna", InputTokens: 1_000_000}, 3, false},
+		// Without a cached price, cached tokens cost the input price.
+		{"cached defaults to input", "acme", UsageEntry{Model: "no-cache-price", CacheReadTokens: 1_000_000}, 4, false},
+		{"missing entry", "acme", UsageEntry{Model: "unknown-model", InputTokens: 1}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fetcher.CalculateProviderCostLocal(tt.provider, tt.entry)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("cost = %g, want %g", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+//go:build windows
+
+package widgets
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/samsaffron/term-llm/internal/procutil"
+)
+
+// killProcessGroup kills the widget's process tree. Windows cannot deliver
+// SIGTERM, so both the polite and the forced stop end the tree immediately.
+func killProcessGroup(proc *os.Process, sig syscall.Signal) {
+	if proc == nil {
+		return
+	}
+	if err := procutil.KillProcessTree(proc.Pid); err != nil {
+		_ = proc.Kill()
+	}
+}
@@ -553,15 +553,6 @@ func (e *widgetEntry) removeSocketIfNeeded() {
 	}
 }
 
-func killProcessGroup(proc *os.Process, sig syscall.Signal) {
-	if proc == nil {
-		return
-	}
-	if err := syscall.Kill(-proc.Pid, sig); err != nil {
-		_ = proc.Signal(sig)
-	}
-}
-
 func (m *Manager) idleLoop() {
 	ticker := time.NewTicker(time.Minute)
 	defer ticker.Stop()